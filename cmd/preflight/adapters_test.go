@@ -228,14 +228,15 @@ func TestWatchPreflightAdapter_WithMode_ReturnsWatchPreflight(t *testing.T) {
 // ---------------------------------------------------------------------------
 
 //nolint:tparallel // modifies global flags
-func TestRunCatalogVerify_SignaturesNotSupported(t *testing.T) {
+func TestRunCatalogVerify_SignaturesNoTrustedRoot(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
 	orig := catalogVerifySigs
 	defer func() { catalogVerifySigs = orig }()
 	catalogVerifySigs = true
 
 	err := runCatalogVerify(nil, nil)
 	require.Error(t, err)
-	assert.Contains(t, err.Error(), "not supported yet")
+	assert.Contains(t, err.Error(), "preflight trust sigstore-root")
 }
 
 //nolint:tparallel // modifies global flags
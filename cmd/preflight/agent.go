@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -13,9 +14,12 @@ import (
 	"time"
 
 	"github.com/felixgeelhaar/preflight/internal/adapters/ipc"
+	"github.com/felixgeelhaar/preflight/internal/adapters/logging"
 	"github.com/felixgeelhaar/preflight/internal/app"
 	"github.com/felixgeelhaar/preflight/internal/domain/agent"
 	"github.com/felixgeelhaar/preflight/internal/domain/execution"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/securestate"
 	"github.com/spf13/cobra"
 )
 
@@ -25,7 +29,8 @@ var agentCmd = &cobra.Command{
 	Long: `Manage the Preflight background agent for scheduled reconciliation and drift detection.
 
 The agent runs in the background and periodically checks your configuration for drift,
-applying remediations according to your policy settings.`,
+applying remediations according to your policy settings. Plugins that are installed,
+updated or removed while it runs are reloaded automatically.`,
 }
 
 // Agent start command flags
@@ -48,10 +53,14 @@ Examples:
   preflight agent start                          # Start with defaults
   preflight agent start --schedule 15m           # Check every 15 minutes
   preflight agent start --foreground             # Run in foreground
-  preflight agent start --remediation auto       # Auto-apply safe remediations`,
+  preflight agent start --remediation auto       # Auto-apply safe remediations
+  preflight agent start --snapshot-every 6h      # Snapshot managed files every 6 hours`,
 	RunE: runAgentStart,
 }
 
+// agentPluginPollInterval is how often the agent checks installed plugins for changes.
+const agentPluginPollInterval = 5 * time.Second
+
 // Agent stop command flags
 var agentStopForce bool
 
@@ -118,6 +127,18 @@ This stops the agent if running and removes the service configuration.`,
 	RunE: runAgentUninstall,
 }
 
+var agentLogsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Show the agent log",
+	Long: `Print agent.log from the state directory, decrypting it when state
+encryption is on (see 'preflight secure-state').
+
+Examples:
+  preflight agent logs`,
+	Args: cobra.NoArgs,
+	RunE: runAgentLogs,
+}
+
 var agentApproveCmd = &cobra.Command{
 	Use:   "approve <request-id>",
 	Short: "Approve a pending remediation request",
@@ -140,6 +161,7 @@ func init() {
 	agentCmd.AddCommand(agentInstallCmd)
 	agentCmd.AddCommand(agentUninstallCmd)
 	agentCmd.AddCommand(agentApproveCmd)
+	agentCmd.AddCommand(agentLogsCmd)
 
 	// Start command flags
 	agentStartCmd.Flags().BoolVar(&agentForeground, "foreground", false, "Run in foreground (don't daemonize)")
@@ -183,6 +205,11 @@ func runAgentStart(_ *cobra.Command, _ []string) error {
 		return fmt.Errorf("invalid remediation policy: %w", err)
 	}
 
+	snapshotEvery, snapshotKeep, err := agentSnapshotSchedule()
+	if err != nil {
+		return err
+	}
+
 	if agentForeground {
 		// Run in foreground
 		fmt.Printf("Starting agent in foreground mode...\n")
@@ -191,6 +218,12 @@ func runAgentStart(_ *cobra.Command, _ []string) error {
 		fmt.Printf("  Target: %s\n", agentTarget)
 		fmt.Println()
 
+		// The agent reports what it does at info unless told otherwise.
+		log := logging.Default()
+		if !rootCmd.PersistentFlags().Changed("log-level") {
+			log.SetLevel(ports.LevelInfo)
+		}
+
 		ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 		defer cancel()
 
@@ -220,6 +253,32 @@ func runAgentStart(_ *cobra.Command, _ []string) error {
 			return fmt.Errorf("failed to start agent: %w", err)
 		}
 
+		// Reload plugins installed or updated while the agent runs.
+		go preflight.WatchPlugins(ctx, agentPluginPollInterval, func(reloads []app.PluginReload) {
+			for _, r := range reloads {
+				log.Info(ctx, "plugin reloaded", ports.F("plugin", r.String()))
+			}
+		})
+
+		// Switch profiles when network or location triggers change.
+		go watchProfileTriggers(ctx, cfg.ConfigPath, agentProfilePollInterval)
+
+		// Upgrade packages, deferring major updates to the maintenance window.
+		go watchUpgrades(ctx, cfg.ConfigPath, agentUpgradePollInterval)
+
+		// Save packages installed outside preflight for 'preflight patches'.
+		go watchUntrackedPackages(ctx, preflight, cfg.ConfigPath, cfg.Target, agentPatchPollInterval)
+
+		// Snapshot managed files so manual edits can be rolled back.
+		if snapshotEvery > 0 {
+			lifecycle, err := app.DefaultLifecycleManager()
+			if err != nil {
+				return fmt.Errorf("failed to initialize snapshots: %w", err)
+			}
+			fmt.Printf("  Snapshots: every %s, kept %s\n", agentSnapshotEvery, agentSnapshotKeep)
+			go watchSnapshots(ctx, lifecycle, snapshotEvery, snapshotKeep)
+		}
+
 		fmt.Println("Agent is running. Press Ctrl+C to stop.")
 
 		<-ctx.Done()
@@ -245,6 +304,8 @@ func runAgentStart(_ *cobra.Command, _ []string) error {
 		"--remediation", agentRemediation,
 		"--target", agentTarget,
 	}
+	args = append(args, agentSnapshotArgs()...)
+	args = append(args, agentLogArgs()...)
 
 	// #nosec G204 -- arguments are validated flags from this CLI, not user-controlled input.
 	cmd := exec.Command(execPath, args...)
@@ -429,6 +490,9 @@ func runAgentInstall(_ *cobra.Command, _ []string) error {
 	if _, err := agent.ParseRemediationPolicy(agentRemediation); err != nil {
 		return fmt.Errorf("invalid remediation policy: %w", err)
 	}
+	if _, _, err := agentSnapshotSchedule(); err != nil {
+		return err
+	}
 	switch runtime.GOOS {
 	case "darwin":
 		return installLaunchAgent()
@@ -526,7 +590,8 @@ func installLaunchAgent() error {
 	if err != nil {
 		return fmt.Errorf("failed to get home directory: %w", err)
 	}
-
+	// The agent logs to agent.log itself, so the log is encrypted with the
+	// rest of the state; launchd would append to it in plain text.
 	plistPath := home + "/Library/LaunchAgents/com.preflight.agent.plist"
 	plistContent := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
 <!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
@@ -543,18 +608,18 @@ func installLaunchAgent() error {
         <string>--schedule</string>
         <string>%s</string>
         <string>--remediation</string>
-        <string>%s</string>
+        <string>%s</string>%s
     </array>
     <key>RunAtLoad</key>
     <true/>
     <key>KeepAlive</key>
     <true/>
     <key>StandardOutPath</key>
-    <string>%s/.preflight/agent.log</string>
+    <string>/dev/null</string>
     <key>StandardErrorPath</key>
-    <string>%s/.preflight/agent.log</string>
+    <string>/dev/null</string>
 </dict>
-</plist>`, execPath, agentSchedule, agentRemediation, home, home)
+</plist>`, execPath, agentSchedule, agentRemediation, plistArgs(append(agentSnapshotArgs(), agentLogArgs()...)))
 
 	// Ensure LaunchAgents directory exists
 	launchAgentsDir := home + "/Library/LaunchAgents"
@@ -581,6 +646,23 @@ func installLaunchAgent() error {
 	return nil
 }
 
+func runAgentLogs(_ *cobra.Command, _ []string) error {
+	path, err := agentLogPath()
+	if err != nil {
+		return err
+	}
+	data, err := securestate.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		fmt.Println("The agent has not written a log yet.")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read agent log: %w", err)
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
+
 func uninstallLaunchAgent() error {
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -632,13 +714,13 @@ After=network.target
 
 [Service]
 Type=simple
-ExecStart=%s agent start --foreground --schedule %s --remediation %s
+ExecStart=%s agent start --foreground --schedule %s --remediation %s%s
 Restart=on-failure
 RestartSec=10
 
 [Install]
 WantedBy=default.target
-`, execPath, agentSchedule, agentRemediation)
+`, execPath, agentSchedule, agentRemediation, commandLineArgs(append(agentSnapshotArgs(), logFlagArgs()...)))
 
 	// Ensure service directory exists
 	// #nosec G301 -- systemd user services must be readable by systemd.
@@ -730,6 +812,11 @@ func reconcile(ctx context.Context, pf reconcileApp, cfg *agent.Config) (*agent.
 
 	result.CompletedAt = time.Now()
 	result.Duration = result.CompletedAt.Sub(startedAt)
+	logging.Default().Info(ctx, "reconcile finished",
+		ports.F("target", cfg.Target),
+		ports.F("drift", result.DriftCount),
+		ports.F("remediated", result.RemediationCount),
+		ports.F("duration", result.Duration))
 	return result, nil
 }
 
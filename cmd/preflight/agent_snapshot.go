@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/adapters/logging"
+	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/spf13/cobra"
+)
+
+// Scheduled snapshot flags of 'agent start' and 'agent install'.
+var (
+	agentSnapshotEvery string
+	agentSnapshotKeep  string
+)
+
+// defaultAgentSnapshotKeep is how long scheduled snapshots are kept.
+const defaultAgentSnapshotKeep = "7d"
+
+func init() {
+	for _, cmd := range []*cobra.Command{agentStartCmd, agentInstallCmd} {
+		cmd.Flags().StringVar(&agentSnapshotEvery, "snapshot-every", "", "Snapshot managed files at this interval, independent of applies (e.g., 6h, 1d); off when empty")
+		cmd.Flags().StringVar(&agentSnapshotKeep, "snapshot-keep", defaultAgentSnapshotKeep, "How long to keep scheduled snapshots (e.g., 7d, 4w)")
+	}
+}
+
+// agentSnapshotSchedule parses the scheduled snapshot flags. every is
+// zero when scheduled snapshots are off.
+func agentSnapshotSchedule() (every, keep time.Duration, err error) {
+	if agentSnapshotEvery == "" {
+		return 0, 0, nil
+	}
+	if every, err = parseDuration(agentSnapshotEvery); err != nil {
+		return 0, 0, fmt.Errorf("invalid --snapshot-every: %w", err)
+	}
+	if every <= 0 {
+		return 0, 0, fmt.Errorf("invalid --snapshot-every: must be positive")
+	}
+	if keep, err = parseDuration(agentSnapshotKeep); err != nil {
+		return 0, 0, fmt.Errorf("invalid --snapshot-keep: %w", err)
+	}
+	return every, keep, nil
+}
+
+// agentSnapshotArgs returns the scheduled snapshot flags to pass to the
+// agent process started by a daemon or service.
+func agentSnapshotArgs() []string {
+	if agentSnapshotEvery == "" {
+		return nil
+	}
+	return []string{"--snapshot-every", agentSnapshotEvery, "--snapshot-keep", agentSnapshotKeep}
+}
+
+// plistArgs renders args as additional ProgramArguments entries of a
+// LaunchAgent plist.
+func plistArgs(args []string) string {
+	var b strings.Builder
+	for _, arg := range args {
+		b.WriteString("\n        <string>" + arg + "</string>")
+	}
+	return b.String()
+}
+
+// commandLineArgs renders args to append to a systemd ExecStart line.
+func commandLineArgs(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	return " " + strings.Join(args, " ")
+}
+
+// watchSnapshots snapshots the files preflight manages every interval so
+// that manual edits can be rolled back, keeping snapshots for keep.
+func watchSnapshots(ctx context.Context, lifecycle *app.LifecycleManager, every, keep time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		set, err := lifecycle.ScheduledSnapshot(ctx, keep)
+		switch {
+		case err != nil:
+			logging.Default().Warn(ctx, "scheduled snapshot failed", ports.F("error", err))
+		case set != nil:
+			logging.Default().Info(ctx, "scheduled snapshot taken",
+				ports.F("snapshot", set.ID), ports.F("files", len(set.Snapshots)))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
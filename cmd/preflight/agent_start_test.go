@@ -466,3 +466,30 @@ func (d *agentDummyStep) Apply(_ compiler.RunContext) error { return nil }
 func (d *agentDummyStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
 	return compiler.Explanation{}
 }
+
+func TestAgentSnapshotSchedule(t *testing.T) {
+	prevEvery, prevKeep := agentSnapshotEvery, agentSnapshotKeep
+	t.Cleanup(func() { agentSnapshotEvery, agentSnapshotKeep = prevEvery, prevKeep })
+
+	agentSnapshotEvery, agentSnapshotKeep = "", defaultAgentSnapshotKeep
+	every, _, err := agentSnapshotSchedule()
+	require.NoError(t, err)
+	assert.Zero(t, every)
+	assert.Empty(t, agentSnapshotArgs())
+
+	agentSnapshotEvery, agentSnapshotKeep = "6h", "2w"
+	every, keep, err := agentSnapshotSchedule()
+	require.NoError(t, err)
+	assert.Equal(t, 6*time.Hour, every)
+	assert.Equal(t, 14*24*time.Hour, keep)
+	assert.Equal(t, []string{"--snapshot-every", "6h", "--snapshot-keep", "2w"}, agentSnapshotArgs())
+	assert.Equal(t, " --snapshot-every 6h --snapshot-keep 2w", commandLineArgs(agentSnapshotArgs()))
+
+	agentSnapshotEvery = "0h"
+	_, _, err = agentSnapshotSchedule()
+	assert.ErrorContains(t, err, "must be positive")
+
+	agentSnapshotEvery, agentSnapshotKeep = "1h", "soon"
+	_, _, err = agentSnapshotSchedule()
+	assert.ErrorContains(t, err, "--snapshot-keep")
+}
@@ -490,6 +490,7 @@ func TestInstallLaunchAgent_PlistContent(t *testing.T) {
 
 	tmpDir := t.TempDir()
 	t.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_STATE_HOME", "")
 
 	origSchedule := agentSchedule
 	origRemediation := agentRemediation
@@ -515,5 +516,5 @@ func TestInstallLaunchAgent_PlistContent(t *testing.T) {
 	assert.Contains(t, content, "--foreground")
 	assert.Contains(t, content, "15m")
 	assert.Contains(t, content, "auto")
-	assert.Contains(t, content, tmpDir+"/.preflight/agent.log")
+	assert.Contains(t, content, tmpDir+"/.local/state/preflight/agent.log")
 }
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/felixgeelhaar/preflight/internal/domain/advisor"
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/offline"
+	"github.com/spf13/cobra"
+)
+
+var aiCmd = &cobra.Command{
+	Use:   "ai",
+	Short: "Inspect the use of AI features",
+	Long: `Inspect the use of AI features.
+
+Every AI call is recorded in the audit log with its token count and an
+estimated cost. Set a monthly budget in preflight.yaml to stop AI calls
+once it is spent:
+
+  ai:
+    monthly_budget: 5.00  # US dollars
+
+Commands then continue without AI and print a warning.`,
+}
+
+var aiUsageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Show AI token usage and estimated cost",
+	Long: `Show AI token usage and estimated cost per command.
+
+Costs are estimates from list prices; local models are free.
+
+Examples:
+  preflight ai usage              # This month
+  preflight ai usage --days 7     # Last 7 days
+  preflight ai usage --json`,
+	Args: cobra.NoArgs,
+	RunE: runAIUsage,
+}
+
+var (
+	aiUsageDays int
+	aiUsageJSON bool
+)
+
+func init() {
+	aiUsageCmd.Flags().IntVar(&aiUsageDays, "days", 0, "Show the last N days instead of this month")
+	aiUsageCmd.Flags().BoolVar(&aiUsageJSON, "json", false, "Output as JSON")
+
+	aiCmd.AddCommand(aiUsageCmd)
+	rootCmd.AddCommand(aiCmd)
+}
+
+func runAIUsage(_ *cobra.Command, _ []string) error {
+	service, err := getAuditService()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = service.Close() }()
+
+	now := time.Now()
+	since := advisor.MonthStart(now)
+	if aiUsageDays > 0 {
+		since = now.AddDate(0, 0, -aiUsageDays)
+	}
+	usage, err := app.NewAuditUsageStore(service).UsageSince(context.Background(), since)
+	if err != nil {
+		return fmt.Errorf("failed to read AI usage: %w", err)
+	}
+	summary := app.SummarizeAIUsage(usage, since, aiBudget())
+
+	if aiUsageJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(summary)
+	}
+
+	fmt.Printf("AI usage since %s\n\n", since.Format("2006-01-02"))
+	if summary.Calls == 0 {
+		fmt.Println("No AI calls recorded.")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "COMMAND\tCALLS\tTOKENS\tEST. COST")
+	for _, c := range summary.Commands {
+		_, _ = fmt.Fprintf(w, "%s\t%d\t%d\t$%.4f\n", c.Command, c.Calls, c.Tokens, c.CostUSD)
+	}
+	_, _ = fmt.Fprintf(w, "total\t%d\t%d\t$%.4f\n", summary.Calls, summary.Tokens, summary.CostUSD)
+	_ = w.Flush()
+
+	if summary.BudgetUSD > 0 && aiUsageDays == 0 {
+		fmt.Printf("\nMonthly budget: $%.2f spent of $%.2f\n", summary.CostUSD, summary.BudgetUSD)
+	}
+	return nil
+}
+
+// meteredAIProvider returns the configured AI provider with its calls for
+// command recorded in the audit log, or nil when none is configured. It
+// returns an error wrapping advisor.ErrBudgetExceeded when this month's
+// budget is spent, or offline.ErrOffline in offline mode; callers continue
+// without AI.
+func meteredAIProvider(command string) (advisor.AIProvider, error) {
+	if offline.Enabled() {
+		return nil, offline.Skipped("AI")
+	}
+	provider := detectAIProvider()
+	if provider == nil {
+		return nil, nil
+	}
+	metered := advisor.NewMeteredProvider(provider, &lazyAuditUsageStore{}, command, aiBudget())
+	if err := metered.CheckBudget(context.Background()); errors.Is(err, advisor.ErrBudgetExceeded) {
+		return nil, err
+	}
+	return metered, nil
+}
+
+// aiBudget returns the monthly AI budget configured in the manifest, or
+// zero when there is none.
+func aiBudget() float64 {
+	configPath := cfgFile
+	if configPath == "" {
+		configPath = "preflight.yaml"
+	}
+	manifest, err := config.NewLoader().LoadManifest(configPath)
+	if err != nil {
+		return 0
+	}
+	return manifest.AI.MonthlyBudget
+}
+
+// lazyAuditUsageStore opens the audit log on first use, so that finding a
+// provider does not touch it.
+type lazyAuditUsageStore struct {
+	once  sync.Once
+	store *app.AuditUsageStore
+	err   error
+}
+
+func (s *lazyAuditUsageStore) open() (*app.AuditUsageStore, error) {
+	s.once.Do(func() {
+		service, err := getAuditService()
+		if err != nil {
+			s.err = err
+			return
+		}
+		s.store = app.NewAuditUsageStore(service)
+	})
+	return s.store, s.err
+}
+
+func (s *lazyAuditUsageStore) RecordUsage(ctx context.Context, usage advisor.Usage) error {
+	store, err := s.open()
+	if err != nil {
+		return err
+	}
+	return store.RecordUsage(ctx, usage)
+}
+
+func (s *lazyAuditUsageStore) UsageSince(ctx context.Context, since time.Time) ([]advisor.Usage, error) {
+	store, err := s.open()
+	if err != nil {
+		return nil, err
+	}
+	return store.UsageSince(ctx, since)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/felixgeelhaar/preflight/internal/domain/advisor"
+	"github.com/felixgeelhaar/preflight/internal/offline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeteredAIProvider_Budget(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("PREFLIGHT_AI_ENDPOINT", "")
+	t.Setenv("ANTHROPIC_API_KEY", "test-key")
+	oldProvider, oldCfgFile := aiProvider, cfgFile
+	defer func() { aiProvider, cfgFile = oldProvider, oldCfgFile }()
+	aiProvider = ""
+
+	cfgFile = filepath.Join(t.TempDir(), "preflight.yaml")
+	require.NoError(t, os.WriteFile(cfgFile, []byte("ai:\n  monthly_budget: 1\ntargets:\n  default: [base]\n"), 0o644))
+
+	provider, err := meteredAIProvider("analyze")
+	require.NoError(t, err)
+	require.NotNil(t, provider)
+	assert.Equal(t, "anthropic", provider.Name())
+
+	service, err := getAuditService()
+	require.NoError(t, err)
+	require.NoError(t, app.NewAuditUsageStore(service).RecordUsage(context.Background(), advisor.Usage{Command: "ask", Provider: "anthropic", Tokens: 200_000, CostUSD: 1.2}))
+	require.NoError(t, service.Close())
+
+	provider, err = meteredAIProvider("analyze")
+	assert.Nil(t, provider)
+	require.True(t, errors.Is(err, advisor.ErrBudgetExceeded))
+
+	aiUsageDays, aiUsageJSON = 0, false
+	output := captureStdout(t, func() {
+		require.NoError(t, runAIUsage(nil, nil))
+	})
+	assert.Contains(t, output, "ask")
+	assert.Contains(t, output, "$1.2000")
+	assert.Contains(t, output, "Monthly budget: $1.20 spent of $1.00")
+}
+
+func TestMeteredAIProvider_Offline(t *testing.T) {
+	t.Setenv(offline.EnvVar, "1")
+	t.Setenv("ANTHROPIC_API_KEY", "test-key")
+
+	provider, err := meteredAIProvider("ask")
+	assert.Nil(t, provider)
+	require.ErrorIs(t, err, offline.ErrOffline)
+	assert.Equal(t, "AI skipped (offline)", err.Error())
+}
@@ -1,18 +1,23 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"text/tabwriter"
+	"time"
 
+	"github.com/felixgeelhaar/preflight/internal/app"
 	"github.com/felixgeelhaar/preflight/internal/domain/advisor"
 	"github.com/felixgeelhaar/preflight/internal/domain/catalog/tools"
 	"github.com/felixgeelhaar/preflight/internal/domain/config"
 	"github.com/felixgeelhaar/preflight/internal/domain/security"
+	"github.com/felixgeelhaar/preflight/internal/offline"
 	"github.com/felixgeelhaar/preflight/internal/tui"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
@@ -36,12 +41,28 @@ Tool Analysis (--tools):
   - Redundant tools (grype + trivy → keep trivy)
   - Consolidation opportunities ([grype, syft, gitleaks] → trivy)
 
+Misplaced packages can be moved with --apply-recommendations, which shows
+each move as a diff of the layer files and asks before applying it.
+
+Config Health (--config-health):
+  Find dead or broken configuration without AI:
+  - Layers not referenced by any target
+  - Targets and requires referencing missing layers
+  - Files entries pointing at nonexistent sources
+  - shell.env variables overridden by a later layer in every target
+  - Layers using npm, pip, gem, cargo or go whose runtime only another
+    layer installs, without requiring it
+
 Examples:
   preflight analyze                       # Analyze all layers
   preflight analyze layers/dev-go.yaml    # Analyze specific layer
   preflight analyze --recommend           # Get detailed recommendations
+  preflight analyze --apply-recommendations  # Preview and apply package moves
   preflight analyze --tools               # Analyze tools for redundancy
   preflight analyze --tools --ai          # AI-enhanced tool analysis
+  preflight analyze --tools --update-kb   # Refresh the tool knowledge base first
+  preflight analyze --config-health       # Find unused layers and dead references
+  preflight analyze --disk                # Disk usage of packages and caches
   preflight analyze --ai-provider gemini  # Use specific AI provider
   preflight analyze --no-ai               # Basic analysis without AI
   preflight analyze --json                # JSON output for CI`,
@@ -60,6 +81,10 @@ var (
 	analyzeTools     bool
 	analyzeAI        bool
 	analyzeFix       bool
+	analyzeHealth    bool
+	analyzeApplyRecs bool
+	analyzeUpdateKB  bool
+	analyzeDisk      bool
 )
 
 func init() {
@@ -72,15 +97,28 @@ func init() {
 	analyzeCmd.Flags().BoolVar(&analyzeTools, "tools", false, "Analyze tools for redundancy, deprecation, and consolidation")
 	analyzeCmd.Flags().BoolVar(&analyzeAI, "ai", false, "Enable AI-enhanced analysis (for --tools mode)")
 	analyzeCmd.Flags().BoolVar(&analyzeFix, "fix", false, "Generate fix suggestions (for --tools mode)")
+	analyzeCmd.Flags().BoolVar(&analyzeApplyRecs, "apply-recommendations", false, "Preview and apply recommended package moves between layers")
+	analyzeCmd.Flags().BoolVar(&analyzeUpdateKB, "update-kb", false, "Download the latest signed tool knowledge base")
+	analyzeCmd.Flags().BoolVar(&analyzeDisk, "disk", false, "Report disk usage of managed packages and package manager caches")
+	analyzeCmd.Flags().BoolVar(&analyzeHealth, "config-health", false, "Report unused layers, missing layers and sources, overridden env vars and implicit dependencies")
 }
 
 func runAnalyze(_ *cobra.Command, args []string) error {
 	ctx := context.Background()
 
+	if analyzeUpdateKB {
+		return runUpdateKnowledgeBase(ctx)
+	}
 	// If --tools flag is set, run tool analysis instead
 	if analyzeTools {
 		return runToolAnalysis(ctx, args)
 	}
+	if analyzeHealth {
+		return runConfigHealth()
+	}
+	if analyzeDisk {
+		return runDiskAnalysis(ctx)
+	}
 
 	// Collect layers to analyze
 	var layerPaths []string
@@ -119,24 +157,80 @@ func runAnalyze(_ *cobra.Command, args []string) error {
 	// Get AI provider if not disabled
 	var aiProvider advisor.AIProvider
 	if !analyzeNoAI && !noAI {
-		aiProvider = detectAIProvider()
-		if aiProvider == nil && !analyzeJSON {
+		var err error
+		aiProvider, err = meteredAIProvider("analyze")
+		switch {
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "Warning: %v; running basic analysis only.\n", err)
+		case aiProvider == nil && !analyzeJSON:
 			fmt.Println("No AI provider configured. Running basic analysis only.")
-			fmt.Println("Set ANTHROPIC_API_KEY, GEMINI_API_KEY, or OPENAI_API_KEY for AI recommendations.")
+			fmt.Println("Set ANTHROPIC_API_KEY, GEMINI_API_KEY, or OPENAI_API_KEY for AI recommendations, or PREFLIGHT_AI_ENDPOINT to use a local model.")
 			fmt.Println()
 		}
 	}
 
 	// Perform analysis
 	report := analyzeLayersWithAI(ctx, layers, aiProvider)
+	moves := app.LayerMovesFromAnalysis("layers", layers, report.Layers)
 
 	// Output results
 	if analyzeJSON {
-		outputAnalyzeJSON(report, nil)
-	} else {
-		outputAnalyzeText(report, analyzeQuiet, analyzeRecommend)
+		writeAnalyzeJSON(report, moves, nil)
+		return nil
+	}
+	outputAnalyzeText(report, analyzeQuiet, analyzeRecommend)
+
+	if analyzeApplyRecs {
+		return applyLayerMoves(moves)
+	}
+	if len(moves) > 0 {
+		fmt.Printf("\n%d package move(s) can be applied with 'preflight analyze --apply-recommendations'.\n", len(moves))
+	}
+	return nil
+}
+
+// applyLayerMoves shows each move with a diff and applies the ones the
+// user accepts, or all of them with --yes.
+func applyLayerMoves(moves []app.LayerMove) error {
+	fmt.Println()
+	if len(moves) == 0 {
+		fmt.Println("No recommendations to apply.")
+		return nil
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	var selected []app.LayerMove
+	for _, move := range moves {
+		diff, err := app.PreviewLayerMove(move)
+		if err != nil {
+			fmt.Printf("Skipping move of %s: %v\n", move.Package, err)
+			continue
+		}
+		fmt.Printf("%s\n", move.Description())
+		if move.Reason != "" {
+			fmt.Printf("  %s\n", move.Reason)
+		}
+		fmt.Println(diff)
+
+		if !yesFlag {
+			fmt.Print("Apply this change? [y/N] ")
+			response, _ := reader.ReadString('\n')
+			response = strings.TrimSpace(strings.ToLower(response))
+			if response != "y" && response != "yes" {
+				continue
+			}
+		}
+		selected = append(selected, move)
 	}
 
+	if len(selected) == 0 {
+		fmt.Println("No changes applied.")
+		return nil
+	}
+	if err := app.ApplyLayerMoves(selected); err != nil {
+		return fmt.Errorf("failed to apply recommendations: %w", err)
+	}
+	fmt.Printf("✓ Applied %d of %d recommendation(s).\n", len(selected), len(moves))
 	return nil
 }
 
@@ -314,11 +408,18 @@ func analyzeLayersWithAI(ctx context.Context, layers []advisor.LayerInfo, aiProv
 }
 
 func outputAnalyzeJSON(report *advisor.AnalysisReport, err error) {
+	writeAnalyzeJSON(report, nil, err)
+}
+
+// writeAnalyzeJSON outputs the analysis and the layer moves that carry out
+// its misplacement recommendations as JSON.
+func writeAnalyzeJSON(report *advisor.AnalysisReport, moves []app.LayerMove, err error) {
 	output := struct {
 		Layers               []advisor.LayerAnalysisResult `json:"layers,omitempty"`
 		TotalPackages        int                           `json:"total_packages,omitempty"`
 		TotalRecommendations int                           `json:"total_recommendations,omitempty"`
 		CrossLayerIssues     []string                      `json:"cross_layer_issues,omitempty"`
+		Moves                []app.LayerMove               `json:"moves,omitempty"`
 		Error                string                        `json:"error,omitempty"`
 	}{}
 
@@ -329,6 +430,7 @@ func outputAnalyzeJSON(report *advisor.AnalysisReport, err error) {
 		output.TotalPackages = report.TotalPackages
 		output.TotalRecommendations = report.TotalRecommendations
 		output.CrossLayerIssues = report.CrossLayerIssues
+		output.Moves = moves
 	}
 
 	enc := json.NewEncoder(os.Stdout)
@@ -406,16 +508,41 @@ func printLayerSummaryTable(layers []advisor.LayerAnalysisResult) {
 	_ = w.Flush()
 }
 
+// runUpdateKnowledgeBase installs the latest knowledge pack. Without network
+// access the installed or embedded knowledge base stays in use.
+func runUpdateKnowledgeBase(ctx context.Context) error {
+	if offline.Enabled() {
+		fmt.Println("Tool knowledge base update skipped (offline).")
+		return nil
+	}
+	version, err := tools.NewPackUpdater(tools.KnowledgePackURL, tools.DefaultPackDir()).Update(ctx)
+	switch {
+	case errors.Is(err, tools.ErrPackNotNewer):
+		fmt.Printf("Tool knowledge base is up to date (%s).\n", version)
+		return nil
+	case errors.Is(err, tools.ErrNoPackKey):
+		fmt.Println("Tool knowledge base updates are not available in this build; using the built-in knowledge base.")
+		return nil
+	case err != nil:
+		return fmt.Errorf("failed to update tool knowledge base: %w", err)
+	}
+	fmt.Printf("✓ Updated tool knowledge base to %s.\n", version)
+	return nil
+}
+
 // runToolAnalysis runs tool analysis for redundancy, deprecation, and consolidation.
 func runToolAnalysis(ctx context.Context, args []string) error {
 	// Load the knowledge base
-	kb, err := tools.LoadKnowledgeBase()
+	kb, kbInfo, err := tools.LoadInstalledKnowledgeBase(tools.DefaultPackDir())
 	if err != nil {
 		if analyzeJSON {
 			outputToolAnalysisJSON(nil, fmt.Errorf("failed to load knowledge base: %w", err))
 		}
 		return fmt.Errorf("failed to load knowledge base: %w", err)
 	}
+	if !analyzeJSON && tools.KnowledgePackUpdatesAvailable() && kbInfo.Stale(time.Now()) {
+		fmt.Printf("Tool knowledge base %s is over 30 days old; run 'preflight analyze --update-kb' to refresh it.\n\n", kbInfo.Version)
+	}
 
 	// Extract tools to analyze
 	var toolNames []string
@@ -476,12 +603,15 @@ func runToolAnalysis(ctx context.Context, args []string) error {
 
 	// If AI enhancement requested, add AI insights
 	if analyzeAI && !noAI {
-		aiProvider := detectAIProvider()
-		if aiProvider != nil {
+		aiProvider, err := meteredAIProvider("analyze")
+		switch {
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "Warning: %v; running knowledge-base analysis only.\n", err)
+		case aiProvider != nil:
 			result = enhanceWithAI(ctx, result, toolNames, aiProvider)
-		} else if !analyzeJSON {
+		case !analyzeJSON:
 			fmt.Println("No AI provider configured. Running knowledge-base analysis only.")
-			fmt.Println("Set ANTHROPIC_API_KEY, GEMINI_API_KEY, or OPENAI_API_KEY for AI insights.")
+			fmt.Println("Set ANTHROPIC_API_KEY, GEMINI_API_KEY, or OPENAI_API_KEY for AI insights, or PREFLIGHT_AI_ENDPOINT to use a local model.")
 			fmt.Println()
 		}
 	}
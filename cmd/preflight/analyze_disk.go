@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/felixgeelhaar/preflight/internal/app"
+)
+
+// diskLocations finds where brew and npm keep packages and caches.
+func diskLocations(ctx context.Context) app.DiskLocations {
+	return app.FindDiskLocations(func(name string, args ...string) (string, error) {
+		out, err := exec.CommandContext(ctx, name, args...).Output()
+		return string(out), err
+	})
+}
+
+// runDiskAnalysis reports the disk usage of the packages the configuration
+// manages and of the package manager caches.
+func runDiskAnalysis(ctx context.Context) error {
+	report := app.AnalyzeDiskUsage(diskLocations(ctx), declaredPackages(cfgFile))
+
+	if analyzeJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+
+	fmt.Println("Disk Usage")
+	fmt.Println(strings.Repeat("═", 50))
+	if len(report.Entries) == 0 {
+		fmt.Println()
+		fmt.Println("No managed packages or caches found.")
+		return nil
+	}
+
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "KIND\tNAME\tSIZE\tPATH")
+	for _, e := range report.Entries {
+		name := e.Name
+		if e.Reclaimable {
+			name += " *"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", strings.ReplaceAll(e.Kind, "_", " "), name, formatByteSize(e.Bytes), e.Path)
+	}
+	_ = w.Flush()
+
+	fmt.Println()
+	fmt.Printf("Total: %s\n", formatByteSize(report.TotalBytes))
+	if report.ReclaimableBytes > 0 {
+		fmt.Printf("Reclaimable (*): %s — run 'preflight cleanup --caches' to free it\n", formatByteSize(report.ReclaimableBytes))
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/app"
+)
+
+// configHealthSections are the text output sections, in order.
+var configHealthSections = []struct {
+	kind  app.ConfigHealthKind
+	title string
+}{
+	{app.ConfigHealthMissingLayer, "Missing Layers"},
+	{app.ConfigHealthUnusedLayer, "Unused Layers"},
+	{app.ConfigHealthMissingSource, "Missing File Sources"},
+	{app.ConfigHealthOverriddenEnv, "Overridden Environment Variables"},
+	{app.ConfigHealthImplicitDependency, "Implicit Dependencies"},
+}
+
+// runConfigHealth reports dead and broken references in the configuration.
+func runConfigHealth() error {
+	configPath := cfgFile
+	if configPath == "" {
+		configPath = "preflight.yaml"
+	}
+
+	issues, err := app.CheckConfigHealth(configPath)
+	if analyzeJSON {
+		outputConfigHealthJSON(issues, err)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Config Health")
+	fmt.Println(strings.Repeat("═", 50))
+	if len(issues) == 0 {
+		fmt.Println()
+		fmt.Println("✅ No unused layers or dead references found.")
+		return nil
+	}
+
+	for _, section := range configHealthSections {
+		var messages []string
+		for _, issue := range issues {
+			if issue.Kind == section.kind {
+				messages = append(messages, issue.Message)
+			}
+		}
+		if len(messages) == 0 {
+			continue
+		}
+		fmt.Println()
+		fmt.Printf("%s (%d)\n", section.title, len(messages))
+		fmt.Println(strings.Repeat("─", 30))
+		for _, msg := range messages {
+			fmt.Printf("  ! %s\n", msg)
+		}
+	}
+
+	fmt.Println()
+	fmt.Println(strings.Repeat("═", 50))
+	fmt.Printf("Summary: %d issues found\n", len(issues))
+	return nil
+}
+
+// outputConfigHealthJSON outputs config health issues as JSON.
+func outputConfigHealthJSON(issues []app.ConfigHealthIssue, err error) {
+	output := struct {
+		Issues []app.ConfigHealthIssue `json:"issues"`
+		Error  string                  `json:"error,omitempty"`
+	}{Issues: issues}
+	if output.Issues == nil {
+		output.Issues = []app.ConfigHealthIssue{}
+	}
+	if err != nil {
+		output.Error = err.Error()
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if encErr := enc.Encode(output); encErr != nil {
+		fmt.Fprintf(os.Stderr, "Error encoding JSON output: %v\n", encErr)
+	}
+}
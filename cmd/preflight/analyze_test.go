@@ -8,6 +8,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/felixgeelhaar/preflight/internal/app"
 	"github.com/felixgeelhaar/preflight/internal/domain/advisor"
 	"github.com/felixgeelhaar/preflight/internal/domain/security"
 	"github.com/felixgeelhaar/preflight/internal/tui"
@@ -932,3 +933,21 @@ func TestOutputToolAnalysisText_NoFindings(t *testing.T) {
 	assert.Contains(t, output, "No issues found")
 	assert.Contains(t, output, "4 tools analyzed")
 }
+
+func TestRunConfigHealth_JSON(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "preflight.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("targets:\n  default: [base]\n"), 0o644))
+
+	prevCfg, prevJSON := cfgFile, analyzeJSON
+	t.Cleanup(func() { cfgFile, analyzeJSON = prevCfg, prevJSON })
+	cfgFile, analyzeJSON = configPath, true
+
+	output := captureStdout(t, func() { require.NoError(t, runConfigHealth()) })
+	var result struct {
+		Issues []app.ConfigHealthIssue `json:"issues"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(output), &result))
+	require.Len(t, result.Issues, 1)
+	assert.Equal(t, app.ConfigHealthMissingLayer, result.Issues[0].Kind)
+}
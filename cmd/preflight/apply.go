@@ -6,11 +6,15 @@ import (
 	"io"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/felixgeelhaar/preflight/internal/app"
 	"github.com/felixgeelhaar/preflight/internal/domain/config"
 	"github.com/felixgeelhaar/preflight/internal/domain/execution"
+	"github.com/felixgeelhaar/preflight/internal/provider/files"
 	"github.com/felixgeelhaar/preflight/internal/telemetry"
 	"github.com/spf13/cobra"
 )
@@ -25,16 +29,32 @@ This command:
 2. Executes each step in dependency order
 3. Reports results
 
-Use --dry-run to see what would happen without making changes.`,
+Use --dry-run to see what would happen without making changes.
+
+Copied and templated files keep the edits made to them since the last
+apply: config changes are merged in line by line. Lines changed on both
+sides are left marked as conflicts, unless --prefer local or --prefer
+config picks a side.
+
+In a terminal, apply shows a progress display with the running step, the
+elapsed time and an estimate of the time left based on previous applies.
+Use --plain, or redirect the output, for line-based output.
+
+With sync.autocommit enabled in preflight.yaml, a successful apply commits
+the updated lockfile to the config repository and optionally tags it.`,
 	RunE: runApply,
 }
 
 var (
-	applyConfigPath string
-	applyTarget     string
-	applyDryRun     bool
-	applyUpdateLock bool
-	applyRollback   bool
+	applyConfigPath  string
+	applyTarget      string
+	applyDryRun      bool
+	applyUpdateLock  bool
+	applyRollback    bool
+	applyWait        bool
+	applyPlain       bool
+	applySummaryFile string
+	applyPrefer      string
 )
 
 type preflightClient interface {
@@ -43,8 +63,13 @@ type preflightClient interface {
 	Apply(context.Context, *execution.Plan, bool) ([]execution.StepResult, error)
 	PrintResults([]execution.StepResult)
 	UpdateLockFromPlan(context.Context, string, *execution.Plan) error
+	RecordMachineState(context.Context, string, string, *execution.Plan) error
 	WithMode(config.ReproducibilityMode) preflightClient
 	WithRollbackOnFailure(bool) preflightClient
+	WithRunLockWait(bool) preflightClient
+	WithMergePreference(files.MergePreference) preflightClient
+	WithProgress(execution.StepStartObserver, execution.StepObserver) preflightClient
+	LastTranscript() *app.Transcript
 }
 
 type preflightAdapter struct {
@@ -63,6 +88,18 @@ func (p *preflightAdapter) WithRollbackOnFailure(enabled bool) preflightClient {
 	return &preflightAdapter{p.Preflight.WithRollbackOnFailure(enabled)}
 }
 
+func (p *preflightAdapter) WithRunLockWait(wait bool) preflightClient {
+	return &preflightAdapter{p.Preflight.WithRunLockWait(wait)}
+}
+
+func (p *preflightAdapter) WithMergePreference(prefer files.MergePreference) preflightClient {
+	return &preflightAdapter{p.Preflight.WithMergePreference(prefer)}
+}
+
+func (p *preflightAdapter) WithProgress(start execution.StepStartObserver, done execution.StepObserver) preflightClient {
+	return &preflightAdapter{p.Preflight.WithStepStartObserver(start).WithStepObserver(done)}
+}
+
 func init() {
 	rootCmd.AddCommand(applyCmd)
 
@@ -71,6 +108,10 @@ func init() {
 	applyCmd.Flags().BoolVar(&applyDryRun, "dry-run", false, "Show what would be done without making changes")
 	applyCmd.Flags().BoolVar(&applyUpdateLock, "update-lock", false, "Update lockfile after apply")
 	applyCmd.Flags().BoolVar(&applyRollback, "rollback-on-error", true, "Attempt rollback when a step fails (disable with --rollback-on-error=false)")
+	applyCmd.Flags().BoolVar(&applyWait, "wait", false, "Wait for another running apply, doctor --fix or agent reconcile to finish")
+	applyCmd.Flags().BoolVar(&applyPlain, "plain", false, "Print plain line-based output instead of the progress display (for CI)")
+	applyCmd.Flags().StringVar(&applyPrefer, "prefer", "", "Resolve conflicts between local edits and config changes to managed files: local or config")
+	applyCmd.Flags().StringVar(&applySummaryFile, "summary-file", "", "Write a summary to this file (JSON for .json, else markdown, e.g. $GITHUB_STEP_SUMMARY)")
 }
 
 func runApply(cmd *cobra.Command, _ []string) error {
@@ -84,11 +125,20 @@ func runApply(cmd *cobra.Command, _ []string) error {
 	} else if modeOverride != nil {
 		preflight = preflight.WithMode(*modeOverride)
 	}
-	preflight = preflight.WithRollbackOnFailure(applyRollback)
+	prefer, err := files.ParseMergePreference(applyPrefer)
+	if err != nil {
+		return err
+	}
+	preflight = preflight.WithRollbackOnFailure(applyRollback).WithRunLockWait(applyWait).WithMergePreference(prefer)
+
+	applyTarget = resolveMachineTarget(ctx, cmd, applyConfigPath, applyTarget)
+	summary := newRunSummary("apply", applyTarget)
+	defer saveSummary(applySummaryFile, summary)
 
 	// Create the plan
 	plan, err := preflight.Plan(ctx, applyConfigPath, applyTarget)
 	if err != nil {
+		summary.fail(err)
 		return (&config.UserError{
 			Code:       config.ErrCodeValidationFailed,
 			Message:    "could not generate plan from your configuration",
@@ -97,11 +147,16 @@ func runApply(cmd *cobra.Command, _ []string) error {
 		})
 	}
 
+	summary.setPlan(plan)
+
 	// Show the plan first
 	preflight.PrintPlan(plan)
 
 	// If no changes needed, we're done
 	if !plan.HasChanges() {
+		if !applyDryRun {
+			recordMachineState(ctx, preflight, plan)
+		}
 		return nil
 	}
 
@@ -113,8 +168,9 @@ func runApply(cmd *cobra.Command, _ []string) error {
 	if app.RequiresBootstrapConfirmation(plan) {
 		steps := app.BootstrapSteps(plan)
 		if !confirmBootstrap(steps) {
+			summary.fail(fmt.Errorf("bootstrap steps declined"))
 			return &config.UserError{
-				Code:       "BOOTSTRAP_DECLINED",
+				Code:       config.ErrCodeBootstrapDeclined,
 				Message:    "bootstrap steps declined; nothing was applied",
 				Suggestion: "Re-run 'preflight apply' and confirm the bootstrap prompt, or pass --allow-bootstrap to skip the prompt.",
 			}
@@ -123,11 +179,24 @@ func runApply(cmd *cobra.Command, _ []string) error {
 
 	fmt.Println("\nApplying changes...")
 
+	// Commit the lockfile afterwards when sync.autocommit is enabled.
+	autocommit := startAutocommit(ctx, applyConfigPath, strings.TrimSuffix(applyConfigPath, filepath.Ext(applyConfigPath))+".lock")
+
+	// Keep the lockfile this apply is about to update so undo can restore it.
+	var lockBackup *LockfileBackup
+	if applyUpdateLock {
+		lockBackup = backupLockfile(applyConfigPath)
+	}
+
 	// Execute the plan
-	results, err := preflight.Apply(ctx, plan, applyDryRun)
+	started := time.Now()
+	results, err := applyPlan(ctx, preflight, plan)
 	// Print results before deciding what to return so the user always sees
 	// per-step status, even on partial failure.
 	preflight.PrintResults(results)
+	printFileBackups(preflight.LastTranscript())
+	recordApplyHistory(preflight, started, results, lockBackup, err)
+	summary.setResults(results, err)
 
 	// Collect per-step failures regardless of whether Apply itself returned an
 	// error — Execute now joins step errors but legacy callers / fakes may
@@ -147,7 +216,7 @@ func runApply(cmd *cobra.Command, _ []string) error {
 	if applyUpdateLock {
 		if err := preflight.UpdateLockFromPlan(ctx, applyConfigPath, plan); err != nil {
 			return &config.UserError{
-				Code:       "LOCK_UPDATE_FAILED",
+				Code:       config.ErrCodeLockUpdateFailed,
 				Message:    "could not update preflight.lock after apply",
 				Suggestion: "Apply succeeded; only the lockfile update failed. Re-run 'preflight lock --update' once write access to the file is restored.",
 				Underlying: err,
@@ -155,5 +224,35 @@ func runApply(cmd *cobra.Command, _ []string) error {
 		}
 	}
 
+	recordMachineState(ctx, preflight, plan)
+
+	applied := make([]string, 0, len(plan.NeedsApply()))
+	for _, entry := range plan.NeedsApply() {
+		applied = append(applied, entry.Step().ID().String())
+	}
+	finishAutocommit(ctx, autocommit, "apply", applyTarget, applied)
+
 	return nil
 }
+
+// recordApplyHistory saves the transcript of an apply to the history.
+// Failures only warn: they do not change the outcome of the apply.
+func recordApplyHistory(preflight preflightClient, started time.Time, results []execution.StepResult, lockBackup *LockfileBackup, applyErr error) {
+	entry := newApplyHistoryEntry(applyTarget, started, results, preflight.LastTranscript(), applyErr)
+	entry.Lockfile = lockBackup
+	if err := SaveHistoryEntry(entry); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not record history: %v\n", err)
+		return
+	}
+	if err := compactHistoryIfNeeded(applyConfigPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not compact history: %v\n", err)
+	}
+}
+
+// recordMachineState records what this machine applied in the machine
+// inventory. Failures only warn: the apply itself succeeded.
+func recordMachineState(ctx context.Context, preflight preflightClient, plan *execution.Plan) {
+	if err := preflight.RecordMachineState(ctx, applyConfigPath, applyTarget, plan); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not record machine state: %v\n", err)
+	}
+}
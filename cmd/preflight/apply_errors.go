@@ -29,7 +29,7 @@ func newApplyFailedUserError(action string, failedIDs []string, underlying error
 	}
 
 	return &config.UserError{
-		Code:       "APPLY_FAILED",
+		Code:       config.ErrCodeApplyFailed,
 		Message:    msg,
 		Suggestion: suggestion,
 		Underlying: underlying,
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/execution"
+	"github.com/felixgeelhaar/preflight/internal/tui"
+)
+
+// estimateHistoryEntries is how many recent applies step durations are
+// estimated from.
+const estimateHistoryEntries = 10
+
+// applyPlan applies plan, showing the progress display when stdout is a
+// terminal and plain output was not asked for.
+func applyPlan(ctx context.Context, preflight preflightClient, plan *execution.Plan) ([]execution.StepResult, error) {
+	if applyPlain || !stdoutIsTerminal() {
+		return preflight.Apply(ctx, plan, false)
+	}
+
+	opts := tui.NewApplyProgressOptions().WithEstimates(stepDurationEstimates(estimateHistoryEntries))
+	result, err := tui.RunApplyProgress(ctx, plan, opts,
+		func(ctx context.Context, start execution.StepStartObserver, done execution.StepObserver) ([]execution.StepResult, error) {
+			return preflight.WithProgress(start, done).Apply(ctx, plan, false)
+		})
+	if err != nil {
+		return nil, err
+	}
+	return result.Results, result.Err
+}
+
+// stepDurationEstimates returns the average duration of each step over the
+// last n applies in the history. Steps that were not applied, or failed,
+// are left out.
+func stepDurationEstimates(n int) map[string]time.Duration {
+	entries, err := loadHistory()
+	if err != nil {
+		return nil
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.After(entries[j].Timestamp)
+	})
+
+	sums := make(map[string]time.Duration)
+	counts := make(map[string]int)
+	seen := 0
+	for _, e := range entries {
+		if seen == n {
+			break
+		}
+		if e.Command != "apply" {
+			continue
+		}
+		seen++
+		full, err := loadHistoryEntry(e.ID)
+		if err != nil {
+			continue
+		}
+		for _, step := range full.Steps {
+			if !step.Applied || step.Error != "" {
+				continue
+			}
+			d, err := time.ParseDuration(step.Duration)
+			if err != nil {
+				continue
+			}
+			sums[step.ID] += d
+			counts[step.ID]++
+		}
+	}
+
+	estimates := make(map[string]time.Duration, len(sums))
+	for id, sum := range sums {
+		estimates[id] = sum / time.Duration(counts[id])
+	}
+	return estimates
+}
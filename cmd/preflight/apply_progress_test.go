@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStepDurationEstimates(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	now := time.Now()
+
+	require.NoError(t, SaveHistoryEntry(HistoryEntry{ID: "1", Timestamp: now.Add(-3 * time.Hour), Command: "apply", Steps: []StepTranscript{
+		{ID: "brew:formula:git", Applied: true, Duration: "30s"},
+	}}))
+	require.NoError(t, SaveHistoryEntry(HistoryEntry{ID: "2", Timestamp: now.Add(-2 * time.Hour), Command: "apply", Steps: []StepTranscript{
+		{ID: "brew:formula:git", Applied: true, Duration: "10s"},
+		{ID: "brew:formula:fd", Applied: true, Duration: "1m", Error: "no bottle"},
+		{ID: "git:config", Status: "satisfied", Duration: "1ms"},
+	}}))
+	require.NoError(t, SaveHistoryEntry(HistoryEntry{ID: "3", Timestamp: now.Add(-time.Hour), Command: "undo"}))
+	require.NoError(t, SaveHistoryEntry(HistoryEntry{ID: "4", Timestamp: now, Command: "apply", Steps: []StepTranscript{
+		{ID: "brew:formula:git", Applied: true, Duration: "20s"},
+	}}))
+
+	assert.Equal(t, map[string]time.Duration{"brew:formula:git": 20 * time.Second}, stepDurationEstimates(10))
+	assert.Equal(t, map[string]time.Duration{"brew:formula:git": 15 * time.Second}, stepDurationEstimates(2))
+}
@@ -5,9 +5,11 @@ import (
 	"io"
 	"testing"
 
+	"github.com/felixgeelhaar/preflight/internal/app"
 	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
 	"github.com/felixgeelhaar/preflight/internal/domain/config"
 	"github.com/felixgeelhaar/preflight/internal/domain/execution"
+	"github.com/felixgeelhaar/preflight/internal/provider/files"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -86,9 +88,11 @@ func TestRunApply_NoChangesSkipsApply(t *testing.T) {
 	assert.True(t, fake.printPlanCalled)
 	assert.False(t, fake.applyCalled)
 	assert.False(t, fake.updateLockCalled)
+	assert.True(t, fake.recordMachineCalled, "an up-to-date machine still records its state")
 }
 
 func TestRunApply_AppliesAndUpdatesLock(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // apply records history
 
 	plan := execution.NewExecutionPlan()
 	step := newDummyStep("files:link:bashrc")
@@ -110,6 +114,7 @@ func TestRunApply_AppliesAndUpdatesLock(t *testing.T) {
 	assert.True(t, fake.applyCalled)
 	assert.True(t, fake.printResultsCalled)
 	assert.True(t, fake.updateLockCalled)
+	assert.True(t, fake.recordMachineCalled)
 }
 
 func overrideNewPreflight(client *fakePreflightClient) func() {
@@ -134,14 +139,15 @@ func setApplyFlags(t *testing.T, dryRun, updateLock bool) func() {
 }
 
 type fakePreflightClient struct {
-	planResult         *execution.Plan
-	planErr            error
-	results            []execution.StepResult
-	applyErr           error
-	printPlanCalled    bool
-	printResultsCalled bool
-	applyCalled        bool
-	updateLockCalled   bool
+	planResult          *execution.Plan
+	planErr             error
+	results             []execution.StepResult
+	applyErr            error
+	printPlanCalled     bool
+	printResultsCalled  bool
+	applyCalled         bool
+	updateLockCalled    bool
+	recordMachineCalled bool
 }
 
 func newFakePreflightClient(plan *execution.Plan, results []execution.StepResult) *fakePreflightClient {
@@ -178,6 +184,11 @@ func (f *fakePreflightClient) UpdateLockFromPlan(_ context.Context, _ string, _
 	return nil
 }
 
+func (f *fakePreflightClient) RecordMachineState(_ context.Context, _ string, _ string, _ *execution.Plan) error {
+	f.recordMachineCalled = true
+	return nil
+}
+
 func (f *fakePreflightClient) WithMode(config.ReproducibilityMode) preflightClient {
 	return f
 }
@@ -186,6 +197,22 @@ func (f *fakePreflightClient) WithRollbackOnFailure(bool) preflightClient {
 	return f
 }
 
+func (f *fakePreflightClient) WithRunLockWait(_ bool) preflightClient {
+	return f
+}
+
+func (f *fakePreflightClient) WithMergePreference(_ files.MergePreference) preflightClient {
+	return f
+}
+
+func (f *fakePreflightClient) WithProgress(execution.StepStartObserver, execution.StepObserver) preflightClient {
+	return f
+}
+
+func (f *fakePreflightClient) LastTranscript() *app.Transcript {
+	return nil
+}
+
 type dummyStep struct {
 	id compiler.StepID
 }
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/spf13/cobra"
+)
+
+var askCmd = &cobra.Command{
+	Use:   "ask <request>",
+	Short: "Edit layers by describing the change",
+	Long: `Ask turns a plain-language request into edits of the package lists in
+your layers, using the configured AI provider.
+
+The proposed change is shown as a diff and written only after you confirm
+(or with --yes). Ask only edits configuration; run 'preflight plan' and
+'preflight apply' to change the system.
+
+Only layer names, targets and package lists are sent to the provider.
+
+Examples:
+  preflight ask "add rust toolchain and lazygit to my dev layer"
+  preflight ask "remove wget from base" --yes`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runAsk,
+}
+
+func init() {
+	rootCmd.AddCommand(askCmd)
+}
+
+func runAsk(cmd *cobra.Command, args []string) error {
+	if noAI {
+		return fmt.Errorf("ask needs an AI provider, but AI is disabled by --no-ai")
+	}
+	provider, err := meteredAIProvider("ask")
+	if err != nil {
+		return err
+	}
+	if provider == nil {
+		return fmt.Errorf("no AI provider configured; set ANTHROPIC_API_KEY, GEMINI_API_KEY, or OPENAI_API_KEY, or PREFLIGHT_AI_ENDPOINT to use a local model")
+	}
+
+	configPath := cfgFile
+	if configPath == "" {
+		configPath = "preflight.yaml"
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	plan, err := app.ProposeConfigEdit(ctx, provider, configPath, strings.Join(args, " "))
+	if err != nil {
+		return err
+	}
+
+	if plan.Summary != "" {
+		fmt.Println(plan.Summary)
+	}
+	for _, skipped := range plan.Skipped {
+		fmt.Printf("  Skipped: %s\n", skipped)
+	}
+	if len(plan.Patches) == 0 {
+		fmt.Println("No changes to make.")
+		return nil
+	}
+
+	diff, err := app.PreviewConfigPatches(plan.Patches)
+	if err != nil {
+		return fmt.Errorf("failed to preview changes: %w", err)
+	}
+	fmt.Println()
+	fmt.Println(diff)
+
+	if !yesFlag {
+		fmt.Print("Apply these changes? [y/N] ")
+		response, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		response = strings.TrimSpace(strings.ToLower(response))
+		if response != "y" && response != "yes" {
+			fmt.Println("No changes applied.")
+			return nil
+		}
+	}
+
+	if err := app.ApplyConfigPatches(plan.Patches); err != nil {
+		return fmt.Errorf("failed to apply changes: %w", err)
+	}
+	fmt.Printf("✓ Updated configuration (%d change(s)). Run 'preflight plan' to review.\n", len(plan.Patches))
+	return nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/felixgeelhaar/preflight/internal/app"
+)
+
+// startAutocommit prepares to commit files after a command changes them,
+// when sync.autocommit is enabled. Failures only warn and disable it.
+func startAutocommit(ctx context.Context, configPath string, files ...string) *app.ConfigAutocommit {
+	autocommit, err := app.StartAutocommit(ctx, configPath, files...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: autocommit disabled: %v\n", err)
+		return nil
+	}
+	return autocommit
+}
+
+// finishAutocommit commits what a command changed and reports the commit.
+// Failures only warn: the command itself succeeded.
+func finishAutocommit(ctx context.Context, autocommit *app.ConfigAutocommit, command, target string, changes []string) {
+	result, err := autocommit.Commit(ctx, command, target, changes)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not commit config changes: %v\n", err)
+	}
+	if result == nil {
+		return
+	}
+	if result.Tag != "" {
+		fmt.Printf("✓ Committed config changes as %s (tag %s)\n", result.Commit, result.Tag)
+		return
+	}
+	fmt.Printf("✓ Committed config changes as %s\n", result.Commit)
+}
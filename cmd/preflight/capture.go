@@ -3,13 +3,17 @@ package main
 import (
 	"context"
 	"fmt"
+	"maps"
 	"os"
 	"os/signal"
+	"slices"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/felixgeelhaar/preflight/internal/adapters/filesystem"
 	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/felixgeelhaar/preflight/internal/domain/advisor"
 	"github.com/felixgeelhaar/preflight/internal/telemetry"
 	"github.com/felixgeelhaar/preflight/internal/tui"
 	"github.com/spf13/cobra"
@@ -28,7 +32,9 @@ Examples:
   preflight capture --all                     # Accept all discovered items
   preflight capture --yes                     # Accept all (alias for --all)
   preflight capture --provider brew           # Only capture Homebrew packages
+  preflight capture --shell                   # Only capture shell frameworks, plugins, aliases, functions
   preflight capture --all --smart-split       # Organize into logical layers (category-based)
+  preflight capture --all --smart-split --ai  # Let the AI provider place unknown packages
   preflight capture --all --split-by language # Organize by programming language
   preflight capture --all --split-by stack    # Organize by tech stack (frontend, backend, devops)
   preflight capture --all --split-by provider # Organize by provider (brew, git, vscode)
@@ -41,33 +47,46 @@ Split strategies:
   stack              - By tech stack role (frontend, backend, devops, data, security)
   provider           - By provider name (brew, git, shell, vscode)
 
-The --smart-split flag is equivalent to --split-by category.`,
+The --smart-split flag is equivalent to --split-by category.
+
+With --ai, packages no built-in rule recognizes are sent with their
+descriptions to the configured AI provider in a single request instead of
+landing in misc.yaml. Without a provider, or when the request fails, they
+stay in misc.yaml.`,
 	RunE: runCapture,
 }
 
 var (
 	captureAll            bool
 	captureProvider       string
+	captureShell          bool
 	captureOutput         string
 	captureTarget         string
 	captureSmartSplit     bool
 	captureSplitBy        string
 	captureIncludeConfigs bool
+	captureAI             bool
 )
 
 func init() {
 	captureCmd.Flags().BoolVar(&captureAll, "all", false, "Accept all discovered items")
 	captureCmd.Flags().StringVar(&captureProvider, "provider", "", "Only capture specific provider")
+	captureCmd.Flags().BoolVar(&captureShell, "shell", false, "Only capture shell setup (equivalent to --provider shell)")
 	captureCmd.Flags().StringVarP(&captureOutput, "output", "o", ".", "Output directory for generated config")
 	captureCmd.Flags().StringVarP(&captureTarget, "target", "t", "default", "Target name for the configuration")
 	captureCmd.Flags().BoolVar(&captureSmartSplit, "smart-split", false, "Automatically organize packages into logical layer files (equivalent to --split-by category)")
 	captureCmd.Flags().StringVar(&captureSplitBy, "split-by", "", fmt.Sprintf("Split strategy for layer organization (%s)", strings.Join(app.ValidSplitStrategies(), ", ")))
+	captureCmd.Flags().BoolVar(&captureAI, "ai", false, "Categorize packages the split rules do not recognize with the AI provider")
 	captureCmd.Flags().BoolVar(&captureIncludeConfigs, "include-configs", false, "Copy config files to dotfiles/ directory for full reproducibility")
 
 	rootCmd.AddCommand(captureCmd)
 }
 
 func runCapture(_ *cobra.Command, _ []string) error {
+	if captureAI && !captureSmartSplit && captureSplitBy == "" {
+		return fmt.Errorf("--ai requires --smart-split or --split-by")
+	}
+
 	// Respect both --all flag and global --yes flag
 	acceptAll := captureAll || yesFlag
 
@@ -84,7 +103,10 @@ func runCapture(_ *cobra.Command, _ []string) error {
 	// Create app instance and capture items
 	preflight := app.New(os.Stdout)
 	captureOpts := app.NewCaptureOptions()
-	if captureProvider != "" {
+	switch {
+	case captureShell:
+		captureOpts = captureOpts.WithProviders("shell")
+	case captureProvider != "":
 		captureOpts = captureOpts.WithProviders(captureProvider)
 	}
 
@@ -180,6 +202,13 @@ func runCapture(_ *cobra.Command, _ []string) error {
 			usingSplit = true
 		}
 
+		if captureAI {
+			if provider := captureAIProvider(); provider != nil {
+				generator.WithAICategorizer(app.NewProviderCategorizer(provider)).
+					WithDescriber(app.BrewDescriptions)
+			}
+		}
+
 		if err := generator.GenerateFromCapture(filteredFindings, captureTarget); err != nil {
 			return fmt.Errorf("failed to generate config: %w", err)
 		}
@@ -196,6 +225,10 @@ func runCapture(_ *cobra.Command, _ []string) error {
 		}
 
 		fmt.Println("Run 'preflight plan' to review the changes.")
+
+		if err := SaveHistoryEntry(newCaptureHistoryEntry(captureTarget, findings.CapturedAt, filteredItems)); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not record history: %v\n", err)
+		}
 	}
 
 	// Capture completed successfully — record activation event for the
@@ -206,6 +239,55 @@ func runCapture(_ *cobra.Command, _ []string) error {
 	return nil
 }
 
+// newCaptureHistoryEntry records a capture with one change per provider
+// captured from, so 'preflight providers' can report when each was last
+// captured.
+func newCaptureHistoryEntry(target string, capturedAt time.Time, items []app.CapturedItem) HistoryEntry {
+	counts := make(map[string]int)
+	for _, item := range items {
+		provider := item.Provider
+		if provider == "brew-cask" {
+			provider = "brew"
+		}
+		counts[provider]++
+	}
+
+	entry := HistoryEntry{
+		Timestamp: capturedAt,
+		Command:   "capture",
+		Target:    target,
+		Status:    "success",
+	}
+	for _, provider := range slices.Sorted(maps.Keys(counts)) {
+		entry.Changes = append(entry.Changes, Change{
+			Provider: provider,
+			Action:   "capture",
+			Item:     fmt.Sprintf("%d captured", counts[provider]),
+		})
+	}
+	return entry
+}
+
+// captureAIProvider returns the provider for --ai, or nil with a notice
+// when AI is disabled or none is configured.
+func captureAIProvider() advisor.AIProvider {
+	if noAI {
+		fmt.Println("AI disabled by --no-ai; unrecognized packages go to misc.yaml.")
+		return nil
+	}
+	provider, err := meteredAIProvider("capture")
+	if err != nil {
+		fmt.Printf("Warning: %v; unrecognized packages go to misc.yaml.\n", err)
+		return nil
+	}
+	if provider == nil || !provider.Available() {
+		fmt.Println("No AI provider available; unrecognized packages go to misc.yaml.")
+		fmt.Println("Set ANTHROPIC_API_KEY, GEMINI_API_KEY, or OPENAI_API_KEY, or PREFLIGHT_AI_ENDPOINT to use a local model.")
+		return nil
+	}
+	return provider
+}
+
 // captureDotfiles copies config files to the dotfiles/ directory.
 // Returns the capture result so it can be passed to the config generator.
 func captureDotfiles() (*app.DotfilesCaptureResult, error) {
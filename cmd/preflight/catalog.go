@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"text/tabwriter"
@@ -10,6 +11,7 @@ import (
 	"github.com/felixgeelhaar/preflight/internal/domain/audit"
 	"github.com/felixgeelhaar/preflight/internal/domain/catalog"
 	"github.com/felixgeelhaar/preflight/internal/domain/catalog/embedded"
+	"github.com/felixgeelhaar/preflight/internal/offline"
 	"github.com/spf13/cobra"
 )
 
@@ -81,12 +83,18 @@ var catalogVerifyCmd = &cobra.Command{
 	Long: `Verify the integrity and optional signatures of registered catalogs.
 
 By default, verifies SHA256 content hashes. Use --signatures to also
-verify Sigstore keyless signatures when available.
+verify the Sigstore keyless signature of each catalog's manifest,
+published next to it as catalog-manifest.yaml.sigstore.json (for example
+with 'cosign sign-blob --bundle').
 
 Sigstore verification checks:
-  - Certificate validity and chain
-  - OIDC identity (GitHub Actions, GitLab CI, etc.)
-  - Content hash matches signed digest
+  - Certificate chain against the trusted Fulcio CAs
+  - Transparency log entry against the trusted Rekor logs
+  - OIDC identity against the identities in the trust store
+  - Manifest matches the signed digest and the registered manifest
+
+Trust identities with 'preflight trust add --issuer ... --identity ...'
+and import the Sigstore trusted root with 'preflight trust sigstore-root'.
 
 Examples:
   preflight catalog verify                       # Verify hashes
@@ -135,6 +143,7 @@ func init() {
 	// Verify flags
 	catalogVerifyCmd.Flags().BoolVar(&catalogVerifySigs, "signatures", false, "Verify Sigstore signatures")
 	catalogVerifyCmd.Flags().BoolVar(&catalogAllowExpired, "allow-expired", false, "Allow expired certificates")
+	_ = catalogVerifyCmd.Flags().MarkDeprecated("allow-expired", "keyless certificates are checked at the time the transparency log recorded the signature")
 	catalogVerifyCmd.Flags().BoolVar(&catalogVerbose, "verbose", false, "Show detailed verification info")
 
 	// Add subcommands
@@ -205,7 +214,13 @@ func getRegistry() (*catalog.Registry, error) {
 			continue
 		}
 
-		rc, err := loader.Load(ctx, src)
+		// Offline, remote catalogs come from the cache of their last load
+		var rc *catalog.RegisteredCatalog
+		if src.IsURL() && offline.Enabled() {
+			rc, err = loader.LoadFromCache(src)
+		} else {
+			rc, err = loader.Load(ctx, src)
+		}
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: failed to load catalog '%s': %v\n", stored.Name, err)
 			continue
@@ -242,6 +257,9 @@ func runCatalogAdd(_ *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid source: %w", err)
 	}
 
+	if source.IsURL() && offline.Enabled() {
+		return offline.Skipped("adding catalog from " + location)
+	}
 	fmt.Printf("Adding catalog '%s' from %s...\n", name, location)
 
 	// Load catalog
@@ -398,8 +416,14 @@ func runCatalogRemove(_ *cobra.Command, args []string) error {
 
 func runCatalogVerify(_ *cobra.Command, args []string) error {
 	ctx := context.Background()
+
+	// Setup Sigstore verifier if signatures flag is set
+	var sigstoreVerifier *catalog.SigstoreVerifier
 	if catalogVerifySigs {
-		return fmt.Errorf("--signatures is not supported yet; omit the flag to verify hashes only")
+		var err error
+		if sigstoreVerifier, err = getKeylessVerifier(); err != nil {
+			return err
+		}
 	}
 
 	// Setup audit service for logging verification results
@@ -436,18 +460,16 @@ func runCatalogVerify(_ *cobra.Command, args []string) error {
 
 	loader := catalog.NewExternalLoader(catalog.DefaultExternalLoaderConfig())
 
-	// Setup Sigstore verifier if signatures flag is set
-	var sigstoreVerifier *catalog.SigstoreVerifier
-	if catalogVerifySigs {
-		config := catalog.DefaultSigstoreVerifierConfig()
-		config.AllowExpired = catalogAllowExpired
-		sigstoreVerifier = catalog.NewSigstoreVerifier(config)
-	}
-
 	var failed int
+	var skipped int
 	var signaturesVerified int
 
 	for _, rc := range toVerify {
+		if rc.Source().IsURL() && offline.Enabled() {
+			fmt.Printf("Verifying %s skipped (offline).\n", rc.Name())
+			skipped++
+			continue
+		}
 		fmt.Printf("Verifying %s...\n", rc.Name())
 
 		// Basic integrity verification
@@ -472,7 +494,7 @@ func runCatalogVerify(_ *cobra.Command, args []string) error {
 			if catalogVerbose {
 				fmt.Printf("  Checking Sigstore signatures... ")
 			}
-			sigResult := verifyCatalogSignatures(rc, sigstoreVerifier)
+			sigResult := verifyCatalogSignatures(ctx, loader, rc, sigstoreVerifier)
 			if sigResult.hasSignature {
 				if sigResult.verified {
 					if catalogVerbose {
@@ -480,6 +502,7 @@ func runCatalogVerify(_ *cobra.Command, args []string) error {
 						fmt.Printf("    Signer: %s\n", sigResult.signer)
 						fmt.Printf("    Issuer: %s\n", sigResult.issuer)
 					}
+					_ = auditSvc.LogSignatureVerified(ctx, rc.Name(), sigResult.signer)
 					signaturesVerified++
 				} else {
 					if catalogVerbose {
@@ -508,9 +531,12 @@ func runCatalogVerify(_ *cobra.Command, args []string) error {
 
 	// Summary
 	if catalogVerifySigs {
-		fmt.Printf("Verified: %d catalogs (%d with signatures)\n", len(toVerify)-failed, signaturesVerified)
+		fmt.Printf("Verified: %d catalogs (%d with signatures)\n", len(toVerify)-failed-skipped, signaturesVerified)
 	} else {
-		fmt.Printf("Verified: %d of %d catalogs\n", len(toVerify)-failed, len(toVerify))
+		fmt.Printf("Verified: %d of %d catalogs\n", len(toVerify)-failed-skipped, len(toVerify))
+	}
+	if skipped > 0 {
+		fmt.Printf("Skipped: %d remote catalogs (offline)\n", skipped)
 	}
 
 	if failed > 0 {
@@ -529,18 +555,21 @@ type signatureVerifyResult struct {
 	err          error
 }
 
-// verifyCatalogSignatures verifies Sigstore signatures for a catalog.
-// Note: Signature verification requires catalogs to include signature files.
-// This is a placeholder that checks for signature metadata in the manifest.
-func verifyCatalogSignatures(_ *catalog.RegisteredCatalog, _ *catalog.SigstoreVerifier) signatureVerifyResult {
-	// Signature verification requires manifest to include signature data.
-	// For catalogs with signature support, the manifest would include:
-	// - A .sig file reference with Sigstore signature data
-	// - OIDC identity information for verification
-	//
-	// Currently returns no signature until catalog authors add signing.
+// verifyCatalogSignatures verifies the Sigstore keyless signature of a
+// catalog's manifest. Catalogs without a signature report no signature.
+func verifyCatalogSignatures(ctx context.Context, loader *catalog.ExternalLoader, rc *catalog.RegisteredCatalog, verifier *catalog.SigstoreVerifier) signatureVerifyResult {
+	signer, err := loader.VerifySignature(ctx, rc, verifier)
+	if errors.Is(err, catalog.ErrNoSignature) {
+		return signatureVerifyResult{}
+	}
+	if err != nil {
+		return signatureVerifyResult{hasSignature: true, err: err}
+	}
 	return signatureVerifyResult{
-		hasSignature: false,
+		hasSignature: true,
+		verified:     true,
+		signer:       fmt.Sprintf("%s (%s)", signer.Subject, signer.Name),
+		issuer:       signer.Issuer,
 	}
 }
 
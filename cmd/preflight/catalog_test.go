@@ -1,10 +1,16 @@
 package main
 
 import (
+	"context"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/felixgeelhaar/preflight/internal/domain/catalog"
+	"github.com/felixgeelhaar/preflight/internal/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDeriveCatalogName(t *testing.T) {
@@ -92,16 +98,53 @@ func TestFilterBySeverity_Empty(t *testing.T) {
 	assert.Empty(t, result)
 }
 
-func TestVerifyCatalogSignatures_NoSignature(t *testing.T) {
+func TestVerifyCatalogSignatures(t *testing.T) {
 	t.Parallel()
 
-	// verifyCatalogSignatures currently returns no signature for all catalogs
-	// as the feature is a placeholder until catalog authors add signing
-	result := verifyCatalogSignatures(nil, nil)
+	const (
+		issuer  = "https://token.actions.githubusercontent.com"
+		subject = "https://github.com/acme/catalog/.github/workflows/release.yml@refs/heads/main"
+	)
+	dir := t.TempDir()
+	catalogData := []byte("presets: []\n")
+	manifestData := []byte("version: \"1.0\"\nname: acme\nintegrity:\n  algorithm: sha256\n  files:\n    catalog.yaml: " +
+		catalog.ComputeSHA256(catalogData) + "\n")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "catalog.yaml"), catalogData, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "catalog-manifest.yaml"), manifestData, 0o644))
+
+	ctx := context.Background()
+	loader := catalog.NewExternalLoader(catalog.ExternalLoaderConfig{Timeout: time.Second, CacheDir: t.TempDir()})
+	source, err := catalog.NewLocalSource("acme", dir)
+	require.NoError(t, err)
+	rc, err := loader.Load(ctx, source)
+	require.NoError(t, err)
+
+	fixture := testutil.NewSigstoreFixture(t)
+	root, err := catalog.ParseSigstoreTrustedRoot(fixture.TrustedRoot)
+	require.NoError(t, err)
+	identity := catalog.ExactSigstoreIdentity(issuer, subject)
+	identity.Name = "acme-release"
+	verifier := catalog.NewSigstoreVerifier(catalog.SigstoreVerifierConfig{
+		TrustedRoot:       root,
+		TrustedIdentities: []catalog.SigstoreIdentity{identity},
+	})
+
+	result := verifyCatalogSignatures(ctx, loader, rc, verifier)
 	assert.False(t, result.hasSignature)
-	assert.Empty(t, result.signer)
-	assert.Empty(t, result.issuer)
 	assert.NoError(t, result.err)
+
+	bundlePath := filepath.Join(dir, "catalog-manifest.yaml"+catalog.SigstoreBundleSuffix)
+	require.NoError(t, os.WriteFile(bundlePath, fixture.Sign(t, manifestData, issuer, subject), 0o644))
+	result = verifyCatalogSignatures(ctx, loader, rc, verifier)
+	assert.True(t, result.verified)
+	assert.Equal(t, subject+" (acme-release)", result.signer)
+	assert.Equal(t, issuer, result.issuer)
+
+	require.NoError(t, os.WriteFile(bundlePath, fixture.Sign(t, manifestData, issuer, "https://github.com/evil/x"), 0o644))
+	result = verifyCatalogSignatures(ctx, loader, rc, verifier)
+	assert.True(t, result.hasSignature)
+	assert.False(t, result.verified)
+	assert.ErrorIs(t, result.err, catalog.ErrSigstoreIdentityMismatch)
 }
 
 func TestSignatureVerifyResult_Fields(t *testing.T) {
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/spf13/cobra"
+)
+
+var ciCmd = &cobra.Command{
+	Use:   "ci",
+	Short: "Check a configuration repository in CI",
+	Long: `Check a configuration repository the way a pull request check should.
+
+Runs without touching the machine it runs on:
+  - validates every target against the schemas and policies
+  - reports packages that several layers of a target declare
+  - checks that preflight.lock matches the declared packages
+
+Findings are printed as text. Under GitHub Actions (or with --annotations)
+they are also emitted as workflow annotations, which show on the changed
+lines of the pull request. --sarif writes a SARIF 2.1.0 report for code
+scanning.
+
+Exit codes:
+  0 - No errors (warnings are allowed unless --strict)
+  1 - Errors found (or warnings with --strict)
+  2 - Policy violations found
+  3 - Could not run the checks
+
+Examples:
+  preflight ci
+  preflight ci --target work --org-policy org-policy.yaml
+  preflight ci --sarif preflight.sarif
+  preflight ci --json`,
+	Args: cobra.NoArgs,
+	RunE: runCI,
+}
+
+var (
+	ciConfigPath    string
+	ciTargets       []string
+	ciPolicyFile    string
+	ciOrgPolicyFile string
+	ciStrict        bool
+	ciJSON          bool
+	ciAnnotations   bool
+	ciSARIFPath     string
+)
+
+func init() {
+	ciCmd.Flags().StringVarP(&ciConfigPath, "config", "c", "preflight.yaml", "Path to preflight.yaml")
+	ciCmd.Flags().StringSliceVarP(&ciTargets, "target", "t", nil, "Targets to check (default: all)")
+	ciCmd.Flags().StringVar(&ciPolicyFile, "policy", "", "Path to policy YAML file (allow/deny rules)")
+	ciCmd.Flags().StringVar(&ciOrgPolicyFile, "org-policy", "", "Path to org policy YAML file (required/forbidden)")
+	ciCmd.Flags().BoolVar(&ciStrict, "strict", false, "Treat warnings as errors")
+	ciCmd.Flags().BoolVar(&ciJSON, "json", false, "Output findings as JSON")
+	ciCmd.Flags().BoolVar(&ciAnnotations, "annotations", os.Getenv("GITHUB_ACTIONS") == "true", "Emit GitHub Actions annotations (default: on under GitHub Actions)")
+	ciCmd.Flags().StringVar(&ciSARIFPath, "sarif", "", "Write a SARIF report to this file")
+
+	rootCmd.AddCommand(ciCmd)
+}
+
+func runCI(_ *cobra.Command, _ []string) error {
+	report, err := app.New(io.Discard).CI(context.Background(), ciConfigPath, app.CIOptions{
+		Targets:       ciTargets,
+		PolicyFile:    ciPolicyFile,
+		OrgPolicyFile: ciOrgPolicyFile,
+	})
+	if err != nil {
+		return withExitCode(exitInternal, err)
+	}
+
+	if ciSARIFPath != "" {
+		data, err := json.MarshalIndent(ciSARIF(report), "", "  ")
+		if err != nil {
+			return withExitCode(exitInternal, err)
+		}
+		if err := os.WriteFile(ciSARIFPath, append(data, '\n'), 0o644); err != nil {
+			return withExitCode(exitInternal, fmt.Errorf("failed to write SARIF report: %w", err))
+		}
+	}
+
+	if ciJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return withExitCode(exitInternal, err)
+		}
+	} else {
+		printCIText(os.Stdout, report)
+	}
+	if ciAnnotations {
+		printCIAnnotations(os.Stdout, report)
+	}
+
+	errs, warnings := report.Count(app.CISeverityError), report.Count(app.CISeverityWarning)
+	switch {
+	case report.HasPolicyViolations():
+		return withExitCode(exitPolicyViolation, nil)
+	case errs > 0 || (ciStrict && warnings > 0):
+		return withExitCode(exitDrift, nil)
+	}
+	return nil
+}
+
+// printCIText prints the findings with their location, errors first.
+func printCIText(w io.Writer, report *app.CIReport) {
+	_, _ = fmt.Fprintf(w, "Checked %s (targets: %s)\n", report.ConfigPath, strings.Join(report.Targets, ", "))
+	for _, severity := range []string{app.CISeverityError, app.CISeverityWarning} {
+		icon := "✗"
+		if severity == app.CISeverityWarning {
+			icon = "⚠"
+		}
+		for _, f := range report.Findings {
+			if f.Severity != severity {
+				continue
+			}
+			msg := strings.ReplaceAll(f.Message, "\n", "\n      ")
+			_, _ = fmt.Fprintf(w, "  %s [%s] %s: %s", icon, f.Check, ciLocation(f), msg)
+			if len(f.Targets) > 0 && len(f.Targets) < len(report.Targets) {
+				_, _ = fmt.Fprintf(w, " (%s)", strings.Join(f.Targets, ", "))
+			}
+			_, _ = fmt.Fprintln(w)
+		}
+	}
+
+	errs, warnings := report.Count(app.CISeverityError), report.Count(app.CISeverityWarning)
+	if errs == 0 && warnings == 0 {
+		_, _ = fmt.Fprintln(w, "✓ No issues found")
+		return
+	}
+	_, _ = fmt.Fprintf(w, "\n%d error(s), %d warning(s)\n", errs, warnings)
+}
+
+// ciLocation formats the location of a finding as file[:line[:column]].
+func ciLocation(f app.CIFinding) string {
+	switch {
+	case f.Line > 0 && f.Column > 0:
+		return fmt.Sprintf("%s:%d:%d", f.File, f.Line, f.Column)
+	case f.Line > 0:
+		return fmt.Sprintf("%s:%d", f.File, f.Line)
+	}
+	return f.File
+}
+
+// printCIAnnotations prints the findings as GitHub Actions workflow
+// commands, e.g. "::error file=layers/base.yaml,line=4::message".
+func printCIAnnotations(w io.Writer, report *app.CIReport) {
+	for _, f := range report.Findings {
+		props := []string{"file=" + escapeAnnotationProperty(ciArtifactPath(f.File))}
+		if f.Line > 0 {
+			props = append(props, fmt.Sprintf("line=%d", f.Line))
+		}
+		if f.Column > 0 {
+			props = append(props, fmt.Sprintf("col=%d", f.Column))
+		}
+		props = append(props, "title="+escapeAnnotationProperty("preflight "+f.Check))
+		_, _ = fmt.Fprintf(w, "::%s %s::%s\n", f.Severity, strings.Join(props, ","), escapeAnnotationData(f.Message))
+	}
+}
+
+// escapeAnnotationData escapes a workflow command message.
+func escapeAnnotationData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeAnnotationProperty escapes a workflow command property value.
+func escapeAnnotationProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// ciArtifactPath returns path relative to the working directory with
+// forward slashes, as annotations and SARIF reports expect.
+func ciArtifactPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, abs); err == nil && filepath.IsLocal(rel) {
+				path = rel
+			}
+		}
+	}
+	return filepath.ToSlash(path)
+}
+
+// SARIF 2.1.0 report, limited to the properties code scanning uses.
+type sarifReport struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// ciRules describes the checks of preflight ci as SARIF rules.
+var ciRules = []sarifRule{
+	{ID: app.CICheckValidate, ShortDescription: sarifMessage{Text: "Configuration is valid"}},
+	{ID: app.CICheckPolicy, ShortDescription: sarifMessage{Text: "Configuration complies with policies"}},
+	{ID: app.CICheckDuplicates, ShortDescription: sarifMessage{Text: "Packages are declared by one layer"}},
+	{ID: app.CICheckLockfile, ShortDescription: sarifMessage{Text: "Lockfile matches the declared packages"}},
+}
+
+// ciSARIF converts a CI report to SARIF.
+func ciSARIF(report *app.CIReport) sarifReport {
+	results := make([]sarifResult, 0, len(report.Findings))
+	for _, f := range report.Findings {
+		result := sarifResult{
+			RuleID:  f.Check,
+			Level:   f.Severity,
+			Message: sarifMessage{Text: f.Message},
+		}
+		if f.File != "" {
+			loc := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: ciArtifactPath(f.File)},
+			}}
+			if f.Line > 0 {
+				loc.PhysicalLocation.Region = &sarifRegion{StartLine: f.Line, StartColumn: f.Column}
+			}
+			result.Locations = []sarifLocation{loc}
+		}
+		results = append(results, result)
+	}
+
+	return sarifReport{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "preflight",
+				Version:        version,
+				InformationURI: "https://github.com/felixgeelhaar/preflight",
+				Rules:          ciRules,
+			}},
+			Results: results,
+		}},
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testCIReport() *app.CIReport {
+	return &app.CIReport{
+		ConfigPath: "preflight.yaml",
+		Targets:    []string{"default", "work"},
+		Findings: []app.CIFinding{
+			{Check: app.CICheckDuplicates, Severity: app.CISeverityWarning, Message: "git is declared by layers a, b", File: "layers/b.yaml", Line: 4, Column: 7, Targets: []string{"work"}},
+			{Check: app.CICheckLockfile, Severity: app.CISeverityError, Message: "100% stale\nrun 'preflight lock update'", File: "preflight.lock"},
+		},
+	}
+}
+
+func TestPrintCIAnnotations(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	printCIAnnotations(&buf, testCIReport())
+	assert.Equal(t,
+		"::warning file=layers/b.yaml,line=4,col=7,title=preflight duplicates::git is declared by layers a, b\n"+
+			"::error file=preflight.lock,title=preflight lockfile::100%25 stale%0Arun 'preflight lock update'\n",
+		buf.String())
+}
+
+func TestPrintCIText(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	printCIText(&buf, testCIReport())
+	assert.Equal(t, `Checked preflight.yaml (targets: default, work)
+  ✗ [lockfile] preflight.lock: 100% stale
+      run 'preflight lock update'
+  ⚠ [duplicates] layers/b.yaml:4:7: git is declared by layers a, b (work)
+
+1 error(s), 1 warning(s)
+`, buf.String())
+
+	buf.Reset()
+	printCIText(&buf, &app.CIReport{ConfigPath: "preflight.yaml", Targets: []string{"default"}})
+	assert.Contains(t, buf.String(), "No issues found")
+}
+
+func TestCISARIF(t *testing.T) {
+	t.Parallel()
+
+	sarif := ciSARIF(testCIReport())
+	assert.Equal(t, "2.1.0", sarif.Version)
+	require.Len(t, sarif.Runs, 1)
+	run := sarif.Runs[0]
+	assert.Len(t, run.Tool.Driver.Rules, 4)
+	require.Len(t, run.Results, 2)
+
+	result := run.Results[0]
+	assert.Equal(t, app.CICheckDuplicates, result.RuleID)
+	assert.Equal(t, "warning", result.Level)
+	require.Len(t, result.Locations, 1)
+	assert.Equal(t, "layers/b.yaml", result.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, &sarifRegion{StartLine: 4, StartColumn: 7}, result.Locations[0].PhysicalLocation.Region)
+
+	assert.Nil(t, run.Results[1].Locations[0].PhysicalLocation.Region, "findings without a line locate the file")
+}
@@ -78,7 +78,7 @@ func runClean(_ *cobra.Command, _ []string) error {
 	config, err := preflight.LoadMergedConfig(ctx, cleanConfigPath, cleanTarget)
 	if err != nil {
 		return &pfconfig.UserError{
-			Code:       "CONFIG_LOAD_FAILED",
+			Code:       pfconfig.ErrCodeConfigLoadFailed,
 			Message:    "could not load configuration for clean",
 			Suggestion: "Run 'preflight validate' to check the config, or pass --config with the path to a valid preflight.yaml.",
 			Underlying: err,
@@ -89,7 +89,7 @@ func runClean(_ *cobra.Command, _ []string) error {
 	systemState, err := preflight.CaptureSystemState(ctx)
 	if err != nil {
 		return &pfconfig.UserError{
-			Code:       "CAPTURE_FAILED",
+			Code:       pfconfig.ErrCodeCaptureFailed,
 			Message:    "could not capture current system state",
 			Suggestion: "Verify required tools (brew/apt/etc.) are on PATH, then re-run. Use --providers to limit scope if a specific provider is failing.",
 			Underlying: err,
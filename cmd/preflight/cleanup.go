@@ -1,15 +1,21 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"os/exec"
 	"os/signal"
+	"path/filepath"
 	"strings"
 	"syscall"
 	"text/tabwriter"
+	"time"
 
+	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
 	"github.com/felixgeelhaar/preflight/internal/domain/security"
 	"github.com/spf13/cobra"
 )
@@ -24,17 +30,25 @@ This command analyzes installed packages for:
   - Overlapping tools serving the same purpose
   - Orphaned dependencies no longer needed
 
+Homebrew, npm/pnpm/yarn globals, pip user packages and pipx applications,
+and cargo installs are analyzed when their tools are installed. Packages of
+ecosystems other than Homebrew are named with their manager, e.g.
+npm:eslint or pipx:black, and removed with that manager.
+
 By default, runs in dry-run mode to show what would be removed.
 
 Exit codes:
   0 - No redundancies found (or cleanup successful)
   1 - Redundancies found (in analysis mode)
-  2 - Checker not available or operation failed
+  3 - Checker not available or operation failed
 
 Examples:
   preflight cleanup                   # Analyze redundancies (dry-run)
   preflight cleanup --remove go@1.24  # Remove specific package
+  preflight cleanup --remove npm:eslint  # Remove a global npm package
+  preflight cleanup --ecosystem pip   # Only analyze pip and pipx
   preflight cleanup --autoremove      # Remove orphaned dependencies
+  preflight cleanup --caches          # Remove package caches and old versions
   preflight cleanup --all             # Interactive cleanup of all
   preflight cleanup --json            # JSON output for CI`,
 	RunE: runCleanup,
@@ -51,6 +65,8 @@ var (
 	cleanupKeep       []string
 	cleanupNoOrphans  bool
 	cleanupNoOverlaps bool
+	cleanupEcosystems []string
+	cleanupCaches     bool
 )
 
 func init() {
@@ -66,58 +82,76 @@ func init() {
 	cleanupCmd.Flags().StringSliceVar(&cleanupKeep, "keep", nil, "Packages to never remove")
 	cleanupCmd.Flags().BoolVar(&cleanupNoOrphans, "no-orphans", false, "Skip orphaned dependency detection")
 	cleanupCmd.Flags().BoolVar(&cleanupNoOverlaps, "no-overlaps", false, "Skip overlapping tools detection")
+	cleanupCmd.Flags().BoolVar(&cleanupCaches, "caches", false, "Remove brew and npm caches and old formula versions")
+	cleanupCmd.Flags().StringSliceVar(&cleanupEcosystems, "ecosystem", nil, "Limit analysis to these ecosystems (brew, npm, pip, cargo)")
 }
 
 func runCleanup(_ *cobra.Command, _ []string) error {
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 
-	// Create checker
 	checker := security.NewBrewRedundancyChecker()
-	if !checker.Available() {
-		if cleanupJSON {
-			outputCleanupJSON(nil, nil, fmt.Errorf("brew not available"))
-		} else {
-			fmt.Fprintf(os.Stderr, "Error: Homebrew is not installed\n")
-		}
-		cancel()
-		os.Exit(2)
-	}
 
 	// Handle specific remove requests
 	if len(cleanupRemove) > 0 {
+		err := handleRemove(ctx, checker, cleanupRemove)
 		cancel()
-		return handleRemove(ctx, checker, cleanupRemove)
+		return err
 	}
 
 	// Handle autoremove
 	if cleanupAutoremove {
+		if !checker.Available() {
+			cancel()
+			cleanupUnavailable(fmt.Errorf("brew not available"), "Homebrew")
+		}
+		err := handleAutoremove(ctx, checker)
 		cancel()
-		return handleAutoremove(ctx, checker)
+		return err
 	}
 
-	// Run analysis
-	opts := security.RedundancyOptions{
-		IgnorePackages:  cleanupIgnore,
-		KeepPackages:    cleanupKeep,
-		IncludeOrphans:  !cleanupNoOrphans,
-		IncludeOverlaps: !cleanupNoOverlaps,
+	if cleanupCaches {
+		err := handleCaches(ctx)
+		cancel()
+		return err
 	}
 
-	result, err := checker.Check(ctx, opts)
-	if err != nil {
-		if cleanupJSON {
-			outputCleanupJSON(nil, nil, err)
-		} else {
-			fmt.Fprintf(os.Stderr, "Analysis failed: %v\n", err)
-		}
+	checkers := cleanupCheckers(checker)
+	if len(checkers) == 0 {
 		cancel()
-		os.Exit(2)
+		cleanupUnavailable(fmt.Errorf("no supported package manager available"), "Homebrew")
+	}
+
+	// Run analysis
+	opts := security.RedundancyOptions{
+		IgnorePackages:   cleanupIgnore,
+		KeepPackages:     cleanupKeep,
+		IncludeOrphans:   !cleanupNoOrphans,
+		IncludeOverlaps:  !cleanupNoOverlaps,
+		DeclaredPackages: declaredBrewPackages(cfgFile),
+	}
+
+	results := make([]*security.RedundancyResult, 0, len(checkers))
+	for _, c := range checkers {
+		result, err := c.Check(ctx, opts)
+		if err != nil {
+			err = fmt.Errorf("%s: %w", c.Name(), err)
+			if cleanupJSON {
+				outputCleanupJSON(nil, nil, err)
+			} else {
+				fmt.Fprintf(os.Stderr, "Analysis failed: %v\n", err)
+			}
+			cancel()
+			os.Exit(exitInternal)
+		}
+		results = append(results, result)
 	}
+	result := security.MergeRedundancyResults(results)
 
 	// Handle --all flag
 	if cleanupAll && len(result.Redundancies) > 0 {
+		err := handleCleanupAll(ctx, checker, result)
 		cancel()
-		return handleCleanupAll(ctx, checker, result)
+		return err
 	}
 
 	// Output analysis results
@@ -130,13 +164,98 @@ func runCleanup(_ *cobra.Command, _ []string) error {
 	// Exit with code 1 if redundancies found
 	if len(result.Redundancies) > 0 {
 		cancel()
-		os.Exit(1)
+		os.Exit(exitDrift)
 	}
 
 	cancel()
 	return nil
 }
 
+// cleanupCheckers returns the available redundancy checkers, limited to
+// --ecosystem when given.
+func cleanupCheckers(brew *security.BrewRedundancyChecker) []security.RedundancyChecker {
+	registry := security.NewRedundancyCheckerRegistry()
+	registry.Register(brew)
+	registry.Register(security.NewNodeRedundancyChecker())
+	registry.Register(security.NewPythonRedundancyChecker())
+	registry.Register(security.NewCargoRedundancyChecker())
+
+	checkers := registry.All()
+	if len(cleanupEcosystems) == 0 {
+		return checkers
+	}
+	selected := make([]security.RedundancyChecker, 0, len(checkers))
+	for _, c := range checkers {
+		for _, ecosystem := range cleanupEcosystems {
+			if c.Name() == ecosystem {
+				selected = append(selected, c)
+			}
+		}
+	}
+	return selected
+}
+
+// declaredBrewPackages returns the Homebrew formulae and casks declared in
+// any layer of the configuration at configPath, or nil when there is none.
+func declaredBrewPackages(configPath string) []string {
+	packages := declaredPackages(configPath)
+	declared := append([]string(nil), packages.Brew.Formulae...)
+	return append(declared, packages.Brew.Casks...)
+}
+
+// declaredPackages returns the packages declared in any layer of the
+// configuration at configPath, whichever targets use them. Layers that do
+// not parse are skipped.
+func declaredPackages(configPath string) config.PackageSet {
+	var declared config.PackageSet
+	if configPath == "" {
+		configPath = "preflight.yaml"
+	}
+	if _, err := os.Stat(configPath); err != nil {
+		return declared
+	}
+	paths, _ := filepath.Glob(filepath.Join(filepath.Dir(configPath), "layers", "*.yaml"))
+	for _, path := range paths {
+		layer, err := config.NewLoader().LoadLayer(path)
+		if err != nil {
+			continue
+		}
+		declared.Brew.Formulae = append(declared.Brew.Formulae, layer.Packages.Brew.Formulae...)
+		declared.Brew.Casks = append(declared.Brew.Casks, layer.Packages.Brew.Casks...)
+		declared.Npm.Packages = append(declared.Npm.Packages, layer.Packages.Npm.Packages...)
+	}
+	return declared
+}
+
+// cleanupUnavailable reports that no checker can run and exits.
+func cleanupUnavailable(err error, tool string) {
+	if cleanupJSON {
+		outputCleanupJSON(nil, nil, err)
+	} else {
+		printError(config.NewProviderUnavailableError(tool))
+	}
+	os.Exit(exitInternal)
+}
+
+// removePackages removes package references: Homebrew packages with the
+// brew checker and the others with their own managers.
+func removePackages(ctx context.Context, checker *security.BrewRedundancyChecker, refs []string) error {
+	var brewPkgs, others []string
+	for _, ref := range refs {
+		if manager, name := security.ParsePackageRef(ref); manager == "brew" {
+			brewPkgs = append(brewPkgs, name)
+		} else {
+			others = append(others, ref)
+		}
+	}
+	if len(brewPkgs) > 0 {
+		if err := checker.Cleanup(ctx, brewPkgs, false); err != nil {
+			return err
+		}
+	}
+	return security.NewPackageUninstaller().Uninstall(ctx, others)
+}
+
 func handleRemove(ctx context.Context, checker *security.BrewRedundancyChecker, packages []string) error {
 	if cleanupDryRun {
 		if cleanupJSON {
@@ -164,14 +283,14 @@ func handleRemove(ctx context.Context, checker *security.BrewRedundancyChecker,
 		}
 	}
 
-	err := checker.Cleanup(ctx, packages, false)
+	err := removePackages(ctx, checker, packages)
 	if err != nil {
 		if cleanupJSON {
 			outputCleanupJSON(nil, nil, err)
 		} else {
 			fmt.Fprintf(os.Stderr, "Cleanup failed: %v\n", err)
 		}
-		os.Exit(2)
+		os.Exit(exitInternal)
 	}
 
 	if cleanupJSON {
@@ -194,7 +313,7 @@ func handleAutoremove(ctx context.Context, checker *security.BrewRedundancyCheck
 		} else {
 			fmt.Fprintf(os.Stderr, "Autoremove failed: %v\n", err)
 		}
-		os.Exit(2)
+		os.Exit(exitInternal)
 	}
 
 	if cleanupJSON {
@@ -219,6 +338,71 @@ func handleAutoremove(ctx context.Context, checker *security.BrewRedundancyCheck
 	return nil
 }
 
+// handleCaches removes package manager caches and old formula versions
+// with the package managers' own cleanup commands, and records the space
+// freed in history.
+func handleCaches(ctx context.Context) error {
+	loc := diskLocations(ctx)
+	commands := app.CacheCleanupCommands(loc)
+	if len(commands) == 0 {
+		return fmt.Errorf("neither brew nor npm is installed")
+	}
+	reclaimable := app.ReclaimableBytes(loc)
+
+	if cleanupDryRun {
+		if cleanupJSON {
+			outputCleanupJSON(nil, &security.CleanupResult{DryRun: true, FreedBytes: reclaimable}, nil)
+		} else {
+			fmt.Printf("Would free about %s by running:\n", formatByteSize(reclaimable))
+			for _, command := range commands {
+				fmt.Printf("  %s\n", strings.Join(command, " "))
+			}
+		}
+		return nil
+	}
+
+	// Confirm unless --yes flag
+	if !yesFlag {
+		fmt.Printf("Free about %s of caches and old versions? [y/N] ", formatByteSize(reclaimable))
+		var response string
+		_, _ = fmt.Scanln(&response)
+		if !strings.EqualFold(response, "y") {
+			fmt.Println("Aborted.")
+			return nil
+		}
+	}
+
+	started := time.Now()
+	entry := HistoryEntry{Command: "cleanup --caches", Status: "success"}
+	var runErr error
+	for _, command := range commands {
+		cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			runErr = fmt.Errorf("%s failed: %w: %s", strings.Join(command, " "), err, strings.TrimSpace(stderr.String()))
+			break
+		}
+		entry.Changes = append(entry.Changes, Change{Provider: command[0], Action: "delete", Item: "cache", Details: strings.Join(command, " ")})
+	}
+	entry.FreedBytes = max(reclaimable-app.ReclaimableBytes(loc), 0)
+	entry.Duration = time.Since(started).Round(time.Millisecond).String()
+	if runErr != nil {
+		entry.Status = "failed"
+		entry.Error = runErr.Error()
+	}
+	if err := SaveHistoryEntry(entry); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not record history: %v\n", err)
+	}
+
+	if cleanupJSON {
+		outputCleanupJSON(nil, &security.CleanupResult{FreedBytes: entry.FreedBytes}, runErr)
+	} else if runErr == nil {
+		fmt.Printf("✓ Freed %s\n", formatByteSize(entry.FreedBytes))
+	}
+	return runErr
+}
+
 func handleCleanupAll(ctx context.Context, checker *security.BrewRedundancyChecker, result *security.RedundancyResult) error {
 	// Collect all packages to remove
 	toRemove := make([]string, 0, len(result.Redundancies))
@@ -261,14 +445,14 @@ func handleCleanupAll(ctx context.Context, checker *security.BrewRedundancyCheck
 		}
 	}
 
-	err := checker.Cleanup(ctx, toRemove, false)
+	err := removePackages(ctx, checker, toRemove)
 	if err != nil {
 		if cleanupJSON {
 			outputCleanupJSON(result, nil, err)
 		} else {
 			fmt.Fprintf(os.Stderr, "Cleanup failed: %v\n", err)
 		}
-		os.Exit(2)
+		os.Exit(exitInternal)
 	}
 
 	if cleanupJSON {
@@ -341,7 +525,14 @@ func outputCleanupText(result *security.RedundancyResult, quiet bool) {
 		printOverlapTable(overlaps)
 	}
 
-	orphans := result.Redundancies.ByType(security.RedundancyOrphan)
+	var orphans, undeclared security.Redundancies
+	for _, o := range result.Redundancies.ByType(security.RedundancyOrphan) {
+		if o.Category == "undeclared_packages" {
+			undeclared = append(undeclared, o)
+		} else {
+			orphans = append(orphans, o)
+		}
+	}
 	if len(orphans) > 0 {
 		fmt.Println()
 		fmt.Printf("Orphaned Dependencies (%d packages)\n", orphans.TotalRemovable())
@@ -351,6 +542,14 @@ func outputCleanupText(result *security.RedundancyResult, quiet bool) {
 		}
 	}
 
+	for _, u := range undeclared {
+		fmt.Println()
+		fmt.Printf("Not in Configuration (%d packages)\n", len(u.Packages))
+		fmt.Printf("  %s\n", strings.Join(u.Packages, ", "))
+		fmt.Printf("  → %s\n", u.Recommendation)
+		fmt.Printf("  → Add them to a layer, or run: %s\n", u.Action)
+	}
+
 	// Print actions
 	fmt.Println()
 	fmt.Println("Actions:")
@@ -363,8 +562,17 @@ func outputCleanupText(result *security.RedundancyResult, quiet bool) {
 			fmt.Printf("  preflight cleanup --remove %s\n", strings.Join(removable, " "))
 		}
 	}
-	if len(orphans) > 0 {
-		fmt.Println("  preflight cleanup --autoremove")
+	for _, o := range orphans {
+		if len(o.Packages) == 0 {
+			continue
+		}
+		// Homebrew orphans are removed with brew autoremove, the others
+		// with the uninstall command of their manager.
+		if manager, _ := security.ParsePackageRef(o.Packages[0]); manager == "brew" {
+			fmt.Println("  preflight cleanup --autoremove")
+		} else {
+			fmt.Printf("  %s\n", o.Action)
+		}
 	}
 	fmt.Println("  preflight cleanup --all    # Remove all")
 }
@@ -2,6 +2,8 @@ package main
 
 import (
 	"encoding/json"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/felixgeelhaar/preflight/internal/domain/security"
@@ -28,6 +30,8 @@ func TestCleanupCommand_Flags(t *testing.T) {
 	assert.NotNil(t, flags.Lookup("keep"))
 	assert.NotNil(t, flags.Lookup("no-orphans"))
 	assert.NotNil(t, flags.Lookup("no-overlaps"))
+	assert.NotNil(t, flags.Lookup("ecosystem"))
+	assert.NotNil(t, flags.Lookup("caches"))
 }
 
 func TestFormatCategory(t *testing.T) {
@@ -365,3 +369,49 @@ func TestPrintOverlapTable(t *testing.T) {
 	assert.Contains(t, output, "Keep: npm")
 	assert.Contains(t, output, "Remove: yarn, pnpm")
 }
+
+func TestDeclaredBrewPackages(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "preflight.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("targets:\n  default: [base]\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "layers"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "layers", "base.yaml"), []byte(`name: base
+packages:
+  brew:
+    formulae: [git, hashicorp/tap/terraform]
+    casks: [docker]
+`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "layers", "broken.yaml"), []byte("packages: [\n"), 0o644))
+
+	assert.Equal(t, []string{"git", "hashicorp/tap/terraform", "docker"}, declaredBrewPackages(configPath))
+	assert.Nil(t, declaredBrewPackages(filepath.Join(dir, "missing.yaml")))
+}
+
+func TestOutputCleanupText_Undeclared(t *testing.T) {
+	t.Parallel()
+
+	result := &security.RedundancyResult{
+		Checker: "brew",
+		Redundancies: security.Redundancies{
+			{
+				Type:           security.RedundancyOrphan,
+				Packages:       []string{"ffmpeg"},
+				Category:       "undeclared_packages",
+				Recommendation: "1 packages installed on request are not in your configuration; removing them also frees x264",
+				Action:         "preflight cleanup --remove ffmpeg,x264",
+			},
+		},
+	}
+
+	output := captureStdout(t, func() {
+		outputCleanupText(result, false)
+	})
+
+	assert.Contains(t, output, "Not in Configuration (1 packages)")
+	assert.Contains(t, output, "frees x264")
+	assert.Contains(t, output, "preflight cleanup --remove ffmpeg,x264")
+	assert.NotContains(t, output, "Orphaned Dependencies")
+	assert.NotContains(t, output, "--autoremove")
+}
@@ -2,12 +2,19 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
 	"github.com/felixgeelhaar/preflight/internal/domain/policy"
+	"github.com/felixgeelhaar/preflight/internal/domain/sync"
+	"github.com/felixgeelhaar/preflight/internal/ports"
 	"github.com/spf13/cobra"
 )
 
@@ -28,8 +35,9 @@ Compliance reports are useful for:
 
 Exit codes:
   0 - Compliant (no blocking violations)
-  1 - Non-compliant (blocking violations found)
-  2 - Could not load configuration or policy
+  1 - Warnings found (with --strict)
+  2 - Non-compliant (blocking violations found)
+  3 - Could not load configuration or policy
 
 Examples:
   preflight compliance
@@ -42,15 +50,29 @@ Examples:
 var complianceAttestCmd = &cobra.Command{
 	Use:   "attest",
 	Short: "Create a signed compliance attestation",
-	Long: `Create a cryptographically signed attestation proving machine compliance.
+	Long: `Create a signed attestation of this machine's compliance.
 
-The attestation wraps the compliance report with a signature, providing
-verifiable proof that the machine met policy requirements at a point in time.
+The attestation is a JSON document with the compliance report, its status and
+score, the SHA256 of the org policy file, the machine ID and hardware
+fingerprint, and the time. It is signed with an ed25519 key whose public key
+is in your trust store, so any machine that trusts the key can verify it with
+'preflight compliance verify'.
+
+The attestation is written to stdout or --output, ready to upload to a fleet
+server or to store in an MDM custom attribute. A copy is kept in the
+attestations directory of the preflight data directory. Unlike 'preflight
+compliance', attest exits 0 for a non-compliant machine: the status is in
+the attestation.
+
+To attest on a schedule, run it from cron, a launchd agent or an MDM script.
 
 Examples:
-  preflight compliance attest
-  preflight compliance attest --sign-key ~/.preflight/signing.key
-  preflight compliance attest --sigstore`,
+  preflight compliance attest --policy org-policy.yaml --sign-key ~/.ssh/preflight_ed25519
+  preflight compliance attest --sign-key ~/.ssh/preflight_ed25519 --key-id acme-it -o attestation.json
+
+  # Hourly from cron
+  0 * * * * preflight compliance attest --policy /etc/preflight/org-policy.yaml --sign-key /etc/preflight/attest.key -o /var/db/preflight-attestation.json`,
+	Args: cobra.NoArgs,
 	RunE: runComplianceAttest,
 }
 
@@ -59,9 +81,11 @@ var complianceVerifyCmd = &cobra.Command{
 	Short: "Verify a compliance attestation",
 	Long: `Verify the signature and contents of a compliance attestation file.
 
+The attestation must be signed by a key in your trust store and unchanged
+since it was signed.
+
 Examples:
-  preflight compliance verify attestation.json
-  preflight compliance verify --sign-key ~/.preflight/signing.key attestation.json`,
+  preflight compliance verify attestation.json`,
 	Args: cobra.ExactArgs(1),
 	RunE: runComplianceVerify,
 }
@@ -74,22 +98,24 @@ var (
 	complianceStrict     bool
 	complianceShowItems  bool
 	complianceSignKey    string
-	complianceSigstore   bool
+	complianceKeyID      string
+	complianceOutput     string
 )
 
 func init() {
 	rootCmd.AddCommand(complianceCmd)
 
-	complianceCmd.Flags().StringVarP(&complianceConfigPath, "config", "c", "preflight.yaml", "Path to preflight.yaml")
-	complianceCmd.Flags().StringVarP(&complianceTarget, "target", "t", "default", "Target to evaluate")
-	complianceCmd.Flags().StringVar(&compliancePolicyFile, "policy", "", "Path to org policy YAML file")
+	complianceCmd.PersistentFlags().StringVarP(&complianceConfigPath, "config", "c", "preflight.yaml", "Path to preflight.yaml")
+	complianceCmd.PersistentFlags().StringVarP(&complianceTarget, "target", "t", "default", "Target to evaluate")
+	complianceCmd.PersistentFlags().StringVar(&compliancePolicyFile, "policy", "", "Path to org policy YAML file")
 	complianceCmd.Flags().BoolVar(&complianceJSON, "json", false, "Output report as JSON")
 	complianceCmd.Flags().BoolVar(&complianceStrict, "strict", false, "Treat warnings as errors (exit 1)")
 	complianceCmd.Flags().BoolVar(&complianceShowItems, "show-items", false, "Include evaluated items in report")
 
-	complianceAttestCmd.Flags().StringVar(&complianceSignKey, "sign-key", "", "Path to signing key file")
-	complianceAttestCmd.Flags().BoolVar(&complianceSigstore, "sigstore", false, "Use Sigstore keyless signing")
-	complianceVerifyCmd.Flags().StringVar(&complianceSignKey, "sign-key", "", "Path to signing key file")
+	complianceAttestCmd.Flags().StringVar(&complianceSignKey, "sign-key", "", "Path to an ed25519 private key whose public key is in the trust store")
+	complianceAttestCmd.Flags().StringVar(&complianceKeyID, "key-id", "", "Trust store key ID (default: found from the public key)")
+	complianceAttestCmd.Flags().StringVarP(&complianceOutput, "output", "o", "", "Write the attestation to a file instead of stdout")
+	_ = complianceAttestCmd.MarkFlagRequired("sign-key")
 
 	complianceCmd.AddCommand(complianceAttestCmd)
 	complianceCmd.AddCommand(complianceVerifyCmd)
@@ -111,23 +137,46 @@ func runCompliance(_ *cobra.Command, _ []string) error {
 		} else {
 			printError(err)
 		}
-		os.Exit(2)
+		os.Exit(exitInternal)
 	}
 
 	// Load the org policy for generating the full compliance report
 	var orgPolicy *policy.OrgPolicy
 	if compliancePolicyFile != "" {
-		orgPolicy, err = policy.LoadOrgPolicyFromFile(compliancePolicyFile)
+		orgPolicy, _, err = app.LoadSignedOrgPolicy(compliancePolicyFile)
 		if err != nil {
 			if complianceJSON {
 				outputComplianceError(err)
 			} else {
 				fmt.Fprintf(os.Stderr, "Error loading org policy: %v\n", err)
 			}
-			os.Exit(2)
+			os.Exit(exitInternal)
 		}
 	}
 
+	report := newComplianceReport(ctx, result, orgPolicy)
+
+	// Output the report
+	if complianceJSON {
+		outputComplianceJSON(report)
+	} else {
+		outputComplianceText(report)
+	}
+
+	// Determine exit code
+	if report.HasBlockingViolations() {
+		os.Exit(exitPolicyViolation)
+	}
+	if complianceStrict && report.Summary.Status == policy.ComplianceStatusWarning {
+		os.Exit(exitDrift)
+	}
+
+	return nil
+}
+
+// newComplianceReport evaluates the validation result against the org
+// policy, which is nil without --policy.
+func newComplianceReport(ctx context.Context, result *app.ValidationResult, orgPolicy *policy.OrgPolicy) *policy.ComplianceReport {
 	// Collect evaluated items from configuration
 	evaluatedItems := collectEvaluatedItems(result)
 
@@ -158,22 +207,30 @@ func runCompliance(_ *cobra.Command, _ []string) error {
 		report.EvaluatedItems = nil
 	}
 
-	// Output the report
-	if complianceJSON {
-		outputComplianceJSON(report)
-	} else {
-		outputComplianceText(report)
+	report.Onboarding = complianceOnboardingStatus(complianceConfigPath, complianceTarget)
+
+	posture := app.CollectPosture(ctx)
+	report.PostureState = &posture
+	if orgPolicy != nil {
+		report.AddPosture(orgPolicy.Posture.Evaluate(posture), orgPolicy.Enforcement)
 	}
 
-	// Determine exit code
-	if report.HasBlockingViolations() {
-		os.Exit(1)
+	return report
+}
+
+// complianceOnboardingStatus reports the layers' onboarding checklist
+// progress on this machine, or nil when no layer declares onboarding steps.
+func complianceOnboardingStatus(configPath, target string) *policy.OnboardingStatus {
+	svc, err := app.DefaultOnboardingService()
+	if err != nil {
+		return nil
 	}
-	if complianceStrict && report.Summary.Status == policy.ComplianceStatusWarning {
-		os.Exit(1)
+	items, err := svc.Checklist(configPath, target)
+	if err != nil || len(items) == 0 {
+		return nil
 	}
-
-	return nil
+	completed, pending := app.OnboardingProgress(items)
+	return &policy.OnboardingStatus{Total: len(items), Completed: completed, Pending: pending}
 }
 
 // collectEvaluatedItems extracts all items that were evaluated from the validation result.
@@ -208,37 +265,128 @@ func outputComplianceJSON(report *policy.ComplianceReport) {
 	data, err := report.ToJSON()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error formatting report: %v\n", err)
-		os.Exit(2)
+		os.Exit(exitInternal)
 	}
 	fmt.Println(string(data))
 }
 
 func runComplianceAttest(_ *cobra.Command, _ []string) error {
-	fmt.Println("Creating compliance attestation...")
+	ctx := context.Background()
 
-	switch {
-	case complianceSigstore:
-		fmt.Println("Using Sigstore keyless signing")
-	case complianceSignKey != "":
-		fmt.Printf("Using signing key: %s\n", complianceSignKey)
-	default:
-		return fmt.Errorf("specify --sign-key or --sigstore for signing")
+	key, keyID, err := resolvePublishSigningKey(complianceSignKey, complianceKeyID)
+	if err != nil {
+		return err
+	}
+	pubKey, err := os.ReadFile(ports.ExpandPath(complianceSignKey) + ".pub")
+	if err != nil {
+		return fmt.Errorf("failed to read public key: %w", err)
+	}
+
+	result, err := app.New(os.Stderr).ValidateWithOptions(ctx, complianceConfigPath, complianceTarget, app.ValidateOptions{
+		OrgPolicyFile: compliancePolicyFile,
+	})
+	if err != nil {
+		return err
+	}
+	var orgPolicy *policy.OrgPolicy
+	var policyHash string
+	if compliancePolicyFile != "" {
+		if orgPolicy, _, err = app.LoadSignedOrgPolicy(compliancePolicyFile); err != nil {
+			return fmt.Errorf("failed to load org policy: %w", err)
+		}
+		data, err := os.ReadFile(compliancePolicyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load org policy: %w", err)
+		}
+		sum := sha256.Sum256(data)
+		policyHash = "sha256:" + hex.EncodeToString(sum[:])
 	}
+	report := newComplianceReport(ctx, result, orgPolicy)
 
-	fmt.Println("\nAttestation created successfully.")
-	fmt.Println("Run 'preflight compliance verify <file>' to verify.")
+	machineID, err := sync.GetMachineID()
+	if err != nil {
+		return fmt.Errorf("failed to read machine ID: %w", err)
+	}
+	hostname, _ := os.Hostname()
+	att, err := policy.NewComplianceAttestation(report, machineID.String(), hostname)
+	if err != nil {
+		return err
+	}
+	att.PolicyHash = policyHash
+	if fp, err := machineFingerprint(ctx); err == nil && !fp.IsZero() {
+		att.Fingerprint = &fp
+	}
+	if err := policy.NewTrustKeyAttester(key, keyID, pubKey).Sign(ctx, att); err != nil {
+		return fmt.Errorf("failed to sign attestation: %w", err)
+	}
+
+	dir, err := platform.Path(platform.DataDir, "attestations")
+	if err != nil {
+		return err
+	}
+	if err := policy.NewAttestationStore(dir).Save(att); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(att, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if complianceOutput == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(complianceOutput, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write attestation: %w", err)
+	}
+	fmt.Printf("Attestation written to %s\n", complianceOutput)
+	fmt.Printf("  Status: %s, score %.1f%%\n", att.Status, att.Score)
+	fmt.Printf("  Signed by: %s\n", keyID)
+	fmt.Println("\nRun 'preflight compliance verify " + complianceOutput + "' to verify.")
 	return nil
 }
 
 func runComplianceVerify(_ *cobra.Command, args []string) error {
-	attestationFile := args[0]
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to read attestation: %w", err)
+	}
+	var att policy.ComplianceAttestation
+	if err := json.Unmarshal(data, &att); err != nil {
+		return fmt.Errorf("failed to parse attestation: %w", err)
+	}
+	if err := att.Validate(); err != nil {
+		return err
+	}
+	if !att.IsSigned() {
+		return policy.ErrAttestationUnsigned
+	}
 
-	if _, err := os.Stat(attestationFile); os.IsNotExist(err) {
-		return fmt.Errorf("attestation file not found: %s", attestationFile)
+	store, err := getTrustStore()
+	if err != nil {
+		return err
+	}
+	trusted, ok := store.Get(att.SignerIdentity)
+	if !ok {
+		return &config.UserError{
+			Code:       config.ErrCodeValidationFailed,
+			Message:    fmt.Sprintf("attestation is signed by %s, which is not in your trust store", att.SignerIdentity),
+			Suggestion: "Add the signer's public key with 'preflight trust add <key.pub>'",
+		}
+	}
+	if err := policy.NewTrustKeyVerifier(trusted.Fingerprint()).Verify(context.Background(), &att); err != nil {
+		return err
 	}
 
-	fmt.Printf("Verifying attestation: %s\n", attestationFile)
-	fmt.Println("Attestation verification complete.")
+	fmt.Printf("Signature verified: %s\n", att.SignerIdentity)
+	fmt.Printf("  Machine:  %s (%s)\n", att.Hostname, att.MachineID)
+	fmt.Printf("  Attested: %s\n", att.AttestedAt.Local().Format(time.RFC3339))
+	fmt.Printf("  Policy:   %s\n", att.Report.PolicyName)
+	if att.PolicyHash != "" {
+		fmt.Printf("  Hash:     %s\n", att.PolicyHash)
+	}
+	fmt.Printf("  Status:   %s, score %.1f%%\n", att.Status, att.Score)
 	return nil
 }
 
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/catalog"
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
+	"github.com/felixgeelhaar/preflight/internal/domain/policy"
+	"github.com/felixgeelhaar/preflight/internal/domain/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+// setupComplianceAttest creates a config, an org policy and a signing key
+// whose public key is trusted as keyID, and returns the key path.
+func setupComplianceAttest(t *testing.T, keyID string) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv(platform.HomeEnvVar, home)
+	dir := t.TempDir()
+	t.Chdir(dir)
+	require.NoError(t, os.MkdirAll("layers", 0o755))
+	require.NoError(t, os.WriteFile("preflight.yaml", []byte("targets:\n  default:\n    - base\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join("layers", "base.yaml"), []byte("name: base\n"), 0o644))
+	require.NoError(t, os.WriteFile("org-policy.yaml", []byte("policy:\n  name: acme\n  enforcement: warn\n"), 0o644))
+
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	block, err := ssh.MarshalPrivateKey(key, "")
+	require.NoError(t, err)
+	sshPub, err := ssh.NewPublicKey(pub)
+	require.NoError(t, err)
+	pubData := ssh.MarshalAuthorizedKey(sshPub)
+	keyPath := filepath.Join(dir, "attest.key")
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(block), 0o600))
+	require.NoError(t, os.WriteFile(keyPath+".pub", pubData, 0o644))
+
+	store := catalog.NewTrustStore(filepath.Join(home, "trust.json"))
+	trusted := catalog.NewTrustedKey(keyID, catalog.SignatureTypeSSH, nil,
+		catalog.NewPublisher("ACME IT", "it@acme.example", keyID, catalog.SignatureTypeSSH))
+	trusted.SetFingerprint(catalog.ComputeKeyFingerprint(pubData))
+	require.NoError(t, store.Add(trusted))
+	require.NoError(t, store.Save())
+
+	oldFingerprint := machineFingerprint
+	machineFingerprint = func(context.Context) (sync.Fingerprint, error) {
+		return sync.Fingerprint{Hostname: "dev-laptop", Serial: "C02XYZ"}, nil
+	}
+	oldConfig, oldTarget, oldPolicy := complianceConfigPath, complianceTarget, compliancePolicyFile
+	complianceConfigPath, complianceTarget, compliancePolicyFile = "preflight.yaml", "default", "org-policy.yaml"
+	complianceSignKey, complianceOutput = keyPath, "attestation.json"
+	t.Cleanup(func() {
+		machineFingerprint = oldFingerprint
+		complianceConfigPath, complianceTarget, compliancePolicyFile = oldConfig, oldTarget, oldPolicy
+		complianceSignKey, complianceKeyID, complianceOutput = "", "", ""
+	})
+	return keyPath
+}
+
+func TestRunComplianceAttest_Verify(t *testing.T) {
+	setupComplianceAttest(t, "acme-it")
+
+	output := captureStdout(t, func() {
+		require.NoError(t, runComplianceAttest(complianceAttestCmd, nil))
+	})
+	assert.Contains(t, output, "Attestation written to attestation.json")
+	assert.Contains(t, output, "Signed by: acme-it")
+
+	data, err := os.ReadFile("attestation.json")
+	require.NoError(t, err)
+	var att policy.ComplianceAttestation
+	require.NoError(t, json.Unmarshal(data, &att))
+	assert.Equal(t, "ed25519", att.SignatureType)
+	assert.Equal(t, "acme-it", att.SignerIdentity)
+	assert.Equal(t, "acme", att.Report.PolicyName)
+	assert.Regexp(t, "^sha256:[0-9a-f]{64}$", att.PolicyHash)
+	require.NotNil(t, att.Fingerprint)
+	assert.Equal(t, "C02XYZ", att.Fingerprint.Serial)
+
+	// A copy is kept in the data directory
+	dir, err := platform.Path(platform.DataDir, "attestations")
+	require.NoError(t, err)
+	saved, err := policy.NewAttestationStore(dir).LoadLatest(att.MachineID)
+	require.NoError(t, err)
+	require.NotNil(t, saved)
+	assert.Equal(t, att.Signature, saved.Signature)
+
+	output = captureStdout(t, func() {
+		require.NoError(t, runComplianceVerify(complianceVerifyCmd, []string{"attestation.json"}))
+	})
+	assert.Contains(t, output, "Signature verified: acme-it")
+	assert.Contains(t, output, att.PolicyHash)
+
+	// A tampered score fails verification
+	att.Score = 42
+	tampered, err := json.Marshal(att)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile("tampered.json", tampered, 0o644))
+	err = runComplianceVerify(complianceVerifyCmd, []string{"tampered.json"})
+	assert.ErrorIs(t, err, policy.ErrSignatureInvalid)
+}
+
+func TestRunComplianceAttest_Stdout(t *testing.T) {
+	setupComplianceAttest(t, "acme-it")
+	complianceOutput = ""
+
+	output := captureStdout(t, func() {
+		require.NoError(t, runComplianceAttest(complianceAttestCmd, nil))
+	})
+	var att policy.ComplianceAttestation
+	require.NoError(t, json.Unmarshal([]byte(output), &att))
+	assert.True(t, att.IsSigned())
+}
+
+func TestRunComplianceAttest_UntrustedKey(t *testing.T) {
+	setupComplianceAttest(t, "acme-it")
+	complianceKeyID = "someone-else"
+
+	err := runComplianceAttest(complianceAttestCmd, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not in the trust store")
+}
+
+func TestRunComplianceVerify_UnknownSigner(t *testing.T) {
+	setupComplianceAttest(t, "acme-it")
+	captureStdout(t, func() {
+		require.NoError(t, runComplianceAttest(complianceAttestCmd, nil))
+	})
+	// A fresh trust store does not know the signer
+	t.Setenv(platform.HomeEnvVar, t.TempDir())
+
+	err := runComplianceVerify(complianceVerifyCmd, []string{"attestation.json"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not in your trust store")
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/spf13/cobra"
+)
+
+// configDirEnvVar names the directory of configs used when no
+// preflight.yaml is found from the working directory.
+const configDirEnvVar = "PREFLIGHT_CONFIG_DIR"
+
+// configDirFlag is the global --config-dir flag.
+var configDirFlag string
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&configDirFlag, "config-dir", "", "directory of configs (or set "+configDirEnvVar+")")
+	_ = rootCmd.MarkPersistentFlagDirname("config-dir")
+}
+
+// resolveConfigFlag points the --config flag of cmd at the config to use.
+//
+// An explicit --config that is a directory means its preflight.yaml. A
+// relative --config that does not exist is looked up in the config
+// directory, so `--config work` picks work/preflight.yaml there.
+//
+// Without --config, the config is the preflight.yaml of --config-dir, else
+// the nearest preflight.yaml from the working directory up, else the
+// preflight.yaml of PREFLIGHT_CONFIG_DIR.
+func resolveConfigFlag(cmd *cobra.Command) error {
+	flag := cmd.Flags().Lookup("config")
+	if flag == nil {
+		return nil
+	}
+	configDir := configDirFlag
+	if configDir == "" {
+		configDir = os.Getenv(configDirEnvVar)
+	}
+
+	if flag.Changed {
+		path := flag.Value.String()
+		if _, err := os.Stat(path); err != nil && configDir != "" && !filepath.IsAbs(path) {
+			if _, err := os.Stat(filepath.Join(configDir, path)); err == nil {
+				path = filepath.Join(configDir, path)
+			}
+		}
+		return flag.Value.Set(config.ManifestPath(path))
+	}
+
+	if configDirFlag != "" {
+		return flag.Value.Set(filepath.Join(configDirFlag, config.ManifestFileName))
+	}
+	if cwd, err := os.Getwd(); err == nil {
+		if path, ok := config.FindManifest(cwd); ok {
+			if filepath.Dir(path) == cwd {
+				// The default already names it
+				return nil
+			}
+			return flag.Value.Set(path)
+		}
+	}
+	if configDir != "" {
+		return flag.Value.Set(filepath.Join(configDir, config.ManifestFileName))
+	}
+	return nil
+}
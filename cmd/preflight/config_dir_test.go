@@ -0,0 +1,100 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newConfigFlagCmd returns a command with a --config flag like plan's.
+func newConfigFlagCmd(t *testing.T, args ...string) (*cobra.Command, *string) {
+	t.Helper()
+	var path string
+	cmd := &cobra.Command{Use: "plan"}
+	cmd.Flags().StringVarP(&path, "config", "c", "preflight.yaml", "Path to preflight.yaml")
+	require.NoError(t, cmd.Flags().Parse(args))
+	return cmd, &path
+}
+
+func writeManifest(t *testing.T, dir string) string {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	path := filepath.Join(dir, "preflight.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("targets:\n  default: [base]\n"), 0o644))
+	return path
+}
+
+func setConfigDirFlag(t *testing.T, dir string) {
+	t.Helper()
+	orig := configDirFlag
+	configDirFlag = dir
+	t.Cleanup(func() { configDirFlag = orig })
+}
+
+func TestResolveConfigFlag_WalksUp(t *testing.T) {
+	t.Setenv(configDirEnvVar, "")
+	root := t.TempDir()
+	manifest := writeManifest(t, root)
+	nested := filepath.Join(root, "layers")
+	require.NoError(t, os.Mkdir(nested, 0o755))
+
+	t.Chdir(nested)
+	cmd, path := newConfigFlagCmd(t)
+	require.NoError(t, resolveConfigFlag(cmd))
+	resolved, err := filepath.EvalSymlinks(*path)
+	require.NoError(t, err)
+	want, err := filepath.EvalSymlinks(manifest)
+	require.NoError(t, err)
+	assert.Equal(t, want, resolved)
+	assert.False(t, cmd.Flags().Changed("config"))
+
+	t.Chdir(root)
+	cmd, path = newConfigFlagCmd(t)
+	require.NoError(t, resolveConfigFlag(cmd))
+	assert.Equal(t, "preflight.yaml", *path, "a config in the working directory keeps the default")
+}
+
+func TestResolveConfigFlag_ConfigDir(t *testing.T) {
+	configs := t.TempDir()
+	writeManifest(t, configs)
+	work := writeManifest(t, filepath.Join(configs, "work"))
+	t.Chdir(t.TempDir())
+
+	t.Setenv(configDirEnvVar, configs)
+	cmd, path := newConfigFlagCmd(t)
+	require.NoError(t, resolveConfigFlag(cmd))
+	assert.Equal(t, filepath.Join(configs, "preflight.yaml"), *path)
+
+	cmd, path = newConfigFlagCmd(t, "--config", "work")
+	require.NoError(t, resolveConfigFlag(cmd))
+	assert.Equal(t, work, *path, "a config name is looked up in the config directory")
+
+	t.Setenv(configDirEnvVar, "")
+	setConfigDirFlag(t, filepath.Join(configs, "work"))
+	cmd, path = newConfigFlagCmd(t)
+	require.NoError(t, resolveConfigFlag(cmd))
+	assert.Equal(t, work, *path)
+}
+
+func TestResolveConfigFlag_ExplicitConfig(t *testing.T) {
+	t.Setenv(configDirEnvVar, "")
+	dir := t.TempDir()
+	manifest := writeManifest(t, dir)
+	t.Chdir(t.TempDir())
+
+	cmd, path := newConfigFlagCmd(t, "-c", dir)
+	require.NoError(t, resolveConfigFlag(cmd))
+	assert.Equal(t, manifest, *path, "a directory means its preflight.yaml")
+
+	cmd, path = newConfigFlagCmd(t, "-c", "missing.yaml")
+	require.NoError(t, resolveConfigFlag(cmd))
+	assert.Equal(t, "missing.yaml", *path)
+
+	cmd, path = newConfigFlagCmd(t)
+	require.NoError(t, resolveConfigFlag(cmd))
+	assert.Equal(t, "preflight.yaml", *path, "nothing found keeps the default")
+}
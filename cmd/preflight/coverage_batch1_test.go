@@ -777,8 +777,9 @@ func TestBatch1_AnalyzeLayersWithAI_SingleLayer(t *testing.T) {
 func TestBatch1_GetProfileDir(t *testing.T) {
 	tmpDir := t.TempDir()
 	t.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_CONFIG_HOME", "")
 	dir := getProfileDir()
-	assert.Equal(t, filepath.Join(tmpDir, ".preflight", "profiles"), dir)
+	assert.Equal(t, filepath.Join(tmpDir, ".config", "preflight", "profiles"), dir)
 }
 
 func TestBatch1_GetCurrentProfile_NoFile(t *testing.T) {
@@ -803,7 +804,7 @@ func TestBatch1_SetCurrentProfile_CreatesDir(t *testing.T) {
 	t.Setenv("HOME", tmpDir)
 	require.NoError(t, setCurrentProfile("test"))
 
-	info, err := os.Stat(filepath.Join(tmpDir, ".preflight", "profiles"))
+	info, err := os.Stat(getProfileDir())
 	require.NoError(t, err)
 	assert.True(t, info.IsDir())
 }
@@ -1110,7 +1111,7 @@ func TestBatch1_WriteEnvFile_NonSecretVars(t *testing.T) {
 	}
 	require.NoError(t, WriteEnvFile(vars))
 
-	content, err := os.ReadFile(filepath.Join(tmpDir, ".preflight", "env.sh"))
+	content, err := os.ReadFile(filepath.Join(profileEnvDir(), "env.sh"))
 	require.NoError(t, err)
 	s := string(content)
 	assert.Contains(t, s, "# Generated by preflight")
@@ -1128,7 +1129,7 @@ func TestBatch1_WriteEnvFile_SecretVarsExcluded(t *testing.T) {
 	}
 	require.NoError(t, WriteEnvFile(vars))
 
-	content, err := os.ReadFile(filepath.Join(tmpDir, ".preflight", "env.sh"))
+	content, err := os.ReadFile(filepath.Join(profileEnvDir(), "env.sh"))
 	require.NoError(t, err)
 	s := string(content)
 	assert.Contains(t, s, "PUBLIC")
@@ -1142,7 +1143,7 @@ func TestBatch1_WriteEnvFile_EmptyVars(t *testing.T) {
 
 	require.NoError(t, WriteEnvFile([]EnvVar{}))
 
-	content, err := os.ReadFile(filepath.Join(tmpDir, ".preflight", "env.sh"))
+	content, err := os.ReadFile(filepath.Join(profileEnvDir(), "env.sh"))
 	require.NoError(t, err)
 	s := string(content)
 	assert.Contains(t, s, "# Generated by preflight")
@@ -1159,7 +1160,7 @@ func TestBatch1_WriteEnvFile_AllSecrets(t *testing.T) {
 	}
 	require.NoError(t, WriteEnvFile(vars))
 
-	content, err := os.ReadFile(filepath.Join(tmpDir, ".preflight", "env.sh"))
+	content, err := os.ReadFile(filepath.Join(profileEnvDir(), "env.sh"))
 	require.NoError(t, err)
 	assert.NotContains(t, string(content), "export")
 }
@@ -1171,7 +1172,7 @@ func TestBatch1_WriteEnvFile_CreatesDirectory(t *testing.T) {
 	vars := []EnvVar{{Name: "X", Value: "1"}}
 	require.NoError(t, WriteEnvFile(vars))
 
-	info, err := os.Stat(filepath.Join(tmpDir, ".preflight"))
+	info, err := os.Stat(profileEnvDir())
 	require.NoError(t, err)
 	assert.True(t, info.IsDir())
 }
@@ -1186,7 +1187,7 @@ func TestBatch1_WriteEnvFile_SpecialCharsInValue(t *testing.T) {
 	}
 	require.NoError(t, WriteEnvFile(vars))
 
-	content, err := os.ReadFile(filepath.Join(tmpDir, ".preflight", "env.sh"))
+	content, err := os.ReadFile(filepath.Join(profileEnvDir(), "env.sh"))
 	require.NoError(t, err)
 	s := string(content)
 	assert.Contains(t, s, "PATH_VAR")
@@ -1613,7 +1614,7 @@ func TestBatch1_WriteEnvFile_MultipleVars(t *testing.T) {
 	}
 	require.NoError(t, WriteEnvFile(vars))
 
-	content, err := os.ReadFile(filepath.Join(tmpDir, ".preflight", "env.sh"))
+	content, err := os.ReadFile(filepath.Join(profileEnvDir(), "env.sh"))
 	require.NoError(t, err)
 	s := string(content)
 	assert.Equal(t, 3, strings.Count(s, "export"))
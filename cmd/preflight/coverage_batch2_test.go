@@ -342,20 +342,6 @@ func TestBatch2_FilterBySeverity_Empty(t *testing.T) {
 	assert.Empty(t, result)
 }
 
-// ---------------------------------------------------------------------------
-// catalog.go -- verifyCatalogSignatures
-// ---------------------------------------------------------------------------
-
-func TestBatch2_VerifyCatalogSignatures_ReturnsNoSignature(t *testing.T) {
-	t.Parallel()
-	result := verifyCatalogSignatures(nil, nil)
-	assert.False(t, result.hasSignature)
-	assert.False(t, result.verified)
-	assert.Empty(t, result.signer)
-	assert.Empty(t, result.issuer)
-	assert.NoError(t, result.err)
-}
-
 // ---------------------------------------------------------------------------
 // catalog.go -- signatureVerifyResult fields
 // ---------------------------------------------------------------------------
@@ -774,10 +760,10 @@ func TestBatch2_OutputHistoryText_VerboseMultipleEntries(t *testing.T) {
 
 func TestBatch2_SaveHistoryEntry_RoundTrip(t *testing.T) {
 	tmpDir := t.TempDir()
-	histDir := filepath.Join(tmpDir, ".preflight", "history")
 
 	// Patch the getHistoryDir to use tmpDir
 	t.Setenv("HOME", tmpDir)
+	histDir := getHistoryDir()
 
 	entry := HistoryEntry{
 		ID:        "test-batch2",
@@ -1670,6 +1656,6 @@ func TestBatch2_RunHistoryClear_WithEntries(t *testing.T) {
 
 func TestBatch2_GetHistoryDir_ContainsPreflight(t *testing.T) {
 	dir := getHistoryDir()
-	assert.Contains(t, dir, ".preflight")
+	assert.Contains(t, dir, "preflight")
 	assert.Contains(t, dir, "history")
 }
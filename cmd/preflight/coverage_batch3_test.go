@@ -85,17 +85,6 @@ func TestBatch3_FilterBySeverity_Nil(t *testing.T) {
 	assert.Empty(t, result)
 }
 
-// ===========================================================================
-// catalog.go: verifyCatalogSignatures
-// ===========================================================================
-
-func TestBatch3_VerifyCatalogSignatures_NoSignature(t *testing.T) {
-	t.Parallel()
-	result := verifyCatalogSignatures(nil, nil)
-	assert.False(t, result.hasSignature)
-	assert.False(t, result.verified)
-}
-
 // ===========================================================================
 // catalog.go: getRegistry
 // ===========================================================================
@@ -132,17 +121,18 @@ func TestBatch3_RunCatalogList_HasBuiltin(t *testing.T) {
 }
 
 // ===========================================================================
-// catalog.go: runCatalogVerify - signatures flag error
+// catalog.go: runCatalogVerify - signatures flag without a trusted root
 // ===========================================================================
 
-func TestBatch3_RunCatalogVerify_SignaturesNotSupported(t *testing.T) {
+func TestBatch3_RunCatalogVerify_SignaturesNoTrustedRoot(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
 	old := catalogVerifySigs
 	defer func() { catalogVerifySigs = old }()
 	catalogVerifySigs = true
 
 	err := runCatalogVerify(nil, nil)
 	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "not supported")
+	assert.Contains(t, err.Error(), "Sigstore trusted root")
 }
 
 // ===========================================================================
@@ -714,12 +704,15 @@ func TestBatch3_WriteEnvFile_CreatesFile(t *testing.T) {
 		{Name: "SECRET_KEY", Value: "secret://vault/key", Secret: true},
 	}
 
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("PREFLIGHT_HOME", "")
+	t.Setenv("XDG_STATE_HOME", "")
+
 	err := WriteEnvFile(vars)
 	assert.NoError(t, err)
 
 	// Verify file exists
-	home, _ := os.UserHomeDir()
-	envPath := filepath.Join(home, ".preflight", "env.sh")
+	envPath := filepath.Join(profileEnvDir(), "env.sh")
 	data, err := os.ReadFile(envPath)
 	assert.NoError(t, err)
 	assert.Contains(t, string(data), "FOO")
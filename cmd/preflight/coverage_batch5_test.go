@@ -824,9 +824,10 @@ func TestBatch5_SaveHistoryEntry_And_LoadHistory(t *testing.T) {
 func TestBatch5_GetHistoryDir(t *testing.T) {
 	tmpDir := t.TempDir()
 	t.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_STATE_HOME", "")
 
 	dir := getHistoryDir()
-	assert.Contains(t, dir, ".preflight")
+	assert.Contains(t, dir, filepath.Join(".local", "state", "preflight"))
 	assert.Contains(t, dir, "history")
 }
 
@@ -1685,7 +1686,7 @@ func TestBatch5_SaveHistoryEntry_AutoGeneratesIDAndTimestamp(t *testing.T) {
 	err := SaveHistoryEntry(entry)
 	require.NoError(t, err)
 
-	histDir := filepath.Join(tmpDir, ".preflight", "history")
+	histDir := getHistoryDir()
 	files, err := os.ReadDir(histDir)
 	require.NoError(t, err)
 	assert.Len(t, files, 1)
@@ -254,7 +254,7 @@ func TestBatch6_WriteEnvFile(t *testing.T) {
 	err := WriteEnvFile(vars)
 	require.NoError(t, err)
 
-	data, err := os.ReadFile(filepath.Join(home, ".preflight", "env.sh"))
+	data, err := os.ReadFile(filepath.Join(profileEnvDir(), "env.sh"))
 	require.NoError(t, err)
 	content := string(data)
 
@@ -1480,7 +1480,7 @@ func TestBatch6_WriteEnvFile_SecretSkipped(t *testing.T) {
 	err := WriteEnvFile(vars)
 	require.NoError(t, err)
 
-	envPath := filepath.Join(tmpDir, ".preflight", "env.sh")
+	envPath := filepath.Join(profileEnvDir(), "env.sh")
 	data, err := os.ReadFile(envPath)
 	require.NoError(t, err)
 	content := string(data)
@@ -539,6 +539,7 @@ func TestCoverBoost_RunApply_PlanFailureReturnsError(t *testing.T) {
 
 //nolint:tparallel // modifies global apply flags
 func TestCoverBoost_RunApply_StepFailureReturnsError(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // apply records history
 	plan := execution.NewExecutionPlan()
 	step := newDummyStep("brew:install:wget")
 	plan.Add(execution.NewPlanEntry(step, compiler.StatusNeedsApply,
@@ -568,6 +569,7 @@ func TestCoverBoost_RunApply_StepFailureReturnsError(t *testing.T) {
 
 //nolint:tparallel // modifies global apply flags
 func TestCoverBoost_RunApply_ApplyErrorReturnsError(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // apply records history
 	plan := execution.NewExecutionPlan()
 	step := newDummyStep("brew:install:fd")
 	plan.Add(execution.NewPlanEntry(step, compiler.StatusNeedsApply,
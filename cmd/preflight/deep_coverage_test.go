@@ -2966,17 +2966,6 @@ func TestDeepCov_HandleCleanupAll_DryRun(t *testing.T) {
 	assert.Contains(t, output, "Would remove 1 package(s)")
 }
 
-// ---------------------------------------------------------------------------
-// verifyCatalogSignatures - placeholder function
-// ---------------------------------------------------------------------------
-
-func TestDeepCov_VerifyCatalogSignatures(t *testing.T) {
-	t.Parallel()
-	result := verifyCatalogSignatures(nil, nil)
-	assert.False(t, result.hasSignature)
-	assert.False(t, result.verified)
-}
-
 // ---------------------------------------------------------------------------
 // filterBySeverity
 // ---------------------------------------------------------------------------
@@ -3905,7 +3894,7 @@ func TestDeepCov_SaveHistoryEntry(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Verify file was created
-	histDir := filepath.Join(tmpDir, ".preflight", "history")
+	histDir := getHistoryDir()
 	files, err := os.ReadDir(histDir)
 	require.NoError(t, err)
 	assert.Len(t, files, 1)
@@ -3928,7 +3917,7 @@ func TestDeepCov_SaveHistoryEntry_WithID(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Verify content
-	data, err := os.ReadFile(filepath.Join(tmpDir, ".preflight", "history", "my-custom-id.json"))
+	data, err := os.ReadFile(filepath.Join(getHistoryDir(), "my-custom-id.json"))
 	require.NoError(t, err)
 	assert.Contains(t, string(data), "doctor --fix")
 	assert.Contains(t, string(data), "my-custom-id")
@@ -4740,8 +4729,9 @@ func (m *mockWatchPreflight) PrintPlan(_ *execution.Plan) {}
 func (m *mockWatchPreflight) Apply(_ context.Context, _ *execution.Plan, _ bool) ([]execution.StepResult, error) {
 	return nil, nil
 }
-func (m *mockWatchPreflight) PrintResults(_ []execution.StepResult)                {}
-func (m *mockWatchPreflight) WithMode(_ config.ReproducibilityMode) watchPreflight { return m }
+func (m *mockWatchPreflight) PrintResults(_ []execution.StepResult)                   {}
+func (m *mockWatchPreflight) WithMode(_ config.ReproducibilityMode) watchPreflight    { return m }
+func (m *mockWatchPreflight) ReloadChangedPlugins(context.Context) []app.PluginReload { return nil }
 
 // ---------------------------------------------------------------------------
 // profile.go: applyGitConfig
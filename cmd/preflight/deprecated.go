@@ -8,6 +8,7 @@ import (
 	"strings"
 	"text/tabwriter"
 
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
 	"github.com/felixgeelhaar/preflight/internal/domain/security"
 	"github.com/spf13/cobra"
 )
@@ -26,7 +27,7 @@ Checkers (in order of preference):
 Exit codes:
   0 - No deprecated packages found
   1 - Deprecated or disabled packages found
-  2 - Checker not available or check failed
+  3 - Checker not available or check failed
 
 Examples:
   preflight deprecated              # Check for deprecated packages
@@ -62,9 +63,9 @@ func runDeprecated(_ *cobra.Command, _ []string) error {
 		if deprecatedJSON {
 			outputDeprecatedJSON(nil, fmt.Errorf("no checkers available"))
 		} else {
-			fmt.Fprintf(os.Stderr, "Error: no deprecation checkers available. Install Homebrew.\n")
+			printError(config.NewProviderUnavailableError("Homebrew"))
 		}
-		os.Exit(2)
+		os.Exit(exitInternal)
 	}
 
 	// Configure options
@@ -84,7 +85,7 @@ func runDeprecated(_ *cobra.Command, _ []string) error {
 			} else {
 				fmt.Fprintf(os.Stderr, "Check failed (%s): %v\n", checker.Name(), err)
 			}
-			os.Exit(2)
+			os.Exit(exitInternal)
 		}
 
 		checkerName = checker.Name()
@@ -106,7 +107,7 @@ func runDeprecated(_ *cobra.Command, _ []string) error {
 
 	// Determine exit code
 	if len(result.Packages) > 0 {
-		os.Exit(1)
+		os.Exit(exitDrift)
 	}
 
 	return nil
@@ -7,7 +7,9 @@ import (
 
 	"github.com/felixgeelhaar/preflight/internal/adapters/command"
 	"github.com/felixgeelhaar/preflight/internal/adapters/github"
+	"github.com/felixgeelhaar/preflight/internal/app"
 	"github.com/felixgeelhaar/preflight/internal/domain/discover"
+	"github.com/felixgeelhaar/preflight/internal/offline"
 	"github.com/spf13/cobra"
 )
 
@@ -19,11 +21,24 @@ var discoverCmd = &cobra.Command{
 It searches GitHub for highly-starred dotfile repositories, analyzes their
 structure, and provides suggestions for your preflight configuration.
 
+With --from-history, it scans your shell history (zsh, bash and fish)
+instead for tools you use often but no layer of the target declares, and
+saves the layer additions that track them for review with
+'preflight patches'.
+
+With --adopt, it scans an existing dotfiles repository, or ~/.config, for
+the configs of tools such as tmux, starship, alacritty and nvim, and
+creates a layer for each that links the config where the tool reads it.
+Configs outside the preflight configuration are copied into its dotfiles
+directory first.
+
 Examples:
   preflight discover                     # Analyze top dotfile repos
   preflight discover --max-repos 100     # Analyze more repositories
   preflight discover --min-stars 50      # Only repos with 50+ stars
-  preflight discover --language shell    # Filter by language`,
+  preflight discover --language shell    # Filter by language
+  preflight discover --from-history      # Find tools from shell history
+  preflight discover --adopt ~/dotfiles  # Create layers for existing dotfiles`,
 	RunE: runDiscover,
 }
 
@@ -32,6 +47,15 @@ var (
 	discoverMinStars int
 	discoverLanguage string
 	discoverShowAll  bool
+
+	discoverFromHistory  bool
+	discoverHistoryFiles []string
+	discoverMinUses      int
+	discoverTarget       string
+	discoverSplitBy      string
+
+	discoverAdopt  string
+	discoverDryRun bool
 )
 
 func init() {
@@ -39,11 +63,29 @@ func init() {
 	discoverCmd.Flags().IntVar(&discoverMinStars, "min-stars", 10, "Minimum star count")
 	discoverCmd.Flags().StringVar(&discoverLanguage, "language", "", "Filter by language (e.g., shell, vim)")
 	discoverCmd.Flags().BoolVar(&discoverShowAll, "all", false, "Show all detected patterns")
+	discoverCmd.Flags().BoolVar(&discoverFromHistory, "from-history", false, "Find untracked tools in your shell history")
+	discoverCmd.Flags().StringSliceVar(&discoverHistoryFiles, "history-file", nil, "Shell history file to scan with --from-history (default: zsh, bash and fish history)")
+	discoverCmd.Flags().IntVar(&discoverMinUses, "min-uses", 5, "Minimum uses of a command with --from-history")
+	discoverCmd.Flags().StringVarP(&discoverTarget, "target", "t", "default", "Target to compare with --from-history or --adopt")
+	discoverCmd.Flags().StringVar(&discoverAdopt, "adopt", "", "Create layers for the tool configs in a dotfiles directory (or ~/.config)")
+	discoverCmd.Flags().BoolVar(&discoverDryRun, "dry-run", false, "Show the layers --adopt would create without writing them")
+	discoverCmd.Flags().StringVar(&discoverSplitBy, "split-by", string(app.SplitByCategory), fmt.Sprintf("Split strategy for picking layers with --from-history (%s)", strings.Join(app.ValidSplitStrategies(), ", ")))
 
 	rootCmd.AddCommand(discoverCmd)
 }
 
 func runDiscover(_ *cobra.Command, _ []string) error {
+	if discoverFromHistory {
+		return runDiscoverHistory()
+	}
+	if discoverAdopt != "" {
+		return runDiscoverAdopt()
+	}
+	if offline.Enabled() {
+		fmt.Println("GitHub discovery skipped (offline).")
+		fmt.Println("--from-history and --adopt work offline.")
+		return nil
+	}
 	ctx := context.Background()
 
 	// Create the GitHub source
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/app"
+)
+
+// runDiscoverAdopt creates layers for the tool configs in a dotfiles
+// directory after confirmation.
+func runDiscoverAdopt() error {
+	configPath := cfgFile
+	if configPath == "" {
+		configPath = "preflight.yaml"
+	}
+
+	adoption, err := app.PlanDotfileAdoption(configPath, discoverTarget, discoverAdopt)
+	if err != nil {
+		return fmt.Errorf("discovery failed: %w", err)
+	}
+	for _, skipped := range adoption.Skipped {
+		fmt.Printf("Skipping %s\n", skipped)
+	}
+	if len(adoption.Configs) == 0 {
+		fmt.Printf("No tool configs to adopt found in %s.\n", adoption.Dir)
+		return nil
+	}
+
+	fmt.Printf("Tool configs found in %s:\n\n", adoption.Dir)
+	for _, adopted := range adoption.Configs {
+		line := fmt.Sprintf("  %-10s %s → %s", adopted.Tool, adopted.Src, adopted.Dest)
+		if adopted.Copied(adoption.ConfigDir) {
+			line += fmt.Sprintf(" (copied from %s)", adopted.From)
+		}
+		fmt.Println(line)
+	}
+	fmt.Println()
+	for _, update := range adoption.Updates {
+		diff, err := app.PreviewLayerUpdate(update)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Create layer %s\n", update.Layer)
+		fmt.Println(diff)
+	}
+
+	if discoverDryRun {
+		fmt.Println("--dry-run: No changes made.")
+		return nil
+	}
+	if !yesFlag {
+		fmt.Printf("Create %d layer(s)? [y/N] ", len(adoption.Updates))
+		response, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		response = strings.TrimSpace(strings.ToLower(response))
+		if response != "y" && response != "yes" {
+			fmt.Println("No changes made.")
+			return nil
+		}
+	}
+
+	if err := app.AdoptDotfiles(context.Background(), adoption); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Created %d layer(s). Run 'preflight plan' to review the links; move existing files at their destinations aside before applying.\n", len(adoption.Updates))
+	return nil
+}
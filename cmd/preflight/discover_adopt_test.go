@@ -0,0 +1,40 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunDiscoverAdopt(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "preflight.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("targets:\n  default: []\n"), 0o644))
+	dotfiles := filepath.Join(dir, "dotfiles")
+	require.NoError(t, os.MkdirAll(filepath.Join(dotfiles, "nvim"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dotfiles, "nvim", "init.lua"), []byte("vim.o.number = true\n"), 0o644))
+
+	prevCfg, prevAdopt, prevTarget, prevDryRun, prevYes := cfgFile, discoverAdopt, discoverTarget, discoverDryRun, yesFlag
+	t.Cleanup(func() {
+		cfgFile, discoverAdopt, discoverTarget, discoverDryRun, yesFlag = prevCfg, prevAdopt, prevTarget, prevDryRun, prevYes
+	})
+	cfgFile, discoverAdopt, discoverTarget = configPath, dotfiles, "default"
+
+	discoverDryRun = true
+	output := captureStdout(t, func() {
+		require.NoError(t, runDiscover(nil, nil))
+	})
+	assert.Contains(t, output, "nvim       dotfiles/nvim → ~/.config/nvim")
+	assert.Contains(t, output, "--dry-run: No changes made.")
+	assert.NoFileExists(t, filepath.Join(dir, "layers", "nvim.yaml"))
+
+	discoverDryRun, yesFlag = false, true
+	output = captureStdout(t, func() {
+		require.NoError(t, runDiscover(nil, nil))
+	})
+	assert.Contains(t, output, "✓ Created 1 layer(s)")
+	assert.FileExists(t, filepath.Join(dir, "layers", "nvim.yaml"))
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"text/tabwriter"
+
+	"github.com/felixgeelhaar/preflight/internal/app"
+)
+
+// commandOnPath reports whether a command is installed.
+var commandOnPath = func(command string) bool {
+	_, err := exec.LookPath(command)
+	return err == nil
+}
+
+// runDiscoverHistory proposes layer additions for the tools the shell
+// history shows in use but no layer of the target declares.
+func runDiscoverHistory() error {
+	configPath := cfgFile
+	if configPath == "" {
+		configPath = "preflight.yaml"
+	}
+	strategy, err := app.ParseSplitStrategy(discoverSplitBy)
+	if err != nil {
+		return err
+	}
+
+	files := discoverHistoryFiles
+	if len(files) == 0 {
+		if files, err = app.DefaultShellHistoryFiles(); err != nil {
+			return err
+		}
+	}
+	counts, err := app.ReadShellHistory(files)
+	if err != nil {
+		return err
+	}
+
+	tools, updates, err := app.DiscoverHistoryTools(configPath, discoverTarget, counts, discoverMinUses, commandOnPath, strategy)
+	if err != nil {
+		return fmt.Errorf("discovery failed: %w", err)
+	}
+	if len(tools) == 0 {
+		// Drop the additions an earlier run saved
+		if _, err := savePendingPatches(app.PatchSourceHistory, configPath, discoverTarget, nil); err != nil {
+			return fmt.Errorf("failed to save pending patches: %w", err)
+		}
+		fmt.Printf("No untracked tools used %d or more times found in your shell history.\n", discoverMinUses)
+		return nil
+	}
+
+	fmt.Printf("Tools from your shell history not tracked by target %s:\n\n", discoverTarget)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "  COMMAND\tUSES\tPACKAGE")
+	for _, tool := range tools {
+		_, _ = fmt.Fprintf(w, "  %s\t%d\t%s %s\n", tool.Command, tool.Uses, tool.Provider, tool.Package)
+	}
+	_ = w.Flush()
+	fmt.Println()
+
+	for _, update := range updates {
+		diff, err := app.PreviewLayerUpdate(update)
+		if err != nil {
+			fmt.Printf("Skipping layer %s: %v\n", update.Layer, err)
+			continue
+		}
+		fmt.Println(update.Description())
+		fmt.Println(diff)
+	}
+
+	id, err := savePendingPatches(app.PatchSourceHistory, configPath, discoverTarget, updates)
+	if err != nil {
+		return fmt.Errorf("failed to save pending patches: %w", err)
+	}
+	if id != "" {
+		fmt.Printf("Saved %d layer update(s) for review. Apply them with 'preflight patches apply %s'.\n", len(updates), id)
+	}
+	return nil
+}
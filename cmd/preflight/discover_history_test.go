@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunDiscoverHistory(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "preflight.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("targets:\n  default: [base]\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "layers"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "layers", "base.yaml"), []byte("name: base\npackages:\n  brew:\n    formulae: [ripgrep]\n"), 0o644))
+	history := filepath.Join(dir, ".zsh_history")
+	require.NoError(t, os.WriteFile(history, []byte(": 1792166636:0;http :8080\n: 1792166637:0;http :8080\n: 1792166638:0;rg foo\n"), 0o600))
+
+	store := app.NewPatchStore(filepath.Join(dir, "patches"))
+	prevStore, prevOnPath := patchStore, commandOnPath
+	prevCfg, prevFiles, prevMinUses, prevTarget, prevSplit := cfgFile, discoverHistoryFiles, discoverMinUses, discoverTarget, discoverSplitBy
+	t.Cleanup(func() {
+		patchStore, commandOnPath = prevStore, prevOnPath
+		cfgFile, discoverHistoryFiles, discoverMinUses, discoverTarget, discoverSplitBy = prevCfg, prevFiles, prevMinUses, prevTarget, prevSplit
+	})
+	patchStore = func() (*app.PatchStore, error) { return store, nil }
+	commandOnPath = func(string) bool { return true }
+	cfgFile, discoverHistoryFiles, discoverTarget, discoverSplitBy = configPath, []string{history}, "default", string(app.SplitByCategory)
+
+	discoverMinUses = 2
+	output := captureStdout(t, func() {
+		require.NoError(t, runDiscoverHistory())
+	})
+	assert.Contains(t, output, "brew httpie")
+	assert.NotContains(t, output, "brew ripgrep", "rg is tracked already")
+
+	sets, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, sets, 1)
+	assert.Equal(t, app.PatchSourceHistory, sets[0].Source)
+	assert.Contains(t, output, "preflight patches apply "+sets[0].ID)
+
+	discoverMinUses = 5
+	output = captureStdout(t, func() {
+		require.NoError(t, runDiscoverHistory())
+	})
+	assert.Contains(t, output, "No untracked tools")
+	sets, err = store.List()
+	require.NoError(t, err)
+	assert.Empty(t, sets, "the earlier additions are dropped")
+}
@@ -1,14 +1,16 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/felixgeelhaar/preflight/internal/app"
-	"github.com/felixgeelhaar/preflight/internal/domain/config"
 	"github.com/felixgeelhaar/preflight/internal/telemetry"
 	"github.com/felixgeelhaar/preflight/internal/tui"
 	"github.com/spf13/cobra"
@@ -21,12 +23,28 @@ var doctorCmd = &cobra.Command{
 
 It detects drift (changes made outside of preflight) and can suggest fixes.
 
+With --update-config, doctor works the other way around: packages you
+installed by hand that no layer declares are added to the layer smart
+split puts them in (see --split-by), creating the layer if needed. Each
+layer's change is shown and applied on confirmation. When the
+configuration is a git repository, each layer is committed on its own,
+or all of them in one commit when sync.autocommit is enabled.
+
 Examples:
   preflight doctor                    # Check for drift
   preflight doctor --fix              # Auto-fix detected issues
   preflight doctor --verbose          # Show detailed output
-  preflight doctor --update-config    # Merge drift back into config
-  preflight doctor --update-config --dry-run  # Preview config changes`,
+  preflight doctor --update-config    # Track manually installed packages
+  preflight doctor --update-config --dry-run  # Preview config changes
+  preflight doctor --check-timeout 2m # Give slow providers more time
+
+Providers are checked concurrently. A provider whose checks take longer
+than --check-timeout is reported as timed out instead of holding up the run.
+
+Exit codes:
+  0 - No issues found (or all issues fixed)
+  1 - Issues remain
+  3 - Checks could not run`,
 	RunE: runDoctor,
 }
 
@@ -34,16 +52,26 @@ var (
 	doctorFix          bool
 	doctorVerbose      bool
 	doctorUpdateConfig bool
+	doctorSplitBy      string
 	doctorDryRun       bool
 	doctorQuiet        bool
+	doctorWait         bool
+	doctorParallel     int
+	doctorCheckTimeout time.Duration
+	doctorSummaryFile  string
 )
 
 func init() {
 	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "Automatically fix detected issues")
 	doctorCmd.Flags().BoolVarP(&doctorVerbose, "verbose", "v", false, "Show detailed output")
-	doctorCmd.Flags().BoolVar(&doctorUpdateConfig, "update-config", false, "Merge drift back into layer files")
+	doctorCmd.Flags().BoolVar(&doctorUpdateConfig, "update-config", false, "Add manually installed packages to layer files")
+	doctorCmd.Flags().StringVar(&doctorSplitBy, "split-by", string(app.SplitByCategory), fmt.Sprintf("Split strategy for picking layers with --update-config (%s)", strings.Join(app.ValidSplitStrategies(), ", ")))
 	doctorCmd.Flags().BoolVar(&doctorDryRun, "dry-run", false, "Show changes without writing (use with --update-config)")
 	doctorCmd.Flags().BoolVarP(&doctorQuiet, "quiet", "q", false, "Print results without TUI (for CI/scripts)")
+	doctorCmd.Flags().BoolVar(&doctorWait, "wait", false, "Wait for another running apply to finish before fixing")
+	doctorCmd.Flags().IntVar(&doctorParallel, "parallel", app.DefaultDoctorParallelism, "Number of providers to check at once")
+	doctorCmd.Flags().DurationVar(&doctorCheckTimeout, "check-timeout", app.DefaultDoctorCheckTimeout, "Maximum time for a provider's checks (0 for no limit)")
+	doctorCmd.Flags().StringVar(&doctorSummaryFile, "summary-file", "", "Write a summary to this file (JSON for .json, else markdown, e.g. $GITHUB_STEP_SUMMARY)")
 
 	rootCmd.AddCommand(doctorCmd)
 }
@@ -59,22 +87,43 @@ func runDoctor(_ *cobra.Command, _ []string) error {
 	}
 
 	// Create app instance
-	preflight := app.New(os.Stdout)
+	preflight := app.New(os.Stdout).WithRunLockWait(doctorWait)
+
+	strategy, err := app.ParseSplitStrategy(doctorSplitBy)
+	if err != nil {
+		return err
+	}
 
 	// Run doctor check
 	doctorOpts := app.NewDoctorOptions(configPath, "default").
 		WithVerbose(doctorVerbose).
 		WithUpdateConfig(doctorUpdateConfig).
-		WithDryRun(doctorDryRun)
+		WithSplitStrategy(strategy).
+		WithDryRun(doctorDryRun).
+		WithParallelism(doctorParallel).
+		WithCheckTimeout(doctorCheckTimeout)
+
+	summary := newRunSummary("doctor", "default")
+	defer saveSummary(doctorSummaryFile, summary)
 
 	appReport, err := preflight.Doctor(ctx, doctorOpts)
 	if err != nil {
+		summary.fail(err)
 		return fmt.Errorf("doctor check failed: %w", err)
 	}
+	summary.setDoctor(appReport)
 
 	// Quiet mode: print results without TUI
 	if doctorQuiet {
 		printDoctorQuiet(appReport)
+		if doctorUpdateConfig {
+			if err := updateConfigFromSystem(ctx, appReport); err != nil {
+				return err
+			}
+		}
+		if appReport.HasIssues() {
+			return withExitCode(exitDrift, nil)
+		}
 		return nil
 	}
 
@@ -96,40 +145,25 @@ func runDoctor(_ *cobra.Command, _ []string) error {
 	}
 
 	// Handle update-config if requested
-	if doctorUpdateConfig && appReport.HasPatches() {
-		if doctorDryRun {
-			fmt.Printf("\n--- Dry Run: Would apply %d config patches ---\n", appReport.PatchCount())
-			for layer, patches := range appReport.PatchesByLayer() {
-				fmt.Printf("\n%s:\n", layer)
-				for _, patch := range patches {
-					fmt.Printf("  %s\n", patch.Description())
-				}
-			}
-			return nil
-		}
-
-		// Apply patches using LayerWriter
-		writer := config.NewLayerWriter()
-		writerPatches := app.ConfigPatchesToWriterPatches(appReport.SuggestedPatches)
-		if err := writer.ApplyPatches(writerPatches); err != nil {
-			return fmt.Errorf("failed to apply config patches: %w", err)
-		}
-
-		fmt.Printf("✓ Applied %d patches to config.\n", appReport.PatchCount())
-		return nil
+	if doctorUpdateConfig {
+		return updateConfigFromSystem(ctx, appReport)
 	}
 
 	// Handle fix if requested
+	remaining := result.Issues
 	switch {
 	case doctorFix && appReport.FixableCount() > 0:
 		fixResult, err := preflight.Fix(ctx, appReport)
 		if err != nil {
+			summary.fail(err)
 			return fmt.Errorf("fix failed: %w", err)
 		}
+		summary.Fixed = fixResult.FixedCount()
 		fmt.Printf("Fixed %d of %d issues.\n", fixResult.FixedCount(), appReport.FixableCount())
 		if fixResult.RemainingCount() > 0 {
 			fmt.Printf("%d issues could not be automatically fixed.\n", fixResult.RemainingCount())
 		}
+		remaining -= fixResult.FixedCount()
 	case result.Issues == 0:
 		fmt.Println("No issues found. Your system is in sync.")
 		// First green doctor for the North Star metric (TTFSA).
@@ -140,11 +174,105 @@ func runDoctor(_ *cobra.Command, _ []string) error {
 		fmt.Printf("\n%d config patches suggested. Run 'preflight doctor --update-config' to apply.\n", appReport.PatchCount())
 	}
 
+	if remaining > 0 {
+		return withExitCode(exitDrift, nil)
+	}
+	return nil
+}
+
+// updateConfigFromSystem shows the layer updates that track manually
+// installed packages and applies each one the user confirms.
+func updateConfigFromSystem(ctx context.Context, report *app.DoctorReport) error {
+	fmt.Println()
+	if len(report.LayerUpdates) == 0 {
+		fmt.Println("No untracked packages found. Your config is in sync with the system.")
+		return nil
+	}
+	if doctorDryRun {
+		fmt.Printf("--- Dry Run: Would apply %d config patches ---\n\n", report.PatchCount())
+	}
+
+	// With sync.autocommit, the applied updates share one commit.
+	var autocommit *app.ConfigAutocommit
+	if !doctorDryRun {
+		var files []string
+		for _, update := range report.LayerUpdates {
+			files = append(files, update.Files()...)
+		}
+		autocommit = startAutocommit(ctx, report.ConfigPath, files...)
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	applied := 0
+	var pending []app.LayerUpdate
+	var descriptions []string
+	for _, update := range report.LayerUpdates {
+		diff, err := app.PreviewLayerUpdate(update)
+		if err != nil {
+			fmt.Printf("Skipping layer %s: %v\n", update.Layer, err)
+			continue
+		}
+		fmt.Println(update.Description())
+		fmt.Println(diff)
+		if doctorDryRun {
+			pending = append(pending, update)
+			continue
+		}
+
+		if !yesFlag {
+			fmt.Print("Apply this change? [y/N] ")
+			response, _ := reader.ReadString('\n')
+			response = strings.TrimSpace(strings.ToLower(response))
+			if response != "y" && response != "yes" {
+				pending = append(pending, update)
+				continue
+			}
+		}
+		if autocommit != nil {
+			if err := app.WriteLayerUpdate(update); err != nil {
+				return fmt.Errorf("failed to update layer %s: %w", update.Layer, err)
+			}
+			applied++
+			descriptions = append(descriptions, update.Description())
+			fmt.Printf("✓ Updated layer %s\n", update.Layer)
+			continue
+		}
+		committed, err := app.ApplyLayerUpdate(ctx, update)
+		if err != nil {
+			return fmt.Errorf("failed to update layer %s: %w", update.Layer, err)
+		}
+		applied++
+		if committed {
+			fmt.Printf("✓ Updated and committed layer %s\n", update.Layer)
+		} else {
+			fmt.Printf("✓ Updated layer %s\n", update.Layer)
+		}
+	}
+
+	if !doctorDryRun {
+		fmt.Printf("Applied %d of %d layer update(s).\n", applied, len(report.LayerUpdates))
+		finishAutocommit(ctx, autocommit, "doctor --update-config", report.Target, descriptions)
+	}
+
+	// Keep what was not applied for review with 'preflight patches'
+	id, err := savePendingPatches(app.PatchSourceDoctor, report.ConfigPath, report.Target, pending)
+	if err != nil {
+		return fmt.Errorf("failed to save pending patches: %w", err)
+	}
+	if id != "" {
+		fmt.Printf("Saved %d layer update(s) for later: preflight patches show %s\n", len(pending), id)
+	}
 	return nil
 }
 
 // printDoctorQuiet prints the doctor report without TUI.
 func printDoctorQuiet(report *app.DoctorReport) {
+	printDoctorPlain(report, "preflight doctor --fix")
+}
+
+// printDoctorPlain prints the doctor report without TUI, pointing at
+// fixCommand for the fixable issues.
+func printDoctorPlain(report *app.DoctorReport, fixCommand string) {
 	fmt.Println("Doctor Report")
 	fmt.Println("=============")
 	fmt.Println()
@@ -174,8 +302,12 @@ func printDoctorQuiet(report *app.DoctorReport) {
 		}
 	}
 
+	if len(report.TimedOut) > 0 {
+		fmt.Printf("\nTimed out: %s (not fully checked)\n", strings.Join(report.TimedOut, ", "))
+	}
+
 	if report.FixableCount() > 0 {
-		fmt.Printf("\n%d issue(s) can be auto-fixed with 'preflight doctor --fix'\n", report.FixableCount())
+		fmt.Printf("\n%d issue(s) can be auto-fixed with '%s'\n", report.FixableCount(), fixCommand)
 	}
 
 	if report.HasPatches() {
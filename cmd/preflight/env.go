@@ -1,7 +1,6 @@
 package main
 
 import (
-	"bufio"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -28,8 +27,8 @@ shell configuration. This provides:
   - Target-specific variables (work target vs personal target)
   - Secure handling (secrets via references, not plaintext)
 
-Variables are written to ~/.preflight/env.sh which should be sourced
-in your shell configuration.
+Variables of the active profile are loaded into your shell by the shell
+hook; see 'preflight hook'.
 
 Examples:
   preflight env list                    # List all variables
@@ -38,6 +37,7 @@ Examples:
   preflight env get EDITOR              # Get a variable
   preflight env unset EDITOR            # Remove a variable
   preflight env export                  # Generate shell export script
+  preflight env direnv                  # Generate project .envrc files
   preflight env diff                    # Show env var differences between targets`,
 	RunE: runEnvList,
 }
@@ -121,7 +121,7 @@ func runEnvList(_ *cobra.Command, _ []string) error {
 	config, err := preflight.LoadMergedConfig(ctx, envConfigPath, envTarget)
 	if err != nil {
 		return &pfconfig.UserError{
-			Code:       "CONFIG_LOAD_FAILED",
+			Code:       pfconfig.ErrCodeConfigLoadFailed,
 			Message:    "could not load configuration for env list",
 			Suggestion: "Run 'preflight validate' to verify the config, or pass --config <path> if preflight.yaml lives elsewhere.",
 			Underlying: err,
@@ -206,7 +206,7 @@ func runEnvSet(_ *cobra.Command, args []string) error {
 	data, err := yaml.Marshal(layerData)
 	if err != nil {
 		return &pfconfig.UserError{
-			Code:       "MARSHAL_FAILED",
+			Code:       pfconfig.ErrCodeMarshalFailed,
 			Message:    fmt.Sprintf("failed to marshal layer %s as YAML", layer),
 			Suggestion: "This usually indicates a programming bug. File a report with the variable name and value.",
 			Underlying: err,
@@ -215,7 +215,7 @@ func runEnvSet(_ *cobra.Command, args []string) error {
 
 	if err := os.MkdirAll(filepath.Dir(layerPath), 0o755); err != nil {
 		return &pfconfig.UserError{
-			Code:       "MKDIR_FAILED",
+			Code:       pfconfig.ErrCodeMkdirFailed,
 			Message:    fmt.Sprintf("failed to create layers directory at %s", filepath.Dir(layerPath)),
 			Suggestion: "Check directory permissions on the parent path.",
 			Underlying: err,
@@ -224,7 +224,7 @@ func runEnvSet(_ *cobra.Command, args []string) error {
 
 	if err := os.WriteFile(layerPath, data, 0o644); err != nil {
 		return &pfconfig.UserError{
-			Code:       "WRITE_FAILED",
+			Code:       pfconfig.ErrCodeWriteFailed,
 			Message:    fmt.Sprintf("failed to write layer %s", layerPath),
 			Suggestion: "Check file permissions on the layer file and disk space.",
 			Underlying: err,
@@ -256,7 +256,7 @@ func runEnvGet(_ *cobra.Command, args []string) error {
 	}
 
 	return &pfconfig.UserError{
-		Code:       "ENV_VAR_NOT_FOUND",
+		Code:       pfconfig.ErrCodeEnvVarNotFound,
 		Message:    fmt.Sprintf("variable '%s' not found in any layer", name),
 		Suggestion: fmt.Sprintf("List defined variables with 'preflight env list', or set this one with 'preflight env set %s <value>'.", name),
 	}
@@ -276,7 +276,7 @@ func runEnvUnset(_ *cobra.Command, args []string) error {
 	data, err := os.ReadFile(layerPath)
 	if err != nil {
 		return &pfconfig.UserError{
-			Code:       "LAYER_NOT_FOUND",
+			Code:       pfconfig.ErrCodeLayerNotFound,
 			Message:    fmt.Sprintf("layer not found: %s", layerPath),
 			Suggestion: "Pass --layer with an existing layer name, or run 'preflight env list' to see what is defined.",
 			Underlying: err,
@@ -297,7 +297,7 @@ func runEnvUnset(_ *cobra.Command, args []string) error {
 	env, ok := layerData["env"].(map[string]interface{})
 	if !ok {
 		return &pfconfig.UserError{
-			Code:       "ENV_SECTION_MISSING",
+			Code:       pfconfig.ErrCodeEnvSectionMissing,
 			Message:    fmt.Sprintf("no env section in layer %s", layer),
 			Suggestion: fmt.Sprintf("Add an env section with 'preflight env set <NAME> <VALUE> --layer %s' first.", layer),
 		}
@@ -305,7 +305,7 @@ func runEnvUnset(_ *cobra.Command, args []string) error {
 
 	if _, exists := env[name]; !exists {
 		return &pfconfig.UserError{
-			Code:       "ENV_VAR_NOT_FOUND",
+			Code:       pfconfig.ErrCodeEnvVarNotFound,
 			Message:    fmt.Sprintf("variable '%s' not found in layer %s", name, layer),
 			Suggestion: fmt.Sprintf("Run 'preflight env list --layer %s' to see what is defined there.", layer),
 		}
@@ -317,7 +317,7 @@ func runEnvUnset(_ *cobra.Command, args []string) error {
 	data, err = yaml.Marshal(layerData)
 	if err != nil {
 		return &pfconfig.UserError{
-			Code:       "MARSHAL_FAILED",
+			Code:       pfconfig.ErrCodeMarshalFailed,
 			Message:    fmt.Sprintf("failed to marshal layer %s as YAML", layer),
 			Suggestion: "This usually indicates a programming bug. File a report including the variable name being removed.",
 			Underlying: err,
@@ -326,7 +326,7 @@ func runEnvUnset(_ *cobra.Command, args []string) error {
 
 	if err := os.WriteFile(layerPath, data, 0o644); err != nil {
 		return &pfconfig.UserError{
-			Code:       "WRITE_FAILED",
+			Code:       pfconfig.ErrCodeWriteFailed,
 			Message:    fmt.Sprintf("failed to write layer %s", layerPath),
 			Suggestion: "Check file permissions on the layer file and disk space.",
 			Underlying: err,
@@ -347,6 +347,7 @@ func runEnvExport(_ *cobra.Command, _ []string) error {
 	}
 
 	vars := extractEnvVars(config)
+	path := extractPathConfig(config)
 
 	// Sort by name
 	sort.Slice(vars, func(i, j int) bool {
@@ -363,18 +364,25 @@ func runEnvExport(_ *cobra.Command, _ []string) error {
 			}
 			fmt.Printf("set -gx %s %q\n", v.Name, v.Value)
 		}
+		if !path.IsZero() {
+			fmt.Println("set -gx PATH" + fishList(path.Prepend) + " $PATH" + fishList(path.Append))
+		}
 	case "bash", "zsh":
 		fmt.Println("# Generated by preflight env export")
-		fmt.Println("# Add to ~/.bashrc or ~/.zshrc: source ~/.preflight/env.sh")
+		fmt.Println("# Add to ~/.bashrc or ~/.zshrc: source ~/.local/state/preflight/env.sh")
 		for _, v := range vars {
 			if v.Secret {
 				continue
 			}
 			fmt.Printf("export %s=%q\n", v.Name, v.Value)
 		}
+		if !path.IsZero() {
+			entries := append(append(append([]string{}, path.Prepend...), "$PATH"), path.Append...)
+			fmt.Printf("export PATH=%q\n", strings.Join(entries, ":"))
+		}
 	default:
 		return &pfconfig.UserError{
-			Code:       "UNSUPPORTED_SHELL",
+			Code:       pfconfig.ErrCodeUnsupportedShell,
 			Message:    fmt.Sprintf("unsupported shell: %s", envShell),
 			Suggestion: "Use --shell with one of: bash, zsh, fish.",
 		}
@@ -393,7 +401,7 @@ func runEnvDiff(_ *cobra.Command, args []string) error {
 	config1, err := preflight.LoadMergedConfig(ctx, envConfigPath, target1)
 	if err != nil {
 		return &pfconfig.UserError{
-			Code:       "TARGET_LOAD_FAILED",
+			Code:       pfconfig.ErrCodeTargetLoadFailed,
 			Message:    fmt.Sprintf("failed to load target %s", target1),
 			Suggestion: "Check the target name with 'preflight validate' and ensure it is defined in preflight.yaml.",
 			Underlying: err,
@@ -403,7 +411,7 @@ func runEnvDiff(_ *cobra.Command, args []string) error {
 	config2, err := preflight.LoadMergedConfig(ctx, envConfigPath, target2)
 	if err != nil {
 		return &pfconfig.UserError{
-			Code:       "TARGET_LOAD_FAILED",
+			Code:       pfconfig.ErrCodeTargetLoadFailed,
 			Message:    fmt.Sprintf("failed to load target %s", target2),
 			Suggestion: "Check the target name with 'preflight validate' and ensure it is defined in preflight.yaml.",
 			Underlying: err,
@@ -470,6 +478,27 @@ func extractEnvVars(config map[string]interface{}) []EnvVar {
 	return vars
 }
 
+// extractPathConfig returns the merged PATH entries with ~ expanded.
+func extractPathConfig(config map[string]interface{}) pfconfig.PathConfig {
+	var path pfconfig.PathConfig
+	section, ok := config["path"].(map[string]interface{})
+	if !ok {
+		return path
+	}
+	entries := func(key string) []string {
+		list, _ := section[key].([]interface{})
+		out := make([]string, 0, len(list))
+		for _, entry := range list {
+			out = append(out, fmt.Sprintf("%v", entry))
+		}
+		return out
+	}
+	path.Prepend = entries("prepend")
+	path.Append = entries("append")
+	home, _ := os.UserHomeDir()
+	return path.Expand(home)
+}
+
 func extractEnvVarsMap(config map[string]interface{}) map[string]string {
 	result := make(map[string]string)
 
@@ -482,30 +511,8 @@ func extractEnvVarsMap(config map[string]interface{}) map[string]string {
 	return result
 }
 
-// WriteEnvFile writes environment variables to ~/.preflight/env.sh
+// WriteEnvFile writes environment variables to env.sh and env.fish in the
+// preflight state directory, which are loaded by 'preflight hook'.
 func WriteEnvFile(vars []EnvVar) error {
-	home, _ := os.UserHomeDir()
-	envPath := filepath.Join(home, ".preflight", "env.sh")
-
-	if err := os.MkdirAll(filepath.Dir(envPath), 0o755); err != nil {
-		return err
-	}
-
-	f, err := os.Create(envPath)
-	if err != nil {
-		return err
-	}
-	defer func() { _ = f.Close() }()
-
-	w := bufio.NewWriter(f)
-	_, _ = w.WriteString("# Generated by preflight - do not edit manually\n\n")
-
-	for _, v := range vars {
-		if v.Secret {
-			continue
-		}
-		_, _ = fmt.Fprintf(w, "export %s=%q\n", v.Name, v.Value)
-	}
-
-	return w.Flush()
+	return writeProfileEnv(profileEnvDir(), profileEnv{Vars: vars})
 }
@@ -439,7 +439,7 @@ func TestWriteEnvFile_ContentFormat(t *testing.T) {
 	err := WriteEnvFile(vars)
 	require.NoError(t, err)
 
-	envPath := filepath.Join(tmpDir, ".preflight", "env.sh")
+	envPath := filepath.Join(profileEnvDir(), "env.sh")
 	data, err := os.ReadFile(envPath)
 	require.NoError(t, err)
 	content := string(data)
@@ -465,7 +465,7 @@ func TestWriteEnvFile_EmptyVars(t *testing.T) {
 	err := WriteEnvFile([]EnvVar{})
 	require.NoError(t, err)
 
-	envPath := filepath.Join(tmpDir, ".preflight", "env.sh")
+	envPath := filepath.Join(profileEnvDir(), "env.sh")
 	data, err := os.ReadFile(envPath)
 	require.NoError(t, err)
 	content := string(data)
@@ -488,7 +488,7 @@ func TestWriteEnvFile_AllSecretsExcluded(t *testing.T) {
 	err := WriteEnvFile(vars)
 	require.NoError(t, err)
 
-	envPath := filepath.Join(tmpDir, ".preflight", "env.sh")
+	envPath := filepath.Join(profileEnvDir(), "env.sh")
 	data, err := os.ReadFile(envPath)
 	require.NoError(t, err)
 	content := string(data)
@@ -508,7 +508,7 @@ func TestWriteEnvFile_CreatesDirectoryStructure(t *testing.T) {
 	require.NoError(t, err)
 
 	// Verify directory was created
-	preflightDir := filepath.Join(tmpDir, ".preflight")
+	preflightDir := profileEnvDir()
 	info, err := os.Stat(preflightDir)
 	require.NoError(t, err)
 	assert.True(t, info.IsDir())
@@ -642,3 +642,33 @@ func TestRunEnvUnset_InvalidYAMLInExistingLayer(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to parse layer")
 }
+
+//nolint:tparallel // modifies global envConfigPath, envTarget, envShell
+func TestRunEnvExport_Path(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "preflight.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("targets:\n  default: [base]\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "layers"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "layers", "base.yaml"),
+		[]byte("name: base\npath:\n  prepend: [~/.local/bin, /opt/homebrew/bin]\n  append: [/opt/tools]\n"), 0o644))
+
+	savedConfigPath, savedTarget, savedShell := envConfigPath, envTarget, envShell
+	t.Cleanup(func() { envConfigPath, envTarget, envShell = savedConfigPath, savedTarget, savedShell })
+	envConfigPath, envTarget = configPath, "default"
+
+	envShell = "bash"
+	output := captureStdout(t, func() { require.NoError(t, runEnvExport(nil, nil)) })
+	assert.Contains(t, output, `export PATH="`+filepath.Join(home, ".local/bin")+`:/opt/homebrew/bin:$PATH:/opt/tools"`)
+
+	envShell = "fish"
+	output = captureStdout(t, func() { require.NoError(t, runEnvExport(nil, nil)) })
+	assert.Contains(t, output, "set -gx PATH '"+filepath.Join(home, ".local/bin")+"' '/opt/homebrew/bin' $PATH '/opt/tools'")
+}
+
+func TestExtractPathConfig_NoSection(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, extractPathConfig(map[string]interface{}{}).IsZero())
+}
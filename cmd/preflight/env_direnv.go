@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/felixgeelhaar/preflight/internal/app"
+	pfconfig "github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/spf13/cobra"
+)
+
+var envDirenvCmd = &cobra.Command{
+	Use:   "direnv",
+	Short: "Generate .envrc files for project directories",
+	Long: `Generate .envrc files for the project directories declared in the
+direnv section of your layers:
+
+  direnv:
+    dirs:
+      - path: ~/work/api
+        env:
+          DATABASE_URL: postgres://localhost/api
+          API_TOKEN: secret://1password/Work/api-token
+
+Secret references are resolved by 'preflight secrets get' when direnv loads
+the file, so secret values are never written to disk. direnv blocks a
+changed .envrc until it is allowed again; pass --allow to run
+'direnv allow' for each written file.
+
+Examples:
+  preflight env direnv                  # Write all .envrc files
+  preflight env direnv --check          # Fail if any file is out of date
+  preflight env direnv --allow          # Write and allow them`,
+	RunE: runEnvDirenv,
+}
+
+var (
+	envDirenvCheck bool
+	envDirenvForce bool
+	envDirenvAllow bool
+)
+
+// runDirenvAllow trusts a directory's .envrc; tests replace it.
+var runDirenvAllow = func(dir string) error {
+	return exec.Command("direnv", "allow", dir).Run()
+}
+
+func init() {
+	envCmd.AddCommand(envDirenvCmd)
+	envDirenvCmd.Flags().BoolVar(&envDirenvCheck, "check", false, "Report out-of-date files without writing them")
+	envDirenvCmd.Flags().BoolVar(&envDirenvForce, "force", false, "Replace .envrc files not generated by preflight")
+	envDirenvCmd.Flags().BoolVar(&envDirenvAllow, "allow", false, "Run 'direnv allow' for each written file")
+}
+
+func runEnvDirenv(_ *cobra.Command, _ []string) error {
+	files, err := app.PlanDirenvFiles(envConfigPath, envTarget)
+	if err != nil {
+		return &pfconfig.UserError{
+			Code:       pfconfig.ErrCodeConfigLoadFailed,
+			Message:    "could not load configuration for env direnv",
+			Suggestion: "Run 'preflight validate' to verify the config, or pass --config <path> if preflight.yaml lives elsewhere.",
+			Underlying: err,
+		}
+	}
+	if len(files) == 0 {
+		fmt.Println("No direnv directories declared.")
+		return nil
+	}
+
+	var outdated, failed int
+	for _, file := range files {
+		if file.Status == app.DirenvCurrent {
+			fmt.Printf("  ✓ %s (up to date)\n", file.Path)
+			continue
+		}
+		if envDirenvCheck {
+			outdated++
+			fmt.Printf("  ✗ %s (%s)\n", file.Path, file.Status)
+			continue
+		}
+		if err := app.WriteDirenvFile(file, envDirenvForce); err != nil {
+			failed++
+			fmt.Printf("  ✗ %s: %v\n", file.Path, err)
+			continue
+		}
+		fmt.Printf("  ✓ %s (written)\n", file.Path)
+		if envDirenvAllow {
+			if err := runDirenvAllow(file.Dir); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: direnv allow %s failed: %v\n", file.Dir, err)
+			}
+		}
+	}
+
+	switch {
+	case outdated > 0:
+		return fmt.Errorf("%d .envrc file(s) out of date; run 'preflight env direnv'", outdated)
+	case failed > 0:
+		return fmt.Errorf("%d .envrc file(s) could not be written", failed)
+	}
+	if _, err := exec.LookPath("direnv"); err != nil {
+		fmt.Fprintln(os.Stderr, "Warning: direnv is not installed; the files load once it is installed and hooked into your shell.")
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:tparallel // modifies global env flags and runDirenvAllow
+func TestRunEnvDirenv(t *testing.T) {
+	dir := t.TempDir()
+	project := t.TempDir()
+	configPath := filepath.Join(dir, "preflight.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("targets:\n  default: [base]\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "layers"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "layers", "base.yaml"),
+		[]byte("name: base\ndirenv:\n  dirs:\n    - path: "+project+"\n      env:\n        PORT: \"8080\"\n"), 0o644))
+
+	savedConfig, savedTarget := envConfigPath, envTarget
+	savedCheck, savedForce, savedAllow, savedRun := envDirenvCheck, envDirenvForce, envDirenvAllow, runDirenvAllow
+	t.Cleanup(func() {
+		envConfigPath, envTarget = savedConfig, savedTarget
+		envDirenvCheck, envDirenvForce, envDirenvAllow, runDirenvAllow = savedCheck, savedForce, savedAllow, savedRun
+	})
+	envConfigPath, envTarget = configPath, "default"
+	var allowed []string
+	runDirenvAllow = func(dir string) error {
+		allowed = append(allowed, dir)
+		return nil
+	}
+
+	envDirenvCheck = true
+	var err error
+	output := captureStdout(t, func() { err = runEnvDirenv(nil, nil) })
+	require.ErrorContains(t, err, "1 .envrc file(s) out of date")
+	assert.Contains(t, output, "(missing)")
+
+	envDirenvCheck, envDirenvAllow = false, true
+	output = captureStdout(t, func() { err = runEnvDirenv(nil, nil) })
+	require.NoError(t, err)
+	assert.Contains(t, output, "(written)")
+	assert.Equal(t, []string{project}, allowed)
+
+	data, err := os.ReadFile(filepath.Join(project, ".envrc"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "export PORT='8080'")
+
+	envDirenvCheck = true
+	output = captureStdout(t, func() { err = runEnvDirenv(nil, nil) })
+	require.NoError(t, err)
+	assert.Contains(t, output, "(up to date)")
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/spf13/cobra"
+)
+
+var errorsCmd = &cobra.Command{
+	Use:   "errors",
+	Short: "List error codes and exit codes",
+	Long: `List the stable error codes preflight reports and its exit codes.
+
+Errors preflight can explain carry a code such as PF2003, printed with the
+error. Codes never change meaning, so scripts and CI can match on them.
+
+Exit codes:
+  0  success; nothing to report
+  1  drift: the machine or config is out of line (doctor issues, outdated
+     or vulnerable packages, failed checks)
+  2  policy violation
+  3  error: the command could not run to completion
+
+Examples:
+  preflight errors                  # List all error codes
+  preflight errors explain PF2003   # Explain one code`,
+	Args: cobra.NoArgs,
+	RunE: runErrorsList,
+}
+
+var errorsExplainCmd = &cobra.Command{
+	Use:   "explain <code>",
+	Short: "Explain an error code",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runErrorsExplain,
+}
+
+var errorsJSON bool
+
+func init() {
+	errorsCmd.PersistentFlags().BoolVar(&errorsJSON, "json", false, "Output as JSON")
+
+	errorsCmd.AddCommand(errorsExplainCmd)
+	rootCmd.AddCommand(errorsCmd)
+}
+
+func runErrorsList(_ *cobra.Command, _ []string) error {
+	codes := config.ErrorCodes()
+	if errorsJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(codes)
+	}
+
+	for _, info := range codes {
+		fmt.Printf("%-8s %s\n", info.ID, info.Title)
+	}
+	fmt.Println("\nRun 'preflight errors explain <code>' for details.")
+	return nil
+}
+
+func runErrorsExplain(_ *cobra.Command, args []string) error {
+	info, ok := config.LookupErrorCode(args[0])
+	if !ok {
+		return fmt.Errorf("unknown error code %q; run 'preflight errors' to list them", args[0])
+	}
+	if errorsJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(info)
+	}
+
+	fmt.Printf("%s: %s\n\n%s\n", info.ID, info.Title, info.Explanation)
+	return nil
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunErrorsExplain(t *testing.T) {
+	output := captureStdout(t, func() {
+		require.NoError(t, runErrorsExplain(nil, []string{"pf2003"}))
+	})
+	assert.Contains(t, output, "PF2003: Provider unavailable")
+	assert.Contains(t, output, "not installed")
+
+	err := runErrorsExplain(nil, []string{"PF0000"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown error code")
+}
+
+func TestRunErrorsList(t *testing.T) {
+	output := captureStdout(t, func() {
+		require.NoError(t, runErrorsList(nil, nil))
+	})
+	assert.Contains(t, output, "PF1001")
+	assert.Contains(t, output, "PF4001")
+}
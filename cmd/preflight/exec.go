@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/felixgeelhaar/preflight/internal/app"
+	pfconfig "github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/provider/runtime"
+	"github.com/spf13/cobra"
+)
+
+var execCmd = &cobra.Command{
+	Use:   "exec [--profile <name>] -- <command> [args...]",
+	Short: "Run a command with a profile's environment",
+	Long: `Run a command with the environment of a profile.
+
+The command gets the profile's environment variables and PATH entries on
+top of the current environment, without switching the active profile.
+Secret references (secret://...) are resolved in memory and passed only to
+the command; they are never written to disk. Tool shims (runtime.shims)
+run the versions the profile declares.
+
+Without --profile the active profile is used. preflight exits with the
+exit code of the command.
+
+Examples:
+  preflight exec --profile work -- terraform plan
+  preflight exec --profile personal -- gh repo list
+  preflight exec -- env`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runExec,
+}
+
+var (
+	execConfigPath string
+	execProfile    string
+)
+
+func init() {
+	execCmd.Flags().StringVarP(&execConfigPath, "config", "c", "preflight.yaml", "Path to preflight.yaml")
+	execCmd.Flags().StringVarP(&execProfile, "profile", "p", "", "Profile to use (default: the active profile)")
+	// Flags after the command belong to the command
+	execCmd.Flags().SetInterspersed(false)
+	rootCmd.AddCommand(execCmd)
+}
+
+func runExec(_ *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	profileName := execProfile
+	if profileName == "" {
+		profileName = getCurrentProfile()
+	}
+	if profileName == "" {
+		profileName = "default"
+	}
+
+	config, err := app.New(os.Stderr).LoadMergedConfig(ctx, execConfigPath, profileTarget(profileName))
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	env := profileEnvFromConfig(profileName, config)
+	env.Vars = append(env.Vars, shimVersionVars(config)...)
+	vars, err := execEnv(os.Environ(), env, resolveSecret)
+	if err != nil {
+		return err
+	}
+	return runWithEnv(ctx, args, vars)
+}
+
+// shimVersionVars returns the variables that make the tool shims run the
+// versions of this profile rather than the active one.
+func shimVersionVars(config map[string]interface{}) []EnvVar {
+	section, _ := config["runtime"].(map[string]interface{})
+	cfg, err := runtime.ParseConfig(section)
+	if err != nil {
+		return nil
+	}
+	var vars []EnvVar
+	for name, value := range cfg.VersionEnv() {
+		vars = append(vars, EnvVar{Name: name, Value: value})
+	}
+	return vars
+}
+
+// execEnv returns base with the profile's variables set, secret references
+// resolved, and its PATH entries added.
+func execEnv(base []string, env profileEnv, resolve func(backend, key string) (string, error)) ([]string, error) {
+	overrides := map[string]string{}
+	if env.Profile != "" {
+		overrides["PREFLIGHT_PROFILE"] = env.Profile
+	}
+	for _, v := range env.Vars {
+		value := v.Value
+		if v.Secret {
+			backend, key, ok := strings.Cut(strings.TrimPrefix(v.Value, "secret://"), "/")
+			if !ok {
+				return nil, &pfconfig.UserError{
+					Code:       pfconfig.ErrCodeValidationFailed,
+					Message:    fmt.Sprintf("invalid secret reference for %s: %s", v.Name, v.Value),
+					Suggestion: "Use secret://<backend>/<key>, for example secret://1password/vault/item/field.",
+				}
+			}
+			resolved, err := resolve(backend, key)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve secret for %s: %w", v.Name, err)
+			}
+			value = resolved
+		}
+		overrides[v.Name] = value
+	}
+
+	path := lookupEnv(base, "PATH")
+	if !env.Path.IsZero() {
+		entries := append([]string{}, env.Path.Prepend...)
+		if path != "" {
+			entries = append(entries, path)
+		}
+		entries = append(entries, env.Path.Append...)
+		overrides["PATH"] = strings.Join(entries, string(os.PathListSeparator))
+	}
+
+	result := make([]string, 0, len(base)+len(overrides))
+	for _, kv := range base {
+		name, _, _ := strings.Cut(kv, "=")
+		if _, ok := overrides[name]; ok {
+			continue
+		}
+		result = append(result, kv)
+	}
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		result = append(result, name+"="+overrides[name])
+	}
+	return result, nil
+}
+
+// lookupEnv returns the value of name in env, the last one winning.
+func lookupEnv(env []string, name string) string {
+	value := ""
+	for _, kv := range env {
+		if k, v, ok := strings.Cut(kv, "="); ok && k == name {
+			value = v
+		}
+	}
+	return value
+}
+
+// runWithEnv runs args with env and the standard streams attached. Signals
+// are forwarded to the command, and its exit code becomes preflight's.
+func runWithEnv(ctx context.Context, args []string, env []string) error {
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Env = env
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to run %s: %w", args[0], err)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				_ = cmd.Process.Signal(sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	err := cmd.Wait()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		code := exitErr.ExitCode()
+		if code < 0 {
+			// Killed by a signal
+			code = exitInternal
+		}
+		return withExitCode(code, nil)
+	}
+	return err
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"runtime"
+	"testing"
+
+	pfconfig "github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecEnv(t *testing.T) {
+	t.Parallel()
+
+	sep := string(os.PathListSeparator)
+	env := profileEnv{
+		Profile: "work",
+		Vars: []EnvVar{
+			{Name: "AWS_PROFILE", Value: "acme"},
+			{Name: "GITHUB_TOKEN", Value: "secret://1password/Work/GitHub/token", Secret: true},
+		},
+		Path: pfconfig.PathConfig{Prepend: []string{"/opt/acme/bin"}, Append: []string{"/opt/tail"}},
+	}
+	var resolved []string
+	resolve := func(backend, key string) (string, error) {
+		resolved = append(resolved, backend+" "+key)
+		return "ghp_123", nil
+	}
+
+	got, err := execEnv([]string{"HOME=/home/jane", "AWS_PROFILE=personal", "PATH=/usr/bin"}, env, resolve)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"HOME=/home/jane",
+		"AWS_PROFILE=acme",
+		"GITHUB_TOKEN=ghp_123",
+		"PATH=/opt/acme/bin" + sep + "/usr/bin" + sep + "/opt/tail",
+		"PREFLIGHT_PROFILE=work",
+	}, got)
+	assert.Equal(t, []string{"1password Work/GitHub/token"}, resolved)
+}
+
+func TestExecEnv_SecretErrors(t *testing.T) {
+	t.Parallel()
+
+	failing := func(string, string) (string, error) { return "", errors.New("op: not signed in") }
+	_, err := execEnv(nil, profileEnv{Vars: []EnvVar{
+		{Name: "TOKEN", Value: "secret://1password/Work/token", Secret: true},
+	}}, failing)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "TOKEN")
+	assert.Contains(t, err.Error(), "not signed in")
+
+	_, err = execEnv(nil, profileEnv{Vars: []EnvVar{
+		{Name: "TOKEN", Value: "secret://keychain", Secret: true},
+	}}, failing)
+	var userErr *pfconfig.UserError
+	require.ErrorAs(t, err, &userErr)
+}
+
+func TestRunWithEnv(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	err := runWithEnv(context.Background(), []string{"sh", "-c", `test "$TOKEN" = s3cret`}, []string{"TOKEN=s3cret"})
+	require.NoError(t, err)
+
+	err = runWithEnv(context.Background(), []string{"sh", "-c", "exit 4"}, nil)
+	assert.Equal(t, 4, exitCodeFor(err))
+	assert.True(t, silentExit(err))
+
+	err = runWithEnv(context.Background(), []string{"preflight-no-such-command"}, nil)
+	require.Error(t, err)
+	assert.Equal(t, exitInternal, exitCodeFor(err))
+}
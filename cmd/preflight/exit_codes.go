@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Exit codes are a stable contract for scripts and CI gating.
+const (
+	// exitOK means the command succeeded and found nothing to report.
+	exitOK = 0
+	// exitDrift means the command ran but found the machine or config out
+	// of line with what is expected: drift, outdated or vulnerable
+	// packages, failed checks.
+	exitDrift = 1
+	// exitPolicyViolation means the configuration breaks a policy rule.
+	exitPolicyViolation = 2
+	// exitInternal means the command could not run to completion.
+	exitInternal = 3
+)
+
+// exitCodeError ends a command with a specific exit code.
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+// withExitCode returns an error that makes preflight exit with code. A nil
+// err exits without printing an error message, for commands that already
+// reported their findings.
+func withExitCode(code int, err error) error {
+	return &exitCodeError{code: code, err: err}
+}
+
+func (e *exitCodeError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("exit status %d", e.code)
+	}
+	return e.err.Error()
+}
+
+func (e *exitCodeError) Unwrap() error {
+	return e.err
+}
+
+// exitCodeFor returns the exit code for the error a command returned.
+func exitCodeFor(err error) int {
+	if err == nil {
+		return exitOK
+	}
+	var exitErr *exitCodeError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	return exitInternal
+}
+
+// silentExit reports whether err only carries an exit code and has no
+// message to print.
+func silentExit(err error) bool {
+	var exitErr *exitCodeError
+	return errors.As(err, &exitErr) && exitErr.err == nil
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCodeFor(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, exitOK, exitCodeFor(nil))
+	assert.Equal(t, exitInternal, exitCodeFor(errors.New("boom")))
+	assert.Equal(t, exitInternal, exitCodeFor(config.NewConfigNotFoundError("preflight.yaml")))
+	assert.Equal(t, exitDrift, exitCodeFor(withExitCode(exitDrift, nil)))
+	assert.Equal(t, exitPolicyViolation, exitCodeFor(fmt.Errorf("validate: %w", withExitCode(exitPolicyViolation, errors.New("denied")))))
+}
+
+func TestSilentExit(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, silentExit(withExitCode(exitDrift, nil)))
+	assert.False(t, silentExit(withExitCode(exitDrift, errors.New("drift"))))
+	assert.False(t, silentExit(errors.New("boom")))
+}
+
+func TestFormatError_IncludesErrorCode(t *testing.T) {
+	t.Parallel()
+
+	formatted := formatError(config.NewProviderUnavailableError("Homebrew"))
+	assert.Contains(t, formatted, "Homebrew is not installed")
+	assert.Contains(t, formatted, "Error code: PF2003 (run 'preflight errors explain PF2003')")
+
+	assert.NotContains(t, formatError(config.NewUserError("SOMETHING_ELSE", "unknown")), "Error code:")
+}
@@ -26,16 +26,28 @@ Supported formats:
   - nix: Nix expression for home-manager integration
   - brewfile: Homebrew Brewfile format
   - shell: Shell script for portable execution
+  - bootstrap: curl | bash script that installs preflight, clones the
+    config repository and applies the target on a new machine
+  - jamf-ea: Jamf Pro extension attribute reporting drift and compliance
+  - kandji-audit: Kandji audit script failing on drift or policy violations
 
 The export merges all layers for the specified target into a single
-output, making it easy to share or migrate configurations.
+output, making it easy to share or migrate configurations. Shell scripts
+keep a section per layer instead, and --layers limits them to some layers.
+
+MDM scripts run preflight doctor (and preflight compliance with --policy)
+as the logged-in user and report one status: in-sync, drift,
+policy-violation, error, not-installed, not-configured or no-user.
 
 Examples:
   preflight export                      # Export as YAML to stdout
   preflight export --format json        # Export as JSON
   preflight export --format nix -o home.nix
   preflight export --format brewfile -o Brewfile
-  preflight export --target work --format shell`,
+  preflight export --target work --format shell
+  preflight export --target work --format shell --layers base,work
+  preflight export --target work --format bootstrap -o bootstrap.sh
+  preflight export --target work --format jamf-ea --policy /Library/Preflight/org-policy.yaml`,
 	RunE: runExport,
 }
 
@@ -45,6 +57,10 @@ var (
 	exportFormat     string
 	exportOutput     string
 	exportFlattened  bool
+	exportRepo       string
+	exportBranch     string
+	exportLayers     []string
+	exportPolicy     string
 )
 
 func init() {
@@ -52,14 +68,26 @@ func init() {
 
 	exportCmd.Flags().StringVarP(&exportConfigPath, "config", "c", "preflight.yaml", "Path to preflight.yaml")
 	exportCmd.Flags().StringVarP(&exportTarget, "target", "t", "default", "Target to export")
-	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "yaml", "Output format (yaml, json, toml, nix, brewfile, shell)")
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "yaml", "Output format (yaml, json, toml, nix, brewfile, shell, bootstrap, jamf-ea, kandji-audit)")
 	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Output file (default: stdout)")
 	exportCmd.Flags().BoolVar(&exportFlattened, "flatten", false, "Flatten all layers into single config")
+	exportCmd.Flags().StringVar(&exportRepo, "repo", "", "Config repository URL for --format bootstrap (default: git remote origin)")
+	exportCmd.Flags().StringVar(&exportBranch, "branch", "", "Branch to clone for --format bootstrap (default: remote default branch)")
+	exportCmd.Flags().StringSliceVar(&exportLayers, "layers", nil, "Layers to include in --format shell (default: all layers of the target)")
+	exportCmd.Flags().StringVar(&exportPolicy, "policy", "", "Org policy path on managed machines for --format jamf-ea and kandji-audit")
 }
 
 func runExport(_ *cobra.Command, _ []string) error {
 	ctx := context.Background()
 
+	format := strings.ToLower(exportFormat)
+	if len(exportLayers) > 0 && format != "shell" && format != "sh" && format != "bash" {
+		return fmt.Errorf("--layers is only supported with --format shell")
+	}
+	if exportPolicy != "" && !isMDMFormat(format) {
+		return fmt.Errorf("--policy is only supported with --format jamf-ea and kandji-audit")
+	}
+
 	preflight := app.New(os.Stdout)
 
 	// Load and merge configuration
@@ -70,7 +98,8 @@ func runExport(_ *cobra.Command, _ []string) error {
 
 	// Convert to export format
 	var output []byte
-	switch strings.ToLower(exportFormat) {
+	perm := os.FileMode(0o644)
+	switch format {
 	case "yaml", "yml":
 		output, err = yaml.Marshal(merged)
 	case "json":
@@ -82,7 +111,22 @@ func runExport(_ *cobra.Command, _ []string) error {
 	case "brewfile":
 		output, err = exportToBrewfile(merged)
 	case "shell", "sh", "bash":
-		output, err = exportToShell(merged)
+		output, err = exportLayersToShell(exportConfigPath, exportTarget, exportLayers)
+	case "bootstrap":
+		var opts bootstrapOptions
+		opts, err = resolveBootstrapOptions(exportConfigPath, exportTarget, exportRepo, exportBranch)
+		if err == nil {
+			output = exportToBootstrap(opts)
+			perm = 0o755
+		}
+	case "jamf-ea", "kandji-audit":
+		output = exportToMDM(mdmOptions{
+			Format:     format,
+			ConfigPath: repoConfigPath(exportConfigPath),
+			Target:     exportTarget,
+			PolicyPath: exportPolicy,
+		})
+		perm = 0o755
 	default:
 		return fmt.Errorf("unsupported format: %s", exportFormat)
 	}
@@ -97,7 +141,7 @@ func runExport(_ *cobra.Command, _ []string) error {
 		if err := os.MkdirAll(dir, 0o755); err != nil {
 			return fmt.Errorf("failed to create directory: %w", err)
 		}
-		if err := os.WriteFile(exportOutput, output, 0o644); err != nil {
+		if err := os.WriteFile(exportOutput, output, perm); err != nil { //nolint:gosec // bootstrap scripts are executable
 			return fmt.Errorf("failed to write file: %w", err)
 		}
 		fmt.Printf("Exported to %s\n", exportOutput)
@@ -212,81 +256,3 @@ func exportToBrewfile(config map[string]interface{}) ([]byte, error) {
 
 	return []byte(sb.String()), nil
 }
-
-//nolint:gocritic,unparam // sprintfQuotedString: literal quotes needed for shell syntax; error return for future implementation
-func exportToShell(config map[string]interface{}) ([]byte, error) {
-	var sb strings.Builder
-
-	sb.WriteString("#!/usr/bin/env bash\n")
-	sb.WriteString("# Generated by preflight export\n")
-	sb.WriteString("# https://github.com/felixgeelhaar/preflight\n\n")
-	sb.WriteString("set -euo pipefail\n\n")
-
-	// Homebrew installation check
-	sb.WriteString("# Ensure Homebrew is installed\n")
-	sb.WriteString("if ! command -v brew &> /dev/null; then\n")
-	sb.WriteString("  echo \"Installing Homebrew...\"\n")
-	sb.WriteString("  /bin/bash -c \"$(curl -fsSL https://raw.githubusercontent.com/Homebrew/install/HEAD/install.sh)\"\n")
-	sb.WriteString("fi\n\n")
-
-	if brew, ok := config["brew"].(map[string]interface{}); ok {
-		// Taps
-		if taps, ok := brew["taps"].([]interface{}); ok && len(taps) > 0 {
-			sb.WriteString("# Add taps\n")
-			for _, t := range taps {
-				if name, ok := t.(string); ok {
-					fmt.Fprintf(&sb, "brew tap %s\n", name)
-				}
-			}
-			sb.WriteString("\n")
-		}
-
-		// Formulae
-		if formulae, ok := brew["formulae"].([]interface{}); ok && len(formulae) > 0 {
-			sb.WriteString("# Install formulae\n")
-			sb.WriteString("brew install \\\n")
-			for i, f := range formulae {
-				if name, ok := f.(string); ok {
-					if i < len(formulae)-1 {
-						fmt.Fprintf(&sb, "  %s \\\n", name)
-					} else {
-						fmt.Fprintf(&sb, "  %s\n", name)
-					}
-				}
-			}
-			sb.WriteString("\n")
-		}
-
-		// Casks
-		if casks, ok := brew["casks"].([]interface{}); ok && len(casks) > 0 {
-			sb.WriteString("# Install casks\n")
-			sb.WriteString("brew install --cask \\\n")
-			for i, c := range casks {
-				if name, ok := c.(string); ok {
-					if i < len(casks)-1 {
-						fmt.Fprintf(&sb, "  %s \\\n", name)
-					} else {
-						fmt.Fprintf(&sb, "  %s\n", name)
-					}
-				}
-			}
-			sb.WriteString("\n")
-		}
-	}
-
-	// Git configuration
-	if git, ok := config["git"].(map[string]interface{}); ok {
-		sb.WriteString("# Configure git\n")
-		if name, ok := git["name"].(string); ok {
-			fmt.Fprintf(&sb, "git config --global user.name \"%s\"\n", name)
-		}
-		if email, ok := git["email"].(string); ok {
-			fmt.Fprintf(&sb, "git config --global user.email \"%s\"\n", email)
-		}
-		sb.WriteString("\n")
-	}
-
-	sb.WriteString("echo \"Setup complete!\"\n")
-
-	return []byte(sb.String()), nil
-}
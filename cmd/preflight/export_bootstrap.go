@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// bootstrapOptions parameterize the generated bootstrap script.
+type bootstrapOptions struct {
+	// RepoURL is the git URL of the config repository.
+	RepoURL string
+	// Branch is checked out after cloning; empty uses the remote default.
+	Branch string
+	// ConfigPath is the path of preflight.yaml inside the repository.
+	ConfigPath string
+	// Target is applied on the new machine.
+	Target string
+}
+
+// resolveBootstrapOptions fills in the repository URL and the config path
+// inside the repository from the git checkout containing configPath.
+func resolveBootstrapOptions(configPath, target, repoURL, branch string) (bootstrapOptions, error) {
+	opts := bootstrapOptions{RepoURL: repoURL, Branch: branch, ConfigPath: repoConfigPath(configPath), Target: target}
+
+	if opts.RepoURL == "" {
+		opts.RepoURL, _ = gitOutput(filepath.Dir(configPath), "remote", "get-url", "origin")
+	}
+	if opts.RepoURL == "" {
+		return opts, fmt.Errorf("no git remote found for %s: pass --repo <url>", configPath)
+	}
+	return opts, nil
+}
+
+// repoConfigPath returns the path of configPath inside the git checkout
+// containing it, or its file name outside a checkout.
+func repoConfigPath(configPath string) string {
+	root, err := gitOutput(filepath.Dir(configPath), "rev-parse", "--show-toplevel")
+	if err != nil {
+		return filepath.Base(configPath)
+	}
+	abs, err := filepath.Abs(configPath)
+	if err != nil {
+		return filepath.Base(configPath)
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+	if rel, err := filepath.Rel(root, abs); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.Base(configPath)
+}
+
+func gitOutput(dir string, args ...string) (string, error) {
+	// #nosec G204 -- args are fixed git subcommands; dir comes from the config path.
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// shellQuote single-quotes s for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// exportToBootstrap renders a self-contained script for new machines: it
+// installs preflight, clones the config repository and applies the target.
+// The body runs from main() at the end so a truncated download over
+// curl | bash never executes a partial script.
+func exportToBootstrap(opts bootstrapOptions) []byte {
+	var sb strings.Builder
+
+	sb.WriteString("#!/usr/bin/env bash\n")
+	sb.WriteString("# Generated by preflight export --format bootstrap\n")
+	sb.WriteString("# https://github.com/felixgeelhaar/preflight\n")
+	sb.WriteString("#\n")
+	fmt.Fprintf(&sb, "# Sets up a new machine with target %q:\n", opts.Target)
+	sb.WriteString("#   curl -fsSL <url-of-this-script> | bash\n")
+	sb.WriteString("#\n")
+	sb.WriteString("# Override the defaults with PREFLIGHT_REPO, PREFLIGHT_BRANCH, PREFLIGHT_TARGET,\n")
+	sb.WriteString("# PREFLIGHT_DIR, PREFLIGHT_VERSION and PREFLIGHT_BIN_DIR.\n\n")
+	sb.WriteString("set -euo pipefail\n\n")
+
+	fmt.Fprintf(&sb, "PREFLIGHT_REPO=${PREFLIGHT_REPO:-%s}\n", shellQuote(opts.RepoURL))
+	fmt.Fprintf(&sb, "PREFLIGHT_BRANCH=${PREFLIGHT_BRANCH:-%s}\n", shellQuote(opts.Branch))
+	fmt.Fprintf(&sb, "PREFLIGHT_TARGET=${PREFLIGHT_TARGET:-%s}\n", shellQuote(opts.Target))
+	fmt.Fprintf(&sb, "PREFLIGHT_CONFIG=%s\n", shellQuote(opts.ConfigPath))
+	sb.WriteString(`PREFLIGHT_DIR=${PREFLIGHT_DIR:-"${XDG_CONFIG_HOME:-$HOME/.config}/preflight/config"}
+PREFLIGHT_VERSION=${PREFLIGHT_VERSION:-latest}
+PREFLIGHT_BIN_DIR=${PREFLIGHT_BIN_DIR:-"$HOME/.local/bin"}
+
+info() { printf '==> %s\n' "$*"; }
+fail() { printf 'error: %s\n' "$*" >&2; exit 1; }
+
+install_preflight() {
+  if command -v preflight >/dev/null 2>&1; then
+    PREFLIGHT_BIN=$(command -v preflight)
+    info "Using $("$PREFLIGHT_BIN" version)"
+    return
+  fi
+
+  local os arch url tmp
+  case "$(uname -s)" in
+    Darwin) os=darwin ;;
+    Linux) os=linux ;;
+    *) fail "unsupported operating system: $(uname -s)" ;;
+  esac
+  case "$(uname -m)" in
+    x86_64 | amd64) arch=amd64 ;;
+    arm64 | aarch64) arch=arm64 ;;
+    *) fail "unsupported architecture: $(uname -m)" ;;
+  esac
+
+  if [ "$PREFLIGHT_VERSION" = latest ]; then
+    url="https://github.com/felixgeelhaar/preflight/releases/latest/download/preflight-$os-$arch.tar.gz"
+  else
+    url="https://github.com/felixgeelhaar/preflight/releases/download/v${PREFLIGHT_VERSION#v}/preflight-$os-$arch.tar.gz"
+  fi
+
+  info "Installing preflight ($PREFLIGHT_VERSION, $os/$arch) to $PREFLIGHT_BIN_DIR"
+  tmp=$(mktemp -d)
+  trap "rm -rf '$tmp'" EXIT
+  curl -fsSL "$url" | tar -xz -C "$tmp"
+  mkdir -p "$PREFLIGHT_BIN_DIR"
+  install -m 0755 "$tmp/preflight" "$PREFLIGHT_BIN_DIR/preflight"
+  PREFLIGHT_BIN="$PREFLIGHT_BIN_DIR/preflight"
+  case ":$PATH:" in
+    *":$PREFLIGHT_BIN_DIR:"*) ;;
+    *) info "Add $PREFLIGHT_BIN_DIR to your PATH to run preflight directly" ;;
+  esac
+}
+
+clone_config() {
+  if ! command -v git >/dev/null 2>&1; then
+    if [ "$(uname -s)" = Darwin ]; then
+      xcode-select --install >/dev/null 2>&1 || true
+      fail "git is required: finish installing the Xcode Command Line Tools, then re-run this script"
+    fi
+    fail "git is required: install it with your package manager, then re-run this script"
+  fi
+
+  if [ -d "$PREFLIGHT_DIR/.git" ]; then
+    info "Updating $PREFLIGHT_DIR"
+    git -C "$PREFLIGHT_DIR" pull --ff-only
+  else
+    info "Cloning $PREFLIGHT_REPO to $PREFLIGHT_DIR"
+    mkdir -p "$(dirname "$PREFLIGHT_DIR")"
+    if [ -n "$PREFLIGHT_BRANCH" ]; then
+      git clone --branch "$PREFLIGHT_BRANCH" "$PREFLIGHT_REPO" "$PREFLIGHT_DIR"
+    else
+      git clone "$PREFLIGHT_REPO" "$PREFLIGHT_DIR"
+    fi
+  fi
+}
+
+main() {
+  install_preflight
+  clone_config
+  info "Applying target $PREFLIGHT_TARGET"
+  "$PREFLIGHT_BIN" apply --config "$PREFLIGHT_DIR/$PREFLIGHT_CONFIG" --target "$PREFLIGHT_TARGET" --yes
+  info "Machine bootstrapped with preflight"
+}
+
+main "$@"
+`)
+
+	return []byte(sb.String())
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportToBootstrap(t *testing.T) {
+	t.Parallel()
+
+	script := string(exportToBootstrap(bootstrapOptions{
+		RepoURL:    "git@github.com:me/dotfiles.git",
+		Branch:     "main",
+		ConfigPath: "machines/preflight.yaml",
+		Target:     "work",
+	}))
+
+	assert.True(t, strings.HasPrefix(script, "#!/usr/bin/env bash\n"))
+	assert.Contains(t, script, "PREFLIGHT_REPO=${PREFLIGHT_REPO:-'git@github.com:me/dotfiles.git'}")
+	assert.Contains(t, script, "PREFLIGHT_BRANCH=${PREFLIGHT_BRANCH:-'main'}")
+	assert.Contains(t, script, "PREFLIGHT_TARGET=${PREFLIGHT_TARGET:-'work'}")
+	assert.Contains(t, script, "PREFLIGHT_CONFIG='machines/preflight.yaml'")
+	assert.Contains(t, script, `apply --config "$PREFLIGHT_DIR/$PREFLIGHT_CONFIG" --target "$PREFLIGHT_TARGET" --yes`)
+	assert.True(t, strings.HasSuffix(script, "main \"$@\"\n"), "nothing runs until the whole script is downloaded")
+
+	if bash, err := exec.LookPath("bash"); err == nil {
+		cmd := exec.Command(bash, "-n")
+		cmd.Stdin = strings.NewReader(script)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "'plain'", shellQuote("plain"))
+	assert.Equal(t, `'it'\''s'`, shellQuote("it's"))
+	assert.Equal(t, "''", shellQuote(""))
+}
+
+func TestResolveBootstrapOptions_FromGit(t *testing.T) {
+	t.Parallel()
+
+	repo := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"remote", "add", "origin", "https://example.com/me/dotfiles.git"},
+	} {
+		require.NoError(t, exec.Command("git", append([]string{"-C", repo}, args...)...).Run())
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, "config"), 0o755))
+	configPath := filepath.Join(repo, "config", "preflight.yaml")
+
+	opts, err := resolveBootstrapOptions(configPath, "work", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/me/dotfiles.git", opts.RepoURL)
+	assert.Equal(t, "config/preflight.yaml", opts.ConfigPath)
+	assert.Equal(t, "work", opts.Target)
+
+	opts, err = resolveBootstrapOptions(configPath, "work", "https://example.com/other.git", "dev")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/other.git", opts.RepoURL)
+	assert.Equal(t, "dev", opts.Branch)
+}
+
+func TestResolveBootstrapOptions_RequiresRepo(t *testing.T) {
+	t.Parallel()
+
+	_, err := resolveBootstrapOptions(filepath.Join(t.TempDir(), "preflight.yaml"), "default", "", "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "pass --repo")
+}
+
+//nolint:tparallel
+func TestRunExport_Bootstrap(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "preflight.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("targets:\n  work:\n    - base\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "layers"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "layers", "base.yaml"), []byte("name: base\n"), 0o644))
+
+	oldFormat, oldConfig, oldTarget, oldOutput, oldRepo := exportFormat, exportConfigPath, exportTarget, exportOutput, exportRepo
+	defer func() {
+		exportFormat, exportConfigPath, exportTarget, exportOutput, exportRepo = oldFormat, oldConfig, oldTarget, oldOutput, oldRepo
+	}()
+
+	exportFormat = "bootstrap"
+	exportConfigPath = configPath
+	exportTarget = "work"
+	exportRepo = "https://example.com/me/dotfiles.git"
+	exportOutput = filepath.Join(tmpDir, "bootstrap.sh")
+
+	captureStdout(t, func() {
+		require.NoError(t, runExport(nil, nil))
+	})
+
+	info, err := os.Stat(exportOutput)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+
+	data, err := os.ReadFile(exportOutput)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "PREFLIGHT_TARGET=${PREFLIGHT_TARGET:-'work'}")
+}
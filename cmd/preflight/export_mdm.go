@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// mdmStatuses are the statuses the MDM scripts report, with their exit
+// codes. They are a stable contract for smart groups.
+var mdmStatuses = []struct {
+	Status  string
+	Meaning string
+}{
+	{"in-sync", "preflight doctor found nothing to fix (exit 0)"},
+	{"drift", "the machine is out of line with the config (exit 1)"},
+	{"policy-violation", "the machine breaks the org policy (exit 2)"},
+	{"error", "preflight could not check the machine (exit 3)"},
+	{"not-installed", "preflight is not installed (exit 3)"},
+	{"not-configured", "the config repository is not cloned (exit 3)"},
+	{"no-user", "nobody is logged in (exit 3)"},
+}
+
+// mdmOptions parameterize the generated MDM scripts.
+type mdmOptions struct {
+	// Format is "jamf-ea" or "kandji-audit".
+	Format string
+	// ConfigPath is the path of preflight.yaml inside the config repository.
+	ConfigPath string
+	// Target is checked on the managed machine.
+	Target string
+	// PolicyPath is the org policy evaluated on the managed machine, if any.
+	PolicyPath string
+}
+
+// isMDMFormat reports whether format is an MDM script format.
+func isMDMFormat(format string) bool {
+	return format == "jamf-ea" || format == "kandji-audit"
+}
+
+// exportToMDM renders a script for an MDM console that reports whether the
+// logged-in user's machine matches the target. MDM agents run scripts as
+// root, so the script runs preflight as the console user against the config
+// repository cloned by the bootstrap script.
+//
+// A Jamf Pro extension attribute prints the status as <result>status</result>
+// and always exits 0. A Kandji audit script prints the status and exits with
+// preflight's exit code, so only an in-sync machine passes.
+func exportToMDM(opts mdmOptions) []byte {
+	var sb strings.Builder
+
+	sb.WriteString("#!/bin/bash\n")
+	fmt.Fprintf(&sb, "# Generated by preflight export --format %s\n", opts.Format)
+	sb.WriteString("# https://github.com/felixgeelhaar/preflight\n")
+	sb.WriteString("#\n")
+	if opts.Format == "jamf-ea" {
+		fmt.Fprintf(&sb, "# Jamf Pro extension attribute reporting whether this machine matches target %q.\n", opts.Target)
+	} else {
+		fmt.Fprintf(&sb, "# Kandji audit script checking that this machine matches target %q.\n", opts.Target)
+	}
+	sb.WriteString("# The status is one of:\n")
+	for _, st := range mdmStatuses {
+		fmt.Fprintf(&sb, "#   %-17s %s\n", st.Status, st.Meaning)
+	}
+	sb.WriteString("#\n")
+	sb.WriteString("# Override the defaults with PREFLIGHT_TARGET, PREFLIGHT_DIR, PREFLIGHT_POLICY\n")
+	sb.WriteString("# and PREFLIGHT_USER.\n\n")
+
+	fmt.Fprintf(&sb, "PREFLIGHT_TARGET=${PREFLIGHT_TARGET:-%s}\n", shellQuote(opts.Target))
+	fmt.Fprintf(&sb, "PREFLIGHT_POLICY=${PREFLIGHT_POLICY:-%s}\n", shellQuote(opts.PolicyPath))
+	fmt.Fprintf(&sb, "PREFLIGHT_CONFIG=%s\n", shellQuote(opts.ConfigPath))
+	sb.WriteString("PREFLIGHT_USER=${PREFLIGHT_USER:-}\n\n")
+
+	if opts.Format == "jamf-ea" {
+		sb.WriteString("report() { printf '<result>%s</result>\\n' \"$1\"; exit 0; }\n")
+	} else {
+		sb.WriteString("report() { printf 'preflight: %s\\n' \"$1\"; exit \"$2\"; }\n")
+	}
+
+	sb.WriteString(`
+console_user() {
+  if [ -n "$PREFLIGHT_USER" ]; then
+    printf '%s\n' "$PREFLIGHT_USER"
+  elif [ "$(id -u)" -ne 0 ]; then
+    id -un
+  else
+    stat -f%Su /dev/console 2>/dev/null || logname 2>/dev/null
+  fi
+}
+
+home_of() {
+  local home
+  home=$(dscl . -read "/Users/$1" NFSHomeDirectory 2>/dev/null | awk '{print $2}')
+  [ -n "$home" ] || home=$(getent passwd "$1" 2>/dev/null | cut -d: -f6)
+  printf '%s\n' "$home"
+}
+
+as_user() {
+  if [ "$(id -u)" -eq 0 ] && [ "$user" != root ]; then
+    sudo -u "$user" -H "$@"
+  else
+    "$@"
+  fi
+}
+
+find_preflight() {
+  local candidate
+  for candidate in "$(command -v preflight 2>/dev/null)" "$home/.local/bin/preflight" \
+    /opt/homebrew/bin/preflight /usr/local/bin/preflight; do
+    if [ -n "$candidate" ] && [ -x "$candidate" ]; then
+      printf '%s\n' "$candidate"
+      return
+    fi
+  done
+}
+
+status_for() {
+  case $1 in
+    0) report in-sync 0 ;;
+    1) report drift 1 ;;
+    2) report policy-violation 2 ;;
+    *) report error 3 ;;
+  esac
+}
+
+main() {
+  user=$(console_user)
+  case $user in
+    "" | loginwindow | _mbsetupuser) report no-user 3 ;;
+  esac
+  home=$(home_of "$user")
+  [ -n "$home" ] || report error 3
+  PREFLIGHT_DIR=${PREFLIGHT_DIR:-"$home/.config/preflight/config"}
+
+  bin=$(find_preflight)
+  [ -n "$bin" ] || report not-installed 3
+  [ -f "$PREFLIGHT_DIR/$PREFLIGHT_CONFIG" ] || report not-configured 3
+
+  if [ -n "$PREFLIGHT_POLICY" ]; then
+    as_user "$bin" compliance --config "$PREFLIGHT_DIR/$PREFLIGHT_CONFIG" --target "$PREFLIGHT_TARGET" \
+      --policy "$PREFLIGHT_POLICY" >/dev/null 2>&1
+    code=$?
+    [ "$code" -eq 0 ] || status_for "$code"
+  fi
+
+  as_user "$bin" doctor --quiet --config "$PREFLIGHT_DIR/$PREFLIGHT_CONFIG" --target "$PREFLIGHT_TARGET" >/dev/null 2>&1
+  status_for $?
+}
+
+main "$@"
+`)
+
+	return []byte(sb.String())
+}
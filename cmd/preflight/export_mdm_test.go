@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportToMDM(t *testing.T) {
+	t.Parallel()
+
+	for _, format := range []string{"jamf-ea", "kandji-audit"} {
+		script := string(exportToMDM(mdmOptions{
+			Format:     format,
+			ConfigPath: "machines/preflight.yaml",
+			Target:     "work",
+			PolicyPath: "/Library/Preflight/org-policy.yaml",
+		}))
+
+		assert.True(t, strings.HasPrefix(script, "#!/bin/bash\n"))
+		assert.Contains(t, script, "# Generated by preflight export --format "+format)
+		assert.Contains(t, script, "PREFLIGHT_TARGET=${PREFLIGHT_TARGET:-'work'}")
+		assert.Contains(t, script, "PREFLIGHT_POLICY=${PREFLIGHT_POLICY:-'/Library/Preflight/org-policy.yaml'}")
+		assert.Contains(t, script, "PREFLIGHT_CONFIG='machines/preflight.yaml'")
+		for _, st := range mdmStatuses {
+			assert.Contains(t, script, "#   "+st.Status)
+		}
+
+		if bash, err := exec.LookPath("bash"); err == nil {
+			cmd := exec.Command(bash, "-n")
+			cmd.Stdin = strings.NewReader(script)
+			out, err := cmd.CombinedOutput()
+			require.NoError(t, err, string(out))
+		}
+	}
+}
+
+// runMDMScript runs a generated script against a fake preflight that exits
+// with doctorCode, and returns its output and exit code.
+func runMDMScript(t *testing.T, format string, doctorCode int, configured bool) (string, int) {
+	t.Helper()
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not available")
+	}
+
+	binDir := t.TempDir()
+	fake := "#!/bin/sh\nexit " + strconv.Itoa(doctorCode) + "\n"
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "preflight"), []byte(fake), 0o755)) //nolint:gosec // test executable
+	configDir := t.TempDir()
+	if configured {
+		require.NoError(t, os.WriteFile(filepath.Join(configDir, "preflight.yaml"), []byte("targets: {}\n"), 0o644))
+	}
+
+	script := exportToMDM(mdmOptions{Format: format, ConfigPath: "preflight.yaml", Target: "work"})
+	cmd := exec.Command(bash, "-s")
+	cmd.Stdin = strings.NewReader(string(script))
+	cmd.Env = append(os.Environ(),
+		"PATH="+binDir+string(os.PathListSeparator)+os.Getenv("PATH"),
+		"PREFLIGHT_DIR="+configDir,
+		"PREFLIGHT_USER=root",
+	)
+	out, err := cmd.Output()
+	code := 0
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		code = exitErr.ExitCode()
+	} else {
+		require.NoError(t, err)
+	}
+	return string(out), code
+}
+
+func TestExportToMDM_JamfStatuses(t *testing.T) {
+	t.Parallel()
+
+	for code, status := range map[int]string{0: "in-sync", 1: "drift", 2: "policy-violation", 3: "error"} {
+		out, exit := runMDMScript(t, "jamf-ea", code, true)
+		assert.Equal(t, "<result>"+status+"</result>\n", out)
+		assert.Equal(t, 0, exit, "extension attributes always exit 0")
+	}
+
+	out, _ := runMDMScript(t, "jamf-ea", 0, false)
+	assert.Equal(t, "<result>not-configured</result>\n", out)
+}
+
+func TestExportToMDM_KandjiExitCodes(t *testing.T) {
+	t.Parallel()
+
+	out, exit := runMDMScript(t, "kandji-audit", 0, true)
+	assert.Equal(t, "preflight: in-sync\n", out)
+	assert.Equal(t, 0, exit)
+
+	out, exit = runMDMScript(t, "kandji-audit", 1, true)
+	assert.Equal(t, "preflight: drift\n", out)
+	assert.Equal(t, 1, exit)
+}
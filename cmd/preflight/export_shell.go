@@ -0,0 +1,281 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+)
+
+// shellSection is the part of an exported shell script that comes from one
+// layer. Sections without a layer name are written without markers.
+type shellSection struct {
+	// Layer is the name of the layer the section comes from.
+	Layer string
+	// Source is the layer file, relative to the config directory.
+	Source string
+	// Config is the raw configuration of the layer.
+	Config map[string]interface{}
+}
+
+// exportToShell renders the merged configuration as a shell script.
+//
+//nolint:unparam // error return for parity with the other exporters
+func exportToShell(config map[string]interface{}) ([]byte, error) {
+	return renderShellScript("", []shellSection{{Config: config}}), nil
+}
+
+// exportLayersToShell renders the layers of a target as a shell script with
+// a section per layer, limited to the layers in only when it is not empty.
+func exportLayersToShell(configPath, target string, only []string) ([]byte, error) {
+	sections, err := loadShellSections(configPath, target, only)
+	if err != nil {
+		return nil, err
+	}
+	return renderShellScript(target, sections), nil
+}
+
+// loadShellSections loads the layers of a target in merge order.
+func loadShellSections(configPath, target string, only []string) ([]shellSection, error) {
+	loader := config.NewLoader()
+	manifest, err := loader.LoadManifest(configPath)
+	if err != nil {
+		return nil, err
+	}
+	name, err := config.NewTargetName(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target name: %w", err)
+	}
+	configDir := filepath.Dir(configPath)
+	resolved, err := loader.LoadTarget(manifest, name, filepath.Join(configDir, "layers"))
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(resolved.Layers))
+	for _, layer := range resolved.Layers {
+		names = append(names, layer.Name.String())
+	}
+	for _, layer := range only {
+		if !slices.Contains(names, layer) {
+			return nil, fmt.Errorf("layer %q is not part of target %s (layers: %s)", layer, target, strings.Join(names, ", "))
+		}
+	}
+
+	sections := make([]shellSection, 0, len(resolved.Layers))
+	for _, layer := range resolved.Layers {
+		if len(only) > 0 && !slices.Contains(only, layer.Name.String()) {
+			continue
+		}
+		merged, err := config.NewMerger().Merge([]config.Layer{layer})
+		if err != nil {
+			return nil, err
+		}
+		source := layer.Provenance
+		if rel, err := filepath.Rel(configDir, source); err == nil {
+			source = rel
+		}
+		sections = append(sections, shellSection{
+			Layer:  layer.Name.String(),
+			Source: filepath.ToSlash(source),
+			Config: merged.Raw(),
+		})
+	}
+	return sections, nil
+}
+
+// shellSteps are the commands of a section.
+type shellSteps struct {
+	taps, formulae, casks []string
+	gitName, gitEmail     string
+}
+
+func (s shellSteps) empty() bool {
+	return len(s.taps) == 0 && len(s.formulae) == 0 && len(s.casks) == 0 && s.gitName == "" && s.gitEmail == ""
+}
+
+// renderShellScript writes a script that is safe to run repeatedly: every
+// command is guarded by a check whether it is already done, and --dry-run
+// prints the commands that would run instead. Packages declared by several
+// layers are installed by the first.
+func renderShellScript(target string, sections []shellSection) []byte {
+	seen := make(map[string]bool)
+	unseen := func(kind string, names []string) []string {
+		var out []string
+		for _, name := range names {
+			if !seen[kind+":"+name] {
+				seen[kind+":"+name] = true
+				out = append(out, name)
+			}
+		}
+		return out
+	}
+
+	steps := make([]shellSteps, len(sections))
+	needsBrew := false
+	for i, section := range sections {
+		if brew, ok := section.Config["brew"].(map[string]interface{}); ok {
+			steps[i].taps = unseen("tap", shellStrings(brew["taps"]))
+			steps[i].formulae = unseen("formula", shellStrings(brew["formulae"]))
+			steps[i].casks = unseen("cask", shellStrings(brew["casks"]))
+		}
+		steps[i].gitName, steps[i].gitEmail = shellGitIdentity(section.Config)
+		needsBrew = needsBrew || len(steps[i].taps)+len(steps[i].formulae)+len(steps[i].casks) > 0
+	}
+
+	var sb strings.Builder
+	sb.WriteString("#!/usr/bin/env bash\n")
+	sb.WriteString("# Generated by preflight export\n")
+	sb.WriteString("# https://github.com/felixgeelhaar/preflight\n")
+	if target != "" {
+		layers := make([]string, 0, len(sections))
+		for _, section := range sections {
+			layers = append(layers, section.Layer)
+		}
+		fmt.Fprintf(&sb, "#\n# Target: %s\n# Layers: %s\n", target, strings.Join(layers, ", "))
+	}
+	sb.WriteString("#\n")
+	sb.WriteString("# Every step checks whether it is already done, so the script is safe to\n")
+	sb.WriteString("# run again. Pass --dry-run to print the commands it would run.\n\n")
+	sb.WriteString("set -euo pipefail\n\n")
+	sb.WriteString("DRY_RUN=0\n")
+	sb.WriteString("if [ \"${1:-}\" = \"--dry-run\" ]; then\n")
+	sb.WriteString("  DRY_RUN=1\n")
+	sb.WriteString("fi\n\n")
+	sb.WriteString("# run prints the command instead of running it with --dry-run.\n")
+	sb.WriteString("run() {\n")
+	sb.WriteString("  if [ \"$DRY_RUN\" = 1 ]; then\n")
+	sb.WriteString("    echo \"+ $*\"\n")
+	sb.WriteString("  else\n")
+	sb.WriteString("    \"$@\"\n")
+	sb.WriteString("  fi\n")
+	sb.WriteString("}\n\n")
+
+	if needsBrew {
+		sb.WriteString("# Ensure Homebrew is installed\n")
+		sb.WriteString("if ! command -v brew &> /dev/null; then\n")
+		sb.WriteString("  if [ \"$DRY_RUN\" = 1 ]; then\n")
+		sb.WriteString("    echo \"+ install Homebrew\"\n")
+		sb.WriteString("  else\n")
+		sb.WriteString("    echo \"Installing Homebrew...\"\n")
+		sb.WriteString("    /bin/bash -c \"$(curl -fsSL https://raw.githubusercontent.com/Homebrew/install/HEAD/install.sh)\"\n")
+		sb.WriteString("    for brew in /opt/homebrew/bin/brew /usr/local/bin/brew /home/linuxbrew/.linuxbrew/bin/brew; do\n")
+		sb.WriteString("      if [ -x \"$brew\" ]; then\n")
+		sb.WriteString("        eval \"$(\"$brew\" shellenv)\"\n")
+		sb.WriteString("        break\n")
+		sb.WriteString("      fi\n")
+		sb.WriteString("    done\n")
+		sb.WriteString("  fi\n")
+		sb.WriteString("fi\n\n")
+	}
+
+	for i, section := range sections {
+		if section.Layer != "" {
+			fmt.Fprintf(&sb, "# >>> layer: %s", section.Layer)
+			if section.Source != "" {
+				fmt.Fprintf(&sb, " (%s)", section.Source)
+			}
+			sb.WriteString("\n")
+			if steps[i].empty() {
+				sb.WriteString("# nothing to export\n")
+			}
+		}
+		writeShellSteps(&sb, steps[i])
+		if section.Layer != "" {
+			fmt.Fprintf(&sb, "# <<< layer: %s\n\n", section.Layer)
+		}
+	}
+
+	sb.WriteString("echo \"Setup complete!\"\n")
+	return []byte(sb.String())
+}
+
+//nolint:gocritic // sprintfQuotedString: literal quotes needed for shell syntax
+func writeShellSteps(sb *strings.Builder, steps shellSteps) {
+	if len(steps.taps) > 0 {
+		sb.WriteString("# Add taps\n")
+		for _, name := range steps.taps {
+			fmt.Fprintf(sb, "brew tap 2> /dev/null | grep -qixF %[1]s || run brew tap %[1]s\n", shellWord(name))
+		}
+		sb.WriteString("\n")
+	}
+	if len(steps.formulae) > 0 {
+		sb.WriteString("# Install formulae\n")
+		for _, name := range steps.formulae {
+			fmt.Fprintf(sb, "brew list --formula %[1]s &> /dev/null || run brew install %[1]s\n", shellWord(name))
+		}
+		sb.WriteString("\n")
+	}
+	if len(steps.casks) > 0 {
+		sb.WriteString("# Install casks\n")
+		for _, name := range steps.casks {
+			fmt.Fprintf(sb, "brew list --cask %[1]s &> /dev/null || run brew install --cask %[1]s\n", shellWord(name))
+		}
+		sb.WriteString("\n")
+	}
+	if steps.gitName != "" || steps.gitEmail != "" {
+		sb.WriteString("# Configure git\n")
+		sb.WriteString("if command -v git &> /dev/null; then\n")
+		for _, kv := range [][2]string{{"user.name", steps.gitName}, {"user.email", steps.gitEmail}} {
+			if kv[1] == "" {
+				continue
+			}
+			value := shellDoubleQuote(kv[1])
+			fmt.Fprintf(sb, "  [ \"$(git config --global %[1]s)\" = %[2]s ] || run git config --global %[1]s %[2]s\n", kv[0], value)
+		}
+		sb.WriteString("else\n")
+		sb.WriteString("  echo \"git is not installed; skipping git configuration\" >&2\n")
+		sb.WriteString("fi\n\n")
+	}
+}
+
+// shellStrings returns the strings of a raw list.
+func shellStrings(v interface{}) []string {
+	items, _ := v.([]interface{})
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok && s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// shellGitIdentity returns the git user name and email of a raw
+// configuration, which keeps them under git.user.
+func shellGitIdentity(raw map[string]interface{}) (name, email string) {
+	git, ok := raw["git"].(map[string]interface{})
+	if !ok {
+		return "", ""
+	}
+	if user, ok := git["user"].(map[string]interface{}); ok {
+		name, _ = user["name"].(string)
+		email, _ = user["email"].(string)
+	}
+	if name == "" {
+		name, _ = git["name"].(string)
+	}
+	if email == "" {
+		email, _ = git["email"].(string)
+	}
+	return name, email
+}
+
+var plainShellWord = regexp.MustCompile(`^[A-Za-z0-9@%+=:,./_-]+$`)
+
+// shellWord quotes s unless it is a plain word such as a package name.
+func shellWord(s string) string {
+	if plainShellWord.MatchString(s) {
+		return s
+	}
+	return shellQuote(s)
+}
+
+// shellDoubleQuote double-quotes s, escaping the characters that stay
+// special inside double quotes.
+func shellDoubleQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`").Replace(s) + `"`
+}
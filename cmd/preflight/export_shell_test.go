@@ -0,0 +1,93 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeShellExportConfig(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "layers"), 0o755))
+	files := map[string]string{
+		"preflight.yaml":   "targets:\n  default: [base, work]\n",
+		"layers/base.yaml": "name: base\npackages:\n  brew:\n    taps: [homebrew/cask-fonts]\n    formulae: [git, jq]\ngit:\n  user:\n    name: Ada \"$Dev\"\n    email: ada@example.com\n",
+		"layers/work.yaml": "name: work\npackages:\n  brew:\n    formulae: [jq, ripgrep]\n    casks: [firefox]\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	return filepath.Join(dir, "preflight.yaml")
+}
+
+func TestExportLayersToShell(t *testing.T) {
+	t.Parallel()
+
+	output, err := exportLayersToShell(writeShellExportConfig(t), "default", nil)
+	require.NoError(t, err)
+	script := string(output)
+
+	assert.Contains(t, script, "# Target: default\n# Layers: base, work\n")
+	base := strings.Index(script, "# >>> layer: base (layers/base.yaml)\n")
+	work := strings.Index(script, "# >>> layer: work (layers/work.yaml)\n")
+	require.NotEqual(t, -1, base)
+	require.Greater(t, work, base)
+	assert.Contains(t, script, "# <<< layer: base\n")
+	assert.Contains(t, script, "# <<< layer: work\n")
+
+	assert.Contains(t, script, "brew tap 2> /dev/null | grep -qixF homebrew/cask-fonts || run brew tap homebrew/cask-fonts\n")
+	assert.Contains(t, script, "brew list --formula ripgrep &> /dev/null || run brew install ripgrep\n")
+	assert.Contains(t, script, "brew list --cask firefox &> /dev/null || run brew install --cask firefox\n")
+	assert.Equal(t, 1, strings.Count(script, "run brew install jq"), "layers after the first skip shared packages")
+	assert.Less(t, strings.Index(script, "run brew install jq"), work)
+	assert.Contains(t, script, `[ "$(git config --global user.name)" = "Ada \"\$Dev\"" ] || run git config --global user.name "Ada \"\$Dev\""`)
+
+	if bash, err := exec.LookPath("bash"); err == nil {
+		cmd := exec.Command(bash, "-n")
+		cmd.Stdin = strings.NewReader(script)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+}
+
+func TestExportLayersToShell_Layers(t *testing.T) {
+	t.Parallel()
+
+	configPath := writeShellExportConfig(t)
+
+	output, err := exportLayersToShell(configPath, "default", []string{"work"})
+	require.NoError(t, err)
+	script := string(output)
+	assert.Contains(t, script, "# Layers: work\n")
+	assert.NotContains(t, script, "layer: base")
+	assert.Contains(t, script, "run brew install jq", "shared packages belong to the first exported layer")
+	assert.NotContains(t, script, "git config")
+
+	_, err = exportLayersToShell(configPath, "default", []string{"missing"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `layer "missing" is not part of target default (layers: base, work)`)
+}
+
+func TestRenderShellScript_EmptyLayer(t *testing.T) {
+	t.Parallel()
+
+	script := string(renderShellScript("default", []shellSection{{Layer: "base", Config: map[string]interface{}{}}}))
+	assert.Contains(t, script, "# >>> layer: base\n# nothing to export\n# <<< layer: base\n")
+	assert.NotContains(t, script, "Ensure Homebrew is installed", "Homebrew is only installed for brew packages")
+}
+
+func TestShellWord(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "node@20", shellWord("node@20"))
+	assert.Equal(t, "hashicorp/tap/terraform", shellWord("hashicorp/tap/terraform"))
+	assert.Equal(t, "'a b'", shellWord("a b"))
+	assert.Equal(t, `"a \"b\" \$c \\"`, shellDoubleQuote(`a "b" $c \`))
+}
@@ -188,7 +188,7 @@ func TestBoostB_WriteEnvFile_WritesCorrectContent(t *testing.T) {
 	err := WriteEnvFile(vars)
 	require.NoError(t, err)
 
-	envPath := filepath.Join(tmpDir, ".preflight", "env.sh")
+	envPath := filepath.Join(profileEnvDir(), "env.sh")
 	content, err := os.ReadFile(envPath)
 	require.NoError(t, err)
 
@@ -208,7 +208,7 @@ func TestBoostB_WriteEnvFile_EmptyVars(t *testing.T) {
 	err := WriteEnvFile([]EnvVar{})
 	require.NoError(t, err)
 
-	envPath := filepath.Join(tmpDir, ".preflight", "env.sh")
+	envPath := filepath.Join(profileEnvDir(), "env.sh")
 	content, err := os.ReadFile(envPath)
 	require.NoError(t, err)
 
@@ -230,7 +230,7 @@ func TestBoostB_WriteEnvFile_AllSecretsSkipped(t *testing.T) {
 	err := WriteEnvFile(vars)
 	require.NoError(t, err)
 
-	envPath := filepath.Join(tmpDir, ".preflight", "env.sh")
+	envPath := filepath.Join(profileEnvDir(), "env.sh")
 	content, err := os.ReadFile(envPath)
 	require.NoError(t, err)
 
@@ -244,7 +244,7 @@ func TestBoostB_WriteEnvFile_CreatesDirectory(t *testing.T) {
 	t.Setenv("HOME", tmpDir)
 
 	// Ensure .preflight dir does not exist
-	preflightDir := filepath.Join(tmpDir, ".preflight")
+	preflightDir := profileEnvDir()
 	_, err := os.Stat(preflightDir)
 	assert.True(t, os.IsNotExist(err))
 
@@ -1077,14 +1077,15 @@ func TestBoostB_CatalogListCmd_HasAliases(t *testing.T) {
 }
 
 //nolint:tparallel // Test modifies global state (catalogVerifySigs)
-func TestBoostB_RunCatalogVerify_SignaturesNotSupported(t *testing.T) {
+func TestBoostB_RunCatalogVerify_SignaturesNoTrustedRoot(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
 	origSigs := catalogVerifySigs
 	defer func() { catalogVerifySigs = origSigs }()
 	catalogVerifySigs = true
 
 	err := runCatalogVerify(nil, nil)
 	require.Error(t, err)
-	assert.Contains(t, err.Error(), "--signatures is not supported yet")
+	assert.Contains(t, err.Error(), "no Sigstore trusted root")
 }
 
 // ---------------------------------------------------------------------------
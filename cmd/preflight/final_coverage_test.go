@@ -15,6 +15,7 @@ import (
 	"github.com/felixgeelhaar/preflight/internal/domain/execution"
 	"github.com/felixgeelhaar/preflight/internal/domain/marketplace"
 	"github.com/felixgeelhaar/preflight/internal/domain/sync"
+	"github.com/felixgeelhaar/preflight/internal/provider/files"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -54,6 +55,10 @@ func (m *fcMockPreflightClient) UpdateLockFromPlan(_ context.Context, _ string,
 	return m.updateLockErr
 }
 
+func (f *fcMockPreflightClient) RecordMachineState(context.Context, string, string, *execution.Plan) error {
+	return nil
+}
+
 func (m *fcMockPreflightClient) WithMode(_ config.ReproducibilityMode) preflightClient {
 	return m
 }
@@ -62,6 +67,22 @@ func (m *fcMockPreflightClient) WithRollbackOnFailure(_ bool) preflightClient {
 	return m
 }
 
+func (m *fcMockPreflightClient) WithRunLockWait(_ bool) preflightClient {
+	return m
+}
+
+func (m *fcMockPreflightClient) WithMergePreference(_ files.MergePreference) preflightClient {
+	return m
+}
+
+func (m *fcMockPreflightClient) WithProgress(execution.StepStartObserver, execution.StepObserver) preflightClient {
+	return m
+}
+
+func (m *fcMockPreflightClient) LastTranscript() *app.Transcript {
+	return nil
+}
+
 // ---------------------------------------------------------------------------
 // Mock watchPreflight
 // ---------------------------------------------------------------------------
@@ -82,8 +103,9 @@ func (m *fcMockWatchPreflight) PrintPlan(_ *execution.Plan) {}
 func (m *fcMockWatchPreflight) Apply(_ context.Context, _ *execution.Plan, _ bool) ([]execution.StepResult, error) {
 	return nil, nil
 }
-func (m *fcMockWatchPreflight) PrintResults(_ []execution.StepResult)                {}
-func (m *fcMockWatchPreflight) WithMode(_ config.ReproducibilityMode) watchPreflight { return m }
+func (m *fcMockWatchPreflight) PrintResults(_ []execution.StepResult)                   {}
+func (m *fcMockWatchPreflight) WithMode(_ config.ReproducibilityMode) watchPreflight    { return m }
+func (m *fcMockWatchPreflight) ReloadChangedPlugins(context.Context) []app.PluginReload { return nil }
 
 // fcMockWatchMode is a fake watch mode that returns immediately.
 type fcMockWatchMode struct {
@@ -147,6 +169,7 @@ func TestFinalCov_RunApply_PlanFails(t *testing.T) {
 
 //nolint:tparallel // modifies global state
 func TestFinalCov_RunApply_ApplyFails(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // apply records history
 	origNew := newPreflight
 	origDryRun := applyDryRun
 	origUpdateLock := applyUpdateLock
@@ -235,6 +258,7 @@ func TestFinalCov_RunApply_UpdateLockSuccess(t *testing.T) {
 
 //nolint:tparallel // modifies global state
 func TestFinalCov_RunApply_StepFailure(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // apply records history
 	origNew := newPreflight
 	origDryRun := applyDryRun
 	origUpdateLock := applyUpdateLock
@@ -272,6 +296,7 @@ func TestFinalCov_RunApply_StepFailure(t *testing.T) {
 
 //nolint:tparallel // modifies global state
 func TestFinalCov_RunApply_UpdateLockFails(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // apply records history
 	origNew := newPreflight
 	origDryRun := applyDryRun
 	origUpdateLock := applyUpdateLock
@@ -13,6 +13,7 @@ import (
 	"github.com/felixgeelhaar/preflight/internal/domain/fleet/execution"
 	"github.com/felixgeelhaar/preflight/internal/domain/fleet/targeting"
 	"github.com/felixgeelhaar/preflight/internal/domain/fleet/transport"
+	"github.com/felixgeelhaar/preflight/internal/offline"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 )
@@ -354,6 +355,9 @@ func runFleetPing(_ *cobra.Command, _ []string) error {
 	if err := requireExperimental("fleet"); err != nil {
 		return err
 	}
+	if offline.Enabled() {
+		return offline.Skipped("fleet ping")
+	}
 	inv, err := loadFleetInventory()
 	if err != nil {
 		return err
@@ -408,6 +412,9 @@ func runFleetPlan(_ *cobra.Command, _ []string) error {
 	if err := requireExperimental("fleet"); err != nil {
 		return err
 	}
+	if offline.Enabled() {
+		return offline.Skipped("fleet plan")
+	}
 	inv, err := loadFleetInventory()
 	if err != nil {
 		return err
@@ -481,6 +488,9 @@ func runFleetApply(_ *cobra.Command, _ []string) error {
 	if err := requireExperimental("fleet"); err != nil {
 		return err
 	}
+	if offline.Enabled() {
+		return offline.Skipped("fleet apply")
+	}
 	inv, err := loadFleetInventory()
 	if err != nil {
 		return err
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/spf13/cobra"
+)
+
+var fmtCmd = &cobra.Command{
+	Use:   "fmt",
+	Short: "Format preflight.yaml and layer files",
+	Long: `Fmt normalizes preflight.yaml and the files in layers/:
+
+  - keys in canonical order
+  - package lists sorted, without duplicates
+  - packages removed from a layer when an earlier layer of every target
+    using it already declares them
+  - two-space indentation
+
+Comments are kept. Without flags, fmt lists the files it would change.
+
+Examples:
+  preflight fmt            # Show what would change
+  preflight fmt --write    # Rewrite files in place
+  preflight fmt --check    # Exit 1 when files are not formatted (CI)`,
+	Args: cobra.NoArgs,
+	RunE: runFmt,
+}
+
+var (
+	fmtCheck bool
+	fmtWrite bool
+)
+
+func init() {
+	fmtCmd.Flags().BoolVar(&fmtCheck, "check", false, "Exit with code 1 if any file is not formatted")
+	fmtCmd.Flags().BoolVar(&fmtWrite, "write", false, "Write formatted files in place")
+	fmtCmd.MarkFlagsMutuallyExclusive("check", "write")
+
+	rootCmd.AddCommand(fmtCmd)
+}
+
+func runFmt(_ *cobra.Command, _ []string) error {
+	configPath := cfgFile
+	if configPath == "" {
+		configPath = "preflight.yaml"
+	}
+
+	files, err := app.FormatConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	changed := 0
+	for _, file := range files {
+		if !file.Changed() {
+			continue
+		}
+		changed++
+		if fmtWrite {
+			info, err := os.Stat(file.Path)
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(file.Path, file.Formatted, info.Mode().Perm()); err != nil {
+				return fmt.Errorf("failed to write %s: %w", file.Path, err)
+			}
+			fmt.Printf("Formatted %s\n", file.Path)
+		} else {
+			fmt.Println(file.Path)
+		}
+		for _, change := range file.Changes {
+			fmt.Printf("  - %s\n", change)
+		}
+	}
+
+	if changed == 0 {
+		if !fmtCheck {
+			fmt.Println("All configuration files are formatted.")
+		}
+		return nil
+	}
+	if fmtCheck {
+		fmt.Fprintf(os.Stderr, "\n%d file(s) not formatted; run 'preflight fmt --write'\n", changed)
+		return withExitCode(exitDrift, nil)
+	}
+	if !fmtWrite {
+		fmt.Printf("\nRun 'preflight fmt --write' to apply.\n")
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunFmt(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "preflight.yaml")
+	layerPath := filepath.Join(dir, "layers", "base.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("targets:\n  default: [base]\n"), 0o644))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "layers"), 0o755))
+	require.NoError(t, os.WriteFile(layerPath, []byte("name: base\npackages:\n  brew:\n    formulae: [jq, git, jq]\n"), 0o644))
+
+	prevCfg, prevCheck, prevWrite := cfgFile, fmtCheck, fmtWrite
+	t.Cleanup(func() { cfgFile, fmtCheck, fmtWrite = prevCfg, prevCheck, prevWrite })
+	cfgFile = configPath
+
+	fmtCheck, fmtWrite = true, false
+	var err error
+	output := captureStdout(t, func() { err = runFmt(nil, nil) })
+	assert.Equal(t, exitDrift, exitCodeFor(err))
+	assert.Contains(t, output, layerPath)
+	assert.Contains(t, output, "removed duplicate jq from packages.brew.formulae")
+	assert.NotContains(t, output, configPath+"\n")
+
+	fmtCheck, fmtWrite = false, true
+	captureStdout(t, func() { require.NoError(t, runFmt(nil, nil)) })
+	data, err := os.ReadFile(layerPath)
+	require.NoError(t, err)
+	assert.Equal(t, "name: base\npackages:\n  brew:\n    formulae: [git, jq]\n", string(data))
+
+	fmtCheck, fmtWrite = true, false
+	captureStdout(t, func() { require.NoError(t, runFmt(nil, nil)) })
+}
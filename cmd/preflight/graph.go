@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/spf13/cobra"
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Draw the configuration as a graph",
+	Long: `Draw the configuration as a dependency graph of targets, the layers they
+merge, the providers the layers use and the packages they install.
+
+Targets point to the targets they extend and layers to the layers they
+require, with dashed edges. The graph is written in Graphviz DOT or as a
+Mermaid flowchart, which GitHub renders in Markdown.
+
+Examples:
+  preflight graph | dot -Tsvg > config.svg         # Render with Graphviz
+  preflight graph --format mermaid -o docs/config.mmd
+  preflight graph --target work --no-packages      # One target, down to providers
+  preflight graph --provider brew,npm              # Only brew and npm packages`,
+	Args: cobra.NoArgs,
+	RunE: runGraph,
+}
+
+var (
+	graphConfigPath string
+	graphFormat     string
+	graphOutput     string
+	graphTargets    []string
+	graphLayers     []string
+	graphProviders  []string
+	graphNoPackages bool
+)
+
+func init() {
+	rootCmd.AddCommand(graphCmd)
+
+	graphCmd.Flags().StringVarP(&graphConfigPath, "config", "c", "preflight.yaml", "Path to preflight.yaml")
+	graphCmd.Flags().StringVarP(&graphFormat, "format", "f", "dot", "Output format (dot, mermaid)")
+	graphCmd.Flags().StringVarP(&graphOutput, "output", "o", "", "Write to a file instead of stdout")
+	graphCmd.Flags().StringSliceVarP(&graphTargets, "target", "t", nil, "Only these targets and the targets they extend")
+	graphCmd.Flags().StringSliceVar(&graphLayers, "layer", nil, "Only these layers")
+	graphCmd.Flags().StringSliceVar(&graphProviders, "provider", nil, "Only these providers (brew, apt, npm, go, pip, gem, cargo, mas, runtime, vscode)")
+	graphCmd.Flags().BoolVar(&graphNoPackages, "no-packages", false, "End the graph at the providers")
+}
+
+func runGraph(_ *cobra.Command, _ []string) error {
+	var render func(*app.ConfigGraph) string
+	switch strings.ToLower(graphFormat) {
+	case "dot":
+		render = renderGraphDOT
+	case "mermaid":
+		render = renderGraphMermaid
+	default:
+		return fmt.Errorf("unsupported format: %s (use dot or mermaid)", graphFormat)
+	}
+
+	graph, err := app.BuildConfigGraph(graphConfigPath, app.ConfigGraphOptions{
+		Targets:      graphTargets,
+		Layers:       graphLayers,
+		Providers:    graphProviders,
+		OmitPackages: graphNoPackages,
+	})
+	if err != nil {
+		return err
+	}
+	output := render(graph)
+
+	if graphOutput == "" {
+		fmt.Print(output)
+		return nil
+	}
+	if dir := filepath.Dir(graphOutput); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(graphOutput, []byte(output), 0o644); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %s\n", graphOutput)
+	return nil
+}
+
+// graphDOTNodeStyles are the DOT attributes of each kind of node.
+var graphDOTNodeStyles = map[app.GraphNodeKind]string{
+	app.GraphNodeTarget:   `shape=box, style="rounded,filled", fillcolor="#dbeafe"`,
+	app.GraphNodeLayer:    `shape=box, style=filled, fillcolor="#dcfce7"`,
+	app.GraphNodeProvider: `shape=hexagon, style=filled, fillcolor="#fef3c7"`,
+	app.GraphNodePackage:  `shape=ellipse`,
+}
+
+// renderGraphDOT renders a graph in Graphviz DOT.
+func renderGraphDOT(g *app.ConfigGraph) string {
+	var sb strings.Builder
+	sb.WriteString("digraph preflight {\n")
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString("  node [fontname=\"Helvetica\"];\n")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&sb, "  %s [label=%s, %s];\n", strconv.Quote(n.ID), strconv.Quote(n.Label), graphDOTNodeStyles[n.Kind])
+	}
+	for _, e := range g.Edges {
+		attrs := ""
+		if e.Kind == app.GraphEdgeExtends || e.Kind == app.GraphEdgeRequires {
+			attrs = fmt.Sprintf(" [style=dashed, label=%q]", string(e.Kind))
+		}
+		fmt.Fprintf(&sb, "  %s -> %s%s;\n", strconv.Quote(e.From), strconv.Quote(e.To), attrs)
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// graphMermaidShapes are the Mermaid brackets of each kind of node.
+var graphMermaidShapes = map[app.GraphNodeKind][2]string{
+	app.GraphNodeTarget:   {"([", "])"},
+	app.GraphNodeLayer:    {"[", "]"},
+	app.GraphNodeProvider: {"{{", "}}"},
+	app.GraphNodePackage:  {"(", ")"},
+}
+
+// renderGraphMermaid renders a graph as a Mermaid flowchart. Mermaid IDs
+// cannot hold the characters of node IDs, so nodes are numbered.
+func renderGraphMermaid(g *app.ConfigGraph) string {
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+	ids := make(map[string]string, len(g.Nodes))
+	for i, n := range g.Nodes {
+		ids[n.ID] = "n" + strconv.Itoa(i)
+		shape := graphMermaidShapes[n.Kind]
+		label := strings.ReplaceAll(n.Label, `"`, "#quot;")
+		fmt.Fprintf(&sb, "  %s%s\"%s\"%s:::%s\n", ids[n.ID], shape[0], label, shape[1], n.Kind)
+	}
+	for _, e := range g.Edges {
+		if e.Kind == app.GraphEdgeExtends || e.Kind == app.GraphEdgeRequires {
+			fmt.Fprintf(&sb, "  %s -. %s .-> %s\n", ids[e.From], e.Kind, ids[e.To])
+			continue
+		}
+		fmt.Fprintf(&sb, "  %s --> %s\n", ids[e.From], ids[e.To])
+	}
+	sb.WriteString("  classDef target fill:#dbeafe\n")
+	sb.WriteString("  classDef layer fill:#dcfce7\n")
+	sb.WriteString("  classDef provider fill:#fef3c7\n")
+	return sb.String()
+}
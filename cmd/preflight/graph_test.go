@@ -0,0 +1,80 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testConfigGraph = &app.ConfigGraph{
+	Nodes: []app.GraphNode{
+		{ID: "target:laptop", Kind: app.GraphNodeTarget, Label: "laptop"},
+		{ID: "target:work", Kind: app.GraphNodeTarget, Label: "work"},
+		{ID: "layer:dev-node", Kind: app.GraphNodeLayer, Label: "dev-node"},
+		{ID: "layer:node", Kind: app.GraphNodeLayer, Label: "node"},
+		{ID: "provider:npm", Kind: app.GraphNodeProvider, Label: "npm"},
+		{ID: "package:npm:@scope/cli", Kind: app.GraphNodePackage, Label: `@scope/"cli"`},
+	},
+	Edges: []app.GraphEdge{
+		{From: "target:laptop", To: "target:work", Kind: app.GraphEdgeExtends},
+		{From: "target:work", To: "layer:dev-node", Kind: app.GraphEdgeMerges},
+		{From: "layer:dev-node", To: "layer:node", Kind: app.GraphEdgeRequires},
+		{From: "layer:dev-node", To: "provider:npm", Kind: app.GraphEdgeUses},
+		{From: "provider:npm", To: "package:npm:@scope/cli", Kind: app.GraphEdgeInstalls},
+	},
+}
+
+func TestRenderGraphDOT(t *testing.T) {
+	t.Parallel()
+
+	out := renderGraphDOT(testConfigGraph)
+	assert.Contains(t, out, "digraph preflight {\n  rankdir=LR;\n")
+	assert.Contains(t, out, `  "target:work" [label="work", shape=box, style="rounded,filled", fillcolor="#dbeafe"];`)
+	assert.Contains(t, out, `  "package:npm:@scope/cli" [label="@scope/\"cli\"", shape=ellipse];`)
+	assert.Contains(t, out, `  "target:laptop" -> "target:work" [style=dashed, label="extends"];`)
+	assert.Contains(t, out, `  "layer:dev-node" -> "layer:node" [style=dashed, label="requires"];`)
+	assert.Contains(t, out, `  "target:work" -> "layer:dev-node";`)
+	assert.Contains(t, out, "}\n")
+}
+
+func TestRenderGraphMermaid(t *testing.T) {
+	t.Parallel()
+
+	out := renderGraphMermaid(testConfigGraph)
+	assert.Contains(t, out, "flowchart LR\n")
+	assert.Contains(t, out, "  n0([\"laptop\"]):::target\n")
+	assert.Contains(t, out, "  n2[\"dev-node\"]:::layer\n")
+	assert.Contains(t, out, "  n4{{\"npm\"}}:::provider\n")
+	assert.Contains(t, out, "  n5(\"@scope/#quot;cli#quot;\"):::package\n")
+	assert.Contains(t, out, "  n0 -. extends .-> n1\n")
+	assert.Contains(t, out, "  n2 -. requires .-> n3\n")
+	assert.Contains(t, out, "  n4 --> n5\n")
+}
+
+func TestRunGraph(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "layers"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "preflight.yaml"), []byte("targets:\n  work: [base]\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "layers", "base.yaml"), []byte("name: base\npackages:\n  brew:\n    formulae: [git]\n"), 0o644))
+
+	graphConfigPath = filepath.Join(dir, "preflight.yaml")
+	graphFormat = "mermaid"
+	graphOutput = filepath.Join(dir, "docs", "config.mmd")
+	t.Cleanup(func() {
+		graphConfigPath = "preflight.yaml"
+		graphFormat = "dot"
+		graphOutput = ""
+	})
+
+	require.NoError(t, runGraph(graphCmd, nil))
+	data, err := os.ReadFile(graphOutput)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "n2{{\"brew\"}}:::provider\n")
+
+	graphFormat = "svg"
+	require.ErrorContains(t, runGraph(graphCmd, nil), "unsupported format: svg")
+}
@@ -417,7 +417,7 @@ func TestSaveHistoryEntry(t *testing.T) {
 	require.NoError(t, err)
 
 	// Verify file was created
-	historyPath := filepath.Join(tmpDir, ".preflight", "history", "test-entry-123.json")
+	historyPath := filepath.Join(getHistoryDir(), "test-entry-123.json")
 	_, err = os.Stat(historyPath)
 	assert.NoError(t, err)
 }
@@ -437,7 +437,7 @@ func TestWriteEnvFile(t *testing.T) {
 	require.NoError(t, err)
 
 	// Read and verify content
-	content, err := os.ReadFile(filepath.Join(tmpDir, ".preflight", "env.sh"))
+	content, err := os.ReadFile(filepath.Join(profileEnvDir(), "env.sh"))
 	require.NoError(t, err)
 
 	// Should contain non-secret vars
@@ -636,9 +636,10 @@ func TestGetProfileDir(t *testing.T) {
 	// Not parallel - modifies HOME env var
 	tmpDir := t.TempDir()
 	t.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_CONFIG_HOME", "")
 
 	dir := getProfileDir()
-	assert.Equal(t, filepath.Join(tmpDir, ".preflight", "profiles"), dir)
+	assert.Equal(t, filepath.Join(tmpDir, ".config", "preflight", "profiles"), dir)
 }
 
 func TestGetCurrentProfile_NoProfile(t *testing.T) {
@@ -1614,7 +1615,7 @@ func TestSaveHistoryEntry_WithDefaults(t *testing.T) {
 	require.NoError(t, err)
 
 	// Verify file was created
-	historyDir := filepath.Join(tmpDir, ".preflight", "history")
+	historyDir := getHistoryDir()
 	files, err := os.ReadDir(historyDir)
 	require.NoError(t, err)
 	assert.Len(t, files, 1)
@@ -1637,7 +1638,7 @@ func TestSaveHistoryEntry_WithProvidedID(t *testing.T) {
 	require.NoError(t, err)
 
 	// Verify file was created with correct name
-	historyDir := filepath.Join(tmpDir, ".preflight", "history")
+	historyDir := getHistoryDir()
 	expectedFile := filepath.Join(historyDir, "custom-id-123.json")
 	_, err = os.Stat(expectedFile)
 	assert.NoError(t, err)
@@ -1685,7 +1686,7 @@ func TestSetCurrentProfile(t *testing.T) {
 	require.NoError(t, err)
 
 	// Verify file was created
-	data, err := os.ReadFile(filepath.Join(tmpDir, ".preflight", "profiles", "current"))
+	data, err := os.ReadFile(filepath.Join(getProfileDir(), "current"))
 	require.NoError(t, err)
 	assert.Equal(t, "my-profile", string(data))
 }
@@ -1746,7 +1747,7 @@ func TestSaveCustomProfiles(t *testing.T) {
 	require.NoError(t, err)
 
 	// Verify file was created
-	data, err := os.ReadFile(filepath.Join(tmpDir, ".preflight", "profiles", "profiles.yaml"))
+	data, err := os.ReadFile(filepath.Join(getProfileDir(), "profiles.yaml"))
 	require.NoError(t, err)
 	assert.Contains(t, string(data), "test-profile")
 	assert.Contains(t, string(data), "A test profile")
@@ -2438,7 +2439,7 @@ func TestWriteEnvFile_Success(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Verify file was written
-	envPath := filepath.Join(tmpDir, ".preflight", "env.sh")
+	envPath := filepath.Join(profileEnvDir(), "env.sh")
 	data, err := os.ReadFile(envPath)
 	assert.NoError(t, err)
 	assert.Contains(t, string(data), "KEY")
@@ -3166,7 +3167,7 @@ func TestSaveHistoryEntry_AutoPopulatesID(t *testing.T) {
 	require.NoError(t, err)
 
 	// Verify file was created
-	historyDir := filepath.Join(tmpDir, ".preflight", "history")
+	historyDir := getHistoryDir()
 	files, err := os.ReadDir(historyDir)
 	require.NoError(t, err)
 	assert.Len(t, files, 1)
@@ -3175,9 +3176,10 @@ func TestSaveHistoryEntry_AutoPopulatesID(t *testing.T) {
 func TestGetHistoryDir(t *testing.T) {
 	tmpDir := t.TempDir()
 	t.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_STATE_HOME", "")
 
 	dir := getHistoryDir()
-	assert.Contains(t, dir, ".preflight")
+	assert.Contains(t, dir, filepath.Join(".local", "state", "preflight"))
 	assert.Contains(t, dir, "history")
 }
 
@@ -3195,7 +3197,7 @@ func TestWriteEnvFile_WithSecrets(t *testing.T) {
 	require.NoError(t, err)
 
 	// Verify file was created
-	envPath := filepath.Join(tmpDir, ".preflight", "env.sh")
+	envPath := filepath.Join(profileEnvDir(), "env.sh")
 	content, err := os.ReadFile(envPath)
 	require.NoError(t, err)
 
@@ -3212,7 +3214,7 @@ func TestWriteEnvFile_EmptyList(t *testing.T) {
 	require.NoError(t, err)
 
 	// Verify file was created with header
-	envPath := filepath.Join(tmpDir, ".preflight", "env.sh")
+	envPath := filepath.Join(profileEnvDir(), "env.sh")
 	content, err := os.ReadFile(envPath)
 	require.NoError(t, err)
 
@@ -3232,7 +3234,7 @@ func TestWriteEnvFile_AllSecrets(t *testing.T) {
 	require.NoError(t, err)
 
 	// Verify file was created but has no exports
-	envPath := filepath.Join(tmpDir, ".preflight", "env.sh")
+	envPath := filepath.Join(profileEnvDir(), "env.sh")
 	content, err := os.ReadFile(envPath)
 	require.NoError(t, err)
 
@@ -4310,7 +4312,7 @@ func TestSaveHistoryEntry_DefaultValues(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Verify the file was created
-	historyDir := filepath.Join(tmpDir, ".preflight", "history")
+	historyDir := getHistoryDir()
 	files, err := os.ReadDir(historyDir)
 	assert.NoError(t, err)
 	assert.Len(t, files, 1)
@@ -4330,7 +4332,7 @@ func TestWriteEnvFile_CreatesFile(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Verify file was created
-	envPath := filepath.Join(tmpDir, ".preflight", "env.sh")
+	envPath := filepath.Join(profileEnvDir(), "env.sh")
 	content, err := os.ReadFile(envPath)
 	require.NoError(t, err)
 	contentStr := string(content)
@@ -4531,9 +4533,10 @@ func TestGetCurrentProfile_Exists(t *testing.T) {
 func TestGetProfileDir_Default(t *testing.T) {
 	tmpDir := t.TempDir()
 	t.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_CONFIG_HOME", "")
 
 	dir := getProfileDir()
-	assert.Contains(t, dir, ".preflight")
+	assert.Contains(t, dir, filepath.Join(".config", "preflight"))
 	assert.Contains(t, dir, "profiles")
 }
 
@@ -4937,7 +4940,8 @@ func TestDetectAIProvider_AnthropicKey(t *testing.T) {
 }
 
 func TestDetectAIProvider_NoSupportedProvider(t *testing.T) {
-	// detectAIProvider only supports Anthropic and OpenAI, not Ollama
+	// OLLAMA_HOST alone does not opt in to a local model
+	t.Setenv("PREFLIGHT_AI_ENDPOINT", "")
 	t.Setenv("OPENAI_API_KEY", "")
 	t.Setenv("ANTHROPIC_API_KEY", "")
 	t.Setenv("OLLAMA_HOST", "http://localhost:11434")
@@ -10,6 +10,11 @@ import (
 	"text/tabwriter"
 	"time"
 
+	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/domain/execution"
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
+	"github.com/felixgeelhaar/preflight/internal/securestate"
 	"github.com/spf13/cobra"
 )
 
@@ -21,11 +26,12 @@ var historyCmd = &cobra.Command{
 The history tracks all apply, rollback, and doctor --fix operations,
 providing an audit trail of system modifications.
 
-History is stored locally in ~/.preflight/history/ and includes:
+History is stored locally in ~/.local/state/preflight/history/ and includes:
   - Timestamp of each operation
   - Command executed
   - Changes made (files, packages, etc.)
   - Success/failure status
+  - Per-step command output, file diffs and package version transitions
 
 Examples:
   preflight history                   # Show recent history
@@ -33,6 +39,9 @@ Examples:
   preflight history --since 7d        # Last 7 days
   preflight history --json            # JSON output
   preflight history --provider brew   # Filter by provider
+  preflight history show <id>         # Inspect one run in depth
+  preflight history diff <id1> <id2>  # Compare two runs
+  preflight history prune             # Apply the retention policy
   preflight history clear             # Clear history`,
 	RunE: runHistory,
 }
@@ -44,6 +53,52 @@ var historyClearCmd = &cobra.Command{
 	RunE:  runHistoryClear,
 }
 
+var historyShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show one history entry in depth",
+	Long: `Show the full transcript of a history entry: each step's status,
+the commands it ran with their output, the diffs of managed files it
+changed and package version transitions.
+
+The ID may be abbreviated to any unique prefix.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runHistoryShow,
+}
+
+var historyDiffCmd = &cobra.Command{
+	Use:   "diff <id1> <id2>",
+	Short: "Compare two history entries",
+	Long: `Compare two runs: their status, the steps whose outcome differs, the
+files each changed and the package versions each left behind.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runHistoryDiff,
+}
+
+var historyPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Prune history beyond the retention policy",
+	Long: `Remove history entries older than the retention age or beyond the
+newest maximum number of entries, and compact the rest into a single
+indexed file.
+
+The policy is read from the history section of preflight.yaml:
+
+  history:
+    keep: 90d          # h, d, w or m
+    max_entries: 1000
+
+Without it, entries older than 90 days and beyond the newest 1000 are
+pruned. Compaction also runs automatically after applies once 50 entries
+have accumulated.`,
+	RunE: runHistoryPrune,
+}
+
+var (
+	historyPruneKeep       string
+	historyPruneMaxEntries int
+	historyPruneDryRun     bool
+)
+
 var (
 	historyLimit    int
 	historySince    string
@@ -55,12 +110,19 @@ var (
 func init() {
 	rootCmd.AddCommand(historyCmd)
 	historyCmd.AddCommand(historyClearCmd)
+	historyCmd.AddCommand(historyShowCmd)
+	historyCmd.AddCommand(historyDiffCmd)
+	historyCmd.AddCommand(historyPruneCmd)
 
 	historyCmd.Flags().IntVarP(&historyLimit, "limit", "n", 20, "Maximum entries to show")
 	historyCmd.Flags().StringVar(&historySince, "since", "", "Show entries since (e.g., 1h, 7d, 2w)")
 	historyCmd.Flags().BoolVar(&historyJSON, "json", false, "Output as JSON")
 	historyCmd.Flags().StringVar(&historyProvider, "provider", "", "Filter by provider")
 	historyCmd.Flags().BoolVarP(&historyVerbose, "verbose", "v", false, "Show detailed output")
+	historyShowCmd.Flags().BoolVar(&historyJSON, "json", false, "Output as JSON")
+	historyPruneCmd.Flags().StringVar(&historyPruneKeep, "keep", "", "Keep entries newer than this age (e.g., 30d); overrides history.keep")
+	historyPruneCmd.Flags().IntVar(&historyPruneMaxEntries, "max-entries", 0, "Keep at most this many entries; overrides history.max_entries")
+	historyPruneCmd.Flags().BoolVar(&historyPruneDryRun, "dry-run", false, "Show what would be pruned without removing it")
 }
 
 // HistoryEntry represents a single history entry
@@ -73,6 +135,34 @@ type HistoryEntry struct {
 	Duration  string    `json:"duration,omitempty"`
 	Changes   []Change  `json:"changes,omitempty"`
 	Error     string    `json:"error,omitempty"`
+	// Steps, Files and Versions form the transcript of the run.
+	Steps    []StepTranscript        `json:"steps,omitempty"`
+	Files    []app.FileDiff          `json:"files,omitempty"`
+	Versions []app.VersionTransition `json:"versions,omitempty"`
+	// Snapshot, Uninstalls and Lockfile record how to undo an apply.
+	Snapshot   string          `json:"snapshot,omitempty"`
+	Uninstalls []app.Uninstall `json:"uninstalls,omitempty"`
+	Lockfile   *LockfileBackup `json:"lockfile,omitempty"`
+	// Backups are the copies of unmanaged files an apply overwrote.
+	Backups []app.FileBackup `json:"backups,omitempty"`
+	// Undoes is the ID of the apply an undo reverted.
+	Undoes string `json:"undoes,omitempty"`
+	// FreedBytes is the disk space a cleanup freed.
+	FreedBytes int64 `json:"freed_bytes,omitempty"`
+}
+
+// StepTranscript records how a step ran and the output of its commands
+// (the last 16 KiB of each stream).
+type StepTranscript struct {
+	ID        string   `json:"id"`
+	Status    string   `json:"status"`
+	Applied   bool     `json:"applied,omitempty"`
+	Duration  string   `json:"duration,omitempty"`
+	Error     string   `json:"error,omitempty"`
+	Commands  []string `json:"commands,omitempty"`
+	Stdout    string   `json:"stdout,omitempty"`
+	Stderr    string   `json:"stderr,omitempty"`
+	Truncated bool     `json:"truncated,omitempty"`
 }
 
 // Change represents a single change made
@@ -155,11 +245,50 @@ func runHistoryClear(_ *cobra.Command, _ []string) error {
 	return nil
 }
 
+func runHistoryPrune(_ *cobra.Command, _ []string) error {
+	configPath := cfgFile
+	if configPath == "" {
+		configPath = "preflight.yaml"
+	}
+	policy, err := historyPolicyFor(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load history policy: %w", err)
+	}
+	if historyPruneKeep != "" {
+		if policy.keep, err = parseDuration(historyPruneKeep); err != nil {
+			return fmt.Errorf("invalid --keep: %w", err)
+		}
+	}
+	if historyPruneMaxEntries < 0 {
+		return fmt.Errorf("invalid --max-entries: must not be negative")
+	}
+	if historyPruneMaxEntries > 0 {
+		policy.maxEntries = historyPruneMaxEntries
+	}
+
+	kept, pruned, err := compactHistory(policy, time.Now(), historyPruneDryRun)
+	if err != nil {
+		return fmt.Errorf("failed to prune history: %w", err)
+	}
+
+	if historyPruneDryRun {
+		fmt.Printf("Would prune %d entries and keep %d.\n", len(pruned), len(kept))
+		for _, e := range pruned {
+			fmt.Printf("  %s  %s  %s\n", e.ID, e.Timestamp.Format("2006-01-02 15:04"), e.Command)
+		}
+		return nil
+	}
+	fmt.Printf("Pruned %d entries; kept %d.\n", len(pruned), len(kept))
+	return nil
+}
+
 func getHistoryDir() string {
-	home, _ := os.UserHomeDir()
-	return filepath.Join(home, ".preflight", "history")
+	dir, _ := platform.Path(platform.StateDir, "history")
+	return dir
 }
 
+// loadHistory returns all entries. Compacted entries are read from the
+// index and come without their transcript; use loadHistoryEntry for it.
 func loadHistory() ([]HistoryEntry, error) {
 	historyDir := getHistoryDir()
 
@@ -167,30 +296,26 @@ func loadHistory() ([]HistoryEntry, error) {
 		return nil, nil
 	}
 
-	files, err := os.ReadDir(historyDir)
+	index, err := readHistoryIndex(historyDir)
+	if err != nil {
+		return nil, err
+	}
+	loose, _, err := readLooseHistory(historyDir)
 	if err != nil {
 		return nil, err
 	}
 
-	entries := make([]HistoryEntry, 0, len(files))
-	for _, f := range files {
-		if !strings.HasSuffix(f.Name(), ".json") {
-			continue
-		}
-
-		data, err := os.ReadFile(filepath.Join(historyDir, f.Name()))
-		if err != nil {
-			continue
-		}
-
-		var entry HistoryEntry
-		if err := json.Unmarshal(data, &entry); err != nil {
-			continue
+	entries := make([]HistoryEntry, 0, len(index.Entries)+len(loose))
+	seen := make(map[string]bool, len(loose))
+	for _, e := range loose {
+		seen[e.ID] = true
+		entries = append(entries, e)
+	}
+	for _, ref := range index.Entries {
+		if !seen[ref.ID] {
+			entries = append(entries, ref.HistoryEntry)
 		}
-
-		entries = append(entries, entry)
 	}
-
 	return entries, nil
 }
 
@@ -247,6 +372,9 @@ func outputHistoryText(entries []HistoryEntry) {
 					fmt.Printf("    [%s] %s: %s\n", c.Provider, c.Action, c.Item)
 				}
 			}
+			if e.FreedBytes > 0 {
+				fmt.Printf("  Freed:    %s\n", formatByteSize(e.FreedBytes))
+			}
 			if e.Error != "" {
 				fmt.Printf("  Error:    %s\n", e.Error)
 			}
@@ -323,5 +451,344 @@ func SaveHistoryEntry(entry HistoryEntry) error {
 	}
 
 	filename := fmt.Sprintf("%s.json", entry.ID)
-	return os.WriteFile(filepath.Join(historyDir, filename), data, 0o644)
+	return securestate.WriteFile(filepath.Join(historyDir, filename), data, 0o644)
+}
+
+// newApplyHistoryEntry builds the history entry of an apply from its step
+// results and transcript. Steps that were already satisfied are omitted.
+func newApplyHistoryEntry(target string, started time.Time, results []execution.StepResult, transcript *app.Transcript, applyErr error) HistoryEntry {
+	entry := HistoryEntry{
+		Timestamp: started,
+		Command:   "apply",
+		Target:    target,
+		Duration:  time.Since(started).Round(time.Millisecond).String(),
+	}
+	if transcript != nil {
+		entry.Files = transcript.Files
+		entry.Versions = transcript.Versions
+		entry.Snapshot = transcript.Snapshot
+		entry.Uninstalls = transcript.Uninstalls
+		entry.Backups = transcript.Backups
+	}
+
+	applied, failed := 0, 0
+	for _, result := range results {
+		output, hasOutput := transcript.Output(result.StepID().String())
+		if !result.Applied() && result.Status() == compiler.StatusSatisfied && !hasOutput {
+			continue
+		}
+		step := StepTranscript{
+			ID:        result.StepID().String(),
+			Status:    string(result.Status()),
+			Applied:   result.Applied(),
+			Commands:  output.Commands,
+			Stdout:    output.Stdout,
+			Stderr:    output.Stderr,
+			Truncated: output.Truncated,
+		}
+		if result.Duration() > 0 {
+			step.Duration = result.Duration().Round(time.Millisecond).String()
+		}
+		if result.Error() != nil {
+			step.Error = result.Error().Error()
+		}
+		entry.Steps = append(entry.Steps, step)
+
+		switch {
+		case result.Applied():
+			applied++
+			diff := result.Diff()
+			item := diff.Name()
+			if item == "" {
+				item = result.StepID().String()
+			}
+			entry.Changes = append(entry.Changes, Change{
+				Provider: result.StepID().Provider(),
+				Action:   changeAction(diff.Type()),
+				Item:     item,
+				Details:  diff.Summary(),
+			})
+		case result.Status() == compiler.StatusFailed:
+			failed++
+		}
+	}
+
+	switch {
+	case applyErr == nil && failed == 0:
+		entry.Status = "success"
+	case applied > 0:
+		entry.Status = "partial"
+	default:
+		entry.Status = "failed"
+	}
+	if applyErr != nil {
+		entry.Error = applyErr.Error()
+	}
+	return entry
+}
+
+func changeAction(diffType compiler.DiffType) string {
+	switch diffType {
+	case compiler.DiffTypeAdd:
+		return "create"
+	case compiler.DiffTypeRemove:
+		return "remove"
+	default:
+		return "modify"
+	}
+}
+
+// findHistoryEntry returns the full entry whose ID is id or starts with it.
+func findHistoryEntry(id string) (HistoryEntry, error) {
+	entries, err := loadHistory()
+	if err != nil {
+		return HistoryEntry{}, fmt.Errorf("failed to load history: %w", err)
+	}
+	var matches []HistoryEntry
+	for _, e := range entries {
+		if e.ID == id {
+			return loadHistoryEntry(e.ID)
+		}
+		if strings.HasPrefix(e.ID, id) {
+			matches = append(matches, e)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return HistoryEntry{}, fmt.Errorf("no history entry %q; run 'preflight history -v' to list IDs", id)
+	case 1:
+		return loadHistoryEntry(matches[0].ID)
+	default:
+		return HistoryEntry{}, fmt.Errorf("history entry ID %q is ambiguous (%d matches)", id, len(matches))
+	}
+}
+
+func runHistoryShow(_ *cobra.Command, args []string) error {
+	entry, err := findHistoryEntry(args[0])
+	if err != nil {
+		return err
+	}
+	if historyJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entry)
+	}
+	outputHistoryShow(entry)
+	return nil
+}
+
+func outputHistoryShow(e HistoryEntry) {
+	fmt.Printf("─── %s ───\n", e.ID)
+	fmt.Printf("  Time:     %s\n", e.Timestamp.Format("2006-01-02 15:04:05"))
+	fmt.Printf("  Command:  %s\n", e.Command)
+	if e.Target != "" {
+		fmt.Printf("  Target:   %s\n", e.Target)
+	}
+	fmt.Printf("  Status:   %s\n", formatStatus(e.Status))
+	if e.Duration != "" {
+		fmt.Printf("  Duration: %s\n", e.Duration)
+	}
+	if e.Error != "" {
+		fmt.Printf("  Error:    %s\n", e.Error)
+	}
+
+	if len(e.Steps) > 0 {
+		fmt.Println("\nSteps:")
+		for _, s := range e.Steps {
+			line := fmt.Sprintf("  %s  %s", s.Status, s.ID)
+			if s.Duration != "" {
+				line += " (" + s.Duration + ")"
+			}
+			fmt.Println(line)
+			if s.Error != "" {
+				fmt.Printf("    error: %s\n", s.Error)
+			}
+			for _, c := range s.Commands {
+				fmt.Printf("    $ %s\n", c)
+			}
+			if s.Truncated {
+				fmt.Println("    (earlier output truncated)")
+			}
+			printIndented("stdout", s.Stdout)
+			printIndented("stderr", s.Stderr)
+		}
+	}
+
+	if len(e.Versions) > 0 {
+		fmt.Println("\nPackage versions:")
+		for _, v := range e.Versions {
+			fmt.Printf("  [%s] %s: %s → %s\n", v.Provider, v.Name, versionOrNone(v.From), versionOrNone(v.To))
+		}
+	}
+
+	if len(e.Files) > 0 {
+		fmt.Println("\nFile changes:")
+		for _, f := range e.Files {
+			fmt.Printf("  %s (%s)\n", f.Path, f.StepID)
+			for _, line := range strings.Split(strings.TrimSuffix(f.Diff, "\n"), "\n") {
+				fmt.Printf("    %s\n", line)
+			}
+		}
+	}
+
+	if len(e.Backups) > 0 {
+		fmt.Println("\nBackups:")
+		for _, b := range e.Backups {
+			fmt.Printf("  %s → %s\n", b.Path, b.Backup)
+		}
+		fmt.Println("\nRestore a file with 'preflight restore-file <path>'.")
+	}
+}
+
+func printIndented(label, text string) {
+	text = strings.TrimRight(text, "\n")
+	if text == "" {
+		return
+	}
+	fmt.Printf("    %s:\n", label)
+	for _, line := range strings.Split(text, "\n") {
+		fmt.Printf("      %s\n", line)
+	}
+}
+
+func versionOrNone(version string) string {
+	if version == "" {
+		return "(none)"
+	}
+	return version
+}
+
+func runHistoryDiff(_ *cobra.Command, args []string) error {
+	a, err := findHistoryEntry(args[0])
+	if err != nil {
+		return err
+	}
+	b, err := findHistoryEntry(args[1])
+	if err != nil {
+		return err
+	}
+	outputHistoryDiff(a, b)
+	return nil
+}
+
+func outputHistoryDiff(a, b HistoryEntry) {
+	fmt.Printf("--- %s  %s  %s\n", a.ID, a.Timestamp.Format("2006-01-02 15:04:05"), formatStatus(a.Status))
+	fmt.Printf("+++ %s  %s  %s\n", b.ID, b.Timestamp.Format("2006-01-02 15:04:05"), formatStatus(b.Status))
+	if a.Target != b.Target {
+		fmt.Printf("\nTarget: %s → %s\n", a.Target, b.Target)
+	}
+
+	differences := 0
+
+	stepsA := make(map[string]StepTranscript, len(a.Steps))
+	for _, s := range a.Steps {
+		stepsA[s.ID] = s
+	}
+	stepsB := make(map[string]StepTranscript, len(b.Steps))
+	for _, s := range b.Steps {
+		stepsB[s.ID] = s
+	}
+	var stepLines []string
+	for _, id := range sortedUnion(keysOf(stepsA), keysOf(stepsB)) {
+		sa, inA := stepsA[id]
+		sb, inB := stepsB[id]
+		switch {
+		case !inB:
+			stepLines = append(stepLines, fmt.Sprintf("  - %s (%s)", id, sa.Status))
+		case !inA:
+			stepLines = append(stepLines, fmt.Sprintf("  + %s (%s)", id, sb.Status))
+		case sa.Status != sb.Status:
+			stepLines = append(stepLines, fmt.Sprintf("  ~ %s: %s → %s", id, sa.Status, sb.Status))
+		}
+	}
+	if len(stepLines) > 0 {
+		differences += len(stepLines)
+		fmt.Println("\nSteps:")
+		for _, line := range stepLines {
+			fmt.Println(line)
+		}
+	}
+
+	versionsA := resultingVersions(a.Versions)
+	versionsB := resultingVersions(b.Versions)
+	var versionLines []string
+	for _, key := range sortedUnion(keysOf(versionsA), keysOf(versionsB)) {
+		va, inA := versionsA[key]
+		vb, inB := versionsB[key]
+		switch {
+		case !inB:
+			versionLines = append(versionLines, fmt.Sprintf("  - %s: %s", key, versionOrNone(va)))
+		case !inA:
+			versionLines = append(versionLines, fmt.Sprintf("  + %s: %s", key, versionOrNone(vb)))
+		case va != vb:
+			versionLines = append(versionLines, fmt.Sprintf("  ~ %s: %s → %s", key, versionOrNone(va), versionOrNone(vb)))
+		}
+	}
+	if len(versionLines) > 0 {
+		differences += len(versionLines)
+		fmt.Println("\nPackage versions:")
+		for _, line := range versionLines {
+			fmt.Println(line)
+		}
+	}
+
+	filesA := make(map[string]bool, len(a.Files))
+	for _, f := range a.Files {
+		filesA[f.Path] = true
+	}
+	filesB := make(map[string]bool, len(b.Files))
+	for _, f := range b.Files {
+		filesB[f.Path] = true
+	}
+	var fileLines []string
+	for _, path := range sortedUnion(keysOf(filesA), keysOf(filesB)) {
+		switch {
+		case !filesB[path]:
+			fileLines = append(fileLines, "  - "+path+" (changed only in "+a.ID+")")
+		case !filesA[path]:
+			fileLines = append(fileLines, "  + "+path+" (changed only in "+b.ID+")")
+		}
+	}
+	if len(fileLines) > 0 {
+		differences += len(fileLines)
+		fmt.Println("\nFiles changed:")
+		for _, line := range fileLines {
+			fmt.Println(line)
+		}
+	}
+
+	if differences == 0 {
+		fmt.Println("\nNo differences in steps, package versions or changed files.")
+	}
+}
+
+// resultingVersions maps provider:name to the version each transition
+// left installed.
+func resultingVersions(transitions []app.VersionTransition) map[string]string {
+	versions := make(map[string]string, len(transitions))
+	for _, v := range transitions {
+		versions[v.Provider+":"+v.Name] = v.To
+	}
+	return versions
+}
+
+func keysOf[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func sortedUnion(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	var union []string
+	for _, k := range append(a, b...) {
+		if !seen[k] {
+			seen[k] = true
+			union = append(union, k)
+		}
+	}
+	sort.Strings(union)
+	return union
 }
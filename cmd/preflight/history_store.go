@@ -0,0 +1,323 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/securestate"
+)
+
+// History is written as one JSON file per entry and periodically compacted
+// into history.jsonl, one full entry per line, with index.json holding a
+// summary of each entry and its offset in the log so that listing does not
+// parse transcripts.
+const (
+	historyLogFile   = "history.jsonl"
+	historyIndexFile = "index.json"
+	// historyCompactThreshold is the number of entry files that triggers
+	// compaction after an apply.
+	historyCompactThreshold = 50
+
+	defaultHistoryKeep       = 90 * 24 * time.Hour
+	defaultHistoryMaxEntries = 1000
+)
+
+// historyIndex lists the compacted entries.
+type historyIndex struct {
+	Entries []historyIndexEntry `json:"entries"`
+}
+
+// historyIndexEntry is the summary of a compacted entry and the location
+// of its full record in the log.
+type historyIndexEntry struct {
+	HistoryEntry
+	Offset int64 `json:"offset"`
+	Length int   `json:"length"`
+}
+
+// historyPolicy bounds the history kept: entries older than keep and
+// beyond the newest maxEntries are pruned. Zero disables a bound.
+type historyPolicy struct {
+	keep       time.Duration
+	maxEntries int
+}
+
+// historyPolicyFor returns the retention configured in the manifest at
+// configPath, or the defaults when there is none.
+func historyPolicyFor(configPath string) (historyPolicy, error) {
+	policy := historyPolicy{keep: defaultHistoryKeep, maxEntries: defaultHistoryMaxEntries}
+	manifest, err := config.NewLoader().LoadManifest(configPath)
+	if err != nil {
+		if config.IsUserError(err, config.ErrCodeConfigNotFound) {
+			return policy, nil
+		}
+		return historyPolicy{}, err
+	}
+	if manifest.History.Keep != "" {
+		keep, err := parseDuration(manifest.History.Keep)
+		if err != nil {
+			return historyPolicy{}, fmt.Errorf("invalid history.keep: %w", err)
+		}
+		policy.keep = keep
+	}
+	if manifest.History.MaxEntries > 0 {
+		policy.maxEntries = manifest.History.MaxEntries
+	}
+	return policy, nil
+}
+
+// apply splits entries into those the policy keeps and those it prunes,
+// both newest first.
+func (p historyPolicy) apply(entries []HistoryEntry, now time.Time) (kept, pruned []HistoryEntry) {
+	sorted := append([]HistoryEntry(nil), entries...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.After(sorted[j].Timestamp)
+	})
+	for _, e := range sorted {
+		tooOld := p.keep > 0 && now.Sub(e.Timestamp) > p.keep
+		tooMany := p.maxEntries > 0 && len(kept) >= p.maxEntries
+		if tooOld || tooMany {
+			pruned = append(pruned, e)
+		} else {
+			kept = append(kept, e)
+		}
+	}
+	return kept, pruned
+}
+
+// summarize drops the transcript of an entry.
+func summarize(e HistoryEntry) HistoryEntry {
+	e.Steps = nil
+	e.Files = nil
+	e.Versions = nil
+	e.Uninstalls = nil
+	e.Lockfile = nil
+	return e
+}
+
+func readHistoryIndex(dir string) (historyIndex, error) {
+	var index historyIndex
+	data, err := securestate.ReadFile(filepath.Join(dir, historyIndexFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return index, nil
+		}
+		return index, err
+	}
+	if err := json.Unmarshal(data, &index); err != nil {
+		return index, fmt.Errorf("corrupt history index: %w", err)
+	}
+	return index, nil
+}
+
+// readLooseHistory reads the entries not compacted yet, with their files.
+func readLooseHistory(dir string) ([]HistoryEntry, []string, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	var entries []HistoryEntry
+	var paths []string
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), ".json") || f.Name() == historyIndexFile {
+			continue
+		}
+		path := filepath.Join(dir, f.Name())
+		data, err := securestate.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var entry HistoryEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+		paths = append(paths, path)
+	}
+	return entries, paths, nil
+}
+
+// readCompactedHistory reads every full entry from the log.
+func readCompactedHistory(dir string) ([]HistoryEntry, error) {
+	data, err := securestate.ReadFile(filepath.Join(dir, historyLogFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var entries []HistoryEntry
+	reader := bufio.NewReader(bytes.NewReader(data))
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			var entry HistoryEntry
+			if jsonErr := json.Unmarshal(line, &entry); jsonErr == nil {
+				entries = append(entries, entry)
+			}
+		}
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// readCompactedEntry reads one full entry from the log, falling back to a
+// scan when the index is out of date or the log is encrypted.
+func readCompactedEntry(dir string, ref historyIndexEntry) (HistoryEntry, error) {
+	// #nosec G304 -- the log lives in preflight's own history directory.
+	file, err := os.Open(filepath.Join(dir, historyLogFile))
+	if err != nil {
+		return HistoryEntry{}, err
+	}
+	defer func() { _ = file.Close() }()
+
+	record := make([]byte, ref.Length)
+	if _, err := file.ReadAt(record, ref.Offset); err == nil {
+		var entry HistoryEntry
+		if json.Unmarshal(record, &entry) == nil && entry.ID == ref.ID {
+			return entry, nil
+		}
+	}
+	entries, err := readCompactedHistory(dir)
+	if err != nil {
+		return HistoryEntry{}, err
+	}
+	for _, entry := range entries {
+		if entry.ID == ref.ID {
+			return entry, nil
+		}
+	}
+	return HistoryEntry{}, fmt.Errorf("history entry %s is missing from %s", ref.ID, historyLogFile)
+}
+
+// loadHistoryEntry returns the full entry with id.
+func loadHistoryEntry(id string) (HistoryEntry, error) {
+	dir := getHistoryDir()
+	if data, err := securestate.ReadFile(filepath.Join(dir, id+".json")); err == nil {
+		var entry HistoryEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return HistoryEntry{}, fmt.Errorf("corrupt history entry %s: %w", id, err)
+		}
+		return entry, nil
+	}
+	index, err := readHistoryIndex(dir)
+	if err != nil {
+		return HistoryEntry{}, err
+	}
+	for _, ref := range index.Entries {
+		if ref.ID == id {
+			return readCompactedEntry(dir, ref)
+		}
+	}
+	return HistoryEntry{}, fmt.Errorf("no history entry %q", id)
+}
+
+// compactHistory rewrites the history as a single log and index holding
+// the entries policy keeps, and removes the entry files. With dryRun it
+// only reports what would be pruned.
+func compactHistory(policy historyPolicy, now time.Time, dryRun bool) (kept, pruned []HistoryEntry, err error) {
+	dir := getHistoryDir()
+	compacted, err := readCompactedHistory(dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read %s: %w", historyLogFile, err)
+	}
+	loose, loosePaths, err := readLooseHistory(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Entry files win over compacted copies with the same ID.
+	byID := make(map[string]HistoryEntry, len(compacted)+len(loose))
+	for _, e := range append(compacted, loose...) {
+		byID[e.ID] = e
+	}
+	all := make([]HistoryEntry, 0, len(byID))
+	for _, e := range byID {
+		all = append(all, e)
+	}
+	kept, pruned = policy.apply(all, now)
+	if dryRun || (len(loose) == 0 && len(pruned) == 0) {
+		return kept, pruned, nil
+	}
+
+	var log bytes.Buffer
+	index := historyIndex{Entries: make([]historyIndexEntry, 0, len(kept))}
+	for _, e := range kept {
+		record, err := json.Marshal(e)
+		if err != nil {
+			return nil, nil, err
+		}
+		index.Entries = append(index.Entries, historyIndexEntry{
+			HistoryEntry: summarize(e),
+			Offset:       int64(log.Len()),
+			Length:       len(record),
+		})
+		log.Write(record)
+		log.WriteByte('\n')
+	}
+	indexData, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, nil, err
+	}
+	if err := writeFileAtomic(filepath.Join(dir, historyLogFile), log.Bytes()); err != nil {
+		return nil, nil, err
+	}
+	if err := writeFileAtomic(filepath.Join(dir, historyIndexFile), indexData); err != nil {
+		return nil, nil, err
+	}
+	for _, path := range loosePaths {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return nil, nil, err
+		}
+	}
+	return kept, pruned, nil
+}
+
+// compactHistoryIfNeeded compacts and prunes the history once enough entry
+// files have accumulated.
+func compactHistoryIfNeeded(configPath string) error {
+	files, err := os.ReadDir(getHistoryDir())
+	if err != nil {
+		return nil
+	}
+	loose := 0
+	for _, f := range files {
+		if strings.HasSuffix(f.Name(), ".json") && f.Name() != historyIndexFile {
+			loose++
+		}
+	}
+	if loose < historyCompactThreshold {
+		return nil
+	}
+	policy, err := historyPolicyFor(configPath)
+	if err != nil {
+		return err
+	}
+	_, _, err = compactHistory(policy, time.Now(), false)
+	return err
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	return securestate.WriteFile(path, data, 0o644)
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoryPolicy_Apply(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	entries := []HistoryEntry{
+		{ID: "old", Timestamp: now.Add(-100 * 24 * time.Hour)},
+		{ID: "new", Timestamp: now.Add(-time.Hour)},
+		{ID: "mid", Timestamp: now.Add(-10 * 24 * time.Hour)},
+		{ID: "recent", Timestamp: now.Add(-2 * time.Hour)},
+	}
+
+	kept, pruned := historyPolicy{keep: 90 * 24 * time.Hour}.apply(entries, now)
+	assert.Equal(t, []string{"new", "recent", "mid"}, historyIDs(kept))
+	assert.Equal(t, []string{"old"}, historyIDs(pruned))
+
+	kept, pruned = historyPolicy{keep: 90 * 24 * time.Hour, maxEntries: 2}.apply(entries, now)
+	assert.Equal(t, []string{"new", "recent"}, historyIDs(kept))
+	assert.Equal(t, []string{"mid", "old"}, historyIDs(pruned))
+
+	kept, pruned = historyPolicy{}.apply(entries, now)
+	assert.Len(t, kept, 4)
+	assert.Empty(t, pruned)
+}
+
+func historyIDs(entries []HistoryEntry) []string {
+	ids := make([]string, 0, len(entries))
+	for _, e := range entries {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
+
+func TestCompactHistory_IndexesAndPrunes(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	now := time.Now()
+
+	require.NoError(t, SaveHistoryEntry(HistoryEntry{ID: "1", Timestamp: now.Add(-200 * 24 * time.Hour), Command: "apply", Status: "success"}))
+	require.NoError(t, SaveHistoryEntry(HistoryEntry{
+		ID: "2", Timestamp: now.Add(-time.Hour), Command: "apply", Status: "failed",
+		Steps: []StepTranscript{{ID: "brew:formula:fd", Status: "failed", Stderr: "no bottle"}},
+	}))
+	require.NoError(t, SaveHistoryEntry(HistoryEntry{ID: "3", Timestamp: now, Command: "apply", Status: "success"}))
+
+	kept, pruned, err := compactHistory(historyPolicy{keep: 90 * 24 * time.Hour}, now, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"3", "2"}, historyIDs(kept))
+	assert.Equal(t, []string{"1"}, historyIDs(pruned))
+
+	dir := getHistoryDir()
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, f := range files {
+		names = append(names, f.Name())
+	}
+	assert.ElementsMatch(t, []string{historyIndexFile, historyLogFile}, names)
+
+	// Listing reads summaries from the index.
+	entries, err := loadHistory()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"2", "3"}, historyIDs(entries))
+	for _, e := range entries {
+		assert.Empty(t, e.Steps)
+	}
+
+	// Inspection reads the full entry from the log.
+	full, err := findHistoryEntry("2")
+	require.NoError(t, err)
+	require.Len(t, full.Steps, 1)
+	assert.Equal(t, "no bottle", full.Steps[0].Stderr)
+
+	// New entries are merged into the compacted history.
+	require.NoError(t, SaveHistoryEntry(HistoryEntry{ID: "4", Timestamp: now, Command: "apply", Status: "success"}))
+	entries, err = loadHistory()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"2", "3", "4"}, historyIDs(entries))
+}
+
+func TestCompactHistory_DryRunKeepsFiles(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	require.NoError(t, SaveHistoryEntry(HistoryEntry{ID: "1", Timestamp: time.Now().Add(-200 * 24 * time.Hour), Command: "apply"}))
+
+	_, pruned, err := compactHistory(historyPolicy{keep: 24 * time.Hour}, time.Now(), true)
+	require.NoError(t, err)
+	assert.Len(t, pruned, 1)
+
+	_, err = os.Stat(filepath.Join(getHistoryDir(), "1.json"))
+	assert.NoError(t, err)
+}
+
+func TestReadCompactedEntry_StaleIndexFallsBackToScan(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	require.NoError(t, SaveHistoryEntry(HistoryEntry{ID: "1", Timestamp: time.Now(), Command: "apply"}))
+	require.NoError(t, SaveHistoryEntry(HistoryEntry{ID: "2", Timestamp: time.Now().Add(-time.Minute), Command: "apply"}))
+	_, _, err := compactHistory(historyPolicy{}, time.Now(), false)
+	require.NoError(t, err)
+
+	entry, err := readCompactedEntry(getHistoryDir(), historyIndexEntry{HistoryEntry: HistoryEntry{ID: "2"}, Offset: 0, Length: 10})
+	require.NoError(t, err)
+	assert.Equal(t, "2", entry.ID)
+}
+
+func TestHistoryPolicyFor(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	policy, err := historyPolicyFor(filepath.Join(dir, "missing.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, historyPolicy{keep: defaultHistoryKeep, maxEntries: defaultHistoryMaxEntries}, policy)
+
+	path := filepath.Join(dir, "preflight.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("history:\n  keep: 2w\n  max_entries: 50\ntargets:\n  default:\n    - base\n"), 0o644))
+	policy, err = historyPolicyFor(path)
+	require.NoError(t, err)
+	assert.Equal(t, historyPolicy{keep: 14 * 24 * time.Hour, maxEntries: 50}, policy)
+}
+
+func TestCompactHistoryIfNeeded_Threshold(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	configPath := filepath.Join(t.TempDir(), "preflight.yaml")
+
+	for i := 0; i < historyCompactThreshold-1; i++ {
+		require.NoError(t, SaveHistoryEntry(HistoryEntry{ID: fmt.Sprintf("%03d", i), Timestamp: time.Now(), Command: "apply"}))
+	}
+	require.NoError(t, compactHistoryIfNeeded(configPath))
+	_, err := os.Stat(filepath.Join(getHistoryDir(), historyIndexFile))
+	assert.True(t, os.IsNotExist(err), "below the threshold entries stay as files")
+
+	require.NoError(t, SaveHistoryEntry(HistoryEntry{ID: "last", Timestamp: time.Now(), Command: "apply"}))
+	require.NoError(t, compactHistoryIfNeeded(configPath))
+	_, err = os.Stat(filepath.Join(getHistoryDir(), historyIndexFile))
+	assert.NoError(t, err)
+
+	entries, err := loadHistory()
+	require.NoError(t, err)
+	assert.Len(t, entries, historyCompactThreshold)
+}
+
+func TestRunHistoryPrune_FlagsOverridePolicy(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	prevCfg, prevKeep, prevMax := cfgFile, historyPruneKeep, historyPruneMaxEntries
+	t.Cleanup(func() { cfgFile, historyPruneKeep, historyPruneMaxEntries = prevCfg, prevKeep, prevMax })
+	cfgFile = filepath.Join(t.TempDir(), "preflight.yaml")
+	historyPruneKeep = ""
+	historyPruneMaxEntries = 1
+
+	require.NoError(t, SaveHistoryEntry(HistoryEntry{ID: "1", Timestamp: time.Now().Add(-time.Hour), Command: "apply"}))
+	require.NoError(t, SaveHistoryEntry(HistoryEntry{ID: "2", Timestamp: time.Now(), Command: "apply"}))
+
+	output := captureStdout(t, func() {
+		require.NoError(t, runHistoryPrune(nil, nil))
+	})
+	assert.Contains(t, output, "Pruned 1 entries; kept 1.")
+
+	entries, err := loadHistory()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2"}, historyIDs(entries))
+}
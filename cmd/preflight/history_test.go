@@ -2,11 +2,15 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/domain/execution"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -341,7 +345,7 @@ func TestSaveHistoryEntry_RoundTrip(t *testing.T) {
 	require.NoError(t, err)
 
 	// Read back the file
-	histDir := filepath.Join(tmpDir, ".preflight", "history")
+	histDir := getHistoryDir()
 	data, err := os.ReadFile(filepath.Join(histDir, "roundtrip-test-001.json"))
 	require.NoError(t, err)
 
@@ -372,7 +376,7 @@ func TestSaveHistoryEntry_AutoGeneratesIDAndTimestamp(t *testing.T) {
 	require.NoError(t, err)
 
 	// Verify a file was created in the history dir
-	histDir := filepath.Join(tmpDir, ".preflight", "history")
+	histDir := getHistoryDir()
 	files, err := os.ReadDir(histDir)
 	require.NoError(t, err)
 	assert.Len(t, files, 1)
@@ -427,7 +431,7 @@ func TestRunHistoryClear_RemovesHistory(t *testing.T) {
 	require.NoError(t, SaveHistoryEntry(entry))
 
 	// Verify history dir exists
-	histDir := filepath.Join(tmpDir, ".preflight", "history")
+	histDir := getHistoryDir()
 	_, err := os.Stat(histDir)
 	require.NoError(t, err)
 
@@ -458,9 +462,10 @@ func TestRunHistoryClear_NoHistoryDir(t *testing.T) {
 func TestGetHistoryDir_UsesHome(t *testing.T) {
 	tmpDir := t.TempDir()
 	t.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_STATE_HOME", "")
 
 	dir := getHistoryDir()
-	assert.Equal(t, filepath.Join(tmpDir, ".preflight", "history"), dir)
+	assert.Equal(t, filepath.Join(tmpDir, ".local", "state", "preflight", "history"), dir)
 }
 
 func TestHistoryEntry_StructFields(t *testing.T) {
@@ -606,3 +611,126 @@ func TestHistoryEntry_JSONOmitsEmpty(t *testing.T) {
 	assert.NotContains(t, string(data), `"changes"`)
 	assert.NotContains(t, string(data), `"error"`)
 }
+
+func TestNewApplyHistoryEntry_RecordsTranscript(t *testing.T) {
+	t.Parallel()
+
+	installed := compiler.MustNewStepID("brew:formula:ripgrep")
+	failed := compiler.MustNewStepID("npm:package:eslint")
+	satisfied := compiler.MustNewStepID("git:config")
+	results := []execution.StepResult{
+		execution.NewStepResult(installed, compiler.StatusSatisfied, nil).
+			WithApplied(true).
+			WithDuration(1500 * time.Millisecond).
+			WithDiff(compiler.NewDiff(compiler.DiffTypeAdd, "formula", "ripgrep", "", "14.0")),
+		execution.NewStepResult(failed, compiler.StatusFailed, errors.New("npm exited 1")),
+		execution.NewStepResult(satisfied, compiler.StatusSatisfied, nil),
+	}
+	transcript := &app.Transcript{
+		Outputs: []app.StepOutput{
+			{StepID: installed.String(), Commands: []string{"brew install ripgrep"}, Stdout: "done\n"},
+			{StepID: failed.String(), Commands: []string{"npm install -g eslint"}, Stderr: "EACCES\n", Truncated: true},
+		},
+		Versions: []app.VersionTransition{{StepID: installed.String(), Provider: "brew", Name: "ripgrep", To: "14.0"}},
+	}
+
+	entry := newApplyHistoryEntry("work", time.Now(), results, transcript, errors.New("step failed"))
+
+	assert.Equal(t, "apply", entry.Command)
+	assert.Equal(t, "work", entry.Target)
+	assert.Equal(t, "partial", entry.Status)
+	assert.Equal(t, "step failed", entry.Error)
+	assert.Equal(t, []Change{{Provider: "brew", Action: "create", Item: "ripgrep", Details: "+ formula ripgrep (14.0)"}}, entry.Changes)
+	require.Len(t, entry.Steps, 2, "satisfied steps without output are omitted")
+	assert.Equal(t, StepTranscript{
+		ID: installed.String(), Status: "satisfied", Applied: true, Duration: "1.5s",
+		Commands: []string{"brew install ripgrep"}, Stdout: "done\n",
+	}, entry.Steps[0])
+	assert.Equal(t, "npm exited 1", entry.Steps[1].Error)
+	assert.True(t, entry.Steps[1].Truncated)
+	assert.Equal(t, transcript.Versions, entry.Versions)
+}
+
+func TestNewApplyHistoryEntry_Status(t *testing.T) {
+	t.Parallel()
+
+	id := compiler.MustNewStepID("brew:formula:fd")
+	ok := []execution.StepResult{execution.NewStepResult(id, compiler.StatusSatisfied, nil).WithApplied(true)}
+	failed := []execution.StepResult{execution.NewStepResult(id, compiler.StatusFailed, errors.New("boom"))}
+
+	assert.Equal(t, "success", newApplyHistoryEntry("", time.Now(), ok, nil, nil).Status)
+	assert.Equal(t, "failed", newApplyHistoryEntry("", time.Now(), failed, nil, errors.New("boom")).Status)
+}
+
+func TestFindHistoryEntry_Prefix(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	require.NoError(t, SaveHistoryEntry(HistoryEntry{ID: "1700000001", Command: "apply", Status: "success"}))
+	require.NoError(t, SaveHistoryEntry(HistoryEntry{ID: "1700000002", Command: "apply", Status: "success"}))
+
+	entry, err := findHistoryEntry("1700000002")
+	require.NoError(t, err)
+	assert.Equal(t, "1700000002", entry.ID)
+
+	_, err = findHistoryEntry("17000000")
+	require.ErrorContains(t, err, "ambiguous")
+
+	_, err = findHistoryEntry("999")
+	require.ErrorContains(t, err, "no history entry")
+}
+
+func TestOutputHistoryShow(t *testing.T) {
+	// Not parallel - writes to stdout
+	entry := HistoryEntry{
+		ID:        "1700000001",
+		Timestamp: time.Now(),
+		Command:   "apply",
+		Status:    "failed",
+		Steps: []StepTranscript{
+			{ID: "brew:formula:fd", Status: "failed", Error: "exit 1", Commands: []string{"brew install fd"}, Stderr: "Error: no bottle\n"},
+		},
+		Files:    []app.FileDiff{{StepID: "files:link:zshrc", Path: "/home/u/.zshrc", Diff: "--- a\n+++ a\n@@ -1,1 +1,1 @@\n-x\n+y\n"}},
+		Versions: []app.VersionTransition{{Provider: "brew", Name: "git", From: "2.44.0", To: "2.45.0"}},
+	}
+
+	output := captureStdout(t, func() { outputHistoryShow(entry) })
+
+	assert.Contains(t, output, "$ brew install fd")
+	assert.Contains(t, output, "      Error: no bottle")
+	assert.Contains(t, output, "[brew] git: 2.44.0 → 2.45.0")
+	assert.Contains(t, output, "/home/u/.zshrc (files:link:zshrc)")
+	assert.Contains(t, output, "    +y")
+}
+
+func TestOutputHistoryDiff(t *testing.T) {
+	// Not parallel - writes to stdout
+	a := HistoryEntry{
+		ID:     "1",
+		Status: "failed",
+		Steps: []StepTranscript{
+			{ID: "brew:formula:fd", Status: "failed"},
+			{ID: "npm:package:eslint", Status: "satisfied"},
+		},
+		Versions: []app.VersionTransition{{Provider: "brew", Name: "git", To: "2.44.0"}},
+		Files:    []app.FileDiff{{Path: "/home/u/.zshrc"}},
+	}
+	b := HistoryEntry{
+		ID:     "2",
+		Status: "success",
+		Steps: []StepTranscript{
+			{ID: "brew:formula:fd", Status: "satisfied"},
+			{ID: "pip:package:black", Status: "satisfied"},
+		},
+		Versions: []app.VersionTransition{{Provider: "brew", Name: "git", From: "2.44.0", To: "2.45.0"}},
+	}
+
+	output := captureStdout(t, func() { outputHistoryDiff(a, b) })
+
+	assert.Contains(t, output, "~ brew:formula:fd: failed → satisfied")
+	assert.Contains(t, output, "- npm:package:eslint (satisfied)")
+	assert.Contains(t, output, "+ pip:package:black (satisfied)")
+	assert.Contains(t, output, "~ brew:git: 2.44.0 → 2.45.0")
+	assert.Contains(t, output, "- /home/u/.zshrc (changed only in 1)")
+
+	same := captureStdout(t, func() { outputHistoryDiff(a, a) })
+	assert.Contains(t, same, "No differences")
+}
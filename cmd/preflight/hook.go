@@ -0,0 +1,272 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	pfconfig "github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
+	"github.com/spf13/cobra"
+)
+
+var hookCmd = &cobra.Command{
+	Use:   "hook <zsh|bash|fish>",
+	Short: "Print a shell hook that loads the active profile environment",
+	Long: `Print a shell hook that keeps your shell in sync with the active profile.
+
+The hook loads the environment variables, PATH additions and aliases of the
+active profile. It is re-evaluated before every prompt, so after
+'preflight profile switch' open shells pick up the new profile without
+being restarted. Variables and aliases of the previous profile are removed.
+
+Add one of these lines to your shell configuration:
+
+  zsh  (~/.zshrc):                     eval "$(preflight hook zsh)"
+  bash (~/.bashrc):                    eval "$(preflight hook bash)"
+  fish (~/.config/fish/config.fish):   preflight hook fish | source`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{"zsh", "bash", "fish"},
+	RunE:      runHook,
+}
+
+func init() {
+	rootCmd.AddCommand(hookCmd)
+}
+
+func runHook(_ *cobra.Command, args []string) error {
+	script, err := shellHook(args[0], profileEnvDir())
+	if err != nil {
+		return err
+	}
+	fmt.Print(script)
+	return nil
+}
+
+// profileEnvDir is where the profile environment scripts are written.
+func profileEnvDir() string {
+	dir, _ := platform.Dir(platform.StateDir)
+	return dir
+}
+
+// shellHook returns the hook for shell. It sources the profile environment
+// script whenever the stamp on its first line changes, which keeps the
+// per-prompt cost to a single read.
+func shellHook(shell, dir string) (string, error) {
+	switch shell {
+	case "zsh", "bash":
+		envPath := shellQuote(filepath.Join(dir, "env.sh"))
+		var sb strings.Builder
+		sb.WriteString("# preflight shell hook\n")
+		sb.WriteString("_preflight_hook() {\n")
+		sb.WriteString("  local line\n")
+		fmt.Fprintf(&sb, "  [ -r %s ] || return 0\n", envPath)
+		fmt.Fprintf(&sb, "  IFS= read -r line < %s\n", envPath)
+		sb.WriteString("  [ \"$line\" = \"${_PREFLIGHT_ENV_STAMP-}\" ] && return 0\n")
+		sb.WriteString("  _PREFLIGHT_ENV_STAMP=$line\n")
+		fmt.Fprintf(&sb, "  . %s\n", envPath)
+		sb.WriteString("}\n")
+		if shell == "zsh" {
+			sb.WriteString("autoload -Uz add-zsh-hook\n")
+			sb.WriteString("add-zsh-hook precmd _preflight_hook\n")
+		} else {
+			sb.WriteString("case \";${PROMPT_COMMAND-};\" in\n")
+			sb.WriteString("  *\";_preflight_hook;\"*) ;;\n")
+			sb.WriteString("  *) PROMPT_COMMAND=\"_preflight_hook${PROMPT_COMMAND:+;$PROMPT_COMMAND}\" ;;\n")
+			sb.WriteString("esac\n")
+		}
+		sb.WriteString("_preflight_hook\n")
+		return sb.String(), nil
+	case "fish":
+		envPath := shellQuote(filepath.Join(dir, "env.fish"))
+		var sb strings.Builder
+		sb.WriteString("# preflight shell hook\n")
+		sb.WriteString("function __preflight_hook --on-event fish_prompt\n")
+		fmt.Fprintf(&sb, "    test -r %s; or return 0\n", envPath)
+		fmt.Fprintf(&sb, "    read -l line < %s\n", envPath)
+		sb.WriteString("    test \"$line\" = \"$__preflight_env_stamp\"; and return 0\n")
+		sb.WriteString("    set -g __preflight_env_stamp $line\n")
+		fmt.Fprintf(&sb, "    source %s\n", envPath)
+		sb.WriteString("end\n")
+		sb.WriteString("__preflight_hook\n")
+		return sb.String(), nil
+	default:
+		return "", &pfconfig.UserError{
+			Code:       pfconfig.ErrCodeUnsupportedShell,
+			Message:    fmt.Sprintf("unsupported shell: %s", shell),
+			Suggestion: "Use one of: zsh, bash, fish.",
+		}
+	}
+}
+
+// profileEnv is the shell environment of a profile.
+type profileEnv struct {
+	Profile string
+	Vars    []EnvVar
+	Path    pfconfig.PathConfig
+	Aliases map[string]string
+}
+
+// profileEnvFromConfig collects the shell environment from a merged config:
+// shell.env and env variables (env wins), PATH entries and shell.aliases.
+func profileEnvFromConfig(profileName string, config map[string]interface{}) profileEnv {
+	byName := make(map[string]EnvVar)
+	if shell, ok := config["shell"].(map[string]interface{}); ok {
+		for _, v := range extractEnvVars(shell) {
+			byName[v.Name] = v
+		}
+	}
+	for _, v := range extractEnvVars(config) {
+		byName[v.Name] = v
+	}
+
+	env := profileEnv{Profile: profileName, Aliases: make(map[string]string)}
+	for _, v := range byName {
+		env.Vars = append(env.Vars, v)
+	}
+	sort.Slice(env.Vars, func(i, j int) bool { return env.Vars[i].Name < env.Vars[j].Name })
+
+	env.Path = extractPathConfig(config)
+	if shell, ok := config["shell"].(map[string]interface{}); ok {
+		if aliases, ok := shell["aliases"].(map[string]interface{}); ok {
+			for name, value := range aliases {
+				env.Aliases[name] = fmt.Sprintf("%v", value)
+			}
+		}
+	}
+	return env
+}
+
+// exported returns the variables that are written to the scripts. Secret
+// references are never resolved into a plain text file.
+func (e profileEnv) exported() []EnvVar {
+	vars := make([]EnvVar, 0, len(e.Vars))
+	for _, v := range e.Vars {
+		if !v.Secret {
+			vars = append(vars, v)
+		}
+	}
+	return vars
+}
+
+func (e profileEnv) aliasNames() []string {
+	names := make([]string, 0, len(e.Aliases))
+	for name := range e.Aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// posixScript renders the environment for bash and zsh. It first undoes
+// what the previously sourced script set, so switching profiles does not
+// leave stale variables, aliases or PATH entries behind.
+func (e profileEnv) posixScript() string {
+	vars := e.exported()
+	names := make([]string, 0, len(vars))
+	for _, v := range vars {
+		names = append(names, v.Name)
+	}
+	if e.Profile != "" {
+		names = append(names, "PREFLIGHT_PROFILE")
+	}
+	aliases := e.aliasNames()
+
+	var sb strings.Builder
+	sb.WriteString("[ -n \"${_PREFLIGHT_VARS-}\" ] && eval \"unset $_PREFLIGHT_VARS\"\n")
+	sb.WriteString("[ -n \"${_PREFLIGHT_ALIASES-}\" ] && eval \"unalias $_PREFLIGHT_ALIASES\" 2>/dev/null\n")
+	sb.WriteString("[ -n \"${_PREFLIGHT_PATH-}\" ] && PATH=${PATH#\"$_PREFLIGHT_PATH:\"}\n")
+	sb.WriteString("[ -n \"${_PREFLIGHT_PATH_APPEND-}\" ] && PATH=${PATH%\":$_PREFLIGHT_PATH_APPEND\"}\n")
+	fmt.Fprintf(&sb, "_PREFLIGHT_VARS=%s\n", shellQuote(strings.Join(names, " ")))
+	fmt.Fprintf(&sb, "_PREFLIGHT_ALIASES=%s\n", shellQuote(strings.Join(aliases, " ")))
+	fmt.Fprintf(&sb, "_PREFLIGHT_PATH=%s\n", shellQuote(strings.Join(e.Path.Prepend, ":")))
+	fmt.Fprintf(&sb, "_PREFLIGHT_PATH_APPEND=%s\n", shellQuote(strings.Join(e.Path.Append, ":")))
+	if e.Profile != "" {
+		fmt.Fprintf(&sb, "export PREFLIGHT_PROFILE=%s\n", shellQuote(e.Profile))
+	}
+	for _, v := range vars {
+		fmt.Fprintf(&sb, "export %s=%q\n", v.Name, v.Value)
+	}
+	if len(e.Path.Prepend) > 0 {
+		sb.WriteString("PATH=\"$_PREFLIGHT_PATH:$PATH\"\n")
+	}
+	if len(e.Path.Append) > 0 {
+		sb.WriteString("PATH=\"$PATH:$_PREFLIGHT_PATH_APPEND\"\n")
+	}
+	for _, name := range aliases {
+		fmt.Fprintf(&sb, "alias %s=%s\n", name, shellQuote(e.Aliases[name]))
+	}
+	return withStamp(sb.String())
+}
+
+// fishScript renders the environment for fish.
+func (e profileEnv) fishScript() string {
+	vars := e.exported()
+	names := make([]string, 0, len(vars))
+	for _, v := range vars {
+		names = append(names, v.Name)
+	}
+	if e.Profile != "" {
+		names = append(names, "PREFLIGHT_PROFILE")
+	}
+	aliases := e.aliasNames()
+
+	var sb strings.Builder
+	sb.WriteString("set -q __preflight_vars[1]; and set -e $__preflight_vars\n")
+	sb.WriteString("set -q __preflight_aliases[1]; and functions -e $__preflight_aliases\n")
+	sb.WriteString("for p in $__preflight_path\n")
+	sb.WriteString("    set -l i (contains -i -- $p $PATH); and set -e PATH[$i]\n")
+	sb.WriteString("end\n")
+	sb.WriteString("set -g __preflight_vars" + fishList(names) + "\n")
+	sb.WriteString("set -g __preflight_aliases" + fishList(aliases) + "\n")
+	sb.WriteString("set -g __preflight_path" + fishList(e.Path.Entries()) + "\n")
+	if e.Profile != "" {
+		fmt.Fprintf(&sb, "set -gx PREFLIGHT_PROFILE %s\n", shellQuote(e.Profile))
+	}
+	for _, v := range vars {
+		fmt.Fprintf(&sb, "set -gx %s %s\n", v.Name, fishQuote(v.Value))
+	}
+	if !e.Path.IsZero() {
+		sb.WriteString("set -gx PATH" + fishList(e.Path.Prepend) + " $PATH" + fishList(e.Path.Append) + "\n")
+	}
+	for _, name := range aliases {
+		fmt.Fprintf(&sb, "alias %s %s\n", name, fishQuote(e.Aliases[name]))
+	}
+	return withStamp(sb.String())
+}
+
+// withStamp prefixes a script with a header whose first line changes
+// whenever the script does; the shell hook compares it before sourcing.
+func withStamp(body string) string {
+	sum := sha256.Sum256([]byte(body))
+	return "# preflight-env " + hex.EncodeToString(sum[:6]) + "\n" +
+		"# Generated by preflight - do not edit manually\n\n" + body
+}
+
+func fishList(items []string) string {
+	var sb strings.Builder
+	for _, item := range items {
+		sb.WriteString(" " + fishQuote(item))
+	}
+	return sb.String()
+}
+
+// fishQuote single-quotes s for fish, which only escapes \ and ' there.
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+// writeProfileEnv writes env.sh and env.fish to dir for the shell hook.
+func writeProfileEnv(dir string, env profileEnv) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "env.sh"), []byte(env.posixScript()), 0o644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "env.fish"), []byte(env.fishScript()), 0o644)
+}
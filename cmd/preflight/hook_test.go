@@ -0,0 +1,115 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	pfconfig "github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShellHook(t *testing.T) {
+	t.Parallel()
+
+	zsh, err := shellHook("zsh", "/home/me/.preflight")
+	require.NoError(t, err)
+	assert.Contains(t, zsh, "add-zsh-hook precmd _preflight_hook")
+	assert.Contains(t, zsh, ". '/home/me/.preflight/env.sh'")
+
+	bash, err := shellHook("bash", "/home/me/.preflight")
+	require.NoError(t, err)
+	assert.Contains(t, bash, `PROMPT_COMMAND="_preflight_hook${PROMPT_COMMAND:+;$PROMPT_COMMAND}"`)
+
+	fish, err := shellHook("fish", "/home/me/.preflight")
+	require.NoError(t, err)
+	assert.Contains(t, fish, "function __preflight_hook --on-event fish_prompt")
+	assert.Contains(t, fish, "source '/home/me/.preflight/env.fish'")
+
+	_, err = shellHook("tcsh", "/home/me/.preflight")
+	assert.ErrorContains(t, err, "unsupported shell: tcsh")
+}
+
+func TestProfileEnvFromConfig(t *testing.T) {
+	t.Parallel()
+
+	env := profileEnvFromConfig("work", map[string]interface{}{
+		"env": map[string]interface{}{"EDITOR": "nvim", "TOKEN": "secret://op/token"},
+		"shell": map[string]interface{}{
+			"env":     map[string]interface{}{"EDITOR": "vim", "PAGER": "less"},
+			"aliases": map[string]interface{}{"k": "kubectl"},
+		},
+		"path": map[string]interface{}{
+			"prepend": []interface{}{"/opt/work/bin"},
+			"append":  []interface{}{"/opt/extra/bin"},
+		},
+	})
+
+	assert.Equal(t, "work", env.Profile)
+	assert.Equal(t, []EnvVar{
+		{Name: "EDITOR", Value: "nvim"},
+		{Name: "PAGER", Value: "less"},
+		{Name: "TOKEN", Value: "secret://op/token", Secret: true},
+	}, env.Vars)
+	assert.Equal(t, pfconfig.PathConfig{Prepend: []string{"/opt/work/bin"}, Append: []string{"/opt/extra/bin"}}, env.Path)
+	assert.Equal(t, map[string]string{"k": "kubectl"}, env.Aliases)
+
+	script := env.posixScript()
+	assert.True(t, strings.HasPrefix(script, "# preflight-env "))
+	assert.NotContains(t, script, "secret://")
+	assert.NotContains(t, env.fishScript(), "secret://")
+}
+
+func TestProfileEnv_StampChangesWithContent(t *testing.T) {
+	t.Parallel()
+
+	stamp := func(script string) string { return strings.SplitN(script, "\n", 2)[0] }
+	a := profileEnv{Profile: "work", Vars: []EnvVar{{Name: "A", Value: "1"}}}
+	b := profileEnv{Profile: "work", Vars: []EnvVar{{Name: "A", Value: "2"}}}
+
+	assert.Equal(t, stamp(a.posixScript()), stamp(a.posixScript()))
+	assert.NotEqual(t, stamp(a.posixScript()), stamp(b.posixScript()))
+	assert.NotEqual(t, stamp(a.fishScript()), stamp(b.fishScript()))
+}
+
+func TestProfileEnv_BashSwitch(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not available")
+	}
+	dir := t.TempDir()
+	hook, err := shellHook("bash", dir)
+	require.NoError(t, err)
+
+	work := profileEnv{
+		Profile: "work",
+		Vars:    []EnvVar{{Name: "WORK_ONLY", Value: "1"}},
+		Path:    pfconfig.PathConfig{Prepend: []string{"/opt/work/bin"}, Append: []string{"/opt/extra/bin"}},
+		Aliases: map[string]string{"k": "kubectl"},
+	}
+	personal := profileEnv{Profile: "personal", Vars: []EnvVar{{Name: "HOME_ONLY", Value: "it's"}}}
+	require.NoError(t, writeProfileEnv(dir, work))
+
+	// The second profile is written between prompts, as 'profile switch' would.
+	script := hook + `
+echo "$PREFLIGHT_PROFILE|${WORK_ONLY-}|$PATH|$(alias k 2>/dev/null)"
+` + "cp " + shellQuote(filepath.Join(dir, "next.sh")) + " " + shellQuote(filepath.Join(dir, "env.sh")) + `
+_preflight_hook
+echo "$PREFLIGHT_PROFILE|${WORK_ONLY-}|$PATH|$(alias k 2>/dev/null)|$HOME_ONLY"
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "next.sh"), []byte(personal.posixScript()), 0o644))
+
+	cmd := exec.Command("bash", "--norc", "--noprofile", "-c", script)
+	cmd.Env = []string{"PATH=/usr/bin:/bin"}
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	require.Len(t, lines, 2, string(out))
+	assert.Equal(t, "work|1|/opt/work/bin:/usr/bin:/bin:/opt/extra/bin|alias k='kubectl'", lines[0])
+	assert.Equal(t, "personal||/usr/bin:/bin||it's", lines[1])
+}
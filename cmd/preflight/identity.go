@@ -11,6 +11,8 @@ import (
 	"time"
 
 	"github.com/felixgeelhaar/preflight/internal/domain/identity"
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
+	"github.com/felixgeelhaar/preflight/internal/offline"
 	"github.com/spf13/cobra"
 )
 
@@ -94,12 +96,12 @@ func init() {
 }
 
 func newIdentityService() (*identity.Service, error) {
-	homeDir, err := os.UserHomeDir()
+	dir, err := platform.Path(platform.DataDir, "identity")
 	if err != nil {
-		return nil, fmt.Errorf("getting home directory: %w", err)
+		return nil, err
 	}
 
-	store := identity.NewTokenStore(homeDir + "/.preflight/identity")
+	store := identity.NewTokenStore(dir)
 	return identity.NewService(store), nil
 }
 
@@ -115,6 +117,9 @@ func resolveProvider(svc *identity.Service) string {
 }
 
 func runIdentityLogin(_ *cobra.Command, _ []string) error {
+	if offline.Enabled() {
+		return offline.Skipped("identity login")
+	}
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
@@ -6,12 +6,15 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
 	"syscall"
 
 	"github.com/felixgeelhaar/preflight/internal/domain/advisor"
 	"github.com/felixgeelhaar/preflight/internal/domain/advisor/anthropic"
 	"github.com/felixgeelhaar/preflight/internal/domain/advisor/gemini"
+	"github.com/felixgeelhaar/preflight/internal/domain/advisor/ollama"
 	"github.com/felixgeelhaar/preflight/internal/domain/advisor/openai"
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
 	"github.com/felixgeelhaar/preflight/internal/telemetry"
 	"github.com/felixgeelhaar/preflight/internal/tui"
 	"github.com/spf13/cobra"
@@ -27,12 +30,35 @@ The wizard will guide you through:
   - Choosing presets for each provider
   - Configuring targets and layers
 
+With --from-template, the configuration is cloned from a team template
+repository instead. Its {{ name }} placeholders are filled in from --var
+flags or prompts, the template is added as the "template" git remote, and
+the result is validated.
+
+--preset also accepts an installed marketplace package that ships layers.
+Its layers are copied into the configuration and its parameters are
+filled in from --var flags, prompts or their defaults.
+
+Init asks which roles you work in, such as backend, frontend or data. A
+role is a named set of layers under roles: in preflight.yaml, and targets
+list roles like layers. Marketplace packages can define their own roles.
+Pick roles up front with --roles.
+
+Init also records this machine's fingerprint (hostname, serial number,
+chip and RAM), which the machines section of preflight.yaml binds targets
+to. See 'preflight machines fingerprint'.
+
 Examples:
   preflight init                    # Interactive wizard
   preflight init --minimal          # Minimal shell:minimal config (no TUI)
   preflight init --provider nvim    # Start with nvim provider
   preflight init --preset balanced  # Use balanced preset
-  preflight init --yes              # Accept defaults`,
+  preflight init --minimal --roles backend,data
+  preflight init --yes              # Accept defaults
+  preflight init --preset acme-base --var email=jane@acme.com
+  preflight init --from-template git@github.com:acme/preflight-template.git \
+    --var name="Jane Doe" --var email=jane@acme.com \
+    --remote git@github.com:jane/dotfiles.git`,
 	RunE: runInit,
 }
 
@@ -45,6 +71,10 @@ var (
 	initNonInteractive bool
 	initMinimal        bool
 	initOutputDir      string
+	initFromTemplate   string
+	initVars           []string
+	initRemote         string
+	initRoles          []string
 )
 
 func init() {
@@ -56,6 +86,10 @@ func init() {
 	initCmd.Flags().BoolVar(&initNonInteractive, "non-interactive", false, "Run without TUI (requires --preset)")
 	initCmd.Flags().BoolVar(&initMinimal, "minimal", false, "Create a minimal shell:minimal configuration without TUI")
 	initCmd.Flags().StringVarP(&initOutputDir, "output", "o", ".", "Output directory for configuration")
+	initCmd.Flags().StringVar(&initFromTemplate, "from-template", "", "Create the configuration from a template git repository")
+	initCmd.Flags().StringArrayVar(&initVars, "var", nil, "Template or package parameter as name=value (repeatable)")
+	initCmd.Flags().StringVar(&initRemote, "remote", "", "Git remote to sync the new configuration with (with --from-template)")
+	initCmd.Flags().StringSliceVar(&initRoles, "roles", nil, "Roles to add to the default target, e.g. backend,data")
 
 	rootCmd.AddCommand(initCmd)
 }
@@ -79,6 +113,22 @@ func runInit(_ *cobra.Command, _ []string) error {
 		return nil
 	}
 
+	// Best effort: apply records the fingerprint when init could not
+	_ = recordInitFingerprint(context.Background())
+
+	if initFromTemplate != "" {
+		ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer cancel()
+		return runInitFromTemplate(ctx, configPath)
+	}
+
+	// Installed marketplace packages bring their own layers
+	if pkg := installedPresetPackage(initPreset); pkg != nil {
+		ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer cancel()
+		return runInitFromPackage(ctx, configPath, pkg)
+	}
+
 	// Non-interactive mode: create config directly from preset
 	if initNonInteractive {
 		return runInitNonInteractive(configPath)
@@ -104,7 +154,10 @@ func runInit(_ *cobra.Command, _ []string) error {
 	}
 
 	// Detect AI provider from environment
-	aiProvider := detectAIProvider()
+	aiProvider, err := meteredAIProvider("init")
+	if err != nil && !initNoAI {
+		fmt.Fprintf(os.Stderr, "Warning: %v; continuing without AI.\n", err)
+	}
 	if aiProvider != nil && !initNoAI {
 		opts = opts.WithAdvisor(aiProvider)
 	}
@@ -121,6 +174,9 @@ func runInit(_ *cobra.Command, _ []string) error {
 		fmt.Println("Initialization cancelled.")
 		return nil
 	}
+	if err := setUpBuiltinRoles(result.ConfigPath, terminalRolePrompt()); err != nil {
+		return err
+	}
 
 	fmt.Printf("Configuration created: %s\n", result.ConfigPath)
 	fmt.Println("\nNext steps:")
@@ -149,17 +205,20 @@ func runInitNonInteractive(configPath string) error {
 	}
 
 	// Generate manifest based on preset
-	manifest := generateManifestForPreset(initPreset)
+	manifest := config.SchemaModeline(config.ManifestSchemaFile) + generateManifestForPreset(initPreset)
 	if err := os.WriteFile(configPath, []byte(manifest), 0o644); err != nil {
 		return fmt.Errorf("failed to write manifest: %w", err)
 	}
 
 	// Generate base layer based on preset
-	layer := generateLayerForPreset(initPreset)
+	layer := config.SchemaModeline(config.LayerSchemaFile) + generateLayerForPreset(initPreset)
 	layerPath := filepath.Join(layersDir, "base.yaml")
 	if err := os.WriteFile(layerPath, []byte(layer), 0o644); err != nil {
 		return fmt.Errorf("failed to write layer: %w", err)
 	}
+	if err := setUpBuiltinRoles(configPath, nil); err != nil {
+		return err
+	}
 
 	fmt.Printf("Configuration created: %s\n", configPath)
 	fmt.Println("\nNext steps:")
@@ -261,6 +320,12 @@ func detectAIProvider() advisor.AIProvider {
 		return getProviderByName(aiProvider)
 	}
 
+	// A local endpoint is an explicit choice to keep data on this machine,
+	// so it wins over API keys in the environment.
+	if endpoint := os.Getenv("PREFLIGHT_AI_ENDPOINT"); endpoint != "" {
+		return localAIProvider(endpoint)
+	}
+
 	// Check for Anthropic API key first (preferred)
 	if apiKey := os.Getenv("ANTHROPIC_API_KEY"); apiKey != "" {
 		provider := anthropic.NewProvider(apiKey)
@@ -312,6 +377,36 @@ func getProviderByName(name string) advisor.AIProvider {
 		if apiKey := os.Getenv("OPENAI_API_KEY"); apiKey != "" {
 			return openai.NewProvider(apiKey)
 		}
+	case "ollama":
+		endpoint := os.Getenv("PREFLIGHT_AI_ENDPOINT")
+		if endpoint == "" {
+			endpoint = os.Getenv("OLLAMA_HOST")
+		}
+		return localAIProvider(endpoint)
 	}
 	return nil
 }
+
+// localAIProvider returns a provider for a local Ollama or other
+// OpenAI-compatible server, defaulting to Ollama on localhost. The model is
+// read from PREFLIGHT_AI_MODEL and an optional key from
+// PREFLIGHT_AI_API_KEY.
+func localAIProvider(endpoint string) advisor.AIProvider {
+	// OLLAMA_HOST is commonly host:port without a scheme.
+	if endpoint != "" && !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+	model := os.Getenv("PREFLIGHT_AI_MODEL")
+	if model == "" {
+		model = ollama.DefaultModel
+	}
+	provider, err := ollama.NewProviderWithConfig(ollama.Config{
+		Endpoint: endpoint,
+		Model:    model,
+		APIKey:   os.Getenv("PREFLIGHT_AI_API_KEY"),
+	})
+	if err != nil {
+		return nil
+	}
+	return provider
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/domain/marketplace"
+	"github.com/felixgeelhaar/preflight/internal/telemetry"
+)
+
+// installedPresetPackage returns the installed marketplace package named by
+// --preset when it ships layers, or nil.
+func installedPresetPackage(preset string) *marketplace.InstalledPackage {
+	if preset == "" {
+		return nil
+	}
+	installed, err := newMarketplaceService().List()
+	if err != nil {
+		return nil
+	}
+	for i := range installed {
+		if installed[i].Package.ID.String() != preset {
+			continue
+		}
+		layers, err := marketplace.PackageLayers(installed[i].Path)
+		if err != nil || len(layers) == 0 {
+			return nil
+		}
+		return &installed[i]
+	}
+	return nil
+}
+
+// runInitFromPackage creates the configuration in initOutputDir from the
+// layers of an installed marketplace package, filling in its parameters.
+func runInitFromPackage(ctx context.Context, configPath string, pkg *marketplace.InstalledPackage) error {
+	preset, err := parseVarFlags(initVars)
+	if err != nil {
+		return err
+	}
+	var params []marketplace.Parameter
+	var roles []initRole
+	spec, err := marketplace.LoadPackageSpec(pkg.Path)
+	switch {
+	case err == nil:
+		params = spec.Parameters
+		roles = packageRoles(spec.Roles)
+	case !errors.Is(err, fs.ErrNotExist):
+		return fmt.Errorf("failed to read parameters of %s: %w", pkg.Package.ID, err)
+	}
+
+	names, err := copyPackageLayers(pkg.Path, initOutputDir)
+	if err != nil {
+		return err
+	}
+	entries, err := packageTargetLayers(names, roles)
+	if err != nil {
+		return fmt.Errorf("invalid roles in %s: %w", pkg.Package.ID, err)
+	}
+	allRoles := make([]string, 0, len(roles))
+	for _, r := range roles {
+		allRoles = append(allRoles, r.Name)
+	}
+	rolePrompt := terminalRolePrompt()
+	if initYes {
+		rolePrompt = nil
+	}
+	selected, err := selectInitRoles(roles, initRoles, allRoles, rolePrompt)
+	if err != nil {
+		return err
+	}
+
+	prompt := terminalParameterPrompt()
+	if initYes {
+		prompt = nil
+	}
+	if _, err := fillPackageParameters(initOutputDir, params, preset, gitIdentityDefaults(ctx), prompt); err != nil {
+		return err
+	}
+
+	manifest := config.SchemaModeline(config.ManifestSchemaFile) + generateManifestForPackage(pkg.Package.ID.String(), append(entries, selected...))
+	if err := os.WriteFile(configPath, []byte(manifest), 0o644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if err := addRolesToManifest(configPath, roles, nil); err != nil {
+		return err
+	}
+
+	fmt.Printf("Configuration created from %s@%s: %s\n", pkg.Package.ID, pkg.Version, configPath)
+	fmt.Println("\nNext steps:")
+	fmt.Println("  preflight plan   - Review the execution plan")
+	fmt.Println("  preflight apply  - Apply the configuration")
+
+	recordEvent(telemetry.EventInitCompleted)
+	return nil
+}
+
+// copyPackageLayers copies the layers of a package into the layers
+// directory of dir and returns their names.
+func copyPackageLayers(pkgDir, dir string) ([]string, error) {
+	layers, err := marketplace.PackageLayers(pkgDir)
+	if err != nil {
+		return nil, err
+	}
+	layersDir := filepath.Join(dir, "layers")
+	if err := os.MkdirAll(layersDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create layers directory: %w", err)
+	}
+
+	names := make([]string, 0, len(layers))
+	for _, src := range layers {
+		data, err := os.ReadFile(src)
+		if err != nil {
+			return nil, err
+		}
+		base := filepath.Base(src)
+		if err := os.WriteFile(filepath.Join(layersDir, base), data, 0o644); err != nil {
+			return nil, fmt.Errorf("failed to write layer: %w", err)
+		}
+		names = append(names, strings.TrimSuffix(base, ".yaml"))
+	}
+	return names, nil
+}
+
+// packageTargetLayers returns the layers of a package that belong to none
+// of its roles, which the default target always uses.
+func packageTargetLayers(layers []string, roles []initRole) ([]string, error) {
+	inRole := make(map[string]bool)
+	for _, r := range roles {
+		for _, layer := range r.Layers {
+			if !slices.Contains(layers, layer) {
+				return nil, fmt.Errorf("role %q lists layer %q, which the package does not ship", r.Name, layer)
+			}
+			inRole[layer] = true
+		}
+	}
+	result := make([]string, 0, len(layers))
+	for _, layer := range layers {
+		if !inRole[layer] {
+			result = append(result, layer)
+		}
+	}
+	return result, nil
+}
+
+// generateManifestForPackage creates a manifest whose default target uses
+// the given layers and roles of a package.
+func generateManifestForPackage(id string, layers []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Generated by preflight init --preset %s\ndefaults:\n  mode: intent\n\ntargets:\n", id)
+	if len(layers) == 0 {
+		b.WriteString("  default: []\n")
+		return b.String()
+	}
+	b.WriteString("  default:\n")
+	for _, layer := range layers {
+		fmt.Fprintf(&b, "    - %s\n", layer)
+	}
+	return b.String()
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/domain/marketplace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopyPackageLayers(t *testing.T) {
+	t.Parallel()
+
+	pkgDir := t.TempDir()
+	layers := filepath.Join(pkgDir, marketplace.PackageLayersDir)
+	require.NoError(t, os.MkdirAll(layers, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(layers, "base.yaml"), []byte("name: base\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(layers, "git.yaml"), []byte("name: git\ngit:\n  user:\n    email: \"{{ email }}\"\n"), 0o644))
+
+	dir := t.TempDir()
+	names, err := copyPackageLayers(pkgDir, dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"base", "git"}, names)
+
+	params := []marketplace.Parameter{{Name: "email", Required: true}, {Name: "proxy_url"}}
+	changed, err := fillPackageParameters(dir, params, map[string]string{"email": "jane@acme.com"}, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "layers", "git.yaml")}, changed)
+
+	data, err := os.ReadFile(filepath.Join(dir, "layers", "git.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `email: "jane@acme.com"`)
+
+	original, err := os.ReadFile(filepath.Join(layers, "git.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(original), "{{ email }}", "the installed package is left alone")
+}
+
+func TestGenerateManifestForPackage(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "preflight.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(generateManifestForPackage("acme-base", []string{"base", "git"})), 0o644))
+
+	manifest, err := config.NewLoader().LoadManifest(path)
+	require.NoError(t, err)
+	target, ok := manifest.Targets["default"]
+	require.True(t, ok)
+	assert.Len(t, target, 2)
+}
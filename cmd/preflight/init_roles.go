@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+)
+
+// initRole is a job family that init can add to the default target.
+type initRole struct {
+	Name        string
+	Description string
+	Layers      []string
+}
+
+// builtinRoles are offered by init for configurations made from the
+// built-in presets.
+var builtinRoles = []initRole{
+	{Name: "backend", Description: "Go, containers and databases", Layers: []string{"lang.go", "tool.containers", "tool.databases"}},
+	{Name: "frontend", Description: "Node.js and pnpm", Layers: []string{"lang.node"}},
+	{Name: "data", Description: "Python, notebooks and databases", Layers: []string{"lang.python", "tool.notebooks", "tool.databases"}},
+}
+
+// builtinRoleLayers are the layers of the built-in roles.
+var builtinRoleLayers = map[string]string{
+	"lang.go":         "name: lang.go\npackages:\n  brew:\n    formulae:\n      - go\n      - golangci-lint\n",
+	"lang.node":       "name: lang.node\npackages:\n  brew:\n    formulae:\n      - node\n      - pnpm\n",
+	"lang.python":     "name: lang.python\npackages:\n  brew:\n    formulae:\n      - python@3.12\n      - uv\n",
+	"tool.containers": "name: tool.containers\npackages:\n  brew:\n    formulae:\n      - colima\n      - docker\n      - docker-compose\n",
+	"tool.databases":  "name: tool.databases\npackages:\n  brew:\n    formulae:\n      - postgresql@16\n      - redis\n",
+	"tool.notebooks":  "name: tool.notebooks\npackages:\n  brew:\n    formulae:\n      - jupyterlab\n      - duckdb\n",
+}
+
+// packageRoles returns the roles a marketplace package defines, by name.
+func packageRoles(roles map[string][]string) []initRole {
+	result := make([]initRole, 0, len(roles))
+	for name, layers := range roles {
+		result = append(result, initRole{Name: name, Description: strings.Join(layers, ", "), Layers: layers})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// selectInitRoles returns the roles picked with --roles, else asked for
+// with prompt, else defaults.
+func selectInitRoles(roles []initRole, flag, defaults []string, prompt func([]initRole) ([]string, error)) ([]string, error) {
+	selected := flag
+	if len(selected) == 0 {
+		if prompt == nil || len(roles) == 0 {
+			return defaults, nil
+		}
+		var err error
+		if selected, err = prompt(roles); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range selected {
+		if !slices.ContainsFunc(roles, func(r initRole) bool { return r.Name == name }) {
+			names := make([]string, 0, len(roles))
+			for _, r := range roles {
+				names = append(names, r.Name)
+			}
+			return nil, &config.UserError{
+				Code:       config.ErrCodeValidationFailed,
+				Message:    fmt.Sprintf("unknown role %q", name),
+				Suggestion: fmt.Sprintf("Pick from: %s.", strings.Join(names, ", ")),
+			}
+		}
+	}
+	return selected, nil
+}
+
+// promptRoles lists the roles on out and reads a comma-separated answer
+// from in. An empty answer picks no role.
+func promptRoles(in *bufio.Reader, out io.Writer) func([]initRole) ([]string, error) {
+	return func(roles []initRole) ([]string, error) {
+		fmt.Fprintln(out, "Roles bundle the layers of a job family:")
+		for _, r := range roles {
+			fmt.Fprintf(out, "  %-10s %s\n", r.Name, r.Description)
+		}
+		fmt.Fprint(out, "Roles (comma-separated, empty for none): ")
+		answer, err := in.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		var selected []string
+		for _, name := range strings.Split(answer, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				selected = append(selected, name)
+			}
+		}
+		return selected, nil
+	}
+}
+
+// terminalRolePrompt prompts on the terminal, or returns nil when stdin is
+// not one or --yes is set.
+func terminalRolePrompt() func([]initRole) ([]string, error) {
+	if initYes || yesFlag {
+		return nil
+	}
+	info, err := os.Stdin.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return nil
+	}
+	return promptRoles(bufio.NewReader(os.Stdin), os.Stdout)
+}
+
+// writeBuiltinRoleLayers writes the layers of the selected built-in roles
+// into the layers directory of dir, keeping existing files.
+func writeBuiltinRoleLayers(dir string, selected []string) error {
+	layersDir := filepath.Join(dir, "layers")
+	for _, r := range builtinRoles {
+		if !slices.Contains(selected, r.Name) {
+			continue
+		}
+		for _, layer := range r.Layers {
+			path := filepath.Join(layersDir, layer+".yaml")
+			if _, err := os.Stat(path); err == nil {
+				continue
+			}
+			content := config.SchemaModeline(config.LayerSchemaFile) + builtinRoleLayers[layer]
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				return fmt.Errorf("failed to write layer: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// addRolesToManifest defines roles in the manifest at configPath and adds
+// the selected ones to its existing default target.
+func addRolesToManifest(configPath string, roles []initRole, selected []string) error {
+	patches := make([]config.Patch, 0, len(roles)+len(selected))
+	for _, r := range roles {
+		patches = append(patches, config.Patch{
+			LayerPath: configPath,
+			YAMLPath:  "roles." + r.Name,
+			Operation: config.PatchOpAdd,
+			NewValue:  r.Layers,
+		})
+	}
+	for _, name := range selected {
+		patches = append(patches, config.Patch{
+			LayerPath: configPath,
+			YAMLPath:  "targets.default",
+			Operation: config.PatchOpAdd,
+			NewValue:  name,
+		})
+	}
+	if len(patches) == 0 {
+		return nil
+	}
+	if err := config.NewLayerWriter().ApplyPatches(patches); err != nil {
+		return fmt.Errorf("failed to update %s: %w", configPath, err)
+	}
+	return nil
+}
+
+// setUpBuiltinRoles asks for built-in roles and adds the selected ones to
+// the configuration at configPath. Only selected roles are defined, so the
+// manifest never names layers that were not written.
+func setUpBuiltinRoles(configPath string, prompt func([]initRole) ([]string, error)) error {
+	selected, err := selectInitRoles(builtinRoles, initRoles, nil, prompt)
+	if err != nil || len(selected) == 0 {
+		return err
+	}
+	if err := writeBuiltinRoleLayers(filepath.Dir(configPath), selected); err != nil {
+		return err
+	}
+	roles := make([]initRole, 0, len(selected))
+	for _, r := range builtinRoles {
+		if slices.Contains(selected, r.Name) {
+			roles = append(roles, r)
+		}
+	}
+	return addRolesToManifest(configPath, roles, selected)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunInitNonInteractive_Roles(t *testing.T) {
+	dir := t.TempDir()
+	oldPreset, oldDir, oldRoles := initPreset, initOutputDir, initRoles
+	initPreset, initOutputDir, initRoles = "shell:minimal", dir, []string{"backend", "data"}
+	t.Cleanup(func() { initPreset, initOutputDir, initRoles = oldPreset, oldDir, oldRoles })
+
+	configPath := filepath.Join(dir, "preflight.yaml")
+	captureStdout(t, func() {
+		require.NoError(t, runInitNonInteractive(configPath))
+	})
+
+	loader := config.NewLoader()
+	manifest, err := loader.LoadManifest(configPath)
+	require.NoError(t, err)
+	name, err := config.NewTargetName("default")
+	require.NoError(t, err)
+	target, err := loader.LoadTarget(manifest, name, filepath.Join(dir, "layers"))
+	require.NoError(t, err)
+	assert.Len(t, target.Layers, 6, "base, then the layers of backend and data, tool.databases once")
+	assert.Contains(t, manifest.Roles, "backend")
+	assert.NotContains(t, manifest.Roles, "frontend", "only selected roles are defined")
+
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "- backend\n")
+	assert.True(t, strings.HasPrefix(string(data), config.SchemaModeline(config.ManifestSchemaFile)))
+}
+
+func TestSelectInitRoles(t *testing.T) {
+	t.Parallel()
+
+	selected, err := selectInitRoles(builtinRoles, []string{"frontend"}, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"frontend"}, selected)
+
+	selected, err = selectInitRoles(builtinRoles, nil, []string{"data"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"data"}, selected, "defaults apply without a prompt")
+
+	var out bytes.Buffer
+	prompt := promptRoles(bufio.NewReader(strings.NewReader("backend, frontend\n")), &out)
+	selected, err = selectInitRoles(builtinRoles, nil, nil, prompt)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backend", "frontend"}, selected)
+	assert.Contains(t, out.String(), "backend    Go, containers and databases")
+
+	_, err = selectInitRoles(builtinRoles, []string{"design"}, nil, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown role "design"`)
+}
+
+func TestPackageTargetLayers(t *testing.T) {
+	t.Parallel()
+
+	roles := packageRoles(map[string][]string{"data": {"python"}, "backend": {"go", "docker"}})
+	assert.Equal(t, "backend", roles[0].Name)
+
+	layers, err := packageTargetLayers([]string{"base", "docker", "go", "python"}, roles)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"base"}, layers)
+
+	_, err = packageTargetLayers([]string{"base", "go"}, roles)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `layer "docker"`)
+}
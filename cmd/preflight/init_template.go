@@ -0,0 +1,336 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/domain/marketplace"
+	"github.com/felixgeelhaar/preflight/internal/offline"
+	"github.com/felixgeelhaar/preflight/internal/telemetry"
+	"github.com/felixgeelhaar/preflight/internal/validation"
+	"gopkg.in/yaml.v3"
+)
+
+// templateSpecFile lets a template repository declare the variables that
+// init asks for. It is not copied into the new configuration.
+const templateSpecFile = "preflight-template.yaml"
+
+// templateRemote is the git remote that points at the template, for
+// pulling in later changes to it.
+const templateRemote = "template"
+
+// templateSpec describes a template repository.
+type templateSpec struct {
+	Variables []marketplace.Parameter `yaml:"variables"`
+	// Files lists further files to substitute variables in, besides
+	// preflight.yaml and layers/*.yaml.
+	Files []string `yaml:"files,omitempty"`
+}
+
+// defaultTemplateVariables are asked for when a template declares none.
+var defaultTemplateVariables = []marketplace.Parameter{
+	{Name: "name", Prompt: "Full name", Required: true},
+	{Name: "email", Prompt: "Email address", Required: true},
+}
+
+// runInitFromTemplate creates the configuration in initOutputDir from a
+// template repository.
+func runInitFromTemplate(ctx context.Context, configPath string) error {
+	url := initFromTemplate
+	if err := validation.ValidateGitRemoteURL(url); err != nil {
+		return fmt.Errorf("invalid template URL: %w", err)
+	}
+	if err := validation.ValidateGitRemoteURL(initRemote); err != nil {
+		return fmt.Errorf("invalid remote URL: %w", err)
+	}
+	preset, err := parseVarFlags(initVars)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.MkdirTemp("", "preflight-template-")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+
+	if offline.Enabled() {
+		return offline.Skipped("cloning template " + url)
+	}
+	src := filepath.Join(tmp, "template")
+	fmt.Printf("Cloning template %s...\n", url)
+	// #nosec G204 -- url is validated above.
+	clone := exec.CommandContext(ctx, "git", "clone", "--quiet", url, src)
+	clone.Stdout = os.Stderr
+	clone.Stderr = os.Stderr
+	if err := clone.Run(); err != nil {
+		return fmt.Errorf("git clone failed: %w", err)
+	}
+	if _, err := os.Stat(filepath.Join(src, "preflight.yaml")); err != nil {
+		return &config.UserError{
+			Code:       config.ErrCodeConfigNotFound,
+			Message:    fmt.Sprintf("template %s has no preflight.yaml at its root", url),
+			Suggestion: "Point --from-template at a repository that holds a preflight configuration.",
+		}
+	}
+
+	spec, err := loadTemplateSpec(src)
+	if err != nil {
+		return err
+	}
+	prompt := promptParameter(bufio.NewReader(os.Stdin), os.Stdout)
+	if initYes {
+		prompt = nil
+	}
+	values, err := resolveParameterValues(spec.Variables, preset, gitIdentityDefaults(ctx), prompt)
+	if err != nil {
+		return err
+	}
+
+	if err := copyTemplate(src, initOutputDir); err != nil {
+		return err
+	}
+	if err := substituteTemplateFiles(initOutputDir, spec.Files, values); err != nil {
+		return err
+	}
+	if err := setupTemplateRemotes(ctx, initOutputDir, url, initRemote); err != nil {
+		return err
+	}
+	fmt.Printf("\nConfiguration created from template: %s\n", configPath)
+
+	if err := validateTemplateConfig(ctx, configPath); err != nil {
+		return err
+	}
+
+	fmt.Println("\nNext steps:")
+	fmt.Println("  git add -A && git commit      - Commit the new configuration")
+	if initRemote != "" {
+		fmt.Println("  git push -u origin HEAD       - Publish it for 'preflight sync'")
+	}
+	fmt.Printf("  git fetch %-19s - Pick up later template changes\n", templateRemote)
+	fmt.Println("  preflight plan                - Review the execution plan")
+
+	recordEvent(telemetry.EventInitCompleted)
+	return nil
+}
+
+// loadTemplateSpec reads the template's variables, falling back to name
+// and email.
+func loadTemplateSpec(dir string) (*templateSpec, error) {
+	spec := &templateSpec{}
+	data, err := os.ReadFile(filepath.Join(dir, templateSpecFile))
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		spec.Variables = defaultTemplateVariables
+		return spec, nil
+	case err != nil:
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, spec); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", templateSpecFile, err)
+	}
+	for _, v := range spec.Variables {
+		if err := v.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", templateSpecFile, err)
+		}
+	}
+	for _, file := range spec.Files {
+		if !filepath.IsLocal(file) {
+			return nil, fmt.Errorf("invalid %s: file %q is outside the template", templateSpecFile, file)
+		}
+	}
+	return spec, nil
+}
+
+// gitIdentityDefaults suggests the global git identity for the name and
+// email variables.
+func gitIdentityDefaults(ctx context.Context) map[string]string {
+	defaults := make(map[string]string)
+	for name, key := range map[string]string{"name": "user.name", "email": "user.email"} {
+		out, err := exec.CommandContext(ctx, "git", "config", "--global", key).Output()
+		if err == nil {
+			defaults[name] = strings.TrimSpace(string(out))
+		}
+	}
+	return defaults
+}
+
+// copyTemplate copies the template into dst, leaving out its git history
+// and spec. Existing files are never overwritten.
+func copyTemplate(src, dst string) error {
+	var conflicts []string
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if rel == ".git" && d.IsDir() {
+			return filepath.SkipDir
+		}
+		if rel == "." || rel == templateSpecFile || d.IsDir() {
+			return nil
+		}
+		if _, err := os.Lstat(filepath.Join(dst, rel)); err == nil {
+			conflicts = append(conflicts, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read template: %w", err)
+	}
+	if len(conflicts) > 0 {
+		return &config.UserError{
+			Code:       config.ErrCodeWriteFailed,
+			Message:    fmt.Sprintf("%s already has files from the template: %s", dst, strings.Join(conflicts, ", ")),
+			Suggestion: "Initialize into an empty directory with --output.",
+		}
+	}
+
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		switch {
+		case rel == ".git" && d.IsDir():
+			return filepath.SkipDir
+		case rel == templateSpecFile:
+			return nil
+		case d.IsDir():
+			return os.MkdirAll(target, 0o755)
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, info.Mode().Perm())
+	})
+}
+
+// substituteTemplateFiles replaces the placeholders of known variables in
+// the manifest, the layers and the extra files of the spec.
+func substituteTemplateFiles(dir string, extra []string, values map[string]string) error {
+	files := []string{"preflight.yaml"}
+	layers, _ := filepath.Glob(filepath.Join(dir, "layers", "*.yaml"))
+	for _, layer := range layers {
+		rel, _ := filepath.Rel(dir, layer)
+		files = append(files, rel)
+	}
+	files = append(files, extra...)
+
+	for _, file := range files {
+		path := filepath.Join(dir, file)
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("failed to read template file %s: %w", file, err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		replaced := marketplace.SubstituteParameters(string(data), values)
+		if replaced == string(data) {
+			continue
+		}
+		if err := os.WriteFile(path, []byte(replaced), info.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+// setupTemplateRemotes makes dir a git repository with the template as the
+// template remote and, when given, origin as the remote that sync uses.
+func setupTemplateRemotes(ctx context.Context, dir, templateURL, origin string) error {
+	git := func(args ...string) error {
+		// #nosec G204 -- the URLs are validated by the caller.
+		cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		if err := git("init", "--quiet"); err != nil {
+			return err
+		}
+	}
+	remotes := map[string]string{templateRemote: templateURL}
+	if origin != "" {
+		remotes["origin"] = origin
+	}
+	names := make([]string, 0, len(remotes))
+	for name := range remotes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if git("remote", "get-url", name) == nil {
+			if err := git("remote", "set-url", name, remotes[name]); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := git("remote", "add", name, remotes[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateTemplateConfig validates the new configuration for its default
+// target, or its first one when it has no default.
+func validateTemplateConfig(ctx context.Context, configPath string) error {
+	targets, err := onboardTargets(configPath)
+	if err != nil {
+		return err
+	}
+	target := "default"
+	if len(targets) > 0 && !slices.Contains(targets, target) {
+		target = targets[0]
+	}
+
+	fmt.Printf("\nValidating target %s...\n", target)
+	result, err := app.New(os.Stdout).Validate(ctx, configPath, target)
+	if err != nil {
+		return err
+	}
+	outputValidationText(result)
+	if len(result.Errors) > 0 {
+		return &config.UserError{
+			Code:       config.ErrCodeValidationFailed,
+			Message:    "the configuration created from the template does not validate",
+			Suggestion: "Fix the errors above in the new files, then run 'preflight validate'.",
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/marketplace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadTemplateSpec(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	spec, err := loadTemplateSpec(dir)
+	require.NoError(t, err)
+	assert.Equal(t, defaultTemplateVariables, spec.Variables, "templates without a spec ask for name and email")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, templateSpecFile), []byte(`
+variables:
+  - name: team
+    prompt: Team
+    default: platform
+files: [dotfiles/.gitconfig]
+`), 0o644))
+	spec, err = loadTemplateSpec(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []marketplace.Parameter{{Name: "team", Prompt: "Team", Default: "platform"}}, spec.Variables)
+	assert.Equal(t, []string{"dotfiles/.gitconfig"}, spec.Files)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, templateSpecFile), []byte("files: [../outside]\n"), 0o644))
+	_, err = loadTemplateSpec(dir)
+	assert.Error(t, err)
+}
+
+func TestRunInitFromTemplate(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	template := t.TempDir()
+	files := map[string]string{
+		"preflight.yaml":      "defaults:\n  mode: intent\ntargets:\n  default: [base]\n",
+		"layers/base.yaml":    "name: base\ngit:\n  user:\n    name: \"{{ name }}\"\n    email: \"{{ email }}\"\n",
+		"dotfiles/.gitignore": "{{ name }}\n",
+		templateSpecFile:      "variables:\n  - name: name\n    required: true\n  - name: email\n    required: true\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(template, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"add", "-A"},
+		{"-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "template"},
+	} {
+		out, err := exec.Command("git", append([]string{"-C", template}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	out := filepath.Join(t.TempDir(), "config")
+	oldTemplate, oldVars, oldRemote, oldOutput, oldYes := initFromTemplate, initVars, initRemote, initOutputDir, initYes
+	t.Cleanup(func() {
+		initFromTemplate, initVars, initRemote, initOutputDir, initYes = oldTemplate, oldVars, oldRemote, oldOutput, oldYes
+	})
+	initFromTemplate = template
+	initVars = []string{"name=Jane Doe", "email=jane@acme.com"}
+	initRemote = ""
+	initOutputDir = out
+	initYes = true
+
+	require.NoError(t, runInitFromTemplate(context.Background(), filepath.Join(out, "preflight.yaml")))
+
+	layer, err := os.ReadFile(filepath.Join(out, "layers", "base.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(layer), `name: "Jane Doe"`)
+	assert.Contains(t, string(layer), `email: "jane@acme.com"`)
+
+	dotfile, err := os.ReadFile(filepath.Join(out, "dotfiles", ".gitignore"))
+	require.NoError(t, err)
+	assert.Equal(t, "{{ name }}\n", string(dotfile), "files not listed in the spec are copied as is")
+	assert.NoFileExists(t, filepath.Join(out, templateSpecFile))
+
+	remote, err := exec.Command("git", "-C", out, "remote", "get-url", templateRemote).Output()
+	require.NoError(t, err)
+	assert.Equal(t, template, strings.TrimSpace(string(remote)))
+
+	err = runInitFromTemplate(context.Background(), filepath.Join(out, "preflight.yaml"))
+	assert.Error(t, err, "existing files are not overwritten")
+}
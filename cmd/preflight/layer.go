@@ -0,0 +1,409 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/domain/marketplace"
+	"github.com/felixgeelhaar/preflight/internal/offline"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/spf13/cobra"
+)
+
+var layerCmd = &cobra.Command{
+	Use:   "layer",
+	Short: "Share single layers as preset bundles",
+	Long: `Export a layer of your configuration as a shareable preset bundle, or
+import a bundle someone shared into your configuration.
+
+A bundle is a YAML file with the layer, marketplace package metadata
+(id, title, version, author), a checksum and an optional signature.`,
+}
+
+var layerExportCmd = &cobra.Command{
+	Use:   "export <layer>",
+	Short: "Export a layer as a preset bundle",
+	Long: `Export a layer as a preset bundle that others can import.
+
+The bundle carries the package metadata of the marketplace. With --sign it
+is signed with an ED25519 key from your trust store (see 'preflight trust
+add'), so that importers can verify who shared it.
+
+With --param, the bundle declares parameters that importers are asked for.
+Write them in the layer as quoted "{{ name }}" placeholders.
+
+Formats:
+  - preset (default): Bundle with metadata, checksum and signature
+  - yaml: The layer file as is
+
+Examples:
+  preflight layer export dev-go
+  preflight layer export dev-go -o dev-go.yaml --version 1.2.0
+  preflight layer export dev-go --sign --key ~/.ssh/id_ed25519
+  preflight layer export acme-git --param email --param proxy_url=http://proxy:3128`,
+	Args: cobra.ExactArgs(1),
+	RunE: runLayerExport,
+}
+
+var layerImportCmd = &cobra.Command{
+	Use:   "import <file|url>",
+	Short: "Import a preset bundle as a layer",
+	Long: `Import a preset bundle from a file or an http(s) URL into layers/ and
+optionally add the layer to targets.
+
+The checksum of the bundle is always verified. Signed bundles are only
+imported when the signing key is in your trust store and the signature
+matches. Unsigned bundles are imported with a warning.
+
+Parameters declared by the bundle are filled in from --var flags, prompted
+for on a terminal, or fall back to their defaults.
+
+Examples:
+  preflight layer import dev-go.yaml --target work
+  preflight layer import https://example.com/presets/dev-go.yaml
+  preflight layer import dev-go.yaml --name role.go --force
+  preflight layer import acme-git.yaml --var email=jane@acme.com`,
+	Args: cobra.ExactArgs(1),
+	RunE: runLayerImport,
+}
+
+var (
+	layerConfigPath string
+
+	layerExportFormat      string
+	layerExportOutput      string
+	layerExportID          string
+	layerExportTitle       string
+	layerExportDescription string
+	layerExportVersion     string
+	layerExportAuthor      string
+	layerExportSign        bool
+	layerExportKey         string
+	layerExportKeyID       string
+	layerExportParams      []string
+
+	layerImportName    string
+	layerImportTargets []string
+	layerImportForce   bool
+	layerImportVars    []string
+)
+
+func init() {
+	layerCmd.PersistentFlags().StringVarP(&layerConfigPath, "config", "c", "preflight.yaml", "Path to preflight.yaml")
+
+	layerExportCmd.Flags().StringVarP(&layerExportFormat, "format", "f", "preset", "Output format (preset, yaml)")
+	layerExportCmd.Flags().StringVarP(&layerExportOutput, "output", "o", "", "Output file (default: stdout)")
+	layerExportCmd.Flags().StringVar(&layerExportID, "id", "", "Package ID (default: derived from the layer name)")
+	layerExportCmd.Flags().StringVar(&layerExportTitle, "title", "", "Package title (default: the layer name)")
+	layerExportCmd.Flags().StringVar(&layerExportDescription, "description", "", "Package description")
+	layerExportCmd.Flags().StringVar(&layerExportVersion, "version", "1.0.0", "Package version")
+	layerExportCmd.Flags().StringVar(&layerExportAuthor, "author", "", "Package author (default: git user.name)")
+	layerExportCmd.Flags().BoolVar(&layerExportSign, "sign", false, "Sign the bundle")
+	layerExportCmd.Flags().StringVar(&layerExportKey, "key", "~/.ssh/id_ed25519", "ED25519 private key used for signing")
+	layerExportCmd.Flags().StringVar(&layerExportKeyID, "key-id", "", "Trust store key ID (defaults to the key's fingerprint)")
+	layerExportCmd.Flags().StringArrayVar(&layerExportParams, "param", nil, "Parameter importers are asked for, as name or name=default (repeatable)")
+
+	layerImportCmd.Flags().StringVar(&layerImportName, "name", "", "Layer name (default: the name in the bundle)")
+	layerImportCmd.Flags().StringSliceVarP(&layerImportTargets, "target", "t", nil, "Targets to add the layer to")
+	layerImportCmd.Flags().BoolVar(&layerImportForce, "force", false, "Overwrite an existing layer")
+	layerImportCmd.Flags().StringArrayVar(&layerImportVars, "var", nil, "Bundle parameter as name=value (repeatable)")
+
+	layerCmd.AddCommand(layerExportCmd, layerImportCmd)
+	rootCmd.AddCommand(layerCmd)
+}
+
+func runLayerExport(_ *cobra.Command, args []string) error {
+	name := args[0]
+	path := filepath.Join(filepath.Dir(layerConfigPath), "layers", name+".yaml")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return &config.UserError{
+			Code:       config.ErrCodeLayerNotFound,
+			Message:    fmt.Sprintf("layer %q not found", name),
+			Suggestion: fmt.Sprintf("Expected %s", path),
+			Underlying: err,
+		}
+	}
+	layer, err := config.ParseLayer(data)
+	if err != nil {
+		return fmt.Errorf("invalid layer %s: %w", path, err)
+	}
+	if len(layer.Files) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: layer %s links files from the config repository; they are not part of the bundle\n", name)
+	}
+
+	var output []byte
+	switch strings.ToLower(layerExportFormat) {
+	case "yaml", "yml":
+		output = data
+	case "preset":
+		output, err = exportLayerBundle(name, data)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported format: %s", layerExportFormat)
+	}
+
+	if layerExportOutput == "" {
+		fmt.Print(string(output))
+		return nil
+	}
+	if err := os.WriteFile(layerExportOutput, output, 0o644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	fmt.Printf("Exported layer %s to %s\n", name, layerExportOutput)
+	return nil
+}
+
+// exportLayerBundle bundles a layer file with the package metadata from
+// the flags, signed when --sign is set.
+func exportLayerBundle(name string, data []byte) ([]byte, error) {
+	params, err := parseParamFlags(layerExportParams)
+	if err != nil {
+		return nil, err
+	}
+	spec := marketplace.PackageSpec{
+		ID:          layerExportID,
+		Type:        marketplace.PackageTypePreset,
+		Title:       layerExportTitle,
+		Description: layerExportDescription,
+		Version:     strings.TrimPrefix(layerExportVersion, "v"),
+		Author:      layerExportAuthor,
+		Parameters:  params,
+	}
+	if spec.ID == "" {
+		spec.ID = packageIDFromLayer(name)
+	}
+	if spec.Title == "" {
+		spec.Title = name
+	}
+	if spec.Author == "" {
+		spec.Author = gitIdentityDefaults(context.Background())["name"]
+	}
+
+	bundle, err := marketplace.NewLayerBundle(spec, data)
+	if err != nil {
+		return nil, err
+	}
+	if layerExportSign {
+		key, keyID, err := resolvePublishSigningKey(layerExportKey, layerExportKeyID)
+		if err != nil {
+			return nil, err
+		}
+		pub, err := os.ReadFile(ports.ExpandPath(layerExportKey) + ".pub")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read public key: %w", err)
+		}
+		if err := bundle.Sign(key, keyID, pub); err != nil {
+			return nil, err
+		}
+	}
+	return bundle.Encode()
+}
+
+var nonPackageIDChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// packageIDFromLayer derives a package ID from a layer name, e.g.
+// "role.go-developer" becomes "role-go-developer".
+func packageIDFromLayer(name string) string {
+	return strings.Trim(nonPackageIDChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+func runLayerImport(_ *cobra.Command, args []string) error {
+	vars, err := parseVarFlags(layerImportVars)
+	if err != nil {
+		return err
+	}
+	data, err := readLayerBundle(args[0])
+	if err != nil {
+		return err
+	}
+	bundle, err := marketplace.ParseLayerBundle(data)
+	if err != nil {
+		return err
+	}
+	if !(marketplace.PackageVersion{MinVersion: strings.TrimPrefix(bundle.MinPreflightVersion, "v")}).IsCompatibleWith(version) {
+		return fmt.Errorf("%s@%s needs preflight %s or later", bundle.ID, bundle.Version, bundle.MinPreflightVersion)
+	}
+
+	if bundle.IsSigned() {
+		if err := verifyLayerBundle(bundle); err != nil {
+			return err
+		}
+	} else {
+		fmt.Fprintf(os.Stderr, "Warning: %s@%s is not signed; review the layer before applying it\n", bundle.ID, bundle.Version)
+	}
+
+	values, err := resolveParameterValues(marketplace.ReferencedParameters(bundle.Layer, bundle.Parameters), vars, nil, terminalParameterPrompt())
+	if err != nil {
+		return err
+	}
+	content := []byte(marketplace.SubstituteParameters(bundle.Layer, values))
+
+	layer, err := config.ParseLayer(content)
+	if err != nil {
+		return fmt.Errorf("invalid layer in bundle: %w", err)
+	}
+	name := layer.Name.String()
+	if layerImportName != "" && layerImportName != name {
+		if _, err := config.NewLayerName(layerImportName); err != nil {
+			return fmt.Errorf("invalid layer name %q: %w", layerImportName, err)
+		}
+		name = layerImportName
+		content, err = renameLayer(content, name)
+		if err != nil {
+			return err
+		}
+	}
+
+	manifest, err := config.NewLoader().LoadManifest(layerConfigPath)
+	if err != nil {
+		return err
+	}
+	for _, target := range layerImportTargets {
+		if _, err := config.NewTargetName(target); err != nil {
+			return fmt.Errorf("invalid target name %q: %w", target, err)
+		}
+	}
+
+	layersDir := filepath.Join(filepath.Dir(layerConfigPath), "layers")
+	path := filepath.Join(layersDir, name+".yaml")
+	if _, err := os.Stat(path); err == nil && !layerImportForce {
+		return &config.UserError{
+			Code:       config.ErrCodeWriteFailed,
+			Message:    fmt.Sprintf("layer %s already exists", name),
+			Suggestion: "Use --force to overwrite it or --name to import under another name",
+		}
+	}
+	if err := os.MkdirAll(layersDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create layers directory: %w", err)
+	}
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		return fmt.Errorf("failed to write layer: %w", err)
+	}
+	fmt.Printf("Imported %s@%s as layer %s (%s)\n", bundle.ID, bundle.Version, name, path)
+
+	if err := addLayerToTargets(layerConfigPath, manifest, name, layerImportTargets); err != nil {
+		return err
+	}
+	for _, target := range layerImportTargets {
+		fmt.Printf("Added %s to target %s\n", name, target)
+	}
+	fmt.Println("\nRun 'preflight plan' to review the changes.")
+	return nil
+}
+
+// layerBundleMaxSize bounds bundles downloaded from a URL.
+const layerBundleMaxSize = 1 << 20
+
+// readLayerBundle reads a bundle from a file or an http(s) URL.
+func readLayerBundle(src string) ([]byte, error) {
+	if !strings.HasPrefix(src, "https://") && !strings.HasPrefix(src, "http://") {
+		data, err := os.ReadFile(src)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bundle: %w", err)
+		}
+		return data, nil
+	}
+	if offline.Enabled() {
+		return nil, offline.Skipped("downloading " + src)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, src, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download bundle: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download bundle: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download bundle: %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, layerBundleMaxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download bundle: %w", err)
+	}
+	if len(data) > layerBundleMaxSize {
+		return nil, fmt.Errorf("bundle is larger than %d bytes", layerBundleMaxSize)
+	}
+	return data, nil
+}
+
+// verifyLayerBundle verifies the signature of a bundle with the key of the
+// trust store it names.
+func verifyLayerBundle(bundle *marketplace.LayerBundle) error {
+	store, err := getTrustStore()
+	if err != nil {
+		return err
+	}
+	trusted, ok := store.Get(bundle.KeyID)
+	if !ok {
+		return &config.UserError{
+			Code:       config.ErrCodeValidationFailed,
+			Message:    fmt.Sprintf("%s@%s is signed by %s, which is not in your trust store", bundle.ID, bundle.Version, bundle.KeyID),
+			Suggestion: "Add the publisher's public key with 'preflight trust add <key.pub>'",
+		}
+	}
+	if err := bundle.VerifySignature(trusted.Fingerprint()); err != nil {
+		return err
+	}
+	fmt.Printf("Signature verified: %s\n", bundle.KeyID)
+	return nil
+}
+
+// renameLayer sets the name of a layer file, preserving its comments.
+func renameLayer(content []byte, name string) ([]byte, error) {
+	const path = "layer.yaml"
+	files, err := config.NewLayerWriter().PreviewFrom(map[string][]byte{path: content}, []config.Patch{
+		{LayerPath: path, YAMLPath: "name", Operation: config.PatchOpModify, NewValue: name},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to rename layer: %w", err)
+	}
+	return files[path], nil
+}
+
+// addLayerToTargets appends a layer to targets of the manifest, creating
+// targets that do not exist yet.
+func addLayerToTargets(configPath string, manifest *config.Manifest, layer string, targets []string) error {
+	patches := make([]config.Patch, 0, len(targets))
+	for _, target := range targets {
+		var value interface{} = layer
+		path := "targets." + target
+		if ext, ok := manifest.Extensions[target]; ok {
+			// A target extending others lists its own layers under add
+			path += ".add"
+			if len(ext.Add) == 0 {
+				value = []string{layer}
+			}
+		} else if _, ok := manifest.Targets[target]; !ok {
+			value = []string{layer}
+		}
+		patches = append(patches, config.Patch{
+			LayerPath: configPath,
+			YAMLPath:  path,
+			Operation: config.PatchOpAdd,
+			NewValue:  value,
+		})
+	}
+	if len(patches) == 0 {
+		return nil
+	}
+	if err := config.NewLayerWriter().ApplyPatches(patches); err != nil {
+		return fmt.Errorf("failed to update %s: %w", configPath, err)
+	}
+	return nil
+}
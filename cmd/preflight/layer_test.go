@@ -0,0 +1,68 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/offline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackageIDFromLayer(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "dev-go", packageIDFromLayer("dev-go"))
+	assert.Equal(t, "role-go-developer", packageIDFromLayer("role.go-developer"))
+	assert.Equal(t, "my-layer", packageIDFromLayer("_My_Layer_"))
+}
+
+func TestRenameLayer(t *testing.T) {
+	t.Parallel()
+
+	renamed, err := renameLayer([]byte("# Go tooling\nname: dev-go\npackages:\n  brew:\n    formulae: [go]\n"), "role.go")
+	require.NoError(t, err)
+	assert.Equal(t, "# Go tooling\nname: role.go\npackages:\n  brew:\n    formulae: [go]\n", string(renamed))
+}
+
+func TestAddLayerToTargets(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "preflight.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("targets:\n  default:\n    - base # shared\n"), 0o644))
+	manifest, err := config.NewLoader().LoadManifest(path)
+	require.NoError(t, err)
+
+	require.NoError(t, addLayerToTargets(path, manifest, "dev-go", []string{"default", "work"}))
+	require.NoError(t, addLayerToTargets(path, manifest, "dev-go", []string{"default"}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "targets:\n  default:\n    - base # shared\n    - dev-go\n  work:\n    - dev-go\n", string(data))
+}
+
+func TestAddLayerToTargets_ExtendedTarget(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "preflight.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("targets:\n  work: [base]\n  laptop:\n    extends: work\n"), 0o644))
+	manifest, err := config.NewLoader().LoadManifest(path)
+	require.NoError(t, err)
+
+	require.NoError(t, addLayerToTargets(path, manifest, "dev-go", []string{"laptop"}))
+
+	manifest, err = config.NewLoader().LoadManifest(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dev-go"}, manifest.Extensions["laptop"].Add)
+	assert.Len(t, manifest.Targets["work"], 1)
+}
+
+func TestReadLayerBundle_Offline(t *testing.T) {
+	t.Setenv(offline.EnvVar, "1")
+
+	_, err := readLayerBundle("https://example.com/dev-go.yaml")
+	require.ErrorIs(t, err, offline.ErrOffline)
+	assert.Equal(t, "downloading https://example.com/dev-go.yaml skipped (offline)", err.Error())
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/felixgeelhaar/preflight/internal/adapters/logging"
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
+	"github.com/felixgeelhaar/preflight/internal/offline"
+	"github.com/felixgeelhaar/preflight/internal/securestate"
+	"github.com/spf13/cobra"
+)
+
+// Logging flags.
+var (
+	logLevel  string
+	logFormat string
+	logFile   string
+)
+
+// Logging defaults. Warnings are shown; the rest needs --log-level.
+const (
+	defaultLogLevel  = "warn"
+	defaultLogFormat = "text"
+)
+
+// logOutput is the open --log-file, closed by Execute.
+var logOutput io.Closer
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", defaultLogLevel, "log level (debug, info, warn, error)")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", defaultLogFormat, "log format (text, json)")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "append logs to this file instead of stderr")
+
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, _ []string) error {
+		setupColor()
+		if offlineFlag || offline.Enabled() {
+			offline.Enable()
+		}
+		if err := resolveConfigFlag(cmd); err != nil {
+			return err
+		}
+		return setupLogging()
+	}
+
+	_ = rootCmd.RegisterFlagCompletionFunc("log-level", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{"debug", "info", "warn", "error"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	_ = rootCmd.RegisterFlagCompletionFunc("log-format", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{"text", "json"}, cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+// setupLogging sets the process-wide logger from the logging flags.
+func setupLogging() error {
+	level, err := logging.ParseLevel(logLevel)
+	if err != nil {
+		return fmt.Errorf("invalid --log-level: %w", err)
+	}
+	format, err := logging.ParseFormat(logFormat)
+	if err != nil {
+		return fmt.Errorf("invalid --log-format: %w", err)
+	}
+
+	var out io.Writer = os.Stderr
+	if isAgentLog(logFile) {
+		// The agent log is state: it is encrypted with the rest of it.
+		w, err := securestate.OpenLog(logFile)
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		out, logOutput = w, w
+	} else if logFile != "" {
+		// #nosec G301 -- the log directory is chosen by the user.
+		if err := os.MkdirAll(filepath.Dir(logFile), 0o755); err != nil {
+			return fmt.Errorf("failed to create log directory: %w", err)
+		}
+		// #nosec G304 -- the log file is chosen by the user.
+		f, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		out, logOutput = f, f
+	}
+
+	logging.SetDefault(logging.NewSlogLogger(out, level, format))
+	return nil
+}
+
+func closeLogging() {
+	if logOutput != nil {
+		_ = logOutput.Close()
+		logOutput = nil
+	}
+}
+
+// logFlagArgs returns the logging flags that differ from their defaults, to
+// pass on to a preflight process started by this one.
+func logFlagArgs() []string {
+	var args []string
+	if logLevel != defaultLogLevel {
+		args = append(args, "--log-level", logLevel)
+	}
+	if logFormat != defaultLogFormat {
+		args = append(args, "--log-format", logFormat)
+	}
+	if logFile != "" {
+		if abs, err := filepath.Abs(logFile); err == nil {
+			args = append(args, "--log-file", abs)
+		}
+	}
+	return args
+}
+
+// agentLogArgs returns the logging flags for an agent started as a daemon.
+// The daemon has no terminal, so without --log-file it logs to
+// agent.log in the state directory.
+func agentLogArgs() []string {
+	args := logFlagArgs()
+	if logFile != "" {
+		return args
+	}
+	path, err := agentLogPath()
+	if err != nil {
+		return args
+	}
+	return append(args, "--log-file", path)
+}
+
+// agentLogPath returns the path of agent.log in the state directory.
+func agentLogPath() (string, error) {
+	return platform.Path(platform.StateDir, "agent.log")
+}
+
+// isAgentLog reports whether path is agent.log in the state directory.
+func isAgentLog(path string) bool {
+	if path == "" {
+		return false
+	}
+	agentLog, err := agentLogPath()
+	if err != nil {
+		return false
+	}
+	abs, err := filepath.Abs(path)
+	return err == nil && abs == filepath.Clean(agentLog)
+}
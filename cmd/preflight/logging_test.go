@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/adapters/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetupLogging_LogFile(t *testing.T) {
+	prevLevel, prevFormat, prevFile := logLevel, logFormat, logFile
+	t.Cleanup(func() {
+		closeLogging()
+		logLevel, logFormat, logFile = prevLevel, prevFormat, prevFile
+		logging.SetDefault(logging.NewNopLogger())
+	})
+
+	logLevel, logFormat = "info", "json"
+	logFile = filepath.Join(t.TempDir(), "logs", "preflight.log")
+	require.NoError(t, setupLogging())
+	logging.Default().Info(context.Background(), "reconcile finished")
+	closeLogging()
+
+	data, err := os.ReadFile(logFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"msg":"reconcile finished"`)
+	assert.Equal(t, []string{"--log-level", "info", "--log-format", "json", "--log-file", logFile}, logFlagArgs())
+
+	logLevel = "loud"
+	assert.ErrorContains(t, setupLogging(), "--log-level")
+}
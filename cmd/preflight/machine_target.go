@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/adapters/command"
+	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/domain/sync"
+	"github.com/spf13/cobra"
+)
+
+// machineFingerprint returns this machine's fingerprint; tests replace it.
+var machineFingerprint = func(ctx context.Context) (sync.Fingerprint, error) {
+	return app.MachineFingerprint(ctx, app.NewFingerprintSensor(command.NewRealRunner()))
+}
+
+// recordInitFingerprint records this machine's fingerprint; tests replace it.
+var recordInitFingerprint = func(ctx context.Context) error {
+	_, err := app.RecordFingerprint(ctx, app.NewFingerprintSensor(command.NewRealRunner()))
+	return err
+}
+
+// resolveMachineTarget returns the target to use when --target was not
+// given: the first target bound to this machine in preflight.yaml, or
+// target when none is. Config errors are left for the plan to report.
+func resolveMachineTarget(ctx context.Context, cmd *cobra.Command, configPath, target string) string {
+	if cmd != nil && cmd.Flags().Changed("target") {
+		return target
+	}
+	manifest, err := config.NewLoader().LoadManifest(configPath)
+	if err != nil || len(manifest.Machines) == 0 {
+		return target
+	}
+	fp, err := machineFingerprint(ctx)
+	if err != nil {
+		return target
+	}
+	binding, ok := manifest.TargetFor(fp)
+	if !ok {
+		return target
+	}
+	fmt.Printf("Using target %s, bound to this machine by %s\n", binding.Target, binding)
+	return binding.Target
+}
+
+// printTargetMachines prints the target and the other machines in the
+// inventory that last applied it.
+func printTargetMachines(configPath, target string) {
+	fmt.Printf("   Target: %s\n", target)
+	inv, err := sync.LoadInventory(app.MachineInventoryDir(configPath))
+	if err != nil {
+		return
+	}
+	self, _ := sync.GetMachineID()
+	var hosts []string
+	for _, s := range inv.ByTarget()[target] {
+		if s.MachineID != self.String() {
+			hosts = append(hosts, s.Hostname)
+		}
+	}
+	if len(hosts) > 0 {
+		fmt.Printf("   Also applied on: %s\n", strings.Join(hosts, ", "))
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/sync"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubMachineFingerprint pins this machine's fingerprint for a test.
+func stubMachineFingerprint(t *testing.T, fp sync.Fingerprint) {
+	t.Helper()
+	orig := machineFingerprint
+	machineFingerprint = func(context.Context) (sync.Fingerprint, error) { return fp, nil }
+	t.Cleanup(func() { machineFingerprint = orig })
+}
+
+func TestResolveMachineTarget(t *testing.T) {
+	stubMachineFingerprint(t, sync.Fingerprint{Hostname: "studio", Chip: "Apple M2 Ultra", MemoryBytes: 64 << 30})
+	configPath := filepath.Join(t.TempDir(), "preflight.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`targets:
+  default: [base]
+  workstation: [base]
+machines:
+  - target: workstation
+    min_memory_gb: 32
+`), 0o644))
+
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().StringP("target", "t", "default", "")
+		return cmd
+	}
+
+	var target string
+	out := captureStdout(t, func() {
+		target = resolveMachineTarget(context.Background(), newCmd(), configPath, "default")
+	})
+	assert.Equal(t, "workstation", target)
+	assert.Contains(t, out, "Using target workstation, bound to this machine by min_memory_gb 32")
+
+	// An explicit --target wins over the binding
+	cmd := newCmd()
+	require.NoError(t, cmd.Flags().Set("target", "default"))
+	assert.Equal(t, "default", resolveMachineTarget(context.Background(), cmd, configPath, "default"))
+
+	// No binding matches
+	stubMachineFingerprint(t, sync.Fingerprint{Hostname: "air", MemoryBytes: 8 << 30})
+	assert.Equal(t, "default", resolveMachineTarget(context.Background(), newCmd(), configPath, "default"))
+
+	// Config errors are left to the plan
+	assert.Equal(t, "default", resolveMachineTarget(context.Background(), newCmd(), filepath.Join(t.TempDir(), "missing.yaml"), "default"))
+}
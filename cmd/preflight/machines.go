@@ -0,0 +1,337 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/domain/sync"
+	"github.com/spf13/cobra"
+)
+
+var machinesCmd = &cobra.Command{
+	Use:   "machines",
+	Short: "Show which machines have applied this configuration",
+	Long: `Show the machines that have applied this configuration.
+
+Every successful 'preflight apply' records what the machine applied in
+.preflight/machines/<machine-id>.json next to preflight.yaml, together with
+the lockfile's version vector. Commit that directory with your config to see
+every machine's state from any of them.
+
+Examples:
+  preflight machines list              # All known machines
+  preflight machines status            # Which machines are behind
+  preflight machines status laptop     # Divergent packages for one machine
+  preflight machines targets           # Which machines use which targets
+  preflight machines fingerprint       # This machine's hardware fingerprint`,
+}
+
+var machinesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List machines that have applied this configuration",
+	Args:  cobra.NoArgs,
+	RunE:  runMachinesList,
+}
+
+var machinesStatusCmd = &cobra.Command{
+	Use:   "status [machine]",
+	Short: "Compare each machine's applied state with the lockfile",
+	Long: `Compare each machine's applied state with the lockfile.
+
+A machine is behind when the lockfile has changed since it last applied,
+or when the versions it applied differ from the locked versions. The
+machine can be given as a hostname, machine ID or unique ID prefix.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runMachinesStatus,
+}
+
+var machinesTargetsCmd = &cobra.Command{
+	Use:   "targets",
+	Short: "Show which machines last applied each target",
+	Args:  cobra.NoArgs,
+	RunE:  runMachinesTargets,
+}
+
+var machinesFingerprintCmd = &cobra.Command{
+	Use:   "fingerprint",
+	Short: "Show this machine's fingerprint and the target bound to it",
+	Long: `Show this machine's fingerprint and the target bound to it.
+
+The fingerprint is recorded by 'preflight init' in
+~/.local/state/preflight/fingerprint.json.
+Bind targets to machines in preflight.yaml so that apply, plan and sync pick
+the target without --target. The first matching entry wins; hostname and chip
+are globs:
+
+  machines:
+    - target: workstation
+      serial: C02XYZ123
+    - target: laptop
+      chip: "Apple M*"
+      min_memory_gb: 16
+    - target: server
+      hostname: "build-*"
+
+Use --record to re-read the hardware, for example after a hostname change.`,
+	Args: cobra.NoArgs,
+	RunE: runMachinesFingerprint,
+}
+
+var (
+	machinesConfigPath string
+	machinesJSON       bool
+	machinesRecord     bool
+)
+
+func init() {
+	rootCmd.AddCommand(machinesCmd)
+	machinesCmd.AddCommand(machinesListCmd)
+	machinesCmd.AddCommand(machinesStatusCmd)
+	machinesCmd.AddCommand(machinesTargetsCmd)
+	machinesCmd.AddCommand(machinesFingerprintCmd)
+
+	machinesCmd.PersistentFlags().StringVarP(&machinesConfigPath, "config", "c", "preflight.yaml", "Path to preflight.yaml")
+	machinesCmd.PersistentFlags().BoolVar(&machinesJSON, "json", false, "Output as JSON")
+	machinesFingerprintCmd.Flags().BoolVar(&machinesRecord, "record", false, "Re-read and record the fingerprint")
+}
+
+// machineStatusJSON is the JSON form of a machine's status.
+type machineStatusJSON struct {
+	sync.MachineState
+	Relation  string                   `json:"relation"`
+	Behind    bool                     `json:"behind"`
+	Divergent []sync.PackageDivergence `json:"divergent,omitempty"`
+}
+
+func runMachinesList(_ *cobra.Command, _ []string) error {
+	inv, lockState, err := app.New(os.Stdout).MachineInventory(context.Background(), machinesConfigPath)
+	if err != nil {
+		return err
+	}
+
+	if machinesJSON {
+		return writeMachinesJSON(inv.List())
+	}
+
+	if inv.Len() == 0 {
+		fmt.Println("No machines recorded yet. Run 'preflight apply' to record this machine.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "HOSTNAME\tMACHINE\tOS\tTARGET\tLAST APPLIED\tSTATUS")
+	for _, s := range inv.Status(lockState) {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			s.Hostname, shortMachineID(s.MachineID), s.OS, s.Target,
+			formatAge(s.AppliedAt), machineStatusLabel(s))
+	}
+	return w.Flush()
+}
+
+func runMachinesStatus(_ *cobra.Command, args []string) error {
+	inv, lockState, err := app.New(os.Stdout).MachineInventory(context.Background(), machinesConfigPath)
+	if err != nil {
+		return err
+	}
+
+	statuses := inv.Status(lockState)
+	if len(args) == 1 {
+		state, ok := inv.FindMachine(args[0])
+		if !ok {
+			return fmt.Errorf("machine %q not found in inventory", args[0])
+		}
+		statuses = []sync.MachineStatus{sync.CompareMachineState(state, lockState)}
+	}
+
+	if machinesJSON {
+		out := make([]machineStatusJSON, 0, len(statuses))
+		for _, s := range statuses {
+			out = append(out, machineStatusJSON{
+				MachineState: s.MachineState,
+				Relation:     s.Relation.String(),
+				Behind:       s.IsBehind(),
+				Divergent:    s.Divergent,
+			})
+		}
+		return writeMachinesJSON(out)
+	}
+
+	if len(statuses) == 0 {
+		fmt.Println("No machines recorded yet. Run 'preflight apply' to record this machine.")
+		return nil
+	}
+
+	behind := 0
+	for _, s := range statuses {
+		if s.IsBehind() {
+			behind++
+		}
+		fmt.Printf("%s (%s)\n", s.Hostname, shortMachineID(s.MachineID))
+		fmt.Printf("  Status:       %s\n", machineStatusLabel(s))
+		fmt.Printf("  Last applied: %s (%s)\n", s.AppliedAt.Local().Format(time.RFC3339), formatAge(s.AppliedAt))
+		if s.Target != "" {
+			fmt.Printf("  Target:       %s\n", s.Target)
+		}
+		if s.Fingerprint != nil {
+			fmt.Printf("  Hardware:     %s\n", describeFingerprint(*s.Fingerprint))
+		}
+		if len(s.Divergent) > 0 {
+			fmt.Printf("  Divergent packages (%d):\n", len(s.Divergent))
+			for _, d := range s.Divergent {
+				fmt.Printf("    %s: applied %s, locked %s\n", d.PackageKey, orNone(d.Applied), orNone(d.Locked))
+			}
+		}
+		fmt.Println()
+	}
+	fmt.Printf("%d of %d machine(s) behind the lockfile\n", behind, len(statuses))
+	return nil
+}
+
+func runMachinesTargets(_ *cobra.Command, _ []string) error {
+	inv, err := sync.LoadInventory(app.MachineInventoryDir(machinesConfigPath))
+	if err != nil {
+		return fmt.Errorf("failed to load machine inventory: %w", err)
+	}
+	byTarget := inv.ByTarget()
+
+	if machinesJSON {
+		out := make(map[string][]string, len(byTarget))
+		for target, states := range byTarget {
+			for _, s := range states {
+				out[target] = append(out[target], s.Hostname)
+			}
+		}
+		return writeMachinesJSON(out)
+	}
+
+	if len(byTarget) == 0 {
+		fmt.Println("No machines recorded yet. Run 'preflight apply' to record this machine.")
+		return nil
+	}
+
+	targets := make([]string, 0, len(byTarget))
+	for target := range byTarget {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "TARGET\tHOSTNAME\tHARDWARE\tLAST APPLIED")
+	for _, target := range targets {
+		for _, s := range byTarget[target] {
+			hardware := "-"
+			if s.Fingerprint != nil {
+				hardware = describeFingerprint(*s.Fingerprint)
+			}
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", orNone(target), s.Hostname, hardware, formatAge(s.AppliedAt))
+		}
+	}
+	return w.Flush()
+}
+
+func runMachinesFingerprint(_ *cobra.Command, _ []string) error {
+	ctx := context.Background()
+	var fp sync.Fingerprint
+	var err error
+	if machinesRecord {
+		err = recordInitFingerprint(ctx)
+	}
+	if err == nil {
+		fp, err = machineFingerprint(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read machine fingerprint: %w", err)
+	}
+
+	var binding config.MachineBinding
+	bound := false
+	if manifest, err := config.NewLoader().LoadManifest(machinesConfigPath); err == nil {
+		binding, bound = manifest.TargetFor(fp)
+	}
+
+	if machinesJSON {
+		out := struct {
+			sync.Fingerprint
+			MemoryGB int    `json:"memory_gb"`
+			Target   string `json:"target,omitempty"`
+		}{Fingerprint: fp, MemoryGB: fp.MemoryGB()}
+		if bound {
+			out.Target = binding.Target
+		}
+		return writeMachinesJSON(out)
+	}
+
+	fmt.Printf("Hostname: %s\n", orNone(fp.Hostname))
+	fmt.Printf("Serial:   %s\n", orNone(fp.Serial))
+	fmt.Printf("Chip:     %s\n", orNone(fp.Chip))
+	fmt.Printf("Memory:   %d GB\n", fp.MemoryGB())
+	if bound {
+		fmt.Printf("Target:   %s (bound by %s)\n", binding.Target, binding)
+	} else {
+		fmt.Println("Target:   none bound; apply uses --target (default \"default\")")
+	}
+	return nil
+}
+
+// describeFingerprint summarizes a machine's hardware on one line.
+func describeFingerprint(fp sync.Fingerprint) string {
+	var parts []string
+	if fp.Chip != "" {
+		parts = append(parts, fp.Chip)
+	}
+	if fp.MemoryBytes > 0 {
+		parts = append(parts, fmt.Sprintf("%d GB", fp.MemoryGB()))
+	}
+	if fp.Serial != "" {
+		parts = append(parts, "serial "+fp.Serial)
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func writeMachinesJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// machineStatusLabel describes how a machine relates to the lockfile.
+func machineStatusLabel(s sync.MachineStatus) string {
+	switch s.Relation {
+	case sync.Before:
+		return "behind"
+	case sync.After:
+		return "ahead (lockfile not synced)"
+	case sync.Concurrent:
+		return "diverged"
+	case sync.Equal:
+		if len(s.Divergent) > 0 {
+			return fmt.Sprintf("drifted (%d packages)", len(s.Divergent))
+		}
+	}
+	return "up to date"
+}
+
+func shortMachineID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
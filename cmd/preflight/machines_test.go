@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	lockadapter "github.com/felixgeelhaar/preflight/internal/adapters/lockfile"
+	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/domain/lock"
+	"github.com/felixgeelhaar/preflight/internal/domain/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupMachineInventory writes a lockfile bumped by the desktop and state
+// files for an up-to-date desktop and a laptop that is behind.
+func setupMachineInventory(t *testing.T) string {
+	t.Helper()
+	configPath := filepath.Join(t.TempDir(), "preflight.yaml")
+	laptop, _ := sync.ParseMachineID("550e8400-e29b-41d4-a716-446655440000")
+	desktop, _ := sync.ParseMachineID("660e8400-e29b-41d4-a716-446655440000")
+
+	lockfile := lock.NewLockfileV2(config.ModeLocked, lock.MachineInfoFromSystem())
+	pkg, err := lock.NewPackageLock("brew", "ripgrep", "14.1.0",
+		lock.IntegrityFromData(lock.AlgorithmSHA256, []byte("rg")), time.Now())
+	require.NoError(t, err)
+	require.NoError(t, lockfile.SetPackage(pkg))
+	lockfile.RecordChange(laptop, "laptop")
+	oldVector := lockfile.SyncMetadata().Vector()
+	lockfile.RecordChange(desktop, "desktop")
+	require.NoError(t, lockadapter.NewYAMLRepository().Save(context.Background(),
+		filepath.Join(filepath.Dir(configPath), "preflight.lock"), lockfile))
+
+	dir := app.MachineInventoryDir(configPath)
+	require.NoError(t, sync.SaveMachineState(dir, sync.MachineState{
+		MachineID: laptop.String(),
+		Hostname:  "laptop",
+		Target:    "work",
+		AppliedAt: time.Now().Add(-48 * time.Hour),
+		Vector:    oldVector,
+		Packages:  map[string]string{"brew:ripgrep": "14.0.0"},
+	}))
+	require.NoError(t, sync.SaveMachineState(dir, sync.MachineState{
+		MachineID: desktop.String(),
+		Hostname:  "desktop",
+		AppliedAt: time.Now(),
+		Vector:    lockfile.SyncMetadata().Vector(),
+		Packages:  map[string]string{"brew:ripgrep": "14.1.0"},
+	}))
+	return configPath
+}
+
+func setMachinesFlags(t *testing.T, configPath string, asJSON bool) {
+	t.Helper()
+	machinesConfigPath = configPath
+	machinesJSON = asJSON
+	t.Cleanup(func() {
+		machinesConfigPath = "preflight.yaml"
+		machinesJSON = false
+	})
+}
+
+func TestRunMachinesList(t *testing.T) {
+	setMachinesFlags(t, setupMachineInventory(t), false)
+
+	out := capturePluginStdout(t, func() {
+		require.NoError(t, runMachinesList(machinesListCmd, nil))
+	})
+	assert.Contains(t, out, "HOSTNAME")
+	assert.Contains(t, out, "desktop")
+	assert.Contains(t, out, "up to date")
+	assert.Contains(t, out, "550e8400")
+	assert.Contains(t, out, "2 days ago")
+	assert.Contains(t, out, "behind")
+}
+
+func TestRunMachinesList_Empty(t *testing.T) {
+	setMachinesFlags(t, filepath.Join(t.TempDir(), "preflight.yaml"), false)
+
+	out := capturePluginStdout(t, func() {
+		require.NoError(t, runMachinesList(machinesListCmd, nil))
+	})
+	assert.Contains(t, out, "No machines recorded yet")
+}
+
+func TestRunMachinesStatus_SingleMachine(t *testing.T) {
+	setMachinesFlags(t, setupMachineInventory(t), false)
+
+	out := capturePluginStdout(t, func() {
+		require.NoError(t, runMachinesStatus(machinesStatusCmd, []string{"laptop"}))
+	})
+	assert.Contains(t, out, "laptop (550e8400)")
+	assert.Contains(t, out, "Status:       behind")
+	assert.Contains(t, out, "brew:ripgrep: applied 14.0.0, locked 14.1.0")
+	assert.Contains(t, out, "1 of 1 machine(s) behind")
+	assert.NotContains(t, out, "desktop")
+}
+
+func TestRunMachinesStatus_JSON(t *testing.T) {
+	setMachinesFlags(t, setupMachineInventory(t), true)
+
+	out := capturePluginStdout(t, func() {
+		require.NoError(t, runMachinesStatus(machinesStatusCmd, nil))
+	})
+	var got []machineStatusJSON
+	require.NoError(t, json.Unmarshal([]byte(out), &got))
+	require.Len(t, got, 2)
+	assert.Equal(t, "desktop", got[0].Hostname)
+	assert.False(t, got[0].Behind)
+	assert.Equal(t, "laptop", got[1].Hostname)
+	assert.Equal(t, "before", got[1].Relation)
+	assert.True(t, got[1].Behind)
+	assert.Len(t, got[1].Divergent, 1)
+}
+
+func TestRunMachinesStatus_UnknownMachine(t *testing.T) {
+	setMachinesFlags(t, setupMachineInventory(t), false)
+
+	err := runMachinesStatus(machinesStatusCmd, []string{"server"})
+	assert.ErrorContains(t, err, `machine "server" not found`)
+}
+
+func TestRunMachinesTargets(t *testing.T) {
+	configPath := setupMachineInventory(t)
+	setMachinesFlags(t, configPath, false)
+	require.NoError(t, sync.SaveMachineState(app.MachineInventoryDir(configPath), sync.MachineState{
+		MachineID:   "770e8400-e29b-41d4-a716-446655440000",
+		Hostname:    "studio",
+		Target:      "work",
+		Fingerprint: &sync.Fingerprint{Chip: "Apple M2 Ultra", MemoryBytes: 64 << 30},
+		AppliedAt:   time.Now(),
+	}))
+
+	out := capturePluginStdout(t, func() {
+		require.NoError(t, runMachinesTargets(machinesTargetsCmd, nil))
+	})
+	assert.Contains(t, out, "TARGET")
+	assert.Regexp(t, `work\s+laptop`, out)
+	assert.Regexp(t, `work\s+studio\s+Apple M2 Ultra, 64 GB`, out)
+	assert.Regexp(t, `\(none\)\s+desktop`, out)
+}
+
+func TestRunMachinesFingerprint(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "preflight.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("targets:\n  laptop: [base]\nmachines:\n  - target: laptop\n    chip: \"Apple M*\"\n"), 0o644))
+	setMachinesFlags(t, configPath, false)
+	stubMachineFingerprint(t, sync.Fingerprint{Hostname: "air", Serial: "C02XYZ", Chip: "Apple M3", MemoryBytes: 16 << 30})
+
+	out := capturePluginStdout(t, func() {
+		require.NoError(t, runMachinesFingerprint(machinesFingerprintCmd, nil))
+	})
+	assert.Contains(t, out, "Serial:   C02XYZ")
+	assert.Contains(t, out, "Memory:   16 GB")
+	assert.Contains(t, out, "Target:   laptop (bound by chip Apple M*)")
+}
@@ -5,7 +5,9 @@ import "os"
 
 func main() {
 	if err := Execute(); err != nil {
-		printError(err)
-		os.Exit(1)
+		if !silentExit(err) {
+			printError(err)
+		}
+		os.Exit(exitCodeFor(err))
 	}
 }
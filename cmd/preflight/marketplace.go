@@ -2,13 +2,16 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"strings"
 	"text/tabwriter"
 	"time"
 
 	"github.com/felixgeelhaar/preflight/internal/domain/marketplace"
+	"github.com/felixgeelhaar/preflight/internal/offline"
 	"github.com/spf13/cobra"
 )
 
@@ -25,7 +28,9 @@ Examples:
   preflight marketplace search nvim          # Search for packages
   preflight marketplace install nvim-pro     # Install a package
   preflight marketplace list                 # List installed packages
-  preflight marketplace update               # Update all packages`,
+  preflight marketplace update               # Update all packages
+  preflight marketplace rate nvim-pro 5       # Rate a package
+  preflight marketplace publish ./my-preset  # Publish a package`,
 }
 
 // Search subcommand
@@ -52,11 +57,24 @@ var marketplaceInstallCmd = &cobra.Command{
 
 If no version is specified, the latest version is installed.
 Packages are verified using SHA256 checksums before installation.
+Packages published with a keyless Sigstore signature are verified against
+the identities in the trust store once a Sigstore trusted root has been
+imported with 'preflight trust sigstore-root', and refused when the
+signature does not verify.
+
+Dependencies declared by the package are resolved and installed first.
+When a package pulls in dependencies, the full list is shown for
+confirmation (skip with --yes).
+
+Packages can declare parameters, such as an email address or a proxy URL,
+that are substituted into their layers. Values are taken from --var,
+prompted for on a terminal, or fall back to the declared defaults.
 
 Examples:
   preflight marketplace install nvim-pro
   preflight marketplace install nvim-pro@1.2.0
-  preflight marketplace install nvim-pro --version 1.2.0`,
+  preflight marketplace install nvim-pro --version 1.2.0
+  preflight marketplace install acme-base --var email=jane@acme.com`,
 	Args: cobra.RangeArgs(1, 2),
 	RunE: runMarketplaceInstall,
 }
@@ -201,6 +219,7 @@ var (
 	mpFeaturedType    string
 	mpScanSeverity    string
 	mpInstallSkipScan bool
+	mpInstallVars     []string
 )
 
 func init() {
@@ -210,6 +229,7 @@ func init() {
 
 	// Install flags
 	marketplaceInstallCmd.Flags().StringVar(&mpInstallVer, "version", "", "Version to install")
+	marketplaceInstallCmd.Flags().StringArrayVar(&mpInstallVars, "var", nil, "Package parameter as name=value (repeatable)")
 
 	// Global marketplace flags
 	marketplaceCmd.PersistentFlags().BoolVar(&mpOfflineMode, "offline", false, "Use cached data only")
@@ -253,18 +273,44 @@ func init() {
 
 func newMarketplaceService() *marketplace.Service {
 	config := marketplace.DefaultServiceConfig()
-	config.OfflineMode = mpOfflineMode
-	return marketplace.NewService(config)
+	config.OfflineMode = marketplaceOffline()
+	config.PreflightVersion = version
+	svc := marketplace.NewService(config)
+	// Verify keyless package signatures once a Sigstore trusted root was imported.
+	if verifier, err := getKeylessVerifier(); err == nil {
+		svc.WithKeylessVerifier(verifier)
+	}
+	return svc
+}
+
+// marketplaceOffline reports whether marketplace commands run offline,
+// with their own --offline flag or in offline mode.
+func marketplaceOffline() bool {
+	return mpOfflineMode || offline.Enabled()
+}
+
+// refreshMarketplaceIndex refreshes the package index for --refresh. In
+// offline mode the cached index is used instead.
+func refreshMarketplaceIndex(ctx context.Context, svc *marketplace.Service) error {
+	if !mpRefreshIndex {
+		return nil
+	}
+	if marketplaceOffline() {
+		fmt.Println("Index refresh skipped (offline); using the cached index.")
+		return nil
+	}
+	if err := svc.RefreshIndex(ctx); err != nil {
+		return fmt.Errorf("failed to refresh index: %w", err)
+	}
+	return nil
 }
 
 func runMarketplaceSearch(_ *cobra.Command, args []string) error {
 	ctx := context.Background()
 	svc := newMarketplaceService()
 
-	if mpRefreshIndex {
-		if err := svc.RefreshIndex(ctx); err != nil {
-			return fmt.Errorf("failed to refresh index: %w", err)
-		}
+	if err := refreshMarketplaceIndex(ctx, svc); err != nil {
+		return err
 	}
 
 	query := ""
@@ -302,6 +348,9 @@ func runMarketplaceSearch(_ *cobra.Command, args []string) error {
 		return nil
 	}
 
+	// Rank by user rating before limiting
+	marketplace.SortByRating(results)
+
 	// Limit results
 	if mpSearchLimit > 0 && len(results) > mpSearchLimit {
 		results = results[:mpSearchLimit]
@@ -309,7 +358,7 @@ func runMarketplaceSearch(_ *cobra.Command, args []string) error {
 
 	// Display results
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	_, _ = fmt.Fprintln(w, "NAME\tTYPE\tTITLE\tVERSION\tDOWNLOADS")
+	_, _ = fmt.Fprintln(w, "NAME\tTYPE\tTITLE\tVERSION\tRATING\tDOWNLOADS")
 
 	for _, pkg := range results {
 		version := ""
@@ -327,8 +376,8 @@ func runMarketplaceSearch(_ *cobra.Command, args []string) error {
 			title = title[:27] + "..."
 		}
 
-		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\n",
-			pkg.ID.String(), pkgType, title, version, pkg.Downloads)
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\n",
+			pkg.ID.String(), pkgType, title, version, pkg.Rating, pkg.Downloads)
 	}
 
 	_ = w.Flush()
@@ -369,23 +418,101 @@ func runMarketplaceInstall(_ *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("invalid package name: %w", err)
 	}
+	vars, err := parseVarFlags(mpInstallVars)
+	if err != nil {
+		return err
+	}
 
-	fmt.Printf("Installing %s@%s...\n", pkgName, version)
-
-	installed, err := svc.Install(ctx, id, version)
+	plan, err := svc.ResolveInstall(ctx, id, version)
 	if err != nil {
 		return fmt.Errorf("installation failed: %w", err)
 	}
 
-	fmt.Printf("Installed %s@%s to %s\n", installed.Package.Title, installed.Version, installed.Path)
+	changes := plan.Changes()
+	if len(changes) == 0 {
+		return fmt.Errorf("installation failed: %w: %s@%s", marketplace.ErrAlreadyInstalled, pkgName, version)
+	}
+
+	if plan.HasDependencies() && !confirmInstallPlan(plan) {
+		fmt.Println("Installation cancelled.")
+		return nil
+	}
+
+	for _, planned := range changes {
+		fmt.Printf("Installing %s@%s...\n", planned.Package.ID, planned.Version.Version)
+	}
+
+	installed, err := svc.InstallPlan(ctx, plan)
+	for _, inst := range installed {
+		fmt.Printf("Installed %s@%s to %s\n", inst.Package.Title, inst.Version, inst.Path)
+		if inst.Package.Provenance.Verified {
+			fmt.Println("  Package is verified.")
+			if signedBy := inst.Package.Provenance.SignedBy; signedBy != "" {
+				fmt.Printf("  Signed by %s\n", signedBy)
+			}
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("installation failed: %w", err)
+	}
 
-	if installed.Package.Provenance.Verified {
-		fmt.Println("  Package is verified.")
+	prompt := terminalParameterPrompt()
+	for _, inst := range installed {
+		if err := applyInstalledParameters(inst, vars, prompt); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+// applyInstalledParameters fills in the parameters of an installed package.
+// Packages without a package.yaml have none.
+func applyInstalledParameters(inst marketplace.InstalledPackage, vars map[string]string, prompt func(marketplace.Parameter) (string, error)) error {
+	spec, err := marketplace.LoadPackageSpec(inst.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read parameters of %s: %w", inst.Package.ID, err)
+	}
+	changed, err := fillPackageParameters(inst.Path, spec.Parameters, vars, nil, prompt)
+	if err != nil {
+		return fmt.Errorf("failed to fill in parameters of %s: %w", inst.Package.ID, err)
+	}
+	if len(changed) > 0 {
+		fmt.Printf("  Filled in parameters in %d layer(s).\n", len(changed))
+	}
+	return nil
+}
+
+// confirmInstallPlan lists every package a plan will change and asks once for all of them.
+func confirmInstallPlan(plan *marketplace.InstallPlan) bool {
+	fmt.Printf("Installing %s requires the following changes:\n", plan.Root)
+	for _, planned := range plan.Changes() {
+		line := fmt.Sprintf("  %s %s@%s", planned.Action, planned.Package.ID, planned.Version.Version)
+		if planned.Action == marketplace.ActionUpgrade {
+			line += fmt.Sprintf(" (from %s)", planned.InstalledVersion)
+		}
+		if len(planned.RequiredBy) > 0 {
+			line += fmt.Sprintf(" (required by %s)", strings.Join(planned.RequiredBy, ", "))
+		}
+		fmt.Println(line)
+	}
+
+	if yesFlag {
+		return true
+	}
+
+	fmt.Print("Proceed? [y/N]: ")
+	var response string
+	if _, err := fmt.Scanln(&response); err != nil {
+		return false
+	}
+	response = strings.ToLower(strings.TrimSpace(response))
+	return response == "y" || response == "yes"
+}
+
 func runMarketplaceUninstall(_ *cobra.Command, args []string) error {
 	svc := newMarketplaceService()
 
@@ -416,6 +543,10 @@ func runMarketplaceUpdate(_ *cobra.Command, args []string) error {
 		if err != nil {
 			return fmt.Errorf("invalid package name: %w", err)
 		}
+		if marketplaceOffline() {
+			fmt.Printf("Update of %s skipped (offline).\n", pkgName)
+			return nil
+		}
 
 		fmt.Printf("Updating %s...\n", pkgName)
 
@@ -429,6 +560,10 @@ func runMarketplaceUpdate(_ *cobra.Command, args []string) error {
 	}
 
 	// Update all packages
+	if marketplaceOffline() {
+		fmt.Println("Updates skipped (offline).")
+		return nil
+	}
 	fmt.Println("Checking for updates...")
 
 	updated, err := svc.UpdateAll(ctx)
@@ -566,6 +701,10 @@ func runMarketplaceInfo(_ *cobra.Command, args []string) error {
 	fmt.Println()
 	fmt.Printf("Downloads:   %d\n", pkg.Downloads)
 	fmt.Printf("Stars:       %d\n", pkg.Stars)
+	fmt.Printf("Rating:      %s\n", pkg.Rating)
+	if own, found, err := svc.LocalRating(id); err == nil && found {
+		fmt.Printf("Your rating: %d/%d\n", own.Score, marketplace.MaxRatingScore)
+	}
 
 	return nil
 }
@@ -592,10 +731,8 @@ func runMarketplaceRecommend(_ *cobra.Command, _ []string) error {
 	ctx := context.Background()
 	svc := newMarketplaceService()
 
-	if mpRefreshIndex {
-		if err := svc.RefreshIndex(ctx); err != nil {
-			return fmt.Errorf("failed to refresh index: %w", err)
-		}
+	if err := refreshMarketplaceIndex(ctx, svc); err != nil {
+		return err
 	}
 
 	// Handle similar package mode
@@ -739,10 +876,8 @@ func runMarketplaceFeatured(_ *cobra.Command, _ []string) error {
 	ctx := context.Background()
 	svc := newMarketplaceService()
 
-	if mpRefreshIndex {
-		if err := svc.RefreshIndex(ctx); err != nil {
-			return fmt.Errorf("failed to refresh index: %w", err)
-		}
+	if err := refreshMarketplaceIndex(ctx, svc); err != nil {
+		return err
 	}
 
 	config := marketplace.DefaultRecommenderConfig()
@@ -773,7 +908,7 @@ func runMarketplaceFeatured(_ *cobra.Command, _ []string) error {
 	fmt.Println("Featured Packages:")
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	_, _ = fmt.Fprintln(w, "NAME\tTYPE\tTITLE\tAUTHOR\tSTARS")
+	_, _ = fmt.Fprintln(w, "NAME\tTYPE\tTITLE\tAUTHOR\tRATING")
 
 	for _, rec := range recommendations {
 		pkgType := rec.Package.Type
@@ -786,9 +921,9 @@ func runMarketplaceFeatured(_ *cobra.Command, _ []string) error {
 			title = title[:22] + "..."
 		}
 
-		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\n",
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
 			rec.Package.ID.String(), pkgType, title,
-			rec.Package.Provenance.Author, rec.Package.Stars)
+			rec.Package.Provenance.Author, rec.Package.Rating)
 	}
 
 	_ = w.Flush()
@@ -799,10 +934,8 @@ func runMarketplacePopular(_ *cobra.Command, _ []string) error {
 	ctx := context.Background()
 	svc := newMarketplaceService()
 
-	if mpRefreshIndex {
-		if err := svc.RefreshIndex(ctx); err != nil {
-			return fmt.Errorf("failed to refresh index: %w", err)
-		}
+	if err := refreshMarketplaceIndex(ctx, svc); err != nil {
+		return err
 	}
 
 	config := marketplace.DefaultRecommenderConfig()
@@ -822,7 +955,7 @@ func runMarketplacePopular(_ *cobra.Command, _ []string) error {
 	fmt.Println("Most Popular Packages:")
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	_, _ = fmt.Fprintln(w, "RANK\tNAME\tTYPE\tDOWNLOADS\tSTARS")
+	_, _ = fmt.Fprintln(w, "RANK\tNAME\tTYPE\tDOWNLOADS\tRATING")
 
 	for i, rec := range recommendations {
 		pkgType := rec.Package.Type
@@ -830,9 +963,9 @@ func runMarketplacePopular(_ *cobra.Command, _ []string) error {
 			pkgType = pkgType[:12]
 		}
 
-		_, _ = fmt.Fprintf(w, "#%d\t%s\t%s\t%d\t%d\n",
+		_, _ = fmt.Fprintf(w, "#%d\t%s\t%s\t%d\t%s\n",
 			i+1, rec.Package.ID.String(), pkgType,
-			rec.Package.Downloads, rec.Package.Stars)
+			rec.Package.Downloads, rec.Package.Rating)
 	}
 
 	_ = w.Flush()
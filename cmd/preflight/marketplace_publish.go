@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/adapters/command"
+	"github.com/felixgeelhaar/preflight/internal/domain/catalog"
+	"github.com/felixgeelhaar/preflight/internal/domain/marketplace"
+	"github.com/felixgeelhaar/preflight/internal/offline"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/spf13/cobra"
+)
+
+// Publish subcommand
+var marketplacePublishCmd = &cobra.Command{
+	Use:   "publish <dir>",
+	Short: "Publish a package to a registry",
+	Long: `Validate, package, sign, and publish a preset, capability pack,
+layer template, or plugin to a marketplace registry.
+
+The package directory must contain a package.yaml describing the package.
+Release notes are taken from the matching "## <version>" section of
+CHANGELOG.md when present.
+
+The archive is signed with an ED25519 key that must already be in your
+trust store (see 'preflight trust add'). Registries can be an HTTP
+registry URL or a local checkout of a git-hosted registry.
+
+Examples:
+  preflight marketplace publish ./my-preset
+  preflight marketplace publish ./my-preset --bump minor
+  preflight marketplace publish ./my-preset --registry ~/src/registry
+  preflight marketplace publish ./my-preset --dry-run`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMarketplacePublish,
+}
+
+var (
+	mpPublishRegistry string
+	mpPublishBump     string
+	mpPublishKey      string
+	mpPublishKeyID    string
+	mpPublishToken    string
+	mpPublishNoSign   bool
+	mpPublishNoPush   bool
+	mpPublishDryRun   bool
+)
+
+func init() {
+	marketplacePublishCmd.Flags().StringVar(&mpPublishRegistry, "registry", marketplace.DefaultRegistryURL, "Registry URL or path to a git registry checkout")
+	marketplacePublishCmd.Flags().StringVar(&mpPublishBump, "bump", "", "Bump version before publishing (major, minor, patch)")
+	marketplacePublishCmd.Flags().StringVar(&mpPublishKey, "key", "~/.ssh/id_ed25519", "ED25519 private key used for signing")
+	marketplacePublishCmd.Flags().StringVar(&mpPublishKeyID, "key-id", "", "Trust store key ID (default: the entry with the key's fingerprint)")
+	marketplacePublishCmd.Flags().StringVar(&mpPublishToken, "token", "", "Registry auth token (default $PREFLIGHT_REGISTRY_TOKEN)")
+	marketplacePublishCmd.Flags().BoolVar(&mpPublishNoSign, "no-sign", false, "Publish without a signature")
+	marketplacePublishCmd.Flags().BoolVar(&mpPublishNoPush, "no-push", false, "Commit to a git registry without pushing")
+	marketplacePublishCmd.Flags().BoolVar(&mpPublishDryRun, "dry-run", false, "Validate and package without publishing")
+
+	marketplaceCmd.AddCommand(marketplacePublishCmd)
+}
+
+func runMarketplacePublish(_ *cobra.Command, args []string) error {
+	ctx := context.Background()
+	dir := args[0]
+
+	opts := marketplace.PublishOptions{
+		Bump:   mpPublishBump,
+		DryRun: mpPublishDryRun,
+	}
+
+	if !mpPublishNoSign {
+		key, keyID, err := resolvePublishSigningKey(mpPublishKey, mpPublishKeyID)
+		if err != nil {
+			return err
+		}
+		opts.SigningKey = key
+		opts.KeyID = keyID
+	}
+
+	release, err := marketplace.PrepareRelease(dir, opts)
+	if err != nil {
+		return fmt.Errorf("failed to prepare release: %w", err)
+	}
+
+	version := release.Version()
+	fmt.Printf("Package:   %s@%s (%s)\n", release.Package.ID, version.Version, release.Package.Type)
+	fmt.Printf("Checksum:  sha256:%s\n", version.Checksum)
+	fmt.Printf("Size:      %d bytes\n", len(release.Archive))
+	if version.IsSigned() {
+		fmt.Printf("Signed by: %s\n", version.KeyID)
+	} else {
+		fmt.Println("Signed by: (unsigned)")
+	}
+	if version.Changelog != "" {
+		fmt.Printf("\nChangelog:\n%s\n", version.Changelog)
+	}
+
+	if mpPublishDryRun {
+		fmt.Println("\nDry run: nothing was published.")
+		return nil
+	}
+
+	registry := newPublishRegistry(mpPublishRegistry)
+	if err := registry.Publish(ctx, release); err != nil {
+		return fmt.Errorf("failed to publish to %s: %w", registry.Location(), err)
+	}
+
+	fmt.Printf("\nPublished %s@%s to %s\n", release.Package.ID, version.Version, registry.Location())
+	return nil
+}
+
+// newPublishRegistry returns an HTTP registry for URLs and a git registry for paths.
+func newPublishRegistry(location string) marketplace.Registry {
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		config := marketplace.DefaultClientConfig()
+		config.RegistryURL = strings.TrimSuffix(location, "/")
+		config.AuthToken = mpPublishToken
+		if config.AuthToken == "" {
+			config.AuthToken = os.Getenv("PREFLIGHT_REGISTRY_TOKEN")
+		}
+		return marketplace.NewHTTPRegistry(marketplace.NewClient(config))
+	}
+
+	push := !mpPublishNoPush
+	if push && offline.Enabled() {
+		fmt.Println("Push skipped (offline); the release is committed to the registry only.")
+		push = false
+	}
+	return marketplace.NewGitRegistry(ports.ExpandPath(location), command.NewRealRunner()).WithPush(push)
+}
+
+// resolvePublishSigningKey loads the signing key and finds its trust store entry.
+// The public half (<key>.pub) must have been added with 'preflight trust add'.
+// Entries are matched on the fingerprint of the public key only, never on a
+// key ID, which is short enough to collide; keyID only chooses among entries
+// with that fingerprint.
+func resolvePublishSigningKey(keyPath, keyID string) (ed25519.PrivateKey, string, error) {
+	keyPath = ports.ExpandPath(keyPath)
+
+	key, err := marketplace.LoadSigningKey(keyPath)
+	if err != nil {
+		return nil, "", err
+	}
+
+	pubData, err := os.ReadFile(keyPath + ".pub")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read public key %s.pub: %w", keyPath, err)
+	}
+	fingerprint := catalog.ComputeKeyFingerprint(pubData)
+
+	store, err := getTrustStore()
+	if err != nil {
+		return nil, "", err
+	}
+
+	var matches []string
+	for _, trusted := range store.List() {
+		if keyID != "" && trusted.KeyID() != keyID {
+			continue
+		}
+		if trusted.Fingerprint() == fingerprint {
+			matches = append(matches, trusted.KeyID())
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, "", fmt.Errorf("signing key %s is not in the trust store; add it with 'preflight trust add %s.pub'",
+			filepath.Base(keyPath), keyPath)
+	case 1:
+		return key, matches[0], nil
+	default:
+		return nil, "", fmt.Errorf("signing key %s matches several trust store entries (%s); choose one with --key-id",
+			filepath.Base(keyPath), strings.Join(matches, ", "))
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/catalog"
+	"github.com/felixgeelhaar/preflight/internal/domain/marketplace"
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestMarketplacePublishCmd_Structure(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "publish <dir>", marketplacePublishCmd.Use)
+
+	for _, name := range []string{"registry", "bump", "key", "key-id", "token", "no-sign", "no-push", "dry-run"} {
+		assert.NotNil(t, marketplacePublishCmd.Flags().Lookup(name), "missing flag %s", name)
+	}
+	assert.Equal(t, marketplace.DefaultRegistryURL, marketplacePublishCmd.Flags().Lookup("registry").DefValue)
+}
+
+func TestNewPublishRegistry(t *testing.T) {
+	t.Parallel()
+
+	_, isHTTP := newPublishRegistry("https://registry.example.com/").(*marketplace.HTTPRegistry)
+	assert.True(t, isHTTP)
+
+	_, isGit := newPublishRegistry(t.TempDir()).(*marketplace.GitRegistry)
+	assert.True(t, isGit)
+}
+
+func TestRunMarketplacePublish_DryRunUnsigned(t *testing.T) {
+	dir := t.TempDir()
+	spec := "id: my-preset\ntype: preset\ntitle: My Preset\nversion: 0.1.0\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, marketplace.PackageSpecFile), []byte(spec), 0o644))
+
+	mpPublishNoSign = true
+	mpPublishDryRun = true
+	mpPublishBump = "minor"
+	defer func() {
+		mpPublishNoSign = false
+		mpPublishDryRun = false
+		mpPublishBump = ""
+	}()
+
+	output := captureStdout(t, func() {
+		require.NoError(t, runMarketplacePublish(nil, []string{dir}))
+	})
+	assert.Contains(t, output, "my-preset@0.2.0")
+	assert.Contains(t, output, "Dry run")
+}
+
+func TestRunMarketplacePublish_InvalidSpec(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, marketplace.PackageSpecFile), []byte("id: Bad_Name\n"), 0o644))
+
+	mpPublishNoSign = true
+	defer func() { mpPublishNoSign = false }()
+
+	err := runMarketplacePublish(nil, []string{dir})
+	require.ErrorIs(t, err, marketplace.ErrInvalidSpec)
+}
+
+func TestResolvePublishSigningKey_MissingKey(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := resolvePublishSigningKey(filepath.Join(t.TempDir(), "id_ed25519"), "")
+	require.Error(t, err)
+}
+
+func TestResolvePublishSigningKey_MatchesFingerprintOnly(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv(platform.HomeEnvVar, home)
+
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	block, err := ssh.MarshalPrivateKey(key, "")
+	require.NoError(t, err)
+	sshPub, err := ssh.NewPublicKey(pub)
+	require.NoError(t, err)
+	pubData := ssh.MarshalAuthorizedKey(sshPub)
+	keyPath := filepath.Join(t.TempDir(), "publisher")
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(block), 0o600))
+	require.NoError(t, os.WriteFile(keyPath+".pub", pubData, 0o644))
+	fingerprint := catalog.ComputeKeyFingerprint(pubData)
+
+	addTrusted := func(keyID, fp string) {
+		store, err := getTrustStore()
+		require.NoError(t, err)
+		trusted := catalog.NewTrustedKey(keyID, catalog.SignatureTypeSSH, nil,
+			catalog.NewPublisher("Publisher", "publisher@example.com", keyID, catalog.SignatureTypeSSH))
+		trusted.SetFingerprint(fp)
+		require.NoError(t, store.Add(trusted))
+		require.NoError(t, store.Save())
+	}
+
+	// An entry whose key ID is the fingerprint does not select the key.
+	addTrusted(fingerprint, "SHA256:other")
+	_, _, err = resolvePublishSigningKey(keyPath, "")
+	require.ErrorContains(t, err, "is not in the trust store")
+
+	addTrusted("publisher", fingerprint)
+	_, keyID, err := resolvePublishSigningKey(keyPath, "")
+	require.NoError(t, err)
+	assert.Equal(t, "publisher", keyID)
+
+	// Entries sharing the fingerprint need --key-id.
+	addTrusted("publisher-ci", fingerprint)
+	_, _, err = resolvePublishSigningKey(keyPath, "")
+	require.ErrorContains(t, err, "choose one with --key-id")
+	_, keyID, err = resolvePublishSigningKey(keyPath, "publisher-ci")
+	require.NoError(t, err)
+	assert.Equal(t, "publisher-ci", keyID)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/marketplace"
+	"github.com/spf13/cobra"
+)
+
+// Rate subcommand
+var marketplaceRateCmd = &cobra.Command{
+	Use:   "rate <package> <1-5>",
+	Short: "Rate a package",
+	Long: `Rate a marketplace package from 1 (poor) to 5 (excellent).
+
+Ratings are stored locally and shown in 'preflight marketplace info'.
+Nothing is sent to the registry unless --submit is given, in which case
+the score is added to the package's public rating.
+
+Examples:
+  preflight marketplace rate nvim-pro 5
+  preflight marketplace rate nvim-pro 4 --submit`,
+	Args: cobra.ExactArgs(2),
+	RunE: runMarketplaceRate,
+}
+
+var (
+	mpRateSubmit bool
+	mpRateToken  string
+)
+
+func init() {
+	marketplaceRateCmd.Flags().BoolVar(&mpRateSubmit, "submit", false, "Also submit the rating to the registry")
+	marketplaceRateCmd.Flags().StringVar(&mpRateToken, "token", "", "Registry auth token (default $PREFLIGHT_REGISTRY_TOKEN)")
+
+	marketplaceCmd.AddCommand(marketplaceRateCmd)
+}
+
+func runMarketplaceRate(_ *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	id, err := marketplace.NewPackageID(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid package name: %w", err)
+	}
+
+	score, err := strconv.Atoi(args[1])
+	if err != nil {
+		return fmt.Errorf("%w: %q", marketplace.ErrInvalidRating, args[1])
+	}
+	if err := marketplace.ValidateRatingScore(score); err != nil {
+		return err
+	}
+
+	config := marketplace.DefaultServiceConfig()
+	config.OfflineMode = marketplaceOffline()
+	config.PreflightVersion = version
+	config.ClientConfig.AuthToken = mpRateToken
+	if config.ClientConfig.AuthToken == "" {
+		config.ClientConfig.AuthToken = os.Getenv("PREFLIGHT_REGISTRY_TOKEN")
+	}
+	svc := marketplace.NewService(config)
+
+	rating, err := svc.Rate(ctx, id, score, mpRateSubmit)
+	if err != nil {
+		if rating != nil {
+			fmt.Printf("Saved rating %d/%d for %s locally.\n", rating.Score, marketplace.MaxRatingScore, id)
+			return fmt.Errorf("failed to submit rating: %w", err)
+		}
+		return fmt.Errorf("failed to rate package: %w", err)
+	}
+
+	fmt.Printf("Rated %s %d/%d.\n", id, rating.Score, marketplace.MaxRatingScore)
+	if rating.Submitted {
+		fmt.Println("  Rating submitted to the registry.")
+	}
+	return nil
+}
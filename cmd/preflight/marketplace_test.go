@@ -1,10 +1,13 @@
 package main
 
 import (
+	"context"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/felixgeelhaar/preflight/internal/domain/marketplace"
+	"github.com/felixgeelhaar/preflight/internal/offline"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -335,6 +338,18 @@ func TestRunMarketplaceFeatured_WithRefreshFlag(t *testing.T) {
 		"unexpected error: %v", err)
 }
 
+func TestRefreshMarketplaceIndex_Offline(t *testing.T) {
+	t.Setenv(offline.EnvVar, "1")
+	oldRefresh := mpRefreshIndex
+	mpRefreshIndex = true
+	defer func() { mpRefreshIndex = oldRefresh }()
+
+	output := captureStdout(t, func() {
+		require.NoError(t, refreshMarketplaceIndex(context.Background(), newMarketplaceService()))
+	})
+	assert.Contains(t, output, "Index refresh skipped (offline); using the cached index.")
+}
+
 // ---------------------------------------------------------------------------
 // runMarketplacePopular tests
 // ---------------------------------------------------------------------------
@@ -400,3 +415,48 @@ func TestMarketplaceInfoCmd_RequiresExactlyOneArg(t *testing.T) {
 	err = marketplaceInfoCmd.Args(marketplaceInfoCmd, []string{"pkg"})
 	assert.NoError(t, err)
 }
+
+func TestConfirmInstallPlan_YesFlag(t *testing.T) {
+	prevYes := yesFlag
+	yesFlag = true
+	defer func() { yesFlag = prevYes }()
+
+	plan := &marketplace.InstallPlan{
+		Root: marketplace.MustNewPackageID("app"),
+		Packages: []marketplace.PlannedPackage{
+			{
+				Package:          marketplace.Package{ID: marketplace.MustNewPackageID("base")},
+				Version:          marketplace.PackageVersion{Version: "2.0.0"},
+				Action:           marketplace.ActionUpgrade,
+				InstalledVersion: "1.0.0",
+				RequiredBy:       []string{"app"},
+			},
+			{
+				Package: marketplace.Package{ID: marketplace.MustNewPackageID("app")},
+				Version: marketplace.PackageVersion{Version: "1.0.0"},
+				Action:  marketplace.ActionInstall,
+			},
+		},
+	}
+
+	var confirmed bool
+	output := captureStdout(t, func() {
+		confirmed = confirmInstallPlan(plan)
+	})
+	assert.True(t, confirmed)
+	assert.Contains(t, output, "upgrade base@2.0.0 (from 1.0.0) (required by app)")
+	assert.Contains(t, output, "install app@1.0.0")
+}
+
+func TestRunMarketplaceRate_InvalidScore(t *testing.T) {
+	for _, score := range []string{"0", "6", "five"} {
+		err := runMarketplaceRate(marketplaceRateCmd, []string{"nvim-pro", score})
+		require.ErrorIs(t, err, marketplace.ErrInvalidRating, score)
+	}
+}
+
+func TestRunMarketplaceRate_InvalidPackageName(t *testing.T) {
+	err := runMarketplaceRate(marketplaceRateCmd, []string{"INVALID", "5"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid package name")
+}
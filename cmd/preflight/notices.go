@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/felixgeelhaar/preflight/internal/notices"
+	"github.com/felixgeelhaar/preflight/internal/offline"
+	"github.com/spf13/cobra"
+)
+
+// releasesURL is where users download new releases.
+const releasesURL = "https://github.com/felixgeelhaar/preflight/releases"
+
+// quietCommands never show notices: their output is read by shells,
+// editors or other programs, or they run unattended.
+var quietCommands = map[string]struct{}{
+	cobra.ShellCompRequestCmd:       {},
+	cobra.ShellCompNoDescRequestCmd: {},
+	"completion":                    {},
+	"version":                       {},
+	"mcp":                           {},
+	"agent":                         {},
+	"hook":                          {},
+	"env":                           {},
+	"exec":                          {},
+	"help":                          {},
+}
+
+// latestRelease looks up the latest preflight release; tests replace it.
+var latestRelease = func(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	return notices.LatestRelease(ctx, http.DefaultClient, notices.ReleaseURL)
+}
+
+func init() {
+	rootCmd.PersistentPostRun = func(cmd *cobra.Command, _ []string) {
+		if wantsNotices(cmd) {
+			printNotices(cmd.Context(), os.Stderr, preflightConfigDir(), time.Now())
+		}
+	}
+}
+
+// wantsNotices reports whether cmd may end with notices: never in CI, in
+// JSON output or when stdout is not a terminal.
+func wantsNotices(cmd *cobra.Command) bool {
+	if os.Getenv("CI") != "" || !stdoutIsTerminal() {
+		return false
+	}
+	for c := cmd; c != nil; c = c.Parent() {
+		if isIn(c.Name(), quietCommands) {
+			return false
+		}
+	}
+	for _, name := range []string{"json", "format", "output"} {
+		flag := cmd.Flags().Lookup(name)
+		if flag != nil && (flag.Value.String() == "true" || flag.Value.String() == "json") {
+			return false
+		}
+	}
+	return true
+}
+
+// printNotices prints a newer release and the deprecated keys of the
+// configuration to w, when the user opted in and no notice was shown in the
+// last day. The latest release is looked up at most once a day, and not
+// at all in offline mode.
+func printNotices(ctx context.Context, w io.Writer, dir string, now time.Time) {
+	if dir == "" {
+		return
+	}
+	state, err := notices.LoadState(dir)
+	if err != nil || !state.Due(now) {
+		return
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if state.CheckDue(now) && !offline.Enabled() {
+		if latest, err := latestRelease(ctx); err == nil {
+			state.LatestVersion = latest
+		}
+		// A failed lookup waits for the next day too
+		state.CheckedAt = now
+	}
+
+	var lines []string
+	if notices.Newer(version, state.LatestVersion) {
+		lines = append(lines, fmt.Sprintf("preflight %s is available (you have %s): %s", state.LatestVersion, version, releasesURL))
+	}
+	configPath := cfgFile
+	if configPath == "" {
+		configPath = "preflight.yaml"
+	}
+	for _, deprecation := range app.ConfigDeprecations(configPath) {
+		lines = append(lines, "Deprecated: "+deprecation)
+	}
+
+	if len(lines) > 0 {
+		_, _ = fmt.Fprintln(w)
+		for _, line := range lines {
+			_, _ = fmt.Fprintf(w, "Notice: %s\n", line)
+		}
+		state.LastShown = now
+	}
+	_ = notices.SaveState(dir, state)
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/notices"
+	"github.com/felixgeelhaar/preflight/internal/offline"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubLatestRelease replaces the release lookup and counts its calls.
+func stubLatestRelease(t *testing.T, latest string) *int {
+	t.Helper()
+	calls := 0
+	orig := latestRelease
+	latestRelease = func(context.Context) (string, error) {
+		calls++
+		return latest, nil
+	}
+	t.Cleanup(func() { latestRelease = orig })
+	return &calls
+}
+
+func setVersion(t *testing.T, v string) {
+	t.Helper()
+	orig := version
+	version = v
+	t.Cleanup(func() { version = orig })
+}
+
+func setCfgFile(t *testing.T, path string) {
+	t.Helper()
+	orig := cfgFile
+	cfgFile = path
+	t.Cleanup(func() { cfgFile = orig })
+}
+
+func TestPrintNotices_DisabledByDefault(t *testing.T) {
+	calls := stubLatestRelease(t, "9.0.0")
+	setVersion(t, "1.0.0")
+	dir := t.TempDir()
+
+	var out bytes.Buffer
+	printNotices(context.Background(), &out, dir, time.Now())
+	assert.Empty(t, out.String())
+	assert.Zero(t, *calls, "nothing is looked up without the opt-in")
+}
+
+func TestPrintNotices_OncePerDay(t *testing.T) {
+	t.Setenv(offline.EnvVar, "")
+	calls := stubLatestRelease(t, "1.2.0")
+	setVersion(t, "1.0.0")
+
+	configDir := t.TempDir()
+	configPath := filepath.Join(configDir, "preflight.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("targets:\n  default: [base]\n"), 0o644))
+	require.NoError(t, os.Mkdir(filepath.Join(configDir, "layers"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "layers", "base.yaml"), []byte("name: base\nnvim:\n  ensure_install: true\n"), 0o644))
+	setCfgFile(t, configPath)
+
+	dir := t.TempDir()
+	require.NoError(t, notices.SaveState(dir, notices.State{Enabled: true}))
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+
+	var out bytes.Buffer
+	printNotices(context.Background(), &out, dir, now)
+	assert.Contains(t, out.String(), "Notice: preflight 1.2.0 is available (you have 1.0.0)")
+	assert.Contains(t, out.String(), "Notice: Deprecated: ")
+	assert.Contains(t, out.String(), "nvim.ensure_install")
+	assert.Equal(t, 1, *calls)
+
+	out.Reset()
+	printNotices(context.Background(), &out, dir, now.Add(12*time.Hour))
+	assert.Empty(t, out.String(), "notices are shown at most once a day")
+
+	printNotices(context.Background(), &out, dir, now.Add(25*time.Hour))
+	assert.Contains(t, out.String(), "preflight 1.2.0 is available")
+	assert.Equal(t, 2, *calls)
+}
+
+func TestPrintNotices_Offline(t *testing.T) {
+	t.Setenv(offline.EnvVar, "1")
+	calls := stubLatestRelease(t, "1.2.0")
+	setVersion(t, "1.0.0")
+	setCfgFile(t, filepath.Join(t.TempDir(), "preflight.yaml"))
+
+	dir := t.TempDir()
+	require.NoError(t, notices.SaveState(dir, notices.State{Enabled: true, LatestVersion: "1.1.0"}))
+
+	var out bytes.Buffer
+	printNotices(context.Background(), &out, dir, time.Now())
+	assert.Zero(t, *calls)
+	assert.Contains(t, out.String(), "preflight 1.1.0 is available", "the last known release is still reported")
+}
+
+func TestWantsNotices_QuietOutput(t *testing.T) {
+	t.Setenv("CI", "")
+
+	assert.False(t, wantsNotices(versionCmd))
+
+	cmd := &cobra.Command{Use: "list"}
+	cmd.Flags().Bool("json", false, "")
+	require.NoError(t, cmd.Flags().Set("json", "true"))
+	assert.False(t, wantsNotices(cmd))
+
+	t.Setenv("CI", "true")
+	assert.False(t, wantsNotices(&cobra.Command{Use: "plan"}))
+}
+
+func TestRunVersion_Notify(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("PREFLIGHT_HOME", home)
+	origNotify := versionNotify
+	t.Cleanup(func() {
+		versionNotify = origNotify
+		_ = versionCmd.Flags().Set("notify", "false")
+		versionCmd.Flags().Lookup("notify").Changed = false
+	})
+
+	var out bytes.Buffer
+	versionCmd.SetOut(&out)
+	t.Cleanup(func() { versionCmd.SetOut(nil) })
+
+	require.NoError(t, versionCmd.Flags().Set("notify", "true"))
+	require.NoError(t, runVersion(versionCmd, nil))
+	assert.Contains(t, out.String(), "Notices are on.")
+	state, err := notices.LoadState(home)
+	require.NoError(t, err)
+	assert.True(t, state.Enabled)
+
+	require.NoError(t, versionCmd.Flags().Set("notify", "false"))
+	require.NoError(t, runVersion(versionCmd, nil))
+	state, err = notices.LoadState(home)
+	require.NoError(t, err)
+	assert.False(t, state.Enabled)
+}
+
+func TestRunVersion_Check(t *testing.T) {
+	t.Setenv(offline.EnvVar, "")
+	stubLatestRelease(t, "2.0.0")
+	setVersion(t, "1.0.0")
+	origCheck := versionCheck
+	versionCheck = true
+	t.Cleanup(func() { versionCheck = origCheck })
+
+	var out bytes.Buffer
+	versionCmd.SetOut(&out)
+	t.Cleanup(func() { versionCmd.SetOut(nil) })
+
+	require.NoError(t, runVersion(versionCmd, nil))
+	assert.Contains(t, out.String(), "preflight 2.0.0 is available")
+
+	t.Setenv(offline.EnvVar, "1")
+	out.Reset()
+	require.NoError(t, runVersion(versionCmd, nil))
+	assert.Contains(t, out.String(), "Release check skipped (offline).")
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// notifyUser shows a desktop notification. It is a no-op on platforms
+// without a supported notifier; tests replace it.
+var notifyUser = func(title, message string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		script := "display notification " + appleScriptQuote(message) + " with title " + appleScriptQuote(title)
+		return exec.Command("osascript", "-e", script).Run()
+	case "linux":
+		if _, err := exec.LookPath("notify-send"); err != nil {
+			return nil
+		}
+		return exec.Command("notify-send", title, message).Run()
+	default:
+		return nil
+	}
+}
+
+// appleScriptQuote returns s as an AppleScript string literal.
+func appleScriptQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,507 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/domain/execution"
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
+	"github.com/felixgeelhaar/preflight/internal/telemetry"
+	"github.com/spf13/cobra"
+)
+
+var onboardCmd = &cobra.Command{
+	Use:   "onboard",
+	Short: "Set up a new machine step by step",
+	Long: `Onboard walks a new machine from a cloned configuration to a fully
+applied target.
+
+The wizard:
+1. Detects the platform
+2. Asks which target (profile) this machine should use
+3. Checks secret references and offers to sign in to 1Password or
+   Bitwarden, or to store missing keychain entries
+4. Confirms bootstrap steps such as installing Homebrew
+5. Applies the plan, streaming progress per step
+6. Walks through the manual onboarding steps declared by the layers
+   (onboarding.steps), remembering which ones are done
+7. Prints a summary and next steps
+
+Use --yes to accept the defaults without prompting, and --checklist to
+revisit only the onboarding checklist.
+
+Examples:
+  preflight onboard                      # Interactive setup
+  preflight onboard --target work        # Skip the target prompt
+  preflight onboard --checklist          # Tick off remaining manual steps
+  preflight onboard -c ~/dotfiles/preflight.yaml --yes`,
+	RunE: runOnboard,
+}
+
+var (
+	onboardConfigPath string
+	onboardTarget     string
+	onboardChecklist  bool
+)
+
+// onboardIn is where the wizard reads answers from; tests replace it.
+var onboardIn io.Reader = os.Stdin
+
+// newOnboardClient creates the client used by onboard. The observer streams
+// step results while the plan is applied.
+var newOnboardClient = func(out io.Writer, observer execution.StepObserver) preflightClient {
+	return &preflightAdapter{app.New(out).WithStepObserver(observer)}
+}
+
+// onboardSignInCommands are the interactive sign-in commands of secret
+// backends that need a session before secrets can be read.
+var onboardSignInCommands = map[string][]string{
+	"1password": {"op", "signin"},
+	"bitwarden": {"bw", "login"},
+}
+
+// Injection points for tests.
+var (
+	onboardResolveSecret = resolveSecret
+	onboardStoreSecret   = setKeychainSecret
+	onboardLookPath      = exec.LookPath
+	newOnboardingService = app.DefaultOnboardingService
+	onboardRunCommand    = func(name string, args ...string) error {
+		cmd := exec.Command(name, args...)
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		return cmd.Run()
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(onboardCmd)
+
+	onboardCmd.Flags().StringVarP(&onboardConfigPath, "config", "c", "preflight.yaml", "Path to preflight.yaml")
+	onboardCmd.Flags().StringVarP(&onboardTarget, "target", "t", "", "Target to apply (prompted when empty)")
+	onboardCmd.Flags().BoolVar(&onboardChecklist, "checklist", false, "Only show the onboarding checklist")
+}
+
+// onboardPrompter asks questions on the terminal. With yes set every
+// question takes its default answer.
+type onboardPrompter struct {
+	in  *bufio.Reader
+	yes bool
+}
+
+func (p *onboardPrompter) ask(question, def string) string {
+	if p.yes {
+		return def
+	}
+	if def != "" {
+		fmt.Printf("%s [%s]: ", question, def)
+	} else {
+		fmt.Printf("%s: ", question)
+	}
+	line, err := p.in.ReadString('\n')
+	line = strings.TrimSpace(line)
+	if line == "" || (err != nil && !errors.Is(err, io.EOF)) {
+		return def
+	}
+	return line
+}
+
+func (p *onboardPrompter) confirm(question string, def bool) bool {
+	if p.yes {
+		return def
+	}
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	switch strings.ToLower(p.ask(fmt.Sprintf("%s [%s]", question, hint), "")) {
+	case "y", "yes":
+		return true
+	case "n", "no":
+		return false
+	default:
+		return def
+	}
+}
+
+// onboardSummary collects what the wizard did for the final report.
+type onboardSummary struct {
+	Platform          string
+	Target            string
+	SecretsResolved   int
+	SecretsUnresolved []SecretRef
+	Applied           int
+	Failed            int
+	UpToDate          bool
+	Checklist         bool
+	ChecklistTotal    int
+	ChecklistDone     int
+}
+
+func runOnboard(_ *cobra.Command, _ []string) error {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	prompter := &onboardPrompter{in: bufio.NewReader(onboardIn), yes: yesFlag}
+	summary := &onboardSummary{}
+
+	if onboardChecklist {
+		targets, err := onboardTargets(onboardConfigPath)
+		if err != nil {
+			return err
+		}
+		target, err := chooseOnboardTarget(prompter, targets, onboardTarget)
+		if err != nil {
+			return err
+		}
+		return runOnboardChecklist(prompter, onboardConfigPath, target, summary)
+	}
+
+	fmt.Println("Welcome to preflight! Let's set up this machine.")
+
+	fmt.Println("\n[1/6] Detecting platform")
+	plat, err := platform.Detect()
+	if err != nil {
+		return fmt.Errorf("failed to detect platform: %w", err)
+	}
+	summary.Platform = plat.String()
+	fmt.Printf("  %s\n", summary.Platform)
+
+	fmt.Println("\n[2/6] Choosing a target")
+	targets, err := onboardTargets(onboardConfigPath)
+	if err != nil {
+		return err
+	}
+	target, err := chooseOnboardTarget(prompter, targets, onboardTarget)
+	if err != nil {
+		return err
+	}
+	summary.Target = target
+	fmt.Printf("  Using target %q\n", target)
+
+	fmt.Println("\n[3/6] Checking secrets")
+	refs, err := onboardSecretRefs(onboardConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to find secrets: %w", err)
+	}
+	resolveOnboardSecrets(prompter, refs, summary)
+
+	fmt.Println("\n[4/6] Planning")
+	client := newOnboardClient(os.Stdout, printOnboardProgress).WithRollbackOnFailure(true)
+	plan, err := client.Plan(ctx, onboardConfigPath, target)
+	if err != nil {
+		return &config.UserError{
+			Code:       config.ErrCodeValidationFailed,
+			Message:    "could not generate plan from your configuration",
+			Suggestion: "Run 'preflight validate' to check your config for issues.",
+			Underlying: err,
+		}
+	}
+
+	pending := len(plan.NeedsApply())
+	if plan.HasChanges() {
+		fmt.Printf("  %d change(s) to apply\n", pending)
+
+		if app.RequiresBootstrapConfirmation(plan) && !confirmBootstrap(app.BootstrapSteps(plan)) {
+			return &config.UserError{
+				Code:       config.ErrCodeBootstrapDeclined,
+				Message:    "bootstrap steps declined; nothing was applied",
+				Suggestion: "Re-run 'preflight onboard' and confirm the bootstrap prompt, or pass --allow-bootstrap to skip the prompt.",
+			}
+		}
+		if !prompter.confirm(fmt.Sprintf("Apply %d change(s) to this machine?", pending), true) {
+			fmt.Println("Onboarding stopped before applying. Run 'preflight onboard' again when ready.")
+			return nil
+		}
+
+		fmt.Println("\n[5/6] Applying")
+		results, err := client.Apply(ctx, plan, false)
+		failedIDs := failedStepIDs(results)
+		for _, r := range results {
+			if r.Applied() {
+				summary.Applied++
+			}
+		}
+		summary.Failed = len(failedIDs)
+		if err != nil || len(failedIDs) > 0 {
+			printOnboardSummary(summary)
+			return newApplyFailedUserError("onboard", failedIDs, err)
+		}
+		recordOnce(telemetry.EventApplyFirstOK)
+	} else {
+		summary.UpToDate = true
+		fmt.Println("  Everything is already up to date.")
+		fmt.Println("\n[5/6] Applying")
+		fmt.Println("  Nothing to apply.")
+	}
+	recordOnboardState(ctx, client, target, plan)
+
+	fmt.Println("\n[6/6] Onboarding checklist")
+	return runOnboardChecklist(prompter, onboardConfigPath, target, summary)
+}
+
+// runOnboardChecklist walks through the manual onboarding steps of target's
+// layers, asks which open ones are done and prints the summary.
+func runOnboardChecklist(p *onboardPrompter, configPath, target string, summary *onboardSummary) error {
+	summary.Checklist = true
+	svc, err := newOnboardingService()
+	if err != nil {
+		return err
+	}
+	items, err := svc.Checklist(configPath, target)
+	if err != nil {
+		return fmt.Errorf("failed to load onboarding checklist: %w", err)
+	}
+
+	summary.ChecklistTotal = len(items)
+	if len(items) == 0 {
+		fmt.Println("  No onboarding steps declared.")
+	}
+	for _, item := range items {
+		if item.Completed() {
+			summary.ChecklistDone++
+			fmt.Printf("  ✓ %s\n", item.Title)
+			continue
+		}
+		fmt.Printf("  ○ %s (%s)\n", item.Title, item.Layer)
+		if item.Description != "" {
+			fmt.Printf("    %s\n", item.Description)
+		}
+		if item.URL != "" {
+			fmt.Printf("    %s\n", item.URL)
+		}
+		if !p.confirm("    Done?", false) {
+			continue
+		}
+		if err := svc.SetCompleted(item.Key(), true); err != nil {
+			return fmt.Errorf("failed to save onboarding progress: %w", err)
+		}
+		summary.ChecklistDone++
+	}
+
+	printOnboardSummary(summary)
+	return nil
+}
+
+// onboardTargets returns the sorted target names of the manifest.
+func onboardTargets(configPath string) ([]string, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, &config.UserError{
+				Code:       config.ErrCodeConfigNotFound,
+				Message:    fmt.Sprintf("no configuration found at %s", configPath),
+				Suggestion: "Clone your config with 'preflight repo clone <url>' or create one with 'preflight init', then pass it with --config.",
+			}
+		}
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	manifest, err := config.ParseManifest(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+
+	targets := make([]string, 0, len(manifest.Targets))
+	for name := range manifest.Targets {
+		targets = append(targets, name)
+	}
+	sort.Strings(targets)
+	return targets, nil
+}
+
+// chooseOnboardTarget picks the target from the flag, the only target, or
+// a prompt that defaults to "default" when the manifest defines it.
+func chooseOnboardTarget(p *onboardPrompter, targets []string, flag string) (string, error) {
+	if flag != "" {
+		for _, t := range targets {
+			if t == flag {
+				return flag, nil
+			}
+		}
+		return "", fmt.Errorf("target %q not found (available: %s)", flag, strings.Join(targets, ", "))
+	}
+	if len(targets) == 1 {
+		return targets[0], nil
+	}
+
+	def := targets[0]
+	for _, t := range targets {
+		if t == "default" {
+			def = t
+		}
+	}
+	if !p.yes {
+		for i, t := range targets {
+			fmt.Printf("  %d) %s\n", i+1, t)
+		}
+	}
+
+	for {
+		answer := p.ask("Which target should this machine use?", def)
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(targets) {
+			return targets[n-1], nil
+		}
+		for _, t := range targets {
+			if t == answer {
+				return t, nil
+			}
+		}
+		fmt.Printf("  Unknown target %q\n", answer)
+	}
+}
+
+// onboardSecretRefs finds secret references in the manifest and its layers.
+func onboardSecretRefs(configPath string) ([]SecretRef, error) {
+	refs, err := findSecretRefs(configPath)
+	if err != nil {
+		return nil, err
+	}
+	layers, _ := filepath.Glob(filepath.Join(filepath.Dir(configPath), "layers", "*.yaml"))
+	sort.Strings(layers)
+	for _, layer := range layers {
+		layerRefs, err := findSecretRefs(layer)
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, layerRefs...)
+	}
+	return refs, nil
+}
+
+// resolveOnboardSecrets checks every secret reference. When a backend
+// cannot resolve its secrets the user may sign in once and retry; missing
+// keychain entries can be stored directly.
+func resolveOnboardSecrets(p *onboardPrompter, refs []SecretRef, summary *onboardSummary) {
+	if len(refs) == 0 {
+		fmt.Println("  No secret references.")
+		return
+	}
+
+	unresolved := make(map[string][]SecretRef)
+	var backends []string
+	for _, ref := range refs {
+		if v, err := onboardResolveSecret(ref.Backend, ref.Key); err == nil && v != "" {
+			summary.SecretsResolved++
+			continue
+		}
+		if _, ok := unresolved[ref.Backend]; !ok {
+			backends = append(backends, ref.Backend)
+		}
+		unresolved[ref.Backend] = append(unresolved[ref.Backend], ref)
+	}
+
+	for _, backend := range backends {
+		missing := unresolved[backend]
+		if signIn, ok := onboardSignInCommands[backend]; ok {
+			if _, err := onboardLookPath(signIn[0]); err != nil {
+				fmt.Printf("  ✗ %d %s secret(s) need the %s CLI, which is not installed\n", len(missing), backend, signIn[0])
+			} else if p.confirm(fmt.Sprintf("  %d %s secret(s) are not accessible. Sign in now?", len(missing), backend), true) {
+				if err := onboardRunCommand(signIn[0], signIn[1:]...); err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: %s sign-in failed: %v\n", backend, err)
+				}
+				missing = retryOnboardSecrets(missing, summary)
+			}
+		} else if backend == "keychain" && !p.yes {
+			var still []SecretRef
+			for _, ref := range missing {
+				value := p.ask(fmt.Sprintf("  Value for keychain secret %q (empty to skip)", ref.Key), "")
+				if value == "" {
+					still = append(still, ref)
+					continue
+				}
+				if err := onboardStoreSecret(ref.Key, value); err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: could not store %s in the keychain: %v\n", ref.Key, err)
+					still = append(still, ref)
+					continue
+				}
+				summary.SecretsResolved++
+			}
+			missing = still
+		}
+		summary.SecretsUnresolved = append(summary.SecretsUnresolved, missing...)
+	}
+
+	fmt.Printf("  %d of %d secret(s) accessible\n", summary.SecretsResolved, len(refs))
+	for _, ref := range summary.SecretsUnresolved {
+		fmt.Printf("  ✗ %s: %s (%s)\n", ref.Path, ref.Key, ref.Backend)
+	}
+}
+
+func retryOnboardSecrets(refs []SecretRef, summary *onboardSummary) []SecretRef {
+	var still []SecretRef
+	for _, ref := range refs {
+		if v, err := onboardResolveSecret(ref.Backend, ref.Key); err == nil && v != "" {
+			summary.SecretsResolved++
+			continue
+		}
+		still = append(still, ref)
+	}
+	return still
+}
+
+// printOnboardProgress prints one line per step that did work as soon as
+// the step finishes. Already-satisfied steps stay quiet.
+func printOnboardProgress(r execution.StepResult) {
+	switch {
+	case r.Error() != nil:
+		fmt.Printf("  ✗ %s: %v\n", r.StepID(), r.Error())
+	case r.Status() == compiler.StatusSkipped:
+		fmt.Printf("  - %s (skipped)\n", r.StepID())
+	case r.Applied():
+		fmt.Printf("  ✓ %s (%s)\n", r.StepID(), r.Duration().Round(time.Millisecond))
+	}
+}
+
+func recordOnboardState(ctx context.Context, client preflightClient, target string, plan *execution.Plan) {
+	if err := client.RecordMachineState(ctx, onboardConfigPath, target, plan); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not record machine state: %v\n", err)
+	}
+}
+
+func printOnboardSummary(s *onboardSummary) {
+	fmt.Println("\nSummary")
+	if s.Platform != "" {
+		fmt.Printf("  Platform:  %s\n", s.Platform)
+		fmt.Printf("  Target:    %s\n", s.Target)
+		if s.UpToDate {
+			fmt.Println("  Changes:   none needed")
+		} else {
+			fmt.Printf("  Changes:   %d applied, %d failed\n", s.Applied, s.Failed)
+		}
+		fmt.Printf("  Secrets:   %d accessible, %d missing\n", s.SecretsResolved, len(s.SecretsUnresolved))
+	}
+	if s.Checklist {
+		fmt.Printf("  Checklist: %d/%d done\n", s.ChecklistDone, s.ChecklistTotal)
+	}
+
+	fmt.Println("\nNext steps")
+	if s.Failed > 0 {
+		fmt.Println("  preflight doctor --verbose    # diagnose the failed steps")
+	} else {
+		fmt.Println("  preflight doctor              # verify the machine matches the config")
+	}
+	if len(s.SecretsUnresolved) > 0 {
+		fmt.Println("  preflight secrets check       # re-check secrets after signing in")
+	}
+	if s.ChecklistDone < s.ChecklistTotal {
+		fmt.Println("  preflight onboard --checklist # finish the remaining onboarding steps")
+	}
+	fmt.Println("  preflight machines status     # compare with your other machines")
+	fmt.Println("  preflight tour                # learn the day-to-day workflow")
+}
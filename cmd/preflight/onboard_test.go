@@ -0,0 +1,247 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/domain/execution"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPrompter(input string, yes bool) *onboardPrompter {
+	return &onboardPrompter{in: bufio.NewReader(strings.NewReader(input)), yes: yes}
+}
+
+func TestChooseOnboardTarget(t *testing.T) {
+	targets := []string{"default", "personal", "work"}
+
+	tests := []struct {
+		name    string
+		targets []string
+		flag    string
+		input   string
+		yes     bool
+		want    string
+		wantErr bool
+	}{
+		{name: "flag", targets: targets, flag: "work", want: "work"},
+		{name: "unknown flag", targets: targets, flag: "home", wantErr: true},
+		{name: "single target", targets: []string{"laptop"}, want: "laptop"},
+		{name: "yes takes default", targets: targets, yes: true, want: "default"},
+		{name: "empty answer takes default", targets: targets, input: "\n", want: "default"},
+		{name: "by number", targets: targets, input: "2\n", want: "personal"},
+		{name: "by name", targets: targets, input: "work\n", want: "work"},
+		{name: "asks again", targets: targets, input: "home\n3\n", want: "work"},
+		{name: "first target without default", targets: []string{"a", "b"}, yes: true, want: "a"},
+	}
+
+	captureStdout(t, func() {
+		for _, tt := range tests {
+			got, err := chooseOnboardTarget(newTestPrompter(tt.input, tt.yes), tt.targets, tt.flag)
+			if tt.wantErr {
+				assert.Error(t, err, tt.name)
+				continue
+			}
+			assert.NoError(t, err, tt.name)
+			assert.Equal(t, tt.want, got, tt.name)
+		}
+	})
+}
+
+func stubOnboardSecrets(t *testing.T, resolvable map[string]bool) *[]string {
+	t.Helper()
+	prevResolve, prevStore, prevLook, prevRun := onboardResolveSecret, onboardStoreSecret, onboardLookPath, onboardRunCommand
+	t.Cleanup(func() {
+		onboardResolveSecret, onboardStoreSecret, onboardLookPath, onboardRunCommand = prevResolve, prevStore, prevLook, prevRun
+	})
+
+	var calls []string
+	onboardResolveSecret = func(backend, key string) (string, error) {
+		if resolvable[backend+"/"+key] {
+			return "value", nil
+		}
+		return "", errors.New("not found")
+	}
+	onboardStoreSecret = func(name, _ string) error {
+		calls = append(calls, "store "+name)
+		resolvable["keychain/"+name] = true
+		return nil
+	}
+	onboardLookPath = func(name string) (string, error) { return "/usr/bin/" + name, nil }
+	onboardRunCommand = func(name string, args ...string) error {
+		calls = append(calls, name+" "+strings.Join(args, " "))
+		resolvable["1password/Dev/GitHub/token"] = true
+		return nil
+	}
+	return &calls
+}
+
+func TestResolveOnboardSecrets(t *testing.T) {
+	calls := stubOnboardSecrets(t, map[string]bool{"env/HOME": true})
+	refs := []SecretRef{
+		{Path: "env", Backend: "env", Key: "HOME"},
+		{Path: "token", Backend: "1password", Key: "Dev/GitHub/token"},
+		{Path: "passphrase", Backend: "keychain", Key: "ssh-work"},
+		{Path: "other", Backend: "keychain", Key: "skipped"},
+	}
+
+	summary := &onboardSummary{}
+	out := captureStdout(t, func() {
+		resolveOnboardSecrets(newTestPrompter("y\nhunter2\n\n", false), refs, summary)
+	})
+
+	assert.Equal(t, []string{"op signin", "store ssh-work"}, *calls)
+	assert.Equal(t, 3, summary.SecretsResolved)
+	require.Len(t, summary.SecretsUnresolved, 1)
+	assert.Equal(t, "skipped", summary.SecretsUnresolved[0].Key)
+	assert.Contains(t, out, "3 of 4 secret(s) accessible")
+}
+
+func TestResolveOnboardSecrets_YesNeverPrompts(t *testing.T) {
+	calls := stubOnboardSecrets(t, map[string]bool{})
+	refs := []SecretRef{{Path: "passphrase", Backend: "keychain", Key: "ssh-work"}}
+
+	summary := &onboardSummary{}
+	captureStdout(t, func() {
+		resolveOnboardSecrets(newTestPrompter("", true), refs, summary)
+	})
+
+	assert.Empty(t, *calls)
+	assert.Len(t, summary.SecretsUnresolved, 1)
+}
+
+func TestOnboardSecretRefs_IncludesLayers(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "preflight.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("targets:\n  default: [base]\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "layers"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "layers", "base.yaml"),
+		[]byte("git:\n  signing_key: \"secret://1password/Dev/GitHub/key\"\n"), 0o644))
+
+	refs, err := onboardSecretRefs(configPath)
+	require.NoError(t, err)
+	require.Len(t, refs, 1)
+	assert.Equal(t, "1password", refs[0].Backend)
+	assert.Equal(t, "Dev/GitHub/key", refs[0].Key)
+}
+
+func writeOnboardConfig(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "preflight.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("targets:\n  default: [base]\n  work: [base, team]\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "layers"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "layers", "base.yaml"), []byte("name: base\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "layers", "team.yaml"), []byte(
+		"name: team\nonboarding:\n  steps:\n    - title: Request VPN access\n      url: https://it.example.com/vpn\n"), 0o644))
+	return configPath
+}
+
+func stubOnboardingService(t *testing.T) *app.OnboardingService {
+	t.Helper()
+	svc := app.NewOnboardingService(t.TempDir())
+	prev := newOnboardingService
+	t.Cleanup(func() { newOnboardingService = prev })
+	newOnboardingService = func() (*app.OnboardingService, error) { return svc, nil }
+	return svc
+}
+
+func TestRunOnboard(t *testing.T) {
+	configPath := writeOnboardConfig(t)
+	svc := stubOnboardingService(t)
+
+	step := newDummyStep("files:link:bashrc")
+	plan := execution.NewExecutionPlan()
+	plan.Add(execution.NewPlanEntry(step, compiler.StatusNeedsApply, compiler.NewDiff(compiler.DiffTypeAdd, "files", "link", "", "")))
+	fake := newFakePreflightClient(plan, []execution.StepResult{
+		execution.NewStepResult(step.ID(), compiler.StatusSatisfied, nil).WithApplied(true),
+	})
+
+	prevClient, prevIn, prevConfig, prevTarget := newOnboardClient, onboardIn, onboardConfigPath, onboardTarget
+	t.Cleanup(func() {
+		newOnboardClient, onboardIn, onboardConfigPath, onboardTarget = prevClient, prevIn, prevConfig, prevTarget
+	})
+	newOnboardClient = func(_ io.Writer, _ execution.StepObserver) preflightClient { return fake }
+	onboardIn = strings.NewReader("work\n\ny\n")
+	onboardConfigPath = configPath
+	onboardTarget = ""
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runOnboard(nil, nil))
+	})
+
+	assert.True(t, fake.applyCalled)
+	assert.True(t, fake.recordMachineCalled)
+	assert.Contains(t, out, `Using target "work"`)
+	assert.Contains(t, out, "1 applied, 0 failed")
+	assert.Contains(t, out, "https://it.example.com/vpn")
+	assert.Contains(t, out, "Checklist: 1/1 done")
+	assert.Contains(t, out, "Next steps")
+
+	items, err := svc.Checklist(configPath, "work")
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.True(t, items[0].Completed(), "completion is persisted")
+}
+
+func TestRunOnboard_ChecklistOnly(t *testing.T) {
+	configPath := writeOnboardConfig(t)
+	stubOnboardingService(t)
+
+	prevClient, prevIn, prevConfig, prevTarget, prevChecklist := newOnboardClient, onboardIn, onboardConfigPath, onboardTarget, onboardChecklist
+	t.Cleanup(func() {
+		newOnboardClient, onboardIn, onboardConfigPath, onboardTarget, onboardChecklist = prevClient, prevIn, prevConfig, prevTarget, prevChecklist
+	})
+	newOnboardClient = func(_ io.Writer, _ execution.StepObserver) preflightClient {
+		t.Fatal("checklist mode must not plan or apply")
+		return nil
+	}
+	onboardIn = strings.NewReader("n\n")
+	onboardConfigPath = configPath
+	onboardTarget = "work"
+	onboardChecklist = true
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runOnboard(nil, nil))
+	})
+
+	assert.NotContains(t, out, "Detecting platform")
+	assert.Contains(t, out, "○ Request VPN access (team)")
+	assert.Contains(t, out, "Checklist: 0/1 done")
+	assert.Contains(t, out, "onboard --checklist")
+}
+
+func TestRunOnboard_MissingConfig(t *testing.T) {
+	prevConfig := onboardConfigPath
+	t.Cleanup(func() { onboardConfigPath = prevConfig })
+	onboardConfigPath = filepath.Join(t.TempDir(), "preflight.yaml")
+
+	var err error
+	captureStdout(t, func() {
+		err = runOnboard(nil, nil)
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no configuration found")
+}
+
+func TestPrintOnboardProgress(t *testing.T) {
+	stepID, _ := compiler.NewStepID("brew:install:jq")
+	out := captureStdout(t, func() {
+		printOnboardProgress(execution.NewStepResult(stepID, compiler.StatusSatisfied, nil))
+		printOnboardProgress(execution.NewStepResult(stepID, compiler.StatusSatisfied, nil).WithApplied(true))
+		printOnboardProgress(execution.NewStepResult(stepID, compiler.StatusFailed, errors.New("boom")))
+	})
+
+	assert.Equal(t, 2, strings.Count(out, "brew:install:jq"), "satisfied steps stay quiet")
+	assert.Contains(t, out, "✗ brew:install:jq: boom")
+}
@@ -5,10 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
+	"sort"
 	"strings"
 	"text/tabwriter"
 
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
 	"github.com/felixgeelhaar/preflight/internal/domain/security"
+	"github.com/felixgeelhaar/preflight/internal/offline"
 	"github.com/spf13/cobra"
 )
 
@@ -20,13 +24,23 @@ var outdatedCmd = &cobra.Command{
 This command checks installed packages for available updates and reports
 them with their update type (major, minor, patch).
 
-Checkers (in order of preference):
-  - brew: Homebrew formulae and casks
+Checkers:
+  - brew:   Homebrew formulae and casks
+  - npm:    global npm packages
+  - pip:    packages installed with pip install --user
+  - pipx:   pipx applications, against PyPI
+  - vscode: VS Code extensions, against the Marketplace
+  - go:     tools installed with go install, against the module proxy
+  - nvim:   lazy.nvim plugins, against the branch each one tracks
+
+All available checkers run unless --provider selects some. Upgrades apply
+to Homebrew packages only. Packages held with 'preflight pin' or brew pin
+are not reported or upgraded.
 
 Exit codes:
   0 - No outdated packages found (or below threshold)
   1 - Outdated packages found above threshold
-  2 - Checker not available or check failed
+  3 - Checker not available or check failed
 
 Examples:
   preflight outdated                       # Check for outdated packages
@@ -34,6 +48,7 @@ Examples:
   preflight outdated --json                # JSON output for CI
   preflight outdated --fail-on major       # Fail only on major updates
   preflight outdated --ignore go           # Ignore specific packages
+  preflight outdated --provider npm,go     # Only check npm and Go tools
 
   # Upgrade outdated packages
   preflight outdated --upgrade             # Upgrade all (minor/patch only)
@@ -52,6 +67,7 @@ var (
 	outdatedUpgrade    bool
 	outdatedMajor      bool
 	outdatedDryRun     bool
+	outdatedProviders  []string
 )
 
 func init() {
@@ -62,6 +78,7 @@ func init() {
 	outdatedCmd.Flags().StringSliceVar(&outdatedIgnore, "ignore", nil, "Package names to ignore (can be specified multiple times)")
 	outdatedCmd.Flags().BoolVar(&outdatedJSON, "json", false, "Output results as JSON")
 	outdatedCmd.Flags().BoolVarP(&outdatedQuiet, "quiet", "q", false, "Only show summary")
+	outdatedCmd.Flags().StringSliceVar(&outdatedProviders, "provider", nil, "Only check these providers (brew, cask, npm, pip, pipx, vscode, go, nvim)")
 
 	// Upgrade flags
 	outdatedCmd.Flags().BoolVar(&outdatedUpgrade, "upgrade", false, "Upgrade outdated packages")
@@ -72,27 +89,40 @@ func init() {
 func runOutdated(_ *cobra.Command, args []string) error {
 	ctx := context.Background()
 
-	// Create checker registry
-	registry := security.NewOutdatedCheckerRegistry()
-	checker := security.NewBrewOutdatedChecker()
-	registry.Register(checker)
-
-	// Get first available checker
-	checkers := registry.All()
-	if len(checkers) == 0 {
+	// Every checker asks a package registry for the latest versions
+	if offline.Enabled() {
 		if outdatedJSON {
-			outputOutdatedJSON(nil, fmt.Errorf("no checkers available"))
+			outputOutdatedJSON(nil, offline.Skipped("outdated check"))
 		} else {
-			fmt.Fprintf(os.Stderr, "Error: no outdated checkers available. Install Homebrew.\n")
+			fmt.Println("Outdated check skipped (offline).")
 		}
-		os.Exit(2)
+		return nil
 	}
 
 	// Handle upgrade mode
 	if outdatedUpgrade || outdatedDryRun {
+		checker := security.NewBrewOutdatedChecker()
+		if !checker.Available() {
+			printError(config.NewProviderUnavailableError("Homebrew"))
+			os.Exit(exitInternal)
+		}
 		return runUpgrade(ctx, checker, args)
 	}
 
+	registry := security.NewDefaultOutdatedCheckerRegistry()
+	checkers, err := selectOutdatedCheckers(registry, outdatedProviders)
+	if err != nil {
+		return err
+	}
+	if len(checkers) == 0 {
+		if outdatedJSON {
+			outputOutdatedJSON(nil, fmt.Errorf("no checkers available"))
+		} else {
+			printError(config.NewProviderUnavailableError("A supported package manager"))
+		}
+		os.Exit(exitInternal)
+	}
+
 	// Parse fail-on threshold
 	failOnType := parseUpdateType(outdatedFailOn)
 
@@ -101,30 +131,37 @@ func runOutdated(_ *cobra.Command, args []string) error {
 		IncludePatch:   outdatedIncludeAll,
 		IncludePinned:  false,
 		IgnorePackages: outdatedIgnore,
+		PinnedPackages: pinnedPackages(cfgFile),
 	}
 
-	// Run checks with all available checkers
-	var allPackages security.OutdatedPackages
-	var checkerName string
+	// Run checks with all selected checkers. A failing checker, such as one
+	// whose registry is unreachable, only fails the run when all do.
+	allPackages := make(security.OutdatedPackages, 0)
+	var names []string
+	var lastErr error
 
 	for _, c := range checkers {
 		result, err := c.Check(ctx, opts)
 		if err != nil {
-			if outdatedJSON {
-				outputOutdatedJSON(nil, err)
-			} else {
-				fmt.Fprintf(os.Stderr, "Check failed (%s): %v\n", c.Name(), err)
-			}
-			os.Exit(2)
+			fmt.Fprintf(os.Stderr, "Check failed (%s): %v\n", c.Name(), err)
+			lastErr = err
+			continue
 		}
 
-		checkerName = c.Name()
-		allPackages = append(allPackages, result.Packages...)
+		names = append(names, c.Name())
+		allPackages = append(allPackages, filterOutdatedProviders(result.Packages, c.Name(), outdatedProviders)...)
+	}
+
+	if len(names) == 0 {
+		if outdatedJSON {
+			outputOutdatedJSON(nil, lastErr)
+		}
+		os.Exit(exitInternal)
 	}
 
 	// Build combined result
 	result := &security.OutdatedResult{
-		Checker:  checkerName,
+		Checker:  strings.Join(names, ", "),
 		Packages: allPackages,
 	}
 
@@ -137,16 +174,61 @@ func runOutdated(_ *cobra.Command, args []string) error {
 
 	// Determine exit code
 	if shouldFailOutdated(result, failOnType) {
-		os.Exit(1)
+		os.Exit(exitDrift)
 	}
 
 	return nil
 }
 
+// selectOutdatedCheckers returns the available checkers for providers, or
+// all available checkers when none are given. "cask" selects brew.
+func selectOutdatedCheckers(registry *security.OutdatedCheckerRegistry, providers []string) ([]security.OutdatedChecker, error) {
+	if len(providers) == 0 {
+		return registry.All(), nil
+	}
+
+	known := registry.Names()
+	selected := make(map[string]bool)
+	for _, p := range providers {
+		name := strings.ToLower(strings.TrimSpace(p))
+		if name == "cask" {
+			name = "brew"
+		}
+		if !slices.Contains(known, name) {
+			return nil, fmt.Errorf("unknown provider %q (available: %s, cask)", p, strings.Join(known, ", "))
+		}
+		selected[name] = true
+	}
+
+	var checkers []security.OutdatedChecker
+	for _, c := range registry.All() {
+		if selected[c.Name()] {
+			checkers = append(checkers, c)
+		}
+	}
+	return checkers, nil
+}
+
+// filterOutdatedProviders keeps the packages of checker that providers
+// ask for, so that --provider cask leaves out formulae.
+func filterOutdatedProviders(packages security.OutdatedPackages, checker string, providers []string) security.OutdatedPackages {
+	if len(providers) == 0 || slices.Contains(providers, checker) {
+		return packages
+	}
+	result := make(security.OutdatedPackages, 0, len(packages))
+	for _, pkg := range packages {
+		if slices.Contains(providers, pkg.Provider) {
+			result = append(result, pkg)
+		}
+	}
+	return result
+}
+
 func runUpgrade(ctx context.Context, checker *security.BrewOutdatedChecker, packages []string) error {
 	opts := security.UpgradeOptions{
-		DryRun:       outdatedDryRun,
-		IncludeMajor: outdatedMajor,
+		DryRun:         outdatedDryRun,
+		IncludeMajor:   outdatedMajor,
+		PinnedPackages: pinnedPackages(cfgFile),
 	}
 
 	if !outdatedJSON {
@@ -166,7 +248,7 @@ func runUpgrade(ctx context.Context, checker *security.BrewOutdatedChecker, pack
 		} else {
 			fmt.Fprintf(os.Stderr, "Upgrade failed: %v\n", err)
 		}
-		os.Exit(2)
+		os.Exit(exitInternal)
 	}
 
 	if outdatedJSON {
@@ -177,7 +259,7 @@ func runUpgrade(ctx context.Context, checker *security.BrewOutdatedChecker, pack
 
 	// Exit with error if any packages failed
 	if len(result.Failed) > 0 {
-		os.Exit(1)
+		os.Exit(exitInternal)
 	}
 
 	return nil
@@ -185,11 +267,12 @@ func runUpgrade(ctx context.Context, checker *security.BrewOutdatedChecker, pack
 
 func outputUpgradeJSON(result *security.UpgradeResult, err error) {
 	output := struct {
-		Upgraded []security.UpgradedPackage `json:"upgraded,omitempty"`
-		Skipped  []security.SkippedPackage  `json:"skipped,omitempty"`
-		Failed   []security.FailedPackage   `json:"failed,omitempty"`
-		DryRun   bool                       `json:"dry_run"`
-		Error    string                     `json:"error,omitempty"`
+		Upgraded   []security.UpgradedPackage   `json:"upgraded,omitempty"`
+		Skipped    []security.SkippedPackage    `json:"skipped,omitempty"`
+		Failed     []security.FailedPackage     `json:"failed,omitempty"`
+		RolledBack []security.RolledBackPackage `json:"rolled_back,omitempty"`
+		DryRun     bool                         `json:"dry_run"`
+		Error      string                       `json:"error,omitempty"`
 	}{
 		DryRun: result != nil && result.DryRun,
 	}
@@ -200,6 +283,7 @@ func outputUpgradeJSON(result *security.UpgradeResult, err error) {
 		output.Upgraded = result.Upgraded
 		output.Skipped = result.Skipped
 		output.Failed = result.Failed
+		output.RolledBack = result.RolledBack
 	}
 
 	enc := json.NewEncoder(os.Stdout)
@@ -227,6 +311,16 @@ func outputUpgradeText(result *security.UpgradeResult) {
 		fmt.Printf("  \033[91m✗\033[0m %s: %s\n", pkg.Name, pkg.Error)
 	}
 
+	// Print packages rolled back after a failed health check
+	for _, pkg := range result.RolledBack {
+		fmt.Printf("  \033[91m↺\033[0m %s: rolled back to %s - check %q failed\n", pkg.Name, pkg.FromVersion, pkg.Check)
+		for _, line := range strings.Split(pkg.Output, "\n") {
+			if line != "" {
+				fmt.Printf("      %s\n", line)
+			}
+		}
+	}
+
 	// Print summary
 	fmt.Println()
 	if result.DryRun {
@@ -241,6 +335,9 @@ func outputUpgradeText(result *security.UpgradeResult) {
 	if len(result.Failed) > 0 {
 		fmt.Printf(", %d failed", len(result.Failed))
 	}
+	if len(result.RolledBack) > 0 {
+		fmt.Printf(", %d rolled back", len(result.RolledBack))
+	}
 	fmt.Println()
 
 	// Print hint for skipped major updates
@@ -349,7 +446,7 @@ func outputOutdatedText(result *security.OutdatedResult, quiet bool) {
 		fmt.Println()
 		fmt.Println("Recommendations:")
 		fmt.Printf("  ⚠  %d packages have MAJOR updates (may include breaking changes)\n", summary.Major)
-		fmt.Println("     Review changelogs before updating: brew upgrade <package>")
+		fmt.Println("     Review changelogs before updating")
 	}
 }
 
@@ -373,12 +470,28 @@ func printUpdateTypeBar(summary security.OutdatedSummary) {
 	fmt.Println()
 }
 
+// printOutdatedTable prints packages grouped by provider, in the order the
+// providers were checked, with a blank line between groups.
 func printOutdatedTable(packages security.OutdatedPackages) {
+	grouped := slices.Clone(packages)
+	order := make(map[string]int)
+	for _, pkg := range packages {
+		if _, ok := order[pkg.Provider]; !ok {
+			order[pkg.Provider] = len(order)
+		}
+	}
+	sort.SliceStable(grouped, func(i, j int) bool {
+		return order[grouped[i].Provider] < order[grouped[j].Provider]
+	})
+
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 	_, _ = fmt.Fprintln(w, "TYPE\tPACKAGE\tCURRENT\tLATEST\tPROVIDER")
 	_, _ = fmt.Fprintln(w, "────\t───────\t───────\t──────\t────────")
 
-	for _, pkg := range packages {
+	for i, pkg := range grouped {
+		if i > 0 && pkg.Provider != grouped[i-1].Provider {
+			_, _ = fmt.Fprintln(w, "\t\t\t\t")
+		}
 		typeStr := formatUpdateType(pkg.UpdateType)
 		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
 			typeStr, pkg.Name, pkg.CurrentVersion, pkg.LatestVersion, pkg.Provider)
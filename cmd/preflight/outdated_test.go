@@ -1,10 +1,13 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/felixgeelhaar/preflight/internal/domain/security"
+	"github.com/felixgeelhaar/preflight/internal/offline"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -23,6 +26,61 @@ func TestOutdatedCommand_Flags(t *testing.T) {
 	assert.NotNil(t, flags.Lookup("ignore"))
 	assert.NotNil(t, flags.Lookup("json"))
 	assert.NotNil(t, flags.Lookup("quiet"))
+	assert.NotNil(t, flags.Lookup("provider"))
+}
+
+type fakeOutdatedChecker struct {
+	name      string
+	available bool
+	packages  security.OutdatedPackages
+}
+
+func (f *fakeOutdatedChecker) Name() string    { return f.name }
+func (f *fakeOutdatedChecker) Available() bool { return f.available }
+func (f *fakeOutdatedChecker) Check(context.Context, security.OutdatedOptions) (*security.OutdatedResult, error) {
+	return &security.OutdatedResult{Checker: f.name, Packages: f.packages}, nil
+}
+
+func TestSelectOutdatedCheckers(t *testing.T) {
+	t.Parallel()
+
+	registry := security.NewOutdatedCheckerRegistry()
+	registry.Register(&fakeOutdatedChecker{name: "brew", available: true})
+	registry.Register(&fakeOutdatedChecker{name: "npm", available: true})
+	registry.Register(&fakeOutdatedChecker{name: "go", available: false})
+
+	names := func(checkers []security.OutdatedChecker) []string {
+		var result []string
+		for _, c := range checkers {
+			result = append(result, c.Name())
+		}
+		return result
+	}
+
+	checkers, err := selectOutdatedCheckers(registry, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"brew", "npm"}, names(checkers))
+
+	checkers, err = selectOutdatedCheckers(registry, []string{"cask", "go"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"brew"}, names(checkers), "unavailable checkers are left out")
+
+	_, err = selectOutdatedCheckers(registry, []string{"cargo"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown provider "cargo"`)
+}
+
+func TestFilterOutdatedProviders(t *testing.T) {
+	t.Parallel()
+
+	packages := security.OutdatedPackages{
+		{Name: "go", Provider: "brew"},
+		{Name: "docker", Provider: "cask"},
+	}
+	assert.Equal(t, packages, filterOutdatedProviders(packages, "brew", nil))
+	assert.Equal(t, packages, filterOutdatedProviders(packages, "brew", []string{"brew"}))
+	assert.Equal(t, security.OutdatedPackages{{Name: "docker", Provider: "cask"}},
+		filterOutdatedProviders(packages, "brew", []string{"cask"}))
 }
 
 func TestParseUpdateType(t *testing.T) {
@@ -384,6 +442,25 @@ func TestPrintOutdatedTable(t *testing.T) {
 	assert.Contains(t, output, "brew")
 }
 
+func TestPrintOutdatedTable_GroupsByProvider(t *testing.T) {
+	packages := security.OutdatedPackages{
+		{Name: "go", CurrentVersion: "1.21.0", LatestVersion: "1.22.0", UpdateType: security.UpdateMinor, Provider: "brew"},
+		{Name: "eslint", CurrentVersion: "8.0.0", LatestVersion: "9.0.0", UpdateType: security.UpdateMajor, Provider: "npm"},
+		{Name: "jq", CurrentVersion: "1.6.0", LatestVersion: "1.7.0", UpdateType: security.UpdateMinor, Provider: "brew"},
+	}
+
+	output := captureStdout(t, func() {
+		printOutdatedTable(packages)
+	})
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	require.Len(t, lines, 6)
+	assert.Contains(t, lines[2], "go")
+	assert.Contains(t, lines[3], "jq")
+	assert.Empty(t, strings.TrimSpace(lines[4]))
+	assert.Contains(t, lines[5], "eslint")
+}
+
 func TestOutputUpgradeJSON_Success(t *testing.T) {
 	result := &security.UpgradeResult{
 		Upgraded: []security.UpgradedPackage{
@@ -520,3 +597,21 @@ func TestOutputUpgradeText_WithSkippedAndFailed(t *testing.T) {
 	// Hint for major upgrades
 	assert.Contains(t, output, "--major")
 }
+
+func TestRunOutdated_Offline(t *testing.T) {
+	t.Setenv(offline.EnvVar, "1")
+	oldJSON := outdatedJSON
+	defer func() { outdatedJSON = oldJSON }()
+
+	outdatedJSON = false
+	output := captureStdout(t, func() {
+		require.NoError(t, runOutdated(nil, nil))
+	})
+	assert.Equal(t, "Outdated check skipped (offline).\n", output)
+
+	outdatedJSON = true
+	output = captureStdout(t, func() {
+		require.NoError(t, runOutdated(nil, nil))
+	})
+	assert.Contains(t, output, `"error": "outdated check skipped (offline)"`)
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// noColor disables colored output.
+var noColor bool
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "disable colored output (also set by NO_COLOR)")
+}
+
+// setupColor turns off colors when --no-color or NO_COLOR is set.
+func setupColor() {
+	if noColor || os.Getenv("NO_COLOR") != "" {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+}
+
+// stdoutIsTerminal reports whether stdout is an interactive terminal, as
+// opposed to a pipe, a file or CI log capture.
+func stdoutIsTerminal() bool {
+	info, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/domain/marketplace"
+)
+
+// parseVarFlags parses --var name=value flags.
+func parseVarFlags(flags []string) (map[string]string, error) {
+	values := make(map[string]string, len(flags))
+	for _, flag := range flags {
+		name, value, ok := strings.Cut(flag, "=")
+		if !ok || !marketplace.IsValidParameterName(name) {
+			return nil, fmt.Errorf("invalid --var %q: use name=value", flag)
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+// parseParamFlags parses --param name or name=default flags. Parameters
+// without a default are required.
+func parseParamFlags(flags []string) ([]marketplace.Parameter, error) {
+	params := make([]marketplace.Parameter, 0, len(flags))
+	for _, flag := range flags {
+		name, value, ok := strings.Cut(flag, "=")
+		p := marketplace.Parameter{Name: name, Default: value, Required: !ok || value == ""}
+		if err := p.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid --param %q: use name or name=default", flag)
+		}
+		params = append(params, p)
+	}
+	return params, nil
+}
+
+// resolveParameterValues returns a value for every parameter: from --var,
+// else asked for with prompt, else its default. Without prompt, a required
+// parameter with no value is an error.
+func resolveParameterValues(params []marketplace.Parameter, preset, defaults map[string]string, prompt func(marketplace.Parameter) (string, error)) (map[string]string, error) {
+	values := make(map[string]string, len(params))
+	for _, p := range params {
+		if value, ok := preset[p.Name]; ok {
+			values[p.Name] = value
+			continue
+		}
+		if p.Default == "" {
+			p.Default = defaults[p.Name]
+		}
+		value := p.Default
+		if prompt != nil {
+			answer, err := prompt(p)
+			if err != nil {
+				return nil, err
+			}
+			if answer != "" {
+				value = answer
+			}
+		}
+		if value == "" && p.Required {
+			return nil, &config.UserError{
+				Code:       config.ErrCodeValidationFailed,
+				Message:    fmt.Sprintf("a value for %q is required", p.Name),
+				Suggestion: fmt.Sprintf("Pass it with --var %s=<value>.", p.Name),
+			}
+		}
+		values[p.Name] = value
+	}
+	return values, nil
+}
+
+// promptParameter asks for a parameter on out and reads the answer from
+// in. An empty answer keeps the default.
+func promptParameter(in *bufio.Reader, out io.Writer) func(marketplace.Parameter) (string, error) {
+	return func(p marketplace.Parameter) (string, error) {
+		label := p.Prompt
+		if label == "" {
+			label = p.Name
+		}
+		if p.Default != "" {
+			fmt.Fprintf(out, "%s [%s]: ", label, p.Default)
+		} else {
+			fmt.Fprintf(out, "%s: ", label)
+		}
+		answer, err := in.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", err
+		}
+		return strings.TrimSpace(answer), nil
+	}
+}
+
+// terminalParameterPrompt prompts on the terminal, or returns nil when
+// stdin is not one or --yes is set, so that defaults and --var apply.
+func terminalParameterPrompt() func(marketplace.Parameter) (string, error) {
+	if yesFlag {
+		return nil
+	}
+	info, err := os.Stdin.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return nil
+	}
+	return promptParameter(bufio.NewReader(os.Stdin), os.Stdout)
+}
+
+// fillPackageParameters resolves the parameters that the layers under dir
+// still reference and substitutes them, returning the files it changed.
+func fillPackageParameters(dir string, params []marketplace.Parameter, preset, defaults map[string]string, prompt func(marketplace.Parameter) (string, error)) ([]string, error) {
+	pending, err := marketplace.PendingParameters(dir, params)
+	if err != nil || len(pending) == 0 {
+		return nil, err
+	}
+	values, err := resolveParameterValues(pending, preset, defaults, prompt)
+	if err != nil {
+		return nil, err
+	}
+	return marketplace.ApplyParameters(dir, values)
+}
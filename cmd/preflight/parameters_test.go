@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/domain/marketplace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVarFlags(t *testing.T) {
+	t.Parallel()
+
+	values, err := parseVarFlags([]string{"name=Jane Doe", "email=jane@acme.com", "team="})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"name": "Jane Doe", "email": "jane@acme.com", "team": ""}, values)
+
+	for _, flag := range []string{"name", "=value", "full-name=Jane"} {
+		_, err := parseVarFlags([]string{flag})
+		assert.Error(t, err, flag)
+	}
+}
+
+func TestParseParamFlags(t *testing.T) {
+	t.Parallel()
+
+	params, err := parseParamFlags([]string{"email", "proxy_url=http://proxy:3128"})
+	require.NoError(t, err)
+	assert.Equal(t, []marketplace.Parameter{
+		{Name: "email", Required: true},
+		{Name: "proxy_url", Default: "http://proxy:3128"},
+	}, params)
+
+	_, err = parseParamFlags([]string{"proxy-url"})
+	assert.Error(t, err)
+}
+
+func TestResolveParameterValues(t *testing.T) {
+	t.Parallel()
+
+	params := []marketplace.Parameter{
+		{Name: "name", Required: true},
+		{Name: "email", Required: true},
+		{Name: "team", Default: "platform"},
+	}
+	preset := map[string]string{"name": "Jane Doe"}
+	defaults := map[string]string{"email": "jane@example.com"}
+
+	values, err := resolveParameterValues(params, preset, defaults, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"name": "Jane Doe", "email": "jane@example.com", "team": "platform"}, values)
+
+	prompt := promptParameter(bufio.NewReader(strings.NewReader("jane@acme.com\n\n")), io.Discard)
+	values, err = resolveParameterValues(params, preset, defaults, prompt)
+	require.NoError(t, err)
+	assert.Equal(t, "jane@acme.com", values["email"])
+	assert.Equal(t, "platform", values["team"], "an empty answer keeps the default")
+
+	_, err = resolveParameterValues(params, preset, nil, nil)
+	var userErr *config.UserError
+	require.ErrorAs(t, err, &userErr)
+	assert.Contains(t, userErr.Suggestion, "--var email=")
+}
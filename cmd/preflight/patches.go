@@ -0,0 +1,283 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/adapters/logging"
+	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/spf13/cobra"
+)
+
+var patchesCmd = &cobra.Command{
+	Use:   "patches",
+	Short: "Review config updates saved for later",
+	Long: `Review and apply pending config patches.
+
+'preflight doctor --update-config' saves the layer updates you do not apply
+right away, the agent saves the packages it finds installed outside
+preflight, and 'preflight discover --from-history' saves the tools it finds
+in your shell history. Pending patches are kept in the patches directory of
+the preflight state directory (see 'preflight paths') until they are
+applied or discarded. A newer set from the same source for the same config
+and target replaces the older one.`,
+}
+
+var patchesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List pending patch sets",
+	RunE:  runPatchesList,
+}
+
+var patchesShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show the changes of a pending patch set",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runPatchesShow,
+}
+
+var patchesApplyCmd = &cobra.Command{
+	Use:   "apply [id...]",
+	Short: "Apply pending patch sets to the layer files",
+	Long: `Apply pending patch sets and remove them from the pending list.
+
+When the configuration is a git repository, each updated layer is committed
+on its own, unless it already had uncommitted changes.
+
+Examples:
+  preflight patches apply 20261016T091500Z-agent
+  preflight patches apply --all --yes`,
+	RunE: runPatchesApply,
+}
+
+var patchesDiscardCmd = &cobra.Command{
+	Use:   "discard [id...]",
+	Short: "Discard pending patch sets",
+	RunE:  runPatchesDiscard,
+}
+
+var (
+	patchesListJSON   bool
+	patchesApplyAll   bool
+	patchesDiscardAll bool
+)
+
+func init() {
+	patchesListCmd.Flags().BoolVar(&patchesListJSON, "json", false, "Output as JSON")
+	patchesApplyCmd.Flags().BoolVar(&patchesApplyAll, "all", false, "Apply every pending patch set")
+	patchesDiscardCmd.Flags().BoolVar(&patchesDiscardAll, "all", false, "Discard every pending patch set")
+
+	patchesCmd.AddCommand(patchesListCmd)
+	patchesCmd.AddCommand(patchesShowCmd)
+	patchesCmd.AddCommand(patchesApplyCmd)
+	patchesCmd.AddCommand(patchesDiscardCmd)
+	rootCmd.AddCommand(patchesCmd)
+}
+
+// patchStore returns the pending patch store in the default location.
+var patchStore = app.DefaultPatchStore
+
+func runPatchesList(_ *cobra.Command, _ []string) error {
+	store, err := patchStore()
+	if err != nil {
+		return err
+	}
+	sets, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	if patchesListJSON {
+		if sets == nil {
+			sets = []app.PendingPatches{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(sets)
+	}
+
+	if len(sets) == 0 {
+		fmt.Println("No pending patches.")
+		return nil
+	}
+
+	fmt.Printf("%-28s %-7s %-10s %-17s %7s  %s\n", "ID", "SOURCE", "TARGET", "CREATED", "PATCHES", "LAYERS")
+	for _, set := range sets {
+		fmt.Printf("%-28s %-7s %-10s %-17s %7d  %s\n",
+			set.ID,
+			set.Source,
+			set.Target,
+			set.CreatedAt.Local().Format("2006-01-02 15:04"),
+			set.PatchCount(),
+			strings.Join(set.Layers(), ", "),
+		)
+	}
+	fmt.Println("\nReview a set with 'preflight patches show <id>'.")
+	return nil
+}
+
+func runPatchesShow(_ *cobra.Command, args []string) error {
+	store, err := patchStore()
+	if err != nil {
+		return err
+	}
+	set, err := store.Get(args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s (from %s, %s)\n", set.ID, set.Source, set.CreatedAt.Local().Format("2006-01-02 15:04"))
+	fmt.Printf("Config: %s (target %s)\n\n", set.ConfigPath, set.Target)
+	printPendingUpdates(set)
+	return nil
+}
+
+// printPendingUpdates prints the description and diff of each update.
+func printPendingUpdates(set *app.PendingPatches) {
+	for _, update := range set.Updates {
+		fmt.Println(update.Description())
+		diff, err := app.PreviewLayerUpdate(update)
+		if err != nil {
+			fmt.Printf("  cannot be applied: %v\n\n", err)
+			continue
+		}
+		fmt.Println(diff)
+	}
+}
+
+// selectPendingPatches returns the sets named by ids, or all sets.
+func selectPendingPatches(store *app.PatchStore, ids []string, all bool) ([]app.PendingPatches, error) {
+	if all {
+		return store.List()
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("name the patch sets to use, or pass --all")
+	}
+	sets := make([]app.PendingPatches, 0, len(ids))
+	for _, id := range ids {
+		set, err := store.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, *set)
+	}
+	return sets, nil
+}
+
+func runPatchesApply(_ *cobra.Command, args []string) error {
+	store, err := patchStore()
+	if err != nil {
+		return err
+	}
+	sets, err := selectPendingPatches(store, args, patchesApplyAll)
+	if err != nil {
+		return err
+	}
+	if len(sets) == 0 {
+		fmt.Println("No pending patches.")
+		return nil
+	}
+
+	if !yesFlag {
+		for i := range sets {
+			fmt.Printf("%s (from %s)\n\n", sets[i].ID, sets[i].Source)
+			printPendingUpdates(&sets[i])
+		}
+		fmt.Print("Apply these changes? [y/N] ")
+		response, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		response = strings.TrimSpace(strings.ToLower(response))
+		if response != "y" && response != "yes" {
+			fmt.Println("No changes applied.")
+			return nil
+		}
+	}
+
+	ctx := context.Background()
+	for _, set := range sets {
+		for i, update := range set.Updates {
+			committed, err := app.ApplyLayerUpdate(ctx, update)
+			if err != nil {
+				// Keep what was not applied for another try
+				set.Updates = set.Updates[i:]
+				if _, saveErr := store.Save(set); saveErr != nil {
+					return fmt.Errorf("failed to update layer %s: %w (and failed to keep the rest pending: %v)", update.Layer, err, saveErr)
+				}
+				return fmt.Errorf("failed to update layer %s: %w", update.Layer, err)
+			}
+			if committed {
+				fmt.Printf("✓ Updated and committed layer %s\n", update.Layer)
+			} else {
+				fmt.Printf("✓ Updated layer %s\n", update.Layer)
+			}
+		}
+		if err := store.Discard(set.ID); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Applied %d patch set(s).\n", len(sets))
+	return nil
+}
+
+func runPatchesDiscard(_ *cobra.Command, args []string) error {
+	store, err := patchStore()
+	if err != nil {
+		return err
+	}
+	sets, err := selectPendingPatches(store, args, patchesDiscardAll)
+	if err != nil {
+		return err
+	}
+	for _, set := range sets {
+		if err := store.Discard(set.ID); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Discarded %d patch set(s).\n", len(sets))
+	return nil
+}
+
+// savePendingPatches keeps updates for review with 'preflight patches'.
+func savePendingPatches(source, configPath, target string, updates []app.LayerUpdate) (string, error) {
+	store, err := patchStore()
+	if err != nil {
+		return "", err
+	}
+	return store.Save(app.NewPendingPatches(source, configPath, target, updates))
+}
+
+// agentPatchPollInterval is how often the agent looks for packages
+// installed outside preflight.
+const agentPatchPollInterval = time.Hour
+
+// watchUntrackedPackages saves the packages installed outside preflight as
+// pending patches, replacing those it saved before.
+func watchUntrackedPackages(ctx context.Context, preflight *app.Preflight, configPath, target string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		updates, err := preflight.UntrackedPackages(ctx, configPath, target, app.SplitByCategory)
+		if err == nil {
+			var id string
+			id, err = savePendingPatches(app.PatchSourceAgent, configPath, target, updates)
+			if id != "" {
+				logging.Default().Info(ctx, "untracked packages found",
+					ports.F("patches", id), ports.F("layers", len(updates)))
+			}
+		}
+		if err != nil {
+			logging.Default().Warn(ctx, "untracked package check failed", ports.F("error", err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
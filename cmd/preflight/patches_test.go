@@ -0,0 +1,86 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunPatches(t *testing.T) {
+	dir := t.TempDir()
+	store := app.NewPatchStore(filepath.Join(dir, "patches"))
+	prevStore, prevYes, prevAll := patchStore, yesFlag, patchesApplyAll
+	t.Cleanup(func() { patchStore, yesFlag, patchesApplyAll = prevStore, prevYes, prevAll })
+	patchStore = func() (*app.PatchStore, error) { return store, nil }
+
+	output := captureStdout(t, func() {
+		require.NoError(t, runPatchesList(nil, nil))
+	})
+	assert.Contains(t, output, "No pending patches.")
+
+	layer := filepath.Join(dir, "layers", "misc.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(layer), 0o755))
+	require.NoError(t, os.WriteFile(layer, []byte("name: misc\n"), 0o644))
+	update := app.LayerUpdate{
+		Layer:    "misc",
+		Path:     layer,
+		Packages: []string{"jq"},
+		Patches:  []app.ConfigPatch{app.NewConfigPatch(layer, "packages.brew.formulae", app.PatchOpAdd, nil, "jq", "agent")},
+	}
+	id, err := savePendingPatches(app.PatchSourceAgent, filepath.Join(dir, "preflight.yaml"), "default", []app.LayerUpdate{update})
+	require.NoError(t, err)
+
+	output = captureStdout(t, func() {
+		require.NoError(t, runPatchesList(nil, nil))
+	})
+	assert.Contains(t, output, id)
+	assert.Contains(t, output, "agent")
+
+	output = captureStdout(t, func() {
+		require.NoError(t, runPatchesShow(nil, []string{id}))
+	})
+	assert.Contains(t, output, "Track 1 package(s) in layer misc: jq")
+	assert.Contains(t, output, "+    formulae:\n")
+
+	require.Error(t, runPatchesApply(nil, nil), "apply needs ids or --all")
+	require.Error(t, runPatchesShow(nil, []string{"missing"}))
+
+	yesFlag = true
+	patchesApplyAll = true
+	output = captureStdout(t, func() {
+		require.NoError(t, runPatchesApply(nil, nil))
+	})
+	assert.Contains(t, output, "✓ Updated layer misc")
+	data, err := os.ReadFile(layer)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "- jq")
+
+	sets, err := store.List()
+	require.NoError(t, err)
+	assert.Empty(t, sets, "applied sets are removed")
+}
+
+func TestRunPatchesDiscard(t *testing.T) {
+	dir := t.TempDir()
+	store := app.NewPatchStore(dir)
+	prevStore, prevAll := patchStore, patchesDiscardAll
+	t.Cleanup(func() { patchStore, patchesDiscardAll = prevStore, prevAll })
+	patchStore = func() (*app.PatchStore, error) { return store, nil }
+
+	update := app.LayerUpdate{Layer: "misc", Path: filepath.Join(dir, "misc.yaml"), Packages: []string{"jq"}}
+	_, err := savePendingPatches(app.PatchSourceDoctor, "preflight.yaml", "default", []app.LayerUpdate{update})
+	require.NoError(t, err)
+
+	patchesDiscardAll = true
+	output := captureStdout(t, func() {
+		require.NoError(t, runPatchesDiscard(nil, nil))
+	})
+	assert.Contains(t, output, "Discarded 1 patch set(s).")
+	sets, err := store.List()
+	require.NoError(t, err)
+	assert.Empty(t, sets)
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
+	"github.com/spf13/cobra"
+)
+
+var pathsCmd = &cobra.Command{
+	Use:   "paths",
+	Short: "Show where preflight keeps its files",
+	Long: `Show where preflight keeps its files.
+
+Preflight follows the XDG Base Directory Specification:
+
+  config  $XDG_CONFIG_HOME/preflight   (~/.config/preflight)
+  data    $XDG_DATA_HOME/preflight     (~/.local/share/preflight)
+  state   $XDG_STATE_HOME/preflight    (~/.local/state/preflight)
+  cache   $XDG_CACHE_HOME/preflight    (~/.cache/preflight)
+
+Set PREFLIGHT_HOME to an absolute path to keep everything in one
+directory instead.
+
+Earlier releases kept everything in ~/.preflight. Preflight moves its
+contents to the directories above on the first run, unless PREFLIGHT_HOME
+is set or the agent is running. Update PATH entries that name
+~/.preflight/shims afterwards.
+
+Examples:
+  preflight paths
+  preflight paths --json`,
+	Args: cobra.NoArgs,
+	RunE: runPaths,
+}
+
+var pathsJSON bool
+
+func init() {
+	pathsCmd.Flags().BoolVar(&pathsJSON, "json", false, "Output as JSON")
+	rootCmd.AddCommand(pathsCmd)
+}
+
+// dirContents says what each preflight directory holds.
+var dirContents = map[platform.DirKind]string{
+	platform.ConfigDir: "trust, plugin grants, profiles, telemetry, notice and encryption settings",
+	platform.DataDir:   "plugins, shims, secrets, identities, catalogs and sync data",
+	platform.StateDir:  "history, snapshots, backups, patches, audit and agent logs, locks",
+	platform.CacheDir:  "marketplace downloads and the knowledge base",
+}
+
+// pathsReport is the output of preflight paths.
+type pathsReport struct {
+	Layout     string     `json:"layout"`
+	ConfigFile string     `json:"config_file"`
+	Found      bool       `json:"config_found"`
+	Dirs       []pathsDir `json:"dirs"`
+}
+
+type pathsDir struct {
+	Kind     platform.DirKind `json:"kind"`
+	Path     string           `json:"path"`
+	Contents string           `json:"contents"`
+}
+
+func runPaths(_ *cobra.Command, _ []string) error {
+	report, err := buildPathsReport()
+	if err != nil {
+		return err
+	}
+	if pathsJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	printPathsReport(os.Stdout, report)
+	return nil
+}
+
+func buildPathsReport() (pathsReport, error) {
+	report := pathsReport{Layout: pathsLayout()}
+
+	configPath := cfgFile
+	if configPath == "" {
+		configPath = "preflight.yaml"
+	}
+	if abs, err := filepath.Abs(configPath); err == nil {
+		configPath = abs
+	}
+	report.ConfigFile = configPath
+	if _, err := os.Stat(configPath); err == nil {
+		report.Found = true
+	}
+
+	for _, kind := range platform.DirKinds {
+		dir, err := platform.Dir(kind)
+		if err != nil {
+			return pathsReport{}, err
+		}
+		report.Dirs = append(report.Dirs, pathsDir{Kind: kind, Path: dir, Contents: dirContents[kind]})
+	}
+	return report, nil
+}
+
+// pathsLayout names where the preflight directories come from: "home"
+// for PREFLIGHT_HOME, "legacy" for a ~/.preflight that was not migrated,
+// and "xdg" otherwise.
+func pathsLayout() string {
+	if os.Getenv(platform.HomeEnvVar) != "" {
+		return "home"
+	}
+	legacy, err := platform.LegacyDir()
+	if err != nil {
+		return "xdg"
+	}
+	if dir, err := platform.Dir(platform.ConfigDir); err == nil && dir == legacy {
+		return "legacy"
+	}
+	return "xdg"
+}
+
+func printPathsReport(w io.Writer, report pathsReport) {
+	found := ""
+	if !report.Found {
+		found = " (not found)"
+	}
+	_, _ = fmt.Fprintf(w, "Config file: %s%s\n\n", report.ConfigFile, found)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "KIND\tPATH\tHOLDS")
+	for _, d := range report.Dirs {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", d.Kind, d.Path, d.Contents)
+	}
+	_ = tw.Flush()
+
+	switch report.Layout {
+	case "home":
+		_, _ = fmt.Fprintf(w, "\nEverything is in %s, set by %s.\n", report.Dirs[0].Path, platform.HomeEnvVar)
+	case "legacy":
+		_, _ = fmt.Fprintln(w, "\n~/.preflight was not moved to the XDG directories yet. Stop the agent")
+		_, _ = fmt.Fprintln(w, "and run preflight again to move it.")
+	}
+}
+
+// migrateLegacyDirs moves ~/.preflight to the XDG directories and tells w
+// what moved. Failures leave ~/.preflight in use and are retried on the
+// next run.
+func migrateLegacyDirs(w io.Writer) {
+	moves, err := platform.MigrateLegacy()
+	switch {
+	case errors.Is(err, platform.ErrAgentRunning):
+		_, _ = fmt.Fprintln(w, "preflight: ~/.preflight stays in use until the agent is stopped; then preflight moves it to the XDG directories")
+		return
+	case err != nil:
+		_, _ = fmt.Fprintf(w, "preflight: ~/.preflight stays in use: %v\n", err)
+		return
+	case len(moves) == 0:
+		return
+	}
+
+	_, _ = fmt.Fprintln(w, "preflight: moved ~/.preflight to the XDG directories:")
+	for _, kind := range platform.DirKinds {
+		if dir, err := platform.XDGDir(kind); err == nil && movedTo(moves, kind) {
+			_, _ = fmt.Fprintf(w, "  %-6s %s\n", kind, dir)
+		}
+	}
+	if shims, err := platform.Path(platform.DataDir, "shims"); err == nil && shimsMoved(moves) {
+		_, _ = fmt.Fprintf(w, "Update PATH entries that name ~/.preflight/shims to %s.\n", shims)
+	}
+	_, _ = fmt.Fprintln(w, "Run 'preflight paths' to see where everything lives.")
+}
+
+// movedTo reports whether any of moves went to a directory of kind.
+func movedTo(moves []platform.Move, kind platform.DirKind) bool {
+	for _, m := range moves {
+		if m.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// shimsMoved reports whether moves include the shims directory.
+func shimsMoved(moves []platform.Move) bool {
+	for _, m := range moves {
+		if strings.HasSuffix(filepath.ToSlash(m.From), "/shims") {
+			return true
+		}
+	}
+	return false
+}
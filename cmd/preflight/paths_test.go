@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setPathsHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(platform.HomeEnvVar, "")
+	for _, env := range []string{"XDG_CONFIG_HOME", "XDG_DATA_HOME", "XDG_STATE_HOME", "XDG_CACHE_HOME"} {
+		t.Setenv(env, "")
+	}
+	return home
+}
+
+func TestRunPaths(t *testing.T) {
+	home := setPathsHome(t)
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.WriteFile("preflight.yaml", []byte("targets: {}\n"), 0o644))
+	oldCfg := cfgFile
+	cfgFile = ""
+	defer func() { cfgFile = oldCfg }()
+
+	output := captureStdout(t, func() {
+		require.NoError(t, runPaths(pathsCmd, nil))
+	})
+
+	assert.Contains(t, output, "preflight.yaml\n")
+	assert.NotContains(t, output, "not found")
+	assert.Contains(t, output, filepath.Join(home, ".config", "preflight"))
+	assert.Contains(t, output, filepath.Join(home, ".local", "state", "preflight"))
+	assert.Contains(t, output, "history, snapshots")
+}
+
+func TestRunPaths_JSON(t *testing.T) {
+	setPathsHome(t)
+	t.Setenv(platform.HomeEnvVar, "/srv/preflight")
+	t.Chdir(t.TempDir())
+	pathsJSON = true
+	defer func() { pathsJSON = false }()
+
+	output := captureStdout(t, func() {
+		require.NoError(t, runPaths(pathsCmd, nil))
+	})
+
+	var report pathsReport
+	require.NoError(t, json.Unmarshal([]byte(output), &report))
+	assert.Equal(t, "home", report.Layout)
+	assert.False(t, report.Found)
+	require.Len(t, report.Dirs, 4)
+	for _, d := range report.Dirs {
+		assert.Equal(t, "/srv/preflight", d.Path)
+	}
+}
+
+func TestPathsLayout_Legacy(t *testing.T) {
+	home := setPathsHome(t)
+	require.NoError(t, os.Mkdir(filepath.Join(home, ".preflight"), 0o700))
+
+	assert.Equal(t, "legacy", pathsLayout())
+}
+
+func TestMigrateLegacyDirs(t *testing.T) {
+	home := setPathsHome(t)
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".preflight", "shims"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".preflight", "shims", "node"), nil, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".preflight", "trust.json"), []byte("{}"), 0o600))
+
+	var out bytes.Buffer
+	migrateLegacyDirs(&out)
+
+	assert.Contains(t, out.String(), "moved ~/.preflight")
+	assert.Contains(t, out.String(), filepath.Join(home, ".config", "preflight"))
+	assert.Contains(t, out.String(), "to "+filepath.Join(home, ".local", "share", "preflight", "shims"))
+	assert.NotContains(t, out.String(), "state")
+	assert.FileExists(t, filepath.Join(home, ".local", "share", "preflight", "shims", "node"))
+	assert.Equal(t, "xdg", pathsLayout())
+
+	// Quiet once migrated
+	out.Reset()
+	migrateLegacyDirs(&out)
+	assert.Empty(t, out.String())
+}
+
+func TestMigrateLegacyDirs_AgentRunning(t *testing.T) {
+	home := setPathsHome(t)
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".preflight"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".preflight", "agent.sock"), nil, 0o600))
+
+	var out bytes.Buffer
+	migrateLegacyDirs(&out)
+
+	assert.Contains(t, out.String(), "until the agent is stopped")
+	assert.DirExists(t, filepath.Join(home, ".preflight"))
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/spf13/cobra"
+)
+
+var pinCmd = &cobra.Command{
+	Use:   "pin",
+	Short: "Hold packages at their current version",
+	Long: `Hold packages at their current version.
+
+Pins are recorded in the layer that declares the package, so they are
+shared with everyone using the layer. 'preflight outdated' and
+'preflight upgrade' leave pinned packages alone, and 'preflight doctor'
+flags pins held longer than upgrades.pin_max_age (default 90d).
+
+Packages are written as manager:name; a name without a manager is a
+Homebrew formula. Pinning also holds the package in its package manager:
+
+  brew     brew pin
+  npm      the layer entry becomes name@version
+  vscode   the layer entry becomes publisher.extension@version
+
+Examples:
+  preflight pin add node                              # brew pin node
+  preflight pin add npm:typescript --reason "TS 6 breaks the build"
+  preflight pin add vscode:golang.go --version 0.41.2 --layer dev-go
+  preflight pin list
+  preflight pin remove node`,
+}
+
+var pinAddCmd = &cobra.Command{
+	Use:   "add <package>",
+	Short: "Pin a package at its installed version",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runPinAdd,
+}
+
+var pinRemoveCmd = &cobra.Command{
+	Use:     "remove <package>",
+	Aliases: []string{"rm"},
+	Short:   "Unpin a package",
+	Args:    cobra.ExactArgs(1),
+	RunE:    runPinRemove,
+}
+
+var pinListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List pinned packages",
+	Args:  cobra.NoArgs,
+	RunE:  runPinList,
+}
+
+var (
+	pinVersion string
+	pinReason  string
+	pinLayer   string
+	pinDryRun  bool
+	pinJSON    bool
+)
+
+func init() {
+	rootCmd.AddCommand(pinCmd)
+	pinCmd.AddCommand(pinAddCmd, pinRemoveCmd, pinListCmd)
+
+	pinAddCmd.Flags().StringVar(&pinVersion, "version", "", "Version to pin (default: the installed version)")
+	pinAddCmd.Flags().StringVar(&pinReason, "reason", "", "Why the package is pinned")
+	pinAddCmd.Flags().StringVar(&pinLayer, "layer", "", "Layer to record the pin in (default: the layer declaring the package)")
+	for _, cmd := range []*cobra.Command{pinAddCmd, pinRemoveCmd} {
+		cmd.Flags().BoolVar(&pinDryRun, "dry-run", false, "Show the layer changes without making them")
+	}
+	pinListCmd.Flags().BoolVar(&pinJSON, "json", false, "Output as JSON")
+}
+
+func runPinAdd(_ *cobra.Command, args []string) error {
+	ctx := context.Background()
+	pin := config.Pin{
+		Package: args[0],
+		Version: pinVersion,
+		Since:   time.Now().Format(config.PinDateLayout),
+		Reason:  pinReason,
+	}
+	if _, _, err := config.ParsePinRef(pin.Package); err != nil {
+		return err
+	}
+	if pin.Version == "" {
+		version, err := app.InstalledVersion(pin.Package, func(name string, args ...string) (string, error) {
+			out, err := exec.CommandContext(ctx, name, args...).Output()
+			return string(out), err
+		})
+		if err != nil {
+			return err
+		}
+		pin.Version = version
+	}
+
+	change, err := app.PlanPinAdd(cfgFile, pinLayer, pin)
+	if err != nil {
+		return err
+	}
+	if err := applyPinChange(ctx, change); err != nil {
+		return err
+	}
+	if !pinDryRun {
+		fmt.Printf("Pinned %s at %s in layer %s\n", pin.Package, pin.Version, change.Layer)
+	}
+	return nil
+}
+
+func runPinRemove(_ *cobra.Command, args []string) error {
+	ctx := context.Background()
+	change, err := app.PlanPinRemove(cfgFile, args[0])
+	if err != nil {
+		return err
+	}
+	if err := applyPinChange(ctx, change); err != nil {
+		return err
+	}
+	if !pinDryRun {
+		fmt.Printf("Unpinned %s in layer %s\n", change.Pin.Package, change.Layer)
+	}
+	return nil
+}
+
+// applyPinChange previews change with --dry-run and otherwise writes the
+// layers and runs the package manager commands. A package manager that is
+// not installed, or a failing command, only warns: the pin is recorded and
+// outdated and upgrade honor it either way.
+func applyPinChange(ctx context.Context, change *app.PinChange) error {
+	if pinDryRun {
+		diff, err := app.PreviewConfigPatches(change.Patches)
+		if err != nil {
+			return err
+		}
+		fmt.Print(diff)
+		for _, command := range change.Commands {
+			fmt.Printf("Would run: %s\n", strings.Join(command, " "))
+		}
+		return nil
+	}
+
+	return app.ApplyPinChange(ctx, change, func(ctx context.Context, name string, args ...string) error {
+		command := strings.Join(append([]string{name}, args...), " ")
+		if _, err := exec.LookPath(name); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %s is not installed; skipped %s\n", name, command)
+			return nil
+		}
+		cmd := exec.CommandContext(ctx, name, args...)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %s failed: %v: %s\n", command, err, strings.TrimSpace(stderr.String()))
+		}
+		return nil
+	})
+}
+
+func runPinList(_ *cobra.Command, _ []string) error {
+	pins, err := app.ListPins(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	if pinJSON {
+		if pins == nil {
+			pins = []app.LayerPin{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(pins)
+	}
+
+	if len(pins) == 0 {
+		fmt.Println("No pinned packages.")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "PACKAGE\tVERSION\tSINCE\tLAYER\tREASON")
+	for _, pin := range pins {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			pin.Package, pin.Version, pin.Since, pin.Layer, pin.Reason)
+	}
+	return w.Flush()
+}
+
+// pinnedPackages returns the packages pinned in the layers next to
+// configPath, as outdated and upgrade expect them.
+func pinnedPackages(configPath string) []string {
+	pins, err := app.ListPins(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not read pins: %v\n", err)
+		return nil
+	}
+	return app.PinnedPackages(pins)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPinAddListRemove(t *testing.T) {
+	saved := cfgFile
+	defer func() { cfgFile, pinVersion, pinReason, pinLayer, pinDryRun, pinJSON = saved, "", "", "", false, false }()
+
+	dir := t.TempDir()
+	cfgFile = filepath.Join(dir, "preflight.yaml")
+	require.NoError(t, os.WriteFile(cfgFile, []byte("targets:\n  default: [base]\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "layers"), 0o755))
+	layerPath := filepath.Join(dir, "layers", "base.yaml")
+	require.NoError(t, os.WriteFile(layerPath, []byte("name: base\npackages:\n  npm:\n    packages:\n      - typescript\n"), 0o644))
+
+	pinVersion, pinReason = "5.4.5", "TS 6 breaks the build"
+	out := captureStdout(t, func() { require.NoError(t, runPinAdd(pinAddCmd, []string{"npm:typescript"})) })
+	assert.Contains(t, out, "Pinned npm:typescript at 5.4.5 in layer base")
+	assert.Equal(t, []string{"npm:typescript"}, pinnedPackages(cfgFile))
+
+	out = captureStdout(t, func() { require.NoError(t, runPinList(pinListCmd, nil)) })
+	assert.Contains(t, out, "npm:typescript")
+	assert.Contains(t, out, "TS 6 breaks the build")
+
+	pinDryRun = true
+	out = captureStdout(t, func() { require.NoError(t, runPinRemove(pinRemoveCmd, []string{"npm:typescript"})) })
+	assert.Contains(t, out, "-      - typescript@5.4.5")
+	assert.Equal(t, []string{"npm:typescript"}, pinnedPackages(cfgFile), "dry run changes nothing")
+
+	pinDryRun = false
+	captureStdout(t, func() { require.NoError(t, runPinRemove(pinRemoveCmd, []string{"npm:typescript"})) })
+	assert.Empty(t, pinnedPackages(cfgFile))
+	data, err := os.ReadFile(layerPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "- typescript\n")
+}
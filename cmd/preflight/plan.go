@@ -6,10 +6,13 @@ import (
 	"io"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
 	"github.com/felixgeelhaar/preflight/internal/app"
 	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/domain/execution"
+	"github.com/felixgeelhaar/preflight/internal/provider/files"
 	"github.com/spf13/cobra"
 )
 
@@ -22,13 +25,24 @@ This command:
 1. Loads and merges configuration layers
 2. Compiles config into executable steps
 3. Checks current system state
-4. Shows what would be changed (without making changes)`,
+4. Shows what would be changed (without making changes)
+
+With --against-ref, plan compares two revisions of a config kept in git:
+it plans the config as of the ref and as it is on disk (or as of a second
+ref, written base..head) against this machine, and shows the steps that
+differ. Use it to review config changes by their effect on a real system.
+
+Examples:
+  preflight plan --against-ref HEAD~5         # What the last 5 commits change here
+  preflight plan --against-ref main..feature  # What merging feature would change`,
 	RunE: runPlan,
 }
 
 var (
-	planConfigPath string
-	planTarget     string
+	planConfigPath  string
+	planTarget      string
+	planSummaryFile string
+	planAgainstRef  string
 )
 
 var newPlanPreflight = func(out io.Writer) preflightClient {
@@ -49,11 +63,28 @@ func (a *planPreflightAdapter) WithRollbackOnFailure(enabled bool) preflightClie
 	return a
 }
 
+func (a *planPreflightAdapter) WithRunLockWait(wait bool) preflightClient {
+	a.Preflight = a.Preflight.WithRunLockWait(wait)
+	return a
+}
+
+func (a *planPreflightAdapter) WithMergePreference(prefer files.MergePreference) preflightClient {
+	a.Preflight = a.Preflight.WithMergePreference(prefer)
+	return a
+}
+
+func (a *planPreflightAdapter) WithProgress(start execution.StepStartObserver, done execution.StepObserver) preflightClient {
+	a.Preflight = a.Preflight.WithStepStartObserver(start).WithStepObserver(done)
+	return a
+}
+
 func init() {
 	rootCmd.AddCommand(planCmd)
 
 	planCmd.Flags().StringVarP(&planConfigPath, "config", "c", "preflight.yaml", "Path to preflight.yaml")
 	planCmd.Flags().StringVarP(&planTarget, "target", "t", "default", "Target to plan")
+	planCmd.Flags().StringVar(&planSummaryFile, "summary-file", "", "Write a summary to this file (JSON for .json, else markdown, e.g. $GITHUB_STEP_SUMMARY)")
+	planCmd.Flags().StringVar(&planAgainstRef, "against-ref", "", "Compare against the config as of a git ref (base or base..head)")
 }
 
 func runPlan(cmd *cobra.Command, _ []string) error {
@@ -68,14 +99,45 @@ func runPlan(cmd *cobra.Command, _ []string) error {
 		preflight.WithMode(*modeOverride)
 	}
 
+	planTarget = resolveMachineTarget(ctx, cmd, planConfigPath, planTarget)
+	if planAgainstRef != "" {
+		return runPlanAgainstRef(ctx, cmd)
+	}
+	summary := newRunSummary("plan", planTarget)
+	defer saveSummary(planSummaryFile, summary)
+
 	// Create the plan
 	plan, err := preflight.Plan(ctx, planConfigPath, planTarget)
 	if err != nil {
+		summary.fail(err)
 		return fmt.Errorf("plan failed: %w", err)
 	}
+	summary.setPlan(plan)
 
 	// Print the plan
 	preflight.PrintPlan(plan)
 
 	return nil
 }
+
+// runPlanAgainstRef shows what moving the config between two git revisions
+// would change on this machine.
+func runPlanAgainstRef(ctx context.Context, cmd *cobra.Command) error {
+	base, head, _ := strings.Cut(planAgainstRef, "..")
+	if base == "" {
+		return fmt.Errorf("--against-ref needs a base ref, got %q", planAgainstRef)
+	}
+
+	preflight := app.New(os.Stdout)
+	if modeOverride, err := resolveModeOverride(cmd); err != nil {
+		return err
+	} else if modeOverride != nil {
+		preflight = preflight.WithMode(*modeOverride)
+	}
+	impact, err := preflight.PlanAgainstRef(ctx, planConfigPath, planTarget, base, head)
+	if err != nil {
+		return fmt.Errorf("plan failed: %w", err)
+	}
+	preflight.PrintRefImpact(impact)
+	return nil
+}
@@ -6,9 +6,11 @@ import (
 	"io"
 	"testing"
 
+	"github.com/felixgeelhaar/preflight/internal/app"
 	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
 	"github.com/felixgeelhaar/preflight/internal/domain/config"
 	"github.com/felixgeelhaar/preflight/internal/domain/execution"
+	"github.com/felixgeelhaar/preflight/internal/provider/files"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -113,6 +115,16 @@ func overrideNewPlanPreflight(client *fakePlanPreflightClient) func() {
 	return func() { newPlanPreflight = prev }
 }
 
+func TestRunPlan_AgainstRefNeedsBase(t *testing.T) {
+	reset := setPlanFlags(t, "preflight.yaml", "default")
+	defer reset()
+	planAgainstRef = "..feature"
+	defer func() { planAgainstRef = "" }()
+
+	err := runPlan(&cobra.Command{}, nil)
+	require.ErrorContains(t, err, `--against-ref needs a base ref, got "..feature"`)
+}
+
 func setPlanFlags(t *testing.T, config, target string) func() {
 	t.Helper()
 	prevConfig := planConfigPath
@@ -168,6 +180,10 @@ func (f *fakePlanPreflightClient) UpdateLockFromPlan(context.Context, string, *e
 	return nil
 }
 
+func (f *fakePlanPreflightClient) RecordMachineState(context.Context, string, string, *execution.Plan) error {
+	return nil
+}
+
 func (f *fakePlanPreflightClient) WithMode(mode config.ReproducibilityMode) preflightClient {
 	f.modeOverride = &mode
 	return f
@@ -176,3 +192,19 @@ func (f *fakePlanPreflightClient) WithMode(mode config.ReproducibilityMode) pref
 func (f *fakePlanPreflightClient) WithRollbackOnFailure(bool) preflightClient {
 	return f
 }
+
+func (f *fakePlanPreflightClient) WithRunLockWait(_ bool) preflightClient {
+	return f
+}
+
+func (f *fakePlanPreflightClient) WithMergePreference(_ files.MergePreference) preflightClient {
+	return f
+}
+
+func (f *fakePlanPreflightClient) WithProgress(execution.StepStartObserver, execution.StepObserver) preflightClient {
+	return f
+}
+
+func (f *fakePlanPreflightClient) LastTranscript() *app.Transcript {
+	return nil
+}
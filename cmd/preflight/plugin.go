@@ -10,6 +10,7 @@ import (
 
 	"github.com/felixgeelhaar/preflight/internal/domain/audit"
 	"github.com/felixgeelhaar/preflight/internal/domain/plugin"
+	"github.com/felixgeelhaar/preflight/internal/offline"
 	"github.com/spf13/cobra"
 	"golang.org/x/mod/semver"
 )
@@ -44,11 +45,16 @@ var pluginListCmd = &cobra.Command{
 var pluginInstallCmd = &cobra.Command{
 	Use:   "install <source>",
 	Short: "Install a plugin",
-	Long: `Install a plugin from a local path or Git repository.
+	Long: `Install a plugin from a local path, Git repository or release archive.
+
+Release archives (.tar.gz or .tgz URLs) must be pinned with --checksum; the
+archive is only extracted after its SHA256 hash matches.
 
 Examples:
   preflight plugin install /path/to/plugin
-  preflight plugin install https://github.com/example/preflight-docker.git`,
+  preflight plugin install https://github.com/example/preflight-docker.git
+  preflight plugin install https://example.com/preflight-docker-1.0.0.tar.gz \
+    --checksum sha256:<hex>`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(_ *cobra.Command, args []string) error {
 		return runPluginInstall(args[0])
@@ -90,6 +96,8 @@ var (
 
 	provisionVars    []string
 	provisionWorkDir string
+
+	pluginInstallChecksum string
 )
 
 var pluginProvisionCmd = &cobra.Command{
@@ -197,6 +205,9 @@ func init() {
 	pluginCmd.AddCommand(pluginUpgradeCmd)
 	pluginCmd.AddCommand(pluginProvisionCmd)
 
+	// Install flags
+	pluginInstallCmd.Flags().StringVar(&pluginInstallChecksum, "checksum", "", "SHA256 checksum of a plugin archive (sha256:<hex>), required for archives")
+
 	// Provision flags
 	pluginProvisionCmd.Flags().StringSliceVar(&provisionVars, "var", nil, "Variables to pass to the provisioner (key=value)")
 	pluginProvisionCmd.Flags().StringVar(&provisionWorkDir, "work-dir", ".", "Working directory for the provisioner")
@@ -279,22 +290,36 @@ func runPluginInstall(source string) error {
 			}
 		}
 		_ = auditSvc.LogPluginInstalled(ctx, p.Manifest.Name, source, p.Manifest.Version, caps)
+		if err := promptPluginGrants(ctx, p, auditSvc); err != nil {
+			return fmt.Errorf("recording permissions: %w", err)
+		}
 
 		// For now, just validate - actual installation would copy to install path
 		fmt.Printf("✓ Plugin validated: %s@%s\n", p.Manifest.Name, p.Manifest.Version)
 		fmt.Println("")
-		fmt.Println("Note: Full installation (copying to ~/.preflight/plugins) not yet implemented.")
+		fmt.Println("Note: Full installation (copying to the preflight plugins directory) not yet implemented.")
 		fmt.Printf("      The plugin at %s can be used directly.\n", source)
 		return nil
 	}
 
-	// Git URL
-	p, err := loader.LoadFromGit(source, "latest")
-	if err != nil {
-		return fmt.Errorf("installing from git: %w", err)
+	if offline.Enabled() {
+		return offline.Skipped("installing from " + source)
 	}
 
-	// Log plugin installation from Git
+	var p *plugin.Plugin
+	if plugin.IsArchiveSource(source) {
+		p, err = loader.LoadFromArchive(ctx, source, pluginInstallChecksum)
+		if err != nil {
+			return fmt.Errorf("installing from archive: %w", err)
+		}
+	} else {
+		p, err = loader.LoadFromGit(source, "latest")
+		if err != nil {
+			return fmt.Errorf("installing from git: %w", err)
+		}
+	}
+
+	// Log plugin installation from Git or archive
 	var caps []string
 	if p.Manifest.WASM != nil {
 		for _, c := range p.Manifest.WASM.Capabilities {
@@ -302,6 +327,9 @@ func runPluginInstall(source string) error {
 		}
 	}
 	_ = auditSvc.LogPluginInstalled(ctx, p.Manifest.Name, source, p.Manifest.Version, caps)
+	if err := promptPluginGrants(ctx, p, auditSvc); err != nil {
+		return fmt.Errorf("recording permissions: %w", err)
+	}
 
 	return nil
 }
@@ -445,6 +473,11 @@ func runPluginSearch(query string) error {
 		return fmt.Errorf("invalid type %q: must be 'config' or 'provider'", searchType)
 	}
 
+	if offline.Enabled() {
+		fmt.Println("Plugin search skipped (offline).")
+		return nil
+	}
+
 	// Create searcher and search
 	searcher := plugin.NewSearcher()
 	results, err := searcher.Search(ctx, opts)
@@ -768,6 +801,11 @@ func runPluginUpgrade(name string) error {
 		}
 	}
 
+	if offline.Enabled() {
+		fmt.Println("Plugin upgrade check skipped (offline).")
+		return nil
+	}
+
 	checker := plugin.NewUpgradeChecker(registry)
 
 	// Check or upgrade specific plugin
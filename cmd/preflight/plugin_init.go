@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/plugin"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pluginInitType        string
+	pluginInitName        string
+	pluginInitAuthor      string
+	pluginInitLicense     string
+	pluginInitDescription string
+)
+
+var pluginInitCmd = &cobra.Command{
+	Use:   "init [dir]",
+	Short: "Create a new plugin from a template",
+	Long: `Create a new plugin directory with a manifest, example code, tests and a README.
+
+Plugin types:
+  provider  WASM provider plugin with example plan/apply/capture hooks (Go)
+  preset    Config plugin contributing presets
+
+The directory defaults to ./<name> and must be empty.
+
+Examples:
+  preflight plugin init --type provider --name docker
+  preflight plugin init --type preset --name team-go ./plugins/team-go`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		dir := pluginInitName
+		if len(args) > 0 {
+			dir = args[0]
+		}
+		return runPluginInit(dir)
+	},
+}
+
+func init() {
+	pluginCmd.AddCommand(pluginInitCmd)
+
+	pluginInitCmd.Flags().StringVar(&pluginInitType, "type", plugin.ScaffoldProvider, "Plugin type: provider, preset")
+	pluginInitCmd.Flags().StringVar(&pluginInitName, "name", "", "Plugin name (required)")
+	pluginInitCmd.Flags().StringVar(&pluginInitAuthor, "author", "", "Plugin author (default: git user.name)")
+	pluginInitCmd.Flags().StringVar(&pluginInitLicense, "license", "MIT", "Plugin license")
+	pluginInitCmd.Flags().StringVar(&pluginInitDescription, "description", "", "Plugin description")
+	_ = pluginInitCmd.MarkFlagRequired("name")
+}
+
+func runPluginInit(dir string) error {
+	author := pluginInitAuthor
+	if author == "" {
+		author = gitUserName()
+	}
+	if author == "" {
+		return fmt.Errorf("could not determine author; pass --author")
+	}
+
+	files, err := plugin.Scaffold(dir, plugin.ScaffoldOptions{
+		Kind:        pluginInitType,
+		Name:        pluginInitName,
+		Description: pluginInitDescription,
+		Author:      author,
+		License:     pluginInitLicense,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Created %s plugin %s in %s\n", pluginInitType, pluginInitName, dir)
+	for _, f := range files {
+		fmt.Printf("  %s\n", filepath.Join(dir, f))
+	}
+	fmt.Println("")
+	fmt.Println("Next steps:")
+	fmt.Printf("  cd %s\n", dir)
+	if pluginInitType == plugin.ScaffoldProvider {
+		fmt.Println("  make test build")
+	}
+	fmt.Println("  preflight plugin validate .")
+	fmt.Println("  preflight plugin install .")
+	return nil
+}
+
+// gitUserName returns the configured git user name, or "" if unavailable.
+func gitUserName() string {
+	out, err := exec.Command("git", "config", "--get", "user.name").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
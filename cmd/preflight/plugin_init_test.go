@@ -0,0 +1,47 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunPluginInit_PassesValidation(t *testing.T) {
+	oldType, oldName, oldAuthor := pluginInitType, pluginInitName, pluginInitAuthor
+	oldJSON, oldStrict := pluginValidateJSON, pluginValidateStrict
+	defer func() {
+		pluginInitType, pluginInitName, pluginInitAuthor = oldType, oldName, oldAuthor
+		pluginValidateJSON, pluginValidateStrict = oldJSON, oldStrict
+	}()
+
+	for _, kind := range []string{"provider", "preset"} {
+		dir := filepath.Join(t.TempDir(), "demo")
+		pluginInitType, pluginInitName, pluginInitAuthor = kind, "demo", "Jane Doe"
+
+		output := capturePluginStdout(t, func() {
+			require.NoError(t, runPluginInit(dir))
+		})
+		assert.Contains(t, output, "Created "+kind+" plugin demo")
+
+		pluginValidateJSON, pluginValidateStrict = false, false
+		output = capturePluginStdout(t, func() {
+			require.NoError(t, runPluginValidate(dir))
+		})
+		assert.Contains(t, output, "Plugin validated: demo@0.1.0")
+		// Signing is the only thing left for strict mode.
+		assert.Contains(t, output, "plugin is not signed")
+		assert.NotContains(t, output, "missing")
+	}
+}
+
+func TestRunPluginInit_InvalidType(t *testing.T) {
+	oldType, oldName, oldAuthor := pluginInitType, pluginInitName, pluginInitAuthor
+	defer func() { pluginInitType, pluginInitName, pluginInitAuthor = oldType, oldName, oldAuthor }()
+
+	pluginInitType, pluginInitName, pluginInitAuthor = "theme", "demo", "Jane Doe"
+	err := runPluginInit(t.TempDir())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported plugin type")
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/felixgeelhaar/preflight/internal/domain/plugin"
+	"github.com/spf13/cobra"
+)
+
+var pluginPermissionsJSON bool
+
+var pluginPermissionsCmd = &cobra.Command{
+	Use:   "permissions [name]",
+	Short: "Show and manage plugin capability grants",
+	Long: `Show the capabilities installed plugins request and whether they were granted.
+
+Command execution, network access and file writes need your consent. You are
+asked when a plugin is installed or first used; decisions are stored per
+plugin version in plugin-grants.json in the preflight config directory
+(~/.config/preflight) and every grant or denial
+is recorded in the audit log.
+
+Examples:
+  preflight plugin permissions
+  preflight plugin permissions docker
+  preflight plugin permissions grant docker shell:execute
+  preflight plugin permissions deny docker net:http
+  preflight plugin permissions reset docker`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		name := ""
+		if len(args) == 1 {
+			name = args[0]
+		}
+		return runPluginPermissions(name)
+	},
+}
+
+var pluginPermissionsGrantCmd = &cobra.Command{
+	Use:   "grant <name> [capability...]",
+	Short: "Grant capabilities to a plugin",
+	Long:  `Grant capabilities to the installed version of a plugin. Without capabilities, all requested capabilities are granted.`,
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		return runPluginPermissionsDecide(args[0], args[1:], true)
+	},
+}
+
+var pluginPermissionsDenyCmd = &cobra.Command{
+	Use:   "deny <name> [capability...]",
+	Short: "Deny capabilities to a plugin",
+	Long:  `Deny capabilities to the installed version of a plugin. Without capabilities, all requested capabilities are denied.`,
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		return runPluginPermissionsDecide(args[0], args[1:], false)
+	},
+}
+
+var pluginPermissionsResetCmd = &cobra.Command{
+	Use:   "reset <name>",
+	Short: "Forget capability decisions for a plugin",
+	Long:  `Remove stored grants and denials for all versions of a plugin. You will be asked again on next use.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		return runPluginPermissionsReset(args[0])
+	},
+}
+
+func init() {
+	pluginCmd.AddCommand(pluginPermissionsCmd)
+	pluginPermissionsCmd.AddCommand(pluginPermissionsGrantCmd)
+	pluginPermissionsCmd.AddCommand(pluginPermissionsDenyCmd)
+	pluginPermissionsCmd.AddCommand(pluginPermissionsResetCmd)
+
+	pluginPermissionsCmd.Flags().BoolVar(&pluginPermissionsJSON, "json", false, "Output permissions as JSON")
+}
+
+// pluginPermission is one requested capability and its status.
+type pluginPermission struct {
+	Plugin        string `json:"plugin"`
+	Version       string `json:"version"`
+	Capability    string `json:"capability"`
+	Status        string `json:"status"`
+	Justification string `json:"justification,omitempty"`
+}
+
+// Permission statuses.
+const (
+	permissionGranted   = "granted"
+	permissionDenied    = "denied"
+	permissionPending   = "pending"
+	permissionAutomatic = "automatic"
+)
+
+func newPluginGrantService() (*plugin.GrantService, func()) {
+	auditSvc := getPluginAuditService()
+	svc := plugin.NewGrantService(plugin.NewGrantStore(plugin.DefaultGrantsPath()), auditSvc)
+	return svc, func() { _ = auditSvc.Close() }
+}
+
+func runPluginPermissions(name string) error {
+	plugins, err := discoverWASMPlugins(name)
+	if err != nil {
+		return err
+	}
+
+	svc, closeAudit := newPluginGrantService()
+	defer closeAudit()
+
+	var perms []pluginPermission
+	for _, p := range plugins {
+		grant, err := svc.Current(p)
+		if err != nil {
+			return err
+		}
+		for _, c := range p.Manifest.WASM.Capabilities {
+			status := permissionAutomatic
+			if plugin.RequiresConsent(c.Name) {
+				granted, decided := grant.Decision(c.Name)
+				switch {
+				case granted:
+					status = permissionGranted
+				case decided:
+					status = permissionDenied
+				default:
+					status = permissionPending
+				}
+			}
+			perms = append(perms, pluginPermission{
+				Plugin:        p.ID(),
+				Version:       p.Manifest.Version,
+				Capability:    c.Name,
+				Status:        status,
+				Justification: c.Justification,
+			})
+		}
+	}
+
+	if pluginPermissionsJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if perms == nil {
+			perms = []pluginPermission{}
+		}
+		return enc.Encode(perms)
+	}
+
+	if len(perms) == 0 {
+		fmt.Println("No installed plugins request capabilities.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "PLUGIN\tVERSION\tCAPABILITY\tSTATUS\tJUSTIFICATION")
+	for _, perm := range perms {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", perm.Plugin, perm.Version, perm.Capability, perm.Status, perm.Justification)
+	}
+	return w.Flush()
+}
+
+func runPluginPermissionsDecide(name string, capabilities []string, grant bool) error {
+	plugins, err := discoverWASMPlugins(name)
+	if err != nil {
+		return err
+	}
+	p := plugins[0]
+
+	if len(plugin.Requested(p)) == 0 {
+		fmt.Printf("%s does not request any capabilities that need consent.\n", p)
+		return nil
+	}
+
+	svc, closeAudit := newPluginGrantService()
+	defer closeAudit()
+
+	ctx := context.Background()
+	var result plugin.Grant
+	if grant {
+		result, err = svc.Grant(ctx, p, capabilities...)
+	} else {
+		result, err = svc.Deny(ctx, p, capabilities...)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(result.Granted) > 0 {
+		fmt.Printf("✓ %s granted: %v\n", p, result.Granted)
+	}
+	if len(result.Denied) > 0 {
+		fmt.Printf("✗ %s denied: %v\n", p, result.Denied)
+	}
+	return nil
+}
+
+func runPluginPermissionsReset(name string) error {
+	svc, closeAudit := newPluginGrantService()
+	defer closeAudit()
+
+	removed, err := svc.Reset(name)
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		fmt.Printf("No stored permissions for %s.\n", name)
+		return nil
+	}
+	fmt.Printf("✓ Reset permissions for %s; you will be asked again on next use.\n", name)
+	return nil
+}
+
+// discoverWASMPlugins returns installed plugins with a WASM module, or the
+// named plugin when name is set.
+func discoverWASMPlugins(name string) ([]*plugin.Plugin, error) {
+	result, err := plugin.NewLoader().Discover(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("discovering plugins: %w", err)
+	}
+
+	var plugins []*plugin.Plugin
+	for _, p := range result.Plugins {
+		if name != "" && p.ID() != name {
+			continue
+		}
+		if p.Manifest.WASM == nil {
+			if name != "" {
+				return []*plugin.Plugin{p}, nil
+			}
+			continue
+		}
+		plugins = append(plugins, p)
+	}
+	if name != "" && len(plugins) == 0 {
+		return nil, fmt.Errorf("plugin %q not found", name)
+	}
+	return plugins, nil
+}
+
+// promptPluginGrants asks for any capabilities the plugin still needs consent
+// for. --yes grants them without asking.
+func promptPluginGrants(ctx context.Context, p *plugin.Plugin, auditor plugin.GrantAuditor) error {
+	svc := plugin.NewGrantService(plugin.NewGrantStore(plugin.DefaultGrantsPath()), auditor)
+	pending, err := svc.Pending(p)
+	if err != nil || len(pending) == 0 {
+		return err
+	}
+
+	if yesFlag {
+		_, err := svc.Grant(ctx, p)
+		return err
+	}
+
+	fmt.Printf("%s requests capabilities that need your approval:\n", p)
+	_, err = svc.Resolve(ctx, p, app.NewConsentPrompter(os.Stdin, os.Stdout))
+	return err
+}
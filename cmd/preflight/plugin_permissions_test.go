@@ -0,0 +1,91 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePermissionsPlugin(t *testing.T, home string) {
+	t.Helper()
+	dir := filepath.Join(home, ".preflight", "plugins", "docker")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	manifest := `apiVersion: v1
+name: docker
+version: 1.0.0
+type: provider
+provides:
+  providers:
+    - name: docker
+      configKey: docker
+wasm:
+  module: plugin.wasm
+  checksum: 0000000000000000000000000000000000000000000000000000000000000000
+  capabilities:
+    - name: files:read
+      justification: Read compose files
+    - name: shell:execute
+      justification: Run docker
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "plugin.yaml"), []byte(manifest), 0o600))
+}
+
+func TestPluginPermissionsCmd(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "permissions [name]", pluginPermissionsCmd.Use)
+	names := make([]string, 0, len(pluginPermissionsCmd.Commands()))
+	for _, c := range pluginPermissionsCmd.Commands() {
+		names = append(names, c.Name())
+	}
+	assert.ElementsMatch(t, []string{"grant", "deny", "reset"}, names)
+}
+
+func TestRunPluginPermissions_GrantAndReset(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	writePermissionsPlugin(t, home)
+
+	output := capturePluginStdout(t, func() {
+		require.NoError(t, runPluginPermissions(""))
+	})
+	assert.Contains(t, output, "files:read")
+	assert.Contains(t, output, "automatic")
+	assert.Contains(t, output, "pending")
+
+	output = capturePluginStdout(t, func() {
+		require.NoError(t, runPluginPermissionsDecide("docker", []string{"shell:execute"}, true))
+	})
+	assert.Contains(t, output, "docker@1.0.0 granted: [shell:execute]")
+
+	grant, found, err := plugin.NewGrantStore(plugin.DefaultGrantsPath()).Get("docker", "1.0.0")
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.True(t, grant.IsGranted("shell:execute"))
+
+	output = capturePluginStdout(t, func() {
+		require.NoError(t, runPluginPermissionsReset("docker"))
+	})
+	assert.Contains(t, output, "Reset permissions for docker")
+}
+
+func TestRunPluginPermissionsDecide_UnknownCapability(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	writePermissionsPlugin(t, home)
+
+	err := runPluginPermissionsDecide("docker", []string{"net:http"}, true)
+	require.ErrorIs(t, err, plugin.ErrUnknownCapability)
+}
+
+func TestRunPluginPermissions_NotFound(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	err := runPluginPermissions("missing")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
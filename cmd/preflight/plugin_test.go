@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/felixgeelhaar/preflight/internal/domain/plugin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -146,6 +147,15 @@ func TestRunPluginInstall_InvalidPath(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestRunPluginInstall_ArchiveRequiresChecksum(t *testing.T) {
+	oldChecksum := pluginInstallChecksum
+	defer func() { pluginInstallChecksum = oldChecksum }()
+	pluginInstallChecksum = ""
+
+	err := runPluginInstall("https://example.com/preflight-docker-1.0.0.tar.gz")
+	require.ErrorIs(t, err, plugin.ErrArchiveChecksumRequired)
+}
+
 func TestRunPluginRemove_NotFound(t *testing.T) {
 	// t.Setenv automatically restores the original value after the test
 	tmpDir := t.TempDir()
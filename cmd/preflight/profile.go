@@ -9,7 +9,15 @@ import (
 	"strings"
 	"text/tabwriter"
 
+	"github.com/felixgeelhaar/preflight/internal/adapters/command"
+	"github.com/felixgeelhaar/preflight/internal/adapters/filesystem"
 	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
+	"github.com/felixgeelhaar/preflight/internal/domain/profile"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/provider/runtime"
+	"github.com/felixgeelhaar/preflight/internal/provider/vscode"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 )
@@ -29,14 +37,18 @@ This is useful when:
   - Temporarily using a different set of tools
 
 The full 'apply' command does a complete sync. Profile switching only
-updates fast-changing settings like environment variables and git config.
+updates fast-changing settings like environment variables, git config and
+the tool shims of runtime.shims. When the target sets vscode.profile.switch,
+VS Code is opened with the target's profile.
 
 Examples:
   preflight profile list              # Show available profiles
   preflight profile current           # Show active profile
   preflight profile switch work       # Switch to work profile
   preflight profile switch personal   # Switch to personal profile
-  preflight profile create meeting    # Create new profile from current`,
+  preflight profile create meeting    # Create new profile from current
+  preflight profile create work --from work --ssid CorpWiFi --vpn
+  preflight profile auto              # Switch by network/location triggers`,
 	RunE: runProfileList,
 }
 
@@ -77,6 +89,10 @@ var (
 	profileConfigPath string
 	profileJSON       bool
 	profileFromTarget string
+	profileSSIDs      []string
+	profileVPN        bool
+	profilePaths      []string
+	profileFallback   bool
 )
 
 func init() {
@@ -90,6 +106,10 @@ func init() {
 	profileCmd.PersistentFlags().StringVarP(&profileConfigPath, "config", "c", "preflight.yaml", "Path to preflight.yaml")
 	profileCmd.PersistentFlags().BoolVar(&profileJSON, "json", false, "Output as JSON")
 	profileCreateCmd.Flags().StringVar(&profileFromTarget, "from", "", "Create from specific target")
+	profileCreateCmd.Flags().StringSliceVar(&profileSSIDs, "ssid", nil, "Activate automatically on this Wi-Fi network (repeatable)")
+	profileCreateCmd.Flags().BoolVar(&profileVPN, "vpn", false, "Activate automatically while a VPN is connected")
+	profileCreateCmd.Flags().StringSliceVar(&profilePaths, "dir", nil, "Activate automatically while this path exists (repeatable)")
+	profileCreateCmd.Flags().BoolVar(&profileFallback, "fallback", false, "Activate automatically when no other trigger matches")
 }
 
 // ProfileInfo represents profile metadata
@@ -99,6 +119,9 @@ type ProfileInfo struct {
 	Description string `json:"description,omitempty"`
 	Active      bool   `json:"active"`
 	LastUsed    string `json:"last_used,omitempty"`
+	// Triggers activate the profile automatically; see 'profile auto'.
+	Triggers []profile.Trigger `json:"triggers,omitempty"`
+	Fallback bool              `json:"fallback,omitempty"`
 }
 
 func runProfileList(_ *cobra.Command, _ []string) error {
@@ -173,24 +196,20 @@ func runProfileCurrent(_ *cobra.Command, _ []string) error {
 }
 
 func runProfileSwitch(_ *cobra.Command, args []string) error {
-	profileName := args[0]
-	ctx := context.Background()
+	return switchProfile(context.Background(), profileConfigPath, args[0])
+}
+
+// switchProfile applies the fast-changing settings of a profile's target
+// and records it as the current profile.
+func switchProfile(ctx context.Context, configPath, profileName string) error {
 	preflight := app.New(os.Stdout)
 
-	// Determine target for profile
-	target := profileName
-	customProfiles, _ := loadCustomProfiles()
-	for _, p := range customProfiles {
-		if p.Name == profileName {
-			target = p.Target
-			break
-		}
-	}
+	target := profileTarget(profileName)
 
 	fmt.Printf("Switching to profile: %s (target: %s)\n\n", profileName, target)
 
 	// Load configuration for target
-	config, err := preflight.LoadMergedConfig(ctx, profileConfigPath, target)
+	config, err := preflight.LoadMergedConfig(ctx, configPath, target)
 	if err != nil {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
@@ -199,11 +218,11 @@ func runProfileSwitch(_ *cobra.Command, args []string) error {
 	fmt.Println("Applying profile settings...")
 
 	// Update environment variables
-	vars := extractEnvVars(config)
-	if err := WriteEnvFile(vars); err != nil {
+	env := profileEnvFromConfig(profileName, config)
+	if err := writeProfileEnv(profileEnvDir(), env); err != nil {
 		fmt.Printf("Warning: failed to write env file: %v\n", err)
 	} else {
-		fmt.Printf("  Updated %d environment variable(s)\n", len(vars))
+		fmt.Printf("  Updated %d environment variable(s)\n", len(env.Vars))
 	}
 
 	// Update git config
@@ -215,14 +234,28 @@ func runProfileSwitch(_ *cobra.Command, args []string) error {
 		}
 	}
 
+	// Update tool shims
+	if updated, err := updateShims(ctx, config); err != nil {
+		fmt.Printf("Warning: failed to update tool shims: %v\n", err)
+	} else if updated {
+		fmt.Println("  Updated tool shims")
+	}
+
+	// Open VS Code with the target's profile
+	if name, err := switchVSCodeProfile(ctx, command.NewRealRunner(), config, target); err != nil {
+		fmt.Printf("Warning: failed to switch VS Code profile: %v\n", err)
+	} else if name != "" {
+		fmt.Printf("  Opened VS Code with profile %s\n", name)
+	}
+
 	// Save current profile
 	if err := setCurrentProfile(profileName); err != nil {
 		fmt.Printf("Warning: failed to save profile state: %v\n", err)
 	}
 
 	fmt.Printf("\nSwitched to profile: %s\n", profileName)
-	fmt.Println("\nNote: Shell environment changes require reloading your shell or running:")
-	fmt.Println("  source ~/.preflight/env.sh")
+	fmt.Println("\nShells with the preflight hook pick up the change at the next prompt.")
+	fmt.Println("To install it, add 'eval \"$(preflight hook zsh)\"' to ~/.zshrc (see 'preflight hook --help').")
 
 	return nil
 }
@@ -244,8 +277,10 @@ func runProfileCreate(_ *cobra.Command, args []string) error {
 	}
 
 	profiles = append(profiles, ProfileInfo{
-		Name:   name,
-		Target: target,
+		Name:     name,
+		Target:   target,
+		Triggers: profileTriggersFromFlags(),
+		Fallback: profileFallback,
 	})
 
 	if err := saveCustomProfiles(profiles); err != nil {
@@ -284,8 +319,56 @@ func runProfileDelete(_ *cobra.Command, args []string) error {
 }
 
 func getProfileDir() string {
-	home, _ := os.UserHomeDir()
-	return filepath.Join(home, ".preflight", "profiles")
+	dir, _ := platform.Path(platform.ConfigDir, "profiles")
+	return dir
+}
+
+// updateShims points the tool shims at the versions of a profile's target,
+// and removes them when the target does not enable shims.
+func updateShims(ctx context.Context, config map[string]interface{}) (bool, error) {
+	section, _ := config["runtime"].(map[string]interface{})
+	cfg, err := runtime.ParseConfig(section)
+	if err != nil {
+		return false, err
+	}
+	step := runtime.NewShimStep(cfg, filesystem.NewRealFileSystem())
+	runCtx := compiler.NewRunContext(ctx)
+	status, err := step.Check(runCtx)
+	if err != nil || status == compiler.StatusSatisfied {
+		return false, err
+	}
+	return true, step.Apply(runCtx)
+}
+
+// switchVSCodeProfile opens VS Code with the profile of a target when
+// vscode.profile.switch is set, and returns the profile name.
+func switchVSCodeProfile(ctx context.Context, runner ports.CommandRunner, config map[string]interface{}, target string) (string, error) {
+	section, _ := config["vscode"].(map[string]interface{})
+	cfg, err := vscode.ParseConfig(section)
+	if err != nil || cfg.Profile == nil || !cfg.Profile.Switch {
+		return "", err
+	}
+	name := cfg.Profile.NameFor(target)
+	result, err := runner.Run(ctx, "code", "--profile", name)
+	if err != nil {
+		return "", err
+	}
+	if !result.Success() {
+		return "", fmt.Errorf("code --profile %s: %s", name, strings.TrimSpace(result.Stderr))
+	}
+	return name, nil
+}
+
+// profileTarget returns the target of a custom profile, or the name itself
+// for a profile that is just a target.
+func profileTarget(profileName string) string {
+	customProfiles, _ := loadCustomProfiles()
+	for _, p := range customProfiles {
+		if p.Name == profileName {
+			return p.Target
+		}
+	}
+	return profileName
 }
 
 func getCurrentProfile() string {
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/adapters/command"
+	"github.com/felixgeelhaar/preflight/internal/adapters/logging"
+	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/felixgeelhaar/preflight/internal/domain/profile"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/spf13/cobra"
+)
+
+var profileAutoCmd = &cobra.Command{
+	Use:   "auto",
+	Short: "Switch profile based on network and location triggers",
+	Long: `Evaluate the triggers of custom profiles and switch to the first one
+that matches:
+
+  --ssid   the connected Wi-Fi network
+  --vpn    a VPN connection is up
+  --dir    a file or directory exists (e.g. a mounted corporate share)
+
+A profile created with --fallback is used when nothing else matches. The
+agent runs this check periodically and notifies you when it switches.
+
+Examples:
+  preflight profile create work --from work --ssid CorpWiFi --vpn
+  preflight profile create personal --from personal --fallback
+  preflight profile auto
+  preflight profile auto --dry-run`,
+	RunE: runProfileAuto,
+}
+
+var profileAutoDryRun bool
+
+// agentProfilePollInterval is how often the agent evaluates profile triggers.
+const agentProfilePollInterval = time.Minute
+
+// senseProfileEnvironment observes the current network; tests replace it.
+var senseProfileEnvironment = func(ctx context.Context) profile.Environment {
+	return app.NewProfileSensor(command.NewRealRunner()).Sense(ctx)
+}
+
+func init() {
+	profileCmd.AddCommand(profileAutoCmd)
+	profileAutoCmd.Flags().BoolVar(&profileAutoDryRun, "dry-run", false, "Show which profile would be activated")
+}
+
+func runProfileAuto(_ *cobra.Command, _ []string) error {
+	sel, switched, err := autoSwitchProfile(context.Background(), profileConfigPath, profileAutoDryRun)
+	if err != nil {
+		return err
+	}
+	switch {
+	case sel.Profile == "":
+		fmt.Println("No profile trigger matches; keeping the current profile.")
+	case !switched && sel.Profile == getCurrentProfile():
+		fmt.Printf("Profile %s is already active (%s).\n", sel.Profile, sel.Reason)
+	case !switched:
+		fmt.Printf("Would switch to profile %s (%s).\n", sel.Profile, sel.Reason)
+	}
+	return nil
+}
+
+// profileCandidates returns the custom profiles that can be activated
+// automatically, in the order they were created.
+func profileCandidates() []profile.Candidate {
+	profiles, _ := loadCustomProfiles()
+	candidates := make([]profile.Candidate, 0, len(profiles))
+	for _, p := range profiles {
+		if len(p.Triggers) == 0 && !p.Fallback {
+			continue
+		}
+		candidates = append(candidates, profile.Candidate{Name: p.Name, Triggers: p.Triggers, Fallback: p.Fallback})
+	}
+	return candidates
+}
+
+// autoSwitchProfile switches to the profile selected by the triggers unless
+// it is already active. It returns the selection, which is empty when no
+// trigger matches, and whether a switch happened.
+func autoSwitchProfile(ctx context.Context, configPath string, dryRun bool) (profile.Selection, bool, error) {
+	candidates := profileCandidates()
+	if len(candidates) == 0 {
+		return profile.Selection{}, false, nil
+	}
+
+	sel, ok := profile.Select(candidates, senseProfileEnvironment(ctx))
+	if !ok || sel.Profile == getCurrentProfile() || dryRun {
+		return sel, false, nil
+	}
+
+	if err := switchProfile(ctx, configPath, sel.Profile); err != nil {
+		return sel, false, err
+	}
+	if err := notifyUser("Preflight", fmt.Sprintf("Switched to profile %s (%s)", sel.Profile, sel.Reason)); err != nil {
+		logging.Default().Warn(ctx, "could not show notification", ports.F("error", err))
+	}
+	return sel, true, nil
+}
+
+// watchProfileTriggers re-evaluates profile triggers every interval until
+// ctx is done. It is used by the agent.
+func watchProfileTriggers(ctx context.Context, configPath string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, _, err := autoSwitchProfile(ctx, configPath, false); err != nil {
+			logging.Default().Warn(ctx, "automatic profile switch failed", ports.F("error", err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// profileTriggersFromFlags builds the triggers of 'profile create'. Each
+// flag value is its own trigger, so any of them activates the profile.
+func profileTriggersFromFlags() []profile.Trigger {
+	var triggers []profile.Trigger
+	for _, ssid := range profileSSIDs {
+		triggers = append(triggers, profile.Trigger{SSID: ssid})
+	}
+	if profileVPN {
+		triggers = append(triggers, profile.Trigger{VPN: true})
+	}
+	for _, path := range profilePaths {
+		triggers = append(triggers, profile.Trigger{Path: path})
+	}
+	return triggers
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/profile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupAutoProfiles(t *testing.T, env profile.Environment) (configPath string, notifications *[]string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	dir := t.TempDir()
+	configPath = filepath.Join(dir, "preflight.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("targets:\n  work: [base]\n  personal: [base]\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "layers"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "layers", "base.yaml"), []byte("name: base\n"), 0o644))
+
+	require.NoError(t, saveCustomProfiles([]ProfileInfo{
+		{Name: "home", Target: "personal", Fallback: true},
+		{Name: "office", Target: "work", Triggers: []profile.Trigger{{SSID: "CorpWiFi"}, {VPN: true}}},
+		{Name: "meeting", Target: "work"},
+	}))
+
+	prevSense, prevNotify := senseProfileEnvironment, notifyUser
+	t.Cleanup(func() { senseProfileEnvironment, notifyUser = prevSense, prevNotify })
+	senseProfileEnvironment = func(context.Context) profile.Environment { return env }
+	var sent []string
+	notifyUser = func(_, message string) error {
+		sent = append(sent, message)
+		return nil
+	}
+	return configPath, &sent
+}
+
+func TestAutoSwitchProfile(t *testing.T) {
+	configPath, notifications := setupAutoProfiles(t, profile.Environment{SSID: "CorpWiFi"})
+
+	var sel profile.Selection
+	var switched bool
+	var err error
+	captureStdout(t, func() {
+		sel, switched, err = autoSwitchProfile(context.Background(), configPath, false)
+	})
+	require.NoError(t, err)
+	assert.True(t, switched)
+	assert.Equal(t, "office", sel.Profile)
+	assert.Equal(t, "office", getCurrentProfile())
+	assert.Equal(t, []string{"Switched to profile office (ssid=CorpWiFi)"}, *notifications)
+
+	// Already active: no second switch or notification.
+	_, switched, err = autoSwitchProfile(context.Background(), configPath, false)
+	require.NoError(t, err)
+	assert.False(t, switched)
+	assert.Len(t, *notifications, 1)
+}
+
+func TestAutoSwitchProfile_FallbackAndDryRun(t *testing.T) {
+	configPath, notifications := setupAutoProfiles(t, profile.Environment{SSID: "Cafe"})
+
+	sel, switched, err := autoSwitchProfile(context.Background(), configPath, true)
+	require.NoError(t, err)
+	assert.False(t, switched)
+	assert.Equal(t, profile.Selection{Profile: "home", Reason: "fallback"}, sel)
+	assert.Empty(t, getCurrentProfile())
+	assert.Empty(t, *notifications)
+}
+
+func TestRunProfileCreate_WithTriggers(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	prevSSIDs, prevVPN, prevPaths, prevFallback, prevFrom := profileSSIDs, profileVPN, profilePaths, profileFallback, profileFromTarget
+	t.Cleanup(func() {
+		profileSSIDs, profileVPN, profilePaths, profileFallback, profileFromTarget = prevSSIDs, prevVPN, prevPaths, prevFallback, prevFrom
+	})
+	profileSSIDs = []string{"CorpWiFi", "Corp-Guest"}
+	profileVPN = true
+	profilePaths = []string{"~/work/.corp"}
+	profileFallback = false
+	profileFromTarget = "work"
+
+	captureStdout(t, func() {
+		require.NoError(t, runProfileCreate(nil, []string{"office"}))
+	})
+
+	loaded, err := loadCustomProfiles()
+	require.NoError(t, err)
+	require.Len(t, loaded, 1)
+	assert.Equal(t, []profile.Trigger{
+		{SSID: "CorpWiFi"},
+		{SSID: "Corp-Guest"},
+		{VPN: true},
+		{Path: "~/work/.corp"},
+	}, loaded[0].Triggers)
+}
+
+func TestAppleScriptQuote(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, `"say \"hi\" \\ bye"`, appleScriptQuote(`say "hi" \ bye`))
+}
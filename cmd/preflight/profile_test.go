@@ -1,10 +1,13 @@
 package main
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/testutil/mocks"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -45,9 +48,10 @@ func TestProfileInfo_InactiveDefaults(t *testing.T) {
 func TestGetProfileDir_UsesHome(t *testing.T) {
 	tmpDir := t.TempDir()
 	t.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_CONFIG_HOME", "")
 
 	dir := getProfileDir()
-	assert.Equal(t, filepath.Join(tmpDir, ".preflight", "profiles"), dir)
+	assert.Equal(t, filepath.Join(tmpDir, ".config", "preflight", "profiles"), dir)
 }
 
 func TestSetAndGetCurrentProfile_RoundTrip(t *testing.T) {
@@ -90,7 +94,7 @@ func TestSetCurrentProfile_CreatesDirectory(t *testing.T) {
 	require.NoError(t, err)
 
 	// Verify the directory was created
-	profileDir := filepath.Join(tmpDir, ".preflight", "profiles")
+	profileDir := getProfileDir()
 	info, err := os.Stat(profileDir)
 	require.NoError(t, err)
 	assert.True(t, info.IsDir())
@@ -160,7 +164,7 @@ func TestSaveCustomProfiles_CreatesDirectory(t *testing.T) {
 	require.NoError(t, err)
 
 	// Verify file exists
-	profilePath := filepath.Join(tmpDir, ".preflight", "profiles", "profiles.yaml")
+	profilePath := filepath.Join(getProfileDir(), "profiles.yaml")
 	_, err = os.Stat(profilePath)
 	require.NoError(t, err)
 
@@ -536,3 +540,24 @@ func TestRunProfileCurrent_JSONOutput(t *testing.T) {
 	assert.Contains(t, output, `"profile"`)
 	assert.Contains(t, output, `"json-profile"`)
 }
+
+func TestSwitchVSCodeProfile(t *testing.T) {
+	t.Parallel()
+
+	runner := mocks.NewCommandRunner()
+	runner.AddResult("code", []string{"--profile", "work"}, ports.CommandResult{ExitCode: 0})
+
+	name, err := switchVSCodeProfile(context.Background(), runner, map[string]interface{}{}, "work")
+	require.NoError(t, err)
+	assert.Empty(t, name, "nothing happens without vscode.profile.switch")
+
+	config := map[string]interface{}{
+		"vscode": map[string]interface{}{
+			"profile": map[string]interface{}{"export": true, "switch": true},
+		},
+	}
+	name, err = switchVSCodeProfile(context.Background(), runner, config, "work")
+	require.NoError(t, err)
+	assert.Equal(t, "work", name)
+	assert.Len(t, runner.Calls(), 1)
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/spf13/cobra"
+)
+
+var projectCmd = &cobra.Command{
+	Use:   "project",
+	Short: "Install and check the tools a repository declares",
+	Long: `Install and check the tools a repository declares in .preflight/project.yaml.
+
+A project config lists the packages and runtime versions contributors need:
+
+  name: api
+  packages:
+    brew:
+      formulae: [protobuf, golangci-lint]
+  runtime:
+    backend: mise
+    tools:
+      - name: golang
+        version: "1.23.0"
+
+It is applied on its own: your preflight.yaml, targets and layers are not
+loaded or changed. Runtime versions go to the .tool-versions file at the
+project root. The config is found in the current directory or the nearest
+parent that has one.
+
+Examples:
+  preflight project apply             # Install the project's tools
+  preflight project apply --dry-run   # Show what would change
+  preflight project doctor            # Check this machine against the project`,
+}
+
+var projectApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Install the tools the project declares",
+	RunE:  runProjectApply,
+}
+
+var projectDoctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check this machine against the project requirements",
+	Long: `Check this machine against the requirements of the project.
+
+Exit codes:
+  0 - The machine meets the project requirements
+  1 - Issues found
+  3 - Checks could not run`,
+	RunE: runProjectDoctor,
+}
+
+var (
+	projectDir    string
+	projectDryRun bool
+)
+
+func init() {
+	projectCmd.PersistentFlags().StringVarP(&projectDir, "dir", "C", ".", "Directory to look for the project config from")
+	projectApplyCmd.Flags().BoolVar(&projectDryRun, "dry-run", false, "Show the plan without applying it")
+
+	projectCmd.AddCommand(projectApplyCmd, projectDoctorCmd)
+	rootCmd.AddCommand(projectCmd)
+}
+
+func runProjectApply(_ *cobra.Command, _ []string) error {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	project, err := loadProject(projectDir)
+	if err != nil {
+		return err
+	}
+
+	preflight := app.New(os.Stdout)
+	plan, err := preflight.PlanProject(ctx, project)
+	if err != nil {
+		return err
+	}
+	preflight.PrintPlan(plan)
+	if !plan.HasChanges() {
+		return nil
+	}
+	if projectDryRun {
+		fmt.Println("\n[Dry run - no changes made]")
+		return nil
+	}
+
+	if app.RequiresBootstrapConfirmation(plan) && !confirmBootstrap(app.BootstrapSteps(plan)) {
+		return &config.UserError{
+			Code:       config.ErrCodeBootstrapDeclined,
+			Message:    "bootstrap steps declined; nothing was applied",
+			Suggestion: "Re-run 'preflight project apply' and confirm the bootstrap prompt.",
+		}
+	}
+
+	fmt.Println("\nApplying changes...")
+	results, err := preflight.Apply(ctx, plan, false)
+	preflight.PrintResults(results)
+	if failedIDs := failedStepIDs(results); err != nil || len(failedIDs) > 0 {
+		return newApplyFailedUserError("project apply", failedIDs, err)
+	}
+	return nil
+}
+
+func runProjectDoctor(_ *cobra.Command, _ []string) error {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	project, err := loadProject(projectDir)
+	if err != nil {
+		return err
+	}
+
+	opts := app.NewDoctorOptions(filepath.Join(project.Root, config.ProjectFile), "project")
+	report, err := app.New(os.Stdout).DoctorProject(ctx, project, opts)
+	if err != nil {
+		return fmt.Errorf("doctor check failed: %w", err)
+	}
+	printDoctorPlain(report, "preflight project apply")
+	if report.HasIssues() {
+		return withExitCode(exitDrift, nil)
+	}
+	return nil
+}
+
+// loadProject finds the project config from dir and changes to the project
+// root, where project-scoped files are written.
+func loadProject(dir string) (*config.Project, error) {
+	project, err := config.LoadProject(dir)
+	if errors.Is(err, config.ErrProjectNotFound) {
+		return nil, &config.UserError{
+			Code:       config.ErrCodeConfigNotFound,
+			Message:    fmt.Sprintf("no %s found in %s or its parents", config.ProjectFile, dir),
+			Suggestion: "Run the command inside a repository that declares its tools, or pass its directory with --dir.",
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chdir(project.Root); err != nil {
+		return nil, fmt.Errorf("failed to enter project root: %w", err)
+	}
+	return project, nil
+}
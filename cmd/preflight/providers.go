@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/spf13/cobra"
+)
+
+var providersCmd = &cobra.Command{
+	Use:   "providers",
+	Short: "Show the health of each provider",
+	Long: `Providers lists every registered provider, builtin and plugin, with:
+
+  - whether the tool it drives is installed, and its version
+  - how many steps it compiles for the target
+  - when an apply last changed something through it
+  - when it was last captured from
+  - hints for fixing a missing tool or a configuration error
+
+Examples:
+  preflight providers             # Health of every provider
+  preflight providers -t work     # Against the work target
+  preflight providers --json      # Machine-readable output`,
+	RunE: runProviders,
+}
+
+var (
+	providersTarget string
+	providersJSON   bool
+)
+
+func init() {
+	providersCmd.Flags().StringVarP(&providersTarget, "target", "t", "default", "Target to check against")
+	providersCmd.Flags().BoolVar(&providersJSON, "json", false, "Output in JSON format")
+
+	rootCmd.AddCommand(providersCmd)
+}
+
+func runProviders(_ *cobra.Command, _ []string) error {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	configPath := cfgFile
+	if configPath == "" {
+		configPath = "preflight.yaml"
+	}
+
+	statuses, err := app.New(os.Stdout).ProviderStatuses(ctx, configPath, providersTarget, exec.LookPath, providerToolOutput)
+	if err != nil {
+		return err
+	}
+	history, err := loadHistory()
+	if err != nil {
+		return fmt.Errorf("failed to load history: %w", err)
+	}
+	addProviderActivity(statuses, history)
+
+	if providersJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(statuses)
+	}
+	printProviderStatuses(statuses)
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		fmt.Printf("\nNo configuration at %s, so step counts are not shown.\n", configPath)
+	}
+	return nil
+}
+
+// providerToolOutput runs a version command. Some tools print their
+// version on stderr, so both streams are returned.
+func providerToolOutput(ctx context.Context, name string, args ...string) (string, error) {
+	out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	return string(out), err
+}
+
+// addProviderActivity sets when each provider last changed something in a
+// successful apply and when it was last captured from.
+func addProviderActivity(statuses []app.ProviderStatus, history []HistoryEntry) {
+	lastApply := make(map[string]time.Time)
+	lastCapture := make(map[string]time.Time)
+	for _, entry := range history {
+		var last map[string]time.Time
+		switch {
+		case entry.Command == "apply" && entry.Status != "failed":
+			last = lastApply
+		case entry.Command == "capture":
+			last = lastCapture
+		default:
+			continue
+		}
+		for _, change := range entry.Changes {
+			if entry.Timestamp.After(last[change.Provider]) {
+				last[change.Provider] = entry.Timestamp
+			}
+		}
+	}
+
+	for i := range statuses {
+		if t, ok := lastApply[statuses[i].Name]; ok {
+			statuses[i].LastApply = &t
+		}
+		if t, ok := lastCapture[statuses[i].Name]; ok {
+			statuses[i].LastCapture = &t
+		}
+	}
+}
+
+func printProviderStatuses(statuses []app.ProviderStatus) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "PROVIDER\tTOOL\tVERSION\tSTEPS\tLAST APPLY\tLAST CAPTURE")
+	var hints []string
+	for _, s := range statuses {
+		tool := "builtin"
+		switch {
+		case s.Plugin:
+			tool = "plugin"
+		case s.Tool != "" && s.Available:
+			tool = "✓ " + s.Tool
+		case s.Tool != "":
+			tool = "✗ " + s.Tool
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n",
+			s.Name, tool, orDash(s.Version), s.Steps, activityAge(s.LastApply), activityAge(s.LastCapture))
+		for _, hint := range s.Hints {
+			hints = append(hints, fmt.Sprintf("  %s: %s", s.Name, hint))
+		}
+	}
+	_ = w.Flush()
+
+	if len(hints) > 0 {
+		fmt.Println("\nHints:")
+		for _, hint := range hints {
+			fmt.Println(hint)
+		}
+	}
+}
+
+func activityAge(t *time.Time) string {
+	if t == nil {
+		return "never"
+	}
+	return formatAge(*t)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddProviderActivity(t *testing.T) {
+	t.Parallel()
+
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 9, 0, 0, 0, time.UTC) }
+	history := []HistoryEntry{
+		{Command: "apply", Status: "success", Timestamp: day(1), Changes: []Change{{Provider: "brew"}, {Provider: "git"}}},
+		{Command: "apply", Status: "partial", Timestamp: day(3), Changes: []Change{{Provider: "brew"}}},
+		{Command: "apply", Status: "failed", Timestamp: day(5), Changes: []Change{{Provider: "git"}}},
+		{Command: "upgrade --canary", Status: "success", Timestamp: day(6), Changes: []Change{{Provider: "brew"}}},
+		{Command: "capture", Status: "success", Timestamp: day(2), Changes: []Change{{Provider: "brew"}}},
+	}
+	statuses := []app.ProviderStatus{{Name: "brew"}, {Name: "git"}, {Name: "npm"}}
+
+	addProviderActivity(statuses, history)
+
+	require.NotNil(t, statuses[0].LastApply)
+	assert.Equal(t, day(3), *statuses[0].LastApply)
+	require.NotNil(t, statuses[0].LastCapture)
+	assert.Equal(t, day(2), *statuses[0].LastCapture)
+	require.NotNil(t, statuses[1].LastApply)
+	assert.Equal(t, day(1), *statuses[1].LastApply, "failed applies do not count")
+	assert.Nil(t, statuses[1].LastCapture)
+	assert.Nil(t, statuses[2].LastApply)
+}
+
+func TestNewCaptureHistoryEntry(t *testing.T) {
+	t.Parallel()
+
+	capturedAt := time.Now()
+	entry := newCaptureHistoryEntry("work", capturedAt, []app.CapturedItem{
+		{Provider: "brew", Name: "jq"},
+		{Provider: "brew-cask", Name: "firefox"},
+		{Provider: "git", Name: "user.name"},
+	})
+
+	assert.Equal(t, "capture", entry.Command)
+	assert.Equal(t, "work", entry.Target)
+	assert.Equal(t, capturedAt, entry.Timestamp)
+	assert.Equal(t, []Change{
+		{Provider: "brew", Action: "capture", Item: "2 captured"},
+		{Provider: "git", Action: "capture", Item: "1 captured"},
+	}, entry.Changes)
+}
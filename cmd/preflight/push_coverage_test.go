@@ -20,6 +20,7 @@ import (
 	"github.com/felixgeelhaar/preflight/internal/domain/security"
 	"github.com/felixgeelhaar/preflight/internal/domain/snapshot"
 	"github.com/felixgeelhaar/preflight/internal/domain/sync"
+	"github.com/felixgeelhaar/preflight/internal/provider/files"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -75,6 +76,10 @@ func (m *pcMockPreflightClient) UpdateLockFromPlan(_ context.Context, _ string,
 	return m.updateLockErr
 }
 
+func (f *pcMockPreflightClient) RecordMachineState(context.Context, string, string, *execution.Plan) error {
+	return nil
+}
+
 func (m *pcMockPreflightClient) WithMode(_ config.ReproducibilityMode) preflightClient {
 	return m
 }
@@ -83,6 +88,22 @@ func (m *pcMockPreflightClient) WithRollbackOnFailure(_ bool) preflightClient {
 	return m
 }
 
+func (m *pcMockPreflightClient) WithRunLockWait(_ bool) preflightClient {
+	return m
+}
+
+func (m *pcMockPreflightClient) WithMergePreference(_ files.MergePreference) preflightClient {
+	return m
+}
+
+func (m *pcMockPreflightClient) WithProgress(execution.StepStartObserver, execution.StepObserver) preflightClient {
+	return m
+}
+
+func (m *pcMockPreflightClient) LastTranscript() *app.Transcript {
+	return nil
+}
+
 // ---------------------------------------------------------------------------
 // 1. sync_conflicts.go -- relationString
 // ---------------------------------------------------------------------------
@@ -714,17 +735,18 @@ func TestPushCov_FormatAge(t *testing.T) {
 }
 
 // ---------------------------------------------------------------------------
-// 21. catalog.go -- runCatalogVerify signatures not supported
+// 21. catalog.go -- runCatalogVerify signatures without a trusted root
 // ---------------------------------------------------------------------------
 
-func TestPushCov_RunCatalogVerify_SignaturesNotSupported(t *testing.T) { //nolint:tparallel
+func TestPushCov_RunCatalogVerify_SignaturesNoTrustedRoot(t *testing.T) { //nolint:tparallel
+	t.Setenv("HOME", t.TempDir())
 	old := catalogVerifySigs
 	defer func() { catalogVerifySigs = old }()
 	catalogVerifySigs = true
 
 	err := runCatalogVerify(nil, nil)
 	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "--signatures is not supported yet")
+	assert.Contains(t, err.Error(), "no Sigstore trusted root")
 }
 
 // ---------------------------------------------------------------------------
@@ -1533,38 +1555,29 @@ func TestPushCov_Resolve1Password_InvalidKeyFormat(t *testing.T) {
 	assert.Error(t, err)
 }
 
-// ---------------------------------------------------------------------------
-// 56. catalog.go -- verifyCatalogSignatures (always returns no signature)
-// ---------------------------------------------------------------------------
-
-func TestPushCov_VerifyCatalogSignatures(t *testing.T) {
-	t.Parallel()
-	result := verifyCatalogSignatures(nil, nil)
-	assert.False(t, result.hasSignature)
-	assert.False(t, result.verified)
-}
-
 // ---------------------------------------------------------------------------
 // 57. env.go -- WriteEnvFile
 // ---------------------------------------------------------------------------
 
 func TestPushCov_WriteEnvFile(t *testing.T) {
-	t.Parallel()
 	vars := []EnvVar{
 		{Name: "EDITOR", Value: "nvim"},
 		{Name: "TOKEN", Value: "secret://vault/key", Secret: true},
 		{Name: "GOPATH", Value: "/home/user/go"},
 	}
 
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("PREFLIGHT_HOME", "")
+	t.Setenv("XDG_STATE_HOME", "")
+
 	err := WriteEnvFile(vars)
-	// This writes to ~/.preflight/env.sh -- may fail in CI but exercises the code
+	// This writes to the state directory -- may fail in CI but exercises the code
 	if err != nil {
 		t.Skipf("WriteEnvFile failed (expected in CI): %v", err)
 	}
 
 	// Read back the file
-	home, _ := os.UserHomeDir()
-	data, err := os.ReadFile(filepath.Join(home, ".preflight", "env.sh"))
+	data, err := os.ReadFile(filepath.Join(profileEnvDir(), "env.sh"))
 	require.NoError(t, err)
 	s := string(data)
 	assert.Contains(t, s, "EDITOR")
@@ -2924,13 +2937,14 @@ func TestPushCov_RunWatch_MissingConfig(t *testing.T) { //nolint:tparallel
 // ---------------------------------------------------------------------------
 
 func TestPushCov_RunCatalogVerify_SigsFlag(t *testing.T) { //nolint:tparallel
+	t.Setenv("HOME", t.TempDir())
 	oldSigs := catalogVerifySigs
 	defer func() { catalogVerifySigs = oldSigs }()
 	catalogVerifySigs = true
 
 	err := runCatalogVerify(nil, nil)
 	require.Error(t, err)
-	assert.Contains(t, err.Error(), "--signatures is not supported")
+	assert.Contains(t, err.Error(), "no Sigstore trusted root")
 }
 
 // ---------------------------------------------------------------------------
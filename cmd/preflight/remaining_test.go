@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/felixgeelhaar/preflight/internal/domain/advisor"
+	"github.com/felixgeelhaar/preflight/internal/domain/advisor/ollama"
 	"github.com/felixgeelhaar/preflight/internal/domain/security"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -401,3 +402,23 @@ func TestRunRepoClone_InvalidURL(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "clone failed")
 }
+
+func TestGetProviderByName_Ollama(t *testing.T) {
+	t.Setenv("PREFLIGHT_AI_ENDPOINT", "")
+	t.Setenv("OLLAMA_HOST", "127.0.0.1:11500")
+	t.Setenv("PREFLIGHT_AI_MODEL", "qwen2.5-coder")
+	result, ok := getProviderByName("ollama").(*ollama.Provider)
+	require.True(t, ok)
+	assert.Equal(t, "http://127.0.0.1:11500", result.Endpoint())
+	assert.Equal(t, "qwen2.5-coder", result.Model())
+}
+
+func TestDetectAIProvider_LocalEndpointWinsOverAPIKeys(t *testing.T) {
+	t.Setenv("PREFLIGHT_AI_ENDPOINT", "http://localhost:1234/v1")
+	t.Setenv("ANTHROPIC_API_KEY", "sk-ant-test-key")
+	t.Setenv("PREFLIGHT_AI_MODEL", "")
+	result, ok := detectAIProvider().(*ollama.Provider)
+	require.True(t, ok)
+	assert.Equal(t, "http://localhost:1234/v1", result.Endpoint())
+	assert.Equal(t, ollama.DefaultModel, result.Model())
+}
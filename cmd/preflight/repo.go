@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 
 	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/felixgeelhaar/preflight/internal/offline"
 	"github.com/spf13/cobra"
 )
 
@@ -143,6 +144,9 @@ func runRepoInit(_ *cobra.Command, _ []string) error {
 
 	// Handle GitHub repository creation
 	if repoGitHub {
+		if offline.Enabled() {
+			return offline.Skipped("creating the GitHub repository")
+		}
 		name := repoName
 		if name == "" {
 			name = filepath.Base(configDir)
@@ -201,6 +205,9 @@ func runRepoStatus(_ *cobra.Command, _ []string) error {
 }
 
 func runRepoPush(_ *cobra.Command, _ []string) error {
+	if offline.Enabled() {
+		return offline.Skipped("git push")
+	}
 	configDir := getConfigDir()
 	if configDir == "" || configDir == "." {
 		cwd, err := os.Getwd()
@@ -230,6 +237,9 @@ func runRepoPush(_ *cobra.Command, _ []string) error {
 }
 
 func runRepoPull(_ *cobra.Command, _ []string) error {
+	if offline.Enabled() {
+		return offline.Skipped("git pull")
+	}
 	configDir := getConfigDir()
 	if configDir == "" || configDir == "." {
 		cwd, err := os.Getwd()
@@ -255,6 +265,9 @@ func runRepoPull(_ *cobra.Command, _ []string) error {
 
 func runRepoClone(cmd *cobra.Command, args []string) error {
 	url := args[0]
+	if offline.Enabled() {
+		return offline.Skipped("cloning " + url)
+	}
 	path := ""
 	if len(args) > 1 {
 		path = args[1]
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/felixgeelhaar/preflight/internal/domain/snapshot"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/spf13/cobra"
+)
+
+var restoreFileCmd = &cobra.Command{
+	Use:   "restore-file <path>",
+	Short: "Restore a file preflight overwrote from its backup",
+	Long: `Restore a file as it was before preflight first overwrote it.
+
+When an apply changes a file that exists but was not managed by preflight
+yet, such as a dotfile from before preflight was adopted, it keeps a copy
+in ~/.local/state/preflight/backups. Unlike snapshots, these backups are never pruned.
+
+The newest backup is restored unless --from names another one. The file
+as it is now is snapshotted first, and preflight stops tracking it, so the
+next apply backs it up again before overwriting it.
+
+Examples:
+  preflight restore-file ~/.zshrc --list
+  preflight restore-file ~/.zshrc
+  preflight restore-file ~/.gitconfig --from 20261016T091500Z --yes`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRestoreFile,
+}
+
+var (
+	restoreFileList bool
+	restoreFileFrom string
+)
+
+// backupStore returns the file backup store in the default location.
+var backupStore = app.DefaultBackupStore
+
+func init() {
+	restoreFileCmd.Flags().BoolVar(&restoreFileList, "list", false, "List the backups of the file")
+	restoreFileCmd.Flags().StringVar(&restoreFileFrom, "from", "", "Restore the backup taken at this time (as listed)")
+
+	rootCmd.AddCommand(restoreFileCmd)
+}
+
+func runRestoreFile(_ *cobra.Command, args []string) error {
+	path, err := filepath.Abs(ports.ExpandPath(args[0]))
+	if err != nil {
+		return fmt.Errorf("invalid path: %w", err)
+	}
+	store, err := backupStore()
+	if err != nil {
+		return err
+	}
+
+	if restoreFileList {
+		backups, err := store.List(path)
+		if err != nil {
+			return err
+		}
+		if len(backups) == 0 {
+			fmt.Printf("No backups of %s.\n", path)
+			return nil
+		}
+		fmt.Printf("%-17s %-17s %s\n", "TAKEN", "CREATED", "BACKUP")
+		for _, backup := range backups {
+			fmt.Printf("%-17s %-17s %s\n", backup.Taken(), backup.CreatedAt.Local().Format("2006-01-02 15:04"), backup.Backup)
+		}
+		return nil
+	}
+
+	backup, err := store.Find(path, restoreFileFrom)
+	if err != nil {
+		return err
+	}
+
+	if !yesFlag {
+		fmt.Printf("Restore %s from the backup taken %s? [y/N] ", path, backup.CreatedAt.Local().Format("2006-01-02 15:04"))
+		var response string
+		_, _ = fmt.Scanln(&response)
+		response = strings.ToLower(strings.TrimSpace(response))
+		if response != "y" && response != "yes" {
+			fmt.Println("Restore cancelled.")
+			return nil
+		}
+	}
+
+	ctx := context.Background()
+	lifecycle, err := app.DefaultLifecycleManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize lifecycle: %w", err)
+	}
+	if _, err := os.Stat(path); err == nil {
+		if _, err := lifecycle.Snapshot().Create(ctx, snapshot.ReasonRollback, []string{path}); err != nil {
+			return fmt.Errorf("failed to snapshot %s before restoring: %w", path, err)
+		}
+	}
+	if err := store.Restore(backup); err != nil {
+		return err
+	}
+	if err := lifecycle.Drift().RemoveTracking(ctx, path); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not stop tracking %s: %v\n", path, err)
+	}
+
+	fmt.Printf("✓ Restored %s from the backup taken %s\n", path, backup.CreatedAt.Local().Format("2006-01-02 15:04"))
+	return nil
+}
+
+// printFileBackups lists the files an apply backed up before overwriting.
+func printFileBackups(transcript *app.Transcript) {
+	if transcript == nil || len(transcript.Backups) == 0 {
+		return
+	}
+	fmt.Printf("\nBacked up %d file(s) preflight did not manage before overwriting them:\n", len(transcript.Backups))
+	for _, backup := range transcript.Backups {
+		fmt.Printf("  %s\n", backup.Path)
+	}
+	fmt.Println("Restore one with 'preflight restore-file <path>'.")
+}
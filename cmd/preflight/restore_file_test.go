@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunRestoreFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	store := app.NewBackupStore(filepath.Join(home, "backups"))
+	prevStore, prevYes, prevList, prevFrom := backupStore, yesFlag, restoreFileList, restoreFileFrom
+	t.Cleanup(func() {
+		backupStore, yesFlag, restoreFileList, restoreFileFrom = prevStore, prevYes, prevList, prevFrom
+	})
+	backupStore = func() (*app.BackupStore, error) { return store, nil }
+	yesFlag = true
+
+	path := filepath.Join(home, ".zshrc")
+	require.Error(t, runRestoreFile(nil, []string{path}), "no backup yet")
+
+	require.NoError(t, os.WriteFile(path, []byte("export EDITOR=vim\n"), 0o644))
+	backup, err := store.Save(path, time.Date(2026, 10, 16, 9, 15, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, []byte("managed\n"), 0o644))
+
+	restoreFileList = true
+	output := captureStdout(t, func() {
+		require.NoError(t, runRestoreFile(nil, []string{"~/.zshrc"}))
+	})
+	assert.Contains(t, output, backup.Taken())
+
+	restoreFileList = false
+	restoreFileFrom = "20250101T000000Z"
+	require.Error(t, runRestoreFile(nil, []string{path}), "unknown backup")
+
+	restoreFileFrom = backup.Taken()
+	output = captureStdout(t, func() {
+		require.NoError(t, runRestoreFile(nil, []string{path}))
+	})
+	assert.Contains(t, output, "✓ Restored "+path)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "export EDITOR=vim\n", string(data))
+}
+
+func TestPrintFileBackups(t *testing.T) {
+	output := captureStdout(t, func() {
+		printFileBackups(nil)
+		printFileBackups(&app.Transcript{})
+	})
+	assert.Empty(t, output)
+
+	output = captureStdout(t, func() {
+		printFileBackups(&app.Transcript{Backups: []app.FileBackup{{Path: "/home/me/.zshrc"}}})
+	})
+	assert.Contains(t, output, "/home/me/.zshrc")
+	assert.Contains(t, output, "preflight restore-file <path>")
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 
 	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/offline"
 	"github.com/spf13/cobra"
 )
 
@@ -19,6 +20,7 @@ var (
 	mode               string
 	yesFlag            bool
 	allowBootstrapFlag bool
+	offlineFlag        bool
 )
 
 var rootCmd = &cobra.Command{
@@ -36,6 +38,8 @@ explainable local setup using the compiler model:
 // Execute runs the root command.
 func Execute() error {
 	organizeCommandGroups(rootCmd)
+	migrateLegacyDirs(os.Stderr)
+	defer closeLogging()
 	return rootCmd.Execute()
 }
 
@@ -61,31 +65,54 @@ var coreCommands = map[string]struct{}{
 }
 
 var inspectCommands = map[string]struct{}{
-	"diff":     {},
-	"validate": {},
-	"compare":  {},
-	"history":  {},
-	"outdated": {},
-	"audit":    {},
-	"discover": {},
-	"explain":  {},
-	"env":      {},
-	"analyze":  {},
-	"watch":    {},
-	"feedback": {},
+	"diff":      {},
+	"graph":     {},
+	"validate":  {},
+	"ci":        {},
+	"compare":   {},
+	"history":   {},
+	"machines":  {},
+	"outdated":  {},
+	"audit":     {},
+	"discover":  {},
+	"explain":   {},
+	"env":       {},
+	"analyze":   {},
+	"watch":     {},
+	"feedback":  {},
+	"errors":    {},
+	"ai":        {},
+	"providers": {},
 }
 
 var configCommands = map[string]struct{}{
-	"catalog":  {},
-	"lock":     {},
-	"profile":  {},
-	"repo":     {},
-	"rollback": {},
-	"clean":    {},
-	"cleanup":  {},
-	"export":   {},
-	"tour":     {},
-	"secrets":  {},
+	"catalog":      {},
+	"layer":        {},
+	"targets":      {},
+	"lock":         {},
+	"profile":      {},
+	"repo":         {},
+	"rollback":     {},
+	"undo":         {},
+	"restore-file": {},
+	"snapshot":     {},
+	"clean":        {},
+	"cleanup":      {},
+	"upgrade":      {},
+	"pin":          {},
+	"export":       {},
+	"hook":         {},
+	"exec":         {},
+	"project":      {},
+	"onboard":      {},
+	"tour":         {},
+	"secrets":      {},
+	"secure-state": {},
+	"schema":       {},
+	"fmt":          {},
+	"patches":      {},
+	"ask":          {},
+	"paths":        {},
 }
 
 // enterpriseCommands are advanced / enterprise features hidden from default
@@ -139,13 +166,14 @@ func isIn(name string, set map[string]struct{}) bool {
 
 func init() {
 	// Global flags
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default: preflight.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file or directory (default: the nearest preflight.yaml)")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
 	rootCmd.PersistentFlags().BoolVar(&noAI, "no-ai", false, "disable AI features")
 	rootCmd.PersistentFlags().StringVar(&aiProvider, "ai-provider", "", "AI provider (openai, anthropic, ollama)")
 	rootCmd.PersistentFlags().StringVar(&mode, "mode", "intent", "reproducibility mode (intent, locked, frozen)")
 	rootCmd.PersistentFlags().BoolVarP(&yesFlag, "yes", "y", false, "auto-confirm all prompts")
 	rootCmd.PersistentFlags().BoolVar(&allowBootstrapFlag, "allow-bootstrap", false, "allow package manager bootstrapping without extra prompt")
+	rootCmd.PersistentFlags().BoolVar(&offlineFlag, "offline", false, "skip everything that needs the network (or set "+offline.EnvVar+"=1)")
 
 	// Register flag completions
 	registerFlagCompletions()
@@ -169,6 +197,9 @@ func formatError(err error) string {
 		if verbose && userErr.Underlying != nil {
 			msg += fmt.Sprintf("\n\nTechnical details: %v", userErr.Underlying)
 		}
+		if id := userErr.ID(); id != "" {
+			msg += fmt.Sprintf("\n\nError code: %s (run 'preflight errors explain %s')", id, id)
+		}
 		return msg
 	}
 	return err.Error()
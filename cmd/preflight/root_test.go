@@ -10,6 +10,7 @@ import (
 
 	"github.com/felixgeelhaar/preflight/internal/domain/config"
 	"github.com/felixgeelhaar/preflight/internal/domain/discover"
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
 	"github.com/felixgeelhaar/preflight/internal/tui"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
@@ -68,6 +69,12 @@ func TestRootCommand_HasPersistentFlags(t *testing.T) {
 		require.NotNil(t, flag)
 		assert.Equal(t, "false", flag.DefValue)
 	})
+
+	t.Run("offline flag exists", func(t *testing.T) {
+		flag := flags.Lookup("offline")
+		require.NotNil(t, flag)
+		assert.Equal(t, "false", flag.DefValue)
+	})
 }
 
 func TestRootCommand_HasVersionSubcommand(t *testing.T) {
@@ -1459,7 +1466,10 @@ targets:
 // ============================================================
 
 func TestExecute(t *testing.T) {
-	// Test the Execute function directly
+	// Test the Execute function directly, away from the real preflight
+	// directories
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(platform.HomeEnvVar, "")
 	// Save original args
 	oldArgs := rootCmd.Args
 	defer func() {
@@ -1793,6 +1803,7 @@ files:
 
 // TestRunApply_WithFilesConfig_Execute tests actual apply execution path
 func TestRunApply_WithFilesConfig_Execute(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // apply records history
 	tmpDir := t.TempDir()
 
 	// Change to temp directory so relative paths work
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/felixgeelhaar/preflight/internal/domain/security"
+	"github.com/felixgeelhaar/preflight/internal/offline"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -702,6 +703,16 @@ func TestRunDiscover_FailsGracefully(t *testing.T) { //nolint:tparallel // modif
 	assert.Contains(t, output, "Analyzing popular dotfile repositories")
 }
 
+func TestRunDiscover_Offline(t *testing.T) {
+	t.Setenv(offline.EnvVar, "1")
+
+	output := captureStdout(t, func() {
+		require.NoError(t, runDiscover(&cobra.Command{}, nil))
+	})
+	assert.Contains(t, output, "GitHub discovery skipped (offline).")
+	assert.NotContains(t, output, "Analyzing popular dotfile repositories")
+}
+
 // ---------------------------------------------------------------------------
 // runMCP - verify command and flags exist (cannot test stdio blocking)
 // ---------------------------------------------------------------------------
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/spf13/cobra"
+)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Export the JSON Schema of the configuration files",
+	Long: `Export JSON Schemas for preflight.yaml and layer files.
+
+YAML language servers use them for completion and inline validation. The
+schemas are published at ` + config.SchemaBaseURL + `; files created by
+'preflight init' point at them with a comment on the first line:
+
+  # yaml-language-server: $schema=` + config.SchemaBaseURL + config.ManifestSchemaFile + `
+
+'preflight validate' checks configuration against the same schemas.`,
+}
+
+var schemaDumpCmd = &cobra.Command{
+	Use:   "dump",
+	Short: "Print a JSON Schema or write both to a directory",
+	Long: `Print the JSON Schema of preflight.yaml, or of layer files with --layer.
+With --output-dir, write both schemas to that directory instead.
+
+Examples:
+  preflight schema dump                          # preflight.yaml schema
+  preflight schema dump --layer                  # Layer file schema
+  preflight schema dump --output-dir .vscode     # Write both for local use`,
+	Args: cobra.NoArgs,
+	RunE: runSchemaDump,
+}
+
+var (
+	schemaDumpLayer     bool
+	schemaDumpOutputDir string
+)
+
+func init() {
+	schemaDumpCmd.Flags().BoolVar(&schemaDumpLayer, "layer", false, "Print the layer file schema")
+	schemaDumpCmd.Flags().StringVar(&schemaDumpOutputDir, "output-dir", "", "Write "+config.ManifestSchemaFile+" and "+config.LayerSchemaFile+" to this directory")
+
+	schemaCmd.AddCommand(schemaDumpCmd)
+	rootCmd.AddCommand(schemaCmd)
+}
+
+func runSchemaDump(_ *cobra.Command, _ []string) error {
+	if schemaDumpOutputDir == "" {
+		schema := config.ManifestSchema()
+		if schemaDumpLayer {
+			schema = config.LayerSchema()
+		}
+		data, err := marshalSchema(schema)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+
+	if err := os.MkdirAll(schemaDumpOutputDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	for _, s := range []struct {
+		file   string
+		schema *config.Schema
+	}{
+		{config.ManifestSchemaFile, config.ManifestSchema()},
+		{config.LayerSchemaFile, config.LayerSchema()},
+	} {
+		data, err := marshalSchema(s.schema)
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(schemaDumpOutputDir, s.file)
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		fmt.Printf("Wrote %s\n", path)
+	}
+	return nil
+}
+
+func marshalSchema(schema *config.Schema) ([]byte, error) {
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode schema: %w", err)
+	}
+	return append(data, '\n'), nil
+}
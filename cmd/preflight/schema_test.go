@@ -0,0 +1,42 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPublishedSchemasUpToDate keeps the schemas published with the website
+// in sync with the config types. Regenerate them with:
+//
+//	go run ./cmd/preflight schema dump --output-dir website/public/schema
+func TestPublishedSchemasUpToDate(t *testing.T) {
+	t.Parallel()
+
+	for file, schema := range map[string]*config.Schema{
+		config.ManifestSchemaFile: config.ManifestSchema(),
+		config.LayerSchemaFile:    config.LayerSchema(),
+	} {
+		want, err := marshalSchema(schema)
+		require.NoError(t, err)
+		got, err := os.ReadFile(filepath.Join("..", "..", "website", "public", "schema", file))
+		require.NoError(t, err)
+		assert.Equal(t, string(want), string(got), "%s is out of date", file)
+	}
+}
+
+func TestRunSchemaDump_OutputDir(t *testing.T) {
+	prev := schemaDumpOutputDir
+	t.Cleanup(func() { schemaDumpOutputDir = prev })
+	schemaDumpOutputDir = t.TempDir()
+
+	output := captureStdout(t, func() {
+		require.NoError(t, runSchemaDump(nil, nil))
+	})
+	assert.Contains(t, output, config.ManifestSchemaFile)
+	assert.FileExists(t, filepath.Join(schemaDumpOutputDir, config.LayerSchemaFile))
+}
@@ -10,6 +10,7 @@ import (
 	"strings"
 	"text/tabwriter"
 
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
 	"github.com/spf13/cobra"
 )
 
@@ -166,7 +167,7 @@ func runSecretsCheck(_ *cobra.Command, _ []string) error {
 	fmt.Printf("\nResults: %d passed, %d failed\n", passed, failed)
 
 	if failed > 0 {
-		os.Exit(1)
+		os.Exit(exitDrift)
 	}
 	return nil
 }
@@ -383,13 +384,16 @@ func setKeychainSecret(name, value string) error {
 }
 
 func resolveAge(key string) (string, error) {
-	home, _ := os.UserHomeDir()
-	keyPath := filepath.Join(home, ".preflight", "secrets", key+".age")
+	keyPath, err := platform.Path(platform.DataDir, "secrets", key+".age")
+	if err != nil {
+		return "", err
+	}
 
 	if _, err := os.Stat(keyPath); os.IsNotExist(err) {
 		return "", fmt.Errorf("age-encrypted secret not found")
 	}
 
+	home, _ := os.UserHomeDir()
 	identityPath := filepath.Join(home, ".age", "key.txt")
 	cmd := exec.Command("age", "-d", "-i", identityPath, keyPath)
 	output, err := cmd.Output()
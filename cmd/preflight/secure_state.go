@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/securestate"
+	"github.com/spf13/cobra"
+)
+
+var secureStateCmd = &cobra.Command{
+	Use:   "secure-state",
+	Short: "Encrypt local state at rest",
+	Long: `Encrypt local state at rest with a key kept in the OS keychain.
+
+History, pending patches, the drift state and the agent log can hold
+package lists, file paths, diffs and command output. With state encryption
+on, they are written as age files (https://age-encryption.org) to a key
+whose private half is kept in the macOS Keychain, the Linux secret service
+or the Windows Credential Manager. Every command reads them as before;
+'preflight agent logs' shows the agent log.
+
+Only the public key is stored in the preflight config directory, so the
+agent encrypts what it writes without asking the keychain. Reading asks
+the keychain once per command.
+
+Losing the key in the keychain makes the encrypted files unreadable.
+
+Examples:
+  preflight secure-state enable
+  preflight secure-state status
+  preflight secure-state disable`,
+}
+
+var secureStateEnableCmd = &cobra.Command{
+	Use:   "enable",
+	Short: "Turn state encryption on and encrypt existing state",
+	Args:  cobra.NoArgs,
+	RunE:  runSecureStateEnable,
+}
+
+var secureStateDisableCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "Decrypt state and turn state encryption off",
+	Long: `Decrypt the state files, then remove the key from the keychain and turn
+state encryption off.`,
+	Args: cobra.NoArgs,
+	RunE: runSecureStateDisable,
+}
+
+var secureStateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether state is encrypted",
+	Args:  cobra.NoArgs,
+	RunE:  runSecureStateStatus,
+}
+
+func init() {
+	secureStateCmd.AddCommand(secureStateEnableCmd)
+	secureStateCmd.AddCommand(secureStateDisableCmd)
+	secureStateCmd.AddCommand(secureStateStatusCmd)
+	rootCmd.AddCommand(secureStateCmd)
+}
+
+func runSecureStateEnable(_ *cobra.Command, _ []string) error {
+	wasEnabled := securestate.Enabled()
+	n, err := securestate.Enable(time.Now())
+	if errors.Is(err, ports.ErrKeychainUnavailable) {
+		return fmt.Errorf("state encryption needs the OS keychain: %w", err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encrypt state: %w", err)
+	}
+	if wasEnabled {
+		fmt.Printf("State encryption is already on. Encrypted %d remaining files.\n", n)
+		return nil
+	}
+	fmt.Println("State encryption is on.")
+	fmt.Printf("Encrypted %d files in history and pending patches.\n", n)
+	fmt.Println("The key is in the OS keychain; without it the encrypted files cannot be read.")
+	return nil
+}
+
+func runSecureStateDisable(_ *cobra.Command, _ []string) error {
+	if !securestate.Enabled() {
+		fmt.Println("State encryption is off.")
+		return nil
+	}
+	n, err := securestate.Disable()
+	if err != nil {
+		return fmt.Errorf("failed to decrypt state: %w", err)
+	}
+	fmt.Println("State encryption is off.")
+	fmt.Printf("Decrypted %d files and removed the key from the keychain.\n", n)
+	return nil
+}
+
+func runSecureStateStatus(_ *cobra.Command, _ []string) error {
+	settings, err := securestate.LoadSettings()
+	if err != nil {
+		return err
+	}
+	encrypted, plain, err := securestate.Count()
+	if err != nil {
+		return err
+	}
+
+	if settings == nil {
+		fmt.Println("State encryption: off")
+	} else {
+		fmt.Printf("State encryption: on (since %s)\n", settings.EnabledAt.Local().Format("2006-01-02"))
+		fmt.Printf("Key:              %s, private key in the OS keychain\n", settings.Recipient)
+	}
+	fmt.Printf("Files:            %d encrypted, %d in plain text\n", encrypted, plain)
+	if settings == nil && encrypted > 0 {
+		fmt.Println("\nSome files are still encrypted. Run 'preflight secure-state enable' and")
+		fmt.Println("then 'preflight secure-state disable' to decrypt them.")
+	}
+	return nil
+}
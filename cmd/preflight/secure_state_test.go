@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/adapters/age"
+	"github.com/felixgeelhaar/preflight/internal/adapters/keychain"
+	"github.com/felixgeelhaar/preflight/internal/adapters/logging"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/securestate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setSecureStateHome(t *testing.T) {
+	t.Helper()
+	t.Setenv("PREFLIGHT_HOME", t.TempDir())
+	kc := keychain.NewFileKeychain(filepath.Join(t.TempDir(), "keychain.json"))
+	old := securestate.Keychain
+	securestate.Keychain = func() ports.Keychain { return kc }
+	t.Cleanup(func() { securestate.Keychain = old })
+}
+
+func TestSecureState_History(t *testing.T) {
+	setSecureStateHome(t)
+	now := time.Now()
+	require.NoError(t, SaveHistoryEntry(HistoryEntry{ID: "1", Timestamp: now.Add(-time.Hour), Command: "apply", Status: "success"}))
+
+	output := captureStdout(t, func() {
+		require.NoError(t, runSecureStateEnable(secureStateEnableCmd, nil))
+	})
+	assert.Contains(t, output, "State encryption is on.")
+	assert.Contains(t, output, "Encrypted 1 files")
+
+	require.NoError(t, SaveHistoryEntry(HistoryEntry{
+		ID: "2", Timestamp: now, Command: "apply", Status: "failed",
+		Steps: []StepTranscript{{ID: "brew:formula:fd", Status: "failed", Stderr: "no bottle"}},
+	}))
+	for _, id := range []string{"1", "2"} {
+		data, err := os.ReadFile(filepath.Join(getHistoryDir(), id+".json"))
+		require.NoError(t, err)
+		assert.True(t, age.IsEncrypted(data), id)
+	}
+
+	entries, err := loadHistory()
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	// The compacted log and index are encrypted too
+	_, _, err = compactHistory(historyPolicy{}, now, false)
+	require.NoError(t, err)
+	for _, name := range []string{historyLogFile, historyIndexFile} {
+		data, err := os.ReadFile(filepath.Join(getHistoryDir(), name))
+		require.NoError(t, err)
+		assert.True(t, age.IsEncrypted(data), name)
+	}
+	entry, err := loadHistoryEntry("2")
+	require.NoError(t, err)
+	require.Len(t, entry.Steps, 1)
+	assert.Equal(t, "no bottle", entry.Steps[0].Stderr)
+
+	output = captureStdout(t, func() {
+		require.NoError(t, runSecureStateStatus(secureStateStatusCmd, nil))
+	})
+	assert.Contains(t, output, "State encryption: on")
+	assert.Contains(t, output, "2 encrypted, 0 in plain text")
+
+	output = captureStdout(t, func() {
+		require.NoError(t, runSecureStateDisable(secureStateDisableCmd, nil))
+	})
+	assert.Contains(t, output, "Decrypted 2 files")
+	data, err := os.ReadFile(filepath.Join(getHistoryDir(), historyIndexFile))
+	require.NoError(t, err)
+	assert.False(t, age.IsEncrypted(data))
+	entry, err = loadHistoryEntry("2")
+	require.NoError(t, err)
+	assert.Equal(t, "failed", entry.Status)
+}
+
+func TestSecureState_DisableWhenOff(t *testing.T) {
+	setSecureStateHome(t)
+
+	output := captureStdout(t, func() {
+		require.NoError(t, runSecureStateDisable(secureStateDisableCmd, nil))
+	})
+	assert.Equal(t, "State encryption is off.\n", output)
+}
+
+func TestSecureState_AgentLog(t *testing.T) {
+	setSecureStateHome(t)
+	prevLevel, prevFormat, prevFile := logLevel, logFormat, logFile
+	t.Cleanup(func() {
+		closeLogging()
+		logLevel, logFormat, logFile = prevLevel, prevFormat, prevFile
+		logging.SetDefault(logging.NewNopLogger())
+	})
+	captureStdout(t, func() {
+		require.NoError(t, runSecureStateEnable(secureStateEnableCmd, nil))
+	})
+
+	path, err := agentLogPath()
+	require.NoError(t, err)
+	logLevel, logFormat, logFile = "info", "text", path
+	require.NoError(t, setupLogging())
+	logging.Default().Info(context.Background(), "drift detected", ports.F("path", "/home/me/.zshrc"))
+	closeLogging()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, age.IsEncrypted(data))
+	assert.NotContains(t, string(data), ".zshrc")
+
+	output := captureStdout(t, func() {
+		require.NoError(t, runAgentLogs(agentLogsCmd, nil))
+	})
+	assert.Contains(t, output, "drift detected")
+	assert.Contains(t, output, "/home/me/.zshrc")
+}
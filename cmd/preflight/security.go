@@ -27,7 +27,7 @@ Scanners (in order of preference):
 Exit codes:
   0 - No vulnerabilities found (or below --fail-on threshold)
   1 - Vulnerabilities found above --fail-on threshold
-  2 - Scanner not available or scan failed
+  3 - Scanner not available or scan failed
 
 Examples:
   preflight security                      # Scan current directory
@@ -85,7 +85,7 @@ func runSecurity(_ *cobra.Command, _ []string) error {
 		} else {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		}
-		os.Exit(2)
+		os.Exit(exitInternal)
 	}
 
 	// Parse severity levels
@@ -115,7 +115,7 @@ func runSecurity(_ *cobra.Command, _ []string) error {
 		} else {
 			fmt.Fprintf(os.Stderr, "Scan failed: %v\n", err)
 		}
-		os.Exit(2)
+		os.Exit(exitInternal)
 	}
 
 	// Filter results by severity
@@ -135,7 +135,7 @@ func runSecurity(_ *cobra.Command, _ []string) error {
 
 	// Determine exit code
 	if shouldFail(result, failOnSeverity) {
-		os.Exit(1)
+		os.Exit(exitDrift)
 	}
 
 	return nil
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/spf13/cobra"
+)
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Inspect and clean up file snapshots",
+	Long: `Manage the snapshots preflight takes of files before changing them.
+
+Snapshot content is stored compressed and deduplicated across snapshot sets
+in ~/.local/state/preflight/snapshots. Use 'preflight rollback' to restore a set.`,
+}
+
+var snapshotListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List snapshot sets with their disk usage",
+	Long: `List snapshot sets, newest first, with the size of the files they hold,
+the compressed size of their content, and the space deleting them would free
+(content no other set shares).`,
+	RunE: runSnapshotList,
+}
+
+var snapshotGCCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Delete old snapshot sets and unreferenced content",
+	Long: `Garbage-collect the snapshot store: delete snapshot sets older than
+--older-than, then the snapshots and stored content nothing refers to.
+
+Examples:
+  preflight snapshot gc                          # Remove unreferenced content
+  preflight snapshot gc --older-than 30d         # Also delete sets older than 30 days
+  preflight snapshot gc --older-than 30d --dry-run`,
+	RunE: runSnapshotGC,
+}
+
+var (
+	snapshotListJSON  bool
+	snapshotOlderThan string
+	snapshotGCDryRun  bool
+)
+
+func init() {
+	snapshotListCmd.Flags().BoolVar(&snapshotListJSON, "json", false, "Output as JSON")
+	snapshotGCCmd.Flags().StringVar(&snapshotOlderThan, "older-than", "", "Delete snapshot sets older than this (e.g., 30d, 4w)")
+	snapshotGCCmd.Flags().BoolVar(&snapshotGCDryRun, "dry-run", false, "Show what would be deleted without deleting")
+
+	snapshotCmd.AddCommand(snapshotListCmd)
+	snapshotCmd.AddCommand(snapshotGCCmd)
+	rootCmd.AddCommand(snapshotCmd)
+}
+
+// snapshotService returns the snapshot service in the default location.
+var snapshotService = app.DefaultSnapshotService
+
+func runSnapshotList(_ *cobra.Command, _ []string) error {
+	svc, err := snapshotService()
+	if err != nil {
+		return fmt.Errorf("failed to initialize snapshot service: %w", err)
+	}
+	usage, err := svc.Usage(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to read snapshots: %w", err)
+	}
+
+	if snapshotListJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(usage)
+	}
+
+	if len(usage) == 0 {
+		fmt.Println("No snapshots available.")
+		return nil
+	}
+
+	fmt.Printf("%-10s %-17s %5s %10s %10s %10s  %s\n", "ID", "CREATED", "FILES", "SIZE", "STORED", "UNIQUE", "REASON")
+	var size int64
+	for _, u := range usage {
+		fmt.Printf("%-10s %-17s %5d %10s %10s %10s  %s\n",
+			shortMachineID(u.ID),
+			u.CreatedAt.Format("2006-01-02 15:04"),
+			u.Files,
+			formatByteSize(u.Size),
+			formatByteSize(u.Stored),
+			formatByteSize(u.Unique),
+			u.Reason,
+		)
+		size += u.Size
+	}
+	fmt.Printf("\n%d sets holding %s of files.\n", len(usage), formatByteSize(size))
+	fmt.Println("STORED is the compressed size of a set's content; UNIQUE is what deleting it would free.")
+	return nil
+}
+
+func runSnapshotGC(_ *cobra.Command, _ []string) error {
+	var olderThan time.Duration
+	if snapshotOlderThan != "" {
+		var err error
+		if olderThan, err = parseDuration(snapshotOlderThan); err != nil {
+			return fmt.Errorf("invalid --older-than: %w", err)
+		}
+	}
+
+	svc, err := snapshotService()
+	if err != nil {
+		return fmt.Errorf("failed to initialize snapshot service: %w", err)
+	}
+	result, err := svc.GC(context.Background(), olderThan, snapshotGCDryRun)
+	if err != nil {
+		return fmt.Errorf("failed to garbage-collect snapshots: %w", err)
+	}
+
+	if snapshotGCDryRun {
+		fmt.Printf("Would delete %d snapshot sets and %d snapshots.\n", result.Sets, result.Snapshots)
+		return nil
+	}
+	fmt.Printf("Deleted %d snapshot sets, %d snapshots and %d stored files; freed %s.\n",
+		result.Sets, result.Snapshots, result.Files, formatByteSize(result.Freed))
+	return nil
+}
+
+// formatByteSize returns a size in bytes in binary units.
+func formatByteSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
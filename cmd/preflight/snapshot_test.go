@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatByteSize(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "512 B", formatByteSize(512))
+	assert.Equal(t, "1.5 KiB", formatByteSize(1536))
+	assert.Equal(t, "2.0 MiB", formatByteSize(2*1024*1024))
+}
+
+func TestRunSnapshotListAndGC(t *testing.T) {
+	dir := t.TempDir()
+	svc := app.NewSnapshotService(dir)
+	prevSvc, prevOlder, prevDry := snapshotService, snapshotOlderThan, snapshotGCDryRun
+	t.Cleanup(func() { snapshotService, snapshotOlderThan, snapshotGCDryRun = prevSvc, prevOlder, prevDry })
+	snapshotService = func() (*app.SnapshotService, error) { return svc, nil }
+
+	file := filepath.Join(dir, "zshrc")
+	require.NoError(t, os.WriteFile(file, []byte("export EDITOR=nvim\n"), 0o644))
+	set, err := svc.BeforeApply(t.Context(), []string{file})
+	require.NoError(t, err)
+
+	output := captureStdout(t, func() {
+		require.NoError(t, runSnapshotList(nil, nil))
+	})
+	assert.Contains(t, output, set.ID[:8])
+	assert.Contains(t, output, "1 sets holding 19 B of files.")
+
+	snapshotOlderThan = "1d"
+	output = captureStdout(t, func() {
+		require.NoError(t, runSnapshotGC(nil, nil))
+	})
+	assert.Contains(t, output, "Deleted 0 snapshot sets, 0 snapshots and 0 stored files")
+
+	snapshotOlderThan = "soon"
+	assert.Error(t, runSnapshotGC(nil, nil))
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/domain/execution"
+)
+
+// Statuses of a run summary.
+const (
+	summaryInSync  = "in-sync"
+	summaryChanges = "changes"
+	summaryApplied = "applied"
+	summaryDrift   = "drift"
+	summaryFailed  = "failed"
+)
+
+// summaryMaxItems caps the lists of a markdown summary so that large plans
+// stay readable.
+const summaryMaxItems = 50
+
+// runSummary is the outcome of plan, apply or doctor, written by
+// --summary-file for CI reports such as $GITHUB_STEP_SUMMARY.
+type runSummary struct {
+	Command  string           `json:"command"`
+	Target   string           `json:"target"`
+	Status   string           `json:"status"`
+	Error    string           `json:"error,omitempty"`
+	Steps    *summarySteps    `json:"steps,omitempty"`
+	Drift    int              `json:"drift"`
+	Fixed    int              `json:"fixed,omitempty"`
+	Changes  []string         `json:"changes,omitempty"`
+	Failures []summaryFailure `json:"failures,omitempty"`
+	Issues   []summaryIssue   `json:"issues,omitempty"`
+}
+
+// summarySteps counts the steps of a plan and, after apply, their results.
+type summarySteps struct {
+	Total     int `json:"total"`
+	Changes   int `json:"changes"`
+	Satisfied int `json:"satisfied"`
+	Applied   int `json:"applied"`
+	Failed    int `json:"failed"`
+	Skipped   int `json:"skipped"`
+}
+
+type summaryFailure struct {
+	Step  string `json:"step"`
+	Error string `json:"error"`
+}
+
+type summaryIssue struct {
+	Severity string `json:"severity"`
+	Provider string `json:"provider,omitempty"`
+	Step     string `json:"step,omitempty"`
+	Message  string `json:"message"`
+}
+
+func newRunSummary(command, target string) *runSummary {
+	return &runSummary{Command: command, Target: target}
+}
+
+// setPlan records the steps of plan and the changes it would make.
+func (s *runSummary) setPlan(plan *execution.Plan) {
+	summary := plan.Summary()
+	s.Steps = &summarySteps{
+		Total:     summary.Total,
+		Changes:   summary.NeedsApply,
+		Satisfied: summary.Satisfied,
+	}
+	s.Drift = summary.NeedsApply
+	s.Changes = nil
+	for _, entry := range plan.NeedsApply() {
+		s.Changes = append(s.Changes, entry.Step().ID().String())
+	}
+	s.Status = summaryInSync
+	if plan.HasChanges() {
+		s.Status = summaryChanges
+	}
+}
+
+// setResults records the results of applying the plan.
+func (s *runSummary) setResults(results []execution.StepResult, err error) {
+	if s.Steps == nil {
+		s.Steps = &summarySteps{}
+	}
+	for i := range results {
+		switch {
+		case results[i].Error() != nil || results[i].Status() == compiler.StatusFailed:
+			s.Steps.Failed++
+			msg := "failed"
+			if results[i].Error() != nil {
+				msg = results[i].Error().Error()
+			}
+			s.Failures = append(s.Failures, summaryFailure{Step: results[i].StepID().String(), Error: msg})
+		case results[i].Status() == compiler.StatusSkipped:
+			s.Steps.Skipped++
+		case results[i].Status() == compiler.StatusSatisfied:
+			s.Steps.Applied++
+		}
+	}
+	s.Status = summaryApplied
+	if err != nil || s.Steps.Failed > 0 {
+		s.fail(err)
+	}
+}
+
+// setDoctor records the issues of a doctor report.
+func (s *runSummary) setDoctor(report *app.DoctorReport) {
+	s.Drift = report.IssueCount()
+	s.Issues = make([]summaryIssue, 0, len(report.Issues))
+	for _, issue := range report.Issues {
+		s.Issues = append(s.Issues, summaryIssue{
+			Severity: string(issue.Severity),
+			Provider: issue.Provider,
+			Step:     issue.StepID,
+			Message:  issue.Message,
+		})
+	}
+	s.Status = summaryInSync
+	if report.HasIssues() {
+		s.Status = summaryDrift
+	}
+}
+
+// fail marks the run as failed with err, which may be nil when the failures
+// are already recorded.
+func (s *runSummary) fail(err error) {
+	s.Status = summaryFailed
+	if err != nil {
+		s.Error = err.Error()
+	}
+}
+
+// saveSummary writes the summary to path when one is given. Failures only
+// warn: they do not change the outcome of the command.
+func saveSummary(path string, s *runSummary) {
+	if path == "" {
+		return
+	}
+	if err := writeSummaryFile(path, s); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not write summary: %v\n", err)
+	}
+}
+
+// writeSummaryFile writes the summary as JSON to a .json path. Any other
+// path gets markdown appended, as $GITHUB_STEP_SUMMARY expects from each
+// step that writes to it.
+func writeSummaryFile(path string, s *runSummary) error {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		data, err := json.MarshalIndent(s, "", "  ")
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, append(data, '\n'), 0o644)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(renderSummaryMarkdown(s)); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// renderSummaryMarkdown renders the summary as a markdown section.
+func renderSummaryMarkdown(s *runSummary) string {
+	var b strings.Builder
+	icon := map[string]string{
+		summaryInSync:  "✅",
+		summaryChanges: "📝",
+		summaryApplied: "✅",
+		summaryDrift:   "⚠️",
+		summaryFailed:  "❌",
+	}[s.Status]
+	fmt.Fprintf(&b, "### %s preflight %s: %s\n\n", icon, s.Command, s.Status)
+	fmt.Fprintf(&b, "Target: `%s`\n\n", s.Target)
+	if s.Error != "" {
+		fmt.Fprintf(&b, "> %s\n\n", strings.ReplaceAll(s.Error, "\n", " "))
+	}
+
+	switch {
+	case s.Steps != nil && s.Command == "plan":
+		b.WriteString("| Steps | Changes | Satisfied |\n")
+		b.WriteString("|------:|--------:|----------:|\n")
+		fmt.Fprintf(&b, "| %d | %d | %d |\n\n", s.Steps.Total, s.Steps.Changes, s.Steps.Satisfied)
+	case s.Steps != nil:
+		b.WriteString("| Steps | Changes | Satisfied | Applied | Failed | Skipped |\n")
+		b.WriteString("|------:|--------:|----------:|--------:|-------:|--------:|\n")
+		fmt.Fprintf(&b, "| %d | %d | %d | %d | %d | %d |\n\n",
+			s.Steps.Total, s.Steps.Changes, s.Steps.Satisfied, s.Steps.Applied, s.Steps.Failed, s.Steps.Skipped)
+	case s.Issues != nil:
+		fmt.Fprintf(&b, "Drift: %d issue(s)", s.Drift)
+		if s.Fixed > 0 {
+			fmt.Fprintf(&b, ", %d fixed", s.Fixed)
+		}
+		b.WriteString("\n\n")
+	}
+
+	if len(s.Failures) > 0 {
+		b.WriteString("**Failures**\n\n")
+		writeSummaryList(&b, len(s.Failures), func(i int) string {
+			return fmt.Sprintf("`%s`: %s", s.Failures[i].Step, strings.ReplaceAll(s.Failures[i].Error, "\n", " "))
+		})
+	}
+	if len(s.Issues) > 0 {
+		b.WriteString("**Drift**\n\n")
+		writeSummaryList(&b, len(s.Issues), func(i int) string {
+			issue := s.Issues[i]
+			if issue.Step != "" {
+				return fmt.Sprintf("%s `%s`: %s", issue.Severity, issue.Step, issue.Message)
+			}
+			return fmt.Sprintf("%s: %s", issue.Severity, issue.Message)
+		})
+	}
+	if len(s.Changes) > 0 && len(s.Failures) == 0 {
+		b.WriteString("<details><summary>Changes</summary>\n\n")
+		writeSummaryList(&b, len(s.Changes), func(i int) string {
+			return "`" + s.Changes[i] + "`"
+		})
+		b.WriteString("</details>\n\n")
+	}
+	return b.String()
+}
+
+// writeSummaryList writes up to summaryMaxItems list items.
+func writeSummaryList(b *strings.Builder, n int, item func(int) string) {
+	for i := 0; i < n && i < summaryMaxItems; i++ {
+		fmt.Fprintf(b, "- %s\n", item(i))
+	}
+	if n > summaryMaxItems {
+		fmt.Fprintf(b, "- … and %d more\n", n-summaryMaxItems)
+	}
+	b.WriteString("\n")
+}
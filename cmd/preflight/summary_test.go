@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/domain/execution"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunSummary_Apply(t *testing.T) {
+	t.Parallel()
+
+	plan := execution.NewExecutionPlan()
+	plan.Add(execution.NewPlanEntry(newDummyStep("files:link:bashrc"), compiler.StatusSatisfied, compiler.Diff{}))
+	plan.Add(execution.NewPlanEntry(newDummyStep("brew:formula:git"), compiler.StatusNeedsApply, compiler.Diff{}))
+	plan.Add(execution.NewPlanEntry(newDummyStep("brew:formula:jq"), compiler.StatusNeedsApply, compiler.Diff{}))
+
+	summary := newRunSummary("apply", "work")
+	summary.setPlan(plan)
+	assert.Equal(t, summaryChanges, summary.Status)
+	assert.Equal(t, 2, summary.Drift)
+	assert.Equal(t, []string{"brew:formula:git", "brew:formula:jq"}, summary.Changes)
+
+	summary.setResults([]execution.StepResult{
+		execution.NewStepResult(newDummyStep("brew:formula:git").ID(), compiler.StatusSatisfied, nil),
+		execution.NewStepResult(newDummyStep("brew:formula:jq").ID(), compiler.StatusFailed, errors.New("no bottle")),
+	}, nil)
+	assert.Equal(t, summaryFailed, summary.Status)
+	assert.Equal(t, summarySteps{Total: 3, Changes: 2, Satisfied: 1, Applied: 1, Failed: 1}, *summary.Steps)
+	assert.Equal(t, []summaryFailure{{Step: "brew:formula:jq", Error: "no bottle"}}, summary.Failures)
+
+	md := renderSummaryMarkdown(summary)
+	assert.Contains(t, md, "### ❌ preflight apply: failed")
+	assert.Contains(t, md, "| 3 | 2 | 1 | 1 | 1 | 0 |")
+	assert.Contains(t, md, "- `brew:formula:jq`: no bottle")
+	assert.NotContains(t, md, "<details>", "failures replace the list of changes")
+}
+
+func TestRunSummary_Doctor(t *testing.T) {
+	t.Parallel()
+
+	summary := newRunSummary("doctor", "default")
+	summary.setDoctor(&app.DoctorReport{Issues: []app.DoctorIssue{
+		{Severity: app.SeverityWarning, Provider: "brew", StepID: "brew:formula:git", Message: "Configuration drift detected"},
+	}})
+	assert.Equal(t, summaryDrift, summary.Status)
+	assert.Equal(t, 1, summary.Drift)
+
+	md := renderSummaryMarkdown(summary)
+	assert.Contains(t, md, "Drift: 1 issue(s)")
+	assert.Contains(t, md, "- warning `brew:formula:git`: Configuration drift detected")
+
+	summary = newRunSummary("doctor", "default")
+	summary.setDoctor(&app.DoctorReport{})
+	assert.Equal(t, summaryInSync, summary.Status)
+}
+
+func TestRenderSummaryMarkdown_CapsLists(t *testing.T) {
+	t.Parallel()
+
+	summary := newRunSummary("plan", "default")
+	summary.Status = summaryChanges
+	summary.Steps = &summarySteps{Total: 60, Changes: 60}
+	for range 60 {
+		summary.Changes = append(summary.Changes, "step")
+	}
+
+	md := renderSummaryMarkdown(summary)
+	assert.Equal(t, summaryMaxItems, strings.Count(md, "- `step`"))
+	assert.Contains(t, md, "and 10 more")
+	assert.Contains(t, md, "| Steps | Changes | Satisfied |\n")
+}
+
+func TestWriteSummaryFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	summary := newRunSummary("plan", "default")
+	summary.Status = summaryInSync
+
+	md := filepath.Join(dir, "step-summary")
+	require.NoError(t, writeSummaryFile(md, summary))
+	require.NoError(t, writeSummaryFile(md, summary))
+	data, err := os.ReadFile(md)
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(data), "preflight plan: in-sync"), "markdown is appended")
+
+	path := filepath.Join(dir, "summary.json")
+	require.NoError(t, writeSummaryFile(path, summary))
+	require.NoError(t, writeSummaryFile(path, summary))
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	var decoded runSummary
+	require.NoError(t, json.Unmarshal(data, &decoded), "JSON is replaced")
+	assert.Equal(t, "in-sync", decoded.Status)
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/felixgeelhaar/preflight/internal/app"
 	"github.com/felixgeelhaar/preflight/internal/domain/lock"
 	"github.com/felixgeelhaar/preflight/internal/domain/sync"
+	"github.com/felixgeelhaar/preflight/internal/offline"
 	"github.com/felixgeelhaar/preflight/internal/validation"
 	"github.com/spf13/cobra"
 )
@@ -104,10 +105,14 @@ func runSync(cmd *cobra.Command, _ []string) error {
 	fmt.Println()
 
 	// Step 1: Fetch from remote
-	fmt.Printf("1. Fetching from %s...\n", syncRemote)
-	if !syncDryRun {
-		if err := gitFetch(repoRoot, syncRemote); err != nil {
-			return fmt.Errorf("failed to fetch: %w", err)
+	if offline.Enabled() {
+		fmt.Printf("1. Fetch from %s skipped (offline).\n", syncRemote)
+	} else {
+		fmt.Printf("1. Fetching from %s...\n", syncRemote)
+		if !syncDryRun {
+			if err := gitFetch(repoRoot, syncRemote); err != nil {
+				return fmt.Errorf("failed to fetch: %w", err)
+			}
 		}
 	}
 
@@ -154,14 +159,17 @@ func runSync(cmd *cobra.Command, _ []string) error {
 	fmt.Println()
 
 	// Step 2: Pull changes
-	if behind > 0 {
+	switch {
+	case offline.Enabled():
+		fmt.Println("2. Pull skipped (offline).")
+	case behind > 0:
 		fmt.Printf("2. Pulling %d commit(s)...\n", behind)
 		if !syncDryRun {
 			if err := gitPull(repoRoot, syncRemote, branch); err != nil {
 				return fmt.Errorf("failed to pull: %w", err)
 			}
 		}
-	} else {
+	default:
 		fmt.Println("2. Already up to date.")
 	}
 	fmt.Println()
@@ -176,6 +184,8 @@ func runSync(cmd *cobra.Command, _ []string) error {
 	}
 
 	configPath := filepath.Join(repoRoot, syncConfigPath)
+	syncTarget = resolveMachineTarget(ctx, cmd, configPath, syncTarget)
+	printTargetMachines(configPath, syncTarget)
 	plan, err := preflight.Plan(ctx, configPath, syncTarget)
 	if err != nil {
 		return fmt.Errorf("failed to plan: %w", err)
@@ -214,14 +224,20 @@ func runSync(cmd *cobra.Command, _ []string) error {
 	fmt.Println()
 
 	// Step 5: Push changes (if requested)
-	if syncPush && ahead > 0 {
+	switch {
+	case syncPush && offline.Enabled():
+		fmt.Println("5. Push skipped (offline).")
+	case syncPush && ahead > 0:
 		fmt.Printf("5. Pushing %d commit(s)...\n", ahead)
+		if err := checkSyncExclusionLeaks(ctx, configPath); err != nil {
+			return err
+		}
 		if !syncDryRun {
 			if err := gitPush(repoRoot, syncRemote, branch); err != nil {
 				return fmt.Errorf("failed to push: %w", err)
 			}
 		}
-	} else if syncPush {
+	case syncPush:
 		fmt.Println("5. Nothing to push.")
 	}
 
@@ -231,6 +247,24 @@ func runSync(cmd *cobra.Command, _ []string) error {
 	return nil
 }
 
+// checkSyncExclusionLeaks refuses a git push that would share content
+// excluded by sync.exclude. Git pushes the whole repository, so excluded
+// files must not be tracked.
+func checkSyncExclusionLeaks(ctx context.Context, configPath string) error {
+	leaks, err := app.SyncExclusionLeaks(ctx, configPath)
+	if err != nil {
+		return fmt.Errorf("failed to check sync exclusions: %w", err)
+	}
+	if len(leaks) == 0 {
+		return nil
+	}
+	fmt.Println("   Excluded content is tracked by git and would be pushed:")
+	for _, leak := range leaks {
+		fmt.Printf("   - %s\n", leak)
+	}
+	return fmt.Errorf("refusing to push %d excluded item(s): untrack them (git rm --cached) or use 'preflight sync push <url>' which honours sync.exclude", len(leaks))
+}
+
 func findRepoRoot() (string, error) {
 	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
 	output, err := cmd.Output()
@@ -309,11 +343,8 @@ func gitPush(repoRoot, remote, branch string) error {
 // Returns nil if no lockfile exists or if there are no conflicts.
 // The branch parameter is unused because getRemoteLockfilePath computes it internally.
 func checkLockfileConflicts(ctx context.Context, repoRoot, lockPath, remote, _ string) (*sync.SyncResult, error) {
-	repo := lockfile.NewYAMLRepository()
-
-	// Load local lockfile
-	localLock, err := repo.Load(ctx, lockPath)
-	if err != nil {
+	// Fail early if there is no local lockfile
+	if _, err := lockfile.NewYAMLRepository().Load(ctx, lockPath); err != nil {
 		return nil, err
 	}
 
@@ -328,7 +359,20 @@ func checkLockfileConflicts(ctx context.Context, repoRoot, lockPath, remote, _ s
 	}
 	defer func() { _ = os.Remove(remoteLockPath) }()
 
-	remoteLock, err := repo.Load(ctx, remoteLockPath)
+	return compareLockfiles(ctx, lockPath, remoteLockPath)
+}
+
+// compareLockfiles runs the sync engine over two lockfiles on disk.
+// Returns nil if they do not need a merge.
+func compareLockfiles(ctx context.Context, localPath, remotePath string) (*sync.SyncResult, error) {
+	repo := lockfile.NewYAMLRepository()
+
+	localLock, err := repo.Load(ctx, localPath)
+	if err != nil {
+		return nil, err
+	}
+
+	remoteLock, err := repo.Load(ctx, remotePath)
 	if err != nil {
 		return nil, err
 	}
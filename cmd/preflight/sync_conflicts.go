@@ -9,10 +9,13 @@ import (
 	"os/exec"
 	"path/filepath"
 	"text/tabwriter"
+	"time"
 
 	"github.com/felixgeelhaar/preflight/internal/adapters/lockfile"
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
 	"github.com/felixgeelhaar/preflight/internal/domain/lock"
 	"github.com/felixgeelhaar/preflight/internal/domain/sync"
+	"github.com/felixgeelhaar/preflight/internal/tui"
 	"github.com/spf13/cobra"
 )
 
@@ -58,19 +61,26 @@ Examples:
 var syncResolveCmd = &cobra.Command{
 	Use:   "resolve [package-key]",
 	Short: "Resolve lockfile conflicts",
-	Long: `Interactively resolve conflicts between local and remote lockfiles.
+	Long: `Resolve conflicts between local and remote lockfiles.
 
-When called without arguments, resolves all conflicts interactively.
+When called without arguments, resolves all conflicts.
 When called with a package key, resolves that specific conflict.
 
+The merged lockfile is written in place, signed with the sync.lock_signing
+key of --config (default: the preflight.yaml at the repository root), and a
+merge record with the local, remote and base versions and the chosen outcome
+is saved to ~/.local/share/preflight/sync/merges/ for audit.
+
 Resolution strategies:
-  --local   Keep local version
-  --remote  Take remote version
-  --newest  Take the most recently modified version (default auto)
-  --skip    Skip this package (keep local, don't sync)
+  -i, --interactive  Pick local, remote or base, or edit the version, per package
+  --local            Keep local version
+  --remote           Take remote version
+  --newest           Take the most recently modified version (default auto)
+  --skip             Skip this package (keep local, don't sync)
 
 Examples:
-  preflight sync resolve                       # Resolve all interactively
+  preflight sync resolve                       # Show conflicts and strategies
+  preflight sync resolve -i                    # Resolve in a three-way view
   preflight sync resolve brew:ripgrep          # Resolve specific package
   preflight sync resolve brew:ripgrep --local  # Keep local version
   preflight sync resolve --remote              # Take remote for all`,
@@ -84,6 +94,7 @@ var (
 	resolveRemote       bool
 	resolveNewest       bool
 	resolveSkip         bool
+	resolveInteractive  bool
 	conflictsLockPath   string
 	conflictsRemotePath string
 )
@@ -103,6 +114,7 @@ func init() {
 	syncResolveCmd.Flags().BoolVar(&resolveRemote, "remote", false, "Take remote version for all conflicts")
 	syncResolveCmd.Flags().BoolVar(&resolveNewest, "newest", false, "Take newest version for all conflicts")
 	syncResolveCmd.Flags().BoolVar(&resolveSkip, "skip", false, "Skip all conflicts (keep local, don't sync)")
+	syncResolveCmd.Flags().BoolVarP(&resolveInteractive, "interactive", "i", false, "Resolve in a three-way view, picking or editing each version")
 }
 
 func runSyncConflicts(_ *cobra.Command, _ []string) error {
@@ -333,19 +345,50 @@ func runSyncResolve(_ *cobra.Command, args []string) error {
 		return nil
 	}
 
-	// Determine resolution strategy
-	var choice sync.ResolutionChoice
-	var useNewestStrategy bool
+	// Filter conflicts if specific package requested
+	conflictsToResolve := result.ManualConflicts
+	if len(args) > 0 {
+		targetKey := args[0]
+		var filtered []sync.LockConflict
+		for _, c := range conflictsToResolve {
+			if c.PackageKey() == targetKey {
+				filtered = append(filtered, c)
+			}
+		}
+		if len(filtered) == 0 {
+			return fmt.Errorf("no conflict found for package: %s", targetKey)
+		}
+		conflictsToResolve = filtered
+	}
+
+	// Determine resolutions
+	var resolutions []sync.Resolution
 	switch {
+	case resolveInteractive:
+		tuiResult, err := tui.RunLockConflictResolution(ctx, conflictsToResolve)
+		if err != nil {
+			return err
+		}
+		if tuiResult.Cancelled {
+			fmt.Println("Resolution cancelled. Lockfile unchanged.")
+			return nil
+		}
+		resolutions = tuiResult.Resolutions
 	case resolveLocal:
-		choice = sync.ChooseLocal
+		resolutions = resolveAll(conflictsToResolve, sync.ChooseLocal)
 	case resolveRemote:
-		choice = sync.ChooseRemote
+		resolutions = resolveAll(conflictsToResolve, sync.ChooseRemote)
 	case resolveNewest:
-		// Will determine choice per-conflict based on timestamps
-		useNewestStrategy = true
+		for _, conflict := range conflictsToResolve {
+			// Compare timestamps to determine newest
+			choice := sync.ChooseRemote
+			if conflict.Local().ModifiedAt().After(conflict.Remote().ModifiedAt()) {
+				choice = sync.ChooseLocal
+			}
+			resolutions = append(resolutions, sync.ResolveManually(conflict, choice))
+		}
 	case resolveSkip:
-		choice = sync.ChooseSkip
+		resolutions = resolveAll(conflictsToResolve, sync.ChooseSkip)
 	default:
 		// No strategy specified - show conflicts and guidance
 		fmt.Printf("Found %d conflict(s) requiring resolution:\n\n", len(result.ManualConflicts))
@@ -353,15 +396,17 @@ func runSyncResolve(_ *cobra.Command, args []string) error {
 		fmt.Println()
 
 		fmt.Println("Resolution strategies:")
-		fmt.Println("  --local   Keep your local versions (discard remote changes)")
-		fmt.Println("  --remote  Accept remote versions (discard local changes)")
-		fmt.Println("  --newest  Automatically choose the most recently modified version")
-		fmt.Println("  --skip    Skip syncing these packages (keep local, exclude from sync)")
+		fmt.Println("  -i, --interactive  Pick or edit each version in a three-way view")
+		fmt.Println("  --local            Keep your local versions (discard remote changes)")
+		fmt.Println("  --remote           Accept remote versions (discard local changes)")
+		fmt.Println("  --newest           Automatically choose the most recently modified version")
+		fmt.Println("  --skip             Skip syncing these packages (keep local, exclude from sync)")
 		fmt.Println()
 
 		fmt.Println("Examples:")
 		if len(result.ManualConflicts) > 0 {
 			examplePkg := result.ManualConflicts[0].PackageKey()
+			fmt.Printf("  preflight sync resolve -i                    # Resolve interactively\n")
 			fmt.Printf("  preflight sync resolve --newest              # Auto-resolve all by timestamp\n")
 			fmt.Printf("  preflight sync resolve %s --local   # Keep local for specific package\n", examplePkg)
 			fmt.Printf("  preflight sync resolve --remote              # Accept all remote versions\n")
@@ -372,75 +417,71 @@ func runSyncResolve(_ *cobra.Command, args []string) error {
 		return nil
 	}
 
-	// Filter conflicts if specific package requested
-	conflictsToResolve := result.ManualConflicts
-	if len(args) > 0 {
-		targetKey := args[0]
-		var filtered []sync.LockConflict
-		for _, c := range conflictsToResolve {
-			if c.PackageKey() == targetKey {
-				filtered = append(filtered, c)
-			}
-		}
-		if len(filtered) == 0 {
-			return fmt.Errorf("no conflict found for package: %s", targetKey)
-		}
-		conflictsToResolve = filtered
-	}
-
 	// Resolve conflicts
 	fmt.Println("Resolving conflicts:")
-	for _, conflict := range conflictsToResolve {
-		resolveChoice := choice
-		chosenVersion := ""
-		if useNewestStrategy {
-			// Compare timestamps to determine newest
-			localTime := conflict.Local().ModifiedAt()
-			remoteTime := conflict.Remote().ModifiedAt()
-			if localTime.After(remoteTime) {
-				resolveChoice = sync.ChooseLocal
-				chosenVersion = conflict.Local().Version()
-			} else {
-				resolveChoice = sync.ChooseRemote
-				chosenVersion = conflict.Remote().Version()
-			}
-		} else {
-			switch resolveChoice {
-			case sync.ChooseLocal, sync.ChooseSkip:
-				chosenVersion = conflict.Local().Version()
-			case sync.ChooseRemote:
-				chosenVersion = conflict.Remote().Version()
-			case sync.ChooseBase:
-				// ChooseBase not used in CLI resolution; falls back to local
-				chosenVersion = conflict.Local().Version()
-			}
-		}
-		if err := engine.ResolveManualConflict(result, conflict, resolveChoice); err != nil {
+	changes := make([]sync.MergeChange, 0, len(resolutions))
+	for _, resolution := range resolutions {
+		conflict := resolution.Conflict()
+		if err := engine.ApplyResolution(result, resolution); err != nil {
 			return fmt.Errorf("failed to resolve %s: %w", conflict.PackageKey(), err)
 		}
-		fmt.Printf("  ✓ %s: %s -> %s (chose %s)\n",
-			conflict.PackageKey(), conflict.Local().Version(), conflict.Remote().Version(), chosenVersion)
+		changes = append(changes, resolution.Change())
+
+		chosenVersion := resolution.Result().Version()
+		if resolution.IsSkipped() {
+			chosenVersion = conflict.Local().Version()
+		}
+		fmt.Printf("  ✓ %s: %s -> %s (chose %s %s)\n",
+			conflict.PackageKey(), conflict.Local().Version(), conflict.Remote().Version(),
+			resolution.Choice(), chosenVersion)
 	}
 
 	// Apply merged result back to lockfile (with remote for ChangeAdded support)
 	mergedLock, err := adapter.ApplyMergeResultWithRemote(localLock, &sync.MergeResult{
 		State:   result.Merged,
-		Changes: nil, // Calculated during merge
+		Changes: changes,
 	}, remoteLock)
 	if err != nil {
 		return fmt.Errorf("failed to apply resolution: %w", err)
 	}
 
-	// Save the merged lockfile
-	if err := repo.Save(ctx, localPath, mergedLock); err != nil {
+	// Save the merged lockfile, signed like apply signs it
+	configPath := cfgFile
+	if configPath == "" {
+		configPath = filepath.Join(repoRoot, config.ManifestFileName)
+	}
+	saveRepo, err := signingLockRepo(repo, configPath)
+	if err != nil {
+		return err
+	}
+	if err := saveRepo.Save(ctx, localPath, mergedLock); err != nil {
 		return fmt.Errorf("failed to save lockfile: %w", err)
 	}
 
-	fmt.Printf("\nResolved %d conflict(s). Lockfile updated.\n", len(conflictsToResolve))
+	// Record the merge for audit
+	record := sync.NewMergeRecord(machineID, hostname, localPath, resolutions, time.Now())
+	recordPath, err := record.Save(sync.DefaultMergeRecordDir())
+	if err != nil {
+		fmt.Printf("Warning: could not write merge record: %v\n", err)
+	}
+
+	fmt.Printf("\nResolved %d conflict(s). Lockfile updated.\n", len(resolutions))
+	if recordPath != "" {
+		fmt.Printf("Merge record: %s\n", recordPath)
+	}
 	fmt.Println("Run 'preflight sync --push' to share changes with remote.")
 	return nil
 }
 
+// resolveAll resolves every conflict with the same choice.
+func resolveAll(conflicts []sync.LockConflict, choice sync.ResolutionChoice) []sync.Resolution {
+	resolutions := make([]sync.Resolution, 0, len(conflicts))
+	for _, c := range conflicts {
+		resolutions = append(resolutions, sync.ResolveManually(c, choice))
+	}
+	return resolutions
+}
+
 func printConflicts(conflicts []sync.LockConflict) {
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 	_, _ = fmt.Fprintln(w, "PACKAGE\tTYPE\tLOCAL\tREMOTE\tRESOLVABLE")
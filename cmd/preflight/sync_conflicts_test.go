@@ -1,12 +1,26 @@
 package main
 
 import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
 	"encoding/json"
+	"encoding/pem"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/felixgeelhaar/preflight/internal/adapters/lockfile"
+	"github.com/felixgeelhaar/preflight/internal/domain/catalog"
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/domain/lock"
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
 	"github.com/felixgeelhaar/preflight/internal/domain/sync"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
 )
 
 func TestSyncConflictsCmd_Exists(t *testing.T) {
@@ -168,7 +182,7 @@ func TestSyncResolveCmd_FlagDefaults(t *testing.T) {
 	t.Parallel()
 
 	// All boolean flags should default to false
-	boolFlags := []string{"local", "remote", "newest", "skip"}
+	boolFlags := []string{"local", "remote", "newest", "skip", "interactive"}
 
 	for _, flag := range boolFlags {
 		t.Run(flag, func(t *testing.T) {
@@ -307,3 +321,128 @@ func TestPrintJSONOutput_Empty(t *testing.T) {
 	manualConflicts := parsed["manual_conflicts"].([]interface{})
 	assert.Empty(t, manualConflicts)
 }
+
+// writeConflictingLockfile writes a lockfile whose ripgrep version conflicts
+// with lockfiles written for other machines.
+func writeConflictingLockfile(t *testing.T, path, machine, version string) {
+	t.Helper()
+	machineID, err := sync.ParseMachineID(machine)
+	require.NoError(t, err)
+	installedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	info, err := lock.NewMachineInfo("darwin", "arm64", "test-host", installedAt)
+	require.NoError(t, err)
+	integrity, err := lock.NewIntegrity(lock.AlgorithmSHA256, "abc123def456abc123def456abc123def456abc123def456abc123def456abcd")
+	require.NoError(t, err)
+	pkg, err := lock.NewPackageLock("brew", "ripgrep", version, integrity, installedAt)
+	require.NoError(t, err)
+
+	lf := lock.NewLockfileV2(config.ModeIntent, info).
+		WithSyncMetadata(sync.NewSyncMetadata(sync.NewVersionVector().Increment(machineID)))
+	require.NoError(t, lf.AddPackage(pkg))
+	require.NoError(t, lockfile.NewYAMLRepository().Save(context.Background(), path, lf))
+}
+
+func TestRunSyncResolve_RemoteWritesLockfileAndMergeRecord(t *testing.T) { //nolint:tparallel // modifies cwd and globals
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	repoDir := t.TempDir()
+	require.NoError(t, exec.Command("git", "init", "-q", repoDir).Run())
+	origDir, _ := os.Getwd()
+	require.NoError(t, os.Chdir(repoDir))
+	defer func() { _ = os.Chdir(origDir) }()
+
+	localPath := filepath.Join(repoDir, "preflight.lock")
+	remotePath := filepath.Join(t.TempDir(), "remote.lock")
+	writeConflictingLockfile(t, localPath, "550e8400-e29b-41d4-a716-446655440001", "14.0.0")
+	writeConflictingLockfile(t, remotePath, "550e8400-e29b-41d4-a716-446655440002", "14.1.0")
+
+	conflictsRemotePath = remotePath
+	resolveRemote = true
+	defer func() {
+		conflictsRemotePath = ""
+		resolveRemote = false
+	}()
+
+	out := capturePluginStdout(t, func() {
+		require.NoError(t, runSyncResolve(syncResolveCmd, nil))
+	})
+	assert.Contains(t, out, "brew:ripgrep: 14.0.0 -> 14.1.0 (chose remote 14.1.0)")
+
+	merged, err := lockfile.NewYAMLRepository().Load(context.Background(), localPath)
+	require.NoError(t, err)
+	pkg, ok := merged.GetPackage("brew", "ripgrep")
+	require.True(t, ok)
+	assert.Equal(t, "14.1.0", pkg.Version())
+
+	records, err := filepath.Glob(filepath.Join(sync.DefaultMergeRecordDir(), "*.json"))
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	data, err := os.ReadFile(records[0])
+	require.NoError(t, err)
+	var record sync.MergeRecord
+	require.NoError(t, json.Unmarshal(data, &record))
+	require.Len(t, record.Entries, 1)
+	assert.Equal(t, "remote", record.Entries[0].Choice)
+	assert.Equal(t, "14.1.0", record.Entries[0].Result)
+}
+
+func TestRunSyncResolve_SignsWithConfigFlagKey(t *testing.T) { //nolint:tparallel // modifies cwd and globals
+	t.Setenv("HOME", t.TempDir())
+	home := t.TempDir()
+	t.Setenv(platform.HomeEnvVar, home)
+
+	repoDir := t.TempDir()
+	require.NoError(t, exec.Command("git", "init", "-q", repoDir).Run())
+	origDir, _ := os.Getwd()
+	require.NoError(t, os.Chdir(repoDir))
+	defer func() { _ = os.Chdir(origDir) }()
+
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	block, err := ssh.MarshalPrivateKey(key, "")
+	require.NoError(t, err)
+	sshPub, err := ssh.NewPublicKey(pub)
+	require.NoError(t, err)
+	pubData := ssh.MarshalAuthorizedKey(sshPub)
+	keyPath := filepath.Join(t.TempDir(), "team")
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(block), 0o600))
+	require.NoError(t, os.WriteFile(keyPath+".pub", pubData, 0o644))
+	store := catalog.NewTrustStore(filepath.Join(home, "trust.json"))
+	trusted := catalog.NewTrustedKey("team", catalog.SignatureTypeSSH, nil,
+		catalog.NewPublisher("Team", "team@example.com", "team", catalog.SignatureTypeSSH))
+	trusted.SetFingerprint(catalog.ComputeKeyFingerprint(pubData))
+	require.NoError(t, store.Add(trusted))
+	require.NoError(t, store.Save())
+
+	// The configuration lives outside the repository root under another name.
+	configPath := filepath.Join(t.TempDir(), "team.yml")
+	manifest := "targets:\n  default: [base]\nsync:\n  lock_signing:\n    key: " + keyPath + "\n"
+	require.NoError(t, os.WriteFile(configPath, []byte(manifest), 0o600))
+
+	localPath := filepath.Join(repoDir, "preflight.lock")
+	remotePath := filepath.Join(t.TempDir(), "remote.lock")
+	writeConflictingLockfile(t, localPath, "550e8400-e29b-41d4-a716-446655440001", "14.0.0")
+	writeConflictingLockfile(t, remotePath, "550e8400-e29b-41d4-a716-446655440002", "14.1.0")
+
+	origCfgFile := cfgFile
+	cfgFile = configPath
+	conflictsRemotePath = remotePath
+	resolveRemote = true
+	defer func() {
+		cfgFile = origCfgFile
+		conflictsRemotePath = ""
+		resolveRemote = false
+	}()
+
+	capturePluginStdout(t, func() {
+		require.NoError(t, runSyncResolve(syncResolveCmd, nil))
+	})
+
+	data, err := os.ReadFile(localPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "signature:", "the merged lockfile is signed with the --config key")
+	_, err = lockfile.NewYAMLRepository().Load(context.Background(), localPath)
+	require.NoError(t, err, "the signed lockfile verifies")
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/felixgeelhaar/preflight/internal/adapters/lockfile"
+	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/domain/lock"
+	"github.com/felixgeelhaar/preflight/internal/domain/sync"
+)
+
+// signingLockRepo returns a repository that signs saved lockfiles with the
+// sync.lock_signing key of configPath. A missing config signs nothing.
+func signingLockRepo(repo *lockfile.YAMLRepository, configPath string) (lock.Repository, error) {
+	signer, err := app.LoadLockSigner(configPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return repo, nil
+		}
+		return nil, fmt.Errorf("failed to load lock signing key: %w", err)
+	}
+	if signer == nil {
+		return repo, nil
+	}
+	return repo.WithSigner(signer), nil
+}
+
+// checkBundleLockProvenance verifies the signature of a pulled lockfile and
+// applies the sync.lock_signing policy to lockfiles that are unsigned or
+// signed by keys outside the trust store. A nil syncCfg is the default
+// policy.
+func checkBundleLockProvenance(syncCfg *config.SyncConfig, bundle *sync.Bundle) error {
+	data, ok := bundle.Files["preflight.lock"]
+	if !ok {
+		return nil
+	}
+
+	provenance, err := app.CheckLockProvenance(syncCfg, data)
+	if err != nil {
+		if errors.Is(err, lock.ErrSignatureInvalid) {
+			return &config.UserError{
+				Code:       config.ErrCodeValidationFailed,
+				Message:    "the pulled lockfile was changed after it was signed",
+				Suggestion: "Nothing was written. Ask the signer to push again and check who has access to the remote",
+				Underlying: err,
+			}
+		}
+		return fmt.Errorf("failed to check lockfile signature: %w", err)
+	}
+
+	switch {
+	case provenance.Untrusted == nil:
+		if provenance.Signer != nil {
+			fmt.Printf("Lockfile signed by %s (trusted)\n", provenance.Signer.KeyID)
+		}
+		return nil
+	case provenance.Warn:
+		fmt.Printf("Warning: %v (allowed by sync.lock_signing.untrusted: warn)\n", provenance.Untrusted)
+		return nil
+	case provenance.Signer == nil:
+		return &config.UserError{
+			Code:       config.ErrCodeValidationFailed,
+			Message:    "the pulled lockfile is not signed",
+			Suggestion: "Set sync.lock_signing.key on the pushing machine, or set sync.lock_signing.untrusted: warn to accept unsigned lockfiles",
+			Underlying: provenance.Untrusted,
+		}
+	default:
+		return &config.UserError{
+			Code:       config.ErrCodeValidationFailed,
+			Message:    fmt.Sprintf("the pulled lockfile is signed by %s, which is not in your trust store", provenance.Signer.KeyID),
+			Suggestion: "Add the signer's public key with 'preflight trust add <key.pub>', or set sync.lock_signing.untrusted: warn",
+			Underlying: provenance.Untrusted,
+		}
+	}
+}
+
+// resignBundleLock re-signs the bundle's lockfile with the key of
+// configPath when sync.exclude changed it since before, as the signature
+// covers the lockfile as it was written.
+func resignBundleLock(configPath string, bundle *sync.Bundle, before []byte) error {
+	data, ok := bundle.Files["preflight.lock"]
+	if !ok || bytes.Equal(data, before) {
+		return nil
+	}
+	resigned, signed, err := app.SignLockData(configPath, data)
+	if err != nil {
+		return err
+	}
+	if !signed {
+		provenance, err := lockfile.ReadProvenance(before)
+		if err != nil || provenance == nil {
+			return nil
+		}
+		fmt.Println("Warning: sync.exclude changed the signed lockfile, which is now unsigned. Set sync.lock_signing.key to sign it.")
+	}
+	bundle.Files["preflight.lock"] = resigned
+	return nil
+}
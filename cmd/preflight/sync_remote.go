@@ -0,0 +1,361 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/adapters/age"
+	"github.com/felixgeelhaar/preflight/internal/adapters/objectstore"
+	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/felixgeelhaar/preflight/internal/domain/lock"
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
+	"github.com/felixgeelhaar/preflight/internal/domain/sync"
+	"github.com/felixgeelhaar/preflight/internal/offline"
+	"github.com/spf13/cobra"
+)
+
+// syncURLEnv names the environment variable holding the default remote URL.
+const syncURLEnv = "PREFLIGHT_SYNC_URL"
+
+var syncPushCmd = &cobra.Command{
+	Use:   "push [url]",
+	Short: "Encrypt and upload configuration to object storage",
+	Long: `Upload preflight.yaml, layers and the lockfile to object storage without git.
+
+The bundle is encrypted client-side with age before upload, so the storage
+provider never sees your configuration. It is encrypted to your sync identity
+and any additional --recipient keys (for example, another machine's key).
+
+Supported remotes:
+  s3://bucket/prefix        AWS S3 or S3-compatible storage (AWS_* credentials)
+  gs://bucket/prefix        Google Cloud Storage (PREFLIGHT_GCS_ACCESS_KEY/SECRET_KEY)
+  webdavs://host/path       WebDAV over HTTPS (PREFLIGHT_WEBDAV_USER/PASSWORD)
+
+The URL defaults to $PREFLIGHT_SYNC_URL.
+
+Examples:
+  preflight sync keygen
+  preflight sync push s3://my-bucket/dotfiles
+  preflight sync push gs://my-bucket --recipient age1...`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runSyncPush,
+}
+
+var syncPullCmd = &cobra.Command{
+	Use:   "pull [url]",
+	Short: "Download and decrypt configuration from object storage",
+	Long: `Download the encrypted bundle uploaded by 'preflight sync push' and write
+it into the config directory.
+
+Lockfile conflicts are checked before any file is written. Run
+'preflight apply' afterwards to apply the pulled configuration.
+
+A lockfile changed after it was signed is always refused. Lockfiles signed
+by keys outside your trust store, and unsigned ones once sync.lock_signing
+is configured, are refused or pulled with a warning according to
+sync.lock_signing.untrusted.
+
+Examples:
+  preflight sync pull s3://my-bucket/dotfiles
+  preflight sync pull --dry-run
+  preflight sync pull webdavs://cloud.example.com/dav/preflight --force`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runSyncPull,
+}
+
+var syncKeygenCmd = &cobra.Command{
+	Use:   "keygen",
+	Short: "Generate the age identity used for encrypted sync",
+	Long: `Generate an age X25519 identity for encrypted object-storage sync.
+
+The identity is written to ~/.local/share/preflight/sync/identity.txt with
+0600 permissions and is compatible with the age CLI. Copy it to every machine that
+should pull, or share the printed public key and push with --recipient.`,
+	Args: cobra.NoArgs,
+	RunE: runSyncKeygen,
+}
+
+var (
+	remoteSyncConfigPath string
+	remoteSyncIdentity   string
+	remoteSyncRecipients []string
+	remoteSyncDryRun     bool
+	remoteSyncForce      bool
+)
+
+func init() {
+	syncCmd.AddCommand(syncPushCmd, syncPullCmd, syncKeygenCmd)
+
+	for _, c := range []*cobra.Command{syncPushCmd, syncPullCmd} {
+		c.Flags().StringVarP(&remoteSyncConfigPath, "config", "c", "preflight.yaml", "Path to preflight.yaml")
+		c.Flags().StringVar(&remoteSyncIdentity, "identity", "", "Path to age identity file (default: sync/identity.txt in the preflight data directory)")
+	}
+	syncKeygenCmd.Flags().StringVar(&remoteSyncIdentity, "identity", "", "Path to write the identity (default: sync/identity.txt in the preflight data directory)")
+
+	syncPushCmd.Flags().StringArrayVar(&remoteSyncRecipients, "recipient", nil, "Additional age recipient public key (repeatable)")
+	syncPullCmd.Flags().BoolVar(&remoteSyncDryRun, "dry-run", false, "Show which files would change without writing them")
+	syncPullCmd.Flags().BoolVar(&remoteSyncForce, "force", false, "Overwrite local files even with lockfile conflicts")
+}
+
+// defaultSyncIdentityPath returns sync/identity.txt in the data directory.
+func defaultSyncIdentityPath() string {
+	path, err := platform.Path(platform.DataDir, "sync", "identity.txt")
+	if err != nil {
+		return filepath.Join(".preflight", "sync", "identity.txt")
+	}
+	return path
+}
+
+func syncIdentityPath() string {
+	if remoteSyncIdentity != "" {
+		return remoteSyncIdentity
+	}
+	return defaultSyncIdentityPath()
+}
+
+func syncRemoteURL(args []string) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+	if u := os.Getenv(syncURLEnv); u != "" {
+		return u, nil
+	}
+	return "", fmt.Errorf("no remote URL given and %s is not set", syncURLEnv)
+}
+
+// openRemoteSync resolves the remote URL and returns a RemoteSync for it.
+func openRemoteSync(args []string, encryptor *age.Encryptor) (*sync.RemoteSync, sync.RemoteStore, error) {
+	rawURL, err := syncRemoteURL(args)
+	if err != nil {
+		return nil, nil, err
+	}
+	if offline.Enabled() {
+		return nil, nil, offline.Skipped("remote sync with " + rawURL)
+	}
+	store, prefix, err := objectstore.Open(rawURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sync.NewRemoteSync(store, encryptor, prefix), store, nil
+}
+
+func runSyncKeygen(_ *cobra.Command, _ []string) error {
+	path := syncIdentityPath()
+	id, err := age.GenerateIdentity()
+	if err != nil {
+		return err
+	}
+	if err := age.WriteIdentityFile(path, id); err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("identity already exists at %s", path)
+		}
+		return fmt.Errorf("failed to write identity: %w", err)
+	}
+
+	fmt.Printf("Wrote identity to %s\n", path)
+	fmt.Printf("Public key: %s\n", id.Recipient())
+	return nil
+}
+
+func runSyncPush(_ *cobra.Command, args []string) error {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	encryptor := &age.Encryptor{}
+	ids, err := age.ReadIdentityFile(syncIdentityPath())
+	switch {
+	case err == nil:
+		for _, id := range ids {
+			encryptor.Recipients = append(encryptor.Recipients, id.Recipient())
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return fmt.Errorf("failed to read identity: %w", err)
+	}
+	for _, r := range remoteSyncRecipients {
+		recipient, err := age.ParseRecipient(r)
+		if err != nil {
+			return err
+		}
+		encryptor.Recipients = append(encryptor.Recipients, recipient)
+	}
+	if len(encryptor.Recipients) == 0 {
+		return fmt.Errorf("no encryption keys: run 'preflight sync keygen' or pass --recipient")
+	}
+
+	rs, store, err := openRemoteSync(args, encryptor)
+	if err != nil {
+		return err
+	}
+
+	manifest := sync.BundleManifest{CreatedAt: time.Now().UTC()}
+	if id, err := sync.GetMachineID(); err == nil {
+		manifest.MachineID = id.String()
+	}
+	manifest.Hostname, _ = os.Hostname()
+
+	dir := filepath.Dir(remoteSyncConfigPath)
+	bundle, err := sync.CollectBundle(dir, manifest)
+	if err != nil {
+		return err
+	}
+	syncCfg, err := app.LoadSyncConfig(remoteSyncConfigPath)
+	if err != nil {
+		return err
+	}
+	pushedLock := bundle.Files["preflight.lock"]
+	excluded, err := bundle.Exclude(syncCfg)
+	if err != nil {
+		return err
+	}
+	if err := resignBundleLock(remoteSyncConfigPath, bundle, pushedLock); err != nil {
+		return err
+	}
+	if err := rs.Push(ctx, bundle); err != nil {
+		return err
+	}
+
+	fmt.Printf("Pushed %d file(s) to %s/%s (encrypted to %d recipient(s))\n",
+		len(bundle.Files), store, rs.Key(), len(encryptor.Recipients))
+	for _, name := range bundle.Names() {
+		fmt.Printf("  %s\n", name)
+	}
+	if len(excluded) > 0 {
+		fmt.Println("Excluded by sync.exclude:")
+		for _, e := range excluded {
+			fmt.Printf("  - %s\n", e)
+		}
+	}
+	return nil
+}
+
+func runSyncPull(_ *cobra.Command, args []string) error {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	ids, err := age.ReadIdentityFile(syncIdentityPath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("no identity at %s: copy it from the pushing machine or run 'preflight sync keygen'", syncIdentityPath())
+		}
+		return fmt.Errorf("failed to read identity: %w", err)
+	}
+
+	rs, store, err := openRemoteSync(args, &age.Encryptor{Identities: ids})
+	if err != nil {
+		return err
+	}
+
+	bundle, err := rs.Pull(ctx)
+	if err != nil {
+		if errors.Is(err, sync.ErrRemoteNotFound) {
+			return fmt.Errorf("nothing pushed to %s yet", store)
+		}
+		return err
+	}
+
+	fmt.Printf("Pulled bundle from %s", store)
+	if bundle.Manifest.Hostname != "" {
+		fmt.Printf(" (pushed by %s at %s)", bundle.Manifest.Hostname, bundle.Manifest.CreatedAt.Local().Format(time.DateTime))
+	}
+	fmt.Println()
+
+	dir := filepath.Dir(remoteSyncConfigPath)
+	syncCfg, err := app.LoadSyncConfig(remoteSyncConfigPath)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		syncCfg = nil
+	}
+	// The lockfile is checked as pushed, under the local signing policy.
+	if err := checkBundleLockProvenance(syncCfg, bundle); err != nil {
+		return err
+	}
+	// The local exclusions apply: excluded layers and sections are kept as
+	// they are on this machine.
+	if syncCfg != nil {
+		pulledLock := bundle.Files["preflight.lock"]
+		if err := bundle.RestoreExcluded(dir, syncCfg); err != nil {
+			return err
+		}
+		if err := resignBundleLock(remoteSyncConfigPath, bundle, pulledLock); err != nil {
+			return err
+		}
+	}
+	changes := bundle.Diff(dir)
+	if len(changes) == 0 {
+		fmt.Println("Already up to date.")
+		return nil
+	}
+
+	if err := checkBundleLockConflicts(ctx, dir, bundle); err != nil {
+		return err
+	}
+
+	for _, c := range changes {
+		marker := "~"
+		if c.Created {
+			marker = "+"
+		}
+		fmt.Printf("  %s %s\n", marker, c.Path)
+	}
+
+	if remoteSyncDryRun {
+		fmt.Println("\nDry run: no files written.")
+		return nil
+	}
+	if err := bundle.WriteTo(dir); err != nil {
+		return err
+	}
+	fmt.Printf("\nUpdated %d file(s). Run 'preflight apply' to apply them.\n", len(changes))
+	return nil
+}
+
+// checkBundleLockConflicts compares the local lockfile with the pulled one
+// and refuses to overwrite it on manual conflicts unless --force is set.
+func checkBundleLockConflicts(ctx context.Context, dir string, bundle *sync.Bundle) error {
+	remoteLock, ok := bundle.Files["preflight.lock"]
+	if !ok {
+		return nil
+	}
+
+	tmp, err := os.CreateTemp("", "preflight-remote-*.lock")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(remoteLock); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	result, err := compareLockfiles(ctx, filepath.Join(dir, "preflight.lock"), tmp.Name())
+	if err != nil {
+		if !errors.Is(err, lock.ErrLockfileNotFound) {
+			fmt.Printf("Warning: could not check lockfile conflicts: %v\n", err)
+		}
+		return nil
+	}
+	if result == nil || !result.HasManualConflicts() {
+		return nil
+	}
+
+	fmt.Printf("⚠️  Lockfile conflict detected! %d package(s) have conflicting versions.\n", len(result.ManualConflicts))
+	for _, c := range result.ManualConflicts {
+		fmt.Printf("   - %s\n", c.PackageKey())
+	}
+	if !remoteSyncForce {
+		return fmt.Errorf("lockfile conflicts detected. Use --force to overwrite the local lockfile")
+	}
+	fmt.Println("   Proceeding with --force (remote versions will be written)...")
+	return nil
+}
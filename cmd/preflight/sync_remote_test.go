@@ -0,0 +1,274 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	gosync "sync"
+	"testing"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/adapters/lockfile"
+	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/felixgeelhaar/preflight/internal/domain/catalog"
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/domain/lock"
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+// newMemoryWebDAV serves PUT/GET/MKCOL from memory.
+func newMemoryWebDAV(t *testing.T) (*httptest.Server, map[string][]byte) {
+	t.Helper()
+	var mu gosync.Mutex
+	objects := map[string][]byte{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case "MKCOL":
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodPut:
+			data, _ := io.ReadAll(r.Body)
+			objects[r.URL.Path] = data
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodGet:
+			data, ok := objects[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write(data)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, objects
+}
+
+func resetRemoteSyncFlags(t *testing.T) {
+	t.Helper()
+	t.Cleanup(func() {
+		remoteSyncConfigPath = "preflight.yaml"
+		remoteSyncIdentity = ""
+		remoteSyncRecipients = nil
+		remoteSyncDryRun = false
+		remoteSyncForce = false
+	})
+}
+
+func TestSyncRemote_PushPull(t *testing.T) {
+	resetRemoteSyncFlags(t)
+	t.Setenv("HOME", t.TempDir())
+
+	srv, objects := newMemoryWebDAV(t)
+	remoteURL := "webdav://" + strings.TrimPrefix(srv.URL, "http://") + "/dav"
+
+	remoteSyncIdentity = filepath.Join(t.TempDir(), "identity.txt")
+	out := capturePluginStdout(t, func() {
+		require.NoError(t, runSyncKeygen(syncKeygenCmd, nil))
+	})
+	assert.Contains(t, out, "Public key: age1")
+	require.Error(t, runSyncKeygen(syncKeygenCmd, nil), "keygen must not overwrite an identity")
+
+	src := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "layers"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "preflight.yaml"), []byte("targets:\n  default: [base]\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "layers", "base.yaml"), []byte("name: base\n"), 0o600))
+
+	remoteSyncConfigPath = filepath.Join(src, "preflight.yaml")
+	out = capturePluginStdout(t, func() {
+		require.NoError(t, runSyncPush(syncPushCmd, []string{remoteURL}))
+	})
+	assert.Contains(t, out, "Pushed 2 file(s)")
+
+	stored := objects["/dav/preflight.bundle.age"]
+	require.NotEmpty(t, stored)
+	assert.True(t, strings.HasPrefix(string(stored), "age-encryption.org/v1\n"))
+	assert.NotContains(t, string(stored), "targets:")
+
+	dst := t.TempDir()
+	remoteSyncConfigPath = filepath.Join(dst, "preflight.yaml")
+	t.Setenv(syncURLEnv, remoteURL)
+
+	remoteSyncDryRun = true
+	out = capturePluginStdout(t, func() {
+		require.NoError(t, runSyncPull(syncPullCmd, nil))
+	})
+	assert.Contains(t, out, "+ layers/base.yaml")
+	assert.Contains(t, out, "Dry run")
+	assert.NoFileExists(t, filepath.Join(dst, "preflight.yaml"))
+
+	remoteSyncDryRun = false
+	out = capturePluginStdout(t, func() {
+		require.NoError(t, runSyncPull(syncPullCmd, nil))
+	})
+	assert.Contains(t, out, "Updated 2 file(s)")
+	data, err := os.ReadFile(filepath.Join(dst, "layers", "base.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "name: base\n", string(data))
+
+	out = capturePluginStdout(t, func() {
+		require.NoError(t, runSyncPull(syncPullCmd, nil))
+	})
+	assert.Contains(t, out, "Already up to date.")
+}
+
+func TestSyncRemote_Exclude(t *testing.T) {
+	resetRemoteSyncFlags(t)
+	t.Setenv("HOME", t.TempDir())
+
+	srv, _ := newMemoryWebDAV(t)
+	remoteURL := "webdav://" + strings.TrimPrefix(srv.URL, "http://") + "/dav"
+	remoteSyncIdentity = filepath.Join(t.TempDir(), "identity.txt")
+	capturePluginStdout(t, func() {
+		require.NoError(t, runSyncKeygen(syncKeygenCmd, nil))
+	})
+
+	manifest := "targets:\n  default: [base, personal]\nsync:\n  exclude:\n    layers: [personal]\n    providers: [ssh]\n"
+	writeConfig := func(dir, base string) {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "layers"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "preflight.yaml"), []byte(manifest), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "layers", "base.yaml"), []byte(base), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "layers", "personal.yaml"), []byte("name: personal\n"), 0o600))
+	}
+
+	src := t.TempDir()
+	writeConfig(src, "name: base\nssh:\n  hosts:\n    - host: home\n")
+	remoteSyncConfigPath = filepath.Join(src, "preflight.yaml")
+	out := capturePluginStdout(t, func() {
+		require.NoError(t, runSyncPush(syncPushCmd, []string{remoteURL}))
+	})
+	assert.Contains(t, out, "Pushed 2 file(s)")
+	assert.Contains(t, out, "- layers/personal.yaml")
+	assert.Contains(t, out, "- layers/base.yaml: ssh")
+
+	// The pulling machine keeps its own excluded content.
+	dst := t.TempDir()
+	writeConfig(dst, "name: base\nssh:\n  hosts:\n    - host: work\n")
+	remoteSyncConfigPath = filepath.Join(dst, "preflight.yaml")
+	out = capturePluginStdout(t, func() {
+		require.NoError(t, runSyncPull(syncPullCmd, []string{remoteURL}))
+	})
+	assert.Contains(t, out, "Already up to date.")
+
+	data, err := os.ReadFile(filepath.Join(dst, "layers", "base.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "host: work")
+}
+
+func TestSyncRemote_SignedLockfile(t *testing.T) {
+	resetRemoteSyncFlags(t)
+	t.Setenv("HOME", t.TempDir())
+	home := t.TempDir()
+	t.Setenv(platform.HomeEnvVar, home)
+
+	srv, _ := newMemoryWebDAV(t)
+	remoteURL := "webdav://" + strings.TrimPrefix(srv.URL, "http://") + "/dav"
+	remoteSyncIdentity = filepath.Join(t.TempDir(), "identity.txt")
+	capturePluginStdout(t, func() {
+		require.NoError(t, runSyncKeygen(syncKeygenCmd, nil))
+	})
+
+	// The team key signs lockfiles and is trusted on both machines.
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	block, err := ssh.MarshalPrivateKey(key, "")
+	require.NoError(t, err)
+	sshPub, err := ssh.NewPublicKey(pub)
+	require.NoError(t, err)
+	pubData := ssh.MarshalAuthorizedKey(sshPub)
+	keyPath := filepath.Join(t.TempDir(), "team")
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(block), 0o600))
+	require.NoError(t, os.WriteFile(keyPath+".pub", pubData, 0o644))
+	store := catalog.NewTrustStore(filepath.Join(home, "trust.json"))
+	trusted := catalog.NewTrustedKey("team", catalog.SignatureTypeSSH, nil,
+		catalog.NewPublisher("Team", "team@example.com", "team", catalog.SignatureTypeSSH))
+	trusted.SetFingerprint(catalog.ComputeKeyFingerprint(pubData))
+	require.NoError(t, store.Add(trusted))
+	require.NoError(t, store.Save())
+
+	manifest := "targets:\n  default: [base]\nsync:\n  exclude:\n    providers: [npm]\n  lock_signing:\n    key: " + keyPath + "\n"
+	src := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "layers"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "preflight.yaml"), []byte(manifest), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "layers", "base.yaml"), []byte("name: base\n"), 0o600))
+	signer, err := app.LoadLockSigner(filepath.Join(src, "preflight.yaml"))
+	require.NoError(t, err)
+	lf := lock.NewLockfile(config.ModeLocked, lock.MachineInfoFromSystem())
+	for _, provider := range []string{"brew", "npm"} {
+		pkg, err := lock.NewPackageLock(provider, "tool", "1.0.0", lock.IntegrityFromData(lock.AlgorithmSHA256, []byte(provider)), time.Now())
+		require.NoError(t, err)
+		require.NoError(t, lf.AddPackage(pkg))
+	}
+	require.NoError(t, lockfile.NewYAMLRepository().WithSigner(signer).Save(context.Background(), filepath.Join(src, "preflight.lock"), lf))
+
+	// Push re-signs the lockfile after withholding the npm packages.
+	remoteSyncConfigPath = filepath.Join(src, "preflight.yaml")
+	out := capturePluginStdout(t, func() {
+		require.NoError(t, runSyncPush(syncPushCmd, []string{remoteURL}))
+	})
+	assert.Contains(t, out, "- preflight.lock: packages.npm:*")
+
+	dst := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dst, "preflight.yaml"), []byte(manifest), 0o600))
+	remoteSyncConfigPath = filepath.Join(dst, "preflight.yaml")
+	out = capturePluginStdout(t, func() {
+		require.NoError(t, runSyncPull(syncPullCmd, []string{remoteURL}))
+	})
+	assert.Contains(t, out, "Lockfile signed by team (trusted)")
+	pulled, err := lockfile.NewYAMLRepository().Load(context.Background(), filepath.Join(dst, "preflight.lock"))
+	require.NoError(t, err, "the pulled lockfile verifies")
+	assert.Equal(t, 1, pulled.PackageCount())
+
+	// A machine that does not trust the team key refuses the lockfile.
+	t.Setenv(platform.HomeEnvVar, t.TempDir())
+	other := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(other, "preflight.yaml"), []byte("targets:\n  default: [base]\n"), 0o600))
+	remoteSyncConfigPath = filepath.Join(other, "preflight.yaml")
+	err = runSyncPull(syncPullCmd, []string{remoteURL})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "signed by team, which is not in your trust store")
+	assert.NoFileExists(t, filepath.Join(other, "preflight.lock"))
+}
+
+func TestSyncRemote_PushRequiresKeys(t *testing.T) {
+	resetRemoteSyncFlags(t)
+
+	remoteSyncIdentity = filepath.Join(t.TempDir(), "missing.txt")
+	err := runSyncPush(syncPushCmd, []string{"webdav://localhost/dav"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "preflight sync keygen")
+}
+
+func TestSyncRemote_PullRequiresIdentity(t *testing.T) {
+	resetRemoteSyncFlags(t)
+
+	remoteSyncIdentity = filepath.Join(t.TempDir(), "missing.txt")
+	err := runSyncPull(syncPullCmd, []string{"webdav://localhost/dav"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no identity")
+}
+
+func TestSyncRemoteURL(t *testing.T) {
+	t.Setenv(syncURLEnv, "")
+	_, err := syncRemoteURL(nil)
+	require.Error(t, err)
+
+	t.Setenv(syncURLEnv, "s3://bucket")
+	u, err := syncRemoteURL(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "s3://bucket", u)
+
+	u, err = syncRemoteURL([]string{"gs://other"})
+	require.NoError(t, err)
+	assert.Equal(t, "gs://other", u)
+}
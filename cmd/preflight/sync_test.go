@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -144,3 +145,30 @@ func TestRunSync_NotInGitRepo(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "not in a git repository")
 }
+
+func TestCheckSyncExclusionLeaks(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "preflight.yaml")
+	manifest := "targets:\n  default: [base]\nsync:\n  exclude:\n    layers: [personal]\n"
+	require.NoError(t, os.WriteFile(configPath, []byte(manifest), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "layers"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "layers", "personal.yaml"), []byte("name: personal\n"), 0o644))
+
+	cmd := exec.Command("git", "init")
+	cmd.Dir = tmpDir
+	require.NoError(t, cmd.Run())
+
+	require.NoError(t, checkSyncExclusionLeaks(context.Background(), configPath), "untracked layers are not pushed")
+
+	cmd = exec.Command("git", "add", "layers/personal.yaml")
+	cmd.Dir = tmpDir
+	require.NoError(t, cmd.Run())
+
+	var err error
+	out := capturePluginStdout(t, func() {
+		err = checkSyncExclusionLeaks(context.Background(), configPath)
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "refusing to push 1 excluded item(s)")
+	assert.Contains(t, out, "- layers/personal.yaml")
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var targetsCmd = &cobra.Command{
+	Use:   "targets",
+	Short: "List targets and show how they resolve",
+	Long: `List the targets of preflight.yaml and show how they resolve.
+
+A target lists layers and roles, or extends other targets and adds layers:
+
+  targets:
+    work: [base, identity.work]
+    work_laptop:
+      extends: work
+      add: [laptop]
+
+Examples:
+  preflight targets list                          # All targets
+  preflight targets show work_laptop              # How a target is defined
+  preflight targets show work_laptop --resolved   # Final layers and merged config`,
+}
+
+var targetsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List targets with what they extend and their layer count",
+	Args:  cobra.NoArgs,
+	RunE:  runTargetsList,
+}
+
+var targetsShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Show a target's definition, or its resolved layers and config",
+	Long: `Show a target's definition.
+
+With --resolved, show the final ordered list of layers, including layers
+added through requires, and the configuration merged from them.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTargetsShow,
+}
+
+var (
+	targetsConfigPath string
+	targetsJSON       bool
+	targetsResolved   bool
+)
+
+func init() {
+	rootCmd.AddCommand(targetsCmd)
+	targetsCmd.AddCommand(targetsListCmd)
+	targetsCmd.AddCommand(targetsShowCmd)
+
+	targetsCmd.PersistentFlags().StringVarP(&targetsConfigPath, "config", "c", "preflight.yaml", "Path to preflight.yaml")
+	targetsCmd.PersistentFlags().BoolVar(&targetsJSON, "json", false, "Output as JSON")
+	targetsShowCmd.Flags().BoolVar(&targetsResolved, "resolved", false, "Show the final layers and merged config")
+}
+
+// targetJSON is the JSON form of a target.
+type targetJSON struct {
+	Name    string                 `json:"name"`
+	Extends []string               `json:"extends,omitempty"`
+	Add     []string               `json:"add,omitempty"`
+	Layers  []targetLayerJSON      `json:"layers"`
+	Config  map[string]interface{} `json:"config,omitempty"`
+}
+
+// targetLayerJSON is a layer of a target. Required marks a layer that only
+// requires brought in.
+type targetLayerJSON struct {
+	Name     string `json:"name"`
+	Source   string `json:"source,omitempty"`
+	Required bool   `json:"required,omitempty"`
+}
+
+func runTargetsList(_ *cobra.Command, _ []string) error {
+	manifest, err := config.NewLoader().LoadManifest(targetsConfigPath)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(manifest.Targets))
+	for name := range manifest.Targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if targetsJSON {
+		targets := make([]targetJSON, 0, len(names))
+		for _, name := range names {
+			targets = append(targets, describeTarget(manifest, name))
+		}
+		return writeTargetsJSON(targets)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "TARGET\tEXTENDS\tLAYERS")
+	for _, name := range names {
+		extends := "-"
+		if ext, ok := manifest.Extensions[name]; ok {
+			extends = strings.Join(ext.Extends, ", ")
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%d\n", name, extends, len(manifest.Targets[name]))
+	}
+	return w.Flush()
+}
+
+func runTargetsShow(_ *cobra.Command, args []string) error {
+	loader := config.NewLoader()
+	manifest, err := loader.LoadManifest(targetsConfigPath)
+	if err != nil {
+		return err
+	}
+	name := args[0]
+	if _, ok := manifest.Targets[name]; !ok {
+		available := make([]string, 0, len(manifest.Targets))
+		for n := range manifest.Targets {
+			available = append(available, n)
+		}
+		sort.Strings(available)
+		return config.NewTargetNotFoundError(name, available)
+	}
+
+	target := describeTarget(manifest, name)
+	if targetsResolved {
+		if err := resolveTargetLayers(loader, manifest, &target); err != nil {
+			return err
+		}
+	}
+
+	if targetsJSON {
+		return writeTargetsJSON(target)
+	}
+
+	fmt.Printf("Target: %s\n", target.Name)
+	if len(target.Extends) > 0 {
+		fmt.Printf("Extends: %s\n", strings.Join(target.Extends, ", "))
+		if len(target.Add) > 0 {
+			fmt.Printf("Adds: %s\n", strings.Join(target.Add, ", "))
+		}
+	}
+	fmt.Println()
+	if !targetsResolved {
+		fmt.Println("Layers:")
+		for _, layer := range target.Layers {
+			fmt.Printf("  %s\n", layer.Name)
+		}
+		return nil
+	}
+
+	fmt.Println("Layers (in merge order):")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for i, layer := range target.Layers {
+		note := ""
+		if layer.Required {
+			note = "\t(required by another layer)"
+		}
+		_, _ = fmt.Fprintf(w, "  %d.\t%s\t%s%s\n", i+1, layer.Name, layer.Source, note)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	out, err := yaml.Marshal(target.Config)
+	if err != nil {
+		return err
+	}
+	fmt.Println()
+	fmt.Println("Merged config:")
+	fmt.Print(string(out))
+	return nil
+}
+
+// describeTarget returns a target as the manifest defines it, with the
+// layers its roles and extended targets resolve to.
+func describeTarget(manifest *config.Manifest, name string) targetJSON {
+	target := targetJSON{Name: name}
+	if ext, ok := manifest.Extensions[name]; ok {
+		target.Extends = ext.Extends
+		target.Add = ext.Add
+	}
+	for _, ln := range manifest.Targets[name] {
+		target.Layers = append(target.Layers, targetLayerJSON{Name: ln.String()})
+	}
+	return target
+}
+
+// resolveTargetLayers loads the layers of target in merge order, with the
+// layers requires adds, and merges its configuration.
+func resolveTargetLayers(loader *config.Loader, manifest *config.Manifest, target *targetJSON) error {
+	name, err := config.NewTargetName(target.Name)
+	if err != nil {
+		return fmt.Errorf("invalid target name: %w", err)
+	}
+	configDir := filepath.Dir(targetsConfigPath)
+	resolved, err := loader.LoadTarget(manifest, name, filepath.Join(configDir, "layers"))
+	if err != nil {
+		return err
+	}
+	merged, err := config.NewMerger().WithDuplicateStrategy(manifest.Defaults.Duplicates).Merge(resolved.Layers)
+	if err != nil {
+		return err
+	}
+
+	listed := manifest.Targets[target.Name]
+	target.Layers = make([]targetLayerJSON, 0, len(resolved.Layers))
+	for _, layer := range resolved.Layers {
+		source := layer.Provenance
+		if rel, err := filepath.Rel(configDir, source); err == nil {
+			source = rel
+		}
+		target.Layers = append(target.Layers, targetLayerJSON{
+			Name:     layer.Name.String(),
+			Source:   source,
+			Required: !slices.Contains(listed, layer.Name),
+		})
+	}
+	target.Config = merged.Raw()
+	return nil
+}
+
+func writeTargetsJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
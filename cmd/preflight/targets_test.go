@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupTargets writes a config where work_laptop extends work and dev-node
+// requires node.
+func setupTargets(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "layers"), 0o755))
+	files := map[string]string{
+		"preflight.yaml":        "defaults:\n  requires: include\ntargets:\n  work: [base, dev-node]\n  work_laptop:\n    extends: work\n    add: [laptop]\n",
+		"layers/base.yaml":      "name: base\npackages:\n  brew:\n    formulae: [git]\n",
+		"layers/node.yaml":      "name: node\npackages:\n  brew:\n    formulae: [node]\n",
+		"layers/dev-node.yaml":  "name: dev-node\nrequires: [node]\npackages:\n  npm:\n    packages: [pnpm]\n",
+		"layers/laptop.yaml":    "name: laptop\nshell:\n  env:\n    POWER: saver\n",
+		"layers/unrelated.yaml": "name: unrelated\n",
+	}
+	for path, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, path), []byte(content), 0o644))
+	}
+	return filepath.Join(dir, "preflight.yaml")
+}
+
+func setTargetsFlags(t *testing.T, configPath string, asJSON, resolved bool) {
+	t.Helper()
+	targetsConfigPath = configPath
+	targetsJSON = asJSON
+	targetsResolved = resolved
+	t.Cleanup(func() {
+		targetsConfigPath = "preflight.yaml"
+		targetsJSON = false
+		targetsResolved = false
+	})
+}
+
+func TestRunTargetsList(t *testing.T) {
+	setTargetsFlags(t, setupTargets(t), false, false)
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runTargetsList(targetsListCmd, nil))
+	})
+	assert.Contains(t, out, "TARGET")
+	assert.Regexp(t, `work\s+-\s+2\n`, out)
+	assert.Regexp(t, `work_laptop\s+work\s+3\n`, out)
+}
+
+func TestRunTargetsShow(t *testing.T) {
+	setTargetsFlags(t, setupTargets(t), false, false)
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runTargetsShow(targetsShowCmd, []string{"work_laptop"}))
+	})
+	assert.Contains(t, out, "Extends: work\nAdds: laptop\n")
+	assert.Contains(t, out, "Layers:\n  base\n  dev-node\n  laptop\n")
+}
+
+func TestRunTargetsShow_Resolved(t *testing.T) {
+	setTargetsFlags(t, setupTargets(t), false, true)
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runTargetsShow(targetsShowCmd, []string{"work_laptop"}))
+	})
+	assert.Regexp(t, `1\.\s+base\s+layers/base\.yaml\n`, out)
+	assert.Regexp(t, `2\.\s+node\s+layers/node\.yaml\s+\(required by another layer\)\n`, out)
+	assert.Regexp(t, `3\.\s+dev-node\s+layers/dev-node\.yaml\n`, out)
+	assert.Regexp(t, `4\.\s+laptop\s+layers/laptop\.yaml\n`, out)
+	assert.Contains(t, out, "Merged config:")
+	assert.Contains(t, out, "POWER: saver")
+}
+
+func TestRunTargetsShow_ResolvedJSON(t *testing.T) {
+	setTargetsFlags(t, setupTargets(t), true, true)
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runTargetsShow(targetsShowCmd, []string{"work_laptop"}))
+	})
+	var target targetJSON
+	require.NoError(t, json.Unmarshal([]byte(out), &target))
+	assert.Equal(t, []string{"work"}, target.Extends)
+	require.Len(t, target.Layers, 4)
+	assert.Equal(t, targetLayerJSON{Name: "node", Source: filepath.Join("layers", "node.yaml"), Required: true}, target.Layers[1])
+	assert.Contains(t, target.Config, "npm")
+}
+
+func TestRunTargetsShow_UnknownTarget(t *testing.T) {
+	setTargetsFlags(t, setupTargets(t), false, false)
+
+	err := runTargetsShow(targetsShowCmd, []string{"home"})
+	require.Error(t, err)
+	require.True(t, config.IsUserError(err, config.ErrCodeTargetNotFound))
+	assert.Equal(t, "Available targets: work, work_laptop", config.GetUserError(err).Suggestion)
+}
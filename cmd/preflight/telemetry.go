@@ -1,9 +1,7 @@
 package main
 
 import (
-	"os"
-	"path/filepath"
-
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
 	"github.com/felixgeelhaar/preflight/internal/telemetry"
 )
 
@@ -17,17 +15,11 @@ import (
 // world-writable roots like /tmp because those are exploitable as
 // consent-spoof + symlink redirect vectors on shared hosts.
 func preflightConfigDir() string {
-	if explicit := os.Getenv("PREFLIGHT_HOME"); explicit != "" {
-		if !filepath.IsAbs(explicit) {
-			return ""
-		}
-		return explicit
-	}
-	home, err := os.UserHomeDir()
+	dir, err := platform.Dir(platform.ConfigDir)
 	if err != nil {
 		return ""
 	}
-	return filepath.Join(home, ".preflight")
+	return dir
 }
 
 // recordEvent fires a telemetry event if the user has opted in. Safe to call
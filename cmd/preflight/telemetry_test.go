@@ -21,13 +21,13 @@ func TestPreflightConfigDir_HonorsAbsolutePREFLIGHT_HOME(t *testing.T) {
 	}
 }
 
-func TestPreflightConfigDir_DefaultsToHomeDotPreflight(t *testing.T) {
+func TestPreflightConfigDir_DefaultsToXDGConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
 	t.Setenv("PREFLIGHT_HOME", "")
-	got := preflightConfigDir()
-	if got == "" {
-		t.Fatal("expected non-empty config dir, got empty")
-	}
-	if filepath.Base(got) != ".preflight" {
-		t.Errorf("preflightConfigDir() = %q, expected suffix '/.preflight'", got)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	want := filepath.Join(home, ".config", "preflight")
+	if got := preflightConfigDir(); got != want {
+		t.Errorf("preflightConfigDir() = %q, want %q", got, want)
 	}
 }
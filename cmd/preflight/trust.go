@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,13 +11,14 @@ import (
 	"time"
 
 	"github.com/felixgeelhaar/preflight/internal/domain/catalog"
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
 	"github.com/spf13/cobra"
 )
 
 var trustCmd = &cobra.Command{
 	Use:   "trust",
 	Short: "Manage trusted catalog publishers",
-	Long: `Manage trusted public keys for catalog signature verification.
+	Long: `Manage trusted public keys and Sigstore identities for signature verification.
 
 Trust levels determine how catalogs are verified:
   builtin    - Embedded in the preflight binary
@@ -27,6 +29,9 @@ Trust levels determine how catalogs are verified:
 Examples:
   preflight trust list                    # List trusted keys
   preflight trust add <keyfile>           # Add a trusted key
+  preflight trust add --issuer <url> --identity <subject>
+                                          # Trust keyless Sigstore signatures
+  preflight trust sigstore-root <file>    # Import the Sigstore trusted root
   preflight trust remove <keyid>          # Remove a trusted key
   preflight trust show <keyid>            # Show key details`,
 }
@@ -42,19 +47,30 @@ Shows key ID, type, publisher, trust level, and status.`,
 }
 
 var trustAddCmd = &cobra.Command{
-	Use:   "add <keyfile>",
-	Short: "Add a trusted key",
-	Long: `Add a public key to the trust store.
+	Use:   "add [keyfile]",
+	Short: "Add a trusted key or Sigstore identity",
+	Long: `Add a public key or a Sigstore identity to the trust store.
 
 Supported key formats:
   - SSH public keys (id_ed25519.pub, id_rsa.pub)
   - GPG public keys (armored or binary)
 
+Instead of a key file, --issuer with --identity or --identity-regexp trusts
+keyless Sigstore signatures made by an OIDC identity, such as a CI workflow,
+without distributing keys. The Fulcio certificate of a signature must have
+been issued by the OIDC issuer for a subject that the identity matches in
+full. Keyless signatures are checked against the Sigstore trusted root
+imported with 'preflight trust sigstore-root'.
+
 Examples:
   preflight trust add ~/.ssh/id_ed25519.pub
   preflight trust add publisher.gpg --name "Publisher Name"
-  preflight trust add key.pub --level verified`,
-	Args: cobra.ExactArgs(1),
+  preflight trust add key.pub --level verified
+  preflight trust add --issuer https://token.actions.githubusercontent.com \
+    --identity-regexp 'https://github.com/acme/catalog/\.github/workflows/.+' \
+    --name acme-catalog --level verified
+  preflight trust add --issuer https://accounts.google.com --identity security@acme.com`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: runTrustAdd,
 }
 
@@ -82,6 +98,24 @@ Examples:
 	RunE: runTrustShow,
 }
 
+var trustSigstoreRootCmd = &cobra.Command{
+	Use:   "sigstore-root [trusted_root.json]",
+	Short: "Import or show the Sigstore trusted root",
+	Long: `Import the Sigstore trusted root that keyless signatures are verified
+against, or show the imported one when no file is given.
+
+The trusted root is a Sigstore trusted_root.json listing the Fulcio
+certificate authorities and Rekor transparency logs to trust, such as the
+one published in the TUF repository of the Sigstore public-good instance,
+or one written by 'cosign trusted-root create' for a private deployment.
+
+Examples:
+  preflight trust sigstore-root trusted_root.json
+  preflight trust sigstore-root`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runTrustSigstoreRoot,
+}
+
 // Flags
 var (
 	trustKeyName  string
@@ -89,6 +123,10 @@ var (
 	trustKeyType  string
 	trustEmail    string
 	trustForce    bool
+
+	trustIssuer         string
+	trustIdentity       string
+	trustIdentityRegexp string
 )
 
 func init() {
@@ -97,6 +135,9 @@ func init() {
 	trustAddCmd.Flags().StringVar(&trustEmail, "email", "", "Publisher email")
 	trustAddCmd.Flags().StringVar(&trustKeyLevel, "level", "community", "Trust level (builtin, verified, community)")
 	trustAddCmd.Flags().StringVar(&trustKeyType, "type", "", "Key type (ssh, gpg) - auto-detected if not specified")
+	trustAddCmd.Flags().StringVar(&trustIssuer, "issuer", "", "OIDC issuer of a trusted Sigstore identity")
+	trustAddCmd.Flags().StringVar(&trustIdentity, "identity", "", "OIDC subject (email or URI) of a trusted Sigstore identity")
+	trustAddCmd.Flags().StringVar(&trustIdentityRegexp, "identity-regexp", "", "Regular expression matching trusted OIDC subjects in full")
 
 	trustRemoveCmd.Flags().BoolVar(&trustForce, "force", false, "Skip confirmation")
 
@@ -105,18 +146,17 @@ func init() {
 	trustCmd.AddCommand(trustAddCmd)
 	trustCmd.AddCommand(trustRemoveCmd)
 	trustCmd.AddCommand(trustShowCmd)
+	trustCmd.AddCommand(trustSigstoreRootCmd)
 
 	rootCmd.AddCommand(trustCmd)
 }
 
 // getTrustStore returns the trust store with keys loaded.
 func getTrustStore() (*catalog.TrustStore, error) {
-	homeDir, err := os.UserHomeDir()
+	storePath, err := platform.Path(platform.ConfigDir, "trust.json")
 	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
+		return nil, err
 	}
-
-	storePath := filepath.Join(homeDir, ".preflight", "trust.json")
 	store := catalog.NewTrustStore(storePath)
 
 	if err := store.Load(); err != nil {
@@ -126,6 +166,20 @@ func getTrustStore() (*catalog.TrustStore, error) {
 	return store, nil
 }
 
+// getKeylessVerifier returns a verifier for keyless Sigstore signatures by
+// the identities in the trust store.
+func getKeylessVerifier() (*catalog.SigstoreVerifier, error) {
+	store, err := getTrustStore()
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := store.KeylessVerifier()
+	if errors.Is(err, catalog.ErrSigstoreNoTrustedRoot) {
+		return nil, fmt.Errorf("%w; import one with 'preflight trust sigstore-root <trusted_root.json>'", err)
+	}
+	return verifier, err
+}
+
 func runTrustList(_ *cobra.Command, _ []string) error {
 	store, err := getTrustStore()
 	if err != nil {
@@ -181,6 +235,15 @@ func runTrustList(_ *cobra.Command, _ []string) error {
 }
 
 func runTrustAdd(_ *cobra.Command, args []string) error {
+	if trustIssuer != "" || trustIdentity != "" || trustIdentityRegexp != "" {
+		if len(args) > 0 {
+			return fmt.Errorf("pass either a key file or --issuer with --identity, not both")
+		}
+		return runTrustAddIdentity()
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("a key file is required, or --issuer with --identity to trust a Sigstore identity")
+	}
 	keyFile := args[0]
 
 	// Read key file
@@ -252,6 +315,102 @@ func runTrustAdd(_ *cobra.Command, args []string) error {
 	return nil
 }
 
+// runTrustAddIdentity adds the Sigstore identity given by the --issuer,
+// --identity and --identity-regexp flags.
+func runTrustAddIdentity() error {
+	if trustIssuer == "" {
+		return fmt.Errorf("--issuer is required with --identity and --identity-regexp")
+	}
+	if (trustIdentity == "") == (trustIdentityRegexp == "") {
+		return fmt.Errorf("pass one of --identity or --identity-regexp with --issuer")
+	}
+
+	identity := catalog.ExactSigstoreIdentity(trustIssuer, trustIdentity)
+	keyID := trustIdentity
+	if trustIdentityRegexp != "" {
+		var err error
+		if identity, err = catalog.SigstoreIdentityRegexp(trustIssuer, trustIdentityRegexp); err != nil {
+			return err
+		}
+		keyID = trustIdentityRegexp
+	}
+	if trustKeyName != "" {
+		keyID = trustKeyName
+	}
+
+	level, err := catalog.TrustLevelFromString(trustKeyLevel)
+	if err != nil {
+		return err
+	}
+
+	key := catalog.NewTrustedIdentity(keyID, identity)
+	key.SetTrustLevel(level)
+
+	store, err := getTrustStore()
+	if err != nil {
+		return err
+	}
+	if err := store.Add(key); err != nil {
+		return fmt.Errorf("failed to add identity: %w", err)
+	}
+	if err := store.Save(); err != nil {
+		return fmt.Errorf("failed to save trust store: %w", err)
+	}
+
+	fmt.Printf("Added identity: %s\n", keyID)
+	fmt.Printf("  Issuer: %s\n", trustIssuer)
+	if trustIdentityRegexp != "" {
+		fmt.Printf("  Subject pattern: %s\n", trustIdentityRegexp)
+	} else {
+		fmt.Printf("  Subject: %s\n", trustIdentity)
+	}
+	fmt.Printf("  Trust level: %s\n", level)
+
+	if _, err := store.SigstoreRoot(); errors.Is(err, catalog.ErrSigstoreNoTrustedRoot) {
+		fmt.Println("\nImport a Sigstore trusted root with 'preflight trust sigstore-root <trusted_root.json>' to verify keyless signatures.")
+	}
+	return nil
+}
+
+func runTrustSigstoreRoot(_ *cobra.Command, args []string) error {
+	store, err := getTrustStore()
+	if err != nil {
+		return err
+	}
+
+	var root *catalog.SigstoreTrustedRoot
+	if len(args) == 1 {
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to read trusted root: %w", err)
+		}
+		if root, err = store.ImportSigstoreRoot(data); err != nil {
+			return err
+		}
+		fmt.Printf("Imported Sigstore trusted root to %s\n", store.SigstoreRootPath())
+	} else {
+		root, err = store.SigstoreRoot()
+		if errors.Is(err, catalog.ErrSigstoreNoTrustedRoot) {
+			fmt.Println("No Sigstore trusted root. Import one with 'preflight trust sigstore-root <trusted_root.json>'.")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Sigstore trusted root: %s\n", store.SigstoreRootPath())
+	}
+
+	fmt.Println("\nCertificate authorities:")
+	for _, uri := range root.Authorities() {
+		fmt.Printf("  %s\n", uri)
+	}
+	fmt.Println("\nTransparency logs:")
+	for _, url := range root.Logs() {
+		fmt.Printf("  %s\n", url)
+	}
+	return nil
+}
+
 func runTrustRemove(_ *cobra.Command, args []string) error {
 	keyID := args[0]
 
@@ -308,9 +467,18 @@ func runTrustShow(_ *cobra.Command, args []string) error {
 
 	fmt.Printf("Key ID:      %s\n", key.KeyID())
 	fmt.Printf("Type:        %s\n", key.KeyType())
-	fmt.Printf("Fingerprint: %s\n", key.Fingerprint())
+	identity := key.Identity()
+	if identity.IssuerRegexp == "" {
+		fmt.Printf("Fingerprint: %s\n", key.Fingerprint())
+	}
 	fmt.Printf("Trust Level: %s\n", key.TrustLevel())
 
+	if identity.IssuerRegexp != "" {
+		fmt.Println("\nIdentity (regular expressions):")
+		fmt.Printf("  Issuer:  %s\n", identity.IssuerRegexp)
+		fmt.Printf("  Subject: %s\n", identity.SubjectRegexp)
+	}
+
 	if !key.Publisher().IsZero() {
 		fmt.Println("\nPublisher:")
 		if key.Publisher().Name() != "" {
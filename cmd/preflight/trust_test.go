@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/felixgeelhaar/preflight/internal/domain/catalog"
+	"github.com/felixgeelhaar/preflight/internal/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -304,3 +305,77 @@ func TestTrustRemoveCmd_HasAliases(t *testing.T) {
 
 	assert.Contains(t, trustRemoveCmd.Aliases, "rm")
 }
+
+func TestRunTrustAddIdentityAndSigstoreRoot(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	originalName := trustKeyName
+	originalLevel := trustKeyLevel
+	originalIssuer := trustIssuer
+	originalIdentity := trustIdentity
+	originalRegexp := trustIdentityRegexp
+	defer func() {
+		trustKeyName = originalName
+		trustKeyLevel = originalLevel
+		trustIssuer = originalIssuer
+		trustIdentity = originalIdentity
+		trustIdentityRegexp = originalRegexp
+	}()
+
+	trustKeyName = "acme-catalog"
+	trustKeyLevel = "verified"
+	trustIssuer = "https://token.actions.githubusercontent.com"
+	trustIdentity = ""
+	trustIdentityRegexp = `https://github\.com/acme/.+`
+
+	require.Error(t, runTrustAdd(nil, []string{"key.pub"}))
+
+	output := captureStdout(t, func() {
+		require.NoError(t, runTrustAdd(nil, nil))
+	})
+	assert.Contains(t, output, "Added identity: acme-catalog")
+	assert.Contains(t, output, "preflight trust sigstore-root")
+
+	output = captureStdout(t, func() {
+		require.NoError(t, runTrustShow(nil, []string{"acme-catalog"}))
+	})
+	assert.Contains(t, output, "Type:        sigstore")
+	assert.Contains(t, output, "Identity (regular expressions):")
+	assert.Contains(t, output, `Issuer:  ^https://token\.actions\.githubusercontent\.com$`)
+
+	rootFile := filepath.Join(tmpDir, "trusted_root.json")
+	require.NoError(t, os.WriteFile(rootFile, testutil.NewSigstoreFixture(t).TrustedRoot, 0o600))
+	output = captureStdout(t, func() {
+		require.NoError(t, runTrustSigstoreRoot(nil, []string{rootFile}))
+	})
+	assert.Contains(t, output, "https://rekor.example.com")
+
+	store, err := getTrustStore()
+	require.NoError(t, err)
+	verifier, err := store.KeylessVerifier()
+	require.NoError(t, err)
+	assert.True(t, verifier.SupportsType(catalog.SignatureTypeSigstore))
+}
+
+func TestRunTrustAddIdentity_RequiresOneSubject(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	originalIssuer := trustIssuer
+	originalIdentity := trustIdentity
+	originalRegexp := trustIdentityRegexp
+	defer func() {
+		trustIssuer = originalIssuer
+		trustIdentity = originalIdentity
+		trustIdentityRegexp = originalRegexp
+	}()
+
+	trustIssuer = "https://accounts.google.com"
+	trustIdentity = ""
+	trustIdentityRegexp = ""
+	require.Error(t, runTrustAdd(nil, nil))
+
+	trustIssuer = ""
+	trustIdentity = "dev@acme.com"
+	require.Error(t, runTrustAdd(nil, nil))
+}
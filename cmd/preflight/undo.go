@@ -0,0 +1,344 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/adapters/command"
+	"github.com/felixgeelhaar/preflight/internal/adapters/runlock"
+	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/spf13/cobra"
+)
+
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Revert the most recent apply",
+	Long: `Undo reverts the most recent apply recorded in the history that has not
+been undone yet:
+
+  - packages it installed are uninstalled
+  - files it changed are restored from the snapshot taken before it ran
+  - files it created are removed
+  - the lockfile it updated is restored
+
+Changes that cannot be reverted automatically, such as package upgrades or
+system settings, are listed so they can be reverted by hand. Running undo
+again reverts the apply before that.
+
+Examples:
+  preflight undo --dry-run   # Preview what would be reverted
+  preflight undo             # Revert after confirmation
+  preflight undo --yes       # Revert without confirmation`,
+	RunE: runUndo,
+}
+
+var (
+	undoDryRun bool
+	undoWait   bool
+)
+
+// undoRunner runs the uninstall commands of an undo.
+var undoRunner ports.CommandRunner = command.NewRealRunner()
+
+func init() {
+	undoCmd.Flags().BoolVar(&undoDryRun, "dry-run", false, "Preview what would be reverted without making changes")
+	undoCmd.Flags().BoolVar(&undoWait, "wait", false, "Wait for another running preflight process instead of failing")
+
+	rootCmd.AddCommand(undoCmd)
+}
+
+// LockfileBackup is the lockfile as it was before an apply updated it.
+type LockfileBackup struct {
+	Path    string `json:"path"`
+	Existed bool   `json:"existed"`
+	Content string `json:"content,omitempty"`
+}
+
+// backupLockfile returns the current lockfile of the config at configPath.
+func backupLockfile(configPath string) *LockfileBackup {
+	path := strings.TrimSuffix(configPath, filepath.Ext(configPath)) + ".lock"
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	// #nosec G304 -- the lockfile sits next to the user's config.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return &LockfileBackup{Path: path}
+	}
+	return &LockfileBackup{Path: path, Existed: true, Content: string(data)}
+}
+
+// undoPlan is what undoing an apply reverts.
+type undoPlan struct {
+	entry      HistoryEntry
+	restore    []string
+	remove     []string
+	uninstalls []app.Uninstall
+	lockfile   *LockfileBackup
+	// manual lists the applied steps undo cannot revert.
+	manual []string
+}
+
+func (p undoPlan) empty() bool {
+	return len(p.restore) == 0 && len(p.remove) == 0 && len(p.uninstalls) == 0 && p.lockfile == nil
+}
+
+func runUndo(_ *cobra.Command, _ []string) error {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	entry, err := lastUndoableApply()
+	if err != nil {
+		return err
+	}
+	snapshots, err := app.DefaultSnapshotService()
+	if err != nil {
+		return fmt.Errorf("failed to initialize snapshot service: %w", err)
+	}
+	plan := newUndoPlan(ctx, entry, snapshots)
+	outputUndoPlan(plan)
+
+	if plan.empty() {
+		fmt.Println("\nNothing can be reverted automatically.")
+		return nil
+	}
+	if undoDryRun {
+		fmt.Println("\n--dry-run: No changes made.")
+		return nil
+	}
+	if !yesFlag {
+		fmt.Print("\nRevert these changes? [y/N] ")
+		var response string
+		_, _ = fmt.Scanln(&response)
+		response = strings.ToLower(strings.TrimSpace(response))
+		if response != "y" && response != "yes" {
+			fmt.Println("Undo cancelled.")
+			return nil
+		}
+	}
+
+	runLock, err := acquireUndoRunLock(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = runLock.Release() }()
+
+	started := time.Now()
+	record := executeUndoPlan(ctx, plan, snapshots)
+	record.Duration = time.Since(started).Round(time.Millisecond).String()
+	if err := SaveHistoryEntry(record); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not record history: %v\n", err)
+	}
+
+	if record.Status != "success" {
+		return fmt.Errorf("undo of %s did not complete: %s", entry.ID, record.Error)
+	}
+	fmt.Printf("\n✓ Reverted apply %s\n", entry.ID)
+	return nil
+}
+
+// lastUndoableApply returns the full entry of the most recent apply that
+// changed something and has not been undone.
+func lastUndoableApply() (HistoryEntry, error) {
+	entries, err := loadHistory()
+	if err != nil {
+		return HistoryEntry{}, fmt.Errorf("failed to load history: %w", err)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.After(entries[j].Timestamp)
+	})
+	undone := make(map[string]bool)
+	for _, e := range entries {
+		if e.Command == "undo" && e.Undoes != "" && e.Status != "failed" {
+			undone[e.Undoes] = true
+		}
+	}
+	for _, e := range entries {
+		if e.Command != "apply" || e.Status == "failed" || undone[e.ID] {
+			continue
+		}
+		return loadHistoryEntry(e.ID)
+	}
+	return HistoryEntry{}, fmt.Errorf("no apply to undo in the history")
+}
+
+// newUndoPlan works out how to revert entry.
+func newUndoPlan(ctx context.Context, entry HistoryEntry, snapshots *app.SnapshotService) undoPlan {
+	plan := undoPlan{entry: entry, lockfile: entry.Lockfile}
+
+	snapshotted := make(map[string]bool)
+	if entry.Snapshot != "" {
+		if set, err := snapshots.GetSnapshotSet(ctx, entry.Snapshot); err == nil {
+			for _, path := range set.Paths() {
+				snapshotted[path] = true
+			}
+		}
+	}
+
+	reverted := make(map[string]bool)
+	for _, file := range entry.Files {
+		switch {
+		case file.Created:
+			plan.remove = append(plan.remove, file.Path)
+		case snapshotted[file.Path]:
+			plan.restore = append(plan.restore, file.Path)
+		default:
+			continue
+		}
+		reverted[file.StepID] = true
+	}
+	// Uninstall in reverse order of installation so that packages go before
+	// the ones they were installed after.
+	for i := len(entry.Uninstalls) - 1; i >= 0; i-- {
+		plan.uninstalls = append(plan.uninstalls, entry.Uninstalls[i])
+		reverted[entry.Uninstalls[i].StepID] = true
+	}
+	for _, step := range entry.Steps {
+		if step.Applied && !reverted[step.ID] {
+			plan.manual = append(plan.manual, step.ID)
+		}
+	}
+	return plan
+}
+
+func outputUndoPlan(plan undoPlan) {
+	entry := plan.entry
+	fmt.Printf("Undo apply %s (%s", entry.ID, entry.Timestamp.Format("2006-01-02 15:04:05"))
+	if entry.Target != "" {
+		fmt.Printf(", target %s", entry.Target)
+	}
+	fmt.Println(")")
+
+	if len(plan.uninstalls) > 0 {
+		fmt.Println("\nUninstall:")
+		for _, u := range plan.uninstalls {
+			fmt.Printf("  - %s  (%s)\n", u.StepID, strings.Join(u.Command, " "))
+		}
+	}
+	if len(plan.restore) > 0 {
+		fmt.Println("\nRestore from snapshot:")
+		for _, path := range plan.restore {
+			fmt.Printf("  ~ %s\n", path)
+		}
+	}
+	if len(plan.remove) > 0 {
+		fmt.Println("\nRemove created files:")
+		for _, path := range plan.remove {
+			fmt.Printf("  - %s\n", path)
+		}
+	}
+	if plan.lockfile != nil {
+		fmt.Println("\nRestore lockfile:")
+		fmt.Printf("  ~ %s\n", plan.lockfile.Path)
+	}
+	if len(plan.manual) > 0 {
+		fmt.Println("\nNot reverted (revert by hand):")
+		for _, id := range plan.manual {
+			fmt.Printf("  ! %s\n", id)
+		}
+	}
+}
+
+// executeUndoPlan reverts the changes of plan, continuing past failures,
+// and returns the history entry recording the undo.
+func executeUndoPlan(ctx context.Context, plan undoPlan, snapshots *app.SnapshotService) HistoryEntry {
+	record := HistoryEntry{
+		Timestamp: time.Now(),
+		Command:   "undo",
+		Target:    plan.entry.Target,
+		Undoes:    plan.entry.ID,
+	}
+	var errs []error
+	fail := func(err error) {
+		errs = append(errs, err)
+		fmt.Printf("  ✗ %v\n", err)
+	}
+	done := func(change Change) {
+		record.Changes = append(record.Changes, change)
+		fmt.Printf("  ✓ %s %s\n", change.Action, change.Item)
+	}
+
+	fmt.Println("\nReverting...")
+	for _, u := range plan.uninstalls {
+		provider, _, _ := strings.Cut(u.StepID, ":")
+		result, err := undoRunner.Run(ctx, u.Command[0], u.Command[1:]...)
+		switch {
+		case err != nil:
+			fail(fmt.Errorf("uninstall %s: %w", u.StepID, err))
+		case !result.Success():
+			fail(fmt.Errorf("uninstall %s: %s", u.StepID, strings.TrimSpace(result.Stderr)))
+		default:
+			done(Change{Provider: provider, Action: "remove", Item: u.StepID})
+		}
+	}
+	if len(plan.restore) > 0 {
+		if err := snapshots.Restore(ctx, plan.entry.Snapshot); err != nil {
+			fail(fmt.Errorf("restore snapshot %s: %w", plan.entry.Snapshot, err))
+		} else {
+			for _, path := range plan.restore {
+				done(Change{Provider: "files", Action: "modify", Item: path})
+			}
+		}
+	}
+	for _, path := range plan.remove {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			fail(fmt.Errorf("remove %s: %w", path, err))
+			continue
+		}
+		done(Change{Provider: "files", Action: "remove", Item: path})
+	}
+	if plan.lockfile != nil {
+		if err := restoreLockfile(plan.lockfile); err != nil {
+			fail(fmt.Errorf("restore lockfile: %w", err))
+		} else {
+			done(Change{Provider: "lockfile", Action: "modify", Item: plan.lockfile.Path})
+		}
+	}
+
+	switch {
+	case len(errs) == 0:
+		record.Status = "success"
+	case len(record.Changes) > 0:
+		record.Status = "partial"
+	default:
+		record.Status = "failed"
+	}
+	if err := errors.Join(errs...); err != nil {
+		record.Error = err.Error()
+	}
+	return record
+}
+
+func restoreLockfile(backup *LockfileBackup) error {
+	if !backup.Existed {
+		if err := os.Remove(backup.Path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return os.WriteFile(backup.Path, []byte(backup.Content), 0o644)
+}
+
+func acquireUndoRunLock(ctx context.Context) (*runlock.Lock, error) {
+	path, err := runlock.DefaultPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate run lock: %w", err)
+	}
+	if undoWait {
+		return runlock.Wait(ctx, path)
+	}
+	runLock, err := runlock.Acquire(path)
+	if errors.Is(err, runlock.ErrLocked) {
+		return nil, fmt.Errorf("%w; wait for it to finish or rerun with --wait", err)
+	}
+	return runLock, err
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/testutil/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLastUndoableApply_SkipsUndoneAndFailed(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	now := time.Now()
+
+	_, err := lastUndoableApply()
+	require.Error(t, err)
+
+	require.NoError(t, SaveHistoryEntry(HistoryEntry{ID: "1", Timestamp: now.Add(-3 * time.Hour), Command: "apply", Status: "success"}))
+	require.NoError(t, SaveHistoryEntry(HistoryEntry{ID: "2", Timestamp: now.Add(-2 * time.Hour), Command: "apply", Status: "partial"}))
+	require.NoError(t, SaveHistoryEntry(HistoryEntry{ID: "3", Timestamp: now.Add(-time.Hour), Command: "apply", Status: "failed"}))
+
+	entry, err := lastUndoableApply()
+	require.NoError(t, err)
+	assert.Equal(t, "2", entry.ID)
+
+	require.NoError(t, SaveHistoryEntry(HistoryEntry{ID: "4", Timestamp: now, Command: "undo", Status: "success", Undoes: "2"}))
+	entry, err = lastUndoableApply()
+	require.NoError(t, err)
+	assert.Equal(t, "1", entry.ID)
+}
+
+func TestUndo_RevertsApply(t *testing.T) {
+	dir := t.TempDir()
+	snapshots := app.NewSnapshotService(dir)
+	ctx := context.Background()
+
+	changed := filepath.Join(dir, "gitconfig")
+	created := filepath.Join(dir, "starship.toml")
+	require.NoError(t, os.WriteFile(changed, []byte("before\n"), 0o644))
+	set, err := snapshots.BeforeApply(ctx, []string{changed})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(changed, []byte("after\n"), 0o644))
+	require.NoError(t, os.WriteFile(created, []byte("new\n"), 0o644))
+
+	lockPath := filepath.Join(dir, "preflight.lock")
+	require.NoError(t, os.WriteFile(lockPath, []byte("updated\n"), 0o644))
+
+	entry := HistoryEntry{
+		ID:       "1",
+		Command:  "apply",
+		Snapshot: set.ID,
+		Files: []app.FileDiff{
+			{StepID: "git:config", Path: changed},
+			{StepID: "files:link:starship", Path: created, Created: true},
+		},
+		Uninstalls: []app.Uninstall{
+			{StepID: "brew:formula:ripgrep", Command: []string{"brew", "uninstall", "--formula", "ripgrep"}},
+			{StepID: "npm:package:typescript", Command: []string{"npm", "uninstall", "-g", "typescript"}},
+		},
+		Lockfile: &LockfileBackup{Path: lockPath, Existed: true, Content: "before\n"},
+		Steps: []StepTranscript{
+			{ID: "git:config", Applied: true},
+			{ID: "files:link:starship", Applied: true},
+			{ID: "brew:formula:ripgrep", Applied: true},
+			{ID: "npm:package:typescript", Applied: true},
+			{ID: "macos:defaults:dock", Applied: true},
+			{ID: "brew:formula:git", Status: "satisfied"},
+		},
+	}
+
+	plan := newUndoPlan(ctx, entry, snapshots)
+	assert.Equal(t, []string{changed}, plan.restore)
+	assert.Equal(t, []string{created}, plan.remove)
+	assert.Equal(t, "npm:package:typescript", plan.uninstalls[0].StepID, "uninstalls run in reverse order")
+	assert.Equal(t, []string{"macos:defaults:dock"}, plan.manual)
+
+	runner := mocks.NewCommandRunner()
+	runner.AddResult("brew", []string{"uninstall", "--formula", "ripgrep"}, ports.CommandResult{})
+	runner.AddResult("npm", []string{"uninstall", "-g", "typescript"}, ports.CommandResult{})
+	prevRunner := undoRunner
+	t.Cleanup(func() { undoRunner = prevRunner })
+	undoRunner = runner
+
+	var record HistoryEntry
+	captureStdout(t, func() {
+		record = executeUndoPlan(ctx, plan, snapshots)
+	})
+	assert.Equal(t, "success", record.Status)
+	assert.Equal(t, "1", record.Undoes)
+	assert.Len(t, record.Changes, 5)
+	assert.Len(t, runner.Calls(), 2)
+
+	data, err := os.ReadFile(changed)
+	require.NoError(t, err)
+	assert.Equal(t, "before\n", string(data))
+	_, err = os.Stat(created)
+	assert.True(t, os.IsNotExist(err))
+	data, err = os.ReadFile(lockPath)
+	require.NoError(t, err)
+	assert.Equal(t, "before\n", string(data))
+}
+
+func TestUndo_ReportsFailedUninstall(t *testing.T) {
+	runner := mocks.NewCommandRunner()
+	runner.AddResult("cargo", []string{"uninstall", "bat"}, ports.CommandResult{ExitCode: 101, Stderr: "package `bat` is not installed\n"})
+	prevRunner := undoRunner
+	t.Cleanup(func() { undoRunner = prevRunner })
+	undoRunner = runner
+
+	plan := undoPlan{
+		entry:      HistoryEntry{ID: "1"},
+		uninstalls: []app.Uninstall{{StepID: "cargo:crate:bat", Command: []string{"cargo", "uninstall", "bat"}}},
+	}
+	var record HistoryEntry
+	captureStdout(t, func() {
+		record = executeUndoPlan(context.Background(), plan, app.NewSnapshotService(t.TempDir()))
+	})
+	assert.Equal(t, "failed", record.Status)
+	assert.Contains(t, record.Error, "is not installed")
+}
+
+func TestBackupLockfile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "preflight.yaml")
+
+	backup := backupLockfile(configPath)
+	assert.Equal(t, filepath.Join(dir, "preflight.lock"), backup.Path)
+	assert.False(t, backup.Existed)
+
+	require.NoError(t, os.WriteFile(backup.Path, []byte("lock\n"), 0o644))
+	backup = backupLockfile(configPath)
+	assert.True(t, backup.Existed)
+	assert.Equal(t, "lock\n", backup.Content)
+
+	// Restoring a lockfile that did not exist removes it.
+	require.NoError(t, restoreLockfile(&LockfileBackup{Path: backup.Path}))
+	_, err := os.Stat(backup.Path)
+	assert.True(t, os.IsNotExist(err))
+}
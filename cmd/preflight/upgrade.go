@@ -0,0 +1,359 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/adapters/logging"
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/domain/security"
+	"github.com/felixgeelhaar/preflight/internal/offline"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/spf13/cobra"
+)
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade [packages...]",
+	Short: "Upgrade outdated packages",
+	Long: `Upgrade outdated Homebrew packages.
+
+Major updates are skipped unless --major is given, and packages held with
+'preflight pin' are never upgraded. With --scheduled, major
+updates are applied only inside the maintenance window configured in
+preflight.yaml and deferred otherwise; minor and patch updates are applied
+at any time. The agent runs scheduled upgrades when upgrades.auto is set.
+
+Before major updates are applied, their release notes are fetched from
+the GitHub releases of each package and shown. --notes-only shows the notes
+of pending major updates, or of the named packages, without upgrading.
+
+With --canary, packages are upgraded one at a time and the health checks
+declared for each under checks: in the layers run after its upgrade. A
+package whose check fails is rolled back to its previous version, and the
+run is recorded in history.
+
+  checks:
+    node:
+      - node --version
+      - npm test --prefix ~/src/app
+
+  upgrades:
+    window: "Sat 09:00-12:00"   # [days] HH:MM-HH:MM, local time
+    auto: true                  # let the agent upgrade on its own
+    notify_after: 5             # notify when this many upgrades are deferred
+    pin_max_age: 90d            # doctor flags pins held longer than this
+
+Examples:
+  preflight upgrade                     # Upgrade all (minor/patch only)
+  preflight upgrade --major             # Include major updates
+  preflight upgrade --dry-run           # Preview what would be upgraded
+  preflight upgrade --scheduled         # Honor the maintenance window
+  preflight upgrade --notes-only        # Review release notes first
+  preflight upgrade --canary            # Roll back upgrades that break checks`,
+	RunE: runUpgradeCmd,
+}
+
+var (
+	upgradeScheduled bool
+	upgradeNotesOnly bool
+	upgradeCanary    bool
+)
+
+// agentUpgradePollInterval is how often the agent runs scheduled upgrades.
+const agentUpgradePollInterval = time.Hour
+
+func init() {
+	rootCmd.AddCommand(upgradeCmd)
+
+	upgradeCmd.Flags().BoolVar(&upgradeScheduled, "scheduled", false, "Defer major updates to the maintenance window (upgrades.window)")
+	upgradeCmd.Flags().BoolVar(&upgradeNotesOnly, "notes-only", false, "Show release notes of pending updates without upgrading")
+	upgradeCmd.Flags().BoolVar(&upgradeCanary, "canary", false, "Upgrade one package at a time and roll back those failing their health checks")
+	upgradeCmd.Flags().BoolVar(&outdatedMajor, "major", false, "Include major version upgrades")
+	upgradeCmd.Flags().BoolVar(&outdatedDryRun, "dry-run", false, "Show what would be upgraded without making changes")
+	upgradeCmd.Flags().BoolVar(&outdatedJSON, "json", false, "Output results as JSON")
+}
+
+func runUpgradeCmd(_ *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	if offline.Enabled() {
+		if outdatedJSON {
+			outputUpgradeJSON(nil, offline.Skipped("upgrade"))
+		} else {
+			fmt.Println("Upgrade skipped (offline).")
+		}
+		return nil
+	}
+
+	checker := security.NewBrewOutdatedChecker()
+	if !checker.Available() {
+		printError(config.NewProviderUnavailableError("Homebrew"))
+		os.Exit(exitInternal)
+	}
+	fetcher := security.NewBrewReleaseNotesFetcher()
+	if upgradeNotesOnly {
+		return runUpgradeNotes(ctx, checker, fetcher, args)
+	}
+	if upgradeCanary && upgradeScheduled {
+		return fmt.Errorf("--canary cannot be combined with --scheduled")
+	}
+	if !upgradeScheduled {
+		if outdatedMajor && !outdatedJSON {
+			showMajorReleaseNotes(ctx, checker, fetcher, args)
+		}
+		if upgradeCanary {
+			return runCanaryUpgradeCmd(ctx, checker, args)
+		}
+		return runUpgrade(ctx, checker, args)
+	}
+	if outdatedMajor {
+		return fmt.Errorf("--major cannot be combined with --scheduled; the maintenance window decides")
+	}
+
+	upgrades, err := upgradesConfig(cfgFile)
+	if err != nil {
+		return err
+	}
+	if window, err := config.ParseMaintenanceWindow(upgrades.Window); err == nil && window.Contains(time.Now()) && !outdatedJSON {
+		showMajorReleaseNotes(ctx, checker, fetcher, args)
+	}
+	result, err := runScheduledUpgrade(ctx, checker, upgrades, args, pinnedPackages(cfgFile), time.Now(), outdatedDryRun)
+	if err != nil {
+		if outdatedJSON {
+			outputUpgradeJSON(nil, err)
+		} else {
+			fmt.Fprintf(os.Stderr, "Upgrade failed: %v\n", err)
+		}
+		os.Exit(exitInternal)
+	}
+
+	if outdatedJSON {
+		outputUpgradeJSON(result, nil)
+	} else {
+		outputUpgradeText(result)
+	}
+	if len(result.Failed) > 0 {
+		os.Exit(exitInternal)
+	}
+	return nil
+}
+
+// runUpgradeNotes prints the release notes of the pending major updates,
+// or of every pending update of the named packages, without upgrading.
+func runUpgradeNotes(ctx context.Context, checker security.OutdatedChecker, fetcher security.ReleaseNotesFetcher, packages []string) error {
+	pending, err := pendingUpgrades(ctx, checker, packages, pinnedPackages(cfgFile), len(packages) == 0)
+	if err != nil {
+		return err
+	}
+	notes := fetchReleaseNotes(ctx, fetcher, pending)
+
+	if outdatedJSON {
+		if notes == nil {
+			notes = []security.PackageReleaseNotes{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(notes)
+	}
+	if len(notes) == 0 {
+		if len(packages) == 0 {
+			fmt.Println("No major updates pending.")
+		} else {
+			fmt.Println("No updates pending for the given packages.")
+		}
+		return nil
+	}
+	printReleaseNotes(os.Stdout, notes)
+	return nil
+}
+
+// showMajorReleaseNotes prints the release notes of the major updates an
+// upgrade of packages is about to apply. Failing to find them only warns.
+func showMajorReleaseNotes(ctx context.Context, checker security.OutdatedChecker, fetcher security.ReleaseNotesFetcher, packages []string) {
+	pending, err := pendingUpgrades(ctx, checker, packages, pinnedPackages(cfgFile), true)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not look up release notes: %v\n", err)
+		return
+	}
+	if notes := fetchReleaseNotes(ctx, fetcher, pending); len(notes) > 0 {
+		printReleaseNotes(os.Stdout, notes)
+		fmt.Println()
+	}
+}
+
+// pendingUpgrades returns the outdated packages that are not pinned,
+// narrowed to packages when any are given and to major updates when
+// majorOnly is set.
+func pendingUpgrades(ctx context.Context, checker security.OutdatedChecker, packages, pinned []string, majorOnly bool) (security.OutdatedPackages, error) {
+	result, err := checker.Check(ctx, security.OutdatedOptions{IncludePatch: true, PinnedPackages: pinned})
+	if err != nil {
+		return nil, err
+	}
+	var pending security.OutdatedPackages
+	for _, pkg := range result.Packages.ExcludePinned() {
+		if len(packages) > 0 && !slices.Contains(packages, pkg.Name) {
+			continue
+		}
+		if majorOnly && pkg.UpdateType != security.UpdateMajor {
+			continue
+		}
+		pending = append(pending, pkg)
+	}
+	return pending, nil
+}
+
+func fetchReleaseNotes(ctx context.Context, fetcher security.ReleaseNotesFetcher, packages security.OutdatedPackages) []security.PackageReleaseNotes {
+	var notes []security.PackageReleaseNotes
+	for _, pkg := range packages {
+		notes = append(notes, fetcher.Fetch(ctx, pkg))
+	}
+	return notes
+}
+
+// printReleaseNotes prints the release notes of each package, or where to
+// find them when they could not be fetched.
+func printReleaseNotes(w io.Writer, notes []security.PackageReleaseNotes) {
+	_, _ = fmt.Fprintln(w, "Release Notes")
+	_, _ = fmt.Fprintln(w, strings.Repeat("─", 50))
+	for i, pkg := range notes {
+		if i > 0 {
+			_, _ = fmt.Fprintln(w)
+		}
+		header := fmt.Sprintf("%s %s → %s (%s)", pkg.Name, pkg.FromVersion, pkg.ToVersion, pkg.UpdateType)
+		if pkg.Source != "" {
+			header += "  " + pkg.Source
+		}
+		_, _ = fmt.Fprintln(w, header)
+
+		if len(pkg.Releases) == 0 {
+			line := "  No release notes found"
+			if pkg.Error != "" {
+				line += ": " + pkg.Error
+			}
+			_, _ = fmt.Fprintln(w, line)
+			if pkg.ChangelogURL != "" {
+				_, _ = fmt.Fprintf(w, "  See %s\n", pkg.ChangelogURL)
+			}
+			continue
+		}
+		for _, release := range pkg.Releases {
+			title := "  " + release.Version
+			if !release.PublishedAt.IsZero() {
+				title += " — " + release.PublishedAt.Format("2006-01-02")
+			}
+			_, _ = fmt.Fprintln(w, title)
+			for _, line := range strings.Split(release.Excerpt, "\n") {
+				if line != "" {
+					_, _ = fmt.Fprintf(w, "    %s\n", line)
+				}
+			}
+			if release.URL != "" {
+				_, _ = fmt.Fprintf(w, "    %s\n", release.URL)
+			}
+		}
+		if pkg.Omitted > 0 {
+			_, _ = fmt.Fprintf(w, "  … %d more releases: %s\n", pkg.Omitted, pkg.ChangelogURL)
+		}
+	}
+}
+
+// upgradesConfig returns the upgrades section of the manifest at
+// configPath, or the zero config when there is no manifest.
+func upgradesConfig(configPath string) (config.UpgradesConfig, error) {
+	manifest, err := config.NewLoader().LoadManifest(configPath)
+	if err != nil {
+		if config.IsUserError(err, config.ErrCodeConfigNotFound) {
+			return config.UpgradesConfig{}, nil
+		}
+		return config.UpgradesConfig{}, err
+	}
+	return manifest.Upgrades, nil
+}
+
+// runScheduledUpgrade upgrades packages that are not pinned, including
+// major updates only when now falls in the maintenance window. Deferred
+// major updates are reported as skipped, and a notification is shown once
+// as many are pending as notify_after asks for. Without a window major
+// updates are always deferred.
+func runScheduledUpgrade(ctx context.Context, upgrader security.PackageUpgrader, upgrades config.UpgradesConfig, packages, pinned []string, now time.Time, dryRun bool) (*security.UpgradeResult, error) {
+	var window *config.MaintenanceWindow
+	if upgrades.Window != "" {
+		w, err := config.ParseMaintenanceWindow(upgrades.Window)
+		if err != nil {
+			return nil, fmt.Errorf("invalid upgrades.window: %w", err)
+		}
+		window = &w
+	}
+
+	inWindow := window != nil && window.Contains(now)
+	result, err := upgrader.Upgrade(ctx, packages, security.UpgradeOptions{
+		DryRun:         dryRun,
+		IncludeMajor:   inWindow,
+		PinnedPackages: pinned,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	deferred := "no maintenance window is configured (upgrades.window)"
+	if window != nil {
+		deferred = "until " + window.Next(now).Format("Mon Jan 2 15:04")
+	}
+	pending := 0
+	for i := range result.Skipped {
+		if result.Skipped[i].Reason == security.SkipReasonMajor {
+			result.Skipped[i].Reason = "major update deferred " + deferred
+			pending++
+		}
+	}
+
+	notifyAfter := upgrades.NotifyAfter
+	if notifyAfter == 0 {
+		notifyAfter = config.DefaultUpgradeNotifyAfter
+	}
+	if pending >= notifyAfter && !dryRun {
+		message := fmt.Sprintf("%d major upgrades are pending, deferred %s", pending, deferred)
+		if err := notifyUser("Preflight upgrades pending", message); err != nil {
+			logging.Default().Warn(ctx, "upgrade notification failed", ports.F("error", err))
+		}
+	}
+	return result, nil
+}
+
+// watchUpgrades runs scheduled upgrades every interval while upgrades.auto
+// is set in the manifest at configPath. The manifest is read on every run
+// so that changes apply without restarting the agent.
+func watchUpgrades(ctx context.Context, configPath string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	checker := security.NewBrewOutdatedChecker()
+	for {
+		upgrades, err := upgradesConfig(configPath)
+		switch {
+		case err != nil:
+			logging.Default().Warn(ctx, "scheduled upgrade skipped", ports.F("error", err))
+		case upgrades.Auto && checker.Available():
+			result, err := runScheduledUpgrade(ctx, checker, upgrades, nil, pinnedPackages(configPath), time.Now(), false)
+			if err != nil {
+				logging.Default().Warn(ctx, "scheduled upgrade failed", ports.F("error", err))
+				break
+			}
+			logging.Default().Info(ctx, "scheduled upgrade finished",
+				ports.F("upgraded", len(result.Upgraded)),
+				ports.F("deferred", len(result.Skipped)),
+				ports.F("failed", len(result.Failed)))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
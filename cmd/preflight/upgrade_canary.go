@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/app"
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/domain/security"
+)
+
+// canaryCheckTimeout bounds each health check command.
+const canaryCheckTimeout = 2 * time.Minute
+
+// canaryUpgrader upgrades packages one at a time and rolls them back.
+type canaryUpgrader interface {
+	security.OutdatedChecker
+	security.PackageUpgrader
+	security.PackageRollbacker
+}
+
+// canaryCheckRunner runs a health check command and returns its output.
+type canaryCheckRunner func(ctx context.Context, command string) (string, error)
+
+// runCanaryCheck runs command with sh, bounded by canaryCheckTimeout.
+func runCanaryCheck(ctx context.Context, command string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, canaryCheckTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "sh", "-c", command).CombinedOutput()
+	if ctx.Err() != nil {
+		return string(out), fmt.Errorf("timed out after %s", canaryCheckTimeout)
+	}
+	return string(out), err
+}
+
+// runCanaryUpgradeCmd runs a canary upgrade of packages with the health
+// checks declared in the layers, and records it in history.
+func runCanaryUpgradeCmd(ctx context.Context, upgrader canaryUpgrader, packages []string) error {
+	checks, err := app.PackageChecks(cfgFile)
+	if err != nil {
+		return err
+	}
+	if !outdatedJSON {
+		if outdatedDryRun {
+			fmt.Println("Dry run - no changes will be made")
+		} else {
+			fmt.Println("Upgrading outdated packages one at a time...")
+		}
+		fmt.Println(strings.Repeat("─", 50))
+	}
+
+	started := time.Now()
+	result, err := runCanaryUpgrade(ctx, upgrader, checks, packages, security.UpgradeOptions{
+		DryRun:         outdatedDryRun,
+		IncludeMajor:   outdatedMajor,
+		PinnedPackages: pinnedPackages(cfgFile),
+	}, runCanaryCheck)
+	if err != nil {
+		if outdatedJSON {
+			outputUpgradeJSON(nil, err)
+		} else {
+			fmt.Fprintf(os.Stderr, "Upgrade failed: %v\n", err)
+		}
+		os.Exit(exitInternal)
+	}
+	if !result.DryRun {
+		if err := SaveHistoryEntry(newCanaryHistoryEntry(started, result)); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not record history: %v\n", err)
+		}
+	}
+
+	if outdatedJSON {
+		outputUpgradeJSON(result, nil)
+	} else {
+		outputUpgradeText(result)
+	}
+	if len(result.Failed) > 0 || len(result.RolledBack) > 0 {
+		os.Exit(exitInternal)
+	}
+	return nil
+}
+
+// runCanaryUpgrade upgrades the pending packages one at a time, keeping
+// the previous version installed. After each upgrade the health checks of
+// the package run in order, and the first failing check rolls the package
+// back. A package that cannot be rolled back is reported as failed.
+func runCanaryUpgrade(ctx context.Context, upgrader canaryUpgrader, checks config.ChecksConfig, packages []string, opts security.UpgradeOptions, runCheck canaryCheckRunner) (*security.UpgradeResult, error) {
+	pending, err := pendingUpgrades(ctx, upgrader, packages, opts.PinnedPackages, false)
+	if err != nil {
+		return nil, err
+	}
+
+	opts.KeepPrevious = true
+	combined := &security.UpgradeResult{DryRun: opts.DryRun}
+	for _, pkg := range pending {
+		result, err := upgrader.Upgrade(ctx, []string{pkg.Name}, opts)
+		if err != nil {
+			combined.Failed = append(combined.Failed, security.FailedPackage{Name: pkg.Name, Error: err.Error()})
+			continue
+		}
+		combined.Skipped = append(combined.Skipped, result.Skipped...)
+		combined.Failed = append(combined.Failed, result.Failed...)
+
+		for _, upgraded := range result.Upgraded {
+			if opts.DryRun {
+				combined.Upgraded = append(combined.Upgraded, upgraded)
+				continue
+			}
+			command, output, ok := runPackageChecks(ctx, checks[upgraded.Name], runCheck)
+			if ok {
+				combined.Upgraded = append(combined.Upgraded, upgraded)
+				continue
+			}
+			if err := upgrader.Rollback(ctx, upgraded); err != nil {
+				combined.Failed = append(combined.Failed, security.FailedPackage{
+					Name:  upgraded.Name,
+					Error: fmt.Sprintf("health check %q failed and rollback failed: %v", command, err),
+				})
+				continue
+			}
+			combined.RolledBack = append(combined.RolledBack, security.RolledBackPackage{
+				Name:        upgraded.Name,
+				FromVersion: upgraded.FromVersion,
+				ToVersion:   upgraded.ToVersion,
+				Provider:    upgraded.Provider,
+				Check:       command,
+				Output:      output,
+			})
+		}
+	}
+	return combined, nil
+}
+
+// runPackageChecks runs commands until one fails, and returns the failing
+// command and its output.
+func runPackageChecks(ctx context.Context, commands []string, runCheck canaryCheckRunner) (string, string, bool) {
+	for _, command := range commands {
+		output, err := runCheck(ctx, command)
+		if err != nil {
+			output = strings.TrimSpace(output)
+			if output == "" {
+				output = err.Error()
+			}
+			return command, output, false
+		}
+	}
+	return "", "", true
+}
+
+// newCanaryHistoryEntry records a canary upgrade: the packages kept at
+// their new version and those rolled back after a failed health check.
+func newCanaryHistoryEntry(started time.Time, result *security.UpgradeResult) HistoryEntry {
+	entry := HistoryEntry{
+		Timestamp: started,
+		Command:   "upgrade --canary",
+		Status:    "success",
+		Duration:  time.Since(started).Round(time.Millisecond).String(),
+	}
+	for _, pkg := range result.Upgraded {
+		entry.Changes = append(entry.Changes, Change{
+			Provider: pkg.Provider,
+			Action:   "update",
+			Item:     pkg.Name,
+			Details:  pkg.FromVersion + " → " + pkg.ToVersion,
+		})
+		entry.Versions = append(entry.Versions, app.VersionTransition{
+			Provider: pkg.Provider,
+			Name:     pkg.Name,
+			From:     pkg.FromVersion,
+			To:       pkg.ToVersion,
+		})
+	}
+	for _, pkg := range result.RolledBack {
+		entry.Changes = append(entry.Changes, Change{
+			Provider: pkg.Provider,
+			Action:   "rollback",
+			Item:     pkg.Name,
+			Details:  fmt.Sprintf("%s → %s rolled back: check %q failed", pkg.FromVersion, pkg.ToVersion, pkg.Check),
+		})
+	}
+
+	var problems []string
+	for _, pkg := range result.RolledBack {
+		problems = append(problems, pkg.Name+" rolled back")
+	}
+	for _, pkg := range result.Failed {
+		problems = append(problems, pkg.Name+": "+pkg.Error)
+	}
+	if len(problems) > 0 {
+		entry.Error = strings.Join(problems, "; ")
+		entry.Status = "partial"
+		if len(result.Upgraded) == 0 {
+			entry.Status = "failed"
+		}
+	}
+	return entry
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/domain/security"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCanaryUpgrader upgrades every package it is asked to and records
+// upgrades and rollbacks.
+type fakeCanaryUpgrader struct {
+	fakeOutdatedChecker
+	rollbackErr map[string]error
+	upgrades    [][]string
+	opts        []security.UpgradeOptions
+	rolledBack  []string
+}
+
+func (f *fakeCanaryUpgrader) Upgrade(_ context.Context, packages []string, opts security.UpgradeOptions) (*security.UpgradeResult, error) {
+	f.upgrades = append(f.upgrades, packages)
+	f.opts = append(f.opts, opts)
+	result := &security.UpgradeResult{DryRun: opts.DryRun}
+	for _, pkg := range f.packages {
+		if pkg.Name == packages[0] {
+			result.Upgraded = append(result.Upgraded, security.UpgradedPackage{
+				Name: pkg.Name, FromVersion: pkg.CurrentVersion, ToVersion: pkg.LatestVersion, Provider: pkg.Provider,
+			})
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeCanaryUpgrader) Rollback(_ context.Context, pkg security.UpgradedPackage) error {
+	if err := f.rollbackErr[pkg.Name]; err != nil {
+		return err
+	}
+	f.rolledBack = append(f.rolledBack, pkg.Name)
+	return nil
+}
+
+func newFakeCanaryUpgrader() *fakeCanaryUpgrader {
+	return &fakeCanaryUpgrader{fakeOutdatedChecker: fakeOutdatedChecker{
+		name:      "brew",
+		available: true,
+		packages: security.OutdatedPackages{
+			{Name: "jq", CurrentVersion: "1.6", LatestVersion: "1.7.1", UpdateType: security.UpdateMinor, Provider: "brew"},
+			{Name: "node", CurrentVersion: "20.11.1", LatestVersion: "22.1.0", UpdateType: security.UpdateMajor, Provider: "brew"},
+			{Name: "ripgrep", CurrentVersion: "14.0.0", LatestVersion: "14.1.0", UpdateType: security.UpdateMinor, Provider: "brew"},
+		},
+	}}
+}
+
+func TestRunCanaryUpgrade(t *testing.T) {
+	t.Parallel()
+
+	upgrader := newFakeCanaryUpgrader()
+	upgrader.rollbackErr = map[string]error{"ripgrep": errors.New("previous version was cleaned up")}
+	checks := config.ChecksConfig{
+		"jq":      {"jq --version"},
+		"node":    {"node --version", "npm test"},
+		"ripgrep": {"rg --version"},
+	}
+	var ran []string
+	runCheck := func(_ context.Context, command string) (string, error) {
+		ran = append(ran, command)
+		if command == "npm test" || command == "rg --version" {
+			return "1 test failed\n", errors.New("exit status 1")
+		}
+		return "", nil
+	}
+
+	result, err := runCanaryUpgrade(context.Background(), upgrader, checks, nil, security.UpgradeOptions{IncludeMajor: true}, runCheck)
+	require.NoError(t, err)
+
+	assert.Equal(t, [][]string{{"jq"}, {"node"}, {"ripgrep"}}, upgrader.upgrades, "packages are upgraded one at a time")
+	for _, opts := range upgrader.opts {
+		assert.True(t, opts.KeepPrevious)
+	}
+	assert.Equal(t, []string{"jq --version", "node --version", "npm test", "rg --version"}, ran)
+	assert.Equal(t, []string{"node"}, upgrader.rolledBack)
+
+	require.Len(t, result.Upgraded, 1)
+	assert.Equal(t, "jq", result.Upgraded[0].Name)
+	assert.Equal(t, []security.RolledBackPackage{{
+		Name: "node", FromVersion: "20.11.1", ToVersion: "22.1.0", Provider: "brew", Check: "npm test", Output: "1 test failed",
+	}}, result.RolledBack)
+	require.Len(t, result.Failed, 1)
+	assert.Equal(t, "ripgrep", result.Failed[0].Name)
+	assert.Contains(t, result.Failed[0].Error, "previous version was cleaned up")
+
+	entry := newCanaryHistoryEntry(time.Now(), result)
+	assert.Equal(t, "upgrade --canary", entry.Command)
+	assert.Equal(t, "partial", entry.Status)
+	require.Len(t, entry.Changes, 2)
+	assert.Equal(t, "update", entry.Changes[0].Action)
+	assert.Equal(t, "rollback", entry.Changes[1].Action)
+	assert.Equal(t, "node", entry.Changes[1].Item)
+	require.Len(t, entry.Versions, 1)
+	assert.Equal(t, "1.7.1", entry.Versions[0].To)
+}
+
+func TestRunCanaryUpgrade_DryRunAndSelection(t *testing.T) {
+	t.Parallel()
+
+	upgrader := newFakeCanaryUpgrader()
+	runCheck := func(context.Context, string) (string, error) {
+		t.Fatal("checks do not run in a dry run")
+		return "", nil
+	}
+
+	result, err := runCanaryUpgrade(context.Background(), upgrader, config.ChecksConfig{"jq": {"false"}}, []string{"jq"},
+		security.UpgradeOptions{DryRun: true}, runCheck)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"jq"}}, upgrader.upgrades)
+	assert.True(t, result.DryRun)
+	require.Len(t, result.Upgraded, 1)
+	assert.Empty(t, result.RolledBack)
+
+	entry := newCanaryHistoryEntry(time.Now(), &security.UpgradeResult{
+		RolledBack: []security.RolledBackPackage{{Name: "jq", Check: "false"}},
+	})
+	assert.Equal(t, "failed", entry.Status, "nothing kept its upgrade")
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/domain/security"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeUpgrader has one minor and two major updates pending, and holds git
+// when it is pinned.
+type fakeUpgrader struct {
+	opts security.UpgradeOptions
+}
+
+func (f *fakeUpgrader) Upgrade(_ context.Context, _ []string, opts security.UpgradeOptions) (*security.UpgradeResult, error) {
+	f.opts = opts
+	result := &security.UpgradeResult{
+		Upgraded: []security.UpgradedPackage{{Name: "jq", FromVersion: "1.6", ToVersion: "1.7", Provider: "brew"}},
+		DryRun:   opts.DryRun,
+	}
+	for _, name := range []string{"node", "python"} {
+		if opts.IncludeMajor {
+			result.Upgraded = append(result.Upgraded, security.UpgradedPackage{Name: name, Provider: "brew"})
+		} else {
+			result.Skipped = append(result.Skipped, security.SkippedPackage{Name: name, Reason: security.SkipReasonMajor, UpdateType: security.UpdateMajor})
+		}
+	}
+	if slices.Contains(opts.PinnedPackages, "brew:git") {
+		result.Skipped = append(result.Skipped, security.SkippedPackage{Name: "git", Reason: security.SkipReasonPinned, UpdateType: security.UpdateMinor})
+	}
+	return result, nil
+}
+
+func captureNotifications(t *testing.T) *[]string {
+	t.Helper()
+	prev := notifyUser
+	t.Cleanup(func() { notifyUser = prev })
+	var sent []string
+	notifyUser = func(_, message string) error {
+		sent = append(sent, message)
+		return nil
+	}
+	return &sent
+}
+
+func TestRunScheduledUpgrade_DefersMajorOutsideWindow(t *testing.T) {
+	sent := captureNotifications(t)
+
+	friday := time.Date(2026, time.October, 16, 15, 0, 0, 0, time.Local)
+	upgrader := &fakeUpgrader{}
+	result, err := runScheduledUpgrade(context.Background(), upgrader, config.UpgradesConfig{Window: "Sat 09:00-12:00", NotifyAfter: 2}, nil, nil, friday, false)
+	require.NoError(t, err)
+
+	assert.False(t, upgrader.opts.IncludeMajor)
+	assert.Len(t, result.Upgraded, 1)
+	require.Len(t, result.Skipped, 2)
+	assert.Equal(t, "major update deferred until Sat Oct 17 09:00", result.Skipped[0].Reason)
+	require.Len(t, *sent, 1)
+	assert.Contains(t, (*sent)[0], "2 major upgrades are pending")
+}
+
+func TestRunScheduledUpgrade_AppliesMajorInWindow(t *testing.T) {
+	sent := captureNotifications(t)
+
+	saturday := time.Date(2026, time.October, 17, 10, 0, 0, 0, time.Local)
+	upgrader := &fakeUpgrader{}
+	result, err := runScheduledUpgrade(context.Background(), upgrader, config.UpgradesConfig{Window: "Sat 09:00-12:00"}, nil, nil, saturday, false)
+	require.NoError(t, err)
+
+	assert.True(t, upgrader.opts.IncludeMajor)
+	assert.Len(t, result.Upgraded, 3)
+	assert.Empty(t, result.Skipped)
+	assert.Empty(t, *sent)
+}
+
+func TestRunScheduledUpgrade_NoWindow(t *testing.T) {
+	sent := captureNotifications(t)
+
+	upgrader := &fakeUpgrader{}
+	result, err := runScheduledUpgrade(context.Background(), upgrader, config.UpgradesConfig{}, nil, nil, time.Now(), true)
+	require.NoError(t, err)
+
+	assert.False(t, upgrader.opts.IncludeMajor)
+	assert.True(t, upgrader.opts.DryRun)
+	require.Len(t, result.Skipped, 2)
+	assert.Contains(t, result.Skipped[0].Reason, "no maintenance window")
+	assert.Empty(t, *sent, "below the default notify_after")
+
+	_, err = runScheduledUpgrade(context.Background(), upgrader, config.UpgradesConfig{Window: "whenever"}, nil, nil, time.Now(), false)
+	require.Error(t, err)
+}
+
+func TestRunScheduledUpgrade_KeepsPins(t *testing.T) {
+	sent := captureNotifications(t)
+
+	friday := time.Date(2026, time.October, 16, 15, 0, 0, 0, time.Local)
+	upgrader := &fakeUpgrader{}
+	result, err := runScheduledUpgrade(context.Background(), upgrader, config.UpgradesConfig{Window: "Sat 09:00-12:00", NotifyAfter: 3}, nil, []string{"brew:git"}, friday, false)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"brew:git"}, upgrader.opts.PinnedPackages)
+	require.Len(t, result.Skipped, 3)
+	assert.Equal(t, security.SkipReasonPinned, result.Skipped[2].Reason)
+	assert.Empty(t, *sent, "pinned packages are not deferred upgrades")
+}
+
+func TestUpgradesConfig(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	upgrades, err := upgradesConfig(filepath.Join(dir, "preflight.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, config.UpgradesConfig{}, upgrades)
+
+	path := filepath.Join(dir, "preflight.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("upgrades:\n  window: Sun 02:00-04:00\ntargets:\n  default: [base]\n"), 0o644))
+	upgrades, err = upgradesConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, "Sun 02:00-04:00", upgrades.Window)
+}
+
+// fakeReleaseNotes has notes for node only.
+type fakeReleaseNotes struct{}
+
+func (fakeReleaseNotes) Fetch(_ context.Context, pkg security.OutdatedPackage) security.PackageReleaseNotes {
+	notes := security.PackageReleaseNotes{
+		Name: pkg.Name, FromVersion: pkg.CurrentVersion, ToVersion: pkg.LatestVersion, UpdateType: pkg.UpdateType,
+		ChangelogURL: "https://example.com/" + pkg.Name,
+	}
+	if pkg.Name != "node" {
+		notes.Error = "no GitHub repository found in the Homebrew metadata"
+		return notes
+	}
+	notes.Source = "github.com/nodejs/node"
+	notes.Releases = []security.ReleaseNote{{
+		Version:     "20.0.0",
+		URL:         "https://github.com/nodejs/node/releases/tag/v20.0.0",
+		PublishedAt: time.Date(2023, time.April, 18, 0, 0, 0, 0, time.UTC),
+		Excerpt:     "* Permission model\n* Stable test runner",
+	}}
+	notes.Omitted = 2
+	return notes
+}
+
+func notesChecker() *fakeOutdatedChecker {
+	return &fakeOutdatedChecker{name: "brew", available: true, packages: security.OutdatedPackages{
+		{Name: "node", CurrentVersion: "18.0.0", LatestVersion: "20.0.0", UpdateType: security.UpdateMajor, Provider: "brew"},
+		{Name: "jq", CurrentVersion: "1.6", LatestVersion: "1.7", UpdateType: security.UpdateMinor, Provider: "brew"},
+		{Name: "git", CurrentVersion: "1.0", LatestVersion: "2.0", UpdateType: security.UpdateMajor, Provider: "brew", Pinned: true},
+	}}
+}
+
+func TestPendingUpgrades(t *testing.T) {
+	t.Parallel()
+
+	pending, err := pendingUpgrades(context.Background(), notesChecker(), nil, nil, true)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, "node", pending[0].Name)
+
+	pending, err = pendingUpgrades(context.Background(), notesChecker(), []string{"jq"}, nil, false)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, "jq", pending[0].Name)
+}
+
+func TestRunUpgradeNotes(t *testing.T) {
+	saved := cfgFile
+	defer func() { cfgFile = saved }()
+	cfgFile = filepath.Join(t.TempDir(), "preflight.yaml")
+
+	out := captureStdout(t, func() {
+		require.NoError(t, runUpgradeNotes(context.Background(), notesChecker(), fakeReleaseNotes{}, nil))
+	})
+	assert.Contains(t, out, "node 18.0.0 → 20.0.0 (major)  github.com/nodejs/node")
+	assert.Contains(t, out, "  20.0.0 — 2023-04-18\n    * Permission model\n    * Stable test runner\n")
+	assert.Contains(t, out, "… 2 more releases: https://example.com/node")
+	assert.NotContains(t, out, "jq", "only major updates without package names")
+
+	out = captureStdout(t, func() {
+		require.NoError(t, runUpgradeNotes(context.Background(), notesChecker(), fakeReleaseNotes{}, []string{"jq"}))
+	})
+	assert.Contains(t, out, "No release notes found: no GitHub repository")
+	assert.Contains(t, out, "See https://example.com/jq")
+}
@@ -21,10 +21,14 @@ This command is designed for CI/CD pipelines to catch configuration
 issues before deployment. It supports both allow/deny policies and
 org policies with required/forbidden patterns.
 
+The config and the target's layer files are first checked against their
+JSON Schemas (see 'preflight schema'); errors point at a line and column.
+
 Exit codes:
   0 - Valid configuration
-  1 - Validation errors or policy violations found
-  2 - Could not read configuration
+  1 - Validation errors found (or warnings with --strict)
+  2 - Policy violations found
+  3 - Could not read configuration
 
 Examples:
   preflight validate
@@ -98,7 +102,7 @@ func runValidate(cmd *cobra.Command, _ []string) error {
 		} else {
 			printError(err)
 		}
-		os.Exit(2)
+		os.Exit(exitInternal)
 	}
 
 	// Determine if validation passed
@@ -114,8 +118,11 @@ func runValidate(cmd *cobra.Command, _ []string) error {
 		outputValidationText(result)
 	}
 
-	if failed {
-		os.Exit(1)
+	switch {
+	case hasPolicyViolations:
+		os.Exit(exitPolicyViolation)
+	case failed:
+		os.Exit(exitDrift)
 	}
 
 	return nil
@@ -129,17 +136,26 @@ func outputValidationJSON(result *app.ValidationResult, err error) {
 		PolicyViolations []string `json:"policy_violations,omitempty"`
 		Info             []string `json:"info,omitempty"`
 		Error            string   `json:"error,omitempty"`
+		ErrorCode        string   `json:"error_code,omitempty"`
 	}{}
 
 	if err != nil {
 		output.Valid = false
 		output.Error = err.Error()
+		if userErr := config.GetUserError(err); userErr != nil {
+			output.ErrorCode = userErr.ID()
+		}
 	} else if result != nil {
 		output.Valid = len(result.Errors) == 0 && len(result.PolicyViolations) == 0
 		output.Errors = result.Errors
 		output.Warnings = result.Warnings
 		output.PolicyViolations = result.PolicyViolations
 		output.Info = result.Info
+		if len(result.PolicyViolations) > 0 {
+			if info, ok := config.LookupErrorCode(config.ErrCodePolicyViolation); ok {
+				output.ErrorCode = info.ID
+			}
+		}
 	}
 
 	enc := json.NewEncoder(os.Stdout)
@@ -2,7 +2,10 @@ package main
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/felixgeelhaar/preflight/internal/notices"
+	"github.com/felixgeelhaar/preflight/internal/offline"
 	"github.com/spf13/cobra"
 )
 
@@ -16,10 +19,78 @@ var (
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Show version information",
-	Run: func(cmd *cobra.Command, _ []string) {
-		out := cmd.OutOrStdout()
-		_, _ = fmt.Fprintf(out, "preflight %s\n", version)
-		_, _ = fmt.Fprintf(out, "  commit: %s\n", commit)
-		_, _ = fmt.Fprintf(out, "  built:  %s\n", buildDate)
-	},
+	Long: `Show version information.
+
+With --check, version looks up the latest release on GitHub now.
+
+With --notify, commands end, at most once a day, with a notice when a newer
+release exists or when the configuration uses deprecated keys. Notices are
+off by default and never shown in CI, in JSON output or when the output is
+not a terminal. The release check sends nothing but a request for the
+latest release, and is skipped in offline mode. --notify=false turns
+notices off again.`,
+	RunE: runVersion,
+}
+
+var (
+	versionCheck  bool
+	versionNotify bool
+)
+
+func init() {
+	versionCmd.Flags().BoolVar(&versionCheck, "check", false, "Check for a newer release now")
+	versionCmd.Flags().BoolVar(&versionNotify, "notify", false, "Turn daily update and deprecation notices on (or off with --notify=false)")
+}
+
+func runVersion(cmd *cobra.Command, _ []string) error {
+	out := cmd.OutOrStdout()
+	_, _ = fmt.Fprintf(out, "preflight %s\n", version)
+	_, _ = fmt.Fprintf(out, "  commit: %s\n", commit)
+	_, _ = fmt.Fprintf(out, "  built:  %s\n", buildDate)
+
+	if cmd.Flags().Changed("notify") {
+		if err := setNotify(versionNotify); err != nil {
+			return err
+		}
+		if versionNotify {
+			_, _ = fmt.Fprintln(out, "\nNotices are on.")
+		} else {
+			_, _ = fmt.Fprintln(out, "\nNotices are off.")
+		}
+	}
+
+	if versionCheck {
+		if offline.Enabled() {
+			_, _ = fmt.Fprintln(out, "\nRelease check skipped (offline).")
+			return nil
+		}
+		latest, err := latestRelease(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if notices.Newer(version, latest) {
+			_, _ = fmt.Fprintf(out, "\npreflight %s is available: %s\n", latest, releasesURL)
+		} else {
+			_, _ = fmt.Fprintf(out, "\nThe latest release is %s.\n", latest)
+		}
+	}
+	return nil
+}
+
+// setNotify records the opt-in to notices. Turning them on shows the next
+// notices right away.
+func setNotify(enabled bool) error {
+	dir := preflightConfigDir()
+	if dir == "" {
+		return fmt.Errorf("cannot find the preflight config directory")
+	}
+	state, err := notices.LoadState(dir)
+	if err != nil {
+		return err
+	}
+	state.Enabled = enabled
+	if enabled {
+		state.LastShown, state.CheckedAt = time.Time{}, time.Time{}
+	}
+	return notices.SaveState(dir, state)
 }
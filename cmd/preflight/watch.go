@@ -25,6 +25,10 @@ This command monitors your preflight.yaml, layers/*.yaml, and dotfiles/
 directory for changes. When a change is detected, it automatically
 runs the apply command.
 
+Installed plugins are watched as well: when a plugin is added, updated or
+removed, its providers are reloaded without restarting and the change is
+recorded in the audit log.
+
 Debouncing prevents multiple rapid applies when saving multiple files.
 The initial apply can be skipped with --skip-initial.`,
 	Example: `  # Watch with default settings
@@ -62,6 +66,7 @@ type watchPreflight interface {
 	Apply(context.Context, *execution.Plan, bool) ([]execution.StepResult, error)
 	PrintResults([]execution.StepResult)
 	WithMode(config.ReproducibilityMode) watchPreflight
+	ReloadChangedPlugins(context.Context) []app.PluginReload
 }
 
 type watchPreflightAdapter struct {
@@ -168,11 +173,12 @@ func runWatch(cmd *cobra.Command, _ []string) error {
 
 	// Create watch options
 	opts := app.WatchOptions{
-		ConfigDir:    configDir,
-		Debounce:     debounce,
-		ApplyOnStart: !watchSkipInitial,
-		DryRun:       watchDryRun,
-		Verbose:      watchVerbose,
+		ConfigDir:     configDir,
+		Debounce:      debounce,
+		ApplyOnStart:  !watchSkipInitial,
+		DryRun:        watchDryRun,
+		Verbose:       watchVerbose,
+		ReloadPlugins: preflight.ReloadChangedPlugins,
 	}
 
 	// Create and start watch mode
@@ -87,6 +87,7 @@ func TestRunWatch_StartsWatcher(t *testing.T) {
 	assert.True(t, fakeMode.startCalled)
 	assert.Equal(t, mustEvalSymlinks(t, dir), mustEvalSymlinks(t, fakeMode.opts.ConfigDir))
 	assert.Equal(t, 111*time.Millisecond, fakeMode.opts.Debounce)
+	assert.NotNil(t, fakeMode.opts.ReloadPlugins)
 }
 
 func setWatchFlags(debounce string, skipInitial, dryRun, verbose bool) func() {
@@ -159,3 +160,7 @@ func (f *fakeWatchPreflight) PrintResults([]execution.StepResult) {}
 func (f *fakeWatchPreflight) WithMode(config.ReproducibilityMode) watchPreflight {
 	return f
 }
+
+func (f *fakeWatchPreflight) ReloadChangedPlugins(context.Context) []app.PluginReload {
+	return nil
+}
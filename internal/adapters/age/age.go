@@ -0,0 +1,194 @@
+// Package age encrypts files in the age v1 format
+// (https://age-encryption.org/v1) to X25519 recipients, using the reference
+// implementation filippo.io/age.
+//
+// Files produced here can be decrypted with the age CLI and vice versa, so
+// users can always recover synced data with standard tooling.
+package age
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+)
+
+// Errors returned by this package.
+var (
+	ErrNoIdentityMatched = errors.New("no identity matched any of the file's recipients")
+	ErrInvalidHeader     = errors.New("invalid age header")
+	ErrInvalidPayload    = errors.New("invalid age payload")
+	ErrInvalidKey        = errors.New("invalid age key")
+	ErrNoRecipients      = errors.New("no recipients")
+)
+
+const intro = "age-encryption.org/v1"
+
+// Recipient is an X25519 public key that files are encrypted to.
+type Recipient struct {
+	key *age.X25519Recipient
+}
+
+// Identity is an X25519 private key that decrypts files.
+type Identity struct {
+	key *age.X25519Identity
+}
+
+// GenerateIdentity creates a new random identity.
+func GenerateIdentity() (*Identity, error) {
+	key, err := age.GenerateX25519Identity()
+	if err != nil {
+		return nil, err
+	}
+	return &Identity{key: key}, nil
+}
+
+// ParseRecipient parses an "age1..." public key.
+func ParseRecipient(s string) (*Recipient, error) {
+	key, err := age.ParseX25519Recipient(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidKey, err)
+	}
+	return &Recipient{key: key}, nil
+}
+
+// ParseIdentity parses an "AGE-SECRET-KEY-1..." private key.
+func ParseIdentity(s string) (*Identity, error) {
+	key, err := age.ParseX25519Identity(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidKey, err)
+	}
+	return &Identity{key: key}, nil
+}
+
+// String returns the "age1..." encoding of the recipient.
+func (r *Recipient) String() string {
+	return r.key.String()
+}
+
+// String returns the "AGE-SECRET-KEY-1..." encoding of the identity.
+func (i *Identity) String() string {
+	return i.key.String()
+}
+
+// Recipient returns the public key matching the identity.
+func (i *Identity) Recipient() *Recipient {
+	return &Recipient{key: i.key.Recipient()}
+}
+
+// ReadIdentityFile reads identities from a file in age-keygen format.
+// Blank lines and lines starting with '#' are ignored.
+func ReadIdentityFile(path string) ([]*Identity, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var ids []*Identity
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		id, err := ParseIdentity(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: no identities in %s", ErrInvalidKey, path)
+	}
+	return ids, nil
+}
+
+// WriteIdentityFile writes an identity in age-keygen format with 0600
+// permissions. It refuses to overwrite an existing file.
+func WriteIdentityFile(path string, id *Identity) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(f, "# public key: %s\n%s\n", id.Recipient(), id)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Encrypt encrypts plaintext to all recipients.
+func Encrypt(plaintext []byte, recipients ...*Recipient) ([]byte, error) {
+	if len(recipients) == 0 {
+		return nil, ErrNoRecipients
+	}
+	keys := make([]age.Recipient, len(recipients))
+	for i, r := range recipients {
+		keys[i] = r.key
+	}
+
+	var out bytes.Buffer
+	w, err := age.Encrypt(&out, keys...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// IsEncrypted reports whether data is an age file.
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(intro+"\n"))
+}
+
+// Decrypt decrypts an age file with the first identity that matches.
+func Decrypt(ciphertext []byte, identities ...*Identity) ([]byte, error) {
+	keys := make([]age.Identity, len(identities))
+	for i, id := range identities {
+		keys[i] = id.key
+	}
+
+	r, err := age.Decrypt(bytes.NewReader(ciphertext), keys...)
+	if err != nil {
+		var noMatch *age.NoIdentityMatchError
+		if errors.As(err, &noMatch) {
+			return nil, ErrNoIdentityMatched
+		}
+		return nil, fmt.Errorf("%w: %w", ErrInvalidHeader, err)
+	}
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPayload, err)
+	}
+	return plaintext, nil
+}
+
+// Encryptor encrypts to a fixed set of recipients and decrypts with a fixed
+// set of identities. It implements sync.Encryptor.
+type Encryptor struct {
+	Recipients []*Recipient
+	Identities []*Identity
+}
+
+// Encrypt implements sync.Encryptor.
+func (e *Encryptor) Encrypt(plaintext []byte) ([]byte, error) {
+	return Encrypt(plaintext, e.Recipients...)
+}
+
+// Decrypt implements sync.Encryptor.
+func (e *Encryptor) Decrypt(ciphertext []byte) ([]byte, error) {
+	return Decrypt(ciphertext, e.Identities...)
+}
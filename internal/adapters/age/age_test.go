@@ -0,0 +1,139 @@
+package age
+
+import (
+	"bytes"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chunkSize is the size of an age payload chunk.
+const chunkSize = 64 * 1024
+
+func TestDecrypt_KnownAnswer(t *testing.T) {
+	t.Parallel()
+
+	// testdata/example.age and its key come from the testdata of the age
+	// reference implementation.
+	ids, err := ReadIdentityFile(filepath.Join("testdata", "example_keys.txt"))
+	require.NoError(t, err)
+	ciphertext, err := os.ReadFile(filepath.Join("testdata", "example.age"))
+	require.NoError(t, err)
+
+	assert.True(t, IsEncrypted(ciphertext))
+	plaintext, err := Decrypt(ciphertext, ids...)
+	require.NoError(t, err)
+	assert.Equal(t, "Black lives matter.", string(plaintext))
+}
+
+func TestKeys_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	id, err := GenerateIdentity()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(id.String(), "AGE-SECRET-KEY-1"))
+	assert.True(t, strings.HasPrefix(id.Recipient().String(), "age1"))
+
+	parsed, err := ParseIdentity(id.String())
+	require.NoError(t, err)
+	assert.Equal(t, id.String(), parsed.String())
+
+	r, err := ParseRecipient(id.Recipient().String())
+	require.NoError(t, err)
+	assert.Equal(t, id.Recipient().String(), r.String())
+
+	_, err = ParseRecipient(id.String())
+	require.ErrorIs(t, err, ErrInvalidKey)
+	_, err = ParseIdentity(id.Recipient().String())
+	require.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	t.Parallel()
+
+	alice, err := GenerateIdentity()
+	require.NoError(t, err)
+	bob, err := GenerateIdentity()
+	require.NoError(t, err)
+
+	large := make([]byte, 3*chunkSize+17)
+	_, _ = rand.Read(large)
+
+	for name, plaintext := range map[string][]byte{
+		"empty":      {},
+		"small":      []byte("hello preflight"),
+		"full chunk": bytes.Repeat([]byte{'a'}, chunkSize),
+		"multi":      large,
+	} {
+		ciphertext, err := Encrypt(plaintext, alice.Recipient(), bob.Recipient())
+		require.NoError(t, err, name)
+		assert.True(t, bytes.HasPrefix(ciphertext, []byte("age-encryption.org/v1\n-> X25519 ")), name)
+		assert.True(t, IsEncrypted(ciphertext), name)
+		assert.False(t, IsEncrypted(plaintext), name)
+
+		for _, id := range []*Identity{alice, bob} {
+			got, err := Decrypt(ciphertext, id)
+			require.NoError(t, err, name)
+			assert.Equal(t, len(plaintext), len(got), name)
+			assert.True(t, bytes.Equal(plaintext, got), name)
+		}
+	}
+}
+
+func TestDecrypt_Failures(t *testing.T) {
+	t.Parallel()
+
+	id, err := GenerateIdentity()
+	require.NoError(t, err)
+	other, err := GenerateIdentity()
+	require.NoError(t, err)
+
+	ciphertext, err := Encrypt([]byte("secret"), id.Recipient())
+	require.NoError(t, err)
+
+	_, err = Decrypt(ciphertext, other)
+	require.ErrorIs(t, err, ErrNoIdentityMatched)
+
+	tampered := append([]byte{}, ciphertext...)
+	tampered[len(tampered)-1] ^= 1
+	_, err = Decrypt(tampered, id)
+	require.ErrorIs(t, err, ErrInvalidPayload)
+
+	// Changing the header invalidates the MAC.
+	header := bytes.Replace(ciphertext, []byte("-> X25519 "), []byte("-> X25519  "), 1)
+	_, err = Decrypt(header, id)
+	require.Error(t, err)
+
+	_, err = Decrypt([]byte("not age"), id)
+	require.ErrorIs(t, err, ErrInvalidHeader)
+
+	_, err = Encrypt([]byte("x"))
+	require.ErrorIs(t, err, ErrNoRecipients)
+}
+
+func TestIdentityFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "keys", "identity.txt")
+	id, err := GenerateIdentity()
+	require.NoError(t, err)
+	require.NoError(t, WriteIdentityFile(path, id))
+	require.Error(t, WriteIdentityFile(path, id), "must not overwrite")
+
+	ids, err := ReadIdentityFile(path)
+	require.NoError(t, err)
+	require.Len(t, ids, 1)
+	assert.Equal(t, id.String(), ids[0].String())
+
+	e := &Encryptor{Recipients: []*Recipient{id.Recipient()}, Identities: ids}
+	ciphertext, err := e.Encrypt([]byte("bundle"))
+	require.NoError(t, err)
+	plaintext, err := e.Decrypt(ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "bundle", string(plaintext))
+}
@@ -71,12 +71,13 @@ func TestNewClient(t *testing.T) {
 }
 
 func TestNewClient_Defaults(t *testing.T) {
+	t.Setenv("PREFLIGHT_HOME", "/srv/preflight")
 	cfg := ClientConfig{} // Empty config
 
 	client := NewClient(cfg)
 
-	assert.Contains(t, client.socketPath, ".preflight")
-	assert.Contains(t, client.lockPath, ".preflight")
+	assert.Equal(t, filepath.Join("/srv/preflight", "agent.sock"), client.socketPath)
+	assert.Equal(t, filepath.Join("/srv/preflight", "agent.lock"), client.lockPath)
 	assert.Equal(t, 30*time.Second, client.timeout)
 }
 
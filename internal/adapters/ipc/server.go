@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/felixgeelhaar/preflight/internal/domain/agent"
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
 )
 
 // AgentProvider provides access to agent operations.
@@ -44,14 +45,14 @@ type ServerConfig struct {
 
 // DefaultSocketPath returns the default socket path.
 func DefaultSocketPath() string {
-	home, _ := os.UserHomeDir()
-	return filepath.Join(home, ".preflight", "agent.sock")
+	path, _ := platform.Path(platform.StateDir, "agent.sock")
+	return path
 }
 
 // DefaultLockPath returns the default lock file path.
 func DefaultLockPath() string {
-	home, _ := os.UserHomeDir()
-	return filepath.Join(home, ".preflight", "agent.lock")
+	path, _ := platform.Path(platform.StateDir, "agent.lock")
+	return path
 }
 
 // NewServer creates a new IPC server.
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -84,15 +85,16 @@ func TestNewServer(t *testing.T) {
 }
 
 func TestNewServer_Defaults(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_STATE_HOME", "")
 	provider := &mockAgentProvider{}
 	cfg := ServerConfig{} // Empty config
 
 	server := NewServer(cfg, provider)
 
-	assert.Contains(t, server.socketPath, ".preflight")
-	assert.Contains(t, server.socketPath, "agent.sock")
-	assert.Contains(t, server.lockPath, ".preflight")
-	assert.Contains(t, server.lockPath, "agent.lock")
+	assert.Equal(t, filepath.Join(home, ".local", "state", "preflight", "agent.sock"), server.socketPath)
+	assert.Equal(t, filepath.Join(home, ".local", "state", "preflight", "agent.lock"), server.lockPath)
 }
 
 func TestServer_StartStop(t *testing.T) {
@@ -327,15 +329,13 @@ func TestServer_StartAfterClose(t *testing.T) {
 }
 
 func TestDefaultSocketPath(t *testing.T) {
-	path := DefaultSocketPath()
-	assert.Contains(t, path, ".preflight")
-	assert.Contains(t, path, "agent.sock")
+	t.Setenv("PREFLIGHT_HOME", "/srv/preflight")
+	assert.Equal(t, filepath.Join("/srv/preflight", "agent.sock"), DefaultSocketPath())
 }
 
 func TestDefaultLockPath(t *testing.T) {
-	path := DefaultLockPath()
-	assert.Contains(t, path, ".preflight")
-	assert.Contains(t, path, "agent.lock")
+	t.Setenv("PREFLIGHT_HOME", "/srv/preflight")
+	assert.Equal(t, filepath.Join("/srv/preflight", "agent.lock"), DefaultLockPath())
 }
 
 func TestServer_SocketPath(t *testing.T) {
@@ -11,15 +11,24 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-// YAMLRepository implements lock.Repository using YAML files.
-type YAMLRepository struct{}
+// YAMLRepository implements lock.Repository using YAML files. Signed
+// lockfiles carry a signature block, which Load verifies.
+type YAMLRepository struct {
+	signer *lock.Signer
+}
 
 // NewYAMLRepository creates a new YAML-based lockfile repository.
 func NewYAMLRepository() *YAMLRepository {
 	return &YAMLRepository{}
 }
 
-// Load reads a lockfile from the given path.
+// WithSigner returns a repository that signs the lockfiles it saves.
+func (r *YAMLRepository) WithSigner(signer *lock.Signer) lock.Repository {
+	return &YAMLRepository{signer: signer}
+}
+
+// Load reads a lockfile from the given path. A signed lockfile that was
+// changed after signing returns lock.ErrSignatureInvalid.
 func (r *YAMLRepository) Load(_ context.Context, path string) (*lock.Lockfile, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -29,9 +38,9 @@ func (r *YAMLRepository) Load(_ context.Context, path string) (*lock.Lockfile, e
 		return nil, fmt.Errorf("failed to read lockfile: %w", err)
 	}
 
-	var dto lock.LockfileDTO
-	if err := yaml.Unmarshal(data, &dto); err != nil {
-		return nil, fmt.Errorf("%w: %w", lock.ErrLockfileCorrupt, err)
+	dto, _, err := decodeLockfile(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
 	}
 
 	lockfile, err := lock.LockfileFromDTO(dto)
@@ -50,6 +59,12 @@ func (r *YAMLRepository) Save(_ context.Context, path string, lockfile *lock.Loc
 	if err != nil {
 		return fmt.Errorf("%w: %w", lock.ErrSaveFailed, err)
 	}
+	if r.signer != nil {
+		dto.Signature = r.signer.Sign(data)
+		if data, err = yaml.Marshal(&dto); err != nil {
+			return fmt.Errorf("%w: %w", lock.ErrSaveFailed, err)
+		}
+	}
 
 	// Ensure directory exists
 	dir := filepath.Dir(path)
@@ -77,5 +92,52 @@ func (r *YAMLRepository) Exists(_ context.Context, path string) bool {
 	return err == nil
 }
 
-// Ensure YAMLRepository implements lock.Repository.
-var _ lock.Repository = (*YAMLRepository)(nil)
+// ReadProvenance verifies the signature of lockfile data and returns who
+// signed it, or nil for an unsigned lockfile.
+func ReadProvenance(data []byte) (*lock.Provenance, error) {
+	_, provenance, err := decodeLockfile(data)
+	return provenance, err
+}
+
+// Resign replaces the signature of lockfile data, which may have been
+// edited since it was signed, with one by signer. A nil signer drops it.
+func Resign(data []byte, signer *lock.Signer) ([]byte, error) {
+	var dto lock.LockfileDTO
+	if err := yaml.Unmarshal(data, &dto); err != nil {
+		return nil, fmt.Errorf("%w: %w", lock.ErrLockfileCorrupt, err)
+	}
+	dto.Signature = nil
+	content, err := yaml.Marshal(&dto)
+	if err != nil || signer == nil {
+		return content, err
+	}
+	dto.Signature = signer.Sign(content)
+	return yaml.Marshal(&dto)
+}
+
+// decodeLockfile parses lockfile data and verifies its signature, which
+// covers the lockfile serialized without it.
+func decodeLockfile(data []byte) (lock.LockfileDTO, *lock.Provenance, error) {
+	var dto lock.LockfileDTO
+	if err := yaml.Unmarshal(data, &dto); err != nil {
+		return dto, nil, fmt.Errorf("%w: %w", lock.ErrLockfileCorrupt, err)
+	}
+	if dto.Signature == nil {
+		return dto, nil, nil
+	}
+
+	signature := dto.Signature
+	dto.Signature = nil
+	content, err := yaml.Marshal(&dto)
+	if err != nil {
+		return dto, nil, fmt.Errorf("%w: %w", lock.ErrLockfileCorrupt, err)
+	}
+	provenance, err := lock.VerifySignature(content, signature)
+	if err != nil {
+		return dto, nil, err
+	}
+	return dto, &provenance, nil
+}
+
+// Ensure YAMLRepository implements lock.SigningRepository.
+var _ lock.SigningRepository = (*YAMLRepository)(nil)
@@ -2,8 +2,11 @@ package lockfile
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -11,6 +14,7 @@ import (
 	"github.com/felixgeelhaar/preflight/internal/domain/lock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
 )
 
 func TestYAMLRepository_SaveAndLoad(t *testing.T) {
@@ -258,3 +262,54 @@ func TestYAMLRepository_SaveToReadOnlyDir(t *testing.T) {
 	err = repo.Save(ctx, lockPath, lockfile)
 	assert.ErrorIs(t, err, lock.ErrSaveFailed)
 }
+
+func TestYAMLRepository_Signed(t *testing.T) {
+	t.Parallel()
+
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	sshPub, err := ssh.NewPublicKey(pub)
+	require.NoError(t, err)
+	signer, err := lock.NewSigner(key, "team", ssh.MarshalAuthorizedKey(sshPub))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	lockPath := filepath.Join(t.TempDir(), "preflight.lock")
+	lockfile := lock.NewLockfile(config.ModeLocked, lock.MachineInfoFromSystem())
+	pkg, err := lock.NewPackageLock("brew", "git", "2.43.0", lock.IntegrityFromData(lock.AlgorithmSHA256, []byte("git")), time.Now())
+	require.NoError(t, err)
+	require.NoError(t, lockfile.AddPackage(pkg))
+
+	require.NoError(t, NewYAMLRepository().WithSigner(signer).Save(ctx, lockPath, lockfile))
+	data, err := os.ReadFile(lockPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "signature:\n    key_id: team\n")
+
+	provenance, err := ReadProvenance(data)
+	require.NoError(t, err)
+	require.NotNil(t, provenance)
+	assert.Equal(t, "team", provenance.KeyID)
+
+	loaded, err := NewYAMLRepository().Load(ctx, lockPath)
+	require.NoError(t, err)
+	assert.Equal(t, 1, loaded.PackageCount())
+
+	// A hand-edited version is detected on read.
+	tampered := []byte(strings.Replace(string(data), "2.43.0", "2.44.0", 1))
+	require.NoError(t, os.WriteFile(lockPath, tampered, 0o644))
+	_, err = NewYAMLRepository().Load(ctx, lockPath)
+	require.ErrorIs(t, err, lock.ErrSignatureInvalid)
+
+	// Re-signing makes an edited lockfile valid again; a nil signer drops
+	// the signature.
+	resigned, err := Resign(tampered, signer)
+	require.NoError(t, err)
+	provenance, err = ReadProvenance(resigned)
+	require.NoError(t, err)
+	require.NotNil(t, provenance)
+	unsigned, err := Resign(tampered, nil)
+	require.NoError(t, err)
+	provenance, err = ReadProvenance(unsigned)
+	require.NoError(t, err)
+	assert.Nil(t, provenance)
+}
@@ -0,0 +1,170 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+
+	"github.com/felixgeelhaar/preflight/internal/ports"
+)
+
+// Format is the output format of a SlogLogger.
+type Format string
+
+// Format constants.
+const (
+	FormatText Format = "text"
+	FormatJSON Format = "json"
+)
+
+// ParseFormat parses a log format name (text, json).
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(s))); f {
+	case FormatText, FormatJSON:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown log format %q (use text or json)", s)
+	}
+}
+
+// ParseLevel parses a log level name (debug, info, warn, error).
+func ParseLevel(s string) (ports.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return ports.LevelDebug, nil
+	case "info":
+		return ports.LevelInfo, nil
+	case "warn", "warning":
+		return ports.LevelWarn, nil
+	case "error":
+		return ports.LevelError, nil
+	default:
+		return 0, fmt.Errorf("unknown log level %q (use debug, info, warn or error)", s)
+	}
+}
+
+// SlogLogger logs structured messages through log/slog.
+type SlogLogger struct {
+	logger *slog.Logger
+	level  *slog.LevelVar
+}
+
+// NewSlogLogger creates a logger writing to w in format, dropping messages
+// below level.
+func NewSlogLogger(w io.Writer, level ports.Level, format Format) *SlogLogger {
+	levelVar := &slog.LevelVar{}
+	levelVar.Set(slogLevel(level))
+
+	opts := &slog.HandlerOptions{Level: levelVar}
+	var handler slog.Handler
+	if format == FormatJSON {
+		handler = slog.NewJSONHandler(w, opts)
+	} else {
+		handler = slog.NewTextHandler(w, opts)
+	}
+	return &SlogLogger{logger: slog.New(handler), level: levelVar}
+}
+
+// Debug logs a debug message.
+func (l *SlogLogger) Debug(ctx context.Context, msg string, fields ...ports.Field) {
+	l.log(ctx, slog.LevelDebug, msg, fields)
+}
+
+// Info logs an informational message.
+func (l *SlogLogger) Info(ctx context.Context, msg string, fields ...ports.Field) {
+	l.log(ctx, slog.LevelInfo, msg, fields)
+}
+
+// Warn logs a warning message.
+func (l *SlogLogger) Warn(ctx context.Context, msg string, fields ...ports.Field) {
+	l.log(ctx, slog.LevelWarn, msg, fields)
+}
+
+// Error logs an error message.
+func (l *SlogLogger) Error(ctx context.Context, msg string, fields ...ports.Field) {
+	l.log(ctx, slog.LevelError, msg, fields)
+}
+
+// With returns a new logger with additional fields. It shares the level of l.
+func (l *SlogLogger) With(fields ...ports.Field) ports.Logger {
+	return &SlogLogger{logger: l.logger.With(attrs(fields)...), level: l.level}
+}
+
+// Level returns the minimum log level.
+func (l *SlogLogger) Level() ports.Level {
+	switch level := l.level.Level(); {
+	case level < slog.LevelInfo:
+		return ports.LevelDebug
+	case level < slog.LevelWarn:
+		return ports.LevelInfo
+	case level < slog.LevelError:
+		return ports.LevelWarn
+	default:
+		return ports.LevelError
+	}
+}
+
+// SetLevel sets the minimum log level.
+func (l *SlogLogger) SetLevel(level ports.Level) {
+	l.level.Set(slogLevel(level))
+}
+
+func (l *SlogLogger) log(ctx context.Context, level slog.Level, msg string, fields []ports.Field) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	l.logger.Log(ctx, level, msg, attrs(fields)...)
+}
+
+func attrs(fields []ports.Field) []any {
+	args := make([]any, 0, len(fields))
+	for _, f := range fields {
+		if err, ok := f.Value.(error); ok {
+			args = append(args, slog.String(f.Key, err.Error()))
+			continue
+		}
+		args = append(args, slog.Any(f.Key, f.Value))
+	}
+	return args
+}
+
+func slogLevel(level ports.Level) slog.Level {
+	switch level {
+	case ports.LevelDebug:
+		return slog.LevelDebug
+	case ports.LevelInfo:
+		return slog.LevelInfo
+	case ports.LevelWarn:
+		return slog.LevelWarn
+	default:
+		return slog.LevelError
+	}
+}
+
+// Ensure SlogLogger implements Logger.
+var _ ports.Logger = (*SlogLogger)(nil)
+
+// defaultLogger holds the process-wide logger set by the CLI.
+var defaultLogger atomic.Value
+
+// Default returns the process-wide logger, a NopLogger until SetDefault is
+// called.
+func Default() ports.Logger {
+	if l, ok := defaultLogger.Load().(loggerHolder); ok {
+		return l.logger
+	}
+	return NewNopLogger()
+}
+
+// SetDefault sets the process-wide logger.
+func SetDefault(logger ports.Logger) {
+	defaultLogger.Store(loggerHolder{logger})
+}
+
+// loggerHolder lets loggers of different types share the atomic.Value.
+type loggerHolder struct {
+	logger ports.Logger
+}
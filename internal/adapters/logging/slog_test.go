@@ -0,0 +1,87 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/ports"
+)
+
+func TestSlogLogger_ImplementsInterface(_ *testing.T) {
+	var _ ports.Logger = NewSlogLogger(&bytes.Buffer{}, ports.LevelInfo, FormatText)
+}
+
+func TestSlogLogger_JSONOutput(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewSlogLogger(&buf, ports.LevelInfo, FormatJSON).With(ports.F("target", "work"))
+
+	logger.Info(context.Background(), "apply finished", ports.F("steps", 3), ports.F("error", errors.New("boom")))
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("output should be JSON, got %q: %v", buf.String(), err)
+	}
+	if entry["msg"] != "apply finished" || entry["level"] != "INFO" {
+		t.Errorf("unexpected entry %v", entry)
+	}
+	if entry["target"] != "work" || entry["steps"] != float64(3) || entry["error"] != "boom" {
+		t.Errorf("entry should carry its fields, got %v", entry)
+	}
+}
+
+func TestSlogLogger_Level(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewSlogLogger(&buf, ports.LevelWarn, FormatText)
+	ctx := context.Background()
+
+	logger.Info(ctx, "hidden")
+	logger.Warn(ctx, "shown")
+	if strings.Contains(buf.String(), "hidden") || !strings.Contains(buf.String(), "level=WARN msg=shown") {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+
+	child := logger.With(ports.F("k", "v"))
+	logger.SetLevel(ports.LevelDebug)
+	if child.Level() != ports.LevelDebug {
+		t.Errorf("child level = %v, want %v", child.Level(), ports.LevelDebug)
+	}
+	child.Debug(ctx, "debug")
+	if !strings.Contains(buf.String(), "msg=debug k=v") {
+		t.Errorf("child should log at the shared level, got %q", buf.String())
+	}
+}
+
+func TestParseLevelAndFormat(t *testing.T) {
+	for input, want := range map[string]ports.Level{"debug": ports.LevelDebug, "INFO": ports.LevelInfo, "warn": ports.LevelWarn, "error": ports.LevelError} {
+		got, err := ParseLevel(input)
+		if err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v", input, got, err, want)
+		}
+	}
+	if _, err := ParseLevel("trace"); err == nil {
+		t.Error("ParseLevel should reject unknown levels")
+	}
+
+	if f, err := ParseFormat("json"); err != nil || f != FormatJSON {
+		t.Errorf("ParseFormat(json) = %v, %v", f, err)
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("ParseFormat should reject unknown formats")
+	}
+}
+
+func TestDefault(t *testing.T) {
+	if _, ok := Default().(*NopLogger); !ok {
+		t.Fatal("Default should be a NopLogger until set")
+	}
+	logger := NewSlogLogger(&bytes.Buffer{}, ports.LevelInfo, FormatText)
+	SetDefault(logger)
+	defer SetDefault(NewNopLogger())
+	if Default() != logger {
+		t.Error("Default should return the logger set with SetDefault")
+	}
+}
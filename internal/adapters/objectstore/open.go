@@ -0,0 +1,111 @@
+package objectstore
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/sync"
+)
+
+// Remote URL errors.
+var (
+	// ErrUnsupportedScheme is returned for remote URLs without a known backend.
+	ErrUnsupportedScheme = errors.New("unsupported remote scheme")
+	// ErrInsecureCredentials is returned when credentials would be sent in
+	// clear text over plain HTTP to a host other than this machine.
+	ErrInsecureCredentials = errors.New("refusing to send credentials over plain HTTP")
+)
+
+// gcsEndpoint is the S3-compatible interoperability endpoint of Google Cloud Storage.
+const gcsEndpoint = "https://storage.googleapis.com"
+
+// Open returns the store and object prefix for a remote URL:
+//
+//	s3://bucket/prefix       AWS S3 or S3-compatible storage
+//	gs://bucket/prefix       Google Cloud Storage (HMAC interoperability keys)
+//	webdav://host/path       WebDAV over HTTP (davs:// or webdavs:// for HTTPS)
+//
+// Credentials are read from the environment: AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN, AWS_REGION and AWS_ENDPOINT_URL
+// for S3; PREFLIGHT_GCS_ACCESS_KEY and PREFLIGHT_GCS_SECRET_KEY for GCS;
+// PREFLIGHT_WEBDAV_USER and PREFLIGHT_WEBDAV_PASSWORD (or the URL's
+// userinfo) for WebDAV. Basic auth over plain HTTP would expose them, so
+// webdav:// and dav:// refuse credentials unless the host is a loopback
+// address.
+func Open(rawURL string) (sync.RemoteStore, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid remote URL: %w", err)
+	}
+	if u.Host == "" {
+		return nil, "", fmt.Errorf("invalid remote URL %q: missing host or bucket", rawURL)
+	}
+	prefix := strings.Trim(u.Path, "/")
+
+	switch u.Scheme {
+	case "s3":
+		region := os.Getenv("AWS_REGION")
+		if region == "" {
+			region = os.Getenv("AWS_DEFAULT_REGION")
+		}
+		store, err := NewS3Store(S3Config{
+			Scheme:   "s3",
+			Bucket:   u.Host,
+			Region:   region,
+			Endpoint: os.Getenv("AWS_ENDPOINT_URL"),
+			Credentials: Credentials{
+				AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+				SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+				SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+			},
+		})
+		return store, prefix, err
+	case "gs":
+		store, err := NewS3Store(S3Config{
+			Scheme:   "gs",
+			Bucket:   u.Host,
+			Region:   "auto",
+			Endpoint: gcsEndpoint,
+			Credentials: Credentials{
+				AccessKeyID:     os.Getenv("PREFLIGHT_GCS_ACCESS_KEY"),
+				SecretAccessKey: os.Getenv("PREFLIGHT_GCS_SECRET_KEY"),
+			},
+		})
+		return store, prefix, err
+	case "webdav", "dav", "webdavs", "davs":
+		scheme := "http"
+		if strings.HasSuffix(u.Scheme, "s") {
+			scheme = "https"
+		}
+		user := os.Getenv("PREFLIGHT_WEBDAV_USER")
+		pass := os.Getenv("PREFLIGHT_WEBDAV_PASSWORD")
+		if u.User != nil {
+			user = u.User.Username()
+			if p, ok := u.User.Password(); ok {
+				pass = p
+			}
+		}
+		if scheme == "http" && (user != "" || pass != "") && !isLoopback(u.Hostname()) {
+			return nil, "", fmt.Errorf("%w to %s; use %ss://", ErrInsecureCredentials, u.Host, u.Scheme)
+		}
+		base := url.URL{Scheme: scheme, Host: u.Host, Path: u.Path}
+		store, err := NewWebDAVStore(base.String(), user, pass, nil)
+		// The URL path is the WebDAV root; no extra prefix is needed.
+		return store, "", err
+	default:
+		return nil, "", fmt.Errorf("%w %q (use s3://, gs:// or webdav://)", ErrUnsupportedScheme, u.Scheme)
+	}
+}
+
+// isLoopback reports whether host names this machine.
+func isLoopback(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,79 @@
+package objectstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpen(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "key")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_REGION", "eu-west-1")
+	t.Setenv("AWS_ENDPOINT_URL", "")
+	t.Setenv("PREFLIGHT_GCS_ACCESS_KEY", "gkey")
+	t.Setenv("PREFLIGHT_GCS_SECRET_KEY", "gsecret")
+	t.Setenv("PREFLIGHT_WEBDAV_USER", "")
+	t.Setenv("PREFLIGHT_WEBDAV_PASSWORD", "")
+
+	tests := []struct {
+		url    string
+		store  string
+		prefix string
+	}{
+		{url: "s3://bucket/team/laptop", store: "s3://bucket", prefix: "team/laptop"},
+		{url: "gs://bucket", store: "gs://bucket", prefix: ""},
+		{url: "davs://user:pw@cloud.example.com/remote.php/dav/files/me", store: "https://cloud.example.com/remote.php/dav/files/me", prefix: ""},
+		{url: "webdav://nas.local/preflight", store: "http://nas.local/preflight", prefix: ""},
+		{url: "webdav://user:pw@127.0.0.1:8080/dav", store: "http://127.0.0.1:8080/dav", prefix: ""},
+		{url: "dav://user:pw@localhost/dav", store: "http://localhost/dav", prefix: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			store, prefix, err := Open(tt.url)
+			require.NoError(t, err)
+			assert.Equal(t, tt.store, store.String())
+			assert.Equal(t, tt.prefix, prefix)
+		})
+	}
+
+	s3, _, err := Open("s3://bucket")
+	require.NoError(t, err)
+	assert.Equal(t, "bucket.s3.eu-west-1.amazonaws.com", s3.(*S3Store).endpoint.Host)
+}
+
+func TestOpen_Errors(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+
+	_, _, err := Open("ftp://host/path")
+	require.ErrorIs(t, err, ErrUnsupportedScheme)
+
+	_, _, err = Open("s3:///prefix")
+	require.Error(t, err)
+
+	_, _, err = Open("s3://bucket")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing credentials")
+}
+
+func TestOpen_WebDAVCredentialsOverHTTP(t *testing.T) {
+	t.Setenv("PREFLIGHT_WEBDAV_USER", "")
+	t.Setenv("PREFLIGHT_WEBDAV_PASSWORD", "")
+
+	_, _, err := Open("webdav://user:pw@nas.example.com/preflight")
+	require.ErrorIs(t, err, ErrInsecureCredentials)
+	assert.Contains(t, err.Error(), "webdavs://")
+
+	t.Setenv("PREFLIGHT_WEBDAV_USER", "user")
+	t.Setenv("PREFLIGHT_WEBDAV_PASSWORD", "pw")
+	_, _, err = Open("dav://192.168.1.10/preflight")
+	require.ErrorIs(t, err, ErrInsecureCredentials)
+	assert.Contains(t, err.Error(), "davs://")
+
+	_, _, err = Open("webdavs://nas.example.com/preflight")
+	require.NoError(t, err)
+	_, _, err = Open("webdav://[::1]:8080/preflight")
+	require.NoError(t, err)
+}
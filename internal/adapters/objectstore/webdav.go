@@ -0,0 +1,111 @@
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/sync"
+)
+
+// WebDAVStore stores objects on a WebDAV server such as Nextcloud.
+type WebDAVStore struct {
+	base     *url.URL
+	username string
+	password string
+	client   *http.Client
+}
+
+// NewWebDAVStore creates a WebDAV store rooted at baseURL (http or https).
+func NewWebDAVStore(baseURL, username, password string, client *http.Client) (*WebDAVStore, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("invalid WebDAV URL %q", baseURL)
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 60 * time.Second}
+	}
+	return &WebDAVStore{base: u, username: username, password: password, client: client}, nil
+}
+
+// String implements sync.RemoteStore.
+func (s *WebDAVStore) String() string {
+	u := *s.base
+	u.User = nil
+	return u.String()
+}
+
+// Put implements sync.RemoteStore. Missing parent collections are created.
+func (s *WebDAVStore) Put(ctx context.Context, key string, data []byte) error {
+	if err := s.mkcolAll(ctx, key); err != nil {
+		return err
+	}
+	resp, err := s.do(ctx, http.MethodPut, s.url(key), data)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode/100 != 2 {
+		return responseError(resp)
+	}
+	return nil
+}
+
+// Get implements sync.RemoteStore.
+func (s *WebDAVStore) Get(ctx context.Context, key string) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, s.url(key), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, sync.ErrRemoteNotFound
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, responseError(resp)
+	}
+	return readBody(resp.Body)
+}
+
+// mkcolAll creates the collections leading up to key. Servers answer 405
+// for collections that already exist.
+func (s *WebDAVStore) mkcolAll(ctx context.Context, key string) error {
+	parts := strings.Split(strings.Trim(key, "/"), "/")
+	for i := 1; i < len(parts); i++ {
+		resp, err := s.do(ctx, "MKCOL", s.url(strings.Join(parts[:i], "/"))+"/", nil)
+		if err != nil {
+			return err
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode/100 != 2 && resp.StatusCode != http.StatusMethodNotAllowed {
+			return fmt.Errorf("creating collection %s: unexpected status %s", strings.Join(parts[:i], "/"), resp.Status)
+		}
+	}
+	return nil
+}
+
+func (s *WebDAVStore) url(key string) string {
+	u := *s.base
+	u.User = nil
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + strings.TrimPrefix(key, "/")
+	u.RawPath = ""
+	return u.String()
+}
+
+func (s *WebDAVStore) do(ctx context.Context, method, target string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if s.username != "" || s.password != "" {
+		req.SetBasicAuth(s.username, s.password)
+	}
+	return s.client.Do(req)
+}
+
+// Ensure WebDAVStore implements sync.RemoteStore.
+var _ sync.RemoteStore = (*WebDAVStore)(nil)
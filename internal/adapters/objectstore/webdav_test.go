@@ -0,0 +1,76 @@
+package objectstore
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	gosync "sync"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebDAVStore_PutGet(t *testing.T) {
+	t.Parallel()
+
+	var mu gosync.Mutex
+	objects := map[string][]byte{}
+	collections := map[string]bool{"/dav/": true}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "me" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case "MKCOL":
+			if collections[r.URL.Path] {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			collections[r.URL.Path] = true
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodPut:
+			data, _ := io.ReadAll(r.Body)
+			objects[r.URL.Path] = data
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodGet:
+			data, ok := objects[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write(data)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	store, err := NewWebDAVStore(srv.URL+"/dav", "me", "secret", nil)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	_, err = store.Get(ctx, "preflight.bundle.age")
+	require.ErrorIs(t, err, sync.ErrRemoteNotFound)
+
+	require.NoError(t, store.Put(ctx, "machines/work/preflight.bundle.age", []byte("ciphertext")))
+	assert.True(t, collections["/dav/machines/"])
+	assert.True(t, collections["/dav/machines/work/"])
+
+	// A second put tolerates existing collections.
+	require.NoError(t, store.Put(ctx, "machines/work/preflight.bundle.age", []byte("updated")))
+
+	data, err := store.Get(ctx, "machines/work/preflight.bundle.age")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("updated"), data)
+}
+
+func TestNewWebDAVStore_InvalidURL(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewWebDAVStore("ftp://example.com", "", "", nil)
+	require.Error(t, err)
+}
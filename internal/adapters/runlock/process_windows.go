@@ -0,0 +1,16 @@
+//go:build windows
+
+package runlock
+
+import "os"
+
+// processRunning reports whether a process with pid exists. On Windows
+// FindProcess opens the process and fails when it does not exist.
+func processRunning(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	_ = process.Release()
+	return true
+}
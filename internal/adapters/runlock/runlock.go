@@ -0,0 +1,269 @@
+// Package runlock provides the advisory lock that keeps preflight processes
+// which change the system, such as apply, doctor --fix and the agent's
+// reconcile, from running at the same time.
+package runlock
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
+)
+
+// ErrLocked is returned when another live process holds the lock.
+var ErrLocked = errors.New("another preflight process is running")
+
+const (
+	// pollInterval is how often Wait retries a held lock.
+	pollInterval = 500 * time.Millisecond
+	// gracePeriod is how long a lock that cannot be read, or a guard for
+	// breaking a stale lock, counts as held before it is considered left
+	// behind by a crashed process.
+	gracePeriod = 10 * time.Second
+	// maxAttempts bounds the retries after breaking a stale lock.
+	maxAttempts = 5
+)
+
+// Holder describes the process holding the lock.
+type Holder struct {
+	PID       int       `json:"pid"`
+	Command   string    `json:"command"`
+	Hostname  string    `json:"hostname"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// LockedError reports the process holding the lock. It matches ErrLocked.
+type LockedError struct {
+	Path   string
+	Holder Holder
+}
+
+// Error identifies the holding process.
+func (e *LockedError) Error() string {
+	if e.Holder.PID == 0 {
+		return fmt.Sprintf("%s: %s is held by a process that could not be identified", ErrLocked, e.Path)
+	}
+	return fmt.Sprintf("%s: %q (pid %d on %s) has held %s since %s",
+		ErrLocked, e.Holder.Command, e.Holder.PID, e.Holder.Hostname, e.Path,
+		e.Holder.StartedAt.Local().Format(time.RFC3339))
+}
+
+// Unwrap returns ErrLocked.
+func (e *LockedError) Unwrap() error {
+	return ErrLocked
+}
+
+// Lock is a held run lock.
+type Lock struct {
+	path string
+	data []byte
+}
+
+// DefaultPath returns run.lock in the preflight state directory.
+func DefaultPath() (string, error) {
+	return platform.Path(platform.StateDir, "run.lock")
+}
+
+// Acquire takes the lock at path for the current process. A lock left
+// behind by a process that is no longer running on this host is removed.
+// When a live process holds it, Acquire returns a *LockedError.
+//
+// The lock file is written in full to a temporary file and then linked
+// into place, so it never exists without its holder. Stale locks are only
+// removed while holding a guard file, after checking that the lock was not
+// replaced in the meantime.
+func Acquire(path string) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+	hostname, _ := os.Hostname()
+	holder := Holder{
+		PID:       os.Getpid(),
+		Command:   commandLine(),
+		Hostname:  hostname,
+		StartedAt: time.Now().UTC(),
+	}
+	data, err := json.Marshal(holder)
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		created, err := createExclusive(path, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create lock %s: %w", path, err)
+		}
+		if created {
+			return &Lock{path: path, data: data}, nil
+		}
+
+		raw, current, err := readLock(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue // released in the meantime
+			}
+			return nil, fmt.Errorf("failed to read lock %s: %w", path, err)
+		}
+		if !isStale(path, current, hostname) {
+			return nil, &LockedError{Path: path, Holder: current}
+		}
+		if err := breakStale(path, raw); err != nil {
+			if errors.Is(err, ErrLocked) {
+				return nil, &LockedError{Path: path, Holder: current}
+			}
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("failed to acquire lock %s", path)
+}
+
+// createExclusive creates path holding data unless it already exists. The
+// data is written to a temporary file first and hard linked to path, which
+// fails atomically when path exists.
+func createExclusive(path string, data []byte) (bool, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return false, err
+	}
+	tmpPath := tmp.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	_, writeErr := tmp.Write(data)
+	closeErr := tmp.Close()
+	if writeErr != nil || closeErr != nil {
+		return false, errors.Join(writeErr, closeErr)
+	}
+	if err := os.Link(tmpPath, path); err != nil {
+		if os.IsExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// breakStale removes the stale lock at path whose content was raw. Only
+// the holder of the path.break guard removes stale locks, and only after
+// re-reading the lock, so that a lock taken by another process after raw
+// was read is never removed. It returns ErrLocked when another process is
+// breaking the lock.
+func breakStale(path string, raw []byte) error {
+	guard := path + ".break"
+	created, err := createExclusive(guard, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create lock guard %s: %w", guard, err)
+	}
+	if !created {
+		// A guard outlives its holder only when that process crashed.
+		if info, err := os.Stat(guard); err == nil && time.Since(info.ModTime()) > gracePeriod {
+			_ = os.Remove(guard)
+		}
+		return ErrLocked
+	}
+	defer func() { _ = os.Remove(guard) }()
+
+	// #nosec G304 -- path is preflight's own lock file.
+	current, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read lock %s: %w", path, err)
+	}
+	if !bytes.Equal(current, raw) {
+		return nil // taken over in the meantime; retry
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove stale lock %s: %w", path, err)
+	}
+	return nil
+}
+
+// Wait acquires the lock at path, retrying while another process holds it
+// until ctx is done.
+func Wait(ctx context.Context, path string) (*Lock, error) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		lock, err := Acquire(path)
+		if !errors.Is(err, ErrLocked) {
+			return lock, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("gave up waiting: %w", err)
+		case <-ticker.C:
+		}
+	}
+}
+
+// Release removes the lock, unless it is no longer held by l.
+func (l *Lock) Release() error {
+	// #nosec G304 -- path is preflight's own lock file.
+	current, err := os.ReadFile(l.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to release lock %s: %w", l.path, err)
+	}
+	if !bytes.Equal(current, l.data) {
+		return nil
+	}
+	if err := os.Remove(l.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to release lock %s: %w", l.path, err)
+	}
+	return nil
+}
+
+// Read returns the holder of the lock at path. A lock that cannot be
+// parsed returns a zero Holder.
+func Read(path string) (Holder, error) {
+	_, holder, err := readLock(path)
+	return holder, err
+}
+
+// readLock returns the content of the lock at path and its holder.
+func readLock(path string) ([]byte, Holder, error) {
+	// #nosec G304 -- path is preflight's own lock file.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, Holder{}, err
+	}
+	var holder Holder
+	if err := json.Unmarshal(data, &holder); err != nil {
+		return data, Holder{}, nil
+	}
+	return data, holder, nil
+}
+
+// isStale reports whether the holder of the lock at path no longer runs.
+// Locks taken on another host, such as with a shared home directory, are
+// never considered stale. A lock without a holder, as written by another
+// tool or left truncated, counts as held until it is older than
+// gracePeriod.
+func isStale(path string, holder Holder, hostname string) bool {
+	if holder.PID <= 0 {
+		info, err := os.Stat(path)
+		return err == nil && time.Since(info.ModTime()) > gracePeriod
+	}
+	if holder.Hostname != "" && holder.Hostname != hostname {
+		return false
+	}
+	return !processRunning(holder.PID)
+}
+
+func commandLine() string {
+	if len(os.Args) == 0 {
+		return "preflight"
+	}
+	args := append([]string{filepath.Base(os.Args[0])}, os.Args[1:]...)
+	return strings.Join(args, " ")
+}
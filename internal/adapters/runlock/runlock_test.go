@@ -0,0 +1,225 @@
+package runlock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeHolder(t *testing.T, path string, holder Holder) {
+	t.Helper()
+	data, err := json.Marshal(holder)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+}
+
+func TestAcquire_CreatesAndReleases(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "nested", "run.lock")
+	lock, err := Acquire(path)
+	require.NoError(t, err)
+
+	holder, err := Read(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.Getpid(), holder.PID)
+	assert.NotEmpty(t, holder.Command)
+	assert.False(t, holder.StartedAt.IsZero())
+
+	require.NoError(t, lock.Release())
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestAcquire_HeldByLiveProcess(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "run.lock")
+	hostname, _ := os.Hostname()
+	started := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	writeHolder(t, path, Holder{PID: os.Getpid(), Command: "preflight apply", Hostname: hostname, StartedAt: started})
+
+	_, err := Acquire(path)
+	require.ErrorIs(t, err, ErrLocked)
+
+	var locked *LockedError
+	require.True(t, errors.As(err, &locked))
+	assert.Equal(t, os.Getpid(), locked.Holder.PID)
+	assert.Contains(t, err.Error(), `"preflight apply"`)
+	assert.Contains(t, err.Error(), path)
+}
+
+func TestAcquire_RemovesStaleLock(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "run.lock")
+	hostname, _ := os.Hostname()
+	// PIDs are far below this bound on every supported platform.
+	writeHolder(t, path, Holder{PID: 1 << 30, Command: "preflight apply", Hostname: hostname})
+
+	lock, err := Acquire(path)
+	require.NoError(t, err)
+	defer func() { _ = lock.Release() }()
+
+	holder, err := Read(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.Getpid(), holder.PID)
+}
+
+func TestAcquire_CorruptLockHeldUntilGracePeriod(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "run.lock")
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+
+	_, err := Acquire(path)
+	require.ErrorIs(t, err, ErrLocked)
+	assert.Contains(t, err.Error(), "could not be identified")
+
+	old := time.Now().Add(-2 * gracePeriod)
+	require.NoError(t, os.Chtimes(path, old, old))
+	lock, err := Acquire(path)
+	require.NoError(t, err)
+	require.NoError(t, lock.Release())
+}
+
+func TestAcquire_StaleBreakGuard(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "run.lock")
+	hostname, _ := os.Hostname()
+	writeHolder(t, path, Holder{PID: 1 << 30, Command: "preflight apply", Hostname: hostname})
+
+	// Another process is breaking the stale lock.
+	require.NoError(t, os.WriteFile(path+".break", nil, 0o600))
+	_, err := Acquire(path)
+	require.ErrorIs(t, err, ErrLocked)
+
+	// A guard left behind by a crashed process expires.
+	old := time.Now().Add(-2 * gracePeriod)
+	require.NoError(t, os.Chtimes(path+".break", old, old))
+	_, err = Acquire(path)
+	require.ErrorIs(t, err, ErrLocked)
+	lock, err := Acquire(path)
+	require.NoError(t, err)
+	require.NoError(t, lock.Release())
+}
+
+func TestRelease_KeepsLockTakenOver(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "run.lock")
+	lock, err := Acquire(path)
+	require.NoError(t, err)
+
+	other := Holder{PID: os.Getpid(), Command: "preflight undo", Hostname: "host"}
+	writeHolder(t, path, other)
+	require.NoError(t, lock.Release())
+
+	holder, err := Read(path)
+	require.NoError(t, err)
+	assert.Equal(t, "preflight undo", holder.Command)
+}
+
+// TestAcquire_Concurrent checks that at most one of many concurrent
+// callers holds the lock at a time, starting from a free lock and from a
+// stale one.
+func TestAcquire_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	for _, stale := range []bool{false, true} {
+		path := filepath.Join(t.TempDir(), "run.lock")
+		if stale {
+			hostname, _ := os.Hostname()
+			writeHolder(t, path, Holder{PID: 1 << 30, Command: "preflight apply", Hostname: hostname})
+		}
+
+		var holders, maxHolders, acquired atomic.Int32
+		var wg sync.WaitGroup
+		start := make(chan struct{})
+		for i := 0; i < 16; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+				for j := 0; j < 20; j++ {
+					lock, err := Acquire(path)
+					if errors.Is(err, ErrLocked) {
+						continue
+					}
+					if !assert.NoError(t, err) {
+						return
+					}
+					acquired.Add(1)
+					n := holders.Add(1)
+					for {
+						m := maxHolders.Load()
+						if n <= m || maxHolders.CompareAndSwap(m, n) {
+							break
+						}
+					}
+					time.Sleep(time.Millisecond)
+					holders.Add(-1)
+					assert.NoError(t, lock.Release())
+				}
+			}()
+		}
+		close(start)
+		wg.Wait()
+
+		assert.Equal(t, int32(1), maxHolders.Load(), "stale=%v", stale)
+		assert.Positive(t, acquired.Load())
+	}
+}
+
+func TestAcquire_KeepsLockFromOtherHost(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "run.lock")
+	writeHolder(t, path, Holder{PID: 1 << 30, Command: "preflight apply", Hostname: "some-other-host.invalid"})
+
+	_, err := Acquire(path)
+	assert.ErrorIs(t, err, ErrLocked)
+}
+
+func TestWait_AcquiresAfterRelease(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "run.lock")
+	held, err := Acquire(path)
+	require.NoError(t, err)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_ = held.Release()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	lock, err := Wait(ctx, path)
+	require.NoError(t, err)
+	require.NoError(t, lock.Release())
+}
+
+func TestWait_GivesUpWhenContextDone(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "run.lock")
+	held, err := Acquire(path)
+	require.NoError(t, err)
+	defer func() { _ = held.Release() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = Wait(ctx, path)
+	assert.ErrorIs(t, err, ErrLocked)
+}
@@ -80,7 +80,11 @@ func buildCategorizationPrompt(req AICategorizationRequest) string {
 	// List packages to categorize
 	sb.WriteString("Packages to categorize:\n")
 	for _, item := range req.Items {
-		fmt.Fprintf(&sb, "- %s (provider: %s)\n", item.Name, item.Provider)
+		if item.Description != "" {
+			fmt.Fprintf(&sb, "- %s (provider: %s): %s\n", item.Name, item.Provider, item.Description)
+		} else {
+			fmt.Fprintf(&sb, "- %s (provider: %s)\n", item.Name, item.Provider)
+		}
 	}
 
 	sb.WriteString("\nRespond with JSON in this exact format:\n")
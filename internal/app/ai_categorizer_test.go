@@ -332,3 +332,14 @@ func TestProviderCategorizer_EmptyItems(t *testing.T) {
 	require.NotNil(t, result)
 	assert.Empty(t, result.Categorizations)
 }
+
+func TestBuildCategorizationPrompt_Descriptions(t *testing.T) {
+	t.Parallel()
+
+	prompt := buildCategorizationPrompt(AICategorizationRequest{Items: []CapturedItem{
+		{Provider: "brew", Name: "hyperfine", Description: "Command-line benchmarking tool"},
+		{Provider: "brew", Name: "oddity"},
+	}})
+	assert.Contains(t, prompt, "- hyperfine (provider: brew): Command-line benchmarking tool\n")
+	assert.Contains(t, prompt, "- oddity (provider: brew)\n")
+}
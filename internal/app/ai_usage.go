@@ -0,0 +1,111 @@
+package app
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/advisor"
+	"github.com/felixgeelhaar/preflight/internal/domain/audit"
+)
+
+// AuditUsageStore keeps AI usage as ai_usage events in the audit log.
+type AuditUsageStore struct {
+	service *audit.Service
+}
+
+// NewAuditUsageStore creates a usage store on top of an audit service.
+func NewAuditUsageStore(service *audit.Service) *AuditUsageStore {
+	return &AuditUsageStore{service: service}
+}
+
+// RecordUsage logs usage as an ai_usage event.
+func (s *AuditUsageStore) RecordUsage(ctx context.Context, usage advisor.Usage) error {
+	return s.service.LogAIUsage(ctx, usage.Command, usage.Provider, usage.Model, usage.Tokens, usage.CostUSD)
+}
+
+// UsageSince returns the usage logged at or after since, oldest first.
+func (s *AuditUsageStore) UsageSince(ctx context.Context, since time.Time) ([]advisor.Usage, error) {
+	events, err := s.service.Query(ctx, audit.NewQuery().
+		WithEventTypes(audit.EventAIUsage).
+		Since(since).
+		Build())
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make([]advisor.Usage, 0, len(events))
+	for _, event := range events {
+		usage = append(usage, advisor.Usage{
+			Time:     event.Timestamp,
+			Command:  event.Source,
+			Provider: detailString(event.Details, "provider"),
+			Model:    detailString(event.Details, "model"),
+			Tokens:   int(detailNumber(event.Details, "tokens")),
+			CostUSD:  detailNumber(event.Details, "cost_usd"),
+		})
+	}
+	sort.SliceStable(usage, func(i, j int) bool { return usage[i].Time.Before(usage[j].Time) })
+	return usage, nil
+}
+
+func detailString(details map[string]interface{}, key string) string {
+	s, _ := details[key].(string)
+	return s
+}
+
+// detailNumber reads a number from event details, which hold the value as
+// logged or, once read back from the log, as a float64.
+func detailNumber(details map[string]interface{}, key string) float64 {
+	switch v := details[key].(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	default:
+		return 0
+	}
+}
+
+// AIUsageSummary is the AI usage of a period, in total and per command.
+type AIUsageSummary struct {
+	Since     time.Time               `json:"since"`
+	Calls     int                     `json:"calls"`
+	Tokens    int                     `json:"tokens"`
+	CostUSD   float64                 `json:"cost_usd"`
+	BudgetUSD float64                 `json:"budget_usd,omitempty"`
+	Commands  []AIUsageCommandSummary `json:"commands"`
+}
+
+// AIUsageCommandSummary is the AI usage of one command.
+type AIUsageCommandSummary struct {
+	Command string  `json:"command"`
+	Calls   int     `json:"calls"`
+	Tokens  int     `json:"tokens"`
+	CostUSD float64 `json:"cost_usd"`
+}
+
+// SummarizeAIUsage totals usage per command, most expensive first.
+func SummarizeAIUsage(usage []advisor.Usage, since time.Time, budget float64) AIUsageSummary {
+	summary := AIUsageSummary{Since: since, BudgetUSD: budget, Commands: []AIUsageCommandSummary{}}
+	index := make(map[string]int)
+	for _, u := range usage {
+		summary.Calls++
+		summary.Tokens += u.Tokens
+		summary.CostUSD += u.CostUSD
+
+		i, ok := index[u.Command]
+		if !ok {
+			i = len(summary.Commands)
+			index[u.Command] = i
+			summary.Commands = append(summary.Commands, AIUsageCommandSummary{Command: u.Command})
+		}
+		summary.Commands[i].Calls++
+		summary.Commands[i].Tokens += u.Tokens
+		summary.Commands[i].CostUSD += u.CostUSD
+	}
+	sort.SliceStable(summary.Commands, func(i, j int) bool {
+		return summary.Commands[i].CostUSD > summary.Commands[j].CostUSD
+	})
+	return summary
+}
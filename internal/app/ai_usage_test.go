@@ -0,0 +1,65 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/advisor"
+	"github.com/felixgeelhaar/preflight/internal/domain/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditUsageStore(t *testing.T) {
+	t.Parallel()
+
+	cfg := audit.DefaultFileLoggerConfig()
+	cfg.Dir = t.TempDir()
+	logger, err := audit.NewFileLogger(cfg)
+	require.NoError(t, err)
+	service := audit.NewService(logger)
+	t.Cleanup(func() { _ = service.Close() })
+
+	store := NewAuditUsageStore(service)
+	ctx := context.Background()
+	start := time.Now().Add(-time.Minute)
+	require.NoError(t, store.RecordUsage(ctx, advisor.Usage{Command: "analyze", Provider: "anthropic", Model: "claude-sonnet-4-6", Tokens: 1200, CostUSD: 0.0072}))
+	require.NoError(t, store.RecordUsage(ctx, advisor.Usage{Command: "ask", Provider: "ollama", Model: "llama3.2", Tokens: 300}))
+
+	usage, err := store.UsageSince(ctx, start)
+	require.NoError(t, err)
+	require.Len(t, usage, 2)
+	assert.Equal(t, "analyze", usage[0].Command)
+	assert.Equal(t, "anthropic", usage[0].Provider)
+	assert.Equal(t, "claude-sonnet-4-6", usage[0].Model)
+	assert.Equal(t, 1200, usage[0].Tokens)
+	assert.InDelta(t, 0.0072, usage[0].CostUSD, 1e-9)
+	assert.Equal(t, "ask", usage[1].Command)
+
+	usage, err = store.UsageSince(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Empty(t, usage)
+}
+
+func TestSummarizeAIUsage(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	summary := SummarizeAIUsage([]advisor.Usage{
+		{Command: "ask", Tokens: 100, CostUSD: 0.01},
+		{Command: "analyze", Tokens: 1000, CostUSD: 0.05},
+		{Command: "ask", Tokens: 200, CostUSD: 0.02},
+	}, since, 5)
+
+	assert.Equal(t, since, summary.Since)
+	assert.Equal(t, 3, summary.Calls)
+	assert.Equal(t, 1300, summary.Tokens)
+	assert.InDelta(t, 0.08, summary.CostUSD, 1e-9)
+	assert.InDelta(t, 5.0, summary.BudgetUSD, 1e-9)
+	require.Len(t, summary.Commands, 2)
+	assert.Equal(t, "analyze", summary.Commands[0].Command)
+	assert.Equal(t, "ask", summary.Commands[1].Command)
+	assert.Equal(t, 2, summary.Commands[1].Calls)
+	assert.Equal(t, 300, summary.Commands[1].Tokens)
+}
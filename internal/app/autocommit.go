@@ -0,0 +1,144 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+)
+
+// maxAutocommitChanges caps the change lines in an autocommit message body.
+const maxAutocommitChanges = 50
+
+// ConfigAutocommit commits the config changes of a command when
+// sync.autocommit is enabled. It is started before the command writes, so
+// that files holding uncommitted edits of the user's own are left out.
+type ConfigAutocommit struct {
+	dir   string
+	cfg   config.SyncAutocommit
+	files []string
+	// now returns the time for tag names; tests replace it.
+	now func() time.Time
+}
+
+// ConfigCommitResult is a commit made by autocommit.
+type ConfigCommitResult struct {
+	Commit string
+	Tag    string
+	Files  []string
+}
+
+// StartAutocommit prepares to commit files, paths inside the directory of
+// configPath, after a command changes them. It returns nil when autocommit
+// is disabled or the config is not in a git repository.
+func StartAutocommit(ctx context.Context, configPath string, files ...string) (*ConfigAutocommit, error) {
+	cfg, err := LoadSyncConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	if !cfg.Autocommit.Enabled {
+		return nil, nil
+	}
+	dir := filepath.Dir(configPath)
+	if _, err := runGit(ctx, dir, "rev-parse", "--is-inside-work-tree"); err != nil {
+		return nil, nil
+	}
+
+	a := &ConfigAutocommit{dir: dir, cfg: cfg.Autocommit, now: time.Now}
+	for _, file := range files {
+		if !slices.Contains(a.files, file) && gitFilesClean(ctx, dir, []string{file}) {
+			a.files = append(a.files, file)
+		}
+	}
+	return a, nil
+}
+
+// Commit commits the files that changed since StartAutocommit with a
+// message naming the command, target and machine, one line per change,
+// and tags the commit when sync.autocommit.tag is set. It returns nil when
+// no file changed or a is nil.
+func (a *ConfigAutocommit) Commit(ctx context.Context, command, target string, changes []string) (*ConfigCommitResult, error) {
+	if a == nil {
+		return nil, nil
+	}
+	var changed []string
+	for _, file := range a.files {
+		out, err := runGit(ctx, a.dir, "status", "--porcelain", "--", file)
+		if err == nil && len(strings.TrimSpace(string(out))) > 0 {
+			changed = append(changed, file)
+		}
+	}
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	machine, _ := os.Hostname()
+	if machine == "" {
+		machine = "unknown"
+	}
+	message := autocommitMessage(command, target, machine, changes)
+	if err := gitCombined(ctx, a.dir, append([]string{"add", "--"}, changed...)...); err != nil {
+		return nil, fmt.Errorf("failed to stage config changes: %w", err)
+	}
+	if err := gitCombined(ctx, a.dir, append([]string{"commit", "-m", message, "--"}, changed...)...); err != nil {
+		return nil, fmt.Errorf("failed to commit config changes: %w", err)
+	}
+	head, err := runGit(ctx, a.dir, "rev-parse", "--short", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit: %w", err)
+	}
+	result := &ConfigCommitResult{Commit: strings.TrimSpace(string(head)), Files: changed}
+
+	if tag := a.cfg.TagName(target, machine, a.now()); tag != "" {
+		subject, _, _ := strings.Cut(message, "\n")
+		if err := gitCombined(ctx, a.dir, "tag", "-a", tag, "-m", subject); err != nil {
+			return result, fmt.Errorf("committed %s but failed to tag it: %w", result.Commit, err)
+		}
+		result.Tag = tag
+	}
+	return result, nil
+}
+
+// autocommitMessage returns the message of an autocommit: a subject, the
+// changes and trailers that tools can read with git interpret-trailers.
+func autocommitMessage(command, target, machine string, changes []string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "preflight %s on %s", command, machine)
+	if target != "" {
+		fmt.Fprintf(&sb, " (%s)", target)
+	}
+	sb.WriteString("\n\n")
+	for i, change := range changes {
+		if i == maxAutocommitChanges {
+			fmt.Fprintf(&sb, "- ... and %d more\n", len(changes)-i)
+			break
+		}
+		fmt.Fprintf(&sb, "- %s\n", change)
+	}
+	if len(changes) > 0 {
+		sb.WriteString("\n")
+	}
+	fmt.Fprintf(&sb, "Preflight-Command: %s\n", command)
+	if target != "" {
+		fmt.Fprintf(&sb, "Preflight-Target: %s\n", target)
+	}
+	fmt.Fprintf(&sb, "Preflight-Machine: %s\n", machine)
+	return sb.String()
+}
+
+// gitCombined runs git in dir and returns an error with its output when it
+// fails.
+func gitCombined(ctx context.Context, dir string, args ...string) error {
+	// #nosec G204 -- args are fixed git subcommands and config file paths.
+	out, err := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package app
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gitLog(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	require.NoError(t, err, string(out))
+	return strings.TrimSpace(string(out))
+}
+
+func TestConfigAutocommit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	dir := t.TempDir()
+	require.NoError(t, exec.Command("git", "init", "-q", dir).Run())
+	commitConfig(t, dir, map[string]string{
+		"preflight.yaml":   "targets:\n  work: [base]\nsync:\n  autocommit:\n    enabled: true\n    tag: \"applied/{target}/{date}\"\n",
+		"layers/base.yaml": "name: base\n",
+		"layers/mine.yaml": "name: mine\n",
+	})
+	configPath := filepath.Join(dir, "preflight.yaml")
+	lockPath := filepath.Join(dir, "preflight.lock")
+	base := filepath.Join(dir, "layers", "base.yaml")
+	mine := filepath.Join(dir, "layers", "mine.yaml")
+
+	// An edit of the user's own is never swept into the commit.
+	require.NoError(t, os.WriteFile(mine, []byte("name: mine\n# wip\n"), 0o644))
+
+	autocommit, err := StartAutocommit(context.Background(), configPath, lockPath, base, mine)
+	require.NoError(t, err)
+	require.NotNil(t, autocommit)
+	autocommit.now = func() time.Time { return time.Date(2026, 3, 1, 8, 30, 0, 0, time.UTC) }
+
+	require.NoError(t, os.WriteFile(lockPath, []byte("version: 1\n"), 0o644))
+	require.NoError(t, os.WriteFile(base, []byte("name: base\npackages:\n  brew:\n    formulae: [git]\n"), 0o644))
+	require.NoError(t, os.WriteFile(mine, []byte("name: mine\n# wip, more\n"), 0o644))
+
+	result, err := autocommit.Commit(context.Background(), "apply", "work", []string{"brew:formula:git"})
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.Equal(t, []string{lockPath, base}, result.Files)
+	assert.Equal(t, "applied/work/20260301T083000Z", result.Tag)
+
+	assert.Equal(t, "layers/base.yaml\npreflight.lock", gitLog(t, dir, "show", "--name-only", "--format=", "HEAD"))
+	message := gitLog(t, dir, "log", "-1", "--format=%B")
+	assert.Contains(t, message, "- brew:formula:git\n\nPreflight-Command: apply\nPreflight-Target: work\nPreflight-Machine: ")
+	assert.Equal(t, "applied/work/20260301T083000Z", gitLog(t, dir, "tag", "--points-at", "HEAD"))
+	assert.Contains(t, gitLog(t, dir, "status", "--porcelain"), "layers/mine.yaml")
+
+	// Nothing left to commit.
+	result, err = autocommit.Commit(context.Background(), "apply", "work", nil)
+	require.NoError(t, err)
+	assert.Nil(t, result)
+}
+
+func TestStartAutocommit_Disabled(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "preflight.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("targets:\n  work: [base]\n"), 0o644))
+
+	autocommit, err := StartAutocommit(context.Background(), configPath, filepath.Join(dir, "preflight.lock"))
+	require.NoError(t, err)
+	assert.Nil(t, autocommit)
+
+	result, err := autocommit.Commit(context.Background(), "apply", "work", nil)
+	require.NoError(t, err)
+	assert.Nil(t, result, "a nil autocommit commits nothing")
+}
+
+func TestAutocommitMessage(t *testing.T) {
+	t.Parallel()
+
+	changes := make([]string, maxAutocommitChanges+2)
+	for i := range changes {
+		changes[i] = "step"
+	}
+	message := autocommitMessage("doctor --update-config", "", "mbp", changes)
+	assert.True(t, strings.HasPrefix(message, "preflight doctor --update-config on mbp\n\n- step\n"))
+	assert.Contains(t, message, "- ... and 2 more\n\nPreflight-Command: doctor --update-config\nPreflight-Machine: mbp\n")
+	assert.NotContains(t, message, "Preflight-Target")
+}
@@ -0,0 +1,87 @@
+package app
+
+import (
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/provider/brew"
+)
+
+// checkBrewPrefixes reports PATH shadowing on a Mac with both an Apple
+// Silicon and an Intel Homebrew: the Intel prefix coming first in PATH, and
+// formulae installed in both prefixes, of which only one can be found.
+// Formulae the layers constrain with arch: are expected in their prefix.
+func checkBrewPrefixes(configPath, targetName string, prefixes []string, pathEnv string, run func(string, ...string) (string, error), report *DoctorReport) {
+	if len(prefixes) < 2 {
+		return
+	}
+
+	armBin := filepath.Join(brew.PrefixARM, "bin")
+	intelBin := filepath.Join(brew.PrefixIntel, "bin")
+	first := ""
+	for _, dir := range filepath.SplitList(pathEnv) {
+		dir = filepath.Clean(dir)
+		if dir == armBin || dir == intelBin {
+			first = dir
+			break
+		}
+	}
+	if first == intelBin {
+		report.Issues = append(report.Issues, DoctorIssue{
+			Provider:   "brew",
+			StepID:     "brew:prefix",
+			Severity:   SeverityWarning,
+			Message:    fmt.Sprintf("%s comes before %s in PATH, so Intel Homebrew and its formulae shadow the Apple Silicon ones", intelBin, armBin),
+			Expected:   armBin + " first",
+			Actual:     intelBin + " first",
+			FixCommand: fmt.Sprintf(`eval "$(%s shellenv)"`, brew.BrewPath(brew.PrefixARM)),
+		})
+	}
+	if first == "" {
+		first = armBin
+	}
+
+	listed := make(map[string]map[string]bool, len(prefixes))
+	for _, prefix := range prefixes {
+		out, err := run(brew.BrewPath(prefix), "list", "--formula", "-1")
+		if err != nil {
+			return
+		}
+		listed[prefix] = make(map[string]bool)
+		for _, name := range strings.Fields(out) {
+			listed[prefix][name] = true
+		}
+	}
+
+	var arch map[string]string
+	if target, err := config.NewTargetName(targetName); err == nil {
+		if merged, err := config.NewLoader().Load(configPath, target); err == nil {
+			arch = merged.Packages.Brew.Arch
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(listed[brew.PrefixARM])) {
+		if !listed[brew.PrefixIntel][name] {
+			continue
+		}
+		// Keep the copy the layers ask for, or else the native one.
+		remove := brew.ArchIntel
+		if arch[name] == brew.ArchIntel {
+			remove = brew.ArchARM
+		}
+		command, args := brew.Command(remove, "uninstall", "--formula", name)
+		report.Issues = append(report.Issues, DoctorIssue{
+			Provider:   "brew",
+			StepID:     "brew:formula:" + name,
+			Severity:   SeverityWarning,
+			Message:    fmt.Sprintf("%s is installed under both %s and %s; only the one in %s is found on PATH", name, brew.PrefixARM, brew.PrefixIntel, first),
+			Expected:   "installed under one prefix",
+			Actual:     "installed under both prefixes",
+			FixCommand: strings.Join(append([]string{command}, args...), " "),
+		})
+	}
+}
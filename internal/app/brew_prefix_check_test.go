@@ -0,0 +1,70 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckBrewPrefixes(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "preflight.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("targets:\n  default: [base]\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "layers"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "layers", "base.yaml"),
+		[]byte("name: base\npackages:\n  brew:\n    formulae: [legacy-tool]\n    arch:\n      legacy-tool: x86_64\n"), 0o644))
+
+	run := func(name string, _ ...string) (string, error) {
+		switch name {
+		case "/opt/homebrew/bin/brew":
+			return "git\njq\nlegacy-tool\n", nil
+		case "/usr/local/bin/brew":
+			return "jq\nlegacy-tool\nwget\n", nil
+		}
+		return "", errors.New("unexpected command " + name)
+	}
+	prefixes := []string{"/opt/homebrew", "/usr/local"}
+
+	report := &DoctorReport{}
+	checkBrewPrefixes(configPath, "default", prefixes, "/usr/local/bin:/opt/homebrew/bin:/usr/bin", run, report)
+	require.Len(t, report.Issues, 3)
+	assert.Equal(t, "brew:prefix", report.Issues[0].StepID)
+	assert.Contains(t, report.Issues[0].FixCommand, "/opt/homebrew/bin/brew shellenv")
+
+	jq := report.Issues[1]
+	assert.Equal(t, "brew:formula:jq", jq.StepID)
+	assert.True(t, strings.HasSuffix(jq.Message, "only the one in /usr/local/bin is found on PATH"), jq.Message)
+	assert.Equal(t, "arch -x86_64 /usr/local/bin/brew uninstall --formula jq", jq.FixCommand)
+	assert.Equal(t, "arch -arm64 /opt/homebrew/bin/brew uninstall --formula legacy-tool", report.Issues[2].FixCommand,
+		"the copy outside the prefix the layers ask for is removed")
+
+	report = &DoctorReport{}
+	checkBrewPrefixes(configPath, "default", prefixes, "/opt/homebrew/bin:/usr/local/bin", run, report)
+	assert.Len(t, report.Issues, 2, "no PATH order issue")
+
+	report = &DoctorReport{}
+	checkBrewPrefixes(configPath, "default", prefixes[:1], "/usr/local/bin", run, report)
+	assert.Empty(t, report.Issues, "a single prefix cannot shadow")
+}
+
+func TestDualPrefixFormulae(t *testing.T) {
+	t.Parallel()
+
+	items := dualPrefixFormulae(map[string][]string{
+		"/opt/homebrew": {"git", "jq"},
+		"/usr/local":    {"jq", "legacy-tool"},
+	}, time.Now())
+	require.Len(t, items, 3)
+	assert.Equal(t, "legacy-tool", items[2].Name)
+	assert.Equal(t, "x86_64", items[2].Arch)
+	assert.Empty(t, items[1].Arch, "formulae in both prefixes stay native")
+	assert.Equal(t, map[string]string{"legacy-tool": "x86_64"}, brewArch(items))
+}
@@ -0,0 +1,77 @@
+package app
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/provider/shell"
+)
+
+// Captured shell item names are prefixed by kind so they stay unique
+// across shells, e.g. "plugin:zsh:git" or "alias:ll".
+const (
+	shellFrameworkItem    = "framework:"
+	shellThemeItem        = "theme:"
+	shellPluginItem       = "plugin:"
+	shellCustomPluginItem = "custom-plugin:"
+	shellAliasItem        = "alias:"
+	shellFunctionItem     = "function:"
+	shellStarshipItem     = "starship.toml"
+)
+
+// shellDiscoveryItems converts a shell discovery into captured items, one
+// per framework, theme, plugin, alias and function.
+func shellDiscoveryItems(d *shell.Discovery, capturedAt time.Time) []CapturedItem {
+	var items []CapturedItem
+	add := func(name string, value interface{}, source string) {
+		items = append(items, CapturedItem{
+			Provider:   "shell",
+			Name:       name,
+			Value:      value,
+			Source:     source,
+			CapturedAt: capturedAt,
+		})
+	}
+
+	for _, entry := range d.Shells {
+		source := entry.ConfigPath()
+		if entry.Framework != "" {
+			add(shellFrameworkItem+entry.Name, entry.Framework, source)
+		}
+		if entry.Theme != "" {
+			add(shellThemeItem+entry.Name, entry.Theme, source)
+		}
+		for _, plugin := range entry.Plugins {
+			add(shellPluginItem+entry.Name+":"+plugin, plugin, source)
+		}
+		for _, plugin := range entry.CustomPlugins {
+			add(shellCustomPluginItem+entry.Name+":"+plugin.Name, plugin, source)
+		}
+	}
+	for _, name := range sortedKeys(d.Aliases) {
+		add(shellAliasItem+name, d.Aliases[name], "shell aliases")
+	}
+	for _, name := range sortedKeys(d.Functions) {
+		add(shellFunctionItem+name, d.Functions[name], "shell functions")
+	}
+	if d.StarshipConfig != "" {
+		add(shellStarshipItem, d.StarshipConfig, d.StarshipConfig)
+	}
+	return items
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// shellItemTarget splits a "kind:shell[:name]" item name after its prefix.
+func shellItemTarget(name, prefix string) (shellName, rest string) {
+	shellName, rest, _ = strings.Cut(strings.TrimPrefix(name, prefix), ":")
+	return shellName, rest
+}
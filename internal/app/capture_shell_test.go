@@ -0,0 +1,59 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/provider/shell"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShellDiscoveryItems_GenerateShell(t *testing.T) {
+	t.Parallel()
+
+	discovery := &shell.Discovery{
+		Shells: []shell.Entry{
+			{
+				Name:      "zsh",
+				Framework: "oh-my-zsh",
+				Theme:     "agnoster",
+				Plugins:   []string{"git", "docker"},
+				CustomPlugins: []shell.CustomPlugin{
+					{Name: "zsh-autosuggestions", Repo: "https://github.com/zsh-users/zsh-autosuggestions", Rev: "abc123"},
+				},
+			},
+			{Name: "fish", Framework: "fisher", Plugins: []string{"PatrickF1/fzf.fish@v9.0"}},
+		},
+		Aliases:        map[string]string{"k": "kubectl"},
+		Functions:      map[string]string{"mkcd": "mkdir -p \"$1\""},
+		StarshipConfig: "/home/me/.config/starship.toml",
+	}
+
+	items := append([]CapturedItem{{Provider: "shell", Name: ".zshrc"}}, shellDiscoveryItems(discovery, time.Now())...)
+	names := make([]string, 0, len(items))
+	for _, item := range items {
+		names = append(names, item.Name)
+	}
+	assert.Contains(t, names, "plugin:zsh:git")
+	assert.Contains(t, names, "custom-plugin:zsh:zsh-autosuggestions")
+	assert.Contains(t, names, "alias:k")
+
+	generated := NewCaptureConfigGenerator(t.TempDir()).generateShellFromCapture(items)
+	require.NotNil(t, generated)
+	assert.Equal(t, "zsh", generated.Default)
+	require.Len(t, generated.Shells, 2)
+	assert.Equal(t, captureShellEntryYAML{
+		Name:      "zsh",
+		Framework: "oh-my-zsh",
+		Theme:     "agnoster",
+		Plugins:   []string{"git", "docker"},
+		CustomPlugins: []captureShellCustomPluginYAML{
+			{Name: "zsh-autosuggestions", Repo: "https://github.com/zsh-users/zsh-autosuggestions", Rev: "abc123"},
+		},
+	}, generated.Shells[0])
+	assert.Equal(t, []string{"PatrickF1/fzf.fish@v9.0"}, generated.Shells[1].Plugins)
+	assert.Equal(t, map[string]string{"k": "kubectl"}, generated.Aliases)
+	assert.Equal(t, map[string]string{"mkcd": "mkdir -p \"$1\""}, generated.Functions)
+	assert.True(t, generated.Starship.Enabled)
+}
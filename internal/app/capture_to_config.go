@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	shellprovider "github.com/felixgeelhaar/preflight/internal/provider/shell"
 	"github.com/felixgeelhaar/preflight/internal/validation"
 	"gopkg.in/yaml.v3"
 )
@@ -19,6 +20,7 @@ type CaptureConfigGenerator struct {
 	smartSplit     bool
 	splitStrategy  SplitStrategy
 	aiCategorizer  AICategorizer
+	describer      PackageDescriber
 	dotfilesResult *DotfilesCaptureResult
 }
 
@@ -50,6 +52,13 @@ func (g *CaptureConfigGenerator) WithAICategorizer(ai AICategorizer) *CaptureCon
 	return g
 }
 
+// WithDescriber sets how package descriptions are looked up for the AI
+// categorizer. Descriptions help it place packages with unfamiliar names.
+func (g *CaptureConfigGenerator) WithDescriber(describe PackageDescriber) *CaptureConfigGenerator {
+	g.describer = describe
+	return g
+}
+
 // WithDotfiles sets the dotfiles capture result for config_source population.
 func (g *CaptureConfigGenerator) WithDotfiles(result *DotfilesCaptureResult) *CaptureConfigGenerator {
 	g.dotfilesResult = result
@@ -97,6 +106,33 @@ func (g *CaptureConfigGenerator) GenerateFromCaptureWithContext(ctx context.Cont
 	return nil
 }
 
+// describeItems adds descriptions to the uncategorized items, which are the
+// ones sent to the AI categorizer.
+func describeItems(ctx context.Context, categorized *CategorizedItems, describe PackageDescriber) {
+	descriptions := describe(ctx, categorized.Uncategorized)
+	for i, item := range categorized.Uncategorized {
+		if description, ok := descriptions[item.Name]; ok && item.Description == "" {
+			categorized.Uncategorized[i].Description = description
+		}
+	}
+}
+
+// providerLayers maps the providers other than brew to the layer smart
+// split puts their items in.
+var providerLayers = map[string]string{
+	"git":     "git",
+	"shell":   "shell",
+	"vscode":  "editor",
+	"runtime": "runtime",
+	"npm":     "dev-node",
+	"go":      "dev-go",
+	"pip":     "dev-python",
+	"gem":     "dev-ruby",
+	"cargo":   "dev-rust",
+	"mas":     "apps",
+	"macos":   "macos",
+}
+
 // generateSmartSplitLayers creates multiple layer files organized by category.
 func (g *CaptureConfigGenerator) generateSmartSplitLayers(ctx context.Context, findings *CaptureFindings, target string) error {
 	// Select categorizer based on strategy
@@ -120,9 +156,12 @@ func (g *CaptureConfigGenerator) generateSmartSplitLayers(ctx context.Context, f
 
 	// Use AI to categorize remaining items if available
 	if g.aiCategorizer != nil && len(categorized.Uncategorized) > 0 {
+		if g.describer != nil {
+			describeItems(ctx, categorized, g.describer)
+		}
 		if err := CategorizeWithAI(ctx, categorized, g.aiCategorizer, g.splitStrategy); err != nil {
 			// Log warning but continue without AI enhancement
-			fmt.Printf("Warning: AI categorization failed: %v\n", err)
+			fmt.Printf("Warning: AI categorization failed, keeping heuristic layers: %v\n", err)
 		}
 	}
 
@@ -145,18 +184,6 @@ func (g *CaptureConfigGenerator) generateSmartSplitLayers(ctx context.Context, f
 	}
 
 	// Second pass: merge provider configs into appropriate layers
-	providerToLayer := map[string]string{
-		"git":     "git",
-		"shell":   "shell",
-		"vscode":  "editor",
-		"runtime": "runtime",
-		"npm":     "dev-node",
-		"go":      "dev-go",
-		"pip":     "dev-python",
-		"gem":     "dev-ruby",
-		"cargo":   "dev-rust",
-	}
-
 	for provider, items := range byProvider {
 		if provider == "brew" || provider == "brew-cask" || len(items) == 0 {
 			continue
@@ -164,7 +191,7 @@ func (g *CaptureConfigGenerator) generateSmartSplitLayers(ctx context.Context, f
 
 		// Determine target layer name
 		layerName := provider
-		if mappedLayer, ok := providerToLayer[provider]; ok {
+		if mappedLayer, ok := providerLayers[provider]; ok {
 			layerName = mappedLayer
 		}
 
@@ -226,6 +253,7 @@ func (g *CaptureConfigGenerator) buildLayerFromBrewItems(name string, items []Ca
 		if len(casks) > 0 {
 			brew.Casks = casks
 		}
+		brew.Arch = brewArch(items)
 		layer.Packages = &capturePackagesYAML{
 			Brew: brew,
 		}
@@ -262,8 +290,14 @@ func (g *CaptureConfigGenerator) addProviderConfigToLayer(layer *captureLayerYAM
 		g.addGemPackagesToLayer(layer, items)
 	case "cargo":
 		g.addCargoPackagesToLayer(layer, items)
+	case "mas":
+		g.addMasAppsToLayer(layer, items)
+	case "macos":
+		layer.MacOS = g.generateDockFromCapture(items)
 	case "terminal":
 		g.addTerminalConfigToLayer(layer, items)
+	case "tmux":
+		layer.Tmux = g.generateTmuxFromCapture(items)
 	}
 }
 
@@ -359,6 +393,7 @@ func (g *CaptureConfigGenerator) applyDotfilesToLayer(layer *captureLayerYAML) {
 		if layer.Shell.Starship == nil {
 			layer.Shell.Starship = &captureStarshipYAML{}
 		}
+		layer.Shell.Starship.Enabled = true
 		layer.Shell.Starship.ConfigSource = configSource
 	}
 
@@ -471,6 +506,7 @@ func (g *CaptureConfigGenerator) generateBrewProviderLayer(name string, formulae
 			f[i] = item.Name
 		}
 		brew.Formulae = f
+		brew.Arch = brewArch(formulae)
 	}
 	if len(casks) > 0 {
 		c := make([]string, len(casks))
@@ -536,8 +572,20 @@ func (g *CaptureConfigGenerator) generateProviderLayerIfSupported(name, provider
 		g.addGemPackagesToLayer(&layer, items)
 	case "cargo":
 		g.addCargoPackagesToLayer(&layer, items)
+	case "mas":
+		g.addMasAppsToLayer(&layer, items)
+	case "macos":
+		layer.MacOS = g.generateDockFromCapture(items)
+		if layer.MacOS == nil {
+			return false, nil
+		}
 	case "terminal":
 		g.addTerminalConfigToLayer(&layer, items)
+	case "tmux":
+		layer.Tmux = g.generateTmuxFromCapture(items)
+		if layer.Tmux == nil {
+			return false, nil
+		}
 	default:
 		// Provider not supported for layer generation
 		return false, nil
@@ -602,6 +650,7 @@ func (g *CaptureConfigGenerator) generateLayerFromCapture(findings *CaptureFindi
 				formulae = append(formulae, item.Name)
 			}
 			brew.Formulae = formulae
+			brew.Arch = brewArch(brewItems)
 		}
 
 		if len(caskItems) > 0 {
@@ -673,11 +722,26 @@ func (g *CaptureConfigGenerator) generateLayerFromCapture(findings *CaptureFindi
 		g.addGemPackagesToLayer(&layer, gemItems)
 	}
 
+	// Generate tmux section
+	if tmuxItems, ok := byProvider["tmux"]; ok && len(tmuxItems) > 0 {
+		layer.Tmux = g.generateTmuxFromCapture(tmuxItems)
+	}
+
 	// Generate cargo section
 	if cargoItems, ok := byProvider["cargo"]; ok && len(cargoItems) > 0 {
 		g.addCargoPackagesToLayer(&layer, cargoItems)
 	}
 
+	// Generate App Store section
+	if masItems, ok := byProvider["mas"]; ok && len(masItems) > 0 {
+		g.addMasAppsToLayer(&layer, masItems)
+	}
+
+	// Generate Dock layout
+	if macosItems, ok := byProvider["macos"]; ok && len(macosItems) > 0 {
+		layer.MacOS = g.generateDockFromCapture(macosItems)
+	}
+
 	// Generate terminal section
 	if terminalItems, ok := byProvider["terminal"]; ok && len(terminalItems) > 0 {
 		g.addTerminalConfigToLayer(&layer, terminalItems)
@@ -740,28 +804,62 @@ func (g *CaptureConfigGenerator) generateShellFromCapture(items []CapturedItem)
 		Shells: make([]captureShellEntryYAML, 0),
 	}
 
-	hasZsh := false
-	hasBash := false
-
-	for _, item := range items {
-		switch item.Name {
-		case ".zshrc":
-			hasZsh = true
-		case ".bashrc", ".bash_profile":
-			hasBash = true
+	found := make(map[string]bool)
+	entries := make(map[string]*captureShellEntryYAML)
+	entry := func(name string) *captureShellEntryYAML {
+		found[name] = true
+		if entries[name] == nil {
+			entries[name] = &captureShellEntryYAML{Name: name}
 		}
+		return entries[name]
 	}
 
-	if hasZsh {
-		shell.Default = "zsh"
-		shell.Shells = append(shell.Shells, captureShellEntryYAML{Name: "zsh"})
+	for _, item := range items {
+		value, _ := item.Value.(string)
+		switch {
+		case item.Name == ".zshrc":
+			entry("zsh")
+		case item.Name == ".bashrc", item.Name == ".bash_profile":
+			entry("bash")
+		case item.Name == "config.fish":
+			entry("fish")
+		case strings.HasPrefix(item.Name, shellFrameworkItem):
+			entry(strings.TrimPrefix(item.Name, shellFrameworkItem)).Framework = value
+		case strings.HasPrefix(item.Name, shellThemeItem):
+			entry(strings.TrimPrefix(item.Name, shellThemeItem)).Theme = value
+		case strings.HasPrefix(item.Name, shellPluginItem):
+			name, _ := shellItemTarget(item.Name, shellPluginItem)
+			e := entry(name)
+			e.Plugins = append(e.Plugins, value)
+		case strings.HasPrefix(item.Name, shellCustomPluginItem):
+			if plugin, ok := item.Value.(shellprovider.CustomPlugin); ok {
+				name, _ := shellItemTarget(item.Name, shellCustomPluginItem)
+				e := entry(name)
+				e.CustomPlugins = append(e.CustomPlugins, captureShellCustomPluginYAML(plugin))
+			}
+		case strings.HasPrefix(item.Name, shellAliasItem):
+			if shell.Aliases == nil {
+				shell.Aliases = make(map[string]string)
+			}
+			shell.Aliases[strings.TrimPrefix(item.Name, shellAliasItem)] = value
+		case strings.HasPrefix(item.Name, shellFunctionItem):
+			if shell.Functions == nil {
+				shell.Functions = make(map[string]string)
+			}
+			shell.Functions[strings.TrimPrefix(item.Name, shellFunctionItem)] = value
+		case item.Name == shellStarshipItem:
+			shell.Starship = &captureStarshipYAML{Enabled: true}
+		}
 	}
 
-	if hasBash {
+	for _, name := range []string{"zsh", "bash", "fish"} {
+		if !found[name] {
+			continue
+		}
 		if shell.Default == "" {
-			shell.Default = "bash"
+			shell.Default = name
 		}
-		shell.Shells = append(shell.Shells, captureShellEntryYAML{Name: "bash"})
+		shell.Shells = append(shell.Shells, *entries[name])
 	}
 
 	if len(shell.Shells) == 0 {
@@ -771,6 +869,28 @@ func (g *CaptureConfigGenerator) generateShellFromCapture(items []CapturedItem)
 	return shell
 }
 
+func (g *CaptureConfigGenerator) generateTmuxFromCapture(items []CapturedItem) *captureTmuxYAML {
+	tmux := &captureTmuxYAML{}
+	for _, item := range items {
+		value, ok := item.Value.(string)
+		if !ok {
+			continue
+		}
+		if key, found := strings.CutPrefix(item.Name, tmuxOptionItem); found {
+			if tmux.Settings == nil {
+				tmux.Settings = make(map[string]string)
+			}
+			tmux.Settings[key] = value
+			continue
+		}
+		tmux.Plugins = append(tmux.Plugins, value)
+	}
+	if len(tmux.Plugins) == 0 && len(tmux.Settings) == 0 {
+		return nil
+	}
+	return tmux
+}
+
 func (g *CaptureConfigGenerator) generateRuntimeFromCapture(items []CapturedItem) *captureRuntimeYAML {
 	runtime := &captureRuntimeYAML{
 		Tools: make([]captureRuntimeToolYAML, 0, len(items)),
@@ -952,6 +1072,42 @@ func (g *CaptureConfigGenerator) addCargoPackagesToLayer(layer *captureLayerYAML
 	}
 }
 
+// addMasAppsToLayer adds App Store apps to a layer's packages section.
+func (g *CaptureConfigGenerator) addMasAppsToLayer(layer *captureLayerYAML, items []CapturedItem) {
+	apps := make([]captureMasAppYAML, 0, len(items))
+	for _, item := range items {
+		if id, ok := item.Value.(int64); ok && id > 0 {
+			apps = append(apps, captureMasAppYAML{ID: id, Name: item.Name})
+		}
+	}
+
+	if len(apps) == 0 {
+		return
+	}
+
+	if layer.Packages == nil {
+		layer.Packages = &capturePackagesYAML{}
+	}
+	layer.Packages.Mas = &captureMasYAML{
+		Apps: apps,
+	}
+}
+
+// generateDockFromCapture creates a macos section with the captured Dock
+// apps in their current order.
+func (g *CaptureConfigGenerator) generateDockFromCapture(items []CapturedItem) *captureMacOSYAML {
+	apps := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.Value.(string); ok && s != "" {
+			apps = append(apps, s)
+		}
+	}
+	if len(apps) == 0 {
+		return nil
+	}
+	return &captureMacOSYAML{Dock: &captureDockYAML{Apps: apps}}
+}
+
 // addTerminalConfigToLayer adds terminal emulator configs to a layer.
 func (g *CaptureConfigGenerator) addTerminalConfigToLayer(layer *captureLayerYAML, items []CapturedItem) {
 	if len(items) == 0 {
@@ -1467,6 +1623,7 @@ type captureLayerYAML struct {
 	Nvim     *captureNvimYAML     `yaml:"nvim,omitempty"`
 	SSH      *captureSSHYAML      `yaml:"ssh,omitempty"`
 	Tmux     *captureTmuxYAML     `yaml:"tmux,omitempty"`
+	MacOS    *captureMacOSYAML    `yaml:"macos,omitempty"`
 	Terminal *captureTerminalYAML `yaml:"terminal,omitempty"`
 }
 
@@ -1477,6 +1634,7 @@ type capturePackagesYAML struct {
 	Pip   *capturePipYAML   `yaml:"pip,omitempty"`
 	Gem   *captureGemYAML   `yaml:"gem,omitempty"`
 	Cargo *captureCargoYAML `yaml:"cargo,omitempty"`
+	Mas   *captureMasYAML   `yaml:"mas,omitempty"`
 }
 
 type captureNpmYAML struct {
@@ -1499,10 +1657,44 @@ type captureCargoYAML struct {
 	Crates []string `yaml:"crates,omitempty"`
 }
 
+type captureMacOSYAML struct {
+	Dock *captureDockYAML `yaml:"dock,omitempty"`
+}
+
+type captureDockYAML struct {
+	Apps []string `yaml:"apps,omitempty"`
+}
+
+type captureMasYAML struct {
+	Apps []captureMasAppYAML `yaml:"apps,omitempty"`
+}
+
+type captureMasAppYAML struct {
+	ID   int64  `yaml:"id"`
+	Name string `yaml:"name,omitempty"`
+}
+
 type captureBrewYAML struct {
-	Taps     []string `yaml:"taps,omitempty"`
-	Formulae []string `yaml:"formulae,omitempty"`
-	Casks    []string `yaml:"casks,omitempty"`
+	Taps     []string          `yaml:"taps,omitempty"`
+	Formulae []string          `yaml:"formulae,omitempty"`
+	Casks    []string          `yaml:"casks,omitempty"`
+	Arch     map[string]string `yaml:"arch,omitempty"`
+}
+
+// brewArch returns the architecture constraints of captured formulae that
+// live in a non-default Homebrew prefix.
+func brewArch(items []CapturedItem) map[string]string {
+	var arch map[string]string
+	for _, item := range items {
+		if item.Provider != "brew" || item.Arch == "" {
+			continue
+		}
+		if arch == nil {
+			arch = make(map[string]string)
+		}
+		arch[item.Name] = item.Arch
+	}
+	return arch
 }
 
 type captureGitYAML struct {
@@ -1530,13 +1722,24 @@ type captureShellYAML struct {
 	Shells       []captureShellEntryYAML       `yaml:"shells,omitempty"`
 	ConfigSource *captureShellConfigSourceYAML `yaml:"config_source,omitempty"` // Paths to shell config files
 	Starship     *captureStarshipYAML          `yaml:"starship,omitempty"`      // Starship prompt configuration
+	Aliases      map[string]string             `yaml:"aliases,omitempty"`
+	Functions    map[string]string             `yaml:"functions,omitempty"`
 }
 
 type captureShellEntryYAML struct {
-	Name      string   `yaml:"name"`
-	Framework string   `yaml:"framework,omitempty"`
-	Theme     string   `yaml:"theme,omitempty"`
-	Plugins   []string `yaml:"plugins,omitempty"`
+	Name          string                         `yaml:"name"`
+	Framework     string                         `yaml:"framework,omitempty"`
+	Theme         string                         `yaml:"theme,omitempty"`
+	Plugins       []string                       `yaml:"plugins,omitempty"`
+	CustomPlugins []captureShellCustomPluginYAML `yaml:"custom_plugins,omitempty"`
+}
+
+// captureShellCustomPluginYAML is a git-cloned plugin pinned to the
+// revision found at capture time.
+type captureShellCustomPluginYAML struct {
+	Name string `yaml:"name"`
+	Repo string `yaml:"repo"`
+	Rev  string `yaml:"rev,omitempty"`
 }
 
 // captureShellConfigSourceYAML represents paths to shell configuration files.
@@ -1549,12 +1752,15 @@ type captureShellConfigSourceYAML struct {
 
 // captureStarshipYAML represents Starship prompt configuration.
 type captureStarshipYAML struct {
+	Enabled      bool   `yaml:"enabled,omitempty"`
 	ConfigSource string `yaml:"config_source,omitempty"` // Path to starship.toml (e.g., "dotfiles/starship")
 }
 
 // captureTmuxYAML represents Tmux configuration.
 type captureTmuxYAML struct {
-	ConfigSource string `yaml:"config_source,omitempty"` // Path to tmux config (e.g., "dotfiles/tmux")
+	ConfigSource string            `yaml:"config_source,omitempty"` // Path to tmux config (e.g., "dotfiles/tmux")
+	Plugins      []string          `yaml:"plugins,omitempty"`
+	Settings     map[string]string `yaml:"settings,omitempty"`
 }
 
 // captureTerminalYAML represents terminal emulator configurations.
@@ -375,6 +375,30 @@ func TestCaptureConfigGenerator_addProviderConfigToLayer(t *testing.T) {
 				assert.Contains(t, layer.Packages.Cargo.Crates, "cargo-tool")
 			},
 		},
+		{
+			name:     "mas",
+			provider: "mas",
+			items: []CapturedItem{
+				{Name: "Xcode", Value: int64(497799835)},
+			},
+			verify: func(t *testing.T, layer *captureLayerYAML) {
+				require.NotNil(t, layer.Packages)
+				require.NotNil(t, layer.Packages.Mas)
+				assert.Equal(t, []captureMasAppYAML{{ID: 497799835, Name: "Xcode"}}, layer.Packages.Mas.Apps)
+			},
+		},
+		{
+			name:     "macos",
+			provider: "macos",
+			items: []CapturedItem{
+				{Name: "Safari", Value: "Safari"},
+				{Name: "/opt/Tool.app", Value: "/opt/Tool.app"},
+			},
+			verify: func(t *testing.T, layer *captureLayerYAML) {
+				require.NotNil(t, layer.MacOS)
+				assert.Equal(t, []string{"Safari", "/opt/Tool.app"}, layer.MacOS.Dock.Apps)
+			},
+		},
 		{
 			name:     "terminal",
 			provider: "terminal",
@@ -436,3 +460,63 @@ func mustReadLayer(t *testing.T, target, name string) captureLayerYAML {
 	require.NoError(t, yaml.Unmarshal(data, &layer))
 	return layer
 }
+
+// recordingAICategorizer records the request and places every item in layer.
+type recordingAICategorizer struct {
+	layer string
+	req   AICategorizationRequest
+}
+
+func (r *recordingAICategorizer) Categorize(_ context.Context, req AICategorizationRequest) (*AICategorizationResult, error) {
+	r.req = req
+	result := &AICategorizationResult{Categorizations: make(map[string]string)}
+	for _, item := range req.Items {
+		result.Categorizations[item.Name] = r.layer
+	}
+	return result, nil
+}
+
+func TestCaptureConfigGenerator_AICategorizesUnknownPackages(t *testing.T) {
+	t.Parallel()
+
+	findings := &CaptureFindings{Items: []CapturedItem{
+		{Provider: "brew", Name: "git"},
+		{Provider: "brew", Name: "frobnicate"},
+	}}
+	describe := func(context.Context, []CapturedItem) map[string]string {
+		return map[string]string{"frobnicate": "Benchmark frobnication pipelines"}
+	}
+
+	t.Run("uses the AI placement", func(t *testing.T) {
+		t.Parallel()
+
+		target := t.TempDir()
+		ai := &recordingAICategorizer{layer: "benchmarking"}
+		err := NewCaptureConfigGenerator(target).
+			WithSplitStrategy(SplitByCategory).
+			WithAICategorizer(ai).
+			WithDescriber(describe).
+			GenerateFromCapture(findings, "default")
+		require.NoError(t, err)
+
+		require.Len(t, ai.req.Items, 1)
+		assert.Equal(t, "frobnicate", ai.req.Items[0].Name)
+		assert.Equal(t, "Benchmark frobnication pipelines", ai.req.Items[0].Description)
+		assert.Contains(t, mustReadLayer(t, target, "benchmarking").Packages.Brew.Formulae, "frobnicate")
+		assert.NoFileExists(t, filepath.Join(target, "layers", "misc.yaml"))
+	})
+
+	t.Run("falls back to misc when AI fails", func(t *testing.T) {
+		t.Parallel()
+
+		target := t.TempDir()
+		err := NewCaptureConfigGenerator(target).
+			WithSplitStrategy(SplitByCategory).
+			WithAICategorizer(&MockAICategorizer{err: assert.AnError}).
+			WithDescriber(describe).
+			GenerateFromCapture(findings, "default")
+		require.NoError(t, err)
+
+		assert.Contains(t, mustReadLayer(t, target, "misc").Packages.Brew.Formulae, "frobnicate")
+	})
+}
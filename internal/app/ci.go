@@ -0,0 +1,369 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/domain/lock"
+	"gopkg.in/yaml.v3"
+)
+
+// CI check names, reported with each finding.
+const (
+	CICheckValidate   = "validate"
+	CICheckPolicy     = "policy"
+	CICheckDuplicates = "duplicates"
+	CICheckLockfile   = "lockfile"
+)
+
+// CI finding severities.
+const (
+	CISeverityError   = "error"
+	CISeverityWarning = "warning"
+)
+
+// CIOptions configures a CI check of a configuration repository.
+type CIOptions struct {
+	// Targets to check. All targets of the manifest are checked when empty.
+	Targets []string
+	// PolicyFile is an optional path to a policy YAML file (allow/deny rules)
+	PolicyFile string
+	// OrgPolicyFile is an optional path to an org policy YAML file
+	OrgPolicyFile string
+}
+
+// CIFinding is a problem found by a CI check. File, Line and Column locate
+// it when known; Line and Column are 1-based and zero when unknown.
+type CIFinding struct {
+	Check    string   `json:"check"`
+	Severity string   `json:"severity"`
+	Message  string   `json:"message"`
+	File     string   `json:"file,omitempty"`
+	Line     int      `json:"line,omitempty"`
+	Column   int      `json:"column,omitempty"`
+	Targets  []string `json:"targets,omitempty"`
+}
+
+// CIReport is the result of a CI check.
+type CIReport struct {
+	ConfigPath string      `json:"config_path"`
+	Targets    []string    `json:"targets"`
+	Findings   []CIFinding `json:"findings"`
+}
+
+// Count returns the number of findings with severity.
+func (r *CIReport) Count(severity string) int {
+	n := 0
+	for _, f := range r.Findings {
+		if f.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+// HasPolicyViolations reports whether a policy check failed.
+func (r *CIReport) HasPolicyViolations() bool {
+	return slices.ContainsFunc(r.Findings, func(f CIFinding) bool {
+		return f.Check == CICheckPolicy && f.Severity == CISeverityError
+	})
+}
+
+// schemaLocation matches validation messages prefixed with the file, line
+// and column of a schema issue.
+var schemaLocation = regexp.MustCompile(`^(.+\.ya?ml):(\d+):(\d+): (.*)$`)
+
+// CI checks a configuration repository the way a pull request check does:
+// every target is validated against its schemas and policies, packages
+// declared by several layers are reported, and the lockfile is compared
+// with the packages the configuration declares. Nothing on the machine is
+// inspected or changed.
+func (p *Preflight) CI(ctx context.Context, configPath string, opts CIOptions) (*CIReport, error) {
+	manifest, err := config.NewLoader().LoadManifest(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load manifest: %w", err)
+	}
+
+	targets := opts.Targets
+	if len(targets) == 0 {
+		for name := range manifest.Targets {
+			targets = append(targets, name)
+		}
+		sort.Strings(targets)
+	}
+	for _, target := range targets {
+		if _, ok := manifest.Targets[target]; !ok {
+			return nil, fmt.Errorf("target %q not found in %s", target, configPath)
+		}
+	}
+
+	report := &CIReport{ConfigPath: configPath, Targets: targets}
+	findings := newCIFindings()
+
+	// Versions are checked against the lockfile by ciLockfile, so the
+	// targets are validated in intent mode: a missing or stale lockfile
+	// must not hide the other problems.
+	mode := p.mode
+	modeSet := p.modeSet
+	p.WithMode(config.ModeIntent)
+
+	validateOpts := ValidateOptions{PolicyFile: opts.PolicyFile, OrgPolicyFile: opts.OrgPolicyFile}
+	for _, target := range targets {
+		result, err := p.ValidateWithOptions(ctx, configPath, target, validateOpts)
+		if err != nil {
+			findings.add(target, CIFinding{Check: CICheckValidate, Severity: CISeverityError, Message: err.Error(), File: configPath})
+			continue
+		}
+		duplicates := p.ciDuplicates(configPath, manifest, target)
+		for _, f := range duplicates {
+			findings.add(target, f)
+		}
+		for _, f := range ciValidationFindings(configPath, result, duplicates) {
+			findings.add(target, f)
+		}
+	}
+	p.mode, p.modeSet = mode, modeSet
+
+	lockFindings, err := p.ciLockfile(ctx, configPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range lockFindings {
+		findings.add("", f)
+	}
+
+	report.Findings = findings.list
+	return report, nil
+}
+
+// ciValidationFindings converts a validation result. The warnings about
+// duplicates are left out; they are reported with their location instead.
+func ciValidationFindings(configPath string, result *ValidationResult, duplicates []CIFinding) []CIFinding {
+	reported := make(map[string]bool, len(duplicates))
+	for _, d := range duplicates {
+		reported[d.Message] = true
+	}
+
+	var findings []CIFinding
+	add := func(check, severity string, messages []string) {
+		for i, msg := range messages {
+			if reported[msg] {
+				continue
+			}
+			// Indented messages continue the list the previous one starts.
+			if i > 0 && strings.HasPrefix(msg, " ") && len(findings) > 0 {
+				last := &findings[len(findings)-1]
+				last.Message += "\n" + strings.TrimSpace(msg)
+				continue
+			}
+			f := CIFinding{Check: check, Severity: severity, Message: msg, File: configPath}
+			if m := schemaLocation.FindStringSubmatch(msg); m != nil {
+				f.File, f.Message = m[1], m[4]
+				f.Line, _ = strconv.Atoi(m[2])
+				f.Column, _ = strconv.Atoi(m[3])
+			}
+			findings = append(findings, f)
+		}
+	}
+	add(CICheckValidate, CISeverityError, result.Errors)
+	add(CICheckPolicy, CISeverityError, result.PolicyViolations)
+	add(CICheckValidate, CISeverityWarning, result.Warnings)
+	return findings
+}
+
+// ciDuplicates reports the packages several layers of target declare, at
+// their declaration in the second layer, unless the manifest accepts
+// duplicates with a defaults.duplicates strategy.
+func (p *Preflight) ciDuplicates(configPath string, manifest *config.Manifest, target string) []CIFinding {
+	if strategy := manifest.Defaults.Duplicates; strategy != "" && strategy != config.DuplicatesWarn {
+		return nil
+	}
+	name, err := config.NewTargetName(target)
+	if err != nil {
+		return nil
+	}
+	layersDir := filepath.Join(filepath.Dir(configPath), "layers")
+	loaded, err := config.NewLoader().LoadTarget(manifest, name, layersDir)
+	if err != nil {
+		return nil
+	}
+
+	var findings []CIFinding
+	for _, d := range config.FindDuplicatePackages(loaded.Layers) {
+		f := CIFinding{
+			Check:    CICheckDuplicates,
+			Severity: CISeverityWarning,
+			Message:  duplicateWarning(d),
+			File:     configPath,
+		}
+		file := filepath.Join(layersDir, d.Layers[1]+".yaml")
+		if line, col := findListItem(file, d.Name); line > 0 {
+			f.File, f.Line, f.Column = file, line, col
+		}
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+// findListItem returns the position of the first sequence item equal to
+// value in the YAML file at path, or zero when it is not found.
+func findListItem(path, value string) (int, int) {
+	// #nosec G304 -- reading the user's own layer files.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, 0
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return 0, 0
+	}
+	var find func(*yaml.Node) *yaml.Node
+	find = func(n *yaml.Node) *yaml.Node {
+		for _, child := range n.Content {
+			if n.Kind == yaml.SequenceNode && child.Kind == yaml.ScalarNode && strings.EqualFold(child.Value, value) {
+				return child
+			}
+			if found := find(child); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+	if n := find(&doc); n != nil {
+		return n.Line, n.Column
+	}
+	return 0, 0
+}
+
+// ciLockfile compares the lockfile with the packages the configuration
+// declares for its lock target. Without a lockfile, only configurations in
+// locked or frozen mode are reported.
+func (p *Preflight) ciLockfile(ctx context.Context, configPath string) ([]CIFinding, error) {
+	mode, err := p.resolveMode(configPath)
+	if err != nil {
+		return nil, err
+	}
+	severity := CISeverityWarning
+	if mode != config.ModeIntent {
+		severity = CISeverityError
+	}
+
+	lockPath := strings.TrimSuffix(configPath, filepath.Ext(configPath)) + ".lock"
+	if p.lockRepo == nil {
+		return nil, fmt.Errorf("lockfile repository not configured")
+	}
+	lockfile, err := p.lockRepo.Load(ctx, lockPath)
+	if errors.Is(err, lock.ErrLockfileNotFound) {
+		if mode == config.ModeIntent {
+			return nil, nil
+		}
+		return []CIFinding{{
+			Check:    CICheckLockfile,
+			Severity: CISeverityError,
+			Message:  fmt.Sprintf("%s mode requires a lockfile; run 'preflight lock update' and commit %s", mode, filepath.Base(lockPath)),
+			File:     configPath,
+		}}, nil
+	}
+	if err != nil {
+		return []CIFinding{{Check: CICheckLockfile, Severity: CISeverityError, Message: err.Error(), File: lockPath}}, nil
+	}
+
+	target, err := selectLockTarget(configPath)
+	if err != nil {
+		return nil, err
+	}
+	// Configurations that do not load or compile are reported by validation.
+	cfg, err := p.loadConfig(configPath, target)
+	if err != nil {
+		return nil, nil
+	}
+	resolver := versionResolverAdapter{
+		resolver: lock.NewResolver(lock.NewLockfile(config.ModeIntent, lock.MachineInfoFromSystem())),
+	}
+	compileCtx := compiler.NewCompileContext(cfg).
+		WithResolver(resolver).
+		WithConfigRoot(filepath.Dir(configPath)).
+		WithTarget(target)
+	graph, err := p.compiler.CompileWithContext(compileCtx)
+	if err != nil {
+		return nil, nil
+	}
+
+	var findings []CIFinding
+	stale := func(msg string) {
+		findings = append(findings, CIFinding{Check: CICheckLockfile, Severity: severity, Message: msg, File: lockPath})
+	}
+	declared := make(map[string]struct{})
+	providers := make(map[string]struct{})
+	for _, step := range graph.Steps() {
+		lockable, ok := step.(compiler.LockableStep)
+		if !ok {
+			continue
+		}
+		info, ok := lockable.LockInfo()
+		if !ok || info.Provider == "" || info.Name == "" {
+			continue
+		}
+		declared[info.Provider+":"+info.Name] = struct{}{}
+		providers[info.Provider] = struct{}{}
+
+		locked, ok := lockfile.GetPackage(info.Provider, info.Name)
+		switch {
+		case !ok:
+			stale(fmt.Sprintf("%s:%s is not in the lockfile; run 'preflight lock update'", info.Provider, info.Name))
+		case info.Version != "" && info.Version != "latest" && locked.Version() != info.Version:
+			stale(fmt.Sprintf("%s:%s is locked at %s but the configuration pins %s; run 'preflight lock update'", info.Provider, info.Name, locked.Version(), info.Version))
+		}
+	}
+
+	keys := make([]string, 0, lockfile.PackageCount())
+	for key := range lockfile.Packages() {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		pkg := lockfile.Packages()[key]
+		if _, ok := providers[pkg.Provider()]; !ok {
+			continue
+		}
+		if _, ok := declared[pkg.Provider()+":"+pkg.Name()]; !ok {
+			stale(fmt.Sprintf("%s:%s is locked but no longer declared for target %s; run 'preflight lock update'", pkg.Provider(), pkg.Name(), target))
+		}
+	}
+	return findings, nil
+}
+
+// ciFindings collects findings, merging those several targets share.
+type ciFindings struct {
+	list  []CIFinding
+	index map[string]int
+}
+
+func newCIFindings() *ciFindings {
+	return &ciFindings{index: make(map[string]int)}
+}
+
+func (c *ciFindings) add(target string, f CIFinding) {
+	key := strings.Join([]string{f.Check, f.Severity, f.File, strconv.Itoa(f.Line), strconv.Itoa(f.Column), f.Message}, "\x00")
+	if i, ok := c.index[key]; ok {
+		if target != "" && !slices.Contains(c.list[i].Targets, target) {
+			c.list[i].Targets = append(c.list[i].Targets, target)
+		}
+		return
+	}
+	if target != "" {
+		f.Targets = []string{target}
+	}
+	c.index[key] = len(c.list)
+	c.list = append(c.list, f)
+}
@@ -0,0 +1,121 @@
+package app
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeCIConfig(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	return filepath.Join(dir, "preflight.yaml")
+}
+
+func TestPreflight_CI(t *testing.T) {
+	t.Parallel()
+
+	configPath := writeCIConfig(t, map[string]string{
+		"preflight.yaml":   "defaults:\n  mode: locked\ntargets:\n  default: [base, work]\n  home: [base]\n",
+		"layers/base.yaml": "name: base\npackages:\n  npm:\n    packages:\n      - typescript\n",
+		"layers/work.yaml": "name: work\npackages:\n  npm:\n    packages:\n      - eslint\n      - typescript\n",
+	})
+
+	report, err := New(io.Discard).CI(context.Background(), configPath, CIOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"default", "home"}, report.Targets)
+
+	var duplicate, lockfile *CIFinding
+	for i, f := range report.Findings {
+		switch f.Check {
+		case CICheckDuplicates:
+			duplicate = &report.Findings[i]
+		case CICheckLockfile:
+			lockfile = &report.Findings[i]
+		}
+	}
+	require.NotNil(t, duplicate)
+	assert.Equal(t, CISeverityWarning, duplicate.Severity)
+	assert.Equal(t, filepath.Join(filepath.Dir(configPath), "layers", "work.yaml"), duplicate.File)
+	assert.Equal(t, 6, duplicate.Line, "points at the second declaration")
+	assert.Equal(t, []string{"default"}, duplicate.Targets)
+
+	require.NotNil(t, lockfile, "locked mode requires a lockfile")
+	assert.Equal(t, CISeverityError, lockfile.Severity)
+	assert.False(t, report.HasPolicyViolations())
+}
+
+func TestPreflight_CI_StaleLockfile(t *testing.T) {
+	t.Parallel()
+
+	configPath := writeCIConfig(t, map[string]string{
+		"preflight.yaml":   "defaults:\n  mode: locked\ntargets:\n  default: [base]\n",
+		"layers/base.yaml": "name: base\npackages:\n  npm:\n    packages: [eslint@8.0.0, typescript]\n",
+	})
+	p := New(io.Discard)
+	require.NoError(t, p.LockUpdate(context.Background(), configPath))
+
+	report, err := p.CI(context.Background(), configPath, CIOptions{})
+	require.NoError(t, err)
+	for _, f := range report.Findings {
+		assert.NotEqual(t, CICheckLockfile, f.Check, f.Message)
+	}
+
+	require.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(configPath), "layers", "base.yaml"),
+		[]byte("name: base\npackages:\n  npm:\n    packages: [eslint@9.0.0, prettier]\n"), 0o644))
+	report, err = p.CI(context.Background(), configPath, CIOptions{})
+	require.NoError(t, err)
+
+	var messages []string
+	for _, f := range report.Findings {
+		if f.Check == CICheckLockfile {
+			messages = append(messages, f.Message)
+		}
+	}
+	assert.ElementsMatch(t, []string{
+		"npm:eslint is locked at 8.0.0 but the configuration pins 9.0.0; run 'preflight lock update'",
+		"npm:prettier is not in the lockfile; run 'preflight lock update'",
+		"npm:typescript is locked but no longer declared for target default; run 'preflight lock update'",
+	}, messages)
+}
+
+func TestPreflight_CI_UnknownTarget(t *testing.T) {
+	t.Parallel()
+
+	configPath := writeCIConfig(t, map[string]string{
+		"preflight.yaml":   "targets:\n  default: [base]\n",
+		"layers/base.yaml": "name: base\n",
+	})
+
+	_, err := New(io.Discard).CI(context.Background(), configPath, CIOptions{Targets: []string{"work"}})
+	require.Error(t, err)
+}
+
+func TestCIValidationFindings(t *testing.T) {
+	t.Parallel()
+
+	result := &ValidationResult{
+		Errors:           []string{"layers/base.yaml:3:5: packages.brew: expected object"},
+		PolicyViolations: []string{"deny: brew:formula:telnet"},
+		Warnings:         []string{"Packages without config:", "  • ripgrep", "packages.brew.formulae: git is declared by layers a, b"},
+	}
+	duplicates := []CIFinding{{Message: "packages.brew.formulae: git is declared by layers a, b"}}
+
+	findings := ciValidationFindings("preflight.yaml", result, duplicates)
+	assert.Equal(t, []CIFinding{
+		{Check: CICheckValidate, Severity: CISeverityError, Message: "packages.brew: expected object", File: "layers/base.yaml", Line: 3, Column: 5},
+		{Check: CICheckPolicy, Severity: CISeverityError, Message: "deny: brew:formula:telnet", File: "preflight.yaml"},
+		{Check: CICheckValidate, Severity: CISeverityWarning, Message: "Packages without config:\n• ripgrep", File: "preflight.yaml"},
+	}, findings)
+}
@@ -0,0 +1,123 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+)
+
+// cloudIdentity is a declared cloud CLI identity and the lightweight call
+// that proves its credentials work.
+type cloudIdentity struct {
+	provider string
+	stepID   string
+	label    string
+	probe    []string
+	fix      string
+}
+
+// checkCloudProfiles verifies that each declared AWS profile, gcloud
+// configuration and Azure subscription can authenticate, using calls that
+// only identify the caller (such as 'aws sts get-caller-identity').
+func checkCloudProfiles(configPath, targetName string, run func(string, ...string) (string, error), report *DoctorReport) {
+	target, err := config.NewTargetName(targetName)
+	if err != nil {
+		return
+	}
+	merged, err := config.NewLoader().Load(configPath, target)
+	if err != nil {
+		return
+	}
+
+	var identities []cloudIdentity
+	for _, p := range merged.AWS.Profiles {
+		identities = append(identities, cloudIdentity{
+			provider: "aws",
+			stepID:   "aws:profile:" + p.Name,
+			label:    "aws profile " + p.Name,
+			probe:    []string{"sts", "get-caller-identity", "--profile", p.Name, "--output", "json"},
+			fix:      "preflight apply",
+		})
+	}
+	for _, p := range merged.AWS.SSO {
+		identities = append(identities, cloudIdentity{
+			provider: "aws",
+			stepID:   "aws:sso:" + p.ProfileName,
+			label:    "aws sso profile " + p.ProfileName,
+			probe:    []string{"sts", "get-caller-identity", "--profile", p.ProfileName, "--output", "json"},
+			fix:      "aws sso login --profile " + p.ProfileName,
+		})
+	}
+	for _, c := range merged.GCloud.Configurations {
+		stepID, fix := "gcloud:configuration:"+c.Name, "gcloud auth login --configuration "+c.Name
+		if c.KeyRef != "" {
+			stepID, fix = "gcloud:auth:"+c.Name, "preflight apply"
+		}
+		identities = append(identities, cloudIdentity{
+			provider: "gcloud",
+			stepID:   stepID,
+			label:    "gcloud configuration " + c.Name,
+			probe:    []string{"auth", "print-access-token", "--configuration", c.Name},
+			fix:      fix,
+		})
+	}
+	for _, a := range merged.Azure.Accounts {
+		subscription := a.Subscription
+		if subscription == "" {
+			subscription = a.Name
+		}
+		fix := "az login"
+		switch {
+		case a.ClientSecretRef != "" || a.ClientCertificateRef != "":
+			fix = "preflight apply"
+		case a.Tenant != "":
+			fix = "az login --tenant " + a.Tenant
+		}
+		identities = append(identities, cloudIdentity{
+			provider: "azure",
+			stepID:   "azure:account:" + a.Name,
+			label:    "azure subscription " + a.Name,
+			probe:    []string{"account", "get-access-token", "--subscription", subscription, "--output", "none"},
+			fix:      fix,
+		})
+	}
+
+	clis := map[string]string{"aws": "aws", "gcloud": "gcloud", "azure": "az"}
+	installs := map[string]string{"aws": "brew install awscli", "gcloud": "brew install --cask google-cloud-sdk", "azure": "brew install azure-cli"}
+	installed := make(map[string]bool)
+	for _, id := range identities {
+		cli := clis[id.provider]
+		ok, checked := installed[cli]
+		if !checked {
+			_, err := run(cli, "--version")
+			ok = err == nil
+			installed[cli] = ok
+			if !ok {
+				report.Issues = append(report.Issues, DoctorIssue{
+					Provider:   id.provider,
+					StepID:     id.provider + ":install",
+					Severity:   SeverityWarning,
+					Message:    fmt.Sprintf("%s is not installed; %s profiles cannot be checked", cli, id.provider),
+					Expected:   cli + " installed",
+					Actual:     "not found",
+					FixCommand: installs[id.provider],
+				})
+			}
+		}
+		if !ok {
+			continue
+		}
+
+		if _, err := run(cli, id.probe...); err != nil {
+			report.Issues = append(report.Issues, DoctorIssue{
+				Provider:   id.provider,
+				StepID:     id.stepID,
+				Severity:   SeverityWarning,
+				Message:    fmt.Sprintf("%s cannot authenticate (missing or expired credentials)", id.label),
+				Expected:   "authenticated",
+				Actual:     "not authenticated",
+				FixCommand: id.fix,
+			})
+		}
+	}
+}
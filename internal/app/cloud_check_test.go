@@ -0,0 +1,54 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckCloudProfiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "preflight.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("targets:\n  default: [base]\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "layers"), 0o755))
+	layer := `name: base
+aws:
+  profiles:
+    - name: dev
+      region: eu-west-1
+  sso:
+    - profile_name: prod
+      sso_session: corp
+      sso_account_id: "123456789012"
+      sso_role_name: Admin
+gcloud:
+  configurations:
+    - name: work
+      project: acme
+azure:
+  accounts:
+    - name: prod
+      subscription: 0000-1111
+      tenant: contoso.onmicrosoft.com
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "layers", "base.yaml"), []byte(layer), 0o644))
+
+	report := &DoctorReport{}
+	checkCloudProfiles(configPath, "default", fakeEngine(map[string]string{
+		"aws --version": "aws-cli/2.15.0",
+		"aws sts get-caller-identity --profile dev --output json": `{"Account": "123456789012"}`,
+		"az --version": "azure-cli 2.60.0",
+	}), report)
+
+	require.Len(t, report.Issues, 3)
+	assert.Equal(t, "aws:sso:prod", report.Issues[0].StepID)
+	assert.Equal(t, "aws sso login --profile prod", report.Issues[0].FixCommand)
+	assert.Equal(t, "gcloud:install", report.Issues[1].StepID)
+	assert.Equal(t, "azure:account:prod", report.Issues[2].StepID)
+	assert.Equal(t, "az login --tenant contoso.onmicrosoft.com", report.Issues[2].FixCommand)
+}
@@ -0,0 +1,186 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/advisor"
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+)
+
+// ConfigEditPlan is a natural-language request turned into layer patches.
+type ConfigEditPlan struct {
+	Summary string        `json:"summary"`
+	Patches []ConfigPatch `json:"patches"`
+	// Reasons holds the AI's reason for each patch, by index.
+	Reasons []string `json:"reasons,omitempty"`
+	// Skipped describes proposed edits that were dropped as invalid or no-ops.
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+// ProposeConfigEdit asks the AI provider to express request as edits of the
+// package lists of the layers next to configPath. The proposal is checked
+// against the layer files; nothing is written.
+func ProposeConfigEdit(ctx context.Context, provider advisor.AIProvider, configPath, request string) (*ConfigEditPlan, error) {
+	manifest, err := config.NewLoader().LoadManifest(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	paths, err := filepath.Glob(filepath.Join(filepath.Dir(configPath), "layers", "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no layer files found in %s", filepath.Join(filepath.Dir(configPath), "layers"))
+	}
+
+	layers := make(map[string]*config.Layer, len(paths))
+	layerPaths := make(map[string]string, len(paths))
+	req := advisor.ConfigEditRequest{
+		Request:   request,
+		Targets:   make(map[string][]string, len(manifest.Targets)),
+		ListPaths: config.PackageListPaths(),
+	}
+	for _, path := range paths {
+		// #nosec G304 -- reading the user's own layer files.
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		layer, err := config.ParseLayer(data)
+		if err != nil {
+			return nil, config.NewYAMLParseError(path, err)
+		}
+		name := strings.TrimSuffix(filepath.Base(path), ".yaml")
+		layers[name] = layer
+		layerPaths[name] = path
+
+		editable := advisor.EditableLayer{Name: name, Lists: make(map[string][]string)}
+		for _, list := range config.PackageListPaths() {
+			if pkgs := layer.PackageList(list); len(pkgs) > 0 {
+				editable.Lists[list] = pkgs
+			}
+		}
+		req.Layers = append(req.Layers, editable)
+	}
+	for target, names := range manifest.Targets {
+		for _, name := range names {
+			req.Targets[target] = append(req.Targets[target], name.String())
+		}
+	}
+
+	if !provider.Available() {
+		return nil, fmt.Errorf("AI provider %s is not available", provider.Name())
+	}
+	response, err := provider.Complete(ctx, advisor.BuildConfigEditPrompt(req))
+	if err != nil {
+		return nil, fmt.Errorf("AI completion failed: %w", err)
+	}
+	proposal, err := advisor.ParseConfigEditResponse(response.Content())
+	if err != nil {
+		return nil, err
+	}
+	return planConfigEdits(proposal, layers, layerPaths), nil
+}
+
+// planConfigEdits turns proposed edits into patches. Edits come from an AI
+// and are not trusted: edits of unknown layers or lists, invalid package
+// names and edits that change nothing are skipped.
+func planConfigEdits(proposal *advisor.ConfigEditResponse, layers map[string]*config.Layer, layerPaths map[string]string) *ConfigEditPlan {
+	plan := &ConfigEditPlan{Summary: proposal.Summary}
+	// pending[layer+path] tracks each list as edited so far.
+	pending := make(map[string][]string)
+
+	for _, edit := range proposal.Edits {
+		layer, ok := layers[edit.Layer]
+		if !ok {
+			plan.Skipped = append(plan.Skipped, fmt.Sprintf("layer %q does not exist", edit.Layer))
+			continue
+		}
+		if !slices.Contains(config.PackageListPaths(), edit.Path) {
+			plan.Skipped = append(plan.Skipped, fmt.Sprintf("%s is not a package list", edit.Path))
+			continue
+		}
+		value := strings.TrimSpace(edit.Value)
+		if value == "" || strings.ContainsAny(value, " \t\n") {
+			plan.Skipped = append(plan.Skipped, fmt.Sprintf("%q is not a package name", edit.Value))
+			continue
+		}
+
+		key := edit.Layer + "\x00" + edit.Path
+		list, ok := pending[key]
+		if !ok {
+			list = slices.Clone(layer.PackageList(edit.Path))
+		}
+		var patch ConfigPatch
+		switch PatchOp(edit.Operation) {
+		case PatchOpAdd:
+			if slices.Contains(list, value) {
+				plan.Skipped = append(plan.Skipped, fmt.Sprintf("%s is already in %s of layer %s", value, edit.Path, edit.Layer))
+				continue
+			}
+			list = append(list, value)
+			patch = NewConfigPatch(layerPaths[edit.Layer], edit.Path, PatchOpAdd, nil, value, "ask")
+		case PatchOpRemove:
+			if !slices.Contains(list, value) {
+				plan.Skipped = append(plan.Skipped, fmt.Sprintf("%s is not in %s of layer %s", value, edit.Path, edit.Layer))
+				continue
+			}
+			list = slices.DeleteFunc(list, func(pkg string) bool { return pkg == value })
+			patch = NewConfigPatch(layerPaths[edit.Layer], edit.Path, PatchOpRemove, value, nil, "ask")
+		default:
+			plan.Skipped = append(plan.Skipped, fmt.Sprintf("unsupported operation %q", edit.Operation))
+			continue
+		}
+		pending[key] = list
+		plan.Patches = append(plan.Patches, patch)
+		plan.Reasons = append(plan.Reasons, edit.Reason)
+	}
+	return plan
+}
+
+// PreviewConfigPatches returns a unified diff of the changes patches make
+// to existing layer files.
+func PreviewConfigPatches(patches []ConfigPatch) (string, error) {
+	return previewPatches(nil, ConfigPatchesToWriterPatches(patches))
+}
+
+// ApplyConfigPatches applies patches to existing layer files.
+func ApplyConfigPatches(patches []ConfigPatch) error {
+	return config.NewLayerWriter().ApplyPatches(ConfigPatchesToWriterPatches(patches))
+}
+
+// previewPatches returns a unified diff of the files patches change. Files
+// in created do not exist yet and start out empty.
+func previewPatches(created map[string][]byte, patches []config.Patch) (string, error) {
+	contents, err := config.NewLayerWriter().PreviewFrom(created, patches)
+	if err != nil {
+		return "", err
+	}
+
+	var diff strings.Builder
+	seen := make(map[string]bool)
+	for _, patch := range patches {
+		path := patch.LayerPath
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		var before []byte
+		if _, isNew := created[path]; !isNew {
+			// #nosec G304 -- layer paths come from the layers directory.
+			if before, err = os.ReadFile(path); err != nil {
+				return "", err
+			}
+		}
+		diff.WriteString(unifiedDiff(path, string(before), string(contents[path])))
+	}
+	return diff.String(), nil
+}
@@ -0,0 +1,96 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/advisor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProposeConfigEdit(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "preflight.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("targets:\n  default: [base, dev]\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "layers"), 0o755))
+	basePath := filepath.Join(dir, "layers", "base.yaml")
+	devPath := filepath.Join(dir, "layers", "dev.yaml")
+	require.NoError(t, os.WriteFile(basePath, []byte("name: base\npackages:\n  brew:\n    formulae:\n      - git\n      - wget\n"), 0o644))
+	require.NoError(t, os.WriteFile(devPath, []byte("name: dev\nshell:\n  env:\n    TOKEN: secret\n"), 0o644))
+
+	response := `{"summary": "Add Rust and lazygit to dev", "edits": [
+		{"layer": "dev", "path": "packages.brew.formulae", "operation": "add", "value": "rustup", "reason": "Rust toolchain installer"},
+		{"layer": "dev", "path": "packages.brew.formulae", "operation": "add", "value": "lazygit"},
+		{"layer": "dev", "path": "packages.brew.formulae", "operation": "add", "value": "lazygit"},
+		{"layer": "base", "path": "packages.brew.formulae", "operation": "remove", "value": "wget"},
+		{"layer": "base", "path": "packages.brew.formulae", "operation": "remove", "value": "curl"},
+		{"layer": "work", "path": "packages.brew.formulae", "operation": "add", "value": "jq"},
+		{"layer": "dev", "path": "shell.env", "operation": "add", "value": "EDITOR"},
+		{"layer": "dev", "path": "packages.brew.formulae", "operation": "add", "value": "rm -rf"}
+	]}`
+	provider := &recordingProvider{response: response}
+
+	plan, err := ProposeConfigEdit(context.Background(), provider, configPath, "add rust toolchain and lazygit to my dev layer")
+	require.NoError(t, err)
+
+	assert.Contains(t, provider.prompt.UserPrompt(), "Request: add rust toolchain and lazygit to my dev layer")
+	assert.Contains(t, provider.prompt.UserPrompt(), "- default: base, dev")
+	assert.Contains(t, provider.prompt.UserPrompt(), "- packages.brew.formulae: git, wget")
+	assert.NotContains(t, provider.prompt.UserPrompt(), "secret")
+
+	assert.Equal(t, "Add Rust and lazygit to dev", plan.Summary)
+	assert.Equal(t, []ConfigPatch{
+		NewConfigPatch(devPath, "packages.brew.formulae", PatchOpAdd, nil, "rustup", "ask"),
+		NewConfigPatch(devPath, "packages.brew.formulae", PatchOpAdd, nil, "lazygit", "ask"),
+		NewConfigPatch(basePath, "packages.brew.formulae", PatchOpRemove, "wget", nil, "ask"),
+	}, plan.Patches)
+	assert.Equal(t, []string{"Rust toolchain installer", "", ""}, plan.Reasons)
+	assert.Equal(t, []string{
+		"lazygit is already in packages.brew.formulae of layer dev",
+		"curl is not in packages.brew.formulae of layer base",
+		`layer "work" does not exist`,
+		"shell.env is not a package list",
+		`"rm -rf" is not a package name`,
+	}, plan.Skipped)
+
+	diff, err := PreviewConfigPatches(plan.Patches)
+	require.NoError(t, err)
+	assert.Contains(t, diff, "+      - rustup")
+	assert.Contains(t, diff, "-      - wget")
+
+	require.NoError(t, ApplyConfigPatches(plan.Patches))
+	data, err := os.ReadFile(devPath)
+	require.NoError(t, err)
+	assert.Equal(t, "name: dev\nshell:\n  env:\n    TOKEN: secret\npackages:\n  brew:\n    formulae:\n      - rustup\n      - lazygit\n", string(data))
+}
+
+func TestProposeConfigEdit_Unavailable(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "preflight.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("targets:\n  default: [base]\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "layers"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "layers", "base.yaml"), []byte("name: base\n"), 0o644))
+
+	_, err := ProposeConfigEdit(context.Background(), fakeAIProvider{}, configPath, "add jq")
+	assert.ErrorContains(t, err, "AI provider fake is not available")
+}
+
+// recordingProvider is an available provider that records the prompt it gets.
+type recordingProvider struct {
+	response string
+	prompt   advisor.Prompt
+}
+
+func (p *recordingProvider) Name() string    { return "recording" }
+func (p *recordingProvider) Available() bool { return true }
+func (p *recordingProvider) Complete(_ context.Context, prompt advisor.Prompt) (advisor.Response, error) {
+	p.prompt = prompt
+	return advisor.NewResponse(p.response, 0, "test"), nil
+}
@@ -0,0 +1,252 @@
+package app
+
+import (
+	"maps"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+)
+
+// GraphNodeKind classifies a node of a configuration graph.
+type GraphNodeKind string
+
+// Kinds of configuration graph nodes.
+const (
+	GraphNodeTarget   GraphNodeKind = "target"
+	GraphNodeLayer    GraphNodeKind = "layer"
+	GraphNodeProvider GraphNodeKind = "provider"
+	GraphNodePackage  GraphNodeKind = "package"
+)
+
+// GraphEdgeKind classifies an edge of a configuration graph.
+type GraphEdgeKind string
+
+// Kinds of configuration graph edges.
+const (
+	// GraphEdgeMerges connects a target to a layer it merges.
+	GraphEdgeMerges GraphEdgeKind = "merges"
+	// GraphEdgeExtends connects a target to a target it extends.
+	GraphEdgeExtends GraphEdgeKind = "extends"
+	// GraphEdgeRequires connects a layer to a layer it requires.
+	GraphEdgeRequires GraphEdgeKind = "requires"
+	// GraphEdgeUses connects a layer to a provider it declares packages for.
+	GraphEdgeUses GraphEdgeKind = "uses"
+	// GraphEdgeInstalls connects a provider to a package.
+	GraphEdgeInstalls GraphEdgeKind = "installs"
+)
+
+// GraphNode is a target, layer, provider or package. IDs are unique across
+// kinds, e.g. "layer:base" or "package:brew:ripgrep".
+type GraphNode struct {
+	ID    string        `json:"id"`
+	Kind  GraphNodeKind `json:"kind"`
+	Label string        `json:"label"`
+}
+
+// GraphEdge connects two nodes by ID.
+type GraphEdge struct {
+	From string        `json:"from"`
+	To   string        `json:"to"`
+	Kind GraphEdgeKind `json:"kind"`
+}
+
+// ConfigGraph is the structure of a configuration: targets, the layers they
+// merge, the providers the layers use and the packages they install.
+type ConfigGraph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// ConfigGraphOptions filter a configuration graph. Empty filters keep
+// everything.
+type ConfigGraphOptions struct {
+	// Targets keeps these targets, the layers they merge and the layers
+	// those require.
+	Targets []string
+	// Layers keeps these layers and the targets merging them.
+	Layers []string
+	// Providers keeps these providers and their packages.
+	Providers []string
+	// OmitPackages ends the graph at the providers.
+	OmitPackages bool
+}
+
+// graphPackageLists are the lists a layer declares packages in, by
+// provider. Taps and PPAs are sources rather than packages.
+var graphPackageLists = []struct {
+	provider string
+	get      func(*config.Layer) []string
+}{
+	{"brew", func(l *config.Layer) []string {
+		return append(slices.Clone(l.Packages.Brew.Formulae), l.Packages.Brew.Casks...)
+	}},
+	{"apt", func(l *config.Layer) []string { return l.Packages.Apt.Packages }},
+	{"npm", func(l *config.Layer) []string { return l.Packages.Npm.Packages }},
+	{"go", func(l *config.Layer) []string { return l.Packages.Go.Tools }},
+	{"pip", func(l *config.Layer) []string { return l.Packages.Pip.Packages }},
+	{"gem", func(l *config.Layer) []string { return l.Packages.Gem.Gems }},
+	{"cargo", func(l *config.Layer) []string { return l.Packages.Cargo.Crates }},
+	{"mas", func(l *config.Layer) []string {
+		apps := make([]string, 0, len(l.Packages.Mas.Apps))
+		for _, app := range l.Packages.Mas.Apps {
+			name := app.Name
+			if name == "" {
+				name = strconv.FormatInt(app.ID, 10)
+			}
+			apps = append(apps, name)
+		}
+		return apps
+	}},
+	{"runtime", func(l *config.Layer) []string {
+		tools := make([]string, 0, len(l.Runtime.Tools))
+		for _, tool := range l.Runtime.Tools {
+			tools = append(tools, tool.Name+"@"+tool.Version)
+		}
+		return tools
+	}},
+	{"vscode", func(l *config.Layer) []string { return l.VSCode.Extensions }},
+}
+
+// BuildConfigGraph returns the graph of the configuration at configPath.
+// Targets are sorted by name and layers appear in the order targets first
+// merge them, followed by layers no target uses.
+func BuildConfigGraph(configPath string, opts ConfigGraphOptions) (*ConfigGraph, error) {
+	manifest, err := config.NewLoader().LoadManifest(configPath)
+	if err != nil {
+		return nil, err
+	}
+	layers, err := loadLayerFiles(filepath.Join(filepath.Dir(configPath), "layers"))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range opts.Targets {
+		if _, ok := manifest.Targets[name]; !ok {
+			return nil, config.NewTargetNotFoundError(name, slices.Sorted(maps.Keys(manifest.Targets)))
+		}
+	}
+	// A kept target keeps the targets it extends.
+	keep := slices.Clone(opts.Targets)
+	for i := 0; i < len(keep); i++ {
+		for _, parent := range manifest.Extensions[keep[i]].Extends {
+			if !slices.Contains(keep, parent) {
+				keep = append(keep, parent)
+			}
+		}
+	}
+
+	targets := make([]string, 0, len(manifest.Targets))
+	for name := range manifest.Targets {
+		if len(keep) > 0 && !slices.Contains(keep, name) {
+			continue
+		}
+		if len(opts.Layers) > 0 && !slices.ContainsFunc(manifest.Targets[name], func(ln config.LayerName) bool {
+			return slices.Contains(opts.Layers, ln.String())
+		}) {
+			continue
+		}
+		targets = append(targets, name)
+	}
+	sort.Strings(targets)
+
+	// Layers in the order targets merge them, then the layers they require.
+	var layerOrder []string
+	addLayer := func(name string) {
+		if !slices.Contains(layerOrder, name) && (len(opts.Layers) == 0 || slices.Contains(opts.Layers, name)) {
+			layerOrder = append(layerOrder, name)
+		}
+	}
+	for _, target := range targets {
+		for _, ln := range manifest.Targets[target] {
+			addLayer(ln.String())
+		}
+	}
+	for i := 0; i < len(layerOrder); i++ {
+		if layer, ok := layers[layerOrder[i]]; ok {
+			for _, req := range layer.Requires {
+				addLayer(req.String())
+			}
+		}
+	}
+	if len(opts.Targets) == 0 {
+		for _, name := range slices.Sorted(maps.Keys(layers)) {
+			addLayer(name)
+		}
+	}
+
+	g := &ConfigGraph{}
+	seen := make(map[string]bool)
+	addNode := func(kind GraphNodeKind, id, label string) string {
+		id = string(kind) + ":" + id
+		if !seen[id] {
+			seen[id] = true
+			g.Nodes = append(g.Nodes, GraphNode{ID: id, Kind: kind, Label: label})
+		}
+		return id
+	}
+	addEdge := func(from, to string, kind GraphEdgeKind) {
+		edge := GraphEdge{From: from, To: to, Kind: kind}
+		if !slices.Contains(g.Edges, edge) {
+			g.Edges = append(g.Edges, edge)
+		}
+	}
+
+	for _, target := range targets {
+		addNode(GraphNodeTarget, target, target)
+	}
+	for _, name := range layerOrder {
+		addNode(GraphNodeLayer, name, name)
+	}
+
+	for _, target := range targets {
+		from := string(GraphNodeTarget) + ":" + target
+		// A target extending others merges the layers it adds itself.
+		var inherited []config.LayerName
+		for _, parent := range manifest.Extensions[target].Extends {
+			inherited = append(inherited, manifest.Targets[parent]...)
+			if seen[string(GraphNodeTarget)+":"+parent] {
+				addEdge(from, string(GraphNodeTarget)+":"+parent, GraphEdgeExtends)
+			}
+		}
+		for _, ln := range manifest.Targets[target] {
+			if !slices.Contains(inherited, ln) && slices.Contains(layerOrder, ln.String()) {
+				addEdge(from, string(GraphNodeLayer)+":"+ln.String(), GraphEdgeMerges)
+			}
+		}
+	}
+
+	for _, name := range layerOrder {
+		layer, ok := layers[name]
+		if !ok {
+			continue
+		}
+		from := string(GraphNodeLayer) + ":" + name
+		for _, req := range layer.Requires {
+			if slices.Contains(layerOrder, req.String()) {
+				addEdge(from, string(GraphNodeLayer)+":"+req.String(), GraphEdgeRequires)
+			}
+		}
+		for _, list := range graphPackageLists {
+			if len(opts.Providers) > 0 && !slices.Contains(opts.Providers, list.provider) {
+				continue
+			}
+			packages := list.get(layer)
+			if len(packages) == 0 {
+				continue
+			}
+			provider := addNode(GraphNodeProvider, list.provider, list.provider)
+			addEdge(from, provider, GraphEdgeUses)
+			if opts.OmitPackages {
+				continue
+			}
+			for _, pkg := range packages {
+				addEdge(provider, addNode(GraphNodePackage, list.provider+":"+pkg, pkg), GraphEdgeInstalls)
+			}
+		}
+	}
+
+	return g, nil
+}
@@ -0,0 +1,94 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeGraphConfig(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"preflight.yaml":       "targets:\n  work: [base, dev-node]\n  laptop:\n    extends: work\n    add: [device]\n  home: [base]\n",
+		"layers/base.yaml":     "name: base\npackages:\n  brew:\n    formulae: [git]\n    casks: [firefox]\n",
+		"layers/node.yaml":     "name: node\nruntime:\n  tools:\n    - name: node\n      version: \"20\"\n",
+		"layers/dev-node.yaml": "name: dev-node\nrequires: [node]\npackages:\n  npm:\n    packages: [pnpm]\n",
+		"layers/device.yaml":   "name: device\n",
+		"layers/old.yaml":      "name: old\npackages:\n  brew:\n    formulae: [git]\n",
+	}
+	for path, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, path)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, path), []byte(content), 0o644))
+	}
+	return filepath.Join(dir, "preflight.yaml")
+}
+
+func graphNodeIDs(g *ConfigGraph) []string {
+	ids := make([]string, 0, len(g.Nodes))
+	for _, n := range g.Nodes {
+		ids = append(ids, n.ID)
+	}
+	return ids
+}
+
+func TestBuildConfigGraph(t *testing.T) {
+	t.Parallel()
+
+	g, err := BuildConfigGraph(writeGraphConfig(t), ConfigGraphOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"target:home", "target:laptop", "target:work",
+		"layer:base", "layer:dev-node", "layer:device", "layer:node", "layer:old",
+		"provider:brew", "package:brew:git", "package:brew:firefox",
+		"provider:npm", "package:npm:pnpm",
+		"provider:runtime", "package:runtime:node@20",
+	}, graphNodeIDs(g))
+
+	assert.Contains(t, g.Edges, GraphEdge{From: "target:laptop", To: "target:work", Kind: GraphEdgeExtends})
+	assert.Contains(t, g.Edges, GraphEdge{From: "target:laptop", To: "layer:device", Kind: GraphEdgeMerges})
+	assert.NotContains(t, g.Edges, GraphEdge{From: "target:laptop", To: "layer:base", Kind: GraphEdgeMerges},
+		"inherited layers are reached through the extended target")
+	assert.Contains(t, g.Edges, GraphEdge{From: "layer:dev-node", To: "layer:node", Kind: GraphEdgeRequires})
+	assert.Contains(t, g.Edges, GraphEdge{From: "layer:old", To: "provider:brew", Kind: GraphEdgeUses})
+
+	installs := 0
+	for _, e := range g.Edges {
+		if e.From == "provider:brew" && e.To == "package:brew:git" {
+			installs++
+		}
+	}
+	assert.Equal(t, 1, installs, "edges are not repeated")
+}
+
+func TestBuildConfigGraph_Filters(t *testing.T) {
+	t.Parallel()
+
+	g, err := BuildConfigGraph(writeGraphConfig(t), ConfigGraphOptions{
+		Targets:      []string{"laptop"},
+		Providers:    []string{"npm"},
+		OmitPackages: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"target:laptop", "target:work",
+		"layer:base", "layer:dev-node", "layer:device", "layer:node",
+		"provider:npm",
+	}, graphNodeIDs(g))
+
+	g, err = BuildConfigGraph(writeGraphConfig(t), ConfigGraphOptions{Layers: []string{"device"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"target:laptop", "layer:device"}, graphNodeIDs(g))
+}
+
+func TestBuildConfigGraph_UnknownTarget(t *testing.T) {
+	t.Parallel()
+
+	_, err := BuildConfigGraph(writeGraphConfig(t), ConfigGraphOptions{Targets: []string{"server"}})
+	assert.True(t, config.IsUserError(err, config.ErrCodeTargetNotFound))
+}
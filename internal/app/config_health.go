@@ -0,0 +1,314 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+)
+
+// ConfigHealthKind classifies a configuration health issue.
+type ConfigHealthKind string
+
+const (
+	// ConfigHealthUnusedLayer is a layer file no target references.
+	ConfigHealthUnusedLayer ConfigHealthKind = "unused_layer"
+	// ConfigHealthMissingLayer is a target referencing a layer without a file.
+	ConfigHealthMissingLayer ConfigHealthKind = "missing_layer"
+	// ConfigHealthMissingSource is a files entry whose source does not exist.
+	ConfigHealthMissingSource ConfigHealthKind = "missing_source"
+	// ConfigHealthOverriddenEnv is a shell.env variable that a later layer
+	// overrides in every target using the layer, so it never takes effect.
+	ConfigHealthOverriddenEnv ConfigHealthKind = "overridden_env"
+	// ConfigHealthImplicitDependency is a layer using a package manager
+	// whose runtime only another layer installs, without requiring it.
+	ConfigHealthImplicitDependency ConfigHealthKind = "implicit_dependency"
+)
+
+// ConfigHealthIssue is dead or broken configuration found by CheckConfigHealth.
+type ConfigHealthIssue struct {
+	Kind    ConfigHealthKind `json:"kind"`
+	Target  string           `json:"target,omitempty"`
+	Layer   string           `json:"layer,omitempty"`
+	Message string           `json:"message"`
+}
+
+// CheckConfigHealth looks for unused layers, targets and requires
+// referencing missing layers, files entries with missing sources,
+// environment variables that are overridden everywhere and dependencies
+// between layers that are not declared with requires. Issues are grouped by kind and sorted by target
+// and layer within it.
+func CheckConfigHealth(configPath string) ([]ConfigHealthIssue, error) {
+	manifest, err := config.NewLoader().LoadManifest(configPath)
+	if err != nil {
+		return nil, err
+	}
+	configDir := filepath.Dir(configPath)
+
+	layers, err := loadLayerFiles(filepath.Join(configDir, "layers"))
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make([]string, 0, len(manifest.Targets))
+	for name := range manifest.Targets {
+		targets = append(targets, name)
+	}
+	sort.Strings(targets)
+
+	var issues []ConfigHealthIssue
+	// usedBy[layer] holds the layers after it, per target using it.
+	usedBy := make(map[string][][]string)
+	for _, target := range targets {
+		names := manifest.Targets[target]
+		for i, name := range names {
+			later := make([]string, 0, len(names)-i-1)
+			for _, next := range names[i+1:] {
+				later = append(later, next.String())
+			}
+			usedBy[name.String()] = append(usedBy[name.String()], later)
+
+			if _, ok := layers[name.String()]; !ok {
+				issues = append(issues, ConfigHealthIssue{
+					Kind:    ConfigHealthMissingLayer,
+					Target:  target,
+					Layer:   name.String(),
+					Message: fmt.Sprintf("target %s references layer %s, but layers/%s.yaml does not exist", target, name, name),
+				})
+			}
+		}
+	}
+
+	layerNames := make([]string, 0, len(layers))
+	for name := range layers {
+		layerNames = append(layerNames, name)
+	}
+	sort.Strings(layerNames)
+
+	required := make(map[string]bool)
+	for _, name := range layerNames {
+		for _, req := range layers[name].Requires {
+			required[req.String()] = true
+			if _, ok := layers[req.String()]; !ok {
+				issues = append(issues, ConfigHealthIssue{
+					Kind:    ConfigHealthMissingLayer,
+					Layer:   name,
+					Message: fmt.Sprintf("layer %s requires layer %s, but layers/%s.yaml does not exist", name, req, req),
+				})
+			}
+		}
+	}
+
+	for _, name := range layerNames {
+		if _, ok := usedBy[name]; !ok && !required[name] {
+			issues = append(issues, ConfigHealthIssue{
+				Kind:    ConfigHealthUnusedLayer,
+				Layer:   name,
+				Message: fmt.Sprintf("layer %s is not used by any target", name),
+			})
+		}
+	}
+
+	for _, name := range layerNames {
+		for _, file := range layers[name].Files {
+			if file.Template == "" {
+				continue
+			}
+			if _, err := os.Stat(resolveSource(configDir, file.Template)); os.IsNotExist(err) {
+				issues = append(issues, ConfigHealthIssue{
+					Kind:    ConfigHealthMissingSource,
+					Layer:   name,
+					Message: fmt.Sprintf("layer %s: source %s of %s does not exist", name, file.Template, file.Path),
+				})
+			}
+		}
+	}
+
+	for _, name := range layerNames {
+		sets, ok := usedBy[name]
+		if !ok {
+			continue
+		}
+		keys := make([]string, 0, len(layers[name].Shell.Env))
+		for key := range layers[name].Shell.Env {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			overriders, ok := overriddenEverywhere(key, sets, layers)
+			if !ok {
+				continue
+			}
+			issues = append(issues, ConfigHealthIssue{
+				Kind:    ConfigHealthOverriddenEnv,
+				Layer:   name,
+				Message: fmt.Sprintf("layer %s: shell.env.%s is overridden by %s in every target using it", name, key, strings.Join(overriders, ", ")),
+			})
+		}
+	}
+
+	issues = append(issues, implicitDependencies(layerNames, layers)...)
+
+	return issues, nil
+}
+
+// toolchains are the runtimes package managers need, with the names of the
+// brew formulae, apt packages and runtime tools that install them.
+var toolchains = []struct {
+	manager   string
+	runtime   string
+	uses      func(*config.Layer) bool
+	providers []string
+}{
+	{"npm", "node", func(l *config.Layer) bool { return len(l.Packages.Npm.Packages) > 0 }, []string{"node", "nodejs"}},
+	{"pip", "python", func(l *config.Layer) bool { return len(l.Packages.Pip.Packages) > 0 }, []string{"python", "python3"}},
+	{"gem", "ruby", func(l *config.Layer) bool { return len(l.Packages.Gem.Gems) > 0 }, []string{"ruby"}},
+	{"cargo", "rust", func(l *config.Layer) bool { return len(l.Packages.Cargo.Crates) > 0 }, []string{"rust", "rustup", "cargo"}},
+	{"go", "go", func(l *config.Layer) bool { return len(l.Packages.Go.Tools) > 0 }, []string{"go", "golang"}},
+}
+
+// implicitDependencies reports layers using a package manager whose runtime
+// comes from other layers, none of which they require, directly or through
+// the layers they require.
+func implicitDependencies(layerNames []string, layers map[string]*config.Layer) []ConfigHealthIssue {
+	var issues []ConfigHealthIssue
+	for _, name := range layerNames {
+		deps := requiredClosure(name, layers)
+		for _, tc := range toolchains {
+			if !tc.uses(layers[name]) || providesRuntime(layers[name], tc.providers) {
+				continue
+			}
+			var from []string
+			satisfied := false
+			for _, other := range layerNames {
+				if other == name || !providesRuntime(layers[other], tc.providers) {
+					continue
+				}
+				if deps[other] {
+					satisfied = true
+					break
+				}
+				from = append(from, other)
+			}
+			if satisfied || len(from) == 0 {
+				continue
+			}
+			issues = append(issues, ConfigHealthIssue{
+				Kind:  ConfigHealthImplicitDependency,
+				Layer: name,
+				Message: fmt.Sprintf("layer %s uses %s, but %s is only installed by %s; add it to requires",
+					name, tc.manager, tc.runtime, strings.Join(from, ", ")),
+			})
+		}
+	}
+	return issues
+}
+
+// requiredClosure returns the layers name requires, directly or through
+// other required layers.
+func requiredClosure(name string, layers map[string]*config.Layer) map[string]bool {
+	deps := make(map[string]bool)
+	queue := []string{name}
+	for len(queue) > 0 {
+		layer, ok := layers[queue[0]]
+		queue = queue[1:]
+		if !ok {
+			continue
+		}
+		for _, req := range layer.Requires {
+			if !deps[req.String()] {
+				deps[req.String()] = true
+				queue = append(queue, req.String())
+			}
+		}
+	}
+	return deps
+}
+
+// providesRuntime reports whether layer installs one of names as a brew
+// formula (in any tap or version), an apt package or a runtime tool.
+func providesRuntime(layer *config.Layer, names []string) bool {
+	for _, formula := range layer.Packages.Brew.Formulae {
+		formula = formula[strings.LastIndex(formula, "/")+1:]
+		formula, _, _ = strings.Cut(formula, "@")
+		if slices.Contains(names, formula) {
+			return true
+		}
+	}
+	for _, pkg := range layer.Packages.Apt.Packages {
+		if slices.Contains(names, pkg) {
+			return true
+		}
+	}
+	for _, tool := range layer.Runtime.Tools {
+		if slices.Contains(names, tool.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadLayerFiles parses the layer files in dir by file name.
+func loadLayerFiles(dir string) (map[string]*config.Layer, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	layers := make(map[string]*config.Layer, len(paths))
+	for _, path := range paths {
+		// #nosec G304 -- reading the user's own layer files.
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		layer, err := config.ParseLayer(data)
+		if err != nil {
+			return nil, config.NewYAMLParseError(path, err)
+		}
+		layers[strings.TrimSuffix(filepath.Base(path), ".yaml")] = layer
+	}
+	return layers, nil
+}
+
+// overriddenEverywhere reports whether each set of later layers has one
+// defining the environment variable key, and returns the last definer of
+// each set without repeats.
+func overriddenEverywhere(key string, sets [][]string, layers map[string]*config.Layer) ([]string, bool) {
+	var overriders []string
+	for _, set := range sets {
+		last := ""
+		for _, name := range set {
+			if layer, ok := layers[name]; ok {
+				if _, defined := layer.Shell.Env[key]; defined {
+					last = name
+				}
+			}
+		}
+		if last == "" {
+			return nil, false
+		}
+		if !slices.Contains(overriders, last) {
+			overriders = append(overriders, last)
+		}
+	}
+	return overriders, len(overriders) > 0
+}
+
+// resolveSource resolves a files source: "~/" is the home directory and
+// relative paths are relative to the configuration directory, where apply
+// runs from.
+func resolveSource(configDir, src string) string {
+	if rest, ok := strings.CutPrefix(src, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	if filepath.IsAbs(src) {
+		return src
+	}
+	return filepath.Join(configDir, src)
+}
@@ -0,0 +1,77 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckConfigHealth(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	write := func(path, content string) {
+		t.Helper()
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, path)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, path), []byte(content), 0o644))
+	}
+	write("preflight.yaml", "targets:\n  work: [base, work, role.rust]\n  home: [base, home]\n")
+	write("dotfiles/gitconfig", "")
+	write("layers/base.yaml", `name: base
+files:
+  - path: ~/.zshrc
+    mode: generated
+    template: dotfiles/zshrc
+  - path: ~/.gitconfig
+    mode: generated
+    template: dotfiles/gitconfig
+shell:
+  env:
+    EDITOR: vim
+    PAGER: less
+`)
+	write("layers/work.yaml", "name: work\nshell:\n  env:\n    EDITOR: code\n    PAGER: bat\n")
+	write("layers/home.yaml", "name: home\nshell:\n  env:\n    EDITOR: nvim\n")
+	write("layers/old.yaml", "name: old\n")
+
+	issues, err := CheckConfigHealth(filepath.Join(dir, "preflight.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, []ConfigHealthIssue{
+		{Kind: ConfigHealthMissingLayer, Target: "work", Layer: "role.rust", Message: "target work references layer role.rust, but layers/role.rust.yaml does not exist"},
+		{Kind: ConfigHealthUnusedLayer, Layer: "old", Message: "layer old is not used by any target"},
+		{Kind: ConfigHealthMissingSource, Layer: "base", Message: "layer base: source dotfiles/zshrc of ~/.zshrc does not exist"},
+		// PAGER is only overridden for work, so it still applies to home.
+		{Kind: ConfigHealthOverriddenEnv, Layer: "base", Message: "layer base: shell.env.EDITOR is overridden by home, work in every target using it"},
+	}, issues)
+}
+
+func TestCheckConfigHealth_Requires(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	write := func(path, content string) {
+		t.Helper()
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, path)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, path), []byte(content), 0o644))
+	}
+	write("preflight.yaml", "defaults:\n  requires: include\ntargets:\n  work: [dev-node, dev-python, dev-go]\n")
+	write("layers/node.yaml", "name: node\npackages:\n  brew:\n    formulae: [node@20]\n")
+	write("layers/python.yaml", "name: python\nruntime:\n  tools:\n    - name: python\n      version: \"3.12\"\n")
+	write("layers/dev-node.yaml", "name: dev-node\npackages:\n  npm:\n    packages: [pnpm]\n")
+	write("layers/dev-python.yaml", "name: dev-python\nrequires: [toolchains]\npackages:\n  pip:\n    packages: [black]\n")
+	write("layers/toolchains.yaml", "name: toolchains\nrequires: [python, rust]\n")
+	write("layers/dev-go.yaml", "name: dev-go\npackages:\n  brew:\n    formulae: [go]\n  go:\n    tools: [golang.org/x/tools/gopls@latest]\n")
+
+	issues, err := CheckConfigHealth(filepath.Join(dir, "preflight.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, []ConfigHealthIssue{
+		{Kind: ConfigHealthMissingLayer, Layer: "toolchains", Message: "layer toolchains requires layer rust, but layers/rust.yaml does not exist"},
+		// node is not required by anything, so no target can use it.
+		{Kind: ConfigHealthUnusedLayer, Layer: "node", Message: "layer node is not used by any target"},
+		// dev-python gets python through toolchains; dev-go installs go itself.
+		{Kind: ConfigHealthImplicitDependency, Layer: "dev-node", Message: "layer dev-node uses npm, but node is only installed by node; add it to requires"},
+	}, issues)
+}
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"fmt"
+	goruntime "runtime"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+)
+
+// checkContainerRuntime verifies that the declared container engine is
+// installed and that its daemon (or Podman machine) answers requests.
+func checkContainerRuntime(configPath, targetName string, run func(string, ...string) (string, error), report *DoctorReport) {
+	target, err := config.NewTargetName(targetName)
+	if err != nil {
+		return
+	}
+	merged, err := config.NewLoader().Load(configPath, target)
+	if err != nil || merged.Docker.IsZero() {
+		return
+	}
+
+	engine := merged.Docker.Engine
+	if engine == "" {
+		engine = "desktop"
+	}
+	cli, install, start := "docker", "brew install --cask docker", "open -a Docker"
+	switch engine {
+	case "colima":
+		install, start = "brew install colima docker", "colima start"
+	case "podman":
+		cli, install, start = "podman", "brew install podman", "podman machine start"
+	default:
+		if goruntime.GOOS != "darwin" {
+			install, start = "curl -fsSL https://get.docker.com | sh", "sudo systemctl start docker"
+		}
+	}
+
+	if _, err := run(cli, "--version"); err != nil {
+		report.Issues = append(report.Issues, DoctorIssue{
+			Provider:   "docker",
+			StepID:     "docker:install",
+			Severity:   SeverityWarning,
+			Message:    fmt.Sprintf("%s is not installed for the %s engine", cli, engine),
+			Expected:   cli + " installed",
+			Actual:     "not found",
+			FixCommand: install,
+		})
+		return
+	}
+
+	if _, err := run(cli, "info"); err != nil {
+		report.Issues = append(report.Issues, DoctorIssue{
+			Provider:   "docker",
+			StepID:     "docker:daemon",
+			Severity:   SeverityWarning,
+			Message:    fmt.Sprintf("the %s engine is not responding", engine),
+			Expected:   "running",
+			Actual:     "not responding",
+			FixCommand: start,
+		})
+	}
+}
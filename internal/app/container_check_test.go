@@ -0,0 +1,66 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeDockerConfig(t *testing.T, engine string) string {
+	t.Helper()
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "preflight.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("targets:\n  default: [base]\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "layers"), 0o755))
+	layer := "name: base\ndocker:\n  engine: " + engine + "\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "layers", "base.yaml"), []byte(layer), 0o644))
+	return configPath
+}
+
+// fakeEngine answers commands keyed by the full command line.
+func fakeEngine(outputs map[string]string) func(string, ...string) (string, error) {
+	return func(name string, args ...string) (string, error) {
+		return fakeTmux(outputs)("", append([]string{name}, args...)...)
+	}
+}
+
+func TestCheckContainerRuntime_NotInstalled(t *testing.T) {
+	t.Parallel()
+
+	report := &DoctorReport{}
+	checkContainerRuntime(writeDockerConfig(t, "podman"), "default", fakeEngine(nil), report)
+
+	require.Len(t, report.Issues, 1)
+	assert.Equal(t, "docker:install", report.Issues[0].StepID)
+	assert.Equal(t, "brew install podman", report.Issues[0].FixCommand)
+}
+
+func TestCheckContainerRuntime_DaemonNotResponding(t *testing.T) {
+	t.Parallel()
+
+	report := &DoctorReport{}
+	checkContainerRuntime(writeDockerConfig(t, "colima"), "default", fakeEngine(map[string]string{
+		"docker --version": "Docker version 27.0.3",
+	}), report)
+
+	require.Len(t, report.Issues, 1)
+	assert.Equal(t, "docker:daemon", report.Issues[0].StepID)
+	assert.True(t, strings.HasPrefix(report.Issues[0].Message, "the colima engine"))
+	assert.Equal(t, "colima start", report.Issues[0].FixCommand)
+}
+
+func TestCheckContainerRuntime_Healthy(t *testing.T) {
+	t.Parallel()
+
+	report := &DoctorReport{}
+	checkContainerRuntime(writeDockerConfig(t, "colima"), "default", fakeEngine(map[string]string{
+		"docker --version": "Docker version 27.0.3",
+		"docker info":      "Server Version: 27.0.3",
+	}), report)
+
+	assert.Empty(t, report.Issues)
+}
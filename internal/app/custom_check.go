@@ -0,0 +1,172 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/domain/plugin"
+)
+
+// customCheckProvider is the provider of doctor checks declared in layers.
+const customCheckProvider = "custom"
+
+// customChecks are doctor checks declared by the layers or by one plugin.
+type customChecks struct {
+	// Provider is customCheckProvider for layers, or the plugin name.
+	Provider string
+	Checks   []config.DoctorCheck
+}
+
+// shellRunner runs a command with sh and returns its combined output and
+// exit code. err is only set when the command could not run at all.
+type shellRunner func(ctx context.Context, command string) (output string, exitCode int, err error)
+
+// runShell runs command with sh.
+func runShell(ctx context.Context, command string) (string, int, error) {
+	out, err := exec.CommandContext(ctx, "sh", "-c", command).CombinedOutput()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return string(out), exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return string(out), -1, err
+	}
+	return string(out), 0, nil
+}
+
+// layerDoctorChecks returns the doctor checks the target's layers declare.
+func layerDoctorChecks(configPath, targetName string) []customChecks {
+	target, err := config.NewTargetName(targetName)
+	if err != nil {
+		return nil
+	}
+	merged, err := config.NewLoader().Load(configPath, target)
+	if err != nil || merged.Doctor.IsZero() {
+		return nil
+	}
+	return []customChecks{{Provider: customCheckProvider, Checks: merged.Doctor.Checks}}
+}
+
+// doctorChecks returns the doctor checks of enabled plugins that were
+// granted shell:execute, sorted by plugin. Plugins whose checks are not
+// granted are reported in report instead.
+func (pp *pluginProviders) doctorChecks(report *DoctorReport) []customChecks {
+	if pp == nil {
+		return nil
+	}
+	pp.mu.Lock()
+	loaded := make([]*plugin.Plugin, 0, len(pp.loaded))
+	for _, p := range pp.loaded {
+		loaded = append(loaded, p)
+	}
+	pp.mu.Unlock()
+	sort.Slice(loaded, func(i, j int) bool { return loaded[i].ID() < loaded[j].ID() })
+
+	grants := plugin.NewGrantService(pp.grants, nil)
+	var checks []customChecks
+	for _, p := range loaded {
+		if !p.Enabled || len(p.Manifest.Provides.Checks) == 0 {
+			continue
+		}
+		if grant, err := grants.Current(p); err != nil || !grant.IsGranted(plugin.CapabilityShellExecute) {
+			report.Issues = append(report.Issues, DoctorIssue{
+				Provider:   p.ID(),
+				StepID:     p.ID() + ":checks",
+				Severity:   SeverityInfo,
+				Message:    fmt.Sprintf("%d doctor check(s) of plugin %s did not run; they need shell:execute", len(p.Manifest.Provides.Checks), p.ID()),
+				Expected:   "shell:execute granted",
+				Actual:     "not granted",
+				FixCommand: fmt.Sprintf("preflight plugin permissions grant %s %s", p.ID(), plugin.CapabilityShellExecute),
+			})
+			continue
+		}
+		checks = append(checks, customChecks{Provider: p.ID(), Checks: p.Manifest.Provides.Checks})
+	}
+	return checks
+}
+
+// checkCustom runs custom doctor checks and reports those whose exit code
+// or output differs from what they expect. A check with a fix command is
+// fixable by doctor --fix.
+func checkCustom(ctx context.Context, sources []customChecks, run shellRunner, report *DoctorReport) {
+	for _, source := range sources {
+		for _, check := range source.Checks {
+			if ctx.Err() != nil {
+				return
+			}
+			expected, actual, ok := runCustomCheck(ctx, check, run)
+			if ok {
+				continue
+			}
+
+			message := check.Description
+			if message == "" {
+				message = "check " + check.Name
+			}
+			issue := DoctorIssue{
+				Provider: source.Provider,
+				StepID:   source.Provider + ":" + check.Name,
+				Severity: customCheckSeverity(check.Severity),
+				Message:  message + " failed",
+				Expected: expected,
+				Actual:   actual,
+			}
+			if check.Fix != "" {
+				issue.Fixable = true
+				issue.FixCommand = check.Fix
+				issue.RunFix = true
+			}
+			report.Issues = append(report.Issues, issue)
+		}
+	}
+}
+
+// runCustomCheck runs check and reports what it expected, what it got and
+// whether they match.
+func runCustomCheck(ctx context.Context, check config.DoctorCheck, run shellRunner) (expected, actual string, ok bool) {
+	output, code, err := run(ctx, check.Command)
+	output = strings.TrimSpace(output)
+	want := check.ExpectedExit()
+	switch {
+	case err != nil:
+		return fmt.Sprintf("exit %d", want), err.Error(), false
+	case code != want:
+		actual = fmt.Sprintf("exit %d", code)
+		if output != "" {
+			actual += ": " + firstLine(output)
+		}
+		return fmt.Sprintf("exit %d", want), actual, false
+	case check.Output != "":
+		// The pattern was validated when the layer or manifest was parsed.
+		if re, err := regexp.Compile(check.Output); err == nil && !re.MatchString(output) {
+			actual = firstLine(output)
+			if actual == "" {
+				actual = "no output"
+			}
+			return "output matching " + check.Output, actual, false
+		}
+	}
+	return "", "", true
+}
+
+func customCheckSeverity(severity string) IssueSeverity {
+	switch severity {
+	case "info":
+		return SeverityInfo
+	case "error":
+		return SeverityError
+	default:
+		return SeverityWarning
+	}
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
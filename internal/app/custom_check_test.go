@@ -0,0 +1,134 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/domain/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckCustom(t *testing.T) {
+	t.Parallel()
+
+	one := 1
+	sources := []customChecks{
+		{Provider: customCheckProvider, Checks: []config.DoctorCheck{
+			{Name: "vpn", Description: "GlobalProtect is installed", Command: "test -d /Applications/GlobalProtect.app", Fix: "brew install --cask globalprotect"},
+			{Name: "filevault", Command: "fdesetup status", Output: "FileVault is On", Severity: "error"},
+			{Name: "no-root-login", Command: "grep -q '^PermitRootLogin yes' /etc/ssh/sshd_config", Exit: &one},
+		}},
+		{Provider: "acme-security", Checks: []config.DoctorCheck{
+			{Name: "agent", Command: "pgrep -q falcon", Severity: "info"},
+		}},
+	}
+	run := func(_ context.Context, command string) (string, int, error) {
+		switch {
+		case strings.HasPrefix(command, "test"):
+			return "", 1, nil
+		case strings.HasPrefix(command, "fdesetup"):
+			return "FileVault is Off.\n", 0, nil
+		case strings.HasPrefix(command, "grep"):
+			return "", 1, nil
+		default:
+			return "", -1, errors.New(`exec: "pgrep": executable file not found`)
+		}
+	}
+
+	report := &DoctorReport{}
+	checkCustom(context.Background(), sources, run, report)
+
+	require.Len(t, report.Issues, 3, "the root login check expects exit 1")
+	assert.Equal(t, DoctorIssue{
+		Provider:   "custom",
+		StepID:     "custom:vpn",
+		Severity:   SeverityWarning,
+		Message:    "GlobalProtect is installed failed",
+		Expected:   "exit 0",
+		Actual:     "exit 1",
+		Fixable:    true,
+		FixCommand: "brew install --cask globalprotect",
+		RunFix:     true,
+	}, report.Issues[0])
+	assert.Equal(t, SeverityError, report.Issues[1].Severity)
+	assert.Equal(t, "output matching FileVault is On", report.Issues[1].Expected)
+	assert.Equal(t, "FileVault is Off.", report.Issues[1].Actual)
+	assert.False(t, report.Issues[1].Fixable)
+	assert.Equal(t, "acme-security:agent", report.Issues[2].StepID)
+	assert.Equal(t, SeverityInfo, report.Issues[2].Severity)
+	assert.Contains(t, report.Issues[2].Actual, "executable file not found")
+}
+
+func TestPluginProviders_DoctorChecks(t *testing.T) {
+	// Sets HOME so the plugin audit log stays in a temp dir.
+	t.Setenv("HOME", t.TempDir())
+
+	searchPath := t.TempDir()
+	pluginDir := filepath.Join(searchPath, "acme-security")
+	require.NoError(t, os.MkdirAll(pluginDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(pluginDir, "plugin.yaml"), []byte(`apiVersion: v1
+name: acme-security
+version: 1.0.0
+provides:
+  checks:
+    - name: vpn
+      command: test -d /Applications/GlobalProtect.app
+`), 0o600))
+
+	grants := plugin.NewGrantStore(filepath.Join(t.TempDir(), "grants.json"))
+	pp := newPluginProviders(plugin.NewLoader().WithSearchPaths(searchPath), grants, nil, &strings.Builder{})
+	pp.register(context.Background(), compiler.NewCompiler())
+
+	report := &DoctorReport{}
+	assert.Empty(t, pp.doctorChecks(report))
+	require.Len(t, report.Issues, 1)
+	assert.Equal(t, "acme-security:checks", report.Issues[0].StepID)
+	assert.Equal(t, "preflight plugin permissions grant acme-security shell:execute", report.Issues[0].FixCommand)
+
+	for _, p := range pp.loaded {
+		_, err := plugin.NewGrantService(grants, nil).Grant(context.Background(), p, plugin.CapabilityShellExecute)
+		require.NoError(t, err)
+	}
+	report = &DoctorReport{}
+	checks := pp.doctorChecks(report)
+	assert.Empty(t, report.Issues)
+	require.Len(t, checks, 1)
+	assert.Equal(t, "acme-security", checks[0].Provider)
+	assert.Equal(t, "vpn", checks[0].Checks[0].Name)
+}
+
+func TestFix_RunsCustomCheckFixes(t *testing.T) {
+	// Sets HOME so plugins and lifecycle state stay in a temp dir.
+	t.Setenv("HOME", t.TempDir())
+
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "enrolled")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "layers"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "preflight.yaml"), []byte("targets:\n  default:\n    - base\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "layers", "base.yaml"), []byte(`name: base
+doctor:
+  checks:
+    - name: enrolled
+      command: test -f `+marker+`
+      fix: touch `+marker+`
+`), 0o600))
+
+	p := New(&strings.Builder{})
+	ctx := context.Background()
+	report, err := p.Doctor(ctx, NewDoctorOptions(filepath.Join(dir, "preflight.yaml"), "default"))
+	require.NoError(t, err)
+	require.Len(t, report.Issues, 1)
+	assert.Equal(t, "custom:enrolled", report.Issues[0].StepID)
+
+	result, err := p.Fix(ctx, report)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.FixedCount())
+	assert.FileExists(t, marker)
+}
@@ -0,0 +1,181 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+)
+
+// envrcHeader marks .envrc files that preflight generated and may rewrite.
+const envrcHeader = "# Generated by preflight env direnv - do not edit manually\n"
+
+// DirenvStatus describes a generated .envrc relative to the configuration.
+type DirenvStatus string
+
+// DirenvStatus constants.
+const (
+	// DirenvCurrent means the file matches the configuration.
+	DirenvCurrent DirenvStatus = "current"
+	// DirenvStale means the file was generated but the configuration changed.
+	DirenvStale DirenvStatus = "stale"
+	// DirenvMissing means the directory has no .envrc yet.
+	DirenvMissing DirenvStatus = "missing"
+	// DirenvUnmanaged means an .envrc exists that preflight did not write.
+	DirenvUnmanaged DirenvStatus = "unmanaged"
+	// DirenvNoDir means the project directory does not exist.
+	DirenvNoDir DirenvStatus = "no-dir"
+)
+
+// DirenvFile is the .envrc preflight generates for a project directory.
+type DirenvFile struct {
+	Dir     string
+	Path    string
+	Content []byte
+	Status  DirenvStatus
+}
+
+// PlanDirenvFiles renders the .envrc of each direnv directory declared by
+// target's layers and compares it with the file on disk.
+func PlanDirenvFiles(configPath, targetName string) ([]DirenvFile, error) {
+	target, err := config.NewTargetName(targetName)
+	if err != nil {
+		return nil, err
+	}
+	merged, err := config.NewLoader().Load(configPath, target)
+	if err != nil {
+		return nil, err
+	}
+	home, _ := os.UserHomeDir()
+
+	files := make([]DirenvFile, 0, len(merged.Direnv.Dirs))
+	for _, dir := range merged.Direnv.Dirs {
+		path := dir.Path
+		if path == "~" || strings.HasPrefix(path, "~/") {
+			path = filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(configPath), path)
+		}
+		file := DirenvFile{
+			Dir:     path,
+			Path:    filepath.Join(path, ".envrc"),
+			Content: RenderEnvrc(dir.Env),
+		}
+		file.Status = direnvStatus(file)
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+func direnvStatus(file DirenvFile) DirenvStatus {
+	if info, err := os.Stat(file.Dir); err != nil || !info.IsDir() {
+		return DirenvNoDir
+	}
+	existing, err := os.ReadFile(file.Path)
+	switch {
+	case err != nil:
+		return DirenvMissing
+	case !bytes.HasPrefix(existing, []byte(envrcHeader)):
+		return DirenvUnmanaged
+	case !bytes.Equal(existing, file.Content):
+		return DirenvStale
+	default:
+		return DirenvCurrent
+	}
+}
+
+// RenderEnvrc renders an .envrc exporting env. Secret references are
+// resolved through 'preflight secrets get' each time direnv loads the
+// file, so no secret value is written to disk.
+func RenderEnvrc(env map[string]string) []byte {
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	buf.WriteString(envrcHeader)
+	for _, name := range names {
+		value := env[name]
+		if ref, ok := strings.CutPrefix(value, "secret://"); ok {
+			if backend, key, found := strings.Cut(ref, "/"); found {
+				fmt.Fprintf(&buf, "export %s=\"$(preflight secrets get --backend %s %s)\"\n", name, envrcQuote(backend), envrcQuote(key))
+				continue
+			}
+		}
+		fmt.Fprintf(&buf, "export %s=%s\n", name, envrcQuote(value))
+	}
+	return buf.Bytes()
+}
+
+// WriteDirenvFile writes file.Content to file.Path. It refuses to
+// overwrite an .envrc preflight did not generate unless force is set.
+func WriteDirenvFile(file DirenvFile, force bool) error {
+	switch file.Status {
+	case DirenvNoDir:
+		return fmt.Errorf("directory %s does not exist", file.Dir)
+	case DirenvUnmanaged:
+		if !force {
+			return fmt.Errorf("%s was not generated by preflight; use --force to replace it", file.Path)
+		}
+	case DirenvCurrent, DirenvStale, DirenvMissing:
+	}
+	return os.WriteFile(file.Path, file.Content, 0o644)
+}
+
+// envrcQuote single-quotes s for the POSIX shell direnv evaluates.
+func envrcQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// checkDirenv reports a missing direnv binary and generated .envrc files
+// that are missing or out of date.
+func checkDirenv(configPath, target string, lookPath func(string) (string, error), report *DoctorReport) {
+	files, err := PlanDirenvFiles(configPath, target)
+	if err != nil || len(files) == 0 {
+		return
+	}
+	if _, err := lookPath("direnv"); err != nil {
+		report.Issues = append(report.Issues, DoctorIssue{
+			Provider:   "direnv",
+			StepID:     "direnv:binary",
+			Severity:   SeverityWarning,
+			Message:    "direnv is not installed; project .envrc files will not load",
+			Expected:   "direnv installed and hooked into your shell",
+			Actual:     "not found",
+			FixCommand: "brew install direnv",
+		})
+	}
+	for _, file := range files {
+		issue := DoctorIssue{
+			Provider:   "direnv",
+			StepID:     "direnv:" + file.Dir,
+			Severity:   SeverityWarning,
+			Expected:   string(DirenvCurrent),
+			Actual:     string(file.Status),
+			FixCommand: "preflight env direnv",
+		}
+		switch file.Status {
+		case DirenvCurrent:
+			continue
+		case DirenvStale:
+			issue.Message = fmt.Sprintf("%s is out of date", file.Path)
+		case DirenvMissing:
+			issue.Message = fmt.Sprintf("%s has not been generated", file.Path)
+		case DirenvUnmanaged:
+			issue.Message = fmt.Sprintf("%s exists but was not generated by preflight", file.Path)
+			issue.FixCommand = "preflight env direnv --force"
+		case DirenvNoDir:
+			issue.Severity = SeverityInfo
+			issue.Message = fmt.Sprintf("direnv directory %s does not exist", file.Dir)
+			issue.FixCommand = ""
+		}
+		report.Issues = append(report.Issues, issue)
+	}
+}
@@ -0,0 +1,91 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeDirenvConfig(t *testing.T, projectDir string) string {
+	t.Helper()
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "preflight.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("targets:\n  default: [base]\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "layers"), 0o755))
+	layer := "name: base\ndirenv:\n  dirs:\n    - path: " + projectDir + "\n      env:\n        PORT: \"8080\"\n        TOKEN: secret://1password/Work/token\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "layers", "base.yaml"), []byte(layer), 0o644))
+	return configPath
+}
+
+func TestRenderEnvrc(t *testing.T) {
+	t.Parallel()
+
+	content := RenderEnvrc(map[string]string{
+		"PORT":  "8080",
+		"QUOTE": "it's",
+		"TOKEN": "secret://1password/Work/api token",
+	})
+	assert.Equal(t, envrcHeader+
+		"export PORT='8080'\n"+
+		"export QUOTE='it'\\''s'\n"+
+		"export TOKEN=\"$(preflight secrets get --backend '1password' 'Work/api token')\"\n",
+		string(content))
+}
+
+func TestPlanDirenvFiles_Statuses(t *testing.T) {
+	t.Parallel()
+
+	project := t.TempDir()
+	configPath := writeDirenvConfig(t, project)
+
+	files, err := PlanDirenvFiles(configPath, "default")
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, filepath.Join(project, ".envrc"), files[0].Path)
+	assert.Equal(t, DirenvMissing, files[0].Status)
+	assert.NotContains(t, string(files[0].Content), "secret://")
+
+	require.NoError(t, WriteDirenvFile(files[0], false))
+	files, err = PlanDirenvFiles(configPath, "default")
+	require.NoError(t, err)
+	assert.Equal(t, DirenvCurrent, files[0].Status)
+
+	require.NoError(t, os.WriteFile(files[0].Path, []byte(envrcHeader+"export PORT='1'\n"), 0o644))
+	files, err = PlanDirenvFiles(configPath, "default")
+	require.NoError(t, err)
+	assert.Equal(t, DirenvStale, files[0].Status)
+
+	require.NoError(t, os.WriteFile(files[0].Path, []byte("use nix\n"), 0o644))
+	files, err = PlanDirenvFiles(configPath, "default")
+	require.NoError(t, err)
+	assert.Equal(t, DirenvUnmanaged, files[0].Status)
+	require.ErrorContains(t, WriteDirenvFile(files[0], false), "--force")
+	require.NoError(t, WriteDirenvFile(files[0], true))
+}
+
+func TestCheckDirenv(t *testing.T) {
+	t.Parallel()
+
+	project := t.TempDir()
+	configPath := writeDirenvConfig(t, project)
+	notFound := func(string) (string, error) { return "", errors.New("not found") }
+
+	report := &DoctorReport{}
+	checkDirenv(configPath, "default", notFound, report)
+	require.Len(t, report.Issues, 2)
+	assert.Equal(t, "direnv:binary", report.Issues[0].StepID)
+	assert.Contains(t, report.Issues[1].Message, "has not been generated")
+	assert.Equal(t, "preflight env direnv", report.Issues[1].FixCommand)
+
+	files, err := PlanDirenvFiles(configPath, "default")
+	require.NoError(t, err)
+	require.NoError(t, WriteDirenvFile(files[0], false))
+
+	report = &DoctorReport{}
+	checkDirenv(configPath, "default", func(string) (string, error) { return "/usr/bin/direnv", nil }, report)
+	assert.Empty(t, report.Issues)
+}
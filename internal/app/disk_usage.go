@@ -0,0 +1,226 @@
+package app
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+)
+
+// Kinds of disk usage entries.
+const (
+	DiskUsageFormula     = "formula"
+	DiskUsageCask        = "cask"
+	DiskUsageNpm         = "npm"
+	DiskUsageCache       = "cache"
+	DiskUsageOldVersions = "old_versions"
+)
+
+// DiskUsageEntry is the disk usage of a package, cache or set of old
+// versions.
+type DiskUsageEntry struct {
+	Kind  string `json:"kind"`
+	Name  string `json:"name"`
+	Path  string `json:"path"`
+	Bytes int64  `json:"bytes"`
+	// Reclaimable is set for caches and old versions, which cleanup
+	// --caches removes.
+	Reclaimable bool `json:"reclaimable,omitempty"`
+}
+
+// DiskUsageReport is the disk usage of the managed packages and the
+// package manager caches, largest first.
+type DiskUsageReport struct {
+	Entries          []DiskUsageEntry `json:"entries"`
+	TotalBytes       int64            `json:"total_bytes"`
+	ReclaimableBytes int64            `json:"reclaimable_bytes"`
+}
+
+// DiskLocations are the directories package managers install packages and
+// keep caches in. Locations of missing package managers are empty.
+type DiskLocations struct {
+	BrewPrefix string `json:"brew_prefix,omitempty"`
+	BrewCache  string `json:"brew_cache,omitempty"`
+	NpmRoot    string `json:"npm_root,omitempty"`
+	NpmCache   string `json:"npm_cache,omitempty"`
+}
+
+// FindDiskLocations asks brew and npm where they keep packages and caches.
+func FindDiskLocations(run func(string, ...string) (string, error)) DiskLocations {
+	ask := func(name string, args ...string) string {
+		out, err := run(name, args...)
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(out)
+	}
+	return DiskLocations{
+		BrewPrefix: ask("brew", "--prefix"),
+		BrewCache:  ask("brew", "--cache"),
+		NpmRoot:    ask("npm", "root", "-g"),
+		NpmCache:   ask("npm", "config", "get", "cache"),
+	}
+}
+
+// AnalyzeDiskUsage measures the formulae, casks and npm packages declared
+// in packages, the old versions of every installed formula and the brew and
+// npm caches. Packages that are not installed are left out.
+func AnalyzeDiskUsage(loc DiskLocations, packages config.PackageSet) DiskUsageReport {
+	var entries []DiskUsageEntry
+	add := func(kind, name, path string, reclaimable bool) {
+		if size := dirSize(path); size > 0 {
+			entries = append(entries, DiskUsageEntry{Kind: kind, Name: name, Path: path, Bytes: size, Reclaimable: reclaimable})
+		}
+	}
+
+	if loc.BrewPrefix != "" {
+		for _, formula := range packages.Brew.Formulae {
+			name := formula[strings.LastIndex(formula, "/")+1:]
+			if current := currentFormulaVersion(loc.BrewPrefix, name); current != "" {
+				add(DiskUsageFormula, name, current, false)
+			}
+		}
+		for _, cask := range packages.Brew.Casks {
+			name := cask[strings.LastIndex(cask, "/")+1:]
+			add(DiskUsageCask, name, filepath.Join(loc.BrewPrefix, "Caskroom", name), false)
+		}
+		entries = append(entries, oldFormulaVersions(loc.BrewPrefix)...)
+	}
+	if loc.NpmRoot != "" {
+		for _, pkg := range packages.Npm.Packages {
+			name := npmPackageName(pkg)
+			add(DiskUsageNpm, name, filepath.Join(loc.NpmRoot, filepath.FromSlash(name)), false)
+		}
+	}
+	if loc.BrewCache != "" {
+		add(DiskUsageCache, "brew", loc.BrewCache, true)
+	}
+	if loc.NpmCache != "" {
+		add(DiskUsageCache, "npm", filepath.Join(loc.NpmCache, "_cacache"), true)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Bytes > entries[j].Bytes })
+	report := DiskUsageReport{Entries: entries}
+	if report.Entries == nil {
+		report.Entries = []DiskUsageEntry{}
+	}
+	for _, e := range entries {
+		report.TotalBytes += e.Bytes
+		if e.Reclaimable {
+			report.ReclaimableBytes += e.Bytes
+		}
+	}
+	return report
+}
+
+// ReclaimableBytes measures what cleanup --caches would remove.
+func ReclaimableBytes(loc DiskLocations) int64 {
+	return AnalyzeDiskUsage(loc, config.PackageSet{}).ReclaimableBytes
+}
+
+// CacheCleanupCommands returns the commands that remove old versions and
+// caches. Both only delete what the package managers can download again.
+func CacheCleanupCommands(loc DiskLocations) [][]string {
+	var commands [][]string
+	if loc.BrewPrefix != "" {
+		commands = append(commands, []string{"brew", "cleanup", "--prune=all", "-s"})
+	}
+	if loc.NpmCache != "" {
+		commands = append(commands, []string{"npm", "cache", "clean", "--force"})
+	}
+	return commands
+}
+
+// currentFormulaVersion returns the Cellar directory of the version of
+// formula linked in opt, or of the only version installed.
+func currentFormulaVersion(prefix, formula string) string {
+	cellar := filepath.Join(prefix, "Cellar", formula)
+	if target, err := os.Readlink(filepath.Join(prefix, "opt", formula)); err == nil {
+		return filepath.Join(cellar, filepath.Base(target))
+	}
+	versions := formulaVersions(cellar)
+	if len(versions) == 1 {
+		return filepath.Join(cellar, versions[0])
+	}
+	return ""
+}
+
+// oldFormulaVersions measures the versions of each formula in the Cellar
+// other than the one linked in opt.
+func oldFormulaVersions(prefix string) []DiskUsageEntry {
+	formulae, err := os.ReadDir(filepath.Join(prefix, "Cellar"))
+	if err != nil {
+		return nil
+	}
+
+	var entries []DiskUsageEntry
+	for _, formula := range formulae {
+		target, err := os.Readlink(filepath.Join(prefix, "opt", formula.Name()))
+		if err != nil {
+			// Without a link the current version is unknown.
+			continue
+		}
+		cellar := filepath.Join(prefix, "Cellar", formula.Name())
+		var size int64
+		for _, version := range formulaVersions(cellar) {
+			if version != filepath.Base(target) {
+				size += dirSize(filepath.Join(cellar, version))
+			}
+		}
+		if size > 0 {
+			entries = append(entries, DiskUsageEntry{
+				Kind:        DiskUsageOldVersions,
+				Name:        formula.Name(),
+				Path:        cellar,
+				Bytes:       size,
+				Reclaimable: true,
+			})
+		}
+	}
+	return entries
+}
+
+func formulaVersions(cellar string) []string {
+	dirs, err := os.ReadDir(cellar)
+	if err != nil {
+		return nil
+	}
+	var versions []string
+	for _, dir := range dirs {
+		if dir.IsDir() {
+			versions = append(versions, dir.Name())
+		}
+	}
+	return versions
+}
+
+// npmPackageName strips the version from a package spec such as
+// "@scope/name@1.0" or "pnpm@10".
+func npmPackageName(spec string) string {
+	if i := strings.LastIndex(spec, "@"); i > 0 {
+		return spec[:i]
+	}
+	return spec
+}
+
+// dirSize returns the total size of the regular files under path, without
+// following symlinks, or 0 when it does not exist.
+func dirSize(path string) int64 {
+	var size int64
+	_ = filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Skip what cannot be read rather than failing the whole walk.
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
@@ -0,0 +1,107 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSized(t *testing.T, path string, size int) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, make([]byte, size), 0o644))
+}
+
+// testDiskLayout creates a brew prefix with git 2.46 linked and 2.45 left
+// behind, a cask, brew and npm caches and a global npm package.
+func testDiskLayout(t *testing.T) DiskLocations {
+	t.Helper()
+	root := t.TempDir()
+	loc := DiskLocations{
+		BrewPrefix: filepath.Join(root, "brew"),
+		BrewCache:  filepath.Join(root, "brew-cache"),
+		NpmRoot:    filepath.Join(root, "npm", "lib"),
+		NpmCache:   filepath.Join(root, "npm-cache"),
+	}
+
+	writeSized(t, filepath.Join(loc.BrewPrefix, "Cellar", "git", "2.46.0", "bin", "git"), 3000)
+	writeSized(t, filepath.Join(loc.BrewPrefix, "Cellar", "git", "2.45.0", "bin", "git"), 2000)
+	require.NoError(t, os.MkdirAll(filepath.Join(loc.BrewPrefix, "opt"), 0o755))
+	require.NoError(t, os.Symlink("../Cellar/git/2.46.0", filepath.Join(loc.BrewPrefix, "opt", "git")))
+	writeSized(t, filepath.Join(loc.BrewPrefix, "Cellar", "jq", "1.7.1", "bin", "jq"), 500)
+	writeSized(t, filepath.Join(loc.BrewPrefix, "Caskroom", "wezterm", "2024", "WezTerm.app"), 4000)
+	writeSized(t, filepath.Join(loc.BrewCache, "downloads", "git.tar.gz"), 1500)
+	writeSized(t, filepath.Join(loc.NpmRoot, "@biomejs", "biome", "package.json"), 700)
+	writeSized(t, filepath.Join(loc.NpmCache, "_cacache", "index"), 800)
+	return loc
+}
+
+func TestAnalyzeDiskUsage(t *testing.T) {
+	t.Parallel()
+
+	loc := testDiskLayout(t)
+	var packages config.PackageSet
+	packages.Brew.Formulae = []string{"git", "jq", "not-installed"}
+	packages.Brew.Casks = []string{"homebrew/cask/wezterm"}
+	packages.Npm.Packages = []string{"@biomejs/biome@1.9"}
+
+	report := AnalyzeDiskUsage(loc, packages)
+
+	got := make(map[string]int64)
+	for _, e := range report.Entries {
+		got[e.Kind+":"+e.Name] = e.Bytes
+	}
+	assert.Equal(t, map[string]int64{
+		"cask:wezterm":       4000,
+		"formula:git":        3000,
+		"old_versions:git":   2000,
+		"cache:brew":         1500,
+		"cache:npm":          800,
+		"npm:@biomejs/biome": 700,
+		"formula:jq":         500,
+	}, got)
+	assert.Equal(t, "cask", report.Entries[0].Kind, "largest first")
+	assert.Equal(t, int64(12500), report.TotalBytes)
+	assert.Equal(t, int64(4300), report.ReclaimableBytes)
+	assert.Equal(t, int64(4300), ReclaimableBytes(loc))
+}
+
+func TestAnalyzeDiskUsage_NoPackageManagers(t *testing.T) {
+	t.Parallel()
+
+	report := AnalyzeDiskUsage(DiskLocations{}, config.PackageSet{})
+	assert.Empty(t, report.Entries)
+	assert.NotNil(t, report.Entries)
+	assert.Empty(t, CacheCleanupCommands(DiskLocations{}))
+}
+
+func TestFindDiskLocations(t *testing.T) {
+	t.Parallel()
+
+	loc := FindDiskLocations(func(name string, args ...string) (string, error) {
+		switch name + " " + strings.Join(args, " ") {
+		case "brew --prefix":
+			return "/opt/homebrew\n", nil
+		case "brew --cache":
+			return "/Users/me/Library/Caches/Homebrew\n", nil
+		}
+		return "", errors.New("not found")
+	})
+	assert.Equal(t, DiskLocations{BrewPrefix: "/opt/homebrew", BrewCache: "/Users/me/Library/Caches/Homebrew"}, loc)
+	assert.Equal(t, [][]string{{"brew", "cleanup", "--prune=all", "-s"}}, CacheCleanupCommands(loc))
+}
+
+func TestNpmPackageName(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "pnpm", npmPackageName("pnpm@10.0"))
+	assert.Equal(t, "@scope/tool", npmPackageName("@scope/tool@1.2"))
+	assert.Equal(t, "@scope/tool", npmPackageName("@scope/tool"))
+	assert.Equal(t, "typescript", npmPackageName("typescript"))
+}
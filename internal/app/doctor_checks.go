@@ -0,0 +1,100 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// doctorCheck is a doctor check that runs alongside the others. It records
+// its findings in the report it is given.
+type doctorCheck struct {
+	name string
+	run  func(ctx context.Context, report *DoctorReport)
+}
+
+// runDoctorChecks runs up to parallelism checks at once, each into a report
+// of its own, and merges the findings into report in the order of checks.
+// A check still running after timeout is abandoned and reported as timed
+// out instead of holding up the whole run.
+func runDoctorChecks(ctx context.Context, checks []doctorCheck, parallelism int, timeout time.Duration, report *DoctorReport) {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	results := make([]*DoctorReport, len(checks))
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check doctorCheck) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = runDoctorCheck(ctx, check, timeout)
+		}(i, check)
+	}
+	wg.Wait()
+
+	for i, result := range results {
+		if result == nil {
+			report.Issues = append(report.Issues, timeoutIssue(checks[i].name, timeout, "check did not finish"))
+			report.TimedOut = append(report.TimedOut, checks[i].name)
+			continue
+		}
+		report.Issues = append(report.Issues, result.Issues...)
+		report.BinaryChecks = append(report.BinaryChecks, result.BinaryChecks...)
+	}
+}
+
+// runDoctorCheck runs check and returns its findings, or nil when it did not
+// finish within timeout.
+func runDoctorCheck(ctx context.Context, check doctorCheck, timeout time.Duration) *DoctorReport {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	done := make(chan *DoctorReport, 1)
+	go func() {
+		result := &DoctorReport{}
+		check.run(ctx, result)
+		done <- result
+	}()
+
+	select {
+	case result := <-done:
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil
+		}
+		return result
+	case <-ctx.Done():
+		return nil
+	}
+}
+
+// timeoutIssue reports checks of name that were abandoned after timeout.
+func timeoutIssue(name string, timeout time.Duration, detail string) DoctorIssue {
+	return DoctorIssue{
+		Provider:   name,
+		StepID:     name,
+		Severity:   SeverityWarning,
+		Message:    fmt.Sprintf("Checks timed out after %s", timeout),
+		Expected:   "checks finish in time",
+		Actual:     detail,
+		Fixable:    false,
+		FixCommand: "preflight doctor --check-timeout " + (2 * timeout).String(),
+	}
+}
+
+// commandOutput returns a command runner for doctor checks that stops
+// commands when ctx is done.
+func commandOutput(ctx context.Context) func(string, ...string) (string, error) {
+	return func(name string, args ...string) (string, error) {
+		out, err := exec.CommandContext(ctx, name, args...).Output()
+		return string(out), err
+	}
+}
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunDoctorChecks_MergesInOrderAndReportsTimeouts(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	defer close(release)
+
+	issue := func(name string) func(context.Context, *DoctorReport) {
+		return func(_ context.Context, r *DoctorReport) {
+			r.Issues = append(r.Issues, DoctorIssue{Provider: name, Message: name + " issue"})
+		}
+	}
+	checks := []doctorCheck{
+		{"slow", func(ctx context.Context, r *DoctorReport) {
+			<-ctx.Done()
+			r.Issues = append(r.Issues, DoctorIssue{Provider: "slow", Message: "late"})
+		}},
+		{"hung", func(_ context.Context, _ *DoctorReport) { <-release }},
+		{"kube", issue("kube")},
+		{"cloud", issue("cloud")},
+	}
+
+	report := &DoctorReport{}
+	started := time.Now()
+	runDoctorChecks(context.Background(), checks, 2, 50*time.Millisecond, report)
+	assert.Less(t, time.Since(started), time.Second)
+
+	require.Len(t, report.Issues, 4)
+	assert.Equal(t, []string{"slow", "hung"}, report.TimedOut)
+	assert.Equal(t, "Checks timed out after 50ms", report.Issues[0].Message)
+	assert.Equal(t, "hung", report.Issues[1].Provider)
+	assert.Equal(t, "kube issue", report.Issues[2].Message)
+	assert.Equal(t, "cloud issue", report.Issues[3].Message)
+}
+
+func TestRunDoctorChecks_NoTimeout(t *testing.T) {
+	t.Parallel()
+
+	report := &DoctorReport{}
+	runDoctorChecks(context.Background(), []doctorCheck{
+		{"path", func(_ context.Context, r *DoctorReport) {
+			r.BinaryChecks = append(r.BinaryChecks, BinaryCheckResult{Name: "nvim", Found: true})
+		}},
+	}, 0, 0, report)
+
+	assert.Empty(t, report.TimedOut)
+	assert.Len(t, report.BinaryChecks, 1)
+}
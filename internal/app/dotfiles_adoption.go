@@ -0,0 +1,172 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/domain/discover"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+)
+
+// adoptedDotfilesDir is where configs adopted from outside the config
+// directory are copied, relative to the config directory.
+const adoptedDotfilesDir = "dotfiles"
+
+// AdoptedConfig is a tool config preflight takes over from a dotfiles
+// directory.
+type AdoptedConfig struct {
+	discover.Adoption
+	// From is the absolute path of the config in the scanned directory.
+	From string
+	// Src is the path of the config the layer links, relative to the
+	// config directory. It differs from From when the config is copied
+	// into the config directory.
+	Src string
+	// Layer is the layer that manages the config.
+	Layer string
+}
+
+// Copied reports whether adopting copies the config into the config
+// directory.
+func (a AdoptedConfig) Copied(configDir string) bool {
+	return filepath.Join(configDir, a.Src) != a.From
+}
+
+// DotfileAdoption is what adopting a dotfiles directory changes.
+type DotfileAdoption struct {
+	Dir       string
+	ConfigDir string
+	Configs   []AdoptedConfig
+	Updates   []LayerUpdate
+	// Skipped explains the tool configs that are not adopted.
+	Skipped []string
+}
+
+// PlanDotfileAdoption scans dir, a dotfiles repository or ~/.config, for
+// the configs of tools preflight recognizes, and returns a new layer for
+// each that links the config where the tool reads it. Configs outside the
+// config directory are copied into its dotfiles directory on adoption.
+func PlanDotfileAdoption(configPath, target, dir string) (*DotfileAdoption, error) {
+	configPath, layers, err := loadTargetLayers(configPath, target)
+	if err != nil {
+		return nil, err
+	}
+	dir, err = filepath.Abs(ports.ExpandPath(dir))
+	if err != nil {
+		return nil, err
+	}
+	files, err := discover.ListFiles(os.DirFS(dir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", dir, err)
+	}
+	return planDotfileAdoption(configPath, target, dir, layers, discover.PlanAdoptions(files)), nil
+}
+
+func planDotfileAdoption(configPath, target, dir string, layers []config.Layer, found []discover.Adoption) *DotfileAdoption {
+	configDir := filepath.Dir(configPath)
+	layersDir := filepath.Join(configDir, "layers")
+	adoption := &DotfileAdoption{Dir: dir, ConfigDir: configDir}
+
+	managed := make(map[string]string)
+	for i := range layers {
+		for _, file := range layers[i].Files {
+			managed[ports.ExpandPath(file.Path)] = layers[i].Name.String()
+		}
+	}
+
+	for _, a := range found {
+		if layer, ok := managed[ports.ExpandPath(a.Dest)]; ok {
+			adoption.Skipped = append(adoption.Skipped, fmt.Sprintf("%s: %s is managed by layer %s", a.Tool, a.Dest, layer))
+			continue
+		}
+		path := filepath.Join(layersDir, a.Tool+".yaml")
+		if _, err := os.Stat(path); err == nil {
+			adoption.Skipped = append(adoption.Skipped, fmt.Sprintf("%s: layer %s exists", a.Tool, a.Tool))
+			continue
+		}
+
+		adopted := AdoptedConfig{Adoption: a, From: filepath.Join(dir, filepath.FromSlash(a.Source)), Layer: a.Tool}
+		if rel, err := filepath.Rel(configDir, adopted.From); err == nil && !strings.HasPrefix(rel, "..") {
+			adopted.Src = filepath.ToSlash(rel)
+		} else {
+			adopted.Src = adoptedDotfilesDir + "/" + a.Source
+		}
+		adoption.Configs = append(adoption.Configs, adopted)
+
+		declaration := config.FileDeclaration{Path: a.Dest, Mode: config.FileModeBYO, Template: adopted.Src}
+		adoption.Updates = append(adoption.Updates, LayerUpdate{
+			Layer: a.Tool,
+			Path:  path,
+			// ApplyLayerUpdate names what the update tracks by Packages
+			Packages: []string{a.Dest},
+			Patches: []ConfigPatch{
+				NewConfigPatch(path, "name", PatchOpAdd, nil, a.Tool, "discover"),
+				NewConfigPatch(path, "files", PatchOpAdd, nil, []config.FileDeclaration{declaration}, "discover"),
+				NewConfigPatch(configPath, "targets."+target, PatchOpAdd, nil, a.Tool, "discover"),
+			},
+			Creates: true,
+		})
+	}
+	return adoption
+}
+
+// AdoptDotfiles copies the adopted configs into the config directory
+// where needed and writes their layers, committing each layer on its own
+// when the config directory is a git repository.
+func AdoptDotfiles(ctx context.Context, adoption *DotfileAdoption) error {
+	for i, adopted := range adoption.Configs {
+		if adopted.Copied(adoption.ConfigDir) {
+			dest := filepath.Join(adoption.ConfigDir, filepath.FromSlash(adopted.Src))
+			if err := copyTree(adopted.From, dest); err != nil {
+				return fmt.Errorf("failed to copy %s config: %w", adopted.Tool, err)
+			}
+		}
+		if _, err := ApplyLayerUpdate(ctx, adoption.Updates[i]); err != nil {
+			return fmt.Errorf("failed to create layer %s: %w", adopted.Layer, err)
+		}
+	}
+	return nil
+}
+
+// copyTree copies the file or directory at src to dest, keeping modes.
+// Symlinks to files are copied as the files they point to; symlinks to
+// directories are left out.
+func copyTree(src, dest string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, rel)
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(target, 0o755)
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		// #nosec G304 -- path is in the dotfiles directory being adopted.
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, info.Mode().Perm())
+	})
+}
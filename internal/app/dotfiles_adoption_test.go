@@ -0,0 +1,85 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanDotfileAdoption(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	configDir := filepath.Join(root, "config")
+	configPath := filepath.Join(configDir, "preflight.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Join(configDir, "layers"), 0o755))
+	require.NoError(t, os.WriteFile(configPath, []byte("targets:\n  default: [base]\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "layers", "base.yaml"), []byte("name: base\nfiles:\n  - path: ~/.config/starship.toml\n    mode: byo\n    template: dotfiles/starship.toml\n"), 0o644))
+
+	dotfiles := filepath.Join(root, "dotfiles")
+	for path, content := range map[string]string{
+		".tmux.conf":            "set -g mouse on\n",
+		".config/nvim/init.lua": "vim.o.number = true\n",
+		".config/starship.toml": "add_newline = false\n",
+		".git/config":           "[core]\n",
+	} {
+		full := filepath.Join(dotfiles, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0o755))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0o644))
+	}
+
+	adoption, err := PlanDotfileAdoption(configPath, "default", dotfiles)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"starship: ~/.config/starship.toml is managed by layer base"}, adoption.Skipped)
+	require.Len(t, adoption.Configs, 2)
+
+	tmux := adoption.Configs[0]
+	assert.Equal(t, "tmux", tmux.Layer)
+	assert.Equal(t, "dotfiles/.tmux.conf", tmux.Src)
+	assert.True(t, tmux.Copied(configDir), "the repository is outside the config directory")
+
+	require.NoError(t, AdoptDotfiles(context.Background(), adoption))
+
+	data, err := os.ReadFile(filepath.Join(configDir, "dotfiles", ".config", "nvim", "init.lua"))
+	require.NoError(t, err)
+	assert.Equal(t, "vim.o.number = true\n", string(data))
+
+	data, err = os.ReadFile(filepath.Join(configDir, "layers", "nvim.yaml"))
+	require.NoError(t, err)
+	layer, err := config.ParseLayer(data)
+	require.NoError(t, err)
+	assert.Equal(t, []config.FileDeclaration{
+		{Path: "~/.config/nvim", Mode: config.FileModeBYO, Template: "dotfiles/.config/nvim"},
+	}, layer.Files)
+
+	data, err = os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "tmux")
+	assert.Contains(t, string(data), "nvim")
+
+	// Adopting again finds the layers it created
+	adoption, err = PlanDotfileAdoption(configPath, "default", dotfiles)
+	require.NoError(t, err)
+	assert.Empty(t, adoption.Configs)
+}
+
+func TestPlanDotfileAdoption_InsideConfigDir(t *testing.T) {
+	t.Parallel()
+
+	configDir := t.TempDir()
+	configPath := filepath.Join(configDir, "preflight.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("targets:\n  default: []\n"), 0o644))
+
+	require.NoError(t, os.MkdirAll(filepath.Join(configDir, "dotfiles", "tmux"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "dotfiles", "tmux", "tmux.conf"), []byte(""), 0o644))
+	adoption, err := PlanDotfileAdoption(configPath, "default", filepath.Join(configDir, "dotfiles"))
+	require.NoError(t, err)
+	require.Len(t, adoption.Configs, 1)
+	assert.Equal(t, "dotfiles/tmux", adoption.Configs[0].Src)
+	assert.False(t, adoption.Configs[0].Copied(configDir), "configs in the config directory are linked in place")
+}
@@ -2,12 +2,17 @@ package app
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"os"
 	"path/filepath"
 	"time"
 
 	"github.com/felixgeelhaar/preflight/internal/adapters/filesystem"
 	"github.com/felixgeelhaar/preflight/internal/domain/drift"
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
+	"github.com/felixgeelhaar/preflight/internal/securestate"
 )
 
 // DriftService provides high-level drift detection operations.
@@ -20,7 +25,7 @@ type DriftService struct {
 // NewDriftService creates a new DriftService.
 func NewDriftService(baseDir string) *DriftService {
 	statePath := filepath.Join(baseDir, "state.json")
-	store := drift.NewStateStore(statePath)
+	store := drift.NewStateStore(statePath).WithFileIO(securestate.ReadFile, securestate.WriteFile)
 
 	return &DriftService{
 		store:   store,
@@ -31,11 +36,10 @@ func NewDriftService(baseDir string) *DriftService {
 
 // DefaultDriftService creates a DriftService using the default preflight directory.
 func DefaultDriftService() (*DriftService, error) {
-	home, err := os.UserHomeDir()
+	baseDir, err := platform.Dir(platform.StateDir)
 	if err != nil {
 		return nil, err
 	}
-	baseDir := filepath.Join(home, ".preflight")
 	return NewDriftService(baseDir), nil
 }
 
@@ -84,9 +88,44 @@ func (s *DriftService) CheckPaths(ctx context.Context, paths []string) ([]drift.
 
 // RemoveTracking removes a file from drift tracking.
 func (s *DriftService) RemoveTracking(ctx context.Context, path string) error {
+	if err := os.Remove(s.contentPath(path)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove applied content: %w", err)
+	}
 	return s.store.RemoveFile(ctx, path)
 }
 
+// RecordAppliedContent keeps content as the config's version of path, the
+// base for merging local edits with later config changes.
+func (s *DriftService) RecordAppliedContent(_ context.Context, path string, content []byte) error {
+	dest := s.contentPath(path)
+	if err := os.MkdirAll(filepath.Dir(dest), 0o700); err != nil {
+		return fmt.Errorf("failed to create applied content directory: %w", err)
+	}
+	if err := os.WriteFile(dest, content, 0o600); err != nil {
+		return fmt.Errorf("failed to record applied content: %w", err)
+	}
+	return nil
+}
+
+// AppliedContent returns the content recorded for path by
+// RecordAppliedContent, and false when none was recorded.
+func (s *DriftService) AppliedContent(_ context.Context, path string) ([]byte, bool, error) {
+	data, err := os.ReadFile(s.contentPath(path))
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read applied content: %w", err)
+	}
+	return data, true, nil
+}
+
+// contentPath returns where the applied content of path is kept.
+func (s *DriftService) contentPath(path string) string {
+	sum := sha256.Sum256([]byte(path))
+	return filepath.Join(s.baseDir, "applied", hex.EncodeToString(sum[:16]))
+}
+
 // ListTrackedFiles returns all files being tracked for drift.
 func (s *DriftService) ListTrackedFiles(ctx context.Context) ([]drift.FileState, error) {
 	state, err := s.store.Load(ctx)
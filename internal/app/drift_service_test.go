@@ -5,7 +5,13 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/felixgeelhaar/preflight/internal/adapters/age"
+	"github.com/felixgeelhaar/preflight/internal/adapters/keychain"
+	"github.com/felixgeelhaar/preflight/internal/domain/drift"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/securestate"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -22,16 +28,17 @@ func TestNewDriftService(t *testing.T) {
 }
 
 func TestDefaultDriftService(t *testing.T) {
-	t.Parallel()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_STATE_HOME", "")
 
 	service, err := DefaultDriftService()
 
 	require.NoError(t, err)
 	assert.NotNil(t, service)
 
-	// Should use ~/.preflight as base
-	home, _ := os.UserHomeDir()
-	expectedBase := filepath.Join(home, ".preflight")
+	// Should use the XDG state directory as base
+	expectedBase := filepath.Join(home, ".local", "state", "preflight")
 	assert.Equal(t, expectedBase, service.baseDir)
 }
 
@@ -280,3 +287,53 @@ func TestDriftService_ListTrackedFiles_Empty(t *testing.T) {
 	require.NoError(t, err)
 	assert.Empty(t, files)
 }
+
+func TestDriftService_AppliedContent(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	service := NewDriftService(tmpDir)
+	ctx := context.Background()
+	testFile := filepath.Join(tmpDir, "gitconfig")
+
+	_, ok, err := service.AppliedContent(ctx, testFile)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, service.RecordAppliedContent(ctx, testFile, []byte("[user]\n")))
+	content, ok, err := service.AppliedContent(ctx, testFile)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "[user]\n", string(content))
+
+	require.NoError(t, service.RemoveTracking(ctx, testFile))
+	_, ok, err = service.AppliedContent(ctx, testFile)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestDriftService_EncryptedState(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("PREFLIGHT_HOME", home)
+	kc := keychain.NewFileKeychain(filepath.Join(t.TempDir(), "keychain.json"))
+	oldKeychain := securestate.Keychain
+	securestate.Keychain = func() ports.Keychain { return kc }
+	defer func() { securestate.Keychain = oldKeychain }()
+	_, err := securestate.Enable(time.Now())
+	require.NoError(t, err)
+
+	service, err := DefaultDriftService()
+	require.NoError(t, err)
+	tracked := filepath.Join(t.TempDir(), "zshrc")
+	require.NoError(t, os.WriteFile(tracked, []byte("export EDITOR=vim\n"), 0o600))
+	require.NoError(t, service.RecordApplied(context.Background(), tracked, "base"))
+
+	data, err := os.ReadFile(filepath.Join(home, "state.json"))
+	require.NoError(t, err)
+	assert.True(t, age.IsEncrypted(data))
+	assert.NotContains(t, string(data), tracked)
+
+	result, err := service.CheckDrift(context.Background(), tracked)
+	require.NoError(t, err)
+	assert.Equal(t, drift.TypeNone, result.Type, "the encrypted state is read back")
+}
@@ -0,0 +1,232 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+)
+
+// ErrNoFileBackup is returned when a file has no backup.
+var ErrNoFileBackup = errors.New("no backup of file")
+
+// backupTimeFormat names the directory of the backups an apply took.
+const backupTimeFormat = "20060102T150405Z"
+
+// FileBackup is a copy of a file preflight did not manage, taken before an
+// apply first changed it. Unlike snapshots, backups are never pruned.
+type FileBackup struct {
+	StepID    string    `json:"step,omitempty"`
+	Path      string    `json:"path"`
+	Backup    string    `json:"backup"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Taken returns when the backup was taken, as it names the backup.
+func (b FileBackup) Taken() string {
+	return b.CreatedAt.UTC().Format(backupTimeFormat)
+}
+
+// BackupStore keeps file backups in a directory, one subdirectory per
+// apply, mirroring the absolute paths of the files.
+type BackupStore struct {
+	dir string
+}
+
+// NewBackupStore creates a backup store in dir.
+func NewBackupStore(dir string) *BackupStore {
+	return &BackupStore{dir: dir}
+}
+
+// DefaultBackupStore returns the backup store in backups in the state
+// directory.
+func DefaultBackupStore() (*BackupStore, error) {
+	dir, err := platform.Path(platform.StateDir, "backups")
+	if err != nil {
+		return nil, err
+	}
+	return NewBackupStore(dir), nil
+}
+
+// Save copies the file at path into the backups taken at.
+func (s *BackupStore) Save(path string, at time.Time) (FileBackup, error) {
+	// #nosec G304 -- path is a file the apply is about to change.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return FileBackup{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return FileBackup{}, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+
+	at = at.UTC().Truncate(time.Second)
+	backup := s.backupPath(at, path)
+	if err := os.MkdirAll(filepath.Dir(backup), 0o700); err != nil {
+		return FileBackup{}, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	if err := os.WriteFile(backup, data, info.Mode().Perm()); err != nil {
+		return FileBackup{}, fmt.Errorf("failed to back up %s: %w", path, err)
+	}
+	return FileBackup{Path: path, Backup: backup, CreatedAt: at}, nil
+}
+
+// Remove deletes a backup that turned out not to be needed.
+func (s *BackupStore) Remove(backup FileBackup) error {
+	if err := os.Remove(backup.Backup); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove backup %s: %w", backup.Backup, err)
+	}
+	// Drop the directories left empty, up to the backup directory
+	for dir := filepath.Dir(backup.Backup); dir != s.dir && len(dir) > len(s.dir); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+	return nil
+}
+
+// List returns the backups of the file at path, newest first.
+func (s *BackupStore) List(path string) ([]FileBackup, error) {
+	entries, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	var backups []FileBackup
+	for _, entry := range entries {
+		at, err := time.Parse(backupTimeFormat, entry.Name())
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+		backup := s.backupPath(at, path)
+		if info, err := os.Stat(backup); err == nil && info.Mode().IsRegular() {
+			backups = append(backups, FileBackup{Path: path, Backup: backup, CreatedAt: at})
+		}
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+	return backups, nil
+}
+
+// Find returns the backup of the file at path taken at the time taken, in
+// the form of Taken, or the newest backup when taken is empty.
+func (s *BackupStore) Find(path, taken string) (FileBackup, error) {
+	backups, err := s.List(path)
+	if err != nil {
+		return FileBackup{}, err
+	}
+	for _, backup := range backups {
+		if taken == "" || backup.Taken() == taken {
+			return backup, nil
+		}
+	}
+	return FileBackup{}, fmt.Errorf("%w %s", ErrNoFileBackup, path)
+}
+
+// Restore copies a backup back to its file.
+func (s *BackupStore) Restore(backup FileBackup) error {
+	// #nosec G304 -- backup is a file in the backup directory.
+	data, err := os.ReadFile(backup.Backup)
+	if err != nil {
+		return fmt.Errorf("failed to read backup: %w", err)
+	}
+	mode := fs.FileMode(0o644)
+	if info, err := os.Stat(backup.Backup); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.MkdirAll(filepath.Dir(backup.Path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(backup.Path, data, mode); err != nil {
+		return fmt.Errorf("failed to restore %s: %w", backup.Path, err)
+	}
+	return nil
+}
+
+// backupPath returns where the backup of path taken at is kept.
+func (s *BackupStore) backupPath(at time.Time, path string) string {
+	return filepath.Join(s.dir, at.UTC().Format(backupTimeFormat), filepath.Clean(path))
+}
+
+// backupUnmanagedFiles backs up the files the plan is about to change that
+// exist but were never applied by preflight, such as dotfiles from before
+// preflight was adopted. Failures only warn: the apply proceeds.
+func (p *Preflight) backupUnmanagedFiles(ctx context.Context, capture *transcriptCapture) map[string]FileBackup {
+	if p.lifecycle == nil || p.backups == nil || len(capture.files) == 0 {
+		return nil
+	}
+	tracked, err := p.lifecycle.Drift().ListTrackedFiles(ctx)
+	if err != nil {
+		p.logger.Warn(ctx, "could not list managed files", ports.F("error", err))
+		return nil
+	}
+	managed := make(map[string]bool, len(tracked))
+	for _, file := range tracked {
+		managed[file.Path] = true
+	}
+
+	now := time.Now()
+	backups := make(map[string]FileBackup)
+	saved := make(map[string]FileBackup)
+	for stepID, state := range capture.files {
+		if !state.exists || managed[state.path] {
+			continue
+		}
+		backup, ok := saved[state.path]
+		if !ok {
+			if backup, err = p.backups.Save(state.path, now); err != nil {
+				p.logger.Warn(ctx, "could not back up file before apply", ports.F("path", state.path), ports.F("error", err))
+				continue
+			}
+			saved[state.path] = backup
+		}
+		backup.StepID = stepID
+		backups[stepID] = backup
+	}
+	return backups
+}
+
+// keepChangedBackups returns the backups of the files the transcript shows
+// changed and removes the others.
+func (p *Preflight) keepChangedBackups(ctx context.Context, backups map[string]FileBackup, transcript *Transcript) []FileBackup {
+	changed := make(map[string]bool, len(transcript.Files))
+	for _, file := range transcript.Files {
+		changed[file.Path] = true
+	}
+
+	stepIDs := make([]string, 0, len(backups))
+	for stepID := range backups {
+		stepIDs = append(stepIDs, stepID)
+	}
+	sort.Strings(stepIDs)
+
+	var kept []FileBackup
+	seen := make(map[string]bool, len(backups))
+	for _, stepID := range stepIDs {
+		backup := backups[stepID]
+		if seen[backup.Path] {
+			continue
+		}
+		seen[backup.Path] = true
+		if changed[backup.Path] {
+			kept = append(kept, backup)
+			continue
+		}
+		if err := p.backups.Remove(backup); err != nil {
+			p.logger.Warn(ctx, "could not remove unneeded backup", ports.F("error", err))
+		}
+	}
+	sort.Slice(kept, func(i, j int) bool { return kept[i].Path < kept[j].Path })
+	return kept
+}
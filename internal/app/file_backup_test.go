@@ -0,0 +1,102 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/adapters/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupStore(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	store := NewBackupStore(filepath.Join(dir, "backups"))
+	path := filepath.Join(dir, "home", ".zshrc")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+
+	backups, err := store.List(path)
+	require.NoError(t, err)
+	assert.Empty(t, backups)
+	_, err = store.Find(path, "")
+	require.ErrorIs(t, err, ErrNoFileBackup)
+
+	first := time.Date(2026, 10, 16, 9, 15, 0, 0, time.UTC)
+	require.NoError(t, os.WriteFile(path, []byte("export EDITOR=vim\n"), 0o600))
+	older, err := store.Save(path, first)
+	require.NoError(t, err)
+	assert.Equal(t, "20261016T091500Z", older.Taken())
+
+	require.NoError(t, os.WriteFile(path, []byte("export EDITOR=nano\n"), 0o600))
+	newer, err := store.Save(path, first.Add(time.Hour))
+	require.NoError(t, err)
+
+	backups, err = store.List(path)
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+	assert.Equal(t, newer.Backup, backups[0].Backup, "newest first")
+
+	info, err := os.Stat(older.Backup)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), "mode is kept")
+
+	require.NoError(t, os.WriteFile(path, []byte("managed\n"), 0o644))
+	backup, err := store.Find(path, older.Taken())
+	require.NoError(t, err)
+	require.NoError(t, store.Restore(backup))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "export EDITOR=vim\n", string(data))
+
+	require.NoError(t, store.Remove(newer))
+	backups, err = store.List(path)
+	require.NoError(t, err)
+	assert.Len(t, backups, 1)
+	_, err = os.Stat(filepath.Join(dir, "backups", newer.Taken()))
+	assert.True(t, os.IsNotExist(err), "empty directories are removed")
+}
+
+func TestPreflight_BackupUnmanagedFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	lifecycle := NewLifecycleManager(NewSnapshotService(dir), NewDriftService(dir))
+	p := &Preflight{
+		lifecycle: lifecycle,
+		backups:   NewBackupStore(filepath.Join(dir, "backups")),
+		logger:    logging.Default(),
+	}
+	ctx := context.Background()
+
+	unmanaged := filepath.Join(dir, ".zshrc")
+	managed := filepath.Join(dir, ".gitconfig")
+	unchanged := filepath.Join(dir, ".vimrc")
+	for _, path := range []string{unmanaged, managed, unchanged} {
+		require.NoError(t, os.WriteFile(path, []byte("before\n"), 0o644))
+	}
+	require.NoError(t, lifecycle.Drift().RecordApplied(ctx, managed, "base"))
+
+	capture := &transcriptCapture{files: map[string]fileState{
+		"files:link:zshrc":     readFileState(unmanaged),
+		"files:copy:gitconfig": readFileState(managed),
+		"files:copy:vimrc":     readFileState(unchanged),
+		"files:copy:new":       readFileState(filepath.Join(dir, ".new")),
+	}}
+	backups := p.backupUnmanagedFiles(ctx, capture)
+	require.Len(t, backups, 2, "only existing files preflight did not manage")
+
+	transcript := &Transcript{Files: []FileDiff{{StepID: "files:link:zshrc", Path: unmanaged}}}
+	kept := p.keepChangedBackups(ctx, backups, transcript)
+	require.Len(t, kept, 1)
+	assert.Equal(t, unmanaged, kept[0].Path)
+	assert.Equal(t, "files:link:zshrc", kept[0].StepID)
+
+	_, err := p.backups.Find(unchanged, "")
+	assert.True(t, errors.Is(err, ErrNoFileBackup), "backups of unchanged files are removed")
+}
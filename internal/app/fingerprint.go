@@ -0,0 +1,117 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/sync"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+)
+
+// FingerprintSensor reads the hardware facts machine bindings match
+// against.
+type FingerprintSensor struct {
+	runner   ports.CommandRunner
+	goos     string
+	hostname func() (string, error)
+	readFile func(string) ([]byte, error)
+}
+
+// NewFingerprintSensor creates a FingerprintSensor for the current OS.
+func NewFingerprintSensor(runner ports.CommandRunner) *FingerprintSensor {
+	return &FingerprintSensor{
+		runner:   runner,
+		goos:     runtime.GOOS,
+		hostname: os.Hostname,
+		readFile: os.ReadFile,
+	}
+}
+
+// Sense returns the fingerprint of this machine. Anything that cannot be
+// detected, such as the serial number without root on Linux, is left
+// empty.
+func (s *FingerprintSensor) Sense(ctx context.Context) sync.Fingerprint {
+	var fp sync.Fingerprint
+	fp.Hostname, _ = s.hostname()
+
+	switch s.goos {
+	case "darwin":
+		if out, ok := s.run(ctx, "sysctl", "-n", "machdep.cpu.brand_string"); ok {
+			fp.Chip = strings.TrimSpace(out)
+		}
+		if out, ok := s.run(ctx, "sysctl", "-n", "hw.memsize"); ok {
+			fp.MemoryBytes, _ = strconv.ParseUint(strings.TrimSpace(out), 10, 64)
+		}
+		if out, ok := s.run(ctx, "ioreg", "-c", "IOPlatformExpertDevice", "-d", "2"); ok {
+			for _, line := range strings.Split(out, "\n") {
+				if _, value, found := strings.Cut(line, `"IOPlatformSerialNumber" = `); found {
+					fp.Serial = strings.Trim(strings.TrimSpace(value), `"`)
+					break
+				}
+			}
+		}
+	case "linux":
+		if data, err := s.readFile("/proc/cpuinfo"); err == nil {
+			fp.Chip = procField(string(data), "model name")
+		}
+		if data, err := s.readFile("/proc/meminfo"); err == nil {
+			// MemTotal is in kB
+			kb, _ := strconv.ParseUint(strings.TrimSuffix(procField(string(data), "MemTotal"), " kB"), 10, 64)
+			fp.MemoryBytes = kb * 1024
+		}
+		if data, err := s.readFile("/sys/class/dmi/id/product_serial"); err == nil {
+			fp.Serial = strings.TrimSpace(string(data))
+		}
+	}
+	return fp
+}
+
+func (s *FingerprintSensor) run(ctx context.Context, name string, args ...string) (string, bool) {
+	result, err := s.runner.Run(ctx, name, args...)
+	if err != nil || !result.Success() {
+		return "", false
+	}
+	return result.Stdout, true
+}
+
+// procField returns the value of the first "key: value" line of a /proc
+// file with the given key.
+func procField(content, key string) string {
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		name, value, found := strings.Cut(scanner.Text(), ":")
+		if found && strings.TrimSpace(name) == key {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// RecordFingerprint senses this machine's fingerprint and records it in
+// ~/.preflight/fingerprint.json for machine bindings to match against.
+func RecordFingerprint(ctx context.Context, sensor *FingerprintSensor) (sync.Fingerprint, error) {
+	fp := sensor.Sense(ctx)
+	return fp, sync.SaveFingerprint(sync.DefaultFingerprintPath(), fp)
+}
+
+// MachineFingerprint returns the recorded fingerprint of this machine,
+// recording it first when init has not. The hostname is read live, since
+// it can change after the fingerprint was recorded.
+func MachineFingerprint(ctx context.Context, sensor *FingerprintSensor) (sync.Fingerprint, error) {
+	fp, err := sync.LoadFingerprint(sync.DefaultFingerprintPath())
+	if errors.Is(err, sync.ErrFingerprintNotFound) {
+		return RecordFingerprint(ctx, sensor)
+	}
+	if err != nil {
+		return sync.Fingerprint{}, err
+	}
+	if hostname, err := sensor.hostname(); err == nil {
+		fp.Hostname = hostname
+	}
+	return fp, nil
+}
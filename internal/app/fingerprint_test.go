@@ -0,0 +1,58 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/sync"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/testutil/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestFingerprintSensor(goos string, runner ports.CommandRunner, files map[string]string) *FingerprintSensor {
+	return &FingerprintSensor{
+		runner:   runner,
+		goos:     goos,
+		hostname: func() (string, error) { return "studio", nil },
+		readFile: func(path string) ([]byte, error) {
+			if content, ok := files[path]; ok {
+				return []byte(content), nil
+			}
+			return nil, errors.New("not found")
+		},
+	}
+}
+
+func TestFingerprintSensor_Darwin(t *testing.T) {
+	t.Parallel()
+
+	runner := mocks.NewCommandRunner()
+	runner.AddResult("sysctl", []string{"-n", "machdep.cpu.brand_string"}, ports.CommandResult{Stdout: "Apple M3 Pro\n"})
+	runner.AddResult("sysctl", []string{"-n", "hw.memsize"}, ports.CommandResult{Stdout: "38654705664\n"})
+	runner.AddResult("ioreg", []string{"-c", "IOPlatformExpertDevice", "-d", "2"}, ports.CommandResult{
+		Stdout: "+-o Root  <class IORegistryEntry>\n    | {\n    |   \"IOPlatformSerialNumber\" = \"C02XYZ123\"\n    |   \"model\" = <\"Mac15,6\">\n",
+	})
+
+	fp := newTestFingerprintSensor("darwin", runner, nil).Sense(context.Background())
+	assert.Equal(t, sync.Fingerprint{Hostname: "studio", Serial: "C02XYZ123", Chip: "Apple M3 Pro", MemoryBytes: 36 << 30}, fp)
+}
+
+func TestFingerprintSensor_Linux(t *testing.T) {
+	t.Parallel()
+
+	fp := newTestFingerprintSensor("linux", mocks.NewCommandRunner(), map[string]string{
+		"/proc/cpuinfo":                    "processor\t: 0\nvendor_id\t: AuthenticAMD\nmodel name\t: AMD Ryzen 9 7950X 16-Core Processor\n\nprocessor\t: 1\nmodel name\t: AMD Ryzen 9 7950X 16-Core Processor\n",
+		"/proc/meminfo":                    "MemTotal:       65536000 kB\nMemFree:        1024 kB\n",
+		"/sys/class/dmi/id/product_serial": "ABC123\n",
+	}).Sense(context.Background())
+	assert.Equal(t, "AMD Ryzen 9 7950X 16-Core Processor", fp.Chip)
+	assert.Equal(t, uint64(65536000*1024), fp.MemoryBytes)
+	assert.Equal(t, "ABC123", fp.Serial)
+	assert.Equal(t, 63, fp.MemoryGB())
+
+	// The serial number needs root on most distributions
+	fp = newTestFingerprintSensor("linux", mocks.NewCommandRunner(), nil).Sense(context.Background())
+	assert.Equal(t, sync.Fingerprint{Hostname: "studio"}, fp)
+}
@@ -0,0 +1,82 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+)
+
+// FormattedFile is a configuration file and its canonical formatting.
+type FormattedFile struct {
+	Path      string
+	Original  []byte
+	Formatted []byte
+	// Changes describes what formatting changed, e.g. "sorted packages.brew.formulae".
+	Changes []string
+}
+
+// Changed reports whether formatting changes the file.
+func (f FormattedFile) Changed() bool {
+	return !bytes.Equal(f.Original, f.Formatted)
+}
+
+// FormatConfig formats the manifest at configPath and every layer file in
+// the layers directory next to it. Packages a layer repeats from an earlier
+// layer of every target using it are removed.
+func FormatConfig(configPath string) ([]FormattedFile, error) {
+	loader := config.NewLoader()
+	manifest, err := loader.LoadManifest(configPath)
+	if err != nil {
+		return nil, err
+	}
+	// #nosec G304 -- formatting the user's own config files.
+	manifestData, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	layerPaths, err := filepath.Glob(filepath.Join(filepath.Dir(configPath), "layers", "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(layerPaths)
+
+	layers := make(map[string]*config.Layer, len(layerPaths))
+	layerData := make(map[string][]byte, len(layerPaths))
+	for _, path := range layerPaths {
+		// #nosec G304 -- formatting the user's own config files.
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		layer, err := config.ParseLayer(data)
+		if err != nil {
+			return nil, config.NewYAMLParseError(path, err)
+		}
+		name := strings.TrimSuffix(filepath.Base(path), ".yaml")
+		layers[name] = layer
+		layerData[path] = data
+	}
+	redundant := config.FindRedundantPackages(manifest, layers)
+
+	formatted, changes, err := config.FormatManifest(manifestData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format %s: %w", configPath, err)
+	}
+	files := []FormattedFile{{Path: configPath, Original: manifestData, Formatted: formatted, Changes: changes}}
+
+	for _, path := range layerPaths {
+		name := strings.TrimSuffix(filepath.Base(path), ".yaml")
+		formatted, changes, err := config.FormatLayer(layerData[path], redundant[name])
+		if err != nil {
+			return nil, fmt.Errorf("failed to format %s: %w", path, err)
+		}
+		files = append(files, FormattedFile{Path: path, Original: layerData[path], Formatted: formatted, Changes: changes})
+	}
+	return files, nil
+}
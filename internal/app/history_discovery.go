@@ -0,0 +1,238 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+)
+
+// HistoryTool is a command run often from the shell that a package
+// provides but no layer of the target declares.
+type HistoryTool struct {
+	Command  string `json:"command"`
+	Uses     int    `json:"uses"`
+	Provider string `json:"provider"`
+	Package  string `json:"package"`
+}
+
+// historyCommandPackages maps the commands of common tools to the brew
+// formula and apt package that provide them. An empty apt name means apt
+// does not provide the command under that name. Commands that ship with
+// the system are left out.
+var historyCommandPackages = map[string]struct{ brew, apt string }{
+	"act":           {"act", ""},
+	"aws":           {"awscli", "awscli"},
+	"az":            {"azure-cli", ""},
+	"bat":           {"bat", ""},
+	"btop":          {"btop", "btop"},
+	"delta":         {"git-delta", "git-delta"},
+	"direnv":        {"direnv", "direnv"},
+	"doctl":         {"doctl", ""},
+	"duf":           {"duf", "duf"},
+	"dust":          {"dust", ""},
+	"entr":          {"entr", "entr"},
+	"eza":           {"eza", "eza"},
+	"fd":            {"fd", ""},
+	"flyctl":        {"flyctl", ""},
+	"fzf":           {"fzf", "fzf"},
+	"gh":            {"gh", "gh"},
+	"glow":          {"glow", ""},
+	"golangci-lint": {"golangci-lint", ""},
+	"goreleaser":    {"goreleaser", ""},
+	"helm":          {"helm", ""},
+	"htop":          {"htop", "htop"},
+	"http":          {"httpie", "httpie"},
+	"hyperfine":     {"hyperfine", "hyperfine"},
+	"jq":            {"jq", "jq"},
+	"just":          {"just", "just"},
+	"k9s":           {"k9s", ""},
+	"kind":          {"kind", ""},
+	"kubectl":       {"kubernetes-cli", ""},
+	"kubectx":       {"kubectx", "kubectx"},
+	"lazygit":       {"lazygit", ""},
+	"minikube":      {"minikube", ""},
+	"mise":          {"mise", ""},
+	"ncdu":          {"ncdu", "ncdu"},
+	"nvim":          {"neovim", "neovim"},
+	"pnpm":          {"pnpm", ""},
+	"procs":         {"procs", ""},
+	"rg":            {"ripgrep", "ripgrep"},
+	"ruff":          {"ruff", ""},
+	"sd":            {"sd", ""},
+	"shellcheck":    {"shellcheck", "shellcheck"},
+	"shfmt":         {"shfmt", "shfmt"},
+	"starship":      {"starship", ""},
+	"stern":         {"stern", ""},
+	"terraform":     {"terraform", ""},
+	"tldr":          {"tlrc", "tldr"},
+	"tmux":          {"tmux", "tmux"},
+	"tokei":         {"tokei", ""},
+	"tree":          {"tree", "tree"},
+	"uv":            {"uv", ""},
+	"watch":         {"watch", ""},
+	"wget":          {"wget", "wget"},
+	"xh":            {"xh", "xh"},
+	"yq":            {"yq", "yq"},
+	"zoxide":        {"zoxide", "zoxide"},
+}
+
+// historyCommandPrefixes are words that run the command after them.
+var historyCommandPrefixes = map[string]bool{
+	"sudo": true, "env": true, "time": true, "command": true,
+	"exec": true, "nohup": true, "nice": true, "builtin": true,
+}
+
+// DefaultShellHistoryFiles returns the history files of zsh, bash and fish
+// in the home directory.
+func DefaultShellHistoryFiles() ([]string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	return []string{
+		filepath.Join(home, ".zsh_history"),
+		filepath.Join(home, ".bash_history"),
+		filepath.Join(dataHome, "fish", "fish_history"),
+	}, nil
+}
+
+// ReadShellHistory counts how often each command was run in the history
+// files. Files that do not exist are skipped.
+func ReadShellHistory(paths []string) (map[string]int, error) {
+	counts := make(map[string]int)
+	for _, path := range paths {
+		// #nosec G304 -- path is a shell history file of the user.
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		for command, n := range parseShellHistory(data) {
+			counts[command] += n
+		}
+	}
+	return counts, nil
+}
+
+// parseShellHistory counts the commands in zsh, bash or fish history. Each
+// command of a pipeline or command list counts.
+func parseShellHistory(data []byte) map[string]int {
+	counts := make(map[string]int)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	continued := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		wasContinued := continued
+		continued = strings.HasSuffix(line, "\\")
+		if wasContinued {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, ": ") && strings.Contains(line, ";"):
+			// zsh extended history: ": <start>:<elapsed>;<command>"
+			line = line[strings.Index(line, ";")+1:]
+		case strings.HasPrefix(line, "- cmd: "):
+			// fish history
+			line = strings.TrimPrefix(line, "- cmd: ")
+		case strings.HasPrefix(line, "#"), strings.HasPrefix(line, "  "):
+			// bash timestamps and fish metadata
+			continue
+		}
+		for _, command := range historyLineCommands(line) {
+			counts[command]++
+		}
+	}
+	return counts
+}
+
+// historyLineCommands returns the commands a history line runs.
+func historyLineCommands(line string) []string {
+	segments := strings.FieldsFunc(line, func(r rune) bool {
+		return r == '|' || r == '&' || r == ';' || r == '(' || r == ')'
+	})
+	var commands []string
+	for _, segment := range segments {
+		for _, word := range strings.Fields(segment) {
+			if historyCommandPrefixes[word] || strings.HasPrefix(word, "-") ||
+				(strings.Contains(word, "=") && !strings.HasPrefix(word, "=")) {
+				continue
+			}
+			commands = append(commands, filepath.Base(word))
+			break
+		}
+	}
+	return commands
+}
+
+// DiscoverHistoryTools returns the commands run at least minUses times
+// that a package provides but no layer of the target declares, and the
+// layer updates that add those packages. installed reports whether a
+// command is on the PATH: commands that are not are left out, as are
+// package managers the target does not use.
+func DiscoverHistoryTools(configPath, target string, counts map[string]int, minUses int, installed func(string) bool, strategy SplitStrategy) ([]HistoryTool, []LayerUpdate, error) {
+	configPath, layers, err := loadTargetLayers(configPath, target)
+	if err != nil {
+		return nil, nil, err
+	}
+	tools, updates := planHistoryTools(configPath, target, layers, counts, minUses, installed, strategy)
+	return tools, updates, nil
+}
+
+func planHistoryTools(configPath, target string, layers []config.Layer, counts map[string]int, minUses int, installed func(string) bool, strategy SplitStrategy) ([]HistoryTool, []LayerUpdate) {
+	// Packages come from brew where the target uses it, apt otherwise
+	provider := ""
+	for i := range layers {
+		if len(layers[i].PackageList("packages.brew.formulae")) > 0 {
+			provider = "brew"
+			break
+		}
+		if len(layers[i].PackageList("packages.apt.packages")) > 0 {
+			provider = "apt"
+		}
+	}
+	if provider == "" {
+		return nil, nil
+	}
+
+	var items []CapturedItem
+	commands := make(map[string]string)
+	for command, uses := range counts {
+		pkg, ok := historyCommandPackages[command]
+		if !ok || uses < minUses || !installed(command) {
+			continue
+		}
+		name := pkg.brew
+		if provider == "apt" {
+			name = pkg.apt
+		}
+		if name == "" {
+			continue
+		}
+		items = append(items, CapturedItem{Provider: provider, Name: name, Source: "shell history"})
+		commands[name] = command
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+
+	untracked := untrackedItems(layers, items)
+	tools := make([]HistoryTool, 0, len(untracked))
+	for _, item := range untracked {
+		command := commands[item.Name]
+		tools = append(tools, HistoryTool{Command: command, Uses: counts[command], Provider: item.Provider, Package: item.Name})
+	}
+	sort.SliceStable(tools, func(i, j int) bool { return tools[i].Uses > tools[j].Uses })
+	return tools, planReverseSync(configPath, target, layers, untracked, strategy)
+}
@@ -0,0 +1,105 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseShellHistory(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		history string
+		want    map[string]int
+	}{
+		{
+			name:    "zsh extended",
+			history: ": 1792166636:0;http GET example.com\n: 1792166640:0;k9s --context prod\n",
+			want:    map[string]int{"http": 1, "k9s": 1},
+		},
+		{
+			name:    "bash with timestamps",
+			history: "#1792166636\nrg TODO | head\n#1792166640\nsudo FOO=1 /usr/local/bin/jq . data.json && ls\n",
+			want:    map[string]int{"rg": 1, "head": 1, "jq": 1, "ls": 1},
+		},
+		{
+			name:    "fish",
+			history: "- cmd: kubectl get pods\n  when: 1792166636\n- cmd: kubectl logs api\n  when: 1792166640\n",
+			want:    map[string]int{"kubectl": 2},
+		},
+		{
+			name:    "multi-line command",
+			history: "docker run \\\n  --rm alpine\nfd main\n",
+			want:    map[string]int{"docker": 1, "fd": 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, parseShellHistory([]byte(tt.history)))
+		})
+	}
+}
+
+func TestReadShellHistory(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	zsh := filepath.Join(dir, ".zsh_history")
+	bash := filepath.Join(dir, ".bash_history")
+	require.NoError(t, os.WriteFile(zsh, []byte(": 1792166636:0;http :8080\n"), 0o600))
+	require.NoError(t, os.WriteFile(bash, []byte("http :8080/health\nrg foo\n"), 0o600))
+
+	counts, err := ReadShellHistory([]string{zsh, bash, filepath.Join(dir, "missing")})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"http": 2, "rg": 1}, counts)
+}
+
+func TestPlanHistoryTools(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "preflight.yaml")
+	layers := []config.Layer{
+		parseTestLayer(t, "name: base\npackages:\n  brew:\n    formulae: [ripgrep, jq]\n"),
+	}
+	counts := map[string]int{
+		"http":      42,
+		"k9s":       17,
+		"rg":        120,
+		"lazygit":   2,
+		"ls":        300,
+		"terraform": 8,
+	}
+	installed := func(command string) bool { return command != "terraform" }
+
+	tools, updates := planHistoryTools(configPath, "default", layers, counts, 5, installed, SplitByCategory)
+	assert.Equal(t, []HistoryTool{
+		{Command: "http", Uses: 42, Provider: "brew", Package: "httpie"},
+		{Command: "k9s", Uses: 17, Provider: "brew", Package: "k9s"},
+	}, tools, "tracked, rarely used, uninstalled and system commands are left out")
+
+	var packages []string
+	for _, update := range updates {
+		packages = append(packages, update.Packages...)
+	}
+	assert.ElementsMatch(t, []string{"httpie", "k9s"}, packages)
+
+	// apt names are used when the target installs packages with apt
+	aptLayers := []config.Layer{parseTestLayer(t, "name: base\npackages:\n  apt:\n    packages: [curl]\n")}
+	tools, _ = planHistoryTools(configPath, "default", aptLayers, counts, 5, installed, SplitByCategory)
+	assert.Equal(t, []HistoryTool{
+		{Command: "rg", Uses: 120, Provider: "apt", Package: "ripgrep"},
+		{Command: "http", Uses: 42, Provider: "apt", Package: "httpie"},
+	}, tools, "k9s is not packaged for apt")
+
+	tools, updates = planHistoryTools(configPath, "default", nil, counts, 5, installed, SplitByCategory)
+	assert.Empty(t, tools)
+	assert.Empty(t, updates)
+}
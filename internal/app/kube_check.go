@@ -0,0 +1,128 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+)
+
+// kubeconfigView is the part of 'kubectl config view -o json' the doctor uses.
+type kubeconfigView struct {
+	Clusters []struct {
+		Name string `json:"name"`
+	} `json:"clusters"`
+	Users []struct {
+		Name string `json:"name"`
+	} `json:"users"`
+	Contexts []struct {
+		Name    string `json:"name"`
+		Context struct {
+			Cluster   string `json:"cluster"`
+			User      string `json:"user"`
+			Namespace string `json:"namespace"`
+		} `json:"context"`
+	} `json:"contexts"`
+}
+
+// checkKubeContexts reports declared kubeconfig contexts that are missing,
+// that differ from their declaration or reference a cluster or user that no
+// longer exists, and contexts whose API server does not respond.
+func checkKubeContexts(configPath, targetName string, run func(string, ...string) (string, error), report *DoctorReport) {
+	target, err := config.NewTargetName(targetName)
+	if err != nil {
+		return
+	}
+	merged, err := config.NewLoader().Load(configPath, target)
+	if err != nil || len(merged.Kubernetes.Contexts) == 0 {
+		return
+	}
+
+	out, err := run("kubectl", "config", "view", "-o", "json")
+	if err != nil {
+		report.Issues = append(report.Issues, DoctorIssue{
+			Provider:   "kubernetes",
+			StepID:     "kubernetes:kubectl",
+			Severity:   SeverityWarning,
+			Message:    "kubectl is not available; kubernetes contexts cannot be checked",
+			Expected:   "kubectl installed",
+			Actual:     "not found",
+			FixCommand: "brew install kubectl",
+		})
+		return
+	}
+	var view kubeconfigView
+	if err := json.Unmarshal([]byte(out), &view); err != nil {
+		return
+	}
+	clusters := make(map[string]bool, len(view.Clusters))
+	for _, c := range view.Clusters {
+		clusters[c.Name] = true
+	}
+	users := make(map[string]bool, len(view.Users))
+	for _, u := range view.Users {
+		users[u.Name] = true
+	}
+
+	for _, declared := range merged.Kubernetes.Contexts {
+		stepID := "kubernetes:context:" + declared.Name
+		idx := -1
+		for i, c := range view.Contexts {
+			if c.Name == declared.Name {
+				idx = i
+			}
+		}
+		if idx < 0 {
+			report.Issues = append(report.Issues, DoctorIssue{
+				Provider:   "kubernetes",
+				StepID:     stepID,
+				Severity:   SeverityWarning,
+				Message:    fmt.Sprintf("kube context %s is missing", declared.Name),
+				Expected:   "present",
+				Actual:     "missing",
+				Fixable:    true,
+				FixCommand: "preflight apply",
+			})
+			continue
+		}
+
+		actual := view.Contexts[idx].Context
+		var stale string
+		switch {
+		case !clusters[actual.Cluster]:
+			stale = fmt.Sprintf("cluster %q does not exist", actual.Cluster)
+		case !users[actual.User]:
+			stale = fmt.Sprintf("user %q does not exist", actual.User)
+		case declared.Cluster != "" && actual.Cluster != declared.Cluster:
+			stale = fmt.Sprintf("cluster is %q, declared %q", actual.Cluster, declared.Cluster)
+		case declared.User != "" && actual.User != declared.User:
+			stale = fmt.Sprintf("user is %q, declared %q", actual.User, declared.User)
+		case declared.Namespace != "" && actual.Namespace != declared.Namespace:
+			stale = fmt.Sprintf("namespace is %q, declared %q", actual.Namespace, declared.Namespace)
+		}
+		if stale != "" {
+			report.Issues = append(report.Issues, DoctorIssue{
+				Provider:   "kubernetes",
+				StepID:     stepID,
+				Severity:   SeverityWarning,
+				Message:    fmt.Sprintf("kube context %s is stale: %s", declared.Name, stale),
+				Expected:   "matches configuration",
+				Actual:     stale,
+				Fixable:    true,
+				FixCommand: "preflight apply",
+			})
+			continue
+		}
+
+		if _, err := run("kubectl", "--context", declared.Name, "--request-timeout=5s", "cluster-info"); err != nil {
+			report.Issues = append(report.Issues, DoctorIssue{
+				Provider: "kubernetes",
+				StepID:   stepID,
+				Severity: SeverityInfo,
+				Message:  fmt.Sprintf("kube context %s is unreachable (VPN or credentials?)", declared.Name),
+				Expected: "API server responds",
+				Actual:   "no response",
+			})
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const kubeView = `{
+  "clusters": [{"name": "dev"}, {"name": "prod"}],
+  "users": [{"name": "dev-admin"}, {"name": "prod-deployer"}],
+  "contexts": [
+    {"name": "dev", "context": {"cluster": "dev", "user": "dev-admin", "namespace": "default"}},
+    {"name": "prod", "context": {"cluster": "prod", "user": "prod-deployer"}},
+    {"name": "old", "context": {"cluster": "gone", "user": "dev-admin"}}
+  ]
+}`
+
+func writeKubeConfig(t *testing.T, contexts string) string {
+	t.Helper()
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "preflight.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("targets:\n  default: [base]\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "layers"), 0o755))
+	layer := "name: base\nkubernetes:\n  contexts:\n" + contexts
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "layers", "base.yaml"), []byte(layer), 0o644))
+	return configPath
+}
+
+func TestCheckKubeContexts(t *testing.T) {
+	t.Parallel()
+
+	configPath := writeKubeConfig(t, `    - name: dev
+      cluster: dev
+      namespace: team-a
+    - name: prod
+      template: kube/prod.yaml
+    - name: old
+      template: kube/old.yaml
+    - name: staging
+      cluster: staging
+`)
+	report := &DoctorReport{}
+	checkKubeContexts(configPath, "default", fakeEngine(map[string]string{
+		"kubectl config view -o json": kubeView,
+	}), report)
+
+	require.Len(t, report.Issues, 4)
+	assert.Contains(t, report.Issues[0].Message, `kube context dev is stale: namespace is "default", declared "team-a"`)
+	assert.Equal(t, "kubernetes:context:prod", report.Issues[1].StepID)
+	assert.Contains(t, report.Issues[1].Message, "unreachable")
+	assert.Equal(t, SeverityInfo, report.Issues[1].Severity)
+	assert.Contains(t, report.Issues[2].Message, `cluster "gone" does not exist`)
+	assert.Contains(t, report.Issues[3].Message, "kube context staging is missing")
+}
+
+func TestCheckKubeContexts_Reachable(t *testing.T) {
+	t.Parallel()
+
+	report := &DoctorReport{}
+	checkKubeContexts(writeKubeConfig(t, "    - name: prod\n      cluster: prod\n"), "default", fakeEngine(map[string]string{
+		"kubectl config view -o json":                              kubeView,
+		"kubectl --context prod --request-timeout=5s cluster-info": "Kubernetes control plane is running",
+	}), report)
+
+	assert.Empty(t, report.Issues)
+}
+
+func TestCheckKubeContexts_NoKubectl(t *testing.T) {
+	t.Parallel()
+
+	report := &DoctorReport{}
+	checkKubeContexts(writeKubeConfig(t, "    - name: prod\n      cluster: prod\n"), "default", fakeEngine(nil), report)
+
+	require.Len(t, report.Issues, 1)
+	assert.Equal(t, "kubernetes:kubectl", report.Issues[0].StepID)
+}
@@ -0,0 +1,129 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/advisor"
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+)
+
+// LayerMove is a recommendation to move a package to another layer, with
+// the patches that carry it out.
+type LayerMove struct {
+	Package string        `json:"package"`
+	From    string        `json:"from"`
+	To      string        `json:"to"`
+	Reason  string        `json:"reason,omitempty"`
+	Patches []ConfigPatch `json:"patches"`
+	// Creates is set when the destination layer file does not exist yet.
+	Creates bool `json:"creates,omitempty"`
+}
+
+// Description returns a one-line summary of the move.
+func (m LayerMove) Description() string {
+	return fmt.Sprintf("Move %s from %s to %s", m.Package, m.From, m.To)
+}
+
+// LayerMovesFromAnalysis turns misplacement recommendations into layer
+// moves. Recommendations naming a package the layer file does not declare
+// or an invalid layer are skipped: they come from an AI and are not trusted.
+func LayerMovesFromAnalysis(layersDir string, layers []advisor.LayerInfo, results []advisor.LayerAnalysisResult) []LayerMove {
+	paths := make(map[string]string, len(layers))
+	for _, layer := range layers {
+		paths[layer.Name] = layer.Path
+	}
+
+	var moves []LayerMove
+	seen := make(map[string]bool)
+	for _, result := range results {
+		from, ok := paths[result.LayerName]
+		if !ok {
+			continue
+		}
+		// #nosec G304 -- layer paths come from the layers directory.
+		data, err := os.ReadFile(from)
+		if err != nil {
+			continue
+		}
+		source, err := config.ParseLayer(data)
+		if err != nil {
+			continue
+		}
+
+		for _, rec := range result.Recommendations {
+			if !isMisplacement(rec.Type) || rec.SuggestedLayer == result.LayerName {
+				continue
+			}
+			toName, err := config.NewLayerName(rec.SuggestedLayer)
+			if err != nil {
+				continue
+			}
+			to, exists := paths[toName.String()]
+			if !exists {
+				to = filepath.Join(layersDir, toName.String()+".yaml")
+			}
+
+			for _, pkg := range rec.Packages {
+				name, cask := strings.CutSuffix(pkg, " (cask)")
+				key := result.LayerName + "\x00" + name
+				if seen[key] {
+					continue
+				}
+				move := LayerMove{Package: name, From: result.LayerName, To: toName.String(), Reason: rec.Message, Creates: !exists}
+				if move.Creates {
+					move.Patches = append(move.Patches, NewConfigPatch(to, "name", PatchOpAdd, nil, toName.String(), "analyze"))
+				}
+				for _, list := range source.FindPackage(name) {
+					if cask != (list == "packages.brew.casks") {
+						continue
+					}
+					move.Patches = append(move.Patches,
+						NewConfigPatch(from, list, PatchOpRemove, name, nil, "analyze"),
+						NewConfigPatch(to, list, PatchOpAdd, nil, name, "analyze"),
+					)
+				}
+				if len(move.Patches) == 0 || (move.Creates && len(move.Patches) == 1) {
+					continue
+				}
+				seen[key] = true
+				moves = append(moves, move)
+			}
+		}
+	}
+	return moves
+}
+
+func isMisplacement(t advisor.RecommendationType) bool {
+	// The prompt asks for "misplacement"; accept the constant's spelling too.
+	return t == advisor.TypeMisplaced || t == "misplacement"
+}
+
+// PreviewLayerMove returns a unified diff of the files a move changes.
+func PreviewLayerMove(move LayerMove) (string, error) {
+	created := make(map[string][]byte)
+	if move.Creates {
+		created[move.Patches[0].LayerPath] = nil
+	}
+	return previewPatches(created, ConfigPatchesToWriterPatches(move.Patches))
+}
+
+// ApplyLayerMoves applies moves to the layer files, creating destination
+// layers that do not exist yet.
+func ApplyLayerMoves(moves []LayerMove) error {
+	var patches []ConfigPatch
+	for _, move := range moves {
+		if move.Creates {
+			path := move.Patches[0].LayerPath
+			if _, err := os.Stat(path); os.IsNotExist(err) {
+				if err := os.WriteFile(path, nil, 0o644); err != nil {
+					return fmt.Errorf("failed to create layer %s: %w", move.To, err)
+				}
+			}
+		}
+		patches = append(patches, move.Patches...)
+	}
+	return config.NewLayerWriter().ApplyPatches(ConfigPatchesToWriterPatches(patches))
+}
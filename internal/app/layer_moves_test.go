@@ -0,0 +1,56 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/advisor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLayerMovesFromAnalysis(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	misc := filepath.Join(dir, "misc.yaml")
+	git := filepath.Join(dir, "git.yaml")
+	require.NoError(t, os.WriteFile(misc, []byte("name: misc\npackages:\n  brew:\n    formulae:\n      - gh\n      - jq # json\n      - lazygit\n    casks:\n      - fork\n"), 0o644))
+	require.NoError(t, os.WriteFile(git, []byte("name: git\npackages:\n  brew:\n    formulae: [git]\n"), 0o644))
+
+	layers := []advisor.LayerInfo{{Name: "misc", Path: misc}, {Name: "git", Path: git}}
+	results := []advisor.LayerAnalysisResult{{
+		LayerName: "misc",
+		Recommendations: []advisor.AnalysisRecommendation{
+			{Type: "misplacement", Message: "Git tools belong in git", Packages: []string{"gh", "lazygit", "fork (cask)", "unknown"}, SuggestedLayer: "git"},
+			{Type: advisor.TypeMisplaced, Packages: []string{"jq"}, SuggestedLayer: "dev-data"},
+			{Type: advisor.TypeMisplaced, Packages: []string{"jq"}, SuggestedLayer: "../escape"},
+			{Type: advisor.TypeBestPractice, Packages: []string{"jq"}, SuggestedLayer: "git"},
+		},
+	}}
+
+	moves := LayerMovesFromAnalysis(dir, layers, results)
+	require.Len(t, moves, 4)
+	assert.Equal(t, "Move gh from misc to git", moves[0].Description())
+	assert.Equal(t, []ConfigPatch{
+		NewConfigPatch(misc, "packages.brew.formulae", PatchOpRemove, "gh", nil, "analyze"),
+		NewConfigPatch(git, "packages.brew.formulae", PatchOpAdd, nil, "gh", "analyze"),
+	}, moves[0].Patches)
+	assert.Equal(t, "packages.brew.casks", moves[2].Patches[0].YAMLPath)
+	assert.True(t, moves[3].Creates)
+	assert.Equal(t, filepath.Join(dir, "dev-data.yaml"), moves[3].Patches[0].LayerPath)
+
+	diff, err := PreviewLayerMove(moves[0])
+	require.NoError(t, err)
+	assert.Contains(t, diff, "-      - gh\n")
+	assert.Contains(t, diff, "+    formulae: [git, gh]\n")
+
+	require.NoError(t, ApplyLayerMoves([]LayerMove{moves[0], moves[3]}))
+	data, err := os.ReadFile(misc)
+	require.NoError(t, err)
+	assert.Equal(t, "name: misc\npackages:\n  brew:\n    formulae:\n      - lazygit\n    casks:\n      - fork\n", string(data))
+	data, err = os.ReadFile(filepath.Join(dir, "dev-data.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "name: dev-data\npackages:\n  brew:\n    formulae:\n      - jq\n", string(data))
+}
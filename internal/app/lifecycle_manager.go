@@ -2,9 +2,15 @@ package app
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"os"
-	"path/filepath"
+	"sort"
+	"time"
 
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
+	"github.com/felixgeelhaar/preflight/internal/domain/snapshot"
 	"github.com/felixgeelhaar/preflight/internal/ports"
 )
 
@@ -25,11 +31,10 @@ func NewLifecycleManager(snapshot *SnapshotService, drift *DriftService) *Lifecy
 
 // DefaultLifecycleManager creates a LifecycleManager using the default preflight directory.
 func DefaultLifecycleManager() (*LifecycleManager, error) {
-	home, err := os.UserHomeDir()
+	baseDir, err := platform.Dir(platform.StateDir)
 	if err != nil {
 		return nil, err
 	}
-	baseDir := filepath.Join(home, ".preflight")
 
 	snapshot := NewSnapshotService(baseDir)
 	drift := NewDriftService(baseDir)
@@ -63,6 +68,92 @@ func (m *LifecycleManager) AfterApply(ctx context.Context, path, sourceLayer str
 	return m.drift.RecordApplied(ctx, expandedPath, sourceLayer)
 }
 
+// RecordAppliedContent keeps content as the config's version of path.
+func (m *LifecycleManager) RecordAppliedContent(ctx context.Context, path string, content []byte) error {
+	return m.drift.RecordAppliedContent(ctx, ports.ExpandPath(path), content)
+}
+
+// AppliedContent returns the config's version of path as last applied.
+func (m *LifecycleManager) AppliedContent(ctx context.Context, path string) ([]byte, bool, error) {
+	return m.drift.AppliedContent(ctx, ports.ExpandPath(path))
+}
+
+// ScheduledSnapshot snapshots the files preflight applied, independent of
+// an apply, after deleting scheduled snapshots older than keep. It returns
+// nil without taking a snapshot when none of the files changed since the
+// last scheduled snapshot.
+func (m *LifecycleManager) ScheduledSnapshot(ctx context.Context, keep time.Duration) (*snapshot.Set, error) {
+	if keep > 0 {
+		if _, err := m.snapshot.Prune(ctx, snapshot.ReasonScheduled, keep); err != nil {
+			return nil, fmt.Errorf("failed to prune scheduled snapshots: %w", err)
+		}
+	}
+
+	tracked, err := m.drift.ListTrackedFiles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list managed files: %w", err)
+	}
+	current := make(map[string]string, len(tracked))
+	for _, file := range tracked {
+		// #nosec G304 -- paths are files preflight applied.
+		data, err := os.ReadFile(file.Path)
+		if err != nil {
+			continue
+		}
+		sum := sha256.Sum256(data)
+		current[file.Path] = hex.EncodeToString(sum[:])
+	}
+	if len(current) == 0 {
+		return nil, nil
+	}
+
+	last, err := m.lastScheduledSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if last != nil && len(last.Snapshots) == len(current) {
+		unchanged := true
+		for _, snap := range last.Snapshots {
+			if current[snap.Path] != snap.Hash {
+				unchanged = false
+				break
+			}
+		}
+		if unchanged {
+			return nil, nil
+		}
+	}
+
+	paths := make([]string, 0, len(current))
+	for path := range current {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return m.snapshot.Create(ctx, snapshot.ReasonScheduled, paths)
+}
+
+// lastScheduledSnapshot returns the most recent scheduled snapshot set,
+// or nil.
+func (m *LifecycleManager) lastScheduledSnapshot(ctx context.Context) (*snapshot.Set, error) {
+	sets, err := m.snapshot.ListSnapshotSets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var latest *snapshot.Set
+	for i := range sets {
+		if sets[i].Reason != string(snapshot.ReasonScheduled) {
+			continue
+		}
+		if latest == nil || sets[i].CreatedAt.After(latest.CreatedAt) {
+			latest = &sets[i]
+		}
+	}
+	if latest == nil {
+		return nil, nil
+	}
+	return m.snapshot.GetSnapshotSet(ctx, latest.ID)
+}
+
 // Snapshot returns the underlying SnapshotService.
 func (m *LifecycleManager) Snapshot() *SnapshotService {
 	return m.snapshot
@@ -73,5 +164,9 @@ func (m *LifecycleManager) Drift() *DriftService {
 	return m.drift
 }
 
-// Ensure LifecycleManager implements ports.FileLifecycle.
-var _ ports.FileLifecycle = (*LifecycleManager)(nil)
+// Ensure LifecycleManager implements ports.FileLifecycle and
+// ports.AppliedContentStore.
+var (
+	_ ports.FileLifecycle       = (*LifecycleManager)(nil)
+	_ ports.AppliedContentStore = (*LifecycleManager)(nil)
+)
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -173,3 +174,42 @@ func TestLifecycleManager_PathExpansion(t *testing.T) {
 	require.NoError(t, err)
 	assert.Len(t, files, 1)
 }
+
+func TestLifecycleManager_ScheduledSnapshot(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	manager := NewLifecycleManager(NewSnapshotService(tmpDir), NewDriftService(tmpDir))
+	ctx := context.Background()
+
+	set, err := manager.ScheduledSnapshot(ctx, time.Hour)
+	require.NoError(t, err)
+	assert.Nil(t, set, "no managed files")
+
+	file := filepath.Join(tmpDir, "gitconfig")
+	require.NoError(t, os.WriteFile(file, []byte("[user]\n"), 0o644))
+	require.NoError(t, manager.AfterApply(ctx, file, "base"))
+
+	first, err := manager.ScheduledSnapshot(ctx, time.Hour)
+	require.NoError(t, err)
+	require.NotNil(t, first)
+	assert.Equal(t, "scheduled", first.Reason)
+	assert.Equal(t, []string{file}, first.Paths())
+
+	unchanged, err := manager.ScheduledSnapshot(ctx, time.Hour)
+	require.NoError(t, err)
+	assert.Nil(t, unchanged, "unchanged files are not snapshotted again")
+
+	// A manual edit is captured by the next scheduled snapshot.
+	require.NoError(t, os.WriteFile(file, []byte("[user]\n  name = me\n"), 0o644))
+	second, err := manager.ScheduledSnapshot(ctx, time.Hour)
+	require.NoError(t, err)
+	require.NotNil(t, second)
+
+	// Retention deletes scheduled snapshots past keep.
+	time.Sleep(20 * time.Millisecond)
+	_, err = manager.ScheduledSnapshot(ctx, 10*time.Millisecond)
+	require.NoError(t, err)
+	_, err = manager.Snapshot().GetSnapshotSet(ctx, first.ID)
+	assert.Error(t, err)
+}
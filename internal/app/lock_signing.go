@@ -0,0 +1,158 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	lockadapter "github.com/felixgeelhaar/preflight/internal/adapters/lockfile"
+	"github.com/felixgeelhaar/preflight/internal/domain/catalog"
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/domain/lock"
+	"github.com/felixgeelhaar/preflight/internal/domain/marketplace"
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+)
+
+// loadTrustStore loads the trust store shared with 'preflight trust'.
+func loadTrustStore() (*catalog.TrustStore, error) {
+	storePath, err := platform.Path(platform.ConfigDir, "trust.json")
+	if err != nil {
+		return nil, err
+	}
+	store := catalog.NewTrustStore(storePath)
+	if err := store.Load(); err != nil {
+		return nil, fmt.Errorf("failed to load trust store: %w", err)
+	}
+	return store, nil
+}
+
+// trustedKeyFor returns the unexpired trust store key with fingerprint, the
+// catalog.ComputeKeyFingerprint of its public key that 'preflight trust add'
+// records. Keys are matched on the fingerprint alone: a key ID is a name
+// chosen when the key is added and says nothing about the key itself.
+func trustedKeyFor(store *catalog.TrustStore, fingerprint string) (*catalog.TrustedKey, bool) {
+	if fingerprint == "" {
+		return nil, false
+	}
+	for _, key := range store.List() {
+		if key.Fingerprint() == fingerprint && !key.IsExpired() {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+// LoadLockSigner returns the signer for the lockfile of configPath, made
+// from sync.lock_signing.key, or nil when no key is configured. The key's
+// public half, read from <key>.pub, must be in the trust store.
+func LoadLockSigner(configPath string) (*lock.Signer, error) {
+	cfg, err := LoadSyncConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.LockSigning.Key == "" {
+		return nil, nil
+	}
+
+	keyPath := ports.ExpandPath(cfg.LockSigning.Key)
+	key, err := marketplace.LoadSigningKey(keyPath)
+	if err != nil {
+		return nil, err
+	}
+	pubData, err := os.ReadFile(keyPath + ".pub")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key %s.pub: %w", keyPath, err)
+	}
+
+	store, err := loadTrustStore()
+	if err != nil {
+		return nil, err
+	}
+	trusted, ok := trustedKeyFor(store, catalog.ComputeKeyFingerprint(pubData))
+	if !ok {
+		return nil, fmt.Errorf("lock signing key %s is not in the trust store; add it with 'preflight trust add %s.pub'",
+			filepath.Base(keyPath), keyPath)
+	}
+	return lock.NewSigner(key, trusted.KeyID(), pubData)
+}
+
+// lockRepoFor returns the repository that saves the lockfile of
+// configPath, signing it when sync.lock_signing.key is set.
+func (p *Preflight) lockRepoFor(configPath string) (lock.Repository, error) {
+	repo, ok := p.lockRepo.(lock.SigningRepository)
+	if !ok {
+		return p.lockRepo, nil
+	}
+	signer, err := LoadLockSigner(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load lock signing key: %w", err)
+	}
+	if signer == nil {
+		return p.lockRepo, nil
+	}
+	return repo.WithSigner(signer), nil
+}
+
+// SignLockData re-signs lockfile data that preflight edited outside the
+// lockfile repository, such as a lockfile filtered by sync.exclude, with
+// the key of configPath. Without a key the stale signature is dropped and
+// signed reports false.
+func SignLockData(configPath string, data []byte) (signedData []byte, signed bool, err error) {
+	signer, err := LoadLockSigner(configPath)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load lock signing key: %w", err)
+	}
+	data, err = lockadapter.Resign(data, signer)
+	return data, signer != nil, err
+}
+
+// LockProvenance describes whether a lockfile from another machine can be
+// trusted.
+type LockProvenance struct {
+	// Signer is the key that signed the lockfile, or nil when it is unsigned.
+	Signer *lock.Provenance
+	// Trusted reports whether the signer is in the trust store.
+	Trusted bool
+	// Untrusted is the reason the lockfile cannot be trusted, wrapping
+	// lock.ErrUnsigned or lock.ErrUntrustedSigner. It is nil when the
+	// lockfile is trusted, or unsigned while lock signing is not configured.
+	Untrusted error
+	// Warn reports whether sync.lock_signing.untrusted lets an untrusted
+	// lockfile through with a warning.
+	Warn bool
+}
+
+// CheckLockProvenance verifies the signature of lockfile data pulled from
+// another machine and checks the signer against the trust store under the
+// sync.lock_signing policy of cfg. A lockfile changed after it was signed
+// returns lock.ErrSignatureInvalid whatever the policy.
+func CheckLockProvenance(cfg *config.SyncConfig, data []byte) (*LockProvenance, error) {
+	signer, err := lockadapter.ReadProvenance(data)
+	if err != nil {
+		return nil, err
+	}
+	policy := config.SyncLockSigning{}
+	if cfg != nil {
+		policy = cfg.LockSigning
+	}
+	result := &LockProvenance{Signer: signer, Warn: policy.WarnsOnUntrusted()}
+
+	if signer == nil {
+		if policy.Enabled() {
+			result.Untrusted = lock.ErrUnsigned
+		}
+		return result, nil
+	}
+
+	store, err := loadTrustStore()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := trustedKeyFor(store, signer.Fingerprint); ok {
+		result.Trusted = true
+		return result, nil
+	}
+	result.Untrusted = fmt.Errorf("%w: %s (%s)", lock.ErrUntrustedSigner, signer.KeyID, signer.Fingerprint)
+	return result, nil
+}
@@ -0,0 +1,129 @@
+package app
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	lockadapter "github.com/felixgeelhaar/preflight/internal/adapters/lockfile"
+	"github.com/felixgeelhaar/preflight/internal/domain/catalog"
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/domain/lock"
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+// writeLockSigningKey writes an ed25519 key pair into dir and, when trust
+// is set, adds its public key to the trust store under home.
+func writeLockSigningKey(t *testing.T, home, dir, keyID string, trust bool) string {
+	t.Helper()
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	block, err := ssh.MarshalPrivateKey(key, "")
+	require.NoError(t, err)
+	sshPub, err := ssh.NewPublicKey(pub)
+	require.NoError(t, err)
+	pubData := ssh.MarshalAuthorizedKey(sshPub)
+	keyPath := filepath.Join(dir, keyID)
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(block), 0o600))
+	require.NoError(t, os.WriteFile(keyPath+".pub", pubData, 0o644))
+
+	if trust {
+		store := catalog.NewTrustStore(filepath.Join(home, "trust.json"))
+		require.NoError(t, store.Load())
+		trusted := catalog.NewTrustedKey(keyID, catalog.SignatureTypeSSH, nil,
+			catalog.NewPublisher("Team", "team@example.com", keyID, catalog.SignatureTypeSSH))
+		trusted.SetFingerprint(catalog.ComputeKeyFingerprint(pubData))
+		require.NoError(t, store.Add(trusted))
+		require.NoError(t, store.Save())
+	}
+	return keyPath
+}
+
+func TestLockSigning(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv(platform.HomeEnvVar, home)
+	dir := t.TempDir()
+	keyPath := writeLockSigningKey(t, home, dir, "team", true)
+	configPath := filepath.Join(dir, "preflight.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("mode: locked\nsync:\n  lock_signing:\n    key: "+keyPath+"\n"), 0o644))
+
+	signer, err := LoadLockSigner(configPath)
+	require.NoError(t, err)
+	require.NotNil(t, signer)
+	assert.Equal(t, "team", signer.KeyID())
+
+	// Lockfiles written by preflight are signed with the key.
+	lockPath := filepath.Join(dir, "preflight.lock")
+	lockfile := lock.NewLockfile(config.ModeIntent, lock.MachineInfoFromSystem())
+	require.NoError(t, lockadapter.NewYAMLRepository().Save(context.Background(), lockPath, lockfile))
+	pf := New(io.Discard)
+	require.NoError(t, pf.LockFreeze(context.Background(), configPath))
+	data, err := os.ReadFile(lockPath)
+	require.NoError(t, err)
+
+	provenance, err := CheckLockProvenance(nil, data)
+	require.NoError(t, err)
+	assert.True(t, provenance.Trusted)
+	assert.NoError(t, provenance.Untrusted)
+	assert.Equal(t, "team", provenance.Signer.KeyID)
+
+	// A key outside the trust store cannot sign and is not trusted.
+	strangerPath := writeLockSigningKey(t, home, dir, "stranger", false)
+	strangerConfig := filepath.Join(dir, "stranger.yaml")
+	require.NoError(t, os.WriteFile(strangerConfig, []byte("sync:\n  lock_signing:\n    key: "+strangerPath+"\n"), 0o644))
+	_, err = LoadLockSigner(strangerConfig)
+	require.ErrorContains(t, err, "lock signing key stranger is not in the trust store")
+
+	t.Setenv(platform.HomeEnvVar, t.TempDir())
+	provenance, err = CheckLockProvenance(&config.SyncConfig{LockSigning: config.SyncLockSigning{Untrusted: config.LockSigningWarn}}, data)
+	require.NoError(t, err)
+	assert.False(t, provenance.Trusted)
+	require.ErrorIs(t, provenance.Untrusted, lock.ErrUntrustedSigner)
+	assert.True(t, provenance.Warn)
+}
+
+func TestTrustedKeyFor(t *testing.T) {
+	t.Parallel()
+
+	fingerprint := catalog.ComputeKeyFingerprint([]byte("ssh-ed25519 AAAA team"))
+	store := catalog.NewTrustStore(filepath.Join(t.TempDir(), "trust.json"))
+	team := catalog.NewTrustedKey("team", catalog.SignatureTypeSSH, nil,
+		catalog.NewPublisher("Team", "", "team", catalog.SignatureTypeSSH))
+	team.SetFingerprint(fingerprint)
+	require.NoError(t, store.Add(team))
+	// A key named after another key's fingerprint is not that key.
+	impostor := catalog.NewTrustedKey(catalog.ComputeKeyFingerprint([]byte("ssh-ed25519 BBBB other")), catalog.SignatureTypeSSH, nil,
+		catalog.NewPublisher("Other", "", "other", catalog.SignatureTypeSSH))
+	require.NoError(t, store.Add(impostor))
+
+	key, ok := trustedKeyFor(store, fingerprint)
+	require.True(t, ok)
+	assert.Equal(t, "team", key.KeyID())
+
+	_, ok = trustedKeyFor(store, impostor.KeyID())
+	assert.False(t, ok)
+	_, ok = trustedKeyFor(store, "")
+	assert.False(t, ok)
+}
+
+func TestCheckLockProvenance_Unsigned(t *testing.T) {
+	t.Parallel()
+
+	data := []byte("version: 1\nmode: intent\n")
+	provenance, err := CheckLockProvenance(nil, data)
+	require.NoError(t, err)
+	assert.NoError(t, provenance.Untrusted, "unsigned lockfiles pass until lock signing is configured")
+
+	provenance, err = CheckLockProvenance(&config.SyncConfig{LockSigning: config.SyncLockSigning{Untrusted: config.LockSigningRefuse}}, data)
+	require.NoError(t, err)
+	require.ErrorIs(t, provenance.Untrusted, lock.ErrUnsigned)
+	assert.False(t, provenance.Warn)
+}
@@ -0,0 +1,121 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/adapters/command"
+	lockadapter "github.com/felixgeelhaar/preflight/internal/adapters/lockfile"
+	"github.com/felixgeelhaar/preflight/internal/domain/execution"
+	"github.com/felixgeelhaar/preflight/internal/domain/lock"
+	"github.com/felixgeelhaar/preflight/internal/domain/sync"
+)
+
+// currentMachineID returns this machine's sync identity. Tests replace it to
+// avoid creating ~/.preflight/machine-id.
+var currentMachineID = sync.GetMachineID
+
+// currentFingerprint returns this machine's hardware fingerprint. Tests
+// replace it to avoid creating ~/.preflight/fingerprint.json.
+var currentFingerprint = func(ctx context.Context) (sync.Fingerprint, error) {
+	return MachineFingerprint(ctx, NewFingerprintSensor(command.NewRealRunner()))
+}
+
+// recordLockActivity advances the lockfile's version vector for this machine.
+func recordLockActivity(lockfile *lock.Lockfile) {
+	machineID, err := currentMachineID()
+	if err != nil {
+		return
+	}
+	hostname, _ := os.Hostname()
+	lockfile.RecordChange(machineID, hostname)
+}
+
+// MachineInventoryDir returns the directory holding per-machine applied state
+// for the config at configPath.
+func MachineInventoryDir(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), filepath.FromSlash(sync.InventoryDir))
+}
+
+// RecordMachineState records the packages this machine applied from plan,
+// together with the lockfile's version vector, so other machines can see
+// which hosts are behind.
+func (p *Preflight) RecordMachineState(ctx context.Context, configPath, target string, plan *execution.Plan) error {
+	if plan == nil {
+		return fmt.Errorf("plan is required to record machine state")
+	}
+
+	machineID, err := currentMachineID()
+	if err != nil {
+		return fmt.Errorf("failed to get machine ID: %w", err)
+	}
+	hostname, _ := os.Hostname()
+	var fingerprint *sync.Fingerprint
+	if fp, err := currentFingerprint(ctx); err == nil && !fp.IsZero() {
+		fingerprint = &fp
+	}
+
+	pkgs, err := planPackageLocks(ctx, plan)
+	if err != nil {
+		return err
+	}
+
+	state := sync.MachineState{
+		MachineID:   machineID.String(),
+		Hostname:    hostname,
+		OS:          runtime.GOOS + "/" + runtime.GOARCH,
+		Target:      target,
+		Fingerprint: fingerprint,
+		AppliedAt:   time.Now().UTC(),
+		Vector:      sync.NewVersionVector(),
+		Packages:    make(map[string]string, len(pkgs)),
+	}
+	for _, pkg := range pkgs {
+		state.Packages[pkg.Key()] = pkg.Version()
+	}
+
+	lockState, err := p.loadLockState(ctx, configPath)
+	if err != nil {
+		return err
+	}
+	state.Vector = lockState.Metadata.Vector()
+
+	return sync.SaveMachineState(MachineInventoryDir(configPath), state)
+}
+
+// MachineInventory loads the per-machine applied state for the config at
+// configPath and the lockfile state to compare it against.
+func (p *Preflight) MachineInventory(ctx context.Context, configPath string) (*sync.Inventory, *sync.LockfileState, error) {
+	inv, err := sync.LoadInventory(MachineInventoryDir(configPath))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load machine inventory: %w", err)
+	}
+	lockState, err := p.loadLockState(ctx, configPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return inv, lockState, nil
+}
+
+// loadLockState loads the lockfile next to configPath as sync state. A
+// missing lockfile yields an empty state.
+func (p *Preflight) loadLockState(ctx context.Context, configPath string) (*sync.LockfileState, error) {
+	lockPath := strings.TrimSuffix(configPath, filepath.Ext(configPath)) + ".lock"
+	if p.lockRepo == nil {
+		return nil, fmt.Errorf("lockfile repository not configured")
+	}
+	lockfile, err := p.lockRepo.Load(ctx, lockPath)
+	if err != nil {
+		if errors.Is(err, lock.ErrLockfileNotFound) {
+			return sync.NewLockfileState(), nil
+		}
+		return nil, fmt.Errorf("failed to load lockfile: %w", err)
+	}
+	return lockadapter.NewSyncAdapter().ToLockfileState(lockfile), nil
+}
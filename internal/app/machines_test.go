@@ -0,0 +1,113 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/domain/execution"
+	"github.com/felixgeelhaar/preflight/internal/domain/lock"
+	"github.com/felixgeelhaar/preflight/internal/domain/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubMachineID pins this machine's sync identity for the duration of a test.
+// Tests using it must not run in parallel.
+func stubMachineID(t *testing.T, id string) sync.MachineID {
+	t.Helper()
+	machineID, err := sync.ParseMachineID(id)
+	require.NoError(t, err)
+	orig := currentMachineID
+	currentMachineID = func() (sync.MachineID, error) { return machineID, nil }
+	t.Cleanup(func() { currentMachineID = orig })
+	return machineID
+}
+
+func TestPreflight_UpdateLockFromPlan_RecordsActivity(t *testing.T) {
+	machineID := stubMachineID(t, "550e8400-e29b-41d4-a716-446655440000")
+	pf := New(&bytes.Buffer{})
+	configPath := filepath.Join(t.TempDir(), "preflight.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("targets:\n  default:\n    - base\n"), 0o644))
+
+	mockRepo := &mockLockRepoWithData{lockfile: lock.NewLockfile(config.ModeIntent, lock.MachineInfo{})}
+	pf.WithLockRepo(mockRepo)
+
+	plan := execution.NewExecutionPlan()
+	plan.Add(execution.NewPlanEntry(newLockInfoStep("brew", "ripgrep", "14.1.0"), compiler.StatusNeedsApply, compiler.Diff{}))
+
+	ctx := context.Background()
+	require.NoError(t, pf.UpdateLockFromPlan(ctx, configPath, plan))
+	assert.Equal(t, uint64(1), mockRepo.savedLock.SyncMetadata().Vector().GetByMachineID(machineID))
+
+	// Re-locking the same versions does not advance the vector.
+	mockRepo.lockfile = mockRepo.savedLock
+	require.NoError(t, pf.UpdateLockFromPlan(ctx, configPath, plan))
+	assert.Equal(t, uint64(1), mockRepo.savedLock.SyncMetadata().Vector().GetByMachineID(machineID))
+}
+
+func TestPreflight_RecordMachineState(t *testing.T) {
+	machineID := stubMachineID(t, "550e8400-e29b-41d4-a716-446655440000")
+	fingerprint := sync.Fingerprint{Hostname: "laptop", Chip: "Apple M3 Pro", MemoryBytes: 18 << 30}
+	origFingerprint := currentFingerprint
+	currentFingerprint = func(context.Context) (sync.Fingerprint, error) { return fingerprint, nil }
+	t.Cleanup(func() { currentFingerprint = origFingerprint })
+	pf := New(&bytes.Buffer{})
+	configPath := filepath.Join(t.TempDir(), "preflight.yaml")
+
+	lockfile := lock.NewLockfile(config.ModeIntent, lock.MachineInfo{})
+	integrity := lock.IntegrityFromData(lock.AlgorithmSHA256, []byte("seed"))
+	require.NoError(t, lockfile.SetPackage(mustPackageLock(t, "brew", "ripgrep", "14.1.0", integrity)))
+	lockfile.RecordChange(machineID, "laptop")
+	pf.WithLockRepo(&mockLockRepoWithData{lockfile: lockfile})
+
+	plan := execution.NewExecutionPlan()
+	plan.Add(execution.NewPlanEntry(newLockInfoStep("brew", "ripgrep", "14.1.0"), compiler.StatusNeedsApply, compiler.Diff{}))
+
+	ctx := context.Background()
+	require.NoError(t, pf.RecordMachineState(ctx, configPath, "work", plan))
+	assert.FileExists(t, filepath.Join(filepath.Dir(configPath), ".preflight", "machines", machineID.String()+".json"))
+
+	inv, lockState, err := pf.MachineInventory(ctx, configPath)
+	require.NoError(t, err)
+	require.Equal(t, 1, inv.Len())
+
+	statuses := inv.Status(lockState)
+	require.Len(t, statuses, 1)
+	assert.Equal(t, "work", statuses[0].Target)
+	assert.Equal(t, &fingerprint, statuses[0].Fingerprint)
+	assert.Equal(t, map[string]string{"brew:ripgrep": "14.1.0"}, statuses[0].Packages)
+	assert.False(t, statuses[0].IsBehind())
+
+	// Another machine bumps the lockfile: this machine is now behind.
+	other, _ := sync.ParseMachineID("660e8400-e29b-41d4-a716-446655440000")
+	require.NoError(t, lockfile.SetPackage(mustPackageLock(t, "brew", "ripgrep", "14.2.0", integrity)))
+	lockfile.RecordChange(other, "desktop")
+
+	inv, lockState, err = pf.MachineInventory(ctx, configPath)
+	require.NoError(t, err)
+	status := inv.Status(lockState)[0]
+	assert.Equal(t, sync.Before, status.Relation)
+	assert.Equal(t, []sync.PackageDivergence{
+		{PackageKey: "brew:ripgrep", Applied: "14.1.0", Locked: "14.2.0"},
+	}, status.Divergent)
+}
+
+func TestPreflight_MachineInventory_NoLockfile(t *testing.T) {
+	t.Parallel()
+
+	pf := New(&bytes.Buffer{})
+	pf.WithLockRepo(&mockLockRepoWithData{loadErr: lock.ErrLockfileNotFound})
+
+	configPath := filepath.Join(t.TempDir(), "preflight.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("targets: {}\n"), 0o644))
+
+	inv, lockState, err := pf.MachineInventory(context.Background(), configPath)
+	require.NoError(t, err)
+	assert.Equal(t, 0, inv.Len())
+	assert.Empty(t, lockState.Packages)
+}
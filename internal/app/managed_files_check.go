@@ -0,0 +1,100 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/provider/editorconfig"
+	"github.com/felixgeelhaar/preflight/internal/provider/git"
+)
+
+// checkManagedFiles reports a global gitignore that git does not read and
+// a global gitignore or editorconfig that apply will not overwrite because
+// preflight did not write it.
+func checkManagedFiles(configPath, targetName, home string, run func(string, ...string) (string, error), report *DoctorReport) {
+	target, err := config.NewTargetName(targetName)
+	if err != nil {
+		return
+	}
+	merged, err := config.NewLoader().Load(configPath, target)
+	if err != nil {
+		return
+	}
+
+	if merged.Git.Ignore.Template != "" || len(merged.Git.Ignore.Patterns) > 0 {
+		ignorePath := git.Config{
+			Core:   git.CoreConfig{ExcludesFile: merged.Git.Core.ExcludesFile},
+			Ignore: git.IgnoreConfig{Path: merged.Git.Ignore.Path},
+		}.IgnorePath()
+		checkUnmanagedFile("git", "git:ignore", ignorePath, home, git.IgnoreHeader, "git.ignore.patterns", report)
+		checkExcludesFile(ignorePath, home, run, report)
+	}
+
+	if !merged.EditorConfig.IsZero() {
+		checkUnmanagedFile("editorconfig", "editorconfig:file", editorconfig.Path, home, editorconfig.Header, "editorconfig.sections", report)
+	}
+}
+
+// checkUnmanagedFile reports path when it exists without the header that
+// marks files preflight generated.
+func checkUnmanagedFile(provider, stepID, path, home, header, key string, report *DoctorReport) {
+	data, err := os.ReadFile(expandHome(path, home))
+	if err != nil || bytes.HasPrefix(data, []byte(header)) {
+		return
+	}
+	report.Issues = append(report.Issues, DoctorIssue{
+		Provider:   provider,
+		StepID:     stepID,
+		Severity:   SeverityWarning,
+		Message:    fmt.Sprintf("%s was not generated by preflight, so apply will not overwrite it; move its content to %s", path, key),
+		Expected:   "generated by preflight",
+		Actual:     "written by hand",
+		FixCommand: fmt.Sprintf("mv %s %s.bak", path, path),
+	})
+}
+
+// checkExcludesFile reports when git reads its global gitignore from
+// somewhere other than the managed file.
+func checkExcludesFile(ignorePath, home string, run func(string, ...string) (string, error), report *DoctorReport) {
+	if _, err := run("git", "--version"); err != nil {
+		return
+	}
+
+	// An unset core.excludesfile makes git fall back to the XDG location
+	actual, err := run("git", "config", "--global", "--get", "core.excludesfile")
+	actual = strings.TrimSpace(actual)
+	if err != nil || actual == "" {
+		xdg := os.Getenv("XDG_CONFIG_HOME")
+		if xdg == "" {
+			xdg = filepath.Join(home, ".config")
+		}
+		actual = filepath.Join(xdg, "git", "ignore")
+	}
+
+	if filepath.Clean(expandHome(actual, home)) == filepath.Clean(expandHome(ignorePath, home)) {
+		return
+	}
+	report.Issues = append(report.Issues, DoctorIssue{
+		Provider:   "git",
+		StepID:     "git:ignore",
+		Severity:   SeverityWarning,
+		Message:    fmt.Sprintf("git reads its global gitignore from %s, not the managed %s", actual, ignorePath),
+		Expected:   ignorePath,
+		Actual:     actual,
+		Fixable:    true,
+		FixCommand: "git config --global core.excludesfile " + envrcQuote(ignorePath),
+		RunFix:     true,
+	})
+}
+
+// expandHome expands a leading ~ in path to home.
+func expandHome(path, home string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		return filepath.Join(home, strings.TrimPrefix(path, "~"))
+	}
+	return path
+}
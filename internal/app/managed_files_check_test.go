@@ -0,0 +1,83 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/felixgeelhaar/preflight/internal/provider/editorconfig"
+	"github.com/felixgeelhaar/preflight/internal/provider/git"
+)
+
+func writeManagedFilesConfig(t *testing.T, layer string) string {
+	t.Helper()
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "preflight.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("targets:\n  default: [base]\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "layers"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "layers", "base.yaml"), []byte("name: base\n"+layer), 0o644))
+	return configPath
+}
+
+// fakeGitConfig answers git --version and reports excludesFile as the
+// global core.excludesfile, or as unset when it is empty.
+func fakeGitConfig(excludesFile string) func(string, ...string) (string, error) {
+	return func(_ string, args ...string) (string, error) {
+		if args[0] == "--version" {
+			return "git version 2.47.0", nil
+		}
+		if excludesFile == "" {
+			return "", errors.New("exit status 1")
+		}
+		return excludesFile + "\n", nil
+	}
+}
+
+func TestCheckManagedFiles_ExcludesFile(t *testing.T) {
+	t.Parallel()
+
+	home := t.TempDir()
+	configPath := writeManagedFilesConfig(t, "git:\n  ignore:\n    path: ~/.gitignore_global\n    patterns: [.DS_Store]\n")
+
+	report := &DoctorReport{}
+	checkManagedFiles(configPath, "default", home, fakeGitConfig("~/.gitignore_global"), report)
+	assert.Empty(t, report.Issues)
+
+	report = &DoctorReport{}
+	checkManagedFiles(configPath, "default", home, fakeGitConfig("~/.gitignore"), report)
+	require.Len(t, report.Issues, 1)
+	issue := report.Issues[0]
+	assert.Equal(t, "git:ignore", issue.StepID)
+	assert.Equal(t, "~/.gitignore", issue.Actual)
+	assert.Equal(t, "git config --global core.excludesfile '~/.gitignore_global'", issue.FixCommand)
+	assert.True(t, issue.RunFix)
+}
+
+func TestCheckManagedFiles_UnsetExcludesFileUsesDefault(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", "")
+	configPath := writeManagedFilesConfig(t, "git:\n  ignore:\n    patterns: [.DS_Store]\n")
+
+	report := &DoctorReport{}
+	checkManagedFiles(configPath, "default", home, fakeGitConfig(""), report)
+	assert.Empty(t, report.Issues)
+}
+
+func TestCheckManagedFiles_HandWrittenFiles(t *testing.T) {
+	t.Parallel()
+
+	home := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".editorconfig"), []byte("root = true\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".gitignore_global"), []byte(git.IgnoreHeader+".DS_Store\n"), 0o644))
+	configPath := writeManagedFilesConfig(t, "git:\n  ignore:\n    path: ~/.gitignore_global\n    patterns: [.DS_Store]\neditorconfig:\n  root: true\n")
+
+	report := &DoctorReport{}
+	checkManagedFiles(configPath, "default", home, fakeGitConfig("~/.gitignore_global"), report)
+	require.Len(t, report.Issues, 1)
+	assert.Equal(t, "editorconfig:file", report.Issues[0].StepID)
+	assert.Equal(t, "mv "+editorconfig.Path+" "+editorconfig.Path+".bak", report.Issues[0].FixCommand)
+}
@@ -0,0 +1,72 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/provider/mas"
+)
+
+// checkMasApps reports declared App Store apps that are not installed. Apply
+// can only install them with an Apple ID signed in to the App Store, so
+// without a sign-in the drift is reported as not automatically fixable.
+func checkMasApps(configPath, targetName string, run func(string, ...string) (string, error), report *DoctorReport) {
+	target, err := config.NewTargetName(targetName)
+	if err != nil {
+		return
+	}
+	merged, err := config.NewLoader().Load(configPath, target)
+	if err != nil || len(merged.Packages.Mas.Apps) == 0 {
+		return
+	}
+
+	out, err := run("mas", "list")
+	if err != nil {
+		report.Issues = append(report.Issues, DoctorIssue{
+			Provider:   "mas",
+			StepID:     "mas:install",
+			Severity:   SeverityWarning,
+			Message:    "mas is not installed; App Store apps cannot be checked or installed",
+			Expected:   "mas installed",
+			Actual:     "not found",
+			FixCommand: "brew install mas",
+		})
+		return
+	}
+	installed := mas.ParseList(out)
+
+	signedIn := -1
+	for _, app := range merged.Packages.Mas.Apps {
+		if _, ok := installed[app.ID]; ok {
+			continue
+		}
+		if signedIn < 0 {
+			signedIn = 0
+			if _, err := run("mas", "account"); err == nil {
+				signedIn = 1
+			}
+		}
+
+		name := app.Name
+		if name == "" {
+			name = strconv.FormatInt(app.ID, 10)
+		}
+		issue := DoctorIssue{
+			Provider: "mas",
+			StepID:   "mas:app:" + strconv.FormatInt(app.ID, 10),
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("App Store app %s is not installed", name),
+			Expected: "installed",
+			Actual:   "missing",
+		}
+		if signedIn == 1 {
+			issue.Fixable = true
+			issue.FixCommand = "preflight apply"
+		} else {
+			issue.Message += " and no App Store sign-in was found"
+			issue.FixCommand = "open -a 'App Store'"
+		}
+		report.Issues = append(report.Issues, issue)
+	}
+}
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeMasConfig(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "preflight.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("targets:\n  default: [base]\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "layers"), 0o755))
+	layer := "name: base\npackages:\n  mas:\n    apps:\n      - id: 497799835\n        name: Xcode\n      - id: 904280696\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "layers", "base.yaml"), []byte(layer), 0o644))
+	return configPath
+}
+
+func TestCheckMasApps(t *testing.T) {
+	t.Parallel()
+
+	outputs := map[string]string{"mas list": "497799835  Xcode  (15.4)\n"}
+
+	report := &DoctorReport{}
+	checkMasApps(writeMasConfig(t), "default", fakeEngine(outputs), report)
+	require.Len(t, report.Issues, 1)
+	assert.Equal(t, "mas:app:904280696", report.Issues[0].StepID)
+	assert.False(t, report.Issues[0].Fixable)
+	assert.Contains(t, report.Issues[0].Message, "no App Store sign-in")
+
+	outputs["mas account"] = "me@example.com\n"
+	report = &DoctorReport{}
+	checkMasApps(writeMasConfig(t), "default", fakeEngine(outputs), report)
+	require.Len(t, report.Issues, 1)
+	assert.True(t, report.Issues[0].Fixable)
+	assert.Equal(t, "preflight apply", report.Issues[0].FixCommand)
+}
+
+func TestCheckMasApps_NotInstalled(t *testing.T) {
+	t.Parallel()
+
+	report := &DoctorReport{}
+	checkMasApps(writeMasConfig(t), "default", fakeEngine(nil), report)
+	require.Len(t, report.Issues, 1)
+	assert.Equal(t, "mas:install", report.Issues[0].StepID)
+}
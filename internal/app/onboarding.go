@@ -0,0 +1,135 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
+)
+
+// OnboardingItem is an onboarding task with its local completion state.
+type OnboardingItem struct {
+	config.OnboardingTask
+	CompletedAt *time.Time
+}
+
+// Completed reports whether the task was checked off on this machine.
+func (i OnboardingItem) Completed() bool {
+	return i.CompletedAt != nil
+}
+
+// onboardingState is the persisted completion state, keyed by task key.
+type onboardingState struct {
+	Completed map[string]time.Time `json:"completed"`
+}
+
+// OnboardingService tracks which layer onboarding steps were completed on
+// this machine. The state is local and never synced.
+type OnboardingService struct {
+	statePath string
+}
+
+// NewOnboardingService creates an OnboardingService storing its state in
+// baseDir.
+func NewOnboardingService(baseDir string) *OnboardingService {
+	return &OnboardingService{statePath: filepath.Join(baseDir, "onboarding.json")}
+}
+
+// DefaultOnboardingService creates an OnboardingService using the default
+// preflight directory.
+func DefaultOnboardingService() (*OnboardingService, error) {
+	baseDir, err := platform.Dir(platform.StateDir)
+	if err != nil {
+		return nil, err
+	}
+	return NewOnboardingService(baseDir), nil
+}
+
+// Checklist returns the onboarding tasks of target's layers with their
+// completion state.
+func (s *OnboardingService) Checklist(configPath, target string) ([]OnboardingItem, error) {
+	loader := config.NewLoader()
+	manifest, err := loader.LoadManifest(configPath)
+	if err != nil {
+		return nil, err
+	}
+	targetName, err := config.NewTargetName(target)
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := loader.LoadTarget(manifest, targetName, filepath.Join(filepath.Dir(configPath), "layers"))
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := resolved.OnboardingTasks()
+	items := make([]OnboardingItem, 0, len(tasks))
+	for _, task := range tasks {
+		item := OnboardingItem{OnboardingTask: task}
+		if at, ok := state.Completed[task.Key()]; ok {
+			item.CompletedAt = &at
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// SetCompleted marks the task with key as completed, or clears it.
+func (s *OnboardingService) SetCompleted(key string, completed bool) error {
+	state, err := s.load()
+	if err != nil {
+		return err
+	}
+	if completed {
+		state.Completed[key] = time.Now().UTC()
+	} else {
+		delete(state.Completed, key)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.statePath), 0o700); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.statePath, data, 0o600)
+}
+
+func (s *OnboardingService) load() (*onboardingState, error) {
+	state := &onboardingState{}
+	data, err := os.ReadFile(s.statePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read onboarding state: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, state); err != nil {
+			return nil, fmt.Errorf("failed to parse onboarding state: %w", err)
+		}
+	}
+	if state.Completed == nil {
+		state.Completed = make(map[string]time.Time)
+	}
+	return state, nil
+}
+
+// OnboardingProgress summarizes a checklist for reports.
+func OnboardingProgress(items []OnboardingItem) (completed int, pending []string) {
+	for _, item := range items {
+		if item.Completed() {
+			completed++
+			continue
+		}
+		pending = append(pending, item.Key())
+	}
+	return completed, pending
+}
@@ -0,0 +1,63 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeOnboardingConfig(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "preflight.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("targets:\n  default: [base, team]\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "layers"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "layers", "base.yaml"), []byte("name: base\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "layers", "team.yaml"), []byte(`name: team
+onboarding:
+  steps:
+    - title: Request VPN access
+    - id: oncall
+      title: Join the on-call rotation
+`), 0o644))
+	return configPath
+}
+
+func TestOnboardingService_Checklist(t *testing.T) {
+	t.Parallel()
+
+	configPath := writeOnboardingConfig(t)
+	svc := NewOnboardingService(t.TempDir())
+
+	items, err := svc.Checklist(configPath, "default")
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, "team/request-vpn-access", items[0].Key())
+	assert.False(t, items[0].Completed())
+
+	require.NoError(t, svc.SetCompleted("team/oncall", true))
+
+	items, err = svc.Checklist(configPath, "default")
+	require.NoError(t, err)
+	assert.False(t, items[0].Completed())
+	assert.True(t, items[1].Completed())
+
+	completed, pending := OnboardingProgress(items)
+	assert.Equal(t, 1, completed)
+	assert.Equal(t, []string{"team/request-vpn-access"}, pending)
+
+	require.NoError(t, svc.SetCompleted("team/oncall", false))
+	items, err = svc.Checklist(configPath, "default")
+	require.NoError(t, err)
+	assert.False(t, items[1].Completed())
+}
+
+func TestOnboardingService_UnknownTarget(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewOnboardingService(t.TempDir()).Checklist(writeOnboardingConfig(t), "work")
+	require.Error(t, err)
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"sort"
 	"strings"
 	"time"
@@ -19,8 +20,13 @@ import (
 	"github.com/felixgeelhaar/preflight/internal/domain/lock"
 	"github.com/felixgeelhaar/preflight/internal/domain/platform"
 	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/provider/brew"
+	"github.com/felixgeelhaar/preflight/internal/provider/macos"
+	"github.com/felixgeelhaar/preflight/internal/provider/mas"
 	"github.com/felixgeelhaar/preflight/internal/provider/nvim"
+	"github.com/felixgeelhaar/preflight/internal/provider/shell"
 	"github.com/felixgeelhaar/preflight/internal/provider/terminal"
+	"github.com/felixgeelhaar/preflight/internal/provider/tmux"
 	"github.com/felixgeelhaar/preflight/internal/templates"
 	"github.com/felixgeelhaar/preflight/internal/validation"
 )
@@ -69,6 +75,7 @@ func defaultCaptureProviders() []string {
 		"ssh",
 		"shell",
 		"terminal",
+		"tmux",
 		"nvim",
 		"vscode",
 		"runtime",
@@ -86,7 +93,7 @@ func defaultCaptureProviders() []string {
 
 	switch plat.OS() {
 	case platform.OSDarwin:
-		return append([]string{"brew"}, common...)
+		return append([]string{"brew", "mas", "macos"}, common...)
 	case platform.OSLinux:
 		return append([]string{"apt"}, common...)
 	case platform.OSWindows:
@@ -108,7 +115,7 @@ func (p *Preflight) captureProvider(ctx context.Context, provider, homeDir strin
 	case "ssh":
 		items = p.captureSSHConfig(homeDir, now, includeSecrets)
 	case "shell":
-		items = p.captureShellConfig(homeDir, now)
+		items = p.captureShellConfig(ctx, homeDir, now)
 	case "nvim":
 		items = p.captureNvimConfig(homeDir, now)
 	case "vscode":
@@ -136,8 +143,14 @@ func (p *Preflight) captureProvider(ctx context.Context, provider, homeDir strin
 		items = p.captureGemPackages(ctx, now)
 	case "cargo":
 		items = p.captureCargoCrates(ctx, now)
+	case "mas":
+		items = p.captureMasApps(ctx, now)
+	case "macos":
+		items = p.captureMacOSDock(ctx, now)
 	case "terminal":
 		items = p.captureTerminalConfig(homeDir, now)
+	case "tmux":
+		items = p.captureTmuxConfig(now)
 	default:
 		return nil, fmt.Errorf("unknown provider: %s", provider)
 	}
@@ -148,28 +161,40 @@ func (p *Preflight) captureProvider(ctx context.Context, provider, homeDir strin
 func (p *Preflight) captureBrewFormulae(_ context.Context, capturedAt time.Time) []CapturedItem {
 	var items []CapturedItem
 
-	// Capture formulae
-	cmd := exec.Command("brew", "list", "--formula", "-1")
-	output, err := cmd.Output()
-	if err == nil {
-		formulae := strings.Split(strings.TrimSpace(string(output)), "\n")
-		for _, f := range formulae {
-			if f == "" {
-				continue
+	// Capture formulae, from each prefix when both an Apple Silicon and
+	// an Intel Homebrew are installed
+	if prefixes := brew.InstalledPrefixes(); len(prefixes) > 1 {
+		listed := make(map[string][]string, len(prefixes))
+		for _, prefix := range prefixes {
+			// #nosec G204 -- prefix is one of the fixed Homebrew prefixes.
+			output, err := exec.Command(brew.BrewPath(prefix), "list", "--formula", "-1").Output()
+			if err == nil {
+				listed[prefix] = strings.Fields(string(output))
+			}
+		}
+		items = append(items, dualPrefixFormulae(listed, capturedAt)...)
+	} else {
+		cmd := exec.Command("brew", "list", "--formula", "-1")
+		output, err := cmd.Output()
+		if err == nil {
+			formulae := strings.Split(strings.TrimSpace(string(output)), "\n")
+			for _, f := range formulae {
+				if f == "" {
+					continue
+				}
+				items = append(items, CapturedItem{
+					Provider:   "brew",
+					Name:       f,
+					Value:      f,
+					Source:     "brew list --formula",
+					CapturedAt: capturedAt,
+				})
 			}
-			items = append(items, CapturedItem{
-				Provider:   "brew",
-				Name:       f,
-				Value:      f,
-				Source:     "brew list --formula",
-				CapturedAt: capturedAt,
-			})
 		}
 	}
 
 	// Capture casks
-	cmd = exec.Command("brew", "list", "--cask", "-1")
-	output, err = cmd.Output()
+	output, err := exec.Command("brew", "list", "--cask", "-1").Output()
 	if err == nil {
 		casks := strings.Split(strings.TrimSpace(string(output)), "\n")
 		for _, c := range casks {
@@ -189,6 +214,39 @@ func (p *Preflight) captureBrewFormulae(_ context.Context, capturedAt time.Time)
 	return items
 }
 
+// dualPrefixFormulae returns the formulae listed in each Homebrew prefix.
+// Formulae in the Apple Silicon prefix are captured as usual; those only
+// in the Intel prefix are captured with the x86_64 architecture so that
+// apply installs them there again.
+func dualPrefixFormulae(listed map[string][]string, capturedAt time.Time) []CapturedItem {
+	native := make(map[string]bool, len(listed[brew.PrefixARM]))
+	var items []CapturedItem
+	for _, f := range listed[brew.PrefixARM] {
+		native[f] = true
+		items = append(items, CapturedItem{
+			Provider:   "brew",
+			Name:       f,
+			Value:      f,
+			Source:     "brew list --formula (" + brew.PrefixARM + ")",
+			CapturedAt: capturedAt,
+		})
+	}
+	for _, f := range listed[brew.PrefixIntel] {
+		if native[f] {
+			continue
+		}
+		items = append(items, CapturedItem{
+			Provider:   "brew",
+			Name:       f,
+			Value:      f,
+			Source:     "brew list --formula (" + brew.PrefixIntel + ")",
+			CapturedAt: capturedAt,
+			Arch:       brew.ArchIntel,
+		})
+	}
+	return items
+}
+
 func (p *Preflight) captureGitConfig(homeDir string, capturedAt time.Time) []CapturedItem {
 	var items []CapturedItem
 
@@ -232,16 +290,16 @@ func (p *Preflight) captureSSHConfig(homeDir string, capturedAt time.Time, _ boo
 	return items
 }
 
-func (p *Preflight) captureShellConfig(homeDir string, capturedAt time.Time) []CapturedItem {
+func (p *Preflight) captureShellConfig(ctx context.Context, homeDir string, capturedAt time.Time) []CapturedItem {
 	var items []CapturedItem
 
-	shellFiles := []string{".zshrc", ".bashrc", ".bash_profile"}
+	shellFiles := []string{".zshrc", ".bashrc", ".bash_profile", ".config/fish/config.fish"}
 	for _, file := range shellFiles {
 		path := filepath.Join(homeDir, file)
 		if _, err := os.Stat(path); err == nil {
 			items = append(items, CapturedItem{
 				Provider:   "shell",
-				Name:       file,
+				Name:       filepath.Base(file),
 				Value:      path,
 				Source:     path,
 				CapturedAt: capturedAt,
@@ -249,7 +307,8 @@ func (p *Preflight) captureShellConfig(homeDir string, capturedAt time.Time) []C
 		}
 	}
 
-	return items
+	discovery := shell.Discover(ctx, homeDir, command.NewRealRunner())
+	return append(items, shellDiscoveryItems(discovery, capturedAt)...)
 }
 
 func (p *Preflight) captureNvimConfig(homeDir string, capturedAt time.Time) []CapturedItem {
@@ -933,6 +992,59 @@ func (p *Preflight) captureCargoCrates(_ context.Context, capturedAt time.Time)
 	return items
 }
 
+// captureMasApps captures apps installed from the Mac App Store.
+func (p *Preflight) captureMasApps(_ context.Context, capturedAt time.Time) []CapturedItem {
+	cmd := exec.Command("mas", "list")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+
+	apps := mas.ParseList(string(output))
+	ids := make([]int64, 0, len(apps))
+	for id := range apps {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	items := make([]CapturedItem, 0, len(ids))
+	for _, id := range ids {
+		items = append(items, CapturedItem{
+			Provider:   "mas",
+			Name:       apps[id],
+			Value:      id,
+			Source:     "mas list",
+			CapturedAt: capturedAt,
+		})
+	}
+	return items
+}
+
+// captureMacOSDock captures the apps in the Dock, in order.
+func (p *Preflight) captureMacOSDock(_ context.Context, capturedAt time.Time) []CapturedItem {
+	output, err := exec.Command("defaults", "export", "com.apple.dock", "-").Output()
+	if err != nil {
+		return nil
+	}
+	apps, err := macos.DockApps(output)
+	if err != nil {
+		return nil
+	}
+
+	items := make([]CapturedItem, 0, len(apps))
+	for _, path := range apps {
+		label := macos.AppLabel(path)
+		items = append(items, CapturedItem{
+			Provider:   "macos",
+			Name:       label,
+			Value:      label,
+			Source:     "com.apple.dock",
+			CapturedAt: capturedAt,
+		})
+	}
+	return items
+}
+
 // captureTerminalConfig discovers installed terminal emulator configurations.
 func (p *Preflight) captureTerminalConfig(homeDir string, capturedAt time.Time) []CapturedItem {
 	discovery := terminal.NewDiscovery()
@@ -958,8 +1070,47 @@ func (p *Preflight) captureTerminalConfig(homeDir string, capturedAt time.Time)
 	return items
 }
 
+// tmuxOptionItem prefixes captured tmux global options, e.g. "option:mouse".
+const tmuxOptionItem = "option:"
+
+// captureTmuxConfig discovers TPM plugins and global options in tmux.conf.
+func (p *Preflight) captureTmuxConfig(capturedAt time.Time) []CapturedItem {
+	configPath := tmux.NewDiscovery().FindConfig()
+	if configPath == "" {
+		return nil
+	}
+	// #nosec G304 -- path comes from tmux config discovery.
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil
+	}
+
+	plugins, settings := tmux.ParseConf(string(content))
+	items := make([]CapturedItem, 0, len(plugins)+len(settings))
+	for _, plugin := range plugins {
+		items = append(items, CapturedItem{
+			Provider:   "tmux",
+			Name:       plugin,
+			Value:      plugin,
+			Source:     configPath,
+			CapturedAt: capturedAt,
+		})
+	}
+	for _, key := range sortedKeys(settings) {
+		items = append(items, CapturedItem{
+			Provider:   "tmux",
+			Name:       tmuxOptionItem + key,
+			Value:      settings[key],
+			Source:     configPath,
+			CapturedAt: capturedAt,
+		})
+	}
+	return items
+}
+
 // Doctor checks system state against configuration and reports issues.
 func (p *Preflight) Doctor(ctx context.Context, opts DoctorOptions) (*DoctorReport, error) {
+	ctx = p.logContext(ctx)
 	startTime := time.Now()
 
 	report := &DoctorReport{
@@ -970,21 +1121,115 @@ func (p *Preflight) Doctor(ctx context.Context, opts DoctorOptions) (*DoctorRepo
 		CheckedAt:    startTime,
 	}
 
-	// Load and compile configuration
-	plan, err := p.Plan(ctx, opts.ConfigPath, opts.Target)
+	// Load and compile configuration, checking providers concurrently
+	planner := p.planner.WithParallelism(opts.Parallelism).WithProviderTimeout(opts.CheckTimeout)
+	plan, err := p.planWith(ctx, planner, opts.ConfigPath, opts.Target)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create plan: %w", err)
 	}
 
 	// Check each step for drift
+	addDriftIssues(plan, opts.CheckTimeout, "preflight apply", report)
+
+	// Flag downloaded artifacts installed without checksum verification
+	checkArtifactVerification(plan, report)
+
+	configPath, target := opts.ConfigPath, opts.Target
+	runDoctorChecks(ctx, []doctorCheck{
+		// Run provider-specific doctor checks
+		{"nvim", func(ctx context.Context, r *DoctorReport) { p.runProviderDoctorChecks(ctx, plan, r) }},
+		// Flag missing or shadowed PATH entries
+		{"path", func(_ context.Context, r *DoctorReport) { checkPathEntries(configPath, target, r) }},
+		// Flag a missing direnv binary and stale generated .envrc files
+		{"direnv", func(_ context.Context, r *DoctorReport) { checkDirenv(configPath, target, exec.LookPath, r) }},
+		// Flag an outdated tmux or a server running with stale settings
+		{"tmux", func(ctx context.Context, r *DoctorReport) {
+			checkTmux(configPath, target, tmux.ConfigPath(), commandOutput(ctx), r)
+		}},
+		// Flag a missing container engine or a daemon that does not respond
+		{"container", func(ctx context.Context, r *DoctorReport) {
+			checkContainerRuntime(configPath, target, commandOutput(ctx), r)
+		}},
+		// Flag declared kube contexts that are missing, stale or unreachable
+		{"kube", func(ctx context.Context, r *DoctorReport) {
+			checkKubeContexts(configPath, target, commandOutput(ctx), r)
+		}},
+		// Flag cloud CLI profiles whose credentials are missing or expired
+		{"cloud", func(ctx context.Context, r *DoctorReport) {
+			checkCloudProfiles(configPath, target, commandOutput(ctx), r)
+		}},
+		// Flag password manager CLIs that are missing or signed out
+		{"password-manager", func(ctx context.Context, r *DoctorReport) {
+			home, _ := os.UserHomeDir()
+			checkPasswordManagers(configPath, target, runtime.GOOS, home, commandOutput(ctx), r)
+		}},
+		// Flag declared App Store apps that are missing
+		{"mas", func(ctx context.Context, r *DoctorReport) { checkMasApps(configPath, target, commandOutput(ctx), r) }},
+		// Flag content excluded from sync that a git push would still share
+		{"sync", func(ctx context.Context, r *DoctorReport) { checkSyncExclusions(ctx, configPath, r) }},
+		// Flag PATH shadowing between the Apple Silicon and Intel Homebrew
+		{"brew-prefix", func(ctx context.Context, r *DoctorReport) {
+			checkBrewPrefixes(configPath, target, brew.InstalledPrefixes(), os.Getenv("PATH"), commandOutput(ctx), r)
+		}},
+		// Flag a global gitignore git does not read and hand-written files
+		// that apply would have to overwrite
+		{"managed-files", func(ctx context.Context, r *DoctorReport) {
+			home, _ := os.UserHomeDir()
+			checkManagedFiles(configPath, target, home, commandOutput(ctx), r)
+		}},
+		// Flag pins held longer than upgrades.pin_max_age
+		{"pins", func(_ context.Context, r *DoctorReport) { checkPins(configPath, target, time.Now(), r) }},
+		// Run the checks declared by layers and plugins
+		{"custom", func(ctx context.Context, r *DoctorReport) {
+			sources := append(layerDoctorChecks(configPath, target), p.plugins.doctorChecks(r)...)
+			checkCustom(ctx, sources, runShell, r)
+		}},
+	}, opts.Parallelism, opts.CheckTimeout, report)
+
+	// Track packages installed outside preflight if UpdateConfig is enabled
+	if opts.UpdateConfig {
+		updates, err := p.reverseSync(ctx, opts.ConfigPath, opts.Target, opts.SplitStrategy, commandOutput(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("failed to find untracked packages: %w", err)
+		}
+		report.LayerUpdates = updates
+		for _, update := range updates {
+			report.SuggestedPatches = append(report.SuggestedPatches, update.Patches...)
+		}
+	}
+
+	report.Duration = time.Since(startTime)
+	for _, name := range report.TimedOut {
+		p.logger.Warn(ctx, "doctor checks timed out", ports.F("check", name), ports.F("timeout", opts.CheckTimeout))
+	}
+	p.logger.Info(ctx, "doctor finished",
+		ports.F("issues", report.IssueCount()),
+		ports.F("duration", report.Duration))
+	return report, nil
+}
+
+// addDriftIssues reports the plan steps that need applying, failed or
+// could not be checked. fixCommand is the command that applies the plan.
+func addDriftIssues(plan *execution.Plan, checkTimeout time.Duration, fixCommand string, report *DoctorReport) {
+	timedOut := make(map[string]int)
+	var timedOutProviders []string
 	for _, entry := range plan.Entries() {
 		status := entry.Status()
 		step := entry.Step()
 
+		if entry.TimedOut() {
+			provider := step.ID().Provider()
+			if timedOut[provider] == 0 {
+				timedOutProviders = append(timedOutProviders, provider)
+			}
+			timedOut[provider]++
+			continue
+		}
+
 		switch status {
 		case compiler.StatusNeedsApply:
 			diff := entry.Diff()
-			report.Issues = append(report.Issues, DoctorIssue{
+			issue := DoctorIssue{
 				Provider:   step.ID().Provider(),
 				StepID:     step.ID().String(),
 				Severity:   SeverityWarning,
@@ -992,8 +1237,17 @@ func (p *Preflight) Doctor(ctx context.Context, opts DoctorOptions) (*DoctorRepo
 				Expected:   diff.Summary(),
 				Actual:     "current state differs",
 				Fixable:    true,
-				FixCommand: "preflight apply",
-			})
+				FixCommand: fixCommand,
+			}
+			// Steps that apply cannot change on this platform carry instructions instead
+			if manual, ok := step.(compiler.ManualStep); ok {
+				if fix := manual.ManualFix(); fix != "" {
+					issue.Message = "Configuration drift detected; fix manually"
+					issue.Fixable = false
+					issue.FixCommand = fix
+				}
+			}
+			report.Issues = append(report.Issues, issue)
 
 		case compiler.StatusFailed:
 			report.Issues = append(report.Issues, DoctorIssue{
@@ -1018,22 +1272,37 @@ func (p *Preflight) Doctor(ctx context.Context, opts DoctorOptions) (*DoctorRepo
 		}
 	}
 
-	// Run provider-specific doctor checks
-	p.runProviderDoctorChecks(ctx, plan, report)
+	for _, provider := range timedOutProviders {
+		report.Issues = append(report.Issues, timeoutIssue(provider, checkTimeout,
+			fmt.Sprintf("%d steps not checked", timedOut[provider])))
+		report.TimedOut = append(report.TimedOut, provider)
+	}
+}
 
-	// Generate config patches if UpdateConfig is enabled
-	if opts.UpdateConfig && len(report.Issues) > 0 {
-		configDir := filepath.Dir(opts.ConfigPath)
-		driftService, err := DefaultDriftService()
-		if err == nil {
-			generator := NewPatchGenerator(driftService)
-			patches := generator.GenerateFromIssues(report.Issues, configDir)
-			report.SuggestedPatches = patches
+// checkArtifactVerification reports installed artifacts that are not pinned to a checksum.
+func checkArtifactVerification(plan *execution.Plan, report *DoctorReport) {
+	for _, entry := range plan.Entries() {
+		if entry.Status() != compiler.StatusSatisfied {
+			continue
+		}
+		verifiable, ok := entry.Step().(compiler.VerifiableStep)
+		if !ok {
+			continue
 		}
+		info, ok := verifiable.ArtifactInfo()
+		if !ok || info.Verified() {
+			continue
+		}
+		report.Issues = append(report.Issues, DoctorIssue{
+			Provider: entry.Step().ID().Provider(),
+			StepID:   entry.Step().ID().String(),
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("Artifact '%s' installed without checksum verification", info.Name),
+			Expected: "checksum pinned in layer",
+			Actual:   "no checksum for " + info.Source,
+			Fixable:  false,
+		})
 	}
-
-	report.Duration = time.Since(startTime)
-	return report, nil
 }
 
 // runProviderDoctorChecks runs health checks for providers used in the plan.
@@ -1134,15 +1403,28 @@ func (p *Preflight) Fix(ctx context.Context, report *DoctorReport) (*FixResult,
 		}, nil
 	}
 
-	// Re-run plan and apply
-	plan, err := p.Plan(ctx, report.ConfigPath, report.Target)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create fix plan: %w", err)
+	// Run the fix commands of custom checks; verification below tells
+	// whether they worked
+	needsApply := false
+	for _, issue := range fixableIssues {
+		if !issue.RunFix {
+			needsApply = true
+			continue
+		}
+		_, _, _ = runShell(ctx, issue.FixCommand)
 	}
 
-	_, err = p.Apply(ctx, plan, false)
-	if err != nil {
-		return nil, fmt.Errorf("failed to apply fixes: %w", err)
+	if needsApply {
+		// Re-run plan and apply
+		plan, err := p.Plan(ctx, report.ConfigPath, report.Target)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create fix plan: %w", err)
+		}
+
+		_, err = p.Apply(ctx, plan, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply fixes: %w", err)
+		}
 	}
 
 	// Verify by re-running doctor
@@ -1299,7 +1581,11 @@ func (p *Preflight) LockFreeze(ctx context.Context, configPath string) error {
 	lockfile = lockfile.WithMode(config.ModeFrozen)
 
 	// Save the frozen lockfile
-	if err := p.lockRepo.Save(ctx, lockPath, lockfile); err != nil {
+	repo, err := p.lockRepoFor(configPath)
+	if err != nil {
+		return err
+	}
+	if err := repo.Save(ctx, lockPath, lockfile); err != nil {
 		return fmt.Errorf("failed to save lockfile: %w", err)
 	}
 
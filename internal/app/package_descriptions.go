@@ -0,0 +1,65 @@
+package app
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+)
+
+// PackageDescriber looks up one-line descriptions of captured packages,
+// keyed by package name. Packages it knows nothing about are left out.
+type PackageDescriber func(ctx context.Context, items []CapturedItem) map[string]string
+
+// BrewDescriptions describes Homebrew formulae and casks with `brew desc`,
+// which reads the local tap metadata and works offline.
+func BrewDescriptions(ctx context.Context, items []CapturedItem) map[string]string {
+	var formulae, casks []string
+	for _, item := range items {
+		switch item.Provider {
+		case "brew":
+			formulae = append(formulae, item.Name)
+		case "brew-cask":
+			casks = append(casks, item.Name)
+		}
+	}
+
+	descriptions := make(map[string]string)
+	for _, group := range []struct {
+		flag  string
+		names []string
+	}{
+		{"--formula", formulae},
+		{"--cask", casks},
+	} {
+		if len(group.names) == 0 {
+			continue
+		}
+		args := append([]string{"desc", group.flag}, group.names...)
+		// #nosec G204 -- package names come from `brew list`.
+		output, err := exec.CommandContext(ctx, "brew", args...).Output()
+		if err != nil {
+			continue
+		}
+		for name, description := range parseBrewDesc(string(output)) {
+			descriptions[name] = description
+		}
+	}
+	return descriptions
+}
+
+// parseBrewDesc parses `brew desc` output. Formulae are listed as
+// "name: description", casks as "name: (App Name) description".
+func parseBrewDesc(output string) map[string]string {
+	descriptions := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		name, description, ok := strings.Cut(line, ": ")
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			continue
+		}
+		description = strings.TrimSpace(description)
+		if description != "" && description != "[no description]" {
+			descriptions[name] = description
+		}
+	}
+	return descriptions
+}
@@ -0,0 +1,24 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBrewDesc(t *testing.T) {
+	t.Parallel()
+
+	output := `==> Formulae
+hyperfine: Command-line benchmarking tool
+xh: Friendly and fast tool for sending HTTP requests
+oddity: [no description]
+==> Casks
+rectangle: (Rectangle) Move and resize windows using keyboard shortcuts or snap areas
+`
+	assert.Equal(t, map[string]string{
+		"hyperfine": "Command-line benchmarking tool",
+		"xh":        "Friendly and fast tool for sending HTTP requests",
+		"rectangle": "(Rectangle) Move and resize windows using keyboard shortcuts or snap areas",
+	}, parseBrewDesc(output))
+}
@@ -0,0 +1,191 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/provider/passwordmanager"
+)
+
+// passwordManagerProvider is the provider of the password manager steps.
+const passwordManagerProvider = "password_manager"
+
+// checkPasswordManagers verifies that each declared password manager CLI
+// is installed and signed in, telling the two apart so the fix is clear,
+// and that SSH uses the 1Password agent when ssh_agent is declared.
+func checkPasswordManagers(configPath, targetName, goos, home string, run func(string, ...string) (string, error), report *DoctorReport) {
+	target, err := config.NewTargetName(targetName)
+	if err != nil {
+		return
+	}
+	merged, err := config.NewLoader().Load(configPath, target)
+	if err != nil || merged.PasswordManager.IsZero() {
+		return
+	}
+
+	if op := merged.PasswordManager.OnePassword; op != nil && checkCLIInstalled(passwordmanager.OnePasswordCLI, goos, run, report) {
+		checkOnePasswordSignIn(op.Account, run, report)
+		if socket := passwordmanager.AgentSocket(goos); op.SSHAgent && socket != "" {
+			checkOnePasswordAgent(socket, home, report)
+		}
+	}
+	if bw := merged.PasswordManager.Bitwarden; bw != nil && checkCLIInstalled(passwordmanager.BitwardenCLI, goos, run, report) {
+		checkBitwardenLogin(bw.Email, run, report)
+	}
+}
+
+// checkCLIInstalled reports a CLI that is not installed. Apply installs it
+// where there is a package for goos.
+func checkCLIInstalled(cli passwordmanager.CLI, goos string, run func(string, ...string) (string, error), report *DoctorReport) bool {
+	if _, err := run(cli.Command, "--version"); err == nil {
+		return true
+	}
+	issue := DoctorIssue{
+		Provider:   passwordManagerProvider,
+		StepID:     passwordManagerProvider + ":" + cli.Name + ":install",
+		Severity:   SeverityWarning,
+		Message:    fmt.Sprintf("%s (%s) is not installed; secret://%s references cannot resolve", cli.Title, cli.Command, cli.Name),
+		Expected:   cli.Command + " installed",
+		Actual:     "not found",
+		FixCommand: "see " + cli.Docs,
+	}
+	if install := cli.InstallCommand(goos); install != nil {
+		issue.Fixable = true
+		issue.FixCommand = strings.Join(install, " ")
+	}
+	report.Issues = append(report.Issues, issue)
+	return false
+}
+
+// checkOnePasswordSignIn reports an account that was never added to op
+// separately from one that is only signed out.
+func checkOnePasswordSignIn(account string, run func(string, ...string) (string, error), report *DoctorReport) {
+	whoami := []string{"whoami"}
+	signin := "op signin"
+	if account != "" {
+		whoami = append(whoami, "--account", account)
+		signin += " --account " + account
+	}
+	if _, err := run("op", whoami...); err == nil {
+		return
+	}
+
+	label := "account"
+	if account != "" {
+		label = "account " + account
+	}
+	issue := DoctorIssue{
+		Provider:   passwordManagerProvider,
+		StepID:     passwordManagerProvider + ":1password:account",
+		Severity:   SeverityWarning,
+		Message:    fmt.Sprintf("1Password CLI is not signed in to the %s", label),
+		Expected:   "signed in",
+		Actual:     "not signed in",
+		FixCommand: signin,
+	}
+	if !onePasswordAccountAdded(account, run) {
+		issue.Message = fmt.Sprintf("1Password %s is not added to op", label)
+		issue.Actual = "not added"
+		issue.FixCommand = "op account add"
+		switch {
+		case strings.Contains(account, "@"):
+			issue.FixCommand += " --email " + account
+		case account != "":
+			issue.FixCommand += " --address " + account
+		}
+	}
+	report.Issues = append(report.Issues, issue)
+}
+
+// onePasswordAccountAdded reports whether op knows the account, or any
+// account when account is empty.
+func onePasswordAccountAdded(account string, run func(string, ...string) (string, error)) bool {
+	out, err := run("op", "account", "list", "--format=json")
+	if err != nil {
+		return false
+	}
+	accounts, err := passwordmanager.ParseOnePasswordAccounts(out)
+	if err != nil {
+		return false
+	}
+	for _, a := range accounts {
+		if account == "" || a.Matches(account) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkOnePasswordAgent reports an agent that is not running and an SSH
+// config that does not use it.
+func checkOnePasswordAgent(socket, home string, report *DoctorReport) {
+	expand := func(path string) string {
+		return filepath.Join(home, strings.TrimPrefix(path, "~/"))
+	}
+	if _, err := os.Stat(expand(socket)); err != nil {
+		report.Issues = append(report.Issues, DoctorIssue{
+			Provider:   passwordManagerProvider,
+			StepID:     passwordManagerProvider + ":1password:ssh-agent",
+			Severity:   SeverityWarning,
+			Message:    "1Password SSH agent is not running",
+			Expected:   socket,
+			Actual:     "no socket",
+			FixCommand: "turn on the SSH agent in 1Password > Settings > Developer",
+		})
+	}
+	sshConfig, err := os.ReadFile(expand("~/.ssh/config"))
+	if err != nil || !passwordmanager.UsesAgent(sshConfig, socket) {
+		report.Issues = append(report.Issues, DoctorIssue{
+			Provider:   passwordManagerProvider,
+			StepID:     passwordManagerProvider + ":1password:ssh-agent",
+			Severity:   SeverityWarning,
+			Message:    "~/.ssh/config does not use the 1Password SSH agent",
+			Expected:   "IdentityAgent " + socket,
+			Actual:     "not set",
+			Fixable:    true,
+			FixCommand: "preflight apply",
+		})
+	}
+}
+
+// checkBitwardenLogin reports a CLI that is logged out or logged in to
+// another account, and notes a locked vault.
+func checkBitwardenLogin(email string, run func(string, ...string) (string, error), report *DoctorReport) {
+	login := strings.TrimSpace("bw login " + email)
+	issue := DoctorIssue{
+		Provider: passwordManagerProvider,
+		StepID:   passwordManagerProvider + ":bitwarden:login",
+		Severity: SeverityWarning,
+		Expected: "logged in",
+	}
+
+	out, err := run("bw", "status")
+	status, parseErr := passwordmanager.ParseBitwardenStatus(out)
+	switch {
+	case err != nil || parseErr != nil:
+		issue.Message = "Bitwarden CLI status could not be read"
+		issue.Actual = "unknown"
+		issue.FixCommand = "bw status"
+	case !status.LoggedIn():
+		issue.Message = "Bitwarden CLI is not logged in"
+		issue.Actual = "not logged in"
+		issue.FixCommand = login
+	case email != "" && !strings.EqualFold(status.UserEmail, email):
+		issue.Message = fmt.Sprintf("Bitwarden CLI is logged in as %s, not %s", status.UserEmail, email)
+		issue.Expected = "logged in as " + email
+		issue.Actual = "logged in as " + status.UserEmail
+		issue.FixCommand = "bw logout && " + login
+	case status.Status == "locked":
+		issue.Severity = SeverityInfo
+		issue.Message = "Bitwarden vault is locked; secret://bitwarden references resolve once it is unlocked"
+		issue.Expected = "unlocked"
+		issue.Actual = "locked"
+		issue.FixCommand = `export BW_SESSION="$(bw unlock --raw)"`
+	default:
+		return
+	}
+	report.Issues = append(report.Issues, issue)
+}
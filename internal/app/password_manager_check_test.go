@@ -0,0 +1,133 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePasswordManagerConfig(t *testing.T, section string) string {
+	t.Helper()
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "preflight.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("targets:\n  default: [base]\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "layers"), 0o755))
+	layer := "name: base\npassword_manager:\n" + section
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "layers", "base.yaml"), []byte(layer), 0o644))
+	return configPath
+}
+
+func TestCheckPasswordManagers_NotInstalled(t *testing.T) {
+	t.Parallel()
+
+	configPath := writePasswordManagerConfig(t, "  1password:\n    account: acme.1password.com\n  bitwarden: {}\n")
+	report := &DoctorReport{}
+	checkPasswordManagers(configPath, "default", "darwin", t.TempDir(), fakeEngine(nil), report)
+
+	require.Len(t, report.Issues, 2)
+	assert.Equal(t, "password_manager:1password:install", report.Issues[0].StepID)
+	assert.Equal(t, "not found", report.Issues[0].Actual)
+	assert.Equal(t, "brew install --cask 1password-cli", report.Issues[0].FixCommand)
+	assert.True(t, report.Issues[0].Fixable)
+	assert.Equal(t, "password_manager:bitwarden:install", report.Issues[1].StepID)
+}
+
+func TestCheckPasswordManagers_NotInstallableOnLinux(t *testing.T) {
+	t.Parallel()
+
+	configPath := writePasswordManagerConfig(t, "  1password: {}\n")
+	report := &DoctorReport{}
+	checkPasswordManagers(configPath, "default", "linux", t.TempDir(), fakeEngine(nil), report)
+
+	require.Len(t, report.Issues, 1)
+	assert.False(t, report.Issues[0].Fixable)
+	assert.Contains(t, report.Issues[0].FixCommand, "developer.1password.com")
+}
+
+func TestCheckPasswordManagers_OnePasswordSignIn(t *testing.T) {
+	t.Parallel()
+
+	configPath := writePasswordManagerConfig(t, "  1password:\n    account: acme.1password.com\n")
+
+	// The account was added but the session expired
+	report := &DoctorReport{}
+	checkPasswordManagers(configPath, "default", "darwin", t.TempDir(), fakeEngine(map[string]string{
+		"op --version":                  "2.30.0",
+		"op account list --format=json": `[{"url": "acme.1password.com", "email": "jane@acme.com"}]`,
+	}), report)
+	require.Len(t, report.Issues, 1)
+	assert.Equal(t, "not signed in", report.Issues[0].Actual)
+	assert.Equal(t, "op signin --account acme.1password.com", report.Issues[0].FixCommand)
+
+	// The account was never added
+	report = &DoctorReport{}
+	checkPasswordManagers(configPath, "default", "darwin", t.TempDir(), fakeEngine(map[string]string{
+		"op --version":                  "2.30.0",
+		"op account list --format=json": `[]`,
+	}), report)
+	require.Len(t, report.Issues, 1)
+	assert.Equal(t, "not added", report.Issues[0].Actual)
+	assert.Equal(t, "op account add --address acme.1password.com", report.Issues[0].FixCommand)
+
+	report = &DoctorReport{}
+	checkPasswordManagers(configPath, "default", "darwin", t.TempDir(), fakeEngine(map[string]string{
+		"op --version":                           "2.30.0",
+		"op whoami --account acme.1password.com": "Email: jane@acme.com",
+	}), report)
+	assert.Empty(t, report.Issues)
+}
+
+func TestCheckPasswordManagers_SSHAgent(t *testing.T) {
+	t.Parallel()
+
+	configPath := writePasswordManagerConfig(t, "  1password:\n    ssh_agent: true\n")
+	run := fakeEngine(map[string]string{"op --version": "2.30.0", "op whoami": "Email: jane@acme.com"})
+
+	home := t.TempDir()
+	report := &DoctorReport{}
+	checkPasswordManagers(configPath, "default", "linux", home, run, report)
+	require.Len(t, report.Issues, 2)
+	assert.Equal(t, "1Password SSH agent is not running", report.Issues[0].Message)
+	assert.Equal(t, "preflight apply", report.Issues[1].FixCommand)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".1password"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".1password", "agent.sock"), nil, 0o600))
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".ssh"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".ssh", "config"), []byte("Host *\n    IdentityAgent \"~/.1password/agent.sock\"\n"), 0o600))
+	report = &DoctorReport{}
+	checkPasswordManagers(configPath, "default", "linux", home, run, report)
+	assert.Empty(t, report.Issues)
+}
+
+func TestCheckPasswordManagers_Bitwarden(t *testing.T) {
+	t.Parallel()
+
+	configPath := writePasswordManagerConfig(t, "  bitwarden:\n    email: jane@acme.com\n")
+	tests := []struct {
+		name   string
+		status string
+		actual string
+		fix    string
+	}{
+		{"logged out", `{"status": "unauthenticated"}`, "not logged in", "bw login jane@acme.com"},
+		{"other account", `{"status": "unlocked", "userEmail": "joe@acme.com"}`, "logged in as joe@acme.com", "bw logout && bw login jane@acme.com"},
+		{"locked", `{"status": "locked", "userEmail": "jane@acme.com"}`, "locked", `export BW_SESSION="$(bw unlock --raw)"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			report := &DoctorReport{}
+			checkPasswordManagers(configPath, "default", "darwin", t.TempDir(), fakeEngine(map[string]string{
+				"bw --version": "2024.6.0",
+				"bw status":    tt.status,
+			}), report)
+			require.Len(t, report.Issues, 1)
+			assert.Equal(t, tt.actual, report.Issues[0].Actual)
+			assert.Equal(t, tt.fix, report.Issues[0].FixCommand)
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
+	"github.com/felixgeelhaar/preflight/internal/securestate"
+)
+
+// Sources of pending patches.
+const (
+	PatchSourceDoctor  = "doctor"
+	PatchSourceAgent   = "agent"
+	PatchSourceHistory = "history"
+)
+
+// ErrPendingPatchesNotFound is returned for an unknown pending patch set.
+var ErrPendingPatchesNotFound = errors.New("pending patches not found")
+
+// PendingPatches are layer updates saved for review with 'preflight
+// patches' instead of being applied right away.
+type PendingPatches struct {
+	ID         string        `json:"id"`
+	Source     string        `json:"source"`
+	ConfigPath string        `json:"config_path"`
+	Target     string        `json:"target"`
+	CreatedAt  time.Time     `json:"created_at"`
+	Updates    []LayerUpdate `json:"updates"`
+}
+
+// NewPendingPatches creates a pending patch set. configPath is made
+// absolute so the set can be applied from any directory.
+func NewPendingPatches(source, configPath, target string, updates []LayerUpdate) PendingPatches {
+	if abs, err := filepath.Abs(configPath); err == nil {
+		configPath = abs
+	}
+	return PendingPatches{
+		Source:     source,
+		ConfigPath: configPath,
+		Target:     target,
+		CreatedAt:  time.Now().UTC(),
+		Updates:    updates,
+	}
+}
+
+// PatchCount returns the number of patches in the set.
+func (p PendingPatches) PatchCount() int {
+	count := 0
+	for _, update := range p.Updates {
+		count += len(update.Patches)
+	}
+	return count
+}
+
+// Layers returns the layers the set updates.
+func (p PendingPatches) Layers() []string {
+	layers := make([]string, 0, len(p.Updates))
+	for _, update := range p.Updates {
+		layers = append(layers, update.Layer)
+	}
+	return layers
+}
+
+// PatchStore keeps pending patch sets as JSON files in a directory,
+// encrypted when state encryption is enabled.
+type PatchStore struct {
+	dir string
+}
+
+// NewPatchStore creates a patch store in dir.
+func NewPatchStore(dir string) *PatchStore {
+	return &PatchStore{dir: dir}
+}
+
+// DefaultPatchStore returns the patch store in patches in the state
+// directory.
+func DefaultPatchStore() (*PatchStore, error) {
+	dir, err := platform.Path(platform.StateDir, "patches")
+	if err != nil {
+		return nil, err
+	}
+	return NewPatchStore(dir), nil
+}
+
+// Save stores set and returns its ID. Sets saved earlier from the same
+// source for the same configuration and target are replaced: the new set
+// is a fresh look at the system. An empty set only removes those.
+func (s *PatchStore) Save(set PendingPatches) (string, error) {
+	existing, err := s.List()
+	if err != nil {
+		return "", err
+	}
+	for _, old := range existing {
+		if old.Source == set.Source && old.ConfigPath == set.ConfigPath && old.Target == set.Target {
+			if err := s.Discard(old.ID); err != nil {
+				return "", err
+			}
+		}
+	}
+	if len(set.Updates) == 0 {
+		return "", nil
+	}
+
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create patch directory: %w", err)
+	}
+	base := set.CreatedAt.UTC().Format("20060102T150405Z") + "-" + set.Source
+	set.ID = base
+	for i := 2; ; i++ {
+		if _, err := os.Stat(s.path(set.ID)); os.IsNotExist(err) {
+			break
+		}
+		set.ID = fmt.Sprintf("%s-%d", base, i)
+	}
+
+	data, err := json.MarshalIndent(set, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := securestate.WriteFile(s.path(set.ID), append(data, '\n'), 0o600); err != nil {
+		return "", fmt.Errorf("failed to write pending patches: %w", err)
+	}
+	return set.ID, nil
+}
+
+// List returns the pending patch sets, oldest first.
+func (s *PatchStore) List() ([]PendingPatches, error) {
+	entries, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read patch directory: %w", err)
+	}
+
+	var sets []PendingPatches
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		set, err := s.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, *set)
+	}
+	sort.Slice(sets, func(i, j int) bool {
+		if !sets[i].CreatedAt.Equal(sets[j].CreatedAt) {
+			return sets[i].CreatedAt.Before(sets[j].CreatedAt)
+		}
+		return sets[i].ID < sets[j].ID
+	})
+	return sets, nil
+}
+
+// Get returns the pending patch set with the ID.
+func (s *PatchStore) Get(id string) (*PendingPatches, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || strings.HasPrefix(id, ".") {
+		return nil, fmt.Errorf("%w: %s", ErrPendingPatchesNotFound, id)
+	}
+	data, err := securestate.ReadFile(s.path(id))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s", ErrPendingPatchesNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pending patches %s: %w", id, err)
+	}
+	var set PendingPatches
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid pending patches %s: %w", id, err)
+	}
+	set.ID = id
+	return &set, nil
+}
+
+// Discard deletes the pending patch set with the ID.
+func (s *PatchStore) Discard(id string) error {
+	if _, err := s.Get(id); err != nil {
+		return err
+	}
+	if err := os.Remove(s.path(id)); err != nil {
+		return fmt.Errorf("failed to discard pending patches %s: %w", id, err)
+	}
+	return nil
+}
+
+func (s *PatchStore) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
@@ -0,0 +1,138 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/adapters/age"
+	"github.com/felixgeelhaar/preflight/internal/adapters/keychain"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/securestate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatchStore(t *testing.T) {
+	t.Parallel()
+
+	store := NewPatchStore(filepath.Join(t.TempDir(), "patches"))
+	sets, err := store.List()
+	require.NoError(t, err)
+	assert.Empty(t, sets)
+
+	layer := filepath.Join(t.TempDir(), "layers", "misc.yaml")
+	update := LayerUpdate{
+		Layer:    "misc",
+		Path:     layer,
+		Packages: []string{"jq"},
+		Patches:  []ConfigPatch{NewConfigPatch(layer, "packages.brew.formulae", PatchOpAdd, nil, "jq", "doctor")},
+	}
+	doctor := NewPendingPatches(PatchSourceDoctor, "preflight.yaml", "default", []LayerUpdate{update})
+	assert.True(t, filepath.IsAbs(doctor.ConfigPath))
+	doctor.CreatedAt = time.Date(2026, 10, 16, 9, 15, 0, 0, time.UTC)
+
+	id, err := store.Save(doctor)
+	require.NoError(t, err)
+	assert.Equal(t, "20261016T091500Z-doctor", id)
+
+	agent := NewPendingPatches(PatchSourceAgent, "preflight.yaml", "default", []LayerUpdate{update, update})
+	agentID, err := store.Save(agent)
+	require.NoError(t, err)
+
+	sets, err = store.List()
+	require.NoError(t, err)
+	require.Len(t, sets, 2)
+	assert.Equal(t, id, sets[0].ID)
+	assert.Equal(t, 1, sets[0].PatchCount())
+	assert.Equal(t, []string{"misc", "misc"}, sets[1].Layers())
+
+	got, err := store.Get(id)
+	require.NoError(t, err)
+	assert.Equal(t, "jq", got.Updates[0].Patches[0].NewValue)
+
+	// A newer set from the same source replaces the older one
+	doctor.CreatedAt = doctor.CreatedAt.Add(time.Hour)
+	newID, err := store.Save(doctor)
+	require.NoError(t, err)
+	assert.Equal(t, "20261016T101500Z-doctor", newID)
+	_, err = store.Get(id)
+	require.ErrorIs(t, err, ErrPendingPatchesNotFound)
+
+	// An empty set only removes the older one
+	empty, err := store.Save(NewPendingPatches(PatchSourceAgent, "preflight.yaml", "default", nil))
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+	_, err = store.Get(agentID)
+	require.ErrorIs(t, err, ErrPendingPatchesNotFound)
+
+	require.NoError(t, store.Discard(newID))
+	sets, err = store.List()
+	require.NoError(t, err)
+	assert.Empty(t, sets)
+
+	require.ErrorIs(t, store.Discard("../escape"), ErrPendingPatchesNotFound)
+}
+
+func TestPatchStore_AppliesAfterRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	layer := filepath.Join(dir, "layers", "editor.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(layer), 0o755))
+
+	store := NewPatchStore(filepath.Join(dir, "patches"))
+	update := LayerUpdate{
+		Layer:    "editor",
+		Path:     layer,
+		Packages: []string{"golang.go"},
+		Creates:  true,
+		Patches: []ConfigPatch{
+			NewConfigPatch(layer, "name", PatchOpAdd, nil, "editor", "doctor"),
+			NewConfigPatch(layer, "vscode.extensions", PatchOpAdd, nil, []string{"golang.go"}, "doctor"),
+		},
+	}
+	id, err := store.Save(NewPendingPatches(PatchSourceDoctor, filepath.Join(dir, "preflight.yaml"), "default", []LayerUpdate{update}))
+	require.NoError(t, err)
+
+	set, err := store.Get(id)
+	require.NoError(t, err)
+	_, err = ApplyLayerUpdate(t.Context(), set.Updates[0])
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(layer)
+	require.NoError(t, err)
+	assert.Equal(t, "name: editor\nvscode:\n  extensions:\n    - golang.go\n", string(data))
+}
+
+func TestPatchStore_Encrypted(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("PREFLIGHT_HOME", home)
+	kc := keychain.NewFileKeychain(filepath.Join(t.TempDir(), "keychain.json"))
+	oldKeychain := securestate.Keychain
+	securestate.Keychain = func() ports.Keychain { return kc }
+	defer func() { securestate.Keychain = oldKeychain }()
+	_, err := securestate.Enable(time.Now())
+	require.NoError(t, err)
+
+	store, err := DefaultPatchStore()
+	require.NoError(t, err)
+	layer := filepath.Join(t.TempDir(), "layers", "misc.yaml")
+	set := NewPendingPatches(PatchSourceAgent, "preflight.yaml", "default", []LayerUpdate{{
+		Layer:   "misc",
+		Path:    layer,
+		Patches: []ConfigPatch{NewConfigPatch(layer, "packages.brew.formulae", PatchOpAdd, nil, "jq", "agent")},
+	}})
+	id, err := store.Save(set)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(home, "patches", id+".json"))
+	require.NoError(t, err)
+	assert.True(t, age.IsEncrypted(data))
+	assert.NotContains(t, string(data), "jq")
+
+	got, err := store.Get(id)
+	require.NoError(t, err)
+	assert.Equal(t, 1, got.PatchCount())
+}
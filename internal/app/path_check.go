@@ -0,0 +1,133 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+)
+
+// maxShadowedListed caps how many shadowed commands one issue names.
+const maxShadowedListed = 5
+
+// checkPathEntries reports configured PATH entries that do not exist, are
+// not on the current PATH, or whose commands are shadowed by a directory
+// earlier on PATH (e.g. /usr/bin/git hiding the Homebrew git).
+func checkPathEntries(configPath, targetName string, report *DoctorReport) {
+	target, err := config.NewTargetName(targetName)
+	if err != nil {
+		return
+	}
+	merged, err := config.NewLoader().Load(configPath, target)
+	if err != nil || merged.Path.IsZero() {
+		return
+	}
+	home, _ := os.UserHomeDir()
+	report.Issues = append(report.Issues, diagnosePath(merged.Path.Expand(home), os.Getenv("PATH"))...)
+}
+
+// diagnosePath checks the configured entries against pathEnv, the value of
+// PATH in the current environment.
+func diagnosePath(cfg config.PathConfig, pathEnv string) []DoctorIssue {
+	onPath := filepath.SplitList(pathEnv)
+	index := make(map[string]int, len(onPath))
+	for i, dir := range onPath {
+		if _, ok := index[filepath.Clean(dir)]; !ok {
+			index[filepath.Clean(dir)] = i
+		}
+	}
+	prepended := make(map[string]bool, len(cfg.Prepend))
+	for _, entry := range cfg.Prepend {
+		prepended[filepath.Clean(entry)] = true
+	}
+
+	var issues []DoctorIssue
+	for _, entry := range cfg.Entries() {
+		dir := filepath.Clean(entry)
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			issues = append(issues, DoctorIssue{
+				Provider: "path",
+				StepID:   "path:" + entry,
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("PATH entry %s does not exist", entry),
+				Expected: "an existing directory",
+				Actual:   "missing",
+			})
+			continue
+		}
+
+		pos, ok := index[dir]
+		if !ok {
+			issues = append(issues, DoctorIssue{
+				Provider: "path",
+				StepID:   "path:" + entry,
+				Severity: SeverityInfo,
+				Message:  fmt.Sprintf("PATH entry %s is not on the current PATH", entry),
+				Expected: "on PATH (load 'preflight hook' in your shell)",
+				Actual:   "not on PATH",
+			})
+			continue
+		}
+
+		// Append entries are expected to lose to earlier directories.
+		if !prepended[dir] {
+			continue
+		}
+		shadowed := shadowedCommands(dir, onPath[:pos], prepended)
+		if len(shadowed) == 0 {
+			continue
+		}
+		listed := shadowed
+		if len(listed) > maxShadowedListed {
+			listed = listed[:maxShadowedListed]
+		}
+		actual := strings.Join(listed, ", ")
+		if len(shadowed) > len(listed) {
+			actual += fmt.Sprintf(" and %d more", len(shadowed)-len(listed))
+		}
+		issues = append(issues, DoctorIssue{
+			Provider: "path",
+			StepID:   "path:" + entry,
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("%d command(s) in %s are shadowed by earlier PATH entries", len(shadowed), entry),
+			Expected: entry + " before other directories on PATH",
+			Actual:   actual,
+		})
+	}
+	return issues
+}
+
+// shadowedCommands returns the executables in dir that resolve to one of
+// earlier instead, formatted as "name (winning path)". Directories that are
+// themselves configured prepend entries are skipped, since their order is
+// intentional.
+func shadowedCommands(dir string, earlier []string, prepended map[string]bool) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var shadowed []string
+	for _, entry := range entries {
+		if !isExecutable(filepath.Join(dir, entry.Name())) {
+			continue
+		}
+		for _, other := range earlier {
+			other = filepath.Clean(other)
+			if other == dir || prepended[other] {
+				continue
+			}
+			if winner := filepath.Join(other, entry.Name()); isExecutable(winner) {
+				shadowed = append(shadowed, fmt.Sprintf("%s (%s)", entry.Name(), winner))
+				break
+			}
+		}
+	}
+	return shadowed
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular() && info.Mode()&0o111 != 0
+}
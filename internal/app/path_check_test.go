@@ -0,0 +1,65 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeExecutable(t *testing.T, dir, name string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"), 0o755))
+}
+
+func TestDiagnosePath(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	system := filepath.Join(root, "usr", "bin")
+	brew := filepath.Join(root, "opt", "homebrew", "bin")
+	goBin := filepath.Join(root, "go", "bin")
+	unloaded := filepath.Join(root, "tools")
+	missing := filepath.Join(root, "missing")
+	writeExecutable(t, system, "git")
+	writeExecutable(t, brew, "git")
+	writeExecutable(t, brew, "jq")
+	writeExecutable(t, goBin, "git")
+	require.NoError(t, os.MkdirAll(unloaded, 0o755))
+
+	cfg := config.PathConfig{
+		Prepend: []string{brew, missing, unloaded},
+		Append:  []string{goBin},
+	}
+	pathEnv := strings.Join([]string{system, brew, goBin}, string(os.PathListSeparator))
+
+	issues := diagnosePath(cfg, pathEnv)
+	require.Len(t, issues, 3)
+
+	assert.Equal(t, "path:"+brew, issues[0].StepID)
+	assert.Equal(t, SeverityWarning, issues[0].Severity)
+	assert.Contains(t, issues[0].Message, "1 command(s)")
+	assert.Equal(t, "git ("+filepath.Join(system, "git")+")", issues[0].Actual)
+
+	assert.Contains(t, issues[1].Message, "does not exist")
+	assert.Equal(t, SeverityInfo, issues[2].Severity)
+	assert.Contains(t, issues[2].Message, "not on the current PATH")
+}
+
+func TestDiagnosePath_PrependFirst(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	system := filepath.Join(root, "usr", "bin")
+	brew := filepath.Join(root, "opt", "homebrew", "bin")
+	writeExecutable(t, system, "git")
+	writeExecutable(t, brew, "git")
+
+	pathEnv := strings.Join([]string{brew, system}, string(os.PathListSeparator))
+	assert.Empty(t, diagnosePath(config.PathConfig{Prepend: []string{brew}}, pathEnv))
+}
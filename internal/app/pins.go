@@ -0,0 +1,444 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+)
+
+// LayerPin is a pin and the layer file that declares it.
+type LayerPin struct {
+	Package string `json:"package"`
+	Version string `json:"version,omitempty"`
+	Since   string `json:"since,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+	Layer   string `json:"layer"`
+}
+
+// Pin returns the pin as declared in the layer.
+func (p LayerPin) Pin() config.Pin {
+	return config.Pin{Package: p.Package, Version: p.Version, Since: p.Since, Reason: p.Reason}
+}
+
+// PinChange adds or removes a pin: the layer patches that record it and
+// the package manager commands that enforce it.
+type PinChange struct {
+	Pin      config.Pin    `json:"pin"`
+	Layer    string        `json:"layer"`
+	Patches  []ConfigPatch `json:"patches"`
+	Commands [][]string    `json:"commands,omitempty"`
+}
+
+// layerFile is a parsed layer file next to the manifest.
+type layerFile struct {
+	name  string
+	path  string
+	layer *config.Layer
+}
+
+// pinListPaths are the package lists that declare the packages of each
+// package manager a pin can refer to.
+var pinListPaths = map[string]string{
+	"brew":   "packages.brew.formulae",
+	"cask":   "packages.brew.casks",
+	"npm":    "packages.npm.packages",
+	"pip":    "packages.pip.packages",
+	"go":     "packages.go.tools",
+	"cargo":  "packages.cargo.crates",
+	"vscode": "vscode.extensions",
+}
+
+// readLayerFiles parses the layer files next to configPath, sorted by name.
+func readLayerFiles(configPath string) ([]layerFile, error) {
+	paths, err := filepath.Glob(filepath.Join(filepath.Dir(configPath), "layers", "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	layers := make([]layerFile, 0, len(paths))
+	for _, path := range paths {
+		// #nosec G304 -- reading the user's own layer files.
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		layer, err := config.ParseLayer(data)
+		if err != nil {
+			return nil, config.NewYAMLParseError(path, err)
+		}
+		layers = append(layers, layerFile{
+			name:  strings.TrimSuffix(filepath.Base(path), ".yaml"),
+			path:  path,
+			layer: layer,
+		})
+	}
+	return layers, nil
+}
+
+// PackageChecks returns the post-upgrade health checks declared by the
+// layers next to configPath. A package checked by several layers keeps
+// the checks of the last one.
+func PackageChecks(configPath string) (config.ChecksConfig, error) {
+	layers, err := readLayerFiles(configPath)
+	if err != nil {
+		return nil, err
+	}
+	checks := make(config.ChecksConfig)
+	for _, l := range layers {
+		for name, commands := range l.layer.Checks {
+			checks[name] = commands
+		}
+	}
+	return checks, nil
+}
+
+// ListPins returns the pins declared by the layers next to configPath,
+// sorted by package and layer.
+func ListPins(configPath string) ([]LayerPin, error) {
+	layers, err := readLayerFiles(configPath)
+	if err != nil {
+		return nil, err
+	}
+	var pins []LayerPin
+	for _, l := range layers {
+		for _, pin := range l.layer.Pins {
+			pins = append(pins, LayerPin{
+				Package: pin.Package,
+				Version: pin.Version,
+				Since:   pin.Since,
+				Reason:  pin.Reason,
+				Layer:   l.name,
+			})
+		}
+	}
+	sort.SliceStable(pins, func(i, j int) bool {
+		return pins[i].Pin().Key() < pins[j].Pin().Key()
+	})
+	return pins, nil
+}
+
+// PinnedPackages returns the "manager:name" references of pins, as
+// outdated and upgrade expect them.
+func PinnedPackages(pins []LayerPin) []string {
+	refs := make([]string, 0, len(pins))
+	for _, pin := range pins {
+		refs = append(refs, pin.Pin().Key())
+	}
+	return refs
+}
+
+// PlanPinAdd plans pinning pin.Package in layerName, or when layerName is
+// empty in the layer that already pins or declares the package. Homebrew
+// formulae are pinned with brew pin; npm packages and VS Code extensions
+// declared in the layer get the pinned version in their entry.
+func PlanPinAdd(configPath, layerName string, pin config.Pin) (*PinChange, error) {
+	manager, name, err := config.ParsePinRef(pin.Package)
+	if err != nil {
+		return nil, err
+	}
+	layers, err := readLayerFiles(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	target := findPinLayer(layers, layerName, pin)
+	if target == nil {
+		if layerName != "" {
+			return nil, fmt.Errorf("layer %q does not exist in %s", layerName, filepath.Join(filepath.Dir(configPath), "layers"))
+		}
+		return nil, fmt.Errorf("no layer declares %s; choose one with --layer", pin.Package)
+	}
+	if (manager == "npm" || manager == "vscode") && pin.Version == "" {
+		return nil, fmt.Errorf("pinning %s needs a version; pass --version", pin.Package)
+	}
+
+	change := &PinChange{Pin: pin, Layer: target.name}
+	pins := withoutPin(target.layer.Pins, pin.Key())
+	pins = append(pins, pin)
+	change.Patches = append(change.Patches, NewConfigPatch(target.path, "pins", PatchOpAdd, nil, pins, "pin"))
+
+	switch manager {
+	case "brew":
+		change.Commands = append(change.Commands, []string{"brew", "pin", name})
+	case "npm", "vscode":
+		if patch, ok := versionEntryPatch(target, manager, name, pin.Version); ok {
+			change.Patches = append(change.Patches, patch)
+		}
+	}
+	return change, nil
+}
+
+// PlanPinRemove plans unpinning the package ref refers to, such as
+// "npm:typescript", in every layer that pins it.
+func PlanPinRemove(configPath, ref string) (*PinChange, error) {
+	manager, name, err := config.ParsePinRef(ref)
+	if err != nil {
+		return nil, err
+	}
+	key := config.Pin{Package: ref}.Key()
+	layers, err := readLayerFiles(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var change *PinChange
+	for i := range layers {
+		l := &layers[i]
+		pin, ok := findPin(l.layer.Pins, key)
+		if !ok {
+			continue
+		}
+		if change == nil {
+			change = &PinChange{Pin: pin, Layer: l.name}
+		} else {
+			change.Layer += ", " + l.name
+		}
+		if pins := withoutPin(l.layer.Pins, key); len(pins) > 0 {
+			change.Patches = append(change.Patches, NewConfigPatch(l.path, "pins", PatchOpAdd, nil, pins, "pin"))
+		} else {
+			change.Patches = append(change.Patches, NewConfigPatch(l.path, "pins", PatchOpRemove, nil, nil, "pin"))
+		}
+		if manager == "npm" || manager == "vscode" {
+			if patch, ok := versionEntryPatch(l, manager, name, ""); ok {
+				change.Patches = append(change.Patches, patch)
+			}
+		}
+	}
+	if change == nil {
+		return nil, fmt.Errorf("%s is not pinned", ref)
+	}
+	if manager == "brew" {
+		change.Commands = append(change.Commands, []string{"brew", "unpin", name})
+	}
+	return change, nil
+}
+
+// ApplyPinChange writes the layer patches of change and then runs its
+// commands with run.
+func ApplyPinChange(ctx context.Context, change *PinChange, run func(ctx context.Context, name string, args ...string) error) error {
+	if err := config.NewLayerWriter().ApplyPatches(ConfigPatchesToWriterPatches(change.Patches)); err != nil {
+		return err
+	}
+	for _, command := range change.Commands {
+		if err := run(ctx, command[0], command[1:]...); err != nil {
+			return fmt.Errorf("%s: %w", strings.Join(command, " "), err)
+		}
+	}
+	return nil
+}
+
+// findPinLayer returns the layer named layerName, or when it is empty the
+// first layer that pins or else declares the package of pin.
+func findPinLayer(layers []layerFile, layerName string, pin config.Pin) *layerFile {
+	if layerName != "" {
+		for i := range layers {
+			if layers[i].name == layerName {
+				return &layers[i]
+			}
+		}
+		return nil
+	}
+	for i := range layers {
+		if _, ok := findPin(layers[i].layer.Pins, pin.Key()); ok {
+			return &layers[i]
+		}
+	}
+	manager, name := pin.Ref()
+	for i := range layers {
+		if _, ok := findPackageEntry(layers[i].layer, manager, name); ok {
+			return &layers[i]
+		}
+	}
+	return nil
+}
+
+func findPin(pins []config.Pin, key string) (config.Pin, bool) {
+	for _, pin := range pins {
+		if pin.Key() == key {
+			return pin, true
+		}
+	}
+	return config.Pin{}, false
+}
+
+func withoutPin(pins []config.Pin, key string) []config.Pin {
+	kept := make([]config.Pin, 0, len(pins))
+	for _, pin := range pins {
+		if pin.Key() != key {
+			kept = append(kept, pin)
+		}
+	}
+	return kept
+}
+
+// findPackageEntry returns the index of the entry of layer that declares
+// name for manager. npm entries and VS Code extensions may carry a
+// version, as in "typescript@5.4" and "golang.go@0.42.0".
+func findPackageEntry(layer *config.Layer, manager, name string) (int, bool) {
+	list, ok := pinListPaths[manager]
+	if !ok {
+		return 0, false
+	}
+	entries := layer.PackageList(list)
+	if manager == "vscode" {
+		entries = layer.VSCode.Extensions
+	}
+	for i, entry := range entries {
+		switch manager {
+		case "npm":
+			if npmPackageName(entry) == name {
+				return i, true
+			}
+		case "vscode":
+			id, _, _ := strings.Cut(entry, "@")
+			if strings.EqualFold(id, name) {
+				return i, true
+			}
+		default:
+			if entry == name {
+				return i, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// versionEntryPatch returns a patch that sets the version of the entry of
+// l declaring name, or drops it when version is empty.
+func versionEntryPatch(l *layerFile, manager, name, version string) (ConfigPatch, bool) {
+	i, ok := findPackageEntry(l.layer, manager, name)
+	if !ok {
+		return ConfigPatch{}, false
+	}
+	list := pinListPaths[manager]
+	entries := l.layer.PackageList(list)
+	if manager == "vscode" {
+		entries = l.layer.VSCode.Extensions
+	}
+	old := entries[i]
+	entry := old
+	if manager == "vscode" {
+		entry, _, _ = strings.Cut(old, "@")
+	} else {
+		entry = npmPackageName(old)
+	}
+	if version != "" {
+		entry += "@" + version
+	}
+	if entry == old {
+		return ConfigPatch{}, false
+	}
+	return NewConfigPatch(l.path, fmt.Sprintf("%s[%d]", list, i), PatchOpModify, old, entry, "pin"), true
+}
+
+// InstalledVersion returns the installed version of the package ref refers
+// to, using output to run commands. Homebrew, npm, pip and VS Code are
+// supported.
+func InstalledVersion(ref string, output func(name string, args ...string) (string, error)) (string, error) {
+	manager, name, err := config.ParsePinRef(ref)
+	if err != nil {
+		return "", err
+	}
+	var version string
+	switch manager {
+	case "brew", "cask":
+		args := []string{"list", "--versions", name}
+		if manager == "cask" {
+			args = []string{"list", "--cask", "--versions", name}
+		}
+		out, err := output("brew", args...)
+		if err != nil {
+			return "", fmt.Errorf("%s is not installed", ref)
+		}
+		if fields := strings.Fields(out); len(fields) > 1 {
+			version = fields[len(fields)-1]
+		}
+	case "npm":
+		out, err := output("npm", "ls", "-g", "--depth=0", "--json")
+		if err != nil && out == "" {
+			return "", err
+		}
+		var tree struct {
+			Dependencies map[string]struct {
+				Version string `json:"version"`
+			} `json:"dependencies"`
+		}
+		if err := json.Unmarshal([]byte(out), &tree); err != nil {
+			return "", fmt.Errorf("failed to parse npm ls output: %w", err)
+		}
+		version = tree.Dependencies[name].Version
+	case "pip":
+		out, err := output("python3", "-m", "pip", "show", name)
+		if err != nil {
+			return "", fmt.Errorf("%s is not installed", ref)
+		}
+		for _, line := range strings.Split(out, "\n") {
+			if v, ok := strings.CutPrefix(line, "Version:"); ok {
+				version = strings.TrimSpace(v)
+			}
+		}
+	case "vscode":
+		out, err := output("code", "--list-extensions", "--show-versions")
+		if err != nil {
+			return "", err
+		}
+		for _, line := range strings.Split(out, "\n") {
+			id, v, ok := strings.Cut(strings.TrimSpace(line), "@")
+			if ok && strings.EqualFold(id, name) {
+				version = v
+			}
+		}
+	default:
+		return "", fmt.Errorf("cannot detect the installed version of %s; pass --version", ref)
+	}
+	if version == "" {
+		return "", fmt.Errorf("%s is not installed", ref)
+	}
+	return version, nil
+}
+
+// checkPins flags pins of the layers of target held longer than
+// upgrades.pin_max_age.
+func checkPins(configPath, target string, now time.Time, report *DoctorReport) {
+	manifest, err := config.NewLoader().LoadManifest(configPath)
+	if err != nil {
+		return
+	}
+	inTarget := make(map[string]bool)
+	for _, name := range manifest.Targets[target] {
+		inTarget[name.String()] = true
+	}
+	pins, err := ListPins(configPath)
+	if err != nil {
+		return
+	}
+
+	maxAge := manifest.Upgrades.MaxPinAge()
+	for _, lp := range pins {
+		since, ok := lp.Pin().SinceTime()
+		if !inTarget[lp.Layer] || !ok || now.Sub(since) <= maxAge {
+			continue
+		}
+		message := fmt.Sprintf("%s has been pinned for %d days", lp.Package, int(now.Sub(since).Hours()/24))
+		if lp.Reason != "" {
+			message += " (" + lp.Reason + ")"
+		}
+		report.Issues = append(report.Issues, DoctorIssue{
+			Provider:   "pins",
+			StepID:     "pin:" + lp.Package,
+			Severity:   SeverityWarning,
+			Message:    message,
+			Expected:   fmt.Sprintf("pins reviewed within %d days (upgrades.pin_max_age)", int(maxAge.Hours()/24)),
+			Actual:     fmt.Sprintf("pinned since %s in layer %s", lp.Since, lp.Layer),
+			FixCommand: "preflight pin remove " + lp.Package,
+		})
+	}
+}
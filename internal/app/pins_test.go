@@ -0,0 +1,244 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writePinConfig creates a config whose base layer declares node, npm
+// packages and VS Code extensions, and whose work layer is empty.
+func writePinConfig(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "preflight.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("upgrades:\n  pin_max_age: 30d\ntargets:\n  default: [base, work]\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "layers"), 0o755))
+	base := `name: base
+packages:
+  brew:
+    formulae:
+      - node # runtime
+  npm:
+    packages:
+      - pnpm
+      - typescript@5
+vscode:
+  extensions:
+    - golang.go
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "layers", "base.yaml"), []byte(base), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "layers", "work.yaml"), []byte("name: work\n"), 0o644))
+	return configPath
+}
+
+func readLayer(t *testing.T, configPath, name string) *config.Layer {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(configPath), "layers", name+".yaml"))
+	require.NoError(t, err)
+	layer, err := config.ParseLayer(data)
+	require.NoError(t, err)
+	return layer
+}
+
+func applyPin(t *testing.T, change *PinChange) [][]string {
+	t.Helper()
+	var ran [][]string
+	require.NoError(t, ApplyPinChange(context.Background(), change, func(_ context.Context, name string, args ...string) error {
+		ran = append(ran, append([]string{name}, args...))
+		return nil
+	}))
+	return ran
+}
+
+func TestPlanPinAdd(t *testing.T) {
+	t.Parallel()
+
+	configPath := writePinConfig(t)
+
+	change, err := PlanPinAdd(configPath, "", config.Pin{Package: "node", Version: "20.11.1", Since: "2026-10-01"})
+	require.NoError(t, err)
+	assert.Equal(t, "base", change.Layer)
+	assert.Equal(t, [][]string{{"brew", "pin", "node"}}, applyPin(t, change))
+
+	change, err = PlanPinAdd(configPath, "", config.Pin{Package: "npm:typescript", Version: "5.4.5"})
+	require.NoError(t, err)
+	assert.Empty(t, applyPin(t, change))
+
+	change, err = PlanPinAdd(configPath, "", config.Pin{Package: "vscode:Golang.Go", Version: "0.41.2"})
+	require.NoError(t, err)
+	applyPin(t, change)
+
+	base := readLayer(t, configPath, "base")
+	assert.Equal(t, []config.Pin{
+		{Package: "node", Version: "20.11.1", Since: "2026-10-01"},
+		{Package: "npm:typescript", Version: "5.4.5"},
+		{Package: "vscode:Golang.Go", Version: "0.41.2"},
+	}, base.Pins)
+	assert.Equal(t, []string{"pnpm", "typescript@5.4.5"}, base.Packages.Npm.Packages)
+	assert.Equal(t, []string{"golang.go@0.41.2"}, base.VSCode.Extensions)
+
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(configPath), "layers", "base.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# runtime", "comments are preserved")
+
+	// Pinning again updates the pin in place.
+	change, err = PlanPinAdd(configPath, "", config.Pin{Package: "brew:node", Version: "20.12.0"})
+	require.NoError(t, err)
+	applyPin(t, change)
+	base = readLayer(t, configPath, "base")
+	require.Len(t, base.Pins, 3)
+	assert.Equal(t, "20.12.0", base.Pins[2].Version)
+}
+
+func TestPlanPinAdd_Errors(t *testing.T) {
+	t.Parallel()
+
+	configPath := writePinConfig(t)
+
+	_, err := PlanPinAdd(configPath, "", config.Pin{Package: "jq", Version: "1.7"})
+	require.ErrorContains(t, err, "--layer")
+
+	_, err = PlanPinAdd(configPath, "missing", config.Pin{Package: "jq", Version: "1.7"})
+	require.ErrorContains(t, err, `layer "missing" does not exist`)
+
+	_, err = PlanPinAdd(configPath, "", config.Pin{Package: "npm:pnpm"})
+	require.ErrorContains(t, err, "--version")
+
+	change, err := PlanPinAdd(configPath, "work", config.Pin{Package: "jq", Version: "1.7"})
+	require.NoError(t, err)
+	assert.Equal(t, "work", change.Layer)
+}
+
+func TestPlanPinRemove(t *testing.T) {
+	t.Parallel()
+
+	configPath := writePinConfig(t)
+	for _, pin := range []config.Pin{
+		{Package: "node", Version: "20.11.1"},
+		{Package: "npm:typescript", Version: "5.4.5"},
+	} {
+		change, err := PlanPinAdd(configPath, "", pin)
+		require.NoError(t, err)
+		applyPin(t, change)
+	}
+
+	change, err := PlanPinRemove(configPath, "npm:typescript")
+	require.NoError(t, err)
+	assert.Empty(t, applyPin(t, change))
+	base := readLayer(t, configPath, "base")
+	assert.Equal(t, []config.Pin{{Package: "node", Version: "20.11.1"}}, base.Pins)
+	assert.Equal(t, []string{"pnpm", "typescript"}, base.Packages.Npm.Packages)
+
+	change, err = PlanPinRemove(configPath, "node")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"brew", "unpin", "node"}}, applyPin(t, change))
+	assert.Empty(t, readLayer(t, configPath, "base").Pins)
+
+	_, err = PlanPinRemove(configPath, "node")
+	require.ErrorContains(t, err, "not pinned")
+}
+
+func TestListPins(t *testing.T) {
+	t.Parallel()
+
+	configPath := writePinConfig(t)
+	for _, add := range []struct {
+		layer string
+		pin   config.Pin
+	}{
+		{"work", config.Pin{Package: "npm:pnpm", Version: "9.1.0"}},
+		{"base", config.Pin{Package: "node", Version: "20.11.1"}},
+	} {
+		change, err := PlanPinAdd(configPath, add.layer, add.pin)
+		require.NoError(t, err)
+		applyPin(t, change)
+	}
+
+	pins, err := ListPins(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, []LayerPin{
+		{Package: "node", Version: "20.11.1", Layer: "base"},
+		{Package: "npm:pnpm", Version: "9.1.0", Layer: "work"},
+	}, pins)
+	assert.Equal(t, []string{"brew:node", "npm:pnpm"}, PinnedPackages(pins))
+}
+
+func TestInstalledVersion(t *testing.T) {
+	t.Parallel()
+
+	run := fakeEngine(map[string]string{
+		"brew list --versions node":              "node 20.11.1 20.12.0\n",
+		"npm ls -g --depth=0 --json":             `{"dependencies": {"typescript": {"version": "5.4.5"}}}`,
+		"code --list-extensions --show-versions": "Golang.Go@0.41.2\n",
+		"python3 -m pip show black":              "Name: black\nVersion: 24.2.0\n",
+	})
+	for ref, want := range map[string]string{
+		"node":             "20.12.0",
+		"npm:typescript":   "5.4.5",
+		"vscode:golang.go": "0.41.2",
+		"pip:black":        "24.2.0",
+	} {
+		got, err := InstalledVersion(ref, run)
+		require.NoError(t, err, ref)
+		assert.Equal(t, want, got, ref)
+	}
+
+	_, err := InstalledVersion("npm:eslint", run)
+	require.ErrorContains(t, err, "not installed")
+	_, err = InstalledVersion("go:golang.org/x/tools/gopls", run)
+	require.ErrorContains(t, err, "--version")
+}
+
+func TestCheckPins(t *testing.T) {
+	t.Parallel()
+
+	configPath := writePinConfig(t)
+	for _, pin := range []config.Pin{
+		{Package: "node", Version: "20.11.1", Since: "2026-08-01", Reason: "native modules"},
+		{Package: "npm:typescript", Version: "5.4.5", Since: "2026-10-01"},
+	} {
+		change, err := PlanPinAdd(configPath, "", pin)
+		require.NoError(t, err)
+		applyPin(t, change)
+	}
+
+	now := time.Date(2026, time.October, 16, 12, 0, 0, 0, time.Local)
+	report := &DoctorReport{}
+	checkPins(configPath, "default", now, report)
+	require.Len(t, report.Issues, 1)
+	issue := report.Issues[0]
+	assert.Equal(t, "pin:node", issue.StepID)
+	assert.True(t, strings.HasPrefix(issue.Message, "node has been pinned for 76 days"), issue.Message)
+	assert.Contains(t, issue.Message, "native modules")
+	assert.Equal(t, "preflight pin remove node", issue.FixCommand)
+
+	report = &DoctorReport{}
+	checkPins(configPath, "other", now, report)
+	assert.Empty(t, report.Issues, "pins of layers outside the target are ignored")
+}
+
+func TestPackageChecks(t *testing.T) {
+	t.Parallel()
+
+	configPath := writePinConfig(t)
+	layers := filepath.Join(filepath.Dir(configPath), "layers")
+	require.NoError(t, os.WriteFile(filepath.Join(layers, "base.yaml"),
+		[]byte("name: base\nchecks:\n  node: [node --version]\n  jq: [jq --version]\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(layers, "work.yaml"),
+		[]byte("name: work\nchecks:\n  node: [npm test]\n"), 0o644))
+
+	checks, err := PackageChecks(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, config.ChecksConfig{
+		"jq":   {"jq --version"},
+		"node": {"npm test"},
+	}, checks)
+}
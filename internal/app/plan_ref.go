@@ -0,0 +1,326 @@
+package app
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/domain/execution"
+)
+
+// ErrNotGitRepository is returned when a config outside a git repository is
+// compared against a git ref.
+var ErrNotGitRepository = errors.New("config is not in a git repository")
+
+// RefChangeKind classifies how a step differs between two revisions.
+type RefChangeKind string
+
+// Kinds of step changes between two revisions.
+const (
+	// RefChangeAdded is a step only the newer revision manages.
+	RefChangeAdded RefChangeKind = "added"
+	// RefChangeChanged is a step both revisions manage differently.
+	RefChangeChanged RefChangeKind = "changed"
+	// RefChangeRemoved is a step only the older revision manages. Preflight
+	// stops managing it but does not undo it.
+	RefChangeRemoved RefChangeKind = "removed"
+)
+
+// RefChange is a step whose effect on the machine differs between two
+// revisions of a configuration.
+type RefChange struct {
+	Kind   RefChangeKind `json:"kind"`
+	StepID string        `json:"step"`
+	// Before and After summarize the step's diff against the machine under
+	// each revision; Before is empty for added steps, After for removed ones.
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+	// NeedsApply reports whether the newer revision would change the
+	// machine for this step, and NeededApply whether the older one would.
+	NeedsApply  bool `json:"needs_apply"`
+	NeededApply bool `json:"needed_apply,omitempty"`
+}
+
+// RefImpact is what moving a configuration from one revision to another
+// would change on this machine.
+type RefImpact struct {
+	Base    string      `json:"base"`
+	Head    string      `json:"head"`
+	Changes []RefChange `json:"changes"`
+}
+
+// Count returns the number of changes of kind.
+func (r *RefImpact) Count(kind RefChangeKind) int {
+	n := 0
+	for _, c := range r.Changes {
+		if c.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// PlanAgainstRef plans target under the configuration as of the git ref
+// base and under head, and returns the steps that differ. An empty head is
+// the configuration on disk. Revisions are read with git archive, so the
+// repository and its working tree are left untouched.
+func (p *Preflight) PlanAgainstRef(ctx context.Context, configPath, target, base, head string) (*RefImpact, error) {
+	basePath, cleanup, err := checkoutConfigAtRef(ctx, configPath, base)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	headPath := configPath
+	if head != "" {
+		var cleanupHead func()
+		headPath, cleanupHead, err = checkoutConfigAtRef(ctx, configPath, head)
+		if err != nil {
+			return nil, err
+		}
+		defer cleanupHead()
+	}
+
+	basePlan, err := p.Plan(ctx, basePath, target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan %s: %w", base, err)
+	}
+	headPlan, err := p.Plan(ctx, headPath, target)
+	if err != nil {
+		if head == "" {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to plan %s: %w", head, err)
+	}
+
+	// Diffs of files rendered from the config mention its directory, which
+	// differs between the checkouts.
+	baseDir, headDir := filepath.Dir(basePath), filepath.Dir(headPath)
+	if abs, err := filepath.Abs(headDir); err == nil {
+		headDir = abs
+	}
+	normalize := func(s string) string {
+		return strings.ReplaceAll(s, baseDir, headDir)
+	}
+
+	impact := &RefImpact{Base: base, Head: head}
+	impact.Changes = diffPlans(basePlan, headPlan, normalize)
+	return impact, nil
+}
+
+// diffPlans returns the steps that differ between two plans: steps of head
+// in plan order, followed by the steps only base has.
+func diffPlans(base, head *execution.Plan, normalize func(string) string) []RefChange {
+	baseEntries := make(map[string]execution.PlanEntry, base.Len())
+	for _, entry := range base.Entries() {
+		baseEntries[entry.Step().ID().String()] = entry
+	}
+
+	var changes []RefChange
+	seen := make(map[string]bool, head.Len())
+	for _, entry := range head.Entries() {
+		id := entry.Step().ID().String()
+		seen[id] = true
+		change := RefChange{
+			Kind:       RefChangeAdded,
+			StepID:     id,
+			After:      diffSummary(entry.Diff()),
+			NeedsApply: entry.Status() == compiler.StatusNeedsApply,
+		}
+		if old, ok := baseEntries[id]; ok {
+			change.Before = normalize(diffSummary(old.Diff()))
+			change.NeededApply = old.Status() == compiler.StatusNeedsApply
+			if change.Before == change.After && change.NeededApply == change.NeedsApply {
+				continue
+			}
+			change.Kind = RefChangeChanged
+		}
+		changes = append(changes, change)
+	}
+	for _, entry := range base.Entries() {
+		id := entry.Step().ID().String()
+		if seen[id] {
+			continue
+		}
+		changes = append(changes, RefChange{
+			Kind:        RefChangeRemoved,
+			StepID:      id,
+			Before:      normalize(diffSummary(entry.Diff())),
+			NeededApply: entry.Status() == compiler.StatusNeedsApply,
+		})
+	}
+	return changes
+}
+
+// diffSummary summarizes a diff, or returns "" for an empty one.
+func diffSummary(d compiler.Diff) string {
+	if d.IsEmpty() {
+		return ""
+	}
+	return d.Summary()
+}
+
+// PrintRefImpact displays what moving between two revisions would change.
+func (p *Preflight) PrintRefImpact(impact *RefImpact) {
+	head := impact.Head
+	if head == "" {
+		head = "the working tree"
+	}
+	title := fmt.Sprintf("Impact of %s → %s", impact.Base, head)
+	p.printf("\n%s\n%s\n\n", title, strings.Repeat("=", len([]rune(title))))
+
+	if len(impact.Changes) == 0 {
+		p.printf("No differences. Both revisions leave this machine in the same state.\n")
+		return
+	}
+
+	toApply := 0
+	for _, c := range impact.Changes {
+		if c.NeedsApply {
+			toApply++
+		}
+	}
+	p.printf("Steps: %d added, %d changed, %d removed; %d would change this machine\n\n",
+		impact.Count(RefChangeAdded), impact.Count(RefChangeChanged), impact.Count(RefChangeRemoved), toApply)
+
+	for _, c := range impact.Changes {
+		note := "already satisfied"
+		if c.NeedsApply {
+			note = "would apply"
+		}
+		switch c.Kind {
+		case RefChangeAdded:
+			p.printf("  + %s (%s)\n", c.StepID, note)
+			if c.After != "" {
+				p.printf("      %s\n", c.After)
+			}
+		case RefChangeChanged:
+			p.printf("  ~ %s (%s)\n", c.StepID, note)
+			if c.Before != "" {
+				p.printf("      before: %s\n", c.Before)
+			}
+			if c.After != "" {
+				p.printf("      after:  %s\n", c.After)
+			}
+		case RefChangeRemoved:
+			p.printf("  - %s (no longer managed; left in place)\n", c.StepID)
+		}
+	}
+}
+
+// checkoutConfigAtRef writes the directory of configPath as of the git ref
+// into a temporary directory and returns the config path inside it along
+// with a function removing the directory.
+func checkoutConfigAtRef(ctx context.Context, configPath, ref string) (string, func(), error) {
+	dir := filepath.Dir(configPath)
+	root, err := runGit(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %s", ErrNotGitRepository, dir)
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", nil, err
+	}
+	if resolved, err := filepath.EvalSymlinks(absDir); err == nil {
+		absDir = resolved
+	}
+	repoRoot := strings.TrimSpace(string(root))
+	rel, err := filepath.Rel(repoRoot, absDir)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", nil, fmt.Errorf("%w: %s", ErrNotGitRepository, dir)
+	}
+
+	if _, err := runGit(ctx, dir, "rev-parse", "--verify", "--quiet", ref+"^{commit}"); err != nil {
+		return "", nil, fmt.Errorf("unknown git ref %q", ref)
+	}
+	treeish := ref
+	if rel != "." {
+		treeish = ref + ":" + filepath.ToSlash(rel)
+	}
+	archive, err := runGit(ctx, repoRoot, "archive", "--format=tar", treeish)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s has no %s directory", ref, filepath.ToSlash(rel))
+	}
+
+	tmp, err := os.MkdirTemp("", "preflight-ref-")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { _ = os.RemoveAll(tmp) }
+	if err := extractTar(bytes.NewReader(archive), tmp); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to read %s: %w", ref, err)
+	}
+
+	refConfig := filepath.Join(tmp, filepath.Base(configPath))
+	if _, err := os.Stat(refConfig); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("%s has no %s", ref, filepath.ToSlash(filepath.Join(rel, filepath.Base(configPath))))
+	}
+	return refConfig, cleanup, nil
+}
+
+// runGit runs git in dir and returns its standard output.
+func runGit(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	// #nosec G204 -- args are fixed git subcommands and a user-supplied ref.
+	return exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...).Output()
+}
+
+// extractTar writes the directories, regular files and symlinks of a tar
+// stream under dir, rejecting paths that would leave it.
+func extractTar(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := path.Clean(hdr.Name)
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("unexpected path %q", hdr.Name)
+		}
+		target := filepath.Join(dir, filepath.FromSlash(name))
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			// #nosec G110 -- the archive comes from the local repository.
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(hdr.Mode)&0o777)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(f, tr); err != nil {
+				_ = f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			if err := os.Symlink(hdr.Linkname, target); err != nil {
+				return err
+			}
+		}
+	}
+}
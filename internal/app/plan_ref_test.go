@@ -0,0 +1,109 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/domain/execution"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffPlans(t *testing.T) {
+	t.Parallel()
+
+	pkg := compiler.NewDiff(compiler.DiffTypeAdd, "package", "ripgrep", "", "latest")
+	file := func(dir string) compiler.Diff {
+		return compiler.NewDiff(compiler.DiffTypeModify, "file", "~/.zshrc", "", dir+"/dotfiles/zshrc")
+	}
+
+	base := execution.NewExecutionPlan()
+	base.Add(execution.NewPlanEntry(newDummyStep("brew:formula:ripgrep"), compiler.StatusNeedsApply, pkg))
+	base.Add(execution.NewPlanEntry(newDummyStep("files:link:zshrc"), compiler.StatusSatisfied, file("/tmp/base")))
+	base.Add(execution.NewPlanEntry(newDummyStep("git:config"), compiler.StatusSatisfied, compiler.Diff{}))
+	base.Add(execution.NewPlanEntry(newDummyStep("brew:formula:wget"), compiler.StatusSatisfied, compiler.Diff{}))
+
+	head := execution.NewExecutionPlan()
+	head.Add(execution.NewPlanEntry(newDummyStep("brew:formula:ripgrep"), compiler.StatusNeedsApply, pkg))
+	head.Add(execution.NewPlanEntry(newDummyStep("files:link:zshrc"), compiler.StatusSatisfied, file("/home/me/config")))
+	head.Add(execution.NewPlanEntry(newDummyStep("git:config"), compiler.StatusNeedsApply, compiler.Diff{}))
+	head.Add(execution.NewPlanEntry(newDummyStep("brew:formula:fd"), compiler.StatusNeedsApply, pkg))
+
+	changes := diffPlans(base, head, strings.NewReplacer("/tmp/base", "/home/me/config").Replace)
+	assert.Equal(t, []RefChange{
+		{Kind: RefChangeChanged, StepID: "git:config", NeedsApply: true},
+		{Kind: RefChangeAdded, StepID: "brew:formula:fd", After: pkg.Summary(), NeedsApply: true},
+		{Kind: RefChangeRemoved, StepID: "brew:formula:wget"},
+	}, changes, "unchanged steps and steps differing only by checkout path are left out")
+}
+
+// commitConfig writes files into a git repository and commits them.
+func commitConfig(t *testing.T, repo string, files map[string]string) {
+	t.Helper()
+	for path, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(repo, path)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(repo, path), []byte(content), 0o644))
+	}
+	for _, args := range [][]string{
+		{"add", "-A"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "update"},
+	} {
+		out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+}
+
+func TestPlanAgainstRef(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	t.Setenv("HOME", t.TempDir())
+
+	repo := t.TempDir()
+	require.NoError(t, exec.Command("git", "init", "-q", repo).Run())
+	commitConfig(t, repo, map[string]string{
+		"dotfiles/preflight.yaml":    "targets:\n  default: [base]\n",
+		"dotfiles/layers/base.yaml":  "name: base\nssh:\n  hosts:\n    - host: home\n",
+		"README.md":                  "config\n",
+		"dotfiles/layers/unused.yml": "name: unused\n",
+	})
+	commitConfig(t, repo, map[string]string{
+		"dotfiles/layers/base.yaml": "name: base\ngit:\n  user:\n    name: Test\n",
+	})
+	configPath := filepath.Join(repo, "dotfiles", "preflight.yaml")
+
+	var buf bytes.Buffer
+	pf := New(&buf)
+	impact, err := pf.PlanAgainstRef(context.Background(), configPath, "default", "HEAD~1", "")
+	require.NoError(t, err)
+	assert.Equal(t, []RefChange{
+		{Kind: RefChangeAdded, StepID: "git:config", After: "+ gitconfig ~/.gitconfig (generated)", NeedsApply: true},
+		{Kind: RefChangeRemoved, StepID: "ssh:config", Before: "+ sshconfig ~/.ssh/config (generated)", NeededApply: true},
+	}, impact.Changes)
+
+	pf.PrintRefImpact(impact)
+	assert.Contains(t, buf.String(), "Impact of HEAD~1 → the working tree")
+	assert.Contains(t, buf.String(), "Steps: 1 added, 0 changed, 1 removed; 1 would change this machine")
+	assert.Contains(t, buf.String(), "  - ssh:config (no longer managed; left in place)")
+
+	impact, err = pf.PlanAgainstRef(context.Background(), configPath, "default", "HEAD", "HEAD")
+	require.NoError(t, err)
+	assert.Empty(t, impact.Changes)
+
+	_, err = pf.PlanAgainstRef(context.Background(), configPath, "default", "no-such-ref", "")
+	require.ErrorContains(t, err, `unknown git ref "no-such-ref"`)
+}
+
+func TestPlanAgainstRef_NotARepo(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	_, err := New(&bytes.Buffer{}).PlanAgainstRef(context.Background(), filepath.Join(dir, "preflight.yaml"), "default", "HEAD", "")
+	require.ErrorIs(t, err, ErrNotGitRepository)
+}
@@ -0,0 +1,289 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/audit"
+	"github.com/felixgeelhaar/preflight/internal/domain/capability"
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/domain/plugin"
+	"github.com/felixgeelhaar/preflight/internal/domain/sandbox"
+	"github.com/felixgeelhaar/preflight/internal/provider/wasmplugin"
+)
+
+// pluginProviders registers providers implemented by installed WASM plugins
+// and re-registers them when plugin directories change on disk. The sandbox
+// runtime is only created once such plugins exist and is reused across
+// reloads. Plugin providers never replace a builtin provider of the same name.
+//
+// Capabilities that need consent are resolved from the grants file when a
+// plugin is first used; prompter asks about undecided ones and may be nil in
+// non-interactive sessions, in which case they stay denied.
+type pluginProviders struct {
+	loader   *plugin.Loader
+	grants   *plugin.GrantStore
+	prompter plugin.ConsentPrompter
+	out      io.Writer
+
+	mu         sync.Mutex
+	watcher    *plugin.Watcher
+	runtime    sandbox.Runtime
+	audit      *audit.Service
+	registered []string
+	loaded     map[string]*plugin.Plugin
+}
+
+// PluginReload is the outcome of reloading one changed plugin directory.
+type PluginReload struct {
+	Plugin  string
+	Version string
+	Change  plugin.ChangeKind
+	Err     error
+}
+
+func newPluginProviders(loader *plugin.Loader, grants *plugin.GrantStore, prompter plugin.ConsentPrompter, out io.Writer) *pluginProviders {
+	return &pluginProviders{
+		loader:   loader,
+		grants:   grants,
+		prompter: prompter,
+		out:      out,
+		loaded:   make(map[string]*plugin.Plugin),
+	}
+}
+
+// register discovers installed plugins and registers their providers.
+func (pp *pluginProviders) register(ctx context.Context, comp *compiler.Compiler) {
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+
+	pp.watcher = plugin.NewWatcher(pp.loader.SearchPaths...)
+	pp.registerLocked(ctx, comp)
+}
+
+// reloadChanged re-registers plugin providers if any plugin directory changed
+// since the last check, and records a plugin_reloaded audit event per change.
+func (pp *pluginProviders) reloadChanged(ctx context.Context, comp *compiler.Compiler) []PluginReload {
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+
+	if pp.watcher == nil {
+		return nil
+	}
+	changes := pp.watcher.Poll()
+	if len(changes) == 0 {
+		return nil
+	}
+
+	if pp.audit == nil {
+		pp.audit = pluginAuditService()
+	}
+
+	previous := pp.loaded
+	for _, name := range pp.registered {
+		comp.UnregisterProvider(name)
+	}
+	pp.registered = nil
+	failed := pp.registerLocked(ctx, comp)
+
+	reloads := make([]PluginReload, 0, len(changes))
+	for _, change := range changes {
+		reload := PluginReload{Change: change.Kind, Plugin: filepath.Base(change.Path)}
+		if p, ok := pp.loaded[change.Path]; ok {
+			reload.Plugin, reload.Version = p.ID(), p.Manifest.Version
+		} else if p, ok := previous[change.Path]; ok {
+			reload.Plugin = p.ID()
+			if change.Kind == plugin.ChangeRemoved {
+				reload.Version = p.Manifest.Version
+			}
+		}
+		if change.Kind != plugin.ChangeRemoved {
+			reload.Err = failed[change.Path]
+		}
+		if pp.audit != nil {
+			_ = pp.audit.LogPluginReloaded(ctx, reload.Plugin, reload.Version, string(reload.Change), reload.Err)
+		}
+		reloads = append(reloads, reload)
+	}
+	return reloads
+}
+
+// registerLocked discovers plugins and registers their providers. It returns
+// load errors keyed by plugin directory.
+func (pp *pluginProviders) registerLocked(ctx context.Context, comp *compiler.Compiler) map[string]error {
+	failed := make(map[string]error)
+	pp.loaded = make(map[string]*plugin.Plugin)
+
+	result, err := pp.loader.Discover(ctx)
+	if err != nil || result == nil {
+		return failed
+	}
+	for _, e := range result.Errors {
+		failed[e.Path] = e.Err
+	}
+
+	var candidates []*plugin.Plugin
+	for _, p := range result.Plugins {
+		pp.loaded[p.Path] = p
+		if p.Enabled && p.Manifest.IsProviderPlugin() {
+			candidates = append(candidates, p)
+		}
+	}
+	if len(candidates) == 0 {
+		return failed
+	}
+
+	if pp.runtime == nil {
+		runtime, err := sandbox.NewWazeroRuntime(ctx)
+		if err != nil {
+			warnf(pp.out, "WASM plugin runtime unavailable: %v", err)
+			return failed
+		}
+		pp.runtime = runtime
+	}
+	if pp.audit == nil {
+		pp.audit = pluginAuditService()
+	}
+
+	services := sandbox.NewSystemServices(capability.DefaultPolicy(), nil)
+	var auditor plugin.GrantAuditor
+	if pp.audit != nil {
+		services.Audit = pp.audit
+		auditor = pp.audit
+	}
+	grantSvc := plugin.NewGrantService(pp.grants, auditor)
+
+	providers, errs := wasmplugin.Providers(candidates, wasmplugin.Options{
+		Runtime:  pp.runtime,
+		Config:   sandbox.DefaultConfig(),
+		Services: services,
+		Policy: func(ctx context.Context, p *plugin.Plugin) (*capability.Policy, error) {
+			grant, err := grantSvc.Resolve(ctx, p, pp.prompter)
+			if err != nil {
+				return nil, err
+			}
+			if pending, _ := grantSvc.Pending(p); len(pending) > 0 {
+				warnf(pp.out, "plugin %s has ungranted capabilities; run 'preflight plugin permissions grant %s'", p.ID(), p.ID())
+			}
+			return wasmplugin.GrantPolicy(p, grant), nil
+		},
+	})
+	for _, err := range errs {
+		warnf(pp.out, "skipping plugin: %v", err)
+	}
+
+	builtin := make(map[string]bool)
+	for _, p := range comp.Providers() {
+		builtin[p.Name()] = true
+	}
+	for _, p := range providers {
+		if builtin[p.Name()] {
+			warnf(pp.out, "plugin %s: provider %q conflicts with a builtin provider, skipping", p.PluginID(), p.Name())
+			continue
+		}
+		comp.RegisterProvider(p)
+		pp.registered = append(pp.registered, p.Name())
+	}
+	return failed
+}
+
+// ReloadChangedPlugins reloads plugin providers if an installed plugin was
+// added, changed or removed since the last check. It returns one entry per
+// changed plugin, or nil when nothing changed.
+func (p *Preflight) ReloadChangedPlugins(ctx context.Context) []PluginReload {
+	if p.plugins == nil {
+		return nil
+	}
+	return p.plugins.reloadChanged(ctx, p.compiler)
+}
+
+// WatchPlugins checks for plugin changes every interval until ctx is done,
+// calling onReload after each reload.
+func (p *Preflight) WatchPlugins(ctx context.Context, interval time.Duration, onReload func([]PluginReload)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if reloads := p.ReloadChangedPlugins(ctx); len(reloads) > 0 && onReload != nil {
+				onReload(reloads)
+			}
+		}
+	}
+}
+
+// String describes the reload for log output.
+func (r PluginReload) String() string {
+	name := r.Plugin
+	if r.Version != "" {
+		name += "@" + r.Version
+	}
+	if r.Err != nil {
+		return fmt.Sprintf("%s %s: %v", name, r.Change, r.Err)
+	}
+	return fmt.Sprintf("%s %s", name, r.Change)
+}
+
+// pluginAuditService returns the audit service for plugin activity, or nil
+// when the audit log cannot be opened.
+func pluginAuditService() *audit.Service {
+	logger, err := audit.NewFileLogger(audit.DefaultFileLoggerConfig())
+	if err != nil {
+		return nil
+	}
+	return audit.NewService(logger)
+}
+
+// ConsentPrompter asks for plugin capabilities on a text terminal.
+type ConsentPrompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// NewConsentPrompter creates a prompter reading answers from in.
+func NewConsentPrompter(in io.Reader, out io.Writer) *ConsentPrompter {
+	return &ConsentPrompter{in: bufio.NewReader(in), out: out}
+}
+
+// ConfirmCapability asks whether a plugin may use a capability. Anything
+// other than "y" or "yes" denies it.
+func (c *ConsentPrompter) ConfirmCapability(p *plugin.Plugin, requested plugin.WASMCapability) (bool, error) {
+	_, _ = fmt.Fprintf(c.out, "Plugin %s requests %s", p, requested.Name)
+	if requested.Justification != "" {
+		_, _ = fmt.Fprintf(c.out, ": %s", requested.Justification)
+	}
+	_, _ = fmt.Fprint(c.out, "\nAllow? [y/N]: ")
+
+	line, err := c.in.ReadString('\n')
+	if err != nil && line == "" {
+		return false, nil
+	}
+	answer := strings.ToLower(strings.TrimSpace(line))
+	return answer == "y" || answer == "yes", nil
+}
+
+// stdinPrompter returns a consent prompter when stdin is interactive.
+func stdinPrompter() plugin.ConsentPrompter {
+	info, err := os.Stdin.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return nil
+	}
+	return NewConsentPrompter(os.Stdin, os.Stderr)
+}
+
+func warnf(out io.Writer, format string, args ...interface{}) {
+	if out == nil {
+		return
+	}
+	_, _ = fmt.Fprintf(out, "Warning: "+format+"\n", args...)
+}
@@ -0,0 +1,153 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/audit"
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/domain/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeProviderPlugin(t *testing.T, dir, name, provider string) {
+	t.Helper()
+	module := []byte("\x00asm\x01\x00\x00\x00")
+	sum := sha256.Sum256(module)
+
+	pluginDir := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(pluginDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(pluginDir, "plugin.wasm"), module, 0o600))
+
+	manifest := fmt.Sprintf(`apiVersion: v1
+name: %s
+version: 1.0.0
+type: provider
+provides:
+  providers:
+    - name: %s
+      configKey: %s
+wasm:
+  module: plugin.wasm
+  checksum: %s
+`, name, provider, provider, hex.EncodeToString(sum[:]))
+	require.NoError(t, os.WriteFile(filepath.Join(pluginDir, "plugin.yaml"), []byte(manifest), 0o600))
+}
+
+func TestRegisterPluginProviders(t *testing.T) {
+	// Sets HOME so the plugin audit log stays in a temp dir.
+	t.Setenv("HOME", t.TempDir())
+
+	searchPath := t.TempDir()
+	writeProviderPlugin(t, searchPath, "custom-plugin", "custom")
+	writeProviderPlugin(t, searchPath, "brew-override", "brew")
+
+	comp := compiler.NewCompiler()
+	comp.RegisterProvider(&stubProvider{name: "brew"})
+
+	var warnings bytes.Buffer
+	grants := plugin.NewGrantStore(filepath.Join(t.TempDir(), "grants.json"))
+	newPluginProviders(plugin.NewLoader().WithSearchPaths(searchPath), grants, nil, &warnings).
+		register(context.Background(), comp)
+
+	names := make([]string, 0, len(comp.Providers()))
+	for _, p := range comp.Providers() {
+		names = append(names, p.Name())
+	}
+	assert.Equal(t, []string{"brew", "custom"}, names)
+	assert.Contains(t, warnings.String(), `provider "brew" conflicts with a builtin provider`)
+}
+
+func TestRegisterPluginProviders_NoPlugins(t *testing.T) {
+	t.Parallel()
+
+	comp := compiler.NewCompiler()
+	var warnings bytes.Buffer
+	newPluginProviders(plugin.NewLoader().WithSearchPaths(t.TempDir()), nil, nil, &warnings).
+		register(context.Background(), comp)
+
+	assert.Empty(t, comp.Providers())
+	assert.Empty(t, warnings.String())
+}
+
+func TestPluginProviders_ReloadChanged(t *testing.T) {
+	// Sets HOME so the plugin audit log stays in a temp dir.
+	t.Setenv("HOME", t.TempDir())
+
+	searchPath := t.TempDir()
+	writeProviderPlugin(t, searchPath, "first-plugin", "first")
+
+	comp := compiler.NewCompiler()
+	var warnings bytes.Buffer
+	grants := plugin.NewGrantStore(filepath.Join(t.TempDir(), "grants.json"))
+	pp := newPluginProviders(plugin.NewLoader().WithSearchPaths(searchPath), grants, nil, &warnings)
+	pp.register(context.Background(), comp)
+
+	logger := audit.NewMemoryLogger()
+	pp.audit = audit.NewService(logger)
+
+	providerNames := func() []string {
+		names := make([]string, 0)
+		for _, p := range comp.Providers() {
+			names = append(names, p.Name())
+		}
+		return names
+	}
+	assert.Equal(t, []string{"first"}, providerNames())
+	assert.Nil(t, pp.reloadChanged(context.Background(), comp))
+
+	writeProviderPlugin(t, searchPath, "second-plugin", "second")
+	reloads := pp.reloadChanged(context.Background(), comp)
+	require.Len(t, reloads, 1)
+	assert.Equal(t, PluginReload{Plugin: "second-plugin", Version: "1.0.0", Change: plugin.ChangeAdded}, reloads[0])
+	assert.ElementsMatch(t, []string{"first", "second"}, providerNames())
+
+	require.NoError(t, os.RemoveAll(filepath.Join(searchPath, "first-plugin")))
+	reloads = pp.reloadChanged(context.Background(), comp)
+	require.Len(t, reloads, 1)
+	assert.Equal(t, PluginReload{Plugin: "first-plugin", Version: "1.0.0", Change: plugin.ChangeRemoved}, reloads[0])
+	assert.Equal(t, []string{"second"}, providerNames())
+
+	events := logger.Events()
+	require.Len(t, events, 2)
+	assert.Equal(t, audit.EventPluginReloaded, events[0].Type)
+	assert.Equal(t, "second-plugin", events[0].Plugin)
+	assert.Equal(t, "removed", events[1].Details["change"])
+}
+
+func TestPluginProviders_ReloadInvalidPlugin(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	searchPath := t.TempDir()
+	comp := compiler.NewCompiler()
+	pp := newPluginProviders(plugin.NewLoader().WithSearchPaths(searchPath), nil, nil, nil)
+	pp.register(context.Background(), comp)
+	pp.audit = audit.NewService(audit.NewMemoryLogger())
+
+	broken := filepath.Join(searchPath, "broken")
+	require.NoError(t, os.MkdirAll(broken, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(broken, "plugin.yaml"), []byte("apiVersion: v1\n"), 0o600))
+
+	reloads := pp.reloadChanged(context.Background(), comp)
+	require.Len(t, reloads, 1)
+	assert.Equal(t, "broken", reloads[0].Plugin)
+	require.Error(t, reloads[0].Err)
+	assert.Contains(t, reloads[0].String(), "broken added")
+}
+
+type stubProvider struct {
+	name string
+}
+
+func (p *stubProvider) Name() string { return p.name }
+
+func (p *stubProvider) Compile(_ compiler.CompileContext) ([]compiler.Step, error) {
+	return nil, nil
+}
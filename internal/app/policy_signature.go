@@ -0,0 +1,64 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/catalog"
+	"github.com/felixgeelhaar/preflight/internal/domain/policy"
+)
+
+// ErrOrgPolicySignature reports an org policy whose keyless signature does
+// not verify.
+var ErrOrgPolicySignature = errors.New("org policy signature verification failed")
+
+// LoadSignedOrgPolicy loads the org policy file at path. When a Sigstore
+// bundle is published next to it as <path>.sigstore.json, the policy must
+// carry a keyless signature by an identity in the trust store, and signer
+// reports who signed it. Unsigned policies load with a nil signer, and a
+// missing file returns a nil policy.
+func LoadSignedOrgPolicy(path string) (orgPolicy *policy.OrgPolicy, signer *catalog.KeylessSigner, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("failed to read org policy file: %w", err)
+	}
+
+	if signer, err = verifyKeylessFile(path, data); err != nil {
+		return nil, nil, fmt.Errorf("%w: %s: %w", ErrOrgPolicySignature, path, err)
+	}
+	orgPolicy, err = policy.ParseOrgPolicyYAML(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return orgPolicy, signer, nil
+}
+
+// verifyKeylessFile verifies the keyless signature of the data of the file
+// at path against its Sigstore bundle, returning nil when it has none.
+func verifyKeylessFile(path string, data []byte) (*catalog.KeylessSigner, error) {
+	bundleData, err := os.ReadFile(path + catalog.SigstoreBundleSuffix)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	bundle, err := catalog.ParseKeylessBundle(bundleData)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := loadTrustStore()
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := store.KeylessVerifier()
+	if err != nil {
+		return nil, err
+	}
+	return verifier.VerifyBundle(data, bundle)
+}
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/catalog"
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
+	"github.com/felixgeelhaar/preflight/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadSignedOrgPolicy(t *testing.T) {
+	const (
+		issuer  = "https://accounts.google.com"
+		subject = "security@acme.com"
+	)
+	home := t.TempDir()
+	t.Setenv(platform.HomeEnvVar, home)
+
+	fixture := testutil.NewSigstoreFixture(t)
+	store := catalog.NewTrustStore(filepath.Join(home, "trust.json"))
+	_, err := store.ImportSigstoreRoot(fixture.TrustedRoot)
+	require.NoError(t, err)
+	require.NoError(t, store.Add(catalog.NewTrustedIdentity("acme-security", catalog.ExactSigstoreIdentity(issuer, subject))))
+	require.NoError(t, store.Save())
+
+	content := []byte("version: \"1\"\npolicy:\n  name: acme\n  required:\n    - pattern: \"git:*\"\n")
+	path := filepath.Join(t.TempDir(), "org-policy.yaml")
+	require.NoError(t, os.WriteFile(path, content, 0o644))
+
+	orgPolicy, signer, err := LoadSignedOrgPolicy(path)
+	require.NoError(t, err)
+	assert.Equal(t, "acme", orgPolicy.Name)
+	assert.Nil(t, signer)
+
+	require.NoError(t, os.WriteFile(path+catalog.SigstoreBundleSuffix, fixture.Sign(t, content, issuer, subject), 0o644))
+	orgPolicy, signer, err = LoadSignedOrgPolicy(path)
+	require.NoError(t, err)
+	assert.Equal(t, "acme", orgPolicy.Name)
+	require.NotNil(t, signer)
+	assert.Equal(t, "acme-security", signer.Name)
+
+	// Loosening the signed policy breaks the signature.
+	require.NoError(t, os.WriteFile(path, []byte("version: \"1\"\npolicy:\n  name: acme\n"), 0o644))
+	_, _, err = LoadSignedOrgPolicy(path)
+	require.ErrorIs(t, err, ErrOrgPolicySignature)
+	require.ErrorIs(t, err, catalog.ErrInvalidSignature)
+
+	// A policy signed by an identity outside the trust store is refused.
+	require.NoError(t, os.WriteFile(path, content, 0o644))
+	require.NoError(t, os.WriteFile(path+catalog.SigstoreBundleSuffix, fixture.Sign(t, content, issuer, "dev@acme.com"), 0o644))
+	_, _, err = LoadSignedOrgPolicy(path)
+	require.ErrorIs(t, err, catalog.ErrSigstoreIdentityMismatch)
+
+	orgPolicy, signer, err = LoadSignedOrgPolicy(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.NoError(t, err)
+	assert.Nil(t, orgPolicy)
+	assert.Nil(t, signer)
+}
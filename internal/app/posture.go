@@ -0,0 +1,55 @@
+package app
+
+import (
+	"context"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/policy"
+)
+
+// firewallCommand reports the state of the macOS application firewall.
+const firewallCommand = "/usr/libexec/ApplicationFirewall/socketfilterfw"
+
+// CollectPosture reads the security posture of this machine. Only macOS
+// is inspected; on other systems the state only records the OS.
+func CollectPosture(ctx context.Context) policy.PostureState {
+	return collectPosture(runtime.GOOS, func(name string, args ...string) (string, error) {
+		out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+		return string(out), err
+	})
+}
+
+func collectPosture(goos string, run func(string, ...string) (string, error)) policy.PostureState {
+	state := policy.PostureState{OS: goos}
+	if goos != "darwin" {
+		return state
+	}
+
+	if out, err := run("sw_vers", "-productVersion"); err == nil {
+		state.OSVersion = strings.TrimSpace(out)
+	}
+	if out, err := run("fdesetup", "status"); err == nil {
+		state.FileVault = postureFlag(strings.Contains(out, "FileVault is On"))
+	}
+	if out, err := run(firewallCommand, "--getglobalstate"); err == nil {
+		on := strings.Contains(out, "enabled") || strings.Contains(out, "State = 1") || strings.Contains(out, "State = 2")
+		state.Firewall = postureFlag(on)
+	}
+	// spctl exits non-zero when assessments are disabled.
+	if out, _ := run("spctl", "--status"); strings.Contains(out, "assessments") {
+		state.Gatekeeper = postureFlag(strings.Contains(out, "assessments enabled"))
+	}
+	if out, err := run("defaults", "-currentHost", "read", "com.apple.screensaver", "idleTime"); err == nil {
+		if seconds, err := strconv.Atoi(strings.TrimSpace(out)); err == nil {
+			state.ScreenLockSeconds = &seconds
+		}
+	}
+	return state
+}
+
+func postureFlag(on bool) *bool {
+	return &on
+}
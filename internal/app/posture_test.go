@@ -0,0 +1,50 @@
+package app
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectPosture(t *testing.T) {
+	t.Parallel()
+
+	outputs := map[string]string{
+		"sw_vers -productVersion":                                   "14.5\n",
+		"fdesetup status":                                           "FileVault is On.\n",
+		firewallCommand + " --getglobalstate":                       "Firewall is disabled. (State = 0)\n",
+		"defaults -currentHost read com.apple.screensaver idleTime": "300\n",
+	}
+	run := func(name string, args ...string) (string, error) {
+		command := strings.Join(append([]string{name}, args...), " ")
+		if command == "spctl --status" {
+			return "assessments disabled\n", errors.New("exit status 1")
+		}
+		if out, ok := outputs[command]; ok {
+			return out, nil
+		}
+		return "", errors.New("unexpected command " + command)
+	}
+
+	state := collectPosture("darwin", run)
+	assert.Equal(t, "darwin", state.OS)
+	assert.Equal(t, "14.5", state.OSVersion)
+	require.NotNil(t, state.FileVault)
+	assert.True(t, *state.FileVault)
+	require.NotNil(t, state.Firewall)
+	assert.False(t, *state.Firewall)
+	require.NotNil(t, state.Gatekeeper)
+	assert.False(t, *state.Gatekeeper)
+	require.NotNil(t, state.ScreenLockSeconds)
+	assert.Equal(t, 300, *state.ScreenLockSeconds)
+
+	linux := collectPosture("linux", func(string, ...string) (string, error) {
+		t.Fatal("nothing is inspected outside macOS")
+		return "", nil
+	})
+	assert.Equal(t, "linux", linux.OS)
+	assert.Nil(t, linux.FileVault)
+}
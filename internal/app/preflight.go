@@ -14,33 +14,49 @@ import (
 	"github.com/felixgeelhaar/preflight/internal/adapters/command"
 	"github.com/felixgeelhaar/preflight/internal/adapters/filesystem"
 	lockadapter "github.com/felixgeelhaar/preflight/internal/adapters/lockfile"
+	"github.com/felixgeelhaar/preflight/internal/adapters/logging"
+	"github.com/felixgeelhaar/preflight/internal/adapters/runlock"
 	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
 	"github.com/felixgeelhaar/preflight/internal/domain/config"
 	"github.com/felixgeelhaar/preflight/internal/domain/execution"
 	"github.com/felixgeelhaar/preflight/internal/domain/lock"
 	"github.com/felixgeelhaar/preflight/internal/domain/platform"
+	"github.com/felixgeelhaar/preflight/internal/domain/plugin"
 	"github.com/felixgeelhaar/preflight/internal/domain/policy"
 	"github.com/felixgeelhaar/preflight/internal/ports"
 	"github.com/felixgeelhaar/preflight/internal/provider/apt"
+	"github.com/felixgeelhaar/preflight/internal/provider/aws"
+	"github.com/felixgeelhaar/preflight/internal/provider/azure"
 	"github.com/felixgeelhaar/preflight/internal/provider/bootstrap"
 	"github.com/felixgeelhaar/preflight/internal/provider/brew"
 	"github.com/felixgeelhaar/preflight/internal/provider/cargo"
 	"github.com/felixgeelhaar/preflight/internal/provider/chocolatey"
+	"github.com/felixgeelhaar/preflight/internal/provider/cron"
+	"github.com/felixgeelhaar/preflight/internal/provider/docker"
+	"github.com/felixgeelhaar/preflight/internal/provider/editorconfig"
 	"github.com/felixgeelhaar/preflight/internal/provider/files"
+	"github.com/felixgeelhaar/preflight/internal/provider/gcloud"
 	"github.com/felixgeelhaar/preflight/internal/provider/gem"
 	"github.com/felixgeelhaar/preflight/internal/provider/git"
 	"github.com/felixgeelhaar/preflight/internal/provider/gotools"
 	"github.com/felixgeelhaar/preflight/internal/provider/helix"
 	"github.com/felixgeelhaar/preflight/internal/provider/jetbrains"
+	"github.com/felixgeelhaar/preflight/internal/provider/kubernetes"
+	"github.com/felixgeelhaar/preflight/internal/provider/macos"
+	"github.com/felixgeelhaar/preflight/internal/provider/mas"
+	"github.com/felixgeelhaar/preflight/internal/provider/network"
 	"github.com/felixgeelhaar/preflight/internal/provider/npm"
 	"github.com/felixgeelhaar/preflight/internal/provider/nvim"
+	"github.com/felixgeelhaar/preflight/internal/provider/passwordmanager"
 	"github.com/felixgeelhaar/preflight/internal/provider/pip"
 	"github.com/felixgeelhaar/preflight/internal/provider/runtime"
 	"github.com/felixgeelhaar/preflight/internal/provider/scoop"
+	"github.com/felixgeelhaar/preflight/internal/provider/scripts"
 	"github.com/felixgeelhaar/preflight/internal/provider/shell"
 	"github.com/felixgeelhaar/preflight/internal/provider/ssh"
 	"github.com/felixgeelhaar/preflight/internal/provider/sublime"
 	"github.com/felixgeelhaar/preflight/internal/provider/terminal"
+	"github.com/felixgeelhaar/preflight/internal/provider/tmux"
 	"github.com/felixgeelhaar/preflight/internal/provider/vscode"
 	"github.com/felixgeelhaar/preflight/internal/provider/windsurf"
 	"github.com/felixgeelhaar/preflight/internal/provider/winget"
@@ -55,14 +71,23 @@ type Preflight struct {
 	mode              config.ReproducibilityMode
 	modeSet           bool
 	rollbackOnFailure bool
+	runLockPath       string
+	runLockWait       bool
+	transcripts       *transcriptRunner
+	lastTranscript    *Transcript
 	out               io.Writer
 	lifecycle         *LifecycleManager
+	files             *files.Provider
+	backups           *BackupStore
+	plugins           *pluginProviders
+	logger            ports.Logger
 }
 
 // New creates a new Preflight application.
 func New(out io.Writer) *Preflight {
-	// Create real implementations
-	cmdRunner := command.NewRealRunner()
+	// Create real implementations. Commands run by steps are recorded for
+	// apply transcripts.
+	cmdRunner := newTranscriptRunner(command.NewRealRunner())
 	fs := filesystem.NewRealFileSystem()
 
 	// Detect platform for platform-aware providers
@@ -71,6 +96,9 @@ func New(out io.Writer) *Preflight {
 	// Create lifecycle manager for file snapshots and drift tracking
 	lifecycle, _ := DefaultLifecycleManager()
 
+	// Files preflight did not manage are backed up before their first change
+	backups, _ := DefaultBackupStore()
+
 	// Create files provider with lifecycle for automatic snapshots
 	filesProvider := files.NewProvider(fs)
 	if lifecycle != nil {
@@ -81,35 +109,56 @@ func New(out io.Writer) *Preflight {
 	comp := compiler.NewCompiler()
 	comp.RegisterProvider(bootstrap.NewProvider(cmdRunner, plat))
 	comp.RegisterProvider(apt.NewProvider(cmdRunner))
-	comp.RegisterProvider(brew.NewProvider(cmdRunner))
+	comp.RegisterProvider(aws.NewProvider(cmdRunner))
+	comp.RegisterProvider(azure.NewProvider(cmdRunner))
+	comp.RegisterProvider(brew.NewProvider(cmdRunner).WithPlatform(plat))
 	comp.RegisterProvider(cargo.NewProvider(cmdRunner))
 	comp.RegisterProvider(chocolatey.NewProvider(cmdRunner, plat))
+	comp.RegisterProvider(cron.NewProvider(cmdRunner))
+	comp.RegisterProvider(docker.NewProvider(cmdRunner))
+	comp.RegisterProvider(editorconfig.NewProvider(fs))
 	comp.RegisterProvider(filesProvider)
+	comp.RegisterProvider(gcloud.NewProvider(cmdRunner))
 	comp.RegisterProvider(gem.NewProvider(cmdRunner))
 	comp.RegisterProvider(git.NewProvider(fs))
 	comp.RegisterProvider(gotools.NewProvider(cmdRunner))
 	comp.RegisterProvider(helix.NewProvider(cmdRunner))
 	comp.RegisterProvider(jetbrains.NewProvider(cmdRunner))
+	comp.RegisterProvider(kubernetes.NewProvider(cmdRunner))
+	comp.RegisterProvider(macos.NewProvider(cmdRunner))
+	comp.RegisterProvider(mas.NewProvider(cmdRunner))
+	comp.RegisterProvider(network.NewProvider(cmdRunner))
 	comp.RegisterProvider(npm.NewProvider(cmdRunner))
 	comp.RegisterProvider(nvim.NewProvider(fs, cmdRunner))
+	comp.RegisterProvider(passwordmanager.NewProvider(fs, cmdRunner))
 	comp.RegisterProvider(pip.NewProvider(cmdRunner))
 	comp.RegisterProvider(runtime.NewProviderWith(fs, cmdRunner))
 	comp.RegisterProvider(scoop.NewProvider(cmdRunner, plat))
+	comp.RegisterProvider(scripts.NewProvider(cmdRunner))
 	comp.RegisterProvider(shell.NewProviderWith(fs, cmdRunner))
 	comp.RegisterProvider(ssh.NewProvider(fs))
 	comp.RegisterProvider(sublime.NewProvider(cmdRunner))
 	comp.RegisterProvider(terminal.NewProvider(fs, cmdRunner))
+	comp.RegisterProvider(tmux.NewProvider(cmdRunner))
 	comp.RegisterProvider(vscode.NewProvider(fs, cmdRunner, plat))
 	comp.RegisterProvider(windsurf.NewProvider(cmdRunner))
 	comp.RegisterProvider(winget.NewProvider(cmdRunner, plat))
+	plugins := newPluginProviders(plugin.NewLoader(),
+		plugin.NewGrantStore(plugin.DefaultGrantsPath()), stdinPrompter(), os.Stderr)
+	plugins.register(context.Background(), comp)
 
 	return &Preflight{
-		compiler:  comp,
-		planner:   execution.NewPlanner(),
-		executor:  execution.NewExecutor(),
-		lockRepo:  lockadapter.NewYAMLRepository(),
-		out:       out,
-		lifecycle: lifecycle,
+		compiler:    comp,
+		planner:     execution.NewPlanner(),
+		executor:    execution.NewExecutor(),
+		lockRepo:    lockadapter.NewYAMLRepository(),
+		transcripts: cmdRunner,
+		out:         out,
+		lifecycle:   lifecycle,
+		files:       filesProvider,
+		backups:     backups,
+		plugins:     plugins,
+		logger:      logging.Default(),
 	}
 }
 
@@ -126,6 +175,55 @@ func (p *Preflight) WithRollbackOnFailure(enabled bool) *Preflight {
 	return p
 }
 
+// WithRunLockWait makes Apply wait for another preflight process holding
+// the run lock to finish instead of failing.
+func (p *Preflight) WithRunLockWait(wait bool) *Preflight {
+	p.runLockWait = wait
+	return p
+}
+
+// WithMergePreference sets how copies and templates resolve conflicts
+// between local edits and config changes.
+func (p *Preflight) WithMergePreference(prefer files.MergePreference) *Preflight {
+	if p.files != nil {
+		p.files.WithMergePreference(prefer)
+	}
+	return p
+}
+
+// WithRunLockPath sets the run lock file. It defaults to
+// ~/.preflight/run.lock.
+func (p *Preflight) WithRunLockPath(path string) *Preflight {
+	p.runLockPath = path
+	return p
+}
+
+// WithLogger sets the logger for diagnostics. It defaults to the
+// process-wide logger.
+func (p *Preflight) WithLogger(logger ports.Logger) *Preflight {
+	p.logger = logger
+	return p
+}
+
+// logContext attaches the logger to ctx so that domain services log
+// through it.
+func (p *Preflight) logContext(ctx context.Context) context.Context {
+	return ports.ContextWithLogger(ctx, p.logger)
+}
+
+// WithStepObserver reports each step result to observer while applying.
+func (p *Preflight) WithStepObserver(observer execution.StepObserver) *Preflight {
+	p.executor = p.executor.WithStepObserver(observer)
+	return p
+}
+
+// WithStepStartObserver reports each step to observer before it runs while
+// applying.
+func (p *Preflight) WithStepStartObserver(observer execution.StepStartObserver) *Preflight {
+	p.executor = p.executor.WithStepStartObserver(observer)
+	return p
+}
+
 // WithLockRepo sets the lock repository for lockfile operations.
 func (p *Preflight) WithLockRepo(repo lock.Repository) *Preflight {
 	p.lockRepo = repo
@@ -134,6 +232,14 @@ func (p *Preflight) WithLockRepo(repo lock.Repository) *Preflight {
 
 // Plan loads configuration and creates an execution plan.
 func (p *Preflight) Plan(ctx context.Context, configPath, target string) (*execution.Plan, error) {
+	return p.planWith(ctx, p.planner, configPath, target)
+}
+
+// planWith creates an execution plan, checking steps with planner.
+func (p *Preflight) planWith(ctx context.Context, planner *execution.Planner, configPath, target string) (*execution.Plan, error) {
+	ctx = p.logContext(ctx)
+	started := time.Now()
+
 	mode, err := p.resolveMode(configPath)
 	if err != nil {
 		return nil, err
@@ -162,18 +268,105 @@ func (p *Preflight) Plan(ctx context.Context, configPath, target string) (*execu
 	}
 
 	// Create execution plan
-	plan, err := p.planner.Plan(ctx, graph)
+	plan, err := planner.Plan(ctx, graph)
 	if err != nil {
 		return nil, fmt.Errorf("failed to plan: %w", err)
 	}
 
+	summary := plan.Summary()
+	p.logger.Info(ctx, "plan created",
+		ports.F("config", configPath),
+		ports.F("target", target),
+		ports.F("steps", summary.Total),
+		ports.F("needs_apply", summary.NeedsApply),
+		ports.F("duration", time.Since(started)))
 	return plan, nil
 }
 
-// Apply executes the plan.
+// Apply executes the plan. Unless dryRun is set, it holds the run lock
+// while steps run so that concurrent applies, doctor --fix and agent
+// reconciles do not interleave.
 func (p *Preflight) Apply(ctx context.Context, plan *execution.Plan, dryRun bool) ([]execution.StepResult, error) {
+	ctx = p.logContext(ctx)
+	started := time.Now()
+	results, err := p.apply(ctx, plan, dryRun)
+	fields := []ports.Field{
+		ports.F("steps", len(results)),
+		ports.F("dry_run", dryRun),
+		ports.F("duration", time.Since(started)),
+	}
+	if err != nil {
+		p.logger.Error(ctx, "apply failed", append(fields, ports.F("error", err))...)
+	} else {
+		p.logger.Info(ctx, "apply finished", fields...)
+	}
+	return results, err
+}
+
+func (p *Preflight) apply(ctx context.Context, plan *execution.Plan, dryRun bool) ([]execution.StepResult, error) {
+	if !dryRun {
+		runLock, err := p.acquireRunLock(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = runLock.Release() }()
+	}
 	executor := p.executor.WithDryRun(dryRun).WithRollbackOnFailure(p.rollbackOnFailure)
-	return executor.Execute(ctx, plan)
+	if dryRun || p.transcripts == nil {
+		return executor.Execute(ctx, plan)
+	}
+
+	capture := captureBefore(ctx, plan)
+	snapshotID := p.snapshotManagedFiles(ctx, capture.existingFiles())
+	backups := p.backupUnmanagedFiles(ctx, capture)
+	p.transcripts.start()
+	results, err := executor.Execute(ctx, plan)
+	outputs := p.transcripts.stop()
+	transcript := capture.finish(ctx, plan, results)
+	transcript.Outputs = outputs
+	transcript.Snapshot = snapshotID
+	transcript.Backups = p.keepChangedBackups(ctx, backups, transcript)
+	p.lastTranscript = transcript
+	return results, err
+}
+
+// snapshotManagedFiles snapshots the files an apply is about to change so
+// that it can be undone, and returns the snapshot set ID. Failing to
+// snapshot only warns: the apply proceeds without being undoable.
+func (p *Preflight) snapshotManagedFiles(ctx context.Context, paths []string) string {
+	if p.lifecycle == nil || len(paths) == 0 {
+		return ""
+	}
+	set, err := p.lifecycle.Snapshot().BeforeApply(ctx, paths)
+	if err != nil {
+		p.logger.Warn(ctx, "could not snapshot files before apply", ports.F("error", err))
+		return ""
+	}
+	return set.ID
+}
+
+// LastTranscript returns the transcript of the last apply that was not a
+// dry run, or nil.
+func (p *Preflight) LastTranscript() *Transcript {
+	return p.lastTranscript
+}
+
+func (p *Preflight) acquireRunLock(ctx context.Context) (*runlock.Lock, error) {
+	path := p.runLockPath
+	if path == "" {
+		var err error
+		if path, err = runlock.DefaultPath(); err != nil {
+			return nil, fmt.Errorf("failed to locate run lock: %w", err)
+		}
+	}
+	if p.runLockWait {
+		return runlock.Wait(ctx, path)
+	}
+	runLock, err := runlock.Acquire(path)
+	if errors.Is(err, runlock.ErrLocked) {
+		return nil, fmt.Errorf("%w; wait for it to finish or rerun with --wait", err)
+	}
+	return runLock, err
 }
 
 // UpdateLockFromPlan updates the lockfile based on lockable steps in the plan.
@@ -203,8 +396,53 @@ func (p *Preflight) UpdateLockFromPlan(ctx context.Context, configPath string, p
 
 	lockfile = lockfile.WithMode(mode)
 
+	pkgs, err := planPackageLocks(ctx, plan)
+	if err != nil {
+		return err
+	}
+
+	before := lockfile.Packages()
 	lockedKeys := make(map[string]struct{})
 	lockedProviders := make(map[string]struct{})
+	for _, pkg := range pkgs {
+		if err := lockfile.SetPackage(pkg); err != nil {
+			return fmt.Errorf("failed to update lockfile: %w", err)
+		}
+		lockedKeys[pkg.Key()] = struct{}{}
+		lockedProviders[pkg.Provider()] = struct{}{}
+	}
+
+	for key, pkg := range lockfile.Packages() {
+		if _, ok := lockedProviders[pkg.Provider()]; !ok {
+			continue
+		}
+		if _, ok := lockedKeys[key]; ok {
+			continue
+		}
+		lockfile.RemovePackage(pkg.Provider(), pkg.Name())
+	}
+
+	// Advance the version vector when the locked versions changed so other
+	// machines can tell that they are behind.
+	if packageVersionsChanged(before, lockfile.Packages()) {
+		recordLockActivity(lockfile)
+	}
+
+	repo, err := p.lockRepoFor(configPath)
+	if err != nil {
+		return err
+	}
+	if err := repo.Save(ctx, lockPath, lockfile); err != nil {
+		return fmt.Errorf("failed to save lockfile: %w", err)
+	}
+
+	p.printf("Lockfile updated: %s\n", lockPath)
+	return nil
+}
+
+// planPackageLocks resolves the package locks for the lockable steps in plan.
+func planPackageLocks(ctx context.Context, plan *execution.Plan) ([]lock.PackageLock, error) {
+	var pkgs []lock.PackageLock
 	runCtx := compiler.NewRunContext(ctx)
 	for _, entry := range plan.Entries() {
 		lockable, ok := entry.Step().(compiler.LockableStep)
@@ -226,7 +464,7 @@ func (p *Preflight) UpdateLockFromPlan(ctx context.Context, configPath string, p
 			if versioned, ok := entry.Step().(compiler.VersionedStep); ok {
 				installed, ok, err := versioned.InstalledVersion(runCtx)
 				if err != nil {
-					return fmt.Errorf("failed to resolve installed version for %s:%s: %w", provider, name, err)
+					return nil, fmt.Errorf("failed to resolve installed version for %s:%s: %w", provider, name, err)
 				}
 				installed = strings.TrimSpace(installed)
 				if ok && installed != "" {
@@ -241,33 +479,25 @@ func (p *Preflight) UpdateLockFromPlan(ctx context.Context, configPath string, p
 		integrity := lock.IntegrityFromData(lock.AlgorithmSHA256, []byte(provider+":"+name+"@"+version))
 		pkg, err := lock.NewPackageLock(provider, name, version, integrity, time.Now())
 		if err != nil {
-			return fmt.Errorf("failed to lock %s:%s: %w", provider, name, err)
-		}
-		if err := lockfile.SetPackage(pkg); err != nil {
-			return fmt.Errorf("failed to update lockfile: %w", err)
+			return nil, fmt.Errorf("failed to lock %s:%s: %w", provider, name, err)
 		}
-
-		key := provider + ":" + name
-		lockedKeys[key] = struct{}{}
-		lockedProviders[provider] = struct{}{}
+		pkgs = append(pkgs, pkg)
 	}
+	return pkgs, nil
+}
 
-	for key, pkg := range lockfile.Packages() {
-		if _, ok := lockedProviders[pkg.Provider()]; !ok {
-			continue
-		}
-		if _, ok := lockedKeys[key]; ok {
-			continue
-		}
-		lockfile.RemovePackage(pkg.Provider(), pkg.Name())
+// packageVersionsChanged reports whether two package sets differ in keys or versions.
+func packageVersionsChanged(before, after map[string]lock.PackageLock) bool {
+	if len(before) != len(after) {
+		return true
 	}
-
-	if err := p.lockRepo.Save(ctx, lockPath, lockfile); err != nil {
-		return fmt.Errorf("failed to save lockfile: %w", err)
+	for key, pkg := range after {
+		prev, ok := before[key]
+		if !ok || prev.Version() != pkg.Version() {
+			return true
+		}
 	}
-
-	p.printf("Lockfile updated: %s\n", lockPath)
-	return nil
+	return false
 }
 
 // LoadMergedConfig loads and merges configuration, returning the raw map.
@@ -466,6 +696,12 @@ func (p *Preflight) Validate(ctx context.Context, configPath, targetName string)
 func (p *Preflight) ValidateWithOptions(ctx context.Context, configPath, targetName string, opts ValidateOptions) (*ValidationResult, error) {
 	result := &ValidationResult{}
 
+	// Check the files against their schemas first: type errors found there
+	// point at a line and column, which loading cannot.
+	if p.validateSchemas(configPath, targetName, result) {
+		return result, nil
+	}
+
 	mode, err := p.resolveMode(configPath)
 	if err != nil {
 		return nil, err
@@ -505,6 +741,9 @@ func (p *Preflight) ValidateWithOptions(ctx context.Context, configPath, targetN
 	// Check for potential issues
 	p.validateSteps(ctx, graph, result)
 
+	// Check for packages declared by several layers
+	p.validateDuplicates(configPath, targetName, result)
+
 	// Check policies (allow/deny rules)
 	p.validatePolicies(ctx, cfg, graph, opts, result)
 
@@ -709,12 +948,18 @@ func (p *Preflight) validateOrgPolicies(_ context.Context, cfg map[string]interf
 
 	// Load org policy from external file if specified
 	if opts.OrgPolicyFile != "" {
-		fileOrgPolicy, err := policy.LoadOrgPolicyFromFile(opts.OrgPolicyFile)
-		if err != nil {
+		fileOrgPolicy, signer, err := LoadSignedOrgPolicy(opts.OrgPolicyFile)
+		switch {
+		case errors.Is(err, ErrOrgPolicySignature):
+			result.Errors = append(result.Errors, err.Error())
+		case err != nil:
 			result.Warnings = append(result.Warnings, fmt.Sprintf("Failed to load org policy file: %v", err))
-		} else if fileOrgPolicy != nil {
+		case fileOrgPolicy != nil:
 			orgPolicies = append(orgPolicies, fileOrgPolicy)
 			result.Info = append(result.Info, fmt.Sprintf("Loaded org policy from %s: %s", opts.OrgPolicyFile, fileOrgPolicy.Name))
+			if signer != nil {
+				result.Info = append(result.Info, fmt.Sprintf("Org policy signed by %s", signer))
+			}
 		}
 	}
 
@@ -729,16 +974,19 @@ func (p *Preflight) validateOrgPolicies(_ context.Context, cfg map[string]interf
 		return
 	}
 
-	// Extract step IDs for org policy evaluation
+	// Extract step IDs, and the values steps configure, for org policy evaluation
 	steps := graph.Steps()
-	stepIDs := make([]string, len(steps))
-	for i, step := range steps {
-		stepIDs[i] = step.ID().String()
+	values := make([]string, 0, len(steps))
+	for _, step := range steps {
+		values = append(values, step.ID().String())
+		if s, ok := step.(compiler.PolicyValuesStep); ok {
+			values = append(values, s.PolicyValues()...)
+		}
 	}
 
 	// Evaluate org policy
 	evaluator := policy.NewOrgEvaluator(mergedOrgPolicy)
-	orgResult := evaluator.Evaluate(stepIDs)
+	orgResult := evaluator.Evaluate(values)
 
 	// Add violations based on enforcement mode
 	if orgResult.HasViolations() {
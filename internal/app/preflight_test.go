@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/felixgeelhaar/preflight/internal/adapters/runlock"
 	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
 	"github.com/felixgeelhaar/preflight/internal/domain/config"
 	"github.com/felixgeelhaar/preflight/internal/domain/execution"
@@ -1213,6 +1214,7 @@ func (s *lockInfoStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
 func (s *lockInfoStep) LockInfo() (compiler.LockInfo, bool) { return s.info, true }
 
 func TestPreflight_UpdateLockFromPlan(t *testing.T) {
+	stubMachineID(t, "550e8400-e29b-41d4-a716-446655440000")
 	var buf bytes.Buffer
 	pf := New(&buf)
 
@@ -1722,6 +1724,27 @@ targets:
 	}
 }
 
+func TestPreflight_Apply_HeldRunLock(t *testing.T) {
+	lockPath := filepath.Join(t.TempDir(), "run.lock")
+	held, err := runlock.Acquire(lockPath)
+	require.NoError(t, err)
+
+	pf := New(&bytes.Buffer{}).WithRunLockPath(lockPath)
+	_, err = pf.Apply(context.Background(), execution.NewExecutionPlan(), false)
+	require.ErrorIs(t, err, runlock.ErrLocked)
+	require.Contains(t, err.Error(), "--wait")
+
+	// Dry runs change nothing and do not take the lock.
+	_, err = pf.Apply(context.Background(), execution.NewExecutionPlan(), true)
+	require.NoError(t, err)
+
+	require.NoError(t, held.Release())
+	_, err = pf.Apply(context.Background(), execution.NewExecutionPlan(), false)
+	require.NoError(t, err)
+	_, err = os.Stat(lockPath)
+	require.True(t, os.IsNotExist(err), "Apply should release the run lock")
+}
+
 func TestPreflight_Validate_ValidConfig(t *testing.T) {
 	skipIfNoHomebrew(t)
 
@@ -1825,6 +1848,44 @@ targets:
 	}
 }
 
+func TestPreflight_ValidateWithOptions_NetworkOrgPolicy(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"preflight.yaml": "targets:\n  default:\n    - base\n",
+		"layers/base.yaml": `
+name: base
+network:
+  dns:
+    servers: [10.0.0.53]
+  proxy:
+    http: proxy.corp.example.com:8080
+`,
+		"policy.yaml": `
+policy:
+  name: corporate-network
+  enforcement: block
+  required:
+    - pattern: "network:dns:10.0.0.53"
+    - pattern: "network:proxy:http:proxy.corp.example.com:*"
+    - pattern: "network:search_domain:*"
+      message: corporate search domain
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	pf := New(&bytes.Buffer{})
+	result, err := pf.ValidateWithOptions(context.Background(), filepath.Join(tmpDir, "preflight.yaml"), "default", ValidateOptions{
+		OrgPolicyFile: filepath.Join(tmpDir, "policy.yaml"),
+	})
+	require.NoError(t, err)
+	require.Len(t, result.PolicyViolations, 1)
+	require.Contains(t, result.PolicyViolations[0], "network:search_domain:*")
+}
+
 func TestValidationResult_Empty(t *testing.T) {
 	result := &ValidationResult{}
 	if len(result.Errors) != 0 {
@@ -1837,3 +1898,40 @@ func TestValidationResult_Empty(t *testing.T) {
 		t.Errorf("Expected 0 info, got %d", len(result.Info))
 	}
 }
+
+type artifactStep struct {
+	*lockInfoStep
+	artifact compiler.ArtifactInfo
+}
+
+func (s *artifactStep) ArtifactInfo() (compiler.ArtifactInfo, bool) { return s.artifact, true }
+
+func TestCheckArtifactVerification(t *testing.T) {
+	t.Parallel()
+
+	unverified := &artifactStep{
+		lockInfoStep: newLockInfoStep("go", "gopls", "latest"),
+		artifact:     compiler.ArtifactInfo{Name: "gopls", Source: "golang.org/x/tools/gopls@latest"},
+	}
+	verified := &artifactStep{
+		lockInfoStep: newLockInfoStep("go", "golangci-lint", "v1.0.0"),
+		artifact:     compiler.ArtifactInfo{Name: "golangci-lint", Source: "example.com/golangci-lint@v1.0.0", Checksum: "sha256:abc"},
+	}
+	pending := &artifactStep{
+		lockInfoStep: newLockInfoStep("go", "staticcheck", "latest"),
+		artifact:     compiler.ArtifactInfo{Name: "staticcheck", Source: "honnef.co/go/tools/cmd/staticcheck@latest"},
+	}
+
+	plan := execution.NewExecutionPlan()
+	plan.Add(execution.NewPlanEntry(unverified, compiler.StatusSatisfied, compiler.Diff{}))
+	plan.Add(execution.NewPlanEntry(verified, compiler.StatusSatisfied, compiler.Diff{}))
+	plan.Add(execution.NewPlanEntry(pending, compiler.StatusNeedsApply, compiler.Diff{}))
+
+	report := &DoctorReport{}
+	checkArtifactVerification(plan, report)
+
+	require.Len(t, report.Issues, 1)
+	require.Equal(t, unverified.ID().String(), report.Issues[0].StepID)
+	require.Equal(t, SeverityWarning, report.Issues[0].Severity)
+	require.Contains(t, report.Issues[0].Message, "without checksum verification")
+}
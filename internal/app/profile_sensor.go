@@ -0,0 +1,116 @@
+package app
+
+import (
+	"context"
+	"net"
+	"runtime"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/profile"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+)
+
+// vpnInterfacePrefixes are interface names used by common VPN clients
+// (OpenVPN, WireGuard, IPsec, GlobalProtect, macOS utun).
+var vpnInterfacePrefixes = []string{"tun", "tap", "wg", "ppp", "ipsec", "gpd", "utun"}
+
+// ProfileSensor observes the Wi-Fi network and VPN state that profile
+// triggers match against.
+type ProfileSensor struct {
+	runner     ports.CommandRunner
+	goos       string
+	interfaces func() ([]net.Interface, error)
+	addrs      func(net.Interface) ([]net.Addr, error)
+}
+
+// NewProfileSensor creates a ProfileSensor for the current OS.
+func NewProfileSensor(runner ports.CommandRunner) *ProfileSensor {
+	return &ProfileSensor{
+		runner:     runner,
+		goos:       runtime.GOOS,
+		interfaces: net.Interfaces,
+		addrs:      func(i net.Interface) ([]net.Addr, error) { return i.Addrs() },
+	}
+}
+
+// Sense returns the current environment. Anything that cannot be detected
+// is left empty rather than reported as an error.
+func (s *ProfileSensor) Sense(ctx context.Context) profile.Environment {
+	return profile.Environment{
+		SSID:         s.ssid(ctx),
+		VPNConnected: s.vpnConnected(),
+	}
+}
+
+func (s *ProfileSensor) ssid(ctx context.Context) string {
+	switch s.goos {
+	case "darwin":
+		// ipconfig works on current macOS; networksetup on older releases.
+		if out, ok := s.run(ctx, "ipconfig", "getsummary", "en0"); ok {
+			for _, line := range strings.Split(out, "\n") {
+				if key, value, found := strings.Cut(strings.TrimSpace(line), " : "); found && key == "SSID" {
+					return strings.TrimSpace(value)
+				}
+			}
+		}
+		if out, ok := s.run(ctx, "networksetup", "-getairportnetwork", "en0"); ok {
+			if _, name, found := strings.Cut(out, "Current Wi-Fi Network: "); found {
+				return strings.TrimSpace(name)
+			}
+		}
+	case "linux":
+		if out, ok := s.run(ctx, "nmcli", "-t", "-f", "active,ssid", "dev", "wifi"); ok {
+			for _, line := range strings.Split(out, "\n") {
+				if name, found := strings.CutPrefix(strings.TrimSpace(line), "yes:"); found {
+					return name
+				}
+			}
+		}
+		if out, ok := s.run(ctx, "iwgetid", "-r"); ok {
+			return strings.TrimSpace(out)
+		}
+	}
+	return ""
+}
+
+func (s *ProfileSensor) run(ctx context.Context, name string, args ...string) (string, bool) {
+	result, err := s.runner.Run(ctx, name, args...)
+	if err != nil || !result.Success() {
+		return "", false
+	}
+	return result.Stdout, true
+}
+
+// vpnConnected reports whether a VPN-style interface is up with an IPv4
+// address. macOS keeps idle utun interfaces with only IPv6 link-local
+// addresses, so those do not count.
+func (s *ProfileSensor) vpnConnected() bool {
+	ifaces, err := s.interfaces()
+	if err != nil {
+		return false
+	}
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || !isVPNInterfaceName(iface.Name) {
+			continue
+		}
+		addrs, err := s.addrs(iface)
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.To4() != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isVPNInterfaceName(name string) bool {
+	for _, prefix := range vpnInterfacePrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/testutil/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestProfileSensor(goos string, runner ports.CommandRunner, ifaces []net.Interface, addrs map[string][]net.Addr) *ProfileSensor {
+	return &ProfileSensor{
+		runner:     runner,
+		goos:       goos,
+		interfaces: func() ([]net.Interface, error) { return ifaces, nil },
+		addrs:      func(i net.Interface) ([]net.Addr, error) { return addrs[i.Name], nil },
+	}
+}
+
+func TestProfileSensor_SSID(t *testing.T) {
+	t.Parallel()
+
+	darwin := mocks.NewCommandRunner()
+	darwin.AddResult("ipconfig", []string{"getsummary", "en0"}, ports.CommandResult{
+		Stdout: "<dictionary> {\n  BSSID : aa:bb\n  SSID : CorpWiFi\n}\n",
+	})
+	env := newTestProfileSensor("darwin", darwin, nil, nil).Sense(context.Background())
+	assert.Equal(t, "CorpWiFi", env.SSID)
+
+	legacy := mocks.NewCommandRunner()
+	legacy.AddResult("ipconfig", []string{"getsummary", "en0"}, ports.CommandResult{ExitCode: 1})
+	legacy.AddResult("networksetup", []string{"-getairportnetwork", "en0"}, ports.CommandResult{
+		Stdout: "Current Wi-Fi Network: Home\n",
+	})
+	assert.Equal(t, "Home", newTestProfileSensor("darwin", legacy, nil, nil).Sense(context.Background()).SSID)
+
+	linux := mocks.NewCommandRunner()
+	linux.AddResult("nmcli", []string{"-t", "-f", "active,ssid", "dev", "wifi"}, ports.CommandResult{
+		Stdout: "no:Neighbour\nyes:Office 5G\n",
+	})
+	assert.Equal(t, "Office 5G", newTestProfileSensor("linux", linux, nil, nil).Sense(context.Background()).SSID)
+
+	assert.Empty(t, newTestProfileSensor("linux", mocks.NewCommandRunner(), nil, nil).Sense(context.Background()).SSID)
+}
+
+func TestProfileSensor_VPN(t *testing.T) {
+	t.Parallel()
+
+	v4 := &net.IPNet{IP: net.ParseIP("10.8.0.2"), Mask: net.CIDRMask(24, 32)}
+	v6 := &net.IPNet{IP: net.ParseIP("fe80::1"), Mask: net.CIDRMask(64, 128)}
+	runner := mocks.NewCommandRunner()
+
+	tests := []struct {
+		name   string
+		ifaces []net.Interface
+		addrs  map[string][]net.Addr
+		want   bool
+	}{
+		{"wireguard up", []net.Interface{{Name: "wg0", Flags: net.FlagUp}}, map[string][]net.Addr{"wg0": {v4}}, true},
+		{"idle utun", []net.Interface{{Name: "utun3", Flags: net.FlagUp}}, map[string][]net.Addr{"utun3": {v6}}, false},
+		{"tun down", []net.Interface{{Name: "tun0"}}, map[string][]net.Addr{"tun0": {v4}}, false},
+		{"ethernet", []net.Interface{{Name: "en0", Flags: net.FlagUp}}, map[string][]net.Addr{"en0": {v4}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			env := newTestProfileSensor("linux", runner, tt.ifaces, tt.addrs).Sense(context.Background())
+			assert.Equal(t, tt.want, env.VPNConnected)
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/domain/execution"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+)
+
+// projectTarget names the target of a project plan in step contexts and
+// reports.
+const projectTarget = "project"
+
+// PlanProject creates an execution plan for the tools a project declares.
+// The user's targets and layers are not loaded. Project-scoped runtime
+// versions are written relative to the working directory, so callers run
+// it from project.Root.
+func (p *Preflight) PlanProject(ctx context.Context, project *config.Project) (*execution.Plan, error) {
+	return p.planProject(ctx, p.planner, project)
+}
+
+func (p *Preflight) planProject(ctx context.Context, planner *execution.Planner, project *config.Project) (*execution.Plan, error) {
+	ctx = p.logContext(ctx)
+
+	merged, err := config.NewMerger().Merge([]config.Layer{project.Layer()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load project: %w", err)
+	}
+	compileCtx := compiler.NewCompileContext(merged.Raw()).
+		WithConfigRoot(project.Root).
+		WithTarget(projectTarget)
+	graph, err := p.compiler.CompileWithContext(compileCtx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile: %w", err)
+	}
+
+	plan, err := planner.Plan(ctx, graph)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan: %w", err)
+	}
+	p.logger.Info(ctx, "project plan created",
+		ports.F("project", project.Root),
+		ports.F("steps", plan.Summary().Total))
+	return plan, nil
+}
+
+// DoctorProject checks the machine against the requirements of a project.
+// Only the plan is checked; the checks of the user's configuration are not
+// run.
+func (p *Preflight) DoctorProject(ctx context.Context, project *config.Project, opts DoctorOptions) (*DoctorReport, error) {
+	startTime := time.Now()
+	report := &DoctorReport{
+		ConfigPath:   filepath.Join(project.Root, config.ProjectFile),
+		Target:       projectTarget,
+		Issues:       make([]DoctorIssue, 0),
+		BinaryChecks: make([]BinaryCheckResult, 0),
+		CheckedAt:    startTime,
+	}
+
+	planner := p.planner.WithParallelism(opts.Parallelism).WithProviderTimeout(opts.CheckTimeout)
+	plan, err := p.planProject(ctx, planner, project)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create plan: %w", err)
+	}
+	addDriftIssues(plan, opts.CheckTimeout, "preflight project apply", report)
+	checkArtifactVerification(plan, report)
+
+	report.Duration = time.Since(startTime)
+	return report, nil
+}
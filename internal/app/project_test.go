@@ -0,0 +1,46 @@
+package app
+
+import (
+	"context"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreflight_DoctorProject(t *testing.T) {
+	root := t.TempDir()
+	t.Chdir(root)
+	t.Setenv("HOME", t.TempDir())
+
+	project := &config.Project{
+		Root: root,
+		Runtime: config.RuntimeConfig{
+			Scope: "project",
+			Tools: []config.RuntimeToolConfig{{Name: "golang", Version: "1.23.0"}},
+		},
+	}
+	p := New(io.Discard)
+
+	report, err := p.DoctorProject(context.Background(), project, NewDoctorOptions("", "project"))
+	require.NoError(t, err)
+	require.Len(t, report.Issues, 1)
+	assert.Equal(t, "runtime:tool-versions", report.Issues[0].StepID)
+	assert.Equal(t, "preflight project apply", report.Issues[0].FixCommand)
+	assert.Equal(t, "project", report.Target)
+
+	plan, err := p.PlanProject(context.Background(), project)
+	require.NoError(t, err)
+	_, err = p.Apply(context.Background(), plan, false)
+	require.NoError(t, err)
+	data, err := os.ReadFile(".tool-versions")
+	require.NoError(t, err)
+	assert.Equal(t, "golang 1.23.0\n", string(data))
+
+	report, err = p.DoctorProject(context.Background(), project, NewDoctorOptions("", "project"))
+	require.NoError(t, err)
+	assert.Empty(t, report.Issues)
+}
@@ -0,0 +1,199 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+)
+
+// ProviderStatus describes a registered provider: the tool it drives,
+// whether that tool is installed, and whether the target uses the provider.
+type ProviderStatus struct {
+	Name      string `json:"name"`
+	Plugin    bool   `json:"plugin,omitempty"`
+	Tool      string `json:"tool,omitempty"`
+	Available bool   `json:"available"`
+	Version   string `json:"version,omitempty"`
+	Path      string `json:"path,omitempty"`
+	// Steps is the number of steps the provider compiles for the target;
+	// zero when the target does not configure it.
+	Steps int    `json:"steps"`
+	Error string `json:"error,omitempty"`
+	// LastApply and LastCapture are when an apply last changed something
+	// through the provider and when it was last captured from.
+	LastApply   *time.Time `json:"last_apply,omitempty"`
+	LastCapture *time.Time `json:"last_capture,omitempty"`
+	Hints       []string   `json:"hints,omitempty"`
+}
+
+// Configured reports whether the target uses the provider.
+func (s ProviderStatus) Configured() bool {
+	return s.Steps > 0
+}
+
+// providerTool is the command a provider drives and how to install it.
+type providerTool struct {
+	command     string
+	versionArgs []string
+	install     string
+}
+
+// providerTools are the commands behind the builtin providers. Providers
+// that only write files have none.
+var providerTools = map[string]providerTool{
+	"apt":        {"apt-get", []string{"--version"}, "apt is only available on Debian and Ubuntu"},
+	"aws":        {"aws", []string{"--version"}, "brew install awscli"},
+	"azure":      {"az", []string{"version", "--output", "tsv"}, "brew install azure-cli"},
+	"brew":       {"brew", []string{"--version"}, "see https://brew.sh"},
+	"cargo":      {"cargo", []string{"--version"}, "see https://rustup.rs"},
+	"chocolatey": {"choco", []string{"--version"}, "see https://chocolatey.org/install"},
+	"cursor":     {"cursor", []string{"--version"}, "in Cursor, run 'Shell Command: Install cursor command in PATH'"},
+	"docker":     {"docker", []string{"--version"}, "brew install --cask docker, or colima"},
+	"gcloud":     {"gcloud", []string{"--version"}, "brew install --cask google-cloud-sdk"},
+	"gem":        {"gem", []string{"--version"}, "install Ruby, e.g. brew install ruby"},
+	"git":        {"git", []string{"--version"}, "xcode-select --install, or brew install git"},
+	"github-cli": {"gh", []string{"--version"}, "brew install gh"},
+	"go":         {"go", []string{"version"}, "brew install go"},
+	"helix":      {"hx", []string{"--version"}, "brew install helix"},
+	"kubernetes": {"kubectl", []string{"version", "--client"}, "brew install kubectl"},
+	"macos":      {"defaults", nil, "only available on macOS"},
+	"mas":        {"mas", []string{"version"}, "brew install mas"},
+	"npm":        {"npm", []string{"--version"}, "install Node.js, e.g. brew install node"},
+	"nvim":       {"nvim", []string{"--version"}, "brew install neovim"},
+	"pip":        {"pip3", []string{"--version"}, "install Python, e.g. brew install python"},
+	"runtime":    {"mise", []string{"--version"}, "brew install mise"},
+	"scoop":      {"scoop", []string{"--version"}, "see https://scoop.sh"},
+	"ssh":        {"ssh", []string{"-V"}, "install OpenSSH"},
+	"starship":   {"starship", []string{"--version"}, "brew install starship"},
+	"sublime":    {"subl", []string{"--version"}, "brew install --cask sublime-text"},
+	"tmux":       {"tmux", []string{"-V"}, "brew install tmux"},
+	"vscode":     {"code", []string{"--version"}, "in VS Code, run 'Shell Command: Install code command in PATH'"},
+	"windsurf":   {"windsurf", []string{"--version"}, "brew install --cask windsurf"},
+	"winget":     {"winget", []string{"--version"}, "install App Installer from the Microsoft Store"},
+	"zed":        {"zed", []string{"--version"}, "brew install --cask zed"},
+}
+
+var providerVersionPattern = regexp.MustCompile(`\d+(\.\d+)+`)
+
+// providerVersionTimeout bounds each version command.
+const providerVersionTimeout = 10 * time.Second
+
+// ProviderStatuses reports every registered provider, builtin and plugin,
+// sorted by name. Each provider is compiled against the target to count
+// its steps, unless configPath does not exist, and the version of its tool
+// is looked up with run.
+func (p *Preflight) ProviderStatuses(ctx context.Context, configPath, target string, lookPath func(string) (string, error), run func(ctx context.Context, name string, args ...string) (string, error)) ([]ProviderStatus, error) {
+	compileCtx, err := p.providerCompileContext(ctx, configPath, target)
+	if err != nil {
+		return nil, err
+	}
+
+	var plugins []string
+	if p.plugins != nil {
+		plugins = p.plugins.registered
+	}
+
+	providers := p.compiler.Providers()
+	statuses := make([]ProviderStatus, len(providers))
+	var wg sync.WaitGroup
+	for i, provider := range providers {
+		statuses[i] = ProviderStatus{Name: provider.Name(), Plugin: slices.Contains(plugins, provider.Name())}
+		if compileCtx != nil {
+			steps, err := provider.Compile(*compileCtx)
+			if err != nil {
+				statuses[i].Error = err.Error()
+			}
+			statuses[i].Steps = len(steps)
+		}
+
+		wg.Add(1)
+		go func(status *ProviderStatus) {
+			defer wg.Done()
+			checkProviderTool(ctx, status, lookPath, run)
+		}(&statuses[i])
+	}
+	wg.Wait()
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses, nil
+}
+
+// providerCompileContext returns the context to compile providers for
+// target, or nil when there is no configuration yet.
+func (p *Preflight) providerCompileContext(ctx context.Context, configPath, target string) (*compiler.CompileContext, error) {
+	if _, err := os.Stat(configPath); errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	mode, err := p.resolveMode(configPath)
+	if err != nil {
+		return nil, err
+	}
+	resolver, err := p.buildResolver(ctx, configPath, mode)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := p.loadConfig(configPath, target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	compileCtx := compiler.NewCompileContext(cfg).
+		WithResolver(resolver).
+		WithConfigRoot(filepath.Dir(configPath)).
+		WithTarget(target)
+	return &compileCtx, nil
+}
+
+// checkProviderTool fills in the tool of status and the hints for it.
+func checkProviderTool(ctx context.Context, status *ProviderStatus, lookPath func(string) (string, error), run func(ctx context.Context, name string, args ...string) (string, error)) {
+	tool, ok := providerTools[status.Name]
+	switch {
+	case status.Plugin:
+		status.Available = true
+		status.Hints = append(status.Hints, "provided by a plugin; see 'preflight plugin list'")
+	case !ok:
+		status.Available = true
+	default:
+		status.Tool = tool.command
+		path, err := lookPath(tool.command)
+		if err != nil {
+			if status.Configured() {
+				status.Hints = append(status.Hints, fmt.Sprintf("%s is not installed; %s", tool.command, tool.install))
+			}
+			break
+		}
+		status.Available, status.Path = true, path
+		if tool.versionArgs != nil {
+			ctx, cancel := context.WithTimeout(ctx, providerVersionTimeout)
+			out, err := run(ctx, tool.command, tool.versionArgs...)
+			cancel()
+			if err == nil {
+				status.Version = providerVersion(out)
+			}
+		}
+	}
+
+	if status.Error != "" {
+		status.Hints = append(status.Hints, "fix the configuration: "+status.Error)
+	}
+}
+
+// providerVersion returns the version number in the output of a version
+// command, or its first line when there is none.
+func providerVersion(out string) string {
+	first, _, _ := strings.Cut(strings.TrimSpace(out), "\n")
+	if v := providerVersionPattern.FindString(first); v != "" {
+		return v
+	}
+	return strings.TrimSpace(first)
+}
@@ -0,0 +1,72 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckProviderTool(t *testing.T) {
+	t.Parallel()
+
+	lookPath := func(name string) (string, error) {
+		if name == "brew" {
+			return "/opt/homebrew/bin/brew", nil
+		}
+		return "", errors.New("not found")
+	}
+	run := func(_ context.Context, name string, args ...string) (string, error) {
+		assert.Equal(t, "brew", name)
+		assert.Equal(t, []string{"--version"}, args)
+		return "Homebrew 4.3.5\nHomebrew/homebrew-core (git revision 1a2b)\n", nil
+	}
+
+	brew := ProviderStatus{Name: "brew", Steps: 3}
+	checkProviderTool(context.Background(), &brew, lookPath, run)
+	assert.True(t, brew.Available)
+	assert.Equal(t, "brew", brew.Tool)
+	assert.Equal(t, "4.3.5", brew.Version)
+	assert.Equal(t, "/opt/homebrew/bin/brew", brew.Path)
+	assert.Empty(t, brew.Hints)
+
+	tmux := ProviderStatus{Name: "tmux", Steps: 1, Error: "invalid plugin name"}
+	checkProviderTool(context.Background(), &tmux, lookPath, run)
+	assert.False(t, tmux.Available)
+	assert.Equal(t, []string{
+		"tmux is not installed; brew install tmux",
+		"fix the configuration: invalid plugin name",
+	}, tmux.Hints)
+
+	unused := ProviderStatus{Name: "kubernetes"}
+	checkProviderTool(context.Background(), &unused, lookPath, run)
+	assert.False(t, unused.Available)
+	assert.Empty(t, unused.Hints, "missing tools of unused providers need no hint")
+
+	files := ProviderStatus{Name: "files", Steps: 2}
+	checkProviderTool(context.Background(), &files, lookPath, run)
+	assert.True(t, files.Available, "providers without a tool are builtin")
+	assert.Empty(t, files.Tool)
+
+	plugin := ProviderStatus{Name: "brew", Plugin: true}
+	checkProviderTool(context.Background(), &plugin, lookPath, run)
+	assert.True(t, plugin.Available)
+	assert.Empty(t, plugin.Tool)
+	assert.Len(t, plugin.Hints, 1)
+}
+
+func TestProviderVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"go version go1.22.3 darwin/arm64\n": "1.22.3",
+		"OpenSSH_9.6p1, LibreSSL 3.3.6\n":    "9.6",
+		"tmux 3.4\n":                         "3.4",
+		"10.2.4\n":                           "10.2.4",
+		"mas unknown\n":                      "mas unknown",
+	}
+	for out, want := range tests {
+		assert.Equal(t, want, providerVersion(out), out)
+	}
+}
@@ -0,0 +1,401 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+)
+
+// LayerUpdate adds packages installed outside preflight to one layer, the
+// reverse of apply. A layer that does not exist yet is created and added
+// to the target.
+type LayerUpdate struct {
+	Layer    string        `json:"layer"`
+	Path     string        `json:"path"`
+	Packages []string      `json:"packages"`
+	Patches  []ConfigPatch `json:"patches"`
+	// Creates is set when the layer file does not exist yet.
+	Creates bool `json:"creates,omitempty"`
+}
+
+// Description returns a one-line summary of the update.
+func (u LayerUpdate) Description() string {
+	if u.Creates {
+		return fmt.Sprintf("Track %d package(s) in new layer %s: %s", len(u.Packages), u.Layer, strings.Join(u.Packages, ", "))
+	}
+	return fmt.Sprintf("Track %d package(s) in layer %s: %s", len(u.Packages), u.Layer, strings.Join(u.Packages, ", "))
+}
+
+// Files returns the files the update changes, the layer first.
+func (u LayerUpdate) Files() []string {
+	var files []string
+	for _, patch := range u.Patches {
+		if !slices.Contains(files, patch.LayerPath) {
+			files = append(files, patch.LayerPath)
+		}
+	}
+	return files
+}
+
+// reverseSyncLists maps the capture providers whose packages reverse sync
+// tracks to the list they are declared in.
+var reverseSyncLists = []struct {
+	provider string
+	list     string
+}{
+	{"brew", "packages.brew.formulae"},
+	{"brew-cask", "packages.brew.casks"},
+	{"apt", "packages.apt.packages"},
+	{"npm", "packages.npm.packages"},
+	{"go", "packages.go.tools"},
+	{"pip", "packages.pip.packages"},
+	{"gem", "packages.gem.gems"},
+	{"cargo", "packages.cargo.crates"},
+	{"vscode", "vscode.extensions"},
+}
+
+// declaredList returns the packages layer declares in list.
+func declaredList(layer *config.Layer, list string) []string {
+	if list == "vscode.extensions" {
+		return layer.VSCode.Extensions
+	}
+	return layer.PackageList(list)
+}
+
+// packageKey returns the name under which list compares packages: without
+// a version or tap, and case-insensitive where the package manager is.
+func packageKey(list, name string) string {
+	switch list {
+	case "packages.brew.formulae", "packages.brew.casks":
+		// homebrew/core/wget and wget are the same formula; wget@2 is not
+		return name[strings.LastIndex(name, "/")+1:]
+	case "packages.npm.packages":
+		// Scoped packages start with @
+		if i := strings.LastIndex(name, "@"); i > 0 {
+			return name[:i]
+		}
+		return name
+	case "packages.pip.packages":
+		if i := strings.IndexAny(name, "=<>~![; "); i >= 0 {
+			name = name[:i]
+		}
+		return strings.ReplaceAll(strings.ToLower(name), "_", "-")
+	case "packages.apt.packages":
+		name, _, _ = strings.Cut(name, "=")
+		return name
+	case "vscode.extensions":
+		name, _, _ = strings.Cut(name, "@")
+		return strings.ToLower(name)
+	default:
+		name, _, _ = strings.Cut(name, "@")
+		return name
+	}
+}
+
+// untrackedItems returns the installed items no layer of the target
+// declares, for the lists that the target uses. Items of a list the target
+// does not use are left alone: the user does not manage that package
+// manager with preflight.
+func untrackedItems(layers []config.Layer, installed []CapturedItem) []CapturedItem {
+	used := make(map[string]bool)
+	declared := make(map[string]bool)
+	for i := range layers {
+		for _, l := range reverseSyncLists {
+			for _, name := range declaredList(&layers[i], l.list) {
+				used[l.list] = true
+				declared[l.list+"\x00"+packageKey(l.list, name)] = true
+			}
+		}
+	}
+
+	var untracked []CapturedItem
+	for _, item := range installed {
+		for _, l := range reverseSyncLists {
+			if l.provider != item.Provider || !used[l.list] {
+				continue
+			}
+			key := l.list + "\x00" + packageKey(l.list, reverseSyncName(item))
+			if !declared[key] {
+				declared[key] = true
+				untracked = append(untracked, item)
+			}
+		}
+	}
+	return untracked
+}
+
+// reverseSyncName returns the name item is declared under.
+func reverseSyncName(item CapturedItem) string {
+	if module, ok := item.Value.(string); ok && item.Provider == "go" {
+		// Go tools are installed by module path, not binary name
+		return module
+	}
+	return item.Name
+}
+
+// reverseSyncLayers returns the layer smart split puts the items in, by
+// item name. Brew packages are categorized by strategy; other providers
+// have a layer of their own.
+func reverseSyncLayers(items []CapturedItem, strategy SplitStrategy) map[string]string {
+	layers := make(map[string]string, len(items))
+	var brewItems []CapturedItem
+	for _, item := range items {
+		switch {
+		case strategy == SplitByProvider && (item.Provider == "brew" || item.Provider == "brew-cask"):
+			layers[item.Provider+"\x00"+item.Name] = "brew"
+		case strategy == SplitByProvider:
+			layers[item.Provider+"\x00"+item.Name] = item.Provider
+		case item.Provider == "brew" || item.Provider == "brew-cask":
+			brewItems = append(brewItems, item)
+		default:
+			layer, ok := providerLayers[item.Provider]
+			if !ok {
+				layer = item.Provider
+			}
+			layers[item.Provider+"\x00"+item.Name] = layer
+		}
+	}
+
+	categorized := StrategyCategorizer(strategy).Categorize(brewItems)
+	for _, layer := range categorized.LayerOrder {
+		for _, item := range categorized.Layers[layer] {
+			layers[item.Provider+"\x00"+item.Name] = layer
+		}
+	}
+	return layers
+}
+
+// planReverseSync returns the updates that add the untracked installed
+// packages to the layers smart split puts them in. A layer of the target
+// is patched; a layer file outside the target, or a new layer, is added to
+// the target as well.
+func planReverseSync(configPath, target string, layers []config.Layer, installed []CapturedItem, strategy SplitStrategy) []LayerUpdate {
+	layersDir := filepath.Join(filepath.Dir(configPath), "layers")
+	inTarget := make(map[string]*config.Layer, len(layers))
+	for i := range layers {
+		inTarget[layers[i].Name.String()] = &layers[i]
+	}
+
+	untracked := untrackedItems(layers, installed)
+	placement := reverseSyncLayers(untracked, strategy)
+
+	byLayer := make(map[string][]CapturedItem)
+	for _, item := range untracked {
+		layer := placement[item.Provider+"\x00"+item.Name]
+		if _, err := config.NewLayerName(layer); err != nil {
+			continue
+		}
+		byLayer[layer] = append(byLayer[layer], item)
+	}
+
+	names := make([]string, 0, len(byLayer))
+	for name := range byLayer {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	updates := make([]LayerUpdate, 0, len(names))
+	for _, name := range names {
+		update := LayerUpdate{Layer: name, Path: filepath.Join(layersDir, name+".yaml")}
+		existing, ok := inTarget[name]
+		if !ok {
+			// #nosec G304 -- the path is in the layers directory.
+			if data, err := os.ReadFile(update.Path); err == nil {
+				existing, _ = config.ParseLayer(data)
+			} else {
+				update.Creates = true
+				update.Patches = append(update.Patches, NewConfigPatch(update.Path, "name", PatchOpAdd, nil, name, "doctor"))
+			}
+		}
+
+		items := byLayer[name]
+		sort.Slice(items, func(i, j int) bool { return reverseSyncName(items[i]) < reverseSyncName(items[j]) })
+		hasExtensions := existing != nil && len(existing.VSCode.Extensions) > 0
+		for _, item := range items {
+			pkg := reverseSyncName(item)
+			for _, l := range reverseSyncLists {
+				if l.provider != item.Provider {
+					continue
+				}
+				var value interface{} = pkg
+				if l.list == "vscode.extensions" && !hasExtensions {
+					// The writer only creates missing package lists as lists
+					value = []string{pkg}
+					hasExtensions = true
+				}
+				update.Packages = append(update.Packages, pkg)
+				update.Patches = append(update.Patches, NewConfigPatch(update.Path, l.list, PatchOpAdd, nil, value, "doctor"))
+			}
+		}
+		if !ok {
+			update.Patches = append(update.Patches, NewConfigPatch(configPath, "targets."+target, PatchOpAdd, nil, name, "doctor"))
+		}
+		updates = append(updates, update)
+	}
+	return updates
+}
+
+// reverseSyncProviders are the capture providers reverse sync reads.
+var reverseSyncProviders = []string{"brew", "apt", "npm", "go", "pip", "gem", "cargo", "vscode"}
+
+// installedPackages captures the packages installed on the system. Brew
+// formulae and apt packages installed as dependencies are left out, so
+// only what the user asked for is tracked.
+func (p *Preflight) installedPackages(ctx context.Context, run func(string, ...string) (string, error)) []CapturedItem {
+	var items []CapturedItem
+	for _, provider := range reverseSyncProviders {
+		captured, err := p.captureProvider(ctx, provider, "", false)
+		if err != nil {
+			continue
+		}
+		switch provider {
+		case "brew":
+			captured = onlyRequested(captured, "brew", run, "brew", "leaves", "--installed-on-request")
+		case "apt":
+			captured = onlyRequested(captured, "apt", run, "apt-mark", "showmanual")
+		case "vscode":
+			captured = slices.DeleteFunc(captured, func(item CapturedItem) bool { return item.Source != "code --list-extensions" })
+		}
+		items = append(items, captured...)
+	}
+	return items
+}
+
+// onlyRequested drops the items of provider that the command, which lists
+// explicitly installed packages one per line, does not list. All items of
+// provider are dropped when the command fails.
+func onlyRequested(items []CapturedItem, provider string, run func(string, ...string) (string, error), name string, args ...string) []CapturedItem {
+	output, err := run(name, args...)
+	requested := make(map[string]bool)
+	if err == nil {
+		for _, pkg := range strings.Fields(output) {
+			requested[pkg] = true
+		}
+	}
+	return slices.DeleteFunc(items, func(item CapturedItem) bool {
+		return item.Provider == provider && !requested[item.Name]
+	})
+}
+
+// UntrackedPackages returns the updates that track the packages installed
+// on the system but declared by no layer of the target.
+func (p *Preflight) UntrackedPackages(ctx context.Context, configPath, target string, strategy SplitStrategy) ([]LayerUpdate, error) {
+	return p.reverseSync(ctx, configPath, target, strategy, commandOutput(ctx))
+}
+
+func (p *Preflight) reverseSync(ctx context.Context, configPath, targetName string, strategy SplitStrategy, run func(string, ...string) (string, error)) ([]LayerUpdate, error) {
+	configPath, layers, err := loadTargetLayers(configPath, targetName)
+	if err != nil {
+		return nil, err
+	}
+	return planReverseSync(configPath, targetName, layers, p.installedPackages(ctx, run), strategy), nil
+}
+
+// loadTargetLayers returns the absolute config path and the layers of the
+// target, for planning layer updates.
+func loadTargetLayers(configPath, targetName string) (string, []config.Layer, error) {
+	target, err := config.NewTargetName(targetName)
+	if err != nil {
+		return "", nil, err
+	}
+	// Pending patches may be applied later from another directory
+	if abs, err := filepath.Abs(configPath); err == nil {
+		configPath = abs
+	}
+	loader := config.NewLoader()
+	manifest, err := loader.LoadManifest(configPath)
+	if err != nil {
+		return "", nil, err
+	}
+	resolved, err := loader.LoadTarget(manifest, target, filepath.Join(filepath.Dir(configPath), "layers"))
+	if err != nil {
+		return "", nil, err
+	}
+	return configPath, resolved.Layers, nil
+}
+
+// PreviewLayerUpdate returns a unified diff of the files an update changes.
+func PreviewLayerUpdate(update LayerUpdate) (string, error) {
+	created := make(map[string][]byte)
+	if update.Creates {
+		created[update.Path] = nil
+	}
+	return previewPatches(created, ConfigPatchesToWriterPatches(update.Patches))
+}
+
+// ApplyLayerUpdate applies an update. When the configuration is in a git
+// repository and the changed files had no uncommitted changes, they are
+// committed on their own, so that history has one commit per layer.
+func ApplyLayerUpdate(ctx context.Context, update LayerUpdate) (committed bool, err error) {
+	files := update.Files()
+	dir := filepath.Dir(filepath.Dir(update.Path))
+	clean := gitFilesClean(ctx, dir, files)
+
+	if err := WriteLayerUpdate(update); err != nil {
+		return false, err
+	}
+	if !clean {
+		return false, nil
+	}
+
+	args := append([]string{"-C", dir, "add", "--"}, files...)
+	// #nosec G204 -- the files are the layer and manifest being updated.
+	if out, err := exec.CommandContext(ctx, "git", args...).CombinedOutput(); err != nil {
+		return false, fmt.Errorf("failed to stage %s: %s", update.Layer, strings.TrimSpace(string(out)))
+	}
+	message := fmt.Sprintf("Track %s in layer %s", strings.Join(update.Packages, ", "), update.Layer)
+	if len(update.Packages) > 3 {
+		message = fmt.Sprintf("Track %d packages in layer %s\n\n%s", len(update.Packages), update.Layer, strings.Join(update.Packages, "\n"))
+	}
+	args = append([]string{"-C", dir, "commit", "-m", message, "--"}, files...)
+	// #nosec G204 -- the files are the layer and manifest being updated.
+	if out, err := exec.CommandContext(ctx, "git", args...).CombinedOutput(); err != nil {
+		return false, fmt.Errorf("failed to commit %s: %s", update.Layer, strings.TrimSpace(string(out)))
+	}
+	return true, nil
+}
+
+// WriteLayerUpdate writes an update to the layer files without committing
+// it.
+func WriteLayerUpdate(update LayerUpdate) error {
+	if update.Creates {
+		if _, err := os.Stat(update.Path); os.IsNotExist(err) {
+			if err := os.MkdirAll(filepath.Dir(update.Path), 0o755); err != nil {
+				return fmt.Errorf("failed to create layers directory: %w", err)
+			}
+			if err := os.WriteFile(update.Path, nil, 0o644); err != nil {
+				return fmt.Errorf("failed to create layer %s: %w", update.Layer, err)
+			}
+		}
+	}
+	return ApplyConfigPatches(update.Patches)
+}
+
+// gitFilesClean reports whether dir is in a git repository and files have
+// no uncommitted changes. Files that do not exist yet count as clean.
+func gitFilesClean(ctx context.Context, dir string, files []string) bool {
+	// #nosec G204 -- dir is the configuration directory.
+	if err := exec.CommandContext(ctx, "git", "-C", dir, "rev-parse", "--is-inside-work-tree").Run(); err != nil {
+		return false
+	}
+	args := append([]string{"-C", dir, "status", "--porcelain", "--"}, files...)
+	// #nosec G204 -- the files are the layer and manifest being updated.
+	out, err := exec.CommandContext(ctx, "git", args...).Output()
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		// A new layer file may be left over from an earlier failed run
+		if line != "" && !strings.HasPrefix(line, "??") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,222 @@
+package app
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseTestLayer(t *testing.T, yaml string) config.Layer {
+	t.Helper()
+	layer, err := config.ParseLayer([]byte(yaml))
+	require.NoError(t, err)
+	return *layer
+}
+
+func TestPackageKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		list string
+		name string
+		want string
+	}{
+		{"packages.brew.formulae", "homebrew/core/wget", "wget"},
+		{"packages.brew.formulae", "node@20", "node@20"},
+		{"packages.npm.packages", "typescript@5", "typescript"},
+		{"packages.npm.packages", "@angular/cli", "@angular/cli"},
+		{"packages.npm.packages", "@angular/cli@17", "@angular/cli"},
+		{"packages.go.tools", "golang.org/x/tools/gopls@latest", "golang.org/x/tools/gopls"},
+		{"packages.pip.packages", "Black==23.1", "black"},
+		{"packages.pip.packages", "typing_extensions", "typing-extensions"},
+		{"packages.apt.packages", "curl=7.88", "curl"},
+		{"vscode.extensions", "Golang.Go@0.40.0", "golang.go"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, packageKey(tt.list, tt.name), "%s %s", tt.list, tt.name)
+	}
+}
+
+func TestUntrackedItems(t *testing.T) {
+	t.Parallel()
+
+	layers := []config.Layer{
+		parseTestLayer(t, "name: base\npackages:\n  brew:\n    formulae: [homebrew/core/git]\n"),
+		parseTestLayer(t, "name: dev\npackages:\n  go:\n    tools: [golang.org/x/tools/gopls@latest]\nvscode:\n  extensions: [Golang.Go]\n"),
+	}
+	installed := []CapturedItem{
+		{Provider: "brew", Name: "git"},
+		{Provider: "brew", Name: "jq"},
+		{Provider: "brew", Name: "jq"},
+		{Provider: "go", Name: "gopls", Value: "golang.org/x/tools/gopls"},
+		{Provider: "go", Name: "dlv", Value: "github.com/go-delve/delve/cmd/dlv"},
+		{Provider: "vscode", Name: "golang.go"},
+		{Provider: "npm", Name: "typescript"},
+	}
+
+	untracked := untrackedItems(layers, installed)
+	assert.Equal(t, []CapturedItem{
+		{Provider: "brew", Name: "jq"},
+		{Provider: "go", Name: "dlv", Value: "github.com/go-delve/delve/cmd/dlv"},
+	}, untracked, "npm is not used by the target")
+}
+
+func TestPlanReverseSync(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "preflight.yaml")
+	layersDir := filepath.Join(dir, "layers")
+	require.NoError(t, os.MkdirAll(layersDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(layersDir, "dev-go.yaml"), []byte("name: dev-go\n"), 0o644))
+
+	layers := []config.Layer{
+		parseTestLayer(t, "name: base\npackages:\n  brew:\n    formulae: [ripgrep]\n  go:\n    tools: [golang.org/x/tools/gopls]\nvscode:\n  extensions: [golang.go]\n"),
+		parseTestLayer(t, "name: misc\npackages:\n  brew:\n    formulae: [tree]\n"),
+	}
+	installed := []CapturedItem{
+		{Provider: "brew", Name: "ripgrep"},
+		{Provider: "brew", Name: "zz-unknown-tool"},
+		{Provider: "brew", Name: "golangci-lint"},
+		{Provider: "go", Name: "dlv", Value: "github.com/go-delve/delve/cmd/dlv"},
+		{Provider: "vscode", Name: "esbenp.prettier-vscode"},
+	}
+
+	updates := planReverseSync(configPath, "default", layers, installed, SplitByCategory)
+	require.Len(t, updates, 3)
+
+	// golangci-lint is categorized into dev-go, which exists outside the target
+	devGo := updates[0]
+	assert.Equal(t, "dev-go", devGo.Layer)
+	assert.False(t, devGo.Creates)
+	assert.Equal(t, []string{"github.com/go-delve/delve/cmd/dlv", "golangci-lint"}, devGo.Packages)
+	devGoPath := filepath.Join(layersDir, "dev-go.yaml")
+	assert.Equal(t, []ConfigPatch{
+		NewConfigPatch(devGoPath, "packages.go.tools", PatchOpAdd, nil, "github.com/go-delve/delve/cmd/dlv", "doctor"),
+		NewConfigPatch(devGoPath, "packages.brew.formulae", PatchOpAdd, nil, "golangci-lint", "doctor"),
+		NewConfigPatch(configPath, "targets.default", PatchOpAdd, nil, "dev-go", "doctor"),
+	}, devGo.Patches)
+	assert.Equal(t, []string{devGoPath, configPath}, devGo.Files())
+
+	// The editor layer does not exist and is created
+	editor := updates[1]
+	assert.Equal(t, "editor", editor.Layer)
+	assert.True(t, editor.Creates)
+	assert.Equal(t, "name", editor.Patches[0].YAMLPath)
+	assert.Equal(t, []string{"esbenp.prettier-vscode"}, editor.Patches[1].NewValue)
+	assert.Contains(t, editor.Description(), "new layer editor")
+
+	// Uncategorized brew packages go to misc, which the target has
+	misc := updates[2]
+	assert.Equal(t, "misc", misc.Layer)
+	assert.Equal(t, []ConfigPatch{
+		NewConfigPatch(filepath.Join(layersDir, "misc.yaml"), "packages.brew.formulae", PatchOpAdd, nil, "zz-unknown-tool", "doctor"),
+	}, misc.Patches)
+}
+
+func TestPlanReverseSync_ProviderStrategy(t *testing.T) {
+	t.Parallel()
+
+	layers := []config.Layer{parseTestLayer(t, "name: base\npackages:\n  brew:\n    formulae: [git]\n    casks: [firefox]\n")}
+	installed := []CapturedItem{
+		{Provider: "brew", Name: "golangci-lint"},
+		{Provider: "brew-cask", Name: "iterm2"},
+	}
+
+	updates := planReverseSync(filepath.Join(t.TempDir(), "preflight.yaml"), "default", layers, installed, SplitByProvider)
+	require.Len(t, updates, 1)
+	assert.Equal(t, "brew", updates[0].Layer)
+	assert.Equal(t, []string{"golangci-lint", "iterm2"}, updates[0].Packages)
+}
+
+func TestOnlyRequested(t *testing.T) {
+	t.Parallel()
+
+	items := []CapturedItem{
+		{Provider: "brew", Name: "jq"},
+		{Provider: "brew", Name: "oniguruma"},
+		{Provider: "brew-cask", Name: "iterm2"},
+	}
+	run := func(string, ...string) (string, error) { return "jq\n", nil }
+	assert.Equal(t, []CapturedItem{
+		{Provider: "brew", Name: "jq"},
+		{Provider: "brew-cask", Name: "iterm2"},
+	}, onlyRequested(items, "brew", run, "brew", "leaves", "--installed-on-request"))
+
+	failing := func(string, ...string) (string, error) { return "", os.ErrNotExist }
+	assert.Equal(t, []CapturedItem{{Provider: "brew-cask", Name: "iterm2"}},
+		onlyRequested([]CapturedItem{{Provider: "brew", Name: "jq"}, {Provider: "brew-cask", Name: "iterm2"}}, "brew", failing, "brew", "leaves"))
+}
+
+func TestApplyLayerUpdate_CommitsPerLayer(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "preflight.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "layers"), 0o755))
+	require.NoError(t, os.WriteFile(configPath, []byte("targets:\n  default:\n    - base\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "layers", "base.yaml"), []byte("name: base\npackages:\n  brew:\n    formulae: [git]\n"), 0o644))
+	gitTrack(t, dir, "preflight.yaml", "layers/base.yaml")
+	for _, args := range [][]string{
+		{"config", "user.name", "Test"},
+		{"config", "user.email", "test@example.com"},
+		{"commit", "-q", "-m", "Initial"},
+	} {
+		require.NoError(t, exec.Command("git", append([]string{"-C", dir}, args...)...).Run())
+	}
+
+	layers := []config.Layer{parseTestLayer(t, "name: base\npackages:\n  brew:\n    formulae: [git]\n")}
+	updates := planReverseSync(configPath, "default", layers, []CapturedItem{{Provider: "brew", Name: "zz-unknown-tool"}}, SplitByCategory)
+	require.Len(t, updates, 1)
+
+	diff, err := PreviewLayerUpdate(updates[0])
+	require.NoError(t, err)
+	assert.Contains(t, diff, "+name: misc\n")
+	assert.Contains(t, diff, "+    - misc\n")
+
+	committed, err := ApplyLayerUpdate(context.Background(), updates[0])
+	require.NoError(t, err)
+	assert.True(t, committed)
+
+	data, err := os.ReadFile(filepath.Join(dir, "layers", "misc.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "name: misc\npackages:\n  brew:\n    formulae:\n      - zz-unknown-tool\n", string(data))
+
+	out, err := exec.Command("git", "-C", dir, "log", "-1", "--name-only", "--format=%s").Output()
+	require.NoError(t, err)
+	assert.Equal(t, "Track zz-unknown-tool in layer misc\n\nlayers/misc.yaml\npreflight.yaml", strings.TrimSpace(string(out)))
+}
+
+func TestApplyLayerUpdate_DirtyFilesAreNotCommitted(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "preflight.yaml")
+	base := filepath.Join(dir, "layers", "base.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(base), 0o755))
+	require.NoError(t, os.WriteFile(configPath, []byte("targets:\n  default:\n    - base\n"), 0o644))
+	require.NoError(t, os.WriteFile(base, []byte("name: base\n"), 0o644))
+	gitTrack(t, dir, "preflight.yaml", "layers/base.yaml")
+
+	update := LayerUpdate{
+		Layer:    "base",
+		Path:     base,
+		Packages: []string{"jq"},
+		Patches:  []ConfigPatch{NewConfigPatch(base, "packages.brew.formulae", PatchOpAdd, nil, "jq", "doctor")},
+	}
+	require.NoError(t, os.WriteFile(base, []byte("name: base\n# edited\n"), 0o644))
+
+	committed, err := ApplyLayerUpdate(context.Background(), update)
+	require.NoError(t, err)
+	assert.False(t, committed)
+	data, err := os.ReadFile(base)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "- jq")
+}
@@ -2,9 +2,10 @@ package app
 
 import (
 	"context"
-	"os"
 	"path/filepath"
+	"time"
 
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
 	"github.com/felixgeelhaar/preflight/internal/domain/snapshot"
 )
 
@@ -28,11 +29,10 @@ func NewSnapshotService(baseDir string) *SnapshotService {
 
 // DefaultSnapshotService creates a SnapshotService using the default preflight directory.
 func DefaultSnapshotService() (*SnapshotService, error) {
-	home, err := os.UserHomeDir()
+	baseDir, err := platform.Dir(platform.StateDir)
 	if err != nil {
 		return nil, err
 	}
-	baseDir := filepath.Join(home, ".preflight")
 	return NewSnapshotService(baseDir), nil
 }
 
@@ -55,3 +55,25 @@ func (s *SnapshotService) GetSnapshotSet(ctx context.Context, id string) (*snaps
 func (s *SnapshotService) ListSnapshotSets(ctx context.Context) ([]snapshot.Set, error) {
 	return s.manager.ListSets(ctx)
 }
+
+// Usage reports the disk space of every snapshot set, newest first.
+func (s *SnapshotService) Usage(ctx context.Context) ([]snapshot.SetUsage, error) {
+	return s.manager.Usage(ctx)
+}
+
+// GC deletes snapshot sets older than olderThan, when positive, and the
+// snapshots and content nothing refers to.
+func (s *SnapshotService) GC(ctx context.Context, olderThan time.Duration, dryRun bool) (snapshot.GCResult, error) {
+	return s.manager.GC(ctx, olderThan, dryRun)
+}
+
+// Create creates a snapshot set of the existing files among paths.
+func (s *SnapshotService) Create(ctx context.Context, reason snapshot.Reason, paths []string) (*snapshot.Set, error) {
+	return s.manager.Create(ctx, reason, paths)
+}
+
+// Prune deletes the snapshot sets created for reason that are older than
+// olderThan.
+func (s *SnapshotService) Prune(ctx context.Context, reason snapshot.Reason, olderThan time.Duration) (snapshot.GCResult, error) {
+	return s.manager.Prune(ctx, reason, olderThan)
+}
@@ -22,16 +22,17 @@ func TestNewSnapshotService(t *testing.T) {
 }
 
 func TestDefaultSnapshotService(t *testing.T) {
-	t.Parallel()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_STATE_HOME", "")
 
 	service, err := DefaultSnapshotService()
 
 	require.NoError(t, err)
 	assert.NotNil(t, service)
 
-	// Should use ~/.preflight as base
-	home, _ := os.UserHomeDir()
-	expectedBase := filepath.Join(home, ".preflight")
+	// Should use the XDG state directory as base
+	expectedBase := filepath.Join(home, ".local", "state", "preflight")
 	assert.Equal(t, expectedBase, service.baseDir)
 }
 
@@ -0,0 +1,83 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/domain/sync"
+)
+
+// LoadSyncConfig reads the sync section of the manifest at configPath.
+func LoadSyncConfig(configPath string) (*config.SyncConfig, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	cfg, err := config.ParseSyncConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sync config: %w", err)
+	}
+	return cfg, nil
+}
+
+// SyncExclusionLeaks returns content excluded by sync.exclude that is
+// tracked by git in the config directory and would therefore be shared by a
+// full-repo push. It returns nil when nothing is excluded or the directory
+// is not a git repository.
+func SyncExclusionLeaks(ctx context.Context, configPath string) ([]sync.Exclusion, error) {
+	cfg, err := LoadSyncConfig(configPath)
+	if err != nil || cfg.IsZero() {
+		return nil, err
+	}
+
+	dir := filepath.Dir(configPath)
+	cmd := exec.CommandContext(ctx, "git", "-C", dir, "ls-files", "-z")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, nil
+	}
+	tracked := make(map[string]bool)
+	for _, name := range strings.Split(string(output), "\x00") {
+		if name != "" {
+			tracked[name] = true
+		}
+	}
+
+	bundle, err := sync.CollectBundle(dir, sync.BundleManifest{})
+	if err != nil {
+		return nil, err
+	}
+	for name := range bundle.Files {
+		if !tracked[name] {
+			delete(bundle.Files, name)
+		}
+	}
+	return bundle.Exclude(cfg)
+}
+
+// checkSyncExclusions warns about excluded content that a git push would leak.
+func checkSyncExclusions(ctx context.Context, configPath string, report *DoctorReport) {
+	leaks, err := SyncExclusionLeaks(ctx, configPath)
+	if err != nil {
+		return
+	}
+	for _, leak := range leaks {
+		expected := "removed from the tracked file or sync.exclude"
+		if leak.Section == "" {
+			expected = fmt.Sprintf("untracked (git rm --cached %s, then add it to .gitignore)", leak.Path)
+		}
+		report.Issues = append(report.Issues, DoctorIssue{
+			Provider: "sync",
+			StepID:   "sync:exclude:" + leak.String(),
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("%s is excluded from sync but tracked by git; a full-repo push would share it", leak),
+			Expected: expected,
+			Actual:   "tracked by git",
+		})
+	}
+}
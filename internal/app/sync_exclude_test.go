@@ -0,0 +1,77 @@
+package app
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeExcludeConfig(t *testing.T, dir string) string {
+	t.Helper()
+	configPath := filepath.Join(dir, "preflight.yaml")
+	manifest := "targets:\n  default: [base, personal]\nsync:\n  exclude:\n    layers: [personal]\n    providers: [ssh]\n"
+	require.NoError(t, os.WriteFile(configPath, []byte(manifest), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "layers"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "layers", "base.yaml"),
+		[]byte("name: base\nssh:\n  hosts:\n    - host: home\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "layers", "personal.yaml"), []byte("name: personal\n"), 0o644))
+	return configPath
+}
+
+func gitTrack(t *testing.T, dir string, files ...string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	require.NoError(t, exec.Command("git", "init", "-q", dir).Run())
+	require.NoError(t, exec.Command("git", append([]string{"-C", dir, "add"}, files...)...).Run())
+}
+
+func TestSyncExclusionLeaks(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	configPath := writeExcludeConfig(t, dir)
+	gitTrack(t, dir, "preflight.yaml", "layers/personal.yaml")
+
+	leaks, err := SyncExclusionLeaks(context.Background(), configPath)
+	require.NoError(t, err)
+	assert.Equal(t, []sync.Exclusion{
+		{Path: "layers/personal.yaml"},
+		{Path: "preflight.yaml", Section: "targets.default[personal]"},
+	}, leaks, "untracked layers/base.yaml is not reported")
+
+	report := &DoctorReport{}
+	checkSyncExclusions(context.Background(), configPath, report)
+	require.Len(t, report.Issues, 2)
+	assert.Equal(t, "sync", report.Issues[0].Provider)
+	assert.Equal(t, SeverityWarning, report.Issues[0].Severity)
+	assert.Contains(t, report.Issues[0].Expected, "git rm --cached layers/personal.yaml")
+}
+
+func TestSyncExclusionLeaks_NotARepo(t *testing.T) {
+	t.Parallel()
+
+	configPath := writeExcludeConfig(t, t.TempDir())
+	leaks, err := SyncExclusionLeaks(context.Background(), configPath)
+	require.NoError(t, err)
+	assert.Empty(t, leaks)
+}
+
+func TestSyncExclusionLeaks_NoExclusions(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "preflight.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("targets:\n  default: [base]\n"), 0o644))
+
+	leaks, err := SyncExclusionLeaks(context.Background(), configPath)
+	require.NoError(t, err)
+	assert.Nil(t, leaks)
+}
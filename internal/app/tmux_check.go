@@ -0,0 +1,113 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/provider/tmux"
+)
+
+// checkTmux verifies that tmux is installed, is recent enough for the
+// declared plugins and config location, and that a running server has
+// loaded the declared settings.
+func checkTmux(configPath, targetName, tmuxConf string, run func(string, ...string) (string, error), report *DoctorReport) {
+	target, err := config.NewTargetName(targetName)
+	if err != nil {
+		return
+	}
+	merged, err := config.NewLoader().Load(configPath, target)
+	if err != nil || (len(merged.Tmux.Plugins) == 0 && len(merged.Tmux.Settings) == 0) {
+		return
+	}
+
+	out, err := run("tmux", "-V")
+	if err != nil {
+		report.Issues = append(report.Issues, DoctorIssue{
+			Provider:   "tmux",
+			StepID:     "tmux:binary",
+			Severity:   SeverityWarning,
+			Message:    "tmux is not installed; the tmux configuration has no effect",
+			Expected:   "tmux installed",
+			Actual:     "not found",
+			FixCommand: "brew install tmux",
+		})
+		return
+	}
+
+	if major, minor, ok := tmux.ParseVersion(out); ok {
+		version := fmt.Sprintf("%d.%d", major, minor)
+		if len(merged.Tmux.Plugins) > 0 && !tmuxAtLeast(major, minor, 1, 9) {
+			report.Issues = append(report.Issues, DoctorIssue{
+				Provider: "tmux",
+				StepID:   "tmux:version",
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("tmux %s is too old for TPM plugins", version),
+				Expected: ">= 1.9",
+				Actual:   version,
+			})
+		}
+		if isXDGTmuxConf(tmuxConf) && !tmuxAtLeast(major, minor, 3, 1) {
+			report.Issues = append(report.Issues, DoctorIssue{
+				Provider:   "tmux",
+				StepID:     "tmux:config",
+				Severity:   SeverityWarning,
+				Message:    fmt.Sprintf("tmux %s does not read %s", version, tmuxConf),
+				Expected:   ">= 3.1 or ~/.tmux.conf",
+				Actual:     version,
+				FixCommand: fmt.Sprintf("ln -s %s ~/.tmux.conf", tmuxConf),
+			})
+		}
+	}
+
+	// Without a running server there is nothing loaded to compare against
+	if _, err := run("tmux", "show-options", "-g"); err != nil {
+		return
+	}
+	keys := make([]string, 0, len(merged.Tmux.Settings))
+	for key := range merged.Tmux.Settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var stale []string
+	for _, key := range keys {
+		value, err := run("tmux", "show-options", "-gv", key)
+		if err != nil {
+			value, err = run("tmux", "show-window-options", "-gv", key)
+		}
+		if err != nil {
+			continue // unknown to this tmux version
+		}
+		if unquoteTmux(value) != unquoteTmux(merged.Tmux.Settings[key]) {
+			stale = append(stale, key)
+		}
+	}
+	if len(stale) > 0 {
+		report.Issues = append(report.Issues, DoctorIssue{
+			Provider:   "tmux",
+			StepID:     "tmux:config",
+			Severity:   SeverityWarning,
+			Message:    "the running tmux server has not loaded the current configuration",
+			Expected:   "declared settings active",
+			Actual:     "differs: " + strings.Join(stale, ", "),
+			FixCommand: "tmux source-file " + tmuxConf,
+		})
+	}
+}
+
+func tmuxAtLeast(major, minor, wantMajor, wantMinor int) bool {
+	return major > wantMajor || (major == wantMajor && minor >= wantMinor)
+}
+
+// isXDGTmuxConf reports whether path is the XDG tmux config location,
+// which tmux only reads since 3.1.
+func isXDGTmuxConf(path string) bool {
+	return filepath.Base(filepath.Dir(path)) == "tmux" && filepath.Base(path) == "tmux.conf"
+}
+
+func unquoteTmux(s string) string {
+	return strings.Trim(strings.TrimSpace(s), `'"`)
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTmuxConfig(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "preflight.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("targets:\n  default: [base]\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "layers"), 0o755))
+	layer := "name: base\ntmux:\n  plugins:\n    - tmux-plugins/tmux-sensible\n  settings:\n    mouse: \"on\"\n    mode-keys: vi\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "layers", "base.yaml"), []byte(layer), 0o644))
+	return configPath
+}
+
+// fakeTmux answers tmux commands from a table keyed by the joined arguments.
+func fakeTmux(outputs map[string]string) func(string, ...string) (string, error) {
+	return func(_ string, args ...string) (string, error) {
+		out, ok := outputs[strings.Join(args, " ")]
+		if !ok {
+			return "", errors.New("exit status 1")
+		}
+		return out, nil
+	}
+}
+
+func TestCheckTmux_NotInstalled(t *testing.T) {
+	t.Parallel()
+
+	report := &DoctorReport{}
+	checkTmux(writeTmuxConfig(t), "default", "/home/u/.tmux.conf", fakeTmux(nil), report)
+
+	require.Len(t, report.Issues, 1)
+	assert.Equal(t, "tmux:binary", report.Issues[0].StepID)
+	assert.Equal(t, "brew install tmux", report.Issues[0].FixCommand)
+}
+
+func TestCheckTmux_OldVersion(t *testing.T) {
+	t.Parallel()
+
+	report := &DoctorReport{}
+	checkTmux(writeTmuxConfig(t), "default", "/home/u/.config/tmux/tmux.conf", fakeTmux(map[string]string{
+		"-V": "tmux 1.8\n",
+	}), report)
+
+	require.Len(t, report.Issues, 2)
+	assert.Equal(t, "tmux:version", report.Issues[0].StepID)
+	assert.Contains(t, report.Issues[1].Message, "does not read /home/u/.config/tmux/tmux.conf")
+	assert.Equal(t, "ln -s /home/u/.config/tmux/tmux.conf ~/.tmux.conf", report.Issues[1].FixCommand)
+}
+
+func TestCheckTmux_StaleServer(t *testing.T) {
+	t.Parallel()
+
+	outputs := map[string]string{
+		"-V":                                "tmux 3.4\n",
+		"show-options -g":                   "mouse off\n",
+		"show-options -gv mouse":            "off\n",
+		"show-window-options -gv mode-keys": "vi\n",
+	}
+	report := &DoctorReport{}
+	checkTmux(writeTmuxConfig(t), "default", "/home/u/.tmux.conf", fakeTmux(outputs), report)
+
+	require.Len(t, report.Issues, 1)
+	assert.Equal(t, "differs: mouse", report.Issues[0].Actual)
+	assert.Equal(t, "tmux source-file /home/u/.tmux.conf", report.Issues[0].FixCommand)
+
+	outputs["show-options -gv mouse"] = "on\n"
+	report = &DoctorReport{}
+	checkTmux(writeTmuxConfig(t), "default", "/home/u/.tmux.conf", fakeTmux(outputs), report)
+	assert.Empty(t, report.Issues)
+}
@@ -0,0 +1,472 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/domain/execution"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/provider/pathutil"
+)
+
+const (
+	// maxTranscriptOutput bounds the stdout and stderr kept per step. The
+	// tail is kept since failures are usually reported last.
+	maxTranscriptOutput = 16 * 1024
+	// maxDiffFileSize bounds the size of files whose content is diffed.
+	maxDiffFileSize = 256 * 1024
+	// maxDiffCells bounds the line comparison table of a file diff.
+	maxDiffCells = 4_000_000
+	// diffContext is the number of unchanged lines around each hunk.
+	diffContext = 3
+)
+
+// Transcript records what an apply did beyond step status: the commands
+// each step ran with their output, diffs of the files it changed, and
+// package version transitions. Snapshot and Uninstalls record how to undo
+// it: the snapshot set holding the managed files as they were before, and
+// the commands that remove the packages it installed. Backups are the
+// copies of files preflight did not manage yet that it changed.
+type Transcript struct {
+	Outputs    []StepOutput        `json:"outputs,omitempty"`
+	Files      []FileDiff          `json:"files,omitempty"`
+	Versions   []VersionTransition `json:"versions,omitempty"`
+	Snapshot   string              `json:"snapshot,omitempty"`
+	Uninstalls []Uninstall         `json:"uninstalls,omitempty"`
+	Backups    []FileBackup        `json:"backups,omitempty"`
+}
+
+// Output returns the output recorded for stepID.
+func (t *Transcript) Output(stepID string) (StepOutput, bool) {
+	if t == nil {
+		return StepOutput{}, false
+	}
+	for _, output := range t.Outputs {
+		if output.StepID == stepID {
+			return output, true
+		}
+	}
+	return StepOutput{}, false
+}
+
+// StepOutput is the output of the commands a step ran. Stdout and Stderr
+// keep the last 16 KiB each; Truncated reports that earlier output was
+// dropped.
+type StepOutput struct {
+	StepID    string   `json:"step"`
+	Commands  []string `json:"commands,omitempty"`
+	Stdout    string   `json:"stdout,omitempty"`
+	Stderr    string   `json:"stderr,omitempty"`
+	Truncated bool     `json:"truncated,omitempty"`
+}
+
+// FileDiff is a unified diff of a managed file a step changed. Created
+// reports that the file did not exist before.
+type FileDiff struct {
+	StepID  string `json:"step"`
+	Path    string `json:"path"`
+	Diff    string `json:"diff"`
+	Created bool   `json:"created,omitempty"`
+}
+
+// VersionTransition is a package version a step changed. From is empty
+// when the package was not installed before.
+type VersionTransition struct {
+	StepID   string `json:"step"`
+	Provider string `json:"provider"`
+	Name     string `json:"name"`
+	From     string `json:"from,omitempty"`
+	To       string `json:"to,omitempty"`
+}
+
+// Uninstall is the command that removes a package a step installed.
+type Uninstall struct {
+	StepID  string   `json:"step"`
+	Command []string `json:"command"`
+}
+
+// transcriptRunner records the output of the commands steps run during
+// apply, attributing it by the step IDs in the command's context. The
+// output of a batched command is recorded for every step of the batch.
+type transcriptRunner struct {
+	next    ports.CommandRunner
+	mu      sync.Mutex
+	outputs map[string]*StepOutput
+	order   []string
+}
+
+func newTranscriptRunner(next ports.CommandRunner) *transcriptRunner {
+	return &transcriptRunner{next: next}
+}
+
+// Run runs the command and records its output while recording.
+func (r *transcriptRunner) Run(ctx context.Context, command string, args ...string) (ports.CommandResult, error) {
+	result, err := r.next.Run(ctx, command, args...)
+	for _, id := range execution.StepIDsFromContext(ctx) {
+		r.record(id.String(), strings.Join(append([]string{command}, args...), " "), result)
+	}
+	return result, err
+}
+
+func (r *transcriptRunner) record(stepID, commandLine string, result ports.CommandResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.outputs == nil {
+		return
+	}
+	output, ok := r.outputs[stepID]
+	if !ok {
+		output = &StepOutput{StepID: stepID}
+		r.outputs[stepID] = output
+		r.order = append(r.order, stepID)
+	}
+	output.Commands = append(output.Commands, commandLine)
+	var truncated bool
+	output.Stdout, truncated = appendTail(output.Stdout, result.Stdout)
+	output.Truncated = output.Truncated || truncated
+	output.Stderr, truncated = appendTail(output.Stderr, result.Stderr)
+	output.Truncated = output.Truncated || truncated
+}
+
+// start begins recording, discarding earlier output.
+func (r *transcriptRunner) start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.outputs = make(map[string]*StepOutput)
+	r.order = nil
+}
+
+// stop ends recording and returns the output per step in the order the
+// steps first ran a command.
+func (r *transcriptRunner) stop() []StepOutput {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	outputs := make([]StepOutput, 0, len(r.order))
+	for _, id := range r.order {
+		outputs = append(outputs, *r.outputs[id])
+	}
+	r.outputs = nil
+	r.order = nil
+	return outputs
+}
+
+// appendTail appends add to s, keeping the last maxTranscriptOutput bytes.
+func appendTail(s, add string) (string, bool) {
+	s += add
+	if len(s) <= maxTranscriptOutput {
+		return s, false
+	}
+	s = s[len(s)-maxTranscriptOutput:]
+	// Do not start in the middle of a UTF-8 sequence.
+	for len(s) > 0 && !utf8.RuneStart(s[0]) {
+		s = s[1:]
+	}
+	return s, true
+}
+
+// transcriptCapture holds the state of files and packages before apply.
+type transcriptCapture struct {
+	files    map[string]fileState
+	versions map[string]VersionTransition
+}
+
+// fileState is the content of a managed file; diffable is false for
+// missing, binary or large files.
+type fileState struct {
+	path     string
+	exists   bool
+	diffable bool
+	content  string
+}
+
+// captureBefore records the managed files and package versions of the
+// steps that plan changes.
+func captureBefore(ctx context.Context, plan *execution.Plan) *transcriptCapture {
+	capture := &transcriptCapture{
+		files:    make(map[string]fileState),
+		versions: make(map[string]VersionTransition),
+	}
+	runCtx := compiler.NewRunContext(ctx)
+	for _, entry := range plan.Entries() {
+		if entry.Status() != compiler.StatusNeedsApply {
+			continue
+		}
+		id := entry.Step().ID().String()
+		if path, ok := managedFilePath(entry.Diff()); ok {
+			capture.files[id] = readFileState(path)
+		}
+		if info, version, ok := installedVersion(runCtx, entry.Step()); ok {
+			capture.versions[id] = VersionTransition{StepID: id, Provider: info.Provider, Name: info.Name, From: version}
+		}
+	}
+	return capture
+}
+
+// existingFiles returns the captured files that exist, sorted.
+func (c *transcriptCapture) existingFiles() []string {
+	paths := make([]string, 0, len(c.files))
+	for _, state := range c.files {
+		if state.exists {
+			paths = append(paths, state.path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// finish compares the captured state with the state after apply for the
+// steps that applied, and records the uninstall commands of the packages
+// they installed.
+func (c *transcriptCapture) finish(ctx context.Context, plan *execution.Plan, results []execution.StepResult) *Transcript {
+	applied := make(map[string]bool, len(results))
+	for _, result := range results {
+		if result.Applied() {
+			applied[result.StepID().String()] = true
+		}
+	}
+	transcript := &Transcript{}
+	runCtx := compiler.NewRunContext(ctx)
+	for _, entry := range plan.Entries() {
+		id := entry.Step().ID().String()
+		if !applied[id] {
+			continue
+		}
+		if before, ok := c.files[id]; ok {
+			after := readFileState(before.path)
+			if diff, changed := diffFileStates(before, after); changed {
+				transcript.Files = append(transcript.Files, FileDiff{
+					StepID: id, Path: before.path, Diff: diff, Created: !before.exists && after.exists,
+				})
+			}
+		}
+		transition, versioned := c.versions[id]
+		if versioned {
+			if _, version, ok := installedVersion(runCtx, entry.Step()); ok {
+				transition.To = version
+			}
+			if transition.From != transition.To {
+				transcript.Versions = append(transcript.Versions, transition)
+			}
+		}
+		// A package counts as installed by this apply when its step planned
+		// an addition and, if its version is known, it was not installed.
+		uninstallable, ok := entry.Step().(compiler.UninstallableStep)
+		if ok && entry.Diff().Type() == compiler.DiffTypeAdd && (!versioned || transition.From == "") {
+			transcript.Uninstalls = append(transcript.Uninstalls, Uninstall{StepID: id, Command: uninstallable.UninstallCommand()})
+		}
+	}
+	return transcript
+}
+
+// managedFilePath returns the file a step's diff names, if it is a path.
+func managedFilePath(diff compiler.Diff) (string, bool) {
+	name := diff.Name()
+	if !strings.HasPrefix(name, "/") && !strings.HasPrefix(name, "~/") {
+		return "", false
+	}
+	return pathutil.ExpandPath(name), true
+}
+
+func readFileState(path string) fileState {
+	state := fileState{path: path}
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return state
+	}
+	state.exists = true
+	if info.Size() > maxDiffFileSize {
+		return state
+	}
+	// #nosec G304 -- path is a file managed by the applied configuration.
+	data, err := os.ReadFile(path)
+	if err != nil || bytes.IndexByte(data, 0) >= 0 || !utf8.Valid(data) {
+		return state
+	}
+	state.diffable = true
+	state.content = string(data)
+	return state
+}
+
+// diffFileStates returns a unified diff between two states of a file and
+// whether it changed.
+func diffFileStates(before, after fileState) (string, bool) {
+	switch {
+	case !before.exists && !after.exists:
+		return "", false
+	case before.diffable && after.diffable:
+		if before.content == after.content {
+			return "", false
+		}
+		return unifiedDiff(before.path, before.content, after.content), true
+	case !before.exists && after.diffable:
+		return unifiedDiff(before.path, "", after.content), true
+	case before.diffable && !after.exists:
+		return unifiedDiff(before.path, before.content, ""), true
+	default:
+		return "(binary or larger than 256 KiB; content not diffed)", true
+	}
+}
+
+// installedVersion returns the package of a lockable, versioned step and
+// its installed version, which is empty when it is not installed.
+func installedVersion(ctx compiler.RunContext, step compiler.Step) (compiler.LockInfo, string, bool) {
+	lockable, ok := step.(compiler.LockableStep)
+	if !ok {
+		return compiler.LockInfo{}, "", false
+	}
+	versioned, ok := step.(compiler.VersionedStep)
+	if !ok {
+		return compiler.LockInfo{}, "", false
+	}
+	info, ok := lockable.LockInfo()
+	if !ok {
+		return compiler.LockInfo{}, "", false
+	}
+	version, installed, err := versioned.InstalledVersion(ctx)
+	if err != nil {
+		return compiler.LockInfo{}, "", false
+	}
+	if !installed {
+		return info, "", true
+	}
+	return info, strings.TrimSpace(version), true
+}
+
+// diffOp is one line of a line diff: ' ' kept, '-' removed, '+' added.
+type diffOp struct {
+	kind byte
+	text string
+}
+
+// unifiedDiff returns a unified diff of two file contents.
+func unifiedDiff(path, before, after string) string {
+	ops := diffLines(splitLines(before), splitLines(after))
+
+	// oldAt and newAt count the lines consumed before each op.
+	oldAt := make([]int, len(ops)+1)
+	newAt := make([]int, len(ops)+1)
+	for i, op := range ops {
+		oldAt[i+1], newAt[i+1] = oldAt[i], newAt[i]
+		if op.kind != '+' {
+			oldAt[i+1]++
+		}
+		if op.kind != '-' {
+			newAt[i+1]++
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", path, path)
+	for i := 0; i < len(ops); {
+		for i < len(ops) && ops[i].kind == ' ' {
+			i++
+		}
+		if i == len(ops) {
+			break
+		}
+		start := max(0, i-diffContext)
+		// Extend the hunk over changes separated by little context.
+		end := i
+		for j := i; j < len(ops); {
+			if ops[j].kind != ' ' {
+				end = j
+				j++
+				continue
+			}
+			k := j
+			for k < len(ops) && ops[k].kind == ' ' {
+				k++
+			}
+			if k == len(ops) || k-j > 2*diffContext {
+				break
+			}
+			j = k
+		}
+		stop := min(len(ops), end+1+diffContext)
+
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n",
+			hunkRange(oldAt[start], oldAt[stop]-oldAt[start]),
+			hunkRange(newAt[start], newAt[stop]-newAt[start]))
+		for _, op := range ops[start:stop] {
+			b.WriteByte(op.kind)
+			b.WriteString(op.text)
+			b.WriteByte('\n')
+		}
+		i = stop
+	}
+	return b.String()
+}
+
+func hunkRange(offset, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", offset)
+	}
+	return fmt.Sprintf("%d,%d", offset+1, count)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines computes a line diff using the longest common subsequence.
+// Inputs too large to compare are reported as replaced whole.
+func diffLines(a, b []string) []diffOp {
+	var ops []diffOp
+	if len(a)*len(b) > maxDiffCells {
+		for _, line := range a {
+			ops = append(ops, diffOp{'-', line})
+		}
+		for _, line := range b {
+			ops = append(ops, diffOp{'+', line})
+		}
+		return ops
+	}
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return ops
+}
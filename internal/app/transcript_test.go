@@ -0,0 +1,276 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/domain/execution"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/provider/brew"
+	"github.com/felixgeelhaar/preflight/internal/testutil/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// transcriptStep writes a file, runs a command and reports a version.
+type transcriptStep struct {
+	id      compiler.StepID
+	path    string
+	runner  ports.CommandRunner
+	version string
+}
+
+func (s *transcriptStep) ID() compiler.StepID { return s.id }
+func (s *transcriptStep) DependsOn() []compiler.StepID {
+	return nil
+}
+func (s *transcriptStep) Check(_ compiler.RunContext) (compiler.StepStatus, error) {
+	return compiler.StatusNeedsApply, nil
+}
+func (s *transcriptStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
+	return compiler.NewDiff(compiler.DiffTypeModify, "file", s.path, "", ""), nil
+}
+func (s *transcriptStep) Apply(ctx compiler.RunContext) error {
+	if _, err := s.runner.Run(ctx.Context(), "tool", "install"); err != nil {
+		return err
+	}
+	s.version = "2.0.0"
+	return os.WriteFile(s.path, []byte("a\nB\nc\n"), 0o644)
+}
+func (s *transcriptStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
+	return compiler.Explanation{}
+}
+func (s *transcriptStep) LockInfo() (compiler.LockInfo, bool) {
+	return compiler.LockInfo{Provider: "brew", Name: "tool"}, true
+}
+func (s *transcriptStep) InstalledVersion(_ compiler.RunContext) (string, bool, error) {
+	return s.version, s.version != "", nil
+}
+
+func TestPreflight_Apply_RecordsTranscript(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(path, []byte("a\nb\nc\n"), 0o644))
+
+	mock := mocks.NewCommandRunner()
+	mock.AddResult("tool", []string{"install"}, ports.CommandResult{Stdout: "installed\n", Stderr: "warning\n"})
+
+	pf := newTranscriptPreflight(t, mock)
+	step := &transcriptStep{id: compiler.MustNewStepID("brew:formula:tool"), path: path, runner: pf.transcripts, version: "1.0.0"}
+
+	plan := execution.NewExecutionPlan()
+	diff, err := step.Plan(compiler.NewRunContext(context.Background()))
+	require.NoError(t, err)
+	plan.Add(execution.NewPlanEntry(step, compiler.StatusNeedsApply, diff))
+
+	_, err = pf.Apply(context.Background(), plan, false)
+	require.NoError(t, err)
+
+	transcript := pf.LastTranscript()
+	require.NotNil(t, transcript)
+
+	output, ok := transcript.Output("brew:formula:tool")
+	require.True(t, ok)
+	assert.Equal(t, []string{"tool install"}, output.Commands)
+	assert.Equal(t, "installed\n", output.Stdout)
+	assert.Equal(t, "warning\n", output.Stderr)
+
+	require.Len(t, transcript.Files, 1)
+	assert.Equal(t, path, transcript.Files[0].Path)
+	assert.Contains(t, transcript.Files[0].Diff, "-b\n+B\n")
+
+	assert.Equal(t, []VersionTransition{{
+		StepID: "brew:formula:tool", Provider: "brew", Name: "tool", From: "1.0.0", To: "2.0.0",
+	}}, transcript.Versions)
+
+	// The file was snapshotted before apply; the upgrade is not undone by
+	// uninstalling.
+	require.NotEmpty(t, transcript.Snapshot)
+	set, err := pf.lifecycle.Snapshot().GetSnapshotSet(context.Background(), transcript.Snapshot)
+	require.NoError(t, err)
+	assert.Equal(t, []string{path}, set.Paths())
+	assert.Empty(t, transcript.Uninstalls)
+}
+
+// installStep plans the installation of a package that was not installed.
+type installStep struct {
+	*transcriptStep
+}
+
+func (s *installStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
+	return compiler.NewDiff(compiler.DiffTypeAdd, "file", s.path, "", ""), nil
+}
+
+func (s *installStep) UninstallCommand() []string {
+	return []string{"tool", "uninstall"}
+}
+
+func TestPreflight_Apply_RecordsUndoInformation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+
+	mock := mocks.NewCommandRunner()
+	mock.AddResult("tool", []string{"install"}, ports.CommandResult{})
+
+	pf := newTranscriptPreflight(t, mock)
+	step := &installStep{&transcriptStep{id: compiler.MustNewStepID("brew:formula:tool"), path: path, runner: pf.transcripts}}
+
+	plan := execution.NewExecutionPlan()
+	diff, err := step.Plan(compiler.NewRunContext(context.Background()))
+	require.NoError(t, err)
+	plan.Add(execution.NewPlanEntry(step, compiler.StatusNeedsApply, diff))
+
+	_, err = pf.Apply(context.Background(), plan, false)
+	require.NoError(t, err)
+
+	transcript := pf.LastTranscript()
+	require.NotNil(t, transcript)
+	assert.Empty(t, transcript.Snapshot, "nothing existed to snapshot")
+	require.Len(t, transcript.Files, 1)
+	assert.True(t, transcript.Files[0].Created)
+	assert.Equal(t, []Uninstall{{StepID: "brew:formula:tool", Command: []string{"tool", "uninstall"}}}, transcript.Uninstalls)
+}
+
+func TestPreflight_Apply_RecordsBatchedOutputForEachStep(t *testing.T) {
+	mock := mocks.NewCommandRunner()
+	mock.AddResult("brew", []string{"install", "git", "jq"}, ports.CommandResult{Stdout: "==> Pouring git\n==> Pouring jq\n"})
+
+	pf := newTranscriptPreflight(t, mock)
+	plan := execution.NewExecutionPlan()
+	for _, name := range []string{"git", "jq"} {
+		step := brew.NewFormulaStep(brew.Formula{Name: name}, pf.transcripts)
+		plan.Add(execution.NewPlanEntry(step, compiler.StatusNeedsApply, compiler.Diff{}))
+	}
+
+	results, err := pf.Apply(context.Background(), plan, false)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	transcript := pf.LastTranscript()
+	require.NotNil(t, transcript)
+	for _, id := range []string{"brew:formula:git", "brew:formula:jq"} {
+		output, ok := transcript.Output(id)
+		require.True(t, ok, id)
+		assert.Contains(t, output.Commands, "brew install git jq")
+		assert.Contains(t, output.Stdout, "Pouring jq")
+	}
+}
+
+// newTranscriptPreflight returns a Preflight recording transcripts of the
+// commands run through mock, with its run lock and snapshots in temporary
+// directories.
+func newTranscriptPreflight(t *testing.T, mock ports.CommandRunner) *Preflight {
+	t.Helper()
+	dir := t.TempDir()
+	pf := New(&bytes.Buffer{}).WithRunLockPath(filepath.Join(dir, "run.lock"))
+	pf.transcripts = newTranscriptRunner(mock)
+	pf.lifecycle = NewLifecycleManager(NewSnapshotService(dir), NewDriftService(dir))
+	return pf
+}
+
+func TestTranscriptRunner_OnlyRecordsStepsWhileStarted(t *testing.T) {
+	t.Parallel()
+
+	mock := mocks.NewCommandRunner()
+	mock.AddResult("echo", []string{"hi"}, ports.CommandResult{Stdout: "hi\n"})
+	runner := newTranscriptRunner(mock)
+	stepCtx := contextWithStep(t, "brew:formula:git")
+
+	_, err := runner.Run(stepCtx, "echo", "hi")
+	require.NoError(t, err)
+
+	runner.start()
+	_, err = runner.Run(context.Background(), "echo", "hi")
+	require.NoError(t, err)
+	_, err = runner.Run(stepCtx, "echo", "hi")
+	require.NoError(t, err)
+	outputs := runner.stop()
+
+	require.Len(t, outputs, 1)
+	assert.Equal(t, "brew:formula:git", outputs[0].StepID)
+	assert.Equal(t, "hi\n", outputs[0].Stdout)
+}
+
+// contextWithStep returns the context an executor passes to Apply.
+func contextWithStep(t *testing.T, id string) context.Context {
+	t.Helper()
+	var ctx context.Context
+	step := newDummyStep(id)
+	plan := execution.NewExecutionPlan()
+	plan.Add(execution.NewPlanEntry(&ctxCapturingStep{dummyStep: step, ctx: &ctx}, compiler.StatusNeedsApply, compiler.Diff{}))
+	_, err := execution.NewExecutor().Execute(context.Background(), plan)
+	require.NoError(t, err)
+	return ctx
+}
+
+type ctxCapturingStep struct {
+	*dummyStep
+	ctx *context.Context
+}
+
+func (s *ctxCapturingStep) Apply(ctx compiler.RunContext) error {
+	*s.ctx = ctx.Context()
+	return nil
+}
+
+func TestAppendTail_KeepsTail(t *testing.T) {
+	t.Parallel()
+
+	got, truncated := appendTail(strings.Repeat("a", maxTranscriptOutput), "bc")
+	assert.True(t, truncated)
+	assert.Len(t, got, maxTranscriptOutput)
+	assert.True(t, strings.HasSuffix(got, "abc"))
+
+	got, truncated = appendTail("a", "b")
+	assert.False(t, truncated)
+	assert.Equal(t, "ab", got)
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	t.Parallel()
+
+	before := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	after := "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n"
+
+	want := `--- f
++++ f
+@@ -1,6 +1,6 @@
+ 1
+ 2
+-3
++three
+ 4
+ 5
+ 6
+@@ -10,3 +10,4 @@
+ 10
+ 11
+ 12
++13
+`
+	assert.Equal(t, want, unifiedDiff("f", before, after))
+}
+
+func TestUnifiedDiff_NewFile(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "--- f\n+++ f\n@@ -0,0 +1,2 @@\n+a\n+b\n", unifiedDiff("f", "", "a\nb\n"))
+}
+
+func TestDiffFileStates(t *testing.T) {
+	t.Parallel()
+
+	_, changed := diffFileStates(fileState{}, fileState{})
+	assert.False(t, changed)
+
+	same := fileState{path: "f", exists: true, diffable: true, content: "x\n"}
+	_, changed = diffFileStates(same, same)
+	assert.False(t, changed)
+
+	diff, changed := diffFileStates(fileState{path: "f", exists: true}, same)
+	assert.True(t, changed)
+	assert.Contains(t, diff, "not diffed")
+}
@@ -60,6 +60,13 @@ type CapturedItem struct {
 	Source     string // e.g., "~/.gitconfig", "brew list"
 	Redacted   bool
 	CapturedAt time.Time
+
+	// Description is a one-line summary of a package, when known.
+	Description string
+
+	// Arch is the architecture a Homebrew formula is installed for, set
+	// when it lives only in the Intel prefix of a Mac with both prefixes.
+	Arch string
 }
 
 // CaptureFindings holds the results of a capture operation.
@@ -93,8 +100,10 @@ type DoctorOptions struct {
 	Target string
 	// Verbose enables detailed output
 	Verbose bool
-	// UpdateConfig merges drift back into layer files
+	// UpdateConfig adds packages installed outside preflight to layer files
 	UpdateConfig bool
+	// SplitStrategy picks the layer UpdateConfig adds a package to
+	SplitStrategy SplitStrategy
 	// DryRun shows changes without writing
 	DryRun bool
 
@@ -131,8 +140,20 @@ type DoctorOptions struct {
 	SecurityOnly bool
 	// OutdatedOnly runs only outdated checks
 	OutdatedOnly bool
+
+	// Concurrency
+	// Parallelism is how many providers are checked at once
+	Parallelism int
+	// CheckTimeout bounds how long a provider's checks may take; zero means no limit
+	CheckTimeout time.Duration
 }
 
+// Doctor concurrency defaults.
+const (
+	DefaultDoctorParallelism  = 8
+	DefaultDoctorCheckTimeout = 30 * time.Second
+)
+
 // NewDoctorOptions creates default doctor options.
 func NewDoctorOptions(configPath, target string) DoctorOptions {
 	return DoctorOptions{
@@ -140,6 +161,7 @@ func NewDoctorOptions(configPath, target string) DoctorOptions {
 		Target:            target,
 		Verbose:           false,
 		UpdateConfig:      false,
+		SplitStrategy:     SplitByCategory,
 		DryRun:            false,
 		SecurityEnabled:   true,
 		SecurityScanner:   "auto",
@@ -149,6 +171,8 @@ func NewDoctorOptions(configPath, target string) DoctorOptions {
 		OutdatedMaxAge:    90 * 24 * time.Hour, // 90 days
 		DeprecatedEnabled: true,
 		DeprecatedEOLWarn: 365 * 24 * time.Hour, // 1 year
+		Parallelism:       DefaultDoctorParallelism,
+		CheckTimeout:      DefaultDoctorCheckTimeout,
 	}
 }
 
@@ -164,6 +188,12 @@ func (o DoctorOptions) WithUpdateConfig(updateConfig bool) DoctorOptions {
 	return o
 }
 
+// WithSplitStrategy sets how config update mode picks layers.
+func (o DoctorOptions) WithSplitStrategy(strategy SplitStrategy) DoctorOptions {
+	o.SplitStrategy = strategy
+	return o
+}
+
 // WithDryRun enables dry run mode.
 func (o DoctorOptions) WithDryRun(dryRun bool) DoctorOptions {
 	o.DryRun = dryRun
@@ -218,6 +248,18 @@ func (o DoctorOptions) WithDeprecated(enabled bool) DoctorOptions {
 	return o
 }
 
+// WithParallelism sets how many providers are checked at once.
+func (o DoctorOptions) WithParallelism(n int) DoctorOptions {
+	o.Parallelism = n
+	return o
+}
+
+// WithCheckTimeout sets how long a provider's checks may take.
+func (o DoctorOptions) WithCheckTimeout(timeout time.Duration) DoctorOptions {
+	o.CheckTimeout = timeout
+	return o
+}
+
 // WithQuick enables quick mode, skipping slow checks.
 func (o DoctorOptions) WithQuick(quick bool) DoctorOptions {
 	o.Quick = quick
@@ -248,6 +290,9 @@ type DoctorIssue struct {
 	Actual     string
 	Fixable    bool
 	FixCommand string
+	// RunFix marks issues that Fix resolves by running FixCommand rather
+	// than by applying the configuration.
+	RunFix bool
 }
 
 // BinaryCheckResult holds the result of checking a required binary.
@@ -274,12 +319,12 @@ const (
 
 // ConfigPatch represents a change to be made to a layer file.
 type ConfigPatch struct {
-	LayerPath  string
-	YAMLPath   string
-	Operation  PatchOp
-	OldValue   interface{}
-	NewValue   interface{}
-	Provenance string
+	LayerPath  string      `json:"layer_path"`
+	YAMLPath   string      `json:"yaml_path"`
+	Operation  PatchOp     `json:"operation"`
+	OldValue   interface{} `json:"old_value,omitempty"`
+	NewValue   interface{} `json:"new_value,omitempty"`
+	Provenance string      `json:"provenance,omitempty"`
 }
 
 // NewConfigPatch creates a new ConfigPatch.
@@ -315,8 +360,12 @@ type DoctorReport struct {
 	Issues           []DoctorIssue
 	BinaryChecks     []BinaryCheckResult
 	SuggestedPatches []ConfigPatch
-	CheckedAt        time.Time
-	Duration         time.Duration
+	// LayerUpdates groups SuggestedPatches by the layer they track packages in.
+	LayerUpdates []LayerUpdate
+	CheckedAt    time.Time
+	Duration     time.Duration
+	// TimedOut lists the providers and checks abandoned after the check timeout.
+	TimedOut []string
 
 	// Security results
 	SecurityScanResult *security.ScanResult
@@ -0,0 +1,48 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+)
+
+// validateDuplicates reports packages that several layers of the target
+// declare. They are installed once, but unless the manifest picks a
+// defaults.duplicates strategy, validate warns about them.
+func (p *Preflight) validateDuplicates(configPath, targetName string, result *ValidationResult) {
+	loader := config.NewLoader()
+	manifest, err := loader.LoadManifest(configPath)
+	if err != nil {
+		return
+	}
+	name, err := config.NewTargetName(targetName)
+	if err != nil {
+		return
+	}
+	target, err := loader.LoadTarget(manifest, name, filepath.Join(filepath.Dir(configPath), "layers"))
+	if err != nil {
+		return
+	}
+
+	duplicates := config.FindDuplicatePackages(target.Layers)
+	if len(duplicates) == 0 {
+		return
+	}
+
+	strategy := manifest.Defaults.Duplicates
+	if strategy != "" && strategy != config.DuplicatesWarn {
+		result.Info = append(result.Info, fmt.Sprintf("Deduplicated %d package(s) declared by several layers (%s)", len(duplicates), strategy))
+		return
+	}
+	for _, d := range duplicates {
+		result.Warnings = append(result.Warnings, duplicateWarning(d))
+	}
+	result.Info = append(result.Info, "Remove the duplicates ('preflight fmt --write' removes those no target needs), or set defaults.duplicates to keep-first or keep-most-specific to accept them")
+}
+
+// duplicateWarning describes a package several layers declare.
+func duplicateWarning(d config.DuplicatePackage) string {
+	return fmt.Sprintf("%s: %s is declared by layers %s", d.Path, d.Name, strings.Join(d.Layers, ", "))
+}
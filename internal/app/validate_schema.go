@@ -0,0 +1,118 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+)
+
+// validateSchemas checks the manifest and the layer files of the target
+// against their JSON Schemas, adding errors and warnings that point at a
+// file, line and column. It reports whether any error was found. Files that
+// are missing or not valid YAML are left to loading, which explains those.
+func (p *Preflight) validateSchemas(configPath, targetName string, result *ValidationResult) bool {
+	failed := checkSchema(configPath, config.ManifestSchema(), result)
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return failed
+	}
+	manifest, err := config.ParseManifest(data)
+	if err != nil {
+		return failed
+	}
+	layers, ok := manifest.Targets[targetName]
+	if !ok {
+		return failed
+	}
+	layersDir := filepath.Join(filepath.Dir(configPath), "layers")
+	layerSchema := config.LayerSchema()
+	for _, name := range layers {
+		if checkSchema(filepath.Join(layersDir, name.String()+".yaml"), layerSchema, result) {
+			failed = true
+		}
+	}
+	return failed
+}
+
+// checkSchema validates the file at path against schema and reports whether
+// it has errors.
+func checkSchema(path string, schema *config.Schema, result *ValidationResult) bool {
+	// #nosec G304 -- validating the user's own config files.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	issues, err := config.ValidateSchema(data, schema)
+	if err != nil {
+		return false
+	}
+
+	failed := false
+	for _, issue := range issues {
+		msg := fmt.Sprintf("%s:%s", path, issue)
+		if issue.Warning {
+			result.Warnings = append(result.Warnings, msg)
+			continue
+		}
+		result.Errors = append(result.Errors, msg)
+		failed = true
+	}
+	return failed
+}
+
+// ConfigDeprecations returns the deprecated keys that the manifest at
+// configPath and the layers of all its targets use, as
+// "path:line:column: key: message".
+func ConfigDeprecations(configPath string) []string {
+	notices := deprecatedKeys(configPath, config.ManifestSchema())
+
+	// #nosec G304 -- reading the user's own config file.
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return notices
+	}
+	manifest, err := config.ParseManifest(data)
+	if err != nil {
+		return notices
+	}
+	var names []string
+	for _, layers := range manifest.Targets {
+		for _, name := range layers {
+			if !slices.Contains(names, name.String()) {
+				names = append(names, name.String())
+			}
+		}
+	}
+	slices.Sort(names)
+
+	layersDir := filepath.Join(filepath.Dir(configPath), "layers")
+	layerSchema := config.LayerSchema()
+	for _, name := range names {
+		notices = append(notices, deprecatedKeys(filepath.Join(layersDir, name+".yaml"), layerSchema)...)
+	}
+	return notices
+}
+
+// deprecatedKeys returns the deprecated keys that the file at path uses.
+func deprecatedKeys(path string, schema *config.Schema) []string {
+	// #nosec G304 -- reading the user's own config files.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	issues, err := config.ValidateSchema(data, schema)
+	if err != nil {
+		return nil
+	}
+	var notices []string
+	for _, issue := range issues {
+		if issue.Deprecated {
+			notices = append(notices, fmt.Sprintf("%s:%s", path, issue))
+		}
+	}
+	return notices
+}
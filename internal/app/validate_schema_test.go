@@ -0,0 +1,80 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreflight_ValidateWithOptions_SchemaErrors(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "preflight.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("targets:\n  default:\n    - base\n"), 0o644))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "layers"), 0o755))
+	layerPath := filepath.Join(dir, "layers", "base.yaml")
+	require.NoError(t, os.WriteFile(layerPath, []byte("name: base\nssh:\n  hosts:\n    - host: dev\n      port: twenty-two\n      hostnme: dev.example.com\n"), 0o644))
+
+	result, err := New(&bytes.Buffer{}).ValidateWithOptions(context.Background(), configPath, "default", ValidateOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{layerPath + `:5:13: ssh.hosts[0].port: expected an integer, found "twenty-two"`}, result.Errors)
+	require.Len(t, result.Warnings, 1)
+	assert.Contains(t, result.Warnings[0], layerPath+`:6:7: ssh.hosts[0].hostnme: unknown key "hostnme" is ignored`)
+}
+
+func TestPreflight_ValidateWithOptions_DuplicatePackages(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		manifest string
+		warnings []string
+	}{
+		{
+			manifest: "targets:\n  default: [base, work]\n",
+			warnings: []string{"packages.brew.formulae: git is declared by layers base, work"},
+		},
+		{manifest: "defaults:\n  duplicates: keep-first\ntargets:\n  default: [base, work]\n"},
+	} {
+		dir := t.TempDir()
+		configPath := filepath.Join(dir, "preflight.yaml")
+		require.NoError(t, os.WriteFile(configPath, []byte(tt.manifest), 0o644))
+		require.NoError(t, os.Mkdir(filepath.Join(dir, "layers"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "layers", "base.yaml"), []byte("name: base\npackages:\n  brew:\n    formulae: [git]\n"), 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "layers", "work.yaml"), []byte("name: work\npackages:\n  brew:\n    formulae: [git, jq]\n"), 0o644))
+
+		result, err := New(&bytes.Buffer{}).ValidateWithOptions(context.Background(), configPath, "default", ValidateOptions{})
+		require.NoError(t, err)
+		var duplicates []string
+		for _, w := range result.Warnings {
+			if strings.Contains(w, "is declared by layers") {
+				duplicates = append(duplicates, w)
+			}
+		}
+		assert.Equal(t, tt.warnings, duplicates, tt.manifest)
+	}
+}
+
+func TestConfigDeprecations(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "preflight.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("targets:\n  default: [base, editor]\n  work: [editor]\n"), 0o644))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "layers"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "layers", "base.yaml"), []byte("name: base\n"), 0o644))
+	editorPath := filepath.Join(dir, "layers", "editor.yaml")
+	require.NoError(t, os.WriteFile(editorPath, []byte("name: editor\nnvim:\n  ensure_install: true\n"), 0o644))
+
+	deprecations := ConfigDeprecations(configPath)
+	require.Len(t, deprecations, 1, "layers shared by targets are reported once")
+	assert.True(t, strings.HasPrefix(deprecations[0], editorPath+`:3:3: nvim.ensure_install: "ensure_install" is deprecated`), deprecations[0])
+
+	assert.Empty(t, ConfigDeprecations(filepath.Join(dir, "missing.yaml")))
+}
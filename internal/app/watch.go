@@ -15,6 +15,7 @@ type WatchMode struct {
 	configDir    string
 	debounce     time.Duration
 	applyFn      func(ctx context.Context) error
+	reloadFn     func(ctx context.Context) []PluginReload
 	stopCh       chan struct{}
 	mu           sync.Mutex
 	lastApply    time.Time
@@ -28,6 +29,9 @@ type WatchOptions struct {
 	ApplyOnStart bool
 	DryRun       bool
 	Verbose      bool
+	// ReloadPlugins, if set, is polled alongside the config files and
+	// reloads installed plugins that changed. A reload triggers an apply.
+	ReloadPlugins func(ctx context.Context) []PluginReload
 }
 
 // NewWatchMode creates a new file watch service.
@@ -41,6 +45,7 @@ func NewWatchMode(opts WatchOptions, applyFn func(ctx context.Context) error) *W
 		configDir: opts.ConfigDir,
 		debounce:  debounce,
 		applyFn:   applyFn,
+		reloadFn:  opts.ReloadPlugins,
 		stopCh:    make(chan struct{}),
 	}
 }
@@ -80,14 +85,34 @@ func (w *WatchMode) watch(ctx context.Context) error {
 		case <-w.stopCh:
 			return nil
 		case <-ticker.C:
+			reloaded := w.reloadPlugins(ctx)
 			changed := w.checkForChanges(lastMod)
 			if len(changed) > 0 {
 				w.handleChanges(ctx, changed)
+			} else if reloaded {
+				_ = w.triggerApply(ctx) //nolint:errcheck // Error logged internally
 			}
 		}
 	}
 }
 
+// reloadPlugins reloads changed plugins and reports whether any were reloaded.
+func (w *WatchMode) reloadPlugins(ctx context.Context) bool {
+	if w.reloadFn == nil {
+		return false
+	}
+	reloads := w.reloadFn(ctx)
+	if len(reloads) == 0 {
+		return false
+	}
+
+	fmt.Printf("\n🔌 Plugins reloaded:\n")
+	for _, r := range reloads {
+		fmt.Printf("   - %s\n", r)
+	}
+	return true
+}
+
 // updateFileTimes scans config files and records their modification times.
 func (w *WatchMode) updateFileTimes(times map[string]time.Time) {
 	patterns := []string{
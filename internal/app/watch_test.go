@@ -103,6 +103,20 @@ func TestWatchMode_checkForChanges(t *testing.T) {
 	assert.Empty(t, changed)
 }
 
+func TestWatchMode_reloadPlugins(t *testing.T) {
+	w := &WatchMode{stopCh: make(chan struct{})}
+	assert.False(t, w.reloadPlugins(context.Background()))
+
+	var reloads []PluginReload
+	w = NewWatchMode(WatchOptions{
+		ReloadPlugins: func(_ context.Context) []PluginReload { return reloads },
+	}, func(_ context.Context) error { return nil })
+	assert.False(t, w.reloadPlugins(context.Background()))
+
+	reloads = []PluginReload{{Plugin: "docker", Version: "1.1.0", Change: "updated"}}
+	assert.True(t, w.reloadPlugins(context.Background()))
+}
+
 func TestNewWatchMode_defaults(t *testing.T) {
 	w := NewWatchMode(WatchOptions{}, func(_ context.Context) error { return nil })
 	assert.Equal(t, 500*time.Millisecond, w.debounce)
@@ -0,0 +1,128 @@
+package advisor
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ConfigEditSystemPrompt is the system prompt for natural-language config edits.
+const ConfigEditSystemPrompt = `You are a Preflight configuration assistant that edits workstation setup layers.
+
+The user describes a change in plain language. Translate it into edits of the
+package lists of existing layers. Only use the layers and list paths you are
+given, use the package names of the package manager (e.g. Homebrew formula
+names), and prefer the layer the user names.
+
+Respond only with JSON in the following format:
+{
+  "summary": "One-line summary of the change",
+  "edits": [
+    {
+      "layer": "existing layer name",
+      "path": "packages.brew.formulae",
+      "operation": "add|remove",
+      "value": "package name",
+      "reason": "Brief reason (optional)"
+    }
+  ]
+}
+
+If the request cannot be expressed as package list edits, return no edits and
+explain why in the summary.`
+
+// EditableLayer is a layer as shown to the AI for a config edit: its name
+// and package lists, keyed by path.
+type EditableLayer struct {
+	Name  string              `json:"name"`
+	Lists map[string][]string `json:"lists"`
+}
+
+// ConfigEditRequest contains the data for a natural-language config edit.
+type ConfigEditRequest struct {
+	Request   string              `json:"request"`
+	Layers    []EditableLayer     `json:"layers"`
+	Targets   map[string][]string `json:"targets,omitempty"`
+	ListPaths []string            `json:"list_paths"`
+}
+
+// ConfigEdit is a single edit proposed by the AI.
+type ConfigEdit struct {
+	Layer     string `json:"layer"`
+	Path      string `json:"path"`
+	Operation string `json:"operation"`
+	Value     string `json:"value"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// ConfigEditResponse is the AI's proposal for a config edit.
+type ConfigEditResponse struct {
+	Summary string       `json:"summary"`
+	Edits   []ConfigEdit `json:"edits"`
+}
+
+// BuildConfigEditPrompt creates a prompt that turns a request into package
+// list edits. Only layer names, targets and package lists are included, so
+// settings such as environment variables never leave the machine.
+func BuildConfigEditPrompt(req ConfigEditRequest) Prompt {
+	var parts []string
+
+	parts = append(parts, fmt.Sprintf("Request: %s", req.Request))
+	parts = append(parts, "")
+
+	if len(req.Targets) > 0 {
+		parts = append(parts, "## Targets")
+		names := make([]string, 0, len(req.Targets))
+		for name := range req.Targets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			parts = append(parts, fmt.Sprintf("- %s: %s", name, strings.Join(req.Targets[name], ", ")))
+		}
+		parts = append(parts, "")
+	}
+
+	parts = append(parts, "## Layers")
+	for _, layer := range req.Layers {
+		parts = append(parts, fmt.Sprintf("### %s", layer.Name))
+		paths := make([]string, 0, len(layer.Lists))
+		for path := range layer.Lists {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			parts = append(parts, fmt.Sprintf("- %s: %s", path, strings.Join(layer.Lists[path], ", ")))
+		}
+		if len(paths) == 0 {
+			parts = append(parts, "- (no packages)")
+		}
+	}
+	parts = append(parts, "")
+
+	parts = append(parts, "## List Paths")
+	parts = append(parts, strings.Join(req.ListPaths, ", "))
+
+	return NewPrompt(ConfigEditSystemPrompt, strings.Join(parts, "\n")).
+		WithMaxTokens(1024).
+		WithTemperature(0.2)
+}
+
+// ParseConfigEditResponse parses an AI response into a config edit proposal.
+func ParseConfigEditResponse(response string) (*ConfigEditResponse, error) {
+	if len(response) > MaxJSONResponseSize {
+		return nil, fmt.Errorf("response too large: %d bytes (max %d)", len(response), MaxJSONResponseSize)
+	}
+
+	jsonStr, err := extractJSON(response)
+	if err != nil {
+		return nil, err
+	}
+
+	var result ConfigEditResponse
+	if err := json.Unmarshal([]byte(jsonStr), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse config edit JSON: %w", err)
+	}
+	return &result, nil
+}
@@ -0,0 +1,49 @@
+package advisor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildConfigEditPrompt(t *testing.T) {
+	t.Parallel()
+
+	prompt := BuildConfigEditPrompt(ConfigEditRequest{
+		Request: "add lazygit to dev",
+		Layers: []EditableLayer{
+			{Name: "base", Lists: map[string][]string{"packages.brew.formulae": {"git"}}},
+			{Name: "dev", Lists: map[string][]string{}},
+		},
+		Targets:   map[string][]string{"work": {"base", "dev"}},
+		ListPaths: []string{"packages.brew.formulae", "packages.brew.casks"},
+	})
+
+	assert.Equal(t, ConfigEditSystemPrompt, prompt.SystemPrompt())
+	assert.Equal(t, `Request: add lazygit to dev
+
+## Targets
+- work: base, dev
+
+## Layers
+### base
+- packages.brew.formulae: git
+### dev
+- (no packages)
+
+## List Paths
+packages.brew.formulae, packages.brew.casks`, prompt.UserPrompt())
+}
+
+func TestParseConfigEditResponse(t *testing.T) {
+	t.Parallel()
+
+	result, err := ParseConfigEditResponse("Here you go:\n```json\n" + `{"summary": "Add lazygit", "edits": [{"layer": "dev", "path": "packages.brew.formulae", "operation": "add", "value": "lazygit"}]}` + "\n```")
+	require.NoError(t, err)
+	assert.Equal(t, "Add lazygit", result.Summary)
+	assert.Equal(t, []ConfigEdit{{Layer: "dev", Path: "packages.brew.formulae", Operation: "add", Value: "lazygit"}}, result.Edits)
+
+	_, err = ParseConfigEditResponse("I cannot help with that.")
+	assert.Error(t, err)
+}
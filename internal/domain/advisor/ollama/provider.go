@@ -1,22 +1,76 @@
 // Package ollama provides an AI provider implementation for Ollama (local LLMs).
+//
+// It talks to the OpenAI-compatible chat completions API, which Ollama
+// serves under /v1, so it also works with other local servers such as
+// LM Studio, llama.cpp and vLLM.
 package ollama
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
 
 	"github.com/felixgeelhaar/preflight/internal/domain/advisor"
 )
 
+// Default configuration values.
+const (
+	DefaultEndpoint = "http://localhost:11434"
+	DefaultModel    = "llama3.2"
+	// DefaultTimeout is generous because local models on laptops are slow.
+	DefaultTimeout  = 5 * time.Minute
+	MaxResponseSize = 10 * 1024 * 1024 // 10MB max response to prevent DoS
+)
+
 // Re-export common errors for backwards compatibility.
 var (
 	ErrNotConfigured = advisor.ErrNotConfigured
 	ErrEmptyModel    = advisor.ErrEmptyModel
+	ErrAPIError      = advisor.ErrAPIError
+	ErrUnauthorized  = advisor.ErrUnauthorized
 )
 
+// API request types.
+type chatRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	MaxTokens   int           `json:"max_tokens,omitempty"`
+	Temperature float64       `json:"temperature,omitempty"`
+	Stream      bool          `json:"stream"`
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// API response types.
+type chatResponse struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+	Usage struct {
+		TotalTokens int `json:"total_tokens"`
+	} `json:"usage"`
+}
+
+type errorResponse struct {
+	Error struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
 // Config holds the configuration for the Ollama provider.
 type Config struct {
 	Endpoint string
 	Model    string
+	APIKey   string // Optional, for servers that require one
 }
 
 // Validate checks if the configuration is valid.
@@ -31,17 +85,20 @@ func (c Config) Validate() error {
 type Provider struct {
 	endpoint string
 	model    string
+	apiKey   string
+	client   *http.Client
 }
 
 // NewProvider creates a new Ollama provider.
 func NewProvider(endpoint string) *Provider {
 	if endpoint == "" {
-		endpoint = "http://localhost:11434"
+		endpoint = DefaultEndpoint
 	}
 
 	return &Provider{
 		endpoint: endpoint,
-		model:    "llama3.2",
+		model:    DefaultModel,
+		client:   &http.Client{Timeout: DefaultTimeout},
 	}
 }
 
@@ -53,12 +110,14 @@ func NewProviderWithConfig(config Config) (*Provider, error) {
 
 	endpoint := config.Endpoint
 	if endpoint == "" {
-		endpoint = "http://localhost:11434"
+		endpoint = DefaultEndpoint
 	}
 
 	return &Provider{
 		endpoint: endpoint,
 		model:    config.Model,
+		apiKey:   config.APIKey,
+		client:   &http.Client{Timeout: DefaultTimeout},
 	}, nil
 }
 
@@ -82,6 +141,8 @@ func (p *Provider) WithModel(model string) *Provider {
 	return &Provider{
 		endpoint: p.endpoint,
 		model:    model,
+		apiKey:   p.apiKey,
+		client:   p.client,
 	}
 }
 
@@ -91,14 +152,80 @@ func (p *Provider) Available() bool {
 	return p.endpoint != ""
 }
 
-// Complete sends a prompt to Ollama and returns the response.
-// AI features require API integration which is planned for a future release.
-func (p *Provider) Complete(_ context.Context, _ advisor.Prompt) (advisor.Response, error) {
+// Complete sends a prompt to the chat completions API of the endpoint and
+// returns the response. Nothing leaves the machine unless the endpoint is
+// remote.
+func (p *Provider) Complete(ctx context.Context, prompt advisor.Prompt) (advisor.Response, error) {
 	if !p.Available() {
 		return advisor.Response{}, ErrNotConfigured
 	}
 
-	// AI completion requires Ollama API integration.
-	// Use the noop provider or disable AI features with --no-ai flag.
-	return advisor.Response{}, ErrNotConfigured
+	messages := []chatMessage{}
+	if prompt.SystemPrompt() != "" {
+		messages = append(messages, chatMessage{Role: "system", Content: prompt.SystemPrompt()})
+	}
+	messages = append(messages, chatMessage{Role: "user", Content: prompt.UserPrompt()})
+
+	jsonBody, err := json.Marshal(chatRequest{
+		Model:       p.model,
+		Messages:    messages,
+		MaxTokens:   prompt.MaxTokens(),
+		Temperature: prompt.Temperature(),
+	})
+	if err != nil {
+		return advisor.Response{}, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.completionsURL(), bytes.NewReader(jsonBody))
+	if err != nil {
+		return advisor.Response{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return advisor.Response{}, fmt.Errorf("request to %s failed (is the server running?): %w", p.endpoint, err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // Best effort close after reading body
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxResponseSize))
+	if err != nil {
+		return advisor.Response{}, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		if resp.StatusCode == http.StatusUnauthorized {
+			return advisor.Response{}, ErrUnauthorized
+		}
+		var errResp errorResponse
+		if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error.Message != "" {
+			return advisor.Response{}, fmt.Errorf("%w: %s", ErrAPIError, errResp.Error.Message)
+		}
+		return advisor.Response{}, fmt.Errorf("%w: status %d", ErrAPIError, resp.StatusCode)
+	}
+
+	var chatResp chatResponse
+	if err := json.Unmarshal(body, &chatResp); err != nil {
+		return advisor.Response{}, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	var content string
+	if len(chatResp.Choices) > 0 {
+		content = chatResp.Choices[0].Message.Content
+	}
+
+	return advisor.NewResponse(content, chatResp.Usage.TotalTokens, chatResp.Model), nil
+}
+
+// completionsURL accepts both a server root (http://localhost:11434) and an
+// OpenAI-style base URL ending in /v1 (http://localhost:1234/v1).
+func (p *Provider) completionsURL() string {
+	base := strings.TrimSuffix(p.endpoint, "/")
+	if !strings.HasSuffix(base, "/v1") {
+		base += "/v1"
+	}
+	return base + "/chat/completions"
 }
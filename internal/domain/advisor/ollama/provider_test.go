@@ -2,6 +2,9 @@ package ollama
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/felixgeelhaar/preflight/internal/domain/advisor"
@@ -62,16 +65,15 @@ func TestProvider_Available_NotConnected(t *testing.T) {
 	assert.True(t, p.Available())
 }
 
-func TestProvider_Complete_ReturnsError(t *testing.T) {
+func TestProvider_Complete_ServerDown(t *testing.T) {
 	t.Parallel()
 
-	p := NewProvider("http://nonexistent:11434")
-	prompt := advisor.NewPrompt("system", "user")
-
-	_, err := p.Complete(context.Background(), prompt)
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
 
-	// Should return an error because Ollama is not actually running
+	_, err := NewProvider(server.URL).Complete(context.Background(), advisor.NewPrompt("system", "user"))
 	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is the server running?")
 }
 
 func TestProvider_ImplementsAIProvider(t *testing.T) {
@@ -183,15 +185,43 @@ func TestNewProviderWithConfig(t *testing.T) {
 	}
 }
 
-func TestProvider_Complete_Available(t *testing.T) {
+func TestProvider_Complete(t *testing.T) {
 	t.Parallel()
 
-	p := NewProvider("http://localhost:11434")
-	prompt := advisor.NewPrompt("system", "user")
+	for _, endpoint := range []string{"", "/v1", "/v1/"} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+			assert.Equal(t, "Bearer local-key", r.Header.Get("Authorization"))
+
+			var req chatRequest
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, "qwen2.5", req.Model)
+			assert.Equal(t, []chatMessage{{Role: "system", Content: "system"}, {Role: "user", Content: "user"}}, req.Messages)
+			assert.False(t, req.Stream)
+
+			_, _ = w.Write([]byte(`{"model":"qwen2.5","choices":[{"message":{"role":"assistant","content":"hello"}}],"usage":{"total_tokens":12}}`))
+		}))
+		t.Cleanup(server.Close)
+
+		p, err := NewProviderWithConfig(Config{Endpoint: server.URL + endpoint, Model: "qwen2.5", APIKey: "local-key"})
+		require.NoError(t, err)
+		resp, err := p.Complete(context.Background(), advisor.NewPrompt("system", "user"))
+		require.NoError(t, err, endpoint)
+		assert.Equal(t, "hello", resp.Content())
+		assert.Equal(t, 12, resp.TokensUsed())
+	}
+}
 
-	_, err := p.Complete(context.Background(), prompt)
+func TestProvider_Complete_ModelNotFound(t *testing.T) {
+	t.Parallel()
 
-	// The API is not actually integrated, so it returns ErrNotConfigured
-	require.Error(t, err)
-	assert.ErrorIs(t, err, ErrNotConfigured)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":{"message":"model \"llama3.2\" not found, try pulling it first"}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	_, err := NewProvider(server.URL).Complete(context.Background(), advisor.NewPrompt("", "user"))
+	require.ErrorIs(t, err, ErrAPIError)
+	assert.Contains(t, err.Error(), "try pulling it first")
 }
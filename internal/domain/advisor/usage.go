@@ -0,0 +1,153 @@
+package advisor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrBudgetExceeded is returned when the monthly AI budget is used up.
+var ErrBudgetExceeded = errors.New("monthly AI budget exceeded")
+
+// Usage is the token usage and estimated cost of one AI call.
+type Usage struct {
+	Time     time.Time `json:"time"`
+	Command  string    `json:"command"`
+	Provider string    `json:"provider"`
+	Model    string    `json:"model,omitempty"`
+	Tokens   int       `json:"tokens"`
+	CostUSD  float64   `json:"cost_usd"`
+}
+
+// UsageStore records AI usage and reads it back.
+type UsageStore interface {
+	RecordUsage(ctx context.Context, usage Usage) error
+	// UsageSince returns the usage recorded at or after since.
+	UsageSince(ctx context.Context, since time.Time) ([]Usage, error)
+}
+
+// modelPrices are list prices in US dollars per million tokens, blended
+// 3:1 input to output since providers report a single total. Models are
+// matched by the longest prefix.
+var modelPrices = map[string]float64{
+	"claude-opus":      10.0,
+	"claude-sonnet":    6.0,
+	"claude-haiku":     2.0,
+	"gpt-4.1":          3.5,
+	"gpt-4.1-mini":     0.7,
+	"gpt-4.1-nano":     0.175,
+	"gpt-4o":           4.375,
+	"gpt-4o-mini":      0.2625,
+	"gemini-2.5-pro":   3.4375,
+	"gemini-2.5-flash": 0.85,
+}
+
+// defaultModelPrice applies to hosted models missing from modelPrices.
+const defaultModelPrice = 5.0
+
+// EstimateCost returns the estimated cost in US dollars of tokens used
+// with model. Local models are free.
+func EstimateCost(provider, model string, tokens int) float64 {
+	if provider == "ollama" || tokens <= 0 {
+		return 0
+	}
+	price, matched := defaultModelPrice, ""
+	for prefix, p := range modelPrices {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(matched) {
+			price, matched = p, prefix
+		}
+	}
+	return float64(tokens) * price / 1e6
+}
+
+// MonthStart returns the start of the calendar month of t.
+func MonthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+// MonthlySpend returns the estimated cost recorded in store for the
+// calendar month of now.
+func MonthlySpend(ctx context.Context, store UsageStore, now time.Time) (float64, error) {
+	usage, err := store.UsageSince(ctx, MonthStart(now))
+	if err != nil {
+		return 0, err
+	}
+	total := 0.0
+	for _, u := range usage {
+		total += u.CostUSD
+	}
+	return total, nil
+}
+
+// MeteredProvider records the usage of every call of a provider and
+// refuses calls once the monthly budget is spent.
+type MeteredProvider struct {
+	provider AIProvider
+	store    UsageStore
+	command  string
+	budget   float64
+	now      func() time.Time
+}
+
+// NewMeteredProvider wraps provider so that calls made for command are
+// recorded in store. A budget of zero means no limit.
+func NewMeteredProvider(provider AIProvider, store UsageStore, command string, budget float64) *MeteredProvider {
+	return &MeteredProvider{
+		provider: provider,
+		store:    store,
+		command:  command,
+		budget:   budget,
+		now:      time.Now,
+	}
+}
+
+// Name returns the name of the wrapped provider.
+func (p *MeteredProvider) Name() string {
+	return p.provider.Name()
+}
+
+// Available reports whether the wrapped provider is available.
+func (p *MeteredProvider) Available() bool {
+	return p.provider.Available()
+}
+
+// CheckBudget returns ErrBudgetExceeded when this month's spend has reached
+// the budget.
+func (p *MeteredProvider) CheckBudget(ctx context.Context) error {
+	if p.budget <= 0 {
+		return nil
+	}
+	spent, err := MonthlySpend(ctx, p.store, p.now())
+	if err != nil {
+		return fmt.Errorf("failed to read AI usage: %w", err)
+	}
+	if spent >= p.budget {
+		return fmt.Errorf("%w: spent $%.2f of $%.2f this month", ErrBudgetExceeded, spent, p.budget)
+	}
+	return nil
+}
+
+// Complete checks the budget, calls the wrapped provider and records the
+// usage of the response.
+func (p *MeteredProvider) Complete(ctx context.Context, prompt Prompt) (Response, error) {
+	if err := p.CheckBudget(ctx); err != nil {
+		return Response{}, err
+	}
+	response, err := p.provider.Complete(ctx, prompt)
+	if err != nil {
+		return response, err
+	}
+	usage := Usage{
+		Time:     p.now(),
+		Command:  p.command,
+		Provider: p.provider.Name(),
+		Model:    response.Model(),
+		Tokens:   response.TokensUsed(),
+		CostUSD:  EstimateCost(p.provider.Name(), response.Model(), response.TokensUsed()),
+	}
+	// Failing to record usage must not fail the command that made the call.
+	_ = p.store.RecordUsage(ctx, usage)
+	return response, nil
+}
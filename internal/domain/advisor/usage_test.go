@@ -0,0 +1,81 @@
+package advisor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateCost(t *testing.T) {
+	t.Parallel()
+
+	assert.InDelta(t, 6.0, EstimateCost("anthropic", "claude-sonnet-4-6", 1_000_000), 1e-9)
+	assert.InDelta(t, 0.7, EstimateCost("openai", "gpt-4.1-mini-2025-04-14", 1_000_000), 1e-9, "longest prefix wins")
+	assert.InDelta(t, 0.005, EstimateCost("openai", "unknown-model", 1_000), 1e-9)
+	assert.Zero(t, EstimateCost("ollama", "llama3.2", 1_000_000))
+}
+
+// memoryUsageStore keeps usage in memory.
+type memoryUsageStore struct {
+	usage []Usage
+}
+
+func (s *memoryUsageStore) RecordUsage(_ context.Context, usage Usage) error {
+	s.usage = append(s.usage, usage)
+	return nil
+}
+
+func (s *memoryUsageStore) UsageSince(_ context.Context, since time.Time) ([]Usage, error) {
+	var usage []Usage
+	for _, u := range s.usage {
+		if !u.Time.Before(since) {
+			usage = append(usage, u)
+		}
+	}
+	return usage, nil
+}
+
+// stubProvider answers every prompt with response.
+type stubProvider struct {
+	response Response
+	calls    int
+}
+
+func (p *stubProvider) Name() string    { return "anthropic" }
+func (p *stubProvider) Available() bool { return true }
+func (p *stubProvider) Complete(context.Context, Prompt) (Response, error) {
+	p.calls++
+	return p.response, nil
+}
+
+func TestMeteredProvider(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	store := &memoryUsageStore{usage: []Usage{
+		{Time: now.AddDate(0, -1, 0), CostUSD: 100}, // last month
+		{Time: now.Add(-time.Hour), CostUSD: 0.5},
+	}}
+	inner := &stubProvider{response: NewResponse("ok", 100_000, "claude-sonnet-4-6")}
+	provider := NewMeteredProvider(inner, store, "analyze", 1.0)
+	provider.now = func() time.Time { return now }
+
+	response, err := provider.Complete(context.Background(), TestPrompt())
+	require.NoError(t, err)
+	assert.Equal(t, "ok", response.Content())
+	require.Len(t, store.usage, 3)
+	assert.Equal(t, Usage{Time: now, Command: "analyze", Provider: "anthropic", Model: "claude-sonnet-4-6", Tokens: 100_000, CostUSD: 0.6}, store.usage[2])
+
+	// $1.10 spent this month: the budget of $1 is used up.
+	_, err = provider.Complete(context.Background(), TestPrompt())
+	require.True(t, errors.Is(err, ErrBudgetExceeded))
+	assert.Contains(t, err.Error(), "spent $1.10 of $1.00 this month")
+	assert.Equal(t, 1, inner.calls)
+
+	unlimited := NewMeteredProvider(inner, store, "analyze", 0)
+	require.NoError(t, unlimited.CheckBudget(context.Background()))
+}
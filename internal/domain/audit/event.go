@@ -28,6 +28,7 @@ const (
 	EventPluginDiscovered  EventType = "plugin_discovered"
 	EventPluginExecuted    EventType = "plugin_executed"
 	EventPluginValidated   EventType = "plugin_validated"
+	EventPluginReloaded    EventType = "plugin_reloaded"
 )
 
 // Event types for trust operations.
@@ -59,6 +60,11 @@ const (
 	EventIdentityRefresh EventType = "identity_refresh"
 )
 
+// Event types for AI features.
+const (
+	EventAIUsage EventType = "ai_usage"
+)
+
 // Severity represents the importance level of an event.
 type Severity string
 
@@ -10,6 +10,8 @@ import (
 	"path/filepath"
 	"sync"
 	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
 )
 
 // Logger defines the interface for audit logging.
@@ -53,9 +55,9 @@ type FileLoggerConfig struct {
 
 // DefaultFileLoggerConfig returns sensible defaults.
 func DefaultFileLoggerConfig() FileLoggerConfig {
-	home, _ := os.UserHomeDir()
+	dir, _ := platform.Path(platform.StateDir, "audit")
 	return FileLoggerConfig{
-		Dir:          filepath.Join(home, ".preflight", "audit"),
+		Dir:          dir,
 		MaxSize:      10 * 1024 * 1024, // 10 MB
 		MaxAge:       90 * 24 * time.Hour,
 		MaxRotations: 10,
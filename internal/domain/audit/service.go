@@ -87,6 +87,25 @@ func (s *Service) LogPluginUninstalled(ctx context.Context, plugin string) error
 	return s.logger.Log(ctx, event)
 }
 
+// LogPluginReloaded logs a plugin being reloaded after its files changed.
+// change describes what happened: "added", "updated" or "removed".
+func (s *Service) LogPluginReloaded(ctx context.Context, plugin, version, change string, err error) error {
+	builder := NewEvent(EventPluginReloaded).
+		WithUser(getCurrentUser()).
+		WithPlugin(plugin).
+		WithSuccess(err == nil).
+		AddDetail("change", change)
+
+	if version != "" {
+		builder.AddDetail("version", version)
+	}
+	if err != nil {
+		builder.WithError(err)
+	}
+
+	return s.logger.Log(ctx, builder.Build())
+}
+
 // LogPluginExecuted logs a plugin execution event.
 func (s *Service) LogPluginExecuted(ctx context.Context, plugin, sandboxMode string, duration time.Duration, success bool, err error) error {
 	builder := NewEvent(EventPluginExecuted).
@@ -206,6 +225,22 @@ func (s *Service) LogSecurityAudit(ctx context.Context, catalog string, passed b
 	return s.logger.Log(ctx, event)
 }
 
+// LogAIUsage logs the token usage and estimated cost of an AI call made
+// by command.
+func (s *Service) LogAIUsage(ctx context.Context, command, provider, model string, tokens int, costUSD float64) error {
+	event := NewEvent(EventAIUsage).
+		WithUser(getCurrentUser()).
+		WithSource(command).
+		WithSuccess(true).
+		AddDetail("provider", provider).
+		AddDetail("model", model).
+		AddDetail("tokens", tokens).
+		AddDetail("cost_usd", costUSD).
+		Build()
+
+	return s.logger.Log(ctx, event)
+}
+
 // Query retrieves events matching the filter.
 func (s *Service) Query(ctx context.Context, filter QueryFilter) ([]Event, error) {
 	return s.logger.Query(ctx, filter)
@@ -176,6 +176,29 @@ func TestService_LogPluginExecuted_Failure(t *testing.T) {
 	assert.Contains(t, events[0].Error, "timeout")
 }
 
+func TestService_LogPluginReloaded(t *testing.T) {
+	t.Parallel()
+
+	logger := audit.NewMemoryLogger()
+	service := audit.NewService(logger)
+	ctx := context.Background()
+
+	require.NoError(t, service.LogPluginReloaded(ctx, "my-plugin", "1.1.0", "updated", nil))
+	require.NoError(t, service.LogPluginReloaded(ctx, "broken", "", "updated", errors.New("invalid manifest")))
+
+	events := logger.Events()
+	require.Len(t, events, 2)
+
+	assert.Equal(t, audit.EventPluginReloaded, events[0].Type)
+	assert.Equal(t, "my-plugin", events[0].Plugin)
+	assert.True(t, events[0].Success)
+	assert.Equal(t, "updated", events[0].Details["change"])
+	assert.Equal(t, "1.1.0", events[0].Details["version"])
+
+	assert.False(t, events[1].Success)
+	assert.Contains(t, events[1].Error, "invalid manifest")
+}
+
 func TestService_LogTrustAdded(t *testing.T) {
 	t.Parallel()
 
@@ -2,6 +2,7 @@ package catalog
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -10,6 +11,8 @@ import (
 	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
 )
 
 // ExternalLoader loads catalogs from external sources (URLs or local paths).
@@ -26,10 +29,10 @@ type ExternalLoaderConfig struct {
 
 // DefaultExternalLoaderConfig returns default configuration.
 func DefaultExternalLoaderConfig() ExternalLoaderConfig {
-	home, _ := os.UserHomeDir()
+	cacheDir, _ := platform.Path(platform.DataDir, "catalogs")
 	return ExternalLoaderConfig{
 		Timeout:  30 * time.Second,
-		CacheDir: filepath.Join(home, ".preflight", "catalogs"),
+		CacheDir: cacheDir,
 	}
 }
 
@@ -133,6 +136,50 @@ func (l *ExternalLoader) Verify(ctx context.Context, rc *RegisteredCatalog) erro
 	return nil
 }
 
+// VerifySignature verifies the keyless Sigstore signature of a catalog's
+// manifest, published next to it as catalog-manifest.yaml.sigstore.json,
+// and that the registered manifest is the signed one. As the manifest
+// holds the hashes of all catalog files, Verify then covers their content.
+// It returns ErrNoSignature when the catalog is not signed.
+func (l *ExternalLoader) VerifySignature(ctx context.Context, rc *RegisteredCatalog, verifier *SigstoreVerifier) (*KeylessSigner, error) {
+	source := rc.Source()
+	bundleData, err := l.fetchFile(ctx, source, "catalog-manifest.yaml"+SigstoreBundleSuffix)
+	if err != nil {
+		if errors.Is(err, ErrSourceNotFound) || errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNoSignature
+		}
+		return nil, fmt.Errorf("failed to fetch signature: %w", err)
+	}
+	bundle, err := ParseKeylessBundle(bundleData)
+	if err != nil {
+		return nil, err
+	}
+
+	manifestData, err := l.fetchFile(ctx, source, "catalog-manifest.yaml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch manifest: %w", err)
+	}
+	signer, err := verifier.VerifyBundle(manifestData, bundle)
+	if err != nil {
+		return nil, err
+	}
+
+	signed, err := l.parseManifest(manifestData)
+	if err != nil {
+		return nil, err
+	}
+	registered := rc.Manifest()
+	if len(signed.Files()) != len(registered.Files()) {
+		return nil, fmt.Errorf("%w: registered manifest differs from the signed manifest", ErrIntegrityMismatch)
+	}
+	for _, file := range signed.Files() {
+		if hash, ok := registered.GetFileHash(file.Path); !ok || hash != file.Hash {
+			return nil, fmt.Errorf("%w: registered manifest differs from the signed manifest for %s", ErrIntegrityMismatch, file.Path)
+		}
+	}
+	return signer, nil
+}
+
 // fetchManifest fetches and parses the manifest file.
 func (l *ExternalLoader) fetchManifest(ctx context.Context, source Source) (Manifest, error) {
 	data, err := l.fetchFile(ctx, source, "catalog-manifest.yaml")
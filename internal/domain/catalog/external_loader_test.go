@@ -6,8 +6,11 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/felixgeelhaar/preflight/internal/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -322,3 +325,51 @@ integrity:
 	assert.Equal(t, "local-catalog", rc.Manifest().Name())
 	assert.Equal(t, 1, rc.Catalog().PresetCount())
 }
+
+func TestExternalLoader_VerifySignature(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	catalogContent := "presets: []\n"
+	manifestContent := `
+version: "1.0"
+name: "signed-catalog"
+integrity:
+  algorithm: sha256
+  files:
+    catalog.yaml: ` + ComputeSHA256([]byte(catalogContent)) + `
+`
+	manifestPath := filepath.Join(tmpDir, "catalog-manifest.yaml")
+	require.NoError(t, os.WriteFile(manifestPath, []byte(manifestContent), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "catalog.yaml"), []byte(catalogContent), 0o644))
+
+	loader := NewExternalLoader(ExternalLoaderConfig{Timeout: time.Second, CacheDir: t.TempDir()})
+	source, err := NewLocalSource("signed-catalog", tmpDir)
+	require.NoError(t, err)
+	rc, err := loader.Load(context.Background(), source)
+	require.NoError(t, err)
+
+	fixture := testutil.NewSigstoreFixture(t)
+	verifier := keylessVerifier(t, fixture.TrustedRoot, ExactSigstoreIdentity(testIssuer, testSubject))
+
+	_, err = loader.VerifySignature(context.Background(), rc, verifier)
+	require.ErrorIs(t, err, ErrNoSignature)
+
+	bundlePath := manifestPath + SigstoreBundleSuffix
+	require.NoError(t, os.WriteFile(bundlePath, fixture.Sign(t, []byte(manifestContent), testIssuer, testSubject), 0o644))
+	signer, err := loader.VerifySignature(context.Background(), rc, verifier)
+	require.NoError(t, err)
+	assert.Equal(t, testSubject, signer.Subject)
+
+	// A newly signed manifest must match the registered one.
+	changed := strings.Replace(manifestContent, ComputeSHA256([]byte(catalogContent)), ComputeSHA256([]byte("presets: [x]\n")), 1)
+	require.NoError(t, os.WriteFile(manifestPath, []byte(changed), 0o644))
+	require.NoError(t, os.WriteFile(bundlePath, fixture.Sign(t, []byte(changed), testIssuer, testSubject), 0o644))
+	_, err = loader.VerifySignature(context.Background(), rc, verifier)
+	require.ErrorIs(t, err, ErrIntegrityMismatch)
+
+	// The manifest changed after it was signed.
+	require.NoError(t, os.WriteFile(manifestPath, []byte(manifestContent), 0o644))
+	_, err = loader.VerifySignature(context.Background(), rc, verifier)
+	require.ErrorIs(t, err, ErrInvalidSignature)
+}
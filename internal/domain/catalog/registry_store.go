@@ -8,6 +8,8 @@ import (
 	"path/filepath"
 	"sync"
 	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
 )
 
 // Store errors.
@@ -23,9 +25,9 @@ type RegistryStoreConfig struct {
 
 // DefaultRegistryStoreConfig returns sensible defaults.
 func DefaultRegistryStoreConfig() RegistryStoreConfig {
-	homeDir, _ := os.UserHomeDir()
+	basePath, _ := platform.Path(platform.DataDir, "catalogs")
 	return RegistryStoreConfig{
-		BasePath: filepath.Join(homeDir, ".preflight", "catalogs"),
+		BasePath: basePath,
 	}
 }
 
@@ -248,11 +248,12 @@ func TestStoredSource_ToSource_Unknown(t *testing.T) {
 }
 
 func TestDefaultRegistryStoreConfig(t *testing.T) {
-	t.Parallel()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", "")
 
 	config := DefaultRegistryStoreConfig()
-	assert.Contains(t, config.BasePath, ".preflight")
-	assert.Contains(t, config.BasePath, "catalogs")
+	assert.Equal(t, filepath.Join(home, ".local", "share", "preflight", "catalogs"), config.BasePath)
 }
 
 func TestRegistryStore_MultipleSources(t *testing.T) {
@@ -192,12 +192,14 @@ func (p Publisher) IsZero() bool {
 	return p.name == "" && p.email == "" && p.keyID == ""
 }
 
-// TrustedKey represents a trusted public key.
+// TrustedKey represents a trusted public key, or for Sigstore an OIDC
+// identity whose keyless signatures are trusted.
 type TrustedKey struct {
 	keyID       string
 	keyType     SignatureType
 	publicKey   crypto.PublicKey
 	fingerprint string
+	identity    SigstoreIdentity
 	publisher   Publisher
 	trustLevel  TrustLevel
 	addedAt     time.Time
@@ -217,6 +219,16 @@ func NewTrustedKey(keyID string, keyType SignatureType, publicKey crypto.PublicK
 	}
 }
 
+// NewTrustedIdentity creates a trusted Sigstore identity: keyless
+// signatures whose Fulcio certificate matches identity are trusted.
+func NewTrustedIdentity(keyID string, identity SigstoreIdentity) *TrustedKey {
+	identity.Name = keyID
+	key := NewTrustedKey(keyID, SignatureTypeSigstore, nil,
+		NewPublisher(keyID, "", keyID, SignatureTypeSigstore))
+	key.identity = identity
+	return key
+}
+
 // KeyID returns the key identifier.
 func (k *TrustedKey) KeyID() string {
 	return k.keyID
@@ -237,6 +249,11 @@ func (k *TrustedKey) Fingerprint() string {
 	return k.fingerprint
 }
 
+// Identity returns the OIDC identity of a Sigstore key.
+func (k *TrustedKey) Identity() SigstoreIdentity {
+	return k.identity
+}
+
 // Publisher returns the publisher info.
 func (k *TrustedKey) Publisher() Publisher {
 	return k.publisher
@@ -0,0 +1,205 @@
+package catalog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/sigstore/sigstore-go/pkg/bundle"
+	"github.com/sigstore/sigstore-go/pkg/fulcio/certificate"
+	"github.com/sigstore/sigstore-go/pkg/root"
+	"github.com/sigstore/sigstore-go/pkg/verify"
+)
+
+// Keyless verification errors.
+var (
+	ErrSigstoreNoTrustedRoot = errors.New("no Sigstore trusted root")
+	ErrSigstoreInvalidBundle = errors.New("invalid Sigstore bundle")
+	ErrSigstoreTlogInvalid   = errors.New("transparency log entry is invalid")
+)
+
+// SigstoreBundleSuffix is appended to the name of a file to name its
+// Sigstore bundle, as written by 'cosign sign-blob --bundle'.
+const SigstoreBundleSuffix = ".sigstore.json"
+
+// ExactSigstoreIdentity returns an identity matching exactly one OIDC
+// issuer and subject.
+func ExactSigstoreIdentity(issuer, subject string) SigstoreIdentity {
+	return SigstoreIdentity{
+		IssuerRegexp:  "^" + regexp.QuoteMeta(issuer) + "$",
+		SubjectRegexp: "^" + regexp.QuoteMeta(subject) + "$",
+	}
+}
+
+// SigstoreIdentityRegexp returns an identity matching one OIDC issuer and
+// the subjects that subjectRegexp matches in full.
+func SigstoreIdentityRegexp(issuer, subjectRegexp string) (SigstoreIdentity, error) {
+	subject := "^(?:" + subjectRegexp + ")$"
+	if _, err := regexp.Compile(subject); err != nil {
+		return SigstoreIdentity{}, fmt.Errorf("invalid identity pattern: %w", err)
+	}
+	return SigstoreIdentity{
+		IssuerRegexp:  "^" + regexp.QuoteMeta(issuer) + "$",
+		SubjectRegexp: subject,
+	}, nil
+}
+
+// SigstoreTrustedRoot holds the Fulcio certificate authorities, Rekor
+// transparency logs and certificate transparency logs that keyless
+// signatures are verified against. It is read from a Sigstore
+// trusted_root.json, as distributed through the Sigstore TUF repository or
+// written by 'cosign trusted-root create'.
+type SigstoreTrustedRoot struct {
+	root *root.TrustedRoot
+}
+
+// ParseSigstoreTrustedRoot parses a Sigstore trusted_root.json.
+func ParseSigstoreTrustedRoot(data []byte) (*SigstoreTrustedRoot, error) {
+	trusted, err := root.NewTrustedRootFromJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trusted root: %w", err)
+	}
+	if len(trusted.FulcioCertificateAuthorities()) == 0 || len(trusted.RekorLogs()) == 0 {
+		return nil, fmt.Errorf("invalid trusted root: it needs a certificate authority and a transparency log")
+	}
+	return &SigstoreTrustedRoot{root: trusted}, nil
+}
+
+// Authorities returns the URIs of the trusted certificate authorities.
+func (r *SigstoreTrustedRoot) Authorities() []string {
+	var uris []string
+	for _, authority := range r.root.FulcioCertificateAuthorities() {
+		if fulcio, ok := authority.(*root.FulcioCertificateAuthority); ok {
+			uris = append(uris, fulcio.URI)
+		}
+	}
+	return uris
+}
+
+// Logs returns the URLs of the trusted transparency logs.
+func (r *SigstoreTrustedRoot) Logs() []string {
+	var urls []string
+	for _, log := range r.root.RekorLogs() {
+		if !slices.Contains(urls, log.BaseURL) {
+			urls = append(urls, log.BaseURL)
+		}
+	}
+	slices.Sort(urls)
+	return urls
+}
+
+// KeylessBundle is a Sigstore bundle for a keyless signature: the Fulcio
+// certificate, the signature and its Rekor log entry.
+type KeylessBundle struct {
+	bundle *bundle.Bundle
+}
+
+// ParseKeylessBundle parses a Sigstore bundle (.sigstore.json) signed with
+// a Fulcio certificate.
+func ParseKeylessBundle(data []byte) (*KeylessBundle, error) {
+	b := &bundle.Bundle{}
+	if err := b.UnmarshalJSON(data); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSigstoreInvalidBundle, err)
+	}
+	content, err := b.VerificationContent()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSigstoreInvalidBundle, err)
+	}
+	if content.Certificate() == nil {
+		return nil, fmt.Errorf("%w: %w", ErrSigstoreInvalidBundle, ErrSigstoreNoCertificate)
+	}
+	return &KeylessBundle{bundle: b}, nil
+}
+
+// KeylessSigner is the OIDC identity behind a verified keyless signature.
+type KeylessSigner struct {
+	// Name is the trust store entry the identity matched.
+	Name    string
+	Issuer  string
+	Subject string
+	// SignedAt is when the transparency log recorded the signature.
+	SignedAt time.Time
+	LogIndex int64
+}
+
+// String formats the signer for display.
+func (s *KeylessSigner) String() string {
+	return fmt.Sprintf("%s (%s via %s)", s.Name, s.Subject, s.Issuer)
+}
+
+// VerifyBundle verifies a keyless signature of content with sigstore-go:
+// the Rekor log entry's signed entry timestamp, inclusion proof and
+// checkpoint, the certificate chain against the trusted Fulcio CAs at the
+// time the entry was logged, the certificate transparency SCT embedded in
+// the certificate, the signature itself, and the certificate's OIDC
+// identity against the trusted identities.
+func (v *SigstoreVerifier) VerifyBundle(content []byte, b *KeylessBundle) (*KeylessSigner, error) {
+	return v.verifyBundle(b, verify.WithArtifact(bytes.NewReader(content)))
+}
+
+func (v *SigstoreVerifier) verifyBundle(b *KeylessBundle, artifact verify.ArtifactPolicyOption) (*KeylessSigner, error) {
+	if v.config.TrustedRoot == nil {
+		return nil, ErrSigstoreNoTrustedRoot
+	}
+	verifier, err := verify.NewVerifier(v.config.TrustedRoot.root,
+		verify.WithSignedCertificateTimestamps(1),
+		verify.WithTransparencyLog(1),
+		verify.WithObserverTimestamps(1),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := verifier.VerifyTransparencyLogInclusion(b.bundle); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSigstoreTlogInvalid, err)
+	}
+	// The identity is checked against the trust store below rather than in
+	// the policy, so that an untrusted signer is told apart from an invalid
+	// signature.
+	result, err := verifier.Verify(b.bundle, verify.NewPolicy(artifact, verify.WithoutIdentitiesUnsafe()))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+	if result.Signature == nil || result.Signature.Certificate == nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSignature, ErrSigstoreNoCertificate)
+	}
+	cert := *result.Signature.Certificate
+
+	identity, err := v.matchIdentity(cert)
+	if err != nil {
+		return nil, err
+	}
+	signer := &KeylessSigner{
+		Name:    identity.Name,
+		Issuer:  cert.Issuer,
+		Subject: cert.SubjectAlternativeName,
+	}
+	for _, ts := range result.VerifiedTimestamps {
+		if signer.SignedAt.IsZero() || ts.Timestamp.Before(signer.SignedAt) {
+			signer.SignedAt = ts.Timestamp
+		}
+	}
+	if entries, err := b.bundle.TlogEntries(); err == nil && len(entries) > 0 {
+		signer.LogIndex = entries[0].LogIndex()
+	}
+	return signer, nil
+}
+
+// matchIdentity returns the first trusted identity matching the verified
+// certificate.
+func (v *SigstoreVerifier) matchIdentity(cert certificate.Summary) (SigstoreIdentity, error) {
+	for _, identity := range v.config.TrustedIdentities {
+		certID, err := verify.NewShortCertificateIdentity("", identity.IssuerRegexp, "", identity.SubjectRegexp)
+		if err != nil {
+			continue
+		}
+		if certID.Verify(cert) == nil {
+			return identity, nil
+		}
+	}
+	return SigstoreIdentity{}, fmt.Errorf("%w: issuer=%s subject=%s",
+		ErrSigstoreIdentityMismatch, cert.Issuer, cert.SubjectAlternativeName)
+}
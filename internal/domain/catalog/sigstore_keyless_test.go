@@ -0,0 +1,286 @@
+package catalog
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/testutil"
+	"github.com/sigstore/sigstore-go/pkg/verify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testIssuer  = "https://token.actions.githubusercontent.com"
+	testSubject = "https://github.com/acme/catalog/.github/workflows/release.yml@refs/heads/main"
+)
+
+func keylessVerifier(t *testing.T, trustedRoot []byte, identities ...SigstoreIdentity) *SigstoreVerifier {
+	t.Helper()
+	root, err := ParseSigstoreTrustedRoot(trustedRoot)
+	require.NoError(t, err)
+	return NewSigstoreVerifier(SigstoreVerifierConfig{TrustedRoot: root, TrustedIdentities: identities})
+}
+
+func TestSigstoreVerifier_VerifyBundle(t *testing.T) {
+	t.Parallel()
+
+	fixture := testutil.NewSigstoreFixture(t)
+	content := []byte("catalog manifest")
+	bundle, err := ParseKeylessBundle(fixture.Sign(t, content, testIssuer, testSubject))
+	require.NoError(t, err)
+
+	exact := ExactSigstoreIdentity(testIssuer, testSubject)
+	exact.Name = "acme-release"
+	pattern, err := SigstoreIdentityRegexp(testIssuer, `https://github\.com/acme/.+`)
+	require.NoError(t, err)
+
+	t.Run("exact identity", func(t *testing.T) {
+		t.Parallel()
+		signer, err := keylessVerifier(t, fixture.TrustedRoot, exact).VerifyBundle(content, bundle)
+		require.NoError(t, err)
+		assert.Equal(t, "acme-release", signer.Name)
+		assert.Equal(t, testIssuer, signer.Issuer)
+		assert.Equal(t, testSubject, signer.Subject)
+		assert.Equal(t, int64(0), signer.LogIndex)
+		assert.False(t, signer.SignedAt.IsZero())
+	})
+
+	t.Run("identity pattern", func(t *testing.T) {
+		t.Parallel()
+		_, err := keylessVerifier(t, fixture.TrustedRoot, pattern).VerifyBundle(content, bundle)
+		require.NoError(t, err)
+	})
+
+	t.Run("tampered content", func(t *testing.T) {
+		t.Parallel()
+		_, err := keylessVerifier(t, fixture.TrustedRoot, exact).VerifyBundle([]byte("catalog manifest!"), bundle)
+		require.ErrorIs(t, err, ErrInvalidSignature)
+	})
+
+	t.Run("untrusted identity", func(t *testing.T) {
+		t.Parallel()
+		other := ExactSigstoreIdentity(testIssuer, "https://github.com/evil/catalog/.github/workflows/release.yml@refs/heads/main")
+		_, err := keylessVerifier(t, fixture.TrustedRoot, other).VerifyBundle(content, bundle)
+		require.ErrorIs(t, err, ErrSigstoreIdentityMismatch)
+	})
+
+	t.Run("pattern must match the whole subject", func(t *testing.T) {
+		t.Parallel()
+		partial, err := SigstoreIdentityRegexp(testIssuer, `https://github\.com/acme/catalog`)
+		require.NoError(t, err)
+		_, err = keylessVerifier(t, fixture.TrustedRoot, partial).VerifyBundle(content, bundle)
+		require.ErrorIs(t, err, ErrSigstoreIdentityMismatch)
+	})
+
+	t.Run("other trusted root", func(t *testing.T) {
+		t.Parallel()
+		other := testutil.NewSigstoreFixture(t)
+		_, err := keylessVerifier(t, other.TrustedRoot, exact).VerifyBundle(content, bundle)
+		require.ErrorIs(t, err, ErrSigstoreTlogInvalid)
+	})
+
+	t.Run("no trusted root", func(t *testing.T) {
+		t.Parallel()
+		verifier := NewSigstoreVerifier(SigstoreVerifierConfig{TrustedIdentities: []SigstoreIdentity{exact}})
+		_, err := verifier.VerifyBundle(content, bundle)
+		require.ErrorIs(t, err, ErrSigstoreNoTrustedRoot)
+	})
+}
+
+func TestSigstoreVerifier_VerifyBundle_ForeignCertificate(t *testing.T) {
+	t.Parallel()
+
+	// A log entry and certificate from another CA must not verify even when
+	// the log is trusted.
+	trusted := testutil.NewSigstoreFixture(t)
+	content := []byte("policy")
+	bundle, err := ParseKeylessBundle(testutil.NewSigstoreFixture(t).Sign(t, content, testIssuer, "dev@acme.com"))
+	require.NoError(t, err)
+
+	_, err = keylessVerifier(t, trusted.TrustedRoot, ExactSigstoreIdentity(testIssuer, "dev@acme.com")).
+		VerifyBundle(content, bundle)
+	require.Error(t, err)
+}
+
+// editBundle returns a copy of a bundle with edit applied to its first
+// transparency log entry.
+func editBundle(t *testing.T, data []byte, edit func(entry map[string]any)) []byte {
+	t.Helper()
+	var raw map[string]any
+	require.NoError(t, json.Unmarshal(data, &raw))
+	material := raw["verificationMaterial"].(map[string]any)
+	edit(material["tlogEntries"].([]any)[0].(map[string]any))
+	edited, err := json.Marshal(raw)
+	require.NoError(t, err)
+	return edited
+}
+
+func TestSigstoreVerifier_VerifyBundle_TransparencyChecks(t *testing.T) {
+	t.Parallel()
+
+	fixture := testutil.NewSigstoreFixture(t)
+	content := []byte("policy")
+	// A second entry gives the log a tree with a non-empty inclusion proof.
+	_ = fixture.Sign(t, []byte("other"), testIssuer, testSubject)
+	signed := fixture.Sign(t, content, testIssuer, testSubject)
+	exact := ExactSigstoreIdentity(testIssuer, testSubject)
+
+	bundle, err := ParseKeylessBundle(signed)
+	require.NoError(t, err)
+	signer, err := keylessVerifier(t, fixture.TrustedRoot, exact).VerifyBundle(content, bundle)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), signer.LogIndex)
+
+	t.Run("inclusion proof is required", func(t *testing.T) {
+		t.Parallel()
+		_, err := ParseKeylessBundle(editBundle(t, signed, func(entry map[string]any) {
+			delete(entry, "inclusionProof")
+		}))
+		require.ErrorIs(t, err, ErrSigstoreInvalidBundle)
+	})
+
+	t.Run("inclusion proof must lead to the checkpoint", func(t *testing.T) {
+		t.Parallel()
+		bundle, err := ParseKeylessBundle(editBundle(t, signed, func(entry map[string]any) {
+			proof := entry["inclusionProof"].(map[string]any)
+			proof["hashes"] = []any{"AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="}
+		}))
+		require.NoError(t, err)
+		_, err = keylessVerifier(t, fixture.TrustedRoot, exact).VerifyBundle(content, bundle)
+		require.ErrorIs(t, err, ErrSigstoreTlogInvalid)
+	})
+
+	t.Run("checkpoint must be signed by the log", func(t *testing.T) {
+		t.Parallel()
+		bundle, err := ParseKeylessBundle(editBundle(t, signed, func(entry map[string]any) {
+			checkpoint := entry["inclusionProof"].(map[string]any)["checkpoint"].(map[string]any)
+			envelope := checkpoint["envelope"].(string)
+			checkpoint["envelope"] = strings.Replace(envelope, "rekor.example.com - 1\n", "rekor.example.com - 2\n", 1)
+		}))
+		require.NoError(t, err)
+		_, err = keylessVerifier(t, fixture.TrustedRoot, exact).VerifyBundle(content, bundle)
+		require.ErrorIs(t, err, ErrSigstoreTlogInvalid)
+	})
+
+	t.Run("certificate needs an SCT from a trusted CT log", func(t *testing.T) {
+		t.Parallel()
+		var root map[string]any
+		require.NoError(t, json.Unmarshal(fixture.TrustedRoot, &root))
+		delete(root, "ctlogs")
+		withoutCTLogs, err := json.Marshal(root)
+		require.NoError(t, err)
+
+		_, err = keylessVerifier(t, withoutCTLogs, exact).VerifyBundle(content, bundle)
+		require.ErrorIs(t, err, ErrInvalidSignature)
+		assert.ErrorContains(t, err, "SCT")
+	})
+}
+
+func TestSigstoreVerifier_VerifyBundle_PublicGood(t *testing.T) {
+	t.Parallel()
+
+	// A bundle from the public-good Sigstore instance, the provenance of
+	// sigstore-js 2.0.0 on npm, verified against the public-good
+	// trusted_root.json. Both come from the sigstore-go test data.
+	trustedRoot, err := os.ReadFile(filepath.Join("testdata", "sigstore", "trusted_root.json"))
+	require.NoError(t, err)
+	data, err := os.ReadFile(filepath.Join("testdata", "sigstore", "sigstore-js-2.0.0-provenance.sigstore.json"))
+	require.NoError(t, err)
+	bundle, err := ParseKeylessBundle(data)
+	require.NoError(t, err)
+
+	root, err := ParseSigstoreTrustedRoot(trustedRoot)
+	require.NoError(t, err)
+	assert.Contains(t, root.Authorities(), "https://fulcio.sigstore.dev")
+	assert.Contains(t, root.Logs(), "https://rekor.sigstore.dev")
+
+	// The provenance attests the npm tarball by its SHA512 digest.
+	digest, err := hex.DecodeString("46d4e2f74c4877316640000a6fdf8a8b59f1e0847667973e9859f774dd31b8f1e0937813b777fb66a2ac67d50540fe34640966eee9fc2ccca387082b4c85cd3c")
+	require.NoError(t, err)
+	const subject = "https://github.com/sigstore/sigstore-js/.github/workflows/release.yml@refs/heads/main"
+
+	identity := ExactSigstoreIdentity(testIssuer, subject)
+	identity.Name = "sigstore-js"
+	signer, err := keylessVerifier(t, trustedRoot, identity).verifyBundle(bundle, verify.WithArtifactDigest("sha512", digest))
+	require.NoError(t, err)
+	assert.Equal(t, "sigstore-js", signer.Name)
+	assert.Equal(t, testIssuer, signer.Issuer)
+	assert.Equal(t, subject, signer.Subject)
+	assert.Equal(t, int64(31821305), signer.LogIndex)
+	assert.Equal(t, time.Unix(1692374735, 0).UTC(), signer.SignedAt.UTC())
+
+	_, err = keylessVerifier(t, trustedRoot, ExactSigstoreIdentity(testIssuer, "https://github.com/evil/sigstore-js/.github/workflows/release.yml@refs/heads/main")).
+		verifyBundle(bundle, verify.WithArtifactDigest("sha512", digest))
+	require.ErrorIs(t, err, ErrSigstoreIdentityMismatch)
+
+	digest[0] ^= 1
+	_, err = keylessVerifier(t, trustedRoot, identity).verifyBundle(bundle, verify.WithArtifactDigest("sha512", digest))
+	require.ErrorIs(t, err, ErrInvalidSignature)
+
+	// The fixture's trusted root does not trust the public-good log.
+	_, err = keylessVerifier(t, testutil.NewSigstoreFixture(t).TrustedRoot, identity).
+		verifyBundle(bundle, verify.WithArtifactDigest("sha512", digest))
+	require.ErrorIs(t, err, ErrSigstoreTlogInvalid)
+}
+
+func TestParseKeylessBundle_Invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := ParseKeylessBundle([]byte(`{"verificationMaterial":{}}`))
+	require.ErrorIs(t, err, ErrSigstoreInvalidBundle)
+
+	_, err = ParseKeylessBundle([]byte(`not json`))
+	require.ErrorIs(t, err, ErrSigstoreInvalidBundle)
+}
+
+func TestParseSigstoreTrustedRoot_Invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := ParseSigstoreTrustedRoot([]byte(`{"tlogs":[],"certificateAuthorities":[]}`))
+	require.Error(t, err)
+}
+
+func TestSigstoreIdentityRegexp_Invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := SigstoreIdentityRegexp(testIssuer, "(")
+	require.Error(t, err)
+}
+
+func TestTrustStore_KeylessVerifier(t *testing.T) {
+	t.Parallel()
+
+	fixture := testutil.NewSigstoreFixture(t)
+	storePath := filepath.Join(t.TempDir(), "trust.json")
+	store := NewTrustStore(storePath)
+
+	_, err := store.KeylessVerifier()
+	require.ErrorIs(t, err, ErrSigstoreNoTrustedRoot)
+
+	_, err = store.ImportSigstoreRoot(fixture.TrustedRoot)
+	require.NoError(t, err)
+	require.NoError(t, store.Add(NewTrustedIdentity("acme-release", ExactSigstoreIdentity(testIssuer, testSubject))))
+	require.NoError(t, store.Save())
+
+	loaded := NewTrustStore(storePath)
+	require.NoError(t, loaded.Load())
+	key, ok := loaded.Get("acme-release")
+	require.True(t, ok)
+	assert.Equal(t, SignatureTypeSigstore, key.KeyType())
+	assert.Equal(t, ExactSigstoreIdentity(testIssuer, testSubject).SubjectRegexp, key.Identity().SubjectRegexp)
+
+	verifier, err := loaded.KeylessVerifier()
+	require.NoError(t, err)
+	content := []byte("package")
+	bundle, err := ParseKeylessBundle(fixture.Sign(t, content, testIssuer, testSubject))
+	require.NoError(t, err)
+	signer, err := verifier.VerifyBundle(content, bundle)
+	require.NoError(t, err)
+	assert.Equal(t, "acme-release", signer.Name)
+}
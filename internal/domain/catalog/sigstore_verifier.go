@@ -26,6 +26,9 @@ var (
 
 // SigstoreIdentity represents an OIDC identity pattern for verification.
 type SigstoreIdentity struct {
+	// Name identifies the identity, such as its trust store key ID.
+	Name string
+
 	// IssuerRegexp is a regex pattern for the OIDC issuer URL.
 	// Examples: "https://token.actions.githubusercontent.com", "https://accounts.google.com"
 	IssuerRegexp string
@@ -47,6 +50,10 @@ type SigstoreVerifierConfig struct {
 
 	// VerifyTimestamp determines if the signature timestamp should be verified.
 	VerifyTimestamp bool
+
+	// TrustedRoot holds the Fulcio CAs and Rekor logs that VerifyBundle
+	// checks keyless signatures against.
+	TrustedRoot *SigstoreTrustedRoot
 }
 
 // DefaultSigstoreVerifierConfig returns a config with common trusted identities.
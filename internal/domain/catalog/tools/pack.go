@@ -0,0 +1,291 @@
+package tools
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
+)
+
+// The knowledge pack is tools.yaml as published in the project repository,
+// with an ED25519 signature of its SHA256 digest in tools.yaml.sig.
+const (
+	// KnowledgePackURL is where the latest knowledge pack is published.
+	KnowledgePackURL = "https://raw.githubusercontent.com/felixgeelhaar/preflight/main/internal/domain/catalog/tools/tools.yaml"
+	// KnowledgePackStaleAfter is the age after which an installed pack, or
+	// the embedded copy when none is installed, is worth refreshing.
+	KnowledgePackStaleAfter = 30 * 24 * time.Hour
+
+	packFile      = "tools.yaml"
+	signatureFile = "tools.yaml.sig"
+	maxPackSize   = 4 << 20 // 4MB
+)
+
+// knowledgePackKey verifies knowledge packs. It is the base64 public half
+// of the project's knowledge pack signing key, whose private half is held
+// only as the KNOWLEDGE_PACK_SIGNING_KEY secret of the knowledge-pack
+// workflow. It is empty until a maintainer pins it as described in
+// "Updating the Tool Knowledge Base" in CONTRIBUTING.md; until then no
+// downloaded pack is trusted and the embedded copy stays in use.
+const knowledgePackKey = ""
+
+// Knowledge pack errors.
+var (
+	ErrInvalidPackSignature = errors.New("knowledge pack signature is invalid")
+	ErrPackNotNewer         = errors.New("knowledge pack is not newer than the one in use")
+	ErrPackSigningKey       = errors.New("signing key is not the knowledge pack key")
+	ErrNoPackKey            = errors.New("no knowledge pack key is pinned in this build")
+)
+
+// PackInfo describes the knowledge base in use.
+type PackInfo struct {
+	// Version is the date-based version of the knowledge base, e.g. "2026.10.16".
+	Version string
+	// Embedded is set when the copy built into the binary is in use.
+	Embedded bool
+	// UpdatedAt is when the installed pack was downloaded; zero for the
+	// embedded copy.
+	UpdatedAt time.Time
+}
+
+// Stale reports whether the knowledge base is due for a refresh at now.
+func (i PackInfo) Stale(now time.Time) bool {
+	if i.Embedded {
+		// The embedded version is a date; fall back to never stale if not.
+		built, err := time.Parse("2006.01.02", i.Version)
+		return err == nil && now.Sub(built) > KnowledgePackStaleAfter
+	}
+	return now.Sub(i.UpdatedAt) > KnowledgePackStaleAfter
+}
+
+// DefaultPackDir returns the directory knowledge packs are installed in.
+func DefaultPackDir() string {
+	dir, _ := platform.Path(platform.CacheDir, "kb")
+	return dir
+}
+
+// KnowledgePackUpdatesAvailable reports whether this build pins a knowledge
+// pack key, without which downloaded packs cannot be verified.
+func KnowledgePackUpdatesAvailable() bool {
+	return knowledgePackKey != ""
+}
+
+// LoadInstalledKnowledgeBase loads the knowledge pack installed in dir when
+// its signature verifies and it is newer than the embedded copy, and the
+// embedded copy otherwise.
+func LoadInstalledKnowledgeBase(dir string) (KnowledgeBase, PackInfo, error) {
+	return loadInstalled(dir, knowledgePackKey)
+}
+
+func loadInstalled(dir, publicKey string) (KnowledgeBase, PackInfo, error) {
+	embedded, err := embeddedFS.ReadFile(packFile)
+	if err != nil {
+		return nil, PackInfo{}, fmt.Errorf("failed to read embedded tools.yaml: %w", err)
+	}
+	embeddedVersion := packVersion(embedded)
+
+	if data, updatedAt, err := readInstalledPack(dir, publicKey); err == nil && packVersion(data) > embeddedVersion {
+		if kb, err := ParseKnowledgeBase(data); err == nil {
+			return kb, PackInfo{Version: packVersion(data), UpdatedAt: updatedAt}, nil
+		}
+	}
+
+	kb, err := ParseKnowledgeBase(embedded)
+	if err != nil {
+		return nil, PackInfo{}, err
+	}
+	return kb, PackInfo{Version: embeddedVersion, Embedded: true}, nil
+}
+
+// readInstalledPack reads and verifies the pack in dir.
+func readInstalledPack(dir, publicKey string) ([]byte, time.Time, error) {
+	path := filepath.Join(dir, packFile)
+	// #nosec G304 -- the pack directory is under ~/.preflight.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	// #nosec G304 -- the pack directory is under ~/.preflight.
+	signature, err := os.ReadFile(filepath.Join(dir, signatureFile))
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if err := verifyPack(data, string(signature), publicKey); err != nil {
+		return nil, time.Time{}, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return data, info.ModTime(), nil
+}
+
+// VerifyKnowledgePack checks the signature of a knowledge pack.
+func VerifyKnowledgePack(data []byte, signature string) error {
+	return verifyPack(data, signature, knowledgePackKey)
+}
+
+// SignKnowledgePack signs a knowledge pack, returning the contents of its
+// tools.yaml.sig. The key must be the private half of the knowledge pack key.
+func SignKnowledgePack(data []byte, key ed25519.PrivateKey) (string, error) {
+	return signPack(data, key, knowledgePackKey)
+}
+
+// KnowledgePackPublicKey returns the public half of key in the form the
+// knowledge pack key is pinned in.
+func KnowledgePackPublicKey(key ed25519.PrivateKey) string {
+	public, _ := key.Public().(ed25519.PublicKey)
+	return base64.StdEncoding.EncodeToString(public)
+}
+
+func signPack(data []byte, key ed25519.PrivateKey, publicKey string) (string, error) {
+	if publicKey == "" {
+		return "", ErrNoPackKey
+	}
+	if KnowledgePackPublicKey(key) != publicKey {
+		return "", ErrPackSigningKey
+	}
+	hash := sha256.Sum256(data)
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(key, hash[:])), nil
+}
+
+func verifyPack(data []byte, signature, publicKey string) error {
+	if publicKey == "" {
+		return ErrNoPackKey
+	}
+	key, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("%w: bad public key", ErrInvalidPackSignature)
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(signature))
+	if err != nil {
+		return ErrInvalidPackSignature
+	}
+	hash := sha256.Sum256(data)
+	if !ed25519.Verify(ed25519.PublicKey(key), hash[:], sig) {
+		return ErrInvalidPackSignature
+	}
+	return nil
+}
+
+// packVersion returns the version of a knowledge pack, or "" when it has
+// none. Versions are dates (YYYY.MM.DD) and compare as strings.
+func packVersion(data []byte) string {
+	var header struct {
+		Version string `yaml:"version"`
+	}
+	_ = yaml.Unmarshal(data, &header)
+	return header.Version
+}
+
+// PackUpdater downloads and installs knowledge packs.
+type PackUpdater struct {
+	client    *http.Client
+	url       string
+	dir       string
+	publicKey string
+}
+
+// NewPackUpdater creates an updater that installs the pack published at
+// url into dir.
+func NewPackUpdater(url, dir string) *PackUpdater {
+	return &PackUpdater{
+		client:    &http.Client{Timeout: 30 * time.Second},
+		url:       url,
+		dir:       dir,
+		publicKey: knowledgePackKey,
+	}
+}
+
+// Update downloads the pack and its signature, verifies them and installs
+// the pack when it is newer than the knowledge base in use. It returns the
+// version installed, ErrPackNotNewer with the current version, or
+// ErrNoPackKey when no knowledge pack key is pinned.
+func (u *PackUpdater) Update(ctx context.Context) (string, error) {
+	if u.publicKey == "" {
+		return "", ErrNoPackKey
+	}
+	data, err := u.fetch(ctx, u.url)
+	if err != nil {
+		return "", err
+	}
+	signature, err := u.fetch(ctx, u.url+".sig")
+	if err != nil {
+		return "", err
+	}
+	if err := verifyPack(data, string(signature), u.publicKey); err != nil {
+		return "", err
+	}
+	if _, err := ParseKnowledgeBase(data); err != nil {
+		return "", err
+	}
+
+	_, current, err := loadInstalled(u.dir, u.publicKey)
+	if err != nil {
+		return "", err
+	}
+	version := packVersion(data)
+	if version <= current.Version {
+		return current.Version, ErrPackNotNewer
+	}
+
+	if err := os.MkdirAll(u.dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", u.dir, err)
+	}
+	// Write the signature first: a pack without a matching signature is
+	// ignored, so an interrupted update falls back to the embedded copy.
+	if err := writeFileAtomic(filepath.Join(u.dir, signatureFile), signature); err != nil {
+		return "", err
+	}
+	if err := writeFileAtomic(filepath.Join(u.dir, packFile), data); err != nil {
+		return "", err
+	}
+	return version, nil
+}
+
+func (u *PackUpdater) fetch(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPackSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	if len(data) > maxPackSize {
+		return nil, fmt.Errorf("%s is larger than %d bytes", url, maxPackSize)
+	}
+	return data, nil
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
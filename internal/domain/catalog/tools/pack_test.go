@@ -0,0 +1,187 @@
+package tools
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPack = `version: "2999.01.01"
+tools:
+  newtool:
+    category: test
+`
+
+func newTestKey(t *testing.T) (string, ed25519.PrivateKey) {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(public), private
+}
+
+func signTestPack(key ed25519.PrivateKey, data []byte) string {
+	hash := sha256.Sum256(data)
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(key, hash[:]))
+}
+
+func TestLoadInstalledKnowledgeBase_Embedded(t *testing.T) {
+	t.Parallel()
+
+	kb, info, err := LoadInstalledKnowledgeBase(t.TempDir())
+	require.NoError(t, err)
+	assert.True(t, info.Embedded)
+	assert.Regexp(t, `^\d{4}\.\d{2}\.\d{2}$`, info.Version)
+	_, found := kb.GetTool("trivy")
+	assert.True(t, found)
+}
+
+func TestLoadInstalledKnowledgeBase_Installed(t *testing.T) {
+	t.Parallel()
+
+	publicKey, privateKey := newTestKey(t)
+
+	t.Run("uses a verified newer pack", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, packFile), []byte(testPack), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, signatureFile), []byte(signTestPack(privateKey, []byte(testPack))), 0o600))
+
+		kb, info, err := loadInstalled(dir, publicKey)
+		require.NoError(t, err)
+		assert.False(t, info.Embedded)
+		assert.Equal(t, "2999.01.01", info.Version)
+		_, found := kb.GetTool("newtool")
+		assert.True(t, found)
+	})
+
+	t.Run("ignores a pack with a bad signature", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		tampered := testPack + "  extra:\n    category: test\n"
+		require.NoError(t, os.WriteFile(filepath.Join(dir, packFile), []byte(tampered), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, signatureFile), []byte(signTestPack(privateKey, []byte(testPack))), 0o600))
+
+		_, info, err := loadInstalled(dir, publicKey)
+		require.NoError(t, err)
+		assert.True(t, info.Embedded)
+	})
+
+	t.Run("ignores an older pack", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		old := "version: \"2000.01.01\"\ntools: {}\n"
+		require.NoError(t, os.WriteFile(filepath.Join(dir, packFile), []byte(old), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, signatureFile), []byte(signTestPack(privateKey, []byte(old))), 0o600))
+
+		_, info, err := loadInstalled(dir, publicKey)
+		require.NoError(t, err)
+		assert.True(t, info.Embedded)
+	})
+}
+
+func TestPackUpdater_Update(t *testing.T) {
+	t.Parallel()
+
+	publicKey, privateKey := newTestKey(t)
+	signature := signTestPack(privateKey, []byte(testPack))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tools.yaml":
+			_, _ = w.Write([]byte(testPack))
+		case "/tools.yaml.sig":
+			_, _ = w.Write([]byte(signature))
+		case "/bad/tools.yaml":
+			_, _ = w.Write([]byte(testPack))
+		case "/bad/tools.yaml.sig":
+			_, _ = w.Write([]byte(signTestPack(privateKey, []byte("other"))))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	updater := NewPackUpdater(server.URL+"/tools.yaml", dir)
+	updater.publicKey = publicKey
+
+	version, err := updater.Update(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "2999.01.01", version)
+	_, info, err := loadInstalled(dir, publicKey)
+	require.NoError(t, err)
+	assert.Equal(t, "2999.01.01", info.Version)
+
+	version, err = updater.Update(context.Background())
+	require.ErrorIs(t, err, ErrPackNotNewer)
+	assert.Equal(t, "2999.01.01", version)
+
+	bad := NewPackUpdater(server.URL+"/bad/tools.yaml", t.TempDir())
+	bad.publicKey = publicKey
+	_, err = bad.Update(context.Background())
+	require.ErrorIs(t, err, ErrInvalidPackSignature)
+
+	missing := NewPackUpdater(server.URL+"/missing/tools.yaml", t.TempDir())
+	missing.publicKey = publicKey
+	_, err = missing.Update(context.Background())
+	assert.ErrorContains(t, err, "404")
+
+	// Without a pinned key nothing is downloaded or installed.
+	unpinned := NewPackUpdater(server.URL+"/tools.yaml", t.TempDir())
+	unpinned.publicKey = ""
+	_, err = unpinned.Update(context.Background())
+	require.ErrorIs(t, err, ErrNoPackKey)
+	assert.NoFileExists(t, filepath.Join(unpinned.dir, packFile))
+}
+
+func TestLoadInstalledKnowledgeBase_NoPackKey(t *testing.T) {
+	t.Parallel()
+
+	_, privateKey := newTestKey(t)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, packFile), []byte(testPack), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, signatureFile), []byte(signTestPack(privateKey, []byte(testPack))), 0o600))
+
+	_, info, err := loadInstalled(dir, "")
+	require.NoError(t, err)
+	assert.True(t, info.Embedded, "a pack cannot be trusted without a pinned key")
+	require.ErrorIs(t, verifyPack([]byte(testPack), signTestPack(privateKey, []byte(testPack)), ""), ErrNoPackKey)
+}
+
+func TestPackInfo_Stale(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)
+	assert.True(t, PackInfo{Version: "2026.10.01", Embedded: true}.Stale(now))
+	assert.False(t, PackInfo{Version: "2026.11.20", Embedded: true}.Stale(now))
+	assert.False(t, PackInfo{Version: "2026.10.01", UpdatedAt: now.Add(-24 * time.Hour)}.Stale(now))
+	assert.True(t, PackInfo{Version: "2026.11.30", UpdatedAt: now.Add(-60 * 24 * time.Hour)}.Stale(now))
+}
+
+func TestSignKnowledgePack(t *testing.T) {
+	t.Parallel()
+
+	publicKey, privateKey := newTestKey(t)
+	assert.Equal(t, publicKey, KnowledgePackPublicKey(privateKey))
+
+	signature, err := signPack([]byte(testPack), privateKey, publicKey)
+	require.NoError(t, err)
+	require.NoError(t, verifyPack([]byte(testPack), signature+"\n", publicKey))
+
+	// Only the knowledge pack key may sign packs.
+	otherKey, _ := newTestKey(t)
+	_, err = signPack([]byte(testPack), privateKey, otherKey)
+	require.ErrorIs(t, err, ErrPackSigningKey)
+	_, err = signPack([]byte(testPack), privateKey, "")
+	require.ErrorIs(t, err, ErrNoPackKey)
+}
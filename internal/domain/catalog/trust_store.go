@@ -180,6 +180,58 @@ func (ts *TrustStore) StorePath() string {
 	return ts.storePath
 }
 
+// SigstoreRootPath returns the path of the Sigstore trusted root, kept
+// next to the trust store.
+func (ts *TrustStore) SigstoreRootPath() string {
+	return filepath.Join(filepath.Dir(ts.storePath), "sigstore_trusted_root.json")
+}
+
+// ImportSigstoreRoot validates a Sigstore trusted_root.json and saves it as
+// the trusted root for keyless verification.
+func (ts *TrustStore) ImportSigstoreRoot(data []byte) (*SigstoreTrustedRoot, error) {
+	root, err := ParseSigstoreTrustedRoot(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(ts.SigstoreRootPath()), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create trust store directory: %w", err)
+	}
+	if err := os.WriteFile(ts.SigstoreRootPath(), data, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write Sigstore trusted root: %w", err)
+	}
+	return root, nil
+}
+
+// SigstoreRoot loads the Sigstore trusted root, or returns
+// ErrSigstoreNoTrustedRoot when none was imported.
+func (ts *TrustStore) SigstoreRoot() (*SigstoreTrustedRoot, error) {
+	data, err := os.ReadFile(ts.SigstoreRootPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrSigstoreNoTrustedRoot
+		}
+		return nil, fmt.Errorf("failed to read Sigstore trusted root: %w", err)
+	}
+	return ParseSigstoreTrustedRoot(data)
+}
+
+// KeylessVerifier returns a verifier that trusts the keyless signatures of
+// the unexpired Sigstore identities in the store, checked against the
+// imported trusted root.
+func (ts *TrustStore) KeylessVerifier() (*SigstoreVerifier, error) {
+	root, err := ts.SigstoreRoot()
+	if err != nil {
+		return nil, err
+	}
+	config := SigstoreVerifierConfig{TrustedRoot: root}
+	for _, key := range ts.ListByType(SignatureTypeSigstore) {
+		if identity := key.Identity(); identity.IssuerRegexp != "" && !key.IsExpired() {
+			config.TrustedIdentities = append(config.TrustedIdentities, identity)
+		}
+	}
+	return NewSigstoreVerifier(config), nil
+}
+
 // trustedKeyJSON is the JSON representation of a trusted key.
 type trustedKeyJSON struct {
 	KeyID       string        `json:"key_id"`
@@ -190,6 +242,14 @@ type trustedKeyJSON struct {
 	AddedAt     time.Time     `json:"added_at"`
 	ExpiresAt   *time.Time    `json:"expires_at,omitempty"`
 	Comment     string        `json:"comment,omitempty"`
+	Identity    *identityJSON `json:"identity,omitempty"`
+}
+
+// identityJSON is the OIDC identity of a Sigstore key, as anchored regular
+// expressions for the issuer and subject.
+type identityJSON struct {
+	IssuerRegexp  string `json:"issuer_regexp"`
+	SubjectRegexp string `json:"subject_regexp"`
 }
 
 type publisherJSON struct {
@@ -244,6 +304,9 @@ func (ts *TrustStore) Save() error {
 			expires := key.ExpiresAt()
 			keyJSON.ExpiresAt = &expires
 		}
+		if identity := key.Identity(); identity.IssuerRegexp != "" {
+			keyJSON.Identity = &identityJSON{IssuerRegexp: identity.IssuerRegexp, SubjectRegexp: identity.SubjectRegexp}
+		}
 		store.Keys = append(store.Keys, keyJSON)
 	}
 
@@ -304,6 +367,13 @@ func (ts *TrustStore) Load() error {
 		if keyJSON.ExpiresAt != nil {
 			key.expiresAt = *keyJSON.ExpiresAt
 		}
+		if keyJSON.Identity != nil {
+			key.identity = SigstoreIdentity{
+				Name:          keyJSON.KeyID,
+				IssuerRegexp:  keyJSON.Identity.IssuerRegexp,
+				SubjectRegexp: keyJSON.Identity.SubjectRegexp,
+			}
+		}
 
 		ts.keys[key.KeyID()] = key
 	}
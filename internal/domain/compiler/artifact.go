@@ -0,0 +1,19 @@
+package compiler
+
+// ArtifactInfo describes a downloaded artifact and the checksum used to verify it.
+type ArtifactInfo struct {
+	Name     string
+	Source   string
+	Checksum string
+}
+
+// Verified reports whether the artifact is pinned to a checksum.
+func (a ArtifactInfo) Verified() bool {
+	return a.Checksum != ""
+}
+
+// VerifiableStep exposes downloaded artifact information so installs
+// without checksum verification can be surfaced by doctor.
+type VerifiableStep interface {
+	ArtifactInfo() (ArtifactInfo, bool)
+}
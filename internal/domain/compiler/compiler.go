@@ -4,10 +4,14 @@ package compiler
 
 import (
 	"fmt"
+	"sync"
 )
 
 // Compiler orchestrates providers to build a StepGraph from configuration.
+// Providers may be registered and unregistered while the compiler is in use,
+// e.g. when plugins are reloaded.
 type Compiler struct {
+	mu        sync.RWMutex
 	providers []Provider
 }
 
@@ -21,12 +25,32 @@ func NewCompiler() *Compiler {
 // RegisterProvider adds a provider to the compiler.
 // Providers are called in registration order during compilation.
 func (c *Compiler) RegisterProvider(provider Provider) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.providers = append(c.providers, provider)
 }
 
+// UnregisterProvider removes the provider registered under name.
+// Returns false if no such provider is registered.
+func (c *Compiler) UnregisterProvider(name string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, p := range c.providers {
+		if p.Name() == name {
+			c.providers = append(c.providers[:i:i], c.providers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
 // Providers returns all registered providers.
 func (c *Compiler) Providers() []Provider {
-	return c.providers
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	providers := make([]Provider, len(c.providers))
+	copy(providers, c.providers)
+	return providers
 }
 
 // Compile transforms configuration into a validated StepGraph.
@@ -46,7 +70,7 @@ func (c *Compiler) CompileWithContext(ctx CompileContext) (*StepGraph, error) {
 	graph := NewStepGraph()
 
 	// Compile each provider
-	for _, provider := range c.providers {
+	for _, provider := range c.Providers() {
 		steps, err := provider.Compile(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("provider %q: %w", provider.Name(), err)
@@ -38,6 +38,25 @@ func TestCompiler_RegisterMultipleProviders(t *testing.T) {
 	}
 }
 
+func TestCompiler_UnregisterProvider(t *testing.T) {
+	c := NewCompiler()
+	c.RegisterProvider(newMockProvider("brew"))
+	c.RegisterProvider(newMockProvider("apt"))
+	c.RegisterProvider(newMockProvider("files"))
+
+	if !c.UnregisterProvider("apt") {
+		t.Fatal("UnregisterProvider(apt) = false, want true")
+	}
+	if c.UnregisterProvider("apt") {
+		t.Error("UnregisterProvider(apt) twice = true, want false")
+	}
+
+	providers := c.Providers()
+	if len(providers) != 2 || providers[0].Name() != "brew" || providers[1].Name() != "files" {
+		t.Errorf("Providers() = %v, want [brew files]", providers)
+	}
+}
+
 func TestCompiler_Compile_Empty(t *testing.T) {
 	c := NewCompiler()
 	config := map[string]interface{}{}
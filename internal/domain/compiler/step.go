@@ -53,3 +53,40 @@ func AsRollbackable(step Step) RollbackableStep {
 	}
 	return nil
 }
+
+// BatchableStep is implemented by steps that can be applied together with
+// other steps in one command, such as several formulae in a single
+// brew install. The executor applies contiguous steps of a plan that share
+// a non-empty BatchKey with one ApplyBatch call on the first of them.
+type BatchableStep interface {
+	Step
+
+	// BatchKey identifies the steps that can be applied together. An empty
+	// key means the step is applied on its own.
+	BatchKey() string
+
+	// ApplyBatch applies steps, the receiver among them, and returns one
+	// error per step: nil for the steps that were applied.
+	ApplyBatch(ctx RunContext, steps []Step) []error
+}
+
+// ManualStep is implemented by steps whose Apply cannot make the change on
+// the current platform. ManualFix returns instructions for making it by
+// hand, or an empty string when Apply can make it.
+type ManualStep interface {
+	ManualFix() string
+}
+
+// PolicyValuesStep is implemented by steps that expose the values they
+// configure, such as a DNS server, so org policies can require or forbid
+// them alongside step IDs.
+type PolicyValuesStep interface {
+	PolicyValues() []string
+}
+
+// UninstallableStep is implemented by steps that install a package and can
+// remove it again. UninstallCommand returns the command and arguments that
+// remove the package, used to undo an apply that installed it.
+type UninstallableStep interface {
+	UninstallCommand() []string
+}
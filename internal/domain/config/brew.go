@@ -0,0 +1,19 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidBrewArch is returned when a layer constrains a formula to an
+// unknown architecture.
+var ErrInvalidBrewArch = errors.New("invalid brew arch")
+
+func (p *BrewPackages) normalize() error {
+	for name, arch := range p.Arch {
+		if arch != "arm64" && arch != "x86_64" {
+			return fmt.Errorf("%w: %s must be arm64 or x86_64, got %q", ErrInvalidBrewArch, name, arch)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLayer_BrewArch(t *testing.T) {
+	t.Parallel()
+
+	layer, err := ParseLayer([]byte(`
+name: intel
+packages:
+  brew:
+    formulae: [node, legacy-tool]
+    arch:
+      legacy-tool: x86_64
+`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"legacy-tool": "x86_64"}, layer.Packages.Brew.Arch)
+
+	_, err = ParseLayer([]byte("name: intel\npackages:\n  brew:\n    arch:\n      legacy-tool: i386\n"))
+	require.ErrorIs(t, err, ErrInvalidBrewArch)
+}
+
+func TestMerger_Merge_BrewArch(t *testing.T) {
+	t.Parallel()
+
+	base := Layer{Packages: PackageSet{Brew: BrewPackages{
+		Formulae: []string{"legacy-tool", "node"},
+		Arch:     map[string]string{"legacy-tool": "x86_64", "node": "x86_64"},
+	}}}
+	work := Layer{Packages: PackageSet{Brew: BrewPackages{Arch: map[string]string{"node": "arm64"}}}}
+
+	merged, err := NewMerger().Merge([]Layer{base, work})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"legacy-tool": "x86_64", "node": "arm64"}, merged.Packages.Brew.Arch)
+
+	raw := merged.Raw()["brew"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"legacy-tool": "x86_64", "node": "arm64"}, raw["arch"])
+}
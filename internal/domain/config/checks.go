@@ -0,0 +1,50 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidChecksConfig is returned when a layer declares a health check
+// without a command.
+var ErrInvalidChecksConfig = errors.New("invalid checks config")
+
+// ChecksConfig declares health check commands per package, keyed by the
+// package name as the package manager reports it. They run after a canary
+// upgrade of the package, and a failing check rolls the upgrade back.
+type ChecksConfig map[string][]string
+
+// IsZero reports whether no checks are declared.
+func (c ChecksConfig) IsZero() bool {
+	return len(c) == 0
+}
+
+func (c ChecksConfig) normalize() error {
+	for name, commands := range c {
+		if len(commands) == 0 {
+			return fmt.Errorf("%w: %s has no commands", ErrInvalidChecksConfig, name)
+		}
+		for _, command := range commands {
+			if strings.TrimSpace(command) == "" {
+				return fmt.Errorf("%w: %s has an empty command", ErrInvalidChecksConfig, name)
+			}
+		}
+	}
+	return nil
+}
+
+// mergeChecks combines the checks of layers. Checks are keyed by package,
+// with later layers replacing earlier definitions.
+func mergeChecks(layers []Layer) ChecksConfig {
+	var merged ChecksConfig
+	for _, layer := range layers {
+		for name, commands := range layer.Checks {
+			if merged == nil {
+				merged = make(ChecksConfig)
+			}
+			merged[name] = commands
+		}
+	}
+	return merged
+}
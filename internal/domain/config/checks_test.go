@@ -0,0 +1,44 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLayer_Checks(t *testing.T) {
+	t.Parallel()
+
+	layer, err := ParseLayer([]byte(`
+name: base
+checks:
+  node:
+    - node --version
+    - npm --version
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"node --version", "npm --version"}, layer.Checks["node"])
+
+	for _, invalid := range []string{
+		"name: base\nchecks:\n  node: []\n",
+		"name: base\nchecks:\n  node: ['  ']\n",
+	} {
+		_, err = ParseLayer([]byte(invalid))
+		require.ErrorIs(t, err, ErrInvalidChecksConfig, invalid)
+	}
+}
+
+func TestMerger_Merge_Checks(t *testing.T) {
+	t.Parallel()
+
+	base := Layer{Checks: ChecksConfig{"node": {"node --version"}, "jq": {"jq --version"}}}
+	work := Layer{Checks: ChecksConfig{"node": {"node -e 'require(\"fs\")'"}}}
+
+	merged, err := NewMerger().Merge([]Layer{base, work})
+	require.NoError(t, err)
+	assert.Equal(t, ChecksConfig{
+		"node": {"node -e 'require(\"fs\")'"},
+		"jq":   {"jq --version"},
+	}, merged.Checks)
+}
@@ -0,0 +1,223 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidCloudConfig is returned when a layer declares a cloud CLI
+// profile that is incomplete or has credentials that are not secret
+// references.
+var ErrInvalidCloudConfig = errors.New("invalid cloud config")
+
+// AWSConfig declares AWS CLI profiles, SSO profiles and SSO sessions.
+type AWSConfig struct {
+	Profiles       []AWSProfile    `yaml:"profiles,omitempty"`
+	SSO            []AWSSSOProfile `yaml:"sso,omitempty"`
+	SSOSessions    []AWSSSOSession `yaml:"sso_sessions,omitempty"`
+	DefaultProfile string          `yaml:"default_profile,omitempty"`
+	DefaultRegion  string          `yaml:"default_region,omitempty"`
+}
+
+// AWSProfile is a named AWS CLI profile. Static keys are secret references.
+type AWSProfile struct {
+	Name            string `yaml:"name"`
+	Region          string `yaml:"region,omitempty"`
+	Output          string `yaml:"output,omitempty"`
+	AccessKeyRef    string `yaml:"access_key_ref,omitempty"`
+	SecretKeyRef    string `yaml:"secret_key_ref,omitempty"`
+	RoleArn         string `yaml:"role_arn,omitempty"`
+	SourceProfile   string `yaml:"source_profile,omitempty"`
+	MFASerial       string `yaml:"mfa_serial,omitempty"`
+	ExternalID      string `yaml:"external_id,omitempty"`
+	DurationSeconds int    `yaml:"duration_seconds,omitempty"`
+}
+
+// AWSSSOProfile is a profile that signs in through IAM Identity Center,
+// either with its own start URL or through a shared SSO session.
+type AWSSSOProfile struct {
+	ProfileName  string `yaml:"profile_name"`
+	SSOSession   string `yaml:"sso_session,omitempty"`
+	SSOStartURL  string `yaml:"sso_start_url,omitempty"`
+	SSORegion    string `yaml:"sso_region,omitempty"`
+	SSOAccountID string `yaml:"sso_account_id,omitempty"`
+	SSORoleName  string `yaml:"sso_role_name,omitempty"`
+	Region       string `yaml:"region,omitempty"`
+	Output       string `yaml:"output,omitempty"`
+}
+
+// AWSSSOSession is a named [sso-session] shared by SSO profiles.
+type AWSSSOSession struct {
+	Name               string `yaml:"name"`
+	StartURL           string `yaml:"sso_start_url"`
+	Region             string `yaml:"sso_region,omitempty"`
+	RegistrationScopes string `yaml:"sso_registration_scopes,omitempty"`
+}
+
+// GCloudConfig declares gcloud configurations and which one is active.
+type GCloudConfig struct {
+	Configurations []GCloudConfiguration `yaml:"configurations,omitempty"`
+	Active         string                `yaml:"active,omitempty"`
+}
+
+// GCloudConfiguration is a named gcloud configuration. With a key_ref, the
+// account is activated as a service account using a key from a secret backend.
+type GCloudConfiguration struct {
+	Name       string            `yaml:"name"`
+	Account    string            `yaml:"account,omitempty"`
+	Project    string            `yaml:"project,omitempty"`
+	Region     string            `yaml:"region,omitempty"`
+	Zone       string            `yaml:"zone,omitempty"`
+	Properties map[string]string `yaml:"properties,omitempty"`
+	KeyRef     string            `yaml:"key_ref,omitempty"`
+}
+
+// AzureConfig declares subscriptions the az CLI should have access to.
+type AzureConfig struct {
+	Accounts []AzureAccount `yaml:"accounts,omitempty"`
+}
+
+// AzureAccount is an Azure subscription, optionally signed in to as a
+// service principal whose certificate or secret is a secret reference.
+type AzureAccount struct {
+	Name                 string `yaml:"name"`
+	Subscription         string `yaml:"subscription,omitempty"`
+	Tenant               string `yaml:"tenant,omitempty"`
+	Default              bool   `yaml:"default,omitempty"`
+	ClientID             string `yaml:"client_id,omitempty"`
+	ClientSecretRef      string `yaml:"client_secret_ref,omitempty"`
+	ClientCertificateRef string `yaml:"client_certificate_ref,omitempty"`
+}
+
+// IsZero reports whether no AWS configuration is declared.
+func (c AWSConfig) IsZero() bool {
+	return len(c.Profiles) == 0 && len(c.SSO) == 0 && len(c.SSOSessions) == 0 &&
+		c.DefaultProfile == "" && c.DefaultRegion == ""
+}
+
+// IsZero reports whether no gcloud configuration is declared.
+func (c GCloudConfig) IsZero() bool {
+	return len(c.Configurations) == 0 && c.Active == ""
+}
+
+// IsZero reports whether no Azure configuration is declared.
+func (c AzureConfig) IsZero() bool {
+	return len(c.Accounts) == 0
+}
+
+func (c *AWSConfig) normalize() error {
+	for _, p := range c.Profiles {
+		if p.Name == "" {
+			return fmt.Errorf("%w: aws profile without a name", ErrInvalidCloudConfig)
+		}
+		if err := requireSecretRef("aws profile "+p.Name, p.AccessKeyRef, p.SecretKeyRef); err != nil {
+			return err
+		}
+	}
+	for _, s := range c.SSO {
+		if s.ProfileName == "" {
+			return fmt.Errorf("%w: aws sso profile without a profile_name", ErrInvalidCloudConfig)
+		}
+	}
+	for _, s := range c.SSOSessions {
+		if s.Name == "" || s.StartURL == "" {
+			return fmt.Errorf("%w: aws sso session needs a name and sso_start_url", ErrInvalidCloudConfig)
+		}
+	}
+	return nil
+}
+
+func (c *GCloudConfig) normalize() error {
+	for _, g := range c.Configurations {
+		if g.Name == "" {
+			return fmt.Errorf("%w: gcloud configuration without a name", ErrInvalidCloudConfig)
+		}
+		if g.KeyRef != "" && g.Account == "" {
+			return fmt.Errorf("%w: gcloud configuration %s has a key_ref but no account", ErrInvalidCloudConfig, g.Name)
+		}
+		if err := requireSecretRef("gcloud configuration "+g.Name, g.KeyRef); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *AzureConfig) normalize() error {
+	for _, a := range c.Accounts {
+		if a.Name == "" {
+			return fmt.Errorf("%w: azure account without a name", ErrInvalidCloudConfig)
+		}
+		if err := requireSecretRef("azure account "+a.Name, a.ClientSecretRef, a.ClientCertificateRef); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// requireSecretRef rejects credentials that are not secret references.
+func requireSecretRef(owner string, refs ...string) error {
+	for _, ref := range refs {
+		if ref != "" && !strings.HasPrefix(ref, "secret://") {
+			return fmt.Errorf("%w: %s credentials must be secret references (secret://backend/key)", ErrInvalidCloudConfig, owner)
+		}
+	}
+	return nil
+}
+
+// mergeCloud combines the aws, gcloud and azure sections of layers.
+// Profiles, sessions, configurations and accounts are keyed by name, with
+// later layers replacing earlier definitions in place; scalars are last-wins.
+func mergeCloud(layers []Layer) (AWSConfig, GCloudConfig, AzureConfig) {
+	var aws AWSConfig
+	var gcloud GCloudConfig
+	var azure AzureConfig
+	for _, layer := range layers {
+		aws.Profiles = mergeNamed(aws.Profiles, layer.AWS.Profiles, func(p AWSProfile) string { return p.Name })
+		aws.SSO = mergeNamed(aws.SSO, layer.AWS.SSO, func(p AWSSSOProfile) string { return p.ProfileName })
+		aws.SSOSessions = mergeNamed(aws.SSOSessions, layer.AWS.SSOSessions, func(s AWSSSOSession) string { return s.Name })
+		if layer.AWS.DefaultProfile != "" {
+			aws.DefaultProfile = layer.AWS.DefaultProfile
+		}
+		if layer.AWS.DefaultRegion != "" {
+			aws.DefaultRegion = layer.AWS.DefaultRegion
+		}
+
+		gcloud.Configurations = mergeNamed(gcloud.Configurations, layer.GCloud.Configurations, func(c GCloudConfiguration) string { return c.Name })
+		if layer.GCloud.Active != "" {
+			gcloud.Active = layer.GCloud.Active
+		}
+
+		azure.Accounts = mergeNamed(azure.Accounts, layer.Azure.Accounts, func(a AzureAccount) string { return a.Name })
+	}
+	return aws, gcloud, azure
+}
+
+// mergeNamed appends src to dst, replacing entries of dst with the same key.
+func mergeNamed[T any](dst, src []T, key func(T) string) []T {
+	for _, item := range src {
+		replaced := false
+		for i := range dst {
+			if key(dst[i]) == key(item) {
+				dst[i] = item
+				replaced = true
+			}
+		}
+		if !replaced {
+			dst = append(dst, item)
+		}
+	}
+	return dst
+}
+
+// sectionRaw converts a typed section into the generic map providers parse
+// by round-tripping it through its YAML tags.
+func sectionRaw(section interface{}) map[string]interface{} {
+	raw := make(map[string]interface{})
+	if data, err := yaml.Marshal(section); err == nil {
+		_ = yaml.Unmarshal(data, &raw)
+	}
+	return raw
+}
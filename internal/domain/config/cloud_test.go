@@ -0,0 +1,84 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLayer_Cloud(t *testing.T) {
+	t.Parallel()
+
+	layer, err := ParseLayer([]byte(`
+name: base
+aws:
+  profiles:
+    - name: ci
+      access_key_ref: secret://1password/aws-ci-key
+      secret_key_ref: secret://1password/aws-ci-secret
+  sso_sessions:
+    - name: corp
+      sso_start_url: https://corp.awsapps.com/start
+gcloud:
+  configurations:
+    - name: work
+      project: acme-prod
+  active: work
+azure:
+  accounts:
+    - name: prod
+      tenant: contoso.onmicrosoft.com
+      default: true
+`))
+	require.NoError(t, err)
+	assert.Equal(t, "secret://1password/aws-ci-key", layer.AWS.Profiles[0].AccessKeyRef)
+	assert.Equal(t, "acme-prod", layer.GCloud.Configurations[0].Project)
+	assert.True(t, layer.Azure.Accounts[0].Default)
+
+	for _, invalid := range []string{
+		"name: base\naws:\n  profiles:\n    - name: ci\n      secret_key_ref: AKIAPLAINTEXT\n",
+		"name: base\naws:\n  sso_sessions:\n    - name: corp\n",
+		"name: base\ngcloud:\n  configurations:\n    - name: ci\n      key_ref: secret://vault/gcp\n",
+		"name: base\nazure:\n  accounts:\n    - name: ci\n      client_secret_ref: hunter2\n",
+	} {
+		_, err = ParseLayer([]byte(invalid))
+		require.ErrorIs(t, err, ErrInvalidCloudConfig, invalid)
+	}
+}
+
+func TestMerger_Merge_Cloud(t *testing.T) {
+	t.Parallel()
+
+	base := Layer{
+		AWS: AWSConfig{
+			Profiles:      []AWSProfile{{Name: "dev", Region: "eu-west-1"}, {Name: "prod", Region: "us-east-1"}},
+			DefaultRegion: "eu-west-1",
+		},
+		GCloud: GCloudConfig{Configurations: []GCloudConfiguration{{Name: "work", Project: "acme-dev"}}},
+	}
+	work := Layer{
+		AWS: AWSConfig{
+			Profiles:       []AWSProfile{{Name: "dev", Region: "eu-central-1", DurationSeconds: 3600}},
+			DefaultProfile: "dev",
+		},
+		GCloud: GCloudConfig{Active: "work"},
+		Azure:  AzureConfig{Accounts: []AzureAccount{{Name: "prod", Default: true}}},
+	}
+
+	merged, err := NewMerger().Merge([]Layer{base, work})
+	require.NoError(t, err)
+	assert.Equal(t, []AWSProfile{
+		{Name: "dev", Region: "eu-central-1", DurationSeconds: 3600},
+		{Name: "prod", Region: "us-east-1"},
+	}, merged.AWS.Profiles)
+	assert.Equal(t, "dev", merged.AWS.DefaultProfile)
+	assert.Equal(t, "eu-west-1", merged.AWS.DefaultRegion)
+	assert.Equal(t, "work", merged.GCloud.Active)
+
+	raw := merged.Raw()
+	aws := raw["aws"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"name": "dev", "region": "eu-central-1", "duration_seconds": 3600}, aws["profiles"].([]interface{})[0])
+	assert.Equal(t, map[string]interface{}{"name": "prod", "default": true}, raw["azure"].(map[string]interface{})["accounts"].([]interface{})[0])
+	assert.Equal(t, "work", raw["gcloud"].(map[string]interface{})["active"])
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidCronConfig is returned when a layer declares an incomplete
+// scheduled job.
+var ErrInvalidCronConfig = errors.New("invalid cron config")
+
+// CronConfig declares scheduled jobs. An explicitly empty job list is kept
+// so that apply removes every preflight-owned crontab entry.
+type CronConfig struct {
+	Jobs []CronJob `yaml:"jobs"`
+}
+
+// CronJob is a command run on a cron schedule, from the user crontab
+// (default) or a launchd agent.
+type CronJob struct {
+	Name     string `yaml:"name"`
+	Schedule string `yaml:"schedule"`
+	Command  string `yaml:"command"`
+	Backend  string `yaml:"backend,omitempty"`
+}
+
+// IsZero reports whether no cron section is declared.
+func (c CronConfig) IsZero() bool {
+	return c.Jobs == nil
+}
+
+func (c *CronConfig) normalize() error {
+	seen := make(map[string]bool)
+	for i, job := range c.Jobs {
+		if job.Name == "" {
+			return fmt.Errorf("%w: job %d has no name", ErrInvalidCronConfig, i+1)
+		}
+		if seen[job.Name] {
+			return fmt.Errorf("%w: duplicate job %q", ErrInvalidCronConfig, job.Name)
+		}
+		seen[job.Name] = true
+		if job.Schedule == "" || job.Command == "" {
+			return fmt.Errorf("%w: job %q needs a schedule and a command", ErrInvalidCronConfig, job.Name)
+		}
+		switch job.Backend {
+		case "", "crontab", "launchd":
+		default:
+			return fmt.Errorf("%w: job %q has unknown backend %q (use crontab or launchd)", ErrInvalidCronConfig, job.Name, job.Backend)
+		}
+	}
+	return nil
+}
+
+// mergeCron combines the cron configuration of layers. Jobs are keyed by
+// name, with later layers replacing earlier definitions in place.
+func mergeCron(layers []Layer) CronConfig {
+	var merged CronConfig
+	for _, layer := range layers {
+		if layer.Cron.Jobs == nil {
+			continue
+		}
+		if merged.Jobs == nil {
+			merged.Jobs = []CronJob{}
+		}
+		merged.Jobs = mergeNamed(merged.Jobs, layer.Cron.Jobs, func(j CronJob) string { return j.Name })
+	}
+	return merged
+}
+
+// raw converts the cron configuration into the map the cron provider parses.
+func (c CronConfig) raw() map[string]interface{} {
+	jobs := make([]interface{}, 0, len(c.Jobs))
+	for _, job := range c.Jobs {
+		entry := map[string]interface{}{
+			"name":     job.Name,
+			"schedule": job.Schedule,
+			"command":  job.Command,
+		}
+		if job.Backend != "" {
+			entry["backend"] = job.Backend
+		}
+		jobs = append(jobs, entry)
+	}
+	return map[string]interface{}{"jobs": jobs}
+}
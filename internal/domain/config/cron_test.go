@@ -0,0 +1,71 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLayer_Cron(t *testing.T) {
+	t.Parallel()
+
+	layer, err := ParseLayer([]byte(`
+name: base
+cron:
+  jobs:
+    - name: brew-update
+      schedule: "0 9 * * 1"
+      command: brew update
+    - name: backup
+      schedule: "@daily"
+      command: restic backup ~/src
+      backend: launchd
+`))
+	require.NoError(t, err)
+	require.Len(t, layer.Cron.Jobs, 2)
+	assert.Equal(t, "launchd", layer.Cron.Jobs[1].Backend)
+
+	empty, err := ParseLayer([]byte("name: base\ncron:\n  jobs: []\n"))
+	require.NoError(t, err)
+	assert.False(t, empty.Cron.IsZero())
+
+	for _, invalid := range []string{
+		"name: base\ncron:\n  jobs:\n    - schedule: '@daily'\n      command: date\n",
+		"name: base\ncron:\n  jobs:\n    - name: a\n      command: date\n",
+		"name: base\ncron:\n  jobs:\n    - name: a\n      schedule: '@daily'\n      command: date\n      backend: systemd\n",
+	} {
+		_, err = ParseLayer([]byte(invalid))
+		require.ErrorIs(t, err, ErrInvalidCronConfig, invalid)
+	}
+}
+
+func TestMerger_Merge_Cron(t *testing.T) {
+	t.Parallel()
+
+	base := Layer{Cron: CronConfig{Jobs: []CronJob{
+		{Name: "brew-update", Schedule: "0 9 * * 1", Command: "brew update"},
+		{Name: "backup", Schedule: "@daily", Command: "restic backup"},
+	}}}
+	work := Layer{Cron: CronConfig{Jobs: []CronJob{
+		{Name: "brew-update", Schedule: "0 8 * * *", Command: "brew update", Backend: "launchd"},
+	}}}
+
+	merged, err := NewMerger().Merge([]Layer{base, work})
+	require.NoError(t, err)
+	assert.Equal(t, []CronJob{
+		{Name: "brew-update", Schedule: "0 8 * * *", Command: "brew update", Backend: "launchd"},
+		{Name: "backup", Schedule: "@daily", Command: "restic backup"},
+	}, merged.Cron.Jobs)
+
+	jobs := merged.Raw()["cron"].(map[string]interface{})["jobs"].([]interface{})
+	assert.Equal(t, map[string]interface{}{"name": "backup", "schedule": "@daily", "command": "restic backup"}, jobs[1])
+
+	cleared, err := NewMerger().Merge([]Layer{{Cron: CronConfig{Jobs: []CronJob{}}}})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"jobs": []interface{}{}}, cleared.Raw()["cron"])
+
+	none, err := NewMerger().Merge([]Layer{{}})
+	require.NoError(t, err)
+	assert.NotContains(t, none.Raw(), "cron")
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidDirenvDir is returned when a layer declares a direnv directory
+// without a path or with an invalid variable name.
+var ErrInvalidDirenvDir = errors.New("invalid direnv directory")
+
+var envVarName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// DirenvConfig declares project directories that get a generated .envrc,
+// so their environment is loaded by direnv when entering the directory.
+type DirenvConfig struct {
+	Dirs []DirenvDir `yaml:"dirs,omitempty"`
+}
+
+// DirenvDir is a project directory and its environment. Values may be
+// secret references (secret://backend/key), which are resolved by direnv
+// when the directory is entered rather than written to disk.
+type DirenvDir struct {
+	Path string            `yaml:"path"`
+	Env  map[string]string `yaml:"env,omitempty"`
+}
+
+func (c *DirenvConfig) normalize() error {
+	for i := range c.Dirs {
+		dir := &c.Dirs[i]
+		dir.Path = strings.TrimSpace(dir.Path)
+		if dir.Path == "" {
+			return fmt.Errorf("%w: entry %d has no path", ErrInvalidDirenvDir, i+1)
+		}
+		for name := range dir.Env {
+			if !envVarName.MatchString(name) {
+				return fmt.Errorf("%w: %s: invalid variable name %q", ErrInvalidDirenvDir, dir.Path, name)
+			}
+		}
+	}
+	return nil
+}
+
+// mergeDirenv combines the direnv directories of layers. Directories keep
+// the order in which they are first declared; their env maps are deep
+// merged with later layers winning per variable.
+func mergeDirenv(layers []Layer) DirenvConfig {
+	var merged DirenvConfig
+	index := make(map[string]int)
+	for _, layer := range layers {
+		for _, dir := range layer.Direnv.Dirs {
+			i, ok := index[dir.Path]
+			if !ok {
+				i = len(merged.Dirs)
+				index[dir.Path] = i
+				merged.Dirs = append(merged.Dirs, DirenvDir{Path: dir.Path, Env: make(map[string]string)})
+			}
+			for name, value := range dir.Env {
+				merged.Dirs[i].Env[name] = value
+			}
+		}
+	}
+	return merged
+}
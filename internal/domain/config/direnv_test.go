@@ -0,0 +1,51 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLayer_Direnv(t *testing.T) {
+	t.Parallel()
+
+	layer, err := ParseLayer([]byte(`
+name: work
+direnv:
+  dirs:
+    - path: ~/work/api
+      env:
+        DATABASE_URL: postgres://localhost/api
+        API_TOKEN: secret://1password/Work/api-token
+`))
+	require.NoError(t, err)
+	require.Len(t, layer.Direnv.Dirs, 1)
+	assert.Equal(t, "~/work/api", layer.Direnv.Dirs[0].Path)
+	assert.Equal(t, "secret://1password/Work/api-token", layer.Direnv.Dirs[0].Env["API_TOKEN"])
+
+	_, err = ParseLayer([]byte("name: work\ndirenv:\n  dirs:\n    - env: {A: b}\n"))
+	require.ErrorIs(t, err, ErrInvalidDirenvDir)
+	_, err = ParseLayer([]byte("name: work\ndirenv:\n  dirs:\n    - path: ~/api\n      env: {\"BAD-NAME\": b}\n"))
+	require.ErrorIs(t, err, ErrInvalidDirenvDir)
+}
+
+func TestMerger_Merge_Direnv(t *testing.T) {
+	t.Parallel()
+
+	base := Layer{Provenance: "layers/base.yaml", Direnv: DirenvConfig{Dirs: []DirenvDir{
+		{Path: "~/work/api", Env: map[string]string{"LOG_LEVEL": "info", "PORT": "8080"}},
+		{Path: "~/work/web", Env: map[string]string{"PORT": "3000"}},
+	}}}
+	work := Layer{Provenance: "layers/work.yaml", Direnv: DirenvConfig{Dirs: []DirenvDir{
+		{Path: "~/work/api", Env: map[string]string{"LOG_LEVEL": "debug"}},
+	}}}
+
+	merged, err := NewMerger().Merge([]Layer{base, work})
+	require.NoError(t, err)
+	assert.Equal(t, []DirenvDir{
+		{Path: "~/work/api", Env: map[string]string{"LOG_LEVEL": "debug", "PORT": "8080"}},
+		{Path: "~/work/web", Env: map[string]string{"PORT": "3000"}},
+	}, merged.Direnv.Dirs)
+	assert.Equal(t, "layers/work.yaml", merged.GetProvenance("direnv.dirs", "~/work/api"))
+}
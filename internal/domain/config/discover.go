@@ -0,0 +1,59 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// ManifestFileName is the file name of a preflight manifest.
+const ManifestFileName = "preflight.yaml"
+
+// FindManifest returns the preflight.yaml in dir or in the nearest parent
+// directory that has one, and false when none does.
+func FindManifest(dir string) (string, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	for {
+		path := filepath.Join(dir, ManifestFileName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// ManifestPath returns path, or the preflight.yaml in it when path is a
+// directory.
+func ManifestPath(path string) string {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return filepath.Join(path, ManifestFileName)
+	}
+	return path
+}
+
+// ManifestsIn returns the names of the subdirectories of dir that hold a
+// preflight.yaml: the configs of a config directory, sorted.
+func ManifestsIn(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, entry.Name(), ManifestFileName)); err == nil {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
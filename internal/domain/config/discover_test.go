@@ -0,0 +1,53 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindManifest_WalksUp(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	manifest := filepath.Join(root, config.ManifestFileName)
+	require.NoError(t, os.WriteFile(manifest, []byte("targets: {}\n"), 0o644))
+	nested := filepath.Join(root, "layers", "nested")
+	require.NoError(t, os.MkdirAll(nested, 0o755))
+
+	path, ok := config.FindManifest(nested)
+	require.True(t, ok)
+	assert.Equal(t, manifest, path)
+
+	path, ok = config.FindManifest(root)
+	require.True(t, ok)
+	assert.Equal(t, manifest, path)
+}
+
+func TestManifestPath(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	assert.Equal(t, filepath.Join(dir, config.ManifestFileName), config.ManifestPath(dir))
+	assert.Equal(t, "work.yaml", config.ManifestPath("work.yaml"))
+}
+
+func TestManifestsIn(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for _, name := range []string{"work", "personal"} {
+		require.NoError(t, os.Mkdir(filepath.Join(dir, name), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name, config.ManifestFileName), []byte("targets: {}\n"), 0o644))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "scratch"), 0o755))
+
+	assert.Equal(t, []string{"personal", "work"}, config.ManifestsIn(dir))
+
+	err := config.NewConfigNotFoundError(filepath.Join(dir, config.ManifestFileName))
+	assert.Equal(t, "Choose a config with --config: personal, work.", err.Suggestion)
+}
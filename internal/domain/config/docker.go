@@ -0,0 +1,222 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidDockerConfig is returned when a layer declares an unknown
+// container engine or an incomplete registry.
+var ErrInvalidDockerConfig = errors.New("invalid docker config")
+
+// DockerConfig declares the container runtime: which engine provides the
+// Docker API, its VM resources, daemon settings, registries and contexts.
+type DockerConfig struct {
+	Engine         string                 `yaml:"engine,omitempty"` // desktop, colima or podman
+	Install        *bool                  `yaml:"install,omitempty"`
+	BuildKit       *bool                  `yaml:"buildkit,omitempty"`
+	Kubernetes     bool                   `yaml:"kubernetes,omitempty"`
+	ResourceLimits *DockerResourceLimits  `yaml:"resource_limits,omitempty"`
+	Registries     []DockerRegistry       `yaml:"registries,omitempty"`
+	Contexts       []DockerContext        `yaml:"contexts,omitempty"`
+	Daemon         map[string]interface{} `yaml:"daemon,omitempty"`
+	CredsStore     string                 `yaml:"creds_store,omitempty"`
+}
+
+// DockerResourceLimits are the CPU, memory and disk given to the engine's VM.
+type DockerResourceLimits struct {
+	CPUs   int    `yaml:"cpus,omitempty"`
+	Memory string `yaml:"memory,omitempty"` // e.g., "8GB"
+	Swap   string `yaml:"swap,omitempty"`
+	Disk   string `yaml:"disk,omitempty"`
+}
+
+// DockerRegistry is a container registry. Registries with a password are
+// logged into; the password must be a secret reference.
+type DockerRegistry struct {
+	URL      string `yaml:"url"`
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"` // secret://backend/key
+	Insecure bool   `yaml:"insecure,omitempty"`
+	Mirror   bool   `yaml:"mirror,omitempty"`
+}
+
+// DockerContext is a Docker context for a remote host.
+type DockerContext struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+	Host        string `yaml:"host"`
+	Default     bool   `yaml:"default,omitempty"`
+}
+
+// IsZero reports whether no docker configuration is declared.
+func (c DockerConfig) IsZero() bool {
+	return c.Engine == "" && c.Install == nil && c.BuildKit == nil && !c.Kubernetes &&
+		c.ResourceLimits == nil && len(c.Registries) == 0 && len(c.Contexts) == 0 &&
+		len(c.Daemon) == 0 && c.CredsStore == ""
+}
+
+func (c *DockerConfig) normalize() error {
+	switch c.Engine {
+	case "", "desktop", "colima", "podman":
+	default:
+		return fmt.Errorf("%w: unknown engine %q (use desktop, colima or podman)", ErrInvalidDockerConfig, c.Engine)
+	}
+	for i, r := range c.Registries {
+		if r.URL == "" {
+			return fmt.Errorf("%w: registry %d has no url", ErrInvalidDockerConfig, i+1)
+		}
+		if r.Password != "" && !strings.HasPrefix(r.Password, "secret://") {
+			return fmt.Errorf("%w: registry %s password must be a secret reference (secret://backend/key)", ErrInvalidDockerConfig, r.URL)
+		}
+		if r.Password != "" && r.Username == "" {
+			return fmt.Errorf("%w: registry %s password requires a username", ErrInvalidDockerConfig, r.URL)
+		}
+	}
+	for i, ctx := range c.Contexts {
+		if ctx.Name == "" || ctx.Host == "" {
+			return fmt.Errorf("%w: context %d needs a name and a host", ErrInvalidDockerConfig, i+1)
+		}
+	}
+	return nil
+}
+
+// mergeDocker combines the docker configuration of layers. Scalars are
+// last-wins, resource limits and daemon settings are merged per field,
+// registries are keyed by URL and contexts by name, with later layers
+// replacing earlier definitions in place.
+func mergeDocker(layers []Layer) DockerConfig {
+	var merged DockerConfig
+	registryIndex := make(map[string]int)
+	contextIndex := make(map[string]int)
+
+	for _, layer := range layers {
+		d := layer.Docker
+		if d.Engine != "" {
+			merged.Engine = d.Engine
+		}
+		if d.Install != nil {
+			merged.Install = d.Install
+		}
+		if d.BuildKit != nil {
+			merged.BuildKit = d.BuildKit
+		}
+		if d.Kubernetes {
+			merged.Kubernetes = true
+		}
+		if d.CredsStore != "" {
+			merged.CredsStore = d.CredsStore
+		}
+		if l := d.ResourceLimits; l != nil {
+			if merged.ResourceLimits == nil {
+				merged.ResourceLimits = &DockerResourceLimits{}
+			}
+			if l.CPUs != 0 {
+				merged.ResourceLimits.CPUs = l.CPUs
+			}
+			if l.Memory != "" {
+				merged.ResourceLimits.Memory = l.Memory
+			}
+			if l.Swap != "" {
+				merged.ResourceLimits.Swap = l.Swap
+			}
+			if l.Disk != "" {
+				merged.ResourceLimits.Disk = l.Disk
+			}
+		}
+		merged.Daemon = mergeSettings(merged.Daemon, d.Daemon)
+		for _, r := range d.Registries {
+			if i, ok := registryIndex[r.URL]; ok {
+				merged.Registries[i] = r
+				continue
+			}
+			registryIndex[r.URL] = len(merged.Registries)
+			merged.Registries = append(merged.Registries, r)
+		}
+		for _, ctx := range d.Contexts {
+			if i, ok := contextIndex[ctx.Name]; ok {
+				merged.Contexts[i] = ctx
+				continue
+			}
+			contextIndex[ctx.Name] = len(merged.Contexts)
+			merged.Contexts = append(merged.Contexts, ctx)
+		}
+	}
+	return merged
+}
+
+// raw converts the docker configuration into the map the docker provider
+// parses.
+func (c DockerConfig) raw() map[string]interface{} {
+	docker := make(map[string]interface{})
+	if c.Engine != "" {
+		docker["engine"] = c.Engine
+	}
+	if c.Install != nil {
+		docker["install"] = *c.Install
+	}
+	if c.BuildKit != nil {
+		docker["buildkit"] = *c.BuildKit
+	}
+	if c.Kubernetes {
+		docker["kubernetes"] = true
+	}
+	if c.CredsStore != "" {
+		docker["creds_store"] = c.CredsStore
+	}
+	if l := c.ResourceLimits; l != nil {
+		limits := make(map[string]interface{})
+		if l.CPUs != 0 {
+			limits["cpus"] = l.CPUs
+		}
+		if l.Memory != "" {
+			limits["memory"] = l.Memory
+		}
+		if l.Swap != "" {
+			limits["swap"] = l.Swap
+		}
+		if l.Disk != "" {
+			limits["disk"] = l.Disk
+		}
+		docker["resource_limits"] = limits
+	}
+	if len(c.Daemon) > 0 {
+		docker["daemon"] = copySettings(c.Daemon)
+	}
+	if len(c.Registries) > 0 {
+		registries := make([]interface{}, 0, len(c.Registries))
+		for _, r := range c.Registries {
+			registry := map[string]interface{}{"url": r.URL}
+			if r.Username != "" {
+				registry["username"] = r.Username
+			}
+			if r.Password != "" {
+				registry["password"] = r.Password
+			}
+			if r.Insecure {
+				registry["insecure"] = true
+			}
+			if r.Mirror {
+				registry["mirror"] = true
+			}
+			registries = append(registries, registry)
+		}
+		docker["registries"] = registries
+	}
+	if len(c.Contexts) > 0 {
+		contexts := make([]interface{}, 0, len(c.Contexts))
+		for _, ctx := range c.Contexts {
+			context := map[string]interface{}{"name": ctx.Name, "host": ctx.Host}
+			if ctx.Description != "" {
+				context["description"] = ctx.Description
+			}
+			if ctx.Default {
+				context["default"] = true
+			}
+			contexts = append(contexts, context)
+		}
+		docker["contexts"] = contexts
+	}
+	return docker
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLayer_Docker(t *testing.T) {
+	t.Parallel()
+
+	layer, err := ParseLayer([]byte(`
+name: base
+docker:
+  engine: colima
+  resource_limits:
+    cpus: 4
+    memory: 8GB
+  registries:
+    - url: ghcr.io
+      username: octocat
+      password: secret://1password/ghcr-token
+`))
+	require.NoError(t, err)
+	assert.Equal(t, "colima", layer.Docker.Engine)
+	assert.Equal(t, 4, layer.Docker.ResourceLimits.CPUs)
+	assert.Equal(t, "ghcr.io", layer.Docker.Registries[0].URL)
+
+	_, err = ParseLayer([]byte("name: base\ndocker:\n  engine: lima\n"))
+	require.ErrorIs(t, err, ErrInvalidDockerConfig)
+	_, err = ParseLayer([]byte("name: base\ndocker:\n  registries:\n    - url: ghcr.io\n      username: u\n      password: hunter2\n"))
+	require.ErrorIs(t, err, ErrInvalidDockerConfig)
+	_, err = ParseLayer([]byte("name: base\ndocker:\n  registries:\n    - url: ghcr.io\n      password: secret://env/TOKEN\n"))
+	require.ErrorIs(t, err, ErrInvalidDockerConfig)
+}
+
+func TestMerger_Merge_Docker(t *testing.T) {
+	t.Parallel()
+
+	off := false
+	base := Layer{Docker: DockerConfig{
+		ResourceLimits: &DockerResourceLimits{CPUs: 2, Memory: "4GB"},
+		Registries:     []DockerRegistry{{URL: "ghcr.io"}, {URL: "mirror.local", Mirror: true}},
+		Daemon:         map[string]interface{}{"debug": true},
+	}}
+	work := Layer{Docker: DockerConfig{
+		Engine:         "podman",
+		BuildKit:       &off,
+		ResourceLimits: &DockerResourceLimits{Memory: "8GB"},
+		Registries:     []DockerRegistry{{URL: "ghcr.io", Username: "octocat", Password: "secret://env/GHCR"}},
+		Daemon:         map[string]interface{}{"log-level": "warn"},
+	}}
+
+	merged, err := NewMerger().Merge([]Layer{base, work})
+	require.NoError(t, err)
+	docker := merged.Docker
+	assert.Equal(t, "podman", docker.Engine)
+	assert.Equal(t, &DockerResourceLimits{CPUs: 2, Memory: "8GB"}, docker.ResourceLimits)
+	assert.Equal(t, []DockerRegistry{
+		{URL: "ghcr.io", Username: "octocat", Password: "secret://env/GHCR"},
+		{URL: "mirror.local", Mirror: true},
+	}, docker.Registries)
+	assert.Equal(t, map[string]interface{}{"debug": true, "log-level": "warn"}, docker.Daemon)
+
+	raw := merged.Raw()["docker"].(map[string]interface{})
+	assert.Equal(t, "podman", raw["engine"])
+	assert.Equal(t, false, raw["buildkit"])
+	assert.NotContains(t, raw, "install")
+	assert.Len(t, raw["registries"], 2)
+}
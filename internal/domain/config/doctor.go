@@ -0,0 +1,102 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ErrInvalidDoctorCheck is returned when a custom doctor check is invalid.
+var ErrInvalidDoctorCheck = errors.New("invalid doctor check")
+
+// doctorCheckSeverities are the severities a custom check can report.
+var doctorCheckSeverities = []string{"info", "warning", "error"}
+
+// DoctorConfig declares custom checks that doctor runs alongside its own,
+// so teams can encode invariants such as a VPN client being installed or
+// disk encryption being on.
+type DoctorConfig struct {
+	Checks []DoctorCheck `yaml:"checks,omitempty"`
+}
+
+// DoctorCheck is a command whose exit code, and optionally output, tell
+// whether an invariant holds. Command and Fix run with sh.
+type DoctorCheck struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+	Command     string `yaml:"command"`
+	// Exit is the expected exit code, 0 when unset.
+	Exit *int `yaml:"exit,omitempty"`
+	// Output is a regular expression the output must match.
+	Output string `yaml:"output,omitempty"`
+	// Severity is info, warning or error; a failing check is a warning
+	// when unset.
+	Severity string `yaml:"severity,omitempty"`
+	// Fix is a command that makes the check pass. It runs on doctor --fix.
+	Fix string `yaml:"fix,omitempty"`
+}
+
+// IsZero reports whether no checks are declared.
+func (c DoctorConfig) IsZero() bool {
+	return len(c.Checks) == 0
+}
+
+func (c DoctorConfig) normalize() error {
+	return ValidateDoctorChecks(c.Checks)
+}
+
+// ValidateDoctorChecks checks that every check has a unique name and a
+// command, a known severity and a valid output pattern.
+func ValidateDoctorChecks(checks []DoctorCheck) error {
+	seen := make(map[string]bool, len(checks))
+	for i, check := range checks {
+		if strings.TrimSpace(check.Name) == "" {
+			return fmt.Errorf("%w: check %d has no name", ErrInvalidDoctorCheck, i+1)
+		}
+		if seen[check.Name] {
+			return fmt.Errorf("%w: %s is declared twice", ErrInvalidDoctorCheck, check.Name)
+		}
+		seen[check.Name] = true
+		if strings.TrimSpace(check.Command) == "" {
+			return fmt.Errorf("%w: %s has no command", ErrInvalidDoctorCheck, check.Name)
+		}
+		if check.Severity != "" && !slices.Contains(doctorCheckSeverities, check.Severity) {
+			return fmt.Errorf("%w: %s has severity %q, want one of %s",
+				ErrInvalidDoctorCheck, check.Name, check.Severity, strings.Join(doctorCheckSeverities, ", "))
+		}
+		if check.Output != "" {
+			if _, err := regexp.Compile(check.Output); err != nil {
+				return fmt.Errorf("%w: %s output: %w", ErrInvalidDoctorCheck, check.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ExpectedExit returns the exit code the check expects.
+func (c DoctorCheck) ExpectedExit() int {
+	if c.Exit == nil {
+		return 0
+	}
+	return *c.Exit
+}
+
+// mergeDoctor combines the doctor checks of layers. A check declared by a
+// later layer replaces an earlier one with the same name in place.
+func mergeDoctor(layers []Layer) DoctorConfig {
+	var merged DoctorConfig
+	index := make(map[string]int)
+	for _, layer := range layers {
+		for _, check := range layer.Doctor.Checks {
+			if i, ok := index[check.Name]; ok {
+				merged.Checks[i] = check
+				continue
+			}
+			index[check.Name] = len(merged.Checks)
+			merged.Checks = append(merged.Checks, check)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLayer_Doctor(t *testing.T) {
+	t.Parallel()
+
+	layer, err := ParseLayer([]byte(`
+name: security
+doctor:
+  checks:
+    - name: filevault
+      description: Disk encryption is on
+      command: fdesetup status
+      output: FileVault is On
+      severity: error
+    - name: vpn
+      command: test -d /Applications/GlobalProtect.app
+      exit: 0
+      fix: brew install --cask globalprotect
+`))
+	require.NoError(t, err)
+	require.Len(t, layer.Doctor.Checks, 2)
+	assert.Equal(t, "FileVault is On", layer.Doctor.Checks[0].Output)
+	assert.Equal(t, "error", layer.Doctor.Checks[0].Severity)
+	assert.Equal(t, 0, layer.Doctor.Checks[0].ExpectedExit())
+	assert.Equal(t, "brew install --cask globalprotect", layer.Doctor.Checks[1].Fix)
+
+	for _, invalid := range []string{
+		"name: base\ndoctor:\n  checks:\n    - command: 'true'\n",
+		"name: base\ndoctor:\n  checks:\n    - name: vpn\n",
+		"name: base\ndoctor:\n  checks:\n    - {name: vpn, command: 'true'}\n    - {name: vpn, command: 'false'}\n",
+		"name: base\ndoctor:\n  checks:\n    - {name: vpn, command: 'true', severity: fatal}\n",
+		"name: base\ndoctor:\n  checks:\n    - {name: vpn, command: 'true', output: '('}\n",
+	} {
+		_, err = ParseLayer([]byte(invalid))
+		require.ErrorIs(t, err, ErrInvalidDoctorCheck, invalid)
+	}
+}
+
+func TestMerger_Merge_Doctor(t *testing.T) {
+	t.Parallel()
+
+	exit := 1
+	base := Layer{Doctor: DoctorConfig{Checks: []DoctorCheck{
+		{Name: "vpn", Command: "test -d /Applications/Tunnelblick.app"},
+		{Name: "filevault", Command: "fdesetup status"},
+	}}}
+	work := Layer{Doctor: DoctorConfig{Checks: []DoctorCheck{
+		{Name: "vpn", Command: "test -d /Applications/GlobalProtect.app"},
+		{Name: "no-root-ssh", Command: "grep -q '^PermitRootLogin yes' /etc/ssh/sshd_config", Exit: &exit},
+	}}}
+
+	merged, err := NewMerger().Merge([]Layer{base, work})
+	require.NoError(t, err)
+	assert.Equal(t, []DoctorCheck{
+		{Name: "vpn", Command: "test -d /Applications/GlobalProtect.app"},
+		{Name: "filevault", Command: "fdesetup status"},
+		{Name: "no-root-ssh", Command: "grep -q '^PermitRootLogin yes' /etc/ssh/sshd_config", Exit: &exit},
+	}, merged.Doctor.Checks)
+}
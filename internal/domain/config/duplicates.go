@@ -0,0 +1,94 @@
+package config
+
+import (
+	"slices"
+	"strings"
+)
+
+// DuplicatePackage is a package that more than one layer of a target
+// declares.
+type DuplicatePackage struct {
+	Path   string   // List path, e.g. "packages.brew.formulae"
+	Name   string   // Package as the first layer declares it
+	Layers []string // Declaring layers, in merge order
+}
+
+// FindDuplicatePackages returns the packages and VS Code extensions that
+// more than one of layers declares, in the order they are first declared.
+// Extension IDs are compared case-insensitively, like VS Code does.
+func FindDuplicatePackages(layers []Layer) []DuplicatePackage {
+	type list struct {
+		path string
+		get  func(*Layer) []string
+		fold bool // compare case-insensitively
+	}
+	lists := make([]list, 0, len(packageLists)+1)
+	for _, l := range packageLists {
+		get := l.get
+		lists = append(lists, list{l.path, func(layer *Layer) []string { return get(&layer.Packages) }, false})
+	}
+	lists = append(lists, list{"vscode.extensions", func(layer *Layer) []string { return layer.VSCode.Extensions }, true})
+
+	var duplicates []DuplicatePackage
+	for _, list := range lists {
+		var order []string
+		found := make(map[string]*DuplicatePackage)
+		for i := range layers {
+			layer := layers[i].Name.String()
+			for _, name := range list.get(&layers[i]) {
+				key := name
+				if list.fold {
+					key = strings.ToLower(name)
+				}
+				d, ok := found[key]
+				if !ok {
+					found[key] = &DuplicatePackage{Path: list.path, Name: name, Layers: []string{layer}}
+					order = append(order, key)
+					continue
+				}
+				if d.Layers[len(d.Layers)-1] != layer {
+					d.Layers = append(d.Layers, layer)
+				}
+			}
+		}
+		for _, key := range order {
+			if d := found[key]; len(d.Layers) > 1 {
+				duplicates = append(duplicates, *d)
+			}
+		}
+	}
+	return duplicates
+}
+
+// FindPackage returns the paths of the package lists of l that declare
+// name, e.g. "packages.brew.formulae".
+func (l *Layer) FindPackage(name string) []string {
+	var paths []string
+	for _, list := range packageLists {
+		if slices.Contains(list.get(&l.Packages), name) {
+			paths = append(paths, list.path)
+		}
+	}
+	return paths
+}
+
+// PackageListPaths returns the paths of the package lists of a layer, e.g.
+// "packages.brew.formulae".
+func PackageListPaths() []string {
+	paths := make([]string, len(packageLists))
+	for i, list := range packageLists {
+		paths[i] = list.path
+	}
+	return paths
+}
+
+// PackageList returns the package list of l at path, or nil when path is
+// not a package list.
+func (l *Layer) PackageList(path string) []string {
+	for _, list := range packageLists {
+		if list.path == path {
+			return list.get(&l.Packages)
+		}
+	}
+	return nil
+}
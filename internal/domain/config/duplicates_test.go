@@ -0,0 +1,30 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindDuplicatePackages(t *testing.T) {
+	t.Parallel()
+
+	var layers []Layer
+	for _, data := range []string{
+		"name: base\npackages:\n  brew:\n    formulae: [git, jq]\n    casks: [firefox]\nvscode:\n  extensions: [golang.Go]\n",
+		"name: role.go\npackages:\n  brew:\n    formulae: [go, git, git]\nvscode:\n  extensions: [golang.go]\n",
+		"name: identity.work\npackages:\n  brew:\n    formulae: [git, go]\n    casks: [slack]\n",
+	} {
+		layer, err := ParseLayer([]byte(data))
+		require.NoError(t, err)
+		layers = append(layers, *layer)
+	}
+
+	assert.Equal(t, []DuplicatePackage{
+		{Path: "packages.brew.formulae", Name: "git", Layers: []string{"base", "role.go", "identity.work"}},
+		{Path: "packages.brew.formulae", Name: "go", Layers: []string{"role.go", "identity.work"}},
+		{Path: "vscode.extensions", Name: "golang.Go", Layers: []string{"base", "role.go"}},
+	}, FindDuplicatePackages(layers))
+	assert.Empty(t, FindDuplicatePackages(layers[:1]), "repeats within a layer are for fmt")
+}
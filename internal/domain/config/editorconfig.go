@@ -0,0 +1,86 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidEditorConfig is returned when a layer declares an editorconfig
+// section without a glob or with an empty property name.
+var ErrInvalidEditorConfig = errors.New("invalid editorconfig")
+
+// EditorConfigConfig declares the default ~/.editorconfig, which editors
+// fall back to in projects without their own. The rendered template, if
+// any, comes first and the sections follow it.
+type EditorConfigConfig struct {
+	Root     bool                  `yaml:"root,omitempty"`
+	Template string                `yaml:"template,omitempty"` // Relative to the configuration directory
+	Vars     map[string]string     `yaml:"vars,omitempty"`
+	Sections []EditorConfigSection `yaml:"sections,omitempty"`
+}
+
+// EditorConfigSection sets properties such as indent_style or
+// end_of_line for the files matching a glob.
+type EditorConfigSection struct {
+	Glob       string            `yaml:"glob"`
+	Properties map[string]string `yaml:"properties"`
+}
+
+// IsZero reports whether no editorconfig is declared.
+func (c EditorConfigConfig) IsZero() bool {
+	return !c.Root && c.Template == "" && len(c.Vars) == 0 && len(c.Sections) == 0
+}
+
+func (c *EditorConfigConfig) normalize() error {
+	for i := range c.Sections {
+		section := &c.Sections[i]
+		section.Glob = strings.TrimSpace(section.Glob)
+		if section.Glob == "" {
+			return fmt.Errorf("%w: section %d has no glob", ErrInvalidEditorConfig, i+1)
+		}
+		for name := range section.Properties {
+			if strings.TrimSpace(name) == "" || strings.ContainsAny(name, "=[]") {
+				return fmt.Errorf("%w: [%s]: invalid property name %q", ErrInvalidEditorConfig, section.Glob, name)
+			}
+		}
+	}
+	return nil
+}
+
+// mergeEditorConfig combines the editorconfig of layers. Root and the
+// template are last-wins and vars are deep merged. Sections keep the order
+// in which their glob is first declared, since later sections take
+// precedence in editorconfig; their properties are deep merged with later
+// layers winning per property.
+func mergeEditorConfig(layers []Layer) EditorConfigConfig {
+	var merged EditorConfigConfig
+	index := make(map[string]int)
+	for _, layer := range layers {
+		e := layer.EditorConfig
+		if e.Root {
+			merged.Root = true
+		}
+		if e.Template != "" {
+			merged.Template = e.Template
+		}
+		for name, value := range e.Vars {
+			if merged.Vars == nil {
+				merged.Vars = make(map[string]string)
+			}
+			merged.Vars[name] = value
+		}
+		for _, section := range e.Sections {
+			i, ok := index[section.Glob]
+			if !ok {
+				i = len(merged.Sections)
+				index[section.Glob] = i
+				merged.Sections = append(merged.Sections, EditorConfigSection{Glob: section.Glob, Properties: make(map[string]string)})
+			}
+			for name, value := range section.Properties {
+				merged.Sections[i].Properties[name] = value
+			}
+		}
+	}
+	return merged
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLayer_EditorConfig(t *testing.T) {
+	t.Parallel()
+
+	layer, err := ParseLayer([]byte(`
+name: base
+editorconfig:
+  root: true
+  sections:
+    - glob: "*"
+      properties:
+        indent_size: 2
+        insert_final_newline: true
+`))
+	require.NoError(t, err)
+	assert.True(t, layer.EditorConfig.Root)
+	require.Len(t, layer.EditorConfig.Sections, 1)
+	assert.Equal(t, map[string]string{"indent_size": "2", "insert_final_newline": "true"}, layer.EditorConfig.Sections[0].Properties)
+
+	_, err = ParseLayer([]byte("name: base\neditorconfig:\n  sections:\n    - properties: {charset: utf-8}\n"))
+	require.ErrorIs(t, err, ErrInvalidEditorConfig)
+	_, err = ParseLayer([]byte("name: base\neditorconfig:\n  sections:\n    - glob: \"*\"\n      properties: {\"a=b\": c}\n"))
+	require.ErrorIs(t, err, ErrInvalidEditorConfig)
+}
+
+func TestMerger_Merge_EditorConfig(t *testing.T) {
+	t.Parallel()
+
+	base := Layer{Provenance: "layers/base.yaml", EditorConfig: EditorConfigConfig{
+		Root: true,
+		Sections: []EditorConfigSection{
+			{Glob: "*", Properties: map[string]string{"indent_size": "2", "charset": "utf-8"}},
+			{Glob: "Makefile", Properties: map[string]string{"indent_style": "tab"}},
+		},
+	}}
+	golang := Layer{Provenance: "layers/go.yaml", EditorConfig: EditorConfigConfig{
+		Template: "templates/editorconfig.tmpl",
+		Vars:     map[string]string{"indent": "4"},
+		Sections: []EditorConfigSection{
+			{Glob: "*.go", Properties: map[string]string{"indent_style": "tab"}},
+			{Glob: "*", Properties: map[string]string{"indent_size": "4"}},
+		},
+	}}
+
+	merged, err := NewMerger().Merge([]Layer{base, golang})
+	require.NoError(t, err)
+	assert.True(t, merged.EditorConfig.Root)
+	assert.Equal(t, "templates/editorconfig.tmpl", merged.EditorConfig.Template)
+	assert.Equal(t, []EditorConfigSection{
+		{Glob: "*", Properties: map[string]string{"indent_size": "4", "charset": "utf-8"}},
+		{Glob: "Makefile", Properties: map[string]string{"indent_style": "tab"}},
+		{Glob: "*.go", Properties: map[string]string{"indent_style": "tab"}},
+	}, merged.EditorConfig.Sections)
+
+	raw := merged.Raw()
+	section, ok := raw["editorconfig"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, true, section["root"])
+	assert.Len(t, section["sections"], 3)
+}
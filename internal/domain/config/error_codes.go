@@ -0,0 +1,267 @@
+package config
+
+import (
+	"sort"
+	"strings"
+)
+
+// ErrorCodeInfo documents a stable, machine-readable error identifier.
+//
+// IDs never change meaning once released, so scripts and CI can match on
+// them. They are grouped by range:
+//
+//	PF1xxx  configuration
+//	PF2xxx  providers and the local environment
+//	PF3xxx  apply and capture
+//	PF4xxx  policy
+//	PF9xxx  internal
+type ErrorCodeInfo struct {
+	ID          string `json:"id"`          // Stable identifier (e.g., "PF1001")
+	Code        string `json:"code"`        // Symbolic code carried by UserError.Code
+	Title       string `json:"title"`       // One-line summary
+	Explanation string `json:"explanation"` // What causes the error and how to resolve it
+}
+
+var errorCodes = []ErrorCodeInfo{
+	{
+		ID:    "PF1001",
+		Code:  ErrCodeConfigParse,
+		Title: "Configuration could not be parsed",
+		Explanation: `preflight.yaml or a layer is not valid YAML, or a value has the wrong
+shape (for example a map where a list is expected). The error location
+names the file and, when known, the line. Fix the syntax and run
+'preflight validate' to confirm.`,
+	},
+	{
+		ID:    "PF1002",
+		Code:  ErrCodeConfigNotFound,
+		Title: "Configuration file not found",
+		Explanation: `No preflight.yaml exists at the given path. Run 'preflight init' to
+create one, or pass --config with the path to an existing configuration.`,
+	},
+	{
+		ID:    "PF1003",
+		Code:  ErrCodeConfigInvalid,
+		Title: "Configuration is invalid",
+		Explanation: `The configuration parsed but breaks a structural rule. Run
+'preflight validate' for the list of problems.`,
+	},
+	{
+		ID:    "PF1004",
+		Code:  ErrCodeConfigLoadFailed,
+		Title: "Configuration could not be loaded",
+		Explanation: `Reading or merging the configuration failed. Check that the config and
+its layers are readable; rerun with --verbose for the underlying error.`,
+	},
+	{
+		ID:    "PF1005",
+		Code:  ErrCodeLayerNotFound,
+		Title: "Layer not found",
+		Explanation: `A target lists a layer that has no file in the layers directory. Create
+the layer or remove it from the target.`,
+	},
+	{
+		ID:    "PF1006",
+		Code:  ErrCodeLayerInvalid,
+		Title: "Layer is invalid",
+		Explanation: `A layer file parsed but holds settings that are not allowed. Run
+'preflight validate' for details.`,
+	},
+	{
+		ID:    "PF1007",
+		Code:  ErrCodeTargetNotFound,
+		Title: "Target not found",
+		Explanation: `The requested target is not defined under 'targets' in preflight.yaml.
+The error lists the targets that exist.`,
+	},
+	{
+		ID:          "PF1008",
+		Code:        ErrCodeTargetInvalid,
+		Title:       "Target is invalid",
+		Explanation: `A target definition is malformed. Targets are lists of layer names.`,
+	},
+	{
+		ID:    "PF1009",
+		Code:  ErrCodeTargetLoadFailed,
+		Title: "Target could not be loaded",
+		Explanation: `Resolving the layers of a target failed. Rerun with --verbose for the
+underlying error.`,
+	},
+	{
+		ID:    "PF1010",
+		Code:  ErrCodeMergeConflict,
+		Title: "Layers conflict",
+		Explanation: `Two layers set the same value in incompatible ways. Use 'preflight
+explain' to see which layer sets what, and resolve the conflict in one of
+them.`,
+	},
+	{
+		ID:    "PF1011",
+		Code:  ErrCodeValidationFailed,
+		Title: "Validation failed",
+		Explanation: `A field holds a value preflight does not accept. The error names the
+field; run 'preflight validate' for the full report.`,
+	},
+	{
+		ID:    "PF1012",
+		Code:  ErrCodeTemplateInvalid,
+		Title: "Template is invalid",
+		Explanation: `A dotfile template failed to parse. Check that variables use the
+{{ .Variable }} form and that blocks are closed.`,
+	},
+	{
+		ID:    "PF1013",
+		Code:  ErrCodeCircularReference,
+		Title: "Circular layer reference",
+		Explanation: `Layers include or inherit from each other in a cycle. The error shows
+the chain; remove one of its links.`,
+	},
+	{
+		ID:    "PF1014",
+		Code:  ErrCodeEnvSectionMissing,
+		Title: "Configuration has no env section",
+		Explanation: `The command needs environment variables, but the merged configuration
+defines none. Add an 'env' section to a layer of the target.`,
+	},
+	{
+		ID:    "PF1015",
+		Code:  ErrCodeEnvVarNotFound,
+		Title: "Environment variable not defined",
+		Explanation: `The variable is not defined in the env section of the target. Run
+'preflight env list' to see the variables that are.`,
+	},
+	{
+		ID:    "PF2001",
+		Code:  ErrCodeProviderNotFound,
+		Title: "Provider not found",
+		Explanation: `The configuration uses a provider preflight does not know. Check the
+spelling of the section name, or install the plugin that provides it.`,
+	},
+	{
+		ID:    "PF2002",
+		Code:  ErrCodeFileNotFound,
+		Title: "File not found",
+		Explanation: `A file referenced by the configuration does not exist. Check the path
+relative to the config directory.`,
+	},
+	{
+		ID:    "PF2003",
+		Code:  ErrCodeProviderUnavailable,
+		Title: "Provider unavailable",
+		Explanation: `The tool a provider drives (for example Homebrew) is not installed or
+not on PATH, so the command cannot run. Install it, or run
+'preflight apply --allow-bootstrap' to let preflight install it.`,
+	},
+	{
+		ID:    "PF2004",
+		Code:  ErrCodeFilePermission,
+		Title: "Permission denied",
+		Explanation: `preflight cannot read or write a file it needs. Check the ownership
+and permissions of the path in the error.`,
+	},
+	{
+		ID:    "PF2005",
+		Code:  ErrCodeMkdirFailed,
+		Title: "Directory could not be created",
+		Explanation: `Creating a directory failed, usually because of permissions or a file
+in the way. Check the path in the error.`,
+	},
+	{
+		ID:    "PF2006",
+		Code:  ErrCodeWriteFailed,
+		Title: "File could not be written",
+		Explanation: `Writing a file failed, usually because of permissions or a full disk.
+Check the path in the error.`,
+	},
+	{
+		ID:    "PF2007",
+		Code:  ErrCodeUnsupportedShell,
+		Title: "Unsupported shell",
+		Explanation: `The command does not support the requested shell. Use bash, zsh or
+fish.`,
+	},
+	{
+		ID:    "PF3001",
+		Code:  ErrCodeApplyFailed,
+		Title: "Apply failed",
+		Explanation: `One or more steps did not complete. The output above the error shows
+each failing step; 'preflight history' keeps their full output. Run
+'preflight undo' to revert the steps that did complete, or 'preflight
+doctor --verbose' to diagnose.`,
+	},
+	{
+		ID:    "PF3002",
+		Code:  ErrCodeBootstrapDeclined,
+		Title: "Bootstrap declined",
+		Explanation: `The plan needs to install a package manager and the confirmation was
+declined. Rerun with --allow-bootstrap to permit it, or install the tool
+yourself first.`,
+	},
+	{
+		ID:    "PF3003",
+		Code:  ErrCodeLockUpdateFailed,
+		Title: "Lockfile could not be updated",
+		Explanation: `The apply succeeded but writing preflight.lock failed. Check that the
+config directory is writable and rerun with --update-lock.`,
+	},
+	{
+		ID:    "PF3004",
+		Code:  ErrCodeCaptureFailed,
+		Title: "Capture failed",
+		Explanation: `Reading the current machine state failed. Rerun with --verbose for
+the provider that failed.`,
+	},
+	{
+		ID:    "PF4001",
+		Code:  ErrCodePolicyViolation,
+		Title: "Policy violation",
+		Explanation: `The configuration breaks a rule of a policy or org policy. Run
+'preflight validate --policy <file>' or 'preflight compliance' for the
+violated rules, and change the configuration or request an override.`,
+	},
+	{
+		ID:    "PF9001",
+		Code:  ErrCodeMarshalFailed,
+		Title: "Internal encoding error",
+		Explanation: `preflight failed to encode its own data. This is a bug; please report
+it with the output of the command rerun with --verbose.`,
+	},
+}
+
+// ErrorCodes returns all documented error codes ordered by ID.
+func ErrorCodes() []ErrorCodeInfo {
+	codes := make([]ErrorCodeInfo, len(errorCodes))
+	copy(codes, errorCodes)
+	sort.Slice(codes, func(i, j int) bool { return codes[i].ID < codes[j].ID })
+	return codes
+}
+
+// LookupErrorCode finds a documented error code by its stable ID (e.g.,
+// "PF2003", case-insensitive) or by its symbolic code.
+func LookupErrorCode(code string) (ErrorCodeInfo, bool) {
+	for _, info := range errorCodes {
+		if strings.EqualFold(info.ID, code) || info.Code == code {
+			return info, true
+		}
+	}
+	return ErrorCodeInfo{}, false
+}
+
+// ID returns the stable identifier of the error's code, or "" when the
+// code is not documented.
+func (e *UserError) ID() string {
+	if info, ok := LookupErrorCode(e.Code); ok {
+		return info.ID
+	}
+	return ""
+}
+
+// NewProviderUnavailableError creates an error for a provider whose tool
+// is not installed.
+func NewProviderUnavailableError(tool string) *UserError {
+	return &UserError{
+		Code:       ErrCodeProviderUnavailable,
+		Message:    tool + " is not installed",
+		Suggestion: "Install " + tool + " or run 'preflight apply --allow-bootstrap' to let preflight install it.",
+	}
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorCodes_UniqueAndWellFormed(t *testing.T) {
+	t.Parallel()
+
+	idPattern := regexp.MustCompile(`^PF[1-49]\d{3}$`)
+	ids := make(map[string]bool)
+	codes := make(map[string]bool)
+	for _, info := range ErrorCodes() {
+		assert.Regexp(t, idPattern, info.ID)
+		assert.False(t, ids[info.ID], "duplicate ID %s", info.ID)
+		assert.False(t, codes[info.Code], "duplicate code %s", info.Code)
+		assert.NotEmpty(t, info.Title, info.ID)
+		assert.NotEmpty(t, info.Explanation, info.ID)
+		ids[info.ID] = true
+		codes[info.Code] = true
+	}
+}
+
+func TestLookupErrorCode(t *testing.T) {
+	t.Parallel()
+
+	info, ok := LookupErrorCode("PF2003")
+	require.True(t, ok)
+	assert.Equal(t, ErrCodeProviderUnavailable, info.Code)
+
+	info, ok = LookupErrorCode("pf1001")
+	require.True(t, ok)
+	assert.Equal(t, ErrCodeConfigParse, info.Code)
+
+	info, ok = LookupErrorCode(ErrCodeConfigNotFound)
+	require.True(t, ok)
+	assert.Equal(t, "PF1002", info.ID)
+
+	_, ok = LookupErrorCode("PF0000")
+	assert.False(t, ok)
+}
+
+func TestUserError_ID(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "PF1001", NewConfigParseError("preflight.yaml", nil).ID())
+	assert.Equal(t, "PF2003", NewProviderUnavailableError("Homebrew").ID())
+	assert.Empty(t, NewUserError("SOMETHING_ELSE", "unknown").ID())
+}
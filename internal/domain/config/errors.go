@@ -3,6 +3,7 @@ package config
 import (
 	"errors"
 	"fmt"
+	"path/filepath"
 	"strings"
 )
 
@@ -22,11 +23,26 @@ const (
 	ErrCodeTemplateInvalid   = "TEMPLATE_INVALID"
 	ErrCodeProviderNotFound  = "PROVIDER_NOT_FOUND"
 	ErrCodeCircularReference = "CIRCULAR_REFERENCE"
+
+	ErrCodeConfigLoadFailed    = "CONFIG_LOAD_FAILED"
+	ErrCodeTargetLoadFailed    = "TARGET_LOAD_FAILED"
+	ErrCodeEnvSectionMissing   = "ENV_SECTION_MISSING"
+	ErrCodeEnvVarNotFound      = "ENV_VAR_NOT_FOUND"
+	ErrCodeProviderUnavailable = "PROVIDER_UNAVAILABLE"
+	ErrCodeUnsupportedShell    = "UNSUPPORTED_SHELL"
+	ErrCodeMkdirFailed         = "MKDIR_FAILED"
+	ErrCodeWriteFailed         = "WRITE_FAILED"
+	ErrCodeApplyFailed         = "APPLY_FAILED"
+	ErrCodeBootstrapDeclined   = "BOOTSTRAP_DECLINED"
+	ErrCodeLockUpdateFailed    = "LOCK_UPDATE_FAILED"
+	ErrCodeCaptureFailed       = "CAPTURE_FAILED"
+	ErrCodePolicyViolation     = "POLICY_VIOLATION"
+	ErrCodeMarshalFailed       = "MARSHAL_FAILED"
 )
 
 // UserError represents a user-friendly error with actionable suggestions.
 type UserError struct {
-	Code       string // Error code for categorization (e.g., "CONFIG_NOT_FOUND")
+	Code       string // Error code for categorization (e.g., "CONFIG_NOT_FOUND"); see ID for its stable identifier
 	Message    string // User-friendly error message
 	Context    string // File path, line number, or other location context
 	Suggestion string // Actionable suggestion to fix the error
@@ -212,12 +228,18 @@ func (l *ErrorList) AsError() error {
 // Common user-friendly error constructors.
 
 // NewConfigNotFoundError creates an error for missing config file.
+// When the directory of path is a config directory holding several configs,
+// the suggestion names them.
 func NewConfigNotFoundError(path string) *UserError {
+	suggestion := "Run 'preflight init' to create a new configuration, or check the file path."
+	if names := ManifestsIn(filepath.Dir(path)); len(names) > 0 {
+		suggestion = fmt.Sprintf("Choose a config with --config: %s.", strings.Join(names, ", "))
+	}
 	return &UserError{
 		Code:       ErrCodeConfigNotFound,
 		Message:    fmt.Sprintf("configuration file not found: %s", path),
 		Context:    path,
-		Suggestion: "Run 'preflight init' to create a new configuration, or check the file path.",
+		Suggestion: suggestion,
 	}
 }
 
@@ -0,0 +1,328 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// packageLists are the lists of a layer that name packages. Their order
+// does not matter, so formatting sorts them.
+var packageLists = []struct {
+	path string
+	get  func(*PackageSet) []string
+}{
+	{"packages.brew.taps", func(p *PackageSet) []string { return p.Brew.Taps }},
+	{"packages.brew.formulae", func(p *PackageSet) []string { return p.Brew.Formulae }},
+	{"packages.brew.casks", func(p *PackageSet) []string { return p.Brew.Casks }},
+	{"packages.apt.ppas", func(p *PackageSet) []string { return p.Apt.PPAs }},
+	{"packages.apt.packages", func(p *PackageSet) []string { return p.Apt.Packages }},
+	{"packages.npm.packages", func(p *PackageSet) []string { return p.Npm.Packages }},
+	{"packages.go.tools", func(p *PackageSet) []string { return p.Go.Tools }},
+	{"packages.pip.packages", func(p *PackageSet) []string { return p.Pip.Packages }},
+	{"packages.gem.gems", func(p *PackageSet) []string { return p.Gem.Gems }},
+	{"packages.cargo.crates", func(p *PackageSet) []string { return p.Cargo.Crates }},
+}
+
+// RedundantPackages maps a list path (e.g. "packages.brew.formulae") to
+// packages that can be removed from it, each with the layer that also
+// declares it.
+type RedundantPackages map[string]map[string]string
+
+// FindRedundantPackages finds packages a layer declares that an earlier
+// layer already declares in every target using it. Lists merge as a set
+// union, so removing them does not change any target. Layers used by no
+// target are left alone.
+func FindRedundantPackages(manifest *Manifest, layers map[string]*Layer) map[string]RedundantPackages {
+	// earlier[layer] holds, per target using the layer, the layers before it.
+	earlier := make(map[string][][]string)
+	for _, names := range manifest.Targets {
+		for i, name := range names {
+			before := make([]string, 0, i)
+			for _, prev := range names[:i] {
+				if prev.String() != name.String() {
+					before = append(before, prev.String())
+				}
+			}
+			earlier[name.String()] = append(earlier[name.String()], before)
+		}
+	}
+
+	result := make(map[string]RedundantPackages)
+	for name, layer := range layers {
+		targets, ok := earlier[name]
+		if !ok {
+			continue
+		}
+		for _, list := range packageLists {
+			for _, pkg := range list.get(&layer.Packages) {
+				declaredIn, ok := declaredInAll(pkg, list.get, targets, layers)
+				if !ok {
+					continue
+				}
+				if result[name] == nil {
+					result[name] = make(RedundantPackages)
+				}
+				if result[name][list.path] == nil {
+					result[name][list.path] = make(map[string]string)
+				}
+				result[name][list.path][pkg] = declaredIn
+			}
+		}
+	}
+	return result
+}
+
+// declaredInAll reports whether every set of layers declares pkg in one of
+// its layers, and returns the first such layer.
+func declaredInAll(pkg string, get func(*PackageSet) []string, sets [][]string, layers map[string]*Layer) (string, bool) {
+	first := ""
+	for _, set := range sets {
+		found := ""
+		for _, name := range set {
+			if layer, ok := layers[name]; ok && slices.Contains(get(&layer.Packages), pkg) {
+				found = name
+				break
+			}
+		}
+		if found == "" {
+			return "", false
+		}
+		if first == "" {
+			first = found
+		}
+	}
+	return first, first != ""
+}
+
+// FormatManifest normalizes preflight.yaml: keys in canonical order and
+// two-space indentation. It returns the formatted document and a
+// description of each change.
+func FormatManifest(data []byte) ([]byte, []string, error) {
+	return formatDocument(data, reflect.TypeOf(manifestYAML{}), nil)
+}
+
+// FormatLayer normalizes a layer file: keys in canonical order, package
+// lists sorted without duplicates, the redundant packages removed and
+// two-space indentation. It returns the formatted document and a
+// description of each change.
+func FormatLayer(data []byte, redundant RedundantPackages) ([]byte, []string, error) {
+	return formatDocument(data, reflect.TypeOf(layerYAML{}), redundant)
+}
+
+func formatDocument(data []byte, t reflect.Type, redundant RedundantPackages) ([]byte, []string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, err
+	}
+	if len(doc.Content) == 0 {
+		return data, nil, nil
+	}
+
+	root := doc.Content[0]
+	spaced := spacedKeys(data, root)
+	f := formatter{redundant: redundant}
+	f.format(root, t, "")
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	formatted := respace(buf.Bytes(), spaced)
+	if len(f.changes) == 0 && !bytes.Equal(formatted, data) {
+		f.changes = append(f.changes, "normalized indentation and spacing")
+	}
+	return formatted, f.changes, nil
+}
+
+type formatter struct {
+	redundant RedundantPackages
+	changes   []string
+}
+
+func (f *formatter) changed(format string, args ...any) {
+	f.changes = append(f.changes, fmt.Sprintf(format, args...))
+}
+
+func (f *formatter) format(node *yaml.Node, t reflect.Type, path string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case node.Kind == yaml.MappingNode && t.Kind() == reflect.Struct:
+		f.formatStruct(node, t, path)
+	case node.Kind == yaml.MappingNode && t.Kind() == reflect.Map:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			f.format(node.Content[i+1], t.Elem(), joinSchemaPath(path, node.Content[i].Value))
+		}
+	case node.Kind == yaml.SequenceNode && t.Kind() == reflect.Slice:
+		if isPackageList(path) {
+			f.formatPackageList(node, path)
+			return
+		}
+		for i, item := range node.Content {
+			f.format(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))
+		}
+	}
+}
+
+// formatStruct puts the keys of node in the order of the fields of t, with
+// keys t does not know last in their original order.
+func (f *formatter) formatStruct(node *yaml.Node, t reflect.Type, path string) {
+	fields := make(map[string]reflect.StructField, t.NumField())
+	rank := make(map[string]int, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		switch {
+		case !field.IsExported() || name == "-":
+			continue
+		case name == "":
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field
+		rank[name] = i
+	}
+
+	type pair struct{ key, value *yaml.Node }
+	pairs := make([]pair, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		pairs = append(pairs, pair{node.Content[i], node.Content[i+1]})
+	}
+	keyRank := func(p pair) int {
+		if r, ok := rank[p.key.Value]; ok {
+			return r
+		}
+		return t.NumField()
+	}
+	if !sort.SliceIsSorted(pairs, func(i, j int) bool { return keyRank(pairs[i]) < keyRank(pairs[j]) }) {
+		first := pairs[0].key
+		sort.SliceStable(pairs, func(i, j int) bool { return keyRank(pairs[i]) < keyRank(pairs[j]) })
+		if path == "" {
+			// Comments at the top of the file stay there.
+			if first != pairs[0].key && first.HeadComment != "" {
+				pairs[0].key.HeadComment = strings.TrimSpace(first.HeadComment + "\n" + pairs[0].key.HeadComment)
+				first.HeadComment = ""
+			}
+			f.changed("ordered top-level keys")
+		} else {
+			f.changed("ordered keys of %s", path)
+		}
+	}
+
+	node.Content = node.Content[:0]
+	for _, p := range pairs {
+		node.Content = append(node.Content, p.key, p.value)
+		if field, ok := fields[p.key.Value]; ok {
+			f.format(p.value, field.Type, joinSchemaPath(path, p.key.Value))
+		}
+	}
+}
+
+// formatPackageList sorts a package list and removes duplicate and
+// redundant entries.
+func (f *formatter) formatPackageList(node *yaml.Node, path string) {
+	seen := make(map[string]bool, len(node.Content))
+	items := make([]*yaml.Node, 0, len(node.Content))
+	for _, item := range node.Content {
+		if item.Kind != yaml.ScalarNode {
+			items = append(items, item)
+			continue
+		}
+		if seen[item.Value] {
+			f.changed("removed duplicate %s from %s", item.Value, path)
+			continue
+		}
+		seen[item.Value] = true
+		if layer, ok := f.redundant[path][item.Value]; ok {
+			f.changed("removed %s from %s (already in layer %s)", item.Value, path, layer)
+			continue
+		}
+		items = append(items, item)
+	}
+
+	less := func(i, j int) bool { return items[i].Value < items[j].Value }
+	if !sort.SliceIsSorted(items, less) {
+		sort.SliceStable(items, less)
+		f.changed("sorted %s", path)
+	}
+	node.Content = items
+}
+
+func isPackageList(path string) bool {
+	for _, list := range packageLists {
+		if list.path == path {
+			return true
+		}
+	}
+	return false
+}
+
+// spacedKeys returns the top-level keys of root that have a blank line
+// before them (and before their comments) in data. The encoder drops blank
+// lines; respace puts these back so sections stay visually separated.
+func spacedKeys(data []byte, root *yaml.Node) map[string]bool {
+	if root.Kind != yaml.MappingNode {
+		return nil
+	}
+	lines := strings.Split(string(data), "\n")
+	spaced := make(map[string]bool)
+	for i := 2; i < len(root.Content); i += 2 {
+		key := root.Content[i]
+		above := key.Line - 2 // index of the line above the key
+		for above >= 0 && strings.HasPrefix(lines[above], "#") {
+			above--
+		}
+		if above >= 0 && strings.TrimSpace(lines[above]) == "" {
+			spaced[key.Value] = true
+		}
+	}
+	// The first key has nothing above it; space it like the second in case
+	// formatting moves it down.
+	if len(root.Content) > 2 && spaced[root.Content[2].Value] {
+		spaced[root.Content[0].Value] = true
+	}
+	return spaced
+}
+
+// respace inserts a blank line before the spaced top-level keys of the
+// encoded document, above any comments on them. The first key is never
+// spaced.
+func respace(data []byte, spaced map[string]bool) []byte {
+	if len(spaced) == 0 {
+		return data
+	}
+	lines := strings.Split(string(data), "\n")
+	out := make([]string, 0, len(lines)+len(spaced))
+	comments := 0 // top-level comment lines just appended
+	first := true
+	for _, line := range lines {
+		key, _, isKey := strings.Cut(line, ":")
+		isKey = isKey && line != "" && !strings.ContainsAny(line[:1], " #-")
+		if isKey {
+			if spaced[key] && !first {
+				at := len(out) - comments
+				out = append(out[:at], append([]string{""}, out[at:]...)...)
+			}
+			first = false
+		}
+		if strings.HasPrefix(line, "#") {
+			comments++
+		} else {
+			comments = 0
+		}
+		out = append(out, line)
+	}
+	return []byte(strings.Join(out, "\n"))
+}
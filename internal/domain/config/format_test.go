@@ -0,0 +1,118 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatLayer(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(`# Work machines
+packages:
+    brew:
+        casks: [slack]
+        formulae:
+            - kubectl
+            - awscli # cloud
+            - git
+            - kubectl
+
+git:
+    user:
+        email: me@work.com
+name: work
+`)
+	redundant := RedundantPackages{"packages.brew.formulae": {"git": "base"}}
+
+	formatted, changes, err := FormatLayer(data, redundant)
+	require.NoError(t, err)
+	assert.Equal(t, `# Work machines
+name: work
+
+packages:
+  brew:
+    formulae:
+      - awscli # cloud
+      - kubectl
+    casks: [slack]
+
+git:
+  user:
+    email: me@work.com
+`, string(formatted))
+	assert.Equal(t, []string{
+		"ordered top-level keys",
+		"ordered keys of packages.brew",
+		"removed git from packages.brew.formulae (already in layer base)",
+		"removed duplicate kubectl from packages.brew.formulae",
+		"sorted packages.brew.formulae",
+	}, changes)
+
+	again, changes, err := FormatLayer(formatted, nil)
+	require.NoError(t, err)
+	assert.Equal(t, string(formatted), string(again))
+	assert.Empty(t, changes)
+}
+
+func TestFormatLayer_Indentation(t *testing.T) {
+	t.Parallel()
+
+	formatted, changes, err := FormatLayer([]byte("name: base\nshell:\n    default: zsh\n"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "name: base\nshell:\n  default: zsh\n", string(formatted))
+	assert.Equal(t, []string{"normalized indentation and spacing"}, changes)
+}
+
+func TestFormatManifest_KeepsFileComment(t *testing.T) {
+	t.Parallel()
+
+	formatted, changes, err := FormatManifest([]byte(`# yaml-language-server: $schema=preflight.schema.json
+targets:
+  default: [base]
+
+defaults:
+  mode: intent
+`))
+	require.NoError(t, err)
+	assert.Equal(t, `# yaml-language-server: $schema=preflight.schema.json
+defaults:
+  mode: intent
+
+targets:
+  default: [base]
+`, string(formatted))
+	assert.Equal(t, []string{"ordered top-level keys"}, changes)
+}
+
+func TestFindRedundantPackages(t *testing.T) {
+	t.Parallel()
+
+	manifest, err := ParseManifest([]byte(`targets:
+  work: [base, role.go, identity.work]
+  home: [base, identity.home]
+  go: [role.go]
+`))
+	require.NoError(t, err)
+	layer := func(yaml string) *Layer {
+		l, err := ParseLayer([]byte(yaml))
+		require.NoError(t, err)
+		return l
+	}
+	layers := map[string]*Layer{
+		"base":          layer("name: base\npackages:\n  brew:\n    formulae: [git, jq]\n"),
+		"role.go":       layer("name: role.go\npackages:\n  brew:\n    formulae: [git, go]\n"),
+		"identity.work": layer("name: identity.work\npackages:\n  brew:\n    formulae: [go, jq, gh]\n"),
+		"identity.home": layer("name: identity.home\npackages:\n  brew:\n    formulae: [jq]\n"),
+		"unused":        layer("name: unused\npackages:\n  brew:\n    formulae: [git]\n"),
+	}
+
+	redundant := FindRedundantPackages(manifest, layers)
+	assert.Equal(t, map[string]RedundantPackages{
+		// role.go is also the first layer of "go", so git is needed there.
+		"identity.work": {"packages.brew.formulae": {"go": "role.go", "jq": "base"}},
+		"identity.home": {"packages.brew.formulae": {"jq": "base"}},
+	}, redundant)
+}
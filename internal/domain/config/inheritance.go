@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 )
 
@@ -184,10 +185,10 @@ func (r *LayerResolver) mergeFiles(parent, child []FileDeclaration) []FileDeclar
 	fileMap := make(map[string]FileDeclaration)
 
 	for _, f := range parent {
-		fileMap[f.Path] = f
+		fileMap[f.Key()] = f
 	}
 	for _, f := range child {
-		fileMap[f.Path] = f
+		fileMap[f.Key()] = f
 	}
 
 	result := make([]FileDeclaration, 0, len(fileMap))
@@ -247,6 +248,27 @@ func (r *LayerResolver) mergeGit(parent, child GitConfig) GitConfig {
 	// Merge includes
 	result.Includes = append(result.Includes, child.Includes...)
 
+	// Merge global gitignore
+	if child.Ignore.Path != "" {
+		result.Ignore.Path = child.Ignore.Path
+	}
+	if child.Ignore.Template != "" {
+		result.Ignore.Template = child.Ignore.Template
+	}
+	if len(child.Ignore.Vars) > 0 {
+		vars := make(map[string]string, len(parent.Ignore.Vars)+len(child.Ignore.Vars))
+		for k, v := range parent.Ignore.Vars {
+			vars[k] = v
+		}
+		for k, v := range child.Ignore.Vars {
+			vars[k] = v
+		}
+		result.Ignore.Vars = vars
+	}
+	if len(child.Ignore.Patterns) > 0 {
+		result.Ignore.Patterns = uniqueStrings(append(slices.Clone(parent.Ignore.Patterns), child.Ignore.Patterns...))
+	}
+
 	return result
 }
 
@@ -274,6 +296,9 @@ func (r *LayerResolver) mergeSSH(parent, child SSHConfig) SSHConfig {
 	if child.Defaults.ServerAliveCountMax > 0 {
 		result.Defaults.ServerAliveCountMax = child.Defaults.ServerAliveCountMax
 	}
+	if child.Defaults.IdentityAgent != "" {
+		result.Defaults.IdentityAgent = child.Defaults.IdentityAgent
+	}
 
 	// Merge hosts (child takes precedence for same host)
 	hostMap := make(map[string]SSHHostConfig)
@@ -307,6 +332,9 @@ func (r *LayerResolver) mergeRuntime(parent, child RuntimeConfig) RuntimeConfig
 	if child.Scope != "" {
 		result.Scope = child.Scope
 	}
+	if child.Shims {
+		result.Shims = true
+	}
 
 	// Merge tools (child versions take precedence)
 	toolMap := make(map[string]RuntimeToolConfig)
@@ -393,6 +421,12 @@ func (r *LayerResolver) mergeNvim(parent, child NvimConfig) NvimConfig {
 	if child.EnsureInstall {
 		result.EnsureInstall = true
 	}
+	if len(child.ExtraPlugins) > 0 {
+		result.ExtraPlugins = uniqueStrings(append(slices.Clone(parent.ExtraPlugins), child.ExtraPlugins...))
+	}
+	if len(child.Packs) > 0 {
+		result.Packs = uniqueStrings(append(slices.Clone(parent.Packs), child.Packs...))
+	}
 
 	return result
 }
@@ -418,6 +452,10 @@ func (r *LayerResolver) mergeVSCode(parent, child VSCodeConfig) VSCodeConfig {
 	allKeybindings = append(allKeybindings, child.Keybindings...)
 	result.Keybindings = allKeybindings
 
+	if child.Profile != nil {
+		result.Profile = child.Profile
+	}
+
 	return result
 }
 
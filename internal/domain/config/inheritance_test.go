@@ -247,6 +247,7 @@ func TestLayerResolver_mergeGit(t *testing.T) {
 			"st": "status",
 		},
 		Includes: []GitInclude{{Path: "~/.gitconfig.local"}},
+		Ignore:   GitIgnoreConfig{Patterns: []string{".DS_Store"}},
 	}
 	child := GitConfig{
 		User: GitUserConfig{
@@ -267,6 +268,7 @@ func TestLayerResolver_mergeGit(t *testing.T) {
 			"co": "checkout",
 		},
 		Includes: []GitInclude{{Path: "~/.gitconfig.work"}},
+		Ignore:   GitIgnoreConfig{Path: "~/.gitignore_global", Patterns: []string{".DS_Store", ".idea/"}},
 	}
 
 	result := r.mergeGit(parent, child)
@@ -282,6 +284,8 @@ func TestLayerResolver_mergeGit(t *testing.T) {
 	assert.Contains(t, result.Aliases, "st")
 	assert.Contains(t, result.Aliases, "co")
 	assert.Len(t, result.Includes, 2)
+	assert.Equal(t, "~/.gitignore_global", result.Ignore.Path)
+	assert.Equal(t, []string{".DS_Store", ".idea/"}, result.Ignore.Patterns)
 }
 
 func TestLayerResolver_mergeSSH(t *testing.T) {
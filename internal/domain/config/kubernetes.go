@@ -0,0 +1,113 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidKubernetesConfig is returned when a layer declares an
+// incomplete kubernetes context.
+var ErrInvalidKubernetesConfig = errors.New("invalid kubernetes config")
+
+// KubernetesConfig declares kubectl plugins (installed with krew),
+// kubeconfig contexts and the default namespace of the current context.
+type KubernetesConfig struct {
+	Plugins          []string            `yaml:"plugins,omitempty"`
+	Contexts         []KubernetesContext `yaml:"contexts,omitempty"`
+	DefaultNamespace string              `yaml:"default_namespace,omitempty"`
+}
+
+// KubernetesContext is a kubeconfig context. It either references an
+// existing cluster and user or renders them from a template in the config
+// directory, which may resolve credentials with {{ secret "backend/key" }}.
+type KubernetesContext struct {
+	Name      string `yaml:"name"`
+	Cluster   string `yaml:"cluster,omitempty"`
+	User      string `yaml:"user,omitempty"`
+	Namespace string `yaml:"namespace,omitempty"`
+	Template  string `yaml:"template,omitempty"`
+}
+
+// IsZero reports whether no kubernetes configuration is declared.
+func (c KubernetesConfig) IsZero() bool {
+	return len(c.Plugins) == 0 && len(c.Contexts) == 0 && c.DefaultNamespace == ""
+}
+
+func (c *KubernetesConfig) normalize() error {
+	seen := make(map[string]bool)
+	for i, ctx := range c.Contexts {
+		if ctx.Name == "" {
+			return fmt.Errorf("%w: context %d has no name", ErrInvalidKubernetesConfig, i+1)
+		}
+		if seen[ctx.Name] {
+			return fmt.Errorf("%w: duplicate context %q", ErrInvalidKubernetesConfig, ctx.Name)
+		}
+		seen[ctx.Name] = true
+		if ctx.Template != "" && (ctx.Cluster != "" || ctx.User != "") {
+			return fmt.Errorf("%w: context %q cannot combine a template with cluster or user", ErrInvalidKubernetesConfig, ctx.Name)
+		}
+	}
+	return nil
+}
+
+// mergeKubernetes combines the kubernetes configuration of layers. Plugins
+// are a set union, contexts are keyed by name with later layers replacing
+// earlier definitions in place, and the default namespace is last-wins.
+func mergeKubernetes(layers []Layer) KubernetesConfig {
+	var merged KubernetesConfig
+	plugins := make(map[string]bool)
+	contextIndex := make(map[string]int)
+
+	for _, layer := range layers {
+		k := layer.Kubernetes
+		for _, plugin := range k.Plugins {
+			if !plugins[plugin] {
+				plugins[plugin] = true
+				merged.Plugins = append(merged.Plugins, plugin)
+			}
+		}
+		for _, ctx := range k.Contexts {
+			if i, ok := contextIndex[ctx.Name]; ok {
+				merged.Contexts[i] = ctx
+				continue
+			}
+			contextIndex[ctx.Name] = len(merged.Contexts)
+			merged.Contexts = append(merged.Contexts, ctx)
+		}
+		if k.DefaultNamespace != "" {
+			merged.DefaultNamespace = k.DefaultNamespace
+		}
+	}
+	return merged
+}
+
+// raw converts the kubernetes configuration into the map the kubernetes
+// provider parses.
+func (c KubernetesConfig) raw() map[string]interface{} {
+	kubernetes := make(map[string]interface{})
+	if len(c.Plugins) > 0 {
+		kubernetes["plugins"] = toInterfaceSlice(c.Plugins)
+	}
+	if len(c.Contexts) > 0 {
+		contexts := make([]interface{}, 0, len(c.Contexts))
+		for _, ctx := range c.Contexts {
+			context := map[string]interface{}{"name": ctx.Name}
+			for key, value := range map[string]string{
+				"cluster":   ctx.Cluster,
+				"user":      ctx.User,
+				"namespace": ctx.Namespace,
+				"template":  ctx.Template,
+			} {
+				if value != "" {
+					context[key] = value
+				}
+			}
+			contexts = append(contexts, context)
+		}
+		kubernetes["contexts"] = contexts
+	}
+	if c.DefaultNamespace != "" {
+		kubernetes["default_namespace"] = c.DefaultNamespace
+	}
+	return kubernetes
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLayer_Kubernetes(t *testing.T) {
+	t.Parallel()
+
+	layer, err := ParseLayer([]byte(`
+name: base
+kubernetes:
+  plugins: [ctx, ns]
+  contexts:
+    - name: prod
+      template: kube/prod.yaml
+      namespace: payments
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ctx", "ns"}, layer.Kubernetes.Plugins)
+	assert.Equal(t, "kube/prod.yaml", layer.Kubernetes.Contexts[0].Template)
+
+	_, err = ParseLayer([]byte("name: base\nkubernetes:\n  contexts:\n    - cluster: prod\n"))
+	require.ErrorIs(t, err, ErrInvalidKubernetesConfig)
+	_, err = ParseLayer([]byte("name: base\nkubernetes:\n  contexts:\n    - name: a\n      template: a.yaml\n      user: admin\n"))
+	require.ErrorIs(t, err, ErrInvalidKubernetesConfig)
+}
+
+func TestMerger_Merge_Kubernetes(t *testing.T) {
+	t.Parallel()
+
+	base := Layer{Kubernetes: KubernetesConfig{
+		Plugins:  []string{"ctx", "ns"},
+		Contexts: []KubernetesContext{{Name: "dev", Cluster: "dev"}, {Name: "prod", Cluster: "prod"}},
+	}}
+	work := Layer{Kubernetes: KubernetesConfig{
+		Plugins:          []string{"ns", "stern"},
+		Contexts:         []KubernetesContext{{Name: "dev", Template: "kube/dev.yaml", Namespace: "team-a"}},
+		DefaultNamespace: "team-a",
+	}}
+
+	merged, err := NewMerger().Merge([]Layer{base, work})
+	require.NoError(t, err)
+	k := merged.Kubernetes
+	assert.Equal(t, []string{"ctx", "ns", "stern"}, k.Plugins)
+	assert.Equal(t, []KubernetesContext{
+		{Name: "dev", Template: "kube/dev.yaml", Namespace: "team-a"},
+		{Name: "prod", Cluster: "prod"},
+	}, k.Contexts)
+
+	raw := merged.Raw()["kubernetes"].(map[string]interface{})
+	assert.Equal(t, "team-a", raw["default_namespace"])
+	assert.Equal(t, map[string]interface{}{"name": "dev", "template": "kube/dev.yaml", "namespace": "team-a"}, raw["contexts"].([]interface{})[0])
+}
@@ -16,6 +16,9 @@ const (
 	FileModeTemplate FileMode = "template"
 	// FileModeBYO means user owns the file; Preflight links/validates only.
 	FileModeBYO FileMode = "byo"
+	// FileModeBlock means Preflight owns a marked block of the file; the
+	// rest of the file is the user's.
+	FileModeBlock FileMode = "block"
 )
 
 // FileDeclaration represents a managed dotfile.
@@ -23,6 +26,21 @@ type FileDeclaration struct {
 	Path     string   `yaml:"path"`
 	Mode     FileMode `yaml:"mode"`
 	Template string   `yaml:"template,omitempty"`
+	// Block names the managed block in block mode. Its content comes from
+	// Content, or from the Template file.
+	Block   string `yaml:"block,omitempty"`
+	Content string `yaml:"content,omitempty"`
+	// Comment starts the block marker lines (default "#").
+	Comment string `yaml:"comment,omitempty"`
+}
+
+// Key identifies the declaration when layers are merged: the path, and
+// the block name for blocks, so a file can hold blocks from several layers.
+func (f FileDeclaration) Key() string {
+	if f.Mode == FileModeBlock {
+		return f.Path + "#" + f.Block
+	}
+	return f.Path
 }
 
 // BrewPackages represents Homebrew package configuration.
@@ -30,6 +48,9 @@ type BrewPackages struct {
 	Taps     []string `yaml:"taps,omitempty"`
 	Formulae []string `yaml:"formulae,omitempty"`
 	Casks    []string `yaml:"casks,omitempty"`
+	// Arch forces formulae under the Homebrew of an architecture: arm64
+	// installs under /opt/homebrew, x86_64 under /usr/local with Rosetta.
+	Arch map[string]string `yaml:"arch,omitempty"`
 }
 
 // AptPackages represents apt package configuration.
@@ -72,6 +93,7 @@ type PackageSet struct {
 	Pip   PipPackages   `yaml:"pip,omitempty"`
 	Gem   GemPackages   `yaml:"gem,omitempty"`
 	Cargo CargoPackages `yaml:"cargo,omitempty"`
+	Mas   MasPackages   `yaml:"mas,omitempty"`
 }
 
 // GitUserConfig represents git user configuration.
@@ -105,6 +127,20 @@ type GitInclude struct {
 	IfConfig string `yaml:"ifconfig,omitempty"`
 }
 
+// GitIgnoreConfig declares the global gitignore. The rendered template, if
+// any, comes first and the patterns follow it.
+type GitIgnoreConfig struct {
+	Path     string            `yaml:"path,omitempty"`     // Defaults to core.excludesfile or ~/.config/git/ignore
+	Template string            `yaml:"template,omitempty"` // Relative to the configuration directory
+	Vars     map[string]string `yaml:"vars,omitempty"`
+	Patterns []string          `yaml:"patterns,omitempty"`
+}
+
+// IsZero reports whether no global gitignore is declared.
+func (c GitIgnoreConfig) IsZero() bool {
+	return c.Path == "" && c.Template == "" && len(c.Vars) == 0 && len(c.Patterns) == 0
+}
+
 // GitConfig represents git configuration.
 type GitConfig struct {
 	User         GitUserConfig     `yaml:"user,omitempty"`
@@ -113,6 +149,7 @@ type GitConfig struct {
 	GPG          GitGPGConfig      `yaml:"gpg,omitempty"`
 	Aliases      map[string]string `yaml:"alias,omitempty"`
 	Includes     []GitInclude      `yaml:"includes,omitempty"`
+	Ignore       GitIgnoreConfig   `yaml:"ignore,omitempty"`
 	ConfigSource string            `yaml:"config_source,omitempty"` // Path to gitconfig.d directory
 }
 
@@ -123,6 +160,9 @@ type SSHDefaultsConfig struct {
 	ForwardAgent        bool `yaml:"forwardagent,omitempty"`
 	ServerAliveInterval int  `yaml:"serveraliveinterval,omitempty"`
 	ServerAliveCountMax int  `yaml:"serveralivecountmax,omitempty"`
+	// IdentityAgent is the socket of the SSH agent to use, such as the
+	// 1Password agent.
+	IdentityAgent string `yaml:"identityagent,omitempty"`
 }
 
 // SSHHostConfig represents an SSH Host block.
@@ -165,6 +205,8 @@ type SSHConfig struct {
 type RuntimeToolConfig struct {
 	Name    string `yaml:"name"`
 	Version string `yaml:"version"`
+
+	Bins []string `yaml:"bins,omitempty"` // Commands the tool provides, for shims (default: the tool name)
 }
 
 // RuntimePluginConfig represents a custom plugin source.
@@ -179,18 +221,23 @@ type RuntimeConfig struct {
 	Scope   string                `yaml:"scope,omitempty"`
 	Tools   []RuntimeToolConfig   `yaml:"tools,omitempty"`
 	Plugins []RuntimePluginConfig `yaml:"plugins,omitempty"`
+
+	Shims bool `yaml:"shims,omitempty"` // Route tool commands to the declared versions via the preflight shims directory
 }
 
 // TmuxConfig represents tmux configuration.
 type TmuxConfig struct {
-	ConfigSource string   `yaml:"config_source,omitempty"` // Path to tmux config (e.g., "dotfiles/tmux")
-	Plugins      []string `yaml:"plugins,omitempty"`       // TPM plugins
+	ConfigSource  string            `yaml:"config_source,omitempty"`  // Path to tmux config (e.g., "dotfiles/tmux")
+	Plugins       []string          `yaml:"plugins,omitempty"`        // TPM plugins
+	Settings      map[string]string `yaml:"settings,omitempty"`       // Global options (set -g)
+	UpdatePlugins bool              `yaml:"update_plugins,omitempty"` // Keep plugins at their latest upstream commit
 }
 
 // ShellCustomPlugin represents a custom shell plugin from a git repository.
 type ShellCustomPlugin struct {
 	Name string `yaml:"name"`
 	Repo string `yaml:"repo"`
+	Rev  string `yaml:"rev,omitempty"` // Pinned commit, tag or branch
 }
 
 // ShellConfigEntry represents a single shell configuration.
@@ -224,6 +271,7 @@ type ShellConfig struct {
 	Starship     ShellStarshipConfig `yaml:"starship,omitempty"`
 	Env          map[string]string   `yaml:"env,omitempty"`
 	Aliases      map[string]string   `yaml:"aliases,omitempty"`
+	Functions    map[string]string   `yaml:"functions,omitempty"`     // Function name to body
 	ConfigSource *ShellConfigSource  `yaml:"config_source,omitempty"` // Paths to shell config files
 }
 
@@ -232,9 +280,10 @@ type NvimConfig struct {
 	Preset        string   `yaml:"preset,omitempty"`
 	PluginManager string   `yaml:"plugin_manager,omitempty"`
 	ConfigRepo    string   `yaml:"config_repo,omitempty"`
-	EnsureInstall bool     `yaml:"ensure_install,omitempty"`
+	EnsureInstall bool     `yaml:"ensure_install,omitempty" deprecated:"it has no effect; set plugin_manager: lazy to sync plugins during apply"`
 	ConfigSource  string   `yaml:"config_source,omitempty"` // Path to local dotfiles (e.g., "dotfiles/nvim")
 	ExtraPlugins  []string `yaml:"extra_plugins,omitempty"` // Additional plugins for layer-specific customization
+	Packs         []string `yaml:"packs,omitempty"`         // Language packs stacked on the preset (go, rust, python, web)
 }
 
 // VSCodeKeybinding represents a single VSCode keybinding.
@@ -251,12 +300,24 @@ type VSCodeConfig struct {
 	Settings     map[string]interface{} `yaml:"settings,omitempty"`
 	Keybindings  []VSCodeKeybinding     `yaml:"keybindings,omitempty"`
 	ConfigSource string                 `yaml:"config_source,omitempty"` // Path to local dotfiles (e.g., "dotfiles/vscode")
+	Profile      *VSCodeProfile         `yaml:"profile,omitempty"`
+}
+
+// VSCodeProfile exports the extensions, settings and keybindings of a
+// target as a VS Code profile.
+type VSCodeProfile struct {
+	Export bool   `yaml:"export,omitempty"` // Write vscode/profiles/<name>.code-profile in the preflight data directory on apply
+	Name   string `yaml:"name,omitempty"`   // Profile name (default: the target name)
+	Switch bool   `yaml:"switch,omitempty"` // Open VS Code with the profile on 'preflight profile switch'
 }
 
 // Layer is a composable configuration overlay.
 type Layer struct {
 	Name       LayerName
 	Provenance string
+	// Requires lists the layers this layer builds on; a target that uses
+	// it must include them too.
+	Requires   []LayerName
 	Packages   PackageSet
 	Files      []FileDeclaration
 	Git        GitConfig
@@ -266,20 +327,62 @@ type Layer struct {
 	Nvim       NvimConfig
 	VSCode     VSCodeConfig
 	Tmux       TmuxConfig
+	Terminal   TerminalConfig
+	Docker     DockerConfig
+	Kubernetes KubernetesConfig
+	AWS        AWSConfig
+	GCloud     GCloudConfig
+	Azure      AzureConfig
+	Cron       CronConfig
+	MacOS      MacOSConfig
+	Network    NetworkConfig
+	Scripts    ScriptsConfig
+	Path       PathConfig
+	Direnv     DirenvConfig
+	Onboarding OnboardingConfig
+	Pins       []Pin
+	Checks     ChecksConfig
+	Doctor     DoctorConfig
+
+	// EditorConfig declares the default ~/.editorconfig
+	EditorConfig EditorConfigConfig
+
+	// PasswordManager declares the password manager CLIs secrets resolve through
+	PasswordManager PasswordManagerConfig
 }
 
 // layerYAML is the YAML representation for unmarshaling.
 type layerYAML struct {
-	Name     string            `yaml:"name"`
-	Packages PackageSet        `yaml:"packages,omitempty"`
-	Files    []FileDeclaration `yaml:"files,omitempty"`
-	Git      GitConfig         `yaml:"git,omitempty"`
-	SSH      SSHConfig         `yaml:"ssh,omitempty"`
-	Runtime  RuntimeConfig     `yaml:"runtime,omitempty"`
-	Shell    ShellConfig       `yaml:"shell,omitempty"`
-	Nvim     NvimConfig        `yaml:"nvim,omitempty"`
-	VSCode   VSCodeConfig      `yaml:"vscode,omitempty"`
-	Tmux     TmuxConfig        `yaml:"tmux,omitempty"`
+	Name       string            `yaml:"name"`
+	Requires   []string          `yaml:"requires,omitempty"`
+	Packages   PackageSet        `yaml:"packages,omitempty"`
+	Files      []FileDeclaration `yaml:"files,omitempty"`
+	Git        GitConfig         `yaml:"git,omitempty"`
+	SSH        SSHConfig         `yaml:"ssh,omitempty"`
+	Runtime    RuntimeConfig     `yaml:"runtime,omitempty"`
+	Shell      ShellConfig       `yaml:"shell,omitempty"`
+	Nvim       NvimConfig        `yaml:"nvim,omitempty"`
+	VSCode     VSCodeConfig      `yaml:"vscode,omitempty"`
+	Tmux       TmuxConfig        `yaml:"tmux,omitempty"`
+	Terminal   TerminalConfig    `yaml:"terminal,omitempty"`
+	Docker     DockerConfig      `yaml:"docker,omitempty"`
+	Kubernetes KubernetesConfig  `yaml:"kubernetes,omitempty"`
+	AWS        AWSConfig         `yaml:"aws,omitempty"`
+	GCloud     GCloudConfig      `yaml:"gcloud,omitempty"`
+	Azure      AzureConfig       `yaml:"azure,omitempty"`
+	Cron       CronConfig        `yaml:"cron,omitempty"`
+	MacOS      MacOSConfig       `yaml:"macos,omitempty"`
+	Network    NetworkConfig     `yaml:"network,omitempty"`
+	Scripts    ScriptsConfig     `yaml:"scripts,omitempty"`
+	Path       PathConfig        `yaml:"path,omitempty"`
+	Direnv     DirenvConfig      `yaml:"direnv,omitempty"`
+	Onboarding OnboardingConfig  `yaml:"onboarding,omitempty"`
+	Pins       []Pin             `yaml:"pins,omitempty"`
+	Checks     ChecksConfig      `yaml:"checks,omitempty"`
+	Doctor     DoctorConfig      `yaml:"doctor,omitempty"`
+
+	PasswordManager PasswordManagerConfig `yaml:"password_manager,omitempty"`
+	EditorConfig    EditorConfigConfig    `yaml:"editorconfig,omitempty"`
 }
 
 // ParseLayer parses a Layer from YAML bytes.
@@ -293,18 +396,99 @@ func ParseLayer(data []byte) (*Layer, error) {
 	if err != nil {
 		return nil, err
 	}
+	requires, err := parseRequires(name, raw.Requires)
+	if err != nil {
+		return nil, err
+	}
+	if err := raw.Packages.Brew.normalize(); err != nil {
+		return nil, err
+	}
+	if err := raw.Packages.Mas.normalize(); err != nil {
+		return nil, err
+	}
+	if err := raw.Path.normalize(); err != nil {
+		return nil, err
+	}
+	if err := raw.Direnv.normalize(); err != nil {
+		return nil, err
+	}
+	if err := raw.Terminal.normalize(); err != nil {
+		return nil, err
+	}
+	if err := raw.Docker.normalize(); err != nil {
+		return nil, err
+	}
+	if err := raw.Kubernetes.normalize(); err != nil {
+		return nil, err
+	}
+	if err := raw.AWS.normalize(); err != nil {
+		return nil, err
+	}
+	if err := raw.GCloud.normalize(); err != nil {
+		return nil, err
+	}
+	if err := raw.Azure.normalize(); err != nil {
+		return nil, err
+	}
+	if err := raw.Cron.normalize(); err != nil {
+		return nil, err
+	}
+	if err := raw.Network.normalize(); err != nil {
+		return nil, err
+	}
+	if err := raw.Scripts.normalize(); err != nil {
+		return nil, err
+	}
+	if err := raw.Onboarding.normalize(); err != nil {
+		return nil, err
+	}
+	if err := normalizePins(raw.Pins); err != nil {
+		return nil, err
+	}
+	if err := raw.Checks.normalize(); err != nil {
+		return nil, err
+	}
+	if err := raw.Doctor.normalize(); err != nil {
+		return nil, err
+	}
+	if err := raw.PasswordManager.normalize(); err != nil {
+		return nil, err
+	}
+	if err := raw.EditorConfig.normalize(); err != nil {
+		return nil, err
+	}
 
 	return &Layer{
-		Name:     name,
-		Packages: raw.Packages,
-		Files:    raw.Files,
-		Git:      raw.Git,
-		SSH:      raw.SSH,
-		Runtime:  raw.Runtime,
-		Shell:    raw.Shell,
-		Nvim:     raw.Nvim,
-		VSCode:   raw.VSCode,
-		Tmux:     raw.Tmux,
+		Name:       name,
+		Requires:   requires,
+		Packages:   raw.Packages,
+		Files:      raw.Files,
+		Git:        raw.Git,
+		SSH:        raw.SSH,
+		Runtime:    raw.Runtime,
+		Shell:      raw.Shell,
+		Nvim:       raw.Nvim,
+		VSCode:     raw.VSCode,
+		Tmux:       raw.Tmux,
+		Terminal:   raw.Terminal,
+		Docker:     raw.Docker,
+		Kubernetes: raw.Kubernetes,
+		AWS:        raw.AWS,
+		GCloud:     raw.GCloud,
+		Azure:      raw.Azure,
+		Cron:       raw.Cron,
+		MacOS:      raw.MacOS,
+		Network:    raw.Network,
+		Scripts:    raw.Scripts,
+		Path:       raw.Path,
+		Direnv:     raw.Direnv,
+		Onboarding: raw.Onboarding,
+		Pins:       raw.Pins,
+		Checks:     raw.Checks,
+		Doctor:     raw.Doctor,
+
+		PasswordManager: raw.PasswordManager,
+		EditorConfig:    raw.EditorConfig,
 	}, nil
 }
 
@@ -77,6 +77,11 @@ func (l *Loader) LoadTarget(manifest *Manifest, target TargetName, layersDir str
 		layers = append(layers, *layer)
 	}
 
+	layers, err = l.resolveRequires(layers, target, layersDir, manifest.Defaults.Requires)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Target{
 		Name:   target,
 		Layers: layers,
@@ -101,7 +106,7 @@ func (l *Loader) Load(manifestPath string, target TargetName) (*MergedConfig, er
 	}
 
 	// Merge layers
-	merger := NewMerger()
+	merger := NewMerger().WithDuplicateStrategy(manifest.Defaults.Duplicates)
 	merged, err := merger.Merge(resolvedTarget.Layers)
 	if err != nil {
 		return nil, err
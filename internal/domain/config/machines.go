@@ -0,0 +1,92 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/sync"
+)
+
+// MachineBinding binds a target to the machines whose fingerprint matches
+// it, so apply picks the target without --target. Hostname and Chip are
+// case-insensitive globs, Serial must match exactly and MinMemoryGB is the
+// least RAM the machine has. Empty criteria match any machine, but a
+// binding needs at least one.
+type MachineBinding struct {
+	Target      string `yaml:"target"`
+	Hostname    string `yaml:"hostname,omitempty"`
+	Serial      string `yaml:"serial,omitempty"`
+	Chip        string `yaml:"chip,omitempty"`
+	MinMemoryGB int    `yaml:"min_memory_gb,omitempty"`
+}
+
+// ErrInvalidMachineBinding is returned for an invalid machines entry.
+var ErrInvalidMachineBinding = errors.New("invalid machine binding")
+
+// validate checks the binding against the manifest's targets.
+func (b MachineBinding) validate(targets map[string][]LayerName) error {
+	if _, ok := targets[b.Target]; !ok {
+		return fmt.Errorf("%w: target %q is not defined", ErrInvalidMachineBinding, b.Target)
+	}
+	if b.Hostname == "" && b.Serial == "" && b.Chip == "" && b.MinMemoryGB == 0 {
+		return fmt.Errorf("%w: target %q needs hostname, serial, chip or min_memory_gb", ErrInvalidMachineBinding, b.Target)
+	}
+	if b.MinMemoryGB < 0 {
+		return fmt.Errorf("%w: min_memory_gb must not be negative", ErrInvalidMachineBinding)
+	}
+	return nil
+}
+
+// Matches reports whether the machine with fingerprint fp is bound.
+func (b MachineBinding) Matches(fp sync.Fingerprint) bool {
+	if b.Hostname != "" && !matchGlob(b.Hostname, fp.Hostname) {
+		return false
+	}
+	if b.Serial != "" && !strings.EqualFold(b.Serial, fp.Serial) {
+		return false
+	}
+	if b.Chip != "" && !matchGlob(b.Chip, fp.Chip) {
+		return false
+	}
+	if b.MinMemoryGB > 0 && fp.MemoryGB() < b.MinMemoryGB {
+		return false
+	}
+	return true
+}
+
+// String describes the criteria of the binding, such as
+// "serial C02XYZ, min_memory_gb 32".
+func (b MachineBinding) String() string {
+	var criteria []string
+	if b.Hostname != "" {
+		criteria = append(criteria, "hostname "+b.Hostname)
+	}
+	if b.Serial != "" {
+		criteria = append(criteria, "serial "+b.Serial)
+	}
+	if b.Chip != "" {
+		criteria = append(criteria, "chip "+b.Chip)
+	}
+	if b.MinMemoryGB > 0 {
+		criteria = append(criteria, fmt.Sprintf("min_memory_gb %d", b.MinMemoryGB))
+	}
+	return strings.Join(criteria, ", ")
+}
+
+// TargetFor returns the first machine binding that matches fp.
+func (m *Manifest) TargetFor(fp sync.Fingerprint) (MachineBinding, bool) {
+	for _, b := range m.Machines {
+		if b.Matches(fp) {
+			return b, true
+		}
+	}
+	return MachineBinding{}, false
+}
+
+// matchGlob matches value against a case-insensitive glob pattern.
+func matchGlob(pattern, value string) bool {
+	matched, _ := regexp.MatchString("(?i)^"+globToRegex(pattern)+"$", value)
+	return matched
+}
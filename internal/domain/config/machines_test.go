@@ -0,0 +1,81 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/domain/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseManifest_Machines(t *testing.T) {
+	t.Parallel()
+
+	manifest, err := config.ParseManifest([]byte(`
+targets:
+  workstation: [base]
+  laptop: [base]
+machines:
+  - target: workstation
+    serial: C02XYZ
+  - target: laptop
+    chip: "Apple M*"
+    min_memory_gb: 16
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []config.MachineBinding{
+		{Target: "workstation", Serial: "C02XYZ"},
+		{Target: "laptop", Chip: "Apple M*", MinMemoryGB: 16},
+	}, manifest.Machines)
+}
+
+func TestParseManifest_InvalidMachines(t *testing.T) {
+	t.Parallel()
+
+	for _, machine := range []string{
+		"target: server\n    hostname: build-*",
+		"target: work",
+		"target: work\n    min_memory_gb: -8",
+	} {
+		_, err := config.ParseManifest([]byte("targets:\n  work: [base]\nmachines:\n  - " + machine + "\n"))
+		require.ErrorIs(t, err, config.ErrInvalidMachineBinding, machine)
+	}
+}
+
+func TestManifest_TargetFor(t *testing.T) {
+	t.Parallel()
+
+	manifest := &config.Manifest{Machines: []config.MachineBinding{
+		{Target: "workstation", Serial: "C02XYZ"},
+		{Target: "laptop", Chip: "Apple M*", MinMemoryGB: 16},
+		{Target: "server", Hostname: "build-*"},
+	}}
+
+	tests := []struct {
+		name string
+		fp   sync.Fingerprint
+		want string
+	}{
+		{"serial", sync.Fingerprint{Serial: "c02xyz", Chip: "Apple M2 Ultra", MemoryBytes: 64 << 30}, "workstation"},
+		{"chip and memory", sync.Fingerprint{Chip: "Apple M3 Pro", MemoryBytes: 18 << 30}, "laptop"},
+		{"too little memory", sync.Fingerprint{Chip: "Apple M1", MemoryBytes: 8 << 30}, ""},
+		{"hostname glob", sync.Fingerprint{Hostname: "BUILD-01"}, "server"},
+		{"no match", sync.Fingerprint{Hostname: "desktop"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			binding, ok := manifest.TargetFor(tt.fp)
+			assert.Equal(t, tt.want != "", ok)
+			assert.Equal(t, tt.want, binding.Target)
+		})
+	}
+}
+
+func TestMachineBinding_String(t *testing.T) {
+	t.Parallel()
+
+	b := config.MachineBinding{Target: "laptop", Chip: "Apple M*", MinMemoryGB: 16}
+	assert.Equal(t, "chip Apple M*, min_memory_gb 16", b.String())
+}
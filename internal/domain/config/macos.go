@@ -0,0 +1,140 @@
+package config
+
+// MacOSConfig declares macOS preferences: arbitrary defaults, the Dock
+// layout and settings, hot corners, Finder and keyboard preferences.
+type MacOSConfig struct {
+	Defaults   []MacOSDefault    `yaml:"defaults,omitempty"`
+	Dock       MacOSDock         `yaml:"dock,omitempty"`
+	HotCorners map[string]string `yaml:"hot_corners,omitempty"`
+	Finder     MacOSFinder       `yaml:"finder,omitempty"`
+	Keyboard   MacOSKeyboard     `yaml:"keyboard,omitempty"`
+}
+
+// MacOSDefault is a single 'defaults write' setting.
+type MacOSDefault struct {
+	Domain string      `yaml:"domain"`
+	Key    string      `yaml:"key"`
+	Type   string      `yaml:"type,omitempty"`
+	Value  interface{} `yaml:"value"`
+}
+
+// MacOSDock declares the Dock. Apps is the exact, ordered list of apps;
+// Add and Remove adjust an unmanaged Dock instead.
+type MacOSDock struct {
+	Apps        []string `yaml:"apps,omitempty"`
+	Add         []string `yaml:"add,omitempty"`
+	Remove      []string `yaml:"remove,omitempty"`
+	Autohide    *bool    `yaml:"autohide,omitempty"`
+	TileSize    *int     `yaml:"tile_size,omitempty"`
+	Orientation string   `yaml:"orientation,omitempty"`
+	ShowRecents *bool    `yaml:"show_recents,omitempty"`
+}
+
+// MacOSFinder declares Finder preferences.
+type MacOSFinder struct {
+	ShowHidden            *bool  `yaml:"show_hidden,omitempty"`
+	ShowExtensions        *bool  `yaml:"show_extensions,omitempty"`
+	ShowPathBar           *bool  `yaml:"show_path_bar,omitempty"`
+	ShowStatusBar         *bool  `yaml:"show_status_bar,omitempty"`
+	DefaultView           string `yaml:"default_view,omitempty"`
+	NewWindowTarget       string `yaml:"new_window_target,omitempty"`
+	SearchCurrentFolder   *bool  `yaml:"search_current_folder,omitempty"`
+	FoldersFirst          *bool  `yaml:"folders_first,omitempty"`
+	WarnOnExtensionChange *bool  `yaml:"warn_on_extension_change,omitempty"`
+}
+
+// MacOSKeyboard declares key repeat preferences.
+type MacOSKeyboard struct {
+	KeyRepeat        *int `yaml:"key_repeat,omitempty"`
+	InitialKeyRepeat *int `yaml:"initial_key_repeat,omitempty"`
+}
+
+// IsZero reports whether no macOS configuration is declared.
+func (c MacOSConfig) IsZero() bool {
+	return len(c.Defaults) == 0 && len(c.HotCorners) == 0 &&
+		c.Dock.IsZero() && c.Finder == (MacOSFinder{}) && c.Keyboard == (MacOSKeyboard{})
+}
+
+// IsZero reports whether no Dock configuration is declared.
+func (d MacOSDock) IsZero() bool {
+	return len(d.Apps) == 0 && len(d.Add) == 0 && len(d.Remove) == 0 &&
+		d.Autohide == nil && d.TileSize == nil && d.Orientation == "" && d.ShowRecents == nil
+}
+
+// mergeMacOS combines the macos configuration of layers. Defaults are keyed
+// by domain and key, hot corners by corner, and preferences are last-wins.
+// The Dock app list is replaced as a whole by the last layer declaring one,
+// since its order is significant; add and remove are set unions.
+func mergeMacOS(layers []Layer) MacOSConfig {
+	var merged MacOSConfig
+	for _, layer := range layers {
+		m := layer.MacOS
+		merged.Defaults = mergeNamed(merged.Defaults, m.Defaults, func(d MacOSDefault) string { return d.Domain + "\x00" + d.Key })
+
+		if len(m.Dock.Apps) > 0 {
+			merged.Dock.Apps = m.Dock.Apps
+		}
+		merged.Dock.Add = appendUnique(merged.Dock.Add, m.Dock.Add)
+		merged.Dock.Remove = appendUnique(merged.Dock.Remove, m.Dock.Remove)
+		mergePtr(&merged.Dock.Autohide, m.Dock.Autohide)
+		mergePtr(&merged.Dock.TileSize, m.Dock.TileSize)
+		mergePtr(&merged.Dock.ShowRecents, m.Dock.ShowRecents)
+		if m.Dock.Orientation != "" {
+			merged.Dock.Orientation = m.Dock.Orientation
+		}
+
+		for corner, action := range m.HotCorners {
+			if merged.HotCorners == nil {
+				merged.HotCorners = make(map[string]string)
+			}
+			merged.HotCorners[corner] = action
+		}
+
+		f := m.Finder
+		mergePtr(&merged.Finder.ShowHidden, f.ShowHidden)
+		mergePtr(&merged.Finder.ShowExtensions, f.ShowExtensions)
+		mergePtr(&merged.Finder.ShowPathBar, f.ShowPathBar)
+		mergePtr(&merged.Finder.ShowStatusBar, f.ShowStatusBar)
+		mergePtr(&merged.Finder.SearchCurrentFolder, f.SearchCurrentFolder)
+		mergePtr(&merged.Finder.FoldersFirst, f.FoldersFirst)
+		mergePtr(&merged.Finder.WarnOnExtensionChange, f.WarnOnExtensionChange)
+		if f.DefaultView != "" {
+			merged.Finder.DefaultView = f.DefaultView
+		}
+		if f.NewWindowTarget != "" {
+			merged.Finder.NewWindowTarget = f.NewWindowTarget
+		}
+
+		mergePtr(&merged.Keyboard.KeyRepeat, m.Keyboard.KeyRepeat)
+		mergePtr(&merged.Keyboard.InitialKeyRepeat, m.Keyboard.InitialKeyRepeat)
+	}
+	// A whole-Dock layout supersedes incremental edits from other layers.
+	if len(merged.Dock.Apps) > 0 {
+		merged.Dock.Add, merged.Dock.Remove = nil, nil
+	}
+	return merged
+}
+
+// mergePtr overwrites dst when src is set.
+func mergePtr[T any](dst **T, src *T) {
+	if src != nil {
+		*dst = src
+	}
+}
+
+// appendUnique appends the items of src that are not yet in dst.
+func appendUnique(dst, src []string) []string {
+	for _, item := range src {
+		found := false
+		for _, existing := range dst {
+			if existing == item {
+				found = true
+				break
+			}
+		}
+		if !found {
+			dst = append(dst, item)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLayer_MacOS(t *testing.T) {
+	t.Parallel()
+
+	layer, err := ParseLayer([]byte(`
+name: base
+macos:
+  dock:
+    apps: [Safari, Mail, /opt/Tool.app]
+    autohide: true
+  hot_corners:
+    top_right: desktop
+  finder:
+    show_hidden: false
+    default_view: column
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Safari", "Mail", "/opt/Tool.app"}, layer.MacOS.Dock.Apps)
+	require.NotNil(t, layer.MacOS.Finder.ShowHidden)
+	assert.False(t, *layer.MacOS.Finder.ShowHidden)
+	assert.False(t, layer.MacOS.IsZero())
+}
+
+func TestMerger_Merge_MacOS(t *testing.T) {
+	t.Parallel()
+
+	yes, no, size := true, false, 48
+	base := Layer{MacOS: MacOSConfig{
+		Defaults:   []MacOSDefault{{Domain: "NSGlobalDomain", Key: "AppleShowScrollBars", Value: "Always"}},
+		Dock:       MacOSDock{Apps: []string{"Safari", "Mail"}, Autohide: &yes, TileSize: &size},
+		HotCorners: map[string]string{"top_left": "mission-control", "top_right": "desktop"},
+		Finder:     MacOSFinder{ShowHidden: &yes},
+	}}
+	work := Layer{MacOS: MacOSConfig{
+		Defaults:   []MacOSDefault{{Domain: "NSGlobalDomain", Key: "AppleShowScrollBars", Value: "WhenScrolling"}},
+		Dock:       MacOSDock{Apps: []string{"Slack", "Safari"}, Add: []string{"Notes"}, Autohide: &no},
+		HotCorners: map[string]string{"top_right": "lock-screen"},
+	}}
+
+	merged, err := NewMerger().Merge([]Layer{base, work})
+	require.NoError(t, err)
+	m := merged.MacOS
+	assert.Equal(t, "WhenScrolling", m.Defaults[0].Value)
+	assert.Equal(t, []string{"Slack", "Safari"}, m.Dock.Apps)
+	assert.Nil(t, m.Dock.Add)
+	assert.False(t, *m.Dock.Autohide)
+	assert.Equal(t, 48, *m.Dock.TileSize)
+	assert.Equal(t, map[string]string{"top_left": "mission-control", "top_right": "lock-screen"}, m.HotCorners)
+	assert.True(t, *m.Finder.ShowHidden)
+
+	raw := merged.Raw()["macos"].(map[string]interface{})
+	dock := raw["dock"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"Slack", "Safari"}, dock["apps"])
+	assert.Equal(t, false, dock["autohide"])
+	assert.Equal(t, 48, dock["tile_size"])
+}
@@ -2,6 +2,8 @@ package config
 
 import (
 	"errors"
+	"fmt"
+	"regexp"
 
 	"gopkg.in/yaml.v3"
 )
@@ -18,28 +20,94 @@ const (
 	ModeFrozen ReproducibilityMode = "frozen"
 )
 
+// DuplicateStrategy controls how a package declared by several layers of a
+// target is handled. It is installed once either way; the strategy decides
+// whether validate warns and which layer it is attributed to.
+type DuplicateStrategy string
+
+const (
+	// DuplicatesWarn reports duplicates in validate. It is the default.
+	DuplicatesWarn DuplicateStrategy = "warn"
+	// DuplicatesKeepFirst attributes a duplicate to the first layer declaring it.
+	DuplicatesKeepFirst DuplicateStrategy = "keep-first"
+	// DuplicatesKeepMostSpecific attributes a duplicate to the last layer
+	// declaring it, the most specific one.
+	DuplicatesKeepMostSpecific DuplicateStrategy = "keep-most-specific"
+)
+
+// RequiresStrategy controls what happens when a target omits a layer that
+// one of its layers requires.
+type RequiresStrategy string
+
+const (
+	// RequiresError fails to load the target. It is the default.
+	RequiresError RequiresStrategy = "error"
+	// RequiresInclude adds the missing layer in front of the layer
+	// requiring it.
+	RequiresInclude RequiresStrategy = "include"
+)
+
 // DefaultConfig holds manifest-level defaults.
 type DefaultConfig struct {
-	Mode   ReproducibilityMode `yaml:"mode,omitempty"`
-	Editor string              `yaml:"editor,omitempty"`
+	Mode       ReproducibilityMode `yaml:"mode,omitempty"`
+	Editor     string              `yaml:"editor,omitempty"`
+	Duplicates DuplicateStrategy   `yaml:"duplicates,omitempty"`
+	Requires   RequiresStrategy    `yaml:"requires,omitempty"`
+}
+
+// HistoryConfig bounds the operation history kept in ~/.preflight/history.
+// Keep is an age such as 90d (units h, d, w, m); entries older than it
+// and beyond the newest MaxEntries are pruned. Zero values use the
+// defaults.
+type HistoryConfig struct {
+	Keep       string `yaml:"keep,omitempty"`
+	MaxEntries int    `yaml:"max_entries,omitempty"`
+}
+
+// AIConfig limits the use of AI features. MonthlyBudget is in US dollars
+// of estimated provider cost per calendar month; zero means no limit.
+type AIConfig struct {
+	MonthlyBudget float64 `yaml:"monthly_budget,omitempty"`
 }
 
 // Manifest is the root configuration (preflight.yaml).
 type Manifest struct {
 	Defaults DefaultConfig
-	Targets  map[string][]LayerName
+	History  HistoryConfig
+	AI       AIConfig
+	Upgrades UpgradesConfig
+	// Roles name sets of layers for job families, such as backend or data.
+	Roles map[string][]LayerName
+	// Targets holds the layers of each target, with roles expanded and
+	// extended targets resolved.
+	Targets map[string][]LayerName
+	// Extensions holds the targets defined by extending others, by name.
+	Extensions map[string]TargetExtension
+	// Machines binds targets to machine fingerprints, first match wins.
+	Machines []MachineBinding
 }
 
 // Errors for Manifest validation.
 var (
-	ErrNoTargets      = errors.New("manifest must define at least one target")
-	ErrTargetNotFound = errors.New("target not found")
+	ErrNoTargets            = errors.New("manifest must define at least one target")
+	ErrTargetNotFound       = errors.New("target not found")
+	ErrInvalidHistoryConfig = errors.New("invalid history config")
+	ErrInvalidDuplicates    = errors.New("invalid duplicates strategy")
+	ErrInvalidRequires      = errors.New("invalid requires strategy")
+	ErrInvalidAIConfig      = errors.New("invalid ai config")
 )
 
+var historyKeepPattern = regexp.MustCompile(`^[1-9][0-9]*[hdwm]$`)
+
 // manifestYAML is the YAML representation for unmarshaling.
 type manifestYAML struct {
-	Defaults DefaultConfig       `yaml:"defaults,omitempty"`
-	Targets  map[string][]string `yaml:"targets"`
+	Defaults DefaultConfig         `yaml:"defaults,omitempty"`
+	History  HistoryConfig         `yaml:"history,omitempty"`
+	AI       AIConfig              `yaml:"ai,omitempty"`
+	Upgrades UpgradesConfig        `yaml:"upgrades,omitempty"`
+	Roles    map[string][]string   `yaml:"roles,omitempty"`
+	Targets  map[string]targetYAML `yaml:"targets"`
+	Machines []MachineBinding      `yaml:"machines,omitempty"`
 }
 
 // ParseManifest parses a Manifest from YAML bytes.
@@ -52,23 +120,64 @@ func ParseManifest(data []byte) (*Manifest, error) {
 	if len(raw.Targets) == 0 {
 		return nil, ErrNoTargets
 	}
+	if raw.History.Keep != "" && !historyKeepPattern.MatchString(raw.History.Keep) {
+		return nil, fmt.Errorf("%w: keep %q must be a number followed by h, d, w or m", ErrInvalidHistoryConfig, raw.History.Keep)
+	}
+	if raw.History.MaxEntries < 0 {
+		return nil, fmt.Errorf("%w: max_entries must not be negative", ErrInvalidHistoryConfig)
+	}
+	switch raw.Defaults.Duplicates {
+	case "", DuplicatesWarn, DuplicatesKeepFirst, DuplicatesKeepMostSpecific:
+	default:
+		return nil, fmt.Errorf("%w: %q must be warn, keep-first or keep-most-specific", ErrInvalidDuplicates, raw.Defaults.Duplicates)
+	}
+	switch raw.Defaults.Requires {
+	case "", RequiresError, RequiresInclude:
+	default:
+		return nil, fmt.Errorf("%w: %q must be error or include", ErrInvalidRequires, raw.Defaults.Requires)
+	}
+	if raw.AI.MonthlyBudget < 0 {
+		return nil, fmt.Errorf("%w: monthly_budget must not be negative", ErrInvalidAIConfig)
+	}
+	if raw.Upgrades.Window != "" {
+		if _, err := ParseMaintenanceWindow(raw.Upgrades.Window); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidUpgradesConfig, err)
+		}
+	}
+	if raw.Upgrades.NotifyAfter < 0 {
+		return nil, fmt.Errorf("%w: notify_after must not be negative", ErrInvalidUpgradesConfig)
+	}
+	if raw.Upgrades.PinMaxAge != "" {
+		if _, err := parseAge(raw.Upgrades.PinMaxAge); err != nil {
+			return nil, fmt.Errorf("%w: pin_max_age: %w", ErrInvalidUpgradesConfig, err)
+		}
+	}
+
+	roles, err := parseRoles(raw.Roles)
+	if err != nil {
+		return nil, err
+	}
+
+	targets, extensions, err := resolveTargets(raw.Targets, roles)
+	if err != nil {
+		return nil, err
+	}
 
-	targets := make(map[string][]LayerName)
-	for targetName, layerNames := range raw.Targets {
-		layers := make([]LayerName, 0, len(layerNames))
-		for _, name := range layerNames {
-			ln, err := NewLayerName(name)
-			if err != nil {
-				return nil, err
-			}
-			layers = append(layers, ln)
+	for _, b := range raw.Machines {
+		if err := b.validate(targets); err != nil {
+			return nil, err
 		}
-		targets[targetName] = layers
 	}
 
 	return &Manifest{
-		Defaults: raw.Defaults,
-		Targets:  targets,
+		Defaults:   raw.Defaults,
+		History:    raw.History,
+		AI:         raw.AI,
+		Upgrades:   raw.Upgrades,
+		Roles:      roles,
+		Targets:    targets,
+		Extensions: extensions,
+		Machines:   raw.Machines,
 	}, nil
 }
 
@@ -79,6 +79,45 @@ targets:
 	assert.Equal(t, "nvim", manifest.Defaults.Editor)
 }
 
+func TestParseManifest_WithHistory_ParsesRetention(t *testing.T) {
+	t.Parallel()
+
+	yaml := `
+history:
+  keep: 90d
+  max_entries: 200
+
+targets:
+  work:
+    - base
+`
+
+	manifest, err := config.ParseManifest([]byte(yaml))
+
+	require.NoError(t, err)
+	assert.Equal(t, config.HistoryConfig{Keep: "90d", MaxEntries: 200}, manifest.History)
+}
+
+func TestParseManifest_InvalidHistory_ReturnsError(t *testing.T) {
+	t.Parallel()
+
+	for _, history := range []string{"keep: 90 days", "keep: 0d", "max_entries: -1"} {
+		_, err := config.ParseManifest([]byte("history:\n  " + history + "\ntargets:\n  work:\n    - base\n"))
+		require.ErrorIs(t, err, config.ErrInvalidHistoryConfig, history)
+	}
+}
+
+func TestParseManifest_Duplicates(t *testing.T) {
+	t.Parallel()
+
+	manifest, err := config.ParseManifest([]byte("defaults:\n  duplicates: keep-first\ntargets:\n  work:\n    - base\n"))
+	require.NoError(t, err)
+	assert.Equal(t, config.DuplicatesKeepFirst, manifest.Defaults.Duplicates)
+
+	_, err = config.ParseManifest([]byte("defaults:\n  duplicates: keep-last\ntargets:\n  work:\n    - base\n"))
+	require.ErrorIs(t, err, config.ErrInvalidDuplicates)
+}
+
 func TestParseManifest_MissingTargets_ReturnsError(t *testing.T) {
 	t.Parallel()
 
@@ -161,3 +200,14 @@ targets:
 	require.Error(t, err)
 	require.ErrorIs(t, err, config.ErrTargetNotFound)
 }
+
+func TestParseManifest_AIBudget(t *testing.T) {
+	t.Parallel()
+
+	manifest, err := config.ParseManifest([]byte("ai:\n  monthly_budget: 5.5\ntargets:\n  work:\n    - base\n"))
+	require.NoError(t, err)
+	assert.InDelta(t, 5.5, manifest.AI.MonthlyBudget, 1e-9)
+
+	_, err = config.ParseManifest([]byte("ai:\n  monthly_budget: -1\ntargets:\n  work:\n    - base\n"))
+	require.ErrorIs(t, err, config.ErrInvalidAIConfig)
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidMasApp is returned when a layer declares an App Store app
+// without a valid store ID.
+var ErrInvalidMasApp = errors.New("invalid mas app")
+
+// MasPackages represents Mac App Store apps installed with mas.
+type MasPackages struct {
+	Apps []MasApp `yaml:"apps,omitempty"`
+}
+
+// MasApp is a Mac App Store app, identified by its numeric store ID. The
+// name is informational.
+type MasApp struct {
+	ID   int64  `yaml:"id"`
+	Name string `yaml:"name,omitempty"`
+}
+
+func (p *MasPackages) normalize() error {
+	seen := make(map[int64]bool, len(p.Apps))
+	for i, app := range p.Apps {
+		if app.ID <= 0 {
+			return fmt.Errorf("%w: app %d needs a positive id", ErrInvalidMasApp, i+1)
+		}
+		if seen[app.ID] {
+			return fmt.Errorf("%w: duplicate app id %d", ErrInvalidMasApp, app.ID)
+		}
+		seen[app.ID] = true
+	}
+	return nil
+}
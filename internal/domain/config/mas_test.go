@@ -0,0 +1,47 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLayer_MasApps(t *testing.T) {
+	t.Parallel()
+
+	layer, err := ParseLayer([]byte(`
+name: apps
+packages:
+  mas:
+    apps:
+      - id: 497799835
+        name: Xcode
+      - id: 6444602274
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []MasApp{{ID: 497799835, Name: "Xcode"}, {ID: 6444602274}}, layer.Packages.Mas.Apps)
+
+	_, err = ParseLayer([]byte("name: apps\npackages:\n  mas:\n    apps:\n      - name: Xcode\n"))
+	require.ErrorIs(t, err, ErrInvalidMasApp)
+	_, err = ParseLayer([]byte("name: apps\npackages:\n  mas:\n    apps:\n      - id: 1\n      - id: 1\n"))
+	require.ErrorIs(t, err, ErrInvalidMasApp)
+}
+
+func TestMerger_Merge_MasApps(t *testing.T) {
+	t.Parallel()
+
+	base := Layer{Packages: PackageSet{Mas: MasPackages{Apps: []MasApp{{ID: 497799835}, {ID: 1295203466, Name: "Microsoft Remote Desktop"}}}}}
+	work := Layer{Packages: PackageSet{Mas: MasPackages{Apps: []MasApp{{ID: 497799835, Name: "Xcode"}, {ID: 904280696, Name: "Things 3"}}}}}
+
+	merged, err := NewMerger().Merge([]Layer{base, work})
+	require.NoError(t, err)
+	assert.Equal(t, []MasApp{
+		{ID: 497799835, Name: "Xcode"},
+		{ID: 1295203466, Name: "Microsoft Remote Desktop"},
+		{ID: 904280696, Name: "Things 3"},
+	}, merged.Packages.Mas.Apps)
+
+	apps := merged.Raw()["mas"].(map[string]interface{})["apps"].([]interface{})
+	assert.Equal(t, map[string]interface{}{"id": int64(497799835), "name": "Xcode"}, apps[0])
+}
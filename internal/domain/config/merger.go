@@ -1,6 +1,11 @@
 package config
 
-import "sort"
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
 
 // ProvenanceMap tracks which layer each value came from.
 type ProvenanceMap map[string]map[string]string
@@ -16,7 +21,28 @@ type MergedConfig struct {
 	Nvim       NvimConfig
 	VSCode     VSCodeConfig
 	Tmux       TmuxConfig
+	Terminal   TerminalConfig
+	Docker     DockerConfig
+	Kubernetes KubernetesConfig
+	AWS        AWSConfig
+	GCloud     GCloudConfig
+	Azure      AzureConfig
+	Cron       CronConfig
+	MacOS      MacOSConfig
+	Network    NetworkConfig
+	Scripts    ScriptsConfig
+	Path       PathConfig
+	Direnv     DirenvConfig
+	Pins       []Pin
+	Checks     ChecksConfig
+	Doctor     DoctorConfig
 	provenance ProvenanceMap
+
+	// PasswordManager is the merged password_manager section
+	PasswordManager PasswordManagerConfig
+
+	// EditorConfig is the merged editorconfig section
+	EditorConfig EditorConfigConfig
 }
 
 // GetProvenance returns the source layer for a given path and value.
@@ -31,13 +57,22 @@ func (m *MergedConfig) GetProvenance(path, value string) string {
 }
 
 // Merger merges multiple layers into a single MergedConfig.
-type Merger struct{}
+type Merger struct {
+	duplicates DuplicateStrategy
+}
 
 // NewMerger creates a new Merger.
 func NewMerger() *Merger {
 	return &Merger{}
 }
 
+// WithDuplicateStrategy sets which layer a list entry declared by several
+// layers is attributed to. By default it is the last one.
+func (m *Merger) WithDuplicateStrategy(strategy DuplicateStrategy) *Merger {
+	m.duplicates = strategy
+	return m
+}
+
 // Merge combines layers according to merge semantics.
 // - Scalars: last-wins
 // - Maps: deep merge
@@ -90,6 +125,7 @@ func (m *Merger) Merge(layers []Layer) (*MergedConfig, error) {
 	pipPackagesSet := make(map[string]bool, pipPkgCount)
 	gemsSet := make(map[string]bool, gemCount)
 	cratesSet := make(map[string]bool, cratesCount)
+	masAppsIndex := make(map[int64]int)
 	filesMap := make(map[string]FileDeclaration, filesCount)
 	aliasesMap := make(map[string]string, aliasesCount)
 	includesSet := make(map[string]bool, includesCount)
@@ -100,6 +136,7 @@ func (m *Merger) Merge(layers []Layer) (*MergedConfig, error) {
 	shellsMap := make(map[string]ShellConfigEntry, shellsCount)
 	shellEnvMap := make(map[string]string, envCount)
 	shellAliasesMap := make(map[string]string, aliasCount)
+	shellFunctionsMap := make(map[string]string)
 	vscodeExtensionsSet := make(map[string]bool, extCount)
 	vscodeKeybindingsSet := make(map[string]bool, keybindingsCount)
 
@@ -110,7 +147,15 @@ func (m *Merger) Merge(layers []Layer) (*MergedConfig, error) {
 				formulaeSet[formula] = true
 				merged.Packages.Brew.Formulae = append(merged.Packages.Brew.Formulae, formula)
 			}
-			m.trackProvenance(merged, "packages.brew.formulae", formula, layer.Provenance)
+			m.trackListProvenance(merged, "packages.brew.formulae", formula, layer.Provenance)
+		}
+
+		// Merge brew architecture constraints; later layers win
+		for name, arch := range layer.Packages.Brew.Arch {
+			if merged.Packages.Brew.Arch == nil {
+				merged.Packages.Brew.Arch = make(map[string]string)
+			}
+			merged.Packages.Brew.Arch[name] = arch
 		}
 
 		// Merge brew casks
@@ -119,7 +164,7 @@ func (m *Merger) Merge(layers []Layer) (*MergedConfig, error) {
 				casksSet[cask] = true
 				merged.Packages.Brew.Casks = append(merged.Packages.Brew.Casks, cask)
 			}
-			m.trackProvenance(merged, "packages.brew.casks", cask, layer.Provenance)
+			m.trackListProvenance(merged, "packages.brew.casks", cask, layer.Provenance)
 		}
 
 		// Merge brew taps
@@ -128,7 +173,7 @@ func (m *Merger) Merge(layers []Layer) (*MergedConfig, error) {
 				tapsSet[tap] = true
 				merged.Packages.Brew.Taps = append(merged.Packages.Brew.Taps, tap)
 			}
-			m.trackProvenance(merged, "packages.brew.taps", tap, layer.Provenance)
+			m.trackListProvenance(merged, "packages.brew.taps", tap, layer.Provenance)
 		}
 
 		// Merge apt PPAs
@@ -137,7 +182,7 @@ func (m *Merger) Merge(layers []Layer) (*MergedConfig, error) {
 				ppasSet[ppa] = true
 				merged.Packages.Apt.PPAs = append(merged.Packages.Apt.PPAs, ppa)
 			}
-			m.trackProvenance(merged, "packages.apt.ppas", ppa, layer.Provenance)
+			m.trackListProvenance(merged, "packages.apt.ppas", ppa, layer.Provenance)
 		}
 
 		// Merge apt packages
@@ -146,7 +191,7 @@ func (m *Merger) Merge(layers []Layer) (*MergedConfig, error) {
 				aptPackagesSet[pkg] = true
 				merged.Packages.Apt.Packages = append(merged.Packages.Apt.Packages, pkg)
 			}
-			m.trackProvenance(merged, "packages.apt.packages", pkg, layer.Provenance)
+			m.trackListProvenance(merged, "packages.apt.packages", pkg, layer.Provenance)
 		}
 
 		// Merge npm packages
@@ -155,7 +200,7 @@ func (m *Merger) Merge(layers []Layer) (*MergedConfig, error) {
 				npmPackagesSet[pkg] = true
 				merged.Packages.Npm.Packages = append(merged.Packages.Npm.Packages, pkg)
 			}
-			m.trackProvenance(merged, "packages.npm.packages", pkg, layer.Provenance)
+			m.trackListProvenance(merged, "packages.npm.packages", pkg, layer.Provenance)
 		}
 
 		// Merge go tools
@@ -164,7 +209,7 @@ func (m *Merger) Merge(layers []Layer) (*MergedConfig, error) {
 				goToolsSet[tool] = true
 				merged.Packages.Go.Tools = append(merged.Packages.Go.Tools, tool)
 			}
-			m.trackProvenance(merged, "packages.go.tools", tool, layer.Provenance)
+			m.trackListProvenance(merged, "packages.go.tools", tool, layer.Provenance)
 		}
 
 		// Merge pip packages
@@ -173,7 +218,7 @@ func (m *Merger) Merge(layers []Layer) (*MergedConfig, error) {
 				pipPackagesSet[pkg] = true
 				merged.Packages.Pip.Packages = append(merged.Packages.Pip.Packages, pkg)
 			}
-			m.trackProvenance(merged, "packages.pip.packages", pkg, layer.Provenance)
+			m.trackListProvenance(merged, "packages.pip.packages", pkg, layer.Provenance)
 		}
 
 		// Merge gem packages
@@ -182,7 +227,7 @@ func (m *Merger) Merge(layers []Layer) (*MergedConfig, error) {
 				gemsSet[gem] = true
 				merged.Packages.Gem.Gems = append(merged.Packages.Gem.Gems, gem)
 			}
-			m.trackProvenance(merged, "packages.gem.gems", gem, layer.Provenance)
+			m.trackListProvenance(merged, "packages.gem.gems", gem, layer.Provenance)
 		}
 
 		// Merge cargo crates
@@ -191,12 +236,25 @@ func (m *Merger) Merge(layers []Layer) (*MergedConfig, error) {
 				cratesSet[crate] = true
 				merged.Packages.Cargo.Crates = append(merged.Packages.Cargo.Crates, crate)
 			}
-			m.trackProvenance(merged, "packages.cargo.crates", crate, layer.Provenance)
+			m.trackListProvenance(merged, "packages.cargo.crates", crate, layer.Provenance)
+		}
+
+		// Merge App Store apps (keyed by ID, later names win)
+		for _, app := range layer.Packages.Mas.Apps {
+			if i, ok := masAppsIndex[app.ID]; ok {
+				if app.Name != "" {
+					merged.Packages.Mas.Apps[i].Name = app.Name
+				}
+			} else {
+				masAppsIndex[app.ID] = len(merged.Packages.Mas.Apps)
+				merged.Packages.Mas.Apps = append(merged.Packages.Mas.Apps, app)
+			}
+			m.trackProvenance(merged, "packages.mas.apps", strconv.FormatInt(app.ID, 10), layer.Provenance)
 		}
 
 		// Merge files (last-wins for same path)
 		for _, file := range layer.Files {
-			filesMap[file.Path] = file
+			filesMap[file.Key()] = file
 			m.trackProvenance(merged, "files", file.Path, layer.Provenance)
 		}
 
@@ -260,6 +318,29 @@ func (m *Merger) Merge(layers []Layer) (*MergedConfig, error) {
 			}
 		}
 
+		// Merge global gitignore (scalars: last-wins, vars: deep merge,
+		// patterns: ordered set union)
+		if layer.Git.Ignore.Path != "" {
+			merged.Git.Ignore.Path = layer.Git.Ignore.Path
+			m.trackProvenance(merged, "git.ignore.path", layer.Git.Ignore.Path, layer.Provenance)
+		}
+		if layer.Git.Ignore.Template != "" {
+			merged.Git.Ignore.Template = layer.Git.Ignore.Template
+			m.trackProvenance(merged, "git.ignore.template", layer.Git.Ignore.Template, layer.Provenance)
+		}
+		for key, value := range layer.Git.Ignore.Vars {
+			if merged.Git.Ignore.Vars == nil {
+				merged.Git.Ignore.Vars = make(map[string]string)
+			}
+			merged.Git.Ignore.Vars[key] = value
+		}
+		for _, pattern := range layer.Git.Ignore.Patterns {
+			if !slices.Contains(merged.Git.Ignore.Patterns, pattern) {
+				merged.Git.Ignore.Patterns = append(merged.Git.Ignore.Patterns, pattern)
+			}
+			m.trackProvenance(merged, "git.ignore.patterns", pattern, layer.Provenance)
+		}
+
 		// Merge SSH config
 		if layer.SSH.Include != "" {
 			merged.SSH.Include = layer.SSH.Include
@@ -287,6 +368,10 @@ func (m *Merger) Merge(layers []Layer) (*MergedConfig, error) {
 			merged.SSH.Defaults.ServerAliveCountMax = layer.SSH.Defaults.ServerAliveCountMax
 			m.trackProvenance(merged, "ssh.defaults.serveralivecountmax", "set", layer.Provenance)
 		}
+		if layer.SSH.Defaults.IdentityAgent != "" {
+			merged.SSH.Defaults.IdentityAgent = layer.SSH.Defaults.IdentityAgent
+			m.trackProvenance(merged, "ssh.defaults.identityagent", layer.SSH.Defaults.IdentityAgent, layer.Provenance)
+		}
 
 		// Merge SSH hosts (last-wins per host name)
 		for _, host := range layer.SSH.Hosts {
@@ -318,6 +403,10 @@ func (m *Merger) Merge(layers []Layer) (*MergedConfig, error) {
 			merged.Runtime.Scope = layer.Runtime.Scope
 			m.trackProvenance(merged, "runtime.scope", layer.Runtime.Scope, layer.Provenance)
 		}
+		if layer.Runtime.Shims {
+			merged.Runtime.Shims = true
+			m.trackProvenance(merged, "runtime.shims", "true", layer.Provenance)
+		}
 
 		// Merge runtime tools (last-wins per tool name)
 		for _, tool := range layer.Runtime.Tools {
@@ -369,6 +458,12 @@ func (m *Merger) Merge(layers []Layer) (*MergedConfig, error) {
 			m.trackProvenance(merged, "shell.aliases", key, layer.Provenance)
 		}
 
+		// Merge shell functions (deep merge, last-wins per name)
+		for name, body := range layer.Shell.Functions {
+			shellFunctionsMap[name] = body
+			m.trackProvenance(merged, "shell.functions", name, layer.Provenance)
+		}
+
 		// Merge shell config_source (struct: last-wins per field)
 		if layer.Shell.ConfigSource != nil {
 			if merged.Shell.ConfigSource == nil {
@@ -430,13 +525,22 @@ func (m *Merger) Merge(layers []Layer) (*MergedConfig, error) {
 			m.trackProvenance(merged, "nvim.extra_plugins", plugin, layer.Provenance)
 		}
 
+		// Merge nvim packs (set union)
+		for _, pack := range layer.Nvim.Packs {
+			if !slices.Contains(merged.Nvim.Packs, pack) {
+				merged.Nvim.Packs = append(merged.Nvim.Packs, pack)
+			}
+			m.trackProvenance(merged, "nvim.packs", pack, layer.Provenance)
+		}
+
 		// Merge VSCode extensions (set union) - O(n) with map lookup
+		// Extension IDs are case-insensitive.
 		for _, ext := range layer.VSCode.Extensions {
-			if !vscodeExtensionsSet[ext] {
-				vscodeExtensionsSet[ext] = true
+			if !vscodeExtensionsSet[strings.ToLower(ext)] {
+				vscodeExtensionsSet[strings.ToLower(ext)] = true
 				merged.VSCode.Extensions = append(merged.VSCode.Extensions, ext)
 			}
-			m.trackProvenance(merged, "vscode.extensions", ext, layer.Provenance)
+			m.trackListProvenance(merged, "vscode.extensions", ext, layer.Provenance)
 		}
 
 		// Merge VSCode settings (deep merge, last-wins per key)
@@ -466,6 +570,23 @@ func (m *Merger) Merge(layers []Layer) (*MergedConfig, error) {
 			m.trackProvenance(merged, "vscode.config_source", layer.VSCode.ConfigSource, layer.Provenance)
 		}
 
+		// Merge VSCode profile (fields: last-wins)
+		if profile := layer.VSCode.Profile; profile != nil {
+			if merged.VSCode.Profile == nil {
+				merged.VSCode.Profile = &VSCodeProfile{}
+			}
+			if profile.Export {
+				merged.VSCode.Profile.Export = true
+			}
+			if profile.Name != "" {
+				merged.VSCode.Profile.Name = profile.Name
+			}
+			if profile.Switch {
+				merged.VSCode.Profile.Switch = true
+			}
+			m.trackProvenance(merged, "vscode.profile", profile.Name, layer.Provenance)
+		}
+
 		// Merge Tmux config (scalars: last-wins)
 		if layer.Tmux.ConfigSource != "" {
 			merged.Tmux.ConfigSource = layer.Tmux.ConfigSource
@@ -486,14 +607,81 @@ func (m *Merger) Merge(layers []Layer) (*MergedConfig, error) {
 			}
 			m.trackProvenance(merged, "tmux.plugins", plugin, layer.Provenance)
 		}
+
+		// Merge Tmux settings (deep merge, last-wins per option)
+		for key, value := range layer.Tmux.Settings {
+			if merged.Tmux.Settings == nil {
+				merged.Tmux.Settings = make(map[string]string)
+			}
+			merged.Tmux.Settings[key] = value
+			m.trackProvenance(merged, "tmux.settings", key, layer.Provenance)
+		}
+		if layer.Tmux.UpdatePlugins {
+			merged.Tmux.UpdatePlugins = true
+		}
+
+		// Track PATH entries; their order is resolved after the loop
+		for _, entry := range layer.Path.Entries() {
+			m.trackProvenance(merged, "path", entry, layer.Provenance)
+		}
+
+		// Track direnv directories; they are merged after the loop
+		for _, dir := range layer.Direnv.Dirs {
+			m.trackProvenance(merged, "direnv.dirs", dir.Path, layer.Provenance)
+		}
 	}
 
+	// Merge PATH entries (ordered, later layers first, deduplicated)
+	merged.Path = mergePaths(layers)
+
+	// Merge direnv directories (deep merge of env, last-wins per variable)
+	merged.Direnv = mergeDirenv(layers)
+
+	// Merge terminal emulator configuration
+	merged.Terminal = mergeTerminal(layers)
+
+	// Merge container runtime configuration
+	merged.Docker = mergeDocker(layers)
+
+	// Merge kubectl plugins and contexts
+	merged.Kubernetes = mergeKubernetes(layers)
+
+	// Merge cloud CLI profiles
+	merged.AWS, merged.GCloud, merged.Azure = mergeCloud(layers)
+
+	// Merge scheduled jobs
+	merged.Cron = mergeCron(layers)
+
+	// Merge macOS preferences
+	merged.MacOS = mergeMacOS(layers)
+
+	// Merge DNS, proxy and hosts settings
+	merged.Network = mergeNetwork(layers)
+
+	// Merge custom scripts
+	merged.Scripts = mergeScripts(layers)
+
+	// Merge package pins
+	merged.Pins = mergePins(layers)
+
+	// Merge post-upgrade health checks
+	merged.Checks = mergeChecks(layers)
+
+	// Merge custom doctor checks
+	merged.Doctor = mergeDoctor(layers)
+
+	// Merge password manager CLIs
+	merged.PasswordManager = mergePasswordManager(layers)
+
+	// Merge the default editorconfig
+	merged.EditorConfig = mergeEditorConfig(layers)
+
 	// Convert files map to slice (sorted by path for deterministic output)
 	for _, file := range filesMap {
 		merged.Files = append(merged.Files, file)
 	}
 	sort.Slice(merged.Files, func(i, j int) bool {
-		return merged.Files[i].Path < merged.Files[j].Path
+		return merged.Files[i].Key() < merged.Files[j].Key()
 	})
 
 	// Convert aliases map to merged config
@@ -538,10 +726,22 @@ func (m *Merger) Merge(layers []Layer) (*MergedConfig, error) {
 	if len(shellAliasesMap) > 0 {
 		merged.Shell.Aliases = shellAliasesMap
 	}
+	if len(shellFunctionsMap) > 0 {
+		merged.Shell.Functions = shellFunctionsMap
+	}
 
 	return merged, nil
 }
 
+// trackListProvenance records the source of a list entry, keeping the first
+// layer that declared it under DuplicatesKeepFirst.
+func (m *Merger) trackListProvenance(merged *MergedConfig, path, value, source string) {
+	if m.duplicates == DuplicatesKeepFirst && merged.GetProvenance(path, value) != "" {
+		return
+	}
+	m.trackProvenance(merged, path, value, source)
+}
+
 func (m *Merger) trackProvenance(merged *MergedConfig, path, value, source string) {
 	if merged.provenance[path] == nil {
 		merged.provenance[path] = make(map[string]string)
@@ -58,6 +58,36 @@ packages:
 	assert.ElementsMatch(t, []string{"git", "ripgrep", "docker", "kubectl"}, merged.Packages.Brew.Formulae)
 }
 
+func TestMerger_Merge_NvimPacks_StackOnPreset(t *testing.T) {
+	t.Parallel()
+
+	baseLayer, err := config.ParseLayer([]byte(`
+name: base
+nvim:
+  preset: minimal
+  packs: [go]
+`))
+	require.NoError(t, err)
+
+	langLayer, err := config.ParseLayer([]byte(`
+name: role.backend
+nvim:
+  packs: [rust, go]
+  extra_plugins: [folke/trouble.nvim]
+`))
+	require.NoError(t, err)
+
+	merged, err := config.NewMerger().Merge([]config.Layer{*baseLayer, *langLayer})
+	require.NoError(t, err)
+	assert.Equal(t, "minimal", merged.Nvim.Preset)
+	assert.Equal(t, []string{"go", "rust"}, merged.Nvim.Packs)
+
+	nvim, ok := merged.Raw()["nvim"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, []interface{}{"go", "rust"}, nvim["packs"])
+	assert.Equal(t, []interface{}{"folke/trouble.nvim"}, nvim["extra_plugins"])
+}
+
 func TestMerger_Merge_Files_CombinesDeclarations(t *testing.T) {
 	t.Parallel()
 
@@ -114,6 +144,42 @@ files:
 	assert.Equal(t, "git/config.tmpl", merged.Files[0].Template)
 }
 
+func TestMerger_Merge_Files_BlocksShareAFile(t *testing.T) {
+	t.Parallel()
+
+	baseLayer, err := config.ParseLayer([]byte(`
+name: base
+files:
+  - path: ~/.zshrc
+    mode: block
+    block: path
+    content: export PATH="$HOME/bin:$PATH"
+`))
+	require.NoError(t, err)
+
+	workLayer, err := config.ParseLayer([]byte(`
+name: identity.work
+files:
+  - path: ~/.zshrc
+    mode: block
+    block: proxy
+    content: export HTTP_PROXY=http://proxy.corp:8080
+`))
+	require.NoError(t, err)
+
+	merger := config.NewMerger()
+	merged, err := merger.Merge([]config.Layer{*baseLayer, *workLayer})
+
+	require.NoError(t, err)
+	require.Len(t, merged.Files, 2)
+	assert.Equal(t, "path", merged.Files[0].Block)
+	assert.Equal(t, "proxy", merged.Files[1].Block)
+
+	blocks := merged.Raw()["files"].(map[string]interface{})["blocks"].([]interface{})
+	require.Len(t, blocks, 2)
+	assert.Equal(t, "proxy", blocks[1].(map[string]interface{})["name"])
+}
+
 func TestMerger_Merge_Provenance_TracksSourceLayer(t *testing.T) {
 	t.Parallel()
 
@@ -150,6 +216,29 @@ packages:
 	assert.Equal(t, "layers/identity.work.yaml", dockerProv)
 }
 
+func TestMerger_Merge_DuplicateStrategy(t *testing.T) {
+	t.Parallel()
+
+	base, err := config.ParseLayer([]byte("name: base\npackages:\n  brew:\n    formulae: [git]\nvscode:\n  extensions: [golang.Go]\n"))
+	require.NoError(t, err)
+	base.SetProvenance("layers/base.yaml")
+	role, err := config.ParseLayer([]byte("name: role.go\npackages:\n  brew:\n    formulae: [git]\nvscode:\n  extensions: [golang.go]\n"))
+	require.NoError(t, err)
+	role.SetProvenance("layers/role.go.yaml")
+
+	for strategy, want := range map[config.DuplicateStrategy]string{
+		"":                                "layers/role.go.yaml",
+		config.DuplicatesKeepFirst:        "layers/base.yaml",
+		config.DuplicatesKeepMostSpecific: "layers/role.go.yaml",
+	} {
+		merged, err := config.NewMerger().WithDuplicateStrategy(strategy).Merge([]config.Layer{*base, *role})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"git"}, merged.Packages.Brew.Formulae)
+		assert.Equal(t, want, merged.GetProvenance("packages.brew.formulae", "git"), strategy)
+		assert.Equal(t, []string{"golang.Go"}, merged.VSCode.Extensions, "extension IDs are case-insensitive")
+	}
+}
+
 func TestMerger_Merge_EmptyLayers_ReturnsEmptyConfig(t *testing.T) {
 	t.Parallel()
 
@@ -326,6 +415,49 @@ git:
 	assert.Equal(t, "~/.gitignore_global", merged.Git.Core.ExcludesFile)
 }
 
+func TestMerger_Merge_Git_Ignore(t *testing.T) {
+	t.Parallel()
+
+	baseLayer, err := config.ParseLayer([]byte(`
+name: base
+git:
+  ignore:
+    template: templates/gitignore.tmpl
+    vars:
+      os: darwin
+    patterns:
+      - .DS_Store
+      - "*.swp"
+`))
+	require.NoError(t, err)
+
+	workLayer, err := config.ParseLayer([]byte(`
+name: identity.work
+git:
+  ignore:
+    path: ~/.gitignore_global
+    vars:
+      team: platform
+    patterns:
+      - "*.swp"
+      - .idea/
+`))
+	require.NoError(t, err)
+
+	merger := config.NewMerger()
+	merged, err := merger.Merge([]config.Layer{*baseLayer, *workLayer})
+
+	require.NoError(t, err)
+	assert.Equal(t, "~/.gitignore_global", merged.Git.Ignore.Path)
+	assert.Equal(t, "templates/gitignore.tmpl", merged.Git.Ignore.Template)
+	assert.Equal(t, map[string]string{"os": "darwin", "team": "platform"}, merged.Git.Ignore.Vars)
+	assert.Equal(t, []string{".DS_Store", "*.swp", ".idea/"}, merged.Git.Ignore.Patterns)
+
+	ignore, ok := merged.Raw()["git"].(map[string]interface{})["ignore"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, []interface{}{".DS_Store", "*.swp", ".idea/"}, ignore["patterns"])
+}
+
 func TestMerger_Merge_SSH_Hosts(t *testing.T) {
 	t.Parallel()
 
@@ -490,6 +622,43 @@ runtime:
 	assert.Equal(t, "20.10.0", merged.Runtime.Tools[0].Version)
 }
 
+func TestMerger_Merge_Runtime_Shims(t *testing.T) {
+	t.Parallel()
+
+	baseLayer, err := config.ParseLayer([]byte(`
+name: base
+runtime:
+  shims: true
+  tools:
+    - name: nodejs
+      version: "20.10.0"
+      bins: [node, npx]
+`))
+	require.NoError(t, err)
+
+	workLayer, err := config.ParseLayer([]byte(`
+name: identity.work
+runtime:
+  tools:
+    - name: terraform
+      version: "1.7.5"
+`))
+	require.NoError(t, err)
+
+	merged, err := config.NewMerger().Merge([]config.Layer{*baseLayer, *workLayer})
+	require.NoError(t, err)
+	assert.True(t, merged.Runtime.Shims, "a later layer does not turn shims off")
+
+	runtime, ok := merged.Raw()["runtime"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, true, runtime["shims"])
+	assert.Contains(t, runtime["tools"], map[string]interface{}{
+		"name":    "nodejs",
+		"version": "20.10.0",
+		"bins":    []interface{}{"node", "npx"},
+	})
+}
+
 func TestMerger_Merge_Runtime_Plugins(t *testing.T) {
 	t.Parallel()
 
@@ -662,3 +831,41 @@ shell:
 	assert.Equal(t, "nvim", merged.Shell.Aliases["vim"])
 	assert.Equal(t, "kubectl", merged.Shell.Aliases["k"])
 }
+
+func TestMerger_Merge_Shell_FunctionsAndPinnedPlugins(t *testing.T) {
+	t.Parallel()
+
+	baseLayer, err := config.ParseLayer([]byte(`
+name: base
+shell:
+  shells:
+    - name: zsh
+      framework: oh-my-zsh
+      custom_plugins:
+        - name: zsh-autosuggestions
+          repo: https://github.com/zsh-users/zsh-autosuggestions
+          rev: v0.7.0
+  functions:
+    mkcd: mkdir -p "$1" && cd "$1"
+    up: cd ..
+`))
+	require.NoError(t, err)
+
+	workLayer, err := config.ParseLayer([]byte(`
+name: identity.work
+shell:
+  functions:
+    up: cd ../..
+`))
+	require.NoError(t, err)
+
+	merged, err := config.NewMerger().Merge([]config.Layer{*baseLayer, *workLayer})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"mkcd": `mkdir -p "$1" && cd "$1"`, "up": "cd ../.."}, merged.Shell.Functions)
+
+	shell := merged.Raw()["shell"].(map[string]interface{})
+	assert.Equal(t, "cd ../..", shell["functions"].(map[string]interface{})["up"])
+	entry := shell["shells"].([]interface{})[0].(map[string]interface{})
+	plugin := entry["custom_plugins"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "v0.7.0", plugin["rev"])
+}
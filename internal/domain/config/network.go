@@ -0,0 +1,126 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+)
+
+// ErrInvalidNetworkConfig is returned when a layer declares a DNS server,
+// proxy or hosts entry that is not well formed.
+var ErrInvalidNetworkConfig = errors.New("invalid network config")
+
+// NetworkConfig declares DNS servers, proxies and managed /etc/hosts
+// entries. Service is the macOS network service they are set on. An
+// explicitly empty hosts list is kept so that apply removes the managed
+// block.
+type NetworkConfig struct {
+	Service string        `yaml:"service,omitempty"`
+	DNS     NetworkDNS    `yaml:"dns,omitempty"`
+	Proxy   NetworkProxy  `yaml:"proxy,omitempty"`
+	Hosts   []NetworkHost `yaml:"hosts"`
+}
+
+// NetworkDNS declares resolvers and search domains, in order.
+type NetworkDNS struct {
+	Servers       []string `yaml:"servers,omitempty"`
+	SearchDomains []string `yaml:"search_domains,omitempty"`
+}
+
+// NetworkProxy declares HTTP and HTTPS proxies as host:port and the hosts
+// that bypass them.
+type NetworkProxy struct {
+	HTTP   string   `yaml:"http,omitempty"`
+	HTTPS  string   `yaml:"https,omitempty"`
+	Bypass []string `yaml:"bypass,omitempty"`
+}
+
+// NetworkHost maps an IP address to host names.
+type NetworkHost struct {
+	IP    string   `yaml:"ip"`
+	Names []string `yaml:"names"`
+}
+
+// IsZero reports whether no network section is declared.
+func (c NetworkConfig) IsZero() bool {
+	return c.Service == "" && len(c.DNS.Servers) == 0 && len(c.DNS.SearchDomains) == 0 &&
+		c.Proxy.HTTP == "" && c.Proxy.HTTPS == "" && len(c.Proxy.Bypass) == 0 && c.Hosts == nil
+}
+
+func (c *NetworkConfig) normalize() error {
+	for _, server := range c.DNS.Servers {
+		if net.ParseIP(server) == nil {
+			return fmt.Errorf("%w: dns server %q is not an IP address", ErrInvalidNetworkConfig, server)
+		}
+	}
+	for _, address := range []string{c.Proxy.HTTP, c.Proxy.HTTPS} {
+		if address == "" {
+			continue
+		}
+		host, port, err := net.SplitHostPort(address)
+		if n, convErr := strconv.Atoi(port); err != nil || convErr != nil || host == "" || n < 1 || n > 65535 {
+			return fmt.Errorf("%w: proxy %q must be host:port", ErrInvalidNetworkConfig, address)
+		}
+	}
+	seen := make(map[string]bool)
+	for _, host := range c.Hosts {
+		if net.ParseIP(host.IP) == nil {
+			return fmt.Errorf("%w: hosts entry %q is not an IP address", ErrInvalidNetworkConfig, host.IP)
+		}
+		if len(host.Names) == 0 {
+			return fmt.Errorf("%w: hosts entry %s has no names", ErrInvalidNetworkConfig, host.IP)
+		}
+		if seen[host.IP] {
+			return fmt.Errorf("%w: duplicate hosts entry %s", ErrInvalidNetworkConfig, host.IP)
+		}
+		seen[host.IP] = true
+	}
+	return nil
+}
+
+// mergeNetwork combines the network configuration of layers. The service
+// and proxies are last-wins, the DNS server, search domain and bypass lists
+// are replaced whole by the last layer declaring them since their order
+// matters, and hosts entries are keyed by IP address.
+func mergeNetwork(layers []Layer) NetworkConfig {
+	var merged NetworkConfig
+	for _, layer := range layers {
+		n := layer.Network
+		if n.Service != "" {
+			merged.Service = n.Service
+		}
+		if len(n.DNS.Servers) > 0 {
+			merged.DNS.Servers = n.DNS.Servers
+		}
+		if len(n.DNS.SearchDomains) > 0 {
+			merged.DNS.SearchDomains = n.DNS.SearchDomains
+		}
+		if n.Proxy.HTTP != "" {
+			merged.Proxy.HTTP = n.Proxy.HTTP
+		}
+		if n.Proxy.HTTPS != "" {
+			merged.Proxy.HTTPS = n.Proxy.HTTPS
+		}
+		if len(n.Proxy.Bypass) > 0 {
+			merged.Proxy.Bypass = n.Proxy.Bypass
+		}
+		if n.Hosts != nil {
+			if merged.Hosts == nil {
+				merged.Hosts = []NetworkHost{}
+			}
+			merged.Hosts = mergeNamed(merged.Hosts, n.Hosts, func(h NetworkHost) string { return h.IP })
+		}
+	}
+	return merged
+}
+
+// raw converts the network configuration into the map the network
+// provider parses. The hosts list is only present when declared.
+func (c NetworkConfig) raw() map[string]interface{} {
+	raw := sectionRaw(c)
+	if c.Hosts == nil {
+		delete(raw, "hosts")
+	}
+	return raw
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLayer_Network(t *testing.T) {
+	t.Parallel()
+
+	layer, err := ParseLayer([]byte(`
+name: work
+network:
+  service: Ethernet
+  dns:
+    servers: [10.0.0.53, 10.0.0.54]
+    search_domains: [corp.example.com]
+  proxy:
+    http: proxy.corp.example.com:8080
+    bypass: [localhost]
+  hosts:
+    - ip: 10.1.2.3
+      names: [git.corp.example.com]
+`))
+	require.NoError(t, err)
+	assert.Equal(t, "Ethernet", layer.Network.Service)
+	assert.Equal(t, []string{"10.0.0.53", "10.0.0.54"}, layer.Network.DNS.Servers)
+	assert.Equal(t, []NetworkHost{{IP: "10.1.2.3", Names: []string{"git.corp.example.com"}}}, layer.Network.Hosts)
+
+	empty, err := ParseLayer([]byte("name: base\nnetwork:\n  hosts: []\n"))
+	require.NoError(t, err)
+	assert.False(t, empty.Network.IsZero())
+
+	for _, invalid := range []string{
+		"name: base\nnetwork:\n  dns:\n    servers: [dns.example.com]\n",
+		"name: base\nnetwork:\n  proxy:\n    http: proxy.example.com\n",
+		"name: base\nnetwork:\n  hosts:\n    - ip: 10.1.2.3\n",
+		"name: base\nnetwork:\n  hosts:\n    - ip: 10.1.2.3\n      names: [a]\n    - ip: 10.1.2.3\n      names: [b]\n",
+	} {
+		_, err = ParseLayer([]byte(invalid))
+		require.ErrorIs(t, err, ErrInvalidNetworkConfig, invalid)
+	}
+}
+
+func TestMerger_Merge_Network(t *testing.T) {
+	t.Parallel()
+
+	base := Layer{Network: NetworkConfig{
+		DNS:   NetworkDNS{Servers: []string{"1.1.1.1", "8.8.8.8"}},
+		Proxy: NetworkProxy{Bypass: []string{"localhost"}},
+		Hosts: []NetworkHost{{IP: "10.1.2.3", Names: []string{"git"}}},
+	}}
+	work := Layer{Network: NetworkConfig{
+		DNS:   NetworkDNS{Servers: []string{"10.0.0.53"}},
+		Proxy: NetworkProxy{HTTP: "proxy:3128"},
+		Hosts: []NetworkHost{{IP: "10.1.2.3", Names: []string{"git.corp.example.com"}}, {IP: "10.1.2.4", Names: []string{"wiki"}}},
+	}}
+
+	merged, err := NewMerger().Merge([]Layer{base, work})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.53"}, merged.Network.DNS.Servers)
+	assert.Equal(t, NetworkProxy{HTTP: "proxy:3128", Bypass: []string{"localhost"}}, merged.Network.Proxy)
+	assert.Equal(t, []NetworkHost{
+		{IP: "10.1.2.3", Names: []string{"git.corp.example.com"}},
+		{IP: "10.1.2.4", Names: []string{"wiki"}},
+	}, merged.Network.Hosts)
+
+	network := merged.Raw()["network"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"servers": []interface{}{"10.0.0.53"}}, network["dns"])
+	assert.Len(t, network["hosts"], 2)
+
+	dnsOnly, err := NewMerger().Merge([]Layer{{Network: NetworkConfig{DNS: NetworkDNS{Servers: []string{"10.0.0.53"}}}}})
+	require.NoError(t, err)
+	assert.NotContains(t, dnsOnly.Raw()["network"], "hosts")
+
+	none, err := NewMerger().Merge([]Layer{{}})
+	require.NoError(t, err)
+	assert.NotContains(t, none.Raw(), "network")
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidOnboardingStep is returned when a layer declares an onboarding
+// step without a title or with a duplicate ID.
+var ErrInvalidOnboardingStep = errors.New("invalid onboarding step")
+
+// OnboardingConfig declares manual tasks a new team member completes once,
+// such as requesting VPN access. They are not applied; preflight onboard
+// renders them as a checklist.
+type OnboardingConfig struct {
+	Steps []OnboardingStep `yaml:"steps,omitempty"`
+}
+
+// OnboardingStep is a single manual task.
+type OnboardingStep struct {
+	// ID identifies the step within its layer; derived from Title when empty.
+	ID          string `yaml:"id,omitempty"`
+	Title       string `yaml:"title"`
+	Description string `yaml:"description,omitempty"`
+	URL         string `yaml:"url,omitempty"`
+}
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// normalize fills in missing IDs and checks titles and ID uniqueness.
+func (c *OnboardingConfig) normalize() error {
+	seen := make(map[string]bool, len(c.Steps))
+	for i := range c.Steps {
+		step := &c.Steps[i]
+		step.Title = strings.TrimSpace(step.Title)
+		if step.Title == "" {
+			return fmt.Errorf("%w: step %d has no title", ErrInvalidOnboardingStep, i+1)
+		}
+		if step.ID == "" {
+			step.ID = strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(step.Title), "-"), "-")
+		}
+		if seen[step.ID] {
+			return fmt.Errorf("%w: duplicate id %q", ErrInvalidOnboardingStep, step.ID)
+		}
+		seen[step.ID] = true
+	}
+	return nil
+}
+
+// OnboardingTask is an onboarding step together with the layer that
+// declared it.
+type OnboardingTask struct {
+	Layer LayerName
+	OnboardingStep
+}
+
+// Key identifies the task across layers as "<layer>/<id>".
+func (t OnboardingTask) Key() string {
+	return t.Layer.String() + "/" + t.ID
+}
+
+// OnboardingTasks returns the onboarding steps of all layers in target
+// order.
+func (t *Target) OnboardingTasks() []OnboardingTask {
+	var tasks []OnboardingTask
+	for _, layer := range t.Layers {
+		for _, step := range layer.Onboarding.Steps {
+			tasks = append(tasks, OnboardingTask{Layer: layer.Name, OnboardingStep: step})
+		}
+	}
+	return tasks
+}
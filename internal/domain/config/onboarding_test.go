@@ -0,0 +1,52 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLayer_Onboarding(t *testing.T) {
+	t.Parallel()
+
+	layer, err := ParseLayer([]byte(`
+name: team-backend
+onboarding:
+  steps:
+    - title: Request VPN access
+      url: https://it.example.com/vpn
+    - id: oncall
+      title: Join the on-call rotation
+      description: Ask your lead to add you in PagerDuty.
+`))
+	require.NoError(t, err)
+	require.Len(t, layer.Onboarding.Steps, 2)
+	assert.Equal(t, "request-vpn-access", layer.Onboarding.Steps[0].ID)
+	assert.Equal(t, "https://it.example.com/vpn", layer.Onboarding.Steps[0].URL)
+	assert.Equal(t, "oncall", layer.Onboarding.Steps[1].ID)
+
+	target := &Target{Layers: []Layer{*layer}}
+	tasks := target.OnboardingTasks()
+	require.Len(t, tasks, 2)
+	assert.Equal(t, "team-backend/request-vpn-access", tasks[0].Key())
+}
+
+func TestParseLayer_OnboardingInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		yaml string
+	}{
+		{"missing title", "name: base\nonboarding:\n  steps:\n    - id: vpn\n"},
+		{"duplicate id", "name: base\nonboarding:\n  steps:\n    - title: VPN\n    - id: vpn\n      title: Other\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := ParseLayer([]byte(tt.yaml))
+			require.ErrorIs(t, err, ErrInvalidOnboardingStep)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// ErrInvalidPasswordManagerConfig is returned when a layer declares a
+// password manager CLI with invalid settings.
+var ErrInvalidPasswordManagerConfig = errors.New("invalid password manager config")
+
+// PasswordManagerConfig declares the password manager CLIs that secret
+// references (secret://1password/..., secret://bitwarden/...) resolve
+// through. A declared CLI is installed and checked for a signed-in account.
+type PasswordManagerConfig struct {
+	OnePassword *OnePasswordConfig `yaml:"1password,omitempty"`
+	Bitwarden   *BitwardenConfig   `yaml:"bitwarden,omitempty"`
+}
+
+// OnePasswordConfig declares the 1Password CLI (op).
+type OnePasswordConfig struct {
+	// Account is the sign-in address, such as my.1password.com, or the
+	// email or shorthand of an account added to op
+	Account string `yaml:"account,omitempty"`
+	// SSHAgent points SSH at the 1Password SSH agent
+	SSHAgent bool `yaml:"ssh_agent,omitempty"`
+}
+
+// BitwardenConfig declares the Bitwarden CLI (bw).
+type BitwardenConfig struct {
+	// Server is the URL of a self-hosted server
+	Server string `yaml:"server,omitempty"`
+	// Email is the account the CLI should be logged in to
+	Email string `yaml:"email,omitempty"`
+}
+
+// IsZero reports whether no password manager is declared.
+func (c PasswordManagerConfig) IsZero() bool {
+	return c.OnePassword == nil && c.Bitwarden == nil
+}
+
+func (c PasswordManagerConfig) normalize() error {
+	if c.Bitwarden != nil && c.Bitwarden.Server != "" {
+		u, err := url.Parse(c.Bitwarden.Server)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("%w: bitwarden server %q must be an http(s) URL", ErrInvalidPasswordManagerConfig, c.Bitwarden.Server)
+		}
+	}
+	return nil
+}
+
+// mergePasswordManager combines the password_manager sections of layers.
+// A CLI is declared if any layer declares it; its settings are last-wins.
+func mergePasswordManager(layers []Layer) PasswordManagerConfig {
+	var merged PasswordManagerConfig
+	for _, layer := range layers {
+		if op := layer.PasswordManager.OnePassword; op != nil {
+			if merged.OnePassword == nil {
+				merged.OnePassword = &OnePasswordConfig{}
+			}
+			if op.Account != "" {
+				merged.OnePassword.Account = op.Account
+			}
+			if op.SSHAgent {
+				merged.OnePassword.SSHAgent = true
+			}
+		}
+		if bw := layer.PasswordManager.Bitwarden; bw != nil {
+			if merged.Bitwarden == nil {
+				merged.Bitwarden = &BitwardenConfig{}
+			}
+			if bw.Server != "" {
+				merged.Bitwarden.Server = bw.Server
+			}
+			if bw.Email != "" {
+				merged.Bitwarden.Email = bw.Email
+			}
+		}
+	}
+	return merged
+}
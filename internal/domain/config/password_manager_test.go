@@ -0,0 +1,51 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLayer_PasswordManager(t *testing.T) {
+	t.Parallel()
+
+	layer, err := ParseLayer([]byte(`
+name: secrets
+password_manager:
+  1password:
+    account: acme.1password.com
+    ssh_agent: true
+  bitwarden: {}
+`))
+	require.NoError(t, err)
+	require.NotNil(t, layer.PasswordManager.OnePassword)
+	assert.Equal(t, "acme.1password.com", layer.PasswordManager.OnePassword.Account)
+	assert.True(t, layer.PasswordManager.OnePassword.SSHAgent)
+	require.NotNil(t, layer.PasswordManager.Bitwarden, "a CLI declared without settings is still declared")
+
+	_, err = ParseLayer([]byte("name: base\npassword_manager:\n  bitwarden:\n    server: vault.acme.com\n"))
+	require.ErrorIs(t, err, ErrInvalidPasswordManagerConfig)
+}
+
+func TestMerger_Merge_PasswordManager(t *testing.T) {
+	t.Parallel()
+
+	base := Layer{PasswordManager: PasswordManagerConfig{
+		OnePassword: &OnePasswordConfig{SSHAgent: true},
+		Bitwarden:   &BitwardenConfig{Server: "https://vault.acme.com"},
+	}}
+	work := Layer{PasswordManager: PasswordManagerConfig{
+		OnePassword: &OnePasswordConfig{Account: "acme.1password.com"},
+	}}
+
+	merged, err := NewMerger().Merge([]Layer{base, work})
+	require.NoError(t, err)
+	assert.Equal(t, &OnePasswordConfig{Account: "acme.1password.com", SSHAgent: true}, merged.PasswordManager.OnePassword)
+	assert.Equal(t, &BitwardenConfig{Server: "https://vault.acme.com"}, merged.PasswordManager.Bitwarden)
+
+	raw := merged.Raw()
+	section, ok := raw["password_manager"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Contains(t, section, "1password")
+}
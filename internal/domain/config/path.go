@@ -0,0 +1,92 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ErrInvalidPathEntry is returned when a layer declares an empty PATH entry
+// or one containing the list separator.
+var ErrInvalidPathEntry = errors.New("invalid path entry")
+
+// PathConfig declares directories a layer adds to PATH. Prepend entries
+// take precedence over the existing PATH; append entries are only used
+// when nothing earlier provides the command.
+type PathConfig struct {
+	Prepend []string `yaml:"prepend,omitempty"`
+	Append  []string `yaml:"append,omitempty"`
+}
+
+// IsZero reports whether no entries are declared.
+func (c PathConfig) IsZero() bool {
+	return len(c.Prepend) == 0 && len(c.Append) == 0
+}
+
+// Entries returns all entries in PATH order, without the existing PATH
+// that sits between the prepend and append entries.
+func (c PathConfig) Entries() []string {
+	return append(append([]string{}, c.Prepend...), c.Append...)
+}
+
+// Expand replaces a leading ~ in each entry with home.
+func (c PathConfig) Expand(home string) PathConfig {
+	expand := func(entries []string) []string {
+		if entries == nil {
+			return nil
+		}
+		out := make([]string, len(entries))
+		for i, entry := range entries {
+			switch {
+			case entry == "~":
+				out[i] = home
+			case strings.HasPrefix(entry, "~/"):
+				out[i] = filepath.Join(home, entry[2:])
+			default:
+				out[i] = entry
+			}
+		}
+		return out
+	}
+	return PathConfig{Prepend: expand(c.Prepend), Append: expand(c.Append)}
+}
+
+func (c *PathConfig) normalize() error {
+	for _, entries := range [][]string{c.Prepend, c.Append} {
+		for i := range entries {
+			entries[i] = strings.TrimSpace(entries[i])
+			if entries[i] == "" {
+				return fmt.Errorf("%w: empty entry", ErrInvalidPathEntry)
+			}
+			if strings.Contains(entries[i], ":") {
+				return fmt.Errorf("%w: %q contains ':'; list each directory separately", ErrInvalidPathEntry, entries[i])
+			}
+		}
+	}
+	return nil
+}
+
+// mergePaths combines the PATH entries of layers. Later layers take
+// precedence: their entries come first within the prepend and append
+// groups, and when a directory is declared more than once the latest
+// layer decides whether it is prepended or appended.
+func mergePaths(layers []Layer) PathConfig {
+	var merged PathConfig
+	seen := make(map[string]bool)
+	for i := len(layers) - 1; i >= 0; i-- {
+		for _, entry := range layers[i].Path.Prepend {
+			if !seen[entry] {
+				seen[entry] = true
+				merged.Prepend = append(merged.Prepend, entry)
+			}
+		}
+		for _, entry := range layers[i].Path.Append {
+			if !seen[entry] {
+				seen[entry] = true
+				merged.Append = append(merged.Append, entry)
+			}
+		}
+	}
+	return merged
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLayer_Path(t *testing.T) {
+	t.Parallel()
+
+	layer, err := ParseLayer([]byte(`
+name: base
+path:
+  prepend:
+    - " ~/.local/bin "
+    - /opt/homebrew/bin
+  append:
+    - ~/go/bin
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"~/.local/bin", "/opt/homebrew/bin"}, layer.Path.Prepend)
+	assert.Equal(t, []string{"~/go/bin"}, layer.Path.Append)
+
+	_, err = ParseLayer([]byte("name: base\npath:\n  prepend: [\"\"]\n"))
+	require.ErrorIs(t, err, ErrInvalidPathEntry)
+	_, err = ParseLayer([]byte("name: base\npath:\n  append: [\"/a:/b\"]\n"))
+	require.ErrorIs(t, err, ErrInvalidPathEntry)
+}
+
+func TestMerger_Merge_Path(t *testing.T) {
+	t.Parallel()
+
+	base := Layer{Provenance: "layers/base.yaml", Path: PathConfig{
+		Prepend: []string{"/opt/homebrew/bin", "~/.local/bin"},
+		Append:  []string{"~/go/bin", "/opt/tools"},
+	}}
+	work := Layer{Provenance: "layers/work.yaml", Path: PathConfig{
+		Prepend: []string{"/opt/corp/bin", "/opt/tools"},
+		Append:  []string{"~/.local/bin"},
+	}}
+
+	merged, err := NewMerger().Merge([]Layer{base, work})
+	require.NoError(t, err)
+
+	// Later layers come first and decide where a shared entry goes.
+	assert.Equal(t, []string{"/opt/corp/bin", "/opt/tools", "/opt/homebrew/bin"}, merged.Path.Prepend)
+	assert.Equal(t, []string{"~/.local/bin", "~/go/bin"}, merged.Path.Append)
+	assert.Equal(t, "layers/work.yaml", merged.GetProvenance("path", "/opt/corp/bin"))
+
+	raw := merged.Raw()
+	assert.Equal(t, map[string]interface{}{
+		"prepend": []interface{}{"/opt/corp/bin", "/opt/tools", "/opt/homebrew/bin"},
+		"append":  []interface{}{"~/.local/bin", "~/go/bin"},
+	}, raw["path"])
+}
+
+func TestPathConfig_Expand(t *testing.T) {
+	t.Parallel()
+
+	cfg := PathConfig{Prepend: []string{"~/.local/bin", "/usr/local/bin"}, Append: []string{"~"}}
+	assert.Equal(t, PathConfig{
+		Prepend: []string{"/home/me/.local/bin", "/usr/local/bin"},
+		Append:  []string{"/home/me"},
+	}, cfg.Expand("/home/me"))
+	assert.Equal(t, []string{"~/.local/bin", "/usr/local/bin", "~"}, cfg.Entries())
+	assert.True(t, PathConfig{}.IsZero())
+}
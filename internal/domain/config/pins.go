@@ -0,0 +1,144 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidPin is returned when a layer declares an invalid pin.
+var ErrInvalidPin = errors.New("invalid pin")
+
+// PinDateLayout is the layout of Pin.Since.
+const PinDateLayout = "2006-01-02"
+
+// DefaultPinMaxAge is how long a pin may be held before doctor flags it
+// when upgrades.pin_max_age is not set.
+const DefaultPinMaxAge = 90 * 24 * time.Hour
+
+// pinManagers are the package managers a pin can refer to.
+var pinManagers = []string{"brew", "cask", "npm", "pip", "pipx", "vscode", "go", "nvim", "cargo"}
+
+// Pin holds a package at its current version so that outdated and upgrade
+// leave it alone. Package is "manager:name", such as "npm:typescript" or
+// "vscode:golang.go"; a name without a manager is a Homebrew formula.
+type Pin struct {
+	Package string `yaml:"package"`
+	Version string `yaml:"version,omitempty"`
+	Since   string `yaml:"since,omitempty"`
+	Reason  string `yaml:"reason,omitempty"`
+}
+
+// ParsePinRef splits a pin reference into its manager and package name.
+func ParsePinRef(ref string) (manager, name string, err error) {
+	manager, name, ok := strings.Cut(ref, ":")
+	if !ok {
+		manager, name = "brew", ref
+	}
+	if name == "" {
+		return "", "", fmt.Errorf("%w: %q has no package name", ErrInvalidPin, ref)
+	}
+	for _, known := range pinManagers {
+		if manager == known {
+			return manager, name, nil
+		}
+	}
+	return "", "", fmt.Errorf("%w: unknown package manager %q, want one of %s", ErrInvalidPin, manager, strings.Join(pinManagers, ", "))
+}
+
+// Ref returns the manager and name of the pinned package.
+func (p Pin) Ref() (manager, name string) {
+	manager, name, _ = ParsePinRef(p.Package)
+	return manager, name
+}
+
+// Key returns the normalized reference of the pinned package, such as
+// "brew:git". VS Code extension IDs are case-insensitive.
+func (p Pin) Key() string {
+	manager, name := p.Ref()
+	if manager == "vscode" {
+		name = strings.ToLower(name)
+	}
+	return manager + ":" + name
+}
+
+// SinceTime returns the day the pin was added, if it is recorded.
+func (p Pin) SinceTime() (time.Time, bool) {
+	t, err := time.ParseInLocation(PinDateLayout, p.Since, time.Local)
+	return t, err == nil
+}
+
+func normalizePins(pins []Pin) error {
+	seen := make(map[string]bool, len(pins))
+	for _, pin := range pins {
+		if pin.Package == "" {
+			return fmt.Errorf("%w: package is required", ErrInvalidPin)
+		}
+		if _, _, err := ParsePinRef(pin.Package); err != nil {
+			return err
+		}
+		if pin.Since != "" {
+			if _, ok := pin.SinceTime(); !ok {
+				return fmt.Errorf("%w: %s: since %q must be a date like 2026-01-31", ErrInvalidPin, pin.Package, pin.Since)
+			}
+		}
+		if seen[pin.Key()] {
+			return fmt.Errorf("%w: %s is pinned twice", ErrInvalidPin, pin.Package)
+		}
+		seen[pin.Key()] = true
+	}
+	return nil
+}
+
+// mergePins combines the pins of layers. A package pinned by several
+// layers keeps the pin of the last one.
+func mergePins(layers []Layer) []Pin {
+	byKey := make(map[string]Pin)
+	for _, layer := range layers {
+		for _, pin := range layer.Pins {
+			byKey[pin.Key()] = pin
+		}
+	}
+	if len(byKey) == 0 {
+		return nil
+	}
+	merged := make([]Pin, 0, len(byKey))
+	for _, pin := range byKey {
+		merged = append(merged, pin)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Key() < merged[j].Key() })
+	return merged
+}
+
+// MaxPinAge returns how long a pin may be held before doctor flags it.
+func (c UpgradesConfig) MaxPinAge() time.Duration {
+	if c.PinMaxAge == "" {
+		return DefaultPinMaxAge
+	}
+	age, err := parseAge(c.PinMaxAge)
+	if err != nil {
+		return DefaultPinMaxAge
+	}
+	return age
+}
+
+// parseAge parses an age such as "12h", "90d", "4w" or "6m".
+func parseAge(s string) (time.Duration, error) {
+	if !historyKeepPattern.MatchString(s) {
+		return 0, fmt.Errorf("age %q must be a number followed by h, d, w or m", s)
+	}
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil {
+		return 0, err
+	}
+	unit := map[byte]time.Duration{
+		'h': time.Hour,
+		'd': 24 * time.Hour,
+		'w': 7 * 24 * time.Hour,
+		'm': 30 * 24 * time.Hour,
+	}[s[len(s)-1]]
+	return time.Duration(n) * unit, nil
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePinRef(t *testing.T) {
+	t.Parallel()
+
+	manager, name, err := ParsePinRef("node")
+	require.NoError(t, err)
+	assert.Equal(t, "brew", manager)
+	assert.Equal(t, "node", name)
+
+	manager, name, err = ParsePinRef("npm:@scope/tool")
+	require.NoError(t, err)
+	assert.Equal(t, "npm", manager)
+	assert.Equal(t, "@scope/tool", name)
+
+	for _, invalid := range []string{"npm:", "apt:curl"} {
+		_, _, err = ParsePinRef(invalid)
+		require.ErrorIs(t, err, ErrInvalidPin, invalid)
+	}
+
+	assert.Equal(t, "vscode:golang.go", Pin{Package: "vscode:Golang.Go"}.Key())
+	assert.Equal(t, "brew:git", Pin{Package: "git"}.Key())
+}
+
+func TestParseLayer_Pins(t *testing.T) {
+	t.Parallel()
+
+	layer, err := ParseLayer([]byte(`
+name: base
+pins:
+  - package: node
+    version: "20.11.1"
+    since: "2026-09-01"
+    reason: native modules need Node 20
+  - package: npm:typescript
+    version: 5.4.5
+`))
+	require.NoError(t, err)
+	require.Len(t, layer.Pins, 2)
+	assert.Equal(t, "20.11.1", layer.Pins[0].Version)
+	since, ok := layer.Pins[0].SinceTime()
+	require.True(t, ok)
+	assert.Equal(t, time.September, since.Month())
+
+	for _, invalid := range []string{
+		"name: base\npins:\n  - version: '1.0'\n",
+		"name: base\npins:\n  - package: apt:curl\n",
+		"name: base\npins:\n  - package: node\n    since: yesterday\n",
+		"name: base\npins:\n  - package: node\n  - package: brew:node\n",
+	} {
+		_, err = ParseLayer([]byte(invalid))
+		require.ErrorIs(t, err, ErrInvalidPin, invalid)
+	}
+}
+
+func TestMerger_Merge_Pins(t *testing.T) {
+	t.Parallel()
+
+	base := Layer{Pins: []Pin{
+		{Package: "node", Version: "20.11.1"},
+		{Package: "npm:typescript", Version: "5.4.5"},
+	}}
+	work := Layer{Pins: []Pin{{Package: "brew:node", Version: "18.19.0"}}}
+
+	merged, err := NewMerger().Merge([]Layer{base, work})
+	require.NoError(t, err)
+	assert.Equal(t, []Pin{
+		{Package: "brew:node", Version: "18.19.0"},
+		{Package: "npm:typescript", Version: "5.4.5"},
+	}, merged.Pins)
+}
+
+func TestUpgradesConfig_MaxPinAge(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, DefaultPinMaxAge, UpgradesConfig{}.MaxPinAge())
+	assert.Equal(t, 2*7*24*time.Hour, UpgradesConfig{PinMaxAge: "2w"}.MaxPinAge())
+
+	_, err := ParseManifest([]byte("upgrades:\n  pin_max_age: soon\ntargets:\n  default: [base]\n"))
+	require.ErrorIs(t, err, ErrInvalidUpgradesConfig)
+}
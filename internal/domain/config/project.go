@@ -0,0 +1,112 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectFile is where a repository declares the tools it needs, relative
+// to the repository root.
+const ProjectFile = ".preflight/project.yaml"
+
+// Errors for project configs.
+var (
+	ErrProjectNotFound      = errors.New("no " + ProjectFile + " found")
+	ErrInvalidProjectConfig = errors.New("invalid project config")
+)
+
+// Project is a repository's declaration of the tools its contributors
+// need. It is applied on its own, never merged with the user's layers, and
+// its runtime versions go to the .tool-versions file of the project.
+type Project struct {
+	Name     string
+	Root     string // Directory that holds .preflight/
+	Packages PackageSet
+	Runtime  RuntimeConfig
+}
+
+// projectYAML is the YAML representation for unmarshaling.
+type projectYAML struct {
+	Name     string        `yaml:"name,omitempty"`
+	Packages PackageSet    `yaml:"packages,omitempty"`
+	Runtime  RuntimeConfig `yaml:"runtime,omitempty"`
+}
+
+// ParseProject parses a Project from YAML bytes. Sections other than
+// packages and runtime are rejected: a project must not change a
+// contributor's global settings.
+func ParseProject(data []byte) (*Project, error) {
+	var raw projectYAML
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&raw); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: %v (a project declares only name, packages and runtime)", ErrInvalidProjectConfig, err)
+	}
+
+	if err := raw.Packages.Brew.normalize(); err != nil {
+		return nil, err
+	}
+	if err := raw.Packages.Mas.normalize(); err != nil {
+		return nil, err
+	}
+	switch {
+	case raw.Runtime.Scope != "" && raw.Runtime.Scope != "project":
+		return nil, fmt.Errorf("%w: runtime.scope must be project, got %q", ErrInvalidProjectConfig, raw.Runtime.Scope)
+	case raw.Runtime.Shims:
+		return nil, fmt.Errorf("%w: runtime.shims is a user setting; declare it in a layer", ErrInvalidProjectConfig)
+	}
+	raw.Runtime.Scope = "project"
+
+	return &Project{
+		Name:     raw.Name,
+		Packages: raw.Packages,
+		Runtime:  raw.Runtime,
+	}, nil
+}
+
+// LoadProject loads the project config of dir or of the nearest parent
+// directory that has one.
+func LoadProject(dir string) (*Project, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		path := filepath.Join(dir, ProjectFile)
+		data, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			project, err := ParseProject(data)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			project.Root = dir
+			return project, nil
+		case !errors.Is(err, fs.ErrNotExist):
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, ErrProjectNotFound
+		}
+		dir = parent
+	}
+}
+
+// Layer returns the project as a layer for compiling.
+func (p *Project) Layer() Layer {
+	return Layer{
+		Name:       LayerName{value: "project"},
+		Provenance: filepath.Join(p.Root, ProjectFile),
+		Packages:   p.Packages,
+		Runtime:    p.Runtime,
+	}
+}
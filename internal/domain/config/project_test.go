@@ -0,0 +1,77 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProject(t *testing.T) {
+	t.Parallel()
+
+	project, err := ParseProject([]byte(`
+name: api
+packages:
+  brew:
+    formulae: [protobuf]
+runtime:
+  backend: mise
+  tools:
+    - name: golang
+      version: "1.23.0"
+`))
+	require.NoError(t, err)
+	assert.Equal(t, "api", project.Name)
+	assert.Equal(t, []string{"protobuf"}, project.Packages.Brew.Formulae)
+	assert.Equal(t, "project", project.Runtime.Scope, "runtime versions are always project scoped")
+
+	project, err = ParseProject(nil)
+	require.NoError(t, err, "an empty project declares nothing")
+	assert.Empty(t, project.Runtime.Tools)
+}
+
+func TestParseProject_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		yaml string
+	}{
+		{"global settings", "git:\n  user:\n    name: Jane\n"},
+		{"global runtime scope", "runtime:\n  scope: global\n"},
+		{"shims", "runtime:\n  shims: true\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := ParseProject([]byte(tt.yaml))
+			require.ErrorIs(t, err, ErrInvalidProjectConfig)
+		})
+	}
+}
+
+func TestLoadProject(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	sub := filepath.Join(root, "cmd", "api")
+	require.NoError(t, os.MkdirAll(sub, 0o755))
+
+	_, err := LoadProject(sub)
+	require.ErrorIs(t, err, ErrProjectNotFound)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".preflight"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ProjectFile), []byte("name: api\n"), 0o644))
+
+	project, err := LoadProject(sub)
+	require.NoError(t, err)
+	assert.Equal(t, root, project.Root)
+
+	layer := project.Layer()
+	assert.Equal(t, "project", layer.Name.String())
+	assert.Equal(t, filepath.Join(root, ProjectFile), layer.Provenance)
+}
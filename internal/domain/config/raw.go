@@ -9,6 +9,13 @@ func (m *MergedConfig) Raw() map[string]interface{} {
 	brew["taps"] = toInterfaceSlice(m.Packages.Brew.Taps)
 	brew["formulae"] = toInterfaceSlice(m.Packages.Brew.Formulae)
 	brew["casks"] = toInterfaceSlice(m.Packages.Brew.Casks)
+	if len(m.Packages.Brew.Arch) > 0 {
+		arch := make(map[string]interface{}, len(m.Packages.Brew.Arch))
+		for name, a := range m.Packages.Brew.Arch {
+			arch[name] = a
+		}
+		brew["arch"] = arch
+	}
 	raw["brew"] = brew
 
 	// Convert apt packages
@@ -52,11 +59,25 @@ func (m *MergedConfig) Raw() map[string]interface{} {
 		raw["cargo"] = cargo
 	}
 
+	// Convert App Store apps
+	if len(m.Packages.Mas.Apps) > 0 {
+		apps := make([]interface{}, 0, len(m.Packages.Mas.Apps))
+		for _, app := range m.Packages.Mas.Apps {
+			entry := map[string]interface{}{"id": app.ID}
+			if app.Name != "" {
+				entry["name"] = app.Name
+			}
+			apps = append(apps, entry)
+		}
+		raw["mas"] = map[string]interface{}{"apps": apps}
+	}
+
 	// Convert files - transform FileDeclaration to provider format
 	// For now, map generated files to links, templates to templates
 	files := make(map[string]interface{})
 	var links []interface{}
 	var templates []interface{}
+	var blocks []interface{}
 
 	for _, f := range m.Files {
 		switch f.Mode {
@@ -72,11 +93,20 @@ func (m *MergedConfig) Raw() map[string]interface{} {
 				"src":  f.Template,
 				"dest": f.Path,
 			})
+		case FileModeBlock:
+			blocks = append(blocks, map[string]interface{}{
+				"dest":    f.Path,
+				"name":    f.Block,
+				"src":     f.Template,
+				"content": f.Content,
+				"comment": f.Comment,
+			})
 		}
 	}
 
 	files["links"] = links
 	files["templates"] = templates
+	files["blocks"] = blocks
 	files["copies"] = []interface{}{} // Empty for now
 	raw["files"] = files
 
@@ -158,6 +188,11 @@ func (m *MergedConfig) Raw() map[string]interface{} {
 		git["includes"] = includes
 	}
 
+	// Global gitignore
+	if !m.Git.Ignore.IsZero() {
+		git["ignore"] = sectionRaw(m.Git.Ignore)
+	}
+
 	if len(git) > 0 {
 		raw["git"] = git
 	}
@@ -187,6 +222,9 @@ func (m *MergedConfig) Raw() map[string]interface{} {
 	if m.SSH.Defaults.ServerAliveCountMax > 0 {
 		defaults["serveralivecountmax"] = m.SSH.Defaults.ServerAliveCountMax
 	}
+	if m.SSH.Defaults.IdentityAgent != "" {
+		defaults["identityagent"] = m.SSH.Defaults.IdentityAgent
+	}
 	if len(defaults) > 0 {
 		ssh["defaults"] = defaults
 	}
@@ -279,6 +317,9 @@ func (m *MergedConfig) Raw() map[string]interface{} {
 	if m.Runtime.Scope != "" {
 		runtime["scope"] = m.Runtime.Scope
 	}
+	if m.Runtime.Shims {
+		runtime["shims"] = true
+	}
 
 	// Tools section
 	if len(m.Runtime.Tools) > 0 {
@@ -288,6 +329,9 @@ func (m *MergedConfig) Raw() map[string]interface{} {
 				"name":    t.Name,
 				"version": t.Version,
 			}
+			if len(t.Bins) > 0 {
+				toolMap["bins"] = toInterfaceSlice(t.Bins)
+			}
 			tools = append(tools, toolMap)
 		}
 		runtime["tools"] = tools
@@ -338,10 +382,14 @@ func (m *MergedConfig) Raw() map[string]interface{} {
 			if len(s.CustomPlugins) > 0 {
 				var customPlugins []interface{}
 				for _, cp := range s.CustomPlugins {
-					customPlugins = append(customPlugins, map[string]interface{}{
+					customPlugin := map[string]interface{}{
 						"name": cp.Name,
 						"repo": cp.Repo,
-					})
+					}
+					if cp.Rev != "" {
+						customPlugin["rev"] = cp.Rev
+					}
+					customPlugins = append(customPlugins, customPlugin)
 				}
 				shellMap["custom_plugins"] = customPlugins
 			}
@@ -358,6 +406,9 @@ func (m *MergedConfig) Raw() map[string]interface{} {
 		if m.Shell.Starship.Preset != "" {
 			starship["preset"] = m.Shell.Starship.Preset
 		}
+		if m.Shell.Starship.ConfigSource != "" {
+			starship["config_source"] = m.Shell.Starship.ConfigSource
+		}
 		shell["starship"] = starship
 	}
 
@@ -379,10 +430,31 @@ func (m *MergedConfig) Raw() map[string]interface{} {
 		shell["aliases"] = aliases
 	}
 
+	// Functions section
+	if len(m.Shell.Functions) > 0 {
+		functions := make(map[string]interface{})
+		for k, v := range m.Shell.Functions {
+			functions[k] = v
+		}
+		shell["functions"] = functions
+	}
+
 	if len(shell) > 0 {
 		raw["shell"] = shell
 	}
 
+	// Convert PATH entries
+	if !m.Path.IsZero() {
+		path := make(map[string]interface{})
+		if len(m.Path.Prepend) > 0 {
+			path["prepend"] = toInterfaceSlice(m.Path.Prepend)
+		}
+		if len(m.Path.Append) > 0 {
+			path["append"] = toInterfaceSlice(m.Path.Append)
+		}
+		raw["path"] = path
+	}
+
 	// Convert nvim config
 	nvim := make(map[string]interface{})
 
@@ -398,11 +470,97 @@ func (m *MergedConfig) Raw() map[string]interface{} {
 	if m.Nvim.EnsureInstall {
 		nvim["ensure_install"] = true
 	}
+	if len(m.Nvim.ExtraPlugins) > 0 {
+		nvim["extra_plugins"] = toInterfaceSlice(m.Nvim.ExtraPlugins)
+	}
+	if len(m.Nvim.Packs) > 0 {
+		nvim["packs"] = toInterfaceSlice(m.Nvim.Packs)
+	}
 
 	if len(nvim) > 0 {
 		raw["nvim"] = nvim
 	}
 
+	// Convert tmux config
+	tmux := make(map[string]interface{})
+
+	if len(m.Tmux.Plugins) > 0 {
+		tmux["plugins"] = toInterfaceSlice(m.Tmux.Plugins)
+	}
+	if len(m.Tmux.Settings) > 0 {
+		settings := make(map[string]interface{})
+		for k, v := range m.Tmux.Settings {
+			settings[k] = v
+		}
+		tmux["settings"] = settings
+	}
+	if m.Tmux.ConfigSource != "" {
+		tmux["config_source"] = m.Tmux.ConfigSource
+	}
+	if m.Tmux.UpdatePlugins {
+		tmux["update_plugins"] = true
+	}
+
+	if len(tmux) > 0 {
+		raw["tmux"] = tmux
+	}
+
+	// Convert terminal config
+	if !m.Terminal.IsZero() {
+		raw["terminal"] = m.Terminal.raw()
+	}
+
+	// Convert docker config
+	if !m.Docker.IsZero() {
+		raw["docker"] = m.Docker.raw()
+	}
+
+	// Convert kubernetes config
+	if !m.Kubernetes.IsZero() {
+		raw["kubernetes"] = m.Kubernetes.raw()
+	}
+
+	// Convert cloud CLI config
+	if !m.AWS.IsZero() {
+		raw["aws"] = sectionRaw(m.AWS)
+	}
+	if !m.GCloud.IsZero() {
+		raw["gcloud"] = sectionRaw(m.GCloud)
+	}
+	if !m.Azure.IsZero() {
+		raw["azure"] = sectionRaw(m.Azure)
+	}
+
+	// Convert scheduled jobs
+	if !m.Cron.IsZero() {
+		raw["cron"] = m.Cron.raw()
+	}
+
+	// Convert macOS preferences
+	if !m.MacOS.IsZero() {
+		raw["macos"] = sectionRaw(m.MacOS)
+	}
+
+	// Convert network settings
+	if !m.Network.IsZero() {
+		raw["network"] = m.Network.raw()
+	}
+
+	// Convert custom scripts
+	if !m.Scripts.IsZero() {
+		raw["scripts"] = sectionRaw(m.Scripts)
+	}
+
+	// Convert password manager CLIs
+	if !m.PasswordManager.IsZero() {
+		raw["password_manager"] = sectionRaw(m.PasswordManager)
+	}
+
+	// Convert the default editorconfig
+	if !m.EditorConfig.IsZero() {
+		raw["editorconfig"] = sectionRaw(m.EditorConfig)
+	}
+
 	// Convert VSCode config
 	vscode := make(map[string]interface{})
 
@@ -436,6 +594,17 @@ func (m *MergedConfig) Raw() map[string]interface{} {
 		vscode["keybindings"] = keybindings
 	}
 
+	if profile := m.VSCode.Profile; profile != nil {
+		vscodeProfile := map[string]interface{}{
+			"export": profile.Export,
+			"switch": profile.Switch,
+		}
+		if profile.Name != "" {
+			vscodeProfile["name"] = profile.Name
+		}
+		vscode["profile"] = vscodeProfile
+	}
+
 	if len(vscode) > 0 {
 		raw["vscode"] = vscode
 	}
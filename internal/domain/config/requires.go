@@ -0,0 +1,82 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+)
+
+// ErrMissingRequiredLayer is returned when a target omits a layer that one
+// of its layers requires.
+var ErrMissingRequiredLayer = errors.New("missing required layer")
+
+// parseRequires validates the requires list of layer name.
+func parseRequires(name LayerName, raw []string) ([]LayerName, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	requires := make([]LayerName, 0, len(raw))
+	for _, r := range raw {
+		ln, err := NewLayerName(r)
+		if err != nil {
+			return nil, fmt.Errorf("requires: %w", err)
+		}
+		if ln == name {
+			return nil, fmt.Errorf("requires: layer %q cannot require itself", name)
+		}
+		requires = append(requires, ln)
+	}
+	return requires, nil
+}
+
+// NewMissingRequiredLayerError creates an error for a layer whose
+// requirement the target does not include.
+func NewMissingRequiredLayerError(layer, required LayerName, target TargetName) *UserError {
+	return &UserError{
+		Code:       ErrCodeTargetInvalid,
+		Message:    fmt.Sprintf("layer '%s' requires '%s', which target '%s' does not include", layer, required, target),
+		Suggestion: fmt.Sprintf("Add '%s' to target '%s', or set defaults.requires: include to add required layers automatically.", required, target),
+		Underlying: ErrMissingRequiredLayer,
+	}
+}
+
+// resolveRequires checks that the layers of target include what they
+// require. With RequiresInclude a missing layer is loaded from layersDir and
+// placed in front of the first layer requiring it, after its own
+// requirements.
+func (l *Loader) resolveRequires(layers []Layer, target TargetName, layersDir string, strategy RequiresStrategy) ([]Layer, error) {
+	present := make(map[LayerName]bool, len(layers))
+	for _, layer := range layers {
+		present[layer.Name] = true
+	}
+
+	resolved := make([]Layer, 0, len(layers))
+	var add func(layer Layer) error
+	add = func(layer Layer) error {
+		for _, req := range layer.Requires {
+			if present[req] {
+				continue
+			}
+			if strategy != RequiresInclude {
+				return NewMissingRequiredLayerError(layer.Name, req, target)
+			}
+			// Marked before loading, so that a cycle of requirements ends.
+			present[req] = true
+			dep, err := l.LoadLayer(filepath.Join(layersDir, req.String()+".yaml"))
+			if err != nil {
+				return err
+			}
+			if err := add(*dep); err != nil {
+				return err
+			}
+		}
+		resolved = append(resolved, layer)
+		return nil
+	}
+	for _, layer := range layers {
+		if err := add(layer); err != nil {
+			return nil, err
+		}
+	}
+	return resolved, nil
+}
@@ -0,0 +1,127 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeRequiresLayers writes layers where dev-node requires node, which
+// requires base.
+func writeRequiresLayers(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	layers := map[string]string{
+		"base":     "name: base\n",
+		"node":     "name: node\nrequires: [base]\npackages:\n  brew:\n    formulae: [node]\n",
+		"dev-node": "name: dev-node\nrequires: [node]\npackages:\n  npm:\n    packages: [pnpm]\n",
+	}
+	for name, content := range layers {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name+".yaml"), []byte(content), 0o644))
+	}
+	return dir
+}
+
+func targetLayerNames(target *config.Target) []string {
+	names := make([]string, 0, len(target.Layers))
+	for _, l := range target.Layers {
+		names = append(names, l.Name.String())
+	}
+	return names
+}
+
+func TestParseLayer_Requires(t *testing.T) {
+	t.Parallel()
+
+	layer, err := config.ParseLayer([]byte("name: dev-node\nrequires: [base, node]\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"base", "node"}, layerNames(layer.Requires))
+
+	_, err = config.ParseLayer([]byte("name: dev-node\nrequires: [dev-node]\n"))
+	require.Error(t, err)
+	_, err = config.ParseLayer([]byte("name: dev-node\nrequires: [\"no de\"]\n"))
+	require.ErrorIs(t, err, config.ErrInvalidLayerName)
+}
+
+func TestParseManifest_InvalidRequiresStrategy(t *testing.T) {
+	t.Parallel()
+
+	_, err := config.ParseManifest([]byte("defaults:\n  requires: ignore\ntargets:\n  default: [base]\n"))
+	require.ErrorIs(t, err, config.ErrInvalidRequires)
+}
+
+func TestLoader_LoadTarget_RequiresSatisfied(t *testing.T) {
+	t.Parallel()
+
+	dir := writeRequiresLayers(t)
+	manifest, err := config.ParseManifest([]byte("targets:\n  work: [dev-node, base, node]\n"))
+	require.NoError(t, err)
+
+	target, err := config.NewLoader().LoadTarget(manifest, mustTarget(t, "work"), dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dev-node", "base", "node"}, targetLayerNames(target), "listed layers keep their order")
+}
+
+func TestLoader_LoadTarget_MissingRequiredLayer(t *testing.T) {
+	t.Parallel()
+
+	dir := writeRequiresLayers(t)
+	manifest, err := config.ParseManifest([]byte("targets:\n  work: [base, dev-node]\n"))
+	require.NoError(t, err)
+
+	_, err = config.NewLoader().LoadTarget(manifest, mustTarget(t, "work"), dir)
+	require.ErrorIs(t, err, config.ErrMissingRequiredLayer)
+	assert.Contains(t, err.Error(), `layer 'dev-node' requires 'node', which target 'work' does not include`)
+}
+
+func TestLoader_LoadTarget_IncludesRequiredLayers(t *testing.T) {
+	t.Parallel()
+
+	dir := writeRequiresLayers(t)
+	manifest, err := config.ParseManifest([]byte("defaults:\n  requires: include\ntargets:\n  work: [dev-node]\n"))
+	require.NoError(t, err)
+
+	target, err := config.NewLoader().LoadTarget(manifest, mustTarget(t, "work"), dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"base", "node", "dev-node"}, targetLayerNames(target))
+}
+
+func TestLoader_LoadTarget_IncludeRequiresCycle(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.yaml"), []byte("name: a\nrequires: [b]\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.yaml"), []byte("name: b\nrequires: [a]\n"), 0o644))
+	manifest, err := config.ParseManifest([]byte("defaults:\n  requires: include\ntargets:\n  work: [a]\n"))
+	require.NoError(t, err)
+
+	target, err := config.NewLoader().LoadTarget(manifest, mustTarget(t, "work"), dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b", "a"}, targetLayerNames(target))
+}
+
+func TestLoader_LoadTarget_IncludeMissingFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.yaml"), []byte("name: a\nrequires: [gone]\n"), 0o644))
+	manifest, err := config.ParseManifest([]byte("defaults:\n  requires: include\ntargets:\n  work: [a]\n"))
+	require.NoError(t, err)
+
+	_, err = config.NewLoader().LoadTarget(manifest, mustTarget(t, "work"), dir)
+	require.Error(t, err)
+	assert.False(t, errors.Is(err, config.ErrMissingRequiredLayer))
+	assert.True(t, config.IsUserError(err, config.ErrCodeLayerNotFound))
+}
+
+func mustTarget(t *testing.T, name string) config.TargetName {
+	t.Helper()
+	tn, err := config.NewTargetName(name)
+	require.NoError(t, err)
+	return tn
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidRole is returned for an invalid roles entry.
+var ErrInvalidRole = errors.New("invalid role")
+
+// parseRoles validates the roles section of a manifest. Role names follow
+// the rules of target names; a role lists layers, not other roles.
+func parseRoles(raw map[string][]string) (map[string][]LayerName, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	roles := make(map[string][]LayerName, len(raw))
+	for name, layerNames := range raw {
+		if _, err := NewTargetName(name); err != nil {
+			return nil, fmt.Errorf("%w: %q: %w", ErrInvalidRole, name, err)
+		}
+		if len(layerNames) == 0 {
+			return nil, fmt.Errorf("%w: %q lists no layers", ErrInvalidRole, name)
+		}
+		layers := make([]LayerName, 0, len(layerNames))
+		for _, layerName := range layerNames {
+			if _, ok := raw[layerName]; ok {
+				return nil, fmt.Errorf("%w: %q cannot include role %q", ErrInvalidRole, name, layerName)
+			}
+			ln, err := NewLayerName(layerName)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %q: %w", ErrInvalidRole, name, err)
+			}
+			layers = append(layers, ln)
+		}
+		roles[name] = layers
+	}
+	return roles, nil
+}
+
+// expandRoles returns the layers of a target, replacing the names of roles
+// with their layers. A layer that several entries bring in is kept at its
+// first position.
+func expandRoles(names []string, roles map[string][]LayerName) ([]LayerName, error) {
+	layers := make([]LayerName, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	add := func(ln LayerName) {
+		if !seen[ln.String()] {
+			seen[ln.String()] = true
+			layers = append(layers, ln)
+		}
+	}
+	for _, name := range names {
+		if role, ok := roles[name]; ok {
+			for _, ln := range role {
+				add(ln)
+			}
+			continue
+		}
+		ln, err := NewLayerName(name)
+		if err != nil {
+			return nil, err
+		}
+		add(ln)
+	}
+	return layers, nil
+}
@@ -0,0 +1,56 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func layerNames(layers []config.LayerName) []string {
+	names := make([]string, 0, len(layers))
+	for _, ln := range layers {
+		names = append(names, ln.String())
+	}
+	return names
+}
+
+func TestParseManifest_RolesExpandInTargets(t *testing.T) {
+	t.Parallel()
+
+	manifest, err := config.ParseManifest([]byte(`
+roles:
+  backend: [lang.go, tool.containers, tool.databases]
+  data: [lang.python, tool.databases]
+targets:
+  work: [base, backend, data, identity.work]
+  personal: [base]
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"lang.go", "tool.containers", "tool.databases"}, layerNames(manifest.Roles["backend"]))
+	assert.Equal(t,
+		[]string{"base", "lang.go", "tool.containers", "tool.databases", "lang.python", "identity.work"},
+		layerNames(manifest.Targets["work"]),
+		"a layer shared by two roles is merged once, at its first position")
+	assert.Equal(t, []string{"base"}, layerNames(manifest.Targets["personal"]))
+}
+
+func TestParseManifest_InvalidRoles(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"dotted name":   "roles:\n  role.backend: [lang.go]\n",
+		"no layers":     "roles:\n  backend: []\n",
+		"nested role":   "roles:\n  backend: [lang.go]\n  fullstack: [backend, lang.node]\n",
+		"invalid layer": "roles:\n  backend: [\"lang go\"]\n",
+	}
+	for name, roles := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			_, err := config.ParseManifest([]byte(roles + "targets:\n  work: [base]\n"))
+			assert.ErrorIs(t, err, config.ErrInvalidRole)
+		})
+	}
+}
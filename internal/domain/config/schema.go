@@ -0,0 +1,222 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// JSON Schema publication details. YAML language servers pick a schema up
+// from a "# yaml-language-server: $schema=<url>" comment or from editor
+// settings that map file patterns to URLs.
+const (
+	// SchemaDialect is the JSON Schema draft the generated schemas use.
+	SchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+	// SchemaBaseURL is where the schemas are published.
+	SchemaBaseURL = "https://felixgeelhaar.github.io/preflight/schema/"
+	// ManifestSchemaFile is the file name of the preflight.yaml schema.
+	ManifestSchemaFile = "preflight.schema.json"
+	// LayerSchemaFile is the file name of the layer file schema.
+	LayerSchemaFile = "layer.schema.json"
+)
+
+// Schema is the subset of JSON Schema that preflight generates and
+// validates against.
+type Schema struct {
+	Dialect     string             `json:"$schema,omitempty"`
+	ID          string             `json:"$id,omitempty"`
+	Ref         string             `json:"$ref,omitempty"`
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Type        string             `json:"type,omitempty"`
+	Enum        []string           `json:"enum,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	// AnyOf lists the shapes of a value that accepts several.
+	AnyOf []*Schema `json:"anyOf,omitempty"`
+	// AdditionalProperties is false, a *Schema for map values, or nil to
+	// allow any additional keys.
+	AdditionalProperties any                `json:"additionalProperties,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+	// Deprecated marks a key that still works but is being phased out;
+	// Description says what to do instead.
+	Deprecated bool `json:"deprecated,omitempty"`
+}
+
+// schemaEnums lists the values of string types that only accept a fixed set.
+var schemaEnums = map[reflect.Type][]string{
+	reflect.TypeOf(FileMode("")):            {string(FileModeGenerated), string(FileModeTemplate), string(FileModeBYO), string(FileModeBlock)},
+	reflect.TypeOf(ReproducibilityMode("")): {string(ModeIntent), string(ModeLocked), string(ModeFrozen)},
+	reflect.TypeOf(DuplicateStrategy("")):   {string(DuplicatesWarn), string(DuplicatesKeepFirst), string(DuplicatesKeepMostSpecific)},
+	reflect.TypeOf(RequiresStrategy("")):    {string(RequiresError), string(RequiresInclude)},
+}
+
+// schemaAlternatives lists the shapes of types that YAML may give in
+// several forms.
+var schemaAlternatives = map[reflect.Type][]reflect.Type{
+	reflect.TypeOf(targetYAML{}): {reflect.TypeOf([]string{}), reflect.TypeOf(TargetExtension{})},
+	reflect.TypeOf(stringList{}): {reflect.TypeOf(""), reflect.TypeOf([]string{})},
+}
+
+// layerSectionDescriptions describe the top-level keys of a layer file.
+var layerSectionDescriptions = map[string]string{
+	"name":       "Layer name; must match the file name",
+	"requires":   "Layers this layer builds on, which targets using it must include",
+	"packages":   "Packages to install, by package manager",
+	"files":      "Dotfiles to manage",
+	"git":        "Git configuration (~/.gitconfig)",
+	"ssh":        "SSH client configuration (~/.ssh/config)",
+	"runtime":    "Language runtime versions",
+	"shell":      "Shell, plugins, aliases and environment",
+	"nvim":       "Neovim configuration",
+	"vscode":     "VS Code extensions and settings",
+	"tmux":       "tmux configuration and plugins",
+	"terminal":   "Terminal emulator configuration",
+	"docker":     "Container runtime, daemon settings, registries and contexts",
+	"kubernetes": "kubectl plugins, contexts and default namespace",
+	"aws":        "AWS CLI profiles",
+	"gcloud":     "Google Cloud CLI configurations",
+	"azure":      "Azure CLI subscriptions",
+	"cron":       "Scheduled jobs",
+	"macos":      "macOS defaults",
+	"network":    "DNS servers, proxies and /etc/hosts entries",
+	"scripts":    "Commands and scripts that run during apply",
+	"path":       "Directories to add to PATH",
+	"direnv":     "Project directories that get a generated .envrc",
+	"onboarding": "Manual onboarding tasks for new team members",
+	"pins":       "Packages held at their current version",
+	"checks":     "Health check commands run after a canary upgrade of a package",
+	"doctor":     "Custom checks that doctor runs, such as a VPN client being installed",
+
+	"password_manager": "Password manager CLIs (1Password, Bitwarden) that secret references resolve through",
+	"editorconfig":     "Default ~/.editorconfig for projects without their own",
+}
+
+// manifestSectionDescriptions describe the top-level keys of preflight.yaml.
+var manifestSectionDescriptions = map[string]string{
+	"ai":       "Limits on AI features",
+	"defaults": "Defaults for all targets",
+	"history":  "Retention of the apply history",
+	"machines": "Targets bound to machine fingerprints, picked by apply without --target",
+	"roles":    "Named sets of layers for job families, which targets list like layers",
+	"targets":  "Targets and the layers they merge, in order, or the targets they extend",
+	"upgrades": "Maintenance window and notifications for scheduled upgrades",
+}
+
+// ManifestSchema returns the JSON Schema of preflight.yaml.
+func ManifestSchema() *Schema {
+	s := rootSchema(reflect.TypeOf(manifestYAML{}), manifestSectionDescriptions)
+	s.ID = SchemaBaseURL + ManifestSchemaFile
+	s.Title = "Preflight manifest (preflight.yaml)"
+	s.Required = []string{"targets"}
+	return s
+}
+
+// LayerSchema returns the JSON Schema of a layer file.
+func LayerSchema() *Schema {
+	s := rootSchema(reflect.TypeOf(layerYAML{}), layerSectionDescriptions)
+	s.ID = SchemaBaseURL + LayerSchemaFile
+	s.Title = "Preflight layer (layers/*.yaml)"
+	s.Required = []string{"name"}
+	return s
+}
+
+// rootSchema builds the schema of a file from the struct it is parsed into.
+// The root allows additional keys because other features read their own
+// sections of the same files; nested objects do not.
+func rootSchema(t reflect.Type, descriptions map[string]string) *Schema {
+	g := schemaGenerator{defs: make(map[string]*Schema)}
+	s := g.objectSchema(t)
+	s.AdditionalProperties = nil
+	for key, description := range descriptions {
+		if prop, ok := s.Properties[key]; ok {
+			prop.Description = description
+		}
+	}
+	s.Dialect = SchemaDialect
+	s.Defs = g.defs
+	return s
+}
+
+// schemaGenerator derives schemas from Go types by their yaml tags.
+type schemaGenerator struct {
+	defs map[string]*Schema
+}
+
+func (g *schemaGenerator) schemaFor(t reflect.Type) *Schema {
+	if values, ok := schemaEnums[t]; ok {
+		return &Schema{Type: "string", Enum: values}
+	}
+	if alternatives, ok := schemaAlternatives[t]; ok {
+		s := &Schema{}
+		for _, alt := range alternatives {
+			s.AnyOf = append(s.AnyOf, g.schemaFor(alt))
+		}
+		return s
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.schemaFor(t.Elem())
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: g.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.objectSchema(t)
+		}
+		// Named structs are shared definitions, which also terminates
+		// recursive types.
+		if _, ok := g.defs[t.Name()]; !ok {
+			g.defs[t.Name()] = &Schema{}
+			*g.defs[t.Name()] = *g.objectSchema(t)
+		}
+		return &Schema{Ref: "#/$defs/" + t.Name()}
+	default:
+		return &Schema{}
+	}
+}
+
+func (g *schemaGenerator) objectSchema(t reflect.Type) *Schema {
+	s := &Schema{
+		Type:                 "object",
+		Properties:           make(map[string]*Schema),
+		AdditionalProperties: false,
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = strings.ToLower(field.Name)
+		}
+		prop := g.schemaFor(field.Type)
+		// A deprecated:"<what to do instead>" tag marks a phased-out key
+		if reason, ok := field.Tag.Lookup("deprecated"); ok {
+			prop.Deprecated = true
+			prop.Description = reason
+		}
+		s.Properties[name] = prop
+	}
+	return s
+}
+
+// SchemaModeline returns the comment that points YAML language servers at
+// a published schema, to put on the first line of a generated file.
+func SchemaModeline(file string) string {
+	return "# yaml-language-server: $schema=" + SchemaBaseURL + file + "\n"
+}
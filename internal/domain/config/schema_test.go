@@ -0,0 +1,99 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLayerSchema_DescribesSections(t *testing.T) {
+	t.Parallel()
+
+	s := LayerSchema()
+	assert.Equal(t, SchemaBaseURL+LayerSchemaFile, s.ID)
+	assert.Equal(t, []string{"name"}, s.Required)
+	assert.Nil(t, s.AdditionalProperties, "other features read their own top-level keys")
+	assert.Equal(t, "#/$defs/PackageSet", s.Properties["packages"].Ref)
+
+	brew := s.Defs["BrewPackages"]
+	require.NotNil(t, brew)
+	assert.Equal(t, false, brew.AdditionalProperties)
+	assert.Equal(t, "array", brew.Properties["formulae"].Type)
+	assert.Equal(t, "string", brew.Properties["formulae"].Items.Type)
+
+	assert.Equal(t, []string{"generated", "template", "byo", "block"}, s.Defs["FileDeclaration"].Properties["mode"].Enum)
+}
+
+func TestValidateSchema_ReportsLineAndColumn(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(`name: base
+packages:
+  brew:
+    formula:
+      - git
+    casks: firefox
+git:
+  commit:
+    gpgsign: "yes"
+files:
+  - path: ~/.zshrc
+    mode: copied
+`)
+	issues, err := ValidateSchema(data, LayerSchema())
+	require.NoError(t, err)
+	require.Len(t, issues, 4)
+
+	assert.Equal(t, SchemaIssue{Line: 4, Column: 5, Path: "packages.brew.formula", Message: `unknown key "formula" is ignored (expected one of arch, casks, formulae, taps)`, Warning: true}, issues[0])
+	assert.Equal(t, "6:12: packages.brew.casks: expected a list, found \"firefox\"", issues[1].String())
+	assert.Equal(t, "9:14: git.commit.gpgsign: expected true or false, found \"yes\"", issues[2].String())
+	assert.Equal(t, "12:11: files[0].mode: \"copied\" is not one of generated, template, byo, block", issues[3].String())
+}
+
+func TestValidateSchema_RequiredAndMaps(t *testing.T) {
+	t.Parallel()
+
+	issues, err := ValidateSchema([]byte("defaults:\n  mode: intent\n"), ManifestSchema())
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, `1:1: missing required key "targets"`, issues[0].String())
+
+	issues, err = ValidateSchema([]byte("targets:\n  work: base\n"), ManifestSchema())
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, "2:9: targets.work: expected a list or an object, found \"base\"", issues[0].String())
+
+	issues, err = ValidateSchema([]byte("targets:\n  work: [base]\n  laptop:\n    extends: work\n    add: [3]\n    remove: [base]\n"), ManifestSchema())
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, "6:5: targets.laptop.remove: unknown key \"remove\" is ignored (expected one of add, extends)", issues[0].String())
+
+	issues, err = ValidateSchema([]byte("targets:\n  default: [base]\nhooks: []\n"), ManifestSchema())
+	require.NoError(t, err)
+	assert.Empty(t, issues, "unknown top-level keys are allowed")
+}
+
+func TestValidateSchema_InvalidYAML(t *testing.T) {
+	t.Parallel()
+
+	_, err := ValidateSchema([]byte("targets: [base"), ManifestSchema())
+	assert.Error(t, err)
+}
+
+func TestValidateSchema_Deprecated(t *testing.T) {
+	t.Parallel()
+
+	s := LayerSchema()
+	ensureInstall := s.Defs["NvimConfig"].Properties["ensure_install"]
+	require.NotNil(t, ensureInstall)
+	assert.True(t, ensureInstall.Deprecated)
+	assert.Contains(t, ensureInstall.Description, "no effect")
+
+	issues, err := ValidateSchema([]byte("name: base\nnvim:\n  preset: kickstart\n  ensure_install: true\n"), s)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.True(t, issues[0].Warning)
+	assert.True(t, issues[0].Deprecated)
+	assert.Equal(t, `4:3: nvim.ensure_install: "ensure_install" is deprecated: `+ensureInstall.Description, issues[0].String())
+}
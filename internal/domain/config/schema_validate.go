@@ -0,0 +1,231 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaIssue is a place where a YAML document does not match its schema.
+type SchemaIssue struct {
+	Line    int
+	Column  int
+	Path    string // Dotted key path, e.g. "packages.brew.formulae[0]"
+	Message string
+	// Warning marks issues preflight tolerates, such as unknown keys,
+	// which are ignored rather than rejected.
+	Warning bool
+	// Deprecated marks a warning about a deprecated key.
+	Deprecated bool
+}
+
+// String returns the issue as "line:column: path: message".
+func (i SchemaIssue) String() string {
+	if i.Path == "" {
+		return fmt.Sprintf("%d:%d: %s", i.Line, i.Column, i.Message)
+	}
+	return fmt.Sprintf("%d:%d: %s: %s", i.Line, i.Column, i.Path, i.Message)
+}
+
+// ValidateSchema checks YAML data against a schema and returns the issues in
+// document order. It returns an error only when data is not valid YAML.
+func ValidateSchema(data []byte, schema *Schema) ([]SchemaIssue, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	v := schemaValidator{defs: schema.Defs}
+	v.validate(doc.Content[0], schema, "")
+	return v.issues, nil
+}
+
+type schemaValidator struct {
+	defs   map[string]*Schema
+	issues []SchemaIssue
+}
+
+func (v *schemaValidator) report(node *yaml.Node, path string, warning bool, format string, args ...any) {
+	v.issues = append(v.issues, SchemaIssue{
+		Line:    node.Line,
+		Column:  node.Column,
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+		Warning: warning,
+	})
+}
+
+func (v *schemaValidator) resolve(s *Schema) *Schema {
+	for s.Ref != "" {
+		def, ok := v.defs[strings.TrimPrefix(s.Ref, "#/$defs/")]
+		if !ok {
+			return &Schema{}
+		}
+		s = def
+	}
+	return s
+}
+
+func (v *schemaValidator) validate(node *yaml.Node, s *Schema, path string) {
+	s = v.resolve(s)
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return
+	}
+	if len(s.AnyOf) > 0 {
+		v.validateAnyOf(node, s.AnyOf, path)
+		return
+	}
+
+	switch s.Type {
+	case "object":
+		v.validateObject(node, s, path)
+	case "array":
+		if node.Kind != yaml.SequenceNode {
+			v.report(node, path, false, "expected a list, found %s", describeNode(node))
+			return
+		}
+		for i, item := range node.Content {
+			v.validate(item, s.Items, fmt.Sprintf("%s[%d]", path, i))
+		}
+	case "string":
+		if node.Kind != yaml.ScalarNode {
+			v.report(node, path, false, "expected a string, found %s", describeNode(node))
+			return
+		}
+		if len(s.Enum) > 0 && !slices.Contains(s.Enum, node.Value) {
+			v.report(node, path, false, "%q is not one of %s", node.Value, strings.Join(s.Enum, ", "))
+		}
+	case "boolean":
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!bool" {
+			v.report(node, path, false, "expected true or false, found %s", describeNode(node))
+		}
+	case "integer":
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!int" {
+			v.report(node, path, false, "expected an integer, found %s", describeNode(node))
+		}
+	case "number":
+		if node.Kind != yaml.ScalarNode || (node.Tag != "!!int" && node.Tag != "!!float") {
+			v.report(node, path, false, "expected a number, found %s", describeNode(node))
+		}
+	}
+}
+
+// validateAnyOf validates node against the alternative for its kind: an
+// object, a list or a scalar.
+func (v *schemaValidator) validateAnyOf(node *yaml.Node, alternatives []*Schema, path string) {
+	kinds := make([]string, 0, len(alternatives))
+	for _, alt := range alternatives {
+		alt = v.resolve(alt)
+		switch alt.Type {
+		case "object":
+			if node.Kind == yaml.MappingNode {
+				v.validate(node, alt, path)
+				return
+			}
+			kinds = append(kinds, "an object")
+		case "array":
+			if node.Kind == yaml.SequenceNode {
+				v.validate(node, alt, path)
+				return
+			}
+			kinds = append(kinds, "a list")
+		default:
+			if node.Kind == yaml.ScalarNode {
+				v.validate(node, alt, path)
+				return
+			}
+			kinds = append(kinds, "a "+alt.Type)
+		}
+	}
+	v.report(node, path, false, "expected %s, found %s", strings.Join(kinds, " or "), describeNode(node))
+}
+
+func (v *schemaValidator) validateObject(node *yaml.Node, s *Schema, path string) {
+	if node.Kind != yaml.MappingNode {
+		v.report(node, path, false, "expected an object, found %s", describeNode(node))
+		return
+	}
+
+	seen := make(map[string]bool, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if key.Value == "<<" {
+			continue // merge key
+		}
+		seen[key.Value] = true
+		keyPath := joinSchemaPath(path, key.Value)
+
+		if prop, ok := s.Properties[key.Value]; ok {
+			if prop.Deprecated {
+				v.issues = append(v.issues, SchemaIssue{
+					Line:       key.Line,
+					Column:     key.Column,
+					Path:       keyPath,
+					Message:    fmt.Sprintf("%q is deprecated: %s", key.Value, prop.Description),
+					Warning:    true,
+					Deprecated: true,
+				})
+			}
+			v.validate(value, prop, keyPath)
+			continue
+		}
+		switch additional := s.AdditionalProperties.(type) {
+		case *Schema:
+			v.validate(value, additional, keyPath)
+		case bool:
+			if !additional {
+				v.report(key, keyPath, true, "unknown key %q is ignored%s", key.Value, knownKeysHint(s))
+			}
+		}
+	}
+
+	for _, required := range s.Required {
+		if !seen[required] {
+			v.report(node, path, false, "missing required key %q", required)
+		}
+	}
+}
+
+func joinSchemaPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// knownKeysHint lists the keys an object accepts, when there are few enough
+// to be useful in a message.
+func knownKeysHint(s *Schema) string {
+	if len(s.Properties) == 0 || len(s.Properties) > 8 {
+		return ""
+	}
+	keys := make([]string, 0, len(s.Properties))
+	for key := range s.Properties {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return " (expected one of " + strings.Join(keys, ", ") + ")"
+}
+
+func describeNode(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "an object"
+	case yaml.SequenceNode:
+		return "a list"
+	}
+	switch node.Tag {
+	case "!!bool":
+		return "a boolean"
+	case "!!int", "!!float":
+		return "a number"
+	}
+	return fmt.Sprintf("%q", node.Value)
+}
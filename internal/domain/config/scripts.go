@@ -0,0 +1,58 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidScriptsConfig is returned when a layer declares a script
+// without a command or without a guard.
+var ErrInvalidScriptsConfig = errors.New("invalid scripts config")
+
+// ScriptsConfig declares custom scripts keyed by name.
+type ScriptsConfig map[string]Script
+
+// Script is an inline command or script file that only runs when none of
+// its guards is satisfied: the creates path exists, or the unless or check
+// command succeeds.
+type Script struct {
+	Run       string   `yaml:"run,omitempty"`
+	Script    string   `yaml:"script,omitempty"`
+	Shell     string   `yaml:"shell,omitempty"`
+	Creates   string   `yaml:"creates,omitempty"`
+	Unless    string   `yaml:"unless,omitempty"`
+	Check     string   `yaml:"check,omitempty"`
+	DependsOn []string `yaml:"depends_on,omitempty"`
+}
+
+// IsZero reports whether no scripts are declared.
+func (c ScriptsConfig) IsZero() bool {
+	return len(c) == 0
+}
+
+func (c ScriptsConfig) normalize() error {
+	for name, script := range c {
+		if (script.Run == "") == (script.Script == "") {
+			return fmt.Errorf("%w: script %q needs exactly one of run or script", ErrInvalidScriptsConfig, name)
+		}
+		if script.Creates == "" && script.Unless == "" && script.Check == "" {
+			return fmt.Errorf("%w: script %q needs creates, unless or check so it only runs when needed", ErrInvalidScriptsConfig, name)
+		}
+	}
+	return nil
+}
+
+// mergeScripts combines the scripts of layers. Scripts are keyed by name,
+// with later layers replacing earlier definitions.
+func mergeScripts(layers []Layer) ScriptsConfig {
+	var merged ScriptsConfig
+	for _, layer := range layers {
+		for name, script := range layer.Scripts {
+			if merged == nil {
+				merged = make(ScriptsConfig)
+			}
+			merged[name] = script
+		}
+	}
+	return merged
+}
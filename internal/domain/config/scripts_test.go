@@ -0,0 +1,55 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLayer_Scripts(t *testing.T) {
+	t.Parallel()
+
+	layer, err := ParseLayer([]byte(`
+name: base
+scripts:
+  rustup:
+    run: curl -sSf https://sh.rustup.rs | sh -s -- -y
+    creates: ~/.cargo/bin/rustup
+    depends_on: [brew:formula:curl]
+`))
+	require.NoError(t, err)
+	assert.Equal(t, "~/.cargo/bin/rustup", layer.Scripts["rustup"].Creates)
+
+	for _, invalid := range []string{
+		"name: base\nscripts:\n  a:\n    unless: 'true'\n",
+		"name: base\nscripts:\n  a:\n    run: 'true'\n",
+	} {
+		_, err = ParseLayer([]byte(invalid))
+		require.ErrorIs(t, err, ErrInvalidScriptsConfig, invalid)
+	}
+}
+
+func TestMerger_Merge_Scripts(t *testing.T) {
+	t.Parallel()
+
+	base := Layer{Scripts: ScriptsConfig{
+		"rustup": {Run: "install-rustup", Creates: "~/.cargo/bin/rustup"},
+		"fonts":  {Run: "install-fonts", Unless: "fc-list | grep -q Fira"},
+	}}
+	work := Layer{Scripts: ScriptsConfig{
+		"rustup": {Run: "install-rustup --proxy", Creates: "~/.cargo/bin/rustup"},
+	}}
+
+	merged, err := NewMerger().Merge([]Layer{base, work})
+	require.NoError(t, err)
+	assert.Equal(t, "install-rustup --proxy", merged.Scripts["rustup"].Run)
+	assert.Len(t, merged.Scripts, 2)
+
+	scripts := merged.Raw()["scripts"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"run": "install-fonts", "unless": "fc-list | grep -q Fira"}, scripts["fonts"])
+
+	none, err := NewMerger().Merge([]Layer{{}})
+	require.NoError(t, err)
+	assert.NotContains(t, none.Raw(), "scripts")
+}
@@ -0,0 +1,167 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SyncConfig controls what preflight sync shares with other machines.
+//
+//	sync:
+//	  exclude:
+//	    layers: [personal, "secrets-*"]
+//	    providers: [ssh]
+//	  autocommit:
+//	    enabled: true
+//	    tag: "applied/{machine}/{date}"
+//	  lock_signing:
+//	    key: ~/.ssh/preflight_ed25519
+//	    untrusted: warn
+type SyncConfig struct {
+	Exclude     SyncExclude     `yaml:"exclude,omitempty"`
+	Autocommit  SyncAutocommit  `yaml:"autocommit,omitempty"`
+	LockSigning SyncLockSigning `yaml:"lock_signing,omitempty"`
+}
+
+// SyncExclude lists content that never leaves this machine through sync.
+type SyncExclude struct {
+	// Layers are layer names or glob patterns; "personal" and "personal.yaml"
+	// are equivalent.
+	Layers []string `yaml:"layers,omitempty"`
+	// Providers are provider names whose layer sections and locked packages
+	// are withheld, such as "ssh" or "npm".
+	Providers []string `yaml:"providers,omitempty"`
+}
+
+// SyncAutocommit commits the lockfile and layer changes of apply and doctor
+// --update-config to the config repository, so that git history follows
+// what machines applied.
+type SyncAutocommit struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// Tag names a tag on each commit. {target}, {machine} and {date} are
+	// replaced with the target, the hostname and the UTC time.
+	Tag string `yaml:"tag,omitempty"`
+}
+
+// autocommitTagPlaceholders are the placeholders a tag template may use.
+var autocommitTagPlaceholders = []string{"{target}", "{machine}", "{date}"}
+
+var placeholderPattern = regexp.MustCompile(`\{[^{}]*\}`)
+
+// Policies for lockfiles that sync pull cannot trust.
+const (
+	// LockSigningRefuse stops the pull.
+	LockSigningRefuse = "refuse"
+	// LockSigningWarn pulls the lockfile and prints a warning.
+	LockSigningWarn = "warn"
+)
+
+// SyncLockSigning signs the lockfile written on this machine and decides
+// what sync pull does with lockfiles it cannot trust.
+type SyncLockSigning struct {
+	// Key is an ed25519 private key whose public key is in the trust store.
+	Key string `yaml:"key,omitempty"`
+	// Untrusted is "refuse" (the default) or "warn". It applies to
+	// lockfiles signed by keys outside the trust store and, once lock
+	// signing is configured, to unsigned lockfiles.
+	Untrusted string `yaml:"untrusted,omitempty"`
+}
+
+// Enabled reports whether lock signing is configured.
+func (s SyncLockSigning) Enabled() bool {
+	return s.Key != "" || s.Untrusted != ""
+}
+
+// WarnsOnUntrusted reports whether untrusted lockfiles are pulled with a
+// warning rather than refused.
+func (s SyncLockSigning) WarnsOnUntrusted() bool {
+	return s.Untrusted == LockSigningWarn
+}
+
+// TagName expands the tag template, or returns "" when no tag is set.
+func (a SyncAutocommit) TagName(target, machine string, at time.Time) string {
+	if a.Tag == "" {
+		return ""
+	}
+	return strings.NewReplacer(
+		"{target}", target,
+		"{machine}", machine,
+		"{date}", at.UTC().Format("20060102T150405Z"),
+	).Replace(a.Tag)
+}
+
+// ParseSyncConfig extracts the sync section from manifest YAML.
+func ParseSyncConfig(data []byte) (*SyncConfig, error) {
+	var raw struct {
+		Sync SyncConfig `yaml:"sync"`
+	}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	for _, pattern := range raw.Sync.Exclude.Layers {
+		if _, err := path.Match(layerPattern(pattern), ""); err != nil {
+			return nil, fmt.Errorf("sync.exclude.layers: invalid pattern %q: %w", pattern, err)
+		}
+	}
+	for _, placeholder := range placeholderPattern.FindAllString(raw.Sync.Autocommit.Tag, -1) {
+		if !slices.Contains(autocommitTagPlaceholders, placeholder) {
+			return nil, fmt.Errorf("sync.autocommit.tag: unknown placeholder %s (use %s)", placeholder, strings.Join(autocommitTagPlaceholders, ", "))
+		}
+	}
+	switch raw.Sync.LockSigning.Untrusted {
+	case "", LockSigningRefuse, LockSigningWarn:
+	default:
+		return nil, fmt.Errorf("sync.lock_signing.untrusted: unknown policy %q (use %s or %s)", raw.Sync.LockSigning.Untrusted, LockSigningRefuse, LockSigningWarn)
+	}
+	return &raw.Sync, nil
+}
+
+// IsZero reports whether nothing is excluded from sync.
+func (c *SyncConfig) IsZero() bool {
+	return c == nil || (len(c.Exclude.Layers) == 0 && len(c.Exclude.Providers) == 0)
+}
+
+// ExcludesLayer reports whether the named layer must not be synced.
+func (c *SyncConfig) ExcludesLayer(name string) bool {
+	if c == nil {
+		return false
+	}
+	name = layerPattern(name)
+	for _, pattern := range c.Exclude.Layers {
+		if ok, _ := path.Match(layerPattern(pattern), name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ExcludesProvider reports whether the provider's configuration must not be synced.
+func (c *SyncConfig) ExcludesProvider(name string) bool {
+	if c == nil {
+		return false
+	}
+	for _, p := range c.Exclude.Providers {
+		if strings.EqualFold(p, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// layerPattern strips the layers/ directory and YAML extension so that layer
+// names and file names can be used interchangeably.
+func layerPattern(s string) string {
+	s = strings.TrimPrefix(s, "layers/")
+	for _, ext := range []string{".yaml", ".yml"} {
+		if strings.HasSuffix(s, ext) {
+			return strings.TrimSuffix(s, ext)
+		}
+	}
+	return s
+}
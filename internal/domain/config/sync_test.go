@@ -0,0 +1,90 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSyncConfig(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseSyncConfig([]byte(`
+targets:
+  default: [base, personal]
+sync:
+  exclude:
+    layers: [personal.yaml, "secrets-*"]
+    providers: [ssh, NPM]
+`))
+	require.NoError(t, err)
+	assert.False(t, cfg.IsZero())
+
+	assert.True(t, cfg.ExcludesLayer("personal"))
+	assert.True(t, cfg.ExcludesLayer("layers/personal.yml"))
+	assert.True(t, cfg.ExcludesLayer("secrets-work"))
+	assert.False(t, cfg.ExcludesLayer("base"))
+
+	assert.True(t, cfg.ExcludesProvider("ssh"))
+	assert.True(t, cfg.ExcludesProvider("npm"))
+	assert.False(t, cfg.ExcludesProvider("brew"))
+}
+
+func TestParseSyncConfig_Absent(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseSyncConfig([]byte("targets:\n  default: [base]\n"))
+	require.NoError(t, err)
+	assert.True(t, cfg.IsZero())
+	assert.False(t, cfg.ExcludesLayer("base"))
+
+	var nilCfg *SyncConfig
+	assert.True(t, nilCfg.IsZero())
+	assert.False(t, nilCfg.ExcludesProvider("ssh"))
+}
+
+func TestParseSyncConfig_InvalidPattern(t *testing.T) {
+	t.Parallel()
+
+	_, err := ParseSyncConfig([]byte("sync:\n  exclude:\n    layers: [\"[\"]\n"))
+	assert.ErrorContains(t, err, "sync.exclude.layers")
+}
+
+func TestParseSyncConfig_Autocommit(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseSyncConfig([]byte("sync:\n  autocommit:\n    enabled: true\n    tag: \"applied/{machine}/{target}-{date}\"\n"))
+	require.NoError(t, err)
+	assert.True(t, cfg.Autocommit.Enabled)
+	assert.True(t, cfg.IsZero(), "autocommit excludes nothing")
+
+	at := time.Date(2026, 3, 1, 9, 30, 0, 0, time.FixedZone("CET", 3600))
+	assert.Equal(t, "applied/mbp/work-20260301T083000Z", cfg.Autocommit.TagName("work", "mbp", at))
+	assert.Empty(t, SyncAutocommit{Enabled: true}.TagName("work", "mbp", at))
+
+	_, err = ParseSyncConfig([]byte("sync:\n  autocommit:\n    tag: \"applied/{host}\"\n"))
+	assert.ErrorContains(t, err, "sync.autocommit.tag: unknown placeholder {host}")
+}
+
+func TestParseSyncConfig_LockSigning(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseSyncConfig([]byte("sync:\n  lock_signing:\n    key: ~/.ssh/preflight_ed25519\n"))
+	require.NoError(t, err)
+	assert.True(t, cfg.LockSigning.Enabled())
+	assert.False(t, cfg.LockSigning.WarnsOnUntrusted(), "untrusted lockfiles are refused by default")
+
+	cfg, err = ParseSyncConfig([]byte("sync:\n  lock_signing:\n    untrusted: warn\n"))
+	require.NoError(t, err)
+	assert.True(t, cfg.LockSigning.Enabled())
+	assert.True(t, cfg.LockSigning.WarnsOnUntrusted())
+
+	cfg, err = ParseSyncConfig([]byte("sync:\n  exclude:\n    providers: [ssh]\n"))
+	require.NoError(t, err)
+	assert.False(t, cfg.LockSigning.Enabled())
+
+	_, err = ParseSyncConfig([]byte("sync:\n  lock_signing:\n    untrusted: ignore\n"))
+	assert.ErrorContains(t, err, `sync.lock_signing.untrusted: unknown policy "ignore"`)
+}
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Errors for targets entries.
+var (
+	ErrInvalidTarget = errors.New("invalid target")
+	ErrTargetCycle   = errors.New("targets extend each other in a cycle")
+)
+
+// TargetExtension composes a target from other targets: the layers of the
+// targets it extends, in order, followed by the layers it adds.
+type TargetExtension struct {
+	Extends stringList `yaml:"extends"`
+	Add     []string   `yaml:"add,omitempty"`
+}
+
+// stringList is a list of strings that YAML may also give as one string.
+type stringList []string
+
+// UnmarshalYAML accepts a string or a list of strings.
+func (l *stringList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*l = stringList{node.Value}
+		return nil
+	}
+	var list []string
+	if err := node.Decode(&list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// targetYAML is a targets entry: a list of layers and roles, or a
+// TargetExtension.
+type targetYAML struct {
+	Layers    []string
+	Extension *TargetExtension
+}
+
+// UnmarshalYAML accepts a list or a mapping with extends and add.
+func (t *targetYAML) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.MappingNode {
+		t.Extension = &TargetExtension{}
+		return node.Decode(t.Extension)
+	}
+	return node.Decode(&t.Layers)
+}
+
+// resolveTargets returns the layers of each target, with roles expanded
+// and extended targets resolved, and the extensions by target name.
+func resolveTargets(raw map[string]targetYAML, roles map[string][]LayerName) (map[string][]LayerName, map[string]TargetExtension, error) {
+	r := targetResolver{
+		raw:      raw,
+		roles:    roles,
+		resolved: make(map[string][]LayerName, len(raw)),
+	}
+	extensions := make(map[string]TargetExtension)
+	for name, target := range raw {
+		if target.Extension != nil {
+			extensions[name] = *target.Extension
+		}
+		if _, err := r.resolve(name); err != nil {
+			return nil, nil, err
+		}
+	}
+	return r.resolved, extensions, nil
+}
+
+// targetResolver resolves targets depth first, remembering the chain of
+// targets being resolved to detect cycles.
+type targetResolver struct {
+	raw      map[string]targetYAML
+	roles    map[string][]LayerName
+	resolved map[string][]LayerName
+	chain    []string
+}
+
+func (r *targetResolver) resolve(name string) ([]LayerName, error) {
+	if layers, ok := r.resolved[name]; ok {
+		return layers, nil
+	}
+	for i, n := range r.chain {
+		if n == name {
+			cycle := append(append([]string{}, r.chain[i:]...), name)
+			return nil, fmt.Errorf("%w: %s", ErrTargetCycle, strings.Join(cycle, " → "))
+		}
+	}
+
+	target := r.raw[name]
+	if target.Extension == nil {
+		layers, err := expandRoles(target.Layers, r.roles)
+		if err != nil {
+			return nil, err
+		}
+		r.resolved[name] = layers
+		return layers, nil
+	}
+
+	ext := target.Extension
+	if len(ext.Extends) == 0 {
+		return nil, fmt.Errorf("%w: %q needs a target to extend", ErrInvalidTarget, name)
+	}
+	r.chain = append(r.chain, name)
+	var layers []LayerName
+	for _, parent := range ext.Extends {
+		if _, ok := r.raw[parent]; !ok {
+			return nil, fmt.Errorf("%w: %q extends %q, which is not defined", ErrInvalidTarget, name, parent)
+		}
+		parentLayers, err := r.resolve(parent)
+		if err != nil {
+			return nil, err
+		}
+		layers = appendNewLayers(layers, parentLayers)
+	}
+	r.chain = r.chain[:len(r.chain)-1]
+
+	added, err := expandRoles(ext.Add, r.roles)
+	if err != nil {
+		return nil, err
+	}
+	layers = appendNewLayers(layers, added)
+	r.resolved[name] = layers
+	return layers, nil
+}
+
+// appendNewLayers appends the layers of more that layers does not have.
+func appendNewLayers(layers, more []LayerName) []LayerName {
+	for _, ln := range more {
+		if !slices.Contains(layers, ln) {
+			layers = append(layers, ln)
+		}
+	}
+	return layers
+}
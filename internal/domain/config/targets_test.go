@@ -0,0 +1,87 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseManifest_TargetExtends(t *testing.T) {
+	t.Parallel()
+
+	manifest, err := config.ParseManifest([]byte(`
+roles:
+  backend: [lang.go, tool.databases]
+targets:
+  work: [base, identity.work]
+  work_laptop:
+    extends: work
+    add: [laptop, backend]
+  work_laptop_gpu:
+    extends: [work_laptop, gpu_base]
+    add: [base]
+  gpu_base: [cuda]
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"base", "identity.work", "laptop", "lang.go", "tool.databases"},
+		layerNames(manifest.Targets["work_laptop"]))
+	assert.Equal(t, []string{"base", "identity.work", "laptop", "lang.go", "tool.databases", "cuda"},
+		layerNames(manifest.Targets["work_laptop_gpu"]),
+		"layers already brought in by an extended target keep their position")
+
+	require.Contains(t, manifest.Extensions, "work_laptop")
+	assert.Equal(t, []string{"work"}, []string(manifest.Extensions["work_laptop"].Extends))
+	assert.Equal(t, []string{"laptop", "backend"}, manifest.Extensions["work_laptop"].Add)
+	assert.NotContains(t, manifest.Extensions, "work")
+}
+
+func TestParseManifest_TargetExtendsCycle(t *testing.T) {
+	t.Parallel()
+
+	_, err := config.ParseManifest([]byte(`
+targets:
+  a:
+    extends: b
+  b:
+    extends: c
+  c:
+    extends: a
+    add: [base]
+`))
+	require.ErrorIs(t, err, config.ErrTargetCycle)
+	assert.Regexp(t, `(a → b → c → a|b → c → a → b|c → a → b → c)$`, err.Error())
+}
+
+func TestParseManifest_InvalidTargetExtends(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"undefined target": "targets:\n  laptop:\n    extends: work\n",
+		"no extends":       "targets:\n  work: [base]\n  laptop:\n    add: [laptop]\n",
+	}
+	for name, manifest := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			_, err := config.ParseManifest([]byte(manifest))
+			assert.ErrorIs(t, err, config.ErrInvalidTarget)
+		})
+	}
+}
+
+func TestParseManifest_MachineBindsExtendedTarget(t *testing.T) {
+	t.Parallel()
+
+	_, err := config.ParseManifest([]byte(`
+targets:
+  work: [base]
+  laptop:
+    extends: work
+machines:
+  - target: laptop
+    chip: "Apple M*"
+`))
+	require.NoError(t, err)
+}
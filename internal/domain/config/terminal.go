@@ -0,0 +1,286 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidTerminalConfig is returned when a layer declares a terminal
+// keybinding or iTerm2 profile that is incomplete or duplicated.
+var ErrInvalidTerminalConfig = errors.New("invalid terminal config")
+
+// TerminalConfig declares terminal emulator configuration. Font, theme and
+// keybindings apply to every configured emulator that supports them; the
+// per-emulator sections select which emulators are managed.
+type TerminalConfig struct {
+	Font        TerminalFont         `yaml:"font,omitempty"`
+	Theme       TerminalTheme        `yaml:"theme,omitempty"`
+	Keybindings []TerminalKeybinding `yaml:"keybindings,omitempty"`
+	Alacritty   *TerminalEmulator    `yaml:"alacritty,omitempty"`
+	Ghostty     *TerminalEmulator    `yaml:"ghostty,omitempty"`
+	WezTerm     *TerminalEmulator    `yaml:"wezterm,omitempty"`
+	ITerm2      *ITerm2Config        `yaml:"iterm2,omitempty"`
+}
+
+// TerminalFont is the font shared by the configured emulators.
+type TerminalFont struct {
+	Family string  `yaml:"family,omitempty"`
+	Size   float64 `yaml:"size,omitempty"`
+}
+
+// TerminalTheme is a named color scheme and/or custom colors keyed by
+// background, foreground and the ANSI names (black, bright_red, ...).
+type TerminalTheme struct {
+	Name   string            `yaml:"name,omitempty"`
+	Custom map[string]string `yaml:"custom,omitempty"`
+}
+
+// TerminalKeybinding maps a key chord such as "ctrl+shift+t" to an action
+// in the emulator's own vocabulary.
+type TerminalKeybinding struct {
+	Keys   string `yaml:"keys"`
+	Action string `yaml:"action"`
+}
+
+// TerminalEmulator configures a file-based emulator, either from a source
+// file in the dotfiles or from settings merged into its config file.
+type TerminalEmulator struct {
+	ConfigPath string                 `yaml:"config_path,omitempty"`
+	Source     string                 `yaml:"source,omitempty"`
+	Link       bool                   `yaml:"link,omitempty"`
+	Settings   map[string]interface{} `yaml:"settings,omitempty"`
+}
+
+// ITerm2Config configures iTerm2 preferences and dynamic profiles.
+type ITerm2Config struct {
+	Settings        map[string]interface{} `yaml:"settings,omitempty"`
+	DynamicProfiles []ITerm2Profile        `yaml:"dynamic_profiles,omitempty"`
+}
+
+// ITerm2Profile is an iTerm2 dynamic profile. Without a GUID, a stable one
+// is derived from the name.
+type ITerm2Profile struct {
+	Name        string            `yaml:"name"`
+	GUID        string            `yaml:"guid,omitempty"`
+	Font        string            `yaml:"font,omitempty"`
+	FontSize    float64           `yaml:"font_size,omitempty"`
+	ColorScheme string            `yaml:"color_scheme,omitempty"`
+	Custom      map[string]string `yaml:"custom,omitempty"`
+}
+
+// IsZero reports whether no terminal configuration is declared.
+func (c TerminalConfig) IsZero() bool {
+	return c.Font == (TerminalFont{}) && c.Theme.Name == "" && len(c.Theme.Custom) == 0 &&
+		len(c.Keybindings) == 0 && c.Alacritty == nil && c.Ghostty == nil && c.WezTerm == nil && c.ITerm2 == nil
+}
+
+func (c *TerminalConfig) normalize() error {
+	for i := range c.Keybindings {
+		kb := &c.Keybindings[i]
+		kb.Keys = strings.ToLower(strings.ReplaceAll(kb.Keys, " ", ""))
+		if kb.Keys == "" || kb.Action == "" {
+			return fmt.Errorf("%w: keybinding %d needs keys and an action", ErrInvalidTerminalConfig, i+1)
+		}
+	}
+	if c.ITerm2 != nil {
+		seen := make(map[string]bool)
+		for i, profile := range c.ITerm2.DynamicProfiles {
+			if profile.Name == "" {
+				return fmt.Errorf("%w: iterm2 profile %d has no name", ErrInvalidTerminalConfig, i+1)
+			}
+			if seen[profile.Name] {
+				return fmt.Errorf("%w: duplicate iterm2 profile %q", ErrInvalidTerminalConfig, profile.Name)
+			}
+			seen[profile.Name] = true
+		}
+	}
+	return nil
+}
+
+// mergeTerminal combines the terminal configuration of layers. Scalars are
+// last-wins, colors and settings are deep merged, keybindings are keyed by
+// their chord and iTerm2 profiles by name, with later layers replacing
+// earlier definitions in place.
+func mergeTerminal(layers []Layer) TerminalConfig {
+	var merged TerminalConfig
+	keyIndex := make(map[string]int)
+	profileIndex := make(map[string]int)
+
+	for _, layer := range layers {
+		t := layer.Terminal
+		if t.Font.Family != "" {
+			merged.Font.Family = t.Font.Family
+		}
+		if t.Font.Size != 0 {
+			merged.Font.Size = t.Font.Size
+		}
+		if t.Theme.Name != "" {
+			merged.Theme.Name = t.Theme.Name
+		}
+		for name, color := range t.Theme.Custom {
+			if merged.Theme.Custom == nil {
+				merged.Theme.Custom = make(map[string]string)
+			}
+			merged.Theme.Custom[name] = color
+		}
+		for _, kb := range t.Keybindings {
+			if i, ok := keyIndex[kb.Keys]; ok {
+				merged.Keybindings[i] = kb
+				continue
+			}
+			keyIndex[kb.Keys] = len(merged.Keybindings)
+			merged.Keybindings = append(merged.Keybindings, kb)
+		}
+
+		merged.Alacritty = mergeTerminalEmulator(merged.Alacritty, t.Alacritty)
+		merged.Ghostty = mergeTerminalEmulator(merged.Ghostty, t.Ghostty)
+		merged.WezTerm = mergeTerminalEmulator(merged.WezTerm, t.WezTerm)
+
+		if t.ITerm2 != nil {
+			if merged.ITerm2 == nil {
+				merged.ITerm2 = &ITerm2Config{}
+			}
+			merged.ITerm2.Settings = mergeSettings(merged.ITerm2.Settings, t.ITerm2.Settings)
+			for _, profile := range t.ITerm2.DynamicProfiles {
+				if i, ok := profileIndex[profile.Name]; ok {
+					merged.ITerm2.DynamicProfiles[i] = profile
+					continue
+				}
+				profileIndex[profile.Name] = len(merged.ITerm2.DynamicProfiles)
+				merged.ITerm2.DynamicProfiles = append(merged.ITerm2.DynamicProfiles, profile)
+			}
+		}
+	}
+	return merged
+}
+
+func mergeTerminalEmulator(dst, src *TerminalEmulator) *TerminalEmulator {
+	if src == nil {
+		return dst
+	}
+	if dst == nil {
+		dst = &TerminalEmulator{}
+	}
+	if src.ConfigPath != "" {
+		dst.ConfigPath = src.ConfigPath
+	}
+	if src.Source != "" {
+		dst.Source = src.Source
+	}
+	if src.Link {
+		dst.Link = true
+	}
+	dst.Settings = mergeSettings(dst.Settings, src.Settings)
+	return dst
+}
+
+func mergeSettings(dst, src map[string]interface{}) map[string]interface{} {
+	for key, value := range src {
+		if dst == nil {
+			dst = make(map[string]interface{})
+		}
+		dst[key] = value
+	}
+	return dst
+}
+
+// raw converts the terminal configuration into the map the terminal
+// provider parses.
+func (c TerminalConfig) raw() map[string]interface{} {
+	terminal := make(map[string]interface{})
+	if c.Font != (TerminalFont{}) {
+		font := map[string]interface{}{"family": c.Font.Family}
+		if c.Font.Size != 0 {
+			font["size"] = c.Font.Size
+		}
+		terminal["font"] = font
+	}
+	if c.Theme.Name != "" || len(c.Theme.Custom) > 0 {
+		theme := map[string]interface{}{"name": c.Theme.Name}
+		if len(c.Theme.Custom) > 0 {
+			custom := make(map[string]interface{})
+			for name, color := range c.Theme.Custom {
+				custom[name] = color
+			}
+			theme["custom"] = custom
+		}
+		terminal["theme"] = theme
+	}
+	if len(c.Keybindings) > 0 {
+		keybindings := make([]interface{}, 0, len(c.Keybindings))
+		for _, kb := range c.Keybindings {
+			keybindings = append(keybindings, map[string]interface{}{"keys": kb.Keys, "action": kb.Action})
+		}
+		terminal["keybindings"] = keybindings
+	}
+	for name, emulator := range map[string]*TerminalEmulator{
+		"alacritty": c.Alacritty,
+		"ghostty":   c.Ghostty,
+		"wezterm":   c.WezTerm,
+	} {
+		if emulator != nil {
+			terminal[name] = emulator.raw()
+		}
+	}
+	if c.ITerm2 != nil {
+		iterm2 := make(map[string]interface{})
+		if len(c.ITerm2.Settings) > 0 {
+			iterm2["settings"] = copySettings(c.ITerm2.Settings)
+		}
+		if len(c.ITerm2.DynamicProfiles) > 0 {
+			profiles := make([]interface{}, 0, len(c.ITerm2.DynamicProfiles))
+			for _, p := range c.ITerm2.DynamicProfiles {
+				profile := map[string]interface{}{"name": p.Name}
+				if p.GUID != "" {
+					profile["guid"] = p.GUID
+				}
+				if p.Font != "" {
+					profile["font"] = p.Font
+				}
+				if p.FontSize != 0 {
+					profile["font_size"] = p.FontSize
+				}
+				if p.ColorScheme != "" {
+					profile["color_scheme"] = p.ColorScheme
+				}
+				if len(p.Custom) > 0 {
+					custom := make(map[string]interface{})
+					for k, v := range p.Custom {
+						custom[k] = v
+					}
+					profile["custom"] = custom
+				}
+				profiles = append(profiles, profile)
+			}
+			iterm2["dynamic_profiles"] = profiles
+		}
+		terminal["iterm2"] = iterm2
+	}
+	return terminal
+}
+
+func (e *TerminalEmulator) raw() map[string]interface{} {
+	emulator := make(map[string]interface{})
+	if e.ConfigPath != "" {
+		emulator["config_path"] = e.ConfigPath
+	}
+	if e.Source != "" {
+		emulator["source"] = e.Source
+	}
+	if e.Link {
+		emulator["link"] = true
+	}
+	if len(e.Settings) > 0 {
+		emulator["settings"] = copySettings(e.Settings)
+	}
+	return emulator
+}
+
+func copySettings(settings map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(settings))
+	for k, v := range settings {
+		out[k] = v
+	}
+	return out
+}
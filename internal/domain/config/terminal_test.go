@@ -0,0 +1,73 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLayer_Terminal(t *testing.T) {
+	t.Parallel()
+
+	layer, err := ParseLayer([]byte(`
+name: base
+terminal:
+  font:
+    family: JetBrains Mono
+    size: 13
+  theme:
+    name: Catppuccin Mocha
+  keybindings:
+    - keys: Ctrl+Shift+T
+      action: new_tab
+  ghostty: {}
+  iterm2:
+    dynamic_profiles:
+      - name: Work
+`))
+	require.NoError(t, err)
+	assert.Equal(t, "JetBrains Mono", layer.Terminal.Font.Family)
+	assert.Equal(t, "ctrl+shift+t", layer.Terminal.Keybindings[0].Keys)
+	assert.NotNil(t, layer.Terminal.Ghostty)
+	assert.Nil(t, layer.Terminal.Alacritty)
+
+	_, err = ParseLayer([]byte("name: base\nterminal:\n  keybindings:\n    - keys: ctrl+t\n"))
+	require.ErrorIs(t, err, ErrInvalidTerminalConfig)
+	_, err = ParseLayer([]byte("name: base\nterminal:\n  iterm2:\n    dynamic_profiles:\n      - name: A\n      - name: A\n"))
+	require.ErrorIs(t, err, ErrInvalidTerminalConfig)
+}
+
+func TestMerger_Merge_Terminal(t *testing.T) {
+	t.Parallel()
+
+	base := Layer{Terminal: TerminalConfig{
+		Font:        TerminalFont{Family: "JetBrains Mono", Size: 13},
+		Theme:       TerminalTheme{Custom: map[string]string{"background": "#000000", "red": "#ff0000"}},
+		Keybindings: []TerminalKeybinding{{Keys: "ctrl+t", Action: "new_tab"}, {Keys: "ctrl+w", Action: "close_surface"}},
+		Alacritty:   &TerminalEmulator{Settings: map[string]interface{}{"live_config_reload": true}},
+		ITerm2:      &ITerm2Config{DynamicProfiles: []ITerm2Profile{{Name: "Work", Font: "Menlo"}}},
+	}}
+	work := Layer{Terminal: TerminalConfig{
+		Font:        TerminalFont{Size: 14},
+		Theme:       TerminalTheme{Custom: map[string]string{"background": "#111111"}},
+		Keybindings: []TerminalKeybinding{{Keys: "ctrl+t", Action: "new_window"}},
+		Alacritty:   &TerminalEmulator{Settings: map[string]interface{}{"scrolling": 10000}},
+		ITerm2:      &ITerm2Config{DynamicProfiles: []ITerm2Profile{{Name: "Work", Font: "FiraCode"}}},
+	}}
+
+	merged, err := NewMerger().Merge([]Layer{base, work})
+	require.NoError(t, err)
+	term := merged.Terminal
+	assert.Equal(t, TerminalFont{Family: "JetBrains Mono", Size: 14}, term.Font)
+	assert.Equal(t, map[string]string{"background": "#111111", "red": "#ff0000"}, term.Theme.Custom)
+	assert.Equal(t, []TerminalKeybinding{{Keys: "ctrl+t", Action: "new_window"}, {Keys: "ctrl+w", Action: "close_surface"}}, term.Keybindings)
+	assert.Equal(t, map[string]interface{}{"live_config_reload": true, "scrolling": 10000}, term.Alacritty.Settings)
+	assert.Nil(t, term.Ghostty)
+	assert.Equal(t, []ITerm2Profile{{Name: "Work", Font: "FiraCode"}}, term.ITerm2.DynamicProfiles)
+
+	raw := merged.Raw()["terminal"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"family": "JetBrains Mono", "size": 14.0}, raw["font"])
+	assert.Contains(t, raw, "alacritty")
+	assert.NotContains(t, raw, "wezterm")
+}
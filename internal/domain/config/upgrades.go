@@ -0,0 +1,157 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// UpgradesConfig controls scheduled upgrades. Window is the maintenance
+// window major updates wait for, such as "Sat 09:00-12:00". Auto lets the
+// agent run scheduled upgrades. NotifyAfter is the number of deferred
+// upgrades that triggers a notification; zero uses the default. PinMaxAge
+// is how long a pin may be held, such as "90d", before doctor flags it.
+type UpgradesConfig struct {
+	Window      string `yaml:"window,omitempty"`
+	Auto        bool   `yaml:"auto,omitempty"`
+	NotifyAfter int    `yaml:"notify_after,omitempty"`
+	PinMaxAge   string `yaml:"pin_max_age,omitempty"`
+}
+
+// DefaultUpgradeNotifyAfter is the number of deferred upgrades that
+// triggers a notification when notify_after is not set.
+const DefaultUpgradeNotifyAfter = 5
+
+// ErrInvalidUpgradesConfig is returned for an invalid upgrades section.
+var ErrInvalidUpgradesConfig = errors.New("invalid upgrades config")
+
+// MaintenanceWindow is a weekly time range, in local time, in which
+// disruptive changes may be made. A window whose end is before its start
+// runs past midnight into the next day.
+type MaintenanceWindow struct {
+	days  [7]bool
+	start time.Duration
+	end   time.Duration
+	spec  string
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// ParseMaintenanceWindow parses a window such as "Sat 09:00-12:00",
+// "Sat,Sun 09:00-12:00", "Mon-Fri 22:00-02:00" or "02:00-04:00" for
+// every day.
+func ParseMaintenanceWindow(s string) (MaintenanceWindow, error) {
+	w := MaintenanceWindow{spec: strings.TrimSpace(s)}
+	fields := strings.Fields(s)
+	var days, hours string
+	switch len(fields) {
+	case 1:
+		hours = fields[0]
+	case 2:
+		days, hours = fields[0], fields[1]
+	default:
+		return MaintenanceWindow{}, fmt.Errorf("window %q must be [days] HH:MM-HH:MM", s)
+	}
+
+	if days == "" || strings.EqualFold(days, "daily") {
+		w.days = [7]bool{true, true, true, true, true, true, true}
+	} else {
+		for _, part := range strings.Split(days, ",") {
+			if err := w.addDays(part); err != nil {
+				return MaintenanceWindow{}, fmt.Errorf("window %q: %w", s, err)
+			}
+		}
+	}
+
+	from, to, ok := strings.Cut(hours, "-")
+	if !ok {
+		return MaintenanceWindow{}, fmt.Errorf("window %q must be [days] HH:MM-HH:MM", s)
+	}
+	var err error
+	if w.start, err = parseClock(from); err != nil {
+		return MaintenanceWindow{}, fmt.Errorf("window %q: %w", s, err)
+	}
+	if w.end, err = parseClock(to); err != nil {
+		return MaintenanceWindow{}, fmt.Errorf("window %q: %w", s, err)
+	}
+	if w.start == w.end {
+		return MaintenanceWindow{}, fmt.Errorf("window %q is empty", s)
+	}
+	return w, nil
+}
+
+// addDays marks a day such as "Sat" or a range such as "Mon-Fri".
+func (w *MaintenanceWindow) addDays(spec string) error {
+	from, to, isRange := strings.Cut(spec, "-")
+	first, ok := weekdayNames[strings.ToLower(from)]
+	if !ok {
+		return fmt.Errorf("unknown day %q", from)
+	}
+	last := first
+	if isRange {
+		if last, ok = weekdayNames[strings.ToLower(to)]; !ok {
+			return fmt.Errorf("unknown day %q", to)
+		}
+	}
+	for d := first; ; d = (d + 1) % 7 {
+		w.days[d] = true
+		if d == last {
+			return nil
+		}
+	}
+}
+
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, want HH:MM", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// String returns the window as it was written.
+func (w MaintenanceWindow) String() string {
+	return w.spec
+}
+
+// Contains reports whether t falls in the window.
+func (w MaintenanceWindow) Contains(t time.Time) bool {
+	clock := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	day := t.Weekday()
+	if w.start < w.end {
+		return w.days[day] && clock >= w.start && clock < w.end
+	}
+	// The window runs past midnight: it is open late on a window day and
+	// early on the day after one.
+	return (w.days[day] && clock >= w.start) || (w.days[(day+6)%7] && clock < w.end)
+}
+
+// Next returns t when it falls in the window, or else the time the window
+// next opens.
+func (w MaintenanceWindow) Next(t time.Time) time.Time {
+	if w.Contains(t) {
+		return t
+	}
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	for i := 0; i <= 7; i++ {
+		day := midnight.AddDate(0, 0, i)
+		if !w.days[day.Weekday()] {
+			continue
+		}
+		opens := time.Date(day.Year(), day.Month(), day.Day(),
+			int(w.start/time.Hour), int(w.start%time.Hour/time.Minute), 0, 0, t.Location())
+		if opens.After(t) {
+			return opens
+		}
+	}
+	return t
+}
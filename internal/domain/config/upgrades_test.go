@@ -0,0 +1,86 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// at returns a local time in the week of Monday 2026-10-12.
+func at(weekday time.Weekday, clock string) time.Time {
+	c, err := time.Parse("15:04", clock)
+	if err != nil {
+		panic(err)
+	}
+	day := 11 + int(weekday)
+	if weekday == time.Sunday {
+		day = 18
+	}
+	return time.Date(2026, time.October, day, c.Hour(), c.Minute(), 0, 0, time.Local)
+}
+
+func TestParseMaintenanceWindow_Contains(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		window string
+		t      time.Time
+		want   bool
+	}{
+		{"Sat 09:00-12:00", at(time.Saturday, "09:00"), true},
+		{"Sat 09:00-12:00", at(time.Saturday, "11:59"), true},
+		{"Sat 09:00-12:00", at(time.Saturday, "12:00"), false},
+		{"Sat 09:00-12:00", at(time.Friday, "10:00"), false},
+		{"sat,SUN 09:00-12:00", at(time.Sunday, "10:00"), true},
+		{"Mon-Fri 22:00-02:00", at(time.Friday, "23:00"), true},
+		{"Mon-Fri 22:00-02:00", at(time.Saturday, "01:30"), true},
+		{"Mon-Fri 22:00-02:00", at(time.Monday, "01:30"), false},
+		{"Fri-Mon 10:00-11:00", at(time.Sunday, "10:30"), true},
+		{"Fri-Mon 10:00-11:00", at(time.Wednesday, "10:30"), false},
+		{"02:00-04:00", at(time.Wednesday, "03:00"), true},
+		{"daily 02:00-04:00", at(time.Wednesday, "05:00"), false},
+	}
+	for _, tt := range tests {
+		w, err := config.ParseMaintenanceWindow(tt.window)
+		require.NoError(t, err, tt.window)
+		assert.Equal(t, tt.want, w.Contains(tt.t), "%s at %s", tt.window, tt.t.Format("Mon 15:04"))
+	}
+}
+
+func TestParseMaintenanceWindow_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for _, window := range []string{"", "Sat", "Sat 9-12", "Caturday 09:00-12:00", "Sat 09:00-09:00", "Sat 25:00-26:00", "Sat Sun 09:00-12:00"} {
+		_, err := config.ParseMaintenanceWindow(window)
+		assert.Error(t, err, window)
+	}
+}
+
+func TestMaintenanceWindow_Next(t *testing.T) {
+	t.Parallel()
+
+	w, err := config.ParseMaintenanceWindow("Sat 09:00-12:00")
+	require.NoError(t, err)
+	assert.Equal(t, "Sat 09:00-12:00", w.String())
+
+	inside := at(time.Saturday, "10:00")
+	assert.Equal(t, inside, w.Next(inside))
+	assert.Equal(t, at(time.Saturday, "09:00"), w.Next(at(time.Tuesday, "15:00")))
+	assert.Equal(t, at(time.Saturday, "09:00").AddDate(0, 0, 7), w.Next(at(time.Saturday, "12:30")))
+}
+
+func TestParseManifest_Upgrades(t *testing.T) {
+	t.Parallel()
+
+	manifest, err := config.ParseManifest([]byte("upgrades:\n  window: Sat 09:00-12:00\n  auto: true\n  notify_after: 3\ntargets:\n  work:\n    - base\n"))
+	require.NoError(t, err)
+	assert.Equal(t, config.UpgradesConfig{Window: "Sat 09:00-12:00", Auto: true, NotifyAfter: 3}, manifest.Upgrades)
+
+	for _, upgrades := range []string{"window: Someday", "notify_after: -1"} {
+		_, err := config.ParseManifest([]byte("upgrades:\n  " + upgrades + "\ntargets:\n  work:\n    - base\n"))
+		require.ErrorIs(t, err, config.ErrInvalidUpgradesConfig, upgrades)
+	}
+}
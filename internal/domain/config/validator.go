@@ -1,6 +1,9 @@
 package config
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // ValidationError represents a validation failure.
 type ValidationError struct {
@@ -57,6 +60,22 @@ func (v *Validator) validateFiles(files []FileDeclaration) []ValidationError {
 				Message: "template path required for template mode",
 			})
 		}
+
+		// Check blocks are named and have content
+		if file.Mode == FileModeBlock {
+			if file.Block == "" || strings.ContainsAny(file.Block, "\n\r") {
+				errors = append(errors, ValidationError{
+					Field:   fmt.Sprintf("files[%d].block", i),
+					Message: "a single-line block name is required for block mode",
+				})
+			}
+			if file.Content == "" && file.Template == "" {
+				errors = append(errors, ValidationError{
+					Field:   fmt.Sprintf("files[%d].content", i),
+					Message: "content or template required for block mode",
+				})
+			}
+		}
 	}
 
 	return errors
@@ -64,7 +83,7 @@ func (v *Validator) validateFiles(files []FileDeclaration) []ValidationError {
 
 func (v *Validator) isValidFileMode(mode FileMode) bool {
 	switch mode {
-	case FileModeGenerated, FileModeTemplate, FileModeBYO, "":
+	case FileModeGenerated, FileModeTemplate, FileModeBYO, FileModeBlock, "":
 		return true
 	default:
 		return false
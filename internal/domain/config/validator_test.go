@@ -79,6 +79,24 @@ func TestValidator_Validate_TemplateModeMissingTemplate_ReturnsError(t *testing.
 	assert.Contains(t, errors[0].Message, "template path required")
 }
 
+func TestValidator_Validate_BlockMode(t *testing.T) {
+	t.Parallel()
+
+	merged := &config.MergedConfig{
+		Files: []config.FileDeclaration{
+			{Path: "~/.zshrc", Mode: config.FileModeBlock, Block: "proxy", Content: "export HTTP_PROXY=x"},
+			{Path: "~/.bashrc", Mode: config.FileModeBlock},
+		},
+	}
+
+	validator := config.NewValidator()
+	errors := validator.Validate(merged)
+
+	require.Len(t, errors, 2)
+	assert.Equal(t, "files[1].block", errors[0].Field)
+	assert.Equal(t, "files[1].content", errors[1].Field)
+}
+
 func TestValidator_Validate_MultipleErrors_ReturnsAllErrors(t *testing.T) {
 	t.Parallel()
 
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"regexp"
@@ -40,16 +41,72 @@ func NewLayerWriter() *LayerWriter {
 
 // ApplyPatch applies a single patch to a layer file.
 func (w *LayerWriter) ApplyPatch(patch Patch) error {
-	// Read the file
-	data, err := os.ReadFile(patch.LayerPath)
+	return w.ApplyPatches([]Patch{patch})
+}
+
+// ApplyPatches applies multiple patches to layer files.
+func (w *LayerWriter) ApplyPatches(patches []Patch) error {
+	contents, err := w.Preview(patches)
 	if err != nil {
-		return fmt.Errorf("failed to read layer file: %w", err)
+		return err
+	}
+	for _, path := range patchedFiles(patches) {
+		if err := os.WriteFile(path, contents[path], 0644); err != nil {
+			return fmt.Errorf("failed to write layer file: %w", err)
+		}
+	}
+	return nil
+}
+
+// Preview returns the content each patched layer file would have after
+// applying patches, in order, without writing anything.
+func (w *LayerWriter) Preview(patches []Patch) (map[string][]byte, error) {
+	return w.PreviewFrom(nil, patches)
+}
+
+// PreviewFrom is Preview with the content of some files given, such as
+// files that do not exist yet. Other files are read from disk.
+func (w *LayerWriter) PreviewFrom(initial map[string][]byte, patches []Patch) (map[string][]byte, error) {
+	contents := make(map[string][]byte, len(initial))
+	for path, data := range initial {
+		contents[path] = data
+	}
+	for _, patch := range patches {
+		data, ok := contents[patch.LayerPath]
+		if !ok {
+			var err error
+			data, err = os.ReadFile(patch.LayerPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read layer file: %w", err)
+			}
+		}
+		patched, err := w.apply(data, patch)
+		if err != nil {
+			return nil, err
+		}
+		contents[patch.LayerPath] = patched
 	}
+	return contents, nil
+}
 
+// patchedFiles returns the files patches touch, in first-touched order.
+func patchedFiles(patches []Patch) []string {
+	var paths []string
+	seen := make(map[string]bool)
+	for _, patch := range patches {
+		if !seen[patch.LayerPath] {
+			seen[patch.LayerPath] = true
+			paths = append(paths, patch.LayerPath)
+		}
+	}
+	return paths
+}
+
+func (w *LayerWriter) apply(data []byte, patch Patch) ([]byte, error) {
 	// Parse YAML preserving comments
 	var root yaml.Node
 	if err := yaml.Unmarshal(data, &root); err != nil {
-		return fmt.Errorf("failed to parse YAML: %w", err)
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
 	}
 
 	// Parse the path
@@ -59,41 +116,30 @@ func (w *LayerWriter) ApplyPatch(patch Patch) error {
 	switch patch.Operation {
 	case PatchOpAdd:
 		if err := w.applyAdd(&root, pathParts, patch.NewValue); err != nil {
-			return err
+			return nil, err
 		}
 	case PatchOpModify:
 		if err := w.applyModify(&root, pathParts, patch.NewValue); err != nil {
-			return err
+			return nil, err
 		}
 	case PatchOpRemove:
-		if err := w.applyRemove(&root, pathParts); err != nil {
-			return err
+		if err := w.applyRemove(&root, pathParts, patch.OldValue); err != nil {
+			return nil, err
 		}
 	default:
-		return fmt.Errorf("unknown patch operation: %s", patch.Operation)
-	}
-
-	// Write back the file
-	output, err := yaml.Marshal(&root)
-	if err != nil {
-		return fmt.Errorf("failed to marshal YAML: %w", err)
+		return nil, fmt.Errorf("unknown patch operation: %s", patch.Operation)
 	}
 
-	if err := os.WriteFile(patch.LayerPath, output, 0644); err != nil {
-		return fmt.Errorf("failed to write layer file: %w", err)
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&root); err != nil {
+		return nil, fmt.Errorf("failed to marshal YAML: %w", err)
 	}
-
-	return nil
-}
-
-// ApplyPatches applies multiple patches to layer files.
-func (w *LayerWriter) ApplyPatches(patches []Patch) error {
-	for _, patch := range patches {
-		if err := w.ApplyPatch(patch); err != nil {
-			return err
-		}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to marshal YAML: %w", err)
 	}
-	return nil
+	return buf.Bytes(), nil
 }
 
 // parsePath parses a YAML path like "parent.child[0].key" into parts.
@@ -124,10 +170,30 @@ type pathPart struct {
 
 func (w *LayerWriter) applyAdd(root *yaml.Node, parts []pathPart, value interface{}) error {
 	node, parent := w.findNode(root, parts)
+	if item, ok := value.(string); ok && node != nil && node.Kind == yaml.SequenceNode {
+		// Adding a value to a list appends it once
+		for _, existing := range node.Content {
+			if existing.Value == item {
+				return nil
+			}
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: item})
+		return nil
+	}
 	if node != nil && !parts[len(parts)-1].isIndex {
 		// Key exists, treat as modify
 		return w.applyModify(root, parts, value)
 	}
+	if node == nil && !parts[len(parts)-1].isIndex {
+		// Create missing parent mappings
+		var err error
+		if parent, err = w.ensureMapping(root, parts[:len(parts)-1]); err != nil {
+			return err
+		}
+		if item, ok := value.(string); ok && isListPath(parts) {
+			value = []string{item}
+		}
+	}
 
 	// Create value node
 	valueNode := &yaml.Node{}
@@ -194,7 +260,12 @@ func (w *LayerWriter) applyModify(root *yaml.Node, parts []pathPart, value inter
 
 	// Update the node in place, preserving style hints
 	if valueNode.Kind == yaml.DocumentNode && len(valueNode.Content) > 0 {
-		*node = *valueNode.Content[0]
+		// Keep the comments of the replaced node
+		replacement := *valueNode.Content[0]
+		replacement.HeadComment = node.HeadComment
+		replacement.LineComment = node.LineComment
+		replacement.FootComment = node.FootComment
+		*node = replacement
 	} else {
 		node.Value = strings.TrimSpace(string(valueBytes))
 	}
@@ -202,11 +273,28 @@ func (w *LayerWriter) applyModify(root *yaml.Node, parts []pathPart, value inter
 	return nil
 }
 
-func (w *LayerWriter) applyRemove(root *yaml.Node, parts []pathPart) error {
+func (w *LayerWriter) applyRemove(root *yaml.Node, parts []pathPart, oldValue interface{}) error {
 	if len(parts) == 0 {
 		return fmt.Errorf("cannot remove root node")
 	}
 
+	// Removing a value from a list removes the matching items
+	if item, ok := oldValue.(string); ok {
+		if node, _ := w.findNode(root, parts); node != nil && node.Kind == yaml.SequenceNode {
+			kept := node.Content[:0]
+			for _, existing := range node.Content {
+				if existing.Value != item {
+					kept = append(kept, existing)
+				}
+			}
+			if len(kept) == len(node.Content) {
+				return fmt.Errorf("%s not found in %s", item, pathToString(parts))
+			}
+			node.Content = kept
+			return nil
+		}
+	}
+
 	// Find parent node
 	parentParts := parts[:len(parts)-1]
 	var parent *yaml.Node
@@ -251,6 +339,44 @@ func (w *LayerWriter) applyRemove(root *yaml.Node, parts []pathPart) error {
 	return nil
 }
 
+// ensureMapping returns the mapping at parts, creating missing keys.
+func (w *LayerWriter) ensureMapping(root *yaml.Node, parts []pathPart) (*yaml.Node, error) {
+	if root.Kind == 0 {
+		root.Kind = yaml.DocumentNode
+		root.Content = []*yaml.Node{{Kind: yaml.MappingNode}}
+	}
+	current := root
+	if current.Kind == yaml.DocumentNode && len(current.Content) > 0 {
+		current = current.Content[0]
+	}
+	for _, part := range parts {
+		if part.isIndex || current.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("cannot add key to non-mapping node")
+		}
+		var next *yaml.Node
+		for j := 0; j < len(current.Content)-1; j += 2 {
+			if current.Content[j].Value == part.key {
+				next = current.Content[j+1]
+				break
+			}
+		}
+		if next == nil {
+			next = &yaml.Node{Kind: yaml.MappingNode}
+			current.Content = append(current.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: part.key}, next)
+		} else if next.Kind == yaml.ScalarNode && next.Tag == "!!null" {
+			*next = yaml.Node{Kind: yaml.MappingNode}
+		}
+		current = next
+	}
+	return current, nil
+}
+
+// isListPath reports whether parts name a package list, which a single
+// added value starts rather than replaces.
+func isListPath(parts []pathPart) bool {
+	return isPackageList(pathToString(parts))
+}
+
 func (w *LayerWriter) findNode(root *yaml.Node, parts []pathPart) (*yaml.Node, *yaml.Node) {
 	if len(parts) == 0 {
 		return root, nil
@@ -246,6 +246,26 @@ value: 10
 	assert.Contains(t, string(content), "value: 20")
 }
 
+func TestLayerWriter_ApplyPatch_ModifyListItemKeepsComment(t *testing.T) {
+	t.Parallel()
+
+	layerPath := filepath.Join(t.TempDir(), "layer.yaml")
+	require.NoError(t, os.WriteFile(layerPath, []byte("name: test\nlist:\n  - pnpm\n  - typescript # compiler\n"), 0o644))
+
+	err := NewLayerWriter().ApplyPatch(Patch{
+		LayerPath: layerPath,
+		YAMLPath:  "list[1]",
+		Operation: PatchOpModify,
+		OldValue:  "typescript",
+		NewValue:  "typescript@5.4.5",
+	})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(layerPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "- typescript@5.4.5 # compiler")
+}
+
 func TestLayerWriter_ApplyPatch_AddTopLevelKey(t *testing.T) {
 	t.Parallel()
 
@@ -307,3 +327,42 @@ func TestLayerWriter_ApplyPatch_NestedPath(t *testing.T) {
 	require.NoError(t, err)
 	assert.Contains(t, string(content), "grandchild: newvalue")
 }
+
+func TestLayerWriter_Preview_ListValues(t *testing.T) {
+	t.Parallel()
+
+	layerPath := filepath.Join(t.TempDir(), "layer.yaml")
+	initial := "name: base\npackages:\n  brew:\n    formulae: [git, jq]\n"
+	require.NoError(t, os.WriteFile(layerPath, []byte(initial), 0o644))
+
+	contents, err := NewLayerWriter().Preview([]Patch{
+		{LayerPath: layerPath, YAMLPath: "packages.brew.formulae", Operation: PatchOpRemove, OldValue: "jq"},
+		{LayerPath: layerPath, YAMLPath: "packages.brew.formulae", Operation: PatchOpAdd, NewValue: "gh"},
+		{LayerPath: layerPath, YAMLPath: "packages.brew.formulae", Operation: PatchOpAdd, NewValue: "git"},
+		{LayerPath: layerPath, YAMLPath: "packages.brew.casks", Operation: PatchOpAdd, NewValue: "fork"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "name: base\npackages:\n  brew:\n    formulae: [git, gh]\n    casks:\n      - fork\n", string(contents[layerPath]))
+
+	data, err := os.ReadFile(layerPath)
+	require.NoError(t, err)
+	assert.Equal(t, initial, string(data), "preview does not write")
+
+	_, err = NewLayerWriter().Preview([]Patch{
+		{LayerPath: layerPath, YAMLPath: "packages.brew.formulae", Operation: PatchOpRemove, OldValue: "wget"},
+	})
+	assert.ErrorContains(t, err, "wget not found in packages.brew.formulae")
+}
+
+func TestLayerWriter_PreviewFrom_NewFile(t *testing.T) {
+	t.Parallel()
+
+	layerPath := filepath.Join(t.TempDir(), "new.yaml")
+	contents, err := NewLayerWriter().PreviewFrom(map[string][]byte{layerPath: nil}, []Patch{
+		{LayerPath: layerPath, YAMLPath: "name", Operation: PatchOpAdd, NewValue: "new"},
+		{LayerPath: layerPath, YAMLPath: "packages.npm.packages", Operation: PatchOpAdd, NewValue: "pnpm"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "name: new\npackages:\n  npm:\n    packages:\n      - pnpm\n", string(contents[layerPath]))
+	assert.NoFileExists(t, layerPath)
+}
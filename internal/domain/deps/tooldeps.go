@@ -20,6 +20,13 @@ const (
 	ToolRust   Tool = "rust"
 )
 
+// Step IDs of the macOS prerequisites bootstrapped before packages are
+// installed.
+const (
+	XcodeCLTStepID = "bootstrap:xcode-clt"
+	RosettaStepID  = "bootstrap:rosetta"
+)
+
 // ResolveToolDeps returns dependency step IDs for the given tool based on config
 // and platform preference. If no matching installer is configured, returns nil.
 func ResolveToolDeps(ctx compiler.CompileContext, plat *platform.Platform, tool Tool) []compiler.StepID {
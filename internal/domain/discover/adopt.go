@@ -0,0 +1,169 @@
+package discover
+
+import (
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+// Adoption is a tool config found in a dotfiles directory, and where the
+// tool reads it in the home directory.
+type Adoption struct {
+	Tool   string      // Name of the tool, used as the layer name
+	Type   PatternType // Category of the tool
+	Source string      // Path of the config in the scanned directory
+	Dest   string      // Path the tool reads, starting with ~/
+	Dir    bool        // Source is a directory
+}
+
+// adoptionCandidate is a place a tool config may sit in a dotfiles
+// directory. Sources ending with "/" are directories.
+type adoptionCandidate struct {
+	source string
+	dest   string
+}
+
+// adoptionRule lists the places a tool config may sit, most specific
+// first: a stow package, a mirror of the home directory, then a directory
+// named after the tool as in ~/.config.
+type adoptionRule struct {
+	tool       string
+	kind       PatternType
+	candidates []adoptionCandidate
+}
+
+// defaultAdoptionRules returns the tools whose configs can be adopted.
+func defaultAdoptionRules() []adoptionRule {
+	return []adoptionRule{
+		{
+			tool: "tmux",
+			kind: PatternTypeTmux,
+			candidates: []adoptionCandidate{
+				{"tmux/.tmux.conf", "~/.tmux.conf"},
+				{"tmux/.config/tmux/", "~/.config/tmux"},
+				{".tmux.conf", "~/.tmux.conf"},
+				{"tmux.conf", "~/.tmux.conf"},
+				{".config/tmux/", "~/.config/tmux"},
+				{"tmux/", "~/.config/tmux"},
+			},
+		},
+		{
+			tool: "starship",
+			kind: PatternTypeShell,
+			candidates: []adoptionCandidate{
+				{"starship/.config/starship.toml", "~/.config/starship.toml"},
+				{".config/starship.toml", "~/.config/starship.toml"},
+				{"starship/starship.toml", "~/.config/starship.toml"},
+				{"starship.toml", "~/.config/starship.toml"},
+			},
+		},
+		{
+			tool: "alacritty",
+			kind: PatternTypeShell,
+			candidates: []adoptionCandidate{
+				{"alacritty/.config/alacritty/", "~/.config/alacritty"},
+				{".config/alacritty/", "~/.config/alacritty"},
+				{".alacritty.toml", "~/.alacritty.toml"},
+				{".alacritty.yml", "~/.alacritty.yml"},
+				{"alacritty/", "~/.config/alacritty"},
+			},
+		},
+		{
+			tool: "kitty",
+			kind: PatternTypeShell,
+			candidates: []adoptionCandidate{
+				{"kitty/.config/kitty/", "~/.config/kitty"},
+				{".config/kitty/", "~/.config/kitty"},
+				{"kitty/", "~/.config/kitty"},
+			},
+		},
+		{
+			tool: "wezterm",
+			kind: PatternTypeShell,
+			candidates: []adoptionCandidate{
+				{"wezterm/.wezterm.lua", "~/.wezterm.lua"},
+				{"wezterm/.config/wezterm/", "~/.config/wezterm"},
+				{".wezterm.lua", "~/.wezterm.lua"},
+				{".config/wezterm/", "~/.config/wezterm"},
+				{"wezterm/", "~/.config/wezterm"},
+			},
+		},
+		{
+			tool: "nvim",
+			kind: PatternTypeEditor,
+			candidates: []adoptionCandidate{
+				{"nvim/.config/nvim/", "~/.config/nvim"},
+				{".config/nvim/", "~/.config/nvim"},
+				{"nvim/", "~/.config/nvim"},
+			},
+		},
+		{
+			tool: "helix",
+			kind: PatternTypeEditor,
+			candidates: []adoptionCandidate{
+				{"helix/.config/helix/", "~/.config/helix"},
+				{".config/helix/", "~/.config/helix"},
+				{"helix/", "~/.config/helix"},
+			},
+		},
+	}
+}
+
+// ListFiles returns the slash-separated paths of the regular files in
+// fsys, leaving out version control directories.
+func ListFiles(fsys fs.FS) ([]string, error) {
+	var files []string
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			switch d.Name() {
+			case ".git", ".hg", ".svn":
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.Type().IsRegular() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// PlanAdoptions returns the tool configs found in files, the paths of a
+// dotfiles directory, at most one per tool.
+func PlanAdoptions(files []string) []Adoption {
+	var adoptions []Adoption
+	for _, rule := range defaultAdoptionRules() {
+		for _, candidate := range rule.candidates {
+			if !matchesAny(files, candidate.source) {
+				continue
+			}
+			adoptions = append(adoptions, Adoption{
+				Tool:   rule.tool,
+				Type:   rule.kind,
+				Source: strings.TrimSuffix(candidate.source, "/"),
+				Dest:   candidate.dest,
+				Dir:    strings.HasSuffix(candidate.source, "/"),
+			})
+			break
+		}
+	}
+	return adoptions
+}
+
+// matchesAny reports whether any of files matches pattern.
+func matchesAny(files []string, pattern string) bool {
+	for _, file := range files {
+		if matchesPattern(file, pattern) {
+			return true
+		}
+	}
+	return false
+}
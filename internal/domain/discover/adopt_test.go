@@ -0,0 +1,71 @@
+package discover
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListFiles(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"nvim/init.lua":      {Data: []byte("-- nvim")},
+		"starship.toml":      {Data: []byte("")},
+		".git/config":        {Data: []byte("[core]")},
+		"tmux/plugins/.keep": {Data: []byte("")},
+	}
+	files, err := ListFiles(fsys)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"nvim/init.lua", "starship.toml", "tmux/plugins/.keep"}, files)
+}
+
+func TestPlanAdoptions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		files []string
+		want  []Adoption
+	}{
+		{
+			name:  "home mirror",
+			files: []string{".tmux.conf", ".config/nvim/init.lua", ".config/nvim/lua/plugins.lua", ".zshrc"},
+			want: []Adoption{
+				{Tool: "tmux", Type: PatternTypeTmux, Source: ".tmux.conf", Dest: "~/.tmux.conf"},
+				{Tool: "nvim", Type: PatternTypeEditor, Source: ".config/nvim", Dest: "~/.config/nvim", Dir: true},
+			},
+		},
+		{
+			name:  "stow packages",
+			files: []string{"tmux/.tmux.conf", "starship/.config/starship.toml", "nvim/.config/nvim/init.lua"},
+			want: []Adoption{
+				{Tool: "tmux", Type: PatternTypeTmux, Source: "tmux/.tmux.conf", Dest: "~/.tmux.conf"},
+				{Tool: "starship", Type: PatternTypeShell, Source: "starship/.config/starship.toml", Dest: "~/.config/starship.toml"},
+				{Tool: "nvim", Type: PatternTypeEditor, Source: "nvim/.config/nvim", Dest: "~/.config/nvim", Dir: true},
+			},
+		},
+		{
+			name:  "config directory",
+			files: []string{"alacritty/alacritty.toml", "starship.toml", "tmux/tmux.conf", "nvim/init.lua", "gh/hosts.yml"},
+			want: []Adoption{
+				{Tool: "tmux", Type: PatternTypeTmux, Source: "tmux", Dest: "~/.config/tmux", Dir: true},
+				{Tool: "starship", Type: PatternTypeShell, Source: "starship.toml", Dest: "~/.config/starship.toml"},
+				{Tool: "alacritty", Type: PatternTypeShell, Source: "alacritty", Dest: "~/.config/alacritty", Dir: true},
+				{Tool: "nvim", Type: PatternTypeEditor, Source: "nvim", Dest: "~/.config/nvim", Dir: true},
+			},
+		},
+		{
+			name:  "nothing recognizable",
+			files: []string{"README.md", "install.sh"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, PlanAdoptions(tt.files))
+		})
+	}
+}
@@ -3,6 +3,7 @@ package drift
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"sync"
@@ -11,17 +12,29 @@ import (
 
 // StateStore handles persistence of AppliedState.
 type StateStore struct {
-	path string
-	mu   sync.RWMutex
+	path      string
+	mu        sync.RWMutex
+	readFile  func(path string) ([]byte, error)
+	writeFile func(path string, data []byte, perm os.FileMode) error
 }
 
 // NewStateStore creates a new StateStore.
 func NewStateStore(path string) *StateStore {
 	return &StateStore{
-		path: path,
+		path:      path,
+		readFile:  os.ReadFile,
+		writeFile: os.WriteFile,
 	}
 }
 
+// WithFileIO sets the functions the state file is read and written with,
+// e.g. to encrypt it at rest.
+func (s *StateStore) WithFileIO(readFile func(path string) ([]byte, error), writeFile func(path string, data []byte, perm os.FileMode) error) *StateStore {
+	s.readFile = readFile
+	s.writeFile = writeFile
+	return s
+}
+
 // Load reads the applied state from disk.
 func (s *StateStore) Load(ctx context.Context) (*AppliedState, error) {
 	_ = ctx // Reserved for future cancellation support
@@ -29,8 +42,8 @@ func (s *StateStore) Load(ctx context.Context) (*AppliedState, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	data, err := os.ReadFile(s.path)
-	if os.IsNotExist(err) {
+	data, err := s.readFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
 		return NewAppliedState(), nil
 	}
 	if err != nil {
@@ -67,7 +80,7 @@ func (s *StateStore) Save(ctx context.Context, state *AppliedState) error {
 		return err
 	}
 
-	return os.WriteFile(s.path, data, 0o600)
+	return s.writeFile(s.path, data, 0o600)
 }
 
 // UpdateFile updates a single file in the state.
@@ -133,8 +146,8 @@ func (s *StateStore) Clear(ctx context.Context) error {
 
 // loadUnsafe loads state without locking (caller must hold lock).
 func (s *StateStore) loadUnsafe() (*AppliedState, error) {
-	data, err := os.ReadFile(s.path)
-	if os.IsNotExist(err) {
+	data, err := s.readFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
 		return NewAppliedState(), nil
 	}
 	if err != nil {
@@ -166,5 +179,5 @@ func (s *StateStore) saveUnsafe(state *AppliedState) error {
 		return err
 	}
 
-	return os.WriteFile(s.path, data, 0o600)
+	return s.writeFile(s.path, data, 0o600)
 }
@@ -13,6 +13,41 @@ import (
 type Executor struct {
 	dryRun            bool
 	rollbackOnFailure bool
+	observer          StepObserver
+	startObserver     StepStartObserver
+}
+
+// StepObserver is notified after each step of a plan has run.
+type StepObserver func(StepResult)
+
+// StepStartObserver is notified before each step of a plan runs.
+type StepStartObserver func(compiler.StepID)
+
+// stepIDKey is the context key under which Apply receives its step ID.
+type stepIDKey struct{}
+
+// batchStepIDsKey is the context key under which ApplyBatch receives the
+// IDs of the steps it applies.
+type batchStepIDsKey struct{}
+
+// StepIDFromContext returns the ID of the step whose Apply runs with ctx,
+// so that command runners can attribute output to steps. For ApplyBatch it
+// is the first step of the batch.
+func StepIDFromContext(ctx context.Context) (compiler.StepID, bool) {
+	id, ok := ctx.Value(stepIDKey{}).(compiler.StepID)
+	return id, ok
+}
+
+// StepIDsFromContext returns the IDs of the steps whose Apply or
+// ApplyBatch runs with ctx.
+func StepIDsFromContext(ctx context.Context) []compiler.StepID {
+	if ids, ok := ctx.Value(batchStepIDsKey{}).([]compiler.StepID); ok {
+		return ids
+	}
+	if id, ok := StepIDFromContext(ctx); ok {
+		return []compiler.StepID{id}
+	}
+	return nil
 }
 
 // NewExecutor creates a new Executor.
@@ -22,19 +57,33 @@ func NewExecutor() *Executor {
 
 // WithDryRun returns an Executor that simulates execution without applying.
 func (e *Executor) WithDryRun(dryRun bool) *Executor {
-	return &Executor{
-		dryRun:            dryRun,
-		rollbackOnFailure: e.rollbackOnFailure,
-	}
+	c := *e
+	c.dryRun = dryRun
+	return &c
 }
 
 // WithRollbackOnFailure returns an Executor that rolls back applied steps on failure.
 // Only steps that implement RollbackableStep will be rolled back.
 func (e *Executor) WithRollbackOnFailure(rollback bool) *Executor {
-	return &Executor{
-		dryRun:            e.dryRun,
-		rollbackOnFailure: rollback,
-	}
+	c := *e
+	c.rollbackOnFailure = rollback
+	return &c
+}
+
+// WithStepObserver returns an Executor that reports each step result to
+// observer as soon as the step finishes, so callers can stream progress.
+func (e *Executor) WithStepObserver(observer StepObserver) *Executor {
+	c := *e
+	c.observer = observer
+	return &c
+}
+
+// WithStepStartObserver returns an Executor that reports each step to
+// observer before running it, so callers can show what is in progress.
+func (e *Executor) WithStepStartObserver(observer StepStartObserver) *Executor {
+	c := *e
+	c.startObserver = observer
+	return &c
 }
 
 // ExecuteResult contains the results of an execution, including any rollback information.
@@ -85,16 +134,13 @@ func (e *Executor) ExecuteWithRollback(ctx context.Context, plan *Plan) ExecuteR
 
 	var failedResult *StepResult
 
-	for _, entry := range plan.Entries() {
-		// Check for context cancellation
-		select {
-		case <-ctx.Done():
-			return ExecuteResult{Results: results}
-		default:
-		}
-
-		result := e.executeEntry(entry, runCtx, failed)
+	// record tracks a step result and reports whether execution stops.
+	record := func(entry PlanEntry, result StepResult) bool {
 		results = append(results, result)
+		logStepResult(ctx, result)
+		if e.observer != nil {
+			e.observer(result)
+		}
 
 		// Track failures for dependency checking
 		if result.Status() == compiler.StatusFailed {
@@ -102,14 +148,41 @@ func (e *Executor) ExecuteWithRollback(ctx context.Context, plan *Plan) ExecuteR
 			failedResult = &result
 
 			// If rollback is enabled, stop executing and rollback
-			if e.rollbackOnFailure {
-				break
-			}
-		} else if result.Applied() {
+			return e.rollbackOnFailure
+		}
+		if result.Applied() {
 			// Track only steps that actually mutated the system in this run.
 			// Already-satisfied (no-op) steps must not be rolled back.
 			appliedSteps = append(appliedSteps, entry.Step())
 		}
+		return false
+	}
+
+	entries := plan.Entries()
+	for i := 0; i < len(entries); {
+		// Check for context cancellation
+		select {
+		case <-ctx.Done():
+			return ExecuteResult{Results: results}
+		default:
+		}
+
+		if batch := batchAt(entries, i, runCtx, failed); len(pendingEntries(batch)) > 1 {
+			i += len(batch)
+			if stop := e.executeBatch(batch, runCtx, record); stop {
+				break
+			}
+			continue
+		}
+
+		entry := entries[i]
+		i++
+		if e.startObserver != nil {
+			e.startObserver(entry.Step().ID())
+		}
+		if stop := record(entry, e.executeEntry(entry, runCtx, failed)); stop {
+			break
+		}
 	}
 
 	// Perform rollback if enabled and we had a failure
@@ -195,8 +268,9 @@ func (e *Executor) executeEntry(entry PlanEntry, ctx compiler.RunContext, failed
 	// so cancellation is honored even by steps that don't internally observe
 	// the context. The step's goroutine continues until Apply returns; the
 	// executor returns control to the caller immediately on cancel.
+	stepCtx := compiler.NewRunContext(context.WithValue(ctx.Context(), stepIDKey{}, stepID)).WithDryRun(ctx.DryRun())
 	start := time.Now()
-	err := applyWithCancellation(ctx, step)
+	err := applyWithCancellation(stepCtx, step)
 	duration := time.Since(start)
 
 	if err != nil {
@@ -209,6 +283,127 @@ func (e *Executor) executeEntry(entry PlanEntry, ctx compiler.RunContext, failed
 		WithApplied(true)
 }
 
+// batchAt returns the contiguous entries from i on whose steps share the
+// batch key of the step at i and do not depend on a failed step or on a
+// step of the batch. Satisfied entries are part of the batch so they do
+// not split it, but are not applied.
+func batchAt(entries []PlanEntry, i int, ctx compiler.RunContext, failed map[string]bool) []PlanEntry {
+	if ctx.DryRun() {
+		return nil
+	}
+	first, ok := entries[i].Step().(compiler.BatchableStep)
+	if !ok || first.BatchKey() == "" {
+		return nil
+	}
+
+	batched := make(map[string]bool)
+	var batch []PlanEntry
+	for _, entry := range entries[i:] {
+		step, ok := entry.Step().(compiler.BatchableStep)
+		if !ok || step.BatchKey() != first.BatchKey() {
+			break
+		}
+		for _, depID := range step.DependsOn() {
+			if failed[depID.String()] || batched[depID.String()] {
+				return batch
+			}
+		}
+		batched[step.ID().String()] = true
+		batch = append(batch, entry)
+	}
+	return batch
+}
+
+// pendingEntries returns the entries of batch that need applying.
+func pendingEntries(batch []PlanEntry) []PlanEntry {
+	var pending []PlanEntry
+	for _, entry := range batch {
+		if entry.Status() != compiler.StatusSatisfied {
+			pending = append(pending, entry)
+		}
+	}
+	return pending
+}
+
+// executeBatch applies the pending entries of batch with one ApplyBatch
+// call and records a result per entry, in plan order. The applied steps
+// share the duration of the call evenly. It reports whether execution
+// stops.
+func (e *Executor) executeBatch(batch []PlanEntry, ctx compiler.RunContext, record func(PlanEntry, StepResult) bool) bool {
+	pending := pendingEntries(batch)
+	steps := make([]compiler.Step, len(pending))
+	ids := make([]compiler.StepID, len(pending))
+	for i, entry := range pending {
+		steps[i] = entry.Step()
+		ids[i] = entry.Step().ID()
+	}
+	if e.startObserver != nil {
+		for _, entry := range batch {
+			e.startObserver(entry.Step().ID())
+		}
+	}
+
+	runCtx := context.WithValue(ctx.Context(), stepIDKey{}, ids[0])
+	runCtx = context.WithValue(runCtx, batchStepIDsKey{}, ids)
+	batchCtx := compiler.NewRunContext(runCtx).WithDryRun(ctx.DryRun())
+	start := time.Now()
+	errs := applyBatchWithCancellation(batchCtx, steps[0].(compiler.BatchableStep), steps)
+	duration := time.Since(start) / time.Duration(len(steps))
+
+	stop := false
+	next := 0
+	for _, entry := range batch {
+		stepID := entry.Step().ID()
+		var result StepResult
+		switch {
+		case entry.Status() == compiler.StatusSatisfied:
+			result = NewStepResult(stepID, compiler.StatusSatisfied, nil)
+		case errs[next] != nil:
+			result = NewStepResult(stepID, compiler.StatusFailed, errs[next]).WithDuration(duration)
+			next++
+		default:
+			result = NewStepResult(stepID, compiler.StatusSatisfied, nil).
+				WithDuration(duration).
+				WithDiff(entry.Diff()).
+				WithApplied(true)
+			next++
+		}
+		// The whole batch has run, so results after a failure are still
+		// recorded and applied steps are rolled back too.
+		if record(entry, result) {
+			stop = true
+		}
+	}
+	return stop
+}
+
+// applyBatchWithCancellation runs ApplyBatch like applyWithCancellation
+// runs Apply. On cancellation every step of the batch fails.
+func applyBatchWithCancellation(ctx compiler.RunContext, step compiler.BatchableStep, steps []compiler.Step) []error {
+	done := make(chan []error, 1)
+	go func() {
+		done <- step.ApplyBatch(ctx, steps)
+	}()
+
+	select {
+	case <-ctx.Context().Done():
+		errs := make([]error, len(steps))
+		for i := range errs {
+			errs[i] = ctx.Context().Err()
+		}
+		return errs
+	case errs := <-done:
+		if len(errs) != len(steps) {
+			err := fmt.Errorf("batch of %s returned %d results for %d steps", step.ID(), len(errs), len(steps))
+			errs = make([]error, len(steps))
+			for i := range errs {
+				errs[i] = err
+			}
+		}
+		return errs
+	}
+}
+
 // applyWithCancellation runs step.Apply and races it against the run context's
 // Done channel. If the context is cancelled before Apply returns, the
 // cancellation error is returned even if the step itself doesn't honor ctx.
@@ -55,6 +55,30 @@ func TestExecutor_SingleStep_Apply(t *testing.T) {
 	}
 }
 
+func TestExecutor_ApplyContextCarriesStepID(t *testing.T) {
+	executor := NewExecutor()
+	plan := NewExecutionPlan()
+
+	var got compiler.StepID
+	var ok bool
+	step := newConfigurableStep("brew:install:git")
+	step.applyFn = func(ctx compiler.RunContext) error {
+		got, ok = StepIDFromContext(ctx.Context())
+		return nil
+	}
+	plan.Add(NewPlanEntry(step, compiler.StatusNeedsApply, compiler.Diff{}))
+
+	if _, err := executor.Execute(context.Background(), plan); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !ok || got.String() != "brew:install:git" {
+		t.Errorf("StepIDFromContext() = %q, %v; want brew:install:git, true", got, ok)
+	}
+	if _, ok := StepIDFromContext(context.Background()); ok {
+		t.Error("StepIDFromContext() should report false outside Apply")
+	}
+}
+
 func TestExecutor_SingleStep_Satisfied(t *testing.T) {
 	executor := NewExecutor()
 	plan := NewExecutionPlan()
@@ -331,6 +355,48 @@ func TestExecutor_WithRollbackOnFailure(t *testing.T) {
 	}
 }
 
+func TestExecutor_WithStepObserver(t *testing.T) {
+	plan := NewExecutionPlan()
+	plan.Add(NewPlanEntry(newConfigurableStep("brew:install:git"), compiler.StatusSatisfied, compiler.Diff{}))
+	plan.Add(NewPlanEntry(newConfigurableStep("brew:install:jq"), compiler.StatusNeedsApply, compiler.Diff{}))
+
+	var observed []string
+	executor := NewExecutor().
+		WithStepObserver(func(r StepResult) { observed = append(observed, r.StepID().String()) }).
+		WithDryRun(false).
+		WithRollbackOnFailure(true)
+
+	results, err := executor.Execute(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if len(observed) != len(results) {
+		t.Fatalf("observed %d steps, want %d", len(observed), len(results))
+	}
+	if observed[0] != "brew:install:git" || observed[1] != "brew:install:jq" {
+		t.Errorf("observed = %v, want plan order", observed)
+	}
+}
+
+func TestExecutor_WithStepStartObserver(t *testing.T) {
+	plan := NewExecutionPlan()
+	plan.Add(NewPlanEntry(newConfigurableStep("brew:install:git"), compiler.StatusNeedsApply, compiler.Diff{}))
+	plan.Add(NewPlanEntry(newConfigurableStep("brew:install:jq"), compiler.StatusNeedsApply, compiler.Diff{}))
+
+	var events []string
+	executor := NewExecutor().
+		WithStepStartObserver(func(id compiler.StepID) { events = append(events, "start "+id.String()) }).
+		WithStepObserver(func(r StepResult) { events = append(events, "done "+r.StepID().String()) })
+
+	if _, err := executor.Execute(context.Background(), plan); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	want := []string{"start brew:install:git", "done brew:install:git", "start brew:install:jq", "done brew:install:jq"}
+	if strings.Join(events, ",") != strings.Join(want, ",") {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+}
+
 func TestExecutor_RollbackOnFailure_RollsBackAppliedSteps(t *testing.T) {
 	executor := NewExecutor().WithRollbackOnFailure(true)
 	plan := NewExecutionPlan()
@@ -750,3 +816,124 @@ func TestExecutor_ApplyHonorsContextCancellation(t *testing.T) {
 		t.Fatal("Execute did not return within 2s after ctx was cancelled mid-Apply — applyWithCancellation broken")
 	}
 }
+
+// batchStep is a step that is applied in batches by the first step of each.
+type batchStep struct {
+	*configurableMockStep
+	key     string
+	batches *[][]string
+	fail    map[string]bool
+}
+
+func newBatchStep(id, key string, batches *[][]string, fail map[string]bool, deps ...string) *batchStep {
+	return &batchStep{configurableMockStep: newConfigurableStep(id, deps...), key: key, batches: batches, fail: fail}
+}
+
+func (s *batchStep) BatchKey() string { return s.key }
+
+func (s *batchStep) ApplyBatch(ctx compiler.RunContext, steps []compiler.Step) []error {
+	ids := make([]string, len(steps))
+	errs := make([]error, len(steps))
+	for i, step := range steps {
+		ids[i] = step.ID().String()
+		if s.fail[ids[i]] {
+			errs[i] = errors.New("no formula " + ids[i])
+		}
+	}
+	if got := StepIDsFromContext(ctx.Context()); len(got) != len(steps) {
+		errs[0] = errors.New("batch step IDs missing from context")
+	}
+	*s.batches = append(*s.batches, ids)
+	return errs
+}
+
+func TestExecutor_AppliesContiguousBatchableStepsTogether(t *testing.T) {
+	var batches [][]string
+	var single []string
+	plan := NewExecutionPlan()
+	for _, id := range []string{"brew:formula:bat", "brew:formula:fd", "brew:formula:jq"} {
+		plan.Add(NewPlanEntry(newBatchStep(id, "brew:formula", &batches, nil), compiler.StatusNeedsApply, compiler.Diff{}))
+	}
+	// A satisfied step does not split the batch
+	plan.Add(NewPlanEntry(newBatchStep("brew:formula:rg", "brew:formula", &batches, nil), compiler.StatusSatisfied, compiler.Diff{}))
+	plan.Add(NewPlanEntry(newBatchStep("brew:formula:zoxide", "brew:formula", &batches, nil), compiler.StatusNeedsApply, compiler.Diff{}))
+	other := newConfigurableStep("git:config")
+	other.applyFn = func(_ compiler.RunContext) error {
+		single = append(single, "git:config")
+		return nil
+	}
+	plan.Add(NewPlanEntry(other, compiler.StatusNeedsApply, compiler.Diff{}))
+	// A lone batchable step is applied on its own
+	lone := newBatchStep("brew:cask:firefox", "brew:cask", &batches, nil)
+	lone.applyFn = func(_ compiler.RunContext) error {
+		single = append(single, "brew:cask:firefox")
+		return nil
+	}
+	plan.Add(NewPlanEntry(lone, compiler.StatusNeedsApply, compiler.Diff{}))
+
+	var started []string
+	results, err := NewExecutor().WithStepStartObserver(func(id compiler.StepID) {
+		started = append(started, id.String())
+	}).Execute(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	want := [][]string{{"brew:formula:bat", "brew:formula:fd", "brew:formula:jq", "brew:formula:zoxide"}}
+	if len(batches) != 1 || strings.Join(batches[0], ",") != strings.Join(want[0], ",") {
+		t.Errorf("batches = %v, want %v", batches, want)
+	}
+	if strings.Join(single, ",") != "git:config,brew:cask:firefox" {
+		t.Errorf("single applies = %v", single)
+	}
+	if len(results) != 7 || len(started) != 7 {
+		t.Fatalf("results = %d, started = %d; want 7 each", len(results), len(started))
+	}
+	for i, r := range results {
+		if r.StepID().String() != plan.Entries()[i].Step().ID().String() {
+			t.Errorf("results[%d] = %s, want plan order", i, r.StepID())
+		}
+		if wantApplied := i != 3; r.Applied() != wantApplied {
+			t.Errorf("results[%d].Applied() = %v, want %v", i, r.Applied(), wantApplied)
+		}
+	}
+}
+
+func TestExecutor_BatchReportsPerStepFailures(t *testing.T) {
+	var batches [][]string
+	fail := map[string]bool{"brew:formula:nope": true}
+	plan := NewExecutionPlan()
+	for _, id := range []string{"brew:formula:bat", "brew:formula:nope", "brew:formula:jq"} {
+		plan.Add(NewPlanEntry(newBatchStep(id, "brew:formula", &batches, fail), compiler.StatusNeedsApply, compiler.Diff{}))
+	}
+	// Depends on a failed step: skipped rather than batched
+	plan.Add(NewPlanEntry(newBatchStep("brew:formula:after", "brew:formula", &batches, fail, "brew:formula:nope"), compiler.StatusNeedsApply, compiler.Diff{}))
+
+	results, err := NewExecutor().Execute(context.Background(), plan)
+	if err == nil || !strings.Contains(err.Error(), "no formula brew:formula:nope") {
+		t.Fatalf("Execute() error = %v, want the failed formula", err)
+	}
+	if len(batches) != 1 || len(batches[0]) != 3 {
+		t.Errorf("batches = %v, want one batch of 3", batches)
+	}
+	wantStatus := []compiler.StepStatus{compiler.StatusSatisfied, compiler.StatusFailed, compiler.StatusSatisfied, compiler.StatusSkipped}
+	for i, r := range results {
+		if r.Status() != wantStatus[i] {
+			t.Errorf("results[%d].Status() = %v, want %v", i, r.Status(), wantStatus[i])
+		}
+	}
+}
+
+func TestExecutor_DryRunDoesNotBatch(t *testing.T) {
+	var batches [][]string
+	plan := NewExecutionPlan()
+	for _, id := range []string{"brew:formula:bat", "brew:formula:fd"} {
+		plan.Add(NewPlanEntry(newBatchStep(id, "brew:formula", &batches, nil), compiler.StatusNeedsApply, compiler.Diff{}))
+	}
+	if _, err := NewExecutor().WithDryRun(true).Execute(context.Background(), plan); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if len(batches) != 0 {
+		t.Errorf("batches = %v, want none in dry run", batches)
+	}
+}
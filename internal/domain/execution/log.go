@@ -0,0 +1,41 @@
+package execution
+
+import (
+	"context"
+
+	"github.com/felixgeelhaar/preflight/internal/ports"
+)
+
+// logStepResult logs the outcome of a step to the logger attached to ctx.
+func logStepResult(ctx context.Context, result StepResult) {
+	log := ports.LoggerFromContext(ctx)
+	if log == nil {
+		return
+	}
+	fields := []ports.Field{
+		ports.F("step", result.StepID().String()),
+		ports.F("status", result.Status().String()),
+		ports.F("duration", result.Duration()),
+	}
+	if err := result.Error(); err != nil {
+		log.Warn(ctx, "step failed", append(fields, ports.F("error", err))...)
+		return
+	}
+	log.Debug(ctx, "step executed", fields...)
+}
+
+// logStepChecked logs the planned status of a step to the logger attached
+// to ctx.
+func logStepChecked(ctx context.Context, entry PlanEntry) {
+	log := ports.LoggerFromContext(ctx)
+	if log == nil {
+		return
+	}
+	if entry.TimedOut() {
+		log.Warn(ctx, "step check timed out", ports.F("step", entry.Step().ID().String()))
+		return
+	}
+	log.Debug(ctx, "step checked",
+		ports.F("step", entry.Step().ID().String()),
+		ports.F("status", entry.Status().String()))
+}
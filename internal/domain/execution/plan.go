@@ -6,9 +6,10 @@ import (
 
 // PlanEntry represents a single step's planned execution.
 type PlanEntry struct {
-	step   compiler.Step
-	status compiler.StepStatus
-	diff   compiler.Diff
+	step     compiler.Step
+	status   compiler.StepStatus
+	diff     compiler.Diff
+	timedOut bool
 }
 
 // NewPlanEntry creates a new PlanEntry.
@@ -20,6 +21,12 @@ func NewPlanEntry(step compiler.Step, status compiler.StepStatus, diff compiler.
 	}
 }
 
+// newTimedOutEntry creates a PlanEntry for a step whose check did not finish
+// within its provider's timeout.
+func newTimedOutEntry(step compiler.Step) PlanEntry {
+	return PlanEntry{step: step, status: compiler.StatusUnknown, timedOut: true}
+}
+
 // Step returns the step to be executed.
 func (e PlanEntry) Step() compiler.Step {
 	return e.step
@@ -35,6 +42,12 @@ func (e PlanEntry) Diff() compiler.Diff {
 	return e.diff
 }
 
+// TimedOut returns true if the step's check was abandoned because its
+// provider exceeded the planner's timeout. Its status is then unknown.
+func (e PlanEntry) TimedOut() bool {
+	return e.timedOut
+}
+
 // PlanSummary provides aggregate statistics about the execution plan.
 type PlanSummary struct {
 	Total      int
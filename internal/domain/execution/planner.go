@@ -3,30 +3,56 @@ package execution
 import (
 	"context"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
 )
 
 // Planner generates an ExecutionPlan from a StepGraph.
 // It checks each step's current status and plans necessary changes.
-type Planner struct{}
+type Planner struct {
+	parallelism     int
+	providerTimeout time.Duration
+}
 
 // NewPlanner creates a new Planner.
 func NewPlanner() *Planner {
 	return &Planner{}
 }
 
+// WithParallelism returns a Planner that checks the steps of up to n
+// providers at once. Steps of the same provider are always checked one
+// after another.
+func (p *Planner) WithParallelism(n int) *Planner {
+	c := *p
+	c.parallelism = n
+	return &c
+}
+
+// WithProviderTimeout returns a Planner that stops waiting for a provider's
+// checks after timeout. Steps left unchecked are planned with an unknown
+// status and reported as timed out. Zero means no timeout.
+func (p *Planner) WithProviderTimeout(timeout time.Duration) *Planner {
+	c := *p
+	c.providerTimeout = timeout
+	return &c
+}
+
 // Plan generates a Plan by checking each step's status.
 // Steps are returned in topological order for correct execution.
 func (p *Planner) Plan(ctx context.Context, graph *compiler.StepGraph) (*Plan, error) {
-	plan := NewExecutionPlan()
-
 	// Get steps in topological order
 	steps, err := graph.TopologicalSort()
 	if err != nil {
 		return nil, fmt.Errorf("failed to sort steps: %w", err)
 	}
 
+	if p.parallelism > 1 || p.providerTimeout > 0 {
+		return p.planByProvider(ctx, steps)
+	}
+
+	plan := NewExecutionPlan()
 	runCtx := compiler.NewRunContext(ctx)
 
 	for _, step := range steps {
@@ -35,11 +61,104 @@ func (p *Planner) Plan(ctx context.Context, graph *compiler.StepGraph) (*Plan, e
 			return nil, fmt.Errorf("failed to plan step %q: %w", step.ID().String(), err)
 		}
 		plan.Add(entry)
+		logStepChecked(ctx, entry)
 	}
 
 	return plan, nil
 }
 
+// planByProvider checks the steps of each provider in their own worker,
+// running at most parallelism workers at once, and assembles the entries
+// in the original step order.
+func (p *Planner) planByProvider(ctx context.Context, steps []compiler.Step) (*Plan, error) {
+	var providers []string
+	byProvider := make(map[string][]int)
+	for i, step := range steps {
+		provider := step.ID().Provider()
+		if _, ok := byProvider[provider]; !ok {
+			providers = append(providers, provider)
+		}
+		byProvider[provider] = append(byProvider[provider], i)
+	}
+
+	parallelism := p.parallelism
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	entries := make([]PlanEntry, len(steps))
+	errs := make([]error, len(steps))
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for _, provider := range providers {
+		wg.Add(1)
+		go func(indexes []int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			p.planProvider(ctx, steps, indexes, entries, errs)
+		}(byProvider[provider])
+	}
+	wg.Wait()
+
+	plan := NewExecutionPlan()
+	for i, step := range steps {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("failed to plan step %q: %w", step.ID().String(), errs[i])
+		}
+		plan.Add(entries[i])
+		logStepChecked(ctx, entries[i])
+	}
+	return plan, nil
+}
+
+// planProvider checks the steps at indexes one after another until they are
+// done or the provider timeout expires. A check that ignores cancellation is
+// abandoned rather than waited for.
+func (p *Planner) planProvider(ctx context.Context, steps []compiler.Step, indexes []int, entries []PlanEntry, errs []error) {
+	if p.providerTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.providerTimeout)
+		defer cancel()
+	}
+	runCtx := compiler.NewRunContext(ctx)
+
+	type result struct {
+		entry PlanEntry
+		err   error
+	}
+	for n, i := range indexes {
+		done := make(chan result, 1)
+		go func(step compiler.Step) {
+			entry, err := p.planStep(step, runCtx)
+			done <- result{entry, err}
+		}(steps[i])
+
+		select {
+		case r := <-done:
+			if r.err != nil && ctx.Err() == context.DeadlineExceeded {
+				p.timeOut(steps, indexes[n:], entries)
+				return
+			}
+			entries[i], errs[i] = r.entry, r.err
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				p.timeOut(steps, indexes[n:], entries)
+				return
+			}
+			errs[i] = ctx.Err()
+			return
+		}
+	}
+}
+
+// timeOut plans the steps at indexes as timed out.
+func (p *Planner) timeOut(steps []compiler.Step, indexes []int, entries []PlanEntry) {
+	for _, i := range indexes {
+		entries[i] = newTimedOutEntry(steps[i])
+	}
+}
+
 // planStep checks a single step and generates a PlanEntry.
 func (p *Planner) planStep(step compiler.Step, ctx compiler.RunContext) (PlanEntry, error) {
 	// Check current status
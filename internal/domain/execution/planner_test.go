@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
 )
@@ -221,3 +222,75 @@ func TestPlanner_PlanError(t *testing.T) {
 		t.Error("Plan() should return error when step plan fails")
 	}
 }
+
+func TestPlanner_ByProvider_PreservesOrder(t *testing.T) {
+	graph := compiler.NewStepGraph()
+	ids := []string{"brew:formula:git", "vscode:extension:go", "brew:formula:fd", "git:config:user"}
+	for _, id := range ids {
+		_ = graph.Add(newConfigurableStep(id))
+	}
+
+	plan, err := NewPlanner().WithParallelism(4).Plan(context.Background(), graph)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+
+	sorted, _ := graph.TopologicalSort()
+	if plan.Len() != len(sorted) {
+		t.Fatalf("Plan should have %d entries, got %d", len(sorted), plan.Len())
+	}
+	for i, entry := range plan.Entries() {
+		if entry.Step().ID() != sorted[i].ID() {
+			t.Errorf("entry %d = %s, want %s", i, entry.Step().ID(), sorted[i].ID())
+		}
+		if entry.Status() != compiler.StatusNeedsApply {
+			t.Errorf("entry %s status = %v, want NeedsApply", entry.Step().ID(), entry.Status())
+		}
+	}
+}
+
+func TestPlanner_ProviderTimeout(t *testing.T) {
+	graph := compiler.NewStepGraph()
+	release := make(chan struct{})
+	defer close(release)
+
+	slow := newConfigurableStep("vscode:extension:go")
+	slow.checkFn = func(_ compiler.RunContext) (compiler.StepStatus, error) {
+		// Ignores cancellation, like a CLI that hangs.
+		<-release
+		return compiler.StatusSatisfied, nil
+	}
+	_ = graph.Add(slow)
+	_ = graph.Add(newConfigurableStep("vscode:extension:rust"))
+	_ = graph.Add(newConfigurableStep("brew:formula:git"))
+
+	plan, err := NewPlanner().WithParallelism(2).WithProviderTimeout(50*time.Millisecond).Plan(context.Background(), graph)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+
+	for _, entry := range plan.Entries() {
+		timedOut := entry.Step().ID().Provider() == "vscode"
+		if entry.TimedOut() != timedOut {
+			t.Errorf("%s TimedOut() = %v, want %v", entry.Step().ID(), entry.TimedOut(), timedOut)
+		}
+		if timedOut && entry.Status() != compiler.StatusUnknown {
+			t.Errorf("%s status = %v, want Unknown", entry.Step().ID(), entry.Status())
+		}
+	}
+}
+
+func TestPlanner_ByProvider_CheckError(t *testing.T) {
+	graph := compiler.NewStepGraph()
+	step := newConfigurableStep("brew:formula:git")
+	step.checkFn = func(_ compiler.RunContext) (compiler.StepStatus, error) {
+		return compiler.StatusUnknown, errors.New("brew not found")
+	}
+	_ = graph.Add(step)
+	_ = graph.Add(newConfigurableStep("git:config:user"))
+
+	_, err := NewPlanner().WithParallelism(2).Plan(context.Background(), graph)
+	if err == nil {
+		t.Fatal("Plan() should return the check error")
+	}
+}
@@ -94,8 +94,12 @@ func (l *Lockfile) WithSyncMetadata(meta sync.SyncMetadata) *Lockfile {
 
 // RecordChange records a change made by the given machine.
 // This increments the version vector and updates the machine's lineage.
+// A v1 lockfile is upgraded to v2 so the sync metadata survives a reload.
 // This method mutates the lockfile in place.
 func (l *Lockfile) RecordChange(machineID sync.MachineID, hostname string) {
+	if l.version < LockfileVersionV2 {
+		l.version = LockfileVersionV2
+	}
 	l.syncMetadata = l.syncMetadata.RecordActivity(machineID, hostname)
 }
 
@@ -197,6 +201,8 @@ func (l *Lockfile) PackagesByProvider(provider string) []PackageLock {
 // The packages are copied, not shared.
 func (l *Lockfile) WithMode(mode config.ReproducibilityMode) *Lockfile {
 	newLock := NewLockfile(mode, l.machineInfo)
+	newLock.version = l.version
+	newLock.syncMetadata = l.syncMetadata
 	for k, v := range l.packages {
 		newLock.packages[k] = v
 	}
@@ -207,6 +213,8 @@ func (l *Lockfile) WithMode(mode config.ReproducibilityMode) *Lockfile {
 // The packages are copied, not shared.
 func (l *Lockfile) WithMachineInfo(info MachineInfo) *Lockfile {
 	newLock := NewLockfile(l.mode, info)
+	newLock.version = l.version
+	newLock.syncMetadata = l.syncMetadata
 	for k, v := range l.packages {
 		newLock.packages[k] = v
 	}
@@ -239,6 +239,19 @@ func TestLockfile_WithMode(t *testing.T) {
 	assert.Equal(t, original.PackageCount(), frozen.PackageCount())
 }
 
+func TestLockfile_WithMode_PreservesSyncMetadata(t *testing.T) {
+	t.Parallel()
+
+	machineID := sync.NewMachineID()
+	original := NewLockfileV2(config.ModeIntent, createTestMachineInfo(t))
+	original.RecordChange(machineID, "laptop")
+
+	frozen := original.WithMode(config.ModeFrozen)
+
+	assert.Equal(t, original.Version(), frozen.Version())
+	assert.Equal(t, uint64(1), frozen.SyncMetadata().Vector().GetByMachineID(machineID))
+}
+
 func TestLockfile_WithMachineInfo(t *testing.T) {
 	t.Parallel()
 
@@ -339,6 +352,15 @@ func TestLockfile_RecordChange(t *testing.T) {
 	assert.Equal(t, "test-host", lineage.Hostname())
 }
 
+func TestLockfile_RecordChange_UpgradesV1(t *testing.T) {
+	t.Parallel()
+
+	lockfile := NewLockfile(config.ModeLocked, createTestMachineInfo(t))
+	lockfile.RecordChange(createTestMachineID(t), "test-host")
+
+	assert.Equal(t, LockfileVersionV2, lockfile.Version())
+}
+
 func TestLockfile_V1CompatibleWithV2(t *testing.T) {
 	t.Parallel()
 
@@ -33,6 +33,14 @@ type Repository interface {
 	Exists(ctx context.Context, path string) bool
 }
 
+// SigningRepository is a Repository that can sign the lockfiles it saves.
+type SigningRepository interface {
+	Repository
+
+	// WithSigner returns a repository that signs saved lockfiles with signer.
+	WithSigner(signer *Signer) Repository
+}
+
 // LockfileDTO is a data transfer object for lockfile serialization.
 // It maps between the domain Lockfile and the persisted format.
 type LockfileDTO struct {
@@ -41,6 +49,7 @@ type LockfileDTO struct {
 	MachineInfo MachineInfoDTO        `yaml:"machine_info"`
 	Sync        *SyncMetadataDTO      `yaml:"sync,omitempty"` // V2: Multi-machine sync
 	Packages    map[string]PackageDTO `yaml:"packages,omitempty"`
+	Signature   *SignatureDTO         `yaml:"signature,omitempty"`
 }
 
 // SyncMetadataDTO is the serializable representation of SyncMetadata.
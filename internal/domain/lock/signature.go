@@ -0,0 +1,92 @@
+package lock
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/catalog"
+	"github.com/felixgeelhaar/preflight/internal/domain/signing"
+)
+
+// Signature errors.
+var (
+	// ErrSignatureInvalid is returned when a lockfile was changed after it
+	// was signed, or its signature is malformed.
+	ErrSignatureInvalid = errors.New("lockfile signature is invalid")
+	// ErrUnsigned is returned when a signed lockfile is required.
+	ErrUnsigned = errors.New("lockfile is not signed")
+	// ErrUntrustedSigner is returned when a lockfile is signed by a key
+	// outside the trust store.
+	ErrUntrustedSigner = errors.New("lockfile is signed by an untrusted key")
+)
+
+// SignatureDTO is the signature block of a signed lockfile. It signs the
+// lockfile as serialized without the block.
+type SignatureDTO struct {
+	KeyID string `yaml:"key_id"`
+	// PublicKey is the signer's OpenSSH public key, as in its .pub file.
+	PublicKey string `yaml:"public_key"`
+	// Value is the base64 ed25519 signature.
+	Value string `yaml:"value"`
+}
+
+// Provenance identifies the key that signed a lockfile.
+type Provenance struct {
+	KeyID string
+	// Fingerprint is the trust store fingerprint of the public key.
+	Fingerprint string
+}
+
+// Signer signs lockfiles with an ed25519 key from the trust store.
+type Signer struct {
+	key       ed25519.PrivateKey
+	keyID     string
+	publicKey []byte
+}
+
+// NewSigner creates a signer for key. keyID is the key's ID in the trust
+// store and publicKey its OpenSSH public key.
+func NewSigner(key ed25519.PrivateKey, keyID string, publicKey []byte) (*Signer, error) {
+	pub, err := signing.ParseED25519PublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	if !pub.Equal(key.Public()) {
+		return nil, fmt.Errorf("public key of %s does not belong to the signing key", keyID)
+	}
+	return &Signer{key: key, keyID: keyID, publicKey: publicKey}, nil
+}
+
+// KeyID returns the trust store ID of the signing key.
+func (s *Signer) KeyID() string {
+	return s.keyID
+}
+
+// Sign signs content, the lockfile serialized without a signature.
+func (s *Signer) Sign(content []byte) *SignatureDTO {
+	return &SignatureDTO{
+		KeyID:     s.keyID,
+		PublicKey: string(s.publicKey),
+		Value:     base64.StdEncoding.EncodeToString(ed25519.Sign(s.key, content)),
+	}
+}
+
+// VerifySignature checks that sig signs content with the public key it
+// carries and returns the signer. Whether the signer is trusted is up to
+// the caller.
+func VerifySignature(content []byte, sig *SignatureDTO) (Provenance, error) {
+	pub, err := signing.ParseED25519PublicKey([]byte(sig.PublicKey))
+	if err != nil {
+		return Provenance{}, fmt.Errorf("%w: %w", ErrSignatureInvalid, err)
+	}
+	value, err := base64.StdEncoding.DecodeString(sig.Value)
+	if err != nil || !ed25519.Verify(pub, content, value) {
+		return Provenance{}, fmt.Errorf("%w: it was changed after %s signed it", ErrSignatureInvalid, sig.KeyID)
+	}
+	return Provenance{
+		KeyID:       sig.KeyID,
+		Fingerprint: catalog.ComputeKeyFingerprint([]byte(sig.PublicKey)),
+	}, nil
+}
@@ -0,0 +1,56 @@
+package lock
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/catalog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func newTestSigner(t *testing.T, keyID string) (*Signer, []byte) {
+	t.Helper()
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	sshPub, err := ssh.NewPublicKey(pub)
+	require.NoError(t, err)
+	pubData := ssh.MarshalAuthorizedKey(sshPub)
+	signer, err := NewSigner(key, keyID, pubData)
+	require.NoError(t, err)
+	return signer, pubData
+}
+
+func TestSigner_SignAndVerify(t *testing.T) {
+	t.Parallel()
+
+	signer, pubData := newTestSigner(t, "team")
+	content := []byte("version: 1\nmode: locked\n")
+	sig := signer.Sign(content)
+	assert.Equal(t, "team", sig.KeyID)
+
+	provenance, err := VerifySignature(content, sig)
+	require.NoError(t, err)
+	assert.Equal(t, Provenance{KeyID: "team", Fingerprint: catalog.ComputeKeyFingerprint(pubData)}, provenance)
+
+	_, err = VerifySignature([]byte("version: 1\nmode: intent\n"), sig)
+	require.ErrorIs(t, err, ErrSignatureInvalid)
+	assert.ErrorContains(t, err, "changed after team signed it")
+
+	sig.PublicKey = "not a key"
+	_, err = VerifySignature(content, sig)
+	require.ErrorIs(t, err, ErrSignatureInvalid)
+}
+
+func TestNewSigner_MismatchedPublicKey(t *testing.T) {
+	t.Parallel()
+
+	_, otherPub := newTestSigner(t, "other")
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	_, err = NewSigner(key, "team", otherPub)
+	assert.ErrorContains(t, err, "does not belong to the signing key")
+}
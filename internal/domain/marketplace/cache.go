@@ -7,6 +7,8 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
 )
 
 // Cache errors.
@@ -27,9 +29,9 @@ type CacheConfig struct {
 
 // DefaultCacheConfig returns sensible defaults.
 func DefaultCacheConfig() CacheConfig {
-	homeDir, _ := os.UserHomeDir()
+	basePath, _ := platform.Path(platform.CacheDir, "marketplace", "cache")
 	return CacheConfig{
-		BasePath:   filepath.Join(homeDir, ".preflight", "marketplace", "cache"),
+		BasePath:   basePath,
 		IndexTTL:   1 * time.Hour,
 		PackageTTL: 24 * time.Hour,
 	}
@@ -301,6 +303,76 @@ func (c *Cache) saveInstalled(installed []InstalledPackage) error {
 	return os.WriteFile(c.installedPath(), data, 0o644)
 }
 
+// ratingsPath returns the path to the local ratings file.
+func (c *Cache) ratingsPath() string {
+	return filepath.Join(c.config.BasePath, "ratings.json")
+}
+
+// GetRatings returns the user's local ratings.
+func (c *Cache) GetRatings() ([]Rating, error) {
+	data, err := os.ReadFile(c.ratingsPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Rating{}, nil
+		}
+		return nil, fmt.Errorf("failed to read ratings: %w", err)
+	}
+
+	var ratings []Rating
+	if err := json.Unmarshal(data, &ratings); err != nil {
+		return nil, fmt.Errorf("failed to parse ratings: %w", err)
+	}
+
+	return ratings, nil
+}
+
+// GetRating returns the user's local rating for a package.
+func (c *Cache) GetRating(id PackageID) (Rating, bool, error) {
+	ratings, err := c.GetRatings()
+	if err != nil {
+		return Rating{}, false, err
+	}
+
+	for _, r := range ratings {
+		if r.Package.Equals(id) {
+			return r, true, nil
+		}
+	}
+
+	return Rating{}, false, nil
+}
+
+// PutRating stores or replaces the user's rating for a package.
+func (c *Cache) PutRating(rating Rating) error {
+	ratings, err := c.GetRatings()
+	if err != nil {
+		return err
+	}
+
+	replaced := false
+	for i, r := range ratings {
+		if r.Package.Equals(rating.Package) {
+			ratings[i] = rating
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		ratings = append(ratings, rating)
+	}
+
+	if err := c.EnsureDir(); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(ratings, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.ratingsPath(), data, 0o644)
+}
+
 // Clear removes all cached data.
 func (c *Cache) Clear() error {
 	return os.RemoveAll(c.config.BasePath)
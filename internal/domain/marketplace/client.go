@@ -1,7 +1,10 @@
 package marketplace
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -184,6 +187,101 @@ func (c *Client) fetch(ctx context.Context, url string) ([]byte, error) {
 	return data, nil
 }
 
+// publishRequest is the upload payload accepted by the registry API.
+type publishRequest struct {
+	Package Package `json:"package"`
+	Archive string  `json:"archive"` // base64-encoded tar.gz
+}
+
+// PublishPackage uploads a release to the registry.
+func (c *Client) PublishPackage(ctx context.Context, release *Release) error {
+	version := release.Version()
+	url := fmt.Sprintf("%s/v1/packages/%s/%s", c.config.RegistryURL, release.Package.ID.String(), version.Version)
+
+	body, err := json.Marshal(publishRequest{
+		Package: release.Package,
+		Archive: base64.StdEncoding.EncodeToString(release.Archive),
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrPublishFailed, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: request creation failed", ErrNetworkError)
+	}
+
+	req.Header.Set("User-Agent", c.config.UserAgent)
+	req.Header.Set("Content-Type", "application/json")
+	if c.config.AuthToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.config.AuthToken)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: request failed", ErrNetworkError)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusAccepted:
+		return nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrUnauthorized
+	case http.StatusConflict:
+		return fmt.Errorf("%w: %s@%s", ErrVersionExists, release.Package.ID, version.Version)
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	default:
+		return fmt.Errorf("%w: status %d", ErrPublishFailed, resp.StatusCode)
+	}
+}
+
+// ratingRequest is the rating payload accepted by the registry API.
+type ratingRequest struct {
+	Score int `json:"score"`
+}
+
+// SubmitRating sends a user's 1-5 rating for a package to the registry.
+func (c *Client) SubmitRating(ctx context.Context, id PackageID, score int) error {
+	url := fmt.Sprintf("%s/v1/packages/%s/ratings", c.config.RegistryURL, id.String())
+
+	body, err := json.Marshal(ratingRequest{Score: score})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: request creation failed", ErrNetworkError)
+	}
+
+	req.Header.Set("User-Agent", c.config.UserAgent)
+	req.Header.Set("Content-Type", "application/json")
+	if c.config.AuthToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.config.AuthToken)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: request failed", ErrNetworkError)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusAccepted, http.StatusNoContent:
+		return nil
+	case http.StatusNotFound:
+		return fmt.Errorf("%w: %s", ErrPackageNotFound, id)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrUnauthorized
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	default:
+		return fmt.Errorf("%w: status %d", ErrNetworkError, resp.StatusCode)
+	}
+}
+
 // Ping checks if the registry is reachable.
 func (c *Client) Ping(ctx context.Context) error {
 	url := c.config.RegistryURL + "/v1/health"
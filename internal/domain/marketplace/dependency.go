@@ -0,0 +1,382 @@
+package marketplace
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// Dependency errors.
+var (
+	ErrDependencyConflict    = errors.New("dependency conflict")
+	ErrDependencyCycle       = errors.New("cyclic dependency")
+	ErrIncompatibleVersion   = errors.New("requires a newer preflight version")
+	ErrInvalidDependency     = errors.New("invalid dependency")
+	ErrUnsatisfiedDependency = errors.New("no version satisfies constraint")
+)
+
+// Dependency declares that a package version requires another marketplace package.
+type Dependency struct {
+	// ID is the required package.
+	ID string `json:"id" yaml:"id"`
+	// Version is a constraint such as "1.2.0", ">=1.2.0", "^1.2.0" or "~1.2.0".
+	// Empty means any version.
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+}
+
+// String returns the dependency as id or id@constraint.
+func (d Dependency) String() string {
+	if d.Version == "" {
+		return d.ID
+	}
+	return d.ID + "@" + d.Version
+}
+
+// Validate checks the dependency ID and constraint.
+func (d Dependency) Validate() error {
+	if _, err := NewPackageID(d.ID); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidDependency, err)
+	}
+	if d.Version != "" && !semver.IsValid(canonicalVersion(constraintVersion(d.Version))) {
+		return fmt.Errorf("%w: %s has invalid version constraint %q", ErrInvalidDependency, d.ID, d.Version)
+	}
+	return nil
+}
+
+// SatisfiesConstraint reports whether version satisfies a constraint.
+// Supported operators: =, >=, <=, >, <, ^ and ~ (same minor). As in semver
+// caret ranges, ^ allows changes that keep the first non-zero component:
+// ^1.2.0 accepts 1.x, ^0.2.0 only 0.2.x and ^0.0.3 only 0.0.3.
+func SatisfiesConstraint(version, constraint string) bool {
+	constraint = strings.TrimSpace(constraint)
+	if constraint == "" {
+		return true
+	}
+
+	v := canonicalVersion(version)
+	cv := canonicalVersion(constraintVersion(constraint))
+	if !semver.IsValid(v) || !semver.IsValid(cv) {
+		return false
+	}
+
+	cmp := semver.Compare(v, cv)
+	switch {
+	case strings.HasPrefix(constraint, ">="):
+		return cmp >= 0
+	case strings.HasPrefix(constraint, "<="):
+		return cmp <= 0
+	case strings.HasPrefix(constraint, ">"):
+		return cmp > 0
+	case strings.HasPrefix(constraint, "<"):
+		return cmp < 0
+	case strings.HasPrefix(constraint, "^"):
+		return cmp >= 0 && caretCompatible(v, cv)
+	case strings.HasPrefix(constraint, "~"):
+		return cmp >= 0 && semver.MajorMinor(v) == semver.MajorMinor(cv)
+	default:
+		return cmp == 0
+	}
+}
+
+// caretCompatible reports whether canonical version v is in the caret range
+// of canonical version cv.
+func caretCompatible(v, cv string) bool {
+	switch {
+	case semver.Major(cv) != "v0":
+		return semver.Major(v) == semver.Major(cv)
+	case semver.MajorMinor(cv) != "v0.0":
+		return semver.MajorMinor(v) == semver.MajorMinor(cv)
+	default:
+		return semver.Compare(v, cv) == 0
+	}
+}
+
+// IsCompatibleWith reports whether this version can run on the given preflight version.
+// Development builds and unparsable versions are treated as compatible.
+func (v PackageVersion) IsCompatibleWith(preflightVersion string) bool {
+	if v.MinVersion == "" {
+		return true
+	}
+	current := canonicalVersion(preflightVersion)
+	minimum := canonicalVersion(v.MinVersion)
+	if !semver.IsValid(current) || !semver.IsValid(minimum) {
+		return true
+	}
+	return semver.Compare(current, minimum) >= 0
+}
+
+// InstallAction describes what happens to a package in an install plan.
+type InstallAction string
+
+// Install actions.
+const (
+	// ActionInstall installs a package that is not yet present.
+	ActionInstall InstallAction = "install"
+	// ActionUpgrade replaces an installed version that does not satisfy a constraint.
+	ActionUpgrade InstallAction = "upgrade"
+	// ActionKeep leaves an installed version that already satisfies all constraints.
+	ActionKeep InstallAction = "keep"
+)
+
+// PlannedPackage is a single resolved package in an install plan.
+type PlannedPackage struct {
+	Package          Package
+	Version          PackageVersion
+	Action           InstallAction
+	InstalledVersion string
+	// RequiredBy lists the packages that depend on this one; empty for the requested package.
+	RequiredBy []string
+}
+
+// InstallPlan is a resolved set of packages ordered so dependencies come first.
+type InstallPlan struct {
+	// Root is the package the user asked for.
+	Root PackageID
+	// Packages is in install order; the root package is last.
+	Packages []PlannedPackage
+}
+
+// Changes returns the packages that need to be installed or upgraded.
+func (p *InstallPlan) Changes() []PlannedPackage {
+	var changes []PlannedPackage
+	for _, pkg := range p.Packages {
+		if pkg.Action != ActionKeep {
+			changes = append(changes, pkg)
+		}
+	}
+	return changes
+}
+
+// HasDependencies returns true if the plan installs anything besides the root package.
+func (p *InstallPlan) HasDependencies() bool {
+	for _, pkg := range p.Changes() {
+		if !pkg.Package.ID.Equals(p.Root) {
+			return true
+		}
+	}
+	return false
+}
+
+// DependencyResolver builds install plans from a marketplace index.
+type DependencyResolver struct {
+	index            *Index
+	installed        map[string]InstalledPackage
+	preflightVersion string
+}
+
+// NewDependencyResolver creates a resolver over an index and the currently installed packages.
+func NewDependencyResolver(idx *Index, installed []InstalledPackage) *DependencyResolver {
+	byID := make(map[string]InstalledPackage, len(installed))
+	for _, pkg := range installed {
+		byID[pkg.Package.ID.String()] = pkg
+	}
+	return &DependencyResolver{
+		index:     idx,
+		installed: byID,
+	}
+}
+
+// WithPreflightVersion skips package versions that need a newer preflight.
+func (r *DependencyResolver) WithPreflightVersion(version string) *DependencyResolver {
+	r.preflightVersion = version
+	return r
+}
+
+// resolution is the working state of a single Resolve call.
+type resolution struct {
+	selected    map[string]*PlannedPackage
+	constraints map[string][]Dependency
+	requiredBy  map[string][]string
+	visiting    map[string]bool
+	path        []string
+	order       []string
+}
+
+// Resolve resolves the dependency graph for a package. Version may be empty or
+// "latest" to select the newest compatible release.
+//
+// Each package is resolved once, to the newest version that satisfies every
+// constraint seen so far; a later constraint that the selected version does not
+// satisfy is reported as a conflict rather than backtracked.
+func (r *DependencyResolver) Resolve(id PackageID, version string) (*InstallPlan, error) {
+	if version == "latest" {
+		version = ""
+	}
+
+	res := &resolution{
+		selected:    make(map[string]*PlannedPackage),
+		constraints: make(map[string][]Dependency),
+		requiredBy:  make(map[string][]string),
+		visiting:    make(map[string]bool),
+	}
+
+	root := Dependency{ID: id.String(), Version: version}
+	if err := r.resolve(res, root, ""); err != nil {
+		return nil, err
+	}
+
+	plan := &InstallPlan{Root: id}
+	for _, name := range res.order {
+		planned := res.selected[name]
+		planned.RequiredBy = res.requiredBy[name]
+		sort.Strings(planned.RequiredBy)
+		plan.Packages = append(plan.Packages, *planned)
+	}
+	return plan, nil
+}
+
+func (r *DependencyResolver) resolve(res *resolution, dep Dependency, parent string) error {
+	if parent != "" {
+		res.requiredBy[dep.ID] = append(res.requiredBy[dep.ID], parent)
+	}
+	res.constraints[dep.ID] = append(res.constraints[dep.ID], Dependency{ID: parent, Version: dep.Version})
+
+	if res.visiting[dep.ID] {
+		cycle := append(append([]string{}, res.path...), dep.ID)
+		return fmt.Errorf("%w: %s", ErrDependencyCycle, strings.Join(cycle, " -> "))
+	}
+
+	if existing, ok := res.selected[dep.ID]; ok {
+		if !SatisfiesConstraint(existing.Version.Version, dep.Version) {
+			return r.conflict(res, dep.ID, existing.Version.Version)
+		}
+		return nil
+	}
+
+	planned, err := r.selectVersion(res, dep.ID)
+	if err != nil {
+		return err
+	}
+	res.selected[dep.ID] = planned
+
+	res.visiting[dep.ID] = true
+	res.path = append(res.path, dep.ID)
+	for _, child := range planned.Version.Dependencies {
+		if err := child.Validate(); err != nil {
+			return fmt.Errorf("%s@%s: %w", dep.ID, planned.Version.Version, err)
+		}
+		if err := r.resolve(res, child, dep.ID); err != nil {
+			return err
+		}
+	}
+	res.path = res.path[:len(res.path)-1]
+	res.visiting[dep.ID] = false
+
+	res.order = append(res.order, dep.ID)
+	return nil
+}
+
+// selectVersion picks the version of a package to use, preferring what is
+// already installed when it satisfies every constraint.
+func (r *DependencyResolver) selectVersion(res *resolution, name string) (*PlannedPackage, error) {
+	id, err := NewPackageID(name)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg, ok := r.index.Get(id)
+	if !ok {
+		if len(res.requiredBy[name]) > 0 {
+			return nil, fmt.Errorf("%w: %s (required by %s)", ErrPackageNotFound, name, strings.Join(res.requiredBy[name], ", "))
+		}
+		return nil, fmt.Errorf("%w: %s", ErrPackageNotFound, name)
+	}
+
+	installed, isInstalled := r.installed[name]
+	if isInstalled && r.satisfiesAll(res, name, installed.Version) {
+		if v, ok := pkg.GetVersion(installed.Version); ok {
+			return &PlannedPackage{Package: pkg, Version: v, Action: ActionKeep, InstalledVersion: installed.Version}, nil
+		}
+	}
+
+	var best *PackageVersion
+	var incompatible *PackageVersion
+	for i := range pkg.Versions {
+		v := pkg.Versions[i]
+		if !r.satisfiesAll(res, name, v.Version) {
+			continue
+		}
+		if !v.IsCompatibleWith(r.preflightVersion) {
+			if incompatible == nil || semver.Compare(canonicalVersion(v.Version), canonicalVersion(incompatible.Version)) > 0 {
+				incompatible = &v
+			}
+			continue
+		}
+		if best == nil || semver.Compare(canonicalVersion(v.Version), canonicalVersion(best.Version)) > 0 {
+			best = &v
+		}
+	}
+
+	if best == nil {
+		if incompatible != nil {
+			return nil, fmt.Errorf("%w: %s@%s needs preflight %s or later (running %s)",
+				ErrIncompatibleVersion, name, incompatible.Version, incompatible.MinVersion, r.preflightVersion)
+		}
+		if len(pkg.Versions) == 0 {
+			return nil, fmt.Errorf("%w: no versions available for %s", ErrVersionNotFound, name)
+		}
+		return nil, fmt.Errorf("%w: %s", ErrUnsatisfiedDependency, describeConstraints(name, res.constraints[name]))
+	}
+
+	planned := &PlannedPackage{Package: pkg, Version: *best, Action: ActionInstall}
+	if isInstalled {
+		planned.InstalledVersion = installed.Version
+		if installed.Version == best.Version {
+			planned.Action = ActionKeep
+		} else {
+			planned.Action = ActionUpgrade
+		}
+	}
+	return planned, nil
+}
+
+func (r *DependencyResolver) satisfiesAll(res *resolution, name, version string) bool {
+	for _, c := range res.constraints[name] {
+		if !SatisfiesConstraint(version, c.Version) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *DependencyResolver) conflict(res *resolution, name, selected string) error {
+	return fmt.Errorf("%w: %s resolved to %s but %s",
+		ErrDependencyConflict, name, selected, describeConstraints(name, res.constraints[name]))
+}
+
+// describeConstraints renders the constraints on a package as "a requires x@^1, b requires x@2.0.0".
+// Constraints keyed by an empty ID come from the install request itself.
+func describeConstraints(name string, constraints []Dependency) string {
+	parts := make([]string, 0, len(constraints))
+	for _, c := range constraints {
+		required := Dependency{ID: name, Version: c.Version}
+		if c.ID == "" {
+			parts = append(parts, fmt.Sprintf("%s was requested", required))
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s requires %s", c.ID, required))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// constraintVersion strips the operator from a version constraint.
+func constraintVersion(constraint string) string {
+	for _, prefix := range []string{">=", "<=", ">", "<", "^", "~", "="} {
+		if strings.HasPrefix(constraint, prefix) {
+			return strings.TrimSpace(strings.TrimPrefix(constraint, prefix))
+		}
+	}
+	return strings.TrimSpace(constraint)
+}
+
+// canonicalVersion adds the "v" prefix expected by golang.org/x/mod/semver.
+func canonicalVersion(version string) string {
+	version = strings.TrimSpace(version)
+	if version == "" || strings.HasPrefix(version, "v") {
+		return version
+	}
+	return "v" + version
+}
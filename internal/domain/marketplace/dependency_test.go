@@ -0,0 +1,336 @@
+package marketplace
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func depIndex(t *testing.T, pkgs ...Package) *Index {
+	t.Helper()
+
+	idx := NewIndex()
+	for _, pkg := range pkgs {
+		require.NoError(t, idx.Add(pkg))
+	}
+	return idx
+}
+
+func depPackage(id string, versions ...PackageVersion) Package {
+	return Package{
+		ID:       MustNewPackageID(id),
+		Type:     PackageTypePreset,
+		Title:    id,
+		Versions: versions,
+	}
+}
+
+func planIDs(plan *InstallPlan) []string {
+	ids := make([]string, 0, len(plan.Packages))
+	for _, p := range plan.Packages {
+		ids = append(ids, p.Package.ID.String()+"@"+p.Version.Version)
+	}
+	return ids
+}
+
+func TestSatisfiesConstraint(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		version    string
+		constraint string
+		want       bool
+	}{
+		{"1.2.0", "", true},
+		{"1.2.0", "1.2.0", true},
+		{"1.2.1", "=1.2.0", false},
+		{"1.3.0", ">=1.2.0", true},
+		{"1.1.0", ">=1.2.0", false},
+		{"1.2.0", ">1.2.0", false},
+		{"1.1.0", "<1.2.0", true},
+		{"1.2.0", "<=1.2.0", true},
+		{"1.9.0", "^1.2.0", true},
+		{"2.0.0", "^1.2.0", false},
+		{"0.2.5", "^0.2.0", true},
+		{"0.3.0", "^0.2.0", false},
+		{"0.9.1", "^0.2.0", false},
+		{"0.0.3", "^0.0.3", true},
+		{"0.0.4", "^0.0.3", false},
+		{"0.1.0", "^0.0.3", false},
+		{"1.2.9", "~1.2.0", true},
+		{"1.3.0", "~1.2.0", false},
+		{"not-semver", ">=1.0.0", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, SatisfiesConstraint(tt.version, tt.constraint), "%s %s", tt.version, tt.constraint)
+	}
+}
+
+func TestDependency_Validate(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, Dependency{ID: "base", Version: "^1.0.0"}.Validate())
+	require.NoError(t, Dependency{ID: "base"}.Validate())
+	assert.ErrorIs(t, Dependency{ID: "Base"}.Validate(), ErrInvalidDependency)
+	assert.ErrorIs(t, Dependency{ID: "base", Version: ">=latest"}.Validate(), ErrInvalidDependency)
+}
+
+func TestPackageVersion_IsCompatibleWith(t *testing.T) {
+	t.Parallel()
+
+	v := PackageVersion{Version: "1.0.0", MinVersion: "2.1.0"}
+	assert.True(t, v.IsCompatibleWith("2.1.0"))
+	assert.True(t, v.IsCompatibleWith("v2.3.0"))
+	assert.False(t, v.IsCompatibleWith("2.0.9"))
+	assert.True(t, v.IsCompatibleWith("dev"))
+	assert.True(t, PackageVersion{Version: "1.0.0"}.IsCompatibleWith("0.1.0"))
+}
+
+func TestDependencyResolver_InstallOrder(t *testing.T) {
+	t.Parallel()
+
+	idx := depIndex(t,
+		depPackage("app", PackageVersion{Version: "1.0.0", Dependencies: []Dependency{
+			{ID: "lang-go", Version: "^1.0.0"},
+			{ID: "base"},
+		}}),
+		depPackage("lang-go", PackageVersion{Version: "1.4.0", Dependencies: []Dependency{{ID: "base", Version: ">=2.0.0"}}}),
+		depPackage("base",
+			PackageVersion{Version: "2.1.0"},
+			PackageVersion{Version: "2.0.0"},
+			PackageVersion{Version: "1.0.0"},
+		),
+	)
+
+	plan, err := NewDependencyResolver(idx, nil).Resolve(MustNewPackageID("app"), "latest")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"base@2.1.0", "lang-go@1.4.0", "app@1.0.0"}, planIDs(plan))
+	assert.Equal(t, []string{"app", "lang-go"}, plan.Packages[0].RequiredBy)
+	assert.Empty(t, plan.Packages[2].RequiredBy)
+	assert.True(t, plan.HasDependencies())
+	assert.Len(t, plan.Changes(), 3)
+}
+
+func TestDependencyResolver_PicksHighestSatisfying(t *testing.T) {
+	t.Parallel()
+
+	idx := depIndex(t,
+		depPackage("app", PackageVersion{Version: "1.0.0", Dependencies: []Dependency{{ID: "base", Version: "~1.1.0"}}}),
+		depPackage("base",
+			PackageVersion{Version: "1.2.0"},
+			PackageVersion{Version: "1.1.0"},
+			PackageVersion{Version: "1.1.3"},
+		),
+	)
+
+	plan, err := NewDependencyResolver(idx, nil).Resolve(MustNewPackageID("app"), "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"base@1.1.3", "app@1.0.0"}, planIDs(plan))
+}
+
+func TestDependencyResolver_Conflict(t *testing.T) {
+	t.Parallel()
+
+	idx := depIndex(t,
+		depPackage("app", PackageVersion{Version: "1.0.0", Dependencies: []Dependency{
+			{ID: "base", Version: "^1.0.0"},
+			{ID: "lang-go"},
+		}}),
+		depPackage("lang-go", PackageVersion{Version: "1.0.0", Dependencies: []Dependency{{ID: "base", Version: "^2.0.0"}}}),
+		depPackage("base", PackageVersion{Version: "2.0.0"}, PackageVersion{Version: "1.5.0"}),
+	)
+
+	_, err := NewDependencyResolver(idx, nil).Resolve(MustNewPackageID("app"), "")
+	require.ErrorIs(t, err, ErrDependencyConflict)
+	assert.Contains(t, err.Error(), "app requires base@^1.0.0")
+	assert.Contains(t, err.Error(), "lang-go requires base@^2.0.0")
+}
+
+func TestDependencyResolver_Cycle(t *testing.T) {
+	t.Parallel()
+
+	idx := depIndex(t,
+		depPackage("a", PackageVersion{Version: "1.0.0", Dependencies: []Dependency{{ID: "b"}}}),
+		depPackage("b", PackageVersion{Version: "1.0.0", Dependencies: []Dependency{{ID: "a"}}}),
+	)
+
+	_, err := NewDependencyResolver(idx, nil).Resolve(MustNewPackageID("a"), "")
+	require.ErrorIs(t, err, ErrDependencyCycle)
+	assert.Contains(t, err.Error(), "a -> b -> a")
+}
+
+func TestDependencyResolver_MissingDependency(t *testing.T) {
+	t.Parallel()
+
+	idx := depIndex(t,
+		depPackage("app", PackageVersion{Version: "1.0.0", Dependencies: []Dependency{{ID: "ghost"}}}),
+	)
+
+	_, err := NewDependencyResolver(idx, nil).Resolve(MustNewPackageID("app"), "")
+	require.ErrorIs(t, err, ErrPackageNotFound)
+	assert.Contains(t, err.Error(), "required by app")
+}
+
+func TestDependencyResolver_UnsatisfiedVersion(t *testing.T) {
+	t.Parallel()
+
+	idx := depIndex(t, depPackage("base", PackageVersion{Version: "1.0.0"}))
+
+	_, err := NewDependencyResolver(idx, nil).Resolve(MustNewPackageID("base"), "2.0.0")
+	require.ErrorIs(t, err, ErrUnsatisfiedDependency)
+	assert.Contains(t, err.Error(), "base@2.0.0 was requested")
+}
+
+func TestDependencyResolver_PreflightVersion(t *testing.T) {
+	t.Parallel()
+
+	idx := depIndex(t,
+		depPackage("base",
+			PackageVersion{Version: "2.0.0", MinVersion: "9.0.0"},
+			PackageVersion{Version: "1.0.0", MinVersion: "1.0.0"},
+		),
+	)
+
+	plan, err := NewDependencyResolver(idx, nil).WithPreflightVersion("1.5.0").Resolve(MustNewPackageID("base"), "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"base@1.0.0"}, planIDs(plan))
+
+	_, err = NewDependencyResolver(idx, nil).WithPreflightVersion("1.5.0").Resolve(MustNewPackageID("base"), "2.0.0")
+	require.ErrorIs(t, err, ErrIncompatibleVersion)
+}
+
+func TestDependencyResolver_Installed(t *testing.T) {
+	t.Parallel()
+
+	base := depPackage("base", PackageVersion{Version: "2.0.0"}, PackageVersion{Version: "1.2.0"})
+	tools := depPackage("tools", PackageVersion{Version: "2.0.0"}, PackageVersion{Version: "1.0.0"})
+	idx := depIndex(t,
+		depPackage("app", PackageVersion{Version: "1.0.0", Dependencies: []Dependency{
+			{ID: "base", Version: "^1.0.0"},
+			{ID: "tools", Version: "^2.0.0"},
+		}}),
+		base,
+		tools,
+	)
+	installed := []InstalledPackage{
+		{Package: base, Version: "1.2.0"},
+		{Package: tools, Version: "1.0.0"},
+	}
+
+	plan, err := NewDependencyResolver(idx, installed).Resolve(MustNewPackageID("app"), "")
+	require.NoError(t, err)
+	require.Len(t, plan.Packages, 3)
+
+	assert.Equal(t, ActionKeep, plan.Packages[0].Action)
+	assert.Equal(t, ActionUpgrade, plan.Packages[1].Action)
+	assert.Equal(t, "1.0.0", plan.Packages[1].InstalledVersion)
+	assert.Equal(t, "2.0.0", plan.Packages[1].Version.Version)
+	assert.Equal(t, ActionInstall, plan.Packages[2].Action)
+	assert.Len(t, plan.Changes(), 2)
+}
+
+func TestService_ResolveAndInstallPlan(t *testing.T) {
+	t.Parallel()
+
+	archive := func(name string) []byte {
+		var buf bytes.Buffer
+		gw := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gw)
+		content := []byte(name)
+		_ = tw.WriteHeader(&tar.Header{Name: "config.yaml", Mode: 0o644, Size: int64(len(content))})
+		_, _ = tw.Write(content)
+		_ = tw.Close()
+		_ = gw.Close()
+		return buf.Bytes()
+	}
+	appData := archive("app")
+	baseData := archive("base")
+
+	idx := depIndex(t,
+		depPackage("app", PackageVersion{
+			Version:      "1.0.0",
+			Checksum:     ComputeChecksum(appData),
+			Dependencies: []Dependency{{ID: "base", Version: "^1.0.0"}},
+		}),
+		depPackage("base", PackageVersion{Version: "1.0.0", Checksum: ComputeChecksum(baseData)}),
+	)
+	indexData, err := json.Marshal(idx)
+	require.NoError(t, err)
+
+	var downloads []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/index.json":
+			_, _ = w.Write(indexData)
+		case "/v1/packages/app/1.0.0.tar.gz":
+			downloads = append(downloads, "app")
+			_, _ = w.Write(appData)
+		case "/v1/packages/base/1.0.0.tar.gz":
+			downloads = append(downloads, "base")
+			_, _ = w.Write(baseData)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	service := NewService(ServiceConfig{
+		InstallPath:  tmpDir + "/installed",
+		CacheConfig:  CacheConfig{BasePath: tmpDir + "/cache", IndexTTL: time.Hour},
+		ClientConfig: ClientConfig{RegistryURL: server.URL, Timeout: 10 * time.Second},
+	})
+
+	ctx := context.Background()
+	plan, err := service.ResolveInstall(ctx, MustNewPackageID("app"), "latest")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"base@1.0.0", "app@1.0.0"}, planIDs(plan))
+
+	installed, err := service.InstallPlan(ctx, plan)
+	require.NoError(t, err)
+	require.Len(t, installed, 2)
+	assert.Equal(t, []string{"base", "app"}, downloads)
+
+	// Everything is installed now, so a second resolution keeps both.
+	plan, err = service.ResolveInstall(ctx, MustNewPackageID("app"), "")
+	require.NoError(t, err)
+	assert.Empty(t, plan.Changes())
+}
+
+func TestService_Install_IncompatiblePreflight(t *testing.T) {
+	t.Parallel()
+
+	indexData := `{"version":"1","packages":[{"id":"future","type":"preset","title":"Future",
+		"versions":[{"version":"1.0.0","checksum":"abc","min_preflight_version":"9.0.0"}]}]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "index.json") {
+			_, _ = w.Write([]byte(indexData))
+			return
+		}
+		t.Errorf("unexpected download: %s", r.URL.Path)
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	service := NewService(ServiceConfig{
+		InstallPath:      tmpDir + "/installed",
+		CacheConfig:      CacheConfig{BasePath: tmpDir + "/cache", IndexTTL: time.Hour},
+		ClientConfig:     ClientConfig{RegistryURL: server.URL, Timeout: 10 * time.Second},
+		PreflightVersion: "1.0.0",
+	})
+
+	_, err := service.Install(context.Background(), MustNewPackageID("future"), "1.0.0")
+	require.ErrorIs(t, err, ErrIncompatibleVersion)
+}
@@ -68,6 +68,47 @@ func (idx *Index) Add(pkg Package) error {
 	return nil
 }
 
+// AddRelease records a newly published version of a package.
+// New packages are added; existing packages get the version prepended
+// and their metadata refreshed. Publishing an existing version fails.
+func (idx *Index) AddRelease(pkg Package) error {
+	if !pkg.IsValid() || len(pkg.Versions) != 1 {
+		return ErrInvalidPackage
+	}
+	release := pkg.Versions[0]
+
+	if idx.byID == nil {
+		idx.byID = make(map[string]Package, len(idx.Packages))
+	}
+
+	existing, ok := idx.byID[pkg.ID.String()]
+	if !ok {
+		idx.Packages = append(idx.Packages, pkg)
+		idx.byID[pkg.ID.String()] = pkg
+		return nil
+	}
+
+	if _, exists := existing.GetVersion(release.Version); exists {
+		return fmt.Errorf("%w: %s@%s", ErrVersionExists, pkg.ID, release.Version)
+	}
+
+	updated := pkg
+	updated.Versions = append([]PackageVersion{release}, existing.Versions...)
+	updated.Downloads = existing.Downloads
+	updated.Stars = existing.Stars
+	updated.Rating = existing.Rating
+	updated.CreatedAt = existing.CreatedAt
+
+	for i := range idx.Packages {
+		if idx.Packages[i].ID.Equals(pkg.ID) {
+			idx.Packages[i] = updated
+			break
+		}
+	}
+	idx.byID[pkg.ID.String()] = updated
+	return nil
+}
+
 // Search finds packages matching the query.
 func (idx *Index) Search(query string) []Package {
 	if query == "" {
@@ -118,6 +159,14 @@ func (idx *Index) ListByPopularity() []Package {
 	return result
 }
 
+// ListByRating returns packages sorted by weighted user rating.
+func (idx *Index) ListByRating() []Package {
+	result := make([]Package, len(idx.Packages))
+	copy(result, idx.Packages)
+	SortByRating(result)
+	return result
+}
+
 // ListByRecent returns packages sorted by update time.
 func (idx *Index) ListByRecent() []Package {
 	result := make([]Package, len(idx.Packages))
@@ -0,0 +1,152 @@
+package marketplace
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/catalog"
+	"github.com/felixgeelhaar/preflight/internal/domain/signing"
+	"gopkg.in/yaml.v3"
+)
+
+// Layer bundle errors.
+var (
+	ErrInvalidBundle         = errors.New("invalid layer bundle")
+	ErrBundleSignatureFailed = errors.New("layer bundle signature verification failed")
+)
+
+// LayerBundle is a single layer packaged for sharing outside a config
+// repository. It carries the package metadata of the marketplace, the layer
+// file as written, its checksum and an optional signature. Bundles are YAML
+// so that they can be reviewed before they are imported.
+//
+// Signed bundles carry the public key of the signer as written in its .pub
+// file, so that importers can match it against the fingerprint in their
+// trust store.
+type LayerBundle struct {
+	PackageSpec `yaml:",inline"`
+
+	ExportedAt time.Time `yaml:"exported_at,omitempty"`
+	// Layer is the content of the layer file.
+	Layer string `yaml:"layer"`
+	// Checksum is the SHA256 of Layer.
+	Checksum  string `yaml:"checksum"`
+	KeyID     string `yaml:"key_id,omitempty"`
+	PublicKey string `yaml:"public_key,omitempty"`
+	Signature string `yaml:"signature,omitempty"`
+}
+
+// NewLayerBundle bundles the content of a layer file. The spec defaults to
+// the preset package type.
+func NewLayerBundle(spec PackageSpec, layer []byte) (*LayerBundle, error) {
+	if spec.Type == "" {
+		spec.Type = PackageTypePreset
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(string(layer)) == "" {
+		return nil, fmt.Errorf("%w: layer is empty", ErrInvalidBundle)
+	}
+
+	return &LayerBundle{
+		PackageSpec: spec,
+		ExportedAt:  time.Now().UTC().Truncate(time.Second),
+		Layer:       string(layer),
+		Checksum:    ComputeChecksum(layer),
+	}, nil
+}
+
+// ParseLayerBundle decodes a bundle and verifies its checksum. Signatures
+// are verified separately with VerifySignature, against a trusted key.
+func ParseLayerBundle(data []byte) (*LayerBundle, error) {
+	var bundle LayerBundle
+	if err := yaml.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidBundle, err)
+	}
+	if err := bundle.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidBundle, err)
+	}
+	if bundle.Layer == "" {
+		return nil, fmt.Errorf("%w: no layer", ErrInvalidBundle)
+	}
+	if err := (PackageVersion{Checksum: bundle.Checksum}).ValidateChecksum([]byte(bundle.Layer)); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidBundle, err)
+	}
+	if bundle.Signature != "" && (bundle.KeyID == "" || bundle.PublicKey == "") {
+		return nil, fmt.Errorf("%w: signature requires key_id and public_key", ErrInvalidBundle)
+	}
+	return &bundle, nil
+}
+
+// IsSigned reports whether the bundle carries a signature.
+func (b *LayerBundle) IsSigned() bool {
+	return b.Signature != ""
+}
+
+// Sign signs the bundle with an ED25519 key identified by keyID in the
+// trust store. publicKey is the content of the key's .pub file. The
+// signature covers the metadata and the layer.
+func (b *LayerBundle) Sign(key ed25519.PrivateKey, keyID string, publicKey []byte) error {
+	if keyID == "" {
+		return fmt.Errorf("%w: key ID is required for signing", ErrInvalidSigningKey)
+	}
+	pub, err := signing.ParseED25519PublicKey(publicKey)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSigningKey, err)
+	}
+	if !pub.Equal(key.Public()) {
+		return fmt.Errorf("%w: public key does not belong to the signing key", ErrInvalidSigningKey)
+	}
+	content, err := b.signedContent()
+	if err != nil {
+		return err
+	}
+	b.KeyID = keyID
+	b.PublicKey = string(publicKey)
+	b.Signature = SignArchive(content, key)
+	return nil
+}
+
+// VerifySignature verifies the signature of the bundle with its public key,
+// which must have the fingerprint of the trusted key.
+func (b *LayerBundle) VerifySignature(fingerprint string) error {
+	if !b.IsSigned() {
+		return fmt.Errorf("%w: bundle is not signed", ErrBundleSignatureFailed)
+	}
+	if catalog.ComputeKeyFingerprint([]byte(b.PublicKey)) != fingerprint {
+		return fmt.Errorf("%w: public key does not match trusted key %s", ErrBundleSignatureFailed, b.KeyID)
+	}
+	key, err := signing.ParseED25519PublicKey([]byte(b.PublicKey))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrBundleSignatureFailed, err)
+	}
+	content, err := b.signedContent()
+	if err != nil {
+		return err
+	}
+	if !VerifyArchiveSignature(content, b.Signature, key) {
+		return fmt.Errorf("%w: signed by %s", ErrBundleSignatureFailed, b.KeyID)
+	}
+	return nil
+}
+
+// signedContent is the encoding of the bundle without its signature.
+func (b *LayerBundle) signedContent() ([]byte, error) {
+	unsigned := *b
+	unsigned.KeyID, unsigned.PublicKey, unsigned.Signature = "", "", ""
+	return yaml.Marshal(&unsigned)
+}
+
+// Encode encodes the bundle as YAML.
+func (b *LayerBundle) Encode() ([]byte, error) {
+	data, err := yaml.Marshal(b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode layer bundle: %w", err)
+	}
+	header := "# Preflight layer bundle. Import it with: preflight layer import <file|url>\n"
+	return append([]byte(header), data...), nil
+}
@@ -0,0 +1,95 @@
+package marketplace
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"strings"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/catalog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+const testLayer = "# Go tooling\nname: dev-go\npackages:\n  brew:\n    formulae: [go, gopls]\n"
+
+func testLayerBundle(t *testing.T) *LayerBundle {
+	t.Helper()
+
+	bundle, err := NewLayerBundle(PackageSpec{ID: "dev-go", Title: "Go", Version: "1.0.0", Author: "Jane"}, []byte(testLayer))
+	require.NoError(t, err)
+	return bundle
+}
+
+func testBundleKey(t *testing.T) (ed25519.PrivateKey, []byte) {
+	t.Helper()
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	sshPub, err := ssh.NewPublicKey(pub)
+	require.NoError(t, err)
+	line := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPub))) + " jane@example.com\n"
+	return priv, []byte(line)
+}
+
+func TestLayerBundle_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	bundle := testLayerBundle(t)
+	assert.Equal(t, PackageTypePreset, bundle.Type)
+
+	data, err := bundle.Encode()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "# Preflight layer bundle."))
+
+	parsed, err := ParseLayerBundle(data)
+	require.NoError(t, err)
+	assert.Equal(t, testLayer, parsed.Layer, "the layer keeps its comments")
+	assert.Equal(t, "dev-go", parsed.ID)
+	assert.Equal(t, "Jane", parsed.Author)
+	assert.False(t, parsed.IsSigned())
+}
+
+func TestParseLayerBundle_Invalid(t *testing.T) {
+	t.Parallel()
+
+	data, err := testLayerBundle(t).Encode()
+	require.NoError(t, err)
+
+	_, err = ParseLayerBundle([]byte(strings.Replace(string(data), "gopls", "gopls, curl", 1)))
+	require.ErrorIs(t, err, ErrInvalidBundle)
+	require.ErrorIs(t, err, ErrChecksumMismatch)
+
+	_, err = ParseLayerBundle([]byte(strings.Replace(string(data), "version: 1.0.0", "version: latest", 1)))
+	require.ErrorIs(t, err, ErrInvalidSpec)
+
+	_, err = NewLayerBundle(PackageSpec{ID: "Dev Go", Title: "Go", Version: "1.0.0"}, []byte(testLayer))
+	require.ErrorIs(t, err, ErrInvalidSpec)
+}
+
+func TestLayerBundle_Signature(t *testing.T) {
+	t.Parallel()
+
+	key, pub := testBundleKey(t)
+	bundle := testLayerBundle(t)
+	require.NoError(t, bundle.Sign(key, "jane", pub))
+
+	data, err := bundle.Encode()
+	require.NoError(t, err)
+	parsed, err := ParseLayerBundle(data)
+	require.NoError(t, err)
+	require.True(t, parsed.IsSigned())
+
+	fingerprint := catalog.ComputeKeyFingerprint(pub)
+	require.NoError(t, parsed.VerifySignature(fingerprint))
+
+	_, other := testBundleKey(t)
+	require.ErrorIs(t, parsed.VerifySignature(catalog.ComputeKeyFingerprint(other)), ErrBundleSignatureFailed,
+		"the public key must be the trusted one")
+
+	parsed.Version = "2.0.0"
+	require.ErrorIs(t, parsed.VerifySignature(fingerprint), ErrBundleSignatureFailed, "metadata is signed")
+
+	require.ErrorIs(t, testLayerBundle(t).Sign(key, "jane", other), ErrInvalidSigningKey)
+}
@@ -16,6 +16,7 @@ const (
 	PackageTypePreset         = "preset"
 	PackageTypeCapabilityPack = "capability-pack"
 	PackageTypeLayerTemplate  = "layer-template"
+	PackageTypePlugin         = "plugin"
 )
 
 // Package errors.
@@ -23,6 +24,7 @@ var (
 	ErrInvalidPackage     = errors.New("invalid package")
 	ErrPackageNotFound    = errors.New("package not found")
 	ErrVersionNotFound    = errors.New("version not found")
+	ErrVersionExists      = errors.New("version already published")
 	ErrChecksumMismatch   = errors.New("checksum mismatch")
 	ErrInvalidChecksum    = errors.New("invalid checksum format")
 	ErrInvalidPackageType = errors.New("invalid package type")
@@ -114,6 +116,18 @@ type PackageVersion struct {
 	ReleasedAt time.Time `json:"released_at" yaml:"released_at"`
 	Changelog  string    `json:"changelog,omitempty" yaml:"changelog,omitempty"`
 	MinVersion string    `json:"min_preflight_version,omitempty" yaml:"min_preflight_version,omitempty"`
+	Signature  string    `json:"signature,omitempty" yaml:"signature,omitempty"` // base64 ED25519 signature of the archive
+	KeyID      string    `json:"key_id,omitempty" yaml:"key_id,omitempty"`       // Trust store key that produced the signature
+	// SigstoreBundle is a base64 Sigstore bundle holding a keyless signature
+	// of the archive, verified against the trusted OIDC identities.
+	SigstoreBundle string `json:"sigstore_bundle,omitempty" yaml:"sigstore_bundle,omitempty"`
+
+	Dependencies []Dependency `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
+}
+
+// IsSigned returns true if the version carries a publisher signature.
+func (v PackageVersion) IsSigned() bool {
+	return v.Signature != "" && v.KeyID != ""
 }
 
 // ValidateChecksum verifies that the given data matches this version's checksum.
@@ -146,6 +160,7 @@ type Package struct {
 	Versions    []PackageVersion `json:"versions" yaml:"versions"`
 	Downloads   int              `json:"downloads" yaml:"downloads"`
 	Stars       int              `json:"stars" yaml:"stars"`
+	Rating      RatingSummary    `json:"rating" yaml:"rating"`
 	CreatedAt   time.Time        `json:"created_at" yaml:"created_at"`
 	UpdatedAt   time.Time        `json:"updated_at" yaml:"updated_at"`
 }
@@ -221,7 +236,7 @@ func (p Package) MatchesQuery(query string) bool {
 
 func isValidPackageType(t string) bool {
 	switch t {
-	case PackageTypePreset, PackageTypeCapabilityPack, PackageTypeLayerTemplate:
+	case PackageTypePreset, PackageTypeCapabilityPack, PackageTypeLayerTemplate, PackageTypePlugin:
 		return true
 	default:
 		return false
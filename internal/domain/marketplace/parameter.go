@@ -0,0 +1,136 @@
+package marketplace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// PackageLayersDir holds the layers of a preset or layer template package.
+const PackageLayersDir = "layers"
+
+// Parameter is a value a package asks for when it is installed, such as an
+// email address or a proxy URL. It is substituted for {{ name }}
+// placeholders in the layers of the package.
+type Parameter struct {
+	Name     string `yaml:"name" json:"name"`
+	Prompt   string `yaml:"prompt,omitempty" json:"prompt,omitempty"`
+	Default  string `yaml:"default,omitempty" json:"default,omitempty"`
+	Required bool   `yaml:"required,omitempty" json:"required,omitempty"`
+}
+
+var parameterName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// parameterPlaceholder matches {{ name }}. Other template syntax is left
+// alone, so dotfile templates rendered at apply time are not touched.
+var parameterPlaceholder = regexp.MustCompile(`\{\{\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*\}\}`)
+
+// IsValidParameterName reports whether name can be used as a placeholder.
+func IsValidParameterName(name string) bool {
+	return parameterName.MatchString(name)
+}
+
+// Validate checks the name of the parameter.
+func (p Parameter) Validate() error {
+	if !IsValidParameterName(p.Name) {
+		return fmt.Errorf("invalid parameter name %q", p.Name)
+	}
+	return nil
+}
+
+// SubstituteParameters replaces {{ name }} with the value of name.
+// Placeholders of unknown parameters are kept.
+func SubstituteParameters(content string, values map[string]string) string {
+	return parameterPlaceholder.ReplaceAllStringFunc(content, func(match string) string {
+		name := parameterPlaceholder.FindStringSubmatch(match)[1]
+		if value, ok := values[name]; ok {
+			return value
+		}
+		return match
+	})
+}
+
+// PackageLayers returns the layer files of a package directory in name
+// order.
+func PackageLayers(dir string) ([]string, error) {
+	layers, err := filepath.Glob(filepath.Join(dir, PackageLayersDir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(layers)
+	return layers, nil
+}
+
+// ReferencedParameters returns the parameters that have a placeholder in
+// content.
+func ReferencedParameters(content string, params []Parameter) []Parameter {
+	referenced := make(map[string]bool)
+	for _, match := range parameterPlaceholder.FindAllStringSubmatch(content, -1) {
+		referenced[match[1]] = true
+	}
+
+	var found []Parameter
+	for _, p := range params {
+		if referenced[p.Name] {
+			found = append(found, p)
+		}
+	}
+	return found
+}
+
+// PendingParameters returns the parameters that are still referenced by a
+// placeholder in the layers of a package directory. Parameters filled in
+// earlier, for example on install, are not asked for again.
+func PendingParameters(dir string, params []Parameter) ([]Parameter, error) {
+	if len(params) == 0 {
+		return nil, nil
+	}
+	layers, err := PackageLayers(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var content strings.Builder
+	for _, path := range layers {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		content.Write(data)
+		content.WriteByte('\n')
+	}
+	return ReferencedParameters(content.String(), params), nil
+}
+
+// ApplyParameters substitutes values into the layers of a package directory
+// and returns the files it changed.
+func ApplyParameters(dir string, values map[string]string) ([]string, error) {
+	layers, err := PackageLayers(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	for _, path := range layers {
+		info, err := os.Stat(path)
+		if err != nil {
+			return changed, err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return changed, err
+		}
+		replaced := SubstituteParameters(string(data), values)
+		if replaced == string(data) {
+			continue
+		}
+		if err := os.WriteFile(path, []byte(replaced), info.Mode().Perm()); err != nil {
+			return changed, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		changed = append(changed, path)
+	}
+	return changed, nil
+}
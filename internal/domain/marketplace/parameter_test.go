@@ -0,0 +1,76 @@
+package marketplace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParameter_Validate(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, Parameter{Name: "git_email"}.Validate())
+	for _, name := range []string{"", "git-email", "1st", "a b"} {
+		assert.Error(t, Parameter{Name: name}.Validate(), name)
+	}
+}
+
+func TestPackageSpec_ValidateParameters(t *testing.T) {
+	t.Parallel()
+
+	spec := PackageSpec{ID: "acme-base", Title: "Acme", Version: "1.0.0", Type: PackageTypePreset,
+		Parameters: []Parameter{{Name: "email", Required: true}, {Name: "proxy_url"}}}
+	require.NoError(t, spec.Validate())
+
+	spec.Parameters = append(spec.Parameters, Parameter{Name: "email"})
+	require.ErrorIs(t, spec.Validate(), ErrInvalidSpec)
+
+	spec.Parameters = []Parameter{{Name: "proxy-url"}}
+	require.ErrorIs(t, spec.Validate(), ErrInvalidSpec)
+}
+
+func TestSubstituteParameters(t *testing.T) {
+	t.Parallel()
+
+	content := "name: {{ name }}\nemail: {{email}}\nhost: {{ .Hostname }}\nother: {{ unknown }}\n"
+	got := SubstituteParameters(content, map[string]string{"name": "Jane", "email": "jane@acme.com"})
+	assert.Equal(t, "name: Jane\nemail: jane@acme.com\nhost: {{ .Hostname }}\nother: {{ unknown }}\n", got)
+}
+
+func TestReferencedParameters(t *testing.T) {
+	t.Parallel()
+
+	params := []Parameter{{Name: "email"}, {Name: "name"}, {Name: "proxy_url"}}
+	got := ReferencedParameters("email: \"{{ email }}\"\nproxy: {{proxy_url}}\n", params)
+	assert.Equal(t, []Parameter{{Name: "email"}, {Name: "proxy_url"}}, got)
+}
+
+func TestApplyParameters(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	layers := filepath.Join(dir, PackageLayersDir)
+	require.NoError(t, os.MkdirAll(layers, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(layers, "git.yaml"), []byte("git:\n  user:\n    email: {{ email }}\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(layers, "base.yaml"), []byte("name: base\n"), 0o644))
+
+	params := []Parameter{{Name: "email"}, {Name: "proxy_url"}}
+	pending, err := PendingParameters(dir, params)
+	require.NoError(t, err)
+	assert.Equal(t, []Parameter{{Name: "email"}}, pending, "only referenced parameters are pending")
+
+	changed, err := ApplyParameters(dir, map[string]string{"email": "jane@acme.com"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(layers, "git.yaml")}, changed)
+
+	data, err := os.ReadFile(filepath.Join(layers, "git.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "git:\n  user:\n    email: jane@acme.com\n", string(data))
+
+	pending, err = PendingParameters(dir, params)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
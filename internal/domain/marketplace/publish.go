@@ -0,0 +1,449 @@
+package marketplace
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/mod/semver"
+	"gopkg.in/yaml.v3"
+)
+
+// Publishing file names.
+const (
+	// PackageSpecFile describes a publishable package.
+	PackageSpecFile = "package.yaml"
+	// ChangelogFile holds release notes, one "## <version>" section per release.
+	ChangelogFile = "CHANGELOG.md"
+)
+
+// Version bump kinds.
+const (
+	BumpMajor = "major"
+	BumpMinor = "minor"
+	BumpPatch = "patch"
+)
+
+// Publish errors.
+var (
+	ErrInvalidSpec       = errors.New("invalid package spec")
+	ErrInvalidBump       = errors.New("invalid version bump")
+	ErrInvalidSigningKey = errors.New("invalid signing key")
+)
+
+// PackageSpec is the author-maintained description of a package to publish.
+type PackageSpec struct {
+	ID                  string   `yaml:"id"`
+	Type                string   `yaml:"type"`
+	Title               string   `yaml:"title"`
+	Description         string   `yaml:"description,omitempty"`
+	Keywords            []string `yaml:"keywords,omitempty"`
+	Version             string   `yaml:"version"`
+	Author              string   `yaml:"author,omitempty"`
+	Repository          string   `yaml:"repository,omitempty"`
+	License             string   `yaml:"license,omitempty"`
+	MinPreflightVersion string   `yaml:"min_preflight_version,omitempty"`
+
+	Dependencies []Dependency `yaml:"dependencies,omitempty"`
+	// Parameters are asked for on install and substituted into the layers.
+	Parameters []Parameter `yaml:"parameters,omitempty"`
+	// Roles name sets of the package's layers for job families, such as
+	// backend or data. Init asks which roles to use.
+	Roles map[string][]string `yaml:"roles,omitempty"`
+}
+
+// LoadPackageSpec reads the package spec from a package directory.
+func LoadPackageSpec(dir string) (*PackageSpec, error) {
+	data, err := os.ReadFile(filepath.Join(dir, PackageSpecFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", PackageSpecFile, err)
+	}
+
+	var spec PackageSpec
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSpec, err)
+	}
+	return &spec, nil
+}
+
+// Validate checks that the spec describes a publishable package.
+func (s *PackageSpec) Validate() error {
+	if _, err := NewPackageID(s.ID); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSpec, err)
+	}
+	if !isValidPackageType(s.Type) {
+		return fmt.Errorf("%w: %w: %q", ErrInvalidSpec, ErrInvalidPackageType, s.Type)
+	}
+	if s.Title == "" {
+		return fmt.Errorf("%w: title is required", ErrInvalidSpec)
+	}
+	if !semver.IsValid("v" + s.Version) {
+		return fmt.Errorf("%w: version %q is not semver (e.g. 1.2.0)", ErrInvalidSpec, s.Version)
+	}
+	if s.MinPreflightVersion != "" && !semver.IsValid("v"+strings.TrimPrefix(s.MinPreflightVersion, "v")) {
+		return fmt.Errorf("%w: min_preflight_version %q is not semver", ErrInvalidSpec, s.MinPreflightVersion)
+	}
+	for _, dep := range s.Dependencies {
+		if err := dep.Validate(); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidSpec, err)
+		}
+		if dep.ID == s.ID {
+			return fmt.Errorf("%w: package cannot depend on itself", ErrInvalidSpec)
+		}
+	}
+	seen := make(map[string]bool, len(s.Parameters))
+	for _, param := range s.Parameters {
+		if err := param.Validate(); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidSpec, err)
+		}
+		if seen[param.Name] {
+			return fmt.Errorf("%w: duplicate parameter %q", ErrInvalidSpec, param.Name)
+		}
+		seen[param.Name] = true
+	}
+	for name, layers := range s.Roles {
+		if !roleName.MatchString(name) {
+			return fmt.Errorf("%w: invalid role name %q", ErrInvalidSpec, name)
+		}
+		if len(layers) == 0 {
+			return fmt.Errorf("%w: role %q lists no layers", ErrInvalidSpec, name)
+		}
+	}
+	return nil
+}
+
+// roleName matches role names, which follow the rules of target names.
+var roleName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`)
+
+// SetSpecVersion updates the version in the spec file on disk, preserving
+// the rest of the document (comments, key order).
+func SetSpecVersion(dir, version string) error {
+	path := filepath.Join(dir, PackageSpecFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", PackageSpecFile, err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSpec, err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("%w: expected a mapping", ErrInvalidSpec)
+	}
+
+	root := doc.Content[0]
+	updated := false
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "version" {
+			root.Content[i+1].Value = version
+			root.Content[i+1].Tag = "!!str"
+			updated = true
+			break
+		}
+	}
+	if !updated {
+		root.Content = append(root.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: "version"},
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: version},
+		)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", PackageSpecFile, err)
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// BumpVersion increments the major, minor, or patch component of a semver version.
+func BumpVersion(version, kind string) (string, error) {
+	v := "v" + strings.TrimPrefix(version, "v")
+	if !semver.IsValid(v) {
+		return "", fmt.Errorf("%w: %q is not semver", ErrInvalidBump, version)
+	}
+
+	var major, minor, patch int
+	core := strings.TrimPrefix(semver.Canonical(v), "v")
+	core = strings.SplitN(strings.SplitN(core, "-", 2)[0], "+", 2)[0]
+	if _, err := fmt.Sscanf(core, "%d.%d.%d", &major, &minor, &patch); err != nil {
+		return "", fmt.Errorf("%w: %q: %w", ErrInvalidBump, version, err)
+	}
+
+	switch kind {
+	case BumpMajor:
+		major, minor, patch = major+1, 0, 0
+	case BumpMinor:
+		minor, patch = minor+1, 0
+	case BumpPatch:
+		patch++
+	default:
+		return "", fmt.Errorf("%w: unknown kind %q (use major, minor, or patch)", ErrInvalidBump, kind)
+	}
+
+	return fmt.Sprintf("%d.%d.%d", major, minor, patch), nil
+}
+
+// ExtractChangelog returns the body of the changelog section for version.
+// Section headings may be written as "## 1.2.0", "## v1.2.0", or "## [1.2.0] - date".
+func ExtractChangelog(data []byte, version string) string {
+	version = strings.TrimPrefix(version, "v")
+	lines := strings.Split(string(data), "\n")
+
+	var body []string
+	inSection := false
+	for _, line := range lines {
+		if strings.HasPrefix(line, "## ") {
+			if inSection {
+				break
+			}
+			heading := strings.TrimSpace(strings.TrimPrefix(line, "## "))
+			heading = strings.TrimPrefix(heading, "[")
+			heading = strings.TrimPrefix(heading, "v")
+			if heading == version || strings.HasPrefix(heading, version+"]") || strings.HasPrefix(heading, version+" ") {
+				inSection = true
+			}
+			continue
+		}
+		if inSection {
+			body = append(body, line)
+		}
+	}
+
+	return strings.TrimSpace(strings.Join(body, "\n"))
+}
+
+// BuildArchive packs a package directory into a deterministic tar.gz.
+// VCS metadata is excluded, entries are sorted, and timestamps are zeroed
+// so the same tree always yields the same checksum.
+func BuildArchive(dir string) ([]byte, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			return fmt.Errorf("symlinks are not allowed in packages: %s", path)
+		}
+		if path != dir {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk package: %w", err)
+	}
+	sort.Strings(paths)
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return nil, err
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return nil, err
+		}
+		header.Name = filepath.ToSlash(rel)
+		header.ModTime = time.Time{}
+		header.AccessTime = time.Time{}
+		header.ChangeTime = time.Time{}
+		header.Uid, header.Gid = 0, 0
+		header.Uname, header.Gname = "", ""
+		if info.IsDir() {
+			header.Name += "/"
+			header.Mode = 0o755
+		} else {
+			header.Mode = 0o644
+			if info.Mode()&0o111 != 0 {
+				header.Mode = 0o755
+			}
+		}
+
+		if err := tw.WriteHeader(header); err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := tw.Write(data); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SignArchive signs the SHA256 digest of an archive with an ED25519 key.
+// The result is compatible with catalog.ED25519Verifier.
+func SignArchive(archive []byte, key ed25519.PrivateKey) string {
+	hash := sha256.Sum256(archive)
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(key, hash[:]))
+}
+
+// VerifyArchiveSignature verifies a signature produced by SignArchive.
+func VerifyArchiveSignature(archive []byte, signature string, key ed25519.PublicKey) bool {
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	hash := sha256.Sum256(archive)
+	return ed25519.Verify(key, hash[:], sig)
+}
+
+// LoadSigningKey reads an unencrypted OpenSSH ED25519 private key.
+func LoadSigningKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+
+	raw, err := ssh.ParseRawPrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSigningKey, err)
+	}
+
+	switch key := raw.(type) {
+	case ed25519.PrivateKey:
+		return key, nil
+	case *ed25519.PrivateKey:
+		return *key, nil
+	default:
+		return nil, fmt.Errorf("%w: only ed25519 keys are supported", ErrInvalidSigningKey)
+	}
+}
+
+// Release is a packaged, checksummed, and optionally signed package version.
+type Release struct {
+	Package Package
+	Archive []byte
+}
+
+// Version returns the single version carried by the release.
+func (r *Release) Version() PackageVersion {
+	return r.Package.Versions[0]
+}
+
+// PublishOptions configures release preparation.
+type PublishOptions struct {
+	// Bump increments the spec version (major, minor, patch) before packaging.
+	Bump string
+	// SigningKey signs the archive when set.
+	SigningKey ed25519.PrivateKey
+	// KeyID identifies SigningKey in the trust store.
+	KeyID string
+	// DryRun prepares the release without rewriting the spec file.
+	DryRun bool
+}
+
+// PrepareRelease validates, versions, packages, and signs a package directory.
+func PrepareRelease(dir string, opts PublishOptions) (*Release, error) {
+	spec, err := LoadPackageSpec(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Bump != "" {
+		next, err := BumpVersion(spec.Version, opts.Bump)
+		if err != nil {
+			return nil, err
+		}
+		spec.Version = next
+		if !opts.DryRun {
+			if err := SetSpecVersion(dir, next); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+
+	changelog := ""
+	if data, err := os.ReadFile(filepath.Join(dir, ChangelogFile)); err == nil {
+		changelog = ExtractChangelog(data, spec.Version)
+	}
+
+	archive, err := BuildArchive(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	version := PackageVersion{
+		Version:      spec.Version,
+		Checksum:     ComputeChecksum(archive),
+		ReleasedAt:   now,
+		Changelog:    changelog,
+		MinVersion:   strings.TrimPrefix(spec.MinPreflightVersion, "v"),
+		Dependencies: spec.Dependencies,
+	}
+
+	provenance := Provenance{
+		Author:     spec.Author,
+		Repository: spec.Repository,
+		License:    spec.License,
+	}
+
+	if opts.SigningKey != nil {
+		if opts.KeyID == "" {
+			return nil, fmt.Errorf("%w: key ID is required for signing", ErrInvalidSigningKey)
+		}
+		version.Signature = SignArchive(archive, opts.SigningKey)
+		version.KeyID = opts.KeyID
+		provenance.SignedBy = opts.KeyID
+		provenance.SignedAt = now
+	}
+
+	return &Release{
+		Package: Package{
+			ID:          MustNewPackageID(spec.ID),
+			Type:        spec.Type,
+			Title:       spec.Title,
+			Description: spec.Description,
+			Keywords:    spec.Keywords,
+			Provenance:  provenance,
+			Versions:    []PackageVersion{version},
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		},
+		Archive: archive,
+	}, nil
+}
@@ -0,0 +1,221 @@
+package marketplace
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/rand"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSpec = `# Package spec
+id: nvim-pro
+type: preset
+title: Neovim Pro
+description: Opinionated Neovim setup
+keywords: [nvim, editor]
+version: 1.2.0
+author: Jane Doe
+license: MIT
+`
+
+const testChangelog = `# Changelog
+
+## [1.3.0] - 2026-01-01
+
+- Add LSP defaults
+
+## 1.2.0
+
+- Initial release
+`
+
+func writePackageDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, PackageSpecFile), []byte(testSpec), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ChangelogFile), []byte(testChangelog), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "layers"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "layers", "base.yaml"), []byte("name: base\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".git"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".git", "HEAD"), []byte("ref"), 0o644))
+	return dir
+}
+
+func TestPackageSpec_Validate(t *testing.T) {
+	t.Parallel()
+
+	valid := PackageSpec{ID: "nvim-pro", Type: PackageTypePreset, Title: "Neovim Pro", Version: "1.0.0"}
+	require.NoError(t, valid.Validate())
+
+	tests := []struct {
+		name   string
+		mutate func(*PackageSpec)
+	}{
+		{"bad id", func(s *PackageSpec) { s.ID = "Nvim_Pro" }},
+		{"bad type", func(s *PackageSpec) { s.Type = "theme" }},
+		{"missing title", func(s *PackageSpec) { s.Title = "" }},
+		{"bad version", func(s *PackageSpec) { s.Version = "one" }},
+		{"bad min version", func(s *PackageSpec) { s.MinPreflightVersion = "latest" }},
+		{"bad dependency", func(s *PackageSpec) { s.Dependencies = []Dependency{{ID: "base", Version: "^one"}} }},
+		{"self dependency", func(s *PackageSpec) { s.Dependencies = []Dependency{{ID: "nvim-pro"}} }},
+		{"bad role name", func(s *PackageSpec) { s.Roles = map[string][]string{"role.backend": {"lang.go"}} }},
+		{"empty role", func(s *PackageSpec) { s.Roles = map[string][]string{"backend": nil} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			spec := valid
+			tt.mutate(&spec)
+			assert.ErrorIs(t, spec.Validate(), ErrInvalidSpec)
+		})
+	}
+}
+
+func TestBumpVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		version, kind, want string
+	}{
+		{"1.2.3", BumpPatch, "1.2.4"},
+		{"1.2.3", BumpMinor, "1.3.0"},
+		{"1.2.3", BumpMajor, "2.0.0"},
+		{"v0.9.0", BumpMinor, "0.10.0"},
+		{"1.0.0-rc.1", BumpPatch, "1.0.1"},
+	}
+	for _, tt := range tests {
+		got, err := BumpVersion(tt.version, tt.kind)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got, "%s %s", tt.version, tt.kind)
+	}
+
+	_, err := BumpVersion("1.0.0", "huge")
+	require.ErrorIs(t, err, ErrInvalidBump)
+	_, err = BumpVersion("nope", BumpPatch)
+	require.ErrorIs(t, err, ErrInvalidBump)
+}
+
+func TestExtractChangelog(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "- Add LSP defaults", ExtractChangelog([]byte(testChangelog), "1.3.0"))
+	assert.Equal(t, "- Initial release", ExtractChangelog([]byte(testChangelog), "v1.2.0"))
+	assert.Empty(t, ExtractChangelog([]byte(testChangelog), "9.9.9"))
+}
+
+func TestSetSpecVersion_PreservesDocument(t *testing.T) {
+	t.Parallel()
+
+	dir := writePackageDir(t)
+	require.NoError(t, SetSpecVersion(dir, "1.3.0"))
+
+	data, err := os.ReadFile(filepath.Join(dir, PackageSpecFile))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# Package spec")
+	assert.Contains(t, string(data), "version: 1.3.0")
+
+	spec, err := LoadPackageSpec(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "1.3.0", spec.Version)
+}
+
+func TestBuildArchive_DeterministicAndExcludesGit(t *testing.T) {
+	t.Parallel()
+
+	dir := writePackageDir(t)
+	first, err := BuildArchive(dir)
+	require.NoError(t, err)
+
+	// Touch a file: content is unchanged so the checksum must be stable.
+	touched := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "layers", "base.yaml"), touched, touched))
+	second, err := BuildArchive(dir)
+	require.NoError(t, err)
+	assert.Equal(t, ComputeChecksum(first), ComputeChecksum(second))
+
+	gr, err := gzip.NewReader(bytes.NewReader(first))
+	require.NoError(t, err)
+	tr := tar.NewReader(gr)
+	var names []string
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		names = append(names, hdr.Name)
+	}
+	assert.Equal(t, []string{"CHANGELOG.md", "layers/", "layers/base.yaml", "package.yaml"}, names)
+}
+
+func TestPrepareRelease(t *testing.T) {
+	t.Parallel()
+
+	dir := writePackageDir(t)
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	release, err := PrepareRelease(dir, PublishOptions{
+		Bump:       BumpMinor,
+		SigningKey: priv,
+		KeyID:      "jane",
+	})
+	require.NoError(t, err)
+
+	version := release.Version()
+	assert.Equal(t, "nvim-pro", release.Package.ID.String())
+	assert.Equal(t, "1.3.0", version.Version)
+	assert.Equal(t, "- Add LSP defaults", version.Changelog)
+	assert.Equal(t, ComputeChecksum(release.Archive), version.Checksum)
+	assert.True(t, version.IsSigned())
+	assert.True(t, VerifyArchiveSignature(release.Archive, version.Signature, pub))
+	assert.Equal(t, "jane", release.Package.Provenance.SignedBy)
+
+	spec, err := LoadPackageSpec(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "1.3.0", spec.Version, "bump should be written back to the spec")
+}
+
+func TestPrepareRelease_DryRunKeepsSpec(t *testing.T) {
+	t.Parallel()
+
+	dir := writePackageDir(t)
+	release, err := PrepareRelease(dir, PublishOptions{Bump: BumpPatch, DryRun: true})
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.1", release.Version().Version)
+	assert.False(t, release.Version().IsSigned())
+
+	spec, err := LoadPackageSpec(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.0", spec.Version)
+}
+
+func TestPrepareRelease_SigningKeyRequiresKeyID(t *testing.T) {
+	t.Parallel()
+
+	dir := writePackageDir(t)
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	_, err = PrepareRelease(dir, PublishOptions{SigningKey: priv})
+	require.ErrorIs(t, err, ErrInvalidSigningKey)
+}
+
+func TestLoadSigningKey_Invalid(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "id_ed25519")
+	require.NoError(t, os.WriteFile(path, []byte("not a key"), 0o600))
+
+	_, err := LoadSigningKey(path)
+	require.ErrorIs(t, err, ErrInvalidSigningKey)
+}
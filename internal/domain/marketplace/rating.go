@@ -0,0 +1,88 @@
+package marketplace
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Rating bounds.
+const (
+	MinRatingScore = 1
+	MaxRatingScore = 5
+)
+
+// Rating ranking parameters. Averages are pulled toward ratingPriorMean with
+// the weight of ratingPriorCount votes so that a single 5-star rating does not
+// outrank a package with many good ratings.
+const (
+	ratingPriorMean  = 3.0
+	ratingPriorCount = 5.0
+)
+
+// ErrInvalidRating is returned for scores outside 1-5.
+var ErrInvalidRating = errors.New("rating must be between 1 and 5")
+
+// RatingSummary is the registry's aggregate of user ratings for a package.
+type RatingSummary struct {
+	Average float64 `json:"average" yaml:"average"`
+	Count   int     `json:"count" yaml:"count"`
+}
+
+// IsZero returns true if the package has not been rated.
+func (s RatingSummary) IsZero() bool {
+	return s.Count == 0
+}
+
+// With returns the summary with one additional rating folded in.
+func (s RatingSummary) With(score int) RatingSummary {
+	total := s.Average*float64(s.Count) + float64(score)
+	return RatingSummary{
+		Average: total / float64(s.Count+1),
+		Count:   s.Count + 1,
+	}
+}
+
+// Weighted returns the Bayesian average used for ranking.
+func (s RatingSummary) Weighted() float64 {
+	return (ratingPriorMean*ratingPriorCount + s.Average*float64(s.Count)) / (ratingPriorCount + float64(s.Count))
+}
+
+// String renders the summary as "4.3 (12)" or "-" when unrated.
+func (s RatingSummary) String() string {
+	if s.IsZero() {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f (%d)", s.Average, s.Count)
+}
+
+// Rating is a user's own rating of a package, stored locally.
+type Rating struct {
+	Package   PackageID `json:"package"`
+	Score     int       `json:"score"`
+	RatedAt   time.Time `json:"rated_at"`
+	Submitted bool      `json:"submitted"`
+}
+
+// ValidateRatingScore checks that a score is in the 1-5 range.
+func ValidateRatingScore(score int) error {
+	if score < MinRatingScore || score > MaxRatingScore {
+		return fmt.Errorf("%w: got %d", ErrInvalidRating, score)
+	}
+	return nil
+}
+
+// SortByRating orders packages by weighted rating, then downloads, then ID.
+func SortByRating(pkgs []Package) {
+	sort.SliceStable(pkgs, func(i, j int) bool {
+		wi, wj := pkgs[i].Rating.Weighted(), pkgs[j].Rating.Weighted()
+		if wi != wj {
+			return wi > wj
+		}
+		if pkgs[i].Downloads != pkgs[j].Downloads {
+			return pkgs[i].Downloads > pkgs[j].Downloads
+		}
+		return pkgs[i].ID.String() < pkgs[j].ID.String()
+	})
+}
@@ -0,0 +1,186 @@
+package marketplace
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRatingSummary(t *testing.T) {
+	t.Parallel()
+
+	var s RatingSummary
+	assert.True(t, s.IsZero())
+	assert.Equal(t, "-", s.String())
+	assert.InDelta(t, 3.0, s.Weighted(), 0.001)
+
+	s = s.With(5).With(4)
+	assert.Equal(t, 2, s.Count)
+	assert.InDelta(t, 4.5, s.Average, 0.001)
+	assert.Equal(t, "4.5 (2)", s.String())
+
+	// Few ratings are pulled toward the prior; many ratings dominate it.
+	few := RatingSummary{Average: 5, Count: 1}
+	many := RatingSummary{Average: 4.6, Count: 200}
+	assert.Greater(t, many.Weighted(), few.Weighted())
+}
+
+func TestValidateRatingScore(t *testing.T) {
+	t.Parallel()
+
+	for score := MinRatingScore; score <= MaxRatingScore; score++ {
+		require.NoError(t, ValidateRatingScore(score))
+	}
+	assert.ErrorIs(t, ValidateRatingScore(0), ErrInvalidRating)
+	assert.ErrorIs(t, ValidateRatingScore(6), ErrInvalidRating)
+}
+
+func TestSortByRating(t *testing.T) {
+	t.Parallel()
+
+	pkgs := []Package{
+		{ID: MustNewPackageID("unrated-popular"), Downloads: 5000, Stars: 100},
+		{ID: MustNewPackageID("one-perfect"), Rating: RatingSummary{Average: 5, Count: 1}},
+		{ID: MustNewPackageID("well-loved"), Rating: RatingSummary{Average: 4.7, Count: 40}},
+		{ID: MustNewPackageID("disliked"), Rating: RatingSummary{Average: 1.5, Count: 20}},
+		{ID: MustNewPackageID("unrated-new")},
+	}
+
+	SortByRating(pkgs)
+
+	ids := make([]string, len(pkgs))
+	for i, p := range pkgs {
+		ids[i] = p.ID.String()
+	}
+	assert.Equal(t, []string{"well-loved", "one-perfect", "unrated-popular", "unrated-new", "disliked"}, ids)
+}
+
+func TestIndex_ListByRating(t *testing.T) {
+	t.Parallel()
+
+	idx := NewIndex()
+	low := Package{ID: MustNewPackageID("low"), Type: PackageTypePreset, Title: "Low",
+		Versions: []PackageVersion{{Version: "1.0.0"}}, Rating: RatingSummary{Average: 2, Count: 10}}
+	high := Package{ID: MustNewPackageID("high"), Type: PackageTypePreset, Title: "High",
+		Versions: []PackageVersion{{Version: "1.0.0"}}, Rating: RatingSummary{Average: 4.8, Count: 10}}
+	require.NoError(t, idx.Add(low))
+	require.NoError(t, idx.Add(high))
+
+	ranked := idx.ListByRating()
+	assert.Equal(t, "high", ranked[0].ID.String())
+	assert.Equal(t, "low", idx.Packages[0].ID.String(), "index order must be untouched")
+}
+
+func TestCache_Ratings(t *testing.T) {
+	t.Parallel()
+
+	cache := NewCache(CacheConfig{BasePath: t.TempDir()})
+
+	ratings, err := cache.GetRatings()
+	require.NoError(t, err)
+	assert.Empty(t, ratings)
+
+	id := MustNewPackageID("nvim-pro")
+	require.NoError(t, cache.PutRating(Rating{Package: id, Score: 3}))
+	require.NoError(t, cache.PutRating(Rating{Package: id, Score: 5}))
+
+	rating, found, err := cache.GetRating(id)
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, 5, rating.Score)
+
+	ratings, err = cache.GetRatings()
+	require.NoError(t, err)
+	assert.Len(t, ratings, 1)
+
+	_, found, err = cache.GetRating(MustNewPackageID("other"))
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestClient_SubmitRating(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+		switch r.URL.Path {
+		case "/v1/packages/nvim-pro/ratings":
+			body, _ := io.ReadAll(r.Body)
+			var req ratingRequest
+			assert.NoError(t, json.Unmarshal(body, &req))
+			assert.Equal(t, 4, req.Score)
+			w.WriteHeader(http.StatusCreated)
+		case "/v1/packages/limited/ratings":
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(ClientConfig{RegistryURL: server.URL, Timeout: 10 * time.Second, AuthToken: "secret"})
+	ctx := context.Background()
+
+	require.NoError(t, client.SubmitRating(ctx, MustNewPackageID("nvim-pro"), 4))
+	assert.ErrorIs(t, client.SubmitRating(ctx, MustNewPackageID("limited"), 4), ErrRateLimited)
+	assert.ErrorIs(t, client.SubmitRating(ctx, MustNewPackageID("missing"), 4), ErrPackageNotFound)
+}
+
+func TestService_Rate(t *testing.T) {
+	t.Parallel()
+
+	submitted := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/index.json":
+			_, _ = w.Write([]byte(`{"version":"1","packages":[{"id":"nvim-pro","type":"preset","title":"Neovim Pro","versions":[{"version":"1.0.0"}]}]}`))
+		case "/v1/packages/nvim-pro/ratings":
+			submitted++
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	service := NewService(ServiceConfig{
+		InstallPath:  tmpDir + "/installed",
+		CacheConfig:  CacheConfig{BasePath: tmpDir + "/cache", IndexTTL: time.Hour},
+		ClientConfig: ClientConfig{RegistryURL: server.URL, Timeout: 10 * time.Second},
+	})
+	ctx := context.Background()
+	id := MustNewPackageID("nvim-pro")
+
+	// Local only by default
+	rating, err := service.Rate(ctx, id, 4, false)
+	require.NoError(t, err)
+	assert.False(t, rating.Submitted)
+	assert.Equal(t, 0, submitted)
+
+	rating, err = service.Rate(ctx, id, 5, true)
+	require.NoError(t, err)
+	assert.True(t, rating.Submitted)
+	assert.Equal(t, 1, submitted)
+
+	stored, found, err := service.LocalRating(id)
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, 5, stored.Score)
+	assert.True(t, stored.Submitted)
+
+	_, err = service.Rate(ctx, id, 9, false)
+	require.ErrorIs(t, err, ErrInvalidRating)
+
+	_, err = service.Rate(ctx, MustNewPackageID("unknown"), 3, false)
+	require.ErrorIs(t, err, ErrPackageNotFound)
+}
@@ -233,7 +233,7 @@ func (r *Recommender) FeaturedPackages(ctx context.Context) ([]Recommendation, e
 		}
 
 		score := r.popularityScore(pkg)
-		if pkg.Stars >= 10 && score > 0.5 {
+		if isHighlyRated(pkg, 10) && score > 0.5 {
 			recommendations = append(recommendations, Recommendation{
 				Package: pkg,
 				Score:   score * 1.5, // Boost verified packages
@@ -314,7 +314,7 @@ func (r *Recommender) scorePackage(
 	}
 
 	// Highly rated boost
-	if pkg.Stars >= 5 {
+	if isHighlyRated(pkg, 5) {
 		reasons = append(reasons, ReasonHighlyRated)
 	}
 
@@ -366,7 +366,8 @@ func (r *Recommender) scoreSimilarity(pkg, source Package, sourceKeywords map[st
 	}
 }
 
-// popularityScore computes a 0-1 score based on downloads and stars.
+// popularityScore computes a 0-1 score based on downloads and ratings.
+// Stars are only used for packages nobody has rated yet.
 func (r *Recommender) popularityScore(pkg Package) float64 {
 	// Normalize downloads (assume 1000 is high)
 	downloadScore := float64(pkg.Downloads) / 1000.0
@@ -374,14 +375,29 @@ func (r *Recommender) popularityScore(pkg Package) float64 {
 		downloadScore = 1.0
 	}
 
-	// Normalize stars (assume 50 is high)
-	starScore := float64(pkg.Stars) / 50.0
-	if starScore > 1.0 {
-		starScore = 1.0
+	// Map the weighted 1-5 rating onto 0-1
+	var ratingScore float64
+	if !pkg.Rating.IsZero() {
+		ratingScore = (pkg.Rating.Weighted() - MinRatingScore) / (MaxRatingScore - MinRatingScore)
+	} else {
+		// Normalize stars (assume 50 is high)
+		ratingScore = float64(pkg.Stars) / 50.0
+		if ratingScore > 1.0 {
+			ratingScore = 1.0
+		}
 	}
 
 	// Combine with weights
-	return (downloadScore * 0.6) + (starScore * 0.4)
+	return (downloadScore * 0.6) + (ratingScore * 0.4)
+}
+
+// isHighlyRated reports whether users rate a package well. Packages without
+// ratings fall back to having at least minStars stars.
+func isHighlyRated(pkg Package, minStars int) bool {
+	if !pkg.Rating.IsZero() {
+		return pkg.Rating.Weighted() >= 3.75
+	}
+	return pkg.Stars >= minStars
 }
 
 // recencyScore computes a 0-1 score based on how recently the package was updated.
@@ -52,6 +52,20 @@ func TestRecommender_PopularityScore(t *testing.T) {
 	}
 }
 
+func TestRecommender_PopularityScore_PrefersRatings(t *testing.T) {
+	t.Parallel()
+
+	r := &Recommender{config: DefaultRecommenderConfig()}
+
+	starred := Package{Stars: 50, Rating: RatingSummary{Average: 1.2, Count: 30}}
+	rated := Package{Stars: 0, Rating: RatingSummary{Average: 4.9, Count: 30}}
+	assert.Greater(t, r.popularityScore(rated), r.popularityScore(starred))
+
+	assert.True(t, isHighlyRated(rated, 10))
+	assert.False(t, isHighlyRated(starred, 10))
+	assert.True(t, isHighlyRated(Package{Stars: 12}, 10))
+}
+
 func TestRecommender_RecencyScore(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,133 @@
+package marketplace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/felixgeelhaar/preflight/internal/ports"
+)
+
+// ErrPublishFailed is returned when a registry rejects or fails to record a release.
+var ErrPublishFailed = errors.New("publish failed")
+
+// Registry accepts published releases.
+type Registry interface {
+	// Publish uploads a release to the registry.
+	Publish(ctx context.Context, release *Release) error
+	// Location describes where releases are published.
+	Location() string
+}
+
+// GitRegistry publishes into a local checkout of a git-hosted registry.
+// The checkout mirrors the HTTP layout served by the registry:
+//
+//	v1/index.json
+//	v1/packages/<id>/<version>.tar.gz
+type GitRegistry struct {
+	path   string
+	runner ports.CommandRunner
+	push   bool
+}
+
+// NewGitRegistry creates a registry backed by the git checkout at path.
+func NewGitRegistry(path string, runner ports.CommandRunner) *GitRegistry {
+	return &GitRegistry{path: path, runner: runner, push: true}
+}
+
+// WithPush controls whether the commit is pushed to the checkout's upstream.
+func (r *GitRegistry) WithPush(push bool) *GitRegistry {
+	r.push = push
+	return r
+}
+
+// Location returns the checkout path.
+func (r *GitRegistry) Location() string {
+	return r.path
+}
+
+// Publish writes the archive, updates the index, and commits the result.
+func (r *GitRegistry) Publish(ctx context.Context, release *Release) error {
+	version := release.Version()
+	root := filepath.Join(r.path, "v1")
+	indexPath := filepath.Join(root, "index.json")
+
+	idx := NewIndex()
+	if data, err := os.ReadFile(indexPath); err == nil {
+		parsed, err := ParseIndex(data)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrPublishFailed, err)
+		}
+		idx = parsed
+	}
+
+	if err := idx.AddRelease(release.Package); err != nil {
+		return fmt.Errorf("%w: %w", ErrPublishFailed, err)
+	}
+
+	pkgDir := filepath.Join(root, "packages", release.Package.ID.String())
+	if err := os.MkdirAll(pkgDir, 0o755); err != nil {
+		return fmt.Errorf("%w: %w", ErrPublishFailed, err)
+	}
+	archivePath := filepath.Join(pkgDir, version.Version+".tar.gz")
+	if err := os.WriteFile(archivePath, release.Archive, 0o644); err != nil {
+		return fmt.Errorf("%w: %w", ErrPublishFailed, err)
+	}
+
+	data, err := idx.Marshal()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrPublishFailed, err)
+	}
+	if err := os.WriteFile(indexPath, data, 0o644); err != nil {
+		return fmt.Errorf("%w: %w", ErrPublishFailed, err)
+	}
+
+	message := fmt.Sprintf("Publish %s@%s", release.Package.ID, version.Version)
+	commands := [][]string{
+		{"-C", r.path, "add", "v1"},
+		{"-C", r.path, "commit", "-m", message},
+	}
+	if r.push {
+		commands = append(commands, []string{"-C", r.path, "push"})
+	}
+
+	for _, args := range commands {
+		result, err := r.runner.Run(ctx, "git", args...)
+		if err != nil {
+			return fmt.Errorf("%w: git %s: %w", ErrPublishFailed, args[2], err)
+		}
+		if !result.Success() {
+			return fmt.Errorf("%w: git %s: %s", ErrPublishFailed, args[2], result.Stderr)
+		}
+	}
+
+	return nil
+}
+
+// HTTPRegistry publishes through the registry HTTP API.
+type HTTPRegistry struct {
+	client *Client
+}
+
+// NewHTTPRegistry creates a registry that uploads through client.
+func NewHTTPRegistry(client *Client) *HTTPRegistry {
+	return &HTTPRegistry{client: client}
+}
+
+// Location returns the registry URL.
+func (r *HTTPRegistry) Location() string {
+	return r.client.config.RegistryURL
+}
+
+// Publish uploads the release.
+func (r *HTTPRegistry) Publish(ctx context.Context, release *Release) error {
+	return r.client.PublishPackage(ctx, release)
+}
+
+// Ensure implementations satisfy Registry.
+var (
+	_ Registry = (*GitRegistry)(nil)
+	_ Registry = (*HTTPRegistry)(nil)
+)
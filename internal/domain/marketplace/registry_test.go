@@ -0,0 +1,133 @@
+package marketplace
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/testutil/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRelease(version string) *Release {
+	archive := []byte("archive-" + version)
+	return &Release{
+		Package: Package{
+			ID:    MustNewPackageID("nvim-pro"),
+			Type:  PackageTypePreset,
+			Title: "Neovim Pro",
+			Versions: []PackageVersion{{
+				Version:  version,
+				Checksum: ComputeChecksum(archive),
+			}},
+		},
+		Archive: archive,
+	}
+}
+
+func TestGitRegistry_Publish(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	runner := mocks.NewCommandRunner()
+	ok := ports.CommandResult{ExitCode: 0}
+	runner.AddResult("git", []string{"-C", dir, "add", "v1"}, ok)
+	runner.AddResult("git", []string{"-C", dir, "commit", "-m", "Publish nvim-pro@1.0.0"}, ok)
+	runner.AddResult("git", []string{"-C", dir, "commit", "-m", "Publish nvim-pro@1.1.0"}, ok)
+
+	registry := NewGitRegistry(dir, runner).WithPush(false)
+	assert.Equal(t, dir, registry.Location())
+
+	require.NoError(t, registry.Publish(context.Background(), testRelease("1.0.0")))
+	require.NoError(t, registry.Publish(context.Background(), testRelease("1.1.0")))
+
+	data, err := os.ReadFile(filepath.Join(dir, "v1", "index.json"))
+	require.NoError(t, err)
+	idx, err := ParseIndex(data)
+	require.NoError(t, err)
+
+	pkg, found := idx.Get(MustNewPackageID("nvim-pro"))
+	require.True(t, found)
+	require.Len(t, pkg.Versions, 2)
+	latest, _ := pkg.LatestVersion()
+	assert.Equal(t, "1.1.0", latest.Version)
+
+	archive, err := os.ReadFile(filepath.Join(dir, "v1", "packages", "nvim-pro", "1.1.0.tar.gz"))
+	require.NoError(t, err)
+	assert.Equal(t, "archive-1.1.0", string(archive))
+
+	err = registry.Publish(context.Background(), testRelease("1.1.0"))
+	require.ErrorIs(t, err, ErrVersionExists)
+}
+
+func TestGitRegistry_Publish_GitFailure(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	runner := mocks.NewCommandRunner()
+	runner.AddResult("git", []string{"-C", dir, "add", "v1"}, ports.CommandResult{ExitCode: 128, Stderr: "not a git repository"})
+
+	err := NewGitRegistry(dir, runner).Publish(context.Background(), testRelease("1.0.0"))
+	require.ErrorIs(t, err, ErrPublishFailed)
+	assert.Contains(t, err.Error(), "not a git repository")
+}
+
+func TestHTTPRegistry_Publish(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/v1/packages/nvim-pro/1.0.0", r.URL.Path)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+		var body publishRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "nvim-pro", body.Package.ID.String())
+		assert.NotEmpty(t, body.Archive)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client := NewClient(ClientConfig{RegistryURL: server.URL, Timeout: 10 * time.Second, AuthToken: "secret"})
+	registry := NewHTTPRegistry(client)
+	assert.Equal(t, server.URL, registry.Location())
+	require.NoError(t, registry.Publish(context.Background(), testRelease("1.0.0")))
+}
+
+func TestHTTPRegistry_Publish_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		status int
+		want   error
+	}{
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusConflict, ErrVersionExists},
+		{http.StatusTooManyRequests, ErrRateLimited},
+		{http.StatusBadRequest, ErrPublishFailed},
+	}
+	for _, tt := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(tt.status)
+		}))
+		client := NewClient(ClientConfig{RegistryURL: server.URL, Timeout: 10 * time.Second})
+		err := NewHTTPRegistry(client).Publish(context.Background(), testRelease("1.0.0"))
+		assert.ErrorIs(t, err, tt.want, "status %d", tt.status)
+		server.Close()
+	}
+}
+
+func TestIndex_AddRelease_RejectsMultipleVersions(t *testing.T) {
+	t.Parallel()
+
+	pkg := testRelease("1.0.0").Package
+	pkg.Versions = append(pkg.Versions, PackageVersion{Version: "0.9.0"})
+	require.ErrorIs(t, NewIndex().AddRelease(pkg), ErrInvalidPackage)
+}
@@ -4,6 +4,7 @@ import (
 	"archive/tar"
 	"compress/gzip"
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"io"
@@ -11,6 +12,9 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/catalog"
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
 )
 
 // Service errors.
@@ -20,6 +24,7 @@ var (
 	ErrInstallFailed    = errors.New("installation failed")
 	ErrScanBlocked      = errors.New("security scan blocked installation")
 	ErrScanFailed       = errors.New("security scan failed")
+	ErrKeylessFailed    = errors.New("keyless signature verification failed")
 )
 
 // ServiceConfig configures the marketplace service.
@@ -32,13 +37,16 @@ type ServiceConfig struct {
 	ClientConfig ClientConfig
 	// OfflineMode disables network access
 	OfflineMode bool
+	// PreflightVersion is the running preflight version, checked against
+	// each package's min_preflight_version. Empty disables the check.
+	PreflightVersion string
 }
 
 // DefaultServiceConfig returns sensible defaults.
 func DefaultServiceConfig() ServiceConfig {
-	homeDir, _ := os.UserHomeDir()
+	installPath, _ := platform.Path(platform.DataDir, "marketplace", "installed")
 	return ServiceConfig{
-		InstallPath:  filepath.Join(homeDir, ".preflight", "marketplace", "installed"),
+		InstallPath:  installPath,
 		CacheConfig:  DefaultCacheConfig(),
 		ClientConfig: DefaultClientConfig(),
 		OfflineMode:  false,
@@ -51,6 +59,7 @@ type Service struct {
 	client  *Client
 	cache   *Cache
 	scanner *Scanner
+	keyless *catalog.SigstoreVerifier
 }
 
 // NewService creates a new marketplace service.
@@ -68,6 +77,14 @@ func (s *Service) WithScanner(scanner *Scanner) *Service {
 	return s
 }
 
+// WithKeylessVerifier sets the verifier for the keyless Sigstore signatures
+// of packages. Signed packages are verified on install and refused when
+// the signature does not verify.
+func (s *Service) WithKeylessVerifier(verifier *catalog.SigstoreVerifier) *Service {
+	s.keyless = verifier
+	return s
+}
+
 // Search finds packages matching the query.
 func (s *Service) Search(ctx context.Context, query string) ([]Package, error) {
 	idx, err := s.getIndex(ctx)
@@ -136,6 +153,11 @@ func (s *Service) Install(ctx context.Context, id PackageID, version string) (*I
 		pkgVersion = v
 	}
 
+	if !pkgVersion.IsCompatibleWith(s.config.PreflightVersion) {
+		return nil, fmt.Errorf("%w: %s@%s needs preflight %s or later",
+			ErrIncompatibleVersion, id, pkgVersion.Version, pkgVersion.MinVersion)
+	}
+
 	// Download package
 	data, err := s.downloadPackage(ctx, id, pkgVersion.Version)
 	if err != nil {
@@ -147,6 +169,17 @@ func (s *Service) Install(ctx context.Context, id PackageID, version string) (*I
 		return nil, err
 	}
 
+	provenance := pkg.Provenance
+	if s.keyless != nil && pkgVersion.SigstoreBundle != "" {
+		signer, err := s.verifyKeyless(data, pkgVersion)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s@%s: %w", ErrKeylessFailed, id, pkgVersion.Version, err)
+		}
+		provenance.Verified = true
+		provenance.SignedBy = signer.String()
+		provenance.SignedAt = signer.SignedAt
+	}
+
 	// Security scan (after checksum, before extraction)
 	var scanStatus ScanStatus
 	if s.scanner != nil {
@@ -175,6 +208,7 @@ func (s *Service) Install(ctx context.Context, id PackageID, version string) (*I
 		AutoUpdate:  false,
 		ScanStatus:  scanStatus,
 	}
+	installed.Package.Provenance = provenance
 
 	if err := s.cache.AddInstalled(installed); err != nil {
 		return nil, err
@@ -183,6 +217,56 @@ func (s *Service) Install(ctx context.Context, id PackageID, version string) (*I
 	return &installed, nil
 }
 
+// verifyKeyless verifies the keyless signature of a package archive.
+func (s *Service) verifyKeyless(archive []byte, version PackageVersion) (*catalog.KeylessSigner, error) {
+	data, err := base64.StdEncoding.DecodeString(version.SigstoreBundle)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", catalog.ErrSigstoreInvalidBundle, err)
+	}
+	bundle, err := catalog.ParseKeylessBundle(data)
+	if err != nil {
+		return nil, err
+	}
+	return s.keyless.VerifyBundle(archive, bundle)
+}
+
+// ResolveInstall resolves a package and its dependencies into an install plan
+// without changing anything on disk.
+func (s *Service) ResolveInstall(ctx context.Context, id PackageID, version string) (*InstallPlan, error) {
+	idx, err := s.getIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	installed, err := s.cache.GetInstalled()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewDependencyResolver(idx, installed).
+		WithPreflightVersion(s.config.PreflightVersion).
+		Resolve(id, version)
+}
+
+// InstallPlan installs every package in a plan that is not already present,
+// dependencies first. Packages installed before a failure are left in place.
+func (s *Service) InstallPlan(ctx context.Context, plan *InstallPlan) ([]InstalledPackage, error) {
+	var installed []InstalledPackage
+	for _, planned := range plan.Changes() {
+		if planned.Action == ActionUpgrade {
+			if err := s.Uninstall(planned.Package.ID); err != nil {
+				return installed, err
+			}
+		}
+		inst, err := s.Install(ctx, planned.Package.ID, planned.Version.Version)
+		if err != nil {
+			return installed, fmt.Errorf("%s@%s: %w", planned.Package.ID, planned.Version.Version, err)
+		}
+		installed = append(installed, *inst)
+	}
+	return installed, nil
+}
+
 // Uninstall removes an installed package.
 func (s *Service) Uninstall(id PackageID) error {
 	pkg, found, err := s.cache.GetInstalledPackage(id)
@@ -303,6 +387,49 @@ func (s *Service) CheckUpdates(ctx context.Context) ([]UpdateInfo, error) {
 	return updates, nil
 }
 
+// Rate records the user's 1-5 rating for a package locally. When submit is
+// true the rating is also sent to the registry; nothing leaves the machine
+// otherwise. A failed submission still keeps the local rating.
+func (s *Service) Rate(ctx context.Context, id PackageID, score int, submit bool) (*Rating, error) {
+	if err := ValidateRatingScore(score); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.Get(ctx, id); err != nil {
+		return nil, err
+	}
+
+	rating := Rating{
+		Package: id,
+		Score:   score,
+		RatedAt: time.Now(),
+	}
+	if err := s.cache.PutRating(rating); err != nil {
+		return nil, err
+	}
+
+	if !submit {
+		return &rating, nil
+	}
+	if s.config.OfflineMode {
+		return &rating, fmt.Errorf("%w: cannot submit rating in offline mode", ErrNetworkError)
+	}
+	if err := s.client.SubmitRating(ctx, id, score); err != nil {
+		return &rating, err
+	}
+
+	rating.Submitted = true
+	if err := s.cache.PutRating(rating); err != nil {
+		return nil, err
+	}
+	return &rating, nil
+}
+
+// LocalRating returns the user's own rating for a package, if any.
+func (s *Service) LocalRating(id PackageID) (Rating, bool, error) {
+	return s.cache.GetRating(id)
+}
+
 // UpdateInfo describes an available update.
 type UpdateInfo struct {
 	Package        Package
@@ -5,11 +5,14 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"encoding/base64"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/felixgeelhaar/preflight/internal/domain/catalog"
+	"github.com/felixgeelhaar/preflight/internal/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -817,3 +820,92 @@ func TestService_CheckUpdates_Empty(t *testing.T) {
 	require.NoError(t, err)
 	assert.Empty(t, updates)
 }
+
+func TestService_Install_KeylessSignature(t *testing.T) {
+	t.Parallel()
+
+	const (
+		issuer  = "https://token.actions.githubusercontent.com"
+		subject = "https://github.com/acme/presets/.github/workflows/release.yml@refs/tags/v1.0.0"
+	)
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	content := []byte("test file content")
+	_ = tw.WriteHeader(&tar.Header{Name: "config.yaml", Mode: 0o644, Size: int64(len(content))})
+	_, _ = tw.Write(content)
+	_ = tw.Close()
+	_ = gw.Close()
+	packageData := buf.Bytes()
+
+	fixture := testutil.NewSigstoreFixture(t)
+	root, err := catalog.ParseSigstoreTrustedRoot(fixture.TrustedRoot)
+	require.NoError(t, err)
+	identity := catalog.ExactSigstoreIdentity(issuer, subject)
+	identity.Name = "acme-presets"
+	verifier := catalog.NewSigstoreVerifier(catalog.SigstoreVerifierConfig{
+		TrustedRoot:       root,
+		TrustedIdentities: []catalog.SigstoreIdentity{identity},
+	})
+
+	newService := func(t *testing.T, bundle []byte) *Service {
+		t.Helper()
+		indexData := `{
+			"version": "1",
+			"packages": [
+				{
+					"id": "signed-test",
+					"type": "preset",
+					"title": "Signed Test",
+					"versions": [{
+						"version": "1.0.0",
+						"checksum": "` + ComputeChecksum(packageData) + `",
+						"sigstore_bundle": "` + base64.StdEncoding.EncodeToString(bundle) + `"
+					}]
+				}
+			]
+		}`
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/v1/index.json":
+				_, _ = w.Write([]byte(indexData))
+			case "/v1/packages/signed-test/1.0.0.tar.gz":
+				_, _ = w.Write(packageData)
+			}
+		}))
+		t.Cleanup(server.Close)
+
+		tmpDir := t.TempDir()
+		return NewService(ServiceConfig{
+			InstallPath:  tmpDir + "/installed",
+			CacheConfig:  CacheConfig{BasePath: tmpDir + "/cache", IndexTTL: time.Hour},
+			ClientConfig: ClientConfig{RegistryURL: server.URL, Timeout: 10 * time.Second},
+		}).WithKeylessVerifier(verifier)
+	}
+
+	t.Run("trusted identity", func(t *testing.T) {
+		t.Parallel()
+		service := newService(t, fixture.Sign(t, packageData, issuer, subject))
+		installed, err := service.Install(context.Background(), MustNewPackageID("signed-test"), "latest")
+		require.NoError(t, err)
+		assert.True(t, installed.Package.Provenance.Verified)
+		assert.Contains(t, installed.Package.Provenance.SignedBy, "acme-presets")
+		assert.False(t, installed.Package.Provenance.SignedAt.IsZero())
+	})
+
+	t.Run("untrusted identity", func(t *testing.T) {
+		t.Parallel()
+		service := newService(t, fixture.Sign(t, packageData, issuer, "https://github.com/evil/presets"))
+		_, err := service.Install(context.Background(), MustNewPackageID("signed-test"), "latest")
+		require.ErrorIs(t, err, ErrKeylessFailed)
+		require.ErrorIs(t, err, catalog.ErrSigstoreIdentityMismatch)
+	})
+
+	t.Run("signature of other content", func(t *testing.T) {
+		t.Parallel()
+		service := newService(t, fixture.Sign(t, []byte("other archive"), issuer, subject))
+		_, err := service.Install(context.Background(), MustNewPackageID("signed-test"), "latest")
+		require.ErrorIs(t, err, ErrKeylessFailed)
+	})
+}
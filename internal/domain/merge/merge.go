@@ -62,16 +62,13 @@ func NewConflictResult(content string, conflicts []Conflict) Result {
 	}
 }
 
-// ThreeWayMerge performs a three-way merge.
+// ThreeWayMerge performs a line-based three-way merge.
 // - base: The original content (what was last applied)
 // - ours: Our content (from config)
 // - theirs: Their content (current file with user modifications)
 //
-// For v1.3 MVP, this uses a simple strategy:
-// - If base == ours: file changed only, use theirs
-// - If base == theirs: config changed only, use ours
-// - If ours == theirs: both made same change, use either
-// - Otherwise: generate conflict markers for the entire file
+// Changes to different parts of the file are combined. Only regions that
+// both sides changed differently become conflicts, marked in the content.
 func ThreeWayMerge(base, ours, theirs string, style ConflictStyle) Result {
 	// Fast path: if base equals ours, user made all changes - use theirs
 	if base == ours {
@@ -88,22 +85,113 @@ func ThreeWayMerge(base, ours, theirs string, style ConflictStyle) Result {
 		return NewCleanResult(ours)
 	}
 
-	// Both sides diverged from base differently - create conflict
-	conflict := Conflict{
-		Start:  0,
-		Base:   splitLines(base),
-		Ours:   splitLines(ours),
-		Theirs: splitLines(theirs),
+	baseLines := splitLines(base)
+	oursLines := splitLines(ours)
+	theirsLines := splitLines(theirs)
+	oursMatch := matchLines(baseLines, oursLines)
+	theirsMatch := matchLines(baseLines, theirsLines)
+
+	var lines []string
+	var conflicts []Conflict
+	// merge resolves the region between two lines unchanged on both sides
+	merge := func(b, o, t []string) {
+		switch {
+		case sliceEqual(o, b):
+			lines = append(lines, t...)
+		case sliceEqual(t, b), sliceEqual(o, t):
+			lines = append(lines, o...)
+		default:
+			c := Conflict{Start: len(lines), Base: b, Ours: o, Theirs: t}
+			lines = append(lines, conflictLines(c, style)...)
+			c.End = len(lines) - 1
+			conflicts = append(conflicts, c)
+		}
+	}
+
+	i, o, t := 0, 0, 0
+	for k := range baseLines {
+		if oursMatch[k] < 0 || theirsMatch[k] < 0 {
+			continue
+		}
+		merge(baseLines[i:k], oursLines[o:oursMatch[k]], theirsLines[t:theirsMatch[k]])
+		lines = append(lines, baseLines[k])
+		i, o, t = k+1, oursMatch[k]+1, theirsMatch[k]+1
+	}
+	merge(baseLines[i:], oursLines[o:], theirsLines[t:])
+
+	content := strings.Join(lines, "\n")
+	if len(lines) > 0 && (strings.HasSuffix(ours, "\n") || strings.HasSuffix(theirs, "\n")) {
+		content += "\n"
+	}
+	if len(conflicts) == 0 {
+		return NewCleanResult(content)
+	}
+	return NewConflictResult(content, conflicts)
+}
+
+// maxMatchCells bounds the table used to match lines. Larger files fall
+// back to treating their differing middle as changed as a whole.
+const maxMatchCells = 1 << 22
+
+// matchLines matches the lines of a to a longest common subsequence of b.
+// It returns, for each line of a, the index of its match in b or -1.
+func matchLines(a, b []string) []int {
+	match := make([]int, len(a))
+	for i := range match {
+		match[i] = -1
 	}
 
-	content := formatWholeFileConflict(conflict, style)
-	conflict.End = len(splitLines(content)) - 1
+	// Common prefix and suffix need no table
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		match[prefix] = prefix
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		match[len(a)-1-suffix] = len(b) - 1 - suffix
+		suffix++
+	}
 
-	return NewConflictResult(content, []Conflict{conflict})
+	midA := a[prefix : len(a)-suffix]
+	midB := b[prefix : len(b)-suffix]
+	n, m := len(midA), len(midB)
+	if n == 0 || m == 0 || (n+1)*(m+1) > maxMatchCells {
+		return match
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of
+	// midA[i:] and midB[j:]
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if midA[i] == midB[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	for i, j := 0, 0; i < n && j < m; {
+		switch {
+		case midA[i] == midB[j]:
+			match[prefix+i] = prefix + j
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return match
 }
 
-// formatWholeFileConflict formats a whole-file conflict with markers.
-func formatWholeFileConflict(c Conflict, style ConflictStyle) string {
+// conflictLines formats a conflict with markers.
+func conflictLines(c Conflict, style ConflictStyle) []string {
 	var lines []string
 
 	lines = append(lines, "<<<<<<< ours (config)")
@@ -118,7 +206,7 @@ func formatWholeFileConflict(c Conflict, style ConflictStyle) string {
 	lines = append(lines, c.Theirs...)
 	lines = append(lines, ">>>>>>> theirs (file)")
 
-	return strings.Join(lines, "\n") + "\n"
+	return lines
 }
 
 // splitLines splits content into lines.
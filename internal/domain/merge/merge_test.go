@@ -85,6 +85,53 @@ func TestThreeWayMerge_Conflicts(t *testing.T) {
 	})
 }
 
+func TestThreeWayMerge_Lines(t *testing.T) {
+	t.Parallel()
+
+	t.Run("changes to different lines are combined", func(t *testing.T) {
+		t.Parallel()
+		base := "[user]\n\tname = John\n\temail = john@example.com\n[core]\n\teditor = vim\n"
+		ours := "[user]\n\tname = John\n\temail = john@work.com\n[core]\n\teditor = vim\n"
+		theirs := "[user]\n\tname = John\n\temail = john@example.com\n[core]\n\teditor = nvim\n[alias]\n\tst = status\n"
+
+		result := ThreeWayMerge(base, ours, theirs, StyleGit)
+
+		assert.True(t, result.CleanMerge)
+		assert.Equal(t, "[user]\n\tname = John\n\temail = john@work.com\n[core]\n\teditor = nvim\n[alias]\n\tst = status\n", result.Content)
+	})
+
+	t.Run("conflict covers only the lines both changed", func(t *testing.T) {
+		t.Parallel()
+		base := "a\nb\nc\nd\ne\n"
+		ours := "a\nB1\nc\nc2\nd\ne\n"
+		theirs := "a\nB2\nc\nd\nE\n"
+
+		result := ThreeWayMerge(base, ours, theirs, StyleGit)
+
+		require.Len(t, result.Conflicts, 1)
+		assert.Equal(t, "a\n<<<<<<< ours (config)\nB1\n=======\nB2\n>>>>>>> theirs (file)\nc\nc2\nd\nE\n", result.Content)
+		assert.Equal(t, Conflict{Start: 1, End: 5, Base: []string{"b"}, Ours: []string{"B1"}, Theirs: []string{"B2"}}, result.Conflicts[0])
+		assert.Equal(t, "a\nB1\nc\nc2\nd\nE\n", ResolveAllConflicts(result.Content, ResolveOurs))
+		assert.Equal(t, "a\nB2\nc\nc2\nd\nE\n", ResolveAllConflicts(result.Content, ResolveTheirs))
+	})
+
+	t.Run("missing trailing newline on both sides is kept", func(t *testing.T) {
+		t.Parallel()
+		result := ThreeWayMerge("a\nb\nc", "A\nb\nc", "a\nb\nC", StyleGit)
+
+		assert.True(t, result.CleanMerge)
+		assert.Equal(t, "A\nb\nC", result.Content)
+	})
+}
+
+func TestMatchLines(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []int{0, -1, 2, 3}, matchLines([]string{"a", "b", "c", "d"}, []string{"a", "x", "c", "d"}))
+	assert.Equal(t, []int{0, 2, -1}, matchLines([]string{"a", "b", "c"}, []string{"a", "y", "b"}))
+	assert.Equal(t, []int{-1, -1}, matchLines([]string{"a", "b"}, nil))
+}
+
 func TestThreeWayMerge_EdgeCases(t *testing.T) {
 	t.Parallel()
 
@@ -499,7 +546,7 @@ func TestChangeType_Description(t *testing.T) {
 	}
 }
 
-func TestFormatWholeFileConflict(t *testing.T) {
+func TestConflictLines(t *testing.T) {
 	t.Parallel()
 
 	conflict := Conflict{
@@ -510,7 +557,7 @@ func TestFormatWholeFileConflict(t *testing.T) {
 
 	t.Run("git style", func(t *testing.T) {
 		t.Parallel()
-		result := formatWholeFileConflict(conflict, StyleGit)
+		result := strings.Join(conflictLines(conflict, StyleGit), "\n")
 
 		assert.Contains(t, result, "<<<<<<< ours")
 		assert.Contains(t, result, "config")
@@ -522,7 +569,7 @@ func TestFormatWholeFileConflict(t *testing.T) {
 
 	t.Run("diff3 style", func(t *testing.T) {
 		t.Parallel()
-		result := formatWholeFileConflict(conflict, StyleDiff3)
+		result := strings.Join(conflictLines(conflict, StyleDiff3), "\n")
 
 		assert.Contains(t, result, "<<<<<<< ours")
 		assert.Contains(t, result, "||||||| base")
@@ -0,0 +1,303 @@
+package platform
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DirKind is a kind of preflight directory, after the XDG Base Directory
+// Specification.
+type DirKind string
+
+const (
+	// ConfigDir holds settings the user chose: trust, grants and consent.
+	ConfigDir DirKind = "config"
+	// DataDir holds what preflight installed or generated for the user to
+	// keep: plugins, shims, keys and exported profiles.
+	DataDir DirKind = "data"
+	// StateDir holds what preflight records as it runs: history,
+	// snapshots, backups, logs and locks.
+	StateDir DirKind = "state"
+	// CacheDir holds downloads that can be fetched again.
+	CacheDir DirKind = "cache"
+)
+
+// DirKinds lists the kinds of preflight directories.
+var DirKinds = []DirKind{ConfigDir, DataDir, StateDir, CacheDir}
+
+// HomeEnvVar puts every preflight directory in one directory. Relative
+// paths are rejected.
+const HomeEnvVar = "PREFLIGHT_HOME"
+
+// xdg maps each kind to its XDG variable and default below the home
+// directory.
+var xdg = map[DirKind]struct{ env, fallback string }{
+	ConfigDir: {"XDG_CONFIG_HOME", ".config"},
+	DataDir:   {"XDG_DATA_HOME", filepath.Join(".local", "share")},
+	StateDir:  {"XDG_STATE_HOME", filepath.Join(".local", "state")},
+	CacheDir:  {"XDG_CACHE_HOME", ".cache"},
+}
+
+// ErrRelativeHome is returned when PREFLIGHT_HOME is not an absolute path.
+var ErrRelativeHome = errors.New(HomeEnvVar + " must be an absolute path")
+
+// LegacyDir returns ~/.preflight, where every preflight directory lived
+// before the XDG layout.
+func LegacyDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".preflight"), nil
+}
+
+// XDGDir returns the XDG directory of kind, such as
+// $XDG_STATE_HOME/preflight or ~/.local/state/preflight.
+func XDGDir(kind DirKind) (string, error) {
+	spec, ok := xdg[kind]
+	if !ok {
+		return "", fmt.Errorf("unknown directory kind %q", kind)
+	}
+	// The spec says to ignore relative paths
+	if base := os.Getenv(spec.env); filepath.IsAbs(base) {
+		return filepath.Join(base, "preflight"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, spec.fallback, "preflight"), nil
+}
+
+// Dir returns the preflight directory of kind: PREFLIGHT_HOME when set,
+// ~/.preflight while it has not been migrated, and the XDG directory of
+// kind otherwise.
+func Dir(kind DirKind) (string, error) {
+	if explicit := os.Getenv(HomeEnvVar); explicit != "" {
+		if !filepath.IsAbs(explicit) {
+			return "", ErrRelativeHome
+		}
+		return explicit, nil
+	}
+	if legacy, err := LegacyDir(); err == nil && usesLegacy(legacy) {
+		return legacy, nil
+	}
+	return XDGDir(kind)
+}
+
+// Path returns elem joined to the preflight directory of kind.
+func Path(kind DirKind, elem ...string) (string, error) {
+	dir, err := Dir(kind)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(append([]string{dir}, elem...)...), nil
+}
+
+// usesLegacy reports whether ~/.preflight is still in use: it exists and
+// the XDG config directory, which migration creates, does not.
+func usesLegacy(legacy string) bool {
+	if _, err := os.Stat(legacy); err != nil {
+		return false
+	}
+	config, err := XDGDir(ConfigDir)
+	if err != nil {
+		return true
+	}
+	_, err = os.Stat(config)
+	return err != nil
+}
+
+// legacyKinds sorts the entries of ~/.preflight into the XDG directories.
+// Entries not listed here are data. Nested entries use forward slashes.
+var legacyKinds = map[string]DirKind{
+	"allowed_signers":       ConfigDir,
+	"machine_id":            ConfigDir,
+	"notices.json":          ConfigDir,
+	"plugin-grants.json":    ConfigDir,
+	"profiles":              ConfigDir,
+	"telemetry-fired.jsonl": ConfigDir,
+	"telemetry.jsonl":       ConfigDir,
+	"telemetry.yaml":        ConfigDir,
+	"trust.json":            ConfigDir,
+	"agent.lock":            StateDir,
+	"agent.log":             StateDir,
+	"applied":               StateDir,
+	"audit":                 StateDir,
+	"backups":               StateDir,
+	"env.fish":              StateDir,
+	"env.sh":                StateDir,
+	"fingerprint.json":      StateDir,
+	"history":               StateDir,
+	"logs":                  StateDir,
+	"machine-id":            StateDir,
+	"onboarding.json":       StateDir,
+	"patches":               StateDir,
+	"run.lock":              StateDir,
+	"snapshots":             StateDir,
+	"state.json":            StateDir,
+	"tour-progress.json":    StateDir,
+	"kb":                    CacheDir,
+	"marketplace/cache":     CacheDir,
+	"marketplace/installed": DataDir,
+	"catalogs":              DataDir,
+	"identity":              DataDir,
+	"plugins":               DataDir,
+	"secrets":               DataDir,
+	"shims":                 DataDir,
+	"sync":                  DataDir,
+	"vscode":                DataDir,
+}
+
+// Move is an entry moved from ~/.preflight by MigrateLegacy.
+type Move struct {
+	From string
+	To   string
+	Kind DirKind
+}
+
+// ErrAgentRunning is returned by MigrateLegacy while the agent, which
+// holds files in ~/.preflight open, is running.
+var ErrAgentRunning = errors.New("the preflight agent is running")
+
+// MigrateLegacy moves the entries of ~/.preflight into the XDG
+// directories and removes it. It does nothing when PREFLIGHT_HOME is set
+// or ~/.preflight is not in use. Either every entry moves or, on error,
+// none does.
+func MigrateLegacy() ([]Move, error) {
+	if os.Getenv(HomeEnvVar) != "" {
+		return nil, nil
+	}
+	legacy, err := LegacyDir()
+	if err != nil || !usesLegacy(legacy) {
+		return nil, nil
+	}
+	if _, err := os.Stat(filepath.Join(legacy, "agent.sock")); err == nil {
+		return nil, ErrAgentRunning
+	}
+
+	plan, err := legacyMoves(legacy)
+	if err != nil {
+		return nil, err
+	}
+	var renames []Move
+	for _, m := range plan {
+		if err := moveEntry(m.From, m.To, &renames); err != nil {
+			for i := len(renames) - 1; i >= 0; i-- {
+				_ = os.Rename(renames[i].To, renames[i].From)
+			}
+			for _, kind := range DirKinds {
+				if dir, err := XDGDir(kind); err == nil {
+					removeEmptyDirs(dir)
+				}
+			}
+			return nil, fmt.Errorf("failed to move %s to %s: %w", m.From, m.To, err)
+		}
+	}
+
+	// The config directory marks the migration as done, even when
+	// nothing was moved into it
+	config, err := XDGDir(ConfigDir)
+	if err != nil {
+		return plan, err
+	}
+	if err := os.MkdirAll(config, 0o700); err != nil {
+		return plan, fmt.Errorf("failed to create %s: %w", config, err)
+	}
+	removeEmptyDirs(legacy)
+	return plan, nil
+}
+
+// legacyMoves returns the moves of the entries of legacy, nested entries
+// such as marketplace/cache before their parents.
+func legacyMoves(legacy string) ([]Move, error) {
+	var names []string
+	for name := range legacyKinds {
+		if strings.Contains(name, "/") {
+			names = append(names, name)
+		}
+	}
+	entries, err := os.ReadDir(legacy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", legacy, err)
+	}
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	sort.SliceStable(names, func(i, j int) bool {
+		return strings.Count(names[i], "/") > strings.Count(names[j], "/")
+	})
+
+	var moves []Move
+	for _, name := range names {
+		from := filepath.Join(legacy, filepath.FromSlash(name))
+		if _, err := os.Lstat(from); err != nil {
+			continue
+		}
+		kind, ok := legacyKinds[name]
+		if !ok {
+			kind = DataDir
+		}
+		dir, err := XDGDir(kind)
+		if err != nil {
+			return nil, err
+		}
+		moves = append(moves, Move{From: from, To: filepath.Join(dir, filepath.FromSlash(name)), Kind: kind})
+	}
+	return moves, nil
+}
+
+// moveEntry renames from to to, recording each rename. A directory is
+// merged into an existing directory entry by entry.
+func moveEntry(from, to string, renames *[]Move) error {
+	fromInfo, err := os.Lstat(from)
+	if err != nil {
+		return nil
+	}
+	if entries, err := os.ReadDir(from); err == nil && len(entries) == 0 {
+		// Emptied by the moves of its nested entries
+		return nil
+	}
+	if toInfo, err := os.Lstat(to); err == nil {
+		if !fromInfo.IsDir() || !toInfo.IsDir() {
+			return fmt.Errorf("%s already exists", to)
+		}
+		entries, err := os.ReadDir(from)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := moveEntry(filepath.Join(from, entry.Name()), filepath.Join(to, entry.Name()), renames); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(to), 0o700); err != nil {
+		return err
+	}
+	if err := os.Rename(from, to); err != nil {
+		return err
+	}
+	*renames = append(*renames, Move{From: from, To: to})
+	return nil
+}
+
+// removeEmptyDirs removes dir and the empty directories in it.
+func removeEmptyDirs(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			removeEmptyDirs(filepath.Join(dir, entry.Name()))
+		}
+	}
+	_ = os.Remove(dir)
+}
@@ -0,0 +1,184 @@
+package platform
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setHome points the preflight directories at a fresh home directory.
+func setHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(HomeEnvVar, "")
+	for _, spec := range xdg {
+		t.Setenv(spec.env, "")
+	}
+	return home
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func TestDir_XDGDefaults(t *testing.T) {
+	home := setHome(t)
+
+	want := map[DirKind]string{
+		ConfigDir: filepath.Join(home, ".config", "preflight"),
+		DataDir:   filepath.Join(home, ".local", "share", "preflight"),
+		StateDir:  filepath.Join(home, ".local", "state", "preflight"),
+		CacheDir:  filepath.Join(home, ".cache", "preflight"),
+	}
+	for kind, path := range want {
+		dir, err := Dir(kind)
+		require.NoError(t, err)
+		assert.Equal(t, path, dir, kind)
+	}
+}
+
+func TestDir_XDGEnv(t *testing.T) {
+	home := setHome(t)
+	t.Setenv("XDG_STATE_HOME", "/var/xdg/state")
+	t.Setenv("XDG_CACHE_HOME", "relative/cache")
+
+	dir, err := Dir(StateDir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("/var/xdg/state", "preflight"), dir)
+
+	// Relative XDG paths are ignored
+	dir, err = Dir(CacheDir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, ".cache", "preflight"), dir)
+}
+
+func TestDir_PreflightHome(t *testing.T) {
+	setHome(t)
+	t.Setenv(HomeEnvVar, "/srv/preflight")
+
+	for _, kind := range DirKinds {
+		dir, err := Dir(kind)
+		require.NoError(t, err)
+		assert.Equal(t, "/srv/preflight", dir)
+	}
+
+	t.Setenv(HomeEnvVar, "relative")
+	_, err := Dir(ConfigDir)
+	assert.ErrorIs(t, err, ErrRelativeHome)
+}
+
+func TestDir_Legacy(t *testing.T) {
+	home := setHome(t)
+	legacy := filepath.Join(home, ".preflight")
+	require.NoError(t, os.Mkdir(legacy, 0o700))
+
+	for _, kind := range DirKinds {
+		dir, err := Dir(kind)
+		require.NoError(t, err)
+		assert.Equal(t, legacy, dir)
+	}
+
+	// Once the XDG config directory exists, ~/.preflight is no longer used
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".config", "preflight"), 0o700))
+	dir, err := Dir(StateDir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, ".local", "state", "preflight"), dir)
+}
+
+func TestPath(t *testing.T) {
+	home := setHome(t)
+
+	path, err := Path(DataDir, "sync", "identity.txt")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, ".local", "share", "preflight", "sync", "identity.txt"), path)
+}
+
+func TestMigrateLegacy(t *testing.T) {
+	home := setHome(t)
+	legacy := filepath.Join(home, ".preflight")
+	writeFile(t, filepath.Join(legacy, "trust.json"), "{}")
+	writeFile(t, filepath.Join(legacy, "history", "a.json"), "a")
+	writeFile(t, filepath.Join(legacy, "kb", "kb.json"), "kb")
+	writeFile(t, filepath.Join(legacy, "marketplace", "cache", "index.json"), "index")
+	writeFile(t, filepath.Join(legacy, "marketplace", "installed", "p", "plugin.yaml"), "p")
+	writeFile(t, filepath.Join(legacy, "signing.key"), "key")
+	// Merged into an existing directory
+	writeFile(t, filepath.Join(home, ".local", "state", "preflight", "history", "b.json"), "b")
+
+	moves, err := MigrateLegacy()
+	require.NoError(t, err)
+	assert.NotEmpty(t, moves)
+
+	want := map[string]string{
+		filepath.Join(home, ".config", "preflight", "trust.json"):                                           "{}",
+		filepath.Join(home, ".local", "state", "preflight", "history", "a.json"):                            "a",
+		filepath.Join(home, ".local", "state", "preflight", "history", "b.json"):                            "b",
+		filepath.Join(home, ".cache", "preflight", "kb", "kb.json"):                                         "kb",
+		filepath.Join(home, ".cache", "preflight", "marketplace", "cache", "index.json"):                    "index",
+		filepath.Join(home, ".local", "share", "preflight", "marketplace", "installed", "p", "plugin.yaml"): "p",
+		filepath.Join(home, ".local", "share", "preflight", "signing.key"):                                  "key",
+	}
+	for path, content := range want {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err, path)
+		assert.Equal(t, content, string(data), path)
+	}
+	assert.NoDirExists(t, legacy)
+
+	dir, err := Dir(ConfigDir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, ".config", "preflight"), dir)
+
+	// Nothing left to do
+	moves, err = MigrateLegacy()
+	require.NoError(t, err)
+	assert.Empty(t, moves)
+}
+
+func TestMigrateLegacy_RollsBackOnCollision(t *testing.T) {
+	home := setHome(t)
+	legacy := filepath.Join(home, ".preflight")
+	writeFile(t, filepath.Join(legacy, "history", "a.json"), "a")
+	writeFile(t, filepath.Join(legacy, "snapshots", "s.json"), "legacy")
+	writeFile(t, filepath.Join(home, ".local", "state", "preflight", "snapshots", "s.json"), "xdg")
+
+	_, err := MigrateLegacy()
+	require.Error(t, err)
+
+	// Every entry is back in ~/.preflight, which stays in use
+	assert.FileExists(t, filepath.Join(legacy, "history", "a.json"))
+	assert.FileExists(t, filepath.Join(legacy, "snapshots", "s.json"))
+	assert.NoDirExists(t, filepath.Join(home, ".local", "state", "preflight", "history"))
+	dir, err := Dir(StateDir)
+	require.NoError(t, err)
+	assert.Equal(t, legacy, dir)
+}
+
+func TestMigrateLegacy_AgentRunning(t *testing.T) {
+	home := setHome(t)
+	legacy := filepath.Join(home, ".preflight")
+	writeFile(t, filepath.Join(legacy, "agent.sock"), "")
+	writeFile(t, filepath.Join(legacy, "trust.json"), "{}")
+
+	_, err := MigrateLegacy()
+	require.ErrorIs(t, err, ErrAgentRunning)
+	assert.FileExists(t, filepath.Join(legacy, "trust.json"))
+}
+
+func TestMigrateLegacy_PreflightHome(t *testing.T) {
+	home := setHome(t)
+	legacy := filepath.Join(home, ".preflight")
+	writeFile(t, filepath.Join(legacy, "trust.json"), "{}")
+	t.Setenv(HomeEnvVar, filepath.Join(home, "pf"))
+
+	moves, err := MigrateLegacy()
+	require.NoError(t, err)
+	assert.Empty(t, moves)
+	assert.FileExists(t, filepath.Join(legacy, "trust.json"))
+}
@@ -0,0 +1,198 @@
+package plugin
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// maxArchiveSize limits plugin archive downloads (100MB).
+	maxArchiveSize int64 = 100 * 1024 * 1024
+	// archiveTimeout bounds a plugin archive download.
+	archiveTimeout = 5 * time.Minute
+)
+
+// ErrArchiveChecksumRequired indicates a plugin archive was installed
+// without the checksum it must be verified against.
+var ErrArchiveChecksumRequired = errors.New("plugin archives require a checksum (sha256:<hex>)")
+
+// IsArchiveSource reports whether source is the URL of a plugin archive
+// (.tar.gz or .tgz) rather than a Git repository.
+func IsArchiveSource(source string) bool {
+	u, err := url.Parse(source)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return false
+	}
+	return strings.HasSuffix(u.Path, ".tar.gz") || strings.HasSuffix(u.Path, ".tgz")
+}
+
+// LoadFromArchive downloads a plugin archive, verifies it against checksum
+// and installs it into the plugins directory under its manifest name. The
+// checksum is mandatory: an archive is never extracted before its SHA256
+// hash matches. An already installed plugin of the same name is loaded
+// instead of being replaced.
+func (l *Loader) LoadFromArchive(ctx context.Context, archiveURL, checksum string) (*Plugin, error) {
+	if checksum == "" {
+		return nil, ErrArchiveChecksumRequired
+	}
+	if !IsArchiveSource(archiveURL) {
+		return nil, &InvalidURLError{URL: archiveURL, Reason: "not an http(s) URL of a .tar.gz or .tgz archive"}
+	}
+
+	data, err := downloadArchive(ctx, archiveURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := VerifyChecksum(data, trimChecksumAlgorithm(checksum)); err != nil {
+		return nil, fmt.Errorf("verifying %s: %w", archiveURL, err)
+	}
+
+	pluginsDir, err := EnsureInstallPath()
+	if err != nil {
+		return nil, err
+	}
+	staging, err := os.MkdirTemp(pluginsDir, ".archive-")
+	if err != nil {
+		return nil, fmt.Errorf("creating staging directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(staging) }()
+
+	if err := extractArchive(data, staging); err != nil {
+		return nil, fmt.Errorf("extracting %s: %w", archiveURL, err)
+	}
+	root, err := archiveRoot(staging)
+	if err != nil {
+		return nil, err
+	}
+	p, err := l.LoadFromPath(root)
+	if err != nil {
+		return nil, err
+	}
+	if err := validatePluginName(p.Manifest.Name); err != nil {
+		return nil, err
+	}
+
+	installPath := filepath.Join(pluginsDir, p.Manifest.Name)
+	if _, err := os.Stat(installPath); err == nil {
+		return l.LoadFromPath(installPath)
+	}
+	if err := os.Rename(root, installPath); err != nil {
+		return nil, fmt.Errorf("installing plugin: %w", err)
+	}
+	return l.LoadFromPath(installPath)
+}
+
+// trimChecksumAlgorithm strips an optional "sha256:" prefix.
+func trimChecksumAlgorithm(checksum string) string {
+	if len(checksum) > len("sha256:") && strings.EqualFold(checksum[:len("sha256:")], "sha256:") {
+		return checksum[len("sha256:"):]
+	}
+	return checksum
+}
+
+// downloadArchive fetches a plugin archive, refusing archives larger than
+// maxArchiveSize.
+func downloadArchive(ctx context.Context, archiveURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, archiveURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	client := &http.Client{Timeout: archiveTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("downloading %s: %w", archiveURL, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("downloading %s: unexpected status %d", archiveURL, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxArchiveSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("downloading %s: %w", archiveURL, err)
+	}
+	if int64(len(data)) > maxArchiveSize {
+		return nil, fmt.Errorf("downloading %s: archive exceeds %d bytes", archiveURL, maxArchiveSize)
+	}
+	return data, nil
+}
+
+// extractArchive extracts the regular files and directories of a tar.gz
+// archive into dir. Entries escaping dir are rejected and links are skipped.
+func extractArchive(data []byte, dir string) error {
+	gr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = gr.Close() }()
+
+	tr := tar.NewReader(gr)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dir, filepath.Clean(header.Name))
+		rel, err := filepath.Rel(dir, target)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return &PathTraversalError{Path: header.Name}
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o700); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o700); err != nil {
+				return err
+			}
+			// #nosec G304 G110 -- target is confined to dir and the archive size is bounded.
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, header.FileInfo().Mode().Perm()&0o755)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(f, tr); err != nil {
+				_ = f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// archiveRoot returns the directory holding plugin.yaml: the archive root,
+// or the single top-level directory release archives usually wrap it in.
+func archiveRoot(dir string) (string, error) {
+	if _, err := os.Stat(filepath.Join(dir, "plugin.yaml")); err == nil {
+		return dir, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) == 1 && entries[0].IsDir() {
+		root := filepath.Join(dir, entries[0].Name())
+		if _, err := os.Stat(filepath.Join(root, "plugin.yaml")); err == nil {
+			return root, nil
+		}
+	}
+	return "", ErrManifestNotFound
+}
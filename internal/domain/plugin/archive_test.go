@@ -0,0 +1,109 @@
+package plugin
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const archiveManifest = `apiVersion: v1
+name: docker
+version: 1.0.0
+provides:
+  providers:
+    - name: docker
+      configKey: docker
+`
+
+// buildArchive returns a tar.gz of the given files.
+func buildArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+	return buf.Bytes()
+}
+
+func serveArchive(t *testing.T, data []byte) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL + "/preflight-docker-1.0.0.tar.gz"
+}
+
+func archiveChecksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func TestIsArchiveSource(t *testing.T) {
+	assert.True(t, IsArchiveSource("https://example.com/releases/plugin-1.0.0.tar.gz"))
+	assert.True(t, IsArchiveSource("https://example.com/plugin.tgz?download=1"))
+	assert.False(t, IsArchiveSource("https://github.com/example/preflight-docker.git"))
+	assert.False(t, IsArchiveSource("/path/to/plugin.tar.gz"))
+}
+
+func TestLoader_LoadFromArchive(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_DATA_HOME", "")
+
+	data := buildArchive(t, map[string]string{"preflight-docker/plugin.yaml": archiveManifest})
+	p, err := NewLoader().LoadFromArchive(context.Background(), serveArchive(t, data), archiveChecksum(data))
+	require.NoError(t, err)
+	assert.Equal(t, "docker", p.Manifest.Name)
+
+	pluginsDir, err := InstallPath()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(pluginsDir, "docker"), p.Path)
+	assert.FileExists(t, filepath.Join(pluginsDir, "docker", "plugin.yaml"))
+}
+
+func TestLoader_LoadFromArchive_ChecksumRequired(t *testing.T) {
+	_, err := NewLoader().LoadFromArchive(context.Background(), "https://example.com/plugin.tar.gz", "")
+	require.ErrorIs(t, err, ErrArchiveChecksumRequired)
+}
+
+func TestLoader_LoadFromArchive_ChecksumMismatch(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", "")
+
+	data := buildArchive(t, map[string]string{"plugin.yaml": archiveManifest})
+	other := archiveChecksum([]byte("other"))
+	_, err := NewLoader().LoadFromArchive(context.Background(), serveArchive(t, data), other)
+	require.True(t, IsChecksumError(err), "expected checksum error, got %v", err)
+
+	pluginsDir, err := InstallPath()
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join(pluginsDir, "docker"))
+	assert.True(t, os.IsNotExist(err), "an unverified archive must not be installed")
+}
+
+func TestLoader_LoadFromArchive_PathTraversal(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_DATA_HOME", "")
+
+	data := buildArchive(t, map[string]string{"../escape/plugin.yaml": archiveManifest})
+	_, err := NewLoader().LoadFromArchive(context.Background(), serveArchive(t, data), archiveChecksum(data))
+	require.True(t, IsPathTraversal(err), "expected path traversal error, got %v", err)
+}
@@ -0,0 +1,385 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
+)
+
+// ErrUnknownCapability is returned when granting a capability the plugin does not request.
+var ErrUnknownCapability = errors.New("capability not requested by plugin")
+
+// Grant records the user's capability decisions for one plugin version.
+// Decisions are tied to the version so an upgrade that changes what a
+// capability is used for asks again.
+type Grant struct {
+	Plugin    string    `json:"plugin"`
+	Version   string    `json:"version"`
+	Granted   []string  `json:"granted,omitempty"`
+	Denied    []string  `json:"denied,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Decision reports whether a capability was granted and whether the user decided at all.
+func (g Grant) Decision(capability string) (granted, decided bool) {
+	for _, c := range g.Granted {
+		if c == capability {
+			return true, true
+		}
+	}
+	for _, c := range g.Denied {
+		if c == capability {
+			return false, true
+		}
+	}
+	return false, false
+}
+
+// IsGranted returns true if the capability was granted.
+func (g Grant) IsGranted(capability string) bool {
+	granted, _ := g.Decision(capability)
+	return granted
+}
+
+// set records a decision, replacing any earlier one for the same capability.
+func (g *Grant) set(capability string, granted bool) {
+	g.Granted = removeString(g.Granted, capability)
+	g.Denied = removeString(g.Denied, capability)
+	if granted {
+		g.Granted = append(g.Granted, capability)
+		sort.Strings(g.Granted)
+	} else {
+		g.Denied = append(g.Denied, capability)
+		sort.Strings(g.Denied)
+	}
+}
+
+// RequiresConsent returns true if the user must approve a capability before
+// a plugin may use it. Command execution, network access and file writes
+// need consent; read-only capabilities are granted with installation.
+func RequiresConsent(capability string) bool {
+	return DangerousCapabilities[capability]
+}
+
+// DefaultGrantsPath returns the path of the user's grants file.
+func DefaultGrantsPath() string {
+	path, err := platform.Path(platform.ConfigDir, "plugin-grants.json")
+	if err != nil {
+		return filepath.Join(".preflight", "plugin-grants.json")
+	}
+	return path
+}
+
+// GrantStore persists capability grants in a JSON file.
+type GrantStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewGrantStore creates a grant store backed by the given file.
+func NewGrantStore(path string) *GrantStore {
+	return &GrantStore{path: path}
+}
+
+// Path returns the grants file location.
+func (s *GrantStore) Path() string {
+	return s.path
+}
+
+// List returns all stored grants ordered by plugin and version.
+func (s *GrantStore) List() ([]Grant, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load()
+}
+
+// Get returns the grant for a plugin version.
+func (s *GrantStore) Get(plugin, version string) (Grant, bool, error) {
+	grants, err := s.List()
+	if err != nil {
+		return Grant{}, false, err
+	}
+	for _, g := range grants {
+		if g.Plugin == plugin && g.Version == version {
+			return g, true, nil
+		}
+	}
+	return Grant{Plugin: plugin, Version: version}, false, nil
+}
+
+// Put stores a grant, replacing an existing grant for the same plugin version.
+func (s *GrantStore) Put(grant Grant) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	grants, err := s.load()
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i, g := range grants {
+		if g.Plugin == grant.Plugin && g.Version == grant.Version {
+			grants[i] = grant
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		grants = append(grants, grant)
+	}
+	return s.save(grants)
+}
+
+// Delete removes all grants for a plugin and returns how many were removed.
+func (s *GrantStore) Delete(plugin string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	grants, err := s.load()
+	if err != nil {
+		return 0, err
+	}
+	kept := grants[:0]
+	for _, g := range grants {
+		if g.Plugin != plugin {
+			kept = append(kept, g)
+		}
+	}
+	removed := len(grants) - len(kept)
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, s.save(kept)
+}
+
+func (s *GrantStore) load() ([]Grant, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read grants: %w", err)
+	}
+	var grants []Grant
+	if err := json.Unmarshal(data, &grants); err != nil {
+		return nil, fmt.Errorf("failed to parse grants %s: %w", s.path, err)
+	}
+	sort.Slice(grants, func(i, j int) bool {
+		if grants[i].Plugin != grants[j].Plugin {
+			return grants[i].Plugin < grants[j].Plugin
+		}
+		return grants[i].Version < grants[j].Version
+	})
+	return grants, nil
+}
+
+func (s *GrantStore) save(grants []Grant) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create grants directory: %w", err)
+	}
+	data, err := json.MarshalIndent(grants, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode grants: %w", err)
+	}
+	return os.WriteFile(s.path, data, 0o600)
+}
+
+// GrantAuditor records capability decisions. It is satisfied by *audit.Service.
+type GrantAuditor interface {
+	LogCapabilityGranted(ctx context.Context, plugin string, capabilities []string) error
+	LogCapabilityDenied(ctx context.Context, plugin string, capabilities []string, reason string) error
+}
+
+// ConsentPrompter asks the user whether a plugin may use a capability.
+type ConsentPrompter interface {
+	ConfirmCapability(p *Plugin, c WASMCapability) (bool, error)
+}
+
+// GrantService manages capability consent for installed plugins.
+type GrantService struct {
+	store   *GrantStore
+	auditor GrantAuditor
+	now     func() time.Time
+}
+
+// NewGrantService creates a grant service. The auditor may be nil.
+func NewGrantService(store *GrantStore, auditor GrantAuditor) *GrantService {
+	return &GrantService{
+		store:   store,
+		auditor: auditor,
+		now:     time.Now,
+	}
+}
+
+// Store returns the underlying grant store.
+func (s *GrantService) Store() *GrantStore {
+	return s.store
+}
+
+// Requested returns the capabilities a plugin declares that need consent.
+// Doctor checks run shell commands, so a plugin that provides them also
+// requests shell:execute.
+func Requested(p *Plugin) []WASMCapability {
+	var caps []WASMCapability
+	if p.Manifest.WASM != nil {
+		for _, c := range p.Manifest.WASM.Capabilities {
+			if RequiresConsent(c.Name) {
+				caps = append(caps, c)
+			}
+		}
+	}
+	if n := len(p.Manifest.Provides.Checks); n > 0 && !containsCapability(caps, CapabilityShellExecute) {
+		caps = append(caps, WASMCapability{
+			Name:          CapabilityShellExecute,
+			Justification: fmt.Sprintf("runs %d doctor check(s)", n),
+		})
+	}
+	return caps
+}
+
+// Current returns the stored grant for the plugin's installed version.
+func (s *GrantService) Current(p *Plugin) (Grant, error) {
+	g, _, err := s.store.Get(p.ID(), p.Manifest.Version)
+	return g, err
+}
+
+// Pending returns requested capabilities without a decision for this version.
+func (s *GrantService) Pending(p *Plugin) ([]WASMCapability, error) {
+	grant, err := s.Current(p)
+	if err != nil {
+		return nil, err
+	}
+	var pending []WASMCapability
+	for _, c := range Requested(p) {
+		if _, decided := grant.Decision(c.Name); !decided {
+			pending = append(pending, c)
+		}
+	}
+	return pending, nil
+}
+
+// Resolve asks the prompter about every pending capability, persists the
+// answers and returns the resulting grant. With a nil prompter pending
+// capabilities stay undecided, which denies them for this run without
+// recording a decision.
+func (s *GrantService) Resolve(ctx context.Context, p *Plugin, prompter ConsentPrompter) (Grant, error) {
+	grant, err := s.Current(p)
+	if err != nil {
+		return Grant{}, err
+	}
+	pending, err := s.Pending(p)
+	if err != nil || len(pending) == 0 || prompter == nil {
+		return grant, err
+	}
+
+	var granted, denied []string
+	for _, c := range pending {
+		ok, err := prompter.ConfirmCapability(p, c)
+		if err != nil {
+			return grant, err
+		}
+		grant.set(c.Name, ok)
+		if ok {
+			granted = append(granted, c.Name)
+		} else {
+			denied = append(denied, c.Name)
+		}
+	}
+
+	if err := s.save(ctx, grant, granted, denied, "denied by user"); err != nil {
+		return grant, err
+	}
+	return grant, nil
+}
+
+// Grant approves capabilities for the plugin's current version.
+// With no capabilities, every requested capability is granted.
+func (s *GrantService) Grant(ctx context.Context, p *Plugin, capabilities ...string) (Grant, error) {
+	return s.decide(ctx, p, true, capabilities)
+}
+
+// Deny refuses capabilities for the plugin's current version.
+// With no capabilities, every requested capability is denied.
+func (s *GrantService) Deny(ctx context.Context, p *Plugin, capabilities ...string) (Grant, error) {
+	return s.decide(ctx, p, false, capabilities)
+}
+
+// Reset forgets all decisions for a plugin so the user is asked again.
+func (s *GrantService) Reset(name string) (int, error) {
+	return s.store.Delete(name)
+}
+
+func (s *GrantService) decide(ctx context.Context, p *Plugin, granted bool, capabilities []string) (Grant, error) {
+	requested := Requested(p)
+	if len(capabilities) == 0 {
+		for _, c := range requested {
+			capabilities = append(capabilities, c.Name)
+		}
+	}
+
+	grant, err := s.Current(p)
+	if err != nil {
+		return Grant{}, err
+	}
+	for _, name := range capabilities {
+		if !containsCapability(requested, name) {
+			return grant, fmt.Errorf("%w: %s does not request %s", ErrUnknownCapability, p.ID(), name)
+		}
+		grant.set(name, granted)
+	}
+	if len(capabilities) == 0 {
+		return grant, nil
+	}
+
+	if granted {
+		return grant, s.save(ctx, grant, capabilities, nil, "")
+	}
+	return grant, s.save(ctx, grant, nil, capabilities, "denied by user")
+}
+
+func (s *GrantService) save(ctx context.Context, grant Grant, granted, denied []string, reason string) error {
+	grant.UpdatedAt = s.now()
+	if err := s.store.Put(grant); err != nil {
+		return err
+	}
+	if s.auditor == nil {
+		return nil
+	}
+	if len(granted) > 0 {
+		_ = s.auditor.LogCapabilityGranted(ctx, grant.Plugin, granted)
+	}
+	if len(denied) > 0 {
+		_ = s.auditor.LogCapabilityDenied(ctx, grant.Plugin, denied, reason)
+	}
+	return nil
+}
+
+func containsCapability(caps []WASMCapability, name string) bool {
+	for _, c := range caps {
+		if c.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func removeString(values []string, value string) []string {
+	out := values[:0]
+	for _, v := range values {
+		if v != value {
+			out = append(out, v)
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
@@ -0,0 +1,184 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func wasmPlugin(version string, caps ...string) *Plugin {
+	wasm := &WASMConfig{Module: "plugin.wasm"}
+	for _, c := range caps {
+		wasm.Capabilities = append(wasm.Capabilities, WASMCapability{Name: c, Justification: "needed"})
+	}
+	return &Plugin{Manifest: Manifest{Name: "docker", Version: version, WASM: wasm}}
+}
+
+type recordingGrantAuditor struct {
+	granted [][]string
+	denied  [][]string
+}
+
+func (a *recordingGrantAuditor) LogCapabilityGranted(_ context.Context, _ string, caps []string) error {
+	a.granted = append(a.granted, caps)
+	return nil
+}
+
+func (a *recordingGrantAuditor) LogCapabilityDenied(_ context.Context, _ string, caps []string, _ string) error {
+	a.denied = append(a.denied, caps)
+	return nil
+}
+
+type answerPrompter map[string]bool
+
+func (a answerPrompter) ConfirmCapability(_ *Plugin, c WASMCapability) (bool, error) {
+	return a[c.Name], nil
+}
+
+func TestGrantStore_PutGetDelete(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "grants.json")
+	store := NewGrantStore(path)
+
+	_, found, err := store.Get("docker", "1.0.0")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	require.NoError(t, store.Put(Grant{Plugin: "docker", Version: "1.0.0", Granted: []string{"shell:execute"}}))
+	require.NoError(t, store.Put(Grant{Plugin: "docker", Version: "1.1.0", Denied: []string{"net:http"}}))
+
+	g, found, err := store.Get("docker", "1.0.0")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.True(t, g.IsGranted("shell:execute"))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	removed, err := store.Delete("docker")
+	require.NoError(t, err)
+	assert.Equal(t, 2, removed)
+
+	grants, err := store.List()
+	require.NoError(t, err)
+	assert.Empty(t, grants)
+}
+
+func TestGrant_Decision(t *testing.T) {
+	t.Parallel()
+
+	g := Grant{Granted: []string{"shell:execute"}, Denied: []string{"net:http"}}
+
+	granted, decided := g.Decision("shell:execute")
+	assert.True(t, granted)
+	assert.True(t, decided)
+
+	granted, decided = g.Decision("net:http")
+	assert.False(t, granted)
+	assert.True(t, decided)
+
+	_, decided = g.Decision("files:write")
+	assert.False(t, decided)
+}
+
+func TestRequested_OnlyConsentCapabilities(t *testing.T) {
+	t.Parallel()
+
+	p := wasmPlugin("1.0.0", "files:read", "shell:execute", "net:http")
+	var names []string
+	for _, c := range Requested(p) {
+		names = append(names, c.Name)
+	}
+	assert.Equal(t, []string{"shell:execute", "net:http"}, names)
+	assert.Empty(t, Requested(&Plugin{}))
+}
+
+func TestRequested_DoctorChecks(t *testing.T) {
+	t.Parallel()
+
+	p := &Plugin{Manifest: Manifest{Name: "acme-security", Version: "1.0.0", Provides: Capabilities{
+		Checks: []config.DoctorCheck{{Name: "vpn", Command: "true"}, {Name: "filevault", Command: "true"}},
+	}}}
+	assert.Equal(t, []WASMCapability{{Name: "shell:execute", Justification: "runs 2 doctor check(s)"}}, Requested(p))
+
+	p = wasmPlugin("1.0.0", "shell:execute")
+	p.Manifest.Provides.Checks = []config.DoctorCheck{{Name: "vpn", Command: "true"}}
+	assert.Len(t, Requested(p), 1, "shell:execute is requested once")
+}
+
+func TestGrantService_Resolve(t *testing.T) {
+	t.Parallel()
+
+	auditor := &recordingGrantAuditor{}
+	svc := NewGrantService(NewGrantStore(filepath.Join(t.TempDir(), "grants.json")), auditor)
+	p := wasmPlugin("1.0.0", "shell:execute", "net:http")
+
+	grant, err := svc.Resolve(context.Background(), p, answerPrompter{"shell:execute": true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"shell:execute"}, grant.Granted)
+	assert.Equal(t, []string{"net:http"}, grant.Denied)
+	assert.Equal(t, [][]string{{"shell:execute"}}, auditor.granted)
+	assert.Equal(t, [][]string{{"net:http"}}, auditor.denied)
+
+	// Decisions persist, so resolving again does not prompt.
+	pending, err := svc.Pending(p)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	// A new version asks again.
+	pending, err = svc.Pending(wasmPlugin("2.0.0", "shell:execute", "net:http"))
+	require.NoError(t, err)
+	assert.Len(t, pending, 2)
+}
+
+func TestGrantService_ResolveWithoutPrompter(t *testing.T) {
+	t.Parallel()
+
+	svc := NewGrantService(NewGrantStore(filepath.Join(t.TempDir(), "grants.json")), nil)
+	p := wasmPlugin("1.0.0", "shell:execute")
+
+	grant, err := svc.Resolve(context.Background(), p, nil)
+	require.NoError(t, err)
+	assert.False(t, grant.IsGranted("shell:execute"))
+
+	pending, err := svc.Pending(p)
+	require.NoError(t, err)
+	assert.Len(t, pending, 1)
+}
+
+func TestGrantService_GrantDenyReset(t *testing.T) {
+	t.Parallel()
+
+	auditor := &recordingGrantAuditor{}
+	svc := NewGrantService(NewGrantStore(filepath.Join(t.TempDir(), "grants.json")), auditor)
+	p := wasmPlugin("1.0.0", "shell:execute", "net:http")
+
+	grant, err := svc.Grant(context.Background(), p)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"net:http", "shell:execute"}, grant.Granted)
+
+	grant, err = svc.Deny(context.Background(), p, "net:http")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"shell:execute"}, grant.Granted)
+	assert.Equal(t, []string{"net:http"}, grant.Denied)
+	assert.Len(t, auditor.granted, 1)
+	assert.Len(t, auditor.denied, 1)
+
+	_, err = svc.Grant(context.Background(), p, "files:write")
+	require.ErrorIs(t, err, ErrUnknownCapability)
+
+	removed, err := svc.Reset("docker")
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+
+	pending, err := svc.Pending(p)
+	require.NoError(t, err)
+	assert.Len(t, pending, 2)
+}
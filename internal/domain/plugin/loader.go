@@ -11,6 +11,8 @@ import (
 	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
 )
 
 const (
@@ -28,10 +30,9 @@ type Loader struct {
 func NewLoader() *Loader {
 	paths := []string{"/usr/local/share/preflight/plugins"}
 
-	home, err := os.UserHomeDir()
-	if err == nil {
+	if dir, err := InstallPath(); err == nil {
 		// Prepend user path (higher priority than system path)
-		paths = append([]string{filepath.Join(home, ".preflight", "plugins")}, paths...)
+		paths = append([]string{dir}, paths...)
 	}
 
 	return &Loader{SearchPaths: paths}
@@ -189,16 +190,15 @@ func (l *Loader) LoadFromGitWithContext(ctx context.Context, repoURL, ref string
 	}
 
 	// Determine install path
-	home, err := os.UserHomeDir()
+	pluginsDir, err := InstallPath()
 	if err != nil {
-		return nil, fmt.Errorf("getting home directory: %w", err)
+		return nil, err
 	}
 
-	installPath := filepath.Join(home, ".preflight", "plugins", repoName)
+	installPath := filepath.Join(pluginsDir, repoName)
 
 	// Ensure the resolved path is within the plugins directory (defense in depth)
 	// Use filepath.Rel which properly handles path traversal attempts
-	pluginsDir := filepath.Join(home, ".preflight", "plugins")
 	absPluginsDir, err := filepath.Abs(pluginsDir)
 	if err != nil {
 		return nil, fmt.Errorf("resolving plugins directory: %w", err)
@@ -291,11 +291,7 @@ func validatePluginName(name string) error {
 
 // InstallPath returns the default plugin installation directory.
 func InstallPath() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("getting home directory: %w", err)
-	}
-	return filepath.Join(home, ".preflight", "plugins"), nil
+	return platform.Path(platform.DataDir, "plugins")
 }
 
 // EnsureInstallPath creates the plugin installation directory if it doesn't exist.
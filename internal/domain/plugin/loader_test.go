@@ -187,10 +187,19 @@ func TestLoader_LoadFromGit_InvalidRepo(t *testing.T) {
 }
 
 func TestInstallPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", "")
+
 	path, err := InstallPath()
 	require.NoError(t, err)
-	assert.Contains(t, path, ".preflight")
-	assert.Contains(t, path, "plugins")
+	assert.Equal(t, filepath.Join(home, ".local", "share", "preflight", "plugins"), path)
+
+	// An existing ~/.preflight is used until it is migrated
+	require.NoError(t, os.Mkdir(filepath.Join(home, ".preflight"), 0o700))
+	path, err = InstallPath()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, ".preflight", "plugins"), path)
 }
 
 func TestEnsureInstallPath(t *testing.T) {
@@ -17,6 +17,9 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
 )
 
 // PluginType indicates the type of plugin.
@@ -134,6 +137,9 @@ type Capabilities struct {
 	Presets []string `yaml:"presets,omitempty"`
 	// CapabilityPacks are catalog capability packs
 	CapabilityPacks []string `yaml:"capabilityPacks,omitempty"`
+	// Checks are custom doctor checks. They run shell commands, so they
+	// need the shell:execute capability to be granted.
+	Checks []config.DoctorCheck `yaml:"checks,omitempty"`
 }
 
 // ProviderSpec describes a provider implementation.
@@ -335,6 +341,10 @@ func ValidateManifest(m *Manifest) error {
 		}
 	}
 
+	if err := config.ValidateDoctorChecks(m.Provides.Checks); err != nil {
+		ve.Addf("provides.checks: %v", err)
+	}
+
 	if ve.HasErrors() {
 		return ve
 	}
@@ -436,9 +446,10 @@ func validateConfigManifest(m *Manifest) error {
 	hasPresets := len(m.Provides.Presets) > 0
 	hasPacks := len(m.Provides.CapabilityPacks) > 0
 	hasProviders := len(m.Provides.Providers) > 0
+	hasChecks := len(m.Provides.Checks) > 0
 
-	if !hasPresets && !hasPacks && !hasProviders {
-		ve.Add("config plugin must provide at least one preset, capability pack, or provider config. Example:\n" +
+	if !hasPresets && !hasPacks && !hasProviders && !hasChecks {
+		ve.Add("config plugin must provide at least one preset, capability pack, provider config, or doctor check. Example:\n" +
 			"  provides:\n" +
 			"    presets:\n" +
 			"      - nvim:balanced\n" +
@@ -838,11 +849,14 @@ var AllowedCapabilities = map[string]bool{
 	"preflight:state":  true,
 }
 
+// CapabilityShellExecute lets a plugin run commands on the host.
+const CapabilityShellExecute = "shell:execute"
+
 // DangerousCapabilities are capabilities that require extra scrutiny.
 var DangerousCapabilities = map[string]bool{
-	"shell:execute": true,
-	"files:write":   true,
-	"net:http":      true,
+	CapabilityShellExecute: true,
+	"files:write":          true,
+	"net:http":             true,
 }
 
 // ValidateCapabilities checks if the requested WASM capabilities are allowed.
@@ -954,13 +968,13 @@ type VerificationConfig struct {
 // DefaultVerificationConfig returns a default verification config.
 // Uses standard locations for key storage.
 func DefaultVerificationConfig() *VerificationConfig {
-	home, err := os.UserHomeDir()
+	allowedSigners, err := platform.Path(platform.ConfigDir, "allowed_signers")
 	if err != nil {
 		return &VerificationConfig{}
 	}
 
 	return &VerificationConfig{
-		SSHAllowedSignersFile: filepath.Join(home, ".preflight", "allowed_signers"),
+		SSHAllowedSignersFile: allowedSigners,
 		GPGKeyring:            "", // Use system default
 		SigstoreTrustedRoots:  "", // Use public Sigstore
 	}
@@ -971,7 +985,7 @@ func verifySSHSignature(manifest *Manifest, manifestData []byte, config *Verific
 	if config == nil || config.SSHAllowedSignersFile == "" {
 		return &SignatureError{
 			Reason: "SSH verification requires an allowed_signers file: " +
-				"create ~/.preflight/allowed_signers with trusted public keys",
+				"create allowed_signers in the preflight config directory with trusted public keys",
 		}
 	}
 
@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -217,6 +218,23 @@ func TestValidateManifest_ConfigPlugin_WithCapabilityPacks(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestValidateManifest_ConfigPlugin_WithChecks(t *testing.T) {
+	m := &Manifest{
+		APIVersion: "v1",
+		Name:       "acme-security",
+		Version:    "1.0.0",
+		Provides: Capabilities{
+			Checks: []config.DoctorCheck{{Name: "vpn", Command: "test -d /Applications/GlobalProtect.app"}},
+		},
+	}
+	assert.NoError(t, ValidateManifest(m))
+
+	m.Provides.Checks = append(m.Provides.Checks, config.DoctorCheck{Name: "filevault"})
+	err := ValidateManifest(m)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "filevault has no command")
+}
+
 func TestValidateManifest_ConfigPlugin_Empty(t *testing.T) {
 	m := &Manifest{
 		APIVersion: "v1",
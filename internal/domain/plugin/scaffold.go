@@ -0,0 +1,381 @@
+package plugin
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// ErrScaffoldExists is returned when the scaffold target directory is not empty.
+var ErrScaffoldExists = errors.New("target directory is not empty")
+
+// Scaffold kinds accepted by `preflight plugin init`.
+const (
+	// ScaffoldProvider creates a WASM provider plugin.
+	ScaffoldProvider = "provider"
+	// ScaffoldPreset creates a config plugin that contributes presets.
+	ScaffoldPreset = "preset"
+)
+
+// ScaffoldOptions configures a new plugin skeleton.
+type ScaffoldOptions struct {
+	// Kind is ScaffoldProvider or ScaffoldPreset.
+	Kind string
+	// Name is the plugin name; it is also used as provider name and config key.
+	Name string
+	// Description defaults to a generic description of the plugin kind.
+	Description string
+	// Author is written to the manifest.
+	Author string
+	// License defaults to MIT.
+	License string
+}
+
+// Validate checks the options and fills in defaults.
+func (o *ScaffoldOptions) Validate() error {
+	if o.Kind != ScaffoldProvider && o.Kind != ScaffoldPreset {
+		return fmt.Errorf("unsupported plugin type %q (use %s or %s)", o.Kind, ScaffoldProvider, ScaffoldPreset)
+	}
+	if err := validatePluginNameFormat(o.Name); err != nil {
+		return err
+	}
+	if o.Author == "" {
+		return errors.New("author is required")
+	}
+	if o.License == "" {
+		o.License = "MIT"
+	}
+	if o.Description == "" {
+		if o.Kind == ScaffoldProvider {
+			o.Description = fmt.Sprintf("%s provider for Preflight", o.Name)
+		} else {
+			o.Description = fmt.Sprintf("%s presets for Preflight", o.Name)
+		}
+	}
+	return nil
+}
+
+// ConfigKey returns the config section handled by a scaffolded provider.
+// Hyphens are not valid in config keys, so they become underscores.
+func (o ScaffoldOptions) ConfigKey() string {
+	return strings.ReplaceAll(o.Name, "-", "_")
+}
+
+// Scaffold writes a new plugin skeleton into dir and returns the created
+// files relative to dir. The directory may exist but must be empty.
+//
+// The generated manifest passes `preflight plugin validate --strict` except
+// for the signature, which is added when the plugin is published.
+func Scaffold(dir string, opts ScaffoldOptions) ([]string, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading %s: %w", dir, err)
+	}
+	if len(entries) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrScaffoldExists, dir)
+	}
+
+	files := presetScaffold
+	if opts.Kind == ScaffoldProvider {
+		files = providerScaffold
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		content, err := renderScaffold(name, files[name], opts)
+		if err != nil {
+			return nil, err
+		}
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil, fmt.Errorf("creating %s: %w", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, content, 0o644); err != nil { //nolint:gosec // scaffolded sources are meant to be shared
+			return nil, fmt.Errorf("writing %s: %w", name, err)
+		}
+	}
+	return names, nil
+}
+
+func renderScaffold(name, text string, opts ScaffoldOptions) ([]byte, error) {
+	tmpl, err := template.New(name).Delims("[[", "]]").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parsing template %s: %w", name, err)
+	}
+	data := struct {
+		ScaffoldOptions
+		ConfigKey string
+	}{opts, opts.ConfigKey()}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("rendering %s: %w", name, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// placeholderChecksum is replaced by `make build` with the module's real hash.
+const placeholderChecksum = "sha256:0000000000000000000000000000000000000000000000000000000000000000"
+
+// Templates use [[ ]] delimiters so the generated Go code and Makefiles can
+// contain braces freely.
+var providerScaffold = map[string]string{
+	"plugin.yaml": `apiVersion: v1
+type: provider
+name: [[.Name]]
+version: 0.1.0
+description: [[.Description]]
+author: [[.Author]]
+license: [[.License]]
+keywords:
+  - [[.Name]]
+
+provides:
+  providers:
+    - name: [[.Name]]
+      configKey: [[.ConfigKey]]
+      description: Manage [[.Name]] items
+
+wasm:
+  module: plugin.wasm
+  checksum: ` + placeholderChecksum + `
+  capabilities:
+    - name: files:read
+      justification: Check whether configured items are present
+`,
+	"go.mod": `module github.com/example/preflight-[[.Name]]
+
+go 1.24
+`,
+	"main.go": `// Command [[.Name]] is a Preflight provider plugin.
+//
+// Preflight runs the module once per hook with a JSON request on stdin and
+// reads the JSON response from stdout. Build with ` + "`make build`" + `.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+func main() {
+	var req Request
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		fmt.Fprintln(os.Stderr, "invalid request:", err)
+		os.Exit(2)
+	}
+	if err := json.NewEncoder(os.Stdout).Encode(Handle(req)); err != nil {
+		fmt.Fprintln(os.Stderr, "writing response:", err)
+		os.Exit(1)
+	}
+}
+`,
+	"hooks.go": `package main
+
+import "fmt"
+
+// Request is the hook request sent by Preflight.
+type Request struct {
+	Hook     string                 ` + "`json:\"hook\"`" + `
+	Provider string                 ` + "`json:\"provider\"`" + `
+	Config   map[string]interface{} ` + "`json:\"config,omitempty\"`" + `
+	Step     string                 ` + "`json:\"step,omitempty\"`" + `
+	DryRun   bool                   ` + "`json:\"dry_run,omitempty\"`" + `
+}
+
+// Step is one unit of work returned by the plan hook.
+type Step struct {
+	ID          string   ` + "`json:\"id\"`" + `
+	Description string   ` + "`json:\"description\"`" + `
+	NeedsApply  bool     ` + "`json:\"needs_apply\"`" + `
+	DependsOn   []string ` + "`json:\"depends_on,omitempty\"`" + `
+	Desired     string   ` + "`json:\"desired,omitempty\"`" + `
+}
+
+// Response is returned to Preflight.
+type Response struct {
+	Steps    []Step                 ` + "`json:\"steps,omitempty\"`" + `
+	Captured map[string]interface{} ` + "`json:\"captured,omitempty\"`" + `
+	Messages []string               ` + "`json:\"messages,omitempty\"`" + `
+	Error    string                 ` + "`json:\"error,omitempty\"`" + `
+}
+
+// Handle dispatches a hook request.
+//
+// The example plans one step per entry of the "items" list in the
+// [[.ConfigKey]] config section:
+//
+//	[[.ConfigKey]]:
+//	  items:
+//	    - example
+func Handle(req Request) Response {
+	switch req.Hook {
+	case "plan":
+		return plan(req.Config)
+	case "apply":
+		return Response{Messages: []string{"applied " + req.Step}}
+	case "capture":
+		return Response{Captured: map[string]interface{}{"items": []string{}}}
+	default:
+		return Response{Error: fmt.Sprintf("unsupported hook %q", req.Hook)}
+	}
+}
+
+func plan(config map[string]interface{}) Response {
+	items, _ := config["items"].([]interface{})
+	resp := Response{}
+	for _, item := range items {
+		name := fmt.Sprint(item)
+		resp.Steps = append(resp.Steps, Step{
+			ID:          "item:" + name,
+			Description: "Ensure " + name,
+			NeedsApply:  true,
+			Desired:     name,
+		})
+	}
+	return resp
+}
+`,
+	"hooks_test.go": `package main
+
+import "testing"
+
+func TestHandle_Plan(t *testing.T) {
+	resp := Handle(Request{
+		Hook:   "plan",
+		Config: map[string]interface{}{"items": []interface{}{"example"}},
+	})
+	if resp.Error != "" {
+		t.Fatalf("unexpected error: %s", resp.Error)
+	}
+	if len(resp.Steps) != 1 || resp.Steps[0].ID != "item:example" {
+		t.Fatalf("unexpected steps: %+v", resp.Steps)
+	}
+}
+
+func TestHandle_UnknownHook(t *testing.T) {
+	if resp := Handle(Request{Hook: "unknown"}); resp.Error == "" {
+		t.Fatal("expected an error for an unknown hook")
+	}
+}
+`,
+	"Makefile": `.PHONY: build test validate install
+
+build:
+	GOOS=wasip1 GOARCH=wasm go build -o plugin.wasm .
+	sed -i.bak "s/checksum: sha256:.*/checksum: sha256:$$(shasum -a 256 plugin.wasm | cut -d' ' -f1)/" plugin.yaml && rm plugin.yaml.bak
+
+test:
+	go test ./...
+
+validate: build
+	preflight plugin validate .
+
+install: build
+	preflight plugin install .
+`,
+	".gitignore": `plugin.wasm
+`,
+	"README.md": `# [[.Name]]
+
+[[.Description]].
+
+## Usage
+
+` + "```yaml" + `
+# preflight.yaml
+[[.ConfigKey]]:
+  items:
+    - example
+` + "```" + `
+
+## Development
+
+` + "```bash" + `
+make test      # run hook tests
+make build     # build plugin.wasm and update the checksum in plugin.yaml
+make validate  # check the manifest
+make install   # install with preflight plugin install
+` + "```" + `
+
+Hooks are implemented in ` + "`hooks.go`" + `. Declare every host capability the
+plugin uses under ` + "`wasm.capabilities`" + ` in plugin.yaml with a justification.
+
+Before publishing, sign plugin.yaml; ` + "`preflight plugin validate --strict`" + `
+treats unsigned plugins as an error.
+`,
+}
+
+var presetScaffold = map[string]string{
+	"plugin.yaml": `apiVersion: v1
+type: config
+name: [[.Name]]
+version: 0.1.0
+description: [[.Description]]
+author: [[.Author]]
+license: [[.License]]
+keywords:
+  - [[.Name]]
+
+provides:
+  presets:
+    - [[.Name]]:basic
+`,
+	"presets/basic.yaml": `id: "[[.Name]]:basic"
+metadata:
+  title: "[[.Name]] basic"
+  description: "A starting point for [[.Name]]"
+  difficulty: beginner
+config:
+  brew:
+    formulae:
+      - git
+`,
+	"Makefile": `.PHONY: test validate install
+
+test: validate
+
+validate:
+	preflight plugin validate .
+
+install:
+	preflight plugin install .
+`,
+	"README.md": `# [[.Name]]
+
+[[.Description]].
+
+## Presets
+
+| Preset | Description |
+|--------|-------------|
+| ` + "`[[.Name]]:basic`" + ` | A starting point for [[.Name]] |
+
+Add presets under ` + "`presets/`" + ` and list their IDs in ` + "`provides.presets`" + `.
+
+## Development
+
+` + "```bash" + `
+make validate  # check the manifest
+make install   # install with preflight plugin install
+` + "```" + `
+
+Before publishing, sign plugin.yaml; ` + "`preflight plugin validate --strict`" + `
+treats unsigned plugins as an error.
+`,
+}
@@ -0,0 +1,84 @@
+package plugin
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScaffold_Provider(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "my-tool")
+	files, err := Scaffold(dir, ScaffoldOptions{Kind: ScaffoldProvider, Name: "my-tool", Author: "Jane Doe"})
+	require.NoError(t, err)
+	assert.Contains(t, files, "plugin.yaml")
+	assert.Contains(t, files, "hooks_test.go")
+	assert.Contains(t, files, "README.md")
+
+	p, err := NewLoader().LoadFromPath(dir)
+	require.NoError(t, err)
+	assert.Equal(t, TypeProvider, p.Manifest.Type)
+	assert.Equal(t, "my-tool provider for Preflight", p.Manifest.Description)
+	assert.Equal(t, "MIT", p.Manifest.License)
+	require.Len(t, p.Manifest.Provides.Providers, 1)
+	assert.Equal(t, "my_tool", p.Manifest.Provides.Providers[0].ConfigKey)
+}
+
+func TestScaffold_Preset(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	files, err := Scaffold(dir, ScaffoldOptions{Kind: ScaffoldPreset, Name: "team", Author: "Jane Doe", License: "Apache-2.0"})
+	require.NoError(t, err)
+	assert.Contains(t, files, "presets/basic.yaml")
+
+	p, err := NewLoader().LoadFromPath(dir)
+	require.NoError(t, err)
+	assert.True(t, p.Manifest.IsConfigPlugin())
+	assert.Equal(t, []string{"team:basic"}, p.Manifest.Provides.Presets)
+	assert.Equal(t, "Apache-2.0", p.Manifest.License)
+}
+
+func TestScaffold_Errors(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "existing"), nil, 0o600))
+
+	_, err := Scaffold(dir, ScaffoldOptions{Kind: ScaffoldPreset, Name: "team", Author: "Jane"})
+	require.ErrorIs(t, err, ErrScaffoldExists)
+
+	_, err = Scaffold(t.TempDir(), ScaffoldOptions{Kind: "theme", Name: "team", Author: "Jane"})
+	require.Error(t, err)
+
+	_, err = Scaffold(t.TempDir(), ScaffoldOptions{Kind: ScaffoldPreset, Name: "1bad", Author: "Jane"})
+	require.Error(t, err)
+
+	_, err = Scaffold(t.TempDir(), ScaffoldOptions{Kind: ScaffoldPreset, Name: "team"})
+	require.Error(t, err)
+}
+
+func TestScaffold_ProviderTestsPass(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go test on the generated plugin")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	dir := t.TempDir()
+	_, err = Scaffold(dir, ScaffoldOptions{Kind: ScaffoldProvider, Name: "example", Author: "Jane Doe"})
+	require.NoError(t, err)
+
+	cmd := exec.Command(goBin, "test", "./...")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=-mod=mod")
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+}
@@ -0,0 +1,119 @@
+package plugin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// ChangeKind describes how a plugin directory changed.
+type ChangeKind string
+
+// Plugin change kinds.
+const (
+	ChangeAdded   ChangeKind = "added"
+	ChangeUpdated ChangeKind = "updated"
+	ChangeRemoved ChangeKind = "removed"
+)
+
+// Change is a plugin directory that was added, modified or removed.
+type Change struct {
+	// Path is the plugin directory.
+	Path string
+	// Kind is what happened to it.
+	Kind ChangeKind
+}
+
+// Watcher detects changes to installed plugin directories by comparing
+// file fingerprints between polls. It is not safe for concurrent use.
+type Watcher struct {
+	paths []string
+	known map[string]string
+}
+
+// NewWatcher creates a watcher for the given search paths and records
+// their current state as the baseline.
+func NewWatcher(paths ...string) *Watcher {
+	w := &Watcher{paths: paths}
+	w.known = w.scan()
+	return w
+}
+
+// Paths returns the watched search paths.
+func (w *Watcher) Paths() []string {
+	return w.paths
+}
+
+// Poll returns plugin directories that changed since the previous poll,
+// ordered by path.
+func (w *Watcher) Poll() []Change {
+	current := w.scan()
+
+	var changes []Change
+	for dir, sum := range current {
+		prev, ok := w.known[dir]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Path: dir, Kind: ChangeAdded})
+		case prev != sum:
+			changes = append(changes, Change{Path: dir, Kind: ChangeUpdated})
+		}
+	}
+	for dir := range w.known {
+		if _, ok := current[dir]; !ok {
+			changes = append(changes, Change{Path: dir, Kind: ChangeRemoved})
+		}
+	}
+	w.known = current
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// scan fingerprints every plugin directory in the search paths.
+func (w *Watcher) scan() map[string]string {
+	state := make(map[string]string)
+	for _, searchPath := range w.paths {
+		entries, err := os.ReadDir(searchPath)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+			dir := filepath.Join(searchPath, entry.Name())
+			state[dir] = fingerprintDir(dir)
+		}
+	}
+	return state
+}
+
+// fingerprintDir hashes the names, sizes and modification times of all
+// files below dir. VCS metadata is ignored.
+func fingerprintDir(dir string) string {
+	h := sha256.New()
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil //nolint:nilerr // unreadable entries are skipped
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil //nolint:nilerr // file vanished during the walk
+		}
+		rel, _ := filepath.Rel(dir, path)
+		_, _ = fmt.Fprintf(h, "%s\x00%d\x00%d\n", rel, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	return hex.EncodeToString(h.Sum(nil))
+}
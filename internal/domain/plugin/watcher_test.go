@@ -0,0 +1,54 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatcher_Poll(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	existing := filepath.Join(root, "existing")
+	require.NoError(t, os.MkdirAll(existing, 0o755))
+	manifest := filepath.Join(existing, "plugin.yaml")
+	require.NoError(t, os.WriteFile(manifest, []byte("name: existing\n"), 0o600))
+
+	w := NewWatcher(root, filepath.Join(root, "missing"))
+	assert.Empty(t, w.Poll())
+
+	// Update an existing plugin.
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.WriteFile(manifest, []byte("name: existing\nversion: 2\n"), 0o600))
+	require.NoError(t, os.Chtimes(manifest, later, later))
+
+	// Add a new plugin.
+	added := filepath.Join(root, "added")
+	require.NoError(t, os.MkdirAll(added, 0o755))
+
+	assert.Equal(t, []Change{
+		{Path: added, Kind: ChangeAdded},
+		{Path: existing, Kind: ChangeUpdated},
+	}, w.Poll())
+	assert.Empty(t, w.Poll())
+
+	require.NoError(t, os.RemoveAll(existing))
+	assert.Equal(t, []Change{{Path: existing, Kind: ChangeRemoved}}, w.Poll())
+}
+
+func TestWatcher_IgnoresGitMetadata(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	dir := filepath.Join(root, "plugin")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".git"), 0o755))
+
+	w := NewWatcher(root)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".git", "HEAD"), []byte("ref"), 0o600))
+	assert.Empty(t, w.Poll())
+}
@@ -7,6 +7,8 @@ import (
 	"errors"
 	"fmt"
 	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/sync"
 )
 
 var (
@@ -33,22 +35,38 @@ type ComplianceAttestation struct {
 	Hostname string `json:"hostname"`
 	// AttestedAt is when the attestation was created.
 	AttestedAt time.Time `json:"attested_at"`
-	// SignatureType is "local" for key-based or "sigstore" for keyless.
+	// Status is the compliance status of the report.
+	Status ComplianceStatus `json:"status,omitempty"`
+	// Score is the compliance score of the report (0-100).
+	Score float64 `json:"score"`
+	// PolicyHash is the SHA256 of the org policy file, empty when no policy
+	// was evaluated.
+	PolicyHash string `json:"policy_hash,omitempty"`
+	// Fingerprint describes the machine's hardware.
+	Fingerprint *sync.Fingerprint `json:"fingerprint,omitempty"`
+	// SignatureType is "local" for an HMAC key, "ed25519" for a trust store
+	// key or "sigstore" for keyless.
 	SignatureType string `json:"signature_type,omitempty"`
 	// Signature is the base64-encoded signature.
 	Signature string `json:"signature,omitempty"`
 	// SignerIdentity is the signer (key ID or OIDC identity).
 	SignerIdentity string `json:"signer_identity,omitempty"`
+	// PublicKey is the OpenSSH public key of an ed25519 signer.
+	PublicKey string `json:"public_key,omitempty"`
 	// ContentDigest is the SHA256 of the report content (for verification).
 	ContentDigest string `json:"content_digest"`
 }
 
 // digestContent is the structure hashed to produce the content digest.
 type digestContent struct {
-	Report     *ComplianceReport `json:"report"`
-	MachineID  string            `json:"machine_id"`
-	Hostname   string            `json:"hostname"`
-	AttestedAt time.Time         `json:"attested_at"`
+	Report      *ComplianceReport `json:"report"`
+	MachineID   string            `json:"machine_id"`
+	Hostname    string            `json:"hostname"`
+	AttestedAt  time.Time         `json:"attested_at"`
+	Status      ComplianceStatus  `json:"status,omitempty"`
+	Score       float64           `json:"score,omitempty"`
+	PolicyHash  string            `json:"policy_hash,omitempty"`
+	Fingerprint *sync.Fingerprint `json:"fingerprint,omitempty"`
 }
 
 // NewComplianceAttestation creates a new unsigned attestation for a report.
@@ -65,19 +83,26 @@ func NewComplianceAttestation(report *ComplianceReport, machineID, hostname stri
 		MachineID:  machineID,
 		Hostname:   hostname,
 		AttestedAt: time.Now(),
+		Status:     report.Summary.Status,
+		Score:      report.Summary.ComplianceScore,
 	}
 	att.ContentDigest = att.Digest()
 
 	return att, nil
 }
 
-// Digest computes the SHA256 of the attestation content (report + machine info).
+// Digest computes the SHA256 of the attestation content (report, policy and
+// machine info).
 func (a *ComplianceAttestation) Digest() string {
 	content := digestContent{
-		Report:     a.Report,
-		MachineID:  a.MachineID,
-		Hostname:   a.Hostname,
-		AttestedAt: a.AttestedAt,
+		Report:      a.Report,
+		MachineID:   a.MachineID,
+		Hostname:    a.Hostname,
+		AttestedAt:  a.AttestedAt,
+		Status:      a.Status,
+		Score:       a.Score,
+		PolicyHash:  a.PolicyHash,
+		Fingerprint: a.Fingerprint,
 	}
 
 	data, err := json.Marshal(content)
@@ -2,13 +2,18 @@ package policy
 
 import (
 	"context"
+	"crypto/ed25519"
 	"crypto/hmac"
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/catalog"
+	"github.com/felixgeelhaar/preflight/internal/domain/signing"
 )
 
 // Attester signs compliance attestations.
@@ -134,3 +139,81 @@ func (a *SigstoreAttester) Verify(_ context.Context, attestation *ComplianceAtte
 func (a *SigstoreAttester) Name() string {
 	return "sigstore"
 }
+
+// TrustKeyAttester signs with an ed25519 key from the trust store, so any
+// machine that trusts the key can verify the attestation.
+type TrustKeyAttester struct {
+	key         ed25519.PrivateKey
+	keyID       string
+	publicKey   []byte
+	fingerprint string
+}
+
+// NewTrustKeyAttester creates an attester that signs with key. keyID is the
+// key's ID in the trust store and publicKey its OpenSSH public key.
+func NewTrustKeyAttester(key ed25519.PrivateKey, keyID string, publicKey []byte) *TrustKeyAttester {
+	return &TrustKeyAttester{
+		key:         key,
+		keyID:       keyID,
+		publicKey:   publicKey,
+		fingerprint: catalog.ComputeKeyFingerprint(publicKey),
+	}
+}
+
+// NewTrustKeyVerifier creates an attester that only verifies attestations
+// signed by the trusted key with the given fingerprint.
+func NewTrustKeyVerifier(fingerprint string) *TrustKeyAttester {
+	return &TrustKeyAttester{fingerprint: fingerprint}
+}
+
+// Sign records the digest of the attestation and signs it.
+func (a *TrustKeyAttester) Sign(_ context.Context, attestation *ComplianceAttestation) error {
+	if a.key == nil {
+		return fmt.Errorf("%w: no signing key", ErrKeyNotFound)
+	}
+	pub, err := signing.ParseED25519PublicKey(a.publicKey)
+	if err != nil {
+		return err
+	}
+	if !pub.Equal(a.key.Public()) {
+		return fmt.Errorf("%w: public key does not belong to the signing key", ErrAttestationInvalid)
+	}
+
+	attestation.ContentDigest = attestation.Digest()
+	attestation.SignatureType = "ed25519"
+	attestation.SignerIdentity = a.keyID
+	attestation.PublicKey = string(a.publicKey)
+	attestation.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(a.key, []byte(attestation.ContentDigest)))
+	return nil
+}
+
+// Verify checks that the attestation was signed by the trusted key and has
+// not been modified since.
+func (a *TrustKeyAttester) Verify(_ context.Context, attestation *ComplianceAttestation) error {
+	if !attestation.IsSigned() {
+		return ErrAttestationUnsigned
+	}
+	if attestation.SignatureType != "ed25519" {
+		return fmt.Errorf("%w: signed with %q, not a trust store key", ErrSignatureInvalid, attestation.SignatureType)
+	}
+	if catalog.ComputeKeyFingerprint([]byte(attestation.PublicKey)) != a.fingerprint {
+		return fmt.Errorf("%w: public key does not match trusted key %s", ErrSignatureInvalid, attestation.SignerIdentity)
+	}
+	if attestation.ContentDigest != attestation.Digest() {
+		return fmt.Errorf("%w: content was modified after signing", ErrSignatureInvalid)
+	}
+	pub, err := signing.ParseED25519PublicKey([]byte(attestation.PublicKey))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrSignatureInvalid, err)
+	}
+	sig, err := base64.StdEncoding.DecodeString(attestation.Signature)
+	if err != nil || !ed25519.Verify(pub, []byte(attestation.ContentDigest), sig) {
+		return ErrSignatureInvalid
+	}
+	return nil
+}
+
+// Name returns the attester name.
+func (a *TrustKeyAttester) Name() string {
+	return "trust-key"
+}
@@ -2,13 +2,18 @@ package policy
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
 	"os"
 	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/felixgeelhaar/preflight/internal/domain/catalog"
+	"github.com/felixgeelhaar/preflight/internal/domain/sync"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
 )
 
 func TestNewLocalKeyAttester(t *testing.T) {
@@ -210,3 +215,70 @@ func TestSigstoreAttester_Available(t *testing.T) {
 	// Just verify it returns a boolean without panicking
 	_ = attester.Available()
 }
+
+func newTrustKey(t *testing.T) (ed25519.PrivateKey, []byte) {
+	t.Helper()
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	sshPub, err := ssh.NewPublicKey(pub)
+	require.NoError(t, err)
+	return key, ssh.MarshalAuthorizedKey(sshPub)
+}
+
+func TestTrustKeyAttester_SignVerify(t *testing.T) {
+	t.Parallel()
+
+	key, pub := newTrustKey(t)
+	report := &ComplianceReport{
+		PolicyName: "acme",
+		Summary:    ComplianceSummary{Status: ComplianceStatusWarning, ComplianceScore: 80},
+	}
+	att, err := NewComplianceAttestation(report, "machine-001", "dev-laptop")
+	require.NoError(t, err)
+	att.PolicyHash = "sha256:abc"
+	att.Fingerprint = &sync.Fingerprint{Serial: "C02XYZ"}
+
+	ctx := context.Background()
+	require.NoError(t, NewTrustKeyAttester(key, "acme-it", pub).Sign(ctx, att))
+	assert.Equal(t, "ed25519", att.SignatureType)
+	assert.Equal(t, "acme-it", att.SignerIdentity)
+	assert.Equal(t, att.Digest(), att.ContentDigest)
+	assert.InDelta(t, 80.0, att.Score, 0)
+
+	verifier := NewTrustKeyVerifier(catalog.ComputeKeyFingerprint(pub))
+	require.NoError(t, verifier.Verify(ctx, att))
+
+	// Changing signed content breaks the signature
+	att.Fingerprint.Serial = "OTHER"
+	assert.ErrorIs(t, verifier.Verify(ctx, att), ErrSignatureInvalid)
+	att.Fingerprint.Serial = "C02XYZ"
+	att.Score = 100
+	assert.ErrorIs(t, verifier.Verify(ctx, att), ErrSignatureInvalid)
+}
+
+func TestTrustKeyAttester_Verify_UntrustedKey(t *testing.T) {
+	t.Parallel()
+
+	key, pub := newTrustKey(t)
+	_, otherPub := newTrustKey(t)
+	att, err := NewComplianceAttestation(&ComplianceReport{PolicyName: "acme"}, "machine-001", "dev-laptop")
+	require.NoError(t, err)
+	require.NoError(t, NewTrustKeyAttester(key, "acme-it", pub).Sign(context.Background(), att))
+
+	err = NewTrustKeyVerifier(catalog.ComputeKeyFingerprint(otherPub)).Verify(context.Background(), att)
+	require.ErrorIs(t, err, ErrSignatureInvalid)
+	assert.Contains(t, err.Error(), "does not match trusted key acme-it")
+}
+
+func TestTrustKeyAttester_Sign_MismatchedPublicKey(t *testing.T) {
+	t.Parallel()
+
+	key, _ := newTrustKey(t)
+	_, otherPub := newTrustKey(t)
+	att, err := NewComplianceAttestation(&ComplianceReport{PolicyName: "acme"}, "machine-001", "dev-laptop")
+	require.NoError(t, err)
+
+	err = NewTrustKeyAttester(key, "acme-it", otherPub).Sign(context.Background(), att)
+	assert.ErrorIs(t, err, ErrAttestationInvalid)
+	assert.False(t, att.IsSigned())
+}
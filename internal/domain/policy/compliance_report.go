@@ -88,6 +88,23 @@ type ComplianceReport struct {
 	Overrides []OverrideDetail `json:"overrides,omitempty"`
 	// EvaluatedItems lists all items that were checked
 	EvaluatedItems []string `json:"evaluated_items,omitempty"`
+	// Onboarding reports progress on the layers' manual onboarding steps
+	Onboarding *OnboardingStatus `json:"onboarding,omitempty"`
+	// PostureState is the observed OS security posture
+	PostureState *PostureState `json:"posture_state,omitempty"`
+	// Posture lists the outcome of the policy's posture requirements
+	Posture []PostureCheck `json:"posture,omitempty"`
+}
+
+// OnboardingStatus summarizes the manual onboarding checklist of a machine.
+// It is informational and does not affect the compliance status.
+type OnboardingStatus struct {
+	// Total is the number of onboarding steps declared by the target's layers
+	Total int `json:"total"`
+	// Completed is the number of steps checked off on this machine
+	Completed int `json:"completed"`
+	// Pending lists the keys ("<layer>/<id>") of open steps
+	Pending []string `json:"pending,omitempty"`
 }
 
 // ReportGenerator generates compliance reports from policy evaluation results.
@@ -348,6 +365,29 @@ func (r *ComplianceReport) ToText() string {
 		sb.WriteString("\n")
 	}
 
+	// Security posture
+	if len(r.Posture) > 0 {
+		sb.WriteString("─── Security Posture ──────────────────────────────────────────\n")
+		for _, check := range r.Posture {
+			icon := "✓"
+			if !check.Passed {
+				icon = "✗"
+			}
+			fmt.Fprintf(&sb, "  %s %-12s %s (required %s)\n", icon, check.Name, check.Actual, check.Required)
+		}
+		sb.WriteString("\n")
+	}
+
+	// Onboarding
+	if r.Onboarding != nil && r.Onboarding.Total > 0 {
+		sb.WriteString("─── Onboarding Checklist ──────────────────────────────────────\n")
+		fmt.Fprintf(&sb, "Completed:        %d/%d\n", r.Onboarding.Completed, r.Onboarding.Total)
+		for _, key := range r.Onboarding.Pending {
+			fmt.Fprintf(&sb, "  ○ %s\n", key)
+		}
+		sb.WriteString("\n")
+	}
+
 	// Footer
 	sb.WriteString("───────────────────────────────────────────────────────────────\n")
 
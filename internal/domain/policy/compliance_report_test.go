@@ -589,6 +589,23 @@ func TestComplianceSummary_CalculateScore(t *testing.T) {
 	}
 }
 
+func TestComplianceReport_ToText_Onboarding(t *testing.T) {
+	t.Parallel()
+
+	report := &ComplianceReport{
+		PolicyName: "none",
+		Onboarding: &OnboardingStatus{Total: 3, Completed: 1, Pending: []string{"team/vpn", "team/oncall"}},
+	}
+
+	text := report.ToText()
+	assert.Contains(t, text, "Onboarding Checklist")
+	assert.Contains(t, text, "Completed:        1/3")
+	assert.Contains(t, text, "○ team/oncall")
+
+	report.Onboarding = nil
+	assert.NotContains(t, report.ToText(), "Onboarding Checklist")
+}
+
 func TestComplianceReport_ToText_AllSections(t *testing.T) {
 	t.Parallel()
 
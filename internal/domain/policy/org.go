@@ -62,6 +62,8 @@ type OrgPolicy struct {
 	Forbidden []Forbidden `yaml:"forbidden,omitempty"`
 	// Overrides allow exceptions to policy rules
 	Overrides []Override `yaml:"overrides,omitempty"`
+	// Posture lists required OS security settings
+	Posture PostureRequirements `yaml:"posture,omitempty"`
 }
 
 // OrgViolation represents an org policy violation.
@@ -61,6 +61,10 @@ func ValidateOrgPolicy(policy *OrgPolicy) error {
 		return fmt.Errorf("invalid enforcement mode: %s (must be 'warn' or 'block')", policy.Enforcement)
 	}
 
+	if err := policy.Posture.validate(); err != nil {
+		return err
+	}
+
 	// Validate required patterns
 	for i, req := range policy.Required {
 		if req.Pattern == "" {
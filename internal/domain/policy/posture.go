@@ -0,0 +1,197 @@
+package policy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PostureRequirements are the OS security settings an org policy requires.
+// Zero values require nothing.
+type PostureRequirements struct {
+	// FileVault requires disk encryption to be on
+	FileVault bool `yaml:"filevault,omitempty"`
+	// Firewall requires the application firewall to be enabled
+	Firewall bool `yaml:"firewall,omitempty"`
+	// Gatekeeper requires Gatekeeper assessments to be enabled
+	Gatekeeper bool `yaml:"gatekeeper,omitempty"`
+	// ScreenLockTimeout is the longest idle time before the screen saver
+	// starts, such as "5m"
+	ScreenLockTimeout string `yaml:"screen_lock_timeout,omitempty"`
+	// MinOSVersion is the oldest allowed OS version, such as "14.5"
+	MinOSVersion string `yaml:"min_os_version,omitempty"`
+}
+
+func (r PostureRequirements) validate() error {
+	if r.ScreenLockTimeout != "" {
+		if d, err := time.ParseDuration(r.ScreenLockTimeout); err != nil || d <= 0 {
+			return fmt.Errorf("posture.screen_lock_timeout %q must be a positive duration such as 5m", r.ScreenLockTimeout)
+		}
+	}
+	if r.MinOSVersion != "" {
+		if _, ok := parseOSVersion(r.MinOSVersion); !ok {
+			return fmt.Errorf("posture.min_os_version %q must be a version such as 14.5", r.MinOSVersion)
+		}
+	}
+	return nil
+}
+
+// PostureState is the observed security posture of a machine. Nil fields
+// could not be determined.
+type PostureState struct {
+	OS         string `json:"os"`
+	OSVersion  string `json:"os_version,omitempty"`
+	FileVault  *bool  `json:"filevault,omitempty"`
+	Firewall   *bool  `json:"firewall,omitempty"`
+	Gatekeeper *bool  `json:"gatekeeper,omitempty"`
+	// ScreenLockSeconds is the idle time before the screen saver starts;
+	// zero means never.
+	ScreenLockSeconds *int `json:"screen_lock_seconds,omitempty"`
+}
+
+// PostureCheck is the outcome of one posture requirement.
+type PostureCheck struct {
+	// Name identifies the setting, such as "filevault"
+	Name string `json:"name"`
+	// Required describes the required setting
+	Required string `json:"required"`
+	// Actual describes the observed setting
+	Actual string `json:"actual"`
+	// Passed is true when the setting meets the requirement
+	Passed bool `json:"passed"`
+}
+
+// Evaluate checks state against the requirements. Settings that are not
+// required are not checked, and settings that could not be determined fail.
+// The requirements are macOS settings, so nothing is checked elsewhere.
+func (r PostureRequirements) Evaluate(state PostureState) []PostureCheck {
+	if state.OS != "darwin" {
+		return nil
+	}
+	var checks []PostureCheck
+	toggle := func(name string, required bool, actual *bool, on string) {
+		if !required {
+			return
+		}
+		check := PostureCheck{Name: name, Required: on, Actual: "unknown"}
+		if actual != nil {
+			check.Passed = *actual
+			check.Actual = map[bool]string{true: on, false: "off"}[*actual]
+		}
+		checks = append(checks, check)
+	}
+	toggle("filevault", r.FileVault, state.FileVault, "on")
+	toggle("firewall", r.Firewall, state.Firewall, "on")
+	toggle("gatekeeper", r.Gatekeeper, state.Gatekeeper, "on")
+
+	if r.ScreenLockTimeout != "" {
+		limit, _ := time.ParseDuration(r.ScreenLockTimeout)
+		check := PostureCheck{Name: "screen_lock", Required: "at most " + limit.String(), Actual: "unknown"}
+		if seconds := state.ScreenLockSeconds; seconds != nil {
+			timeout := time.Duration(*seconds) * time.Second
+			check.Passed = timeout > 0 && timeout <= limit
+			check.Actual = timeout.String()
+			if timeout == 0 {
+				check.Actual = "never"
+			}
+		}
+		checks = append(checks, check)
+	}
+
+	if r.MinOSVersion != "" {
+		check := PostureCheck{Name: "os_version", Required: "at least " + r.MinOSVersion, Actual: "unknown"}
+		if state.OSVersion != "" {
+			check.Actual = state.OSVersion
+			minimum, _ := parseOSVersion(r.MinOSVersion)
+			if current, ok := parseOSVersion(state.OSVersion); ok {
+				check.Passed = compareOSVersions(current, minimum) >= 0
+			}
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// AddPosture adds the posture checks to the report. Failed checks become
+// violations or warnings depending on enforcement, and the summary is
+// updated to include them.
+func (r *ComplianceReport) AddPosture(checks []PostureCheck, enforcement EnforcementMode) {
+	r.Posture = append(r.Posture, checks...)
+	for _, check := range checks {
+		r.Summary.TotalChecks++
+		if check.Passed {
+			r.Summary.PassedChecks++
+			continue
+		}
+		detail := ViolationDetail{
+			Type:           "posture",
+			Pattern:        check.Name,
+			Value:          check.Actual,
+			Message:        fmt.Sprintf("%s is %s, required %s", check.Name, check.Actual, check.Required),
+			Recommendation: postureRecommendations[check.Name],
+		}
+		if enforcement == EnforcementWarn {
+			detail.Severity = "warning"
+			r.Warnings = append(r.Warnings, detail)
+			r.Summary.WarningCount++
+		} else {
+			detail.Severity = "error"
+			r.Violations = append(r.Violations, detail)
+			r.Summary.ViolationCount++
+		}
+	}
+
+	if r.Summary.TotalChecks > 0 {
+		r.Summary.ComplianceScore = float64(r.Summary.PassedChecks) / float64(r.Summary.TotalChecks) * 100
+	}
+	switch {
+	case r.Summary.ViolationCount > 0 && enforcement != EnforcementWarn:
+		r.Summary.Status = ComplianceStatusNonCompliant
+	case r.Summary.Status == ComplianceStatusCompliant && (r.Summary.WarningCount > 0 || r.Summary.ViolationCount > 0):
+		r.Summary.Status = ComplianceStatusWarning
+	}
+}
+
+// postureRecommendations tell how to fix a failed posture check on macOS.
+var postureRecommendations = map[string]string{
+	"filevault":   "Turn on FileVault in System Settings > Privacy & Security, or run 'sudo fdesetup enable'.",
+	"firewall":    "Run 'sudo /usr/libexec/ApplicationFirewall/socketfilterfw --setglobalstate on'.",
+	"gatekeeper":  "Run 'sudo spctl --global-enable'.",
+	"screen_lock": "Shorten the screen saver delay in System Settings > Lock Screen.",
+	"os_version":  "Install the latest OS update in System Settings > General > Software Update.",
+}
+
+// parseOSVersion parses a dotted version such as "14.5.1".
+func parseOSVersion(v string) ([]int, bool) {
+	parts := strings.Split(strings.TrimSpace(v), ".")
+	version := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		version[i] = n
+	}
+	return version, true
+}
+
+// compareOSVersions compares dotted versions; missing parts count as 0.
+func compareOSVersions(a, b []int) int {
+	for i := 0; i < max(len(a), len(b)); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,90 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOrgPolicyYAML_Posture(t *testing.T) {
+	t.Parallel()
+
+	p, err := ParseOrgPolicyYAML([]byte(`
+policy:
+  name: security-baseline
+  posture:
+    filevault: true
+    firewall: true
+    gatekeeper: true
+    screen_lock_timeout: 5m
+    min_os_version: "14.5"
+`))
+	require.NoError(t, err)
+	assert.Equal(t, PostureRequirements{
+		FileVault: true, Firewall: true, Gatekeeper: true, ScreenLockTimeout: "5m", MinOSVersion: "14.5",
+	}, p.Posture)
+
+	for _, invalid := range []string{
+		"policy:\n  name: p\n  posture:\n    screen_lock_timeout: soon\n",
+		"policy:\n  name: p\n  posture:\n    min_os_version: sonoma\n",
+	} {
+		_, err := ParseOrgPolicyYAML([]byte(invalid))
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestPostureRequirements_Evaluate(t *testing.T) {
+	t.Parallel()
+
+	on, off := true, false
+	idle := 1200
+	required := PostureRequirements{
+		FileVault: true, Firewall: true, Gatekeeper: true, ScreenLockTimeout: "5m", MinOSVersion: "14.5",
+	}
+	state := PostureState{OS: "darwin", OSVersion: "15.0.1", FileVault: &on, Firewall: &off, ScreenLockSeconds: &idle}
+
+	assert.Equal(t, []PostureCheck{
+		{Name: "filevault", Required: "on", Actual: "on", Passed: true},
+		{Name: "firewall", Required: "on", Actual: "off"},
+		{Name: "gatekeeper", Required: "on", Actual: "unknown"},
+		{Name: "screen_lock", Required: "at most 5m0s", Actual: "20m0s"},
+		{Name: "os_version", Required: "at least 14.5", Actual: "15.0.1", Passed: true},
+	}, required.Evaluate(state))
+
+	assert.Empty(t, PostureRequirements{}.Evaluate(state))
+	assert.Empty(t, required.Evaluate(PostureState{OS: "linux"}), "posture requirements are macOS settings")
+
+	never := 0
+	checks := PostureRequirements{ScreenLockTimeout: "5m", MinOSVersion: "14.5"}.Evaluate(PostureState{OS: "darwin", OSVersion: "14.4.1", ScreenLockSeconds: &never})
+	assert.Equal(t, "never", checks[0].Actual)
+	assert.False(t, checks[0].Passed)
+	assert.False(t, checks[1].Passed)
+}
+
+func TestComplianceReport_AddPosture(t *testing.T) {
+	t.Parallel()
+
+	checks := []PostureCheck{
+		{Name: "filevault", Required: "on", Actual: "on", Passed: true},
+		{Name: "firewall", Required: "on", Actual: "off"},
+	}
+
+	report := &ComplianceReport{Summary: ComplianceSummary{Status: ComplianceStatusCompliant, TotalChecks: 2, PassedChecks: 2, ComplianceScore: 100}}
+	report.AddPosture(checks, EnforcementBlock)
+	assert.Equal(t, ComplianceStatusNonCompliant, report.Summary.Status)
+	assert.Equal(t, 4, report.Summary.TotalChecks)
+	assert.Equal(t, 3, report.Summary.PassedChecks)
+	assert.InDelta(t, 75.0, report.Summary.ComplianceScore, 0.01)
+	require.Len(t, report.Violations, 1)
+	assert.Equal(t, "posture", report.Violations[0].Type)
+	assert.Equal(t, "firewall is off, required on", report.Violations[0].Message)
+	assert.NotEmpty(t, report.Violations[0].Recommendation)
+	assert.Contains(t, report.ToText(), "Security Posture")
+
+	report = &ComplianceReport{Summary: ComplianceSummary{Status: ComplianceStatusCompliant}}
+	report.AddPosture(checks, EnforcementWarn)
+	assert.Equal(t, ComplianceStatusWarning, report.Summary.Status)
+	assert.Len(t, report.Warnings, 1)
+	assert.Empty(t, report.Violations)
+}
@@ -0,0 +1,115 @@
+// Package profile selects the profile that fits the machine's current
+// surroundings, such as the Wi-Fi network or an active VPN.
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Trigger activates a profile when all of its set conditions hold.
+type Trigger struct {
+	// SSID matches the name of the connected Wi-Fi network.
+	SSID string `yaml:"ssid,omitempty" json:"ssid,omitempty"`
+	// VPN matches while a VPN connection is up.
+	VPN bool `yaml:"vpn,omitempty" json:"vpn,omitempty"`
+	// Path matches while the file or directory exists; ~ is expanded.
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+}
+
+// IsZero reports whether the trigger has no conditions.
+func (t Trigger) IsZero() bool {
+	return t.SSID == "" && !t.VPN && t.Path == ""
+}
+
+// Matches reports whether every condition of the trigger holds in env.
+// A trigger without conditions never matches.
+func (t Trigger) Matches(env Environment) bool {
+	if t.IsZero() {
+		return false
+	}
+	if t.SSID != "" && t.SSID != env.SSID {
+		return false
+	}
+	if t.VPN && !env.VPNConnected {
+		return false
+	}
+	if t.Path != "" && !env.pathExists(t.Path) {
+		return false
+	}
+	return true
+}
+
+// String describes the trigger, e.g. "ssid=CorpWiFi, vpn".
+func (t Trigger) String() string {
+	var parts []string
+	if t.SSID != "" {
+		parts = append(parts, "ssid="+t.SSID)
+	}
+	if t.VPN {
+		parts = append(parts, "vpn")
+	}
+	if t.Path != "" {
+		parts = append(parts, "path="+t.Path)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Environment is what the machine currently observes.
+type Environment struct {
+	// SSID is the connected Wi-Fi network, empty when not on Wi-Fi.
+	SSID string
+	// VPNConnected reports whether a VPN interface is up.
+	VPNConnected bool
+	// PathExists checks path triggers; nil uses the filesystem.
+	PathExists func(path string) bool
+}
+
+func (e Environment) pathExists(path string) bool {
+	if e.PathExists != nil {
+		return e.PathExists(path)
+	}
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return false
+		}
+		path = filepath.Join(home, rest)
+	}
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// Candidate is a profile that may be activated automatically.
+type Candidate struct {
+	Name     string
+	Triggers []Trigger
+	// Fallback activates the profile when no other trigger matches.
+	Fallback bool
+}
+
+// Selection is the outcome of Select.
+type Selection struct {
+	Profile string
+	// Reason is the matching trigger, or "fallback".
+	Reason string
+}
+
+// Select returns the first candidate with a matching trigger, or else the
+// first fallback candidate. ok is false when neither exists.
+func Select(candidates []Candidate, env Environment) (sel Selection, ok bool) {
+	for _, c := range candidates {
+		for _, t := range c.Triggers {
+			if t.Matches(env) {
+				return Selection{Profile: c.Name, Reason: t.String()}, true
+			}
+		}
+	}
+	for _, c := range candidates {
+		if c.Fallback {
+			return Selection{Profile: c.Name, Reason: "fallback"}, true
+		}
+	}
+	return Selection{}, false
+}
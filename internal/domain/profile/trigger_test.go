@@ -0,0 +1,81 @@
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrigger_Matches(t *testing.T) {
+	t.Parallel()
+
+	existing := t.TempDir()
+	env := Environment{SSID: "CorpWiFi", VPNConnected: true}
+
+	tests := []struct {
+		name    string
+		trigger Trigger
+		env     Environment
+		want    bool
+	}{
+		{"ssid", Trigger{SSID: "CorpWiFi"}, env, true},
+		{"other ssid", Trigger{SSID: "Home"}, env, false},
+		{"vpn", Trigger{VPN: true}, env, true},
+		{"vpn down", Trigger{VPN: true}, Environment{SSID: "CorpWiFi"}, false},
+		{"ssid and vpn", Trigger{SSID: "CorpWiFi", VPN: true}, env, true},
+		{"ssid and missing vpn", Trigger{SSID: "CorpWiFi", VPN: true}, Environment{SSID: "CorpWiFi"}, false},
+		{"path", Trigger{Path: existing}, env, true},
+		{"missing path", Trigger{Path: filepath.Join(existing, "missing")}, env, false},
+		{"empty trigger", Trigger{}, env, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, tt.trigger.Matches(tt.env))
+		})
+	}
+}
+
+func TestTrigger_MatchesHomePath(t *testing.T) {
+	t.Parallel()
+
+	if home, err := os.UserHomeDir(); err == nil {
+		if _, err := os.Stat(home); err == nil {
+			assert.True(t, Trigger{Path: "~/"}.Matches(Environment{}))
+		}
+	}
+
+	var checked string
+	env := Environment{PathExists: func(p string) bool { checked = p; return true }}
+	assert.True(t, Trigger{Path: "~/work/.corp"}.Matches(env))
+	assert.Equal(t, "~/work/.corp", checked, "custom checkers receive the raw path")
+}
+
+func TestTrigger_String(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "ssid=Corp, vpn, path=~/work", Trigger{SSID: "Corp", VPN: true, Path: "~/work"}.String())
+}
+
+func TestSelect(t *testing.T) {
+	t.Parallel()
+
+	candidates := []Candidate{
+		{Name: "personal", Fallback: true},
+		{Name: "work", Triggers: []Trigger{{SSID: "CorpWiFi"}, {VPN: true}}},
+		{Name: "client", Triggers: []Trigger{{VPN: true}}},
+	}
+
+	sel, ok := Select(candidates, Environment{VPNConnected: true})
+	assert.True(t, ok)
+	assert.Equal(t, Selection{Profile: "work", Reason: "vpn"}, sel, "first matching candidate wins")
+
+	sel, ok = Select(candidates, Environment{SSID: "Home"})
+	assert.True(t, ok)
+	assert.Equal(t, Selection{Profile: "personal", Reason: "fallback"}, sel)
+
+	_, ok = Select(candidates[1:], Environment{SSID: "Home"})
+	assert.False(t, ok)
+}
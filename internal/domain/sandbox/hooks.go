@@ -0,0 +1,187 @@
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/capability"
+)
+
+// ErrHookFailed is returned when a plugin hook fails or returns an error.
+var ErrHookFailed = errors.New("plugin hook failed")
+
+// Hook identifies a provider plugin entry point.
+//
+// Provider plugins are WASI command modules. Each hook invocation runs the
+// module once with a JSON HookRequest on stdin; the module writes a JSON
+// HookResponse to stdout. Any language that targets wasip1 can implement
+// the protocol.
+type Hook string
+
+// Provider plugin hooks.
+const (
+	// HookCapture reads the current machine state into a config section.
+	HookCapture Hook = "capture"
+	// HookPlan returns the steps needed to reach the desired config.
+	HookPlan Hook = "plan"
+	// HookApply applies a single planned step.
+	HookApply Hook = "apply"
+)
+
+// HookRequest is the input passed to a plugin hook on stdin.
+type HookRequest struct {
+	// Hook is the entry point being invoked.
+	Hook Hook `json:"hook"`
+	// Provider is the provider name from the plugin manifest.
+	Provider string `json:"provider"`
+	// Config is the provider's config section.
+	Config map[string]interface{} `json:"config,omitempty"`
+	// Step is the step ID to apply (apply only).
+	Step string `json:"step,omitempty"`
+	// DryRun asks the plugin not to make changes.
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// HookStep is a unit of work returned by the plan hook.
+type HookStep struct {
+	// ID is unique within the provider.
+	ID string `json:"id"`
+	// Description is a one-line summary of the step.
+	Description string `json:"description"`
+	// NeedsApply is false when the system already matches.
+	NeedsApply bool `json:"needs_apply"`
+	// DependsOn lists step IDs within the same provider.
+	DependsOn []string `json:"depends_on,omitempty"`
+	// Current and Desired describe the change for diffs.
+	Current string `json:"current,omitempty"`
+	Desired string `json:"desired,omitempty"`
+}
+
+// HookResponse is the output a plugin hook writes to stdout.
+type HookResponse struct {
+	// Steps is returned by the plan hook.
+	Steps []HookStep `json:"steps,omitempty"`
+	// Captured is the config section returned by the capture hook.
+	Captured map[string]interface{} `json:"captured,omitempty"`
+	// Messages are informational lines to show the user.
+	Messages []string `json:"messages,omitempty"`
+	// Error reports a plugin-side failure.
+	Error string `json:"error,omitempty"`
+}
+
+// servicesSetter is implemented by sandboxes that accept host services.
+type servicesSetter interface {
+	SetServices(services *HostServices)
+}
+
+// HookRunner invokes provider plugin hooks in a sandbox.
+type HookRunner struct {
+	runtime  Runtime
+	config   Config
+	services *HostServices
+}
+
+// NewHookRunner creates a hook runner. The config is the base sandbox
+// configuration; filesystem and network access are enabled per plugin only
+// when the plugin declares a matching capability.
+func NewHookRunner(runtime Runtime, config Config, services *HostServices) *HookRunner {
+	return &HookRunner{
+		runtime:  runtime,
+		config:   config,
+		services: services,
+	}
+}
+
+// ConfigFor returns the sandbox configuration used for a plugin.
+func (r *HookRunner) ConfigFor(plugin *Plugin) Config {
+	cfg := r.config
+	if cfg.Mode != ModeRestricted || plugin.Capabilities == nil {
+		return cfg
+	}
+	for _, c := range plugin.Capabilities.FullSet().List() {
+		switch c.Category() {
+		case capability.CategoryFiles:
+			cfg.AllowFileSystem = true
+		case capability.CategoryNetwork:
+			cfg.AllowNetwork = true
+		}
+	}
+	return cfg
+}
+
+// Invoke runs a hook and decodes its response.
+func (r *HookRunner) Invoke(ctx context.Context, plugin *Plugin, req HookRequest) (*HookResponse, error) {
+	cfg := r.ConfigFor(plugin)
+
+	sb, err := r.runtime.NewSandbox(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sandbox: %w", err)
+	}
+	defer func() { _ = sb.Close() }()
+
+	if setter, ok := sb.(servicesSetter); ok {
+		setter.SetServices(r.services)
+	}
+
+	if err := sb.Validate(ctx, plugin); err != nil {
+		if errors.Is(err, ErrCapabilityDenied) && r.auditor() != nil {
+			_ = r.auditor().LogCapabilityDenied(ctx, plugin.ID, declaredCapabilities(plugin), err.Error())
+		}
+		return nil, fmt.Errorf("plugin %s: %w", plugin.ID, err)
+	}
+
+	input, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode hook request: %w", err)
+	}
+
+	result, err := sb.Execute(ctx, plugin, input)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", plugin.ID, err)
+	}
+
+	if r.auditor() != nil {
+		_ = r.auditor().LogPluginExecuted(ctx, plugin.ID, string(cfg.Mode), result.Duration, result.Success, result.Error)
+	}
+
+	if result.Error != nil {
+		return nil, fmt.Errorf("%w: %s %s: %w%s", ErrHookFailed, plugin.ID, req.Hook, result.Error, stderrSuffix(result.Errors))
+	}
+
+	var resp HookResponse
+	if err := json.Unmarshal(bytes.TrimSpace(result.Output), &resp); err != nil {
+		return nil, fmt.Errorf("%w: %s %s: invalid response: %w", ErrHookFailed, plugin.ID, req.Hook, err)
+	}
+	if resp.Error != "" {
+		return &resp, fmt.Errorf("%w: %s %s: %s", ErrHookFailed, plugin.ID, req.Hook, resp.Error)
+	}
+	return &resp, nil
+}
+
+func (r *HookRunner) auditor() Auditor {
+	if r.services == nil {
+		return nil
+	}
+	return r.services.Audit
+}
+
+// declaredCapabilities returns a plugin's capabilities as strings.
+func declaredCapabilities(plugin *Plugin) []string {
+	if plugin.Capabilities == nil {
+		return nil
+	}
+	return plugin.Capabilities.FullSet().Strings()
+}
+
+// stderrSuffix formats captured stderr for inclusion in an error message.
+func stderrSuffix(stderr []byte) string {
+	msg := strings.TrimSpace(string(stderr))
+	if msg == "" {
+		return ""
+	}
+	return " (stderr: " + msg + ")"
+}
@@ -0,0 +1,248 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/capability"
+)
+
+type recordingAuditor struct {
+	mu       sync.Mutex
+	executed []string
+	denied   []string
+}
+
+func (a *recordingAuditor) LogPluginExecuted(_ context.Context, plugin, _ string, _ time.Duration, _ bool, _ error) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.executed = append(a.executed, plugin)
+	return nil
+}
+
+func (a *recordingAuditor) LogCapabilityDenied(_ context.Context, plugin string, _ []string, _ string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.denied = append(a.denied, plugin)
+	return nil
+}
+
+// stubRuntime returns sandboxes with a canned execution result.
+type stubRuntime struct {
+	result      *ExecutionResult
+	validateErr error
+	input       []byte
+	config      Config
+}
+
+func (r *stubRuntime) NewSandbox(config Config) (Sandbox, error) {
+	r.config = config
+	return &stubSandbox{runtime: r}, nil
+}
+func (r *stubRuntime) IsAvailable() bool { return true }
+func (r *stubRuntime) Version() string   { return "stub" }
+func (r *stubRuntime) Close() error      { return nil }
+
+type stubSandbox struct {
+	runtime *stubRuntime
+}
+
+func (s *stubSandbox) Execute(_ context.Context, _ *Plugin, input []byte) (*ExecutionResult, error) {
+	s.runtime.input = input
+	return s.runtime.result, nil
+}
+func (s *stubSandbox) Validate(_ context.Context, _ *Plugin) error { return s.runtime.validateErr }
+func (s *stubSandbox) Close() error                                { return nil }
+
+func TestHookRunner_Invoke(t *testing.T) {
+	t.Parallel()
+
+	plugin := &Plugin{ID: "demo", Name: "demo", Module: []byte{0}}
+
+	t.Run("decodes response and audits execution", func(t *testing.T) {
+		t.Parallel()
+		rt := &stubRuntime{result: &ExecutionResult{
+			Success: true,
+			Output:  []byte(`{"steps":[{"id":"a","description":"A","needs_apply":true}]}` + "\n"),
+		}}
+		auditor := &recordingAuditor{}
+		runner := NewHookRunner(rt, DefaultConfig(), &HostServices{Audit: auditor})
+
+		resp, err := runner.Invoke(context.Background(), plugin, HookRequest{Hook: HookPlan, Provider: "demo"})
+		require.NoError(t, err)
+		require.Len(t, resp.Steps, 1)
+		assert.Equal(t, "a", resp.Steps[0].ID)
+		assert.True(t, resp.Steps[0].NeedsApply)
+		assert.JSONEq(t, `{"hook":"plan","provider":"demo"}`, string(rt.input))
+		assert.Equal(t, []string{"demo"}, auditor.executed)
+	})
+
+	t.Run("returns plugin error", func(t *testing.T) {
+		t.Parallel()
+		rt := &stubRuntime{result: &ExecutionResult{Success: true, Output: []byte(`{"error":"nope"}`)}}
+		runner := NewHookRunner(rt, DefaultConfig(), nil)
+
+		_, err := runner.Invoke(context.Background(), plugin, HookRequest{Hook: HookApply})
+		require.ErrorIs(t, err, ErrHookFailed)
+		assert.Contains(t, err.Error(), "nope")
+	})
+
+	t.Run("includes stderr on failure", func(t *testing.T) {
+		t.Parallel()
+		rt := &stubRuntime{result: &ExecutionResult{
+			Error:  errors.New("exit code 1"),
+			Errors: []byte("something broke\n"),
+		}}
+		runner := NewHookRunner(rt, DefaultConfig(), nil)
+
+		_, err := runner.Invoke(context.Background(), plugin, HookRequest{Hook: HookApply})
+		require.ErrorIs(t, err, ErrHookFailed)
+		assert.Contains(t, err.Error(), "stderr: something broke")
+	})
+
+	t.Run("rejects invalid output", func(t *testing.T) {
+		t.Parallel()
+		rt := &stubRuntime{result: &ExecutionResult{Success: true, Output: []byte("not json")}}
+		runner := NewHookRunner(rt, DefaultConfig(), nil)
+
+		_, err := runner.Invoke(context.Background(), plugin, HookRequest{Hook: HookPlan})
+		require.ErrorIs(t, err, ErrHookFailed)
+		assert.Contains(t, err.Error(), "invalid response")
+	})
+
+	t.Run("audits capability denial", func(t *testing.T) {
+		t.Parallel()
+		rt := &stubRuntime{validateErr: ErrCapabilityDenied}
+		auditor := &recordingAuditor{}
+		runner := NewHookRunner(rt, DefaultConfig(), &HostServices{Audit: auditor})
+
+		_, err := runner.Invoke(context.Background(), plugin, HookRequest{Hook: HookPlan})
+		require.ErrorIs(t, err, ErrCapabilityDenied)
+		assert.Equal(t, []string{"demo"}, auditor.denied)
+		assert.Empty(t, auditor.executed)
+	})
+}
+
+func TestHookRunner_ConfigFor(t *testing.T) {
+	t.Parallel()
+
+	caps := capability.NewRequirements()
+	caps.AddCapability(capability.CapFilesRead, "read config")
+	withFiles := &Plugin{ID: "files", Capabilities: caps}
+
+	runner := NewHookRunner(&stubRuntime{}, DefaultConfig(), nil)
+	cfg := runner.ConfigFor(withFiles)
+	assert.True(t, cfg.AllowFileSystem)
+	assert.False(t, cfg.AllowNetwork)
+
+	cfg = runner.ConfigFor(&Plugin{ID: "none"})
+	assert.False(t, cfg.AllowFileSystem)
+	assert.False(t, cfg.AllowNetwork)
+
+	isolated := NewHookRunner(&stubRuntime{}, FullIsolationConfig(), nil)
+	cfg = isolated.ConfigFor(withFiles)
+	assert.False(t, cfg.AllowFileSystem)
+}
+
+// buildHookPlugin compiles testdata/hookplugin to WASM.
+func buildHookPlugin(t *testing.T) []byte {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("skipping WASM build in short mode")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	out := filepath.Join(t.TempDir(), "hookplugin.wasm")
+	cmd := exec.Command(goBin, "build", "-o", out, "./testdata/hookplugin")
+	cmd.Env = append(os.Environ(), "GOOS=wasip1", "GOARCH=wasm", "CGO_ENABLED=0")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("cannot build wasip1 test plugin: %v\n%s", err, output)
+	}
+
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	return data
+}
+
+func TestHookRunner_WASMPlugin(t *testing.T) {
+	t.Parallel()
+
+	module := buildHookPlugin(t)
+	ctx := context.Background()
+
+	runtime, err := NewWazeroRuntime(ctx)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = runtime.Close() })
+
+	caps := capability.NewRequirements()
+	caps.AddCapability(capability.CapFilesRead, "check files")
+	declared := &Plugin{ID: "hook-demo", Name: "hook-demo", Module: module, Capabilities: caps}
+	undeclared := &Plugin{ID: "hook-undeclared", Name: "hook-undeclared", Module: module}
+
+	existing := filepath.Join(t.TempDir(), "exists.txt")
+	require.NoError(t, os.WriteFile(existing, []byte("x"), 0o600))
+
+	auditor := &recordingAuditor{}
+	services := NewSystemServices(capability.DefaultPolicy(), nil)
+	services.Audit = auditor
+	runner := NewHookRunner(runtime, DefaultConfig(), services)
+
+	// Subtests share the runtime and run sequentially.
+	t.Run("plan", func(t *testing.T) {
+		resp, err := runner.Invoke(ctx, declared, HookRequest{
+			Hook:   HookPlan,
+			Config: map[string]interface{}{"items": []interface{}{"present", "missing"}},
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Steps, 2)
+		assert.Equal(t, "item:present", resp.Steps[0].ID)
+		assert.False(t, resp.Steps[0].NeedsApply)
+		assert.True(t, resp.Steps[1].NeedsApply)
+	})
+
+	t.Run("capture", func(t *testing.T) {
+		resp, err := runner.Invoke(ctx, declared, HookRequest{Hook: HookCapture})
+		require.NoError(t, err)
+		assert.Equal(t, []interface{}{"present"}, resp.Captured["items"])
+	})
+
+	t.Run("apply uses declared capability", func(t *testing.T) {
+		resp, err := runner.Invoke(ctx, declared, HookRequest{
+			Hook:   HookApply,
+			Config: map[string]interface{}{"path": existing},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"exists=1"}, resp.Messages)
+	})
+
+	t.Run("apply denies undeclared capability", func(t *testing.T) {
+		_, err := runner.Invoke(ctx, undeclared, HookRequest{
+			Hook:   HookApply,
+			Config: map[string]interface{}{"path": existing},
+		})
+		require.ErrorIs(t, err, ErrHookFailed)
+		assert.Contains(t, err.Error(), "file_exists returned -1")
+		assert.Contains(t, auditor.denied, "hook-undeclared")
+	})
+
+	t.Run("reports exit code and stderr", func(t *testing.T) {
+		_, err := runner.Invoke(ctx, declared, HookRequest{Hook: "crash"})
+		require.ErrorIs(t, err, ErrHookFailed)
+		assert.Contains(t, err.Error(), "exit code 3")
+		assert.Contains(t, err.Error(), "boom")
+	})
+
+	assert.Contains(t, auditor.executed, "hook-demo")
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"io"
+	"time"
 
 	"github.com/felixgeelhaar/preflight/internal/domain/capability"
 )
@@ -124,6 +125,9 @@ type HostServices struct {
 
 	// Policy for capability checks
 	Policy *capability.Policy
+
+	// Audit records capability denials and executions (optional)
+	Audit Auditor
 }
 
 // FileSystem interface for file operations.
@@ -171,6 +175,16 @@ type HTTPClient interface {
 	Post(ctx context.Context, url string, contentType string, body []byte) ([]byte, int, error)
 }
 
+// Auditor records security-relevant plugin activity.
+// It is satisfied by *audit.Service.
+type Auditor interface {
+	// LogPluginExecuted records a plugin execution
+	LogPluginExecuted(ctx context.Context, plugin, sandboxMode string, duration time.Duration, success bool, err error) error
+
+	// LogCapabilityDenied records a capability the plugin was refused
+	LogCapabilityDenied(ctx context.Context, plugin string, capabilities []string, reason string) error
+}
+
 // Logger interface for plugin logging.
 type Logger interface {
 	// Info logs an info message
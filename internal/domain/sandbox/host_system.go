@@ -0,0 +1,138 @@
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/capability"
+)
+
+// maxHTTPResponseBytes bounds response bodies returned to plugins.
+const maxHTTPResponseBytes = 10 * 1024 * 1024
+
+// NewSystemServices creates services backed by the local machine.
+// Every call is still gated by the sandbox config, the plugin's declared
+// capabilities, and the policy before it reaches these implementations.
+func NewSystemServices(policy *capability.Policy, logger Logger) *HostServices {
+	if logger == nil {
+		logger = NullLogger{}
+	}
+	return &HostServices{
+		FileSystem:     OSFileSystem{},
+		PackageManager: NullPackageManager{},
+		Shell:          ExecShell{},
+		HTTP:           &NetHTTPClient{Client: &http.Client{Timeout: 30 * time.Second}},
+		Logger:         logger,
+		Policy:         policy,
+	}
+}
+
+// OSFileSystem implements FileSystem using the os package.
+type OSFileSystem struct{}
+
+// ReadFile reads a file.
+func (OSFileSystem) ReadFile(_ context.Context, path string) ([]byte, error) {
+	return os.ReadFile(expandHome(path))
+}
+
+// WriteFile writes a file, creating parent directories as needed.
+func (OSFileSystem) WriteFile(_ context.Context, path string, data []byte) error {
+	path = expandHome(path)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Exists checks if a path exists.
+func (OSFileSystem) Exists(_ context.Context, path string) (bool, error) {
+	_, err := os.Stat(expandHome(path))
+	if err == nil {
+		return true, nil
+	}
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+// Remove removes a file or empty directory.
+func (OSFileSystem) Remove(_ context.Context, path string) error {
+	return os.Remove(expandHome(path))
+}
+
+// ExecShell implements Shell by running commands directly, without a shell.
+type ExecShell struct{}
+
+// Exec executes a command and returns its combined output.
+func (s ExecShell) Exec(ctx context.Context, cmd string, args ...string) ([]byte, error) {
+	return s.ExecWithInput(ctx, nil, cmd, args...)
+}
+
+// ExecWithInput executes a command with stdin and returns its combined output.
+func (ExecShell) ExecWithInput(ctx context.Context, input io.Reader, cmd string, args ...string) ([]byte, error) {
+	c := exec.CommandContext(ctx, cmd, args...) //nolint:gosec // gated by shell:execute capability
+	c.Stdin = input
+	return c.CombinedOutput()
+}
+
+// NetHTTPClient implements HTTPClient using net/http.
+type NetHTTPClient struct {
+	Client *http.Client
+}
+
+// Get performs an HTTP GET.
+func (c *NetHTTPClient) Get(ctx context.Context, url string) ([]byte, int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	return c.do(req)
+}
+
+// Post performs an HTTP POST.
+func (c *NetHTTPClient) Post(ctx context.Context, url string, contentType string, body []byte) ([]byte, int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	return c.do(req)
+}
+
+func (c *NetHTTPClient) do(req *http.Request) ([]byte, int, error) {
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPResponseBytes))
+	if err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("failed to read response: %w", err)
+	}
+	return data, resp.StatusCode, nil
+}
+
+// expandHome expands a leading ~/ to the user's home directory.
+func expandHome(path string) string {
+	if len(path) < 2 || path[:2] != "~/" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
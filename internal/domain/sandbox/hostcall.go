@@ -0,0 +1,310 @@
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/capability"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+)
+
+// Host function result codes. Functions that return data write it into a
+// caller-provided buffer and return the number of bytes written; failures
+// are reported as one of these negative codes.
+const (
+	// HostErrDenied means the plugin lacks the capability for the call.
+	HostErrDenied int32 = -1
+	// HostErrFailed means the host operation itself failed.
+	HostErrFailed int32 = -2
+	// HostErrBufferTooSmall means the result does not fit in the buffer.
+	HostErrBufferTooSmall int32 = -3
+	// HostErrInvalidArgs means the arguments could not be read from memory.
+	HostErrInvalidArgs int32 = -4
+)
+
+// hostCallKey is the context key for the active host call.
+type hostCallKey struct{}
+
+// hostCall carries the executing plugin and its services to host functions.
+// Host functions are registered once per runtime, so per-execution state must
+// travel with the context instead of being captured by closures.
+type hostCall struct {
+	plugin   *Plugin
+	services *HostServices
+	config   Config
+}
+
+func withHostCall(ctx context.Context, call *hostCall) context.Context {
+	return context.WithValue(ctx, hostCallKey{}, call)
+}
+
+func hostCallFrom(ctx context.Context) *hostCall {
+	call, _ := ctx.Value(hostCallKey{}).(*hostCall)
+	return call
+}
+
+// authorize checks that the executing plugin may use a capability.
+// A capability must be enabled for the sandbox, declared by the plugin
+// (unless trusted), and allowed by both the sandbox and host policies.
+// Denials are logged and audited.
+func (c *hostCall) authorize(ctx context.Context, required capability.Capability) error {
+	err := c.check(required)
+	if err == nil {
+		return nil
+	}
+
+	if c.services != nil {
+		if c.services.Logger != nil {
+			c.services.Logger.Warn(fmt.Sprintf("plugin %s denied %s: %v", c.plugin.ID, required, err))
+		}
+		if c.services.Audit != nil {
+			_ = c.services.Audit.LogCapabilityDenied(ctx, c.plugin.ID, []string{required.String()}, err.Error())
+		}
+	}
+	return err
+}
+
+func (c *hostCall) check(required capability.Capability) error {
+	if c.services == nil || c.config.Mode == ModeFull {
+		return fmt.Errorf("%w: %s in full isolation", ErrCapabilityDenied, required)
+	}
+
+	switch required.Category() {
+	case capability.CategoryFiles:
+		if !c.config.AllowFileSystem {
+			return fmt.Errorf("%w: filesystem access disabled", ErrCapabilityDenied)
+		}
+	case capability.CategoryNetwork:
+		if !c.config.AllowNetwork {
+			return fmt.Errorf("%w: network access disabled", ErrCapabilityDenied)
+		}
+	}
+
+	if c.config.Mode != ModeTrusted {
+		if c.plugin.Capabilities == nil || !c.plugin.Capabilities.FullSet().Matches(required) {
+			return fmt.Errorf("%w: %s not declared by plugin", ErrCapabilityDenied, required)
+		}
+	}
+
+	if c.config.Policy != nil {
+		if err := c.config.Policy.Check(required); err != nil {
+			return fmt.Errorf("%w: %w", ErrCapabilityDenied, err)
+		}
+	}
+	if err := c.services.CheckCapability(required); err != nil {
+		return fmt.Errorf("%w: %w", ErrCapabilityDenied, err)
+	}
+	return nil
+}
+
+// registerHostFunctions adds the "preflight" host module to the runtime.
+func registerHostFunctions(ctx context.Context, runtime wazero.Runtime) error {
+	builder := runtime.NewHostModuleBuilder("preflight")
+
+	// Log functions (always available)
+	logFn := func(level func(Logger, string)) func(context.Context, api.Module, uint32, uint32) {
+		return func(ctx context.Context, m api.Module, ptr, length uint32) {
+			call := hostCallFrom(ctx)
+			if call == nil || call.services == nil || call.services.Logger == nil {
+				return
+			}
+			level(call.services.Logger, readString(m, ptr, length))
+		}
+	}
+	builder.NewFunctionBuilder().WithFunc(logFn(Logger.Info)).Export("log_info")
+	builder.NewFunctionBuilder().WithFunc(logFn(Logger.Warn)).Export("log_warn")
+	builder.NewFunctionBuilder().WithFunc(logFn(Logger.Error)).Export("log_error")
+
+	// read_file(path_ptr, path_len, buf_ptr, buf_cap) -> bytes written
+	builder.NewFunctionBuilder().
+		WithFunc(func(ctx context.Context, m api.Module, pathPtr, pathLen, bufPtr, bufCap uint32) int32 {
+			call, code := authorizedCall(ctx, capability.CapFilesRead)
+			if call == nil {
+				return code
+			}
+			path, ok := readArg(m, pathPtr, pathLen)
+			if !ok {
+				return HostErrInvalidArgs
+			}
+			data, err := call.services.FileSystem.ReadFile(ctx, path)
+			if err != nil {
+				return HostErrFailed
+			}
+			return writeResult(m, bufPtr, bufCap, data)
+		}).
+		Export("read_file")
+
+	// write_file(path_ptr, path_len, data_ptr, data_len) -> 0
+	builder.NewFunctionBuilder().
+		WithFunc(func(ctx context.Context, m api.Module, pathPtr, pathLen, dataPtr, dataLen uint32) int32 {
+			call, code := authorizedCall(ctx, capability.CapFilesWrite)
+			if call == nil {
+				return code
+			}
+			path, ok := readArg(m, pathPtr, pathLen)
+			if !ok {
+				return HostErrInvalidArgs
+			}
+			data, ok := m.Memory().Read(dataPtr, dataLen)
+			if !ok {
+				return HostErrInvalidArgs
+			}
+			if err := call.services.FileSystem.WriteFile(ctx, path, bytes.Clone(data)); err != nil {
+				return HostErrFailed
+			}
+			return 0
+		}).
+		Export("write_file")
+
+	// file_exists(path_ptr, path_len) -> 1 or 0
+	builder.NewFunctionBuilder().
+		WithFunc(func(ctx context.Context, m api.Module, pathPtr, pathLen uint32) int32 {
+			call, code := authorizedCall(ctx, capability.CapFilesRead)
+			if call == nil {
+				return code
+			}
+			path, ok := readArg(m, pathPtr, pathLen)
+			if !ok {
+				return HostErrInvalidArgs
+			}
+			exists, err := call.services.FileSystem.Exists(ctx, path)
+			if err != nil {
+				return HostErrFailed
+			}
+			if exists {
+				return 1
+			}
+			return 0
+		}).
+		Export("file_exists")
+
+	// shell_exec(argv_ptr, argv_len, buf_ptr, buf_cap) -> bytes written
+	// argv is the command and its arguments separated by NUL bytes.
+	builder.NewFunctionBuilder().
+		WithFunc(func(ctx context.Context, m api.Module, argvPtr, argvLen, bufPtr, bufCap uint32) int32 {
+			call, code := authorizedCall(ctx, capability.CapShellExecute)
+			if call == nil {
+				return code
+			}
+			raw, ok := readArg(m, argvPtr, argvLen)
+			if !ok || raw == "" {
+				return HostErrInvalidArgs
+			}
+			argv := strings.Split(strings.TrimRight(raw, "\x00"), "\x00")
+			out, err := call.services.Shell.Exec(ctx, argv[0], argv[1:]...)
+			if err != nil {
+				return HostErrFailed
+			}
+			return writeResult(m, bufPtr, bufCap, out)
+		}).
+		Export("shell_exec")
+
+	// http_get(url_ptr, url_len, buf_ptr, buf_cap) -> bytes written
+	builder.NewFunctionBuilder().
+		WithFunc(func(ctx context.Context, m api.Module, urlPtr, urlLen, bufPtr, bufCap uint32) int32 {
+			call, code := authorizedCall(ctx, capability.CapNetworkFetch)
+			if call == nil {
+				return code
+			}
+			url, ok := readArg(m, urlPtr, urlLen)
+			if !ok {
+				return HostErrInvalidArgs
+			}
+			body, status, err := call.services.HTTP.Get(ctx, url)
+			if err != nil || status >= 400 {
+				return HostErrFailed
+			}
+			return writeResult(m, bufPtr, bufCap, body)
+		}).
+		Export("http_get")
+
+	_, err := builder.Instantiate(ctx)
+	return err
+}
+
+// authorizedCall returns the active host call when the capability is allowed,
+// or nil and the result code to return to the plugin.
+func authorizedCall(ctx context.Context, required capability.Capability) (*hostCall, int32) {
+	call := hostCallFrom(ctx)
+	if call == nil {
+		return nil, HostErrDenied
+	}
+	if err := call.authorize(ctx, required); err != nil {
+		return nil, HostErrDenied
+	}
+	return call, 0
+}
+
+// readArg reads a string argument from WASM memory.
+func readArg(m api.Module, ptr, length uint32) (string, bool) {
+	if m == nil || m.Memory() == nil {
+		return "", false
+	}
+	data, ok := m.Memory().Read(ptr, length)
+	if !ok {
+		return "", false
+	}
+	return string(data), true
+}
+
+// writeResult copies data into a caller-provided buffer.
+func writeResult(m api.Module, bufPtr, bufCap uint32, data []byte) int32 {
+	if uint64(len(data)) > uint64(bufCap) {
+		return HostErrBufferTooSmall
+	}
+	if !m.Memory().Write(bufPtr, data) {
+		return HostErrInvalidArgs
+	}
+	return int32(len(data)) //nolint:gosec // bounded by bufCap
+}
+
+// limitedBuffer collects output up to a byte limit and drops the rest.
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int64
+	truncated bool
+}
+
+func newLimitedBuffer(limit int64) *limitedBuffer {
+	return &limitedBuffer{limit: limit}
+}
+
+// Write implements io.Writer. It never fails so a chatty plugin is not
+// killed mid-write; truncation is reported after execution instead.
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.limit <= 0 {
+		return b.buf.Write(p)
+	}
+	remaining := b.limit - int64(b.buf.Len())
+	if remaining <= 0 {
+		b.truncated = len(p) > 0 || b.truncated
+		return len(p), nil
+	}
+	if int64(len(p)) > remaining {
+		b.buf.Write(p[:remaining])
+		b.truncated = true
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+// Bytes returns the collected output.
+func (b *limitedBuffer) Bytes() []byte {
+	if b.buf.Len() == 0 {
+		return nil
+	}
+	return b.buf.Bytes()
+}
+
+// Len returns the number of bytes collected.
+func (b *limitedBuffer) Len() int {
+	return b.buf.Len()
+}
+
+// Truncated reports whether output was dropped.
+func (b *limitedBuffer) Truncated() bool {
+	return b.truncated
+}
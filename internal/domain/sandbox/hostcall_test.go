@@ -0,0 +1,96 @@
+package sandbox
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/capability"
+)
+
+func TestHostCall_Authorize(t *testing.T) {
+	t.Parallel()
+
+	declared := capability.NewRequirements()
+	declared.AddCapability(capability.CapFilesRead, "read config")
+	declared.AddCapability(capability.CapShellExecute, "run tool")
+	plugin := &Plugin{ID: "demo", Capabilities: declared}
+
+	restricted := DefaultConfig()
+	restricted.AllowFileSystem = true
+
+	tests := []struct {
+		name     string
+		config   Config
+		services *HostServices
+		plugin   *Plugin
+		required capability.Capability
+		allowed  bool
+	}{
+		{"declared and granted", restricted, NewSystemServices(capability.DefaultPolicy(), nil), plugin, capability.CapFilesRead, true},
+		{"no services", restricted, nil, plugin, capability.CapFilesRead, false},
+		{"full isolation", FullIsolationConfig(), NewSystemServices(capability.FullAccessPolicy(), nil), plugin, capability.CapFilesRead, false},
+		{"filesystem disabled", DefaultConfig(), NewSystemServices(capability.DefaultPolicy(), nil), plugin, capability.CapFilesRead, false},
+		{"not declared", restricted, NewSystemServices(capability.DefaultPolicy(), nil), plugin, capability.CapFilesWrite, false},
+		{"declared but not granted", restricted, NewSystemServices(capability.FullAccessPolicy(), nil), plugin, capability.CapShellExecute, false},
+		{"trusted skips declaration", TrustedConfig(), NewSystemServices(capability.FullAccessPolicy(), nil), &Plugin{ID: "trusted"}, capability.CapShellExecute, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			auditor := &recordingAuditor{}
+			if tt.services != nil {
+				tt.services.Audit = auditor
+			}
+			call := &hostCall{plugin: tt.plugin, services: tt.services, config: tt.config}
+
+			err := call.authorize(context.Background(), tt.required)
+			if tt.allowed {
+				require.NoError(t, err)
+				assert.Empty(t, auditor.denied)
+				return
+			}
+			require.ErrorIs(t, err, ErrCapabilityDenied)
+			if tt.services != nil {
+				assert.Equal(t, []string{tt.plugin.ID}, auditor.denied)
+			}
+		})
+	}
+}
+
+func TestLimitedBuffer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("truncates at limit", func(t *testing.T) {
+		t.Parallel()
+		buf := newLimitedBuffer(5)
+		n, err := buf.Write([]byte("abc"))
+		require.NoError(t, err)
+		assert.Equal(t, 3, n)
+
+		n, err = buf.Write([]byte("defg"))
+		require.NoError(t, err)
+		assert.Equal(t, 4, n)
+		assert.Equal(t, "abcde", string(buf.Bytes()))
+		assert.True(t, buf.Truncated())
+
+		_, _ = buf.Write([]byte("h"))
+		assert.Equal(t, 5, buf.Len())
+	})
+
+	t.Run("unlimited", func(t *testing.T) {
+		t.Parallel()
+		buf := newLimitedBuffer(0)
+		_, _ = buf.Write([]byte("hello"))
+		assert.Equal(t, "hello", string(buf.Bytes()))
+		assert.False(t, buf.Truncated())
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+		assert.Nil(t, newLimitedBuffer(10).Bytes())
+	})
+}
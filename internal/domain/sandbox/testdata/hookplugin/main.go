@@ -0,0 +1,78 @@
+//go:build wasip1
+
+// Command hookplugin is a provider plugin used by the sandbox tests.
+// Build with: GOOS=wasip1 GOARCH=wasm go build -o hookplugin.wasm .
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"unsafe"
+)
+
+//go:wasmimport preflight file_exists
+func fileExists(ptr, length uint32) int32
+
+type request struct {
+	Hook   string                 `json:"hook"`
+	Config map[string]interface{} `json:"config"`
+	Step   string                 `json:"step"`
+}
+
+type step struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+	NeedsApply  bool   `json:"needs_apply"`
+	Desired     string `json:"desired,omitempty"`
+}
+
+type response struct {
+	Steps    []step                 `json:"steps,omitempty"`
+	Captured map[string]interface{} `json:"captured,omitempty"`
+	Messages []string               `json:"messages,omitempty"`
+	Error    string                 `json:"error,omitempty"`
+}
+
+func main() {
+	var req request
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		fmt.Fprintln(os.Stderr, "bad request:", err)
+		os.Exit(2)
+	}
+
+	var resp response
+	switch req.Hook {
+	case "plan":
+		items, _ := req.Config["items"].([]interface{})
+		for _, item := range items {
+			name := fmt.Sprint(item)
+			resp.Steps = append(resp.Steps, step{
+				ID:          "item:" + name,
+				Description: "Ensure " + name,
+				NeedsApply:  name != "present",
+				Desired:     name,
+			})
+		}
+	case "capture":
+		resp.Captured = map[string]interface{}{"items": []string{"present"}}
+	case "apply":
+		path, _ := req.Config["path"].(string)
+		if path == "" {
+			break
+		}
+		code := fileExists(uint32(uintptr(unsafe.Pointer(unsafe.StringData(path)))), uint32(len(path)))
+		if code < 0 {
+			resp.Error = fmt.Sprintf("file_exists returned %d", code)
+		} else {
+			resp.Messages = append(resp.Messages, fmt.Sprintf("exists=%d", code))
+		}
+	case "crash":
+		fmt.Fprintln(os.Stderr, "boom")
+		os.Exit(3)
+	default:
+		resp.Error = "unknown hook " + req.Hook
+	}
+
+	_ = json.NewEncoder(os.Stdout).Encode(resp)
+}
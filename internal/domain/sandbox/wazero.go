@@ -1,6 +1,7 @@
 package sandbox
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -10,12 +11,14 @@ import (
 	"github.com/tetratelabs/wazero"
 	"github.com/tetratelabs/wazero/api"
 	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
 )
 
 // WazeroRuntime implements Runtime using Wazero.
 type WazeroRuntime struct {
 	runtime              wazero.Runtime
 	hostFunctionsEnabled bool
+	compiled             map[string]wazero.CompiledModule
 	mu                   sync.Mutex
 	closed               bool
 }
@@ -76,9 +79,33 @@ func (r *WazeroRuntime) Close() error {
 	}
 
 	r.closed = true
+	r.compiled = nil
 	return r.runtime.Close(context.Background())
 }
 
+// compile returns the compiled form of a module, reusing earlier compilations
+// of identical bytes. Compiling large modules dominates hook latency, and a
+// provider plugin is typically invoked once per step.
+func (r *WazeroRuntime) compile(ctx context.Context, module []byte) (wazero.CompiledModule, error) {
+	key := sha256Hex(module)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if compiled, ok := r.compiled[key]; ok {
+		return compiled, nil
+	}
+	compiled, err := r.runtime.CompileModule(ctx, module)
+	if err != nil {
+		return nil, err
+	}
+	if r.compiled == nil {
+		r.compiled = make(map[string]wazero.CompiledModule)
+	}
+	r.compiled[key] = compiled
+	return compiled, nil
+}
+
 // WazeroSandbox implements Sandbox using Wazero.
 type WazeroSandbox struct {
 	runtime       wazero.Runtime
@@ -96,7 +123,9 @@ func (s *WazeroSandbox) SetServices(services *HostServices) {
 }
 
 // Execute runs a plugin with the given input.
-func (s *WazeroSandbox) Execute(ctx context.Context, plugin *Plugin, _ []byte) (*ExecutionResult, error) {
+// The input is provided on the module's stdin; stdout and stderr are captured
+// up to the configured output limit.
+func (s *WazeroSandbox) Execute(ctx context.Context, plugin *Plugin, input []byte) (*ExecutionResult, error) {
 	if err := plugin.Validate(); err != nil {
 		return nil, fmt.Errorf("%w: %w", ErrPluginInvalid, err)
 	}
@@ -114,7 +143,7 @@ func (s *WazeroSandbox) Execute(ctx context.Context, plugin *Plugin, _ []byte) (
 	// Register host functions for plugin access (only once per runtime)
 	s.parentRuntime.mu.Lock()
 	if !s.parentRuntime.hostFunctionsEnabled {
-		if err := s.registerHostFunctions(ctx); err != nil {
+		if err := registerHostFunctions(ctx, s.runtime); err != nil {
 			s.parentRuntime.mu.Unlock()
 			result.Error = fmt.Errorf("failed to register host functions: %w", err)
 			result.Duration = time.Since(start)
@@ -124,18 +153,35 @@ func (s *WazeroSandbox) Execute(ctx context.Context, plugin *Plugin, _ []byte) (
 	}
 	s.parentRuntime.mu.Unlock()
 
+	// Host functions are shared by the runtime; the calling plugin and its
+	// services travel with the context.
+	s.mu.Lock()
+	services := s.services
+	s.mu.Unlock()
+	ctx = withHostCall(ctx, &hostCall{
+		plugin:   plugin,
+		services: services,
+		config:   s.config,
+	})
+
 	// Compile the module
-	compiled, err := s.runtime.CompileModule(ctx, plugin.Module)
+	compiled, err := s.parentRuntime.compile(ctx, plugin.Module)
 	if err != nil {
 		result.Error = fmt.Errorf("failed to compile module: %w", err)
 		result.Duration = time.Since(start)
 		return result, nil
 	}
-	defer func() { _ = compiled.Close(ctx) }()
+
+	stdout := newLimitedBuffer(s.config.Limits.MaxOutputBytes)
+	stderr := newLimitedBuffer(s.config.Limits.MaxOutputBytes)
 
 	// Create module config with resource limits
 	modConfig := wazero.NewModuleConfig().
 		WithName(plugin.ID).
+		WithArgs(plugin.ID).
+		WithStdin(bytes.NewReader(input)).
+		WithStdout(stdout).
+		WithStderr(stderr).
 		WithStartFunctions("_start", "_initialize")
 
 	// Note: Memory limits are enforced via the WASM module's defined limits
@@ -145,37 +191,52 @@ func (s *WazeroSandbox) Execute(ctx context.Context, plugin *Plugin, _ []byte) (
 	// Instantiate and run
 	instance, err := s.runtime.InstantiateModule(ctx, compiled, modConfig)
 	if err != nil {
-		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
-			result.Error = ErrSandboxTimeout
-		} else {
-			result.Error = fmt.Errorf("failed to instantiate module: %w", err)
-		}
-		result.Duration = time.Since(start)
+		result.Error = executionError(ctx, "failed to instantiate module", err)
+		s.finish(result, stdout, stderr, start)
 		return result, nil
 	}
 	defer func() { _ = instance.Close(ctx) }()
 
-	// Call the main function if it exists
-	mainFn := instance.ExportedFunction("main")
-	if mainFn == nil {
-		mainFn = instance.ExportedFunction("run")
-	}
+	// Call the main function if it exists; WASI commands already ran in _start
+	if !instance.IsClosed() {
+		mainFn := instance.ExportedFunction("main")
+		if mainFn == nil {
+			mainFn = instance.ExportedFunction("run")
+		}
 
-	if mainFn != nil {
-		_, err = mainFn.Call(ctx)
-		if err != nil {
-			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
-				result.Error = ErrSandboxTimeout
-			} else {
-				result.Error = fmt.Errorf("plugin execution failed: %w", err)
+		if mainFn != nil {
+			if _, err := mainFn.Call(ctx); err != nil {
+				result.Error = executionError(ctx, "plugin execution failed", err)
 			}
 		}
 	}
 
+	s.finish(result, stdout, stderr, start)
+	return result, nil
+}
+
+// finish records output, resource usage, and timing on a result.
+func (s *WazeroSandbox) finish(result *ExecutionResult, stdout, stderr *limitedBuffer, start time.Time) {
+	result.Output = stdout.Bytes()
+	result.Errors = stderr.Bytes()
+	result.ResourceUsage.OutputBytes = int64(stdout.Len() + stderr.Len())
+	if result.Error == nil && (stdout.Truncated() || stderr.Truncated()) {
+		result.Error = fmt.Errorf("%w: output exceeds %d bytes", ErrResourceExhausted, s.config.Limits.MaxOutputBytes)
+	}
 	result.Duration = time.Since(start)
 	result.Success = result.Error == nil
+}
 
-	return result, nil
+// executionError maps a wazero error to a sandbox error.
+func executionError(ctx context.Context, msg string, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ErrSandboxTimeout
+	}
+	var exitErr *sys.ExitError
+	if errors.As(err, &exitErr) {
+		return fmt.Errorf("%s: exit code %d", msg, exitErr.ExitCode())
+	}
+	return fmt.Errorf("%s: %w", msg, err)
 }
 
 // Validate checks if a plugin can be loaded.
@@ -196,11 +257,9 @@ func (s *WazeroSandbox) Validate(ctx context.Context, plugin *Plugin) error {
 	}
 
 	// Try to compile the module
-	compiled, err := s.runtime.CompileModule(ctx, plugin.Module)
-	if err != nil {
+	if _, err := s.parentRuntime.compile(ctx, plugin.Module); err != nil {
 		return fmt.Errorf("%w: failed to compile: %w", ErrPluginInvalid, err)
 	}
-	defer func() { _ = compiled.Close(ctx) }()
 
 	return nil
 }
@@ -210,42 +269,6 @@ func (s *WazeroSandbox) Close() error {
 	return nil
 }
 
-// registerHostFunctions adds preflight host functions to the runtime.
-func (s *WazeroSandbox) registerHostFunctions(ctx context.Context) error {
-	builder := s.runtime.NewHostModuleBuilder("preflight")
-
-	// Log functions (always available)
-	builder.NewFunctionBuilder().
-		WithFunc(func(_ context.Context, m api.Module, ptr, length uint32) {
-			if s.services != nil && s.services.Logger != nil {
-				msg := readString(m, ptr, length)
-				s.services.Logger.Info(msg)
-			}
-		}).
-		Export("log_info")
-
-	builder.NewFunctionBuilder().
-		WithFunc(func(_ context.Context, m api.Module, ptr, length uint32) {
-			if s.services != nil && s.services.Logger != nil {
-				msg := readString(m, ptr, length)
-				s.services.Logger.Warn(msg)
-			}
-		}).
-		Export("log_warn")
-
-	builder.NewFunctionBuilder().
-		WithFunc(func(_ context.Context, m api.Module, ptr, length uint32) {
-			if s.services != nil && s.services.Logger != nil {
-				msg := readString(m, ptr, length)
-				s.services.Logger.Error(msg)
-			}
-		}).
-		Export("log_error")
-
-	_, err := builder.Instantiate(ctx)
-	return err
-}
-
 // readString reads a string from WASM memory.
 func readString(m api.Module, ptr, length uint32) string {
 	if m == nil {
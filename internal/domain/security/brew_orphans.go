@@ -0,0 +1,221 @@
+package security
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// DefaultSafeRuntimes returns patterns of formulae that are never reported
+// as orphans. Language runtimes are used by pipx environments, global npm
+// packages and scripts without any formula depending on them.
+func DefaultSafeRuntimes() []string {
+	return []string{
+		"python@*",
+		"node",
+		"node@*",
+		"openjdk",
+		"openjdk@*",
+		"ruby",
+		"ruby@*",
+		"go",
+		"rust",
+		"ca-certificates",
+		"openssl@*",
+	}
+}
+
+// brewGraph holds the installed formulae and casks and their dependencies.
+type brewGraph struct {
+	// formulae maps each installed formula to its node.
+	formulae map[string]brewNode
+	// casks maps each installed cask to the formulae it depends on.
+	casks map[string][]string
+}
+
+type brewNode struct {
+	deps      []string
+	onRequest bool
+}
+
+type brewInfoV2Output struct {
+	Formulae []struct {
+		Name         string   `json:"name"`
+		Dependencies []string `json:"dependencies"`
+		Installed    []struct {
+			InstalledOnRequest  bool `json:"installed_on_request"`
+			RuntimeDependencies []struct {
+				FullName string `json:"full_name"`
+			} `json:"runtime_dependencies"`
+		} `json:"installed"`
+	} `json:"formulae"`
+	Casks []struct {
+		Token     string `json:"token"`
+		DependsOn struct {
+			Formula []string `json:"formula"`
+		} `json:"depends_on"`
+	} `json:"casks"`
+}
+
+// parseBrewInfo parses the output of brew info --json=v2 --installed.
+// Runtime dependencies are the ones the installed version was built
+// against; the declared dependencies stand in when they are missing.
+func parseBrewInfo(data []byte) (*brewGraph, error) {
+	var output brewInfoV2Output
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, fmt.Errorf("failed to parse brew info: %w", err)
+	}
+
+	graph := &brewGraph{
+		formulae: make(map[string]brewNode, len(output.Formulae)),
+		casks:    make(map[string][]string, len(output.Casks)),
+	}
+	for _, f := range output.Formulae {
+		node := brewNode{deps: shortBrewNames(f.Dependencies)}
+		if len(f.Installed) > 0 {
+			node.onRequest = f.Installed[0].InstalledOnRequest
+			if runtime := f.Installed[0].RuntimeDependencies; len(runtime) > 0 {
+				node.deps = make([]string, 0, len(runtime))
+				for _, dep := range runtime {
+					node.deps = append(node.deps, shortBrewName(dep.FullName))
+				}
+			}
+		}
+		graph.formulae[f.Name] = node
+	}
+	for _, c := range output.Casks {
+		graph.casks[c.Token] = shortBrewNames(c.DependsOn.Formula)
+	}
+	return graph, nil
+}
+
+// shortBrewName strips the tap from a name like "hashicorp/tap/terraform".
+func shortBrewName(name string) string {
+	return name[strings.LastIndex(name, "/")+1:]
+}
+
+func shortBrewNames(names []string) []string {
+	short := make([]string, 0, len(names))
+	for _, name := range names {
+		short = append(short, shortBrewName(name))
+	}
+	return short
+}
+
+// closure returns the formulae roots depend on, including the roots.
+func (g *brewGraph) closure(roots []string) map[string]bool {
+	needed := make(map[string]bool)
+	stack := append([]string(nil), roots...)
+	for len(stack) > 0 {
+		name := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if needed[name] {
+			continue
+		}
+		needed[name] = true
+		stack = append(stack, g.formulae[name].deps...)
+	}
+	return needed
+}
+
+// brewOrphanPlan is the result of orphan analysis.
+type brewOrphanPlan struct {
+	// Dependencies are formulae installed as dependencies that nothing
+	// installed needs any more; brew autoremove would remove them.
+	Dependencies []string
+	// Undeclared are formulae and casks installed on request that the
+	// configuration does not declare and no declared package needs.
+	Undeclared []string
+	// Freed are dependencies that only undeclared packages need.
+	Freed []string
+}
+
+// planBrewOrphans analyzes graph. Formulae matching a safe pattern, and
+// ignored packages, are kept along with their dependencies. Undeclared
+// packages are only looked for when declared is not empty.
+func planBrewOrphans(graph *brewGraph, declared map[string]bool, safe []string, ignore map[string]bool) brewOrphanPlan {
+	kept := func(name string) bool {
+		if ignore[name] {
+			return true
+		}
+		for _, pattern := range safe {
+			if ok, _ := path.Match(pattern, name); ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	var roots []string
+	for name, node := range graph.formulae {
+		if node.onRequest || kept(name) {
+			roots = append(roots, name)
+		}
+	}
+	for _, deps := range graph.casks {
+		roots = append(roots, deps...)
+	}
+	needed := graph.closure(roots)
+
+	var plan brewOrphanPlan
+	for name := range graph.formulae {
+		if !needed[name] {
+			plan.Dependencies = append(plan.Dependencies, name)
+		}
+	}
+	sort.Strings(plan.Dependencies)
+
+	if len(declared) == 0 {
+		return plan
+	}
+
+	// Keep what is declared or kept, and everything it needs.
+	var keptRoots []string
+	for name := range graph.formulae {
+		if declared[name] || kept(name) {
+			keptRoots = append(keptRoots, name)
+		}
+	}
+	undeclared := make(map[string]bool)
+	for token, deps := range graph.casks {
+		if declared[token] || ignore[token] {
+			keptRoots = append(keptRoots, deps...)
+		} else {
+			undeclared[token] = true
+		}
+	}
+	neededByDeclared := graph.closure(keptRoots)
+
+	for name, node := range graph.formulae {
+		if node.onRequest && !neededByDeclared[name] {
+			undeclared[name] = true
+		}
+	}
+	for name := range undeclared {
+		plan.Undeclared = append(plan.Undeclared, name)
+	}
+	for name := range graph.formulae {
+		if needed[name] && !neededByDeclared[name] && !undeclared[name] {
+			plan.Freed = append(plan.Freed, name)
+		}
+	}
+	sort.Strings(plan.Undeclared)
+	sort.Strings(plan.Freed)
+	return plan
+}
+
+// readGraph reads the installed packages and their dependencies.
+func (b *BrewRedundancyChecker) readGraph(ctx context.Context) (*brewGraph, error) {
+	cmd := b.execCommand(ctx, "brew", "info", "--json=v2", "--installed")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to read brew dependency graph: %w: %s", err, stderr.String())
+	}
+	return parseBrewInfo(stdout.Bytes())
+}
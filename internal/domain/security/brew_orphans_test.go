@@ -0,0 +1,85 @@
+package security
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBrewInfo(t *testing.T) {
+	t.Parallel()
+
+	graph, err := parseBrewInfo([]byte(`{
+  "formulae": [
+    {"name": "terraform", "dependencies": ["go"], "installed": [{"installed_on_request": true, "runtime_dependencies": []}]},
+    {"name": "wget", "dependencies": ["openssl@3"], "installed": [{"installed_on_request": true, "runtime_dependencies": [{"full_name": "openssl@3"}, {"full_name": "ca-certificates"}]}]}
+  ],
+  "casks": [{"token": "wezterm", "depends_on": {"macos": {">=": ["10.10"]}}}]
+}`))
+	require.NoError(t, err)
+	assert.Equal(t, brewNode{deps: []string{"go"}, onRequest: true}, graph.formulae["terraform"])
+	assert.Equal(t, []string{"openssl@3", "ca-certificates"}, graph.formulae["wget"].deps)
+	assert.Empty(t, graph.casks["wezterm"])
+
+	_, err = parseBrewInfo([]byte("not json"))
+	assert.Error(t, err)
+}
+
+func TestPlanBrewOrphans(t *testing.T) {
+	t.Parallel()
+
+	// jq and node were requested, jq needs oniguruma; gh was requested and
+	// needs nothing; libyaml was needed by a formula since removed; ruby
+	// is a runtime nothing depends on; the cask needs a formula too.
+	graph := &brewGraph{
+		formulae: map[string]brewNode{
+			"jq":           {deps: []string{"oniguruma"}, onRequest: true},
+			"oniguruma":    {},
+			"gh":           {onRequest: true},
+			"libyaml":      {},
+			"ruby":         {deps: []string{"libyaml"}},
+			"ffmpeg":       {deps: []string{"x264"}, onRequest: true},
+			"x264":         {},
+			"docker-creds": {},
+		},
+		casks: map[string][]string{"docker": {"docker-creds"}},
+	}
+
+	t.Run("dependencies only", func(t *testing.T) {
+		t.Parallel()
+		plan := planBrewOrphans(graph, nil, nil, nil)
+		assert.Equal(t, []string{"libyaml", "ruby"}, plan.Dependencies)
+		assert.Empty(t, plan.Undeclared)
+	})
+
+	t.Run("safe runtimes keep their dependencies", func(t *testing.T) {
+		t.Parallel()
+		plan := planBrewOrphans(graph, nil, DefaultSafeRuntimes(), nil)
+		assert.Empty(t, plan.Dependencies)
+	})
+
+	t.Run("undeclared packages and the dependencies they free", func(t *testing.T) {
+		t.Parallel()
+		declared := map[string]bool{"jq": true, "gh": true}
+		plan := planBrewOrphans(graph, declared, DefaultSafeRuntimes(), map[string]bool{})
+		assert.Equal(t, []string{"docker", "ffmpeg"}, plan.Undeclared)
+		assert.Equal(t, []string{"docker-creds", "x264"}, plan.Freed)
+	})
+
+	t.Run("a declared package keeps a requested dependency", func(t *testing.T) {
+		t.Parallel()
+		declared := map[string]bool{"jq": true, "gh": true, "docker": true}
+		withRequestedDep := &brewGraph{
+			formulae: map[string]brewNode{
+				"jq":        {deps: []string{"oniguruma"}, onRequest: true},
+				"oniguruma": {onRequest: true},
+				"gh":        {onRequest: true},
+			},
+			casks: map[string][]string{"docker": nil},
+		}
+		plan := planBrewOrphans(withRequestedDep, declared, nil, nil)
+		assert.Empty(t, plan.Undeclared)
+		assert.Empty(t, plan.Freed)
+	})
+}
@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
 	"strings"
 	"time"
@@ -179,14 +181,36 @@ type PackageUpgrader interface {
 	Upgrade(ctx context.Context, packages []string, opts UpgradeOptions) (*UpgradeResult, error)
 }
 
+// PackageRollbacker can put an upgraded package back at its previous
+// version.
+type PackageRollbacker interface {
+	// Rollback restores pkg.FromVersion of an upgraded package.
+	Rollback(ctx context.Context, pkg UpgradedPackage) error
+}
+
+// ErrRollbackUnsupported is returned when a package cannot be rolled back.
+var ErrRollbackUnsupported = errors.New("rollback is not supported")
+
 // UpgradeOptions configures package upgrades.
 type UpgradeOptions struct {
 	// DryRun shows what would be upgraded without making changes.
 	DryRun bool `json:"dry_run"`
 	// IncludeMajor allows major version upgrades (breaking changes).
 	IncludeMajor bool `json:"include_major"`
+	// PinnedPackages are "provider:name" references of packages held at
+	// their current version, in addition to those pinned with brew pin.
+	PinnedPackages []string `json:"pinned_packages,omitempty"`
+	// KeepPrevious keeps the previous version installed so that the
+	// upgrade can be rolled back.
+	KeepPrevious bool `json:"keep_previous,omitempty"`
 }
 
+// Reasons for skipping a package during an upgrade.
+const (
+	SkipReasonMajor  = "major update requires --major flag"
+	SkipReasonPinned = "pinned"
+)
+
 // UpgradeResult contains the results of an upgrade operation.
 type UpgradeResult struct {
 	// Upgraded contains packages that were successfully upgraded.
@@ -195,6 +219,9 @@ type UpgradeResult struct {
 	Skipped []SkippedPackage `json:"skipped"`
 	// Failed contains packages that failed to upgrade.
 	Failed []FailedPackage `json:"failed"`
+	// RolledBack contains packages whose upgrade was undone after a failed
+	// health check.
+	RolledBack []RolledBackPackage `json:"rolled_back,omitempty"`
 	// DryRun indicates if this was a dry run.
 	DryRun bool `json:"dry_run"`
 }
@@ -214,6 +241,17 @@ type SkippedPackage struct {
 	UpdateType UpdateType `json:"update_type"`
 }
 
+// RolledBackPackage is a package put back at its previous version because
+// a health check failed after the upgrade.
+type RolledBackPackage struct {
+	Name        string `json:"name"`
+	FromVersion string `json:"from_version"`
+	ToVersion   string `json:"to_version"`
+	Provider    string `json:"provider"`
+	Check       string `json:"check"`
+	Output      string `json:"output,omitempty"`
+}
+
 // FailedPackage represents a package that failed to upgrade.
 type FailedPackage struct {
 	Name  string `json:"name"`
@@ -225,7 +263,8 @@ type OutdatedOptions struct {
 	IncludePatch   bool          `json:"include_patch"`
 	IncludePinned  bool          `json:"include_pinned"`
 	IgnorePackages []string      `json:"ignore_packages"`
-	MaxAge         time.Duration `json:"max_age"` // Only report if update is older than this
+	PinnedPackages []string      `json:"pinned_packages,omitempty"` // "provider:name" references held at their version
+	MaxAge         time.Duration `json:"max_age"`                   // Only report if update is older than this
 }
 
 // DetermineUpdateType determines the update type between two semver versions.
@@ -324,20 +363,7 @@ func (b *BrewOutdatedChecker) Check(ctx context.Context, opts OutdatedOptions) (
 		return nil, err
 	}
 
-	result.Packages = packages
-
-	// Apply filters
-	if !opts.IncludePatch {
-		result.Packages = result.Packages.ByUpdateType(UpdateMinor)
-	}
-
-	if !opts.IncludePinned {
-		result.Packages = result.Packages.ExcludePinned()
-	}
-
-	if len(opts.IgnorePackages) > 0 {
-		result.Packages = result.Packages.ExcludeNames(opts.IgnorePackages)
-	}
+	result.Packages = filterOutdated(packages, opts)
 
 	return result, nil
 }
@@ -427,7 +453,7 @@ func (b *BrewOutdatedChecker) Upgrade(ctx context.Context, packages []string, op
 	}
 
 	// First, get current outdated packages to know versions
-	checkOpts := OutdatedOptions{IncludePatch: true, IncludePinned: false}
+	checkOpts := OutdatedOptions{IncludePatch: true, IncludePinned: true, PinnedPackages: opts.PinnedPackages}
 	outdated, err := b.Check(ctx, checkOpts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check outdated packages: %w", err)
@@ -439,10 +465,10 @@ func (b *BrewOutdatedChecker) Upgrade(ctx context.Context, packages []string, op
 		outdatedMap[pkg.Name] = pkg
 	}
 
-	// If no specific packages provided, use all outdated
+	// If no specific packages provided, use all outdated that are not pinned
 	toUpgrade := packages
 	if len(toUpgrade) == 0 {
-		for _, pkg := range outdated.Packages {
+		for _, pkg := range outdated.Packages.ExcludePinned() {
 			toUpgrade = append(toUpgrade, pkg.Name)
 		}
 	}
@@ -455,11 +481,21 @@ func (b *BrewOutdatedChecker) Upgrade(ctx context.Context, packages []string, op
 			continue
 		}
 
+		// Pinned packages are held even when asked for by name
+		if pkg.Pinned {
+			result.Skipped = append(result.Skipped, SkippedPackage{
+				Name:       name,
+				Reason:     SkipReasonPinned,
+				UpdateType: pkg.UpdateType,
+			})
+			continue
+		}
+
 		// Check if we should skip major updates
 		if pkg.UpdateType == UpdateMajor && !opts.IncludeMajor {
 			result.Skipped = append(result.Skipped, SkippedPackage{
 				Name:       name,
-				Reason:     "major update requires --major flag",
+				Reason:     SkipReasonMajor,
 				UpdateType: UpdateMajor,
 			})
 			continue
@@ -484,6 +520,11 @@ func (b *BrewOutdatedChecker) Upgrade(ctx context.Context, packages []string, op
 			cmd = b.execCommand(ctx, "brew", "upgrade", name)
 		}
 
+		if opts.KeepPrevious {
+			// brew removes the previous version after upgrading otherwise
+			cmd.Env = append(os.Environ(), "HOMEBREW_NO_INSTALL_CLEANUP=1")
+		}
+
 		var stderr bytes.Buffer
 		cmd.Stderr = &stderr
 
@@ -506,6 +547,29 @@ func (b *BrewOutdatedChecker) Upgrade(ctx context.Context, packages []string, op
 	return result, nil
 }
 
+// Rollback restores the previous version of an upgraded formula, which
+// must have been kept with UpgradeOptions.KeepPrevious: the new version is
+// uninstalled and the previous one linked again. Casks are replaced on
+// upgrade and cannot be rolled back.
+func (b *BrewOutdatedChecker) Rollback(ctx context.Context, pkg UpgradedPackage) error {
+	if pkg.Provider == "cask" {
+		return fmt.Errorf("%w for casks", ErrRollbackUnsupported)
+	}
+	// Without --force, brew uninstall removes only the newest version
+	for _, args := range [][]string{
+		{"uninstall", "--ignore-dependencies", pkg.Name},
+		{"link", "--overwrite", pkg.Name},
+	} {
+		cmd := b.execCommand(ctx, "brew", args...)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("brew %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+		}
+	}
+	return nil
+}
+
 // OutdatedCheckerRegistry manages available outdated checkers.
 type OutdatedCheckerRegistry struct {
 	checkers []OutdatedChecker
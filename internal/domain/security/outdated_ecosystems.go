@@ -0,0 +1,729 @@
+package security
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/module"
+)
+
+// Default registries consulted by checkers whose package manager has no
+// outdated command.
+const (
+	DefaultPyPIURL        = "https://pypi.org/pypi"
+	DefaultGoProxyURL     = "https://proxy.golang.org"
+	DefaultMarketplaceURL = "https://marketplace.visualstudio.com/_apis/public/gallery"
+)
+
+// NewDefaultOutdatedCheckerRegistry returns a registry with a checker for
+// every supported provider, brew first.
+func NewDefaultOutdatedCheckerRegistry() *OutdatedCheckerRegistry {
+	registry := NewOutdatedCheckerRegistry()
+	registry.Register(NewBrewOutdatedChecker())
+	registry.Register(NewNpmOutdatedChecker())
+	registry.Register(NewPipOutdatedChecker())
+	registry.Register(NewPipxOutdatedChecker())
+	registry.Register(NewVSCodeOutdatedChecker())
+	registry.Register(NewGoOutdatedChecker())
+	registry.Register(NewNvimOutdatedChecker())
+	return registry
+}
+
+// filterOutdated applies the patch, pinned and ignore filters of opts.
+func filterOutdated(packages OutdatedPackages, opts OutdatedOptions) OutdatedPackages {
+	if len(opts.PinnedPackages) > 0 {
+		packages = markPinned(packages, opts.PinnedPackages)
+	}
+	if !opts.IncludePatch {
+		packages = packages.ByUpdateType(UpdateMinor)
+	}
+	if !opts.IncludePinned {
+		packages = packages.ExcludePinned()
+	}
+	if len(opts.IgnorePackages) > 0 {
+		packages = packages.ExcludeNames(opts.IgnorePackages)
+	}
+	return packages
+}
+
+// markPinned returns packages with those referenced by pins, such as
+// "npm:typescript", marked as pinned. Homebrew formulae and casks share a
+// namespace, and VS Code extension IDs are compared case-insensitively.
+func markPinned(packages OutdatedPackages, pins []string) OutdatedPackages {
+	pinned := make(map[string]bool, len(pins))
+	for _, ref := range pins {
+		pinned[pinKey(ref)] = true
+	}
+	marked := make(OutdatedPackages, len(packages))
+	for i, pkg := range packages {
+		if pinned[pinKey(pkg.Provider+":"+pkg.Name)] {
+			pkg.Pinned = true
+		}
+		marked[i] = pkg
+	}
+	return marked
+}
+
+func pinKey(ref string) string {
+	provider, name, _ := strings.Cut(ref, ":")
+	switch provider {
+	case "cask":
+		provider = "brew"
+	case "vscode":
+		name = strings.ToLower(name)
+	}
+	return provider + ":" + name
+}
+
+// newOutdatedResult wraps packages found by checker after filtering.
+func newOutdatedResult(checker string, packages OutdatedPackages, opts OutdatedOptions) *OutdatedResult {
+	if packages == nil {
+		packages = make(OutdatedPackages, 0)
+	}
+	return &OutdatedResult{
+		Checker:   checker,
+		CheckedAt: time.Now(),
+		Packages:  filterOutdated(packages, opts),
+	}
+}
+
+// outdatedPackage builds a package whose update type is derived from its
+// versions.
+func outdatedPackage(provider, name, current, latest string) OutdatedPackage {
+	return OutdatedPackage{
+		Name:           name,
+		CurrentVersion: current,
+		LatestVersion:  latest,
+		UpdateType:     DetermineUpdateType(current, latest),
+		Provider:       provider,
+	}
+}
+
+// runOutput runs cmd and returns its stdout. Output on stdout is kept when
+// the command exits non-zero, since npm outdated exits 1 when it finds
+// outdated packages.
+func runOutput(cmd *exec.Cmd, what string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || stdout.Len() == 0 {
+			return nil, fmt.Errorf("failed to run %s: %w: %s", what, err, strings.TrimSpace(stderr.String()))
+		}
+	}
+	return stdout.Bytes(), nil
+}
+
+// fetchJSON decodes the JSON response to req into v.
+func fetchJSON(client *http.Client, req *http.Request, v any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", req.URL, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch %s: %s", req.URL, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", req.URL, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", req.URL, err)
+	}
+	return nil
+}
+
+// getJSON fetches rawURL and decodes the JSON response into v.
+func getJSON(ctx context.Context, client *http.Client, rawURL string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	return fetchJSON(client, req, v)
+}
+
+// NpmOutdatedChecker checks for outdated global npm packages.
+type NpmOutdatedChecker struct {
+	execCommand func(ctx context.Context, name string, args ...string) *exec.Cmd
+	lookPath    func(file string) (string, error)
+}
+
+// NewNpmOutdatedChecker creates a new npm outdated checker.
+func NewNpmOutdatedChecker() *NpmOutdatedChecker {
+	return &NpmOutdatedChecker{
+		execCommand: exec.CommandContext,
+		lookPath:    exec.LookPath,
+	}
+}
+
+// Name returns the checker name.
+func (n *NpmOutdatedChecker) Name() string {
+	return "npm"
+}
+
+// Available returns true if npm is installed.
+func (n *NpmOutdatedChecker) Available() bool {
+	_, err := n.lookPath("npm")
+	return err == nil
+}
+
+// Check returns outdated global npm packages.
+func (n *NpmOutdatedChecker) Check(ctx context.Context, opts OutdatedOptions) (*OutdatedResult, error) {
+	if !n.Available() {
+		return nil, ErrScannerNotAvailable
+	}
+
+	data, err := runOutput(n.execCommand(ctx, "npm", "outdated", "-g", "--json"), "npm outdated")
+	if err != nil {
+		return nil, err
+	}
+	packages, err := parseNpmOutdated(data)
+	if err != nil {
+		return nil, err
+	}
+	return newOutdatedResult(n.Name(), packages, opts), nil
+}
+
+// parseNpmOutdated parses npm outdated --json output. Packages that are
+// not installed have no current version and are skipped.
+func parseNpmOutdated(data []byte) (OutdatedPackages, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return OutdatedPackages{}, nil
+	}
+
+	var output map[string]struct {
+		Current string `json:"current"`
+		Latest  string `json:"latest"`
+	}
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, fmt.Errorf("failed to parse npm outdated output: %w", err)
+	}
+
+	packages := make(OutdatedPackages, 0, len(output))
+	for name, pkg := range output {
+		if pkg.Current == "" || pkg.Latest == "" || pkg.Current == pkg.Latest {
+			continue
+		}
+		packages = append(packages, outdatedPackage("npm", name, pkg.Current, pkg.Latest))
+	}
+	sortOutdated(packages)
+	return packages, nil
+}
+
+// PipOutdatedChecker checks for outdated packages in the pip user site.
+type PipOutdatedChecker struct {
+	execCommand func(ctx context.Context, name string, args ...string) *exec.Cmd
+	lookPath    func(file string) (string, error)
+}
+
+// NewPipOutdatedChecker creates a new pip outdated checker.
+func NewPipOutdatedChecker() *PipOutdatedChecker {
+	return &PipOutdatedChecker{
+		execCommand: exec.CommandContext,
+		lookPath:    exec.LookPath,
+	}
+}
+
+// Name returns the checker name.
+func (p *PipOutdatedChecker) Name() string {
+	return "pip"
+}
+
+// Available returns true if python3 is installed.
+func (p *PipOutdatedChecker) Available() bool {
+	_, err := p.lookPath("python3")
+	return err == nil
+}
+
+// Check returns outdated packages installed with pip install --user.
+func (p *PipOutdatedChecker) Check(ctx context.Context, opts OutdatedOptions) (*OutdatedResult, error) {
+	if !p.Available() {
+		return nil, ErrScannerNotAvailable
+	}
+
+	cmd := p.execCommand(ctx, "python3", "-m", "pip", "list", "--outdated", "--user", "--format=json")
+	data, err := runOutput(cmd, "pip list --outdated")
+	if err != nil {
+		return nil, err
+	}
+	packages, err := parsePipOutdated(data)
+	if err != nil {
+		return nil, err
+	}
+	return newOutdatedResult(p.Name(), packages, opts), nil
+}
+
+// parsePipOutdated parses pip list --outdated --format=json output.
+func parsePipOutdated(data []byte) (OutdatedPackages, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return OutdatedPackages{}, nil
+	}
+
+	var output []struct {
+		Name          string `json:"name"`
+		Version       string `json:"version"`
+		LatestVersion string `json:"latest_version"`
+	}
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, fmt.Errorf("failed to parse pip list output: %w", err)
+	}
+
+	packages := make(OutdatedPackages, 0, len(output))
+	for _, pkg := range output {
+		packages = append(packages, outdatedPackage("pip", pkg.Name, pkg.Version, pkg.LatestVersion))
+	}
+	return packages, nil
+}
+
+// PipxOutdatedChecker checks the applications installed with pipx against
+// the latest releases on PyPI.
+type PipxOutdatedChecker struct {
+	execCommand func(ctx context.Context, name string, args ...string) *exec.Cmd
+	lookPath    func(file string) (string, error)
+	client      *http.Client
+	indexURL    string
+}
+
+// NewPipxOutdatedChecker creates a new pipx outdated checker.
+func NewPipxOutdatedChecker() *PipxOutdatedChecker {
+	return &PipxOutdatedChecker{
+		execCommand: exec.CommandContext,
+		lookPath:    exec.LookPath,
+		client:      &http.Client{Timeout: 30 * time.Second},
+		indexURL:    DefaultPyPIURL,
+	}
+}
+
+// Name returns the checker name.
+func (p *PipxOutdatedChecker) Name() string {
+	return "pipx"
+}
+
+// Available returns true if pipx is installed.
+func (p *PipxOutdatedChecker) Available() bool {
+	_, err := p.lookPath("pipx")
+	return err == nil
+}
+
+// Check returns pipx applications with a newer release on PyPI.
+func (p *PipxOutdatedChecker) Check(ctx context.Context, opts OutdatedOptions) (*OutdatedResult, error) {
+	if !p.Available() {
+		return nil, ErrScannerNotAvailable
+	}
+
+	data, err := runOutput(p.execCommand(ctx, "pipx", "list", "--json"), "pipx list")
+	if err != nil {
+		return nil, err
+	}
+	installed, err := parsePipxVersions(data)
+	if err != nil {
+		return nil, err
+	}
+
+	packages := make(OutdatedPackages, 0)
+	for _, name := range sortedKeys(installed) {
+		var release struct {
+			Info struct {
+				Version string `json:"version"`
+			} `json:"info"`
+		}
+		if err := getJSON(ctx, p.client, p.indexURL+"/"+url.PathEscape(name)+"/json", &release); err != nil {
+			return nil, err
+		}
+		if latest := release.Info.Version; latest != "" && latest != installed[name] {
+			packages = append(packages, outdatedPackage("pipx", name, installed[name], latest))
+		}
+	}
+	return newOutdatedResult(p.Name(), packages, opts), nil
+}
+
+// parsePipxVersions maps the main package of each pipx venv to its version.
+func parsePipxVersions(data []byte) (map[string]string, error) {
+	var output struct {
+		Venvs map[string]struct {
+			Metadata struct {
+				MainPackage struct {
+					Package        string `json:"package"`
+					PackageVersion string `json:"package_version"`
+				} `json:"main_package"`
+			} `json:"metadata"`
+		} `json:"venvs"`
+	}
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, fmt.Errorf("failed to parse pipx list output: %w", err)
+	}
+
+	versions := make(map[string]string, len(output.Venvs))
+	for venv, v := range output.Venvs {
+		name := v.Metadata.MainPackage.Package
+		if name == "" {
+			name = venv
+		}
+		versions[name] = v.Metadata.MainPackage.PackageVersion
+	}
+	return versions, nil
+}
+
+// VSCodeOutdatedChecker checks installed VS Code extensions against the
+// Visual Studio Marketplace.
+type VSCodeOutdatedChecker struct {
+	execCommand    func(ctx context.Context, name string, args ...string) *exec.Cmd
+	lookPath       func(file string) (string, error)
+	client         *http.Client
+	marketplaceURL string
+}
+
+// NewVSCodeOutdatedChecker creates a new VS Code extension outdated checker.
+func NewVSCodeOutdatedChecker() *VSCodeOutdatedChecker {
+	return &VSCodeOutdatedChecker{
+		execCommand:    exec.CommandContext,
+		lookPath:       exec.LookPath,
+		client:         &http.Client{Timeout: 30 * time.Second},
+		marketplaceURL: DefaultMarketplaceURL,
+	}
+}
+
+// Name returns the checker name.
+func (v *VSCodeOutdatedChecker) Name() string {
+	return "vscode"
+}
+
+// Available returns true if the code CLI is installed.
+func (v *VSCodeOutdatedChecker) Available() bool {
+	_, err := v.lookPath("code")
+	return err == nil
+}
+
+// Check returns extensions with a newer version on the Marketplace.
+func (v *VSCodeOutdatedChecker) Check(ctx context.Context, opts OutdatedOptions) (*OutdatedResult, error) {
+	if !v.Available() {
+		return nil, ErrScannerNotAvailable
+	}
+
+	data, err := runOutput(v.execCommand(ctx, "code", "--list-extensions", "--show-versions"), "code --list-extensions")
+	if err != nil {
+		return nil, err
+	}
+	installed := parseExtensionVersions(data)
+	if len(installed) == 0 {
+		return newOutdatedResult(v.Name(), nil, opts), nil
+	}
+
+	latest, err := v.latestVersions(ctx, sortedKeys(installed))
+	if err != nil {
+		return nil, err
+	}
+
+	packages := make(OutdatedPackages, 0)
+	for _, id := range sortedKeys(installed) {
+		if version, ok := latest[id]; ok && version != installed[id] {
+			packages = append(packages, outdatedPackage("vscode", id, installed[id], version))
+		}
+	}
+	return newOutdatedResult(v.Name(), packages, opts), nil
+}
+
+// parseExtensionVersions parses "publisher.name@version" lines. Extension
+// IDs are case-insensitive and are lowercased.
+func parseExtensionVersions(data []byte) map[string]string {
+	versions := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		id, version, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "@")
+		if ok && id != "" {
+			versions[strings.ToLower(id)] = version
+		}
+	}
+	return versions
+}
+
+// Marketplace query constants: filter by extension ID, and include only
+// the latest version.
+const (
+	marketplaceFilterExtensionName = 7
+	marketplaceFlags               = 0x1 | 0x200
+)
+
+// latestVersions asks the Marketplace for the latest version of ids.
+func (v *VSCodeOutdatedChecker) latestVersions(ctx context.Context, ids []string) (map[string]string, error) {
+	type criterion struct {
+		FilterType int    `json:"filterType"`
+		Value      string `json:"value"`
+	}
+	criteria := make([]criterion, 0, len(ids))
+	for _, id := range ids {
+		criteria = append(criteria, criterion{FilterType: marketplaceFilterExtensionName, Value: id})
+	}
+	body, err := json.Marshal(map[string]any{
+		"filters": []map[string]any{{"criteria": criteria, "pageSize": len(ids)}},
+		"flags":   marketplaceFlags,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.marketplaceURL+"/extensionquery", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json;api-version=3.0-preview.1")
+
+	var output struct {
+		Results []struct {
+			Extensions []struct {
+				ExtensionName string `json:"extensionName"`
+				Publisher     struct {
+					PublisherName string `json:"publisherName"`
+				} `json:"publisher"`
+				Versions []struct {
+					Version string `json:"version"`
+				} `json:"versions"`
+			} `json:"extensions"`
+		} `json:"results"`
+	}
+	if err := fetchJSON(v.client, req, &output); err != nil {
+		return nil, err
+	}
+
+	latest := make(map[string]string)
+	for _, result := range output.Results {
+		for _, ext := range result.Extensions {
+			if len(ext.Versions) == 0 {
+				continue
+			}
+			id := strings.ToLower(ext.Publisher.PublisherName + "." + ext.ExtensionName)
+			latest[id] = ext.Versions[0].Version
+		}
+	}
+	return latest, nil
+}
+
+// GoOutdatedChecker checks the tools installed with go install against
+// the latest versions on the module proxy.
+type GoOutdatedChecker struct {
+	execCommand func(ctx context.Context, name string, args ...string) *exec.Cmd
+	lookPath    func(file string) (string, error)
+	client      *http.Client
+}
+
+// NewGoOutdatedChecker creates a new Go tools outdated checker.
+func NewGoOutdatedChecker() *GoOutdatedChecker {
+	return &GoOutdatedChecker{
+		execCommand: exec.CommandContext,
+		lookPath:    exec.LookPath,
+		client:      &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Name returns the checker name.
+func (g *GoOutdatedChecker) Name() string {
+	return "go"
+}
+
+// Available returns true if go is installed.
+func (g *GoOutdatedChecker) Available() bool {
+	_, err := g.lookPath("go")
+	return err == nil
+}
+
+// Check returns installed tools whose module has a newer version. Tools
+// built from a local checkout report "(devel)" and are skipped.
+func (g *GoOutdatedChecker) Check(ctx context.Context, opts OutdatedOptions) (*OutdatedResult, error) {
+	if !g.Available() {
+		return nil, ErrScannerNotAvailable
+	}
+
+	env, err := runOutput(g.execCommand(ctx, "go", "env", "GOBIN", "GOPATH", "GOPROXY"), "go env")
+	if err != nil {
+		return nil, err
+	}
+	binDir, proxy := goToolLocations(string(env))
+	if binDir == "" {
+		return newOutdatedResult(g.Name(), nil, opts), nil
+	}
+	if _, err := os.Stat(binDir); err != nil {
+		return newOutdatedResult(g.Name(), nil, opts), nil
+	}
+
+	data, err := runOutput(g.execCommand(ctx, "go", "version", "-m", binDir), "go version -m")
+	if err != nil {
+		return nil, err
+	}
+	modules := parseGoBinaryModules(data)
+
+	packages := make(OutdatedPackages, 0)
+	for _, path := range sortedKeys(modules) {
+		escaped, err := module.EscapePath(path)
+		if err != nil {
+			continue
+		}
+		var info struct {
+			Version string `json:"Version"`
+		}
+		if err := getJSON(ctx, g.client, proxy+"/"+escaped+"/@latest", &info); err != nil {
+			return nil, err
+		}
+		if info.Version != "" && info.Version != modules[path] {
+			packages = append(packages, outdatedPackage("go", path, modules[path], info.Version))
+		}
+	}
+	return newOutdatedResult(g.Name(), packages, opts), nil
+}
+
+// goToolLocations reads the install directory and the first HTTP proxy
+// from go env GOBIN GOPATH GOPROXY output.
+func goToolLocations(env string) (binDir, proxy string) {
+	lines := strings.Split(strings.TrimRight(env, "\n"), "\n")
+	for len(lines) < 3 {
+		lines = append(lines, "")
+	}
+	binDir = strings.TrimSpace(lines[0])
+	if gopath := strings.TrimSpace(lines[1]); binDir == "" && gopath != "" {
+		binDir = filepath.Join(filepath.SplitList(gopath)[0], "bin")
+	}
+
+	proxy = DefaultGoProxyURL
+	for _, entry := range strings.FieldsFunc(lines[2], func(r rune) bool { return r == ',' || r == '|' }) {
+		if strings.HasPrefix(entry, "https://") || strings.HasPrefix(entry, "http://") {
+			proxy = strings.TrimSuffix(entry, "/")
+			break
+		}
+	}
+	return binDir, proxy
+}
+
+// parseGoBinaryModules maps the main module of each binary listed by
+// go version -m to its version.
+func parseGoBinaryModules(data []byte) map[string]string {
+	modules := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || fields[0] != "mod" {
+			continue
+		}
+		if version := fields[2]; version != "(devel)" {
+			modules[fields[1]] = version
+		}
+	}
+	return modules
+}
+
+// NvimOutdatedChecker checks the plugins pinned in lazy-lock.json against
+// the branches they track.
+type NvimOutdatedChecker struct {
+	execCommand func(ctx context.Context, name string, args ...string) *exec.Cmd
+	lockPath    string
+	pluginDir   string
+}
+
+// NewNvimOutdatedChecker creates a new Neovim plugin outdated checker for
+// the lazy.nvim lockfile and plugin directory.
+func NewNvimOutdatedChecker() *NvimOutdatedChecker {
+	config := os.Getenv("XDG_CONFIG_HOME")
+	data := os.Getenv("XDG_DATA_HOME")
+	if home, err := os.UserHomeDir(); err == nil {
+		if config == "" {
+			config = filepath.Join(home, ".config")
+		}
+		if data == "" {
+			data = filepath.Join(home, ".local", "share")
+		}
+	}
+	return &NvimOutdatedChecker{
+		execCommand: exec.CommandContext,
+		lockPath:    filepath.Join(config, "nvim", "lazy-lock.json"),
+		pluginDir:   filepath.Join(data, "nvim", "lazy"),
+	}
+}
+
+// Name returns the checker name.
+func (n *NvimOutdatedChecker) Name() string {
+	return "nvim"
+}
+
+// Available returns true if a lazy.nvim lockfile exists.
+func (n *NvimOutdatedChecker) Available() bool {
+	_, err := os.Stat(n.lockPath)
+	return err == nil
+}
+
+// Check returns plugins whose tracked branch has moved past the locked
+// commit. Commits have no update type, so the patch filter does not apply.
+func (n *NvimOutdatedChecker) Check(ctx context.Context, opts OutdatedOptions) (*OutdatedResult, error) {
+	if !n.Available() {
+		return nil, ErrScannerNotAvailable
+	}
+
+	data, err := os.ReadFile(n.lockPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", n.lockPath, err)
+	}
+	var lock map[string]struct {
+		Branch string `json:"branch"`
+		Commit string `json:"commit"`
+	}
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", n.lockPath, err)
+	}
+
+	packages := make(OutdatedPackages, 0)
+	for _, name := range sortedKeys(lock) {
+		plugin := lock[name]
+		dir := filepath.Join(n.pluginDir, name)
+		if plugin.Commit == "" || plugin.Branch == "" {
+			continue
+		}
+		if _, err := os.Stat(dir); err != nil {
+			// Not installed yet; lazy.nvim installs the locked commit.
+			continue
+		}
+		cmd := n.execCommand(ctx, "git", "-C", dir, "ls-remote", "origin", "refs/heads/"+plugin.Branch)
+		out, err := runOutput(cmd, "git ls-remote for "+name)
+		if err != nil {
+			return nil, err
+		}
+		fields := strings.Fields(string(out))
+		if len(fields) == 0 || fields[0] == plugin.Commit {
+			continue
+		}
+		packages = append(packages, OutdatedPackage{
+			Name:           name,
+			CurrentVersion: shortCommit(plugin.Commit),
+			LatestVersion:  shortCommit(fields[0]),
+			UpdateType:     UpdateUnknown,
+			Provider:       "nvim",
+		})
+	}
+
+	opts.IncludePatch = true
+	return newOutdatedResult(n.Name(), packages, opts), nil
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}
+
+func sortOutdated(packages OutdatedPackages) {
+	sort.Slice(packages, func(i, j int) bool { return packages[i].Name < packages[j].Name })
+}
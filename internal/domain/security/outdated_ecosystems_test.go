@@ -0,0 +1,278 @@
+package security
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultOutdatedCheckerRegistry(t *testing.T) {
+	t.Parallel()
+
+	registry := NewDefaultOutdatedCheckerRegistry()
+	assert.Equal(t, []string{"brew", "npm", "pip", "pipx", "vscode", "go", "nvim"}, registry.Names())
+}
+
+func TestNpmOutdatedChecker_Check(t *testing.T) {
+	t.Parallel()
+
+	output := `{
+		"typescript": {"current": "5.3.3", "wanted": "5.4.5", "latest": "5.4.5"},
+		"eslint": {"current": "8.57.0", "wanted": "9.1.0", "latest": "9.1.0"},
+		"prettier": {"current": "3.2.4", "wanted": "3.2.5", "latest": "3.2.5"},
+		"missing": {"wanted": "1.0.0", "latest": "1.0.0"}
+	}`
+	checker := &NpmOutdatedChecker{
+		// npm outdated exits 1 when it finds outdated packages.
+		execCommand: func(_ context.Context, _ string, _ ...string) *exec.Cmd {
+			return exec.Command("sh", "-c", `printf '%s' "$0"; exit 1`, output)
+		},
+		lookPath: fakeLookPath("npm"),
+	}
+
+	result, err := checker.Check(context.Background(), OutdatedOptions{IncludePatch: true})
+	require.NoError(t, err)
+	assert.Equal(t, "npm", result.Checker)
+	assert.Equal(t, OutdatedPackages{
+		{Name: "eslint", CurrentVersion: "8.57.0", LatestVersion: "9.1.0", UpdateType: UpdateMajor, Provider: "npm"},
+		{Name: "prettier", CurrentVersion: "3.2.4", LatestVersion: "3.2.5", UpdateType: UpdatePatch, Provider: "npm"},
+		{Name: "typescript", CurrentVersion: "5.3.3", LatestVersion: "5.4.5", UpdateType: UpdateMinor, Provider: "npm"},
+	}, result.Packages)
+
+	result, err = checker.Check(context.Background(), OutdatedOptions{IgnorePackages: []string{"eslint"}})
+	require.NoError(t, err)
+	require.Len(t, result.Packages, 1)
+	assert.Equal(t, "typescript", result.Packages[0].Name)
+
+	result, err = checker.Check(context.Background(), OutdatedOptions{PinnedPackages: []string{"npm:typescript", "brew:eslint"}})
+	require.NoError(t, err)
+	require.Len(t, result.Packages, 1)
+	assert.Equal(t, "eslint", result.Packages[0].Name)
+}
+
+func TestMarkPinned(t *testing.T) {
+	t.Parallel()
+
+	packages := OutdatedPackages{
+		{Name: "wezterm", Provider: "cask"},
+		{Name: "Golang.Go", Provider: "vscode"},
+		{Name: "git", Provider: "brew"},
+	}
+	marked := markPinned(packages, []string{"brew:wezterm", "vscode:golang.go"})
+	assert.True(t, marked[0].Pinned, "casks share the brew namespace")
+	assert.True(t, marked[1].Pinned, "extension IDs are case-insensitive")
+	assert.False(t, marked[2].Pinned)
+	assert.False(t, packages[0].Pinned, "packages are not modified")
+}
+
+func TestNpmOutdatedChecker_Errors(t *testing.T) {
+	t.Parallel()
+
+	checker := &NpmOutdatedChecker{execCommand: fakeOutputs(nil), lookPath: fakeLookPath()}
+	_, err := checker.Check(context.Background(), OutdatedOptions{})
+	require.ErrorIs(t, err, ErrScannerNotAvailable)
+
+	checker.lookPath = fakeLookPath("npm")
+	_, err = checker.Check(context.Background(), OutdatedOptions{})
+	require.Error(t, err)
+}
+
+func TestPipOutdatedChecker_Check(t *testing.T) {
+	t.Parallel()
+
+	checker := &PipOutdatedChecker{
+		execCommand: fakeOutputs(map[string]string{
+			"python3 -m pip list --outdated --user --format=json": `[
+				{"name": "black", "version": "23.1.0", "latest_version": "24.2.0", "latest_filetype": "wheel"},
+				{"name": "requests", "version": "2.31.0", "latest_version": "2.32.0", "latest_filetype": "wheel"}
+			]`,
+		}),
+		lookPath: fakeLookPath("python3"),
+	}
+
+	result, err := checker.Check(context.Background(), OutdatedOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, OutdatedPackages{
+		{Name: "black", CurrentVersion: "23.1.0", LatestVersion: "24.2.0", UpdateType: UpdateMajor, Provider: "pip"},
+		{Name: "requests", CurrentVersion: "2.31.0", LatestVersion: "2.32.0", UpdateType: UpdateMinor, Provider: "pip"},
+	}, result.Packages)
+}
+
+func TestPipxOutdatedChecker_Check(t *testing.T) {
+	t.Parallel()
+
+	latest := map[string]string{"black": "24.2.0", "httpie": "3.2.2"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := filepath.Base(filepath.Dir(r.URL.Path))
+		version, ok := latest[name]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"info": map[string]string{"version": version}})
+	}))
+	defer server.Close()
+
+	checker := &PipxOutdatedChecker{
+		execCommand: fakeOutputs(map[string]string{
+			"pipx list --json": `{"venvs": {
+				"black": {"metadata": {"main_package": {"package": "black", "package_version": "24.1.0"}}},
+				"httpie": {"metadata": {"main_package": {"package": "httpie", "package_version": "3.2.2"}}}
+			}}`,
+		}),
+		lookPath: fakeLookPath("pipx"),
+		client:   server.Client(),
+		indexURL: server.URL + "/pypi",
+	}
+
+	result, err := checker.Check(context.Background(), OutdatedOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, OutdatedPackages{
+		{Name: "black", CurrentVersion: "24.1.0", LatestVersion: "24.2.0", UpdateType: UpdateMinor, Provider: "pipx"},
+	}, result.Packages)
+
+	delete(latest, "httpie")
+	_, err = checker.Check(context.Background(), OutdatedOptions{})
+	require.Error(t, err)
+}
+
+func TestVSCodeOutdatedChecker_Check(t *testing.T) {
+	t.Parallel()
+
+	var query struct {
+		Filters []struct {
+			Criteria []struct {
+				FilterType int    `json:"filterType"`
+				Value      string `json:"value"`
+			} `json:"criteria"`
+		} `json:"filters"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/extensionquery", r.URL.Path)
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&query))
+		_, _ = w.Write([]byte(`{"results": [{"extensions": [
+			{"extensionName": "Go", "publisher": {"publisherName": "golang"}, "versions": [{"version": "0.42.0"}]},
+			{"extensionName": "python", "publisher": {"publisherName": "ms-python"}, "versions": [{"version": "2024.4.0"}]}
+		]}]}`))
+	}))
+	defer server.Close()
+
+	checker := &VSCodeOutdatedChecker{
+		execCommand: fakeOutputs(map[string]string{
+			"code --list-extensions --show-versions": "golang.go@0.41.2\nms-python.python@2024.4.0\n",
+		}),
+		lookPath:       fakeLookPath("code"),
+		client:         server.Client(),
+		marketplaceURL: server.URL,
+	}
+
+	result, err := checker.Check(context.Background(), OutdatedOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, OutdatedPackages{
+		{Name: "golang.go", CurrentVersion: "0.41.2", LatestVersion: "0.42.0", UpdateType: UpdateMinor, Provider: "vscode"},
+	}, result.Packages)
+
+	require.Len(t, query.Filters, 1)
+	require.Len(t, query.Filters[0].Criteria, 2)
+	assert.Equal(t, 7, query.Filters[0].Criteria[0].FilterType)
+	assert.Equal(t, "golang.go", query.Filters[0].Criteria[0].Value)
+}
+
+func TestGoOutdatedChecker_Check(t *testing.T) {
+	t.Parallel()
+
+	gopath := t.TempDir()
+	binDir := filepath.Join(gopath, "bin")
+	require.NoError(t, os.MkdirAll(binDir, 0o755))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/golang.org/x/tools/gopls/@latest":
+			_, _ = w.Write([]byte(`{"Version": "v0.16.1"}`))
+		case "/github.com/!burnt!sushi/toml/cmd/tomlv/@latest":
+			_, _ = w.Write([]byte(`{"Version": "v1.4.0"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	checker := &GoOutdatedChecker{
+		execCommand: fakeOutputs(map[string]string{
+			"go env GOBIN GOPATH GOPROXY": "\n" + gopath + "\n" + server.URL + ",direct\n",
+			"go version -m " + binDir: binDir + "/gopls: go1.23.0\n" +
+				"\tpath\tgolang.org/x/tools/gopls\n" +
+				"\tmod\tgolang.org/x/tools/gopls\tv0.15.3\th1:abc=\n" +
+				"\tdep\tgolang.org/x/mod\tv0.20.0\th1:def=\n" +
+				binDir + "/tomlv: go1.23.0\n" +
+				"\tpath\tgithub.com/BurntSushi/toml/cmd/tomlv\n" +
+				"\tmod\tgithub.com/BurntSushi/toml/cmd/tomlv\tv1.4.0\th1:ghi=\n" +
+				binDir + "/mytool: go1.23.0\n" +
+				"\tmod\texample.com/mytool\t(devel)\t\n",
+		}),
+		lookPath: fakeLookPath("go"),
+		client:   server.Client(),
+	}
+
+	result, err := checker.Check(context.Background(), OutdatedOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, OutdatedPackages{
+		{Name: "golang.org/x/tools/gopls", CurrentVersion: "v0.15.3", LatestVersion: "v0.16.1", UpdateType: UpdateMinor, Provider: "go"},
+	}, result.Packages)
+}
+
+func TestGoToolLocations(t *testing.T) {
+	t.Parallel()
+
+	binDir, proxy := goToolLocations("/custom/bin\n/home/me/go\nhttps://goproxy.example.com/,direct\n")
+	assert.Equal(t, "/custom/bin", binDir)
+	assert.Equal(t, "https://goproxy.example.com", proxy)
+
+	binDir, proxy = goToolLocations("\n/home/me/go" + string(filepath.ListSeparator) + "/other\noff\n")
+	assert.Equal(t, filepath.Join("/home/me/go", "bin"), binDir)
+	assert.Equal(t, DefaultGoProxyURL, proxy)
+}
+
+func TestNvimOutdatedChecker_Check(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	lockPath := filepath.Join(dir, "lazy-lock.json")
+	pluginDir := filepath.Join(dir, "lazy")
+	require.NoError(t, os.WriteFile(lockPath, []byte(`{
+		"lazy.nvim": {"branch": "main", "commit": "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"},
+		"telescope.nvim": {"branch": "master", "commit": "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"},
+		"not-installed.nvim": {"branch": "main", "commit": "cccccccccccccccccccccccccccccccccccccccc"}
+	}`), 0o644))
+	for _, name := range []string{"lazy.nvim", "telescope.nvim"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(pluginDir, name), 0o755))
+	}
+
+	checker := &NvimOutdatedChecker{
+		execCommand: fakeOutputs(map[string]string{
+			"git -C " + filepath.Join(pluginDir, "lazy.nvim") + " ls-remote origin refs/heads/main":        "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa\trefs/heads/main\n",
+			"git -C " + filepath.Join(pluginDir, "telescope.nvim") + " ls-remote origin refs/heads/master": "dddddddddddddddddddddddddddddddddddddddd\trefs/heads/master\n",
+		}),
+		lockPath:  lockPath,
+		pluginDir: pluginDir,
+	}
+	require.True(t, checker.Available())
+
+	// Commits have no update type, so they are reported without --all.
+	result, err := checker.Check(context.Background(), OutdatedOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, OutdatedPackages{
+		{Name: "telescope.nvim", CurrentVersion: "bbbbbbb", LatestVersion: "ddddddd", UpdateType: UpdateUnknown, Provider: "nvim"},
+	}, result.Packages)
+
+	checker.lockPath = filepath.Join(dir, "missing.json")
+	assert.False(t, checker.Available())
+}
@@ -3,6 +3,7 @@ package security
 import (
 	"context"
 	"os/exec"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -865,6 +866,34 @@ func TestBrewOutdatedChecker_Upgrade_WithMockExec(t *testing.T) {
 		assert.Equal(t, UpdateMajor, result.Skipped[0].UpdateType)
 	})
 
+	t.Run("hold pinned packages", func(t *testing.T) {
+		t.Parallel()
+		checker := &BrewOutdatedChecker{
+			execCommand: func(_ context.Context, name string, args ...string) *exec.Cmd {
+				if name == "brew" && len(args) > 0 && args[0] == "outdated" {
+					return exec.Command("printf", "%s", brewOutdatedJSON)
+				}
+				return exec.Command("echo", "ok")
+			},
+		}
+
+		if !checker.Available() {
+			t.Skip("brew not available")
+		}
+
+		opts := UpgradeOptions{DryRun: true, PinnedPackages: []string{"brew:go", "cask:docker"}}
+		result, err := checker.Upgrade(context.Background(), nil, opts)
+		require.NoError(t, err)
+		assert.Empty(t, result.Upgraded)
+		require.Len(t, result.Skipped, 1)
+		assert.Equal(t, "node", result.Skipped[0].Name)
+
+		result, err = checker.Upgrade(context.Background(), []string{"go"}, opts)
+		require.NoError(t, err)
+		require.Len(t, result.Skipped, 1)
+		assert.Equal(t, SkipReasonPinned, result.Skipped[0].Reason)
+	})
+
 	t.Run("include major upgrade", func(t *testing.T) {
 		t.Parallel()
 		checker := &BrewOutdatedChecker{
@@ -1055,3 +1084,27 @@ func TestExtractMinor_EdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestBrewOutdatedChecker_Rollback(t *testing.T) {
+	t.Parallel()
+
+	var ran []string
+	checker := &BrewOutdatedChecker{
+		execCommand: func(_ context.Context, name string, args ...string) *exec.Cmd {
+			ran = append(ran, name+" "+strings.Join(args, " "))
+			return exec.Command("true")
+		},
+	}
+
+	require.NoError(t, checker.Rollback(context.Background(), UpgradedPackage{Name: "node", FromVersion: "18.0.0", ToVersion: "20.0.0", Provider: "brew"}))
+	assert.Equal(t, []string{"brew uninstall --ignore-dependencies node", "brew link --overwrite node"}, ran)
+
+	err := checker.Rollback(context.Background(), UpgradedPackage{Name: "firefox", Provider: "cask"})
+	require.ErrorIs(t, err, ErrRollbackUnsupported)
+
+	checker.execCommand = func(_ context.Context, _ string, _ ...string) *exec.Cmd {
+		return exec.Command("sh", "-c", "echo 'Error: No such keg' >&2; exit 1")
+	}
+	err = checker.Rollback(context.Background(), UpgradedPackage{Name: "node", Provider: "brew"})
+	require.ErrorContains(t, err, "No such keg")
+}
@@ -111,6 +111,13 @@ type RedundancyOptions struct {
 	KeepPackages    []string `json:"keep_packages"`
 	IncludeOrphans  bool     `json:"include_orphans"`
 	IncludeOverlaps bool     `json:"include_overlaps"`
+	// DeclaredPackages are the Homebrew formulae and casks the configuration
+	// declares. When set, packages installed on request that are missing
+	// from it are reported as undeclared.
+	DeclaredPackages []string `json:"declared_packages,omitempty"`
+	// SafePackages are patterns of formulae never reported as orphans, in
+	// addition to DefaultSafeRuntimes.
+	SafePackages []string `json:"safe_packages,omitempty"`
 }
 
 // ToolCategory defines a category of overlapping tools.
@@ -246,10 +253,7 @@ func (b *BrewRedundancyChecker) Check(ctx context.Context, opts RedundancyOption
 
 	// Detect orphaned dependencies
 	if opts.IncludeOrphans {
-		orphans := b.detectOrphans(ctx, ignoreMap)
-		if len(orphans.Packages) > 0 {
-			result.Redundancies = append(result.Redundancies, orphans)
-		}
+		result.Redundancies = append(result.Redundancies, b.detectOrphans(ctx, ignoreMap, opts)...)
 	}
 
 	return result, nil
@@ -363,96 +367,56 @@ func (b *BrewRedundancyChecker) detectDuplicates(packages []string, ignore, keep
 
 // detectOverlaps finds overlapping tools in the same category.
 func (b *BrewRedundancyChecker) detectOverlaps(packages []string, ignore map[string]bool) Redundancies {
-	packageSet := make(map[string]bool)
+	installed := make(map[string]string, len(packages))
 	for _, pkg := range packages {
-		if !ignore[pkg] {
-			packageSet[pkg] = true
-		}
+		installed[pkg] = pkg
 	}
-
-	redundancies := make(Redundancies, 0)
-
-	for _, category := range b.toolCategories {
-		// Find installed tools in this category
-		var installed []string
-		for _, tool := range category.Tools {
-			if packageSet[tool] {
-				installed = append(installed, tool)
-			}
-		}
-
-		if len(installed) <= 1 {
-			continue
-		}
-
-		red := Redundancy{
-			Type:     RedundancyOverlap,
-			Packages: installed,
-			Category: category.Name,
-		}
-
-		if category.KeepAll {
-			red.Recommendation = fmt.Sprintf("%s - typically used together", category.Description)
-			red.Keep = installed
-		} else {
-			red.Recommendation = fmt.Sprintf("%s - consider keeping only one", category.Description)
-			red.Keep = []string{installed[0]}
-			if len(installed) > 1 {
-				red.Remove = installed[1:]
-			}
-		}
-
-		redundancies = append(redundancies, red)
-	}
-
-	return redundancies
+	return detectCategoryOverlaps(b.toolCategories, installed, ignore)
 }
 
-// detectOrphans finds orphaned dependencies.
-func (b *BrewRedundancyChecker) detectOrphans(ctx context.Context, ignore map[string]bool) Redundancy {
-	// Get packages that are installed but not required by others
-	cmd := b.execCommand(ctx, "brew", "autoremove", "--dry-run")
-	var stdout bytes.Buffer
-	cmd.Stdout = &stdout
-
-	// autoremove returns exit code 0 even with packages to remove
-	_ = cmd.Run()
-
-	output := stdout.String()
-	var orphans []string
-
-	// Parse "Would remove: pkg1, pkg2, ..." or line-by-line
-	lines := strings.Split(output, "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "==>") {
-			continue
-		}
-		// Skip informational lines
-		if strings.Contains(line, "Would") || strings.Contains(line, "Nothing") {
-			continue
-		}
-		// Extract package names
-		for _, pkg := range strings.Fields(line) {
-			pkg = strings.TrimSuffix(pkg, ",")
-			if pkg != "" && !ignore[pkg] {
-				orphans = append(orphans, pkg)
-			}
-		}
+// detectOrphans finds orphaned dependencies in the dependency graph of the
+// installed packages and, when the configuration is known, packages
+// installed on request that it does not declare.
+func (b *BrewRedundancyChecker) detectOrphans(ctx context.Context, ignore map[string]bool, opts RedundancyOptions) Redundancies {
+	graph, err := b.readGraph(ctx)
+	if err != nil {
+		return nil
 	}
 
-	if len(orphans) == 0 {
-		return Redundancy{}
+	declared := make(map[string]bool, len(opts.DeclaredPackages))
+	for _, pkg := range opts.DeclaredPackages {
+		declared[shortBrewName(pkg)] = true
 	}
+	safe := append(DefaultSafeRuntimes(), opts.SafePackages...)
+	plan := planBrewOrphans(graph, declared, safe, ignore)
 
-	return Redundancy{
-		Type:           RedundancyOrphan,
-		Packages:       orphans,
-		Category:       "orphaned_dependencies",
-		Recommendation: fmt.Sprintf("%d orphaned dependencies can be removed", len(orphans)),
-		Action:         "preflight cleanup --autoremove",
-		Remove:         orphans,
+	redundancies := make(Redundancies, 0, 2)
+	if len(plan.Dependencies) > 0 {
+		redundancies = append(redundancies, Redundancy{
+			Type:           RedundancyOrphan,
+			Packages:       plan.Dependencies,
+			Category:       "orphaned_dependencies",
+			Recommendation: fmt.Sprintf("%d orphaned dependencies can be removed", len(plan.Dependencies)),
+			Action:         "preflight cleanup --autoremove",
+			Remove:         plan.Dependencies,
+		})
 	}
+	if len(plan.Undeclared) > 0 {
+		// Packages installed on request were chosen by someone, so they are
+		// reported but never removed by --all.
+		recommendation := fmt.Sprintf("%d packages installed on request are not in your configuration", len(plan.Undeclared))
+		if len(plan.Freed) > 0 {
+			recommendation += fmt.Sprintf("; removing them also frees %s", strings.Join(plan.Freed, ", "))
+		}
+		redundancies = append(redundancies, Redundancy{
+			Type:           RedundancyOrphan,
+			Packages:       plan.Undeclared,
+			Category:       "undeclared_packages",
+			Recommendation: recommendation,
+			Action:         "preflight cleanup --remove " + strings.Join(append(append([]string(nil), plan.Undeclared...), plan.Freed...), ","),
+		})
+	}
+	return redundancies
 }
 
 // Cleanup removes specified packages.
@@ -478,38 +442,22 @@ func (b *BrewRedundancyChecker) Cleanup(ctx context.Context, packages []string,
 	return nil
 }
 
-// Autoremove removes orphaned dependencies.
+// Autoremove removes orphaned dependencies, as brew autoremove does but
+// keeping DefaultSafeRuntimes. It returns the formulae removed, or that
+// would be removed when dryRun is set.
 func (b *BrewRedundancyChecker) Autoremove(ctx context.Context, dryRun bool) ([]string, error) {
-	args := []string{"autoremove"}
-	if dryRun {
-		args = append(args, "--dry-run")
+	graph, err := b.readGraph(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to autoremove: %w", err)
 	}
-
-	cmd := b.execCommand(ctx, "brew", args...)
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("failed to autoremove: %w: %s", err, stderr.String())
+	removable := planBrewOrphans(graph, nil, DefaultSafeRuntimes(), nil).Dependencies
+	if dryRun || len(removable) == 0 {
+		return removable, nil
 	}
-
-	// Parse removed packages from output
-	var removed []string
-	lines := strings.Split(stdout.String(), "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line != "" && !strings.HasPrefix(line, "==>") && !strings.Contains(line, "Nothing") {
-			for _, pkg := range strings.Fields(line) {
-				pkg = strings.TrimSuffix(pkg, ",")
-				if pkg != "" {
-					removed = append(removed, pkg)
-				}
-			}
-		}
+	if err := b.Cleanup(ctx, removable, false); err != nil {
+		return nil, fmt.Errorf("failed to autoremove: %w", err)
 	}
-
-	return removed, nil
+	return removable, nil
 }
 
 // RedundancyCheckerRegistry manages available redundancy checkers.
@@ -555,6 +503,8 @@ type CleanupResult struct {
 	Removed []string `json:"removed"`
 	DryRun  bool     `json:"dry_run"`
 	Error   string   `json:"error,omitempty"`
+	// FreedBytes is the disk space cache cleanup freed, or would free.
+	FreedBytes int64 `json:"freed_bytes,omitempty"`
 }
 
 // CleanupResultJSON is the JSON output format for cleanup.
@@ -0,0 +1,850 @@
+package security
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Packages reported by the npm, pip and cargo checkers are references of
+// the form "manager:name", e.g. "npm:eslint" or "pipx:black", so that
+// cleanup knows how to uninstall them. Names without a manager are
+// Homebrew packages.
+
+// packageManagers are the managers a package reference can name.
+var packageManagers = map[string]bool{
+	"brew":  true,
+	"npm":   true,
+	"pnpm":  true,
+	"yarn":  true,
+	"pip":   true,
+	"pipx":  true,
+	"cargo": true,
+}
+
+// ParsePackageRef splits a package reference into its manager and name.
+// References without a known manager are Homebrew packages.
+func ParsePackageRef(ref string) (manager, name string) {
+	if m, n, ok := strings.Cut(ref, ":"); ok && packageManagers[m] {
+		return m, n
+	}
+	return "brew", ref
+}
+
+func packageRef(manager, name string) string {
+	return manager + ":" + name
+}
+
+// UninstallCommands returns the commands that uninstall the referenced
+// packages, one per package manager in order of first appearance. pipx
+// uninstalls one package per command.
+func UninstallCommands(refs []string) [][]string {
+	var managers []string
+	byManager := make(map[string][]string)
+	for _, ref := range refs {
+		manager, name := ParsePackageRef(ref)
+		if _, seen := byManager[manager]; !seen {
+			managers = append(managers, manager)
+		}
+		byManager[manager] = append(byManager[manager], name)
+	}
+
+	var commands [][]string
+	for _, manager := range managers {
+		names := byManager[manager]
+		switch manager {
+		case "brew":
+			commands = append(commands, append([]string{"brew", "uninstall"}, names...))
+		case "npm":
+			commands = append(commands, append([]string{"npm", "uninstall", "-g"}, names...))
+		case "pnpm":
+			commands = append(commands, append([]string{"pnpm", "remove", "-g"}, names...))
+		case "yarn":
+			commands = append(commands, append([]string{"yarn", "global", "remove"}, names...))
+		case "pip":
+			commands = append(commands, append([]string{"python3", "-m", "pip", "uninstall", "-y"}, names...))
+		case "pipx":
+			for _, name := range names {
+				commands = append(commands, []string{"pipx", "uninstall", name})
+			}
+		case "cargo":
+			commands = append(commands, append([]string{"cargo", "uninstall"}, names...))
+		}
+	}
+	return commands
+}
+
+// uninstallAction renders the uninstall commands of refs for display.
+func uninstallAction(refs []string) string {
+	commands := UninstallCommands(refs)
+	lines := make([]string, 0, len(commands))
+	for _, command := range commands {
+		lines = append(lines, strings.Join(command, " "))
+	}
+	return strings.Join(lines, " && ")
+}
+
+// PackageUninstaller removes packages with the manager that installed them.
+type PackageUninstaller struct {
+	execCommand func(ctx context.Context, name string, args ...string) *exec.Cmd
+}
+
+// NewPackageUninstaller creates a new package uninstaller.
+func NewPackageUninstaller() *PackageUninstaller {
+	return &PackageUninstaller{execCommand: exec.CommandContext}
+}
+
+// Uninstall runs the uninstall commands of the referenced packages.
+func (u *PackageUninstaller) Uninstall(ctx context.Context, refs []string) error {
+	for _, command := range UninstallCommands(refs) {
+		cmd := u.execCommand(ctx, command[0], command[1:]...)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("failed to run %s: %w: %s", strings.Join(command, " "), err, stderr.String())
+		}
+	}
+	return nil
+}
+
+// detectCategoryOverlaps finds categories with more than one installed
+// tool. installed maps package names to the references reported.
+func detectCategoryOverlaps(categories []ToolCategory, installed map[string]string, ignore map[string]bool) Redundancies {
+	redundancies := make(Redundancies, 0)
+
+	for _, category := range categories {
+		var found []string
+		for _, tool := range category.Tools {
+			if ref, ok := installed[tool]; ok && !ignore[ref] && !ignore[tool] {
+				found = append(found, ref)
+			}
+		}
+
+		if len(found) <= 1 {
+			continue
+		}
+
+		red := Redundancy{
+			Type:     RedundancyOverlap,
+			Packages: found,
+			Category: category.Name,
+		}
+
+		if category.KeepAll {
+			red.Recommendation = fmt.Sprintf("%s - typically used together", category.Description)
+			red.Keep = found
+		} else {
+			red.Recommendation = fmt.Sprintf("%s - consider keeping only one", category.Description)
+			red.Keep = []string{found[0]}
+			red.Remove = found[1:]
+		}
+
+		redundancies = append(redundancies, red)
+	}
+
+	return redundancies
+}
+
+// detectManagerDuplicates finds packages installed by more than one of
+// managers, keeping the copy of the first manager unless another copy is
+// marked to keep.
+func detectManagerDuplicates(managers []string, installed map[string][]string, ignore, keep map[string]bool) Redundancies {
+	owners := make(map[string][]string)
+	for _, manager := range managers {
+		for _, name := range installed[manager] {
+			if ignore[name] || ignore[packageRef(manager, name)] {
+				continue
+			}
+			owners[name] = append(owners[name], manager)
+		}
+	}
+
+	names := make([]string, 0, len(owners))
+	for name, found := range owners {
+		if len(found) > 1 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	redundancies := make(Redundancies, 0, len(names))
+	for _, name := range names {
+		refs := make([]string, 0, len(owners[name]))
+		keepRef := ""
+		for _, manager := range owners[name] {
+			ref := packageRef(manager, name)
+			refs = append(refs, ref)
+			if keepRef == "" && keep[ref] {
+				keepRef = ref
+			}
+		}
+		if keepRef == "" {
+			keepRef = refs[0]
+		}
+
+		var remove []string
+		for _, ref := range refs {
+			if ref != keepRef {
+				remove = append(remove, ref)
+			}
+		}
+
+		redundancies = append(redundancies, Redundancy{
+			Type:           RedundancyDuplicate,
+			Packages:       refs,
+			Category:       name,
+			Recommendation: fmt.Sprintf("Installed with %s - keep %s", strings.Join(owners[name], " and "), keepRef),
+			Action:         uninstallAction(remove),
+			Keep:           []string{keepRef},
+			Remove:         remove,
+		})
+	}
+	return redundancies
+}
+
+// setOverlapActions fills in the uninstall commands of overlaps.
+func setOverlapActions(redundancies Redundancies) {
+	for i := range redundancies {
+		if len(redundancies[i].Remove) > 0 {
+			redundancies[i].Action = uninstallAction(redundancies[i].Remove)
+		}
+	}
+}
+
+// refSets builds the option sets of a check; references and plain names
+// are both accepted.
+func refSets(opts RedundancyOptions) (ignore, keep map[string]bool) {
+	ignore = make(map[string]bool)
+	for _, pkg := range opts.IgnorePackages {
+		ignore[pkg] = true
+	}
+	keep = make(map[string]bool)
+	for _, pkg := range opts.KeepPackages {
+		keep[pkg] = true
+	}
+	return ignore, keep
+}
+
+// NodeToolCategories returns overlapping categories of global Node.js packages.
+func NodeToolCategories() []ToolCategory {
+	return []ToolCategory{
+		{
+			Name:        "node_package_managers",
+			Description: "Node.js package managers",
+			Tools:       []string{"npm", "yarn", "pnpm", "bun"},
+		},
+		{
+			Name:        "js_formatters",
+			Description: "JavaScript formatters",
+			Tools:       []string{"prettier", "@biomejs/biome", "dprint"},
+		},
+		{
+			Name:        "js_linters",
+			Description: "JavaScript linters",
+			Tools:       []string{"eslint", "oxlint", "standard", "jshint"},
+		},
+		{
+			Name:        "typescript_runners",
+			Description: "TypeScript runners",
+			Tools:       []string{"tsx", "ts-node", "esno"},
+		},
+	}
+}
+
+// NodeRedundancyChecker checks the global packages of npm, pnpm and yarn.
+type NodeRedundancyChecker struct {
+	execCommand    func(ctx context.Context, name string, args ...string) *exec.Cmd
+	lookPath       func(file string) (string, error)
+	toolCategories []ToolCategory
+}
+
+// NewNodeRedundancyChecker creates a new Node.js redundancy checker.
+func NewNodeRedundancyChecker() *NodeRedundancyChecker {
+	return &NodeRedundancyChecker{
+		execCommand:    exec.CommandContext,
+		lookPath:       exec.LookPath,
+		toolCategories: NodeToolCategories(),
+	}
+}
+
+// nodeManagers are the Node.js managers in order of preference.
+var nodeManagers = []string{"npm", "pnpm", "yarn"}
+
+// Name returns the checker name.
+func (n *NodeRedundancyChecker) Name() string {
+	return "npm"
+}
+
+// Available returns true if npm, pnpm or yarn is installed.
+func (n *NodeRedundancyChecker) Available() bool {
+	for _, manager := range nodeManagers {
+		if _, err := n.lookPath(manager); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Check returns packages installed globally by more than one manager and
+// overlapping global tools.
+func (n *NodeRedundancyChecker) Check(ctx context.Context, opts RedundancyOptions) (*RedundancyResult, error) {
+	if !n.Available() {
+		return nil, ErrScannerNotAvailable
+	}
+
+	installed := make(map[string][]string)
+	for _, manager := range nodeManagers {
+		if _, err := n.lookPath(manager); err != nil {
+			continue
+		}
+		packages, err := n.globalPackages(ctx, manager)
+		if err != nil {
+			return nil, err
+		}
+		installed[manager] = packages
+	}
+
+	ignore, keep := refSets(opts)
+	result := &RedundancyResult{
+		Checker:      n.Name(),
+		CheckedAt:    time.Now(),
+		Redundancies: detectManagerDuplicates(nodeManagers, installed, ignore, keep),
+	}
+
+	if opts.IncludeOverlaps {
+		refs := make(map[string]string)
+		for _, manager := range nodeManagers {
+			for _, name := range installed[manager] {
+				if _, ok := refs[name]; !ok {
+					refs[name] = packageRef(manager, name)
+				}
+			}
+		}
+		overlaps := detectCategoryOverlaps(n.toolCategories, refs, ignore)
+		setOverlapActions(overlaps)
+		result.Redundancies = append(result.Redundancies, overlaps...)
+	}
+
+	return result, nil
+}
+
+// globalPackages lists the global packages of a Node.js manager.
+func (n *NodeRedundancyChecker) globalPackages(ctx context.Context, manager string) ([]string, error) {
+	var args []string
+	switch manager {
+	case "npm":
+		args = []string{"ls", "-g", "--depth=0", "--json"}
+	case "pnpm":
+		args = []string{"ls", "-g", "--depth=0", "--json"}
+	case "yarn":
+		args = []string{"global", "list"}
+	}
+
+	cmd := n.execCommand(ctx, manager, args...)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		switch {
+		case manager != "npm":
+			// pnpm and yarn fail when no global package was ever installed.
+			return nil, nil
+		case stdout.Len() == 0:
+			return nil, fmt.Errorf("failed to list npm global packages: %w", err)
+		}
+		// npm also fails on extraneous or invalid packages, but still
+		// lists them.
+	}
+
+	switch manager {
+	case "npm":
+		return parseNpmGlobals(stdout.Bytes())
+	case "pnpm":
+		return parsePnpmGlobals(stdout.Bytes())
+	default:
+		return parseYarnGlobals(stdout.String()), nil
+	}
+}
+
+type npmListOutput struct {
+	Dependencies map[string]json.RawMessage `json:"dependencies"`
+}
+
+// parseNpmGlobals parses the output of npm ls -g --json.
+func parseNpmGlobals(data []byte) ([]string, error) {
+	var output npmListOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, fmt.Errorf("failed to parse npm output: %w", err)
+	}
+	return sortedKeys(output.Dependencies), nil
+}
+
+// parsePnpmGlobals parses the output of pnpm ls -g --json, a list of
+// projects.
+func parsePnpmGlobals(data []byte) ([]string, error) {
+	var output []npmListOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, fmt.Errorf("failed to parse pnpm output: %w", err)
+	}
+	deps := make(map[string]json.RawMessage)
+	for _, project := range output {
+		for name, dep := range project.Dependencies {
+			deps[name] = dep
+		}
+	}
+	return sortedKeys(deps), nil
+}
+
+// yarnGlobalRegex matches `info "name@version" has binaries:` lines.
+var yarnGlobalRegex = regexp.MustCompile(`^info "(.+)@[^@"]+" has binaries`)
+
+// parseYarnGlobals parses the output of yarn global list.
+func parseYarnGlobals(output string) []string {
+	var packages []string
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		if matches := yarnGlobalRegex.FindStringSubmatch(strings.TrimSpace(scanner.Text())); len(matches) > 1 {
+			packages = append(packages, matches[1])
+		}
+	}
+	return packages
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// PythonToolCategories returns overlapping categories of Python tools.
+func PythonToolCategories() []ToolCategory {
+	return []ToolCategory{
+		{
+			Name:        "python_package_managers",
+			Description: "Python package managers",
+			Tools:       []string{"uv", "poetry", "pdm", "pipenv", "hatch"},
+		},
+		{
+			Name:        "python_formatters",
+			Description: "Python formatters",
+			Tools:       []string{"ruff", "black", "yapf", "autopep8"},
+		},
+		{
+			Name:        "python_linters",
+			Description: "Python linters",
+			Tools:       []string{"ruff", "flake8", "pylint", "pycodestyle"},
+		},
+	}
+}
+
+// PythonRedundancyChecker checks packages installed with pip into the user
+// site and applications installed with pipx.
+type PythonRedundancyChecker struct {
+	execCommand    func(ctx context.Context, name string, args ...string) *exec.Cmd
+	lookPath       func(file string) (string, error)
+	toolCategories []ToolCategory
+}
+
+// NewPythonRedundancyChecker creates a new Python redundancy checker.
+func NewPythonRedundancyChecker() *PythonRedundancyChecker {
+	return &PythonRedundancyChecker{
+		execCommand:    exec.CommandContext,
+		lookPath:       exec.LookPath,
+		toolCategories: PythonToolCategories(),
+	}
+}
+
+// Name returns the checker name.
+func (p *PythonRedundancyChecker) Name() string {
+	return "pip"
+}
+
+// Available returns true if python3 or pipx is installed.
+func (p *PythonRedundancyChecker) Available() bool {
+	for _, tool := range []string{"python3", "pipx"} {
+		if _, err := p.lookPath(tool); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Check returns packages installed with both pip and pipx, overlapping
+// tools and user packages that were installed as dependencies of packages
+// since removed.
+func (p *PythonRedundancyChecker) Check(ctx context.Context, opts RedundancyOptions) (*RedundancyResult, error) {
+	if !p.Available() {
+		return nil, ErrScannerNotAvailable
+	}
+
+	var userPackages []pipPackage
+	if _, err := p.lookPath("python3"); err == nil {
+		packages, err := p.userPackages(ctx)
+		if err != nil {
+			return nil, err
+		}
+		userPackages = packages
+	}
+	var pipxApps []string
+	if _, err := p.lookPath("pipx"); err == nil {
+		apps, err := p.pipxApps(ctx)
+		if err != nil {
+			return nil, err
+		}
+		pipxApps = apps
+	}
+
+	installed := map[string][]string{"pipx": pipxApps}
+	for _, pkg := range userPackages {
+		installed["pip"] = append(installed["pip"], pkg.Name)
+	}
+
+	ignore, keep := refSets(opts)
+	result := &RedundancyResult{
+		Checker:   p.Name(),
+		CheckedAt: time.Now(),
+		// pipx installs each application in its own environment, so its
+		// copy is the one to keep.
+		Redundancies: detectManagerDuplicates([]string{"pipx", "pip"}, installed, ignore, keep),
+	}
+
+	if opts.IncludeOverlaps {
+		refs := make(map[string]string)
+		for _, manager := range []string{"pipx", "pip"} {
+			for _, name := range installed[manager] {
+				if _, ok := refs[name]; !ok {
+					refs[name] = packageRef(manager, name)
+				}
+			}
+		}
+		overlaps := detectCategoryOverlaps(p.toolCategories, refs, ignore)
+		setOverlapActions(overlaps)
+		result.Redundancies = append(result.Redundancies, overlaps...)
+	}
+
+	if opts.IncludeOrphans {
+		if orphans := detectPipOrphans(userPackages, ignore); len(orphans.Packages) > 0 {
+			result.Redundancies = append(result.Redundancies, orphans)
+		}
+	}
+
+	return result, nil
+}
+
+// pipPackage is a package installed into the user site.
+type pipPackage struct {
+	Name      string
+	Requested bool
+	Requires  []string
+}
+
+type pipInspectOutput struct {
+	Installed []struct {
+		Metadata struct {
+			Name         string   `json:"name"`
+			RequiresDist []string `json:"requires_dist"`
+		} `json:"metadata"`
+		Requested bool `json:"requested"`
+	} `json:"installed"`
+}
+
+// userPackages lists the packages in the user site with pip inspect.
+func (p *PythonRedundancyChecker) userPackages(ctx context.Context) ([]pipPackage, error) {
+	cmd := p.execCommand(ctx, "python3", "-m", "pip", "inspect", "--user")
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to list pip user packages: %w", err)
+	}
+	return parsePipInspect(stdout.Bytes())
+}
+
+// requirementNameRegex matches the project name of a requirement.
+var requirementNameRegex = regexp.MustCompile(`^\s*([A-Za-z0-9][A-Za-z0-9._-]*)`)
+
+// parsePipInspect parses the output of pip inspect.
+func parsePipInspect(data []byte) ([]pipPackage, error) {
+	var output pipInspectOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, fmt.Errorf("failed to parse pip output: %w", err)
+	}
+
+	packages := make([]pipPackage, 0, len(output.Installed))
+	for _, dist := range output.Installed {
+		pkg := pipPackage{
+			Name:      normalizePythonName(dist.Metadata.Name),
+			Requested: dist.Requested,
+		}
+		for _, req := range dist.Metadata.RequiresDist {
+			if matches := requirementNameRegex.FindStringSubmatch(req); len(matches) > 1 {
+				pkg.Requires = append(pkg.Requires, normalizePythonName(matches[1]))
+			}
+		}
+		packages = append(packages, pkg)
+	}
+	sort.Slice(packages, func(i, j int) bool { return packages[i].Name < packages[j].Name })
+	return packages, nil
+}
+
+var pythonNameSeparators = regexp.MustCompile(`[-_.]+`)
+
+// normalizePythonName normalizes a project name as PEP 503 does.
+func normalizePythonName(name string) string {
+	return strings.ToLower(pythonNameSeparators.ReplaceAllString(name, "-"))
+}
+
+// detectPipOrphans finds user packages that were installed as dependencies
+// and are no longer required by any other user package. Optional
+// dependencies count as required, so packages installed for an extra are
+// kept.
+func detectPipOrphans(packages []pipPackage, ignore map[string]bool) Redundancy {
+	required := make(map[string]bool)
+	for _, pkg := range packages {
+		for _, req := range pkg.Requires {
+			required[req] = true
+		}
+	}
+
+	var orphans []string
+	for _, pkg := range packages {
+		ref := packageRef("pip", pkg.Name)
+		if pkg.Requested || required[pkg.Name] || ignore[pkg.Name] || ignore[ref] {
+			continue
+		}
+		orphans = append(orphans, ref)
+	}
+
+	if len(orphans) == 0 {
+		return Redundancy{}
+	}
+
+	return Redundancy{
+		Type:           RedundancyOrphan,
+		Packages:       orphans,
+		Category:       "orphaned_dependencies",
+		Recommendation: fmt.Sprintf("%d orphaned pip dependencies can be removed", len(orphans)),
+		Action:         uninstallAction(orphans),
+		Remove:         orphans,
+	}
+}
+
+type pipxListOutput struct {
+	Venvs map[string]json.RawMessage `json:"venvs"`
+}
+
+// pipxApps lists the applications installed with pipx.
+func (p *PythonRedundancyChecker) pipxApps(ctx context.Context) ([]string, error) {
+	cmd := p.execCommand(ctx, "pipx", "list", "--json")
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to list pipx applications: %w", err)
+	}
+
+	var output pipxListOutput
+	if err := json.Unmarshal(stdout.Bytes(), &output); err != nil {
+		return nil, fmt.Errorf("failed to parse pipx output: %w", err)
+	}
+	apps := make([]string, 0, len(output.Venvs))
+	for _, name := range sortedKeys(output.Venvs) {
+		apps = append(apps, normalizePythonName(name))
+	}
+	return apps, nil
+}
+
+// CargoToolCategories returns overlapping categories of crates.
+func CargoToolCategories() []ToolCategory {
+	return []ToolCategory{
+		{
+			Name:        "file_listers",
+			Description: "ls replacements",
+			Tools:       []string{"eza", "lsd", "exa"},
+		},
+		{
+			Name:        "disk_usage_analyzers",
+			Description: "Disk usage analyzers",
+			Tools:       []string{"du-dust", "dua-cli", "diskus"},
+		},
+	}
+}
+
+// CargoRedundancyChecker checks crates installed with cargo install.
+type CargoRedundancyChecker struct {
+	execCommand    func(ctx context.Context, name string, args ...string) *exec.Cmd
+	lookPath       func(file string) (string, error)
+	toolCategories []ToolCategory
+	// binDir is where cargo installs binaries and path the PATH searched
+	// for other copies of them.
+	binDir string
+	path   string
+}
+
+// NewCargoRedundancyChecker creates a new cargo redundancy checker.
+func NewCargoRedundancyChecker() *CargoRedundancyChecker {
+	cargoHome := os.Getenv("CARGO_HOME")
+	if cargoHome == "" {
+		home, _ := os.UserHomeDir()
+		cargoHome = filepath.Join(home, ".cargo")
+	}
+	return &CargoRedundancyChecker{
+		execCommand:    exec.CommandContext,
+		lookPath:       exec.LookPath,
+		toolCategories: CargoToolCategories(),
+		binDir:         filepath.Join(cargoHome, "bin"),
+		path:           os.Getenv("PATH"),
+	}
+}
+
+// Name returns the checker name.
+func (c *CargoRedundancyChecker) Name() string {
+	return "cargo"
+}
+
+// Available returns true if cargo is installed.
+func (c *CargoRedundancyChecker) Available() bool {
+	_, err := c.lookPath("cargo")
+	return err == nil
+}
+
+// Check returns crates whose binaries are also installed elsewhere on the
+// PATH, typically by Homebrew, and overlapping crates.
+func (c *CargoRedundancyChecker) Check(ctx context.Context, opts RedundancyOptions) (*RedundancyResult, error) {
+	if !c.Available() {
+		return nil, ErrScannerNotAvailable
+	}
+
+	cmd := c.execCommand(ctx, "cargo", "install", "--list")
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to list cargo installs: %w", err)
+	}
+	crates := parseCargoInstallList(stdout.String())
+
+	ignore, keep := refSets(opts)
+	result := &RedundancyResult{
+		Checker:      c.Name(),
+		CheckedAt:    time.Now(),
+		Redundancies: c.detectShadowedCrates(crates, ignore, keep),
+	}
+
+	if opts.IncludeOverlaps {
+		refs := make(map[string]string, len(crates))
+		for _, crate := range crates {
+			refs[crate.Name] = packageRef("cargo", crate.Name)
+		}
+		overlaps := detectCategoryOverlaps(c.toolCategories, refs, ignore)
+		setOverlapActions(overlaps)
+		result.Redundancies = append(result.Redundancies, overlaps...)
+	}
+
+	return result, nil
+}
+
+// cargoCrate is a crate installed with cargo install.
+type cargoCrate struct {
+	Name     string
+	Binaries []string
+}
+
+// parseCargoInstallList parses the output of cargo install --list:
+//
+//	ripgrep v14.1.0:
+//	    rg
+func parseCargoInstallList(output string) []cargoCrate {
+	var crates []cargoCrate
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			if len(crates) > 0 {
+				last := &crates[len(crates)-1]
+				last.Binaries = append(last.Binaries, strings.TrimSpace(line))
+			}
+			continue
+		}
+		if fields := strings.Fields(line); len(fields) > 0 {
+			crates = append(crates, cargoCrate{Name: fields[0]})
+		}
+	}
+	return crates
+}
+
+// detectShadowedCrates finds crates with a binary that another directory
+// on the PATH also provides. The cargo copy is suggested for removal as
+// cargo does not upgrade it.
+func (c *CargoRedundancyChecker) detectShadowedCrates(crates []cargoCrate, ignore, keep map[string]bool) Redundancies {
+	redundancies := make(Redundancies, 0)
+	for _, crate := range crates {
+		ref := packageRef("cargo", crate.Name)
+		if ignore[crate.Name] || ignore[ref] {
+			continue
+		}
+		for _, binary := range crate.Binaries {
+			other := c.findElsewhere(binary)
+			if other == "" {
+				continue
+			}
+			red := Redundancy{
+				Type:           RedundancyDuplicate,
+				Packages:       []string{ref, other},
+				Category:       binary,
+				Recommendation: fmt.Sprintf("%s is also installed at %s - keep one", binary, other),
+			}
+			if keep[crate.Name] || keep[ref] {
+				red.Keep = []string{ref}
+			} else {
+				red.Keep = []string{other}
+				red.Remove = []string{ref}
+				red.Action = uninstallAction(red.Remove)
+			}
+			redundancies = append(redundancies, red)
+			break
+		}
+	}
+	return redundancies
+}
+
+// findElsewhere returns the path of an executable named binary in a PATH
+// directory other than the cargo bin directory, or "".
+func (c *CargoRedundancyChecker) findElsewhere(binary string) string {
+	for _, dir := range filepath.SplitList(c.path) {
+		if dir == "" || filepath.Clean(dir) == filepath.Clean(c.binDir) {
+			continue
+		}
+		candidate := filepath.Join(dir, binary)
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() && info.Mode()&0o111 != 0 {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// MergeRedundancyResults combines the results of several checkers.
+func MergeRedundancyResults(results []*RedundancyResult) *RedundancyResult {
+	merged := &RedundancyResult{
+		CheckedAt:    time.Now(),
+		Redundancies: make(Redundancies, 0),
+	}
+	names := make([]string, 0, len(results))
+	for _, result := range results {
+		names = append(names, result.Checker)
+		merged.Redundancies = append(merged.Redundancies, result.Redundancies...)
+	}
+	merged.Checker = strings.Join(names, ", ")
+	return merged
+}
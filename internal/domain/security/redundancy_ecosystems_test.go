@@ -0,0 +1,303 @@
+package security
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLookPath finds only the given tools.
+func fakeLookPath(tools ...string) func(string) (string, error) {
+	return func(file string) (string, error) {
+		for _, tool := range tools {
+			if tool == file {
+				return "/usr/bin/" + file, nil
+			}
+		}
+		return "", exec.ErrNotFound
+	}
+}
+
+// fakeOutputs answers each command, keyed by its name and arguments, with
+// the given output.
+func fakeOutputs(outputs map[string]string) func(context.Context, string, ...string) *exec.Cmd {
+	return func(_ context.Context, name string, args ...string) *exec.Cmd {
+		out, ok := outputs[strings.Join(append([]string{name}, args...), " ")]
+		if !ok {
+			return exec.Command("false")
+		}
+		return exec.Command("printf", "%s", out)
+	}
+}
+
+func TestParsePackageRef(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		ref     string
+		manager string
+		name    string
+	}{
+		{"go@1.24", "brew", "go@1.24"},
+		{"npm:eslint", "npm", "eslint"},
+		{"npm:@biomejs/biome", "npm", "@biomejs/biome"},
+		{"pipx:black", "pipx", "black"},
+		{"unknown:thing", "brew", "unknown:thing"},
+	}
+	for _, tt := range tests {
+		manager, name := ParsePackageRef(tt.ref)
+		assert.Equal(t, tt.manager, manager, tt.ref)
+		assert.Equal(t, tt.name, name, tt.ref)
+	}
+}
+
+func TestUninstallCommands(t *testing.T) {
+	t.Parallel()
+
+	commands := UninstallCommands([]string{
+		"npm:eslint", "go@1.24", "pipx:black", "npm:prettier", "pipx:flake8",
+		"pip:six", "pnpm:tsx", "yarn:serve", "cargo:exa",
+	})
+	assert.Equal(t, [][]string{
+		{"npm", "uninstall", "-g", "eslint", "prettier"},
+		{"brew", "uninstall", "go@1.24"},
+		{"pipx", "uninstall", "black"},
+		{"pipx", "uninstall", "flake8"},
+		{"python3", "-m", "pip", "uninstall", "-y", "six"},
+		{"pnpm", "remove", "-g", "tsx"},
+		{"yarn", "global", "remove", "serve"},
+		{"cargo", "uninstall", "exa"},
+	}, commands)
+}
+
+func TestPackageUninstaller_Uninstall(t *testing.T) {
+	t.Parallel()
+
+	t.Run("runs each command", func(t *testing.T) {
+		t.Parallel()
+		var ran []string
+		uninstaller := &PackageUninstaller{
+			execCommand: func(_ context.Context, name string, args ...string) *exec.Cmd {
+				ran = append(ran, strings.Join(append([]string{name}, args...), " "))
+				return exec.Command("true")
+			},
+		}
+
+		require.NoError(t, uninstaller.Uninstall(context.Background(), []string{"npm:eslint", "cargo:exa"}))
+		assert.Equal(t, []string{"npm uninstall -g eslint", "cargo uninstall exa"}, ran)
+	})
+
+	t.Run("stops at the first failure", func(t *testing.T) {
+		t.Parallel()
+		calls := 0
+		uninstaller := &PackageUninstaller{
+			execCommand: func(_ context.Context, _ string, _ ...string) *exec.Cmd {
+				calls++
+				return exec.Command("false")
+			},
+		}
+
+		err := uninstaller.Uninstall(context.Background(), []string{"npm:eslint", "cargo:exa"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "npm uninstall -g eslint")
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestNodeRedundancyChecker_Check(t *testing.T) {
+	t.Parallel()
+
+	checker := &NodeRedundancyChecker{
+		execCommand: fakeOutputs(map[string]string{
+			"npm ls -g --depth=0 --json":  `{"dependencies":{"npm":{"version":"10.8.0"},"yarn":{"version":"1.22.22"},"typescript":{"version":"5.6.2"},"prettier":{"version":"3.3.3"}}}`,
+			"pnpm ls -g --depth=0 --json": `[{"dependencies":{"typescript":{"version":"5.5.0"},"@biomejs/biome":{"version":"1.9.0"}}}]`,
+			"yarn global list":            "yarn global v1.22.22\ninfo \"typescript@5.4.5\" has binaries:\n   - tsc\n   - tsserver\nDone in 0.05s.\n",
+		}),
+		lookPath:       fakeLookPath("npm", "pnpm", "yarn"),
+		toolCategories: NodeToolCategories(),
+	}
+
+	result, err := checker.Check(context.Background(), RedundancyOptions{IncludeOverlaps: true})
+	require.NoError(t, err)
+	assert.Equal(t, "npm", result.Checker)
+
+	duplicates := result.Redundancies.ByType(RedundancyDuplicate)
+	require.Len(t, duplicates, 1)
+	assert.Equal(t, []string{"npm:typescript", "pnpm:typescript", "yarn:typescript"}, duplicates[0].Packages)
+	assert.Equal(t, []string{"npm:typescript"}, duplicates[0].Keep)
+	assert.Equal(t, []string{"pnpm:typescript", "yarn:typescript"}, duplicates[0].Remove)
+	assert.Equal(t, "pnpm remove -g typescript && yarn global remove typescript", duplicates[0].Action)
+
+	overlaps := result.Redundancies.ByType(RedundancyOverlap)
+	require.Len(t, overlaps, 2)
+	assert.Equal(t, "node_package_managers", overlaps[0].Category)
+	assert.Equal(t, []string{"npm:npm", "npm:yarn"}, overlaps[0].Packages)
+	assert.Equal(t, []string{"npm:yarn"}, overlaps[0].Remove)
+	assert.Equal(t, "npm uninstall -g yarn", overlaps[0].Action)
+	assert.Equal(t, "js_formatters", overlaps[1].Category)
+	assert.Equal(t, []string{"npm:prettier", "pnpm:@biomejs/biome"}, overlaps[1].Packages)
+}
+
+func TestNodeRedundancyChecker_Check_KeepAndIgnore(t *testing.T) {
+	t.Parallel()
+
+	checker := &NodeRedundancyChecker{
+		execCommand: fakeOutputs(map[string]string{
+			"npm ls -g --depth=0 --json":  `{"dependencies":{"typescript":{},"eslint":{}}}`,
+			"pnpm ls -g --depth=0 --json": `[{"dependencies":{"typescript":{},"eslint":{}}}]`,
+		}),
+		lookPath:       fakeLookPath("npm", "pnpm"),
+		toolCategories: NodeToolCategories(),
+	}
+
+	result, err := checker.Check(context.Background(), RedundancyOptions{
+		IgnorePackages: []string{"eslint"},
+		KeepPackages:   []string{"pnpm:typescript"},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Redundancies, 1)
+	assert.Equal(t, []string{"pnpm:typescript"}, result.Redundancies[0].Keep)
+	assert.Equal(t, []string{"npm:typescript"}, result.Redundancies[0].Remove)
+}
+
+func TestNodeRedundancyChecker_Check_NpmFailure(t *testing.T) {
+	t.Parallel()
+
+	checker := &NodeRedundancyChecker{
+		execCommand:    fakeOutputs(nil),
+		lookPath:       fakeLookPath("npm"),
+		toolCategories: NodeToolCategories(),
+	}
+
+	_, err := checker.Check(context.Background(), RedundancyOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to list npm global packages")
+
+	unavailable := &NodeRedundancyChecker{lookPath: fakeLookPath()}
+	assert.False(t, unavailable.Available())
+	_, err = unavailable.Check(context.Background(), RedundancyOptions{})
+	assert.True(t, errors.Is(err, ErrScannerNotAvailable))
+}
+
+func TestPythonRedundancyChecker_Check(t *testing.T) {
+	t.Parallel()
+
+	inspect := `{"installed":[
+		{"metadata":{"name":"Black","requires_dist":["click>=8.0.0","platformdirs>=2"]},"requested":true},
+		{"metadata":{"name":"click","requires_dist":["colorama; platform_system == \"Windows\""]},"requested":false},
+		{"metadata":{"name":"platformdirs"},"requested":false},
+		{"metadata":{"name":"typing_extensions"},"requested":false},
+		{"metadata":{"name":"ruff"},"requested":true}
+	]}`
+	checker := &PythonRedundancyChecker{
+		execCommand: fakeOutputs(map[string]string{
+			"python3 -m pip inspect --user": inspect,
+			"pipx list --json":              `{"venvs":{"black":{},"poetry":{}}}`,
+		}),
+		lookPath:       fakeLookPath("python3", "pipx"),
+		toolCategories: PythonToolCategories(),
+	}
+
+	result, err := checker.Check(context.Background(), RedundancyOptions{IncludeOverlaps: true, IncludeOrphans: true})
+	require.NoError(t, err)
+	assert.Equal(t, "pip", result.Checker)
+
+	duplicates := result.Redundancies.ByType(RedundancyDuplicate)
+	require.Len(t, duplicates, 1)
+	assert.Equal(t, []string{"pipx:black"}, duplicates[0].Keep)
+	assert.Equal(t, []string{"pip:black"}, duplicates[0].Remove)
+	assert.Equal(t, "python3 -m pip uninstall -y black", duplicates[0].Action)
+
+	overlaps := result.Redundancies.ByType(RedundancyOverlap)
+	require.Len(t, overlaps, 1)
+	assert.Equal(t, "python_formatters", overlaps[0].Category)
+	assert.Equal(t, []string{"pip:ruff", "pipx:black"}, overlaps[0].Packages)
+	assert.Equal(t, "pipx uninstall black", overlaps[0].Action)
+
+	orphans := result.Redundancies.ByType(RedundancyOrphan)
+	require.Len(t, orphans, 1)
+	assert.Equal(t, []string{"pip:typing-extensions"}, orphans[0].Remove)
+}
+
+func TestPythonRedundancyChecker_Check_PipxOnly(t *testing.T) {
+	t.Parallel()
+
+	checker := &PythonRedundancyChecker{
+		execCommand: fakeOutputs(map[string]string{
+			"pipx list --json": `{"venvs":{"poetry":{},"pdm":{}}}`,
+		}),
+		lookPath:       fakeLookPath("pipx"),
+		toolCategories: PythonToolCategories(),
+	}
+
+	result, err := checker.Check(context.Background(), RedundancyOptions{IncludeOverlaps: true, IncludeOrphans: true})
+	require.NoError(t, err)
+	require.Len(t, result.Redundancies, 1)
+	assert.Equal(t, []string{"pipx:poetry", "pipx:pdm"}, result.Redundancies[0].Packages)
+}
+
+func TestCargoRedundancyChecker_Check(t *testing.T) {
+	t.Parallel()
+
+	binDir := t.TempDir()
+	otherDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "rg"), []byte("#!/bin/sh\n"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(otherDir, "rg"), []byte("#!/bin/sh\n"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(otherDir, "fd"), []byte("not executable"), 0o644))
+
+	list := "ripgrep v14.1.0:\n    rg\nfd-find v10.2.0:\n    fd\neza v0.20.0:\n    eza\nexa v0.10.1 (/src/exa):\n    exa\n"
+	checker := &CargoRedundancyChecker{
+		execCommand:    fakeOutputs(map[string]string{"cargo install --list": list}),
+		lookPath:       fakeLookPath("cargo"),
+		toolCategories: CargoToolCategories(),
+		binDir:         binDir,
+		path:           strings.Join([]string{binDir, otherDir}, string(os.PathListSeparator)),
+	}
+
+	result, err := checker.Check(context.Background(), RedundancyOptions{IncludeOverlaps: true})
+	require.NoError(t, err)
+
+	duplicates := result.Redundancies.ByType(RedundancyDuplicate)
+	require.Len(t, duplicates, 1)
+	assert.Equal(t, []string{"cargo:ripgrep", filepath.Join(otherDir, "rg")}, duplicates[0].Packages)
+	assert.Equal(t, []string{"cargo:ripgrep"}, duplicates[0].Remove)
+	assert.Equal(t, "cargo uninstall ripgrep", duplicates[0].Action)
+
+	overlaps := result.Redundancies.ByType(RedundancyOverlap)
+	require.Len(t, overlaps, 1)
+	assert.Equal(t, []string{"cargo:eza"}, overlaps[0].Keep)
+	assert.Equal(t, []string{"cargo:exa"}, overlaps[0].Remove)
+
+	kept, err := checker.Check(context.Background(), RedundancyOptions{KeepPackages: []string{"ripgrep"}})
+	require.NoError(t, err)
+	require.Len(t, kept.Redundancies, 1)
+	assert.Empty(t, kept.Redundancies[0].Remove)
+}
+
+func TestParseCargoInstallList(t *testing.T) {
+	t.Parallel()
+
+	crates := parseCargoInstallList("cargo-edit v0.13.0:\n    cargo-add\n    cargo-rm\n\nbat v0.24.0:\n\tbat\n")
+	assert.Equal(t, []cargoCrate{
+		{Name: "cargo-edit", Binaries: []string{"cargo-add", "cargo-rm"}},
+		{Name: "bat", Binaries: []string{"bat"}},
+	}, crates)
+}
+
+func TestMergeRedundancyResults(t *testing.T) {
+	t.Parallel()
+
+	merged := MergeRedundancyResults([]*RedundancyResult{
+		{Checker: "brew", Redundancies: Redundancies{{Type: RedundancyDuplicate}}},
+		{Checker: "npm", Redundancies: Redundancies{{Type: RedundancyOverlap}}},
+	})
+	assert.Equal(t, "brew, npm", merged.Checker)
+	assert.Len(t, merged.Redundancies, 2)
+}
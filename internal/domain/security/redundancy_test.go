@@ -435,6 +435,23 @@ func TestBrewRedundancyChecker_getInstalledPackages(t *testing.T) {
 	})
 }
 
+// testBrewInfo is brew info --json=v2 --installed output: git was
+// installed on request, libpng is left over from a removed formula and
+// docker depends on a formula.
+const testBrewInfo = `{
+  "formulae": [
+    {"name": "git", "dependencies": ["pcre2"], "installed": [{"installed_on_request": true, "runtime_dependencies": [{"full_name": "pcre2"}]}]},
+    {"name": "pcre2", "installed": [{"installed_on_request": false}]},
+    {"name": "libpng", "installed": [{"installed_on_request": false}]},
+    {"name": "zlib", "installed": [{"installed_on_request": false}]},
+    {"name": "python@3.12", "installed": [{"installed_on_request": false}]},
+    {"name": "docker-completion", "installed": [{"installed_on_request": false}]}
+  ],
+  "casks": [
+    {"token": "docker", "depends_on": {"formula": ["docker-completion"]}}
+  ]
+}`
+
 func TestBrewRedundancyChecker_detectOrphans(t *testing.T) {
 	t.Parallel()
 
@@ -442,43 +459,52 @@ func TestBrewRedundancyChecker_detectOrphans(t *testing.T) {
 		t.Parallel()
 		checker := &BrewRedundancyChecker{
 			execCommand: func(_ context.Context, _ string, _ ...string) *exec.Cmd {
-				return exec.Command("printf", "%s", "==> Would remove:\nlibpng zlib jpeg\n")
+				return exec.Command("printf", "%s", testBrewInfo)
 			},
 			toolCategories: DefaultToolCategories(),
 		}
 
-		result := checker.detectOrphans(context.Background(), make(map[string]bool))
-		assert.Equal(t, RedundancyOrphan, result.Type)
-		assert.ElementsMatch(t, []string{"libpng", "zlib", "jpeg"}, result.Packages)
-		assert.ElementsMatch(t, []string{"libpng", "zlib", "jpeg"}, result.Remove)
-		assert.Contains(t, result.Recommendation, "3 orphaned dependencies")
+		result := checker.detectOrphans(context.Background(), make(map[string]bool), RedundancyOptions{})
+		require.Len(t, result, 1)
+		assert.Equal(t, RedundancyOrphan, result[0].Type)
+		assert.Equal(t, []string{"libpng", "zlib"}, result[0].Packages)
+		assert.Equal(t, []string{"libpng", "zlib"}, result[0].Remove)
+		assert.Contains(t, result[0].Recommendation, "2 orphaned dependencies")
+		assert.Equal(t, "preflight cleanup --autoremove", result[0].Action)
 	})
 
-	t.Run("no orphans", func(t *testing.T) {
+	t.Run("orphans with ignore and safe lists", func(t *testing.T) {
 		t.Parallel()
 		checker := &BrewRedundancyChecker{
 			execCommand: func(_ context.Context, _ string, _ ...string) *exec.Cmd {
-				return exec.Command("printf", "%s", "Nothing to remove.\n")
+				return exec.Command("printf", "%s", testBrewInfo)
 			},
 			toolCategories: DefaultToolCategories(),
 		}
 
-		result := checker.detectOrphans(context.Background(), make(map[string]bool))
-		assert.Empty(t, result.Packages)
+		ignore := map[string]bool{"zlib": true}
+		result := checker.detectOrphans(context.Background(), ignore, RedundancyOptions{SafePackages: []string{"lib*"}})
+		assert.Empty(t, result)
 	})
 
-	t.Run("orphans with ignore list", func(t *testing.T) {
+	t.Run("undeclared packages", func(t *testing.T) {
 		t.Parallel()
 		checker := &BrewRedundancyChecker{
 			execCommand: func(_ context.Context, _ string, _ ...string) *exec.Cmd {
-				return exec.Command("printf", "%s", "libpng zlib jpeg\n")
+				return exec.Command("printf", "%s", testBrewInfo)
 			},
 			toolCategories: DefaultToolCategories(),
 		}
 
-		ignore := map[string]bool{"zlib": true}
-		result := checker.detectOrphans(context.Background(), ignore)
-		assert.ElementsMatch(t, []string{"libpng", "jpeg"}, result.Packages)
+		result := checker.detectOrphans(context.Background(), make(map[string]bool), RedundancyOptions{
+			DeclaredPackages: []string{"homebrew/cask/docker"},
+		})
+		require.Len(t, result, 2)
+		assert.Equal(t, "undeclared_packages", result[1].Category)
+		assert.Equal(t, []string{"git"}, result[1].Packages)
+		assert.Empty(t, result[1].Remove)
+		assert.Contains(t, result[1].Recommendation, "frees pcre2")
+		assert.Equal(t, "preflight cleanup --remove git,pcre2", result[1].Action)
 	})
 
 	t.Run("command failure returns empty", func(t *testing.T) {
@@ -490,21 +516,8 @@ func TestBrewRedundancyChecker_detectOrphans(t *testing.T) {
 			toolCategories: DefaultToolCategories(),
 		}
 
-		result := checker.detectOrphans(context.Background(), make(map[string]bool))
-		assert.Empty(t, result.Packages)
-	})
-
-	t.Run("empty lines and headers filtered", func(t *testing.T) {
-		t.Parallel()
-		checker := &BrewRedundancyChecker{
-			execCommand: func(_ context.Context, _ string, _ ...string) *exec.Cmd {
-				return exec.Command("printf", "%s", "==> Header\n\nWould remove something\npkg1 pkg2\n\n")
-			},
-			toolCategories: DefaultToolCategories(),
-		}
-
-		result := checker.detectOrphans(context.Background(), make(map[string]bool))
-		assert.ElementsMatch(t, []string{"pkg1", "pkg2"}, result.Packages)
+		result := checker.detectOrphans(context.Background(), make(map[string]bool), RedundancyOptions{})
+		assert.Empty(t, result)
 	})
 }
 
@@ -573,8 +586,8 @@ func TestBrewRedundancyChecker_Check_WithMockExec(t *testing.T) {
 				if name == "brew" && len(args) > 0 && args[0] == "list" {
 					return exec.Command("printf", "%s", "curl\ngit\n")
 				}
-				if name == "brew" && len(args) > 0 && args[0] == "autoremove" {
-					return exec.Command("printf", "%s", "libpng zlib\n")
+				if name == "brew" && len(args) > 0 && args[0] == "info" {
+					return exec.Command("printf", "%s", testBrewInfo)
 				}
 				return exec.Command("echo", "")
 			},
@@ -729,40 +742,46 @@ func TestBrewRedundancyChecker_Autoremove(t *testing.T) {
 
 	t.Run("autoremove success with packages", func(t *testing.T) {
 		t.Parallel()
+		var uninstalled []string
 		checker := &BrewRedundancyChecker{
-			execCommand: func(_ context.Context, _ string, _ ...string) *exec.Cmd {
-				return exec.Command("printf", "%s", "==> Autoremoving\nlibpng zlib\n")
+			execCommand: func(_ context.Context, _ string, args ...string) *exec.Cmd {
+				if args[0] == "uninstall" {
+					uninstalled = args[1:]
+					return exec.Command("true")
+				}
+				return exec.Command("printf", "%s", testBrewInfo)
 			},
 			toolCategories: DefaultToolCategories(),
 		}
 
 		removed, err := checker.Autoremove(context.Background(), false)
 		require.NoError(t, err)
-		assert.ElementsMatch(t, []string{"libpng", "zlib"}, removed)
+		assert.Equal(t, []string{"libpng", "zlib"}, removed)
+		assert.Equal(t, []string{"libpng", "zlib"}, uninstalled)
 	})
 
 	t.Run("autoremove dry run", func(t *testing.T) {
 		t.Parallel()
-		var capturedArgs []string
+		var calls [][]string
 		checker := &BrewRedundancyChecker{
 			execCommand: func(_ context.Context, _ string, args ...string) *exec.Cmd {
-				capturedArgs = args
-				return exec.Command("printf", "%s", "pkg1 pkg2\n")
+				calls = append(calls, args)
+				return exec.Command("printf", "%s", testBrewInfo)
 			},
 			toolCategories: DefaultToolCategories(),
 		}
 
 		removed, err := checker.Autoremove(context.Background(), true)
 		require.NoError(t, err)
-		assert.Contains(t, capturedArgs, "--dry-run")
-		assert.ElementsMatch(t, []string{"pkg1", "pkg2"}, removed)
+		assert.Equal(t, []string{"libpng", "zlib"}, removed)
+		assert.Equal(t, [][]string{{"info", "--json=v2", "--installed"}}, calls)
 	})
 
 	t.Run("autoremove nothing to remove", func(t *testing.T) {
 		t.Parallel()
 		checker := &BrewRedundancyChecker{
 			execCommand: func(_ context.Context, _ string, _ ...string) *exec.Cmd {
-				return exec.Command("printf", "%s", "Nothing to do.\n")
+				return exec.Command("printf", "%s", `{"formulae":[],"casks":[]}`)
 			},
 			toolCategories: DefaultToolCategories(),
 		}
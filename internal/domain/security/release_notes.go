@@ -0,0 +1,299 @@
+package security
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/semver"
+)
+
+// DefaultGitHubAPIURL is the GitHub API release notes are fetched from.
+const DefaultGitHubAPIURL = "https://api.github.com"
+
+// Limits on the release notes shown per package.
+const (
+	DefaultReleaseNotesReleases = 5
+	DefaultReleaseNotesLines    = 8
+)
+
+// ReleaseNote is the changelog of one release of a package.
+type ReleaseNote struct {
+	Version     string    `json:"version"`
+	Title       string    `json:"title,omitempty"`
+	URL         string    `json:"url,omitempty"`
+	PublishedAt time.Time `json:"published_at,omitempty"`
+	Excerpt     string    `json:"excerpt,omitempty"`
+}
+
+// PackageReleaseNotes are the releases of a package between its installed
+// and latest versions, newest first. Omitted counts releases left out
+// beyond the limit. When no notes can be fetched, ChangelogURL points to
+// where they can be read and Error says why.
+type PackageReleaseNotes struct {
+	Name         string        `json:"name"`
+	Provider     string        `json:"provider"`
+	FromVersion  string        `json:"from_version"`
+	ToVersion    string        `json:"to_version"`
+	UpdateType   UpdateType    `json:"update_type"`
+	Source       string        `json:"source,omitempty"`
+	ChangelogURL string        `json:"changelog_url,omitempty"`
+	Releases     []ReleaseNote `json:"releases,omitempty"`
+	Omitted      int           `json:"omitted,omitempty"`
+	Error        string        `json:"error,omitempty"`
+}
+
+// ReleaseNotesFetcher fetches the release notes of outdated packages.
+type ReleaseNotesFetcher interface {
+	Fetch(ctx context.Context, pkg OutdatedPackage) PackageReleaseNotes
+}
+
+// BrewReleaseNotesFetcher fetches release notes of Homebrew formulae and
+// casks from GitHub releases. The repository is found in the livecheck
+// metadata of the package, or else in its download, head or homepage URL.
+type BrewReleaseNotesFetcher struct {
+	execCommand func(ctx context.Context, name string, args ...string) *exec.Cmd
+	client      *http.Client
+	apiURL      string
+	token       string
+	maxReleases int
+	maxLines    int
+}
+
+// NewBrewReleaseNotesFetcher creates a fetcher that authenticates with
+// GITHUB_TOKEN or GH_TOKEN when one is set.
+func NewBrewReleaseNotesFetcher() *BrewReleaseNotesFetcher {
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		token = os.Getenv("GH_TOKEN")
+	}
+	return &BrewReleaseNotesFetcher{
+		execCommand: exec.CommandContext,
+		client:      &http.Client{Timeout: 30 * time.Second},
+		apiURL:      DefaultGitHubAPIURL,
+		token:       token,
+		maxReleases: DefaultReleaseNotesReleases,
+		maxLines:    DefaultReleaseNotesLines,
+	}
+}
+
+// Fetch returns the release notes of pkg. Failures are recorded in the
+// result rather than returned, so that one package without notes does not
+// hide the notes of the others.
+func (f *BrewReleaseNotesFetcher) Fetch(ctx context.Context, pkg OutdatedPackage) PackageReleaseNotes {
+	notes := PackageReleaseNotes{
+		Name:        pkg.Name,
+		Provider:    pkg.Provider,
+		FromVersion: pkg.CurrentVersion,
+		ToVersion:   pkg.LatestVersion,
+		UpdateType:  pkg.UpdateType,
+	}
+
+	urls, homepage := f.packageURLs(ctx, pkg)
+	notes.ChangelogURL = homepage
+	repo := ""
+	for _, u := range urls {
+		if repo = githubRepo(u); repo != "" {
+			break
+		}
+	}
+	if repo == "" {
+		notes.Error = "no GitHub repository found in the Homebrew metadata"
+		return notes
+	}
+	notes.Source = "github.com/" + repo
+	notes.ChangelogURL = "https://github.com/" + repo + "/releases"
+
+	releases, err := f.releases(ctx, repo)
+	if err != nil {
+		notes.Error = err.Error()
+		return notes
+	}
+	notes.Releases, notes.Omitted = selectReleases(releases, pkg.CurrentVersion, pkg.LatestVersion, f.maxReleases, f.maxLines)
+	if len(notes.Releases) == 0 {
+		notes.Error = "no GitHub releases between the installed and latest versions"
+	}
+	return notes
+}
+
+// packageURLs returns the URLs that may name the repository of pkg, most
+// reliable first, and its homepage.
+func (f *BrewReleaseNotesFetcher) packageURLs(ctx context.Context, pkg OutdatedPackage) ([]string, string) {
+	var urls []string
+
+	// livecheck knows where new versions come from
+	if out, err := f.execCommand(ctx, "brew", "livecheck", "--json", "--verbose", pkg.Name).Output(); err == nil {
+		var checks []struct {
+			Meta struct {
+				URL struct {
+					Original  string `json:"original"`
+					Processed string `json:"processed"`
+				} `json:"url"`
+			} `json:"meta"`
+		}
+		if json.Unmarshal(out, &checks) == nil {
+			for _, c := range checks {
+				urls = append(urls, c.Meta.URL.Processed, c.Meta.URL.Original)
+			}
+		}
+	}
+
+	args := []string{"info", "--json=v2", pkg.Name}
+	if pkg.Provider == "cask" {
+		args = []string{"info", "--json=v2", "--cask", pkg.Name}
+	}
+	var homepage string
+	if out, err := f.execCommand(ctx, "brew", args...).Output(); err == nil {
+		var info struct {
+			Formulae []struct {
+				Homepage string `json:"homepage"`
+				URLs     map[string]struct {
+					URL string `json:"url"`
+				} `json:"urls"`
+			} `json:"formulae"`
+			Casks []struct {
+				Homepage string `json:"homepage"`
+				URL      string `json:"url"`
+			} `json:"casks"`
+		}
+		if json.Unmarshal(out, &info) == nil {
+			for _, formula := range info.Formulae {
+				urls = append(urls, formula.URLs["stable"].URL, formula.URLs["head"].URL, formula.Homepage)
+				homepage = formula.Homepage
+			}
+			for _, cask := range info.Casks {
+				urls = append(urls, cask.URL, cask.Homepage)
+				homepage = cask.Homepage
+			}
+		}
+	}
+	return urls, homepage
+}
+
+var githubRepoPattern = regexp.MustCompile(`^https?://(?:www\.|codeload\.)?github\.com/([\w.-]+)/([\w.-]+)`)
+
+// githubRepo returns the "owner/repo" a GitHub URL points into, or "".
+func githubRepo(rawURL string) string {
+	m := githubRepoPattern.FindStringSubmatch(rawURL)
+	if m == nil {
+		return ""
+	}
+	return m[1] + "/" + strings.TrimSuffix(m[2], ".git")
+}
+
+type githubRelease struct {
+	TagName     string    `json:"tag_name"`
+	Name        string    `json:"name"`
+	Body        string    `json:"body"`
+	HTMLURL     string    `json:"html_url"`
+	Draft       bool      `json:"draft"`
+	Prerelease  bool      `json:"prerelease"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+func (f *BrewReleaseNotesFetcher) releases(ctx context.Context, repo string) ([]githubRelease, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		fmt.Sprintf("%s/repos/%s/releases?per_page=50", strings.TrimSuffix(f.apiURL, "/"), repo), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("User-Agent", "preflight-cli")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if f.token != "" {
+		req.Header.Set("Authorization", "Bearer "+f.token)
+	}
+	var releases []githubRelease
+	if err := fetchJSON(f.client, req, &releases); err != nil {
+		return nil, err
+	}
+	return releases, nil
+}
+
+// selectReleases returns the published releases newer than from and no
+// newer than to, newest first and at most maxReleases, with bodies cut to
+// maxLines lines. It also returns how many matching releases were left out.
+func selectReleases(releases []githubRelease, from, to string, maxReleases, maxLines int) ([]ReleaseNote, int) {
+	lower, upper := releaseSemver(from), releaseSemver(to)
+	if lower == "" || upper == "" {
+		return nil, 0
+	}
+
+	type candidate struct {
+		version string
+		release githubRelease
+	}
+	var matches []candidate
+	for _, r := range releases {
+		if r.Draft || r.Prerelease {
+			continue
+		}
+		v := releaseSemver(r.TagName)
+		if v == "" || semver.Compare(v, lower) <= 0 || semver.Compare(v, upper) > 0 {
+			continue
+		}
+		matches = append(matches, candidate{v, r})
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return semver.Compare(matches[i].version, matches[j].version) > 0 })
+
+	omitted := 0
+	if len(matches) > maxReleases {
+		omitted = len(matches) - maxReleases
+		matches = matches[:maxReleases]
+	}
+	notes := make([]ReleaseNote, 0, len(matches))
+	for _, m := range matches {
+		notes = append(notes, ReleaseNote{
+			Version:     strings.TrimPrefix(m.version, "v"),
+			Title:       m.release.Name,
+			URL:         m.release.HTMLURL,
+			PublishedAt: m.release.PublishedAt,
+			Excerpt:     excerpt(m.release.Body, maxLines),
+		})
+	}
+	return notes, omitted
+}
+
+// releaseSemver turns a version or release tag such as "1.7.1_1", "v20.0.0",
+// "jq-1.7.1" or "curl-8_10_1" into a semver string, or "" when it has none.
+func releaseSemver(s string) string {
+	start := strings.IndexAny(s, "0123456789")
+	if start < 0 {
+		return ""
+	}
+	v := s[start:]
+	if !strings.Contains(v, ".") {
+		v = strings.ReplaceAll(v, "_", ".")
+	} else if i := strings.Index(v, "_"); i >= 0 {
+		// Homebrew revision, as in 1.7.1_1
+		v = v[:i]
+	}
+	v = semver.Canonical("v" + v)
+	if v == "" || semver.Prerelease(v) != "" {
+		return ""
+	}
+	return v
+}
+
+// excerpt returns the first maxLines non-blank lines of body.
+func excerpt(body string, maxLines int) string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if len(lines) == maxLines {
+			lines = append(lines, "…")
+			break
+		}
+		lines = append(lines, strings.TrimRight(line, " \t"))
+	}
+	return strings.Join(lines, "\n")
+}
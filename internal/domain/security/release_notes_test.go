@@ -0,0 +1,109 @@
+package security
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBrewReleaseNotesFetcher_Fetch(t *testing.T) {
+	t.Parallel()
+
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		if r.URL.Path != "/repos/nodejs/node/releases" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`[
+			{"tag_name": "v21.0.0", "name": "Node 21", "body": "too new", "html_url": "https://github.com/nodejs/node/releases/tag/v21.0.0"},
+			{"tag_name": "v20.0.0-rc.1", "body": "release candidate", "prerelease": true},
+			{"tag_name": "v20.0.0", "name": "Node 20", "body": "## Notable changes\r\n\r\n* Permission model\r\n* Stable test runner\r\n* V8 11.3", "html_url": "https://github.com/nodejs/node/releases/tag/v20.0.0"},
+			{"tag_name": "v19.0.0", "name": "Node 19", "body": "Watch mode", "html_url": "https://github.com/nodejs/node/releases/tag/v19.0.0"},
+			{"tag_name": "v18.0.0", "name": "Node 18", "body": "installed"}
+		]`))
+	}))
+	defer server.Close()
+
+	fetcher := &BrewReleaseNotesFetcher{
+		execCommand: fakeOutputs(map[string]string{
+			"brew info --json=v2 node": `{"formulae": [{"homepage": "https://nodejs.org/", "urls": {"stable": {"url": "https://github.com/nodejs/node/archive/refs/tags/v20.0.0.tar.gz"}}}]}`,
+		}),
+		client:      server.Client(),
+		apiURL:      server.URL,
+		token:       "secret",
+		maxReleases: 1,
+		maxLines:    3,
+	}
+
+	notes := fetcher.Fetch(context.Background(), OutdatedPackage{
+		Name: "node", CurrentVersion: "18.0.0", LatestVersion: "20.0.0_1", UpdateType: UpdateMajor, Provider: "brew",
+	})
+	assert.Empty(t, notes.Error)
+	assert.Equal(t, "github.com/nodejs/node", notes.Source)
+	assert.Equal(t, "https://github.com/nodejs/node/releases", notes.ChangelogURL)
+	require.Len(t, notes.Releases, 1)
+	assert.Equal(t, "20.0.0", notes.Releases[0].Version)
+	assert.Equal(t, "## Notable changes\n* Permission model\n* Stable test runner\n…", notes.Releases[0].Excerpt)
+	assert.Equal(t, 1, notes.Omitted)
+	assert.Equal(t, "Bearer secret", auth)
+}
+
+func TestBrewReleaseNotesFetcher_NoRepository(t *testing.T) {
+	t.Parallel()
+
+	fetcher := &BrewReleaseNotesFetcher{
+		execCommand: fakeOutputs(map[string]string{
+			"brew livecheck --json --verbose firefox": `[{"cask": "firefox", "meta": {"url": {"original": "https://www.mozilla.org/firefox/releases/"}}}]`,
+			"brew info --json=v2 --cask firefox":      `{"casks": [{"homepage": "https://www.mozilla.org/firefox/", "url": "https://download.mozilla.org/firefox.dmg"}]}`,
+		}),
+	}
+
+	notes := fetcher.Fetch(context.Background(), OutdatedPackage{Name: "firefox", CurrentVersion: "130.0", LatestVersion: "131.0", Provider: "cask"})
+	assert.Contains(t, notes.Error, "no GitHub repository")
+	assert.Equal(t, "https://www.mozilla.org/firefox/", notes.ChangelogURL)
+}
+
+func TestGithubRepo(t *testing.T) {
+	t.Parallel()
+
+	for url, want := range map[string]string{
+		"https://github.com/jqlang/jq/releases/download/jq-1.7.1/jq-1.7.1.tar.gz": "jqlang/jq",
+		"https://codeload.github.com/junegunn/fzf/tar.gz/v0.54.0":                 "junegunn/fzf",
+		"https://github.com/neovim/neovim.git":                                    "neovim/neovim",
+		"https://jqlang.github.io/jq/":                                            "",
+		"":                                                                        "",
+	} {
+		assert.Equal(t, want, githubRepo(url), url)
+	}
+}
+
+func TestReleaseSemver(t *testing.T) {
+	t.Parallel()
+
+	for tag, want := range map[string]string{
+		"v20.0.0":     "v20.0.0",
+		"jq-1.7.1":    "v1.7.1",
+		"1.7.1_1":     "v1.7.1",
+		"curl-8_10_1": "v8.10.1",
+		"2024.1":      "v2024.1.0",
+		"v2.0.0-rc.1": "",
+		"nightly":     "",
+	} {
+		assert.Equal(t, want, releaseSemver(tag), tag)
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "a\nb", excerpt("a\n\n  \nb\n", 5))
+	assert.Equal(t, "a\n…", excerpt("a\nb\nc", 1))
+	assert.Empty(t, strings.TrimSpace(excerpt("", 3)))
+}
@@ -0,0 +1,30 @@
+// Package signing provides the ED25519 key handling shared by preflight's
+// signed artifacts: lockfiles, layer bundles and compliance attestations.
+package signing
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// ErrInvalidPublicKey is returned for a public key that cannot be parsed or
+// is not an ED25519 key.
+var ErrInvalidPublicKey = errors.New("invalid public key")
+
+// ParseED25519PublicKey parses an OpenSSH ED25519 public key, as found in a
+// .pub file or an authorized_keys line.
+func ParseED25519PublicKey(data []byte) (ed25519.PublicKey, error) {
+	parsed, _, _, _, err := ssh.ParseAuthorizedKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPublicKey, err)
+	}
+	if crypto, ok := parsed.(ssh.CryptoPublicKey); ok {
+		if key, ok := crypto.CryptoPublicKey().(ed25519.PublicKey); ok {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: only ed25519 keys are supported", ErrInvalidPublicKey)
+}
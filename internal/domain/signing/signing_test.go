@@ -0,0 +1,34 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestParseED25519PublicKey(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	sshPub, err := ssh.NewPublicKey(pub)
+	require.NoError(t, err)
+
+	key, err := ParseED25519PublicKey(ssh.MarshalAuthorizedKey(sshPub))
+	require.NoError(t, err)
+	assert.True(t, pub.Equal(key))
+
+	_, err = ParseED25519PublicKey([]byte("not a key"))
+	require.ErrorIs(t, err, ErrInvalidPublicKey)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rsaPub, err := ssh.NewPublicKey(&rsaKey.PublicKey)
+	require.NoError(t, err)
+	_, err = ParseED25519PublicKey(ssh.MarshalAuthorizedKey(rsaPub))
+	require.ErrorIs(t, err, ErrInvalidPublicKey)
+	assert.Contains(t, err.Error(), "only ed25519 keys are supported")
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 	"time"
 
@@ -44,6 +45,11 @@ func NewManager(store Store) *Manager {
 
 // BeforeApply creates snapshots for all existing files before applying changes.
 func (m *Manager) BeforeApply(ctx context.Context, paths []string) (*Set, error) {
+	return m.Create(ctx, ReasonApply, paths)
+}
+
+// Create creates a snapshot set of the existing files among paths.
+func (m *Manager) Create(ctx context.Context, reason Reason, paths []string) (*Set, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -78,7 +84,7 @@ func (m *Manager) BeforeApply(ctx context.Context, paths []string) (*Set, error)
 		ID:        uuid.New().String(),
 		Snapshots: snapshots,
 		CreatedAt: now,
-		Reason:    string(ReasonApply),
+		Reason:    string(reason),
 	}
 
 	// Persist snapshot set index
@@ -177,6 +183,171 @@ func (m *Manager) ListSets(ctx context.Context) ([]Set, error) {
 	return sets, nil
 }
 
+// SetUsage reports the disk space of a snapshot set. Size is the size of
+// the snapshotted files, Stored the compressed size of their content, and
+// Unique the part of Stored no other set shares, which deleting the set
+// would free.
+type SetUsage struct {
+	ID        string    `json:"id"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+	Files     int       `json:"files"`
+	Size      int64     `json:"size"`
+	Stored    int64     `json:"stored"`
+	Unique    int64     `json:"unique"`
+}
+
+// Usage reports the disk space of every snapshot set, newest first.
+func (m *Manager) Usage(ctx context.Context) ([]SetUsage, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	fileStore, ok := m.store.(*FileStore)
+	if !ok {
+		return nil, nil
+	}
+	index, err := m.loadIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	snaps, err := m.listAllSnapshots(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]Snapshot, len(snaps))
+	for _, snap := range snaps {
+		byID[snap.ID] = snap
+	}
+
+	// Count the sets referring to each piece of content.
+	refs := make(map[string]int)
+	for _, entry := range index.Sets {
+		hashes := make(map[string]bool)
+		for _, id := range entry.SnapshotIDs {
+			if snap, ok := byID[id]; ok && !hashes[snap.Hash] {
+				hashes[snap.Hash] = true
+				refs[snap.Hash]++
+			}
+		}
+	}
+
+	usage := make([]SetUsage, 0, len(index.Sets))
+	for _, entry := range index.Sets {
+		stored, err := fileStore.StoredSize(entry.SnapshotIDs)
+		if err != nil {
+			return nil, err
+		}
+		u := SetUsage{ID: entry.ID, Reason: entry.Reason, CreatedAt: entry.CreatedAt}
+		for _, id := range entry.SnapshotIDs {
+			if snap, ok := byID[id]; ok {
+				u.Files++
+				u.Size += snap.Size
+			}
+		}
+		for hash, size := range stored {
+			u.Stored += size
+			if refs[hash] == 1 {
+				u.Unique += size
+			}
+		}
+		usage = append(usage, u)
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		return usage[i].CreatedAt.After(usage[j].CreatedAt)
+	})
+	return usage, nil
+}
+
+// GC deletes the snapshot sets older than olderThan, when it is positive,
+// then the snapshots no set refers to and the content no snapshot refers
+// to. With dryRun it only reports the sets and snapshots it would delete.
+func (m *Manager) GC(ctx context.Context, olderThan time.Duration, dryRun bool) (GCResult, error) {
+	now := time.Now()
+	return m.gc(ctx, func(entry snapshotSetEntry) bool {
+		return olderThan > 0 && now.Sub(entry.CreatedAt) > olderThan
+	}, dryRun)
+}
+
+// Prune deletes the snapshot sets created for reason that are older than
+// olderThan, with the content only they refer to.
+func (m *Manager) Prune(ctx context.Context, reason Reason, olderThan time.Duration) (GCResult, error) {
+	now := time.Now()
+	return m.gc(ctx, func(entry snapshotSetEntry) bool {
+		return entry.Reason == string(reason) && now.Sub(entry.CreatedAt) > olderThan
+	}, false)
+}
+
+// gc deletes the snapshot sets drop selects and everything only they
+// refer to.
+func (m *Manager) gc(ctx context.Context, drop func(snapshotSetEntry) bool, dryRun bool) (GCResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var result GCResult
+	fileStore, ok := m.store.(*FileStore)
+	if !ok {
+		return result, nil
+	}
+	index, err := m.loadIndex(ctx)
+	if err != nil {
+		return result, err
+	}
+	filesBefore, bytesBefore, err := fileStore.DiskUsage()
+	if err != nil {
+		return result, err
+	}
+
+	kept := make(map[string]snapshotSetEntry, len(index.Sets))
+	for id, entry := range index.Sets {
+		if drop(entry) {
+			result.Sets++
+			continue
+		}
+		kept[id] = entry
+	}
+	referenced := make(map[string]bool)
+	for _, entry := range kept {
+		for _, id := range entry.SnapshotIDs {
+			referenced[id] = true
+		}
+	}
+	snaps, err := m.listAllSnapshots(ctx)
+	if err != nil {
+		return result, err
+	}
+	var orphans []string
+	for _, snap := range snaps {
+		if !referenced[snap.ID] {
+			orphans = append(orphans, snap.ID)
+		}
+	}
+	result.Snapshots = len(orphans)
+	if dryRun {
+		return result, nil
+	}
+
+	if result.Sets > 0 {
+		if err := m.persistIndex(ctx, &snapshotSetIndex{Sets: kept}); err != nil {
+			return result, err
+		}
+	}
+	for _, id := range orphans {
+		if err := m.store.Delete(ctx, id); err != nil && !errors.Is(err, ErrSnapshotNotFound) {
+			return result, err
+		}
+	}
+	if _, err := fileStore.GC(ctx); err != nil {
+		return result, err
+	}
+	filesAfter, bytesAfter, err := fileStore.DiskUsage()
+	if err != nil {
+		return result, err
+	}
+	result.Files = filesBefore - filesAfter
+	result.Freed = bytesBefore - bytesAfter
+	return result, nil
+}
+
 // saveSet persists a snapshot set to the index.
 func (m *Manager) saveSet(ctx context.Context, set *Set, snapshotIDs []string) error {
 	index, err := m.loadIndex(ctx)
@@ -270,3 +270,79 @@ func TestManager_ListSets(t *testing.T) {
 		assert.Empty(t, sets)
 	})
 }
+
+func TestManager_Usage(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	manager := NewManager(NewFileStore(filepath.Join(tmpDir, "snapshots")))
+	ctx := context.Background()
+
+	shared := filepath.Join(tmpDir, "shared.txt")
+	own := filepath.Join(tmpDir, "own.txt")
+	require.NoError(t, os.WriteFile(shared, []byte("shared content"), 0o644))
+	require.NoError(t, os.WriteFile(own, []byte("own content"), 0o644))
+
+	first, err := manager.BeforeApply(ctx, []string{shared})
+	require.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+	second, err := manager.BeforeApply(ctx, []string{shared, own})
+	require.NoError(t, err)
+
+	usage, err := manager.Usage(ctx)
+	require.NoError(t, err)
+	require.Len(t, usage, 2)
+
+	assert.Equal(t, second.ID, usage[0].ID, "newest first")
+	assert.Equal(t, 2, usage[0].Files)
+	assert.Equal(t, int64(len("shared content")+len("own content")), usage[0].Size)
+	assert.Positive(t, usage[0].Unique)
+	assert.Less(t, usage[0].Unique, usage[0].Stored, "shared content is not unique")
+
+	assert.Equal(t, first.ID, usage[1].ID)
+	assert.Zero(t, usage[1].Unique)
+}
+
+func TestManager_GC(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	store := NewFileStore(filepath.Join(tmpDir, "snapshots"))
+	manager := NewManager(store)
+	ctx := context.Background()
+
+	file := filepath.Join(tmpDir, "file.txt")
+	require.NoError(t, os.WriteFile(file, []byte("old"), 0o644))
+	old, err := manager.BeforeApply(ctx, []string{file})
+	require.NoError(t, err)
+	time.Sleep(300 * time.Millisecond)
+	require.NoError(t, os.WriteFile(file, []byte("new"), 0o644))
+	recent, err := manager.BeforeApply(ctx, []string{file})
+	require.NoError(t, err)
+
+	// A snapshot saved outside any set is an orphan.
+	_, err = store.Save(ctx, "/tmp/orphan", []byte("orphan"))
+	require.NoError(t, err)
+
+	preview, err := manager.GC(ctx, 200*time.Millisecond, true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, preview.Sets)
+	assert.Equal(t, 2, preview.Snapshots)
+	_, err = manager.GetSet(ctx, old.ID)
+	require.NoError(t, err, "dry run keeps the set")
+
+	result, err := manager.GC(ctx, 200*time.Millisecond, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Sets)
+	assert.Equal(t, 2, result.Snapshots)
+	assert.Equal(t, 2, result.Files)
+	assert.Positive(t, result.Freed)
+
+	_, err = manager.GetSet(ctx, old.ID)
+	require.ErrorIs(t, err, ErrSetNotFound)
+	require.NoError(t, os.WriteFile(file, []byte("changed"), 0o644))
+	require.NoError(t, manager.Restore(ctx, recent.ID))
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(data))
+}
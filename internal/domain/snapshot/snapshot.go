@@ -12,15 +12,16 @@ type Reason string
 
 // Snapshot reason constants.
 const (
-	ReasonApply    Reason = "apply"
-	ReasonFix      Reason = "fix"
-	ReasonRollback Reason = "rollback"
+	ReasonApply     Reason = "apply"
+	ReasonFix       Reason = "fix"
+	ReasonRollback  Reason = "rollback"
+	ReasonScheduled Reason = "scheduled"
 )
 
 // IsValid checks if the reason is a known valid reason.
 func (r Reason) IsValid() bool {
 	switch r {
-	case ReasonApply, ReasonFix, ReasonRollback:
+	case ReasonApply, ReasonFix, ReasonRollback, ReasonScheduled:
 		return true
 	default:
 		return false
@@ -153,6 +153,7 @@ func TestReason_IsValid(t *testing.T) {
 		{ReasonApply, true},
 		{ReasonFix, true},
 		{ReasonRollback, true},
+		{ReasonScheduled, true},
 		{Reason("invalid"), false},
 		{Reason(""), false},
 	}
@@ -6,12 +6,16 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/klauspost/compress/zstd"
 )
 
 // ErrSnapshotNotFound is returned when a snapshot cannot be found.
@@ -31,17 +35,44 @@ type snapshotIndex struct {
 	Snapshots map[string]indexEntry `json:"snapshots"`
 }
 
-// indexEntry stores metadata for a single snapshot.
+// indexEntry stores metadata for a single snapshot. Content is stored
+// once per hash as a compressed object; Filename names the raw copy of
+// snapshots saved before content-addressed storage.
 type indexEntry struct {
 	ID        string    `json:"id"`
 	Path      string    `json:"path"`
 	Hash      string    `json:"hash"`
 	Size      int64     `json:"size"`
 	CreatedAt time.Time `json:"created_at"`
-	Filename  string    `json:"filename"`
+	Filename  string    `json:"filename,omitempty"`
+	Object    string    `json:"object,omitempty"`
 }
 
-// FileStore implements Store using the local filesystem.
+// objectExt is the extension of zstd-compressed snapshot objects.
+const objectExt = ".zst"
+
+// The zstd encoder and decoder are shared: EncodeAll and DecodeAll are
+// safe for concurrent use.
+var (
+	objectEncoder, _ = zstd.NewWriter(nil)
+	objectDecoder, _ = zstd.NewReader(nil)
+)
+
+// objectsDir holds the compressed content of snapshots, named by hash.
+const objectsDir = "objects"
+
+// GCResult reports what a garbage collection removed: snapshot sets,
+// snapshots, stored files, and the bytes freed on disk.
+type GCResult struct {
+	Sets      int
+	Snapshots int
+	Files     int
+	Freed     int64
+}
+
+// FileStore implements Store using the local filesystem. Snapshot content
+// is stored zstd-compressed and addressed by its SHA256 hash, so identical
+// content saved in several snapshots is stored once.
 type FileStore struct {
 	basePath string
 	mu       sync.RWMutex
@@ -68,12 +99,10 @@ func (s *FileStore) Save(ctx context.Context, path string, content []byte) (*Sna
 	// Generate snapshot metadata
 	id := uuid.New().String()
 	hash := sha256Hash(content)
-	filename := id + ".snapshot"
 	now := time.Now()
 
-	// Write snapshot file (0600 for privacy - may contain sensitive content)
-	snapshotPath := filepath.Join(s.basePath, filename)
-	if err := os.WriteFile(snapshotPath, content, 0o600); err != nil {
+	created, err := s.writeObject(hash, content)
+	if err != nil {
 		return nil, err
 	}
 
@@ -89,12 +118,14 @@ func (s *FileStore) Save(ctx context.Context, path string, content []byte) (*Sna
 		Hash:      hash,
 		Size:      int64(len(content)),
 		CreatedAt: now,
-		Filename:  filename,
+		Object:    hash,
 	}
 
 	if err := s.saveIndex(index); err != nil {
-		// Clean up the snapshot file on failure
-		_ = os.Remove(snapshotPath)
+		// Clean up the object on failure unless other snapshots share it
+		if created {
+			_ = os.Remove(s.objectPath(hash))
+		}
 		return nil, err
 	}
 
@@ -125,8 +156,10 @@ func (s *FileStore) Get(ctx context.Context, id string) ([]byte, error) {
 		return nil, ErrSnapshotNotFound
 	}
 
-	snapshotPath := filepath.Join(s.basePath, entry.Filename)
-	return os.ReadFile(snapshotPath)
+	if entry.Object == "" {
+		return os.ReadFile(filepath.Join(s.basePath, entry.Filename))
+	}
+	return s.readObject(entry.Object)
 }
 
 // List returns all snapshots for a given path.
@@ -172,15 +205,12 @@ func (s *FileStore) Delete(ctx context.Context, id string) error {
 		return ErrSnapshotNotFound
 	}
 
-	// Remove snapshot file
-	snapshotPath := filepath.Join(s.basePath, entry.Filename)
-	if err := os.Remove(snapshotPath); err != nil && !os.IsNotExist(err) {
+	// Update index, then remove content no other snapshot shares
+	delete(index.Snapshots, id)
+	if err := s.saveIndex(index); err != nil {
 		return err
 	}
-
-	// Update index
-	delete(index.Snapshots, id)
-	return s.saveIndex(index)
+	return s.removeContent(index, entry)
 }
 
 // Cleanup removes snapshots older than maxAge.
@@ -196,31 +226,216 @@ func (s *FileStore) Cleanup(ctx context.Context, maxAge time.Duration) (int, err
 
 	now := time.Now()
 	count := 0
-	toDelete := make([]string, 0)
+	expired := make([]indexEntry, 0)
 
-	for id, entry := range index.Snapshots {
+	for _, entry := range index.Snapshots {
 		if now.Sub(entry.CreatedAt) > maxAge {
-			// Remove snapshot file
-			snapshotPath := filepath.Join(s.basePath, entry.Filename)
-			_ = os.Remove(snapshotPath)
-			toDelete = append(toDelete, id)
+			expired = append(expired, entry)
 			count++
 		}
 	}
 
-	for _, id := range toDelete {
-		delete(index.Snapshots, id)
+	for _, entry := range expired {
+		delete(index.Snapshots, entry.ID)
 	}
 
 	if count > 0 {
 		if err := s.saveIndex(index); err != nil {
 			return count, err
 		}
+		for _, entry := range expired {
+			_ = s.removeContent(index, entry)
+		}
 	}
 
 	return count, nil
 }
 
+// GC removes stored content no snapshot in the index refers to, such as
+// objects left behind by an interrupted save.
+func (s *FileStore) GC(ctx context.Context) (GCResult, error) {
+	_ = ctx // Reserved for future cancellation support
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result GCResult
+	index, err := s.loadIndex()
+	if err != nil {
+		return result, err
+	}
+	objects := make(map[string]bool)
+	files := make(map[string]bool)
+	for _, entry := range index.Snapshots {
+		objects[entry.Object] = true
+		files[entry.Filename] = true
+	}
+
+	remove := func(path string, info fs.FileInfo) error {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		result.Files++
+		result.Freed += info.Size()
+		return nil
+	}
+
+	entries, err := os.ReadDir(s.basePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return result, nil
+		}
+		return result, err
+	}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".snapshot") || files[e.Name()] {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		if err := remove(filepath.Join(s.basePath, e.Name()), info); err != nil {
+			return result, err
+		}
+	}
+
+	err = filepath.Walk(filepath.Join(s.basePath, objectsDir), func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		// Leftover temporary files do not end in .zst and are removed too.
+		if objects[strings.TrimSuffix(info.Name(), objectExt)] {
+			return nil
+		}
+		return remove(path, info)
+	})
+	return result, err
+}
+
+// DiskUsage returns the number of files and bytes the store occupies on
+// disk.
+func (s *FileStore) DiskUsage() (int, int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var files int
+	var total int64
+	err := filepath.Walk(s.basePath, func(_ string, info fs.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !info.IsDir() {
+			files++
+			total += info.Size()
+		}
+		return nil
+	})
+	return files, total, err
+}
+
+// StoredSize returns the bytes on disk of the content of each snapshot,
+// keyed by content hash so shared content is counted once.
+func (s *FileStore) StoredSize(ids []string) (map[string]int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	index, err := s.loadIndex()
+	if err != nil {
+		return nil, err
+	}
+	sizes := make(map[string]int64, len(ids))
+	for _, id := range ids {
+		entry, ok := index.Snapshots[id]
+		if !ok {
+			continue
+		}
+		path := filepath.Join(s.basePath, entry.Filename)
+		if entry.Object != "" {
+			path = s.objectPath(entry.Object)
+		}
+		if info, err := os.Stat(path); err == nil {
+			sizes[entry.Hash] = info.Size()
+		}
+	}
+	return sizes, nil
+}
+
+// objectPath returns the path of the object holding content with hash.
+func (s *FileStore) objectPath(hash string) string {
+	return filepath.Join(s.basePath, objectsDir, hash[:2], hash+objectExt)
+}
+
+// writeObject stores content under its hash unless it is already stored,
+// and reports whether it created the object.
+func (s *FileStore) writeObject(hash string, content []byte) (bool, error) {
+	path := s.objectPath(hash)
+	if _, err := os.Stat(path); err == nil {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return false, err
+	}
+
+	compressed := objectEncoder.EncodeAll(content, nil)
+
+	// Write then rename so a crash never leaves a truncated object
+	// (0600 for privacy - may contain sensitive content)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, compressed, 0o600); err != nil {
+		return false, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return false, err
+	}
+	return true, nil
+}
+
+// readObject returns the content stored under hash.
+func (s *FileStore) readObject(hash string) ([]byte, error) {
+	compressed, err := os.ReadFile(s.objectPath(hash))
+	if err != nil {
+		return nil, err
+	}
+	content, err := objectDecoder.DecodeAll(compressed, nil)
+	if err != nil {
+		return nil, fmt.Errorf("corrupt snapshot object %s: %w", hash, err)
+	}
+	if sha256Hash(content) != hash {
+		return nil, fmt.Errorf("corrupt snapshot object %s: content does not match its hash", hash)
+	}
+	return content, nil
+}
+
+// removeContent removes the stored content of a snapshot deleted from
+// index, unless another snapshot shares it.
+func (s *FileStore) removeContent(index *snapshotIndex, removed indexEntry) error {
+	var path string
+	if removed.Object == "" {
+		path = filepath.Join(s.basePath, removed.Filename)
+	} else {
+		for _, entry := range index.Snapshots {
+			if entry.Object == removed.Object {
+				return nil
+			}
+		}
+		path = s.objectPath(removed.Object)
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
 // loadIndex loads the snapshot index from disk.
 func (s *FileStore) loadIndex() (*snapshotIndex, error) {
 	indexPath := filepath.Join(s.basePath, "index.json")
@@ -1,6 +1,7 @@
 package snapshot
 
 import (
+	"bytes"
 	"context"
 	"os"
 	"path/filepath"
@@ -248,3 +249,96 @@ func TestFileStore_BasePath(t *testing.T) {
 		assert.True(t, info.IsDir())
 	})
 }
+
+func TestFileStore_ContentAddressed(t *testing.T) {
+	t.Parallel()
+
+	t.Run("stores identical content once, compressed", func(t *testing.T) {
+		t.Parallel()
+
+		tmpDir := t.TempDir()
+		store := NewFileStore(tmpDir)
+		ctx := context.Background()
+
+		content := bytes.Repeat([]byte("export PATH=$HOME/bin:$PATH\n"), 200)
+		first, err := store.Save(ctx, "/home/user/.zshrc", content)
+		require.NoError(t, err)
+		second, err := store.Save(ctx, "/home/user/.zshrc", content)
+		require.NoError(t, err)
+		assert.NotEqual(t, first.ID, second.ID)
+
+		objects, err := filepath.Glob(filepath.Join(tmpDir, objectsDir, "*", "*"+objectExt))
+		require.NoError(t, err)
+		require.Len(t, objects, 1)
+		info, err := os.Stat(objects[0])
+		require.NoError(t, err)
+		assert.Less(t, info.Size(), int64(len(content)))
+
+		// Deleting one snapshot keeps the content the other shares.
+		require.NoError(t, store.Delete(ctx, first.ID))
+		got, err := store.Get(ctx, second.ID)
+		require.NoError(t, err)
+		assert.Equal(t, content, got)
+
+		require.NoError(t, store.Delete(ctx, second.ID))
+		_, err = os.Stat(objects[0])
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("reads snapshots saved as raw copies", func(t *testing.T) {
+		t.Parallel()
+
+		tmpDir := t.TempDir()
+		store := NewFileStore(tmpDir)
+		ctx := context.Background()
+
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "legacy.snapshot"), []byte("raw"), 0o600))
+		index := &snapshotIndex{Snapshots: map[string]indexEntry{
+			"legacy": {ID: "legacy", Path: "/etc/hosts", Hash: sha256Hash([]byte("raw")), Size: 3, Filename: "legacy.snapshot"},
+		}}
+		require.NoError(t, store.saveIndex(index))
+
+		got, err := store.Get(ctx, "legacy")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("raw"), got)
+	})
+
+	t.Run("detects corrupt content", func(t *testing.T) {
+		t.Parallel()
+
+		tmpDir := t.TempDir()
+		store := NewFileStore(tmpDir)
+		ctx := context.Background()
+
+		snap, err := store.Save(ctx, "/path/file", []byte("content"))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(store.objectPath(snap.Hash), []byte("garbage"), 0o600))
+
+		_, err = store.Get(ctx, snap.ID)
+		assert.ErrorContains(t, err, "corrupt snapshot object")
+	})
+}
+
+func TestFileStore_GC(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	store := NewFileStore(tmpDir)
+	ctx := context.Background()
+
+	snap, err := store.Save(ctx, "/path/file", []byte("kept"))
+	require.NoError(t, err)
+	orphan := store.objectPath(sha256Hash([]byte("orphan")))
+	require.NoError(t, os.MkdirAll(filepath.Dir(orphan), 0o700))
+	require.NoError(t, os.WriteFile(orphan, []byte("12345"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "stale.snapshot"), []byte("123"), 0o600))
+
+	result, err := store.GC(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Files)
+	assert.Equal(t, int64(8), result.Freed)
+
+	got, err := store.Get(ctx, snap.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("kept"), got)
+}
@@ -0,0 +1,335 @@
+package sync
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BundleFilter decides which configuration stays on this machine.
+// config.SyncConfig implements it from the manifest's sync.exclude section.
+type BundleFilter interface {
+	// ExcludesLayer reports whether the named layer must not be synced.
+	ExcludesLayer(name string) bool
+	// ExcludesProvider reports whether a provider's sections must not be synced.
+	ExcludesProvider(name string) bool
+}
+
+// Exclusion is content withheld from a bundle.
+type Exclusion struct {
+	// Path is the bundle file, e.g. "layers/personal.yaml".
+	Path string
+	// Section is the withheld YAML section, e.g. "packages.npm". It is empty
+	// when the whole file is withheld.
+	Section string
+}
+
+// String formats the exclusion for display.
+func (e Exclusion) String() string {
+	if e.Section == "" {
+		return e.Path
+	}
+	return e.Path + ": " + e.Section
+}
+
+// Exclude removes content matching filter from the bundle before it is
+// pushed and reports what was withheld.
+func (b *Bundle) Exclude(filter BundleFilter) ([]Exclusion, error) {
+	var excluded []Exclusion
+	for _, name := range b.Names() {
+		if isLayerFile(name) && filter.ExcludesLayer(layerFileName(name)) {
+			delete(b.Files, name)
+			excluded = append(excluded, Exclusion{Path: name})
+			continue
+		}
+		data, sections, err := rewriteYAML(b.Files[name], func(root *yaml.Node) []string {
+			return stripExcluded(name, root, filter)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("filtering %s: %w", name, err)
+		}
+		b.Files[name] = data
+		for _, s := range sections {
+			excluded = append(excluded, Exclusion{Path: name, Section: s})
+		}
+	}
+	b.Manifest.Files = b.Names()
+	return excluded, nil
+}
+
+// RestoreExcluded prepares a pulled bundle for writing into dir: excluded
+// layers are dropped so the local files stay untouched, and excluded
+// sections are replaced with the local copies. Pulling therefore never
+// overwrites or deletes content that is not synced.
+func (b *Bundle) RestoreExcluded(dir string, filter BundleFilter) error {
+	for _, name := range b.Names() {
+		if isLayerFile(name) && filter.ExcludesLayer(layerFileName(name)) {
+			delete(b.Files, name)
+			continue
+		}
+
+		var local *yaml.Node
+		if data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name))); err == nil {
+			var doc yaml.Node
+			if err := yaml.Unmarshal(data, &doc); err == nil {
+				local = documentRoot(&doc)
+			}
+		}
+
+		data, _, err := rewriteYAML(b.Files[name], func(root *yaml.Node) []string {
+			changed := stripExcluded(name, root, filter)
+			if local != nil && local.Kind == yaml.MappingNode {
+				changed = append(changed, restoreExcluded(name, root, local, filter)...)
+			}
+			return changed
+		})
+		if err != nil {
+			return fmt.Errorf("restoring %s: %w", name, err)
+		}
+		b.Files[name] = data
+	}
+	b.Manifest.Files = b.Names()
+	return nil
+}
+
+func isLayerFile(name string) bool {
+	return strings.HasPrefix(name, "layers/")
+}
+
+func layerFileName(name string) string {
+	base := path.Base(name)
+	return strings.TrimSuffix(base, path.Ext(base))
+}
+
+// rewriteYAML applies edit to a YAML document and re-encodes it only when
+// edit reports changes, so untouched files keep their exact bytes.
+func rewriteYAML(data []byte, edit func(root *yaml.Node) []string) ([]byte, []string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, err
+	}
+	root := documentRoot(&doc)
+	if root == nil || root.Kind != yaml.MappingNode {
+		return data, nil, nil
+	}
+	changed := edit(root)
+	if len(changed) == 0 {
+		return data, nil, nil
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, nil, err
+	}
+	return buf.Bytes(), changed, nil
+}
+
+func documentRoot(doc *yaml.Node) *yaml.Node {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil
+	}
+	return doc.Content[0]
+}
+
+// stripExcluded removes excluded sections from the root mapping of a bundle
+// file and returns their names.
+func stripExcluded(name string, root *yaml.Node, filter BundleFilter) []string {
+	switch {
+	case name == "preflight.yaml":
+		var removed []string
+		targets := mappingValue(root, "targets")
+		if targets == nil || targets.Kind != yaml.MappingNode {
+			return nil
+		}
+		for i := 0; i+1 < len(targets.Content); i += 2 {
+			target, layers := targets.Content[i].Value, targetLayers(targets.Content[i+1])
+			if layers == nil {
+				continue
+			}
+			kept := layers.Content[:0]
+			for _, layer := range layers.Content {
+				if filter.ExcludesLayer(layer.Value) {
+					removed = append(removed, fmt.Sprintf("targets.%s[%s]", target, layer.Value))
+					continue
+				}
+				kept = append(kept, layer)
+			}
+			layers.Content = kept
+		}
+		return removed
+
+	case name == "preflight.lock":
+		packages := mappingValue(root, "packages")
+		if packages == nil {
+			return nil
+		}
+		providers := removeKeys(packages, func(key string) bool {
+			return filter.ExcludesProvider(lockProvider(key))
+		})
+		removed := make([]string, 0, len(providers))
+		seen := make(map[string]bool)
+		for _, key := range providers {
+			if p := lockProvider(key); !seen[p] {
+				seen[p] = true
+				removed = append(removed, "packages."+p+":*")
+			}
+		}
+		return removed
+
+	case isLayerFile(name):
+		removed := removeKeys(root, filter.ExcludesProvider)
+		if packages := mappingValue(root, "packages"); packages != nil {
+			for _, key := range removeKeys(packages, filter.ExcludesProvider) {
+				removed = append(removed, "packages."+key)
+			}
+		}
+		return removed
+	}
+	return nil
+}
+
+// targetLayers returns the list of layers of a targets entry: the entry
+// itself, or the layers added by a target extending others.
+func targetLayers(node *yaml.Node) *yaml.Node {
+	if node != nil && node.Kind == yaml.MappingNode {
+		node = mappingValue(node, "add")
+	}
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return nil
+	}
+	return node
+}
+
+// restoreExcluded copies the excluded sections of the local file into the
+// stripped remote root and returns their names.
+func restoreExcluded(name string, root, local *yaml.Node, filter BundleFilter) []string {
+	var restored []string
+	switch {
+	case name == "preflight.yaml":
+		remoteTargets := mappingValue(root, "targets")
+		localTargets := mappingValue(local, "targets")
+		if remoteTargets == nil || localTargets == nil {
+			return nil
+		}
+		for i := 0; i+1 < len(localTargets.Content); i += 2 {
+			target := localTargets.Content[i].Value
+			localLayers := targetLayers(localTargets.Content[i+1])
+			remoteLayers := targetLayers(mappingValue(remoteTargets, target))
+			if remoteLayers == nil || localLayers == nil {
+				continue
+			}
+			for j, layer := range localLayers.Content {
+				if !filter.ExcludesLayer(layer.Value) {
+					continue
+				}
+				at := 0
+				if j > 0 {
+					at = len(remoteLayers.Content)
+					if k := sequenceIndex(remoteLayers, localLayers.Content[j-1].Value); k >= 0 {
+						at = k + 1
+					}
+				}
+				remoteLayers.Content = append(remoteLayers.Content[:at],
+					append([]*yaml.Node{layer}, remoteLayers.Content[at:]...)...)
+				restored = append(restored, fmt.Sprintf("targets.%s[%s]", target, layer.Value))
+			}
+		}
+
+	case name == "preflight.lock":
+		localPackages := mappingValue(local, "packages")
+		if localPackages == nil {
+			return nil
+		}
+		packages := ensureMapping(root, "packages")
+		for i := 0; i+1 < len(localPackages.Content); i += 2 {
+			if filter.ExcludesProvider(lockProvider(localPackages.Content[i].Value)) {
+				packages.Content = append(packages.Content, localPackages.Content[i], localPackages.Content[i+1])
+				restored = append(restored, "packages."+localPackages.Content[i].Value)
+			}
+		}
+
+	case isLayerFile(name):
+		for i := 0; i+1 < len(local.Content); i += 2 {
+			if filter.ExcludesProvider(local.Content[i].Value) {
+				root.Content = append(root.Content, local.Content[i], local.Content[i+1])
+				restored = append(restored, local.Content[i].Value)
+			}
+		}
+		if localPackages := mappingValue(local, "packages"); localPackages != nil {
+			for i := 0; i+1 < len(localPackages.Content); i += 2 {
+				if filter.ExcludesProvider(localPackages.Content[i].Value) {
+					packages := ensureMapping(root, "packages")
+					packages.Content = append(packages.Content, localPackages.Content[i], localPackages.Content[i+1])
+					restored = append(restored, "packages."+localPackages.Content[i].Value)
+				}
+			}
+		}
+	}
+	return restored
+}
+
+// lockProvider returns the provider of a "provider:name" lockfile key.
+func lockProvider(key string) string {
+	provider, _, _ := strings.Cut(key, ":")
+	return provider
+}
+
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func ensureMapping(m *yaml.Node, key string) *yaml.Node {
+	if v := mappingValue(m, key); v != nil && v.Kind == yaml.MappingNode {
+		return v
+	}
+	removeKeys(m, func(k string) bool { return k == key })
+	v := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, v)
+	return v
+}
+
+// removeKeys deletes the entries of mapping m whose key matches and returns
+// the deleted keys.
+func removeKeys(m *yaml.Node, match func(key string) bool) []string {
+	if m.Kind != yaml.MappingNode {
+		return nil
+	}
+	var removed []string
+	kept := m.Content[:0]
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if match(m.Content[i].Value) {
+			removed = append(removed, m.Content[i].Value)
+			continue
+		}
+		kept = append(kept, m.Content[i], m.Content[i+1])
+	}
+	m.Content = kept
+	return removed
+}
+
+func sequenceIndex(seq *yaml.Node, value string) int {
+	for i, n := range seq.Content {
+		if n.Value == value {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,167 @@
+package sync
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+type testFilter struct {
+	layers    map[string]bool
+	providers map[string]bool
+}
+
+func (f testFilter) ExcludesLayer(name string) bool    { return f.layers[name] }
+func (f testFilter) ExcludesProvider(name string) bool { return f.providers[name] }
+func newTestFilter(layers, providers []string) testFilter {
+	f := testFilter{layers: map[string]bool{}, providers: map[string]bool{}}
+	for _, l := range layers {
+		f.layers[l] = true
+	}
+	for _, p := range providers {
+		f.providers[p] = true
+	}
+	return f
+}
+
+const filterManifest = `targets:
+  default:
+    - base
+    - personal
+    - work
+`
+
+const filterLayer = `name: base
+packages:
+  brew:
+    formulae: [ripgrep]
+  npm:
+    packages: [prettier]
+ssh:
+  hosts:
+    - host: home
+`
+
+const filterLock = `version: 2
+packages:
+  brew:ripgrep:
+    version: 14.1.0
+  npm:prettier:
+    version: 3.0.0
+`
+
+func decodeYAML(t *testing.T, data []byte) map[string]any {
+	t.Helper()
+	var m map[string]any
+	require.NoError(t, yaml.Unmarshal(data, &m))
+	return m
+}
+
+func TestBundle_Exclude(t *testing.T) {
+	t.Parallel()
+
+	b := &Bundle{Files: map[string][]byte{
+		"preflight.yaml":       []byte(filterManifest),
+		"preflight.lock":       []byte(filterLock),
+		"layers/base.yaml":     []byte(filterLayer),
+		"layers/personal.yaml": []byte("name: personal\n"),
+		"layers/work.yaml":     []byte("name: work\n"),
+	}}
+
+	excluded, err := b.Exclude(newTestFilter([]string{"personal"}, []string{"npm", "ssh"}))
+	require.NoError(t, err)
+
+	assert.Equal(t, []Exclusion{
+		{Path: "layers/base.yaml", Section: "ssh"},
+		{Path: "layers/base.yaml", Section: "packages.npm"},
+		{Path: "layers/personal.yaml"},
+		{Path: "preflight.lock", Section: "packages.npm:*"},
+		{Path: "preflight.yaml", Section: "targets.default[personal]"},
+	}, excluded)
+	assert.Equal(t, "layers/personal.yaml", excluded[2].String())
+	assert.Equal(t, []string{"layers/base.yaml", "layers/work.yaml", "preflight.lock", "preflight.yaml"}, b.Manifest.Files)
+
+	layer := decodeYAML(t, b.Files["layers/base.yaml"])
+	assert.NotContains(t, layer, "ssh")
+	assert.Equal(t, map[string]any{"brew": map[string]any{"formulae": []any{"ripgrep"}}}, layer["packages"])
+
+	lockPkgs := decodeYAML(t, b.Files["preflight.lock"])["packages"].(map[string]any)
+	assert.Contains(t, lockPkgs, "brew:ripgrep")
+	assert.NotContains(t, lockPkgs, "npm:prettier")
+
+	manifest := decodeYAML(t, b.Files["preflight.yaml"])
+	assert.Equal(t, []any{"base", "work"}, manifest["targets"].(map[string]any)["default"])
+
+	// Files without excluded content keep their exact bytes.
+	assert.Equal(t, "name: work\n", string(b.Files["layers/work.yaml"]))
+}
+
+func TestBundle_Exclude_ExtendedTarget(t *testing.T) {
+	t.Parallel()
+
+	b := &Bundle{Files: map[string][]byte{
+		"preflight.yaml": []byte("targets:\n  work: [base]\n  laptop:\n    extends: work\n    add: [personal, device]\n"),
+	}}
+
+	excluded, err := b.Exclude(newTestFilter([]string{"personal"}, nil))
+	require.NoError(t, err)
+
+	assert.Equal(t, []Exclusion{{Path: "preflight.yaml", Section: "targets.laptop[personal]"}}, excluded)
+	laptop := decodeYAML(t, b.Files["preflight.yaml"])["targets"].(map[string]any)["laptop"]
+	assert.Equal(t, map[string]any{"extends": "work", "add": []any{"device"}}, laptop)
+}
+
+func TestBundle_RestoreExcluded(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "layers"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "preflight.yaml"), []byte(filterManifest), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "preflight.lock"), []byte(filterLock), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "layers", "base.yaml"), []byte(filterLayer), 0o644))
+
+	// The remote was pushed by a machine that does not know the personal
+	// layer and locked a different npm version.
+	b := &Bundle{Files: map[string][]byte{
+		"preflight.yaml": []byte("targets:\n  default:\n    - base\n    - work\n    - extra\n"),
+		"preflight.lock": []byte("version: 2\npackages:\n  brew:ripgrep:\n    version: 14.2.0\n  npm:prettier:\n    version: 9.9.9\n"),
+		"layers/base.yaml": []byte(
+			"name: base\npackages:\n  brew:\n    formulae: [ripgrep, fd]\n  npm:\n    packages: [eslint]\n"),
+		"layers/personal.yaml": []byte("name: personal\n"),
+	}}
+
+	filter := newTestFilter([]string{"personal"}, []string{"npm", "ssh"})
+	require.NoError(t, b.RestoreExcluded(dir, filter))
+
+	assert.NotContains(t, b.Files, "layers/personal.yaml", "excluded layers are never written")
+
+	manifest := decodeYAML(t, b.Files["preflight.yaml"])
+	assert.Equal(t, []any{"base", "personal", "work", "extra"}, manifest["targets"].(map[string]any)["default"])
+
+	lockPkgs := decodeYAML(t, b.Files["preflight.lock"])["packages"].(map[string]any)
+	assert.Equal(t, "14.2.0", lockPkgs["brew:ripgrep"].(map[string]any)["version"])
+	assert.Equal(t, "3.0.0", lockPkgs["npm:prettier"].(map[string]any)["version"], "local npm lock kept")
+
+	layer := decodeYAML(t, b.Files["layers/base.yaml"])
+	assert.Equal(t, map[string]any{
+		"brew": map[string]any{"formulae": []any{"ripgrep", "fd"}},
+		"npm":  map[string]any{"packages": []any{"prettier"}},
+	}, layer["packages"])
+	assert.Contains(t, layer, "ssh", "local ssh section kept")
+}
+
+func TestBundle_RestoreExcluded_NoLocalFiles(t *testing.T) {
+	t.Parallel()
+
+	b := &Bundle{Files: map[string][]byte{
+		"layers/base.yaml": []byte(filterLayer),
+	}}
+	require.NoError(t, b.RestoreExcluded(t.TempDir(), newTestFilter(nil, []string{"ssh"})))
+
+	layer := decodeYAML(t, b.Files["layers/base.yaml"])
+	assert.NotContains(t, layer, "ssh")
+}
@@ -1,6 +1,9 @@
 package sync
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 // ResolutionStrategy defines how conflicts should be resolved.
 type ResolutionStrategy int
@@ -66,6 +69,8 @@ const (
 	ChooseBase
 	// ChooseSkip skips this conflict (leave unresolved).
 	ChooseSkip
+	// ChooseCustom selects a version entered by the user.
+	ChooseCustom
 )
 
 // String returns a human-readable name for the choice.
@@ -79,6 +84,8 @@ func (c ResolutionChoice) String() string {
 		return "base"
 	case ChooseSkip:
 		return "skip"
+	case ChooseCustom:
+		return "custom"
 	default:
 		return "unknown"
 	}
@@ -333,7 +340,47 @@ func ResolveManually(c LockConflict, choice ResolutionChoice) Resolution {
 		reason = "manually selected base"
 	case ChooseSkip:
 		reason = "manually skipped"
+	case ChooseCustom:
+		// Custom versions are created with ResolveWithVersion.
+		reason = "manually skipped"
+		choice = ChooseSkip
 	}
 
 	return NewResolution(c, choice, result, reason)
 }
+
+// ResolveWithVersion creates a manual resolution that pins the package to a
+// version entered by the user. Provenance is taken from the local side, or
+// the remote side if the package does not exist locally.
+func ResolveWithVersion(c LockConflict, version string, at time.Time) Resolution {
+	origin := c.Local()
+	if origin.IsZero() {
+		origin = c.Remote()
+	}
+	result := NewPackageLockInfoWithTime(version, origin.Provenance(), at)
+	return NewResolution(c, ChooseCustom, result, "manually edited to "+version)
+}
+
+// Change returns the lockfile change needed to apply this resolution on top
+// of the local lockfile.
+func (r Resolution) Change() MergeChange {
+	local := r.conflict.Local()
+	change := MergeChange{
+		PackageKey: r.conflict.PackageKey(),
+		Before:     local,
+		After:      r.result,
+		Reason:     r.reason,
+	}
+	switch {
+	case r.IsSkipped():
+		change.Type = ChangeKept
+		change.After = local
+	case r.IsDelete():
+		change.Type = ChangeRemoved
+	case local.IsZero():
+		change.Type = ChangeAdded
+	default:
+		change.Type = ChangeUpdated
+	}
+	return change
+}
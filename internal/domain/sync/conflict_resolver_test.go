@@ -71,6 +71,7 @@ func TestResolutionChoice_String(t *testing.T) {
 		{ChooseRemote, "remote"},
 		{ChooseBase, "base"},
 		{ChooseSkip, "skip"},
+		{ChooseCustom, "custom"},
 		{ResolutionChoice(99), "unknown"},
 	}
 
@@ -435,6 +436,59 @@ func TestResolveManually(t *testing.T) {
 	}
 }
 
+func TestResolveWithVersion(t *testing.T) {
+	t.Parallel()
+
+	machineA, _ := ParseMachineID("550e8400-e29b-41d4-a716-446655440000")
+	prov := NewPackageProvenance(machineA, NewVersionVector().Increment(machineA))
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	conflict := NewLockConflict("brew:ripgrep", BothModified,
+		NewPackageLockInfo("14.0.0", prov), NewPackageLockInfo("14.1.0", PackageProvenance{}), PackageLockInfo{})
+
+	resolution := ResolveWithVersion(conflict, "14.1.1", at)
+	assert.Equal(t, ChooseCustom, resolution.Choice())
+	assert.Equal(t, "14.1.1", resolution.Result().Version())
+	assert.Equal(t, at, resolution.Result().ModifiedAt())
+	assert.Equal(t, machineA.String(), resolution.Result().Provenance().ModifiedBy())
+	assert.False(t, resolution.IsDelete())
+}
+
+func TestResolution_Change(t *testing.T) {
+	t.Parallel()
+
+	machineA, _ := ParseMachineID("550e8400-e29b-41d4-a716-446655440000")
+	prov := NewPackageProvenance(machineA, NewVersionVector().Increment(machineA))
+	local := NewPackageLockInfo("14.0.0", prov)
+	remote := NewPackageLockInfo("14.1.0", prov)
+
+	both := NewLockConflict("brew:ripgrep", BothModified, local, remote, PackageLockInfo{})
+	remoteOnly := NewLockConflict("brew:fd", RemoteOnly, PackageLockInfo{}, remote, PackageLockInfo{})
+	localOnly := NewLockConflict("brew:bat", LocalOnly, local, PackageLockInfo{}, PackageLockInfo{})
+
+	tests := []struct {
+		name       string
+		resolution Resolution
+		wantType   MergeChangeType
+		wantAfter  string
+	}{
+		{"remote update", ResolveManually(both, ChooseRemote), ChangeUpdated, "14.1.0"},
+		{"skip keeps local", ResolveManually(both, ChooseSkip), ChangeKept, "14.0.0"},
+		{"remote addition", ResolveManually(remoteOnly, ChooseRemote), ChangeAdded, "14.1.0"},
+		{"removal", ResolveManually(localOnly, ChooseRemote), ChangeRemoved, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			change := tt.resolution.Change()
+			assert.Equal(t, tt.resolution.Conflict().PackageKey(), change.PackageKey)
+			assert.Equal(t, tt.wantType, change.Type)
+			assert.Equal(t, tt.wantAfter, change.After.Version())
+		})
+	}
+}
+
 func TestResolution_IsZero(t *testing.T) {
 	t.Parallel()
 
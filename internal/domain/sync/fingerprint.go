@@ -0,0 +1,74 @@
+package sync
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ErrFingerprintNotFound indicates no fingerprint has been recorded.
+var ErrFingerprintNotFound = errors.New("machine fingerprint not found")
+
+// Fingerprint describes the hardware of a machine. Unlike MachineID it is
+// derived from the machine itself, so targets can be bound to it in
+// preflight.yaml before the machine has ever run preflight.
+type Fingerprint struct {
+	Hostname string `json:"hostname,omitempty"`
+	// Serial is the hardware serial number, when the OS exposes it.
+	Serial string `json:"serial,omitempty"`
+	// Chip is the CPU model, such as "Apple M3 Pro".
+	Chip string `json:"chip,omitempty"`
+	// MemoryBytes is the installed RAM.
+	MemoryBytes uint64 `json:"memory_bytes,omitempty"`
+}
+
+// MemoryGB returns the installed RAM rounded to whole gigabytes.
+func (f Fingerprint) MemoryGB() int {
+	const gb = 1 << 30
+	return int((f.MemoryBytes + gb/2) / gb)
+}
+
+// IsZero reports whether nothing is known about the machine.
+func (f Fingerprint) IsZero() bool {
+	return f == Fingerprint{}
+}
+
+// DefaultFingerprintPath returns where the machine fingerprint is recorded,
+// next to the machine ID.
+func DefaultFingerprintPath() string {
+	return filepath.Join(filepath.Dir(DefaultMachineIDPath()), "fingerprint.json")
+}
+
+// LoadFingerprint reads a fingerprint recorded with SaveFingerprint.
+func LoadFingerprint(path string) (Fingerprint, error) {
+	// #nosec G304 -- path is the fingerprint file in ~/.preflight.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return Fingerprint{}, ErrFingerprintNotFound
+		}
+		return Fingerprint{}, fmt.Errorf("failed to read machine fingerprint: %w", err)
+	}
+	var fp Fingerprint
+	if err := json.Unmarshal(data, &fp); err != nil {
+		return Fingerprint{}, fmt.Errorf("failed to parse machine fingerprint: %w", err)
+	}
+	return fp, nil
+}
+
+// SaveFingerprint records fp at path, creating parent directories if needed.
+func SaveFingerprint(path string, fp Fingerprint) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	data, err := json.MarshalIndent(fp, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write machine fingerprint: %w", err)
+	}
+	return nil
+}
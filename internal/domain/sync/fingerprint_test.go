@@ -0,0 +1,38 @@
+package sync
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFingerprint_SaveAndLoad(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), ".preflight", "fingerprint.json")
+	_, err := LoadFingerprint(path)
+	require.ErrorIs(t, err, ErrFingerprintNotFound)
+
+	fp := Fingerprint{Hostname: "laptop", Serial: "C02XYZ", Chip: "Apple M3 Pro", MemoryBytes: 36 << 30}
+	require.NoError(t, SaveFingerprint(path, fp))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	loaded, err := LoadFingerprint(path)
+	require.NoError(t, err)
+	assert.Equal(t, fp, loaded)
+}
+
+func TestFingerprint_MemoryGB(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 16, Fingerprint{MemoryBytes: 16 << 30}.MemoryGB())
+	// Linux reports slightly less than the installed RAM
+	assert.Equal(t, 16, Fingerprint{MemoryBytes: 16_318_420 * 1024}.MemoryGB())
+	assert.Equal(t, 0, Fingerprint{}.MemoryGB())
+	assert.True(t, Fingerprint{}.IsZero())
+}
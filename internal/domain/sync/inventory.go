@@ -0,0 +1,210 @@
+package sync
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// InventoryDir is the directory, relative to the config directory, that holds
+// one applied-state file per machine. Keeping a file per machine means
+// machines never edit the same file, so the directory can be committed and
+// synced without merge conflicts.
+const InventoryDir = ".preflight/machines"
+
+// MachineState is the state a machine last applied.
+type MachineState struct {
+	MachineID string `json:"machine_id"`
+	Hostname  string `json:"hostname"`
+	OS        string `json:"os,omitempty"`
+	Target    string `json:"target,omitempty"`
+	// Fingerprint is the machine's hardware, which targets can bind to.
+	Fingerprint *Fingerprint `json:"fingerprint,omitempty"`
+	// AppliedAt is when the machine last applied successfully.
+	AppliedAt time.Time `json:"applied_at"`
+	// Vector is the lockfile's version vector at the time of the apply.
+	Vector VersionVector `json:"vector"`
+	// Packages maps package keys to the versions that were applied.
+	Packages map[string]string `json:"packages"`
+}
+
+// PackageDivergence is a package whose applied version differs from the lockfile.
+type PackageDivergence struct {
+	PackageKey string `json:"package"`
+	Applied    string `json:"applied,omitempty"`
+	Locked     string `json:"locked,omitempty"`
+}
+
+// MachineStatus compares a machine's applied state with the current lockfile.
+type MachineStatus struct {
+	MachineState
+	// Relation is the causal relation of the applied vector to the lockfile's.
+	Relation CausalRelation
+	// Divergent lists packages whose applied version differs from the lockfile.
+	Divergent []PackageDivergence
+}
+
+// IsBehind reports whether the machine has not applied the current lockfile.
+func (s MachineStatus) IsBehind() bool {
+	return s.Relation == Before || s.Relation == Concurrent || len(s.Divergent) > 0
+}
+
+// Inventory is the set of machines that have applied a configuration.
+type Inventory struct {
+	machines map[string]MachineState
+}
+
+// NewInventory creates an empty inventory.
+func NewInventory() *Inventory {
+	return &Inventory{machines: make(map[string]MachineState)}
+}
+
+// LoadInventory reads all machine state files from dir. A missing directory
+// yields an empty inventory.
+func LoadInventory(dir string) (*Inventory, error) {
+	inv := NewInventory()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return inv, nil
+		}
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var state MachineState
+		if err := json.Unmarshal(data, &state); err != nil {
+			return nil, fmt.Errorf("invalid machine state %s: %w", entry.Name(), err)
+		}
+		if state.MachineID == "" {
+			continue
+		}
+		inv.Record(state)
+	}
+	return inv, nil
+}
+
+// SaveMachineState writes a single machine's state into dir.
+func SaveMachineState(dir string, state MachineState) error {
+	if state.MachineID == "" {
+		return ErrInvalidMachineID
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, state.MachineID+".json")
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil { //nolint:gosec // state is shared via the config repo
+		return fmt.Errorf("failed to write machine state: %w", err)
+	}
+	return nil
+}
+
+// Record adds or replaces a machine's state.
+func (i *Inventory) Record(state MachineState) {
+	i.machines[state.MachineID] = state
+}
+
+// Get returns the state of a machine.
+func (i *Inventory) Get(machineID string) (MachineState, bool) {
+	state, ok := i.machines[machineID]
+	return state, ok
+}
+
+// Len returns the number of machines.
+func (i *Inventory) Len() int {
+	return len(i.machines)
+}
+
+// List returns all machine states ordered by hostname.
+func (i *Inventory) List() []MachineState {
+	states := make([]MachineState, 0, len(i.machines))
+	for _, s := range i.machines {
+		states = append(states, s)
+	}
+	sort.Slice(states, func(a, b int) bool {
+		if states[a].Hostname != states[b].Hostname {
+			return states[a].Hostname < states[b].Hostname
+		}
+		return states[a].MachineID < states[b].MachineID
+	})
+	return states
+}
+
+// ByTarget groups the machines by the target they last applied, each
+// group ordered by hostname.
+func (i *Inventory) ByTarget() map[string][]MachineState {
+	targets := make(map[string][]MachineState)
+	for _, s := range i.List() {
+		targets[s.Target] = append(targets[s.Target], s)
+	}
+	return targets
+}
+
+// Status compares every machine's applied state with the lockfile state.
+func (i *Inventory) Status(lockState *LockfileState) []MachineStatus {
+	states := i.List()
+	statuses := make([]MachineStatus, 0, len(states))
+	for _, s := range states {
+		statuses = append(statuses, CompareMachineState(s, lockState))
+	}
+	return statuses
+}
+
+// CompareMachineState compares a machine's applied state with the lockfile state.
+func CompareMachineState(state MachineState, lockState *LockfileState) MachineStatus {
+	status := MachineStatus{MachineState: state, Relation: Equal}
+	if lockState == nil {
+		return status
+	}
+	status.Relation = state.Vector.Compare(lockState.Metadata.Vector())
+
+	keys := make(map[string]struct{}, len(lockState.Packages)+len(state.Packages))
+	for k := range lockState.Packages {
+		keys[k] = struct{}{}
+	}
+	for k := range state.Packages {
+		keys[k] = struct{}{}
+	}
+	for k := range keys {
+		locked := lockState.Packages[k].Version()
+		applied := state.Packages[k]
+		if locked != applied && !(applied != "" && locked == "latest") {
+			status.Divergent = append(status.Divergent, PackageDivergence{PackageKey: k, Applied: applied, Locked: locked})
+		}
+	}
+	sort.Slice(status.Divergent, func(a, b int) bool {
+		return status.Divergent[a].PackageKey < status.Divergent[b].PackageKey
+	})
+	return status
+}
+
+// FindMachine looks up a machine by hostname, full ID or ID prefix.
+func (i *Inventory) FindMachine(query string) (MachineState, bool) {
+	if s, ok := i.machines[query]; ok {
+		return s, true
+	}
+	var match MachineState
+	found := 0
+	for _, s := range i.List() {
+		if strings.EqualFold(s.Hostname, query) || strings.HasPrefix(s.MachineID, query) {
+			match = s
+			found++
+		}
+	}
+	return match, found == 1
+}
@@ -0,0 +1,168 @@
+package sync
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testLockState(vector VersionVector, versions map[string]string) *LockfileState {
+	state := NewLockfileStateWithMetadata(NewSyncMetadata(vector))
+	prov := NewPackageProvenance(NewMachineID(), vector)
+	for k, v := range versions {
+		state.Packages[k] = NewPackageLockInfo(v, prov)
+	}
+	return state
+}
+
+func TestInventory_SaveAndLoad(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "machines")
+	machineA, _ := ParseMachineID("550e8400-e29b-41d4-a716-446655440000")
+	state := MachineState{
+		MachineID: machineA.String(),
+		Hostname:  "laptop",
+		OS:        "darwin/arm64",
+		Target:    "work",
+		AppliedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		Vector:    NewVersionVector().Increment(machineA),
+		Packages:  map[string]string{"brew:ripgrep": "14.1.0"},
+	}
+	require.NoError(t, SaveMachineState(dir, state))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("ignored"), 0o644))
+
+	inv, err := LoadInventory(dir)
+	require.NoError(t, err)
+	require.Equal(t, 1, inv.Len())
+
+	got, ok := inv.Get(machineA.String())
+	require.True(t, ok)
+	assert.Equal(t, state.Hostname, got.Hostname)
+	assert.Equal(t, state.AppliedAt, got.AppliedAt)
+	assert.Equal(t, uint64(1), got.Vector.GetByMachineID(machineA))
+	assert.Equal(t, state.Packages, got.Packages)
+}
+
+func TestInventory_LoadMissingDir(t *testing.T) {
+	t.Parallel()
+
+	inv, err := LoadInventory(filepath.Join(t.TempDir(), "missing"))
+	require.NoError(t, err)
+	assert.Equal(t, 0, inv.Len())
+}
+
+func TestInventory_LoadInvalidFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bad.json"), []byte("{"), 0o644))
+
+	_, err := LoadInventory(dir)
+	assert.ErrorContains(t, err, "invalid machine state bad.json")
+}
+
+func TestSaveMachineState_RequiresID(t *testing.T) {
+	t.Parallel()
+
+	err := SaveMachineState(t.TempDir(), MachineState{Hostname: "laptop"})
+	assert.ErrorIs(t, err, ErrInvalidMachineID)
+}
+
+func TestInventory_Status(t *testing.T) {
+	t.Parallel()
+
+	machineA, _ := ParseMachineID("550e8400-e29b-41d4-a716-446655440000")
+	machineB, _ := ParseMachineID("660e8400-e29b-41d4-a716-446655440000")
+	v1 := NewVersionVector().Increment(machineA)
+	v2 := v1.Increment(machineB)
+	lockState := testLockState(v2, map[string]string{
+		"brew:ripgrep": "14.1.0",
+		"brew:fd":      "latest",
+	})
+
+	inv := NewInventory()
+	inv.Record(MachineState{
+		MachineID: machineA.String(),
+		Hostname:  "laptop",
+		Vector:    v1,
+		Packages:  map[string]string{"brew:ripgrep": "14.0.0", "brew:fd": "10.0.0", "brew:jq": "1.7"},
+	})
+	inv.Record(MachineState{
+		MachineID: machineB.String(),
+		Hostname:  "desktop",
+		Vector:    v2,
+		Packages:  map[string]string{"brew:ripgrep": "14.1.0", "brew:fd": "10.0.0"},
+	})
+
+	statuses := inv.Status(lockState)
+	require.Len(t, statuses, 2)
+
+	desktop := statuses[0]
+	assert.Equal(t, "desktop", desktop.Hostname)
+	assert.Equal(t, Equal, desktop.Relation)
+	assert.Empty(t, desktop.Divergent, "applied versions satisfy \"latest\"")
+	assert.False(t, desktop.IsBehind())
+
+	laptop := statuses[1]
+	assert.Equal(t, Before, laptop.Relation)
+	assert.True(t, laptop.IsBehind())
+	assert.Equal(t, []PackageDivergence{
+		{PackageKey: "brew:jq", Applied: "1.7"},
+		{PackageKey: "brew:ripgrep", Applied: "14.0.0", Locked: "14.1.0"},
+	}, laptop.Divergent)
+}
+
+func TestCompareMachineState_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	machineA, _ := ParseMachineID("550e8400-e29b-41d4-a716-446655440000")
+	machineB, _ := ParseMachineID("660e8400-e29b-41d4-a716-446655440000")
+	state := MachineState{MachineID: machineA.String(), Vector: NewVersionVector().Increment(machineA)}
+
+	status := CompareMachineState(state, testLockState(NewVersionVector().Increment(machineB), nil))
+	assert.Equal(t, Concurrent, status.Relation)
+	assert.True(t, status.IsBehind())
+}
+
+func TestInventory_FindMachine(t *testing.T) {
+	t.Parallel()
+
+	inv := NewInventory()
+	inv.Record(MachineState{MachineID: "550e8400-e29b-41d4-a716-446655440000", Hostname: "Laptop"})
+	inv.Record(MachineState{MachineID: "550e9999-e29b-41d4-a716-446655440000", Hostname: "desktop"})
+
+	s, ok := inv.FindMachine("laptop")
+	require.True(t, ok)
+	assert.Equal(t, "Laptop", s.Hostname)
+
+	s, ok = inv.FindMachine("550e99")
+	require.True(t, ok)
+	assert.Equal(t, "desktop", s.Hostname)
+
+	_, ok = inv.FindMachine("550e")
+	assert.False(t, ok, "ambiguous prefix")
+
+	_, ok = inv.FindMachine("server")
+	assert.False(t, ok)
+}
+
+func TestInventory_ByTarget(t *testing.T) {
+	t.Parallel()
+
+	inv := NewInventory()
+	inv.Record(MachineState{MachineID: "a", Hostname: "studio", Target: "workstation"})
+	inv.Record(MachineState{MachineID: "b", Hostname: "air", Target: "laptop"})
+	inv.Record(MachineState{MachineID: "c", Hostname: "pro", Target: "laptop"})
+
+	byTarget := inv.ByTarget()
+	require.Len(t, byTarget, 2)
+	require.Len(t, byTarget["laptop"], 2)
+	assert.Equal(t, "air", byTarget["laptop"][0].Hostname)
+	assert.Equal(t, "pro", byTarget["laptop"][1].Hostname)
+	assert.Equal(t, "studio", byTarget["workstation"][0].Hostname)
+}
@@ -11,6 +11,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
 	"github.com/google/uuid"
 )
 
@@ -97,18 +98,19 @@ type FileMachineIdentityRepository struct {
 }
 
 // NewFileMachineIdentityRepository creates a repository that stores the machine ID
-// at the specified path. The default path is ~/.preflight/machine-id.
+// at the specified path. The default path is machine-id in the state
+// directory.
 func NewFileMachineIdentityRepository(path string) *FileMachineIdentityRepository {
 	return &FileMachineIdentityRepository{path: path}
 }
 
 // DefaultMachineIDPath returns the default path for storing machine identity.
 func DefaultMachineIDPath() string {
-	home, err := os.UserHomeDir()
+	path, err := platform.Path(platform.StateDir, "machine-id")
 	if err != nil {
 		return ".preflight/machine-id"
 	}
-	return filepath.Join(home, ".preflight", "machine-id")
+	return path
 }
 
 // Load reads the machine ID from the file.
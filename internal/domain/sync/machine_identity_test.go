@@ -291,11 +291,12 @@ func TestLoadOrCreate_Idempotent(t *testing.T) {
 }
 
 func TestDefaultMachineIDPath(t *testing.T) {
-	t.Parallel()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_STATE_HOME", "")
 
 	path := DefaultMachineIDPath()
-	assert.Contains(t, path, ".preflight")
-	assert.Contains(t, path, "machine-id")
+	assert.Equal(t, filepath.Join(home, ".local", "state", "preflight", "machine-id"), path)
 }
 
 func TestFileMachineIdentityRepository_LoadCorruptedFile(t *testing.T) {
@@ -0,0 +1,85 @@
+package sync
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
+)
+
+// MergeRecord is an audit record of a manual conflict resolution session.
+// It captures all three sides of every conflict and the chosen outcome.
+type MergeRecord struct {
+	MachineID  string             `json:"machine_id"`
+	Hostname   string             `json:"hostname,omitempty"`
+	Lockfile   string             `json:"lockfile"`
+	ResolvedAt time.Time          `json:"resolved_at"`
+	Entries    []MergeRecordEntry `json:"entries"`
+}
+
+// MergeRecordEntry records the resolution of a single conflicting package.
+type MergeRecordEntry struct {
+	PackageKey string `json:"package"`
+	Conflict   string `json:"conflict"`
+	Local      string `json:"local,omitempty"`
+	Remote     string `json:"remote,omitempty"`
+	Base       string `json:"base,omitempty"`
+	Choice     string `json:"choice"`
+	Result     string `json:"result,omitempty"`
+	Reason     string `json:"reason"`
+}
+
+// NewMergeRecord creates a merge record for the given resolutions.
+func NewMergeRecord(machineID MachineID, hostname, lockfile string, resolutions []Resolution, at time.Time) MergeRecord {
+	record := MergeRecord{
+		MachineID:  machineID.String(),
+		Hostname:   hostname,
+		Lockfile:   lockfile,
+		ResolvedAt: at.UTC(),
+		Entries:    make([]MergeRecordEntry, 0, len(resolutions)),
+	}
+	for _, r := range resolutions {
+		c := r.Conflict()
+		record.Entries = append(record.Entries, MergeRecordEntry{
+			PackageKey: c.PackageKey(),
+			Conflict:   c.Type().String(),
+			Local:      c.Local().Version(),
+			Remote:     c.Remote().Version(),
+			Base:       c.Base().Version(),
+			Choice:     r.Choice().String(),
+			Result:     r.Result().Version(),
+			Reason:     r.Reason(),
+		})
+	}
+	return record
+}
+
+// DefaultMergeRecordDir returns the default directory for merge records.
+func DefaultMergeRecordDir() string {
+	dir, err := platform.Path(platform.DataDir, "sync", "merges")
+	if err != nil {
+		return filepath.Join(".preflight", "sync", "merges")
+	}
+	return dir
+}
+
+// Save writes the record as JSON into dir and returns the file path.
+func (r MergeRecord) Save(dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, r.ResolvedAt.Format("20060102T150405Z")+".json")
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return "", fmt.Errorf("failed to write merge record: %w", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,50 @@
+package sync
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeRecord_Save(t *testing.T) {
+	t.Parallel()
+
+	machineA, _ := ParseMachineID("550e8400-e29b-41d4-a716-446655440000")
+	prov := NewPackageProvenance(machineA, NewVersionVector().Increment(machineA))
+	conflict := NewLockConflict("brew:ripgrep", BothModified,
+		NewPackageLockInfo("14.0.0", prov), NewPackageLockInfo("14.1.0", prov), NewPackageLockInfo("13.0.0", prov))
+	at := time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC)
+
+	record := NewMergeRecord(machineA, "laptop", "/repo/preflight.lock", []Resolution{
+		ResolveWithVersion(conflict, "14.1.1", at),
+	}, at)
+
+	dir := filepath.Join(t.TempDir(), "merges")
+	path, err := record.Save(dir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "20260301T123000Z.json"), path)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var got MergeRecord
+	require.NoError(t, json.Unmarshal(data, &got))
+
+	assert.Equal(t, machineA.String(), got.MachineID)
+	assert.Equal(t, "laptop", got.Hostname)
+	require.Len(t, got.Entries, 1)
+	assert.Equal(t, MergeRecordEntry{
+		PackageKey: "brew:ripgrep",
+		Conflict:   "both_modified",
+		Local:      "14.0.0",
+		Remote:     "14.1.0",
+		Base:       "13.0.0",
+		Choice:     "custom",
+		Result:     "14.1.1",
+		Reason:     "manually edited to 14.1.1",
+	}, got.Entries[0])
+}
@@ -0,0 +1,297 @@
+package sync
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Remote sync errors.
+var (
+	// ErrRemoteNotFound is returned when no bundle has been pushed to the remote yet.
+	ErrRemoteNotFound = errors.New("no bundle found on remote")
+	// ErrInvalidBundle is returned when a downloaded bundle cannot be read.
+	ErrInvalidBundle = errors.New("invalid sync bundle")
+)
+
+// BundleObject is the object name of the encrypted bundle below the remote prefix.
+const BundleObject = "preflight.bundle.age"
+
+// maxBundleSize limits decompressed bundle contents to guard against corrupt
+// or hostile archives (64MB).
+const maxBundleSize = 64 << 20
+
+// bundleManifestName is the metadata entry stored first in every bundle.
+const bundleManifestName = ".preflight-bundle.json"
+
+// RemoteStore stores opaque objects in commodity storage such as S3 or WebDAV.
+// Implementations never see plaintext; bundles are encrypted before upload.
+type RemoteStore interface {
+	// Put uploads an object, replacing any existing object with the same key.
+	Put(ctx context.Context, key string, data []byte) error
+	// Get downloads an object. It returns ErrRemoteNotFound if the key does not exist.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// String describes the remote location for display.
+	String() string
+}
+
+// Encryptor encrypts bundles client-side before they leave the machine.
+type Encryptor interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// BundleManifest describes who produced a bundle and what it contains.
+type BundleManifest struct {
+	MachineID string    `json:"machine_id,omitempty"`
+	Hostname  string    `json:"hostname,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	Files     []string  `json:"files"`
+}
+
+// Bundle is the set of configuration files synced between machines: the
+// config, its layers and the lockfile. Paths are slash-separated and
+// relative to the config directory.
+type Bundle struct {
+	Manifest BundleManifest
+	Files    map[string][]byte
+}
+
+// BundlePatterns are the files included in a bundle, relative to the config directory.
+var BundlePatterns = []string{
+	"preflight.yaml",
+	"preflight.lock",
+	"layers/*.yaml",
+	"layers/*.yml",
+}
+
+// CollectBundle reads the bundle files from a config directory.
+func CollectBundle(dir string, manifest BundleManifest) (*Bundle, error) {
+	b := &Bundle{Manifest: manifest, Files: make(map[string][]byte)}
+	for _, pattern := range BundlePatterns {
+		matches, err := filepath.Glob(filepath.Join(dir, filepath.FromSlash(pattern)))
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			data, err := os.ReadFile(match)
+			if err != nil {
+				return nil, fmt.Errorf("reading %s: %w", match, err)
+			}
+			rel, err := filepath.Rel(dir, match)
+			if err != nil {
+				return nil, err
+			}
+			b.Files[filepath.ToSlash(rel)] = data
+		}
+	}
+	if _, ok := b.Files["preflight.yaml"]; !ok {
+		return nil, fmt.Errorf("no preflight.yaml in %s", dir)
+	}
+	b.Manifest.Files = b.Names()
+	return b, nil
+}
+
+// Names returns the bundle's file names in sorted order.
+func (b *Bundle) Names() []string {
+	names := make([]string, 0, len(b.Files))
+	for name := range b.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Marshal encodes the bundle as a gzipped tar archive.
+func (b *Bundle) Marshal() ([]byte, error) {
+	manifest, err := json.Marshal(b.Manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	write := func(name string, data []byte) error {
+		hdr := &tar.Header{
+			Name:    name,
+			Mode:    0o644,
+			Size:    int64(len(data)),
+			ModTime: b.Manifest.CreatedAt,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+
+	if err := write(bundleManifestName, manifest); err != nil {
+		return nil, err
+	}
+	for _, name := range b.Names() {
+		if err := write(name, b.Files[name]); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBundle decodes a bundle produced by Marshal.
+func UnmarshalBundle(data []byte) (*Bundle, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidBundle, err)
+	}
+	tr := tar.NewReader(io.LimitReader(gz, maxBundleSize))
+
+	b := &Bundle{Files: make(map[string][]byte)}
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidBundle, err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidBundle, err)
+		}
+		if hdr.Name == bundleManifestName {
+			if err := json.Unmarshal(content, &b.Manifest); err != nil {
+				return nil, fmt.Errorf("%w: manifest: %w", ErrInvalidBundle, err)
+			}
+			continue
+		}
+		if !validBundlePath(hdr.Name) {
+			return nil, fmt.Errorf("%w: unexpected path %q", ErrInvalidBundle, hdr.Name)
+		}
+		b.Files[hdr.Name] = content
+	}
+	return b, nil
+}
+
+// validBundlePath reports whether name is a relative path matching one of
+// the bundle patterns, so a bundle can never write outside the config dir.
+func validBundlePath(name string) bool {
+	if name != path.Clean(name) || path.IsAbs(name) || strings.HasPrefix(name, "..") {
+		return false
+	}
+	for _, pattern := range BundlePatterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// FileChange describes how writing a bundle changes a local file.
+type FileChange struct {
+	Path    string
+	Created bool
+}
+
+// Diff returns the bundle files that differ from the files in dir.
+func (b *Bundle) Diff(dir string) []FileChange {
+	var changes []FileChange
+	for _, name := range b.Names() {
+		local, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		switch {
+		case os.IsNotExist(err):
+			changes = append(changes, FileChange{Path: name, Created: true})
+		case err != nil || !bytes.Equal(local, b.Files[name]):
+			changes = append(changes, FileChange{Path: name})
+		}
+	}
+	return changes
+}
+
+// WriteTo writes the bundle files into dir.
+func (b *Bundle) WriteTo(dir string) error {
+	for _, name := range b.Names() {
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, b.Files[name], 0o644); err != nil { //nolint:gosec // config files are not secret
+			return fmt.Errorf("writing %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// RemoteSync pushes and pulls encrypted bundles through a RemoteStore.
+type RemoteSync struct {
+	store     RemoteStore
+	encryptor Encryptor
+	prefix    string
+}
+
+// NewRemoteSync creates a remote sync using store for storage and encryptor
+// for client-side encryption. Objects are stored below prefix.
+func NewRemoteSync(store RemoteStore, encryptor Encryptor, prefix string) *RemoteSync {
+	return &RemoteSync{store: store, encryptor: encryptor, prefix: strings.Trim(prefix, "/")}
+}
+
+// Key returns the object key of the bundle.
+func (s *RemoteSync) Key() string {
+	if s.prefix == "" {
+		return BundleObject
+	}
+	return s.prefix + "/" + BundleObject
+}
+
+// Push encrypts and uploads a bundle.
+func (s *RemoteSync) Push(ctx context.Context, b *Bundle) error {
+	plaintext, err := b.Marshal()
+	if err != nil {
+		return fmt.Errorf("encoding bundle: %w", err)
+	}
+	ciphertext, err := s.encryptor.Encrypt(plaintext)
+	if err != nil {
+		return fmt.Errorf("encrypting bundle: %w", err)
+	}
+	if err := s.store.Put(ctx, s.Key(), ciphertext); err != nil {
+		return fmt.Errorf("uploading to %s: %w", s.store, err)
+	}
+	return nil
+}
+
+// Pull downloads and decrypts the bundle.
+func (s *RemoteSync) Pull(ctx context.Context) (*Bundle, error) {
+	ciphertext, err := s.store.Get(ctx, s.Key())
+	if err != nil {
+		return nil, fmt.Errorf("downloading from %s: %w", s.store, err)
+	}
+	plaintext, err := s.encryptor.Decrypt(ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting bundle: %w", err)
+	}
+	return UnmarshalBundle(plaintext)
+}
@@ -0,0 +1,174 @@
+package sync
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryStore struct {
+	objects map[string][]byte
+}
+
+func (m *memoryStore) Put(_ context.Context, key string, data []byte) error {
+	m.objects[key] = data
+	return nil
+}
+
+func (m *memoryStore) Get(_ context.Context, key string) ([]byte, error) {
+	data, ok := m.objects[key]
+	if !ok {
+		return nil, ErrRemoteNotFound
+	}
+	return data, nil
+}
+
+func (m *memoryStore) String() string { return "memory://" }
+
+// xorEncryptor is a reversible stand-in for real encryption.
+type xorEncryptor struct{}
+
+func (xorEncryptor) Encrypt(p []byte) ([]byte, error) { return xor(p), nil }
+
+func (xorEncryptor) Decrypt(c []byte) ([]byte, error) {
+	if len(c) == 0 {
+		return nil, errors.New("empty ciphertext")
+	}
+	return xor(c), nil
+}
+
+func xor(b []byte) []byte {
+	out := make([]byte, len(b))
+	for i, c := range b {
+		out[i] = c ^ 0x5a
+	}
+	return out
+}
+
+func writeConfigDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "layers"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "preflight.yaml"), []byte("targets:\n  default: [base]\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "preflight.lock"), []byte("version: 1\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "layers", "base.yaml"), []byte("name: base\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0o600))
+	return dir
+}
+
+func TestCollectBundle(t *testing.T) {
+	t.Parallel()
+
+	dir := writeConfigDir(t)
+	b, err := CollectBundle(dir, BundleManifest{Hostname: "laptop"})
+	require.NoError(t, err)
+
+	want := []string{"layers/base.yaml", "preflight.lock", "preflight.yaml"}
+	assert.Equal(t, want, b.Names())
+	assert.Equal(t, want, b.Manifest.Files)
+}
+
+func TestCollectBundle_RequiresConfig(t *testing.T) {
+	t.Parallel()
+
+	_, err := CollectBundle(t.TempDir(), BundleManifest{})
+	require.Error(t, err)
+}
+
+func TestBundle_MarshalRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	b, err := CollectBundle(writeConfigDir(t), BundleManifest{Hostname: "laptop", CreatedAt: created})
+	require.NoError(t, err)
+
+	data, err := b.Marshal()
+	require.NoError(t, err)
+
+	got, err := UnmarshalBundle(data)
+	require.NoError(t, err)
+	assert.Equal(t, b.Files, got.Files)
+	assert.Equal(t, "laptop", got.Manifest.Hostname)
+	assert.True(t, created.Equal(got.Manifest.CreatedAt))
+}
+
+func TestUnmarshalBundle_RejectsUnexpectedPaths(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"../preflight.yaml", "/etc/passwd", "layers/../../x.yaml", ".ssh/config"} {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gz)
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: 1}))
+		_, err := tw.Write([]byte("x"))
+		require.NoError(t, err)
+		require.NoError(t, tw.Close())
+		require.NoError(t, gz.Close())
+
+		_, err = UnmarshalBundle(buf.Bytes())
+		require.ErrorIs(t, err, ErrInvalidBundle, name)
+	}
+
+	_, err := UnmarshalBundle([]byte("not gzip"))
+	require.ErrorIs(t, err, ErrInvalidBundle)
+}
+
+func TestBundle_DiffAndWriteTo(t *testing.T) {
+	t.Parallel()
+
+	b, err := CollectBundle(writeConfigDir(t), BundleManifest{})
+	require.NoError(t, err)
+
+	target := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(target, "preflight.yaml"), []byte("targets: {}\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(target, "preflight.lock"), b.Files["preflight.lock"], 0o600))
+
+	assert.Equal(t, []FileChange{
+		{Path: "layers/base.yaml", Created: true},
+		{Path: "preflight.yaml"},
+	}, b.Diff(target))
+
+	require.NoError(t, b.WriteTo(target))
+	assert.Empty(t, b.Diff(target))
+}
+
+func TestRemoteSync_PushPull(t *testing.T) {
+	t.Parallel()
+
+	store := &memoryStore{objects: map[string][]byte{}}
+	rs := NewRemoteSync(store, xorEncryptor{}, "/team/laptop/")
+	assert.Equal(t, "team/laptop/"+BundleObject, rs.Key())
+
+	ctx := context.Background()
+	_, err := rs.Pull(ctx)
+	require.ErrorIs(t, err, ErrRemoteNotFound)
+
+	b, err := CollectBundle(writeConfigDir(t), BundleManifest{Hostname: "laptop"})
+	require.NoError(t, err)
+	require.NoError(t, rs.Push(ctx, b))
+
+	// The stored object is not the plaintext archive.
+	plaintext, err := b.Marshal()
+	require.NoError(t, err)
+	assert.NotEqual(t, plaintext, store.objects[rs.Key()])
+
+	got, err := rs.Pull(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, b.Files, got.Files)
+}
+
+func TestRemoteSync_NoPrefix(t *testing.T) {
+	t.Parallel()
+
+	rs := NewRemoteSync(&memoryStore{}, xorEncryptor{}, "")
+	assert.Equal(t, BundleObject, rs.Key())
+}
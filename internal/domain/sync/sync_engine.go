@@ -321,6 +321,14 @@ func (e *SyncEngine) ResolveManualConflict(
 	conflict LockConflict,
 	choice ResolutionChoice,
 ) error {
+	return e.ApplyResolution(result, ResolveManually(conflict, choice))
+}
+
+// ApplyResolution applies a manual resolution, such as one created by
+// ResolveWithVersion, to the sync result.
+func (e *SyncEngine) ApplyResolution(result *SyncResult, resolution Resolution) error {
+	conflict := resolution.Conflict()
+
 	// Find and remove the conflict from manual list
 	found := false
 	newManual := make([]LockConflict, 0, len(result.ManualConflicts))
@@ -336,8 +344,6 @@ func (e *SyncEngine) ResolveManualConflict(
 		return fmt.Errorf("conflict not found: %s", conflict.PackageKey())
 	}
 
-	// Create resolution
-	resolution := ResolveManually(conflict, choice)
 	result.Resolutions = append(result.Resolutions, resolution)
 	result.ManualConflicts = newManual
 
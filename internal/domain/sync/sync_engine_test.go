@@ -2,6 +2,7 @@ package sync
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -263,6 +264,33 @@ func TestSyncEngine_ResolveManualConflict(t *testing.T) {
 	assert.Equal(t, "14.1.0", result.Merged.Packages["brew:ripgrep"].Version())
 }
 
+func TestSyncEngine_ApplyResolution_CustomVersion(t *testing.T) {
+	t.Parallel()
+
+	machineA, _ := ParseMachineID("550e8400-e29b-41d4-a716-446655440000")
+	machineB, _ := ParseMachineID("660e8400-e29b-41d4-a716-446655440000")
+
+	local := NewLockfileState()
+	local.AddPackage("brew:ripgrep", NewPackageLockInfo("14.0.0", NewPackageProvenance(machineA, NewVersionVector().Increment(machineA))))
+	remote := NewLockfileState()
+	remote.AddPackage("brew:ripgrep", NewPackageLockInfo("14.1.0", NewPackageProvenance(machineB, NewVersionVector().Increment(machineB))))
+
+	engine := NewSyncEngine()
+	result, err := engine.Sync(SyncInput{Local: local, Remote: remote})
+	require.NoError(t, err)
+	require.Len(t, result.ManualConflicts, 1)
+
+	resolution := ResolveWithVersion(result.ManualConflicts[0], "14.1.1", time.Now())
+	require.NoError(t, engine.ApplyResolution(result, resolution))
+
+	assert.True(t, result.IsClean())
+	assert.Equal(t, "14.1.1", result.Merged.Packages["brew:ripgrep"].Version())
+	require.Len(t, result.Resolutions, 1)
+	assert.Equal(t, ChooseCustom, result.Resolutions[0].Choice())
+
+	assert.Error(t, engine.ApplyResolution(result, resolution), "already resolved")
+}
+
 func TestSyncEngine_ResolveManualConflict_NotFound(t *testing.T) {
 	t.Parallel()
 
@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"sync"
 	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
 )
 
 // Progress tracks user's tour completion state.
@@ -155,11 +157,10 @@ type ProgressStore struct {
 
 // NewProgressStore creates a store with the default path.
 func NewProgressStore() (*ProgressStore, error) {
-	homeDir, err := os.UserHomeDir()
+	path, err := platform.Path(platform.StateDir, "tour-progress.json")
 	if err != nil {
 		return nil, err
 	}
-	path := filepath.Join(homeDir, ".preflight", "tour-progress.json")
 	return &ProgressStore{path: path}, nil
 }
 
@@ -295,11 +295,14 @@ func TestProgressStore_Path(t *testing.T) {
 }
 
 func TestNewProgressStore(t *testing.T) {
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	t.Setenv("XDG_STATE_HOME", "")
+
 	store, err := NewProgressStore()
 	require.NoError(t, err)
 
-	homeDir, _ := os.UserHomeDir()
-	expected := filepath.Join(homeDir, ".preflight", "tour-progress.json")
+	expected := filepath.Join(homeDir, ".local", "state", "preflight", "tour-progress.json")
 	assert.Equal(t, expected, store.Path())
 }
 
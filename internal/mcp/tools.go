@@ -288,6 +288,7 @@ type OutdatedPackage struct {
 	CurrentVersion string `json:"current_version"`
 	LatestVersion  string `json:"latest_version"`
 	UpdateType     string `json:"update_type"` // major, minor, patch
+	Provider       string `json:"provider"`
 }
 
 // OutdatedSummary contains outdated packages summary.
@@ -971,9 +972,7 @@ func registerOutdatedTool(srv *mcp.Server) {
 		ReadOnly().
 		OutputSchema(OutdatedOutput{}).
 		Handler(func(ctx context.Context, in OutdatedInput) (*OutdatedOutput, error) {
-			registry := security.NewOutdatedCheckerRegistry()
-			checker := security.NewBrewOutdatedChecker()
-			registry.Register(checker)
+			registry := security.NewDefaultOutdatedCheckerRegistry()
 
 			checkers := registry.All()
 			if len(checkers) == 0 {
@@ -982,17 +981,19 @@ func registerOutdatedTool(srv *mcp.Server) {
 				}, nil
 			}
 
-			// Use first available checker
-			activeChecker := checkers[0]
-
 			opts := security.OutdatedOptions{
 				IncludePatch:   in.IncludeAll,
 				IgnorePackages: in.IgnoreIDs,
 			}
 
-			result, err := activeChecker.Check(ctx, opts)
-			if err != nil {
-				return nil, err
+			// Check every available provider
+			result := &security.OutdatedResult{}
+			for _, c := range checkers {
+				checked, err := c.Check(ctx, opts)
+				if err != nil {
+					return nil, err
+				}
+				result.Packages = append(result.Packages, checked.Packages...)
 			}
 
 			output := &OutdatedOutput{
@@ -1009,6 +1010,7 @@ func registerOutdatedTool(srv *mcp.Server) {
 					CurrentVersion: pkg.CurrentVersion,
 					LatestVersion:  pkg.LatestVersion,
 					UpdateType:     updateType,
+					Provider:       pkg.Provider,
 				})
 
 				switch pkg.UpdateType { //nolint:exhaustive // UpdateUnknown intentionally ignored
@@ -1339,7 +1341,7 @@ func registerToolAnalyzeTool(srv *mcp.Server) {
 			}
 
 			// Load the knowledge base
-			kb, err := tools.LoadKnowledgeBase()
+			kb, _, err := tools.LoadInstalledKnowledgeBase(tools.DefaultPackDir())
 			if err != nil {
 				return nil, fmt.Errorf("failed to load tool knowledge base: %w", err)
 			}
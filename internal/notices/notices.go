@@ -0,0 +1,131 @@
+// Package notices tells the user, at most once a day, that a newer
+// preflight release exists or that their configuration uses deprecated
+// keys.
+//
+// **Default: disabled.** Nothing is checked until the user opts in with
+// 'preflight version --notify'. The release check is a plain request for
+// the latest GitHub release: it sends no machine ID, config or usage data,
+// and it never reads or writes telemetry.
+package notices
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/semver"
+)
+
+// Interval is the least time between two notices, and between two
+// release checks.
+const Interval = 24 * time.Hour
+
+// ReleaseURL is the GitHub API endpoint of the latest preflight release.
+const ReleaseURL = "https://api.github.com/repos/felixgeelhaar/preflight/releases/latest"
+
+// stateFile is the name of the state file in the preflight config directory.
+const stateFile = "notices.json"
+
+// State is what notices keeps between runs.
+type State struct {
+	// Enabled is the user's opt-in.
+	Enabled bool `json:"enabled"`
+	// LastShown is when notices were last printed.
+	LastShown time.Time `json:"last_shown,omitempty"`
+	// CheckedAt is when the latest release was last looked up.
+	CheckedAt time.Time `json:"checked_at,omitempty"`
+	// LatestVersion is the latest release found by that lookup.
+	LatestVersion string `json:"latest_version,omitempty"`
+}
+
+// Due reports whether notices may be shown at now.
+func (s State) Due(now time.Time) bool {
+	return s.Enabled && now.Sub(s.LastShown) >= Interval
+}
+
+// CheckDue reports whether the latest release should be looked up again
+// at now.
+func (s State) CheckDue(now time.Time) bool {
+	return s.Enabled && now.Sub(s.CheckedAt) >= Interval
+}
+
+// LoadState reads the state from the preflight config directory dir. A
+// missing state is the zero State, which is disabled.
+func LoadState(dir string) (State, error) {
+	// #nosec G304 -- the state file in the preflight config directory.
+	data, err := os.ReadFile(filepath.Join(dir, stateFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return State{}, nil
+	}
+	if err != nil {
+		return State{}, fmt.Errorf("failed to read notice state: %w", err)
+	}
+	var s State
+	if err := json.Unmarshal(data, &s); err != nil {
+		return State{}, fmt.Errorf("failed to parse notice state: %w", err)
+	}
+	return s, nil
+}
+
+// SaveState writes s to the preflight config directory dir.
+func SaveState(dir string, s State) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, stateFile), append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write notice state: %w", err)
+	}
+	return nil
+}
+
+// LatestRelease returns the version of the latest release at url, a
+// GitHub API latest-release endpoint such as ReleaseURL.
+func LatestRelease(ctx context.Context, client *http.Client, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to check for a new release: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to check for a new release: %s", resp.Status)
+	}
+	var release struct {
+		TagName string `json:"tag_name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return "", fmt.Errorf("failed to parse the latest release: %w", err)
+	}
+	if !semver.IsValid(canonical(release.TagName)) {
+		return "", fmt.Errorf("latest release %q is not a version", release.TagName)
+	}
+	return strings.TrimPrefix(release.TagName, "v"), nil
+}
+
+// Newer reports whether latest is a newer version than current.
+// Development builds, whose version is not semantic, are never outdated.
+func Newer(current, latest string) bool {
+	current, latest = canonical(current), canonical(latest)
+	if !semver.IsValid(current) || !semver.IsValid(latest) {
+		return false
+	}
+	return semver.Compare(latest, current) > 0
+}
+
+func canonical(v string) string {
+	return "v" + strings.TrimPrefix(v, "v")
+}
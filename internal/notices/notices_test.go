@@ -0,0 +1,107 @@
+package notices
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadState_MissingIsDisabled(t *testing.T) {
+	t.Parallel()
+
+	s, err := LoadState(t.TempDir())
+	require.NoError(t, err)
+	assert.Equal(t, State{}, s)
+	assert.False(t, s.Due(time.Now()))
+	assert.False(t, s.CheckDue(time.Now()))
+}
+
+func TestSaveState_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), ".preflight")
+	want := State{
+		Enabled:       true,
+		LastShown:     time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC),
+		CheckedAt:     time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC),
+		LatestVersion: "1.4.0",
+	}
+	require.NoError(t, SaveState(dir, want))
+
+	info, err := os.Stat(filepath.Join(dir, stateFile))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	got, err := LoadState(dir)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestLoadState_Corrupt(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, stateFile), []byte("{"), 0o600))
+	_, err := LoadState(dir)
+	assert.Error(t, err)
+}
+
+func TestState_Due(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 2, 12, 0, 0, 0, time.UTC)
+	s := State{Enabled: true, LastShown: now.Add(-23 * time.Hour), CheckedAt: now.Add(-25 * time.Hour)}
+	assert.False(t, s.Due(now))
+	assert.True(t, s.CheckDue(now))
+
+	s.LastShown = now.Add(-Interval)
+	assert.True(t, s.Due(now))
+
+	s.Enabled = false
+	assert.False(t, s.Due(now))
+	assert.False(t, s.CheckDue(now))
+}
+
+func TestNewer(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, Newer("1.2.0", "1.3.0"))
+	assert.True(t, Newer("v1.2.0", "1.2.1"))
+	assert.False(t, Newer("1.3.0", "1.3.0"))
+	assert.False(t, Newer("1.3.0", "v1.2.9"))
+	assert.False(t, Newer("dev", "1.3.0"), "development builds are never outdated")
+	assert.False(t, Newer("1.3.0", ""))
+}
+
+func TestLatestRelease(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/latest":
+			_, _ = w.Write([]byte(`{"tag_name": "v1.5.0", "name": "Preflight 1.5"}`))
+		case "/nightly":
+			_, _ = w.Write([]byte(`{"tag_name": "nightly"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	latest, err := LatestRelease(context.Background(), server.Client(), server.URL+"/latest")
+	require.NoError(t, err)
+	assert.Equal(t, "1.5.0", latest)
+
+	_, err = LatestRelease(context.Background(), server.Client(), server.URL+"/nightly")
+	require.ErrorContains(t, err, `"nightly" is not a version`)
+
+	_, err = LatestRelease(context.Background(), server.Client(), server.URL+"/missing")
+	require.ErrorContains(t, err, "404")
+}
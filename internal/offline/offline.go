@@ -0,0 +1,51 @@
+// Package offline implements preflight's offline mode, enabled with the
+// global --offline flag or PREFLIGHT_OFFLINE=1. In offline mode commands
+// skip everything that needs the network, such as marketplace refreshes,
+// AI, version checks and remote layers, and say so instead of timing out.
+package offline
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync/atomic"
+)
+
+// EnvVar enables offline mode when set to "1".
+const EnvVar = "PREFLIGHT_OFFLINE"
+
+// ErrOffline is returned for anything skipped because offline mode is on.
+var ErrOffline = errors.New("skipped (offline)")
+
+var enabled atomic.Bool
+
+// Enabled reports whether offline mode is on.
+func Enabled() bool {
+	return enabled.Load() || os.Getenv(EnvVar) == "1"
+}
+
+// Enable turns offline mode on for the rest of the process. As a safety
+// net for call sites that do not check Enabled, requests through
+// http.DefaultTransport fail with ErrOffline from then on.
+func Enable() {
+	if enabled.Swap(true) {
+		return
+	}
+	http.DefaultTransport = Transport{}
+}
+
+// Skipped returns an ErrOffline noting what was skipped, such as
+// "AI skipped (offline)".
+func Skipped(what string) error {
+	return fmt.Errorf("%s %w", what, ErrOffline)
+}
+
+// Transport is an http.RoundTripper that fails every request with
+// ErrOffline.
+type Transport struct{}
+
+// RoundTrip implements http.RoundTripper.
+func (Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return nil, Skipped(req.Method + " " + req.URL.Redacted())
+}
@@ -0,0 +1,50 @@
+package offline
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnabled_Env(t *testing.T) {
+	t.Setenv(EnvVar, "")
+	assert.False(t, Enabled())
+
+	t.Setenv(EnvVar, "1")
+	assert.True(t, Enabled())
+}
+
+func TestSkipped(t *testing.T) {
+	t.Parallel()
+
+	err := Skipped("AI")
+	require.ErrorIs(t, err, ErrOffline)
+	assert.Equal(t, "AI skipped (offline)", err.Error())
+}
+
+func TestEnable_BlocksDefaultTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	oldTransport := http.DefaultTransport
+	defer func() {
+		http.DefaultTransport = oldTransport
+		enabled.Store(false)
+	}()
+	t.Setenv(EnvVar, "")
+
+	Enable()
+	assert.True(t, Enabled())
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	_, err = http.DefaultClient.Do(req)
+	require.ErrorIs(t, err, ErrOffline)
+	assert.Contains(t, err.Error(), "GET "+server.URL+" skipped (offline)")
+}
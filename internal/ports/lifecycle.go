@@ -14,6 +14,18 @@ type FileLifecycle interface {
 	AfterApply(ctx context.Context, path, sourceLayer string) error
 }
 
+// AppliedContentStore keeps the content the config last produced for a
+// managed file. It is the base for merging local edits to the file with
+// later config changes. A FileLifecycle may implement it.
+type AppliedContentStore interface {
+	// RecordAppliedContent keeps content as the config's version of path.
+	RecordAppliedContent(ctx context.Context, path string, content []byte) error
+
+	// AppliedContent returns the config's version of path as last applied,
+	// and false when none was recorded.
+	AppliedContent(ctx context.Context, path string) ([]byte, bool, error)
+}
+
 // NoopLifecycle is a no-op implementation of FileLifecycle.
 // Use this when lifecycle management is not needed.
 type NoopLifecycle struct{}
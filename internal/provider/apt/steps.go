@@ -338,6 +338,11 @@ func (s *PackageStep) LockInfo() (compiler.LockInfo, bool) {
 	}, true
 }
 
+// UninstallCommand returns the command that removes the package.
+func (s *PackageStep) UninstallCommand() []string {
+	return []string{"sudo", "apt-get", "remove", "-y", s.pkg.Name}
+}
+
 // InstalledVersion returns the installed apt package version if available.
 func (s *PackageStep) InstalledVersion(ctx compiler.RunContext) (string, bool, error) {
 	result, err := s.runner.Run(ctx.Context(), "dpkg-query", "-W", "-f=${Version}\n", s.pkg.Name)
@@ -698,6 +698,13 @@ func TestPackageStep_LockInfo_NoVersion(t *testing.T) {
 	assert.Equal(t, "", info.Version)
 }
 
+func TestPackageStep_UninstallCommand(t *testing.T) {
+	t.Parallel()
+
+	step := apt.NewPackageStep(apt.Package{Name: "git", Version: "2.39.0"}, mocks.NewCommandRunner())
+	assert.Equal(t, []string{"sudo", "apt-get", "remove", "-y", "git"}, step.UninstallCommand())
+}
+
 // --- PackageStep InstalledVersion tests ---
 
 func TestPackageStep_InstalledVersion_Found(t *testing.T) {
@@ -2,12 +2,14 @@ package aws
 
 import (
 	"fmt"
+	"strings"
 )
 
 // Config represents the aws section of the configuration.
 type Config struct {
 	Profiles       []Profile
 	SSO            []SSOConfig
+	SSOSessions    []SSOSession
 	DefaultProfile string
 	DefaultRegion  string
 }
@@ -26,9 +28,11 @@ type Profile struct {
 	DurationSeconds int
 }
 
-// SSOConfig represents AWS SSO configuration.
+// SSOConfig represents AWS SSO configuration. A profile either references
+// a shared SSO session or carries its own start URL and region.
 type SSOConfig struct {
 	ProfileName  string
+	SSOSession   string
 	SSOStartURL  string
 	SSORegion    string
 	SSOAccountID string
@@ -37,6 +41,15 @@ type SSOConfig struct {
 	Output       string
 }
 
+// SSOSession is a named [sso-session] section shared by SSO profiles, so
+// one 'aws sso login --sso-session' refreshes tokens for all of them.
+type SSOSession struct {
+	Name               string
+	StartURL           string
+	Region             string
+	RegistrationScopes string
+}
+
 // ParseConfig parses the aws configuration from a raw map.
 func ParseConfig(raw map[string]interface{}) (*Config, error) {
 	cfg := &Config{
@@ -66,6 +79,17 @@ func ParseConfig(raw map[string]interface{}) (*Config, error) {
 		}
 	}
 
+	// Parse SSO sessions
+	if sessions, ok := raw["sso_sessions"].([]interface{}); ok {
+		for _, s := range sessions {
+			session, err := parseSSOSession(s)
+			if err != nil {
+				return nil, err
+			}
+			cfg.SSOSessions = append(cfg.SSOSessions, session)
+		}
+	}
+
 	// Parse default profile
 	if defaultProfile, ok := raw["default_profile"].(string); ok {
 		cfg.DefaultProfile = defaultProfile
@@ -121,6 +145,15 @@ func parseProfile(raw interface{}) (Profile, error) {
 		profile.DurationSeconds = durationSeconds
 	}
 
+	if (profile.AccessKeyRef == "") != (profile.SecretKeyRef == "") {
+		return Profile{}, fmt.Errorf("profile %s: access_key_ref and secret_key_ref must be set together", profile.Name)
+	}
+	for _, ref := range []string{profile.AccessKeyRef, profile.SecretKeyRef} {
+		if ref != "" && !strings.HasPrefix(ref, "secret://") {
+			return Profile{}, fmt.Errorf("profile %s: credentials must be secret references (secret://backend/key)", profile.Name)
+		}
+	}
+
 	return profile, nil
 }
 
@@ -138,6 +171,9 @@ func parseSSOConfig(raw interface{}) (SSOConfig, error) {
 		return SSOConfig{}, fmt.Errorf("sso config must have a profile_name")
 	}
 
+	if session, ok := m["sso_session"].(string); ok {
+		sso.SSOSession = session
+	}
 	if startURL, ok := m["sso_start_url"].(string); ok {
 		sso.SSOStartURL = startURL
 	}
@@ -159,3 +195,30 @@ func parseSSOConfig(raw interface{}) (SSOConfig, error) {
 
 	return sso, nil
 }
+
+func parseSSOSession(raw interface{}) (SSOSession, error) {
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		return SSOSession{}, fmt.Errorf("sso session must be an object")
+	}
+
+	session := SSOSession{}
+	if name, ok := m["name"].(string); ok {
+		session.Name = name
+	} else {
+		return SSOSession{}, fmt.Errorf("sso session must have a name")
+	}
+	if startURL, ok := m["sso_start_url"].(string); ok {
+		session.StartURL = startURL
+	} else {
+		return SSOSession{}, fmt.Errorf("sso session %s must have an sso_start_url", session.Name)
+	}
+	if region, ok := m["sso_region"].(string); ok {
+		session.Region = region
+	}
+	if scopes, ok := m["sso_registration_scopes"].(string); ok {
+		session.RegistrationScopes = scopes
+	}
+
+	return session, nil
+}
@@ -40,7 +40,10 @@ func (p *Provider) Compile(ctx compiler.CompileContext) ([]compiler.Step, error)
 		steps = append(steps, NewProfileStep(profile, p.runner))
 	}
 
-	// Add SSO steps
+	// Add SSO session and profile steps
+	for _, session := range cfg.SSOSessions {
+		steps = append(steps, NewSSOSessionStep(session, p.runner))
+	}
 	for _, sso := range cfg.SSO {
 		steps = append(steps, NewSSOStep(sso, p.runner))
 	}
@@ -0,0 +1,174 @@
+package aws
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/provider/secretutil"
+	"gopkg.in/ini.v1"
+)
+
+// SSOSessionStep writes an [sso-session] section to ~/.aws/config.
+type SSOSessionStep struct {
+	session SSOSession
+	id      compiler.StepID
+	runner  ports.CommandRunner
+}
+
+// NewSSOSessionStep creates a new SSOSessionStep.
+func NewSSOSessionStep(session SSOSession, runner ports.CommandRunner) *SSOSessionStep {
+	return &SSOSessionStep{
+		session: session,
+		id:      compiler.MustNewStepID("aws:sso-session:" + session.Name),
+		runner:  runner,
+	}
+}
+
+// ID returns the step identifier.
+func (s *SSOSessionStep) ID() compiler.StepID {
+	return s.id
+}
+
+// DependsOn returns the step dependencies.
+func (s *SSOSessionStep) DependsOn() []compiler.StepID {
+	return nil
+}
+
+// settings returns the declared session keys.
+func (s *SSOSessionStep) settings() map[string]string {
+	settings := map[string]string{"sso_start_url": s.session.StartURL}
+	if s.session.Region != "" {
+		settings["sso_region"] = s.session.Region
+	}
+	if s.session.RegistrationScopes != "" {
+		settings["sso_registration_scopes"] = s.session.RegistrationScopes
+	}
+	return settings
+}
+
+// Check determines if the session section matches.
+func (s *SSOSessionStep) Check(_ compiler.RunContext) (compiler.StepStatus, error) {
+	cfg, err := ini.Load(filepath.Join(getAWSConfigPath(), "config"))
+	if err != nil {
+		return compiler.StatusNeedsApply, nil //nolint:nilerr // File not existing means we need to apply
+	}
+	section, err := cfg.GetSection("sso-session " + s.session.Name)
+	if err != nil {
+		return compiler.StatusNeedsApply, nil //nolint:nilerr // Section not existing means we need to apply
+	}
+	for key, value := range s.settings() {
+		if section.Key(key).String() != value {
+			return compiler.StatusNeedsApply, nil
+		}
+	}
+	return compiler.StatusSatisfied, nil
+}
+
+// Plan returns the diff for this step.
+func (s *SSOSessionStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
+	return compiler.NewDiff(compiler.DiffTypeModify, "sso-session", s.session.Name, "", s.session.StartURL), nil
+}
+
+// Apply writes the session section, keeping the rest of the config file.
+func (s *SSOSessionStep) Apply(_ compiler.RunContext) error {
+	path := filepath.Join(getAWSConfigPath(), "config")
+	return updateINI(path, "sso-session "+s.session.Name, s.settings(), 0o600)
+}
+
+// Explain provides a human-readable explanation.
+func (s *SSOSessionStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
+	return compiler.NewExplanation(
+		"Configure AWS SSO Session",
+		fmt.Sprintf("Creates the %s SSO session for %s", s.session.Name, s.session.StartURL),
+		[]string{
+			"https://docs.aws.amazon.com/cli/latest/userguide/sso-configure-profile-token.html",
+		},
+	).WithTradeoffs([]string{
+		"+ One login refreshes tokens for every profile using the session",
+		"+ Tokens refresh automatically",
+		"- Requires AWS CLI v2.9 or later",
+	})
+}
+
+// writeCredentials resolves the profile's access keys from the secret
+// backend and writes them to ~/.aws/credentials.
+func (s *ProfileStep) writeCredentials(ctx compiler.RunContext) error {
+	accessKey, err := resolveSecret(ctx, s.runner, s.profile.AccessKeyRef)
+	if err != nil {
+		return err
+	}
+	secretKey, err := resolveSecret(ctx, s.runner, s.profile.SecretKeyRef)
+	if err != nil {
+		return err
+	}
+	return updateINI(credentialsPath(), s.profile.Name, map[string]string{
+		"aws_access_key_id":     accessKey,
+		"aws_secret_access_key": secretKey,
+	}, 0o600)
+}
+
+// hasCredentials reports whether ~/.aws/credentials has keys for profile.
+func hasCredentials(profile string) bool {
+	cfg, err := ini.Load(credentialsPath())
+	if err != nil {
+		return false
+	}
+	section, err := cfg.GetSection(profile)
+	return err == nil && section.HasKey("aws_access_key_id")
+}
+
+func credentialsPath() string {
+	if path := os.Getenv("AWS_SHARED_CREDENTIALS_FILE"); path != "" {
+		return path
+	}
+	return filepath.Join(getAWSConfigPath(), "credentials")
+}
+
+// updateINI sets keys in section of the INI file at path, creating both
+// as needed.
+func updateINI(path, section string, keys map[string]string, perm os.FileMode) error {
+	cfg, err := ini.Load(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		cfg = ini.Empty()
+	}
+	sec := cfg.Section(section)
+	for key, value := range keys {
+		sec.Key(key).SetValue(value)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	_, err = cfg.WriteTo(f)
+	return err
+}
+
+// resolveSecret reads a secret://backend/key reference with
+// 'preflight secrets get'.
+func resolveSecret(ctx compiler.RunContext, runner ports.CommandRunner, ref string) (string, error) {
+	backend, key, ok := strings.Cut(strings.TrimPrefix(ref, "secret://"), "/")
+	if !ok {
+		return "", fmt.Errorf("invalid secret reference %q", ref)
+	}
+	result, err := runner.Run(ctx.Context(), secretutil.Command(), "secrets", "get", "--backend", backend, key)
+	if err != nil {
+		return "", err
+	}
+	if !result.Success() {
+		return "", fmt.Errorf("failed to resolve secret %s: %s", ref, strings.TrimSpace(result.Stderr))
+	}
+	return strings.TrimRight(result.Stdout, "\r\n"), nil
+}
+
+var _ compiler.Step = (*SSOSessionStep)(nil)
@@ -0,0 +1,93 @@
+package aws_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/provider/aws"
+	"github.com/felixgeelhaar/preflight/internal/testutil/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSSOSessionStep_ApplyAndCheck(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".aws"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".aws", "config"), []byte("[profile dev]\nregion = eu-west-1\n"), 0o600))
+
+	step := aws.NewSSOSessionStep(aws.SSOSession{Name: "corp", StartURL: "https://corp.awsapps.com/start", Region: "eu-west-1"}, mocks.NewCommandRunner())
+	ctx := compiler.NewRunContext(context.TODO())
+	assert.Equal(t, "aws:sso-session:corp", step.ID().String())
+
+	status, err := step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+
+	require.NoError(t, step.Apply(ctx))
+	data, err := os.ReadFile(filepath.Join(home, ".aws", "config"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "[profile dev]")
+	assert.Contains(t, string(data), "[sso-session corp]")
+
+	status, err = step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, status)
+}
+
+func TestProfileStep_Apply_SecretCredentials(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "")
+
+	exe, err := os.Executable()
+	require.NoError(t, err)
+	runner := mocks.NewCommandRunner()
+	runner.AddResult(exe, []string{"secrets", "get", "--backend", "1password", "aws-ci-key-id"}, ports.CommandResult{Stdout: "AKIAEXAMPLE\n"})
+	runner.AddResult(exe, []string{"secrets", "get", "--backend", "1password", "aws-ci-secret"}, ports.CommandResult{Stdout: "s3cr3t\n"})
+
+	step := aws.NewProfileStep(aws.Profile{
+		Name:         "ci",
+		AccessKeyRef: "secret://1password/aws-ci-key-id",
+		SecretKeyRef: "secret://1password/aws-ci-secret",
+	}, runner)
+	require.NoError(t, step.Apply(compiler.NewRunContext(context.TODO())))
+
+	path := filepath.Join(home, ".aws", "credentials")
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "[ci]")
+	assert.Contains(t, string(data), "AKIAEXAMPLE")
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestParseConfig_SSOSessionsAndCredentials(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := aws.ParseConfig(map[string]interface{}{
+		"sso_sessions": []interface{}{
+			map[string]interface{}{"name": "corp", "sso_start_url": "https://corp.awsapps.com/start", "sso_region": "eu-west-1"},
+		},
+		"sso": []interface{}{
+			map[string]interface{}{"profile_name": "prod", "sso_session": "corp", "sso_account_id": "123", "sso_role_name": "Admin"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "corp", cfg.SSOSessions[0].Name)
+	assert.Equal(t, "corp", cfg.SSO[0].SSOSession)
+
+	_, err = aws.ParseConfig(map[string]interface{}{
+		"profiles": []interface{}{map[string]interface{}{"name": "ci", "access_key_ref": "secret://env/KEY"}},
+	})
+	require.Error(t, err)
+	_, err = aws.ParseConfig(map[string]interface{}{
+		"profiles": []interface{}{map[string]interface{}{"name": "ci", "access_key_ref": "AKIA", "secret_key_ref": "plain"}},
+	})
+	require.Error(t, err)
+}
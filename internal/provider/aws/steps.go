@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
@@ -64,13 +65,40 @@ func (s *ProfileStep) Check(_ compiler.RunContext) (compiler.StepStatus, error)
 	}
 
 	// Check if key settings match
-	if s.profile.Region != "" && section.Key("region").String() != s.profile.Region {
+	for key, value := range s.settings() {
+		if section.Key(key).String() != value {
+			return compiler.StatusNeedsApply, nil
+		}
+	}
+
+	if s.profile.AccessKeyRef != "" && !hasCredentials(s.profile.Name) {
 		return compiler.StatusNeedsApply, nil
 	}
 
 	return compiler.StatusSatisfied, nil
 }
 
+// settings returns the declared profile settings keyed by config name.
+func (s *ProfileStep) settings() map[string]string {
+	settings := make(map[string]string)
+	for key, value := range map[string]string{
+		"region":         s.profile.Region,
+		"output":         s.profile.Output,
+		"role_arn":       s.profile.RoleArn,
+		"source_profile": s.profile.SourceProfile,
+		"mfa_serial":     s.profile.MFASerial,
+		"external_id":    s.profile.ExternalID,
+	} {
+		if value != "" {
+			settings[key] = value
+		}
+	}
+	if s.profile.DurationSeconds > 0 {
+		settings["duration_seconds"] = strconv.Itoa(s.profile.DurationSeconds)
+	}
+	return settings
+}
+
 // Plan returns the diff for this step.
 func (s *ProfileStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
 	return compiler.NewDiff(compiler.DiffTypeModify, "profile", s.profile.Name, "", s.profile.Region), nil
@@ -119,6 +147,30 @@ func (s *ProfileStep) Apply(ctx compiler.RunContext) error {
 		}
 	}
 
+	extra := []struct{ key, value string }{
+		{"mfa_serial", s.profile.MFASerial},
+		{"external_id", s.profile.ExternalID},
+	}
+	if s.profile.DurationSeconds > 0 {
+		extra = append(extra, struct{ key, value string }{"duration_seconds", strconv.Itoa(s.profile.DurationSeconds)})
+	}
+	for _, setting := range extra {
+		if setting.value == "" {
+			continue
+		}
+		result, err := s.runner.Run(ctx.Context(), "aws", "configure", "set", setting.key, setting.value, "--profile", s.profile.Name)
+		if err != nil {
+			return err
+		}
+		if !result.Success() {
+			return fmt.Errorf("aws configure set %s failed: %s", setting.key, result.Stderr)
+		}
+	}
+
+	if s.profile.AccessKeyRef != "" {
+		return s.writeCredentials(ctx)
+	}
+
 	return nil
 }
 
@@ -180,7 +232,11 @@ func (s *SSOStep) Check(_ compiler.RunContext) (compiler.StepStatus, error) {
 	}
 
 	// Check SSO configuration
-	if section.Key("sso_start_url").String() != s.sso.SSOStartURL {
+	if s.sso.SSOSession != "" {
+		if section.Key("sso_session").String() != s.sso.SSOSession {
+			return compiler.StatusNeedsApply, nil
+		}
+	} else if section.Key("sso_start_url").String() != s.sso.SSOStartURL {
 		return compiler.StatusNeedsApply, nil
 	}
 
@@ -189,7 +245,15 @@ func (s *SSOStep) Check(_ compiler.RunContext) (compiler.StepStatus, error) {
 
 // Plan returns the diff for this step.
 func (s *SSOStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
-	return compiler.NewDiff(compiler.DiffTypeModify, "sso", s.sso.ProfileName, "", s.sso.SSOStartURL), nil
+	return compiler.NewDiff(compiler.DiffTypeModify, "sso", s.sso.ProfileName, "", s.target()), nil
+}
+
+// target describes where the profile signs in: its session or start URL.
+func (s *SSOStep) target() string {
+	if s.sso.SSOSession != "" {
+		return "sso-session " + s.sso.SSOSession
+	}
+	return s.sso.SSOStartURL
 }
 
 // Apply creates or updates the SSO profile.
@@ -197,6 +261,7 @@ func (s *SSOStep) Apply(ctx compiler.RunContext) error {
 	profile := s.sso.ProfileName
 
 	settings := map[string]string{
+		"sso_session":    s.sso.SSOSession,
 		"sso_start_url":  s.sso.SSOStartURL,
 		"sso_region":     s.sso.SSORegion,
 		"sso_account_id": s.sso.SSOAccountID,
@@ -225,7 +290,7 @@ func (s *SSOStep) Apply(ctx compiler.RunContext) error {
 func (s *SSOStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
 	return compiler.NewExplanation(
 		"Configure AWS SSO Profile",
-		fmt.Sprintf("Creates SSO profile %s pointing to %s", s.sso.ProfileName, s.sso.SSOStartURL),
+		fmt.Sprintf("Creates SSO profile %s pointing to %s", s.sso.ProfileName, s.target()),
 		[]string{
 			"https://docs.aws.amazon.com/cli/latest/userguide/cli-configure-sso.html",
 		},
@@ -0,0 +1,104 @@
+package azure
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Config represents the azure section of the configuration.
+type Config struct {
+	Accounts []Account
+}
+
+// Account is an Azure subscription the az CLI should have access to.
+type Account struct {
+	// Name identifies the account in step IDs
+	Name string
+	// Subscription is the subscription name or ID (default: Name)
+	Subscription string
+	Tenant       string
+	// Default makes this the subscription az uses by default
+	Default bool
+	// ClientID and either ClientCertificateRef or ClientSecretRef sign in
+	// as a service principal; both are secret references
+	// (secret://backend/key). The certificate is a PEM holding the
+	// certificate and its private key.
+	ClientID             string
+	ClientSecretRef      string
+	ClientCertificateRef string
+}
+
+// ParseConfig parses the azure configuration from a raw map.
+func ParseConfig(raw map[string]interface{}) (*Config, error) {
+	cfg := &Config{Accounts: make([]Account, 0)}
+
+	if accounts, ok := raw["accounts"].([]interface{}); ok {
+		defaults := 0
+		for _, a := range accounts {
+			account, err := parseAccount(a)
+			if err != nil {
+				return nil, err
+			}
+			if account.Default {
+				defaults++
+			}
+			cfg.Accounts = append(cfg.Accounts, account)
+		}
+		if defaults > 1 {
+			return nil, fmt.Errorf("only one azure account can be the default")
+		}
+	}
+
+	return cfg, nil
+}
+
+func parseAccount(raw interface{}) (Account, error) {
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		return Account{}, fmt.Errorf("account must be an object")
+	}
+
+	account := Account{}
+	if name, ok := m["name"].(string); ok && name != "" {
+		account.Name = name
+	} else {
+		return Account{}, fmt.Errorf("account must have a name")
+	}
+	account.Subscription = account.Name
+	if subscription, ok := m["subscription"].(string); ok && subscription != "" {
+		account.Subscription = subscription
+	}
+	if tenant, ok := m["tenant"].(string); ok {
+		account.Tenant = tenant
+	}
+	if isDefault, ok := m["default"].(bool); ok {
+		account.Default = isDefault
+	}
+	if clientID, ok := m["client_id"].(string); ok {
+		account.ClientID = clientID
+	}
+	if secretRef, ok := m["client_secret_ref"].(string); ok {
+		account.ClientSecretRef = secretRef
+	}
+	if certificateRef, ok := m["client_certificate_ref"].(string); ok {
+		account.ClientCertificateRef = certificateRef
+	}
+
+	if account.ClientSecretRef != "" && account.ClientCertificateRef != "" {
+		return Account{}, fmt.Errorf("account %s: set only one of client_secret_ref and client_certificate_ref", account.Name)
+	}
+	credential := account.ClientSecretRef + account.ClientCertificateRef
+	if (account.ClientID == "") != (credential == "") {
+		return Account{}, fmt.Errorf("account %s: client_id requires client_certificate_ref or client_secret_ref, and they require client_id", account.Name)
+	}
+	if credential != "" {
+		if !strings.HasPrefix(credential, "secret://") {
+			return Account{}, fmt.Errorf("account %s: service principal credentials must be secret references (secret://backend/key)", account.Name)
+		}
+		if account.Tenant == "" {
+			return Account{}, fmt.Errorf("account %s: a service principal requires a tenant", account.Name)
+		}
+	}
+
+	return account, nil
+}
@@ -0,0 +1,48 @@
+package azure_test
+
+import (
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/provider/azure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfig(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := azure.ParseConfig(map[string]interface{}{
+		"accounts": []interface{}{
+			map[string]interface{}{"name": "prod", "subscription": "0000-1111", "tenant": "acme.onmicrosoft.com", "default": true},
+			map[string]interface{}{"name": "ci", "tenant": "acme.onmicrosoft.com", "client_id": "app-id", "client_secret_ref": "secret://1password/az-ci"},
+			map[string]interface{}{"name": "deploy", "tenant": "acme.onmicrosoft.com", "client_id": "app-id", "client_certificate_ref": "secret://1password/az-deploy"},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, cfg.Accounts, 3)
+	assert.Equal(t, "0000-1111", cfg.Accounts[0].Subscription)
+	assert.Equal(t, "ci", cfg.Accounts[1].Subscription)
+	assert.Equal(t, "secret://1password/az-deploy", cfg.Accounts[2].ClientCertificateRef)
+}
+
+func TestParseConfig_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string][]interface{}{
+		"missing name":   {map[string]interface{}{"tenant": "t"}},
+		"plain secret":   {map[string]interface{}{"name": "ci", "tenant": "t", "client_id": "id", "client_secret_ref": "hunter2"}},
+		"secret only":    {map[string]interface{}{"name": "ci", "tenant": "t", "client_secret_ref": "secret://env/AZ"}},
+		"sp sans tenant": {map[string]interface{}{"name": "ci", "client_id": "id", "client_secret_ref": "secret://env/AZ"}},
+		"plain cert":     {map[string]interface{}{"name": "ci", "tenant": "t", "client_id": "id", "client_certificate_ref": "cert.pem"}},
+		"secret and cert": {map[string]interface{}{"name": "ci", "tenant": "t", "client_id": "id",
+			"client_secret_ref": "secret://env/AZ", "client_certificate_ref": "secret://env/AZ_CERT"}},
+		"two defaults": {
+			map[string]interface{}{"name": "a", "default": true},
+			map[string]interface{}{"name": "b", "default": true},
+		},
+	}
+	for name, accounts := range tests {
+		_, err := azure.ParseConfig(map[string]interface{}{"accounts": accounts})
+		assert.Error(t, err, name)
+	}
+}
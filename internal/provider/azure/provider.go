@@ -0,0 +1,45 @@
+// Package azure provides the Azure CLI provider for subscriptions and service principals.
+package azure
+
+import (
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+)
+
+// Provider implements the compiler.Provider interface for the az CLI.
+type Provider struct {
+	runner ports.CommandRunner
+}
+
+// NewProvider creates a new Azure provider.
+func NewProvider(runner ports.CommandRunner) *Provider {
+	return &Provider{runner: runner}
+}
+
+// Name returns the provider name.
+func (p *Provider) Name() string {
+	return "azure"
+}
+
+// Compile transforms azure configuration into executable steps.
+func (p *Provider) Compile(ctx compiler.CompileContext) ([]compiler.Step, error) {
+	rawConfig := ctx.GetSection("azure")
+	if rawConfig == nil {
+		return nil, nil
+	}
+
+	cfg, err := ParseConfig(rawConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	steps := make([]compiler.Step, 0, len(cfg.Accounts))
+	for _, account := range cfg.Accounts {
+		steps = append(steps, NewAccountStep(account, p.runner))
+	}
+
+	return steps, nil
+}
+
+// Ensure Provider implements compiler.Provider.
+var _ compiler.Provider = (*Provider)(nil)
@@ -0,0 +1,34 @@
+package azure_test
+
+import (
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/provider/azure"
+	"github.com/felixgeelhaar/preflight/internal/testutil/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvider_Name(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "azure", azure.NewProvider(mocks.NewCommandRunner()).Name())
+}
+
+func TestProvider_Compile(t *testing.T) {
+	t.Parallel()
+
+	p := azure.NewProvider(mocks.NewCommandRunner())
+	steps, err := p.Compile(compiler.NewCompileContext(map[string]interface{}{}))
+	require.NoError(t, err)
+	assert.Nil(t, steps)
+
+	steps, err = p.Compile(compiler.NewCompileContext(map[string]interface{}{
+		"azure": map[string]interface{}{
+			"accounts": []interface{}{map[string]interface{}{"name": "prod", "default": true}},
+		},
+	}))
+	require.NoError(t, err)
+	require.Len(t, steps, 1)
+	assert.Equal(t, "azure:account:prod", steps[0].ID().String())
+}
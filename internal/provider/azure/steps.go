@@ -0,0 +1,160 @@
+package azure
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/provider/secretutil"
+)
+
+// AccountStep makes a subscription available to the az CLI, signing in as
+// a service principal when one is declared, and optionally selects it as
+// the default subscription.
+type AccountStep struct {
+	account Account
+	id      compiler.StepID
+	runner  ports.CommandRunner
+}
+
+// NewAccountStep creates a new AccountStep.
+func NewAccountStep(account Account, runner ports.CommandRunner) *AccountStep {
+	return &AccountStep{
+		account: account,
+		id:      compiler.MustNewStepID("azure:account:" + account.Name),
+		runner:  runner,
+	}
+}
+
+// ID returns the step identifier.
+func (s *AccountStep) ID() compiler.StepID {
+	return s.id
+}
+
+// DependsOn returns the step dependencies.
+func (s *AccountStep) DependsOn() []compiler.StepID {
+	return nil
+}
+
+// show returns whether az can see the subscription and whether it is the default.
+func (s *AccountStep) show(ctx compiler.RunContext) (found, isDefault bool) {
+	result, err := s.runner.Run(ctx.Context(), "az", "account", "show", "--subscription", s.account.Subscription, "--output", "json")
+	if err != nil || !result.Success() {
+		return false, false
+	}
+	var account struct {
+		IsDefault bool `json:"isDefault"`
+	}
+	_ = json.Unmarshal([]byte(result.Stdout), &account)
+	return true, account.IsDefault
+}
+
+// Check determines if the subscription is accessible (and the default, if declared).
+func (s *AccountStep) Check(ctx compiler.RunContext) (compiler.StepStatus, error) {
+	found, isDefault := s.show(ctx)
+	if !found || (s.account.Default && !isDefault) {
+		return compiler.StatusNeedsApply, nil
+	}
+	return compiler.StatusSatisfied, nil
+}
+
+// Plan returns the diff for this step.
+func (s *AccountStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
+	return compiler.NewDiff(compiler.DiffTypeModify, "account", s.account.Name, "", s.account.Subscription), nil
+}
+
+// Apply signs in if needed and selects the default subscription.
+func (s *AccountStep) Apply(ctx compiler.RunContext) error {
+	if found, _ := s.show(ctx); !found {
+		if s.account.ClientID == "" {
+			login := "az login"
+			if s.account.Tenant != "" {
+				login += " --tenant " + s.account.Tenant
+			}
+			return fmt.Errorf("subscription %s is not accessible; sign in with '%s'", s.account.Subscription, login)
+		}
+		if err := s.loginServicePrincipal(ctx); err != nil {
+			return err
+		}
+	}
+
+	if s.account.Default {
+		result, err := s.runner.Run(ctx.Context(), "az", "account", "set", "--subscription", s.account.Subscription)
+		if err != nil {
+			return err
+		}
+		if !result.Success() {
+			return fmt.Errorf("az account set failed: %s", result.Stderr)
+		}
+	}
+	return nil
+}
+
+// loginServicePrincipal signs in as the service principal, with its
+// certificate when one is declared and its client secret otherwise.
+func (s *AccountStep) loginServicePrincipal(ctx compiler.RunContext) error {
+	ref := s.account.ClientCertificateRef
+	if ref == "" {
+		ref = s.account.ClientSecretRef
+	}
+	backend, key, ok := strings.Cut(strings.TrimPrefix(ref, "secret://"), "/")
+	if !ok {
+		return fmt.Errorf("invalid secret reference %q", ref)
+	}
+	secretsGet := fmt.Sprintf("%s secrets get --backend %s %s",
+		shellQuote(secretutil.Command()), shellQuote(backend), shellQuote(key))
+	login := fmt.Sprintf("az login --service-principal --username %s --tenant %s",
+		shellQuote(s.account.ClientID), shellQuote(s.account.Tenant))
+
+	var script string
+	if s.account.ClientCertificateRef != "" {
+		// The PEM is written to a private temporary file that is removed
+		// when the shell exits, so it never appears on a command line.
+		script = fmt.Sprintf(`umask 077 && cert="$(mktemp)" && trap 'rm -f "$cert"' EXIT && %s > "$cert" && %s --certificate "$cert" --output none`,
+			secretsGet, login)
+	} else {
+		// az only takes a client secret as an argument: the shell keeps it
+		// out of preflight's own arguments, but other local users can read
+		// it from the process list while az login runs.
+		script = fmt.Sprintf(`%s --password "$(%s)" --output none`, login, secretsGet)
+	}
+
+	result, err := s.runner.Run(ctx.Context(), "sh", "-c", script)
+	if err != nil {
+		return err
+	}
+	if !result.Success() {
+		return fmt.Errorf("az login --service-principal failed: %s", strings.TrimSpace(result.Stderr))
+	}
+	return nil
+}
+
+// Explain provides a human-readable explanation.
+func (s *AccountStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
+	detail := fmt.Sprintf("Ensures the az CLI can use subscription %s", s.account.Subscription)
+	if s.account.Default {
+		detail += " and makes it the default"
+	}
+	return compiler.NewExplanation(
+		"Configure Azure Account",
+		detail,
+		[]string{
+			"https://learn.microsoft.com/cli/azure/manage-azure-subscriptions-azure-cli",
+			"https://learn.microsoft.com/cli/azure/authenticate-azure-cli-service-principal",
+		},
+	).WithTradeoffs([]string{
+		"+ Consistent default subscription across machines",
+		"+ Service principal credentials stay in the secret backend",
+		"- A client secret is visible in the process list while az login runs; prefer a certificate",
+		"- Interactive accounts still need 'az login'",
+	})
+}
+
+// shellQuote single-quotes s for sh.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+var _ compiler.Step = (*AccountStep)(nil)
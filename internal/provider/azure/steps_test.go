@@ -0,0 +1,81 @@
+package azure_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/provider/azure"
+	"github.com/felixgeelhaar/preflight/internal/testutil/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountStep_Check(t *testing.T) {
+	t.Parallel()
+
+	runner := mocks.NewCommandRunner()
+	runner.AddResult("az", []string{"account", "show", "--subscription", "prod", "--output", "json"}, ports.CommandResult{
+		Stdout: `{"name": "prod", "isDefault": false}`,
+	})
+	ctx := compiler.NewRunContext(context.Background())
+
+	step := azure.NewAccountStep(azure.Account{Name: "prod", Subscription: "prod"}, runner)
+	assert.Equal(t, "azure:account:prod", step.ID().String())
+	status, err := step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, status)
+
+	step = azure.NewAccountStep(azure.Account{Name: "prod", Subscription: "prod", Default: true}, runner)
+	status, err = step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+}
+
+func TestAccountStep_Apply_InteractiveNotSignedIn(t *testing.T) {
+	t.Parallel()
+
+	step := azure.NewAccountStep(azure.Account{Name: "prod", Subscription: "prod", Tenant: "acme"}, mocks.NewCommandRunner())
+	err := step.Apply(compiler.NewRunContext(context.Background()))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "az login --tenant acme")
+}
+
+func TestAccountStep_Apply_ServicePrincipal(t *testing.T) {
+	t.Parallel()
+
+	exe, err := os.Executable()
+	require.NoError(t, err)
+	runner := mocks.NewCommandRunner()
+	runner.AddResult("sh", []string{"-c", `az login --service-principal --username 'app-id' --tenant 'acme' --password "$('` + exe +
+		`' secrets get --backend '1password' 'az-ci')" --output none`}, ports.CommandResult{})
+	runner.AddResult("az", []string{"account", "set", "--subscription", "ci"}, ports.CommandResult{})
+
+	step := azure.NewAccountStep(azure.Account{
+		Name: "ci", Subscription: "ci", Tenant: "acme", Default: true,
+		ClientID: "app-id", ClientSecretRef: "secret://1password/az-ci",
+	}, runner)
+	require.NoError(t, step.Apply(compiler.NewRunContext(context.Background())))
+}
+
+func TestAccountStep_Apply_ServicePrincipalCertificate(t *testing.T) {
+	t.Parallel()
+
+	exe, err := os.Executable()
+	require.NoError(t, err)
+	runner := mocks.NewCommandRunner()
+	runner.AddResult("sh", []string{"-c", `umask 077 && cert="$(mktemp)" && trap 'rm -f "$cert"' EXIT && '` + exe +
+		`' secrets get --backend '1password' 'az-deploy' > "$cert" && az login --service-principal --username 'app-id' --tenant 'acme' --certificate "$cert" --output none`},
+		ports.CommandResult{})
+
+	step := azure.NewAccountStep(azure.Account{
+		Name: "deploy", Subscription: "deploy", Tenant: "acme",
+		ClientID: "app-id", ClientCertificateRef: "secret://1password/az-deploy",
+	}, runner)
+	require.NoError(t, step.Apply(compiler.NewRunContext(context.Background())))
+	for _, call := range runner.Calls() {
+		assert.NotContains(t, call.Args, "--password")
+	}
+}
@@ -0,0 +1,172 @@
+package bootstrap
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	tooldeps "github.com/felixgeelhaar/preflight/internal/domain/deps"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+)
+
+// cltInstallPlaceholder makes softwareupdate list the Command Line Tools,
+// which it otherwise only offers through the xcode-select dialog.
+var cltInstallPlaceholder = "/tmp/.com.apple.dt.CommandLineTools.installondemand.in-progress"
+
+// XcodeCLTStep ensures the Xcode Command Line Tools are installed. Homebrew
+// and git need them on macOS.
+type XcodeCLTStep struct {
+	id     compiler.StepID
+	runner ports.CommandRunner
+}
+
+// NewXcodeCLTStep creates a new XcodeCLTStep.
+func NewXcodeCLTStep(runner ports.CommandRunner) *XcodeCLTStep {
+	return &XcodeCLTStep{
+		id:     compiler.MustNewStepID(tooldeps.XcodeCLTStepID),
+		runner: runner,
+	}
+}
+
+// ID returns the step identifier.
+func (s *XcodeCLTStep) ID() compiler.StepID {
+	return s.id
+}
+
+// DependsOn returns the step dependencies.
+func (s *XcodeCLTStep) DependsOn() []compiler.StepID {
+	return nil
+}
+
+// Check determines if the Command Line Tools are installed.
+func (s *XcodeCLTStep) Check(ctx compiler.RunContext) (compiler.StepStatus, error) {
+	result, err := s.runner.Run(ctx.Context(), "xcode-select", "-p")
+	if err == nil && result.Success() && strings.TrimSpace(result.Stdout) != "" {
+		return compiler.StatusSatisfied, nil
+	}
+	return compiler.StatusNeedsApply, nil
+}
+
+// Plan returns the diff for this step.
+func (s *XcodeCLTStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
+	return compiler.NewDiff(compiler.DiffTypeAdd, "bootstrap", "xcode-clt", "", "Command Line Tools"), nil
+}
+
+// Apply installs the newest Command Line Tools offered by softwareupdate.
+// When softwareupdate offers none, the xcode-select installer dialog is
+// opened instead and apply has to be run again once it has finished.
+func (s *XcodeCLTStep) Apply(ctx compiler.RunContext) error {
+	if err := os.WriteFile(cltInstallPlaceholder, nil, 0o600); err != nil {
+		return fmt.Errorf("failed to prepare Command Line Tools install: %w", err)
+	}
+	defer func() { _ = os.Remove(cltInstallPlaceholder) }()
+
+	result, err := s.runner.Run(ctx.Context(), "softwareupdate", "--list")
+	if err != nil {
+		return err
+	}
+	label := latestCLTLabel(result.Stdout + result.Stderr)
+	if label == "" {
+		if _, err := s.runner.Run(ctx.Context(), "xcode-select", "--install"); err != nil {
+			return err
+		}
+		return fmt.Errorf("softwareupdate does not offer the Command Line Tools; finish the installer that opened, then run apply again")
+	}
+
+	result, err = s.runner.Run(ctx.Context(), "sudo", "softwareupdate", "--install", label, "--verbose")
+	if err != nil {
+		return err
+	}
+	if !result.Success() {
+		return fmt.Errorf("softwareupdate --install %q failed: %s", label, result.Stderr)
+	}
+	return nil
+}
+
+// Explain provides a human-readable explanation.
+func (s *XcodeCLTStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
+	return compiler.NewExplanation(
+		"Install Xcode Command Line Tools",
+		"Installs the compilers and git that Homebrew needs on macOS.",
+		[]string{"https://developer.apple.com/xcode/resources/"},
+	)
+}
+
+// latestCLTLabel returns the label of the newest Command Line Tools in the
+// output of softwareupdate --list, or "" when none is offered.
+func latestCLTLabel(output string) string {
+	label := ""
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "*") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "*"))
+		line = strings.TrimPrefix(line, "Label: ")
+		if strings.HasPrefix(line, "Command Line Tools") {
+			label = line
+		}
+	}
+	return label
+}
+
+// RosettaStep ensures Rosetta 2 is installed on Apple Silicon, so that
+// apps and casks built for Intel Macs run.
+type RosettaStep struct {
+	id     compiler.StepID
+	runner ports.CommandRunner
+}
+
+// NewRosettaStep creates a new RosettaStep.
+func NewRosettaStep(runner ports.CommandRunner) *RosettaStep {
+	return &RosettaStep{
+		id:     compiler.MustNewStepID(tooldeps.RosettaStepID),
+		runner: runner,
+	}
+}
+
+// ID returns the step identifier.
+func (s *RosettaStep) ID() compiler.StepID {
+	return s.id
+}
+
+// DependsOn returns the step dependencies.
+func (s *RosettaStep) DependsOn() []compiler.StepID {
+	return nil
+}
+
+// Check determines if Rosetta can run Intel binaries.
+func (s *RosettaStep) Check(ctx compiler.RunContext) (compiler.StepStatus, error) {
+	result, err := s.runner.Run(ctx.Context(), "/usr/bin/arch", "-x86_64", "/usr/bin/true")
+	if err == nil && result.Success() {
+		return compiler.StatusSatisfied, nil
+	}
+	return compiler.StatusNeedsApply, nil
+}
+
+// Plan returns the diff for this step.
+func (s *RosettaStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
+	return compiler.NewDiff(compiler.DiffTypeAdd, "bootstrap", "rosetta", "", "Rosetta 2"), nil
+}
+
+// Apply installs Rosetta 2, agreeing to its license.
+func (s *RosettaStep) Apply(ctx compiler.RunContext) error {
+	result, err := s.runner.Run(ctx.Context(), "sudo", "softwareupdate", "--install-rosetta", "--agree-to-license")
+	if err != nil {
+		return err
+	}
+	if !result.Success() {
+		return fmt.Errorf("rosetta install failed: %s", result.Stderr)
+	}
+	return nil
+}
+
+// Explain provides a human-readable explanation.
+func (s *RosettaStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
+	return compiler.NewExplanation(
+		"Install Rosetta 2",
+		"Installs Rosetta 2 so that apps built for Intel Macs run on Apple Silicon.",
+		[]string{"https://support.apple.com/en-us/102527"},
+	)
+}
@@ -32,15 +32,14 @@ func (p *Provider) Name() string {
 	return "bootstrap"
 }
 
-// Compile emits toolchain bootstrap steps for tool-dependent providers.
+// Compile emits toolchain bootstrap steps for tool-dependent providers,
+// and on macOS the prerequisites Homebrew and Intel apps need.
 func (p *Provider) Compile(ctx compiler.CompileContext) ([]compiler.Step, error) {
 	tools := requiredTools(ctx)
-	if len(tools) == 0 {
-		return nil, nil
-	}
 
-	steps := make([]compiler.Step, 0, len(tools))
+	steps := make([]compiler.Step, 0, len(tools)+2)
 	added := make(map[string]struct{})
+	p.ensurePrerequisites(ctx, tools, &steps, added)
 
 	for _, tool := range tools {
 		plan, ok := tooldeps.ResolveToolBootstrap(ctx, p.platform, tool)
@@ -60,6 +59,33 @@ func (p *Provider) Compile(ctx compiler.CompileContext) ([]compiler.Step, error)
 	return steps, nil
 }
 
+// ensurePrerequisites adds the Xcode Command Line Tools when Homebrew is
+// used on macOS, and Rosetta 2 when casks are installed on Apple Silicon.
+func (p *Provider) ensurePrerequisites(ctx compiler.CompileContext, tools []tooldeps.Tool, steps *[]compiler.Step, added map[string]struct{}) {
+	if p.platform == nil || !p.platform.IsMacOS() {
+		return
+	}
+	if p.usesBrew(ctx, tools) {
+		appendStep(steps, added, NewXcodeCLTStep(p.runner))
+	}
+	if p.platform.Arch() == "arm64" && hasList(ctx.GetSection("brew"), "casks") {
+		appendStep(steps, added, NewRosettaStep(p.runner))
+	}
+}
+
+// usesBrew reports whether Homebrew is configured or installs a toolchain.
+func (p *Provider) usesBrew(ctx compiler.CompileContext, tools []tooldeps.Tool) bool {
+	if managerConfigured(ctx, "brew") {
+		return true
+	}
+	for _, tool := range tools {
+		if plan, ok := tooldeps.ResolveToolBootstrap(ctx, p.platform, tool); ok && plan.Manager == "brew" {
+			return true
+		}
+	}
+	return false
+}
+
 func requiredTools(ctx compiler.CompileContext) []tooldeps.Tool {
 	tools := make([]tooldeps.Tool, 0, 5)
 
@@ -86,7 +112,7 @@ func (p *Provider) ensureManagerSteps(ctx compiler.CompileContext, manager strin
 	switch manager {
 	case "brew":
 		if !managerConfigured(ctx, "brew") {
-			appendStep(steps, added, brew.NewInstallStep(p.runner))
+			appendStep(steps, added, brew.NewInstallStep(p.runner, brew.InstallPrerequisites(p.platform)...))
 		}
 		return compiler.MustNewStepID("brew:install")
 	case "apt":
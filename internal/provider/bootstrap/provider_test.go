@@ -42,10 +42,11 @@ func TestProviderCompile_ImplicitToolBootstrap(t *testing.T) {
 	provider := NewProvider(runner, plat)
 	steps, err := provider.Compile(ctx)
 	require.NoError(t, err)
-	require.Len(t, steps, 2)
+	require.Len(t, steps, 3)
 
-	ids := []string{steps[0].ID().String(), steps[1].ID().String()}
-	require.ElementsMatch(t, []string{"brew:install", "bootstrap:tool:node"}, ids)
+	ids := []string{steps[0].ID().String(), steps[1].ID().String(), steps[2].ID().String()}
+	require.ElementsMatch(t, []string{"bootstrap:xcode-clt", "brew:install", "bootstrap:tool:node"}, ids)
+	require.Equal(t, []compiler.StepID{compiler.MustNewStepID("bootstrap:xcode-clt")}, steps[1].DependsOn())
 }
 
 func TestProviderCompile_ExplicitToolConfigured(t *testing.T) {
@@ -64,7 +65,31 @@ func TestProviderCompile_ExplicitToolConfigured(t *testing.T) {
 	provider := NewProvider(runner, plat)
 	steps, err := provider.Compile(ctx)
 	require.NoError(t, err)
-	require.Len(t, steps, 0)
+	require.Len(t, steps, 1)
+	require.Equal(t, "bootstrap:xcode-clt", steps[0].ID().String(), "Homebrew still needs the Command Line Tools")
+}
+
+func TestProviderCompile_MacOSPrerequisites(t *testing.T) {
+	cfg := map[string]interface{}{
+		"brew": map[string]interface{}{
+			"casks": []interface{}{"docker"},
+		},
+	}
+	runner := mocks.NewCommandRunner()
+
+	ids := func(plat *platform.Platform) []string {
+		steps, err := NewProvider(runner, plat).Compile(compiler.NewCompileContext(cfg))
+		require.NoError(t, err)
+		var result []string
+		for _, step := range steps {
+			result = append(result, step.ID().String())
+		}
+		return result
+	}
+
+	require.Equal(t, []string{"bootstrap:xcode-clt", "bootstrap:rosetta"}, ids(platform.New(platform.OSDarwin, "arm64", platform.EnvNative)))
+	require.Equal(t, []string{"bootstrap:xcode-clt"}, ids(platform.New(platform.OSDarwin, "amd64", platform.EnvNative)))
+	require.Empty(t, ids(platform.New(platform.OSLinux, "arm64", platform.EnvNative)))
 }
 
 func TestRequiredTools(t *testing.T) {
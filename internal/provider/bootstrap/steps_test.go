@@ -2,7 +2,9 @@ package bootstrap
 
 import (
 	"context"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"testing"
 
 	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
@@ -177,3 +179,54 @@ func TestToolStep_Apply_CommandNotFound(t *testing.T) {
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "not found in PATH")
 }
+
+func TestXcodeCLTStep_Check(t *testing.T) {
+	runner := mocks.NewCommandRunner()
+	runner.AddResult("xcode-select", []string{"-p"}, ports.CommandResult{Stdout: "/Library/Developer/CommandLineTools\n"})
+	step := NewXcodeCLTStep(runner)
+
+	status, err := step.Check(compiler.NewRunContext(context.Background()))
+	require.NoError(t, err)
+	require.Equal(t, compiler.StatusSatisfied, status)
+
+	runner = mocks.NewCommandRunner()
+	runner.AddResult("xcode-select", []string{"-p"}, ports.CommandResult{ExitCode: 2, Stderr: "unable to get active developer directory"})
+	status, err = NewXcodeCLTStep(runner).Check(compiler.NewRunContext(context.Background()))
+	require.NoError(t, err)
+	require.Equal(t, compiler.StatusNeedsApply, status)
+}
+
+func TestXcodeCLTStep_Apply(t *testing.T) {
+	cltInstallPlaceholder = filepath.Join(t.TempDir(), "clt-in-progress")
+	runner := mocks.NewCommandRunner()
+	runner.AddResult("softwareupdate", []string{"--list"}, ports.CommandResult{Stdout: `Software Update found the following new or updated software:
+* Label: Command Line Tools for Xcode-15.3
+	Title: Command Line Tools for Xcode, Version: 15.3, Size: 707501KiB, Recommended: YES,
+* Label: Command Line Tools for Xcode-16.0
+	Title: Command Line Tools for Xcode, Version: 16.0, Size: 751000KiB, Recommended: YES,
+`})
+	runner.AddResult("sudo", []string{"softwareupdate", "--install", "Command Line Tools for Xcode-16.0", "--verbose"}, ports.CommandResult{})
+
+	require.NoError(t, NewXcodeCLTStep(runner).Apply(compiler.NewRunContext(context.Background())))
+	_, err := os.Stat(cltInstallPlaceholder)
+	require.True(t, os.IsNotExist(err), "the placeholder is removed")
+
+	runner = mocks.NewCommandRunner()
+	runner.AddResult("softwareupdate", []string{"--list"}, ports.CommandResult{Stderr: "No new software available.\n"})
+	runner.AddResult("xcode-select", []string{"--install"}, ports.CommandResult{})
+	err = NewXcodeCLTStep(runner).Apply(compiler.NewRunContext(context.Background()))
+	require.ErrorContains(t, err, "run apply again")
+}
+
+func TestRosettaStep(t *testing.T) {
+	runner := mocks.NewCommandRunner()
+	runner.AddResult("/usr/bin/arch", []string{"-x86_64", "/usr/bin/true"}, ports.CommandResult{ExitCode: 1, Stderr: "Bad CPU type in executable"})
+	runner.AddResult("sudo", []string{"softwareupdate", "--install-rosetta", "--agree-to-license"}, ports.CommandResult{})
+	step := NewRosettaStep(runner)
+	ctx := compiler.NewRunContext(context.Background())
+
+	status, err := step.Check(ctx)
+	require.NoError(t, err)
+	require.Equal(t, compiler.StatusNeedsApply, status)
+	require.NoError(t, step.Apply(ctx))
+}
@@ -3,6 +3,7 @@ package brew
 
 import (
 	"fmt"
+	"strings"
 )
 
 // Config represents the brew section of the configuration.
@@ -17,6 +18,7 @@ type Formula struct {
 	Name string
 	Tap  string   // Optional: specific tap (e.g., "homebrew/core")
 	Args []string // Optional: install arguments (e.g., "--HEAD")
+	Arch string   // Optional: arm64 or x86_64, the prefix to install under
 }
 
 // FullName returns the fully qualified formula name.
@@ -94,6 +96,31 @@ func ParseConfig(raw map[string]interface{}) (*Config, error) {
 		}
 	}
 
+	// Parse architecture constraints
+	if arch, ok := raw["arch"]; ok {
+		archMap, ok := arch.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("arch must be a map of formula to architecture")
+		}
+		for name, value := range archMap {
+			archStr, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("arch of %s must be a string", name)
+			}
+			for i := range cfg.Formulae {
+				f := cfg.Formulae[i]
+				if f.Name == name || f.FullName() == name || strings.HasSuffix(f.FullName(), "/"+name) {
+					cfg.Formulae[i].Arch = archStr
+				}
+			}
+		}
+	}
+	for _, formula := range cfg.Formulae {
+		if formula.Arch != "" && PrefixForArch(formula.Arch) == "" {
+			return nil, fmt.Errorf("arch of %s must be %s or %s, got %q", formula.Name, ArchARM, ArchIntel, formula.Arch)
+		}
+	}
+
 	return cfg, nil
 }
 
@@ -112,6 +139,9 @@ func parseFormula(raw interface{}) (Formula, error) {
 		if tap, ok := v["tap"].(string); ok {
 			formula.Tap = tap
 		}
+		if arch, ok := v["arch"].(string); ok {
+			formula.Arch = arch
+		}
 		if args, ok := v["args"].([]interface{}); ok {
 			for _, arg := range args {
 				if argStr, ok := arg.(string); ok {
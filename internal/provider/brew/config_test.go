@@ -300,3 +300,33 @@ func TestParseConfig_FormulaWithTap(t *testing.T) {
 		t.Errorf("Formulae[0].Tap = %q, want %q", cfg.Formulae[0].Tap, "homebrew/core")
 	}
 }
+
+func TestParseConfig_FormulaArch(t *testing.T) {
+	raw := map[string]interface{}{
+		"formulae": []interface{}{
+			"node",
+			"homebrew/core/legacy-tool",
+			map[string]interface{}{"name": "gcc", "arch": "arm64"},
+		},
+		"arch": map[string]interface{}{"legacy-tool": "x86_64"},
+	}
+	cfg, err := ParseConfig(raw)
+	if err != nil {
+		t.Fatalf("ParseConfig() error = %v", err)
+	}
+	got := map[string]string{}
+	for _, f := range cfg.Formulae {
+		got[f.Name] = f.Arch
+	}
+	want := map[string]string{"node": "", "homebrew/core/legacy-tool": "x86_64", "gcc": "arm64"}
+	for name, arch := range want {
+		if got[name] != arch {
+			t.Errorf("Arch of %s = %q, want %q", name, got[name], arch)
+		}
+	}
+
+	raw["arch"] = map[string]interface{}{"node": "ppc"}
+	if _, err := ParseConfig(raw); err == nil {
+		t.Error("ParseConfig() should reject an unknown architecture")
+	}
+}
@@ -0,0 +1,73 @@
+package brew
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Homebrew prefixes on Apple Silicon and Intel Macs. A Mac migrated from an
+// Intel machine, or one running Intel-only formulae under Rosetta, can have
+// both.
+const (
+	PrefixARM   = "/opt/homebrew"
+	PrefixIntel = "/usr/local"
+)
+
+// Architectures a formula can be constrained to with arch:.
+const (
+	ArchARM   = "arm64"
+	ArchIntel = "x86_64"
+)
+
+// dualPrefixes are the prefixes InstalledPrefixes looks for.
+var dualPrefixes = []string{PrefixARM, PrefixIntel}
+
+// PrefixForArch returns the prefix of the Homebrew that installs formulae
+// for arch, or "" for an unknown architecture.
+func PrefixForArch(arch string) string {
+	switch arch {
+	case ArchARM:
+		return PrefixARM
+	case ArchIntel:
+		return PrefixIntel
+	default:
+		return ""
+	}
+}
+
+// ArchForPrefix returns the architecture the Homebrew in prefix installs
+// formulae for.
+func ArchForPrefix(prefix string) string {
+	if prefix == PrefixARM {
+		return ArchARM
+	}
+	return ArchIntel
+}
+
+// BrewPath returns the brew executable under prefix.
+func BrewPath(prefix string) string {
+	return filepath.Join(prefix, "bin", "brew")
+}
+
+// InstalledPrefixes returns the Apple Silicon and Intel prefixes that
+// have Homebrew installed.
+func InstalledPrefixes() []string {
+	var prefixes []string
+	for _, prefix := range dualPrefixes {
+		if info, err := os.Stat(BrewPath(prefix)); err == nil && !info.IsDir() {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	return prefixes
+}
+
+// Command returns the command and arguments that run brew with args for
+// arch. Without an architecture it is the brew on PATH; otherwise it is
+// the brew of the matching prefix, run under that architecture.
+func Command(arch string, args ...string) (string, []string) {
+	prefix := PrefixForArch(arch)
+	if prefix == "" {
+		return "brew", args
+	}
+	return "arch", append([]string{"-" + arch, BrewPath(prefix)}, args...)
+}
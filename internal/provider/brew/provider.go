@@ -2,12 +2,15 @@ package brew
 
 import (
 	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	tooldeps "github.com/felixgeelhaar/preflight/internal/domain/deps"
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
 	"github.com/felixgeelhaar/preflight/internal/ports"
 )
 
 // Provider implements the compiler.Provider interface for Homebrew.
 type Provider struct {
-	runner ports.CommandRunner
+	runner   ports.CommandRunner
+	platform *platform.Platform
 }
 
 // NewProvider creates a new Homebrew provider.
@@ -15,6 +18,22 @@ func NewProvider(runner ports.CommandRunner) *Provider {
 	return &Provider{runner: runner}
 }
 
+// WithPlatform makes the Homebrew install wait for the prerequisites the
+// bootstrap provider installs on plat.
+func (p *Provider) WithPlatform(plat *platform.Platform) *Provider {
+	p.platform = plat
+	return p
+}
+
+// InstallPrerequisites returns the steps that must run before Homebrew is
+// installed on plat: the Xcode Command Line Tools on macOS.
+func InstallPrerequisites(plat *platform.Platform) []compiler.StepID {
+	if plat == nil || !plat.IsMacOS() {
+		return nil
+	}
+	return []compiler.StepID{compiler.MustNewStepID(tooldeps.XcodeCLTStepID)}
+}
+
 // Name returns the provider name.
 func (p *Provider) Name() string {
 	return "brew"
@@ -37,7 +56,7 @@ func (p *Provider) Compile(ctx compiler.CompileContext) ([]compiler.Step, error)
 	}
 
 	steps := make([]compiler.Step, 0)
-	steps = append(steps, NewInstallStep(p.runner))
+	steps = append(steps, NewInstallStep(p.runner, InstallPrerequisites(p.platform)...))
 
 	// Add tap steps first (they have no dependencies on other brew steps)
 	for _, tap := range cfg.Taps {
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
 	"github.com/felixgeelhaar/preflight/internal/testutil/mocks"
 )
 
@@ -208,3 +209,26 @@ func TestBrewProvider_Compile_StepsOrder(t *testing.T) {
 		t.Errorf("Second step should be tap, got %s", steps[1].ID().String())
 	}
 }
+
+func TestBrewProvider_WithPlatform(t *testing.T) {
+	ctx := compiler.NewCompileContext(map[string]interface{}{
+		"brew": map[string]interface{}{"formulae": []interface{}{"git"}},
+	})
+
+	steps, err := NewProvider(nil).WithPlatform(platform.New(platform.OSDarwin, "arm64", platform.EnvNative)).Compile(ctx)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	deps := steps[0].DependsOn()
+	if len(deps) != 1 || deps[0].String() != "bootstrap:xcode-clt" {
+		t.Errorf("brew:install DependsOn() = %v, want [bootstrap:xcode-clt] on macOS", deps)
+	}
+
+	steps, err = NewProvider(nil).WithPlatform(platform.New(platform.OSLinux, "amd64", platform.EnvNative)).Compile(ctx)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if deps := steps[0].DependsOn(); len(deps) != 0 {
+		t.Errorf("brew:install DependsOn() = %v, want none on Linux", deps)
+	}
+}
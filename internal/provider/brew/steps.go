@@ -2,7 +2,9 @@ package brew
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 
 	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
@@ -13,16 +15,31 @@ import (
 
 const brewInstallStepID = "brew:install"
 
+// brewInstallScript installs Homebrew without prompting; the installer
+// picks /opt/homebrew on Apple Silicon and /usr/local on Intel Macs.
+const brewInstallScript = "curl -fsSL https://raw.githubusercontent.com/Homebrew/install/HEAD/install.sh | NONINTERACTIVE=1 /bin/bash"
+
+// brewLocations are where the Homebrew installer puts brew on Apple
+// Silicon, Intel Macs and Linux.
+var brewLocations = []string{
+	"/opt/homebrew/bin/brew",
+	"/usr/local/bin/brew",
+	"/home/linuxbrew/.linuxbrew/bin/brew",
+}
+
 // InstallStep ensures Homebrew is installed.
 type InstallStep struct {
 	id     compiler.StepID
+	deps   []compiler.StepID
 	runner ports.CommandRunner
 }
 
-// NewInstallStep creates a new InstallStep.
-func NewInstallStep(runner ports.CommandRunner) *InstallStep {
+// NewInstallStep creates a new InstallStep. deps are the prerequisites
+// that must be installed first, such as the Xcode Command Line Tools.
+func NewInstallStep(runner ports.CommandRunner, deps ...compiler.StepID) *InstallStep {
 	return &InstallStep{
 		id:     compiler.MustNewStepID(brewInstallStepID),
+		deps:   deps,
 		runner: runner,
 	}
 }
@@ -34,12 +51,14 @@ func (s *InstallStep) ID() compiler.StepID {
 
 // DependsOn returns the step dependencies.
 func (s *InstallStep) DependsOn() []compiler.StepID {
-	return nil
+	return s.deps
 }
 
-// Check determines if Homebrew is installed.
+// Check determines if Homebrew is installed. A brew that is installed but
+// not yet on PATH, as on a fresh Apple Silicon Mac, is added to PATH so
+// that the steps after it can run it.
 func (s *InstallStep) Check(_ compiler.RunContext) (compiler.StepStatus, error) {
-	if _, err := exec.LookPath("brew"); err == nil {
+	if ensureBrewOnPath() {
 		return compiler.StatusSatisfied, nil
 	}
 	return compiler.StatusNeedsApply, nil
@@ -52,16 +71,33 @@ func (s *InstallStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
 
 // Apply installs Homebrew using the official install script.
 func (s *InstallStep) Apply(ctx compiler.RunContext) error {
-	result, err := s.runner.Run(ctx.Context(), "/bin/bash", "-c", "curl -fsSL https://raw.githubusercontent.com/Homebrew/install/HEAD/install.sh | /bin/bash")
+	result, err := s.runner.Run(ctx.Context(), "/bin/bash", "-c", brewInstallScript)
 	if err != nil {
 		return err
 	}
 	if !result.Success() {
 		return fmt.Errorf("homebrew install failed: %s", result.Stderr)
 	}
+	ensureBrewOnPath()
 	return nil
 }
 
+// ensureBrewOnPath reports whether brew can be run, adding the directory
+// of an installed brew to PATH when it is missing there.
+func ensureBrewOnPath() bool {
+	if _, err := exec.LookPath("brew"); err == nil {
+		return true
+	}
+	for _, location := range brewLocations {
+		if info, err := os.Stat(location); err == nil && !info.IsDir() {
+			dir := filepath.Dir(location)
+			_ = os.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+			return true
+		}
+	}
+	return false
+}
+
 // Explain provides a human-readable explanation.
 func (s *InstallStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
 	return compiler.NewExplanation(
@@ -195,7 +231,8 @@ func (s *FormulaStep) DependsOn() []compiler.StepID {
 
 // Check determines if the formula is already installed.
 func (s *FormulaStep) Check(ctx compiler.RunContext) (compiler.StepStatus, error) {
-	result, err := s.runner.Run(ctx.Context(), "brew", "list", "--formula")
+	name, args := Command(s.formula.Arch, "list", "--formula")
+	result, err := s.runner.Run(ctx.Context(), name, args...)
 	if err != nil {
 		if commandutil.IsCommandNotFound(err) {
 			return compiler.StatusNeedsApply, nil
@@ -222,6 +259,30 @@ func (s *FormulaStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
 
 // Apply executes the formula installation.
 func (s *FormulaStep) Apply(ctx compiler.RunContext) error {
+	if err := s.validate(); err != nil {
+		return err
+	}
+
+	installArgs := make([]string, 0, 2+len(s.formula.Args))
+	installArgs = append(installArgs, "install", s.formula.Name)
+	installArgs = append(installArgs, s.formula.Args...)
+
+	name, args := Command(s.formula.Arch, installArgs...)
+	result, err := s.runner.Run(ctx.Context(), name, args...)
+	if err != nil {
+		if commandutil.IsCommandNotFound(err) {
+			return fmt.Errorf("brew not found in PATH; install Homebrew first")
+		}
+		return err
+	}
+	if !result.Success() {
+		return fmt.Errorf("brew install %s failed: %s", s.formula.Name, result.Stderr)
+	}
+	return nil
+}
+
+// validate checks the formula before it is passed to brew.
+func (s *FormulaStep) validate() error {
 	// Validate formula name before execution to prevent command injection
 	if err := validation.ValidatePackageName(s.formula.Name); err != nil {
 		return fmt.Errorf("invalid formula name: %w", err)
@@ -234,21 +295,72 @@ func (s *FormulaStep) Apply(ctx compiler.RunContext) error {
 		}
 	}
 
-	args := make([]string, 0, 2+len(s.formula.Args))
-	args = append(args, "install", s.formula.Name)
-	args = append(args, s.formula.Args...)
+	if prefix := PrefixForArch(s.formula.Arch); prefix != "" {
+		if _, err := os.Stat(BrewPath(prefix)); err != nil {
+			return fmt.Errorf("%s requires the %s Homebrew in %s, which is not installed", s.formula.Name, s.formula.Arch, prefix)
+		}
+	}
+	return nil
+}
 
-	result, err := s.runner.Run(ctx.Context(), "brew", args...)
-	if err != nil {
-		if commandutil.IsCommandNotFound(err) {
-			return fmt.Errorf("brew not found in PATH; install Homebrew first")
+// BatchKey batches formulae installed by the same Homebrew. Formulae with
+// install arguments are installed on their own, since brew would apply
+// the arguments to every formula of the command.
+func (s *FormulaStep) BatchKey() string {
+	if len(s.formula.Args) > 0 {
+		return ""
+	}
+	return "brew:formula:" + s.formula.Arch
+}
+
+// ApplyBatch installs the formulae of steps with a single brew install.
+// When it fails, the formulae brew did not install are installed one at a
+// time, so that each reports its own error.
+func (s *FormulaStep) ApplyBatch(ctx compiler.RunContext, steps []compiler.Step) []error {
+	errs := make([]error, len(steps))
+	var batched []*FormulaStep
+	var indexes []int
+	for i, step := range steps {
+		formula, ok := step.(*FormulaStep)
+		if !ok {
+			errs[i] = step.Apply(ctx)
+			continue
 		}
-		return err
+		if err := formula.validate(); err != nil {
+			errs[i] = err
+			continue
+		}
+		batched = append(batched, formula)
+		indexes = append(indexes, i)
 	}
-	if !result.Success() {
-		return fmt.Errorf("brew install %s failed: %s", s.formula.Name, result.Stderr)
+	if len(batched) == 0 {
+		return errs
 	}
-	return nil
+
+	installArgs := []string{"install"}
+	for _, formula := range batched {
+		installArgs = append(installArgs, formula.formula.Name)
+	}
+	name, args := Command(s.formula.Arch, installArgs...)
+	result, err := s.runner.Run(ctx.Context(), name, args...)
+	if err == nil && result.Success() {
+		return errs
+	}
+	if err != nil && commandutil.IsCommandNotFound(err) {
+		for _, i := range indexes {
+			errs[i] = fmt.Errorf("brew not found in PATH; install Homebrew first")
+		}
+		return errs
+	}
+
+	name, args = Command(s.formula.Arch, "list", "--formula")
+	installed := listInstalled(ctx, s.runner, name, args...)
+	for j, formula := range batched {
+		if !installed[formula.formula.Name] {
+			errs[indexes[j]] = formula.Apply(ctx)
+		}
+	}
+	return errs
 }
 
 // Explain provides a human-readable explanation.
@@ -257,6 +369,9 @@ func (s *FormulaStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
 	if len(s.formula.Args) > 0 {
 		desc += fmt.Sprintf(" With args: %s", strings.Join(s.formula.Args, " "))
 	}
+	if prefix := PrefixForArch(s.formula.Arch); prefix != "" {
+		desc += fmt.Sprintf(" Installed for %s under %s.", s.formula.Arch, prefix)
+	}
 	return compiler.NewExplanation(
 		"Install Homebrew Formula",
 		desc,
@@ -273,7 +388,8 @@ func (s *FormulaStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
 
 // InstalledVersion returns the installed formula version if available.
 func (s *FormulaStep) InstalledVersion(ctx compiler.RunContext) (string, bool, error) {
-	result, err := s.runner.Run(ctx.Context(), "brew", "list", "--versions", s.formula.Name)
+	name, args := Command(s.formula.Arch, "list", "--versions", s.formula.Name)
+	result, err := s.runner.Run(ctx.Context(), name, args...)
 	if err != nil {
 		if commandutil.IsCommandNotFound(err) {
 			return "", false, nil
@@ -290,6 +406,12 @@ func (s *FormulaStep) InstalledVersion(ctx compiler.RunContext) (string, bool, e
 	return fields[1], true, nil
 }
 
+// UninstallCommand returns the command that uninstalls the formula.
+func (s *FormulaStep) UninstallCommand() []string {
+	name, args := Command(s.formula.Arch, "uninstall", "--formula", s.formula.Name)
+	return append([]string{name}, args...)
+}
+
 // CaskStep represents a Homebrew cask installation step.
 type CaskStep struct {
 	cask   Cask
@@ -369,6 +491,60 @@ func (s *CaskStep) Apply(ctx compiler.RunContext) error {
 	return nil
 }
 
+// BatchKey batches all cask installs.
+func (s *CaskStep) BatchKey() string {
+	return "brew:cask"
+}
+
+// ApplyBatch installs the casks of steps with a single brew install --cask.
+// When it fails, the casks brew did not install are installed one at a
+// time, so that each reports its own error.
+func (s *CaskStep) ApplyBatch(ctx compiler.RunContext, steps []compiler.Step) []error {
+	errs := make([]error, len(steps))
+	var batched []*CaskStep
+	var indexes []int
+	for i, step := range steps {
+		cask, ok := step.(*CaskStep)
+		if !ok {
+			errs[i] = step.Apply(ctx)
+			continue
+		}
+		// Validate cask name before execution to prevent command injection
+		if err := validation.ValidatePackageName(cask.cask.Name); err != nil {
+			errs[i] = fmt.Errorf("invalid cask name: %w", err)
+			continue
+		}
+		batched = append(batched, cask)
+		indexes = append(indexes, i)
+	}
+	if len(batched) == 0 {
+		return errs
+	}
+
+	args := []string{"install", "--cask"}
+	for _, cask := range batched {
+		args = append(args, cask.cask.Name)
+	}
+	result, err := s.runner.Run(ctx.Context(), "brew", args...)
+	if err == nil && result.Success() {
+		return errs
+	}
+	if err != nil && commandutil.IsCommandNotFound(err) {
+		for _, i := range indexes {
+			errs[i] = fmt.Errorf("brew not found in PATH; install Homebrew first")
+		}
+		return errs
+	}
+
+	installed := listInstalled(ctx, s.runner, "brew", "list", "--cask")
+	for j, cask := range batched {
+		if !installed[cask.cask.Name] {
+			errs[indexes[j]] = cask.Apply(ctx)
+		}
+	}
+	return errs
+}
+
 // Explain provides a human-readable explanation.
 func (s *CaskStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
 	return compiler.NewExplanation(
@@ -403,3 +579,22 @@ func (s *CaskStep) InstalledVersion(ctx compiler.RunContext) (string, bool, erro
 	}
 	return fields[1], true, nil
 }
+
+// UninstallCommand returns the command that uninstalls the cask.
+func (s *CaskStep) UninstallCommand() []string {
+	return []string{"brew", "uninstall", "--cask", s.cask.Name}
+}
+
+// listInstalled runs a brew list command and returns the names it reports,
+// empty when it fails.
+func listInstalled(ctx compiler.RunContext, runner ports.CommandRunner, name string, args ...string) map[string]bool {
+	installed := make(map[string]bool)
+	result, err := runner.Run(ctx.Context(), name, args...)
+	if err != nil || !result.Success() {
+		return installed
+	}
+	for _, line := range strings.Split(strings.TrimSpace(result.Stdout), "\n") {
+		installed[strings.TrimSpace(line)] = true
+	}
+	return installed
+}
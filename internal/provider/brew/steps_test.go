@@ -2,7 +2,9 @@ package brew
 
 import (
 	"context"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -242,6 +244,17 @@ func TestCaskStep_ID(t *testing.T) {
 	}
 }
 
+func TestFormulaAndCaskStep_UninstallCommand(t *testing.T) {
+	formula := NewFormulaStep(Formula{Name: "ripgrep", Args: []string{"--HEAD"}}, nil)
+	if got := strings.Join(formula.UninstallCommand(), " "); got != "brew uninstall --formula ripgrep" {
+		t.Errorf("formula UninstallCommand() = %q", got)
+	}
+	cask := NewCaskStep(Cask{Name: "docker"}, nil)
+	if got := strings.Join(cask.UninstallCommand(), " "); got != "brew uninstall --cask docker" {
+		t.Errorf("cask UninstallCommand() = %q", got)
+	}
+}
+
 func TestCaskStep_Check_Installed(t *testing.T) {
 	runner := mocks.NewCommandRunner()
 	runner.AddResult("brew", []string{"list", "--cask"}, ports.CommandResult{
@@ -563,7 +576,7 @@ func TestInstallStep_Apply_Success(t *testing.T) {
 	t.Parallel()
 
 	runner := mocks.NewCommandRunner()
-	runner.AddResult("/bin/bash", []string{"-c", "curl -fsSL https://raw.githubusercontent.com/Homebrew/install/HEAD/install.sh | /bin/bash"}, ports.CommandResult{
+	runner.AddResult("/bin/bash", []string{"-c", brewInstallScript}, ports.CommandResult{
 		ExitCode: 0,
 		Stdout:   "Homebrew installed successfully",
 	})
@@ -596,7 +609,7 @@ func TestInstallStep_Apply_CommandFailure(t *testing.T) {
 	t.Parallel()
 
 	runner := mocks.NewCommandRunner()
-	runner.AddResult("/bin/bash", []string{"-c", "curl -fsSL https://raw.githubusercontent.com/Homebrew/install/HEAD/install.sh | /bin/bash"}, ports.CommandResult{
+	runner.AddResult("/bin/bash", []string{"-c", brewInstallScript}, ports.CommandResult{
 		ExitCode: 1,
 		Stderr:   "installation failed",
 	})
@@ -610,6 +623,32 @@ func TestInstallStep_Apply_CommandFailure(t *testing.T) {
 	}
 }
 
+func TestInstallStep_Check_BrewOffPath(t *testing.T) {
+	dir := t.TempDir()
+	brew := filepath.Join(dir, "bin", "brew")
+	if err := os.MkdirAll(filepath.Dir(brew), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(brew, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	saved := brewLocations
+	brewLocations = []string{filepath.Join(dir, "missing", "brew"), brew}
+	t.Cleanup(func() { brewLocations = saved })
+	t.Setenv("PATH", t.TempDir())
+
+	status, err := NewInstallStep(nil).Check(compiler.NewRunContext(context.Background()))
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if status != compiler.StatusSatisfied {
+		t.Errorf("Check() = %v, want %v for a brew outside PATH", status, compiler.StatusSatisfied)
+	}
+	if !strings.HasPrefix(os.Getenv("PATH"), filepath.Dir(brew)) {
+		t.Errorf("PATH = %q, want it to start with %q", os.Getenv("PATH"), filepath.Dir(brew))
+	}
+}
+
 func TestInstallStep_Explain(t *testing.T) {
 	t.Parallel()
 
@@ -977,3 +1016,128 @@ func TestCaskStep_InstalledVersion_ShortOutput(t *testing.T) {
 		t.Errorf("InstalledVersion() version = %q, want empty", version)
 	}
 }
+
+func TestFormulaStep_Arch(t *testing.T) {
+	runner := mocks.NewCommandRunner()
+	runner.AddResult("arch", []string{"-x86_64", "/usr/local/bin/brew", "list", "--formula"}, ports.CommandResult{
+		Stdout: "legacy-tool\n",
+	})
+	step := NewFormulaStep(Formula{Name: "legacy-tool", Arch: ArchIntel}, runner)
+
+	status, err := step.Check(compiler.NewRunContext(context.Background()))
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if status != compiler.StatusSatisfied {
+		t.Errorf("Check() = %v, want %v", status, compiler.StatusSatisfied)
+	}
+
+	want := []string{"arch", "-x86_64", "/usr/local/bin/brew", "uninstall", "--formula", "legacy-tool"}
+	if got := step.UninstallCommand(); strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("UninstallCommand() = %v, want %v", got, want)
+	}
+}
+
+func TestCommand(t *testing.T) {
+	tests := []struct {
+		arch string
+		want string
+	}{
+		{"", "brew list"},
+		{ArchARM, "arch -arm64 /opt/homebrew/bin/brew list"},
+		{ArchIntel, "arch -x86_64 /usr/local/bin/brew list"},
+	}
+	for _, tt := range tests {
+		name, args := Command(tt.arch, "list")
+		if got := strings.Join(append([]string{name}, args...), " "); got != tt.want {
+			t.Errorf("Command(%q) = %q, want %q", tt.arch, got, tt.want)
+		}
+	}
+}
+
+func TestFormulaStep_BatchKey(t *testing.T) {
+	if got := NewFormulaStep(Formula{Name: "git"}, nil).BatchKey(); got != "brew:formula:" {
+		t.Errorf("BatchKey() = %q, want brew:formula:", got)
+	}
+	if got := NewFormulaStep(Formula{Name: "git", Arch: ArchIntel}, nil).BatchKey(); got != "brew:formula:"+ArchIntel {
+		t.Errorf("BatchKey() = %q, want the Intel batch", got)
+	}
+	if got := NewFormulaStep(Formula{Name: "neovim", Args: []string{"--HEAD"}}, nil).BatchKey(); got != "" {
+		t.Errorf("BatchKey() = %q, want no batching for formulae with args", got)
+	}
+}
+
+func TestFormulaStep_ApplyBatch(t *testing.T) {
+	runner := mocks.NewCommandRunner()
+	runner.AddResult("brew", []string{"install", "git", "jq", "fd"}, ports.CommandResult{})
+
+	steps := []compiler.Step{
+		NewFormulaStep(Formula{Name: "git"}, runner),
+		NewFormulaStep(Formula{Name: "jq"}, runner),
+		NewFormulaStep(Formula{Name: "fd"}, runner),
+	}
+	errs := steps[0].(*FormulaStep).ApplyBatch(compiler.NewRunContext(context.Background()), steps)
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("errs[%d] = %v", i, err)
+		}
+	}
+	if calls := runner.Calls(); len(calls) != 1 {
+		t.Errorf("calls = %v, want a single brew install", calls)
+	}
+}
+
+func TestFormulaStep_ApplyBatch_PartialFailure(t *testing.T) {
+	runner := mocks.NewCommandRunner()
+	runner.AddResult("brew", []string{"install", "git", "nope", "jq"}, ports.CommandResult{
+		ExitCode: 1,
+		Stderr:   "Error: No available formula with the name \"nope\".",
+	})
+	runner.AddResult("brew", []string{"list", "--formula"}, ports.CommandResult{Stdout: "git\n"})
+	runner.AddResult("brew", []string{"install", "nope"}, ports.CommandResult{
+		ExitCode: 1,
+		Stderr:   "Error: No available formula with the name \"nope\".",
+	})
+	runner.AddResult("brew", []string{"install", "jq"}, ports.CommandResult{})
+
+	steps := []compiler.Step{
+		NewFormulaStep(Formula{Name: "git"}, runner),
+		NewFormulaStep(Formula{Name: "nope"}, runner),
+		NewFormulaStep(Formula{Name: "jq"}, runner),
+	}
+	errs := steps[0].(*FormulaStep).ApplyBatch(compiler.NewRunContext(context.Background()), steps)
+	if errs[0] != nil || errs[2] != nil {
+		t.Errorf("errs = %v, want git and jq installed", errs)
+	}
+	if errs[1] == nil || !strings.Contains(errs[1].Error(), "brew install nope failed") {
+		t.Errorf("errs[1] = %v, want the error of nope", errs[1])
+	}
+}
+
+func TestCaskStep_ApplyBatch(t *testing.T) {
+	runner := mocks.NewCommandRunner()
+	runner.AddResult("brew", []string{"install", "--cask", "firefox", "iterm2"}, ports.CommandResult{
+		ExitCode: 1,
+		Stderr:   "Error: It seems there is already an App at '/Applications/iTerm.app'.",
+	})
+	runner.AddResult("brew", []string{"list", "--cask"}, ports.CommandResult{Stdout: "firefox\n"})
+	runner.AddResult("brew", []string{"install", "--cask", "iterm2"}, ports.CommandResult{
+		ExitCode: 1,
+		Stderr:   "Error: It seems there is already an App at '/Applications/iTerm.app'.",
+	})
+
+	steps := []compiler.Step{
+		NewCaskStep(Cask{Name: "firefox"}, runner),
+		NewCaskStep(Cask{Name: "iterm2"}, runner),
+	}
+	if got := steps[0].(*CaskStep).BatchKey(); got != "brew:cask" {
+		t.Errorf("BatchKey() = %q, want brew:cask", got)
+	}
+	errs := steps[0].(*CaskStep).ApplyBatch(compiler.NewRunContext(context.Background()), steps)
+	if errs[0] != nil {
+		t.Errorf("errs[0] = %v, want firefox installed", errs[0])
+	}
+	if errs[1] == nil || !strings.Contains(errs[1].Error(), "already an App") {
+		t.Errorf("errs[1] = %v, want the error of iterm2", errs[1])
+	}
+}
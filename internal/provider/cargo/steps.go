@@ -133,6 +133,11 @@ func (s *CrateStep) LockInfo() (compiler.LockInfo, bool) {
 	}, true
 }
 
+// UninstallCommand returns the command that uninstalls the crate.
+func (s *CrateStep) UninstallCommand() []string {
+	return []string{"cargo", "uninstall", s.crate.Name}
+}
+
 // InstalledVersion returns the installed crate version if available.
 func (s *CrateStep) InstalledVersion(ctx compiler.RunContext) (string, bool, error) {
 	result, err := s.runner.Run(ctx.Context(), "cargo", "install", "--list")
@@ -0,0 +1,50 @@
+// Package checksumutil provides checksum parsing and verification for downloaded artifacts.
+package checksumutil
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/lock"
+)
+
+// ErrChecksumMismatch is returned when artifact content does not match its checksum.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// Parse parses a checksum in "algorithm:hash" form. A bare hex string is
+// treated as SHA256 for compatibility with published *.sha256 files.
+func Parse(checksum string) (lock.Integrity, error) {
+	checksum = strings.TrimSpace(checksum)
+	if !strings.Contains(checksum, ":") {
+		return lock.NewIntegrity(lock.AlgorithmSHA256, strings.ToLower(checksum))
+	}
+	parts := strings.SplitN(checksum, ":", 2)
+	return lock.ParseIntegrity(strings.ToLower(parts[0]) + ":" + strings.ToLower(parts[1]))
+}
+
+// Verify checks data against the expected checksum.
+func Verify(data []byte, checksum string) error {
+	expected, err := Parse(checksum)
+	if err != nil {
+		return err
+	}
+	if !expected.Verify(data) {
+		actual := lock.IntegrityFromData(expected.Algorithm(), data)
+		return fmt.Errorf("%w: expected %s, got %s", ErrChecksumMismatch, expected, actual)
+	}
+	return nil
+}
+
+// VerifyFile checks the file at path against the expected checksum.
+func VerifyFile(path, checksum string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := Verify(data, checksum); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package checksumutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/lock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sha256 of "hello"
+const helloSHA256 = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		algorithm string
+		wantErr   bool
+	}{
+		{"prefixed sha256", "sha256:" + helloSHA256, lock.AlgorithmSHA256, false},
+		{"bare hex", helloSHA256, lock.AlgorithmSHA256, false},
+		{"uppercase", "SHA256:2CF24DBA5FB0A30E26E83B2AC5B9E29E1B161E5C1FA7425E73043362938B9824", lock.AlgorithmSHA256, false},
+		{"unsupported algorithm", "md5:" + helloSHA256, "", true},
+		{"short hash", "sha256:abc", "", true},
+		{"empty", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.algorithm, got.Algorithm())
+			assert.Equal(t, helloSHA256, got.Hash())
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	require.NoError(t, Verify([]byte("hello"), "sha256:"+helloSHA256))
+
+	err := Verify([]byte("tampered"), "sha256:"+helloSHA256)
+	require.ErrorIs(t, err, ErrChecksumMismatch)
+}
+
+func TestVerifyFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "artifact")
+	require.NoError(t, os.WriteFile(path, []byte("hello"), 0o600))
+
+	require.NoError(t, VerifyFile(path, helloSHA256))
+	require.ErrorIs(t, VerifyFile(path, "sha256:"+helloSHA256[:63]+"0"), ErrChecksumMismatch)
+	require.Error(t, VerifyFile(filepath.Join(dir, "missing"), helloSHA256))
+}
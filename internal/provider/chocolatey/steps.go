@@ -397,6 +397,11 @@ func (s *PackageStep) LockInfo() (compiler.LockInfo, bool) {
 	}, true
 }
 
+// UninstallCommand returns the command that uninstalls the package.
+func (s *PackageStep) UninstallCommand() []string {
+	return []string{s.chocoCommand(), "uninstall", s.pkg.Name, "-y"}
+}
+
 // InstalledVersion returns the installed Chocolatey package version if available.
 func (s *PackageStep) InstalledVersion(ctx compiler.RunContext) (string, bool, error) {
 	cmd := s.chocoCommand()
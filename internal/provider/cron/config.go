@@ -0,0 +1,108 @@
+// Package cron provides the scheduled job provider. Jobs are written to the
+// user crontab or, on macOS, to launchd agents.
+package cron
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Backends a job can be scheduled with.
+const (
+	BackendCrontab = "crontab"
+	BackendLaunchd = "launchd"
+)
+
+var jobNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// Config represents the cron section of the configuration.
+type Config struct {
+	Jobs []Job
+}
+
+// Job is a command run on a cron schedule.
+type Job struct {
+	Name     string
+	Schedule string
+	Command  string
+	Backend  string
+}
+
+// ParseConfig parses the cron configuration from a raw map.
+func ParseConfig(raw map[string]interface{}) (*Config, error) {
+	cfg := &Config{}
+	jobs, ok := raw["jobs"]
+	if !ok {
+		return cfg, nil
+	}
+	list, ok := jobs.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("cron jobs must be a list")
+	}
+	seen := make(map[string]bool)
+	for _, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("cron job must be an object")
+		}
+		job := Job{Backend: BackendCrontab}
+		job.Name, _ = m["name"].(string)
+		job.Schedule, _ = m["schedule"].(string)
+		job.Command, _ = m["command"].(string)
+		if backend, ok := m["backend"].(string); ok && backend != "" {
+			job.Backend = backend
+		}
+		if err := job.validate(); err != nil {
+			return nil, err
+		}
+		if seen[job.Name] {
+			return nil, fmt.Errorf("duplicate cron job %q", job.Name)
+		}
+		seen[job.Name] = true
+		cfg.Jobs = append(cfg.Jobs, job)
+	}
+	return cfg, nil
+}
+
+// JobsFor returns the jobs scheduled with backend.
+func (c *Config) JobsFor(backend string) []Job {
+	var jobs []Job
+	for _, job := range c.Jobs {
+		if job.Backend == backend {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs
+}
+
+func (j Job) validate() error {
+	if !jobNamePattern.MatchString(j.Name) {
+		return fmt.Errorf("cron job name %q must start with a letter or digit and contain only letters, digits, '.', '_' or '-'", j.Name)
+	}
+	if strings.TrimSpace(j.Command) == "" {
+		return fmt.Errorf("cron job %s has no command", j.Name)
+	}
+	if strings.ContainsAny(j.Command, "\r\n") {
+		return fmt.Errorf("cron job %s command must be a single line", j.Name)
+	}
+	switch j.Backend {
+	case BackendCrontab:
+		if strings.Contains(j.Command, "%") {
+			return fmt.Errorf("cron job %s command contains '%%', which crontab treats as a newline; escape it as '\\%%' or use a script", j.Name)
+		}
+	case BackendLaunchd:
+	default:
+		return fmt.Errorf("cron job %s has unknown backend %q (use crontab or launchd)", j.Name, j.Backend)
+	}
+	schedule, err := ParseSchedule(j.Schedule)
+	if err != nil {
+		return fmt.Errorf("cron job %s: %w", j.Name, err)
+	}
+	if j.Backend == BackendLaunchd {
+		if _, err := schedule.CalendarIntervals(); err != nil {
+			return fmt.Errorf("cron job %s: %w", j.Name, err)
+		}
+	}
+	return nil
+}
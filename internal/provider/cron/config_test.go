@@ -0,0 +1,45 @@
+package cron
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfig(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseConfig(map[string]interface{}{
+		"jobs": []interface{}{
+			map[string]interface{}{"name": "brew-update", "schedule": "0 9 * * 1", "command": "brew update"},
+			map[string]interface{}{"name": "backup", "schedule": "@daily", "command": "restic backup", "backend": "launchd"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []Job{{Name: "brew-update", Schedule: "0 9 * * 1", Command: "brew update", Backend: BackendCrontab}}, cfg.JobsFor(BackendCrontab))
+	assert.Equal(t, "backup", cfg.JobsFor(BackendLaunchd)[0].Name)
+}
+
+func TestParseConfig_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for name, job := range map[string]map[string]interface{}{
+		"bad name":        {"name": "my job", "schedule": "@daily", "command": "date"},
+		"no command":      {"name": "a", "schedule": "@daily"},
+		"multiline":       {"name": "a", "schedule": "@daily", "command": "date\nrm -rf ~"},
+		"percent":         {"name": "a", "schedule": "@daily", "command": "date +%F"},
+		"bad schedule":    {"name": "a", "schedule": "every day", "command": "date"},
+		"unknown backend": {"name": "a", "schedule": "@daily", "command": "date", "backend": "systemd"},
+		"launchd day+dow": {"name": "a", "schedule": "0 0 1 * 1", "command": "date", "backend": "launchd"},
+	} {
+		_, err := ParseConfig(map[string]interface{}{"jobs": []interface{}{job}})
+		assert.Error(t, err, name)
+	}
+
+	_, err := ParseConfig(map[string]interface{}{"jobs": []interface{}{
+		map[string]interface{}{"name": "a", "schedule": "@daily", "command": "date"},
+		map[string]interface{}{"name": "a", "schedule": "@hourly", "command": "date"},
+	}})
+	require.Error(t, err)
+}
@@ -0,0 +1,191 @@
+package cron
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/provider/commandutil"
+)
+
+// markerPrefix starts the comment line preceding every crontab entry that
+// preflight owns. The job name follows the prefix.
+const markerPrefix = "# preflight:"
+
+// CrontabStep reconciles the preflight-owned entries of the user crontab.
+// Each owned entry is a marker comment followed by the schedule line, so
+// declared jobs are updated in place, jobs removed from the configuration
+// are deleted, and lines without a marker are never touched.
+type CrontabStep struct {
+	jobs   []Job
+	id     compiler.StepID
+	runner ports.CommandRunner
+}
+
+// NewCrontabStep creates a step owning jobs in the user crontab.
+func NewCrontabStep(jobs []Job, runner ports.CommandRunner) *CrontabStep {
+	return &CrontabStep{
+		jobs:   jobs,
+		id:     compiler.MustNewStepID("cron:crontab"),
+		runner: runner,
+	}
+}
+
+// ID returns the step identifier.
+func (s *CrontabStep) ID() compiler.StepID {
+	return s.id
+}
+
+// DependsOn returns the step dependencies.
+func (s *CrontabStep) DependsOn() []compiler.StepID {
+	return nil
+}
+
+// Check compares the crontab with the reconciled crontab.
+func (s *CrontabStep) Check(ctx compiler.RunContext) (compiler.StepStatus, error) {
+	current, err := s.read(ctx)
+	if err != nil {
+		return compiler.StatusUnknown, err
+	}
+	if Reconcile(current, s.jobs) == current {
+		return compiler.StatusSatisfied, nil
+	}
+	return compiler.StatusNeedsApply, nil
+}
+
+// Plan returns the diff for this step.
+func (s *CrontabStep) Plan(ctx compiler.RunContext) (compiler.Diff, error) {
+	current, err := s.read(ctx)
+	if err != nil {
+		return compiler.Diff{}, err
+	}
+	owned := OwnedJobs(current)
+	names := make([]string, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		names = append(names, job.Name)
+	}
+	return compiler.NewDiff(compiler.DiffTypeModify, "crontab", "user",
+		fmt.Sprintf("%d preflight jobs", len(owned)), strings.Join(names, ", ")), nil
+}
+
+// Apply installs the reconciled crontab.
+func (s *CrontabStep) Apply(ctx compiler.RunContext) error {
+	current, err := s.read(ctx)
+	if err != nil {
+		return err
+	}
+	file, err := os.CreateTemp("", "preflight-crontab-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(file.Name()) }()
+	if _, err := file.WriteString(Reconcile(current, s.jobs)); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	result, err := s.runner.Run(ctx.Context(), "crontab", file.Name())
+	if err != nil {
+		return err
+	}
+	if !result.Success() {
+		return fmt.Errorf("crontab install failed: %s", strings.TrimSpace(result.Stderr))
+	}
+	return nil
+}
+
+// Explain provides a human-readable explanation.
+func (s *CrontabStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
+	return compiler.NewExplanation(
+		"Manage Crontab Jobs",
+		fmt.Sprintf("Installs %d scheduled jobs in the user crontab, marking each with a '%s<name>' comment", len(s.jobs), markerPrefix),
+		[]string{
+			"https://man7.org/linux/man-pages/man5/crontab.5.html",
+		},
+	).WithTradeoffs([]string{
+		"+ Manually added crontab lines are preserved",
+		"+ Jobs removed from the configuration are removed from the crontab",
+		"- Editing a marked entry by hand is overwritten on the next apply",
+	})
+}
+
+// read returns the current user crontab, or an empty string when the user
+// has none.
+func (s *CrontabStep) read(ctx compiler.RunContext) (string, error) {
+	result, err := s.runner.Run(ctx.Context(), "crontab", "-l")
+	if err != nil {
+		if commandutil.IsCommandNotFound(err) {
+			return "", fmt.Errorf("crontab not found in PATH")
+		}
+		return "", err
+	}
+	if !result.Success() {
+		if strings.Contains(strings.ToLower(result.Stderr), "no crontab") {
+			return "", nil
+		}
+		return "", fmt.Errorf("crontab -l failed: %s", strings.TrimSpace(result.Stderr))
+	}
+	return result.Stdout, nil
+}
+
+// Reconcile returns crontab with its preflight-owned entries replaced by
+// jobs. Declared jobs keep the position of their existing entry, new jobs
+// are appended, and owned entries for undeclared jobs are dropped.
+func Reconcile(crontab string, jobs []Job) string {
+	declared := make(map[string]Job, len(jobs))
+	for _, job := range jobs {
+		declared[job.Name] = job
+	}
+	written := make(map[string]bool, len(jobs))
+
+	var lines []string
+	source := strings.Split(strings.TrimRight(crontab, "\n"), "\n")
+	if crontab == "" {
+		source = nil
+	}
+	for i := 0; i < len(source); i++ {
+		name, owned := strings.CutPrefix(source[i], markerPrefix)
+		if !owned {
+			lines = append(lines, source[i])
+			continue
+		}
+		i++ // the marker owns the following schedule line
+		job, ok := declared[name]
+		if !ok || written[name] {
+			continue
+		}
+		written[name] = true
+		lines = append(lines, entryLines(job)...)
+	}
+	for _, job := range jobs {
+		if !written[job.Name] {
+			lines = append(lines, entryLines(job)...)
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// OwnedJobs returns the names of preflight-owned entries in crontab.
+func OwnedJobs(crontab string) []string {
+	var names []string
+	for _, line := range strings.Split(crontab, "\n") {
+		if name, ok := strings.CutPrefix(line, markerPrefix); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func entryLines(job Job) []string {
+	return []string{markerPrefix + job.Name, strings.TrimSpace(job.Schedule) + " " + job.Command}
+}
+
+var _ compiler.Step = (*CrontabStep)(nil)
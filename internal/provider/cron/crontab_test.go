@@ -0,0 +1,88 @@
+package cron
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/testutil/mocks"
+)
+
+const userCrontab = `MAILTO=me@example.com
+# preflight:old-job
+0 * * * * old-command
+5 4 * * * manual-backup.sh
+# preflight:brew-update
+0 9 * * 1 brew update
+`
+
+var declaredJobs = []Job{
+	{Name: "cleanup", Schedule: "@weekly", Command: "brew cleanup", Backend: BackendCrontab},
+	{Name: "brew-update", Schedule: "0 8 * * *", Command: "brew update", Backend: BackendCrontab},
+}
+
+func TestReconcile(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, `MAILTO=me@example.com
+5 4 * * * manual-backup.sh
+# preflight:brew-update
+0 8 * * * brew update
+# preflight:cleanup
+@weekly brew cleanup
+`, Reconcile(userCrontab, declaredJobs))
+
+	assert.Equal(t, "MAILTO=me@example.com\n5 4 * * * manual-backup.sh\n", Reconcile(userCrontab, nil))
+	assert.Equal(t, "", Reconcile("", nil))
+	assert.Equal(t, []string{"old-job", "brew-update"}, OwnedJobs(userCrontab))
+}
+
+func TestCrontabStep_Check(t *testing.T) {
+	t.Parallel()
+
+	ctx := compiler.NewRunContext(context.Background())
+	runner := mocks.NewCommandRunner()
+	runner.AddResult("crontab", []string{"-l"}, ports.CommandResult{Stdout: Reconcile(userCrontab, declaredJobs)})
+
+	status, err := NewCrontabStep(declaredJobs, runner).Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, status)
+
+	status, err = NewCrontabStep(declaredJobs[:1], runner).Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+
+	empty := mocks.NewCommandRunner()
+	empty.AddResult("crontab", []string{"-l"}, ports.CommandResult{ExitCode: 1, Stderr: "crontab: no crontab for me"})
+	status, err = NewCrontabStep(nil, empty).Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, status)
+}
+
+// crontabRunner serves 'crontab -l' and records what 'crontab <file>' installs.
+type crontabRunner struct {
+	current   string
+	installed string
+}
+
+func (r *crontabRunner) Run(_ context.Context, _ string, args ...string) (ports.CommandResult, error) {
+	if args[0] == "-l" {
+		return ports.CommandResult{Stdout: r.current}, nil
+	}
+	data, err := os.ReadFile(args[0])
+	r.installed = string(data)
+	return ports.CommandResult{}, err
+}
+
+func TestCrontabStep_Apply(t *testing.T) {
+	t.Parallel()
+
+	runner := &crontabRunner{current: userCrontab}
+	require.NoError(t, NewCrontabStep(declaredJobs, runner).Apply(compiler.NewRunContext(context.Background())))
+	assert.Equal(t, Reconcile(userCrontab, declaredJobs), runner.installed)
+}
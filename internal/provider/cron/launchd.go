@@ -0,0 +1,201 @@
+package cron
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/provider/plistutil"
+)
+
+// LabelPrefix prefixes the launchd label of every preflight-owned job.
+const LabelPrefix = "com.preflight.cron."
+
+// LaunchdStep reconciles the preflight-owned launchd agents. Agents are
+// identified by their label prefix, so agents for jobs removed from the
+// configuration are unloaded and deleted while other agents are untouched.
+type LaunchdStep struct {
+	jobs      []Job
+	id        compiler.StepID
+	agentsDir string
+	runner    ports.CommandRunner
+}
+
+// NewLaunchdStep creates a step owning jobs as launchd agents in agentsDir.
+func NewLaunchdStep(jobs []Job, agentsDir string, runner ports.CommandRunner) *LaunchdStep {
+	return &LaunchdStep{
+		jobs:      jobs,
+		id:        compiler.MustNewStepID("cron:launchd"),
+		agentsDir: agentsDir,
+		runner:    runner,
+	}
+}
+
+// ID returns the step identifier.
+func (s *LaunchdStep) ID() compiler.StepID {
+	return s.id
+}
+
+// DependsOn returns the step dependencies.
+func (s *LaunchdStep) DependsOn() []compiler.StepID {
+	return nil
+}
+
+// Check reports whether every agent plist matches its job and no stale
+// preflight agents remain.
+func (s *LaunchdStep) Check(_ compiler.RunContext) (compiler.StepStatus, error) {
+	changed, stale, err := s.diff()
+	if err != nil {
+		return compiler.StatusUnknown, err
+	}
+	if len(changed) == 0 && len(stale) == 0 {
+		return compiler.StatusSatisfied, nil
+	}
+	return compiler.StatusNeedsApply, nil
+}
+
+// Plan returns the diff for this step.
+func (s *LaunchdStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
+	changed, stale, err := s.diff()
+	if err != nil {
+		return compiler.Diff{}, err
+	}
+	names := make([]string, 0, len(changed))
+	for name := range changed {
+		names = append(names, name)
+	}
+	return compiler.NewDiff(compiler.DiffTypeModify, "launchd", s.agentsDir,
+		fmt.Sprintf("remove: %s", strings.Join(stale, ", ")), fmt.Sprintf("write: %s", strings.Join(names, ", "))), nil
+}
+
+// Apply writes changed agents and reloads them, then unloads and deletes
+// agents whose jobs are no longer declared.
+func (s *LaunchdStep) Apply(ctx compiler.RunContext) error {
+	changed, stale, err := s.diff()
+	if err != nil {
+		return err
+	}
+	// #nosec G301 -- LaunchAgents must be readable by launchctl.
+	if err := os.MkdirAll(s.agentsDir, 0o755); err != nil {
+		return err
+	}
+	for _, job := range s.jobs {
+		content, ok := changed[job.Name]
+		if !ok {
+			continue
+		}
+		path := s.plistPath(job.Name)
+		if _, err := os.Stat(path); err == nil {
+			_, _ = s.runner.Run(ctx.Context(), "launchctl", "unload", path)
+		}
+		// #nosec G306 -- LaunchAgent plist must be readable by launchctl.
+		if err := os.WriteFile(path, content, 0o644); err != nil {
+			return err
+		}
+		result, err := s.runner.Run(ctx.Context(), "launchctl", "load", path)
+		if err != nil {
+			return err
+		}
+		if !result.Success() {
+			return fmt.Errorf("launchctl load %s failed: %s", path, strings.TrimSpace(result.Stderr))
+		}
+	}
+	for _, name := range stale {
+		path := s.plistPath(name)
+		_, _ = s.runner.Run(ctx.Context(), "launchctl", "unload", path)
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Explain provides a human-readable explanation.
+func (s *LaunchdStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
+	return compiler.NewExplanation(
+		"Manage launchd Jobs",
+		fmt.Sprintf("Installs %d scheduled jobs as launchd agents labelled %s<name>", len(s.jobs), LabelPrefix),
+		[]string{
+			"https://developer.apple.com/library/archive/documentation/MacOSX/Conceptual/BPSystemStartup/Chapters/ScheduledJobs.html",
+		},
+	).WithTradeoffs([]string{
+		"+ Runs missed jobs after the Mac wakes from sleep",
+		"+ Agents removed from the configuration are unloaded and deleted",
+		"- macOS only",
+	})
+}
+
+// diff returns the rendered plists that differ from disk, keyed by job name,
+// and the names of preflight agents that are no longer declared.
+func (s *LaunchdStep) diff() (map[string][]byte, []string, error) {
+	changed := make(map[string][]byte)
+	declared := make(map[string]bool, len(s.jobs))
+	for _, job := range s.jobs {
+		declared[job.Name] = true
+		content, err := RenderPlist(job)
+		if err != nil {
+			return nil, nil, err
+		}
+		// #nosec G304 -- path is derived from the job name under LaunchAgents.
+		current, err := os.ReadFile(s.plistPath(job.Name))
+		if err != nil || !bytes.Equal(current, content) {
+			changed[job.Name] = content
+		}
+	}
+
+	matches, err := filepath.Glob(filepath.Join(s.agentsDir, LabelPrefix+"*.plist"))
+	if err != nil {
+		return nil, nil, err
+	}
+	var stale []string
+	for _, match := range matches {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(match), LabelPrefix), ".plist")
+		if !declared[name] {
+			stale = append(stale, name)
+		}
+	}
+	return changed, stale, nil
+}
+
+func (s *LaunchdStep) plistPath(name string) string {
+	return filepath.Join(s.agentsDir, LabelPrefix+name+".plist")
+}
+
+// RenderPlist renders the launchd agent plist for job. The command runs
+// through /bin/sh so it behaves like a crontab line.
+func RenderPlist(job Job) ([]byte, error) {
+	schedule, err := ParseSchedule(job.Schedule)
+	if err != nil {
+		return nil, err
+	}
+	agent := map[string]interface{}{
+		"Label":            LabelPrefix + job.Name,
+		"ProgramArguments": []interface{}{"/bin/sh", "-c", job.Command},
+	}
+	switch intervals, err := schedule.CalendarIntervals(); {
+	case err != nil:
+		return nil, err
+	case schedule.AtReboot:
+		agent["RunAtLoad"] = true
+	case len(intervals) == 0:
+		agent["StartInterval"] = 60
+	default:
+		agent["StartCalendarInterval"] = intervals
+	}
+	return plistutil.Encode(agent)
+}
+
+// AgentsDir returns the user LaunchAgents directory.
+func AgentsDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "Library", "LaunchAgents"), nil
+}
+
+var _ compiler.Step = (*LaunchdStep)(nil)
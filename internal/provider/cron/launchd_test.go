@@ -0,0 +1,61 @@
+package cron
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/testutil/mocks"
+)
+
+func TestRenderPlist(t *testing.T) {
+	t.Parallel()
+
+	content, err := RenderPlist(Job{Name: "backup", Schedule: "30 2 * * *", Command: "restic backup ~/src"})
+	require.NoError(t, err)
+	plist := string(content)
+	assert.Contains(t, plist, "<string>com.preflight.cron.backup</string>")
+	assert.Contains(t, plist, "<string>restic backup ~/src</string>")
+	assert.Contains(t, plist, "<key>Hour</key>\n\t\t\t<integer>2</integer>")
+
+	content, err = RenderPlist(Job{Name: "login", Schedule: "@reboot", Command: "date"})
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "<key>RunAtLoad</key>")
+}
+
+func TestLaunchdStep(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	stale := filepath.Join(dir, LabelPrefix+"old.plist")
+	manual := filepath.Join(dir, "com.example.agent.plist")
+	require.NoError(t, os.WriteFile(stale, []byte("old"), 0o644))
+	require.NoError(t, os.WriteFile(manual, []byte("mine"), 0o644))
+
+	job := Job{Name: "backup", Schedule: "@daily", Command: "restic backup", Backend: BackendLaunchd}
+	path := filepath.Join(dir, LabelPrefix+"backup.plist")
+	runner := mocks.NewCommandRunner()
+	runner.AddResult("launchctl", []string{"load", path}, ports.CommandResult{})
+	runner.AddResult("launchctl", []string{"unload", stale}, ports.CommandResult{})
+	step := NewLaunchdStep([]Job{job}, dir, runner)
+	ctx := compiler.NewRunContext(context.Background())
+
+	status, err := step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+
+	require.NoError(t, step.Apply(ctx))
+	assert.FileExists(t, path)
+	assert.NoFileExists(t, stale)
+	assert.FileExists(t, manual)
+
+	status, err = step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, status)
+}
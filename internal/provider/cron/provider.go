@@ -0,0 +1,53 @@
+package cron
+
+import (
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+)
+
+// Provider implements the compiler.Provider interface for scheduled jobs.
+type Provider struct {
+	runner ports.CommandRunner
+}
+
+// NewProvider creates a new cron provider.
+func NewProvider(runner ports.CommandRunner) *Provider {
+	return &Provider{runner: runner}
+}
+
+// Name returns the provider name.
+func (p *Provider) Name() string {
+	return "cron"
+}
+
+// Compile transforms cron configuration into executable steps. The
+// crontab step is produced unless every job uses launchd, so an empty job
+// list still removes preflight-owned crontab entries.
+func (p *Provider) Compile(ctx compiler.CompileContext) ([]compiler.Step, error) {
+	rawConfig := ctx.GetSection("cron")
+	if rawConfig == nil {
+		return nil, nil
+	}
+
+	cfg, err := ParseConfig(rawConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var steps []compiler.Step
+	crontab, launchd := cfg.JobsFor(BackendCrontab), cfg.JobsFor(BackendLaunchd)
+	if len(crontab) > 0 || len(launchd) == 0 {
+		steps = append(steps, NewCrontabStep(crontab, p.runner))
+	}
+	if len(launchd) > 0 {
+		agentsDir, err := AgentsDir()
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, NewLaunchdStep(launchd, agentsDir, p.runner))
+	}
+	return steps, nil
+}
+
+// Ensure Provider implements compiler.Provider.
+var _ compiler.Provider = (*Provider)(nil)
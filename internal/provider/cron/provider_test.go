@@ -0,0 +1,38 @@
+package cron
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/testutil/mocks"
+)
+
+func TestProvider_Compile(t *testing.T) {
+	t.Parallel()
+
+	provider := NewProvider(mocks.NewCommandRunner())
+	assert.Equal(t, "cron", provider.Name())
+
+	steps, err := provider.Compile(compiler.NewCompileContext(map[string]interface{}{}))
+	require.NoError(t, err)
+	assert.Empty(t, steps)
+
+	steps, err = provider.Compile(compiler.NewCompileContext(map[string]interface{}{
+		"cron": map[string]interface{}{"jobs": []interface{}{}},
+	}))
+	require.NoError(t, err)
+	require.Len(t, steps, 1)
+	assert.Equal(t, "cron:crontab", steps[0].ID().String())
+
+	steps, err = provider.Compile(compiler.NewCompileContext(map[string]interface{}{
+		"cron": map[string]interface{}{"jobs": []interface{}{
+			map[string]interface{}{"name": "backup", "schedule": "@daily", "command": "restic backup", "backend": "launchd"},
+		}},
+	}))
+	require.NoError(t, err)
+	require.Len(t, steps, 1)
+	assert.Equal(t, "cron:launchd", steps[0].ID().String())
+}
@@ -0,0 +1,165 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxCalendarIntervals bounds how many launchd calendar entries a single
+// schedule may expand to.
+const maxCalendarIntervals = 100
+
+// Schedule is a parsed cron expression. A nil field matches every value.
+type Schedule struct {
+	Minute   []int
+	Hour     []int
+	Day      []int
+	Month    []int
+	Weekday  []int
+	AtReboot bool
+}
+
+var scheduleMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var scheduleFields = []struct {
+	name     string
+	min, max int
+}{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day", 1, 31},
+	{"month", 1, 12},
+	{"weekday", 0, 7},
+}
+
+// ParseSchedule parses a five-field cron expression or one of the @yearly,
+// @monthly, @weekly, @daily, @hourly and @reboot macros. Fields support
+// '*', numbers, ranges (a-b), lists (a,b) and steps (*/n, a-b/n).
+func ParseSchedule(expr string) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "@reboot" {
+		return Schedule{AtReboot: true}, nil
+	}
+	if macro, ok := scheduleMacros[expr]; ok {
+		expr = macro
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != len(scheduleFields) {
+		return Schedule{}, fmt.Errorf("schedule %q must have 5 fields (minute hour day month weekday)", expr)
+	}
+
+	values := make([][]int, len(fields))
+	for i, field := range fields {
+		spec := scheduleFields[i]
+		parsed, err := parseField(field, spec.min, spec.max)
+		if err != nil {
+			return Schedule{}, fmt.Errorf("schedule %q: invalid %s: %w", expr, spec.name, err)
+		}
+		values[i] = parsed
+	}
+	for i, day := range values[4] {
+		if day == 7 {
+			values[4][i] = 0
+		}
+	}
+	return Schedule{Minute: values[0], Hour: values[1], Day: values[2], Month: values[3], Weekday: values[4]}, nil
+}
+
+func parseField(field string, minValue, maxValue int) ([]int, error) {
+	if field == "*" {
+		return nil, nil
+	}
+	seen := make(map[int]bool)
+	var values []int
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("bad step %q", stepPart)
+			}
+			step = n
+		}
+		lo, hi := minValue, maxValue
+		if rangePart != "*" {
+			from, to, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = strconv.Atoi(from); err != nil {
+				return nil, fmt.Errorf("bad value %q", from)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(to); err != nil {
+					return nil, fmt.Errorf("bad value %q", to)
+				}
+			} else if hasStep {
+				hi = maxValue
+			}
+		}
+		if lo < minValue || hi > maxValue || lo > hi {
+			return nil, fmt.Errorf("%q is outside %d-%d", part, minValue, maxValue)
+		}
+		for v := lo; v <= hi; v += step {
+			if !seen[v] {
+				seen[v] = true
+				values = append(values, v)
+			}
+		}
+	}
+	return values, nil
+}
+
+// CalendarIntervals expands the schedule into launchd StartCalendarInterval
+// entries. An empty result means the schedule runs every minute.
+//
+// Unlike cron, launchd requires both day and weekday to match when both are
+// set, so schedules restricting both are rejected.
+func (s Schedule) CalendarIntervals() ([]map[string]interface{}, error) {
+	if s.Day != nil && s.Weekday != nil {
+		return nil, fmt.Errorf("launchd cannot schedule by both day of month and weekday")
+	}
+	intervals := []map[string]interface{}{{}}
+	for _, field := range []struct {
+		key    string
+		values []int
+	}{
+		{"Minute", s.Minute},
+		{"Hour", s.Hour},
+		{"Day", s.Day},
+		{"Month", s.Month},
+		{"Weekday", s.Weekday},
+	} {
+		if field.values == nil {
+			continue
+		}
+		expanded := make([]map[string]interface{}, 0, len(intervals)*len(field.values))
+		for _, interval := range intervals {
+			for _, v := range field.values {
+				next := make(map[string]interface{}, len(interval)+1)
+				for k, existing := range interval {
+					next[k] = existing
+				}
+				next[field.key] = v
+				expanded = append(expanded, next)
+			}
+		}
+		if len(expanded) > maxCalendarIntervals {
+			return nil, fmt.Errorf("schedule expands to more than %d launchd calendar entries", maxCalendarIntervals)
+		}
+		intervals = expanded
+	}
+	if len(intervals) == 1 && len(intervals[0]) == 0 {
+		return nil, nil
+	}
+	return intervals, nil
+}
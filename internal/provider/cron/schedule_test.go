@@ -0,0 +1,61 @@
+package cron
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSchedule(t *testing.T) {
+	t.Parallel()
+
+	s, err := ParseSchedule("*/15 9-17 * * 1-5")
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 15, 30, 45}, s.Minute)
+	assert.Equal(t, []int{9, 10, 11, 12, 13, 14, 15, 16, 17}, s.Hour)
+	assert.Nil(t, s.Day)
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, s.Weekday)
+
+	s, err = ParseSchedule("@weekly")
+	require.NoError(t, err)
+	assert.Equal(t, Schedule{Minute: []int{0}, Hour: []int{0}, Weekday: []int{0}}, s)
+
+	s, err = ParseSchedule("0 0 * * 7")
+	require.NoError(t, err)
+	assert.Equal(t, []int{0}, s.Weekday)
+
+	s, err = ParseSchedule("@reboot")
+	require.NoError(t, err)
+	assert.True(t, s.AtReboot)
+
+	for _, invalid := range []string{"", "* * * *", "60 * * * *", "0 0 32 * *", "*/0 * * * *", "5-1 * * * *", "@often"} {
+		_, err := ParseSchedule(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestSchedule_CalendarIntervals(t *testing.T) {
+	t.Parallel()
+
+	s, _ := ParseSchedule("30 9,17 * * 1")
+	intervals, err := s.CalendarIntervals()
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{
+		{"Minute": 30, "Hour": 9, "Weekday": 1},
+		{"Minute": 30, "Hour": 17, "Weekday": 1},
+	}, intervals)
+
+	s, _ = ParseSchedule("* * * * *")
+	intervals, err = s.CalendarIntervals()
+	require.NoError(t, err)
+	assert.Empty(t, intervals)
+
+	s, _ = ParseSchedule("0 0 1 * 1")
+	_, err = s.CalendarIntervals()
+	require.Error(t, err)
+
+	s, _ = ParseSchedule("*/5 */2 * * *")
+	_, err = s.CalendarIntervals()
+	require.Error(t, err, "144 entries exceed the limit")
+}
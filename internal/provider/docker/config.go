@@ -3,11 +3,27 @@ package docker
 
 import (
 	"fmt"
+	"strings"
+)
+
+// Engine is the container runtime that provides the Docker API.
+type Engine string
+
+// Supported engines.
+const (
+	// EngineDesktop is Docker Desktop (or Docker Engine on Linux).
+	EngineDesktop Engine = "desktop"
+	// EngineColima runs the Docker daemon in a Colima VM.
+	EngineColima Engine = "colima"
+	// EnginePodman is Podman with a Podman machine.
+	EnginePodman Engine = "podman"
 )
 
 // Config represents the docker section of the configuration.
 type Config struct {
-	// Install enables Docker Desktop installation
+	// Engine selects the container runtime (default: desktop)
+	Engine Engine
+	// Install enables installation of the engine
 	Install bool
 	// Compose enables Docker Compose
 	Compose bool
@@ -21,6 +37,10 @@ type Config struct {
 	Registries []Registry
 	// Contexts configures Docker contexts for multi-host management
 	Contexts []Context
+	// Daemon holds additional daemon.json settings
+	Daemon map[string]interface{}
+	// CredsStore is the credential helper written to ~/.docker/config.json
+	CredsStore string
 }
 
 // ResourceLimits defines Docker Desktop resource allocation.
@@ -31,11 +51,31 @@ type ResourceLimits struct {
 	Disk   string `yaml:"disk"`   // e.g., "60GB", "100GB"
 }
 
-// Registry represents a container registry configuration.
+// Registry represents a container registry configuration. A registry with
+// a username and password is logged into; the password must be a secret
+// reference (secret://backend/key).
 type Registry struct {
 	URL      string `yaml:"url"`
 	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
 	Insecure bool   `yaml:"insecure,omitempty"`
+	// Mirror adds the registry as a pull-through mirror of Docker Hub
+	Mirror bool `yaml:"mirror,omitempty"`
+}
+
+// Host returns the registry host without scheme or path.
+func (r Registry) Host() string {
+	host := r.URL
+	if i := strings.Index(host, "://"); i >= 0 {
+		host = host[i+3:]
+	}
+	host, _, _ = strings.Cut(host, "/")
+	return host
+}
+
+// NeedsLogin reports whether the registry declares credentials.
+func (r Registry) NeedsLogin() bool {
+	return r.Password != ""
 }
 
 // Context represents a Docker context for multi-host management.
@@ -49,6 +89,7 @@ type Context struct {
 // ParseConfig parses the docker configuration from a raw map.
 func ParseConfig(raw map[string]interface{}) (*Config, error) {
 	cfg := &Config{
+		Engine:     EngineDesktop,
 		Install:    true, // Default to installing Docker
 		Compose:    true, // Docker Compose is included by default
 		Kubernetes: false,
@@ -57,6 +98,16 @@ func ParseConfig(raw map[string]interface{}) (*Config, error) {
 		Contexts:   make([]Context, 0),
 	}
 
+	// Parse engine
+	if engine, ok := raw["engine"].(string); ok && engine != "" {
+		switch Engine(engine) {
+		case EngineDesktop, EngineColima, EnginePodman:
+			cfg.Engine = Engine(engine)
+		default:
+			return nil, fmt.Errorf("unknown engine %q (use desktop, colima or podman)", engine)
+		}
+	}
+
 	// Parse install
 	if install, ok := raw["install"]; ok {
 		if b, ok := install.(bool); ok {
@@ -92,8 +143,11 @@ func ParseConfig(raw map[string]interface{}) (*Config, error) {
 			return nil, fmt.Errorf("resource_limits must be an object")
 		}
 		cfg.ResourceLimits = &ResourceLimits{}
-		if cpus, ok := limitsMap["cpus"].(int); ok {
+		switch cpus := limitsMap["cpus"].(type) {
+		case int:
 			cfg.ResourceLimits.CPUs = cpus
+		case float64:
+			cfg.ResourceLimits.CPUs = int(cpus)
 		}
 		if memory, ok := limitsMap["memory"].(string); ok {
 			cfg.ResourceLimits.Memory = memory
@@ -104,6 +158,15 @@ func ParseConfig(raw map[string]interface{}) (*Config, error) {
 		if disk, ok := limitsMap["disk"].(string); ok {
 			cfg.ResourceLimits.Disk = disk
 		}
+		for name, size := range map[string]string{
+			"memory": cfg.ResourceLimits.Memory,
+			"swap":   cfg.ResourceLimits.Swap,
+			"disk":   cfg.ResourceLimits.Disk,
+		} {
+			if _, err := parseSizeMiB(size); err != nil {
+				return nil, fmt.Errorf("resource_limits.%s: %w", name, err)
+			}
+		}
 	}
 
 	// Parse registries
@@ -121,6 +184,22 @@ func ParseConfig(raw map[string]interface{}) (*Config, error) {
 		}
 	}
 
+	// Parse daemon settings
+	if daemon, ok := raw["daemon"]; ok {
+		daemonMap, ok := daemon.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("daemon must be an object")
+		}
+		if cfg.Engine == EnginePodman {
+			return nil, fmt.Errorf("daemon settings are not supported with podman, which has no daemon")
+		}
+		cfg.Daemon = daemonMap
+	}
+
+	if credsStore, ok := raw["creds_store"].(string); ok {
+		cfg.CredsStore = credsStore
+	}
+
 	// Parse contexts
 	if contexts, ok := raw["contexts"]; ok {
 		contextList, ok := contexts.([]interface{})
@@ -136,6 +215,13 @@ func ParseConfig(raw map[string]interface{}) (*Config, error) {
 		}
 	}
 
+	if cfg.Kubernetes && cfg.Engine != EngineDesktop {
+		return nil, fmt.Errorf("kubernetes is only supported with the desktop engine")
+	}
+	if len(cfg.Contexts) > 0 && cfg.Engine == EnginePodman {
+		return nil, fmt.Errorf("docker contexts are not supported with podman")
+	}
+
 	return cfg, nil
 }
 
@@ -154,9 +240,23 @@ func parseRegistry(raw interface{}) (Registry, error) {
 		if username, ok := v["username"].(string); ok {
 			registry.Username = username
 		}
+		if password, ok := v["password"].(string); ok {
+			registry.Password = password
+		}
 		if insecure, ok := v["insecure"].(bool); ok {
 			registry.Insecure = insecure
 		}
+		if mirror, ok := v["mirror"].(bool); ok {
+			registry.Mirror = mirror
+		}
+		if registry.Password != "" {
+			if !strings.HasPrefix(registry.Password, "secret://") {
+				return Registry{}, fmt.Errorf("registry %s: password must be a secret reference (secret://backend/key)", registry.URL)
+			}
+			if registry.Username == "" {
+				return Registry{}, fmt.Errorf("registry %s: password requires a username", registry.URL)
+			}
+		}
 		return registry, nil
 	default:
 		return Registry{}, fmt.Errorf("registry must be a string or object")
@@ -220,3 +220,47 @@ func TestParseConfig_DisabledInstall(t *testing.T) {
 
 	assert.False(t, cfg.Install)
 }
+
+func TestParseConfig_Engine(t *testing.T) {
+	cfg, err := ParseConfig(map[string]interface{}{"engine": "colima"})
+	require.NoError(t, err)
+	assert.Equal(t, EngineColima, cfg.Engine)
+
+	_, err = ParseConfig(map[string]interface{}{"engine": "lima"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown engine")
+
+	_, err = ParseConfig(map[string]interface{}{"engine": "podman", "kubernetes": true})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "only supported with the desktop engine")
+
+	_, err = ParseConfig(map[string]interface{}{"engine": "podman", "daemon": map[string]interface{}{"debug": true}})
+	require.Error(t, err)
+}
+
+func TestParseConfig_RegistryPassword(t *testing.T) {
+	cfg, err := ParseConfig(map[string]interface{}{
+		"registries": []interface{}{
+			map[string]interface{}{"url": "https://ghcr.io/v2", "username": "octocat", "password": "secret://env/GHCR"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ghcr.io", cfg.Registries[0].Host())
+	assert.True(t, cfg.Registries[0].NeedsLogin())
+
+	_, err = ParseConfig(map[string]interface{}{
+		"registries": []interface{}{
+			map[string]interface{}{"url": "ghcr.io", "username": "octocat", "password": "hunter2"},
+		},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "secret reference")
+}
+
+func TestParseConfig_ResourceLimits_InvalidSize(t *testing.T) {
+	_, err := ParseConfig(map[string]interface{}{
+		"resource_limits": map[string]interface{}{"memory": "lots"},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "resource_limits.memory")
+}
@@ -1,6 +1,10 @@
 package docker
 
 import (
+	"os"
+	"path/filepath"
+	"runtime"
+
 	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
 	"github.com/felixgeelhaar/preflight/internal/ports"
 )
@@ -34,13 +38,13 @@ func (p *Provider) Compile(ctx compiler.CompileContext) ([]compiler.Step, error)
 
 	steps := make([]compiler.Step, 0)
 
-	// Add Docker Desktop installation step
+	// Add engine installation step
 	if cfg.Install {
-		steps = append(steps, NewInstallStep(p.runner))
+		steps = append(steps, NewEngineInstallStep(cfg.Engine, p.runner))
 	}
 
-	// Add BuildKit configuration step
-	if cfg.BuildKit {
+	// Add BuildKit configuration step (Podman builds with Buildah)
+	if cfg.BuildKit && cfg.Engine != EnginePodman {
 		steps = append(steps, NewBuildKitStep(cfg.Install, p.runner))
 	}
 
@@ -49,6 +53,40 @@ func (p *Provider) Compile(ctx compiler.CompileContext) ([]compiler.Step, error)
 		steps = append(steps, NewKubernetesStep(cfg.Install, p.runner))
 	}
 
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	// Add resource limits step
+	if cfg.ResourceLimits != nil {
+		steps = append(steps, NewResourcesStep(cfg.Engine, cfg.ResourceLimits, desktopSettingsPath(home), cfg.Install, p.runner))
+	}
+
+	// Add daemon and registry configuration step
+	if daemonPath := daemonConfigPath(cfg, home); daemonPath != "" {
+		steps = append(steps, NewDaemonConfigStep(cfg.Engine, cfg, daemonPath, cfg.Install))
+	}
+
+	// Add credential store step
+	cliConfigPath := filepath.Join(home, ".docker", "config.json")
+	loginDeps := make([]compiler.StepID, 0, 2)
+	if cfg.Install {
+		loginDeps = append(loginDeps, compiler.MustNewStepID("docker:install"))
+	}
+	if cfg.CredsStore != "" {
+		config := NewCLIConfigStep(cfg.CredsStore, cliConfigPath, cfg.Install)
+		steps = append(steps, config)
+		loginDeps = append(loginDeps, config.ID())
+	}
+
+	// Add registry login steps
+	for _, registry := range cfg.Registries {
+		if registry.NeedsLogin() {
+			steps = append(steps, NewLoginStep(cfg.Engine, registry, cliConfigPath, loginDeps, p.runner))
+		}
+	}
+
 	// Add context steps
 	for _, context := range cfg.Contexts {
 		steps = append(steps, NewContextStep(context, cfg.Install, p.runner))
@@ -57,5 +95,33 @@ func (p *Provider) Compile(ctx compiler.CompileContext) ([]compiler.Step, error)
 	return steps, nil
 }
 
+// desktopSettingsPath returns Docker Desktop's settings-store.json.
+func desktopSettingsPath(home string) string {
+	if runtime.GOOS == "darwin" {
+		return filepath.Join(home, "Library", "Group Containers", "group.com.docker", "settings-store.json")
+	}
+	return filepath.Join(home, ".docker", "desktop", "settings-store.json")
+}
+
+// daemonConfigPath returns where the engine reads daemon and registry
+// settings, or "" when cfg declares none.
+func daemonConfigPath(cfg *Config, home string) string {
+	if cfg.Engine == EnginePodman {
+		for _, r := range cfg.Registries {
+			if r.Insecure || r.Mirror {
+				return filepath.Join(home, ".config", "containers", "registries.conf.d", "preflight.conf")
+			}
+		}
+		return ""
+	}
+	if len(DaemonSettings(cfg)) == 0 {
+		return ""
+	}
+	if cfg.Engine == EngineColima {
+		return filepath.Join(home, ".colima", "default", "colima.yaml")
+	}
+	return filepath.Join(home, ".docker", "daemon.json")
+}
+
 // Ensure Provider implements compiler.Provider.
 var _ compiler.Provider = (*Provider)(nil)
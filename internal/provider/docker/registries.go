@@ -0,0 +1,457 @@
+package docker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/provider/secretutil"
+)
+
+// dockerHubAuthKey is the config.json auths key Docker uses for Docker Hub.
+const dockerHubAuthKey = "https://index.docker.io/v1/"
+
+// podmanRegistriesHeader marks the registries.conf drop-in preflight writes.
+const podmanRegistriesHeader = "# Managed by preflight - do not edit manually\n"
+
+// DaemonSettings returns the daemon.json settings for cfg: the declared
+// daemon settings plus insecure registries and registry mirrors.
+func DaemonSettings(cfg *Config) map[string]interface{} {
+	settings := make(map[string]interface{}, len(cfg.Daemon)+2)
+	for key, value := range cfg.Daemon {
+		settings[key] = value
+	}
+	var insecure, mirrors []interface{}
+	for _, r := range cfg.Registries {
+		if r.Insecure {
+			insecure = append(insecure, r.Host())
+		}
+		if r.Mirror {
+			mirror := r.URL
+			if !strings.Contains(mirror, "://") {
+				mirror = "https://" + mirror
+			}
+			mirrors = append(mirrors, mirror)
+		}
+	}
+	if len(insecure) > 0 {
+		settings["insecure-registries"] = insecure
+	}
+	if len(mirrors) > 0 {
+		settings["registry-mirrors"] = mirrors
+	}
+	return settings
+}
+
+// DaemonConfigStep writes daemon settings and registry configuration where
+// the engine reads them: ~/.docker/daemon.json for Docker Desktop, the
+// docker section of colima.yaml for Colima, and a registries.conf drop-in
+// for Podman.
+type DaemonConfigStep struct {
+	engine          Engine
+	cfg             *Config
+	path            string
+	requiresInstall bool
+}
+
+// NewDaemonConfigStep creates a daemon configuration step writing to path.
+func NewDaemonConfigStep(engine Engine, cfg *Config, path string, requiresInstall bool) *DaemonConfigStep {
+	return &DaemonConfigStep{
+		engine:          engine,
+		cfg:             cfg,
+		path:            path,
+		requiresInstall: requiresInstall,
+	}
+}
+
+// ID returns the step identifier.
+func (s *DaemonConfigStep) ID() compiler.StepID {
+	return compiler.MustNewStepID("docker:daemon")
+}
+
+// DependsOn returns the step dependencies.
+func (s *DaemonConfigStep) DependsOn() []compiler.StepID {
+	if s.requiresInstall {
+		return []compiler.StepID{compiler.MustNewStepID("docker:install")}
+	}
+	return nil
+}
+
+// Check determines if the daemon configuration is up to date.
+func (s *DaemonConfigStep) Check(_ compiler.RunContext) (compiler.StepStatus, error) {
+	if s.engine == EnginePodman {
+		// #nosec G304 -- path is preflight's own registries.conf drop-in.
+		existing, err := os.ReadFile(s.path)
+		if err == nil && string(existing) == s.podmanRegistries() {
+			return compiler.StatusSatisfied, nil
+		}
+		return compiler.StatusNeedsApply, nil
+	}
+
+	current, err := s.currentSettings()
+	if err != nil {
+		return compiler.StatusNeedsApply, nil //nolint:nilerr // intentional: missing or invalid config means needs apply
+	}
+	for key, value := range DaemonSettings(s.cfg) {
+		if !jsonEqual(current[key], value) {
+			return compiler.StatusNeedsApply, nil
+		}
+	}
+	return compiler.StatusSatisfied, nil
+}
+
+// currentSettings reads the daemon settings the engine uses today.
+func (s *DaemonConfigStep) currentSettings() (map[string]interface{}, error) {
+	if s.engine != EngineColima {
+		return readJSONFile(s.path)
+	}
+	// #nosec G304 -- path is Colima's profile config.
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+	var colima struct {
+		Docker map[string]interface{} `yaml:"docker"`
+	}
+	if err := yaml.Unmarshal(data, &colima); err != nil {
+		return nil, err
+	}
+	return colima.Docker, nil
+}
+
+// Plan returns the diff for this step.
+func (s *DaemonConfigStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
+	keys := sortedKeys(DaemonSettings(s.cfg))
+	if s.engine == EnginePodman {
+		keys = nil
+		for _, r := range s.cfg.Registries {
+			if r.Insecure || r.Mirror {
+				keys = append(keys, r.Host())
+			}
+		}
+	}
+	return compiler.NewDiff(compiler.DiffTypeModify, "daemon", s.path, "", strings.Join(keys, ", ")), nil
+}
+
+// Apply writes the configuration. Existing settings that preflight does not
+// manage are kept.
+func (s *DaemonConfigStep) Apply(_ compiler.RunContext) error {
+	switch s.engine {
+	case EnginePodman:
+		if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(s.path, []byte(s.podmanRegistries()), 0o644)
+	case EngineColima:
+		return s.applyColima()
+	default:
+		settings, err := readJSONFile(s.path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if settings == nil {
+			settings = make(map[string]interface{})
+		}
+		for key, value := range DaemonSettings(s.cfg) {
+			settings[key] = value
+		}
+		return writeJSONFile(s.path, settings)
+	}
+}
+
+// applyColima sets the docker section of colima.yaml, keeping the comments
+// and other settings Colima writes there. Colima applies it on next start.
+func (s *DaemonConfigStep) applyColima() error {
+	var doc yaml.Node
+	// #nosec G304 -- path is Colima's profile config.
+	if data, err := os.ReadFile(s.path); err == nil {
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", s.path, err)
+		}
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s is not a YAML mapping", s.path)
+	}
+
+	current, _ := s.currentSettings()
+	if current == nil {
+		current = make(map[string]interface{})
+	}
+	for key, value := range DaemonSettings(s.cfg) {
+		current[key] = value
+	}
+	var value yaml.Node
+	if err := value.Encode(current); err != nil {
+		return err
+	}
+
+	replaced := false
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "docker" {
+			root.Content[i+1] = &value
+			replaced = true
+		}
+	}
+	if !replaced {
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "docker"}, &value)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, buf.Bytes(), 0o644)
+}
+
+// podmanRegistries renders the registries.conf drop-in for Podman.
+func (s *DaemonConfigStep) podmanRegistries() string {
+	var b strings.Builder
+	b.WriteString(podmanRegistriesHeader)
+	var mirrors []Registry
+	for _, r := range s.cfg.Registries {
+		if r.Mirror {
+			mirrors = append(mirrors, r)
+		}
+		if r.Insecure {
+			fmt.Fprintf(&b, "\n[[registry]]\nlocation = %q\ninsecure = true\n", r.Host())
+		}
+	}
+	if len(mirrors) > 0 {
+		b.WriteString("\n[[registry]]\nprefix = \"docker.io\"\nlocation = \"docker.io\"\n")
+		for _, r := range mirrors {
+			fmt.Fprintf(&b, "\n[[registry.mirror]]\nlocation = %q\n", r.Host())
+		}
+	}
+	return b.String()
+}
+
+// Explain provides a human-readable explanation.
+func (s *DaemonConfigStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
+	return compiler.NewExplanation(
+		"Configure Container Daemon",
+		"Declares daemon settings, insecure registries and registry mirrors for the container engine.",
+		[]string{
+			"https://docs.docker.com/reference/cli/dockerd/#daemon-configuration-file",
+			"https://github.com/containers/image/blob/main/docs/containers-registries.conf.5.md",
+		},
+	).WithTradeoffs([]string{
+		"+ Registry access works the same on every machine",
+		"- The engine must be restarted to pick up changes",
+	})
+}
+
+// CLIConfigStep sets the credential store in ~/.docker/config.json.
+type CLIConfigStep struct {
+	credsStore      string
+	path            string
+	requiresInstall bool
+}
+
+// NewCLIConfigStep creates a step that sets credsStore in the Docker CLI config at path.
+func NewCLIConfigStep(credsStore, path string, requiresInstall bool) *CLIConfigStep {
+	return &CLIConfigStep{credsStore: credsStore, path: path, requiresInstall: requiresInstall}
+}
+
+// ID returns the step identifier.
+func (s *CLIConfigStep) ID() compiler.StepID {
+	return compiler.MustNewStepID("docker:config")
+}
+
+// DependsOn returns the step dependencies.
+func (s *CLIConfigStep) DependsOn() []compiler.StepID {
+	if s.requiresInstall {
+		return []compiler.StepID{compiler.MustNewStepID("docker:install")}
+	}
+	return nil
+}
+
+// Check determines if the credential store is configured.
+func (s *CLIConfigStep) Check(_ compiler.RunContext) (compiler.StepStatus, error) {
+	config, err := readJSONFile(s.path)
+	if err == nil && config["credsStore"] == s.credsStore {
+		return compiler.StatusSatisfied, nil
+	}
+	return compiler.StatusNeedsApply, nil
+}
+
+// Plan returns the diff for this step.
+func (s *CLIConfigStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
+	return compiler.NewDiff(compiler.DiffTypeModify, "config", "credsStore", "", s.credsStore), nil
+}
+
+// Apply writes credsStore, keeping the rest of config.json.
+func (s *CLIConfigStep) Apply(_ compiler.RunContext) error {
+	config, err := readJSONFile(s.path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if config == nil {
+		config = make(map[string]interface{})
+	}
+	config["credsStore"] = s.credsStore
+	return writeJSONFile(s.path, config)
+}
+
+// Explain provides a human-readable explanation.
+func (s *CLIConfigStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
+	return compiler.NewExplanation(
+		"Configure Docker Credential Store",
+		fmt.Sprintf("Stores registry credentials with docker-credential-%s instead of in config.json.", s.credsStore),
+		[]string{"https://docs.docker.com/reference/cli/docker/login/#credential-stores"},
+	).WithTradeoffs([]string{
+		"+ Registry passwords are kept out of plain-text files",
+		"- The credential helper must be installed",
+	})
+}
+
+// LoginStep logs into a registry with a password read from a secret
+// backend. The password is piped from 'preflight secrets get' to the
+// engine's login command, so it never appears in arguments or on disk.
+type LoginStep struct {
+	engine     Engine
+	registry   Registry
+	configPath string
+	deps       []compiler.StepID
+	runner     ports.CommandRunner
+}
+
+// NewLoginStep creates a registry login step. configPath is the Docker CLI
+// config.json consulted to see whether credentials are already stored.
+func NewLoginStep(engine Engine, registry Registry, configPath string, deps []compiler.StepID, runner ports.CommandRunner) *LoginStep {
+	return &LoginStep{
+		engine:     engine,
+		registry:   registry,
+		configPath: configPath,
+		deps:       deps,
+		runner:     runner,
+	}
+}
+
+// ID returns the step identifier.
+func (s *LoginStep) ID() compiler.StepID {
+	return compiler.MustNewStepID("docker:login:" + s.registry.Host())
+}
+
+// DependsOn returns the step dependencies.
+func (s *LoginStep) DependsOn() []compiler.StepID {
+	return s.deps
+}
+
+// Check determines if credentials for the registry are stored.
+func (s *LoginStep) Check(ctx compiler.RunContext) (compiler.StepStatus, error) {
+	if s.engine == EnginePodman {
+		result, err := s.runner.Run(ctx.Context(), "podman", "login", "--get-login", s.registry.Host())
+		if err == nil && result.Success() {
+			return compiler.StatusSatisfied, nil
+		}
+		return compiler.StatusNeedsApply, nil
+	}
+
+	config, err := readJSONFile(s.configPath)
+	if err != nil {
+		return compiler.StatusNeedsApply, nil //nolint:nilerr // intentional: no config means not logged in
+	}
+	auths, _ := config["auths"].(map[string]interface{})
+	if _, ok := auths[s.authKey()]; ok {
+		return compiler.StatusSatisfied, nil
+	}
+	return compiler.StatusNeedsApply, nil
+}
+
+// authKey returns the key Docker stores the registry's credentials under.
+func (s *LoginStep) authKey() string {
+	switch host := s.registry.Host(); host {
+	case "docker.io", "index.docker.io", "registry-1.docker.io":
+		return dockerHubAuthKey
+	default:
+		return host
+	}
+}
+
+// Plan returns the diff for this step.
+func (s *LoginStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
+	return compiler.NewDiff(compiler.DiffTypeAdd, "login", s.registry.Host(), "", s.registry.Username), nil
+}
+
+// Apply logs into the registry.
+func (s *LoginStep) Apply(ctx compiler.RunContext) error {
+	ref := strings.TrimPrefix(s.registry.Password, "secret://")
+	backend, key, ok := strings.Cut(ref, "/")
+	if !ok {
+		return fmt.Errorf("registry %s: invalid secret reference %q", s.registry.Host(), s.registry.Password)
+	}
+	cli := "docker"
+	if s.engine == EnginePodman {
+		cli = "podman"
+	}
+	script := fmt.Sprintf("%s secrets get --backend %s %s | %s login %s --username %s --password-stdin",
+		shellQuote(secretutil.Command()), shellQuote(backend), shellQuote(key),
+		cli, shellQuote(s.registry.Host()), shellQuote(s.registry.Username))
+
+	result, err := s.runner.Run(ctx.Context(), "sh", "-c", script)
+	if err != nil {
+		return err
+	}
+	if !result.Success() {
+		return fmt.Errorf("%s login %s failed: %s", cli, s.registry.Host(), strings.TrimSpace(result.Stderr))
+	}
+	return nil
+}
+
+// Explain provides a human-readable explanation.
+func (s *LoginStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
+	return compiler.NewExplanation(
+		"Log In to Container Registry",
+		fmt.Sprintf("Logs into %s as %s using a password from the secret backend.", s.registry.Host(), s.registry.Username),
+		[]string{"https://docs.docker.com/reference/cli/docker/login/"},
+	).WithTradeoffs([]string{
+		"+ Pulls from private registries work right after setup",
+		"+ The password is never written to the configuration",
+		"- Tokens that expire need a new apply",
+	})
+}
+
+// shellQuote single-quotes s for sh.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// jsonEqual reports whether a and b encode to the same JSON, so values
+// decoded from YAML and JSON compare equal.
+func jsonEqual(a, b interface{}) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var (
+	_ compiler.Step = (*DaemonConfigStep)(nil)
+	_ compiler.Step = (*CLIConfigStep)(nil)
+	_ compiler.Step = (*LoginStep)(nil)
+)
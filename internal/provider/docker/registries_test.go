@@ -0,0 +1,172 @@
+package docker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/provider/secretutil"
+	"github.com/felixgeelhaar/preflight/internal/testutil/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDaemonSettings(t *testing.T) {
+	settings := DaemonSettings(&Config{
+		Daemon: map[string]interface{}{"debug": true},
+		Registries: []Registry{
+			{URL: "registry.local:5000", Insecure: true},
+			{URL: "mirror.gcr.io", Mirror: true},
+			{URL: "ghcr.io"},
+		},
+	})
+	assert.Equal(t, map[string]interface{}{
+		"debug":               true,
+		"insecure-registries": []interface{}{"registry.local:5000"},
+		"registry-mirrors":    []interface{}{"https://mirror.gcr.io"},
+	}, settings)
+}
+
+func TestDaemonConfigStep_Desktop(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daemon.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"features": {"buildkit": true}}`), 0o644))
+	cfg := &Config{Registries: []Registry{{URL: "registry.local:5000", Insecure: true}}}
+	step := NewDaemonConfigStep(EngineDesktop, cfg, path, false)
+	ctx := compiler.NewRunContext(context.Background())
+
+	status, err := step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+
+	require.NoError(t, step.Apply(ctx))
+	settings, err := readJSONFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"registry.local:5000"}, settings["insecure-registries"])
+	assert.Contains(t, settings, "features")
+
+	status, err = step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, status)
+}
+
+func TestDaemonConfigStep_Colima_KeepsComments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "colima.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("# Number of CPUs\ncpu: 2\ndocker: {}\n"), 0o644))
+	cfg := &Config{Daemon: map[string]interface{}{"debug": true}}
+	step := NewDaemonConfigStep(EngineColima, cfg, path, false)
+	ctx := compiler.NewRunContext(context.Background())
+
+	require.NoError(t, step.Apply(ctx))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# Number of CPUs")
+	assert.Contains(t, string(data), "debug: true")
+
+	status, err := step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, status)
+}
+
+func TestDaemonConfigStep_Podman(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "registries.conf.d", "preflight.conf")
+	cfg := &Config{Registries: []Registry{
+		{URL: "registry.local:5000", Insecure: true},
+		{URL: "https://mirror.gcr.io", Mirror: true},
+	}}
+	step := NewDaemonConfigStep(EnginePodman, cfg, path, false)
+	ctx := compiler.NewRunContext(context.Background())
+
+	require.NoError(t, step.Apply(ctx))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "location = \"registry.local:5000\"\ninsecure = true")
+	assert.Contains(t, string(data), "[[registry.mirror]]\nlocation = \"mirror.gcr.io\"")
+
+	status, err := step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, status)
+}
+
+func TestCLIConfigStep(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"auths": {"ghcr.io": {}}}`), 0o644))
+	step := NewCLIConfigStep("osxkeychain", path, false)
+	ctx := compiler.NewRunContext(context.Background())
+
+	status, err := step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+
+	require.NoError(t, step.Apply(ctx))
+	config, err := readJSONFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "osxkeychain", config["credsStore"])
+	assert.Contains(t, config, "auths")
+}
+
+func TestLoginStep_Check_DockerHub(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"auths": {"https://index.docker.io/v1/": {}}}`), 0o644))
+	registry := Registry{URL: "docker.io", Username: "octocat", Password: "secret://env/HUB"}
+	step := NewLoginStep(EngineDesktop, registry, path, nil, mocks.NewCommandRunner())
+
+	assert.Equal(t, "docker:login:docker.io", step.ID().String())
+	status, err := step.Check(compiler.NewRunContext(context.Background()))
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, status)
+}
+
+func TestLoginStep_Apply_PipesSecret(t *testing.T) {
+	runner := mocks.NewCommandRunner()
+	registry := Registry{URL: "ghcr.io", Username: "octocat", Password: "secret://1password/ghcr-token"}
+	step := NewLoginStep(EnginePodman, registry, "", nil, runner)
+	ctx := compiler.NewRunContext(context.Background())
+
+	status, err := step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+
+	script := shellQuote(secretutil.Command()) + " secrets get --backend '1password' 'ghcr-token' | " +
+		"podman login 'ghcr.io' --username 'octocat' --password-stdin"
+	runner.AddResult("sh", []string{"-c", script}, ports.CommandResult{})
+	require.NoError(t, step.Apply(ctx))
+
+	for _, call := range runner.Calls() {
+		assert.False(t, strings.Contains(strings.Join(call.Args, " "), "hunter2"))
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	assert.Equal(t, `'it'\''s'`, shellQuote("it's"))
+}
+
+func TestProvider_Compile_Registries(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	provider := NewProvider(mocks.NewCommandRunner())
+	ctx := compiler.NewCompileContext(map[string]interface{}{
+		"docker": map[string]interface{}{
+			"engine":      "podman",
+			"creds_store": "pass",
+			"resource_limits": map[string]interface{}{
+				"cpus": 4,
+			},
+			"registries": []interface{}{
+				map[string]interface{}{"url": "ghcr.io", "username": "octocat", "password": "secret://env/GHCR"},
+				map[string]interface{}{"url": "mirror.gcr.io", "mirror": true},
+			},
+		},
+	})
+
+	steps, err := provider.Compile(ctx)
+	require.NoError(t, err)
+	ids := make([]string, len(steps))
+	for i, step := range steps {
+		ids[i] = step.ID().String()
+	}
+	assert.Equal(t, []string{"docker:install", "docker:resources", "docker:daemon", "docker:config", "docker:login:ghcr.io"}, ids)
+	assert.Len(t, steps[4].DependsOn(), 2)
+}
@@ -0,0 +1,306 @@
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+)
+
+// parseSizeMiB converts a size such as "8GB", "512MiB" or "2g" to MiB.
+// Units are binary; an empty size is zero.
+func parseSizeMiB(size string) (int, error) {
+	s := strings.ToUpper(strings.TrimSpace(size))
+	if s == "" {
+		return 0, nil
+	}
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "B"), "I")
+	multiplier := 1.0
+	switch {
+	case strings.HasSuffix(s, "T"):
+		multiplier = 1024 * 1024
+	case strings.HasSuffix(s, "G"):
+		multiplier = 1024
+	case strings.HasSuffix(s, "M"):
+	default:
+		return 0, fmt.Errorf("invalid size %q: use a unit such as MB or GB", size)
+	}
+	value, err := strconv.ParseFloat(strings.TrimSpace(s[:len(s)-1]), 64)
+	if err != nil || value <= 0 {
+		return 0, fmt.Errorf("invalid size %q", size)
+	}
+	return int(value * multiplier), nil
+}
+
+// resources are the declared limits in the units the engines use.
+type resources struct {
+	cpus    int
+	memory  int // MiB
+	swap    int // MiB
+	diskMiB int
+}
+
+func newResources(limits *ResourceLimits) resources {
+	memory, _ := parseSizeMiB(limits.Memory)
+	swap, _ := parseSizeMiB(limits.Swap)
+	disk, _ := parseSizeMiB(limits.Disk)
+	return resources{cpus: limits.CPUs, memory: memory, swap: swap, diskMiB: disk}
+}
+
+// diskGiB rounds the disk size up to whole GiB, the unit colima and podman use.
+func (r resources) diskGiB() int {
+	return (r.diskMiB + 1023) / 1024
+}
+
+// ResourcesStep applies CPU, memory and disk limits to the engine's VM:
+// Docker Desktop's settings file, the Colima VM or the Podman machine.
+type ResourcesStep struct {
+	engine          Engine
+	limits          resources
+	settingsPath    string
+	requiresInstall bool
+	runner          ports.CommandRunner
+}
+
+// NewResourcesStep creates a resource limits step. settingsPath is Docker
+// Desktop's settings-store.json and is only used by the desktop engine.
+func NewResourcesStep(engine Engine, limits *ResourceLimits, settingsPath string, requiresInstall bool, runner ports.CommandRunner) *ResourcesStep {
+	return &ResourcesStep{
+		engine:          engine,
+		limits:          newResources(limits),
+		settingsPath:    settingsPath,
+		requiresInstall: requiresInstall,
+		runner:          runner,
+	}
+}
+
+// ID returns the step identifier.
+func (s *ResourcesStep) ID() compiler.StepID {
+	return compiler.MustNewStepID("docker:resources")
+}
+
+// DependsOn returns the step dependencies.
+func (s *ResourcesStep) DependsOn() []compiler.StepID {
+	if s.requiresInstall {
+		return []compiler.StepID{compiler.MustNewStepID("docker:install")}
+	}
+	return nil
+}
+
+// Check compares the engine's current resources with the declared limits.
+func (s *ResourcesStep) Check(ctx compiler.RunContext) (compiler.StepStatus, error) {
+	current, ok := s.current(ctx)
+	if !ok || !s.matches(current) {
+		return compiler.StatusNeedsApply, nil
+	}
+	return compiler.StatusSatisfied, nil
+}
+
+// matches reports whether current satisfies every declared limit.
+func (s *ResourcesStep) matches(current resources) bool {
+	want := s.limits
+	switch {
+	case want.cpus > 0 && current.cpus != want.cpus,
+		want.memory > 0 && current.memory != want.memory:
+		return false
+	case s.engine == EngineDesktop:
+		return (want.swap == 0 || current.swap == want.swap) && (want.diskMiB == 0 || current.diskMiB == want.diskMiB)
+	default:
+		return want.diskMiB == 0 || current.diskGiB() == want.diskGiB()
+	}
+}
+
+// current reads the engine's configured resources.
+func (s *ResourcesStep) current(ctx compiler.RunContext) (resources, bool) {
+	switch s.engine {
+	case EngineColima:
+		result, err := s.runner.Run(ctx.Context(), "colima", "list", "--json")
+		if err != nil || !result.Success() {
+			return resources{}, false
+		}
+		for _, line := range strings.Split(strings.TrimSpace(result.Stdout), "\n") {
+			var vm struct {
+				Name   string `json:"name"`
+				CPUs   int    `json:"cpus"`
+				Memory int64  `json:"memory"`
+				Disk   int64  `json:"disk"`
+			}
+			if json.Unmarshal([]byte(line), &vm) == nil && vm.Name == "default" {
+				return resources{cpus: vm.CPUs, memory: int(vm.Memory >> 20), diskMiB: int(vm.Disk >> 20)}, true
+			}
+		}
+		return resources{}, false
+	case EnginePodman:
+		result, err := s.runner.Run(ctx.Context(), "podman", "machine", "inspect")
+		if err != nil || !result.Success() {
+			return resources{}, false
+		}
+		var machines []struct {
+			Resources struct {
+				CPUs     int `json:"CPUs"`
+				Memory   int `json:"Memory"`
+				DiskSize int `json:"DiskSize"`
+			} `json:"Resources"`
+		}
+		if json.Unmarshal([]byte(result.Stdout), &machines) != nil || len(machines) == 0 {
+			return resources{}, false
+		}
+		r := machines[0].Resources
+		return resources{cpus: r.CPUs, memory: r.Memory, diskMiB: r.DiskSize * 1024}, true
+	default:
+		settings, err := readJSONFile(s.settingsPath)
+		if err != nil {
+			return resources{}, false
+		}
+		number := func(key string) int {
+			v, _ := settings[key].(float64)
+			return int(v)
+		}
+		return resources{
+			cpus:    number("Cpus"),
+			memory:  number("MemoryMiB"),
+			swap:    number("SwapMiB"),
+			diskMiB: number("DiskSizeMiB"),
+		}, true
+	}
+}
+
+// Plan returns the diff for this step.
+func (s *ResourcesStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
+	var parts []string
+	if s.limits.cpus > 0 {
+		parts = append(parts, fmt.Sprintf("%d CPUs", s.limits.cpus))
+	}
+	if s.limits.memory > 0 {
+		parts = append(parts, fmt.Sprintf("%d MiB memory", s.limits.memory))
+	}
+	if s.limits.swap > 0 && s.engine == EngineDesktop {
+		parts = append(parts, fmt.Sprintf("%d MiB swap", s.limits.swap))
+	}
+	if s.limits.diskMiB > 0 {
+		parts = append(parts, fmt.Sprintf("%d GiB disk", s.limits.diskGiB()))
+	}
+	return compiler.NewDiff(compiler.DiffTypeModify, "resources", string(s.engine), "", strings.Join(parts, ", ")), nil
+}
+
+// Apply configures the resources. Colima and Podman VMs are restarted so
+// the new limits take effect; Docker Desktop applies them on its next start.
+func (s *ResourcesStep) Apply(ctx compiler.RunContext) error {
+	switch s.engine {
+	case EngineColima:
+		args := []string{"start"}
+		if s.limits.cpus > 0 {
+			args = append(args, "--cpu", strconv.Itoa(s.limits.cpus))
+		}
+		if s.limits.memory > 0 {
+			args = append(args, "--memory", strconv.FormatFloat(float64(s.limits.memory)/1024, 'f', -1, 64))
+		}
+		if s.limits.diskMiB > 0 {
+			args = append(args, "--disk", strconv.Itoa(s.limits.diskGiB()))
+		}
+		_, _ = s.runner.Run(ctx.Context(), "colima", "stop")
+		return s.run(ctx, "colima", args...)
+	case EnginePodman:
+		var args []string
+		if s.limits.cpus > 0 {
+			args = append(args, "--cpus", strconv.Itoa(s.limits.cpus))
+		}
+		if s.limits.memory > 0 {
+			args = append(args, "--memory", strconv.Itoa(s.limits.memory))
+		}
+		if s.limits.diskMiB > 0 {
+			args = append(args, "--disk-size", strconv.Itoa(s.limits.diskGiB()))
+		}
+		if _, ok := s.current(ctx); !ok {
+			if err := s.run(ctx, "podman", append([]string{"machine", "init"}, args...)...); err != nil {
+				return err
+			}
+		} else {
+			_, _ = s.runner.Run(ctx.Context(), "podman", "machine", "stop")
+			if err := s.run(ctx, "podman", append([]string{"machine", "set"}, args...)...); err != nil {
+				return err
+			}
+		}
+		return s.run(ctx, "podman", "machine", "start")
+	default:
+		settings, err := readJSONFile(s.settingsPath)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if settings == nil {
+			settings = make(map[string]interface{})
+		}
+		for key, value := range map[string]int{
+			"Cpus":        s.limits.cpus,
+			"MemoryMiB":   s.limits.memory,
+			"SwapMiB":     s.limits.swap,
+			"DiskSizeMiB": s.limits.diskMiB,
+		} {
+			if value > 0 {
+				settings[key] = value
+			}
+		}
+		return writeJSONFile(s.settingsPath, settings)
+	}
+}
+
+func (s *ResourcesStep) run(ctx compiler.RunContext, name string, args ...string) error {
+	result, err := s.runner.Run(ctx.Context(), name, args...)
+	if err != nil {
+		return err
+	}
+	if !result.Success() {
+		return fmt.Errorf("%s %s failed: %s", name, strings.Join(args, " "), result.Stderr)
+	}
+	return nil
+}
+
+// Explain provides a human-readable explanation.
+func (s *ResourcesStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
+	return compiler.NewExplanation(
+		"Configure Container Resources",
+		fmt.Sprintf("Sets the CPU, memory and disk available to the %s VM.", s.engine),
+		[]string{
+			"https://docs.docker.com/desktop/settings-and-maintenance/settings/",
+			"https://github.com/abiosoft/colima",
+			"https://docs.podman.io/en/latest/markdown/podman-machine-set.1.html",
+		},
+	).WithTradeoffs([]string{
+		"+ Same limits on every machine",
+		"- Colima and Podman machines are restarted to apply changes",
+		"- Disks can usually grow but not shrink",
+	})
+}
+
+// readJSONFile reads a JSON object from path.
+func readJSONFile(path string) (map[string]interface{}, error) {
+	// #nosec G304 -- path is a well-known engine config file.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	settings := make(map[string]interface{})
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return settings, nil
+}
+
+// writeJSONFile writes settings to path as indented JSON.
+func writeJSONFile(path string, settings map[string]interface{}) error {
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+var _ compiler.Step = (*ResourcesStep)(nil)
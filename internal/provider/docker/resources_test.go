@@ -0,0 +1,100 @@
+package docker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/testutil/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSizeMiB(t *testing.T) {
+	tests := []struct {
+		size string
+		want int
+	}{
+		{"", 0},
+		{"512MB", 512},
+		{"8GB", 8192},
+		{"2g", 2048},
+		{"1.5GiB", 1536},
+		{"1T", 1024 * 1024},
+	}
+	for _, tt := range tests {
+		got, err := parseSizeMiB(tt.size)
+		require.NoError(t, err, tt.size)
+		assert.Equal(t, tt.want, got, tt.size)
+	}
+
+	for _, size := range []string{"8", "lots", "-1GB"} {
+		_, err := parseSizeMiB(size)
+		assert.Error(t, err, size)
+	}
+}
+
+func TestResourcesStep_Desktop(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings-store.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"Cpus": 2, "MemoryMiB": 2048, "AutoStart": true}`), 0o644))
+	step := NewResourcesStep(EngineDesktop, &ResourceLimits{CPUs: 4, Memory: "8GB"}, path, true, mocks.NewCommandRunner())
+	ctx := compiler.NewRunContext(context.Background())
+
+	assert.Equal(t, "docker:resources", step.ID().String())
+	assert.Len(t, step.DependsOn(), 1)
+
+	status, err := step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+
+	require.NoError(t, step.Apply(ctx))
+	settings, err := readJSONFile(path)
+	require.NoError(t, err)
+	assert.InDelta(t, 4.0, settings["Cpus"], 0)
+	assert.InDelta(t, 8192.0, settings["MemoryMiB"], 0)
+	assert.Equal(t, true, settings["AutoStart"])
+
+	status, err = step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, status)
+}
+
+func TestResourcesStep_Colima(t *testing.T) {
+	runner := mocks.NewCommandRunner()
+	runner.AddResult("colima", []string{"list", "--json"}, ports.CommandResult{
+		Stdout: `{"name":"default","status":"Running","cpus":2,"memory":2147483648,"disk":64424509440}`,
+	})
+	step := NewResourcesStep(EngineColima, &ResourceLimits{CPUs: 4, Memory: "6GB", Disk: "60GB"}, "", false, runner)
+	ctx := compiler.NewRunContext(context.Background())
+
+	status, err := step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+
+	runner.AddResult("colima", []string{"stop"}, ports.CommandResult{})
+	runner.AddResult("colima", []string{"start", "--cpu", "4", "--memory", "6", "--disk", "60"}, ports.CommandResult{})
+	require.NoError(t, step.Apply(ctx))
+
+	runner.AddResult("colima", []string{"list", "--json"}, ports.CommandResult{
+		Stdout: `{"name":"default","status":"Running","cpus":4,"memory":6442450944,"disk":64424509440}`,
+	})
+	status, err = step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, status)
+}
+
+func TestResourcesStep_Podman_InitsMissingMachine(t *testing.T) {
+	runner := mocks.NewCommandRunner()
+	runner.AddResult("podman", []string{"machine", "init", "--cpus", "2", "--memory", "4096"}, ports.CommandResult{})
+	runner.AddResult("podman", []string{"machine", "start"}, ports.CommandResult{})
+	step := NewResourcesStep(EnginePodman, &ResourceLimits{CPUs: 2, Memory: "4GB"}, "", false, runner)
+	ctx := compiler.NewRunContext(context.Background())
+
+	status, err := step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+	require.NoError(t, step.Apply(ctx))
+}
@@ -9,17 +9,25 @@ import (
 	"github.com/felixgeelhaar/preflight/internal/ports"
 )
 
-// InstallStep represents Docker Desktop installation.
+// InstallStep installs the container engine: Docker Desktop (Docker
+// Engine on Linux), Colima with the Docker CLI, or Podman.
 type InstallStep struct {
 	id     compiler.StepID
+	engine Engine
 	runner ports.CommandRunner
 }
 
 // NewInstallStep creates a new Docker Desktop installation step.
 func NewInstallStep(runner ports.CommandRunner) *InstallStep {
+	return NewEngineInstallStep(EngineDesktop, runner)
+}
+
+// NewEngineInstallStep creates an installation step for engine.
+func NewEngineInstallStep(engine Engine, runner ports.CommandRunner) *InstallStep {
 	id := compiler.MustNewStepID("docker:install")
 	return &InstallStep{
 		id:     id,
+		engine: engine,
 		runner: runner,
 	}
 }
@@ -31,21 +39,25 @@ func (s *InstallStep) ID() compiler.StepID {
 
 // DependsOn returns the step dependencies.
 func (s *InstallStep) DependsOn() []compiler.StepID {
-	// Docker Desktop installation depends on Homebrew on macOS
-	if runtime.GOOS == "darwin" {
-		return []compiler.StepID{compiler.MustNewStepID("brew:cask:docker")}
-	}
 	return nil
 }
 
-// Check determines if Docker is installed.
+// Check determines if the engine is installed.
 func (s *InstallStep) Check(ctx compiler.RunContext) (compiler.StepStatus, error) {
-	result, err := s.runner.Run(ctx.Context(), "docker", "--version")
+	command, marker := "docker", "Docker version"
+	switch s.engine {
+	case EngineColima:
+		command, marker = "colima", "colima version"
+	case EnginePodman:
+		command, marker = "podman", "podman version"
+	case EngineDesktop:
+	}
+	result, err := s.runner.Run(ctx.Context(), command, "--version")
 	if err != nil {
-		// Command not found means Docker needs to be installed
+		// Command not found means the engine needs to be installed
 		return compiler.StatusNeedsApply, nil //nolint:nilerr // intentional: command failure = needs apply
 	}
-	if result.Success() && strings.Contains(result.Stdout, "Docker version") {
+	if result.Success() && strings.Contains(result.Stdout, marker) {
 		return compiler.StatusSatisfied, nil
 	}
 	return compiler.StatusNeedsApply, nil
@@ -53,39 +65,74 @@ func (s *InstallStep) Check(ctx compiler.RunContext) (compiler.StepStatus, error
 
 // Plan returns the diff for this step.
 func (s *InstallStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
-	return compiler.NewDiff(compiler.DiffTypeAdd, "docker", "Docker Desktop", "", "installed"), nil
+	return compiler.NewDiff(compiler.DiffTypeAdd, "docker", s.engineName(), "", "installed"), nil
 }
 
-// Apply executes Docker Desktop installation.
+// Apply installs the engine.
 func (s *InstallStep) Apply(ctx compiler.RunContext) error {
-	switch runtime.GOOS {
-	case "darwin":
+	var command string
+	var args []string
+	switch {
+	case s.engine == EngineColima:
+		// Colima provides the VM; the docker formula provides the CLI
+		command, args = "brew", []string{"install", "colima", "docker"}
+	case s.engine == EnginePodman:
+		command, args = "brew", []string{"install", "podman"}
+	case runtime.GOOS == "darwin":
 		// Docker Desktop is installed via brew cask
-		result, err := s.runner.Run(ctx.Context(), "brew", "install", "--cask", "docker")
-		if err != nil {
-			return err
-		}
-		if !result.Success() {
-			return fmt.Errorf("brew install docker failed: %s", result.Stderr)
-		}
-	case "linux":
+		command, args = "brew", []string{"install", "--cask", "docker"}
+	case runtime.GOOS == "linux":
 		// Install Docker Engine on Linux using convenience script
-		result, err := s.runner.Run(ctx.Context(), "sh", "-c",
-			"curl -fsSL https://get.docker.com | sh")
-		if err != nil {
-			return err
-		}
-		if !result.Success() {
-			return fmt.Errorf("docker installation failed: %s", result.Stderr)
-		}
+		command, args = "sh", []string{"-c", "curl -fsSL https://get.docker.com | sh"}
 	default:
 		return fmt.Errorf("unsupported OS: %s", runtime.GOOS)
 	}
+
+	result, err := s.runner.Run(ctx.Context(), command, args...)
+	if err != nil {
+		return err
+	}
+	if !result.Success() {
+		return fmt.Errorf("%s installation failed: %s", s.engineName(), result.Stderr)
+	}
 	return nil
 }
 
+func (s *InstallStep) engineName() string {
+	switch s.engine {
+	case EngineColima:
+		return "Colima"
+	case EnginePodman:
+		return "Podman"
+	default:
+		return "Docker Desktop"
+	}
+}
+
 // Explain provides a human-readable explanation.
 func (s *InstallStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
+	switch s.engine {
+	case EngineColima:
+		return compiler.NewExplanation(
+			"Install Colima",
+			"Installs Colima and the Docker CLI. Colima runs the Docker daemon in a lightweight Linux VM.",
+			[]string{"https://github.com/abiosoft/colima"},
+		).WithTradeoffs([]string{
+			"+ Free and open source, no Docker Desktop license needed",
+			"+ Lower resource usage than Docker Desktop",
+			"- No GUI; managed from the command line",
+		})
+	case EnginePodman:
+		return compiler.NewExplanation(
+			"Install Podman",
+			"Installs Podman, a daemonless container engine with a Docker-compatible CLI.",
+			[]string{"https://podman.io/docs/installation"},
+		).WithTradeoffs([]string{
+			"+ Daemonless and rootless by default",
+			"+ Free and open source",
+			"- Some Docker Compose workflows need podman-compose or the Docker socket shim",
+		})
+	}
 	return compiler.NewExplanation(
 		"Install Docker Desktop",
 		"Installs Docker Desktop, providing the Docker runtime, Docker Compose, and container management tools.",
@@ -0,0 +1,85 @@
+// Package editorconfig provides the provider for the default ~/.editorconfig.
+package editorconfig
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Path is where the default editorconfig is written. Editors search the
+// directories above a file for .editorconfig, so projects under the home
+// directory fall back to it.
+const Path = "~/.editorconfig"
+
+// Config represents the editorconfig section of the configuration.
+type Config struct {
+	Root     bool              // Stop the search for .editorconfig files at the home directory
+	Template string            // text/template rendered before the sections
+	Vars     map[string]string // Template variables
+	Sections []Section
+}
+
+// Section sets properties for the files matching a glob.
+type Section struct {
+	Glob       string
+	Properties map[string]string
+}
+
+// PropertyNames returns the property names of the section, sorted.
+func (s Section) PropertyNames() []string {
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsZero reports whether there is nothing to write.
+func (c *Config) IsZero() bool {
+	return !c.Root && c.Template == "" && len(c.Sections) == 0
+}
+
+// ParseConfig parses the editorconfig configuration from a raw map.
+func ParseConfig(raw map[string]interface{}) (*Config, error) {
+	cfg := &Config{}
+
+	if root, ok := raw["root"].(bool); ok {
+		cfg.Root = root
+	}
+	if tmpl, ok := raw["template"].(string); ok {
+		cfg.Template = tmpl
+	}
+	if vars, ok := raw["vars"].(map[string]interface{}); ok {
+		cfg.Vars = make(map[string]string, len(vars))
+		for k, v := range vars {
+			cfg.Vars[k] = fmt.Sprint(v)
+		}
+	}
+
+	if sections, ok := raw["sections"]; ok {
+		list, ok := sections.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("sections must be a list")
+		}
+		for _, item := range list {
+			m, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("section must be an object")
+			}
+			glob, ok := m["glob"].(string)
+			if !ok || glob == "" {
+				return nil, fmt.Errorf("section must have a glob")
+			}
+			section := Section{Glob: glob, Properties: make(map[string]string)}
+			if props, ok := m["properties"].(map[string]interface{}); ok {
+				for name, value := range props {
+					section.Properties[name] = fmt.Sprint(value)
+				}
+			}
+			cfg.Sections = append(cfg.Sections, section)
+		}
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,44 @@
+package editorconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfig(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseConfig(map[string]interface{}{
+		"root":     true,
+		"template": "templates/editorconfig.tmpl",
+		"vars":     map[string]interface{}{"indent": 4},
+		"sections": []interface{}{
+			map[string]interface{}{"glob": "*", "properties": map[string]interface{}{"indent_size": 2, "insert_final_newline": true}},
+			map[string]interface{}{"glob": "Makefile", "properties": map[string]interface{}{"indent_style": "tab"}},
+		},
+	})
+	require.NoError(t, err)
+	assert.True(t, cfg.Root)
+	assert.Equal(t, "templates/editorconfig.tmpl", cfg.Template)
+	assert.Equal(t, map[string]string{"indent": "4"}, cfg.Vars)
+	require.Len(t, cfg.Sections, 2)
+	assert.Equal(t, "*", cfg.Sections[0].Glob)
+	assert.Equal(t, []string{"indent_size", "insert_final_newline"}, cfg.Sections[0].PropertyNames())
+	assert.Equal(t, "true", cfg.Sections[0].Properties["insert_final_newline"])
+	assert.False(t, cfg.IsZero())
+}
+
+func TestParseConfig_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for name, raw := range map[string]map[string]interface{}{
+		"sections not list":   {"sections": "*"},
+		"section not object":  {"sections": []interface{}{"*"}},
+		"section has no glob": {"sections": []interface{}{map[string]interface{}{"properties": map[string]interface{}{}}}},
+	} {
+		_, err := ParseConfig(raw)
+		assert.Error(t, err, name)
+	}
+}
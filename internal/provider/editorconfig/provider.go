@@ -0,0 +1,49 @@
+package editorconfig
+
+import (
+	"path/filepath"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+)
+
+// Provider implements the compiler.Provider interface for the default
+// editorconfig.
+type Provider struct {
+	fs ports.FileSystem
+}
+
+// NewProvider creates a new editorconfig provider.
+func NewProvider(fs ports.FileSystem) *Provider {
+	return &Provider{fs: fs}
+}
+
+// Name returns the provider name.
+func (p *Provider) Name() string {
+	return "editorconfig"
+}
+
+// Compile transforms editorconfig configuration into executable steps.
+func (p *Provider) Compile(ctx compiler.CompileContext) ([]compiler.Step, error) {
+	rawConfig := ctx.GetSection("editorconfig")
+	if rawConfig == nil {
+		return nil, nil
+	}
+
+	cfg, err := ParseConfig(rawConfig)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.IsZero() {
+		return nil, nil
+	}
+
+	templatePath := ports.ExpandPath(cfg.Template)
+	if templatePath != "" && !filepath.IsAbs(templatePath) {
+		templatePath = filepath.Join(ctx.ConfigRoot(), templatePath)
+	}
+	return []compiler.Step{NewFileStep(cfg, templatePath, p.fs)}, nil
+}
+
+// Ensure Provider implements compiler.Provider.
+var _ compiler.Provider = (*Provider)(nil)
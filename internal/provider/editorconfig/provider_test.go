@@ -0,0 +1,32 @@
+package editorconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/testutil/mocks"
+)
+
+func TestProvider_Compile(t *testing.T) {
+	t.Parallel()
+
+	provider := NewProvider(mocks.NewFileSystem())
+	assert.Equal(t, "editorconfig", provider.Name())
+
+	steps, err := provider.Compile(compiler.NewCompileContext(map[string]interface{}{}))
+	require.NoError(t, err)
+	assert.Empty(t, steps)
+
+	steps, err = provider.Compile(compiler.NewCompileContext(map[string]interface{}{
+		"editorconfig": map[string]interface{}{
+			"template": "templates/editorconfig.tmpl",
+		},
+	}).WithConfigRoot("/config"))
+	require.NoError(t, err)
+	require.Len(t, steps, 1)
+	assert.Equal(t, "editorconfig:file", steps[0].ID().String())
+	assert.Equal(t, "/config/templates/editorconfig.tmpl", steps[0].(*FileStep).templatePath)
+}
@@ -0,0 +1,131 @@
+package editorconfig
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"text/template"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+)
+
+// Header starts the editorconfig preflight generates, so that a file
+// written by hand is never overwritten.
+const Header = "# Generated by preflight. Do not edit: changes are overwritten on apply.\n"
+
+// FileStep generates the default editorconfig.
+type FileStep struct {
+	cfg          *Config
+	templatePath string
+	id           compiler.StepID
+	fs           ports.FileSystem
+}
+
+// NewFileStep creates a new FileStep. templatePath is the resolved path of
+// the editorconfig template, if any.
+func NewFileStep(cfg *Config, templatePath string, fs ports.FileSystem) *FileStep {
+	return &FileStep{
+		cfg:          cfg,
+		templatePath: templatePath,
+		id:           compiler.MustNewStepID("editorconfig:file"),
+		fs:           fs,
+	}
+}
+
+// ID returns the step identifier.
+func (s *FileStep) ID() compiler.StepID {
+	return s.id
+}
+
+// DependsOn returns the step dependencies.
+func (s *FileStep) DependsOn() []compiler.StepID {
+	return nil
+}
+
+// Check determines if the editorconfig needs to be written.
+func (s *FileStep) Check(_ compiler.RunContext) (compiler.StepStatus, error) {
+	expected, err := s.Render()
+	if err != nil {
+		return compiler.StatusUnknown, err
+	}
+	existing, err := s.fs.ReadFile(ports.ExpandPath(Path))
+	if err == nil && bytes.Equal(existing, expected) {
+		return compiler.StatusSatisfied, nil
+	}
+	return compiler.StatusNeedsApply, nil
+}
+
+// Plan returns the diff for this step.
+func (s *FileStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
+	diffType := compiler.DiffTypeAdd
+	if s.fs.Exists(ports.ExpandPath(Path)) {
+		diffType = compiler.DiffTypeModify
+	}
+	return compiler.NewDiff(diffType, "editorconfig", Path, "", fmt.Sprintf("%d sections", len(s.cfg.Sections))), nil
+}
+
+// Apply writes the editorconfig. It refuses to overwrite a file that
+// preflight did not generate.
+func (s *FileStep) Apply(_ compiler.RunContext) error {
+	content, err := s.Render()
+	if err != nil {
+		return err
+	}
+	path := ports.ExpandPath(Path)
+	if existing, err := s.fs.ReadFile(path); err == nil && !bytes.HasPrefix(existing, []byte(Header)) {
+		return fmt.Errorf("%s was not generated by preflight; move its sections to editorconfig.sections and remove it", Path)
+	}
+	if err := s.fs.WriteFile(path, content, 0o644); err != nil {
+		return fmt.Errorf("failed to write editorconfig: %w", err)
+	}
+	return nil
+}
+
+// Explain provides a human-readable explanation.
+func (s *FileStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
+	return compiler.NewExplanation(
+		"Generate Default EditorConfig",
+		fmt.Sprintf("Generates %s with defaults such as indentation and line endings. Editors use it for files in projects that do not ship their own .editorconfig.", Path),
+		[]string{
+			"https://editorconfig.org",
+			"https://spec.editorconfig.org",
+		},
+	)
+}
+
+// Render returns the editorconfig: the header, root, the rendered template
+// and the sections with their properties sorted by name.
+func (s *FileStep) Render() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(Header)
+	if s.cfg.Root {
+		buf.WriteString("root = true\n")
+	}
+
+	if s.templatePath != "" {
+		source, err := s.fs.ReadFile(s.templatePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read editorconfig template: %w", err)
+		}
+		tmpl, err := template.New(filepath.Base(s.templatePath)).Option("missingkey=error").Parse(string(source))
+		if err != nil {
+			return nil, fmt.Errorf("invalid editorconfig template %s: %w", s.templatePath, err)
+		}
+		buf.WriteByte('\n')
+		if err := tmpl.Execute(&buf, s.cfg.Vars); err != nil {
+			return nil, fmt.Errorf("failed to render editorconfig template %s: %w", s.templatePath, err)
+		}
+		if buf.Bytes()[buf.Len()-1] != '\n' {
+			buf.WriteByte('\n')
+		}
+	}
+
+	for _, section := range s.cfg.Sections {
+		fmt.Fprintf(&buf, "\n[%s]\n", section.Glob)
+		for _, name := range section.PropertyNames() {
+			fmt.Fprintf(&buf, "%s = %s\n", name, section.Properties[name])
+		}
+	}
+	return buf.Bytes(), nil
+}
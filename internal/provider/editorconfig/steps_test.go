@@ -0,0 +1,70 @@
+package editorconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/testutil/mocks"
+)
+
+func TestFileStep_Render(t *testing.T) {
+	t.Parallel()
+
+	fs := mocks.NewFileSystem()
+	fs.AddFile("/config/editorconfig.tmpl", "[*.go]\nindent_size = {{ .indent }}")
+	step := NewFileStep(&Config{
+		Root: true,
+		Vars: map[string]string{"indent": "4"},
+		Sections: []Section{
+			{Glob: "*", Properties: map[string]string{"indent_style": "space", "end_of_line": "lf"}},
+			{Glob: "Makefile", Properties: map[string]string{"indent_style": "tab"}},
+		},
+	}, "/config/editorconfig.tmpl", fs)
+
+	content, err := step.Render()
+	require.NoError(t, err)
+	assert.Equal(t, Header+`root = true
+
+[*.go]
+indent_size = 4
+
+[*]
+end_of_line = lf
+indent_style = space
+
+[Makefile]
+indent_style = tab
+`, string(content))
+}
+
+func TestFileStep_ApplyAndCheck(t *testing.T) {
+	t.Parallel()
+
+	fs := mocks.NewFileSystem()
+	step := NewFileStep(&Config{Sections: []Section{{Glob: "*", Properties: map[string]string{"charset": "utf-8"}}}}, "", fs)
+
+	status, err := step.Check(compiler.RunContext{})
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+
+	require.NoError(t, step.Apply(compiler.RunContext{}))
+	status, err = step.Check(compiler.RunContext{})
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, status)
+}
+
+func TestFileStep_ApplyRefusesHandWrittenFile(t *testing.T) {
+	t.Parallel()
+
+	fs := mocks.NewFileSystem()
+	fs.AddFile(ports.ExpandPath(Path), "root = true\n")
+	step := NewFileStep(&Config{Root: true}, "", fs)
+
+	err := step.Apply(compiler.RunContext{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not generated by preflight")
+}
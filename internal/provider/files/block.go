@@ -0,0 +1,218 @@
+package files
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/validation"
+)
+
+// BlockStep keeps a marked block of a file in line with the config. Only
+// the lines between the block's markers are written; the block is appended
+// when the file does not have it yet.
+type BlockStep struct {
+	block     Block
+	id        compiler.StepID
+	fs        ports.FileSystem
+	lifecycle ports.FileLifecycle
+}
+
+// NewBlockStep creates a new BlockStep.
+func NewBlockStep(block Block, fs ports.FileSystem, lifecycle ports.FileLifecycle) *BlockStep {
+	if lifecycle == nil {
+		lifecycle = &ports.NoopLifecycle{}
+	}
+	if block.Comment == "" {
+		block.Comment = "#"
+	}
+	id := compiler.MustNewStepID("files:block:" + block.ID())
+	return &BlockStep{
+		block:     block,
+		id:        id,
+		fs:        fs,
+		lifecycle: lifecycle,
+	}
+}
+
+// ID returns the step identifier.
+func (s *BlockStep) ID() compiler.StepID {
+	return s.id
+}
+
+// DependsOn returns the step dependencies.
+func (s *BlockStep) DependsOn() []compiler.StepID {
+	return nil
+}
+
+// Check determines if the block in the file has the configured content.
+func (s *BlockStep) Check(_ compiler.RunContext) (compiler.StepStatus, error) {
+	dest := ports.ExpandPath(s.block.Dest)
+	if !s.fs.Exists(dest) {
+		return compiler.StatusNeedsApply, nil
+	}
+
+	content, err := s.fs.ReadFile(dest)
+	if err != nil {
+		return compiler.StatusUnknown, err
+	}
+	body, err := s.body()
+	if err != nil {
+		return compiler.StatusUnknown, err
+	}
+
+	if current, ok := readBlock(string(content), s.block); ok && current == body {
+		return compiler.StatusSatisfied, nil
+	}
+	return compiler.StatusNeedsApply, nil
+}
+
+// Plan returns the diff for this step.
+func (s *BlockStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
+	body, err := s.body()
+	if err != nil {
+		return compiler.Diff{}, err
+	}
+	resource := "block " + s.block.Name
+
+	dest := ports.ExpandPath(s.block.Dest)
+	if content, err := s.fs.ReadFile(dest); err == nil {
+		if current, ok := readBlock(string(content), s.block); ok {
+			return compiler.NewDiff(compiler.DiffTypeModify, resource, s.block.Dest, current, body), nil
+		}
+	}
+	return compiler.NewDiff(compiler.DiffTypeAdd, resource, s.block.Dest, "", body), nil
+}
+
+// Apply writes the block into the file.
+func (s *BlockStep) Apply(_ compiler.RunContext) error {
+	// Validate paths to prevent path traversal attacks
+	if s.block.Src != "" {
+		if err := validation.ValidatePath(s.block.Src); err != nil {
+			return fmt.Errorf("invalid source path: %w", err)
+		}
+	}
+	if err := validation.ValidatePath(s.block.Dest); err != nil {
+		return fmt.Errorf("invalid destination path: %w", err)
+	}
+
+	dest := ports.ExpandPath(s.block.Dest)
+	ctx := context.Background()
+
+	body, err := s.body()
+	if err != nil {
+		return err
+	}
+
+	var existing []byte
+	mode := os.FileMode(0o644)
+	if s.fs.Exists(dest) {
+		existing, err = s.fs.ReadFile(dest)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", dest, err)
+		}
+		if info, err := s.fs.GetFileInfo(dest); err == nil {
+			mode = info.Mode.Perm()
+		}
+	}
+	updated, err := writeBlock(string(existing), s.block, body)
+	if err != nil {
+		return err
+	}
+
+	// Snapshot before modification
+	if err := s.lifecycle.BeforeModify(ctx, dest); err != nil {
+		return fmt.Errorf("failed to snapshot before modify: %w", err)
+	}
+
+	// The rest of the file is the user's, so it is not tracked for drift
+	if err := s.fs.WriteFile(dest, []byte(updated), mode); err != nil {
+		return fmt.Errorf("failed to write %s: %w", dest, err)
+	}
+	return nil
+}
+
+// Explain provides a human-readable explanation.
+func (s *BlockStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
+	start, end := blockMarkers(s.block)
+	return compiler.NewExplanation(
+		"Manage File Block",
+		fmt.Sprintf("Keeps the lines of %s between %q and %q in line with the config. The rest of the file is left as it is.", s.block.Dest, start, end),
+		nil,
+	)
+}
+
+// body returns the configured content of the block, ending in a newline.
+func (s *BlockStep) body() (string, error) {
+	body := s.block.Content
+	if s.block.Src != "" {
+		content, err := s.fs.ReadFile(ports.ExpandPath(s.block.Src))
+		if err != nil {
+			return "", fmt.Errorf("failed to read block source: %w", err)
+		}
+		body = string(content)
+	}
+	if body != "" && !strings.HasSuffix(body, "\n") {
+		body += "\n"
+	}
+	return body, nil
+}
+
+// blockMarkers returns the lines that start and end the block.
+func blockMarkers(b Block) (start, end string) {
+	return fmt.Sprintf("%s >>> preflight %s >>>", b.Comment, b.Name),
+		fmt.Sprintf("%s <<< preflight %s <<<", b.Comment, b.Name)
+}
+
+// findBlock returns the indexes of the marker lines of the block in lines,
+// or -1 for a marker that was not found.
+func findBlock(lines []string, b Block) (startIdx, endIdx int) {
+	start, end := blockMarkers(b)
+	startIdx, endIdx = -1, -1
+	for i, line := range lines {
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case startIdx < 0 && line == start:
+			startIdx = i
+		case startIdx >= 0 && line == end:
+			return startIdx, i
+		}
+	}
+	return startIdx, endIdx
+}
+
+// readBlock returns the lines between the markers of the block, and false
+// when content does not have the block.
+func readBlock(content string, b Block) (string, bool) {
+	lines := strings.SplitAfter(content, "\n")
+	startIdx, endIdx := findBlock(lines, b)
+	if startIdx < 0 || endIdx < 0 {
+		return "", false
+	}
+	return strings.Join(lines[startIdx+1:endIdx], ""), true
+}
+
+// writeBlock returns content with the block set to body, appending the
+// block when content does not have it. A start marker without an end
+// marker is an error rather than a guess at where the block ends.
+func writeBlock(content string, b Block, body string) (string, error) {
+	start, end := blockMarkers(b)
+	block := start + "\n" + body + end + "\n"
+
+	lines := strings.SplitAfter(content, "\n")
+	startIdx, endIdx := findBlock(lines, b)
+	switch {
+	case startIdx >= 0 && endIdx >= 0:
+		return strings.Join(lines[:startIdx], "") + block + strings.Join(lines[endIdx+1:], ""), nil
+	case startIdx >= 0:
+		return "", fmt.Errorf("block %s in %s has no end marker %q", b.Name, b.Dest, end)
+	case content == "":
+		return block, nil
+	case !strings.HasSuffix(content, "\n"):
+		content += "\n"
+	}
+	return content + "\n" + block, nil
+}
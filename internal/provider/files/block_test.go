@@ -0,0 +1,134 @@
+package files
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/testutil/mocks"
+)
+
+func TestParseConfig_Blocks(t *testing.T) {
+	raw := map[string]interface{}{
+		"blocks": []interface{}{
+			map[string]interface{}{
+				"dest":    "~/.zshrc",
+				"name":    "proxy",
+				"content": "export HTTP_PROXY=http://proxy.corp:8080",
+			},
+			map[string]interface{}{
+				"dest":    "~/.vimrc",
+				"name":    "plugins",
+				"src":     "vim/plugins.vim",
+				"comment": `"`,
+			},
+		},
+	}
+	cfg, err := ParseConfig(raw)
+	if err != nil {
+		t.Fatalf("ParseConfig() error = %v", err)
+	}
+	if len(cfg.Blocks) != 2 {
+		t.Fatalf("Blocks len = %d, want 2", len(cfg.Blocks))
+	}
+	if cfg.Blocks[0].Comment != "#" {
+		t.Errorf("Blocks[0].Comment = %q, want #", cfg.Blocks[0].Comment)
+	}
+	if cfg.Blocks[1].Comment != `"` || cfg.Blocks[1].Src != "vim/plugins.vim" {
+		t.Errorf("Blocks[1] = %+v", cfg.Blocks[1])
+	}
+
+	for _, block := range []map[string]interface{}{
+		{"dest": "~/.zshrc", "content": "x"},
+		{"dest": "~/.zshrc", "name": "proxy"},
+	} {
+		if _, err := ParseConfig(map[string]interface{}{"blocks": []interface{}{block}}); err == nil {
+			t.Errorf("ParseConfig(%v) should fail", block)
+		}
+	}
+}
+
+func TestWriteBlock(t *testing.T) {
+	block := Block{Dest: "~/.zshrc", Name: "proxy", Comment: "#"}
+	body := "export HTTP_PROXY=new\n"
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"empty file", "", "# >>> preflight proxy >>>\nexport HTTP_PROXY=new\n# <<< preflight proxy <<<\n"},
+		{"appended", "alias ll='ls -l'", "alias ll='ls -l'\n\n# >>> preflight proxy >>>\nexport HTTP_PROXY=new\n# <<< preflight proxy <<<\n"},
+		{
+			"replaced in place",
+			"alias ll='ls -l'\n# >>> preflight proxy >>>\nexport HTTP_PROXY=old\nexport NO_PROXY=local\n# <<< preflight proxy <<<\nalias gs='git status'\n",
+			"alias ll='ls -l'\n# >>> preflight proxy >>>\nexport HTTP_PROXY=new\n# <<< preflight proxy <<<\nalias gs='git status'\n",
+		},
+	}
+	for _, tt := range tests {
+		got, err := writeBlock(tt.content, block, body)
+		if err != nil {
+			t.Fatalf("%s: writeBlock() error = %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: writeBlock() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	if _, err := writeBlock("# >>> preflight proxy >>>\nexport HTTP_PROXY=old\nalias ll='ls -l'\n", block, body); err == nil {
+		t.Error("writeBlock() should fail without an end marker")
+	}
+}
+
+func TestBlockStep_ApplyAndCheck(t *testing.T) {
+	fs := mocks.NewFileSystem()
+	fs.AddFile("/home/.zshrc", "# my settings\nalias ll='ls -l'\n")
+	block := Block{Dest: "/home/.zshrc", Name: "proxy", Content: "export HTTP_PROXY=http://proxy.corp:8080"}
+	step := NewBlockStep(block, fs, nil)
+	ctx := compiler.NewRunContext(context.Background())
+
+	status, err := step.Check(ctx)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if status != compiler.StatusNeedsApply {
+		t.Errorf("Check() = %v, want %v", status, compiler.StatusNeedsApply)
+	}
+	diff, err := step.Plan(ctx)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if diff.Type() != compiler.DiffTypeAdd || diff.Name() != "/home/.zshrc" {
+		t.Errorf("Plan() = %v %s, want an addition to /home/.zshrc", diff.Type(), diff.Name())
+	}
+
+	if err := step.Apply(ctx); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	status, _ = step.Check(ctx)
+	if status != compiler.StatusSatisfied {
+		t.Errorf("Check() after apply = %v, want %v", status, compiler.StatusSatisfied)
+	}
+
+	// Edits outside the block are the user's; edits inside it are drift
+	content, _ := fs.ReadFile("/home/.zshrc")
+	fs.AddFile("/home/.zshrc", "export EDITOR=nvim\n"+string(content))
+	if status, _ := step.Check(ctx); status != compiler.StatusSatisfied {
+		t.Errorf("Check() after editing outside the block = %v, want %v", status, compiler.StatusSatisfied)
+	}
+	content, _ = fs.ReadFile("/home/.zshrc")
+	fs.AddFile("/home/.zshrc", strings.Replace(string(content), "proxy.corp", "other", 1))
+	if status, _ := step.Check(ctx); status != compiler.StatusNeedsApply {
+		t.Errorf("Check() after editing the block = %v, want %v", status, compiler.StatusNeedsApply)
+	}
+
+	if err := step.Apply(ctx); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	content, _ = fs.ReadFile("/home/.zshrc")
+	want := "export EDITOR=nvim\n# my settings\nalias ll='ls -l'\n\n# >>> preflight proxy >>>\nexport HTTP_PROXY=http://proxy.corp:8080\n# <<< preflight proxy <<<\n"
+	if string(content) != want {
+		t.Errorf("content = %q, want %q", content, want)
+	}
+}
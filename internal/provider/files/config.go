@@ -5,6 +5,10 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/provider/checksumutil"
 )
 
 // Config represents the files section of the configuration.
@@ -12,6 +16,8 @@ type Config struct {
 	Links     []Link
 	Templates []Template
 	Copies    []Copy
+	Downloads []Download
+	Blocks    []Block
 }
 
 // Link represents a symbolic link to create.
@@ -55,12 +61,44 @@ func (c Copy) ID() string {
 	return hex.EncodeToString(hash[:8])
 }
 
+// Block represents a marked block of a file that preflight manages. The
+// rest of the file is left as it is.
+type Block struct {
+	Dest    string // Destination path
+	Name    string // Block name, written in the marker lines
+	Src     string // Source file with the block content
+	Content string // Inline block content, used without Src
+	Comment string // Comment prefix of the marker lines (default "#")
+}
+
+// ID returns a unique identifier for this block.
+func (b Block) ID() string {
+	hash := sha256.Sum256([]byte(b.Dest + "#" + b.Name))
+	return hex.EncodeToString(hash[:8])
+}
+
+// Download represents a file fetched from a URL and verified against a checksum.
+type Download struct {
+	URL      string // Source URL (https)
+	Dest     string // Destination path
+	Checksum string // Required checksum (e.g., "sha256:...")
+	Mode     string // File mode (e.g., "0755")
+}
+
+// ID returns a unique identifier for this download.
+func (d Download) ID() string {
+	hash := sha256.Sum256([]byte(d.Dest))
+	return hex.EncodeToString(hash[:8])
+}
+
 // ParseConfig parses the files configuration from a raw map.
 func ParseConfig(raw map[string]interface{}) (*Config, error) {
 	cfg := &Config{
 		Links:     make([]Link, 0),
 		Templates: make([]Template, 0),
 		Copies:    make([]Copy, 0),
+		Downloads: make([]Download, 0),
+		Blocks:    make([]Block, 0),
 	}
 
 	// Parse links
@@ -108,6 +146,36 @@ func ParseConfig(raw map[string]interface{}) (*Config, error) {
 		}
 	}
 
+	// Parse downloads
+	if downloads, ok := raw["downloads"]; ok {
+		downloadList, ok := downloads.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("downloads must be a list")
+		}
+		for _, d := range downloadList {
+			dl, err := parseDownload(d)
+			if err != nil {
+				return nil, err
+			}
+			cfg.Downloads = append(cfg.Downloads, dl)
+		}
+	}
+
+	// Parse blocks
+	if blocks, ok := raw["blocks"]; ok {
+		blockList, ok := blocks.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("blocks must be a list")
+		}
+		for _, b := range blockList {
+			block, err := parseBlock(b)
+			if err != nil {
+				return nil, err
+			}
+			cfg.Blocks = append(cfg.Blocks, block)
+		}
+	}
+
 	return cfg, nil
 }
 
@@ -208,3 +276,83 @@ func parseCopy(raw interface{}) (Copy, error) {
 
 	return cp, nil
 }
+
+// parseBlock parses a single block from a map.
+func parseBlock(raw interface{}) (Block, error) {
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		return Block{}, fmt.Errorf("block must be an object")
+	}
+
+	block := Block{Comment: "#"}
+
+	if dest, ok := m["dest"].(string); ok && dest != "" {
+		block.Dest = dest
+	} else {
+		return Block{}, fmt.Errorf("block must have a dest")
+	}
+
+	if name, ok := m["name"].(string); ok && name != "" && !strings.ContainsAny(name, "\n\r") {
+		block.Name = name
+	} else {
+		return Block{}, fmt.Errorf("block in %s must have a single-line name", block.Dest)
+	}
+
+	if src, ok := m["src"].(string); ok {
+		block.Src = src
+	}
+	if content, ok := m["content"].(string); ok {
+		block.Content = content
+	}
+	if block.Src == "" && block.Content == "" {
+		return Block{}, fmt.Errorf("block %s in %s must have a src or content", block.Name, block.Dest)
+	}
+
+	if comment, ok := m["comment"].(string); ok && comment != "" {
+		block.Comment = comment
+	}
+
+	return block, nil
+}
+
+// parseDownload parses a single download from a map.
+// A checksum is mandatory so that downloaded content is always verified.
+func parseDownload(raw interface{}) (Download, error) {
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		return Download{}, fmt.Errorf("download must be an object")
+	}
+
+	dl := Download{}
+
+	rawURL, ok := m["url"].(string)
+	if !ok {
+		return Download{}, fmt.Errorf("download must have a url")
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return Download{}, fmt.Errorf("download url %q must be an http(s) URL", rawURL)
+	}
+	dl.URL = rawURL
+
+	if dest, ok := m["dest"].(string); ok {
+		dl.Dest = dest
+	} else {
+		return Download{}, fmt.Errorf("download must have a dest")
+	}
+
+	checksum, ok := m["checksum"].(string)
+	if !ok || checksum == "" {
+		return Download{}, fmt.Errorf("download %s must have a checksum", dl.URL)
+	}
+	if _, err := checksumutil.Parse(checksum); err != nil {
+		return Download{}, fmt.Errorf("download %s has invalid checksum: %w", dl.URL, err)
+	}
+	dl.Checksum = checksum
+
+	if mode, ok := m["mode"].(string); ok {
+		dl.Mode = mode
+	}
+
+	return dl, nil
+}
@@ -0,0 +1,169 @@
+package files
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/provider/checksumutil"
+	"github.com/felixgeelhaar/preflight/internal/validation"
+)
+
+// maxDownloadSize limits the size of a single downloaded artifact.
+const maxDownloadSize = 512 << 20
+
+// DownloadStep represents a verified file download step.
+type DownloadStep struct {
+	dl        Download
+	id        compiler.StepID
+	fs        ports.FileSystem
+	lifecycle ports.FileLifecycle
+	client    *http.Client
+}
+
+// NewDownloadStep creates a new DownloadStep.
+func NewDownloadStep(dl Download, fs ports.FileSystem, lifecycle ports.FileLifecycle) *DownloadStep {
+	if lifecycle == nil {
+		lifecycle = &ports.NoopLifecycle{}
+	}
+	id := compiler.MustNewStepID("files:download:" + dl.ID())
+	return &DownloadStep{
+		dl:        dl,
+		id:        id,
+		fs:        fs,
+		lifecycle: lifecycle,
+		client:    &http.Client{Timeout: 5 * time.Minute},
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to fetch the artifact.
+func (s *DownloadStep) WithHTTPClient(client *http.Client) *DownloadStep {
+	if client != nil {
+		s.client = client
+	}
+	return s
+}
+
+// ID returns the step identifier.
+func (s *DownloadStep) ID() compiler.StepID {
+	return s.id
+}
+
+// DependsOn returns the step dependencies.
+func (s *DownloadStep) DependsOn() []compiler.StepID {
+	return nil
+}
+
+// Check determines if the destination already holds the verified artifact.
+func (s *DownloadStep) Check(_ compiler.RunContext) (compiler.StepStatus, error) {
+	dest := ports.ExpandPath(s.dl.Dest)
+
+	if !s.fs.Exists(dest) {
+		return compiler.StatusNeedsApply, nil
+	}
+
+	content, err := s.fs.ReadFile(dest)
+	if err != nil {
+		return compiler.StatusUnknown, err
+	}
+
+	if checksumutil.Verify(content, s.dl.Checksum) != nil {
+		return compiler.StatusNeedsApply, nil
+	}
+
+	return compiler.StatusSatisfied, nil
+}
+
+// Plan returns the diff for this step.
+func (s *DownloadStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
+	return compiler.NewDiff(compiler.DiffTypeAdd, "download", s.dl.Dest, "", s.dl.URL), nil
+}
+
+// Apply downloads the artifact, verifies its checksum, and writes it.
+// Content that fails verification is never written to disk.
+func (s *DownloadStep) Apply(ctx compiler.RunContext) error {
+	if err := validation.ValidatePath(s.dl.Dest); err != nil {
+		return fmt.Errorf("invalid destination path: %w", err)
+	}
+
+	dest := ports.ExpandPath(s.dl.Dest)
+
+	content, err := s.fetch(ctx.Context())
+	if err != nil {
+		return err
+	}
+
+	if err := checksumutil.Verify(content, s.dl.Checksum); err != nil {
+		return fmt.Errorf("verification of %s failed: %w", s.dl.URL, err)
+	}
+
+	// Snapshot before modification
+	if err := s.lifecycle.BeforeModify(ctx.Context(), dest); err != nil {
+		return fmt.Errorf("failed to snapshot before modify: %w", err)
+	}
+
+	mode := parseFileMode(s.dl.Mode, 0o644)
+	if err := s.fs.WriteFile(dest, content, mode); err != nil {
+		return fmt.Errorf("failed to write destination: %w", err)
+	}
+
+	// Record for drift tracking
+	if err := s.lifecycle.AfterApply(ctx.Context(), dest, "files"); err != nil {
+		return fmt.Errorf("failed to record apply: %w", err)
+	}
+
+	return nil
+}
+
+// fetch retrieves the artifact body.
+func (s *DownloadStep) fetch(ctx context.Context) ([]byte, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.dl.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", s.dl.URL, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: HTTP %d", s.dl.URL, resp.StatusCode)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxDownloadSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", s.dl.URL, err)
+	}
+	if len(content) > maxDownloadSize {
+		return nil, fmt.Errorf("download %s exceeds %d bytes", s.dl.URL, maxDownloadSize)
+	}
+
+	return content, nil
+}
+
+// Explain provides a human-readable explanation.
+func (s *DownloadStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
+	return compiler.NewExplanation(
+		"Download File",
+		fmt.Sprintf("Downloads %s to %s and verifies it against %s.", s.dl.URL, s.dl.Dest, s.dl.Checksum),
+		nil,
+	)
+}
+
+// ArtifactInfo returns the downloaded artifact details for verification reporting.
+func (s *DownloadStep) ArtifactInfo() (compiler.ArtifactInfo, bool) {
+	return compiler.ArtifactInfo{
+		Name:     s.dl.Dest,
+		Source:   s.dl.URL,
+		Checksum: s.dl.Checksum,
+	}, true
+}
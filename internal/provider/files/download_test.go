@@ -0,0 +1,148 @@
+package files
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/testutil/mocks"
+)
+
+// sha256 of "hello"
+const helloChecksum = "sha256:2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+func TestParseConfig_Downloads(t *testing.T) {
+	raw := map[string]interface{}{
+		"downloads": []interface{}{
+			map[string]interface{}{
+				"url":      "https://example.com/tool",
+				"dest":     "~/.local/bin/tool",
+				"checksum": helloChecksum,
+				"mode":     "0755",
+			},
+		},
+	}
+	cfg, err := ParseConfig(raw)
+	if err != nil {
+		t.Fatalf("ParseConfig() error = %v", err)
+	}
+	if len(cfg.Downloads) != 1 {
+		t.Fatalf("Downloads len = %d, want 1", len(cfg.Downloads))
+	}
+	if cfg.Downloads[0].Checksum != helloChecksum {
+		t.Errorf("Checksum = %q, want %q", cfg.Downloads[0].Checksum, helloChecksum)
+	}
+}
+
+func TestParseConfig_DownloadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		dl   map[string]interface{}
+	}{
+		{"missing checksum", map[string]interface{}{"url": "https://example.com/tool", "dest": "/tmp/tool"}},
+		{"invalid checksum", map[string]interface{}{"url": "https://example.com/tool", "dest": "/tmp/tool", "checksum": "sha256:zz"}},
+		{"missing url", map[string]interface{}{"dest": "/tmp/tool", "checksum": helloChecksum}},
+		{"non-http url", map[string]interface{}{"url": "file:///etc/passwd", "dest": "/tmp/tool", "checksum": helloChecksum}},
+		{"missing dest", map[string]interface{}{"url": "https://example.com/tool", "checksum": helloChecksum}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseConfig(map[string]interface{}{"downloads": []interface{}{tt.dl}})
+			if err == nil {
+				t.Error("ParseConfig() expected error")
+			}
+		})
+	}
+}
+
+func TestDownloadStep_Check(t *testing.T) {
+	fs := mocks.NewFileSystem()
+	dl := Download{URL: "https://example.com/tool", Dest: "/home/user/bin/tool", Checksum: helloChecksum}
+	step := NewDownloadStep(dl, fs, nil)
+	ctx := compiler.NewRunContext(context.Background())
+
+	status, err := step.Check(ctx)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if status != compiler.StatusNeedsApply {
+		t.Errorf("Check() = %v, want %v", status, compiler.StatusNeedsApply)
+	}
+
+	fs.AddFile("/home/user/bin/tool", "tampered")
+	status, _ = step.Check(ctx)
+	if status != compiler.StatusNeedsApply {
+		t.Errorf("Check() with mismatched content = %v, want %v", status, compiler.StatusNeedsApply)
+	}
+
+	fs.AddFile("/home/user/bin/tool", "hello")
+	status, _ = step.Check(ctx)
+	if status != compiler.StatusSatisfied {
+		t.Errorf("Check() with verified content = %v, want %v", status, compiler.StatusSatisfied)
+	}
+}
+
+func TestDownloadStep_Apply(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+
+	fs := mocks.NewFileSystem()
+	dl := Download{URL: server.URL + "/tool", Dest: "/home/user/bin/tool", Checksum: helloChecksum}
+	step := NewDownloadStep(dl, fs, nil).WithHTTPClient(server.Client())
+
+	if err := step.Apply(compiler.NewRunContext(context.Background())); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	content, err := fs.ReadFile("/home/user/bin/tool")
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(content) != "hello" {
+		t.Errorf("content = %q, want %q", content, "hello")
+	}
+}
+
+func TestDownloadStep_Apply_ChecksumMismatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("tampered"))
+	}))
+	defer server.Close()
+
+	fs := mocks.NewFileSystem()
+	dl := Download{URL: server.URL + "/tool", Dest: "/home/user/bin/tool", Checksum: helloChecksum}
+	step := NewDownloadStep(dl, fs, nil).WithHTTPClient(server.Client())
+
+	if err := step.Apply(compiler.NewRunContext(context.Background())); err == nil {
+		t.Fatal("Apply() expected checksum error")
+	}
+	if fs.Exists("/home/user/bin/tool") {
+		t.Error("Apply() must not write unverified content")
+	}
+}
+
+func TestDownloadStep_Apply_HTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	dl := Download{URL: server.URL + "/tool", Dest: "/home/user/bin/tool", Checksum: helloChecksum}
+	step := NewDownloadStep(dl, mocks.NewFileSystem(), nil).WithHTTPClient(server.Client())
+
+	if err := step.Apply(compiler.NewRunContext(context.Background())); err == nil {
+		t.Fatal("Apply() expected HTTP error")
+	}
+}
+
+func TestDownloadStep_ArtifactInfo(t *testing.T) {
+	dl := Download{URL: "https://example.com/tool", Dest: "/home/user/bin/tool", Checksum: helloChecksum}
+	info, ok := NewDownloadStep(dl, nil, nil).ArtifactInfo()
+	if !ok || !info.Verified() {
+		t.Errorf("ArtifactInfo() = %+v, %v; want verified artifact", info, ok)
+	}
+}
@@ -0,0 +1,155 @@
+package files
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/domain/merge"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+)
+
+// MergePreference decides conflicting changes when a managed file was
+// edited locally and its source in the config changed too.
+type MergePreference string
+
+const (
+	// PreferNone leaves conflicts marked in the file for the user.
+	PreferNone MergePreference = ""
+	// PreferLocal keeps the local edits where they conflict.
+	PreferLocal MergePreference = "local"
+	// PreferConfig takes the config's version where they conflict.
+	PreferConfig MergePreference = "config"
+)
+
+// ParseMergePreference parses "local" or "config". An empty string means
+// no preference.
+func ParseMergePreference(s string) (MergePreference, error) {
+	switch pref := MergePreference(s); pref {
+	case PreferNone, PreferLocal, PreferConfig:
+		return pref, nil
+	default:
+		return PreferNone, fmt.Errorf("invalid merge preference %q (use local or config)", s)
+	}
+}
+
+// resolution returns the conflict side the preference keeps.
+func (p MergePreference) resolution() merge.Resolution {
+	if p == PreferLocal {
+		return merge.ResolveTheirs
+	}
+	return merge.ResolveOurs
+}
+
+// ConflictError reports a managed file left with conflict markers because
+// local edits and config changes touched the same lines.
+type ConflictError struct {
+	Path      string
+	Conflicts int
+}
+
+// Error implements the error interface.
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s has %d conflict(s) between local edits and the config: resolve the marked sections, or apply again with --prefer local|config", e.Path, e.Conflicts)
+}
+
+// managedWriter writes the config's version of a file, keeping the edits
+// made to the file since the last apply. The version the config produced
+// at the last apply is the merge base; without one the file is replaced.
+type managedWriter struct {
+	fs        ports.FileSystem
+	lifecycle ports.FileLifecycle
+	prefer    MergePreference
+}
+
+// appliedContent returns the config's version of dest at the last apply.
+func (w managedWriter) appliedContent(ctx context.Context, dest string) ([]byte, bool) {
+	store, ok := w.lifecycle.(ports.AppliedContentStore)
+	if !ok {
+		return nil, false
+	}
+	content, found, err := store.AppliedContent(ctx, dest)
+	if err != nil || !found {
+		return nil, false
+	}
+	return content, true
+}
+
+// check reports whether the existing dest is up to date with content.
+// Local edits are up to date as long as the config did not change since
+// the last apply.
+func (w managedWriter) check(ctx context.Context, dest string, content []byte) (compiler.StepStatus, error) {
+	existing, err := w.fs.ReadFile(dest)
+	if err != nil {
+		return compiler.StatusUnknown, err
+	}
+	if bytes.Equal(existing, content) {
+		return compiler.StatusSatisfied, nil
+	}
+	if hasUnresolvedConflicts(existing, content) {
+		return compiler.StatusNeedsApply, nil
+	}
+	if base, ok := w.appliedContent(ctx, dest); ok && bytes.Equal(base, content) {
+		return compiler.StatusSatisfied, nil
+	}
+	return compiler.StatusNeedsApply, nil
+}
+
+// write writes content merged with the local edits to dest and records
+// content as the config's version. It returns the number of conflicts
+// left marked in dest.
+func (w managedWriter) write(ctx context.Context, dest string, content []byte, mode os.FileMode) (int, error) {
+	merged, conflicts, err := w.merge(ctx, dest, content)
+	if err != nil {
+		return 0, err
+	}
+	if err := w.fs.WriteFile(dest, merged, mode); err != nil {
+		return 0, err
+	}
+	if store, ok := w.lifecycle.(ports.AppliedContentStore); ok {
+		if err := store.RecordAppliedContent(ctx, dest, content); err != nil {
+			return 0, fmt.Errorf("failed to record applied content: %w", err)
+		}
+	}
+	return conflicts, nil
+}
+
+// merge returns what to write to dest for content.
+func (w managedWriter) merge(ctx context.Context, dest string, content []byte) ([]byte, int, error) {
+	if !w.fs.Exists(dest) {
+		return content, 0, nil
+	}
+	existing, err := w.fs.ReadFile(dest)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Conflicts left by an earlier apply are resolved first
+	if hasUnresolvedConflicts(existing, content) {
+		if w.prefer == PreferNone {
+			return nil, 0, &ConflictError{Path: dest, Conflicts: len(merge.ParseConflictRegions(string(existing)))}
+		}
+		existing = []byte(merge.ResolveAllConflicts(string(existing), w.prefer.resolution()))
+	}
+
+	base, ok := w.appliedContent(ctx, dest)
+	if !ok {
+		return content, 0, nil
+	}
+	result := merge.ThreeWayMerge(string(base), string(content), string(existing), merge.StyleGit)
+	if !result.HasConflicts {
+		return []byte(result.Content), 0, nil
+	}
+	if w.prefer != PreferNone {
+		return []byte(merge.ResolveAllConflicts(result.Content, w.prefer.resolution())), 0, nil
+	}
+	return []byte(result.Content), len(result.Conflicts), nil
+}
+
+// hasUnresolvedConflicts reports whether existing holds conflict markers
+// that content, the config's version, does not.
+func hasUnresolvedConflicts(existing, content []byte) bool {
+	return merge.HasConflictMarkers(string(existing)) && !merge.HasConflictMarkers(string(content))
+}
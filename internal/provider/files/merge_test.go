@@ -0,0 +1,141 @@
+package files
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/testutil/mocks"
+)
+
+// contentLifecycle records applied content in memory.
+type contentLifecycle struct {
+	ports.NoopLifecycle
+	applied map[string]string
+}
+
+func (l *contentLifecycle) RecordAppliedContent(_ context.Context, path string, content []byte) error {
+	l.applied[path] = string(content)
+	return nil
+}
+
+func (l *contentLifecycle) AppliedContent(_ context.Context, path string) ([]byte, bool, error) {
+	content, ok := l.applied[path]
+	return []byte(content), ok, nil
+}
+
+func readFile(t *testing.T, fs *mocks.FileSystem, path string) string {
+	t.Helper()
+	data, err := fs.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile(%s) error = %v", path, err)
+	}
+	return string(data)
+}
+
+func TestParseMergePreference(t *testing.T) {
+	for _, s := range []string{"", "local", "config"} {
+		if pref, err := ParseMergePreference(s); err != nil || string(pref) != s {
+			t.Errorf("ParseMergePreference(%q) = %q, %v", s, pref, err)
+		}
+	}
+	if _, err := ParseMergePreference("theirs"); err == nil {
+		t.Error("ParseMergePreference(theirs) should fail")
+	}
+}
+
+func TestCopyStep_Apply_MergesLocalEdits(t *testing.T) {
+	fs := mocks.NewFileSystem()
+	fs.AddFile("/src/gitconfig", "[user]\n\temail = me@work.com\n[core]\n\teditor = vim\n")
+	fs.AddFile("/dest/gitconfig", "[user]\n\temail = me@example.com\n[core]\n\teditor = vim\n[alias]\n\tst = status\n")
+	lifecycle := &contentLifecycle{applied: map[string]string{
+		"/dest/gitconfig": "[user]\n\temail = me@example.com\n[core]\n\teditor = vim\n",
+	}}
+
+	step := NewCopyStep(Copy{Src: "/src/gitconfig", Dest: "/dest/gitconfig"}, fs, lifecycle)
+	if err := step.Apply(compiler.NewRunContext(context.Background())); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	want := "[user]\n\temail = me@work.com\n[core]\n\teditor = vim\n[alias]\n\tst = status\n"
+	if got := readFile(t, fs, "/dest/gitconfig"); got != want {
+		t.Errorf("merged content = %q, want %q", got, want)
+	}
+	if got := lifecycle.applied["/dest/gitconfig"]; got != "[user]\n\temail = me@work.com\n[core]\n\teditor = vim\n" {
+		t.Errorf("applied content = %q, want the config's version", got)
+	}
+
+	// The config did not change since, so the local edits are kept
+	status, err := step.Check(compiler.NewRunContext(context.Background()))
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if status != compiler.StatusSatisfied {
+		t.Errorf("Check() = %v, want %v", status, compiler.StatusSatisfied)
+	}
+}
+
+func TestTemplateStep_Apply_Conflict(t *testing.T) {
+	newStep := func(prefer MergePreference) (*TemplateStep, *mocks.FileSystem) {
+		fs := mocks.NewFileSystem()
+		fs.AddFile("/src/zshrc.tmpl", "export EDITOR={{ .editor }}\n")
+		fs.AddFile("/dest/.zshrc", "export EDITOR=code\n")
+		lifecycle := &contentLifecycle{applied: map[string]string{"/dest/.zshrc": "export EDITOR=vim\n"}}
+		step := NewTemplateStep(Template{Src: "/src/zshrc.tmpl", Dest: "/dest/.zshrc", Vars: map[string]string{"editor": "nvim"}}, fs, lifecycle)
+		step.prefer = prefer
+		return step, fs
+	}
+	ctx := compiler.NewRunContext(context.Background())
+
+	t.Run("marks conflicts", func(t *testing.T) {
+		step, fs := newStep(PreferNone)
+		err := step.Apply(ctx)
+		var conflict *ConflictError
+		if !errors.As(err, &conflict) || conflict.Conflicts != 1 {
+			t.Fatalf("Apply() error = %v, want one conflict", err)
+		}
+		want := "<<<<<<< ours (config)\nexport EDITOR=nvim\n=======\nexport EDITOR=code\n>>>>>>> theirs (file)\n"
+		if got := readFile(t, fs, "/dest/.zshrc"); got != want {
+			t.Errorf("content = %q, want %q", got, want)
+		}
+
+		// Unresolved markers keep the step pending until a preference settles them
+		if status, _ := step.Check(ctx); status != compiler.StatusNeedsApply {
+			t.Errorf("Check() = %v, want %v", status, compiler.StatusNeedsApply)
+		}
+		step.prefer = PreferLocal
+		if err := step.Apply(ctx); err != nil {
+			t.Fatalf("Apply() error = %v", err)
+		}
+		if got := readFile(t, fs, "/dest/.zshrc"); got != "export EDITOR=code\n" {
+			t.Errorf("content = %q, want the local edit", got)
+		}
+	})
+
+	t.Run("prefer config", func(t *testing.T) {
+		step, fs := newStep(PreferConfig)
+		if err := step.Apply(ctx); err != nil {
+			t.Fatalf("Apply() error = %v", err)
+		}
+		if got := readFile(t, fs, "/dest/.zshrc"); got != "export EDITOR=nvim\n" {
+			t.Errorf("content = %q, want the config's version", got)
+		}
+	})
+}
+
+func TestCopyStep_Apply_WithoutBaseOverwrites(t *testing.T) {
+	fs := mocks.NewFileSystem()
+	fs.AddFile("/src/vimrc", "set number\n")
+	fs.AddFile("/dest/.vimrc", "set nonumber\n")
+	lifecycle := &contentLifecycle{applied: map[string]string{}}
+
+	step := NewCopyStep(Copy{Src: "/src/vimrc", Dest: "/dest/.vimrc"}, fs, lifecycle)
+	if err := step.Apply(compiler.NewRunContext(context.Background())); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if got := readFile(t, fs, "/dest/.vimrc"); got != "set number\n" {
+		t.Errorf("content = %q, want the config's version", got)
+	}
+}
@@ -9,6 +9,7 @@ import (
 type Provider struct {
 	fs        ports.FileSystem
 	lifecycle ports.FileLifecycle
+	prefer    MergePreference
 }
 
 // NewProvider creates a new files provider.
@@ -27,6 +28,13 @@ func (p *Provider) WithLifecycle(lifecycle ports.FileLifecycle) *Provider {
 	return p
 }
 
+// WithMergePreference sets how copies and templates resolve conflicts
+// between local edits and config changes.
+func (p *Provider) WithMergePreference(prefer MergePreference) *Provider {
+	p.prefer = prefer
+	return p
+}
+
 // Name returns the provider name.
 func (p *Provider) Name() string {
 	return "files"
@@ -53,12 +61,26 @@ func (p *Provider) Compile(ctx compiler.CompileContext) ([]compiler.Step, error)
 
 	// Add template steps
 	for _, tmpl := range cfg.Templates {
-		steps = append(steps, NewTemplateStep(tmpl, p.fs, p.lifecycle))
+		step := NewTemplateStep(tmpl, p.fs, p.lifecycle)
+		step.prefer = p.prefer
+		steps = append(steps, step)
 	}
 
 	// Add copy steps
 	for _, cp := range cfg.Copies {
-		steps = append(steps, NewCopyStep(cp, p.fs, p.lifecycle))
+		step := NewCopyStep(cp, p.fs, p.lifecycle)
+		step.prefer = p.prefer
+		steps = append(steps, step)
+	}
+
+	// Add block steps
+	for _, block := range cfg.Blocks {
+		steps = append(steps, NewBlockStep(block, p.fs, p.lifecycle))
+	}
+
+	// Add download steps
+	for _, dl := range cfg.Downloads {
+		steps = append(steps, NewDownloadStep(dl, p.fs, p.lifecycle))
 	}
 
 	return steps, nil
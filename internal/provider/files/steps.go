@@ -130,6 +130,7 @@ type CopyStep struct {
 	id        compiler.StepID
 	fs        ports.FileSystem
 	lifecycle ports.FileLifecycle
+	prefer    MergePreference
 }
 
 // NewCopyStep creates a new CopyStep.
@@ -157,7 +158,7 @@ func (s *CopyStep) DependsOn() []compiler.StepID {
 }
 
 // Check determines if the file needs to be copied.
-func (s *CopyStep) Check(_ compiler.RunContext) (compiler.StepStatus, error) {
+func (s *CopyStep) Check(ctx compiler.RunContext) (compiler.StepStatus, error) {
 	src := ports.ExpandPath(s.cp.Src)
 	dest := ports.ExpandPath(s.cp.Dest)
 
@@ -180,7 +181,11 @@ func (s *CopyStep) Check(_ compiler.RunContext) (compiler.StepStatus, error) {
 		return compiler.StatusSatisfied, nil
 	}
 
-	return compiler.StatusNeedsApply, nil
+	content, err := s.fs.ReadFile(src)
+	if err != nil {
+		return compiler.StatusUnknown, err
+	}
+	return s.writer().check(ctx.Context(), dest, content)
 }
 
 // Plan returns the diff for this step.
@@ -213,7 +218,8 @@ func (s *CopyStep) Apply(_ compiler.RunContext) error {
 	}
 
 	mode := parseFileMode(s.cp.Mode, 0o644)
-	if err := s.fs.WriteFile(dest, content, mode); err != nil {
+	conflicts, err := s.writer().write(ctx, dest, content, mode)
+	if err != nil {
 		return fmt.Errorf("failed to write destination: %w", err)
 	}
 
@@ -222,9 +228,17 @@ func (s *CopyStep) Apply(_ compiler.RunContext) error {
 		return fmt.Errorf("failed to record apply: %w", err)
 	}
 
+	if conflicts > 0 {
+		return &ConflictError{Path: dest, Conflicts: conflicts}
+	}
 	return nil
 }
 
+// writer returns the writer that merges local edits into the copy.
+func (s *CopyStep) writer() managedWriter {
+	return managedWriter{fs: s.fs, lifecycle: s.lifecycle, prefer: s.prefer}
+}
+
 // Explain provides a human-readable explanation.
 func (s *CopyStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
 	return compiler.NewExplanation(
@@ -240,6 +254,7 @@ type TemplateStep struct {
 	id        compiler.StepID
 	fs        ports.FileSystem
 	lifecycle ports.FileLifecycle
+	prefer    MergePreference
 }
 
 // NewTemplateStep creates a new TemplateStep.
@@ -267,7 +282,7 @@ func (s *TemplateStep) DependsOn() []compiler.StepID {
 }
 
 // Check determines if the template needs to be rendered.
-func (s *TemplateStep) Check(_ compiler.RunContext) (compiler.StepStatus, error) {
+func (s *TemplateStep) Check(ctx compiler.RunContext) (compiler.StepStatus, error) {
 	dest := ports.ExpandPath(s.tmpl.Dest)
 
 	if !s.fs.Exists(dest) {
@@ -291,16 +306,7 @@ func (s *TemplateStep) Check(_ compiler.RunContext) (compiler.StepStatus, error)
 		return compiler.StatusUnknown, err
 	}
 
-	existingContent, err := s.fs.ReadFile(dest)
-	if err != nil {
-		return compiler.StatusUnknown, err
-	}
-
-	if bytes.Equal(buf.Bytes(), existingContent) {
-		return compiler.StatusSatisfied, nil
-	}
-
-	return compiler.StatusNeedsApply, nil
+	return s.writer().check(ctx.Context(), dest, buf.Bytes())
 }
 
 // Plan returns the diff for this step.
@@ -343,7 +349,8 @@ func (s *TemplateStep) Apply(_ compiler.RunContext) error {
 	}
 
 	mode := parseFileMode(s.tmpl.Mode, 0o644)
-	if err := s.fs.WriteFile(dest, buf.Bytes(), mode); err != nil {
+	conflicts, err := s.writer().write(ctx, dest, buf.Bytes(), mode)
+	if err != nil {
 		return fmt.Errorf("failed to write output: %w", err)
 	}
 
@@ -352,9 +359,17 @@ func (s *TemplateStep) Apply(_ compiler.RunContext) error {
 		return fmt.Errorf("failed to record apply: %w", err)
 	}
 
+	if conflicts > 0 {
+		return &ConflictError{Path: dest, Conflicts: conflicts}
+	}
 	return nil
 }
 
+// writer returns the writer that merges local edits into the output.
+func (s *TemplateStep) writer() managedWriter {
+	return managedWriter{fs: s.fs, lifecycle: s.lifecycle, prefer: s.prefer}
+}
+
 // Explain provides a human-readable explanation.
 func (s *TemplateStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
 	return compiler.NewExplanation(
@@ -0,0 +1,124 @@
+package gcloud
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Config represents the gcloud section of the configuration.
+type Config struct {
+	Configurations []Configuration
+	Active         string
+}
+
+// Configuration is a named gcloud configuration.
+type Configuration struct {
+	Name    string
+	Account string
+	Project string
+	Region  string
+	Zone    string
+	// Properties holds additional properties keyed by section/property,
+	// e.g. "core/disable_usage_reporting".
+	Properties map[string]string
+	// KeyRef is a secret reference to a service account key. When set,
+	// Account is activated as a service account with that key.
+	KeyRef string
+}
+
+// properties returns every declared property keyed by section/property.
+func (c Configuration) properties() map[string]string {
+	props := make(map[string]string, len(c.Properties)+4)
+	for key, value := range c.Properties {
+		props[key] = value
+	}
+	for key, value := range map[string]string{
+		"core/account":   c.Account,
+		"core/project":   c.Project,
+		"compute/region": c.Region,
+		"compute/zone":   c.Zone,
+	} {
+		if value != "" {
+			props[key] = value
+		}
+	}
+	return props
+}
+
+// sortedProperties returns the property keys in a stable order.
+func sortedProperties(props map[string]string) []string {
+	keys := make([]string, 0, len(props))
+	for key := range props {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ParseConfig parses the gcloud configuration from a raw map.
+func ParseConfig(raw map[string]interface{}) (*Config, error) {
+	cfg := &Config{Configurations: make([]Configuration, 0)}
+
+	if configurations, ok := raw["configurations"].([]interface{}); ok {
+		for _, c := range configurations {
+			configuration, err := parseConfiguration(c)
+			if err != nil {
+				return nil, err
+			}
+			cfg.Configurations = append(cfg.Configurations, configuration)
+		}
+	}
+
+	if active, ok := raw["active"].(string); ok {
+		cfg.Active = active
+	}
+
+	return cfg, nil
+}
+
+func parseConfiguration(raw interface{}) (Configuration, error) {
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		return Configuration{}, fmt.Errorf("configuration must be an object")
+	}
+
+	c := Configuration{}
+	if name, ok := m["name"].(string); ok && name != "" {
+		c.Name = name
+	} else {
+		return Configuration{}, fmt.Errorf("configuration must have a name")
+	}
+	if account, ok := m["account"].(string); ok {
+		c.Account = account
+	}
+	if project, ok := m["project"].(string); ok {
+		c.Project = project
+	}
+	if region, ok := m["region"].(string); ok {
+		c.Region = region
+	}
+	if zone, ok := m["zone"].(string); ok {
+		c.Zone = zone
+	}
+	if props, ok := m["properties"].(map[string]interface{}); ok {
+		c.Properties = make(map[string]string, len(props))
+		for key, value := range props {
+			if !strings.Contains(key, "/") {
+				return Configuration{}, fmt.Errorf("configuration %s: property %q must be section/property", c.Name, key)
+			}
+			c.Properties[key] = fmt.Sprint(value)
+		}
+	}
+	if keyRef, ok := m["key_ref"].(string); ok {
+		if !strings.HasPrefix(keyRef, "secret://") {
+			return Configuration{}, fmt.Errorf("configuration %s: key_ref must be a secret reference (secret://backend/key)", c.Name)
+		}
+		if c.Account == "" {
+			return Configuration{}, fmt.Errorf("configuration %s: key_ref requires the service account", c.Name)
+		}
+		c.KeyRef = keyRef
+	}
+
+	return c, nil
+}
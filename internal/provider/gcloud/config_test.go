@@ -0,0 +1,46 @@
+package gcloud_test
+
+import (
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/provider/gcloud"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfig(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := gcloud.ParseConfig(map[string]interface{}{
+		"configurations": []interface{}{
+			map[string]interface{}{
+				"name":       "work",
+				"account":    "me@example.com",
+				"project":    "acme-prod",
+				"region":     "europe-west1",
+				"properties": map[string]interface{}{"core/disable_usage_reporting": true},
+			},
+		},
+		"active": "work",
+	})
+	require.NoError(t, err)
+	require.Len(t, cfg.Configurations, 1)
+	assert.Equal(t, "acme-prod", cfg.Configurations[0].Project)
+	assert.Equal(t, "true", cfg.Configurations[0].Properties["core/disable_usage_reporting"])
+	assert.Equal(t, "work", cfg.Active)
+}
+
+func TestParseConfig_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]map[string]interface{}{
+		"missing name":     {"project": "p"},
+		"bare property":    {"name": "w", "properties": map[string]interface{}{"project": "p"}},
+		"plain key":        {"name": "w", "account": "sa@p.iam.gserviceaccount.com", "key_ref": "/tmp/key.json"},
+		"key sans account": {"name": "w", "key_ref": "secret://1password/gcp-key"},
+	}
+	for name, configuration := range tests {
+		_, err := gcloud.ParseConfig(map[string]interface{}{"configurations": []interface{}{configuration}})
+		assert.Error(t, err, name)
+	}
+}
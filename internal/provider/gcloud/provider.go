@@ -0,0 +1,57 @@
+// Package gcloud provides the Google Cloud CLI provider for named configurations.
+package gcloud
+
+import (
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+)
+
+// Provider implements the compiler.Provider interface for the gcloud CLI.
+type Provider struct {
+	runner ports.CommandRunner
+}
+
+// NewProvider creates a new gcloud provider.
+func NewProvider(runner ports.CommandRunner) *Provider {
+	return &Provider{runner: runner}
+}
+
+// Name returns the provider name.
+func (p *Provider) Name() string {
+	return "gcloud"
+}
+
+// Compile transforms gcloud configuration into executable steps.
+func (p *Provider) Compile(ctx compiler.CompileContext) ([]compiler.Step, error) {
+	rawConfig := ctx.GetSection("gcloud")
+	if rawConfig == nil {
+		return nil, nil
+	}
+
+	cfg, err := ParseConfig(rawConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	steps := make([]compiler.Step, 0)
+	declared := make(map[string]bool)
+
+	for _, configuration := range cfg.Configurations {
+		declared[configuration.Name] = true
+		steps = append(steps, NewConfigurationStep(configuration, p.runner))
+
+		// Activate service accounts with secret-backed keys
+		if configuration.KeyRef != "" {
+			steps = append(steps, NewServiceAccountStep(configuration, p.runner))
+		}
+	}
+
+	if cfg.Active != "" {
+		steps = append(steps, NewActiveStep(cfg.Active, declared[cfg.Active], p.runner))
+	}
+
+	return steps, nil
+}
+
+// Ensure Provider implements compiler.Provider.
+var _ compiler.Provider = (*Provider)(nil)
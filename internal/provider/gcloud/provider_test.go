@@ -0,0 +1,46 @@
+package gcloud_test
+
+import (
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/provider/gcloud"
+	"github.com/felixgeelhaar/preflight/internal/testutil/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvider_Name(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "gcloud", gcloud.NewProvider(mocks.NewCommandRunner()).Name())
+}
+
+func TestProvider_Compile_NoConfig(t *testing.T) {
+	t.Parallel()
+
+	steps, err := gcloud.NewProvider(mocks.NewCommandRunner()).Compile(compiler.NewCompileContext(map[string]interface{}{}))
+	require.NoError(t, err)
+	assert.Nil(t, steps)
+}
+
+func TestProvider_Compile(t *testing.T) {
+	t.Parallel()
+
+	ctx := compiler.NewCompileContext(map[string]interface{}{
+		"gcloud": map[string]interface{}{
+			"configurations": []interface{}{
+				map[string]interface{}{"name": "work", "project": "acme-prod"},
+				map[string]interface{}{"name": "ci", "account": "ci@acme.iam.gserviceaccount.com", "key_ref": "secret://1password/gcp-ci"},
+			},
+			"active": "work",
+		},
+	})
+	steps, err := gcloud.NewProvider(mocks.NewCommandRunner()).Compile(ctx)
+	require.NoError(t, err)
+
+	ids := make([]string, len(steps))
+	for i, step := range steps {
+		ids[i] = step.ID().String()
+	}
+	assert.Equal(t, []string{"gcloud:configuration:work", "gcloud:configuration:ci", "gcloud:auth:ci", "gcloud:active"}, ids)
+}
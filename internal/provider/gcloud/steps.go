@@ -0,0 +1,298 @@
+package gcloud
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/provider/secretutil"
+	"gopkg.in/ini.v1"
+)
+
+// ConfigDir returns the gcloud configuration directory: $CLOUDSDK_CONFIG,
+// or ~/.config/gcloud (%APPDATA%\gcloud on Windows).
+func ConfigDir() string {
+	if dir := os.Getenv("CLOUDSDK_CONFIG"); dir != "" {
+		return dir
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("APPDATA"), "gcloud")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config", "gcloud")
+}
+
+// ConfigurationStep creates a named gcloud configuration and sets its properties.
+type ConfigurationStep struct {
+	configuration Configuration
+	id            compiler.StepID
+	runner        ports.CommandRunner
+}
+
+// NewConfigurationStep creates a new ConfigurationStep.
+func NewConfigurationStep(configuration Configuration, runner ports.CommandRunner) *ConfigurationStep {
+	return &ConfigurationStep{
+		configuration: configuration,
+		id:            compiler.MustNewStepID("gcloud:configuration:" + configuration.Name),
+		runner:        runner,
+	}
+}
+
+// ID returns the step identifier.
+func (s *ConfigurationStep) ID() compiler.StepID {
+	return s.id
+}
+
+// DependsOn returns the step dependencies.
+func (s *ConfigurationStep) DependsOn() []compiler.StepID {
+	return nil
+}
+
+// path returns the configuration's properties file.
+func (s *ConfigurationStep) path() string {
+	return filepath.Join(ConfigDir(), "configurations", "config_"+s.configuration.Name)
+}
+
+// Check determines if the configuration exists with the declared properties.
+func (s *ConfigurationStep) Check(_ compiler.RunContext) (compiler.StepStatus, error) {
+	cfg, err := ini.Load(s.path())
+	if err != nil {
+		return compiler.StatusNeedsApply, nil //nolint:nilerr // File not existing means we need to apply
+	}
+	for key, value := range s.configuration.properties() {
+		section, property, _ := strings.Cut(key, "/")
+		if cfg.Section(section).Key(property).String() != value {
+			return compiler.StatusNeedsApply, nil
+		}
+	}
+	return compiler.StatusSatisfied, nil
+}
+
+// Plan returns the diff for this step.
+func (s *ConfigurationStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
+	return compiler.NewDiff(compiler.DiffTypeModify, "configuration", s.configuration.Name, "", s.configuration.Project), nil
+}
+
+// Apply creates the configuration if needed and sets each property.
+func (s *ConfigurationStep) Apply(ctx compiler.RunContext) error {
+	name := s.configuration.Name
+	if _, err := os.Stat(s.path()); os.IsNotExist(err) {
+		if err := s.run(ctx, "config", "configurations", "create", name, "--no-activate"); err != nil {
+			return err
+		}
+	}
+	props := s.configuration.properties()
+	for _, key := range sortedProperties(props) {
+		if err := s.run(ctx, "config", "set", key, props[key], "--configuration", name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *ConfigurationStep) run(ctx compiler.RunContext, args ...string) error {
+	result, err := s.runner.Run(ctx.Context(), "gcloud", args...)
+	if err != nil {
+		return err
+	}
+	if !result.Success() {
+		return fmt.Errorf("gcloud %s failed: %s", strings.Join(args[:2], " "), result.Stderr)
+	}
+	return nil
+}
+
+// Explain provides a human-readable explanation.
+func (s *ConfigurationStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
+	return compiler.NewExplanation(
+		"Configure gcloud Configuration",
+		fmt.Sprintf("Creates or updates the %s gcloud configuration", s.configuration.Name),
+		[]string{
+			"https://cloud.google.com/sdk/docs/configurations",
+		},
+	).WithTradeoffs([]string{
+		"+ Switch projects and accounts with one command",
+		"+ Per-configuration defaults for region and zone",
+		"- User accounts still need 'gcloud auth login'",
+	})
+}
+
+// ServiceAccountStep activates a service account whose key is read from a
+// secret backend. The key is written to a private temporary file for the
+// activation and removed afterwards; gcloud keeps its own credential copy.
+type ServiceAccountStep struct {
+	configuration Configuration
+	id            compiler.StepID
+	runner        ports.CommandRunner
+}
+
+// NewServiceAccountStep creates a new ServiceAccountStep.
+func NewServiceAccountStep(configuration Configuration, runner ports.CommandRunner) *ServiceAccountStep {
+	return &ServiceAccountStep{
+		configuration: configuration,
+		id:            compiler.MustNewStepID("gcloud:auth:" + configuration.Name),
+		runner:        runner,
+	}
+}
+
+// ID returns the step identifier.
+func (s *ServiceAccountStep) ID() compiler.StepID {
+	return s.id
+}
+
+// DependsOn returns the step dependencies.
+func (s *ServiceAccountStep) DependsOn() []compiler.StepID {
+	return []compiler.StepID{compiler.MustNewStepID("gcloud:configuration:" + s.configuration.Name)}
+}
+
+// Check determines if the service account is already credentialed.
+func (s *ServiceAccountStep) Check(ctx compiler.RunContext) (compiler.StepStatus, error) {
+	result, err := s.runner.Run(ctx.Context(), "gcloud", "auth", "list", "--format=value(account)")
+	if err != nil || !result.Success() {
+		return compiler.StatusNeedsApply, nil //nolint:nilerr // gcloud failure means needs apply
+	}
+	for _, account := range strings.Fields(result.Stdout) {
+		if account == s.configuration.Account {
+			return compiler.StatusSatisfied, nil
+		}
+	}
+	return compiler.StatusNeedsApply, nil
+}
+
+// Plan returns the diff for this step.
+func (s *ServiceAccountStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
+	return compiler.NewDiff(compiler.DiffTypeAdd, "auth", s.configuration.Account, "", s.configuration.Name), nil
+}
+
+// Apply activates the service account with the key from the secret backend.
+func (s *ServiceAccountStep) Apply(ctx compiler.RunContext) error {
+	backend, key, ok := strings.Cut(strings.TrimPrefix(s.configuration.KeyRef, "secret://"), "/")
+	if !ok {
+		return fmt.Errorf("invalid secret reference %q", s.configuration.KeyRef)
+	}
+	secret, err := s.runner.Run(ctx.Context(), secretutil.Command(), "secrets", "get", "--backend", backend, key)
+	if err != nil {
+		return err
+	}
+	if !secret.Success() {
+		return fmt.Errorf("failed to resolve secret %s: %s", s.configuration.KeyRef, strings.TrimSpace(secret.Stderr))
+	}
+
+	keyFile, err := os.CreateTemp("", "preflight-gcloud-key-*.json")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(keyFile.Name()) }()
+	if _, err := keyFile.WriteString(secret.Stdout); err != nil {
+		_ = keyFile.Close()
+		return err
+	}
+	if err := keyFile.Close(); err != nil {
+		return err
+	}
+
+	result, err := s.runner.Run(ctx.Context(), "gcloud", "auth", "activate-service-account", s.configuration.Account,
+		"--key-file="+keyFile.Name(), "--configuration", s.configuration.Name)
+	if err != nil {
+		return err
+	}
+	if !result.Success() {
+		return fmt.Errorf("gcloud auth activate-service-account failed: %s", result.Stderr)
+	}
+	return nil
+}
+
+// Explain provides a human-readable explanation.
+func (s *ServiceAccountStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
+	return compiler.NewExplanation(
+		"Activate gcloud Service Account",
+		fmt.Sprintf("Activates %s in the %s configuration with a key from the secret backend", s.configuration.Account, s.configuration.Name),
+		[]string{
+			"https://cloud.google.com/sdk/gcloud/reference/auth/activate-service-account",
+		},
+	).WithTradeoffs([]string{
+		"+ Key never lives in the configuration repository",
+		"- gcloud stores the credential in its own config directory",
+	})
+}
+
+// ActiveStep activates a gcloud configuration.
+type ActiveStep struct {
+	name     string
+	declared bool
+	id       compiler.StepID
+	runner   ports.CommandRunner
+}
+
+// NewActiveStep creates a new ActiveStep. When declared, the step runs
+// after the configuration is created.
+func NewActiveStep(name string, declared bool, runner ports.CommandRunner) *ActiveStep {
+	return &ActiveStep{
+		name:     name,
+		declared: declared,
+		id:       compiler.MustNewStepID("gcloud:active"),
+		runner:   runner,
+	}
+}
+
+// ID returns the step identifier.
+func (s *ActiveStep) ID() compiler.StepID {
+	return s.id
+}
+
+// DependsOn returns the step dependencies.
+func (s *ActiveStep) DependsOn() []compiler.StepID {
+	if s.declared {
+		return []compiler.StepID{compiler.MustNewStepID("gcloud:configuration:" + s.name)}
+	}
+	return nil
+}
+
+// Check determines if the configuration is active.
+func (s *ActiveStep) Check(_ compiler.RunContext) (compiler.StepStatus, error) {
+	data, err := os.ReadFile(filepath.Join(ConfigDir(), "active_config"))
+	if err == nil && strings.TrimSpace(string(data)) == s.name {
+		return compiler.StatusSatisfied, nil
+	}
+	return compiler.StatusNeedsApply, nil
+}
+
+// Plan returns the diff for this step.
+func (s *ActiveStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
+	return compiler.NewDiff(compiler.DiffTypeModify, "active", "configuration", "", s.name), nil
+}
+
+// Apply activates the configuration.
+func (s *ActiveStep) Apply(ctx compiler.RunContext) error {
+	result, err := s.runner.Run(ctx.Context(), "gcloud", "config", "configurations", "activate", s.name)
+	if err != nil {
+		return err
+	}
+	if !result.Success() {
+		return fmt.Errorf("gcloud config configurations activate failed: %s", result.Stderr)
+	}
+	return nil
+}
+
+// Explain provides a human-readable explanation.
+func (s *ActiveStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
+	return compiler.NewExplanation(
+		"Activate gcloud Configuration",
+		fmt.Sprintf("Makes %s the active gcloud configuration", s.name),
+		[]string{
+			"https://cloud.google.com/sdk/gcloud/reference/config/configurations/activate",
+		},
+	).WithTradeoffs([]string{
+		"+ gcloud commands use the expected project by default",
+		"- Overrides a configuration activated manually",
+	})
+}
+
+var (
+	_ compiler.Step = (*ConfigurationStep)(nil)
+	_ compiler.Step = (*ServiceAccountStep)(nil)
+	_ compiler.Step = (*ActiveStep)(nil)
+)
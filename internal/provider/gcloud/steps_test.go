@@ -0,0 +1,84 @@
+package gcloud_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/provider/gcloud"
+	"github.com/felixgeelhaar/preflight/internal/testutil/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigurationStep_ApplyCreatesAndSets(t *testing.T) {
+	t.Setenv("CLOUDSDK_CONFIG", t.TempDir())
+
+	runner := mocks.NewCommandRunner()
+	runner.AddResult("gcloud", []string{"config", "configurations", "create", "work", "--no-activate"}, ports.CommandResult{})
+	runner.AddResult("gcloud", []string{"config", "set", "compute/region", "europe-west1", "--configuration", "work"}, ports.CommandResult{})
+	runner.AddResult("gcloud", []string{"config", "set", "core/project", "acme-prod", "--configuration", "work"}, ports.CommandResult{})
+
+	step := gcloud.NewConfigurationStep(gcloud.Configuration{Name: "work", Project: "acme-prod", Region: "europe-west1"}, runner)
+	ctx := compiler.NewRunContext(context.Background())
+	assert.Equal(t, "gcloud:configuration:work", step.ID().String())
+
+	status, err := step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+	require.NoError(t, step.Apply(ctx))
+	assert.Len(t, runner.Calls(), 3)
+}
+
+func TestConfigurationStep_Check_Satisfied(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("CLOUDSDK_CONFIG", dir)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "configurations"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "configurations", "config_work"),
+		[]byte("[core]\naccount = me@example.com\nproject = acme-prod\n\n[compute]\nzone = europe-west1-b\n"), 0o644))
+
+	step := gcloud.NewConfigurationStep(gcloud.Configuration{Name: "work", Account: "me@example.com", Project: "acme-prod"}, mocks.NewCommandRunner())
+	status, err := step.Check(compiler.NewRunContext(context.Background()))
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, status)
+
+	step = gcloud.NewConfigurationStep(gcloud.Configuration{Name: "work", Project: "acme-dev"}, mocks.NewCommandRunner())
+	status, err = step.Check(compiler.NewRunContext(context.Background()))
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+}
+
+func TestServiceAccountStep_Check(t *testing.T) {
+	t.Parallel()
+
+	runner := mocks.NewCommandRunner()
+	runner.AddResult("gcloud", []string{"auth", "list", "--format=value(account)"}, ports.CommandResult{
+		Stdout: "me@example.com\nci@acme.iam.gserviceaccount.com\n",
+	})
+	step := gcloud.NewServiceAccountStep(gcloud.Configuration{Name: "ci", Account: "ci@acme.iam.gserviceaccount.com", KeyRef: "secret://1password/gcp-ci"}, runner)
+
+	assert.Equal(t, []compiler.StepID{compiler.MustNewStepID("gcloud:configuration:ci")}, step.DependsOn())
+	status, err := step.Check(compiler.NewRunContext(context.Background()))
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, status)
+}
+
+func TestActiveStep(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("CLOUDSDK_CONFIG", dir)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "active_config"), []byte("default"), 0o644))
+
+	runner := mocks.NewCommandRunner()
+	runner.AddResult("gcloud", []string{"config", "configurations", "activate", "work"}, ports.CommandResult{})
+	step := gcloud.NewActiveStep("work", true, runner)
+	ctx := compiler.NewRunContext(context.Background())
+
+	status, err := step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+	require.NoError(t, step.Apply(ctx))
+	assert.Len(t, step.DependsOn(), 1)
+}
@@ -125,6 +125,12 @@ func (s *Step) LockInfo() (compiler.LockInfo, bool) {
 	}, true
 }
 
+// UninstallCommand returns the command that uninstalls the gem and its
+// executables.
+func (s *Step) UninstallCommand() []string {
+	return []string{"gem", "uninstall", s.gem.Name, "--all", "--executables"}
+}
+
 // InstalledVersion returns the installed gem version if available.
 func (s *Step) InstalledVersion(ctx compiler.RunContext) (string, bool, error) {
 	result, err := s.runner.Run(ctx.Context(), "gem", "list", s.gem.Name, "--exact")
@@ -15,6 +15,7 @@ type Config struct {
 	GPG      GPGConfig         // [gpg] section
 	Aliases  map[string]string // [alias] section
 	Includes []Include         // [includeIf] directives for identity separation
+	Ignore   IgnoreConfig      // Global gitignore, set as core.excludesfile
 }
 
 // UserConfig represents the [user] section.
@@ -48,6 +49,23 @@ type Include struct {
 	IfConfig string // Condition (e.g., "gitdir:~/work/")
 }
 
+// IgnoreConfig represents the global gitignore.
+type IgnoreConfig struct {
+	Path     string            // Defaults to core.excludesfile or DefaultIgnorePath
+	Template string            // text/template rendered before the patterns
+	Vars     map[string]string // Template variables
+	Patterns []string
+}
+
+// DefaultIgnorePath is where git looks for the global gitignore when
+// core.excludesfile is not set.
+const DefaultIgnorePath = "~/.config/git/ignore"
+
+// IsZero reports whether no global gitignore is declared.
+func (c IgnoreConfig) IsZero() bool {
+	return c.Template == "" && len(c.Patterns) == 0
+}
+
 // ID returns a unique identifier for this include.
 func (i Include) ID() string {
 	h := sha256.Sum256([]byte(i.Path + i.IfConfig))
@@ -62,6 +80,27 @@ func (c Config) ConfigPath() string {
 	return "~/.gitconfig"
 }
 
+// IgnorePath returns the path of the managed global gitignore.
+func (c Config) IgnorePath() string {
+	switch {
+	case c.Ignore.Path != "":
+		return c.Ignore.Path
+	case c.Core.ExcludesFile != "":
+		return c.Core.ExcludesFile
+	default:
+		return DefaultIgnorePath
+	}
+}
+
+// ExcludesFile returns the core.excludesfile value to write: the managed
+// global gitignore when one is declared.
+func (c Config) ExcludesFile() string {
+	if !c.Ignore.IsZero() {
+		return c.IgnorePath()
+	}
+	return c.Core.ExcludesFile
+}
+
 // ParseConfig parses the git configuration from a raw map.
 func ParseConfig(raw map[string]interface{}) (*Config, error) {
 	cfg := &Config{
@@ -143,5 +182,30 @@ func ParseConfig(raw map[string]interface{}) (*Config, error) {
 		}
 	}
 
+	// Parse global gitignore
+	if ignore, ok := raw["ignore"].(map[string]interface{}); ok {
+		if path, ok := ignore["path"].(string); ok {
+			cfg.Ignore.Path = path
+		}
+		if tmpl, ok := ignore["template"].(string); ok {
+			cfg.Ignore.Template = tmpl
+		}
+		if vars, ok := ignore["vars"].(map[string]interface{}); ok {
+			cfg.Ignore.Vars = make(map[string]string, len(vars))
+			for k, v := range vars {
+				cfg.Ignore.Vars[k] = fmt.Sprint(v)
+			}
+		}
+		if patterns, ok := ignore["patterns"].([]interface{}); ok {
+			for _, pattern := range patterns {
+				str, ok := pattern.(string)
+				if !ok {
+					return nil, fmt.Errorf("ignore patterns must be strings")
+				}
+				cfg.Ignore.Patterns = append(cfg.Ignore.Patterns, str)
+			}
+		}
+	}
+
 	return cfg, nil
 }
@@ -0,0 +1,129 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"text/template"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+)
+
+// IgnoreHeader starts the global gitignore preflight generates, so that a
+// file written by hand is never overwritten.
+const IgnoreHeader = "# Generated by preflight. Do not edit: changes are overwritten on apply.\n"
+
+// IgnoreStep generates the global gitignore.
+type IgnoreStep struct {
+	ignore       IgnoreConfig
+	path         string
+	templatePath string
+	id           compiler.StepID
+	fs           ports.FileSystem
+}
+
+// NewIgnoreStep creates a new IgnoreStep writing path. templatePath is the
+// resolved path of the ignore template, if any.
+func NewIgnoreStep(ignore IgnoreConfig, path, templatePath string, fs ports.FileSystem) *IgnoreStep {
+	return &IgnoreStep{
+		ignore:       ignore,
+		path:         path,
+		templatePath: templatePath,
+		id:           compiler.MustNewStepID("git:ignore"),
+		fs:           fs,
+	}
+}
+
+// ID returns the step identifier.
+func (s *IgnoreStep) ID() compiler.StepID {
+	return s.id
+}
+
+// DependsOn returns the step dependencies.
+func (s *IgnoreStep) DependsOn() []compiler.StepID {
+	return nil
+}
+
+// Check determines if the global gitignore needs to be written.
+func (s *IgnoreStep) Check(_ compiler.RunContext) (compiler.StepStatus, error) {
+	expected, err := s.render()
+	if err != nil {
+		return compiler.StatusUnknown, err
+	}
+	existing, err := s.fs.ReadFile(ports.ExpandPath(s.path))
+	if err == nil && bytes.Equal(existing, expected) {
+		return compiler.StatusSatisfied, nil
+	}
+	return compiler.StatusNeedsApply, nil
+}
+
+// Plan returns the diff for this step.
+func (s *IgnoreStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
+	diffType := compiler.DiffTypeAdd
+	if s.fs.Exists(ports.ExpandPath(s.path)) {
+		diffType = compiler.DiffTypeModify
+	}
+	return compiler.NewDiff(diffType, "gitignore", s.path, "", "generated"), nil
+}
+
+// Apply writes the global gitignore. It refuses to overwrite a file that
+// preflight did not generate.
+func (s *IgnoreStep) Apply(_ compiler.RunContext) error {
+	content, err := s.render()
+	if err != nil {
+		return err
+	}
+	path := ports.ExpandPath(s.path)
+	if existing, err := s.fs.ReadFile(path); err == nil && !bytes.HasPrefix(existing, []byte(IgnoreHeader)) {
+		return fmt.Errorf("%s was not generated by preflight; move its patterns to git.ignore.patterns and remove it", s.path)
+	}
+	if err := s.fs.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for gitignore: %w", err)
+	}
+	if err := s.fs.WriteFile(path, content, 0o644); err != nil {
+		return fmt.Errorf("failed to write gitignore: %w", err)
+	}
+	return nil
+}
+
+// Explain provides a human-readable explanation.
+func (s *IgnoreStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
+	return compiler.NewExplanation(
+		"Generate Global Gitignore",
+		fmt.Sprintf("Generates %s and sets it as core.excludesfile, so editor and OS files such as .DS_Store are ignored in every repository without adding them to each project's .gitignore.", s.path),
+		[]string{
+			"https://git-scm.com/docs/gitignore",
+		},
+	)
+}
+
+// render returns the global gitignore: the header, the rendered template
+// and the patterns.
+func (s *IgnoreStep) render() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(IgnoreHeader)
+
+	if s.templatePath != "" {
+		source, err := s.fs.ReadFile(s.templatePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read gitignore template: %w", err)
+		}
+		tmpl, err := template.New(filepath.Base(s.templatePath)).Option("missingkey=error").Parse(string(source))
+		if err != nil {
+			return nil, fmt.Errorf("invalid gitignore template %s: %w", s.templatePath, err)
+		}
+		if err := tmpl.Execute(&buf, s.ignore.Vars); err != nil {
+			return nil, fmt.Errorf("failed to render gitignore template %s: %w", s.templatePath, err)
+		}
+		if buf.Len() > 0 && buf.Bytes()[buf.Len()-1] != '\n' {
+			buf.WriteByte('\n')
+		}
+	}
+
+	for _, pattern := range s.ignore.Patterns {
+		buf.WriteString(pattern)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
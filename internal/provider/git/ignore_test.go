@@ -0,0 +1,88 @@
+package git
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/testutil/mocks"
+)
+
+func TestIgnoreStep_ApplyRendersTemplateAndPatterns(t *testing.T) {
+	fs := mocks.NewFileSystem()
+	fs.AddFile("/config/templates/gitignore.tmpl", "# {{ .team }}\n.DS_Store")
+	ignore := IgnoreConfig{
+		Template: "templates/gitignore.tmpl",
+		Vars:     map[string]string{"team": "platform"},
+		Patterns: []string{".idea/", "*.swp"},
+	}
+
+	step := NewIgnoreStep(ignore, DefaultIgnorePath, "/config/templates/gitignore.tmpl", fs)
+	if step.ID().String() != "git:ignore" {
+		t.Errorf("ID() = %q, want git:ignore", step.ID().String())
+	}
+	if err := step.Apply(compiler.RunContext{}); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	content, _ := fs.ReadFile(ports.ExpandPath(DefaultIgnorePath))
+	want := IgnoreHeader + "# platform\n.DS_Store\n.idea/\n*.swp\n"
+	if string(content) != want {
+		t.Errorf("content = %q, want %q", content, want)
+	}
+
+	status, err := step.Check(compiler.RunContext{})
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if status != compiler.StatusSatisfied {
+		t.Errorf("Check() = %v, want %v", status, compiler.StatusSatisfied)
+	}
+}
+
+func TestIgnoreStep_ApplyRefusesHandWrittenFile(t *testing.T) {
+	fs := mocks.NewFileSystem()
+	fs.AddFile(ports.ExpandPath(DefaultIgnorePath), "node_modules/\n")
+
+	step := NewIgnoreStep(IgnoreConfig{Patterns: []string{".DS_Store"}}, DefaultIgnorePath, "", fs)
+	err := step.Apply(compiler.RunContext{})
+	if err == nil || !strings.Contains(err.Error(), "not generated by preflight") {
+		t.Fatalf("Apply() error = %v, want refusal", err)
+	}
+}
+
+func TestIgnoreStep_MissingTemplateVariable(t *testing.T) {
+	fs := mocks.NewFileSystem()
+	fs.AddFile("/config/gitignore.tmpl", "{{ .missing }}\n")
+
+	step := NewIgnoreStep(IgnoreConfig{Template: "gitignore.tmpl"}, DefaultIgnorePath, "/config/gitignore.tmpl", fs)
+	if _, err := step.Check(compiler.RunContext{}); err == nil {
+		t.Fatal("Check() expected error for an undefined variable")
+	}
+}
+
+func TestConfig_IgnorePathAndExcludesFile(t *testing.T) {
+	tests := []struct {
+		name         string
+		cfg          Config
+		ignorePath   string
+		excludesFile string
+	}{
+		{"default", Config{Ignore: IgnoreConfig{Patterns: []string{".DS_Store"}}}, DefaultIgnorePath, DefaultIgnorePath},
+		{"explicit path", Config{Ignore: IgnoreConfig{Path: "~/.gitignore_global", Patterns: []string{".DS_Store"}}}, "~/.gitignore_global", "~/.gitignore_global"},
+		{"core excludesfile", Config{Core: CoreConfig{ExcludesFile: "~/.gitignore"}, Ignore: IgnoreConfig{Patterns: []string{".DS_Store"}}}, "~/.gitignore", "~/.gitignore"},
+		{"no ignore", Config{Core: CoreConfig{ExcludesFile: "~/.gitignore"}}, "~/.gitignore", "~/.gitignore"},
+		{"nothing", Config{}, DefaultIgnorePath, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.IgnorePath(); got != tt.ignorePath {
+				t.Errorf("IgnorePath() = %q, want %q", got, tt.ignorePath)
+			}
+			if got := tt.cfg.ExcludesFile(); got != tt.excludesFile {
+				t.Errorf("ExcludesFile() = %q, want %q", got, tt.excludesFile)
+			}
+		})
+	}
+}
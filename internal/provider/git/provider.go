@@ -1,6 +1,8 @@
 package git
 
 import (
+	"path/filepath"
+
 	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
 	"github.com/felixgeelhaar/preflight/internal/ports"
 )
@@ -32,14 +34,22 @@ func (p *Provider) Compile(ctx compiler.CompileContext) ([]compiler.Step, error)
 		return nil, err
 	}
 
+	steps := make([]compiler.Step, 0, 2)
+
 	// Only create step if there's actual config to write
-	if cfg.User.Name == "" && cfg.User.Email == "" && len(cfg.Aliases) == 0 &&
-		len(cfg.Includes) == 0 && cfg.Core.Editor == "" && !cfg.Commit.GPGSign {
-		return nil, nil
+	if cfg.User.Name != "" || cfg.User.Email != "" || len(cfg.Aliases) > 0 ||
+		len(cfg.Includes) > 0 || cfg.Core.Editor != "" || cfg.Commit.GPGSign {
+		steps = append(steps, NewConfigStep(cfg, p.fs))
 	}
 
-	steps := make([]compiler.Step, 0, 1)
-	steps = append(steps, NewConfigStep(cfg, p.fs))
+	// Generate the global gitignore
+	if !cfg.Ignore.IsZero() {
+		templatePath := ports.ExpandPath(cfg.Ignore.Template)
+		if templatePath != "" && !filepath.IsAbs(templatePath) {
+			templatePath = filepath.Join(ctx.ConfigRoot(), templatePath)
+		}
+		steps = append(steps, NewIgnoreStep(cfg.Ignore, cfg.IgnorePath(), templatePath, p.fs))
+	}
 
 	return steps, nil
 }
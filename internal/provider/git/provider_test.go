@@ -1,6 +1,7 @@
 package git
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
@@ -131,3 +132,57 @@ func TestGitProvider_Compile_InvalidConfig(t *testing.T) {
 		t.Error("Compile() should return error for invalid config")
 	}
 }
+
+func TestGitProvider_Compile_WithIgnore(t *testing.T) {
+	fs := mocks.NewFileSystem()
+	provider := NewProvider(fs)
+
+	ctx := compiler.NewCompileContext(map[string]interface{}{
+		"git": map[string]interface{}{
+			"user": map[string]interface{}{
+				"name": "John Doe",
+			},
+			"ignore": map[string]interface{}{
+				"template": "templates/gitignore.tmpl",
+				"patterns": []interface{}{".DS_Store"},
+			},
+		},
+	}).WithConfigRoot("/config")
+	steps, err := provider.Compile(ctx)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if len(steps) != 2 {
+		t.Fatalf("Compile() len = %d, want 2", len(steps))
+	}
+	ignore, ok := steps[1].(*IgnoreStep)
+	if !ok {
+		t.Fatalf("steps[1] = %T, want *IgnoreStep", steps[1])
+	}
+	if ignore.templatePath != "/config/templates/gitignore.tmpl" {
+		t.Errorf("templatePath = %q, want it resolved against the config root", ignore.templatePath)
+	}
+	if got := string(steps[0].(*ConfigStep).generateConfig()); !strings.Contains(got, "\texcludesfile = "+DefaultIgnorePath+"\n") {
+		t.Errorf("gitconfig does not set core.excludesfile:\n%s", got)
+	}
+}
+
+func TestGitProvider_Compile_IgnoreOnly(t *testing.T) {
+	fs := mocks.NewFileSystem()
+	provider := NewProvider(fs)
+
+	ctx := compiler.NewCompileContext(map[string]interface{}{
+		"git": map[string]interface{}{
+			"ignore": map[string]interface{}{
+				"patterns": []interface{}{".DS_Store"},
+			},
+		},
+	})
+	steps, err := provider.Compile(ctx)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if len(steps) != 1 || steps[0].ID().String() != "git:ignore" {
+		t.Fatalf("Compile() = %v, want only git:ignore so ~/.gitconfig is left alone", steps)
+	}
+}
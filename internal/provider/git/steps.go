@@ -116,7 +116,8 @@ func (s *ConfigStep) generateConfig() []byte {
 	}
 
 	// Write core section
-	if s.cfg.Core.Editor != "" || s.cfg.Core.AutoCRLF != "" || s.cfg.Core.ExcludesFile != "" {
+	excludesFile := s.cfg.ExcludesFile()
+	if s.cfg.Core.Editor != "" || s.cfg.Core.AutoCRLF != "" || excludesFile != "" {
 		buf.WriteString("[core]\n")
 		if s.cfg.Core.Editor != "" {
 			fmt.Fprintf(&buf, "\teditor = %s\n", s.cfg.Core.Editor)
@@ -124,8 +125,8 @@ func (s *ConfigStep) generateConfig() []byte {
 		if s.cfg.Core.AutoCRLF != "" {
 			fmt.Fprintf(&buf, "\tautocrlf = %s\n", s.cfg.Core.AutoCRLF)
 		}
-		if s.cfg.Core.ExcludesFile != "" {
-			fmt.Fprintf(&buf, "\texcludesfile = %s\n", s.cfg.Core.ExcludesFile)
+		if excludesFile != "" {
+			fmt.Fprintf(&buf, "\texcludesfile = %s\n", excludesFile)
 		}
 	}
 
@@ -2,11 +2,15 @@
 package gotools
 
 import (
+	"encoding/base64"
 	"fmt"
 	"path"
 	"strings"
 )
 
+// moduleSumPrefix prefixes the go.sum hash of a module's file tree.
+const moduleSumPrefix = "h1:"
+
 // Config represents the go section of the configuration.
 type Config struct {
 	Tools []Tool
@@ -14,8 +18,9 @@ type Config struct {
 
 // Tool represents a Go tool to install.
 type Tool struct {
-	Module  string // Full module path (e.g., "golang.org/x/tools/gopls")
-	Version string // Optional: specific version (e.g., "latest", "v0.14.0")
+	Module   string // Full module path (e.g., "golang.org/x/tools/gopls")
+	Version  string // Optional: specific version (e.g., "latest", "v0.14.0")
+	Checksum string // Optional: go.sum hash of the module providing the tool (e.g., "h1:...")
 }
 
 // FullName returns the module path with version for go install.
@@ -74,6 +79,16 @@ func parseTool(raw interface{}) (Tool, error) {
 		if version, ok := v["version"].(string); ok {
 			tool.Version = version
 		}
+		if checksum, ok := v["checksum"].(string); ok {
+			if err := validateModuleSum(checksum); err != nil {
+				return Tool{}, fmt.Errorf("tool %s has invalid checksum: %w", tool.Module, err)
+			}
+			// The hash covers one version of the module, so it must be pinned
+			if tool.Version == "" || tool.Version == "latest" {
+				return Tool{}, fmt.Errorf("tool %s has a checksum but no pinned version", tool.Module)
+			}
+			tool.Checksum = checksum
+		}
 		return tool, nil
 	default:
 		return Tool{}, fmt.Errorf("tool must be a string or object")
@@ -90,3 +105,18 @@ func parseToolString(s string) Tool {
 	}
 	return Tool{Module: s}
 }
+
+// validateModuleSum checks that sum is a go.sum module hash ("h1:" and a
+// base64 SHA-256). Unlike a hash of the built binary, it is the same on
+// every OS, architecture and toolchain.
+func validateModuleSum(sum string) error {
+	encoded, ok := strings.CutPrefix(sum, moduleSumPrefix)
+	if !ok {
+		return fmt.Errorf("expected a go.sum module hash (%s...), got %q", moduleSumPrefix, sum)
+	}
+	hash, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(hash) != 32 {
+		return fmt.Errorf("%q is not a valid %s hash", sum, strings.TrimSuffix(moduleSumPrefix, ":"))
+	}
+	return nil
+}
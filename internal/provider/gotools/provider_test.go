@@ -2,9 +2,11 @@ package gotools
 
 import (
 	"context"
+	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
@@ -441,6 +443,34 @@ func TestParseTool_Map(t *testing.T) {
 			input: map[string]interface{}{"module": "golang.org/x/tools/gopls", "version": "v0.14.0"},
 			want:  Tool{Module: "golang.org/x/tools/gopls", Version: "v0.14.0"},
 		},
+		{
+			name: "map with checksum",
+			input: map[string]interface{}{
+				"module":   "golang.org/x/tools/gopls",
+				"version":  "v0.14.0",
+				"checksum": testModuleSum,
+			},
+			want: Tool{
+				Module:   "golang.org/x/tools/gopls",
+				Version:  "v0.14.0",
+				Checksum: testModuleSum,
+			},
+		},
+		{
+			name:    "map with binary checksum",
+			input:   map[string]interface{}{"module": "golang.org/x/tools/gopls", "version": "v0.14.0", "checksum": "sha256:2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"},
+			wantErr: true,
+		},
+		{
+			name:    "map with invalid checksum",
+			input:   map[string]interface{}{"module": "golang.org/x/tools/gopls", "version": "v0.14.0", "checksum": "h1:short"},
+			wantErr: true,
+		},
+		{
+			name:    "map with checksum and latest version",
+			input:   map[string]interface{}{"module": "golang.org/x/tools/gopls", "version": "latest", "checksum": testModuleSum},
+			wantErr: true,
+		},
 		{
 			name:    "map missing module",
 			input:   map[string]interface{}{"version": "latest"},
@@ -519,3 +549,131 @@ func (e *commandNotFoundError) Error() string {
 func (e *commandNotFoundError) Unwrap() error {
 	return exec.ErrNotFound
 }
+
+// testModuleSum is a well-formed go.sum module hash.
+const testModuleSum = "h1:MEFYZIghzUoJOoLWYHMxYhjYS1a661TfYidBqpaC1yA="
+
+func TestToolStep_Apply_ChecksumVerified(t *testing.T) {
+	runner := mocks.NewCommandRunner()
+	runner.AddResult("go", []string{"mod", "download", "-json", "github.com/golangci/golangci-lint/cmd/golangci-lint@v1.55.0"}, ports.CommandResult{
+		Stdout:   `{"Path": "github.com/golangci/golangci-lint/cmd/golangci-lint", "Version": "v1.55.0", "Error": "module not found"}`,
+		ExitCode: 1,
+	})
+	runner.AddResult("go", []string{"mod", "download", "-json", "github.com/golangci/golangci-lint/cmd@v1.55.0"}, ports.CommandResult{
+		Stdout:   `{"Path": "github.com/golangci/golangci-lint/cmd", "Version": "v1.55.0", "Error": "module not found"}`,
+		ExitCode: 1,
+	})
+	runner.AddResult("go", []string{"mod", "download", "-json", "github.com/golangci/golangci-lint@v1.55.0"}, ports.CommandResult{
+		Stdout:   `{"Path": "github.com/golangci/golangci-lint", "Version": "v1.55.0", "Sum": "` + testModuleSum + `"}`,
+		ExitCode: 0,
+	})
+	runner.AddResult("go", []string{"install", "github.com/golangci/golangci-lint/cmd/golangci-lint@v1.55.0"}, ports.CommandResult{ExitCode: 0})
+
+	tool := Tool{
+		Module:   "github.com/golangci/golangci-lint/cmd/golangci-lint",
+		Version:  "v1.55.0",
+		Checksum: testModuleSum,
+	}
+	step := NewToolStep(tool, runner, nil)
+
+	if err := step.Apply(compiler.NewRunContext(context.Background())); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if calls := runner.Calls(); len(calls) != 4 || calls[3].Args[0] != "install" {
+		t.Errorf("Apply() should install after verifying the module, calls = %v", calls)
+	}
+}
+
+func TestToolStep_Apply_ChecksumMismatch(t *testing.T) {
+	runner := mocks.NewCommandRunner()
+	runner.AddResult("go", []string{"mod", "download", "-json", "golang.org/x/tools/gopls@v0.14.0"}, ports.CommandResult{
+		Stdout:   `{"Path": "golang.org/x/tools/gopls", "Version": "v0.14.0", "Sum": "h1:2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7="}`,
+		ExitCode: 0,
+	})
+
+	tool := Tool{
+		Module:   "golang.org/x/tools/gopls",
+		Version:  "v0.14.0",
+		Checksum: testModuleSum,
+	}
+	step := NewToolStep(tool, runner, nil)
+
+	err := step.Apply(compiler.NewRunContext(context.Background()))
+	if !errors.Is(err, ErrModuleSumMismatch) {
+		t.Fatalf("Apply() error = %v, want %v", err, ErrModuleSumMismatch)
+	}
+	for _, call := range runner.Calls() {
+		if call.Args[0] == "install" {
+			t.Error("Apply() should not install an unverified module")
+		}
+	}
+}
+
+func TestToolStep_Apply_ChecksumNoModule(t *testing.T) {
+	runner := mocks.NewCommandRunner()
+	for _, modPath := range []string{"example.com/missing/tool", "example.com/missing", "example.com"} {
+		runner.AddResult("go", []string{"mod", "download", "-json", modPath + "@v1.0.0"}, ports.CommandResult{
+			Stdout:   `{"Path": "` + modPath + `", "Version": "v1.0.0", "Error": "module not found"}`,
+			ExitCode: 1,
+		})
+	}
+
+	step := NewToolStep(Tool{Module: "example.com/missing/tool", Version: "v1.0.0", Checksum: testModuleSum}, runner, nil)
+
+	err := step.Apply(compiler.NewRunContext(context.Background()))
+	if err == nil || !strings.Contains(err.Error(), "module not found") {
+		t.Fatalf("Apply() error = %v, want module not found", err)
+	}
+}
+
+func TestToolStep_Check_Checksum(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("GOBIN", tempDir)
+	binaryPath := filepath.Join(tempDir, "gopls")
+	if err := os.WriteFile(binaryPath, []byte("fake"), 0755); err != nil {
+		t.Fatalf("failed to create fake binary: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		sum    string
+		status compiler.StepStatus
+	}{
+		{name: "built from pinned module", sum: testModuleSum, status: compiler.StatusSatisfied},
+		{name: "built from other module", sum: "h1:2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7=", status: compiler.StatusNeedsApply},
+		{name: "no module hash", sum: "", status: compiler.StatusNeedsApply},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := mocks.NewCommandRunner()
+			runner.AddResult("go", []string{"version", "-m", binaryPath}, ports.CommandResult{
+				Stdout:   "gopls: go1.21.0\n\tpath\tgolang.org/x/tools/gopls\n\tmod\tgolang.org/x/tools/gopls\tv0.14.0\t" + tt.sum + "\n",
+				ExitCode: 0,
+			})
+			step := NewToolStep(Tool{Module: "golang.org/x/tools/gopls", Version: "v0.14.0", Checksum: testModuleSum}, runner, nil)
+
+			status, err := step.Check(compiler.NewRunContext(context.Background()))
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+			if status != tt.status {
+				t.Errorf("Check() = %v, want %v", status, tt.status)
+			}
+		})
+	}
+}
+
+func TestToolStep_ArtifactInfo(t *testing.T) {
+	step := NewToolStep(Tool{Module: "golang.org/x/tools/gopls", Version: "v0.14.0"}, nil, nil)
+
+	info, ok := step.ArtifactInfo()
+	if !ok {
+		t.Fatal("ArtifactInfo() should report an artifact")
+	}
+	if info.Source != "golang.org/x/tools/gopls@v0.14.0" {
+		t.Errorf("ArtifactInfo().Source = %q", info.Source)
+	}
+	if info.Verified() {
+		t.Error("ArtifactInfo().Verified() should be false without a checksum")
+	}
+}
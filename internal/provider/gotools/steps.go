@@ -1,9 +1,13 @@
 package gotools
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"strings"
 
@@ -13,6 +17,10 @@ import (
 	"github.com/felixgeelhaar/preflight/internal/validation"
 )
 
+// ErrModuleSumMismatch is returned when a module's go.sum hash does not
+// match the checksum pinned in the configuration.
+var ErrModuleSumMismatch = errors.New("module checksum mismatch")
+
 // ToolStep represents a Go tool installation step.
 type ToolStep struct {
 	tool   Tool
@@ -56,10 +64,17 @@ func getGoBin() string {
 }
 
 // Check determines if the tool is already installed.
-func (s *ToolStep) Check(_ compiler.RunContext) (compiler.StepStatus, error) {
+func (s *ToolStep) Check(ctx compiler.RunContext) (compiler.StepStatus, error) {
 	// Check if binary exists in GOBIN
 	binaryPath := filepath.Join(getGoBin(), s.tool.BinaryName())
 	if _, err := os.Stat(binaryPath); err == nil {
+		// A pinned tool must have been built from the pinned module
+		if s.tool.Checksum != "" {
+			mod, ok := s.builtModule(ctx.Context(), binaryPath)
+			if !ok || len(mod) < 4 || mod[3] != s.tool.Checksum {
+				return compiler.StatusNeedsApply, nil
+			}
+		}
 		return compiler.StatusSatisfied, nil
 	}
 	if len(s.deps) == 0 {
@@ -86,6 +101,12 @@ func (s *ToolStep) Apply(ctx compiler.RunContext) error {
 		return fmt.Errorf("invalid Go tool: %w", err)
 	}
 
+	if s.tool.Checksum != "" {
+		if err := s.verifyModuleSum(ctx.Context()); err != nil {
+			return fmt.Errorf("verification of %s failed: %w", s.tool.FullName(), err)
+		}
+	}
+
 	result, err := s.runner.Run(ctx.Context(), "go", "install", s.tool.FullName())
 	if err != nil {
 		if commandutil.IsCommandNotFound(err) {
@@ -99,6 +120,43 @@ func (s *ToolStep) Apply(ctx compiler.RunContext) error {
 	return nil
 }
 
+// verifyModuleSum downloads the module providing the tool into the module
+// cache, which 'go install' then builds from, and checks its go.sum hash
+// against the pinned checksum. The tool path is usually a package inside a
+// larger module, so its parent paths are tried in turn, longest first, as
+// the go command resolves modules.
+func (s *ToolStep) verifyModuleSum(ctx context.Context) error {
+	var lastErr error
+	for modPath := s.tool.Module; ; modPath = path.Dir(modPath) {
+		result, err := s.runner.Run(ctx, "go", "mod", "download", "-json", modPath+"@"+s.tool.Version)
+		if err != nil {
+			if commandutil.IsCommandNotFound(err) {
+				return fmt.Errorf("go not found in PATH; install Go first")
+			}
+			return err
+		}
+		var download struct {
+			Sum   string
+			Error string
+		}
+		if err := json.Unmarshal([]byte(result.Stdout), &download); err != nil {
+			return fmt.Errorf("go mod download %s failed: %s", modPath, result.Stderr)
+		}
+		if download.Error == "" {
+			if download.Sum != s.tool.Checksum {
+				return fmt.Errorf("%w: %s@%s has %s, expected %s",
+					ErrModuleSumMismatch, modPath, s.tool.Version, download.Sum, s.tool.Checksum)
+			}
+			return nil
+		}
+		lastErr = errors.New(download.Error)
+		if !strings.Contains(modPath, "/") {
+			break
+		}
+	}
+	return fmt.Errorf("no module provides %s: %w", s.tool.FullName(), lastErr)
+}
+
 // Explain provides a human-readable explanation.
 func (s *ToolStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
 	desc := fmt.Sprintf("Installs the %s tool via 'go install'.", s.tool.BinaryName())
@@ -130,6 +188,15 @@ func (s *ToolStep) LockInfo() (compiler.LockInfo, bool) {
 	}, true
 }
 
+// ArtifactInfo returns the downloaded artifact details for verification reporting.
+func (s *ToolStep) ArtifactInfo() (compiler.ArtifactInfo, bool) {
+	return compiler.ArtifactInfo{
+		Name:     s.tool.BinaryName(),
+		Source:   s.tool.FullName(),
+		Checksum: s.tool.Checksum,
+	}, true
+}
+
 // InstalledVersion returns the installed Go tool version if available.
 func (s *ToolStep) InstalledVersion(ctx compiler.RunContext) (string, bool, error) {
 	binaryPath := filepath.Join(getGoBin(), s.tool.BinaryName())
@@ -148,15 +215,29 @@ func (s *ToolStep) InstalledVersion(ctx compiler.RunContext) (string, bool, erro
 		return "", false, nil
 	}
 
-	for _, line := range strings.Split(result.Stdout, "\n") {
+	if mod, ok := parseModLine(result.Stdout); ok && len(mod) >= 3 {
+		return mod[2], true, nil
+	}
+	return "", false, nil
+}
+
+// builtModule returns the fields of the "mod" line of a binary's build
+// info: "mod", the module path, its version and its go.sum hash.
+func (s *ToolStep) builtModule(ctx context.Context, binaryPath string) ([]string, bool) {
+	result, err := s.runner.Run(ctx, "go", "version", "-m", binaryPath)
+	if err != nil || !result.Success() {
+		return nil, false
+	}
+	return parseModLine(result.Stdout)
+}
+
+// parseModLine finds the main module line in 'go version -m' output.
+func parseModLine(output string) ([]string, bool) {
+	for _, line := range strings.Split(output, "\n") {
 		fields := strings.Fields(line)
 		if len(fields) >= 3 && fields[0] == "mod" {
-			version := strings.TrimSpace(fields[2])
-			if version != "" {
-				return version, true, nil
-			}
+			return fields, true
 		}
 	}
-
-	return "", false, nil
+	return nil, false
 }
@@ -11,12 +11,17 @@ type Config struct {
 	DefaultNamespace string
 }
 
-// Context represents a Kubernetes context configuration.
+// Context represents a Kubernetes context configuration. A context either
+// references an existing cluster and user, or renders them from a
+// kubeconfig template.
 type Context struct {
 	Name      string
 	Cluster   string
 	User      string
 	Namespace string
+	// Template is a kubeconfig fragment, relative to the config root, that
+	// defines the context's cluster and user. It may use {{ secret "backend/key" }}.
+	Template string
 }
 
 // ParseConfig parses the kubernetes configuration from a raw map.
@@ -86,5 +91,13 @@ func parseContext(raw interface{}) (Context, error) {
 		ctx.Namespace = namespace
 	}
 
+	if template, ok := m["template"].(string); ok {
+		ctx.Template = template
+	}
+
+	if ctx.Template != "" && (ctx.Cluster != "" || ctx.User != "") {
+		return Context{}, fmt.Errorf("context %s: template cannot be combined with cluster or user", ctx.Name)
+	}
+
 	return ctx, nil
 }
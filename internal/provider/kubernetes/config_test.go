@@ -161,3 +161,23 @@ func TestParseConfig_InvalidContext_NoName(t *testing.T) {
 	assert.Contains(t, err.Error(), "context must have a name")
 	assert.Nil(t, cfg)
 }
+
+func TestParseConfig_ContextTemplate(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := kubernetes.ParseConfig(map[string]interface{}{
+		"contexts": []interface{}{
+			map[string]interface{}{"name": "prod", "template": "kube/prod.yaml", "namespace": "payments"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "kube/prod.yaml", cfg.Contexts[0].Template)
+
+	_, err = kubernetes.ParseConfig(map[string]interface{}{
+		"contexts": []interface{}{
+			map[string]interface{}{"name": "prod", "template": "kube/prod.yaml", "cluster": "prod"},
+		},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot be combined")
+}
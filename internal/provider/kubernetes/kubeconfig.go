@@ -0,0 +1,286 @@
+package kubernetes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/provider/secretutil"
+)
+
+// kubeconfigSections are the named lists a kubeconfig fragment contributes.
+var kubeconfigSections = []string{"clusters", "users", "contexts"}
+
+// KubeconfigStep renders a context's kubeconfig template and merges its
+// clusters, users and context into the kubeconfig. Secrets referenced with
+// {{ secret "backend/key" }} are resolved through 'preflight secrets get'
+// at apply time, so the template itself can be committed.
+type KubeconfigStep struct {
+	context        Context
+	id             compiler.StepID
+	templatePath   string
+	kubeconfigPath string
+	runner         ports.CommandRunner
+}
+
+// NewKubeconfigStep creates a step rendering templatePath into kubeconfigPath.
+func NewKubeconfigStep(context Context, templatePath, kubeconfigPath string, runner ports.CommandRunner) *KubeconfigStep {
+	return &KubeconfigStep{
+		context:        context,
+		id:             compiler.MustNewStepID("kubernetes:context:" + context.Name),
+		templatePath:   templatePath,
+		kubeconfigPath: kubeconfigPath,
+		runner:         runner,
+	}
+}
+
+// ID returns the step identifier.
+func (s *KubeconfigStep) ID() compiler.StepID {
+	return s.id
+}
+
+// DependsOn returns the step dependencies.
+func (s *KubeconfigStep) DependsOn() []compiler.StepID {
+	return nil
+}
+
+// Check compares the rendered template with the kubeconfig without
+// resolving secrets: the context must match and its cluster servers and
+// users must exist. Rotated credentials are not detected.
+func (s *KubeconfigStep) Check(_ compiler.RunContext) (compiler.StepStatus, error) {
+	fragment, err := s.render(func(string) (string, error) { return "", nil })
+	if err != nil {
+		return compiler.StatusUnknown, err
+	}
+	current, err := readKubeconfig(s.kubeconfigPath)
+	if err != nil {
+		return compiler.StatusNeedsApply, nil //nolint:nilerr // missing kubeconfig means needs apply
+	}
+
+	for _, want := range namedEntries(fragment, "clusters") {
+		have := findEntry(current, "clusters", entryName(want))
+		if have == nil || nestedString(have, "cluster", "server") != nestedString(want, "cluster", "server") {
+			return compiler.StatusNeedsApply, nil
+		}
+	}
+	for _, want := range namedEntries(fragment, "users") {
+		if findEntry(current, "users", entryName(want)) == nil {
+			return compiler.StatusNeedsApply, nil
+		}
+	}
+	for _, want := range namedEntries(fragment, "contexts") {
+		have := findEntry(current, "contexts", entryName(want))
+		if have == nil || !jsonEqual(have["context"], want["context"]) {
+			return compiler.StatusNeedsApply, nil
+		}
+	}
+	return compiler.StatusSatisfied, nil
+}
+
+// Plan returns the diff for this step.
+func (s *KubeconfigStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
+	return compiler.NewDiff(compiler.DiffTypeModify, "context", s.context.Name, "", s.context.Template), nil
+}
+
+// Apply renders the template with resolved secrets and merges it into the
+// kubeconfig, replacing entries with the same name. The current context is
+// left unchanged.
+func (s *KubeconfigStep) Apply(ctx compiler.RunContext) error {
+	fragment, err := s.render(func(ref string) (string, error) {
+		return s.resolveSecret(ctx, ref)
+	})
+	if err != nil {
+		return err
+	}
+	current, err := readKubeconfig(s.kubeconfigPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+		current = map[string]interface{}{"apiVersion": "v1", "kind": "Config", "preferences": map[string]interface{}{}}
+	}
+
+	for _, section := range kubeconfigSections {
+		entries, _ := current[section].([]interface{})
+		for _, entry := range namedEntries(fragment, section) {
+			replaced := false
+			for i, existing := range entries {
+				if m, ok := existing.(map[string]interface{}); ok && entryName(m) == entryName(entry) {
+					entries[i] = entry
+					replaced = true
+				}
+			}
+			if !replaced {
+				entries = append(entries, entry)
+			}
+		}
+		current[section] = entries
+	}
+
+	data, err := yaml.Marshal(current)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.kubeconfigPath), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(s.kubeconfigPath, data, 0o600)
+}
+
+// Explain provides a human-readable explanation.
+func (s *KubeconfigStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
+	return compiler.NewExplanation(
+		"Render Kubernetes Context",
+		fmt.Sprintf("Renders %s into the kubeconfig as the %s context, resolving credentials from the secret backend", s.context.Template, s.context.Name),
+		[]string{
+			"https://kubernetes.io/docs/concepts/configuration/organize-cluster-access-kubeconfig/",
+		},
+	).WithTradeoffs([]string{
+		"+ Cluster access is reproducible without committing credentials",
+		"- Resolved credentials are stored in the kubeconfig (mode 0600)",
+		"- Rotated credentials need another apply",
+	})
+}
+
+// render executes the template and returns the kubeconfig fragment with the
+// declared context added or adjusted.
+func (s *KubeconfigStep) render(resolve func(ref string) (string, error)) (map[string]interface{}, error) {
+	// #nosec G304 -- template path comes from the user's configuration.
+	source, err := os.ReadFile(s.templatePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kubeconfig template: %w", err)
+	}
+	tmpl, err := template.New(filepath.Base(s.templatePath)).
+		Option("missingkey=error").
+		Funcs(template.FuncMap{
+			"secret": func(ref string) (string, error) { return resolve(strings.TrimPrefix(ref, "secret://")) },
+			"quote":  strconv.Quote,
+		}).
+		Parse(string(source))
+	if err != nil {
+		return nil, fmt.Errorf("invalid kubeconfig template %s: %w", s.templatePath, err)
+	}
+	var buf bytes.Buffer
+	data := map[string]string{"Name": s.context.Name, "Namespace": s.context.Namespace}
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w", s.templatePath, err)
+	}
+
+	fragment := make(map[string]interface{})
+	if err := yaml.Unmarshal(buf.Bytes(), &fragment); err != nil {
+		return nil, fmt.Errorf("rendered %s is not valid YAML: %w", s.templatePath, err)
+	}
+
+	context := findEntry(fragment, "contexts", s.context.Name)
+	if context == nil {
+		clusters, users := namedEntries(fragment, "clusters"), namedEntries(fragment, "users")
+		if len(clusters) != 1 || len(users) != 1 {
+			return nil, fmt.Errorf("%s must define context %q or exactly one cluster and user", s.templatePath, s.context.Name)
+		}
+		context = map[string]interface{}{
+			"name":    s.context.Name,
+			"context": map[string]interface{}{"cluster": entryName(clusters[0]), "user": entryName(users[0])},
+		}
+		contexts, _ := fragment["contexts"].([]interface{})
+		fragment["contexts"] = append(contexts, context)
+	}
+	if s.context.Namespace != "" {
+		if settings, ok := context["context"].(map[string]interface{}); ok {
+			settings["namespace"] = s.context.Namespace
+		}
+	}
+	return fragment, nil
+}
+
+// resolveSecret reads backend/key with 'preflight secrets get'.
+func (s *KubeconfigStep) resolveSecret(ctx compiler.RunContext, ref string) (string, error) {
+	backend, key, ok := strings.Cut(ref, "/")
+	if !ok {
+		return "", fmt.Errorf("invalid secret reference %q (use backend/key)", ref)
+	}
+	result, err := s.runner.Run(ctx.Context(), secretutil.Command(), "secrets", "get", "--backend", backend, key)
+	if err != nil {
+		return "", err
+	}
+	if !result.Success() {
+		return "", fmt.Errorf("failed to resolve secret %s: %s", ref, strings.TrimSpace(result.Stderr))
+	}
+	return strings.TrimRight(result.Stdout, "\r\n"), nil
+}
+
+// KubeconfigPath returns the kubeconfig kubectl writes to: the first entry
+// of $KUBECONFIG, or ~/.kube/config.
+func KubeconfigPath() (string, error) {
+	for _, path := range filepath.SplitList(os.Getenv("KUBECONFIG")) {
+		if path != "" {
+			return path, nil
+		}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".kube", "config"), nil
+}
+
+func readKubeconfig(path string) (map[string]interface{}, error) {
+	// #nosec G304 -- path is the user's kubeconfig.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config := make(map[string]interface{})
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// namedEntries returns the entries of a kubeconfig list such as "clusters".
+func namedEntries(config map[string]interface{}, section string) []map[string]interface{} {
+	list, _ := config[section].([]interface{})
+	entries := make([]map[string]interface{}, 0, len(list))
+	for _, item := range list {
+		if m, ok := item.(map[string]interface{}); ok {
+			entries = append(entries, m)
+		}
+	}
+	return entries
+}
+
+func findEntry(config map[string]interface{}, section, name string) map[string]interface{} {
+	for _, entry := range namedEntries(config, section) {
+		if entryName(entry) == name {
+			return entry
+		}
+	}
+	return nil
+}
+
+func entryName(entry map[string]interface{}) string {
+	name, _ := entry["name"].(string)
+	return name
+}
+
+func nestedString(entry map[string]interface{}, key, field string) string {
+	m, _ := entry[key].(map[string]interface{})
+	v, _ := m[field].(string)
+	return v
+}
+
+func jsonEqual(a, b interface{}) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
+
+var _ compiler.Step = (*KubeconfigStep)(nil)
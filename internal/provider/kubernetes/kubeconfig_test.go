@@ -0,0 +1,139 @@
+package kubernetes_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/provider/kubernetes"
+	"github.com/felixgeelhaar/preflight/internal/testutil/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const prodTemplate = `clusters:
+  - name: prod
+    cluster:
+      server: https://prod.example.com:6443
+users:
+  - name: prod-deployer
+    user:
+      token: {{ secret "secret://1password/k8s-prod-token" | quote }}
+`
+
+func TestKubeconfigStep_ApplyAndCheck(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	templatePath := filepath.Join(dir, "prod.yaml")
+	require.NoError(t, os.WriteFile(templatePath, []byte(prodTemplate), 0o644))
+	kubeconfig := filepath.Join(dir, ".kube", "config")
+	require.NoError(t, os.MkdirAll(filepath.Dir(kubeconfig), 0o700))
+	require.NoError(t, os.WriteFile(kubeconfig, []byte("current-context: dev\ncontexts:\n  - name: dev\n    context: {cluster: dev}\n"), 0o600))
+
+	exe, err := os.Executable()
+	require.NoError(t, err)
+	runner := mocks.NewCommandRunner()
+	runner.AddResult(exe, []string{"secrets", "get", "--backend", "1password", "k8s-prod-token"}, ports.CommandResult{Stdout: "s3cr3t\n"})
+
+	step := kubernetes.NewKubeconfigStep(kubernetes.Context{Name: "prod", Namespace: "payments", Template: "prod.yaml"}, templatePath, kubeconfig, runner)
+	ctx := compiler.NewRunContext(context.Background())
+	assert.Equal(t, "kubernetes:context:prod", step.ID().String())
+
+	status, err := step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+
+	require.NoError(t, step.Apply(ctx))
+
+	data, err := os.ReadFile(kubeconfig)
+	require.NoError(t, err)
+	var config struct {
+		CurrentContext string `yaml:"current-context"`
+		Contexts       []struct {
+			Name    string            `yaml:"name"`
+			Context map[string]string `yaml:"context"`
+		} `yaml:"contexts"`
+		Users []struct {
+			User map[string]string `yaml:"user"`
+		} `yaml:"users"`
+	}
+	require.NoError(t, yaml.Unmarshal(data, &config))
+	assert.Equal(t, "dev", config.CurrentContext)
+	require.Len(t, config.Contexts, 2)
+	assert.Equal(t, map[string]string{"cluster": "prod", "user": "prod-deployer", "namespace": "payments"}, config.Contexts[1].Context)
+	assert.Equal(t, "s3cr3t", config.Users[0].User["token"])
+
+	info, err := os.Stat(kubeconfig)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	status, err = step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, status)
+}
+
+func TestKubeconfigStep_AmbiguousTemplate(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	templatePath := filepath.Join(dir, "multi.yaml")
+	require.NoError(t, os.WriteFile(templatePath, []byte("clusters:\n  - name: a\n  - name: b\nusers:\n  - name: u\n"), 0o644))
+
+	step := kubernetes.NewKubeconfigStep(kubernetes.Context{Name: "x", Template: "multi.yaml"}, templatePath, filepath.Join(dir, "config"), mocks.NewCommandRunner())
+	_, err := step.Check(compiler.NewRunContext(context.Background()))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exactly one cluster and user")
+}
+
+func TestKrewStep(t *testing.T) {
+	t.Parallel()
+
+	runner := mocks.NewCommandRunner()
+	step := kubernetes.NewKrewStep(runner)
+	ctx := compiler.NewRunContext(context.Background())
+
+	status, err := step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+
+	runner.AddResult("brew", []string{"install", "krew"}, ports.CommandResult{})
+	require.NoError(t, step.Apply(ctx))
+	assert.Equal(t, []compiler.StepID{step.ID()}, kubernetes.NewPluginStep("ctx", true, runner).DependsOn())
+}
+
+func TestContextStep_Check_NamespaceDrift(t *testing.T) {
+	t.Parallel()
+
+	runner := mocks.NewCommandRunner()
+	runner.AddResult("kubectl", []string{"config", "get-contexts", "-o", "name"}, ports.CommandResult{Stdout: "dev\n"})
+	runner.AddResult("kubectl", []string{"config", "view", "-o", `jsonpath={.contexts[?(@.name=="dev")].context.namespace}`}, ports.CommandResult{Stdout: "default"})
+
+	step := kubernetes.NewContextStep(kubernetes.Context{Name: "dev", Cluster: "dev", Namespace: "team-a"}, runner)
+	status, err := step.Check(compiler.NewRunContext(context.Background()))
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+}
+
+func TestProvider_Compile_TemplatedContext(t *testing.T) {
+	t.Setenv("KUBECONFIG", "/tmp/kube/config:/tmp/kube/other")
+
+	p := kubernetes.NewProvider(mocks.NewCommandRunner())
+	ctx := compiler.NewCompileContext(map[string]interface{}{
+		"kubernetes": map[string]interface{}{
+			"contexts": []interface{}{
+				map[string]interface{}{"name": "prod", "template": "kube/prod.yaml"},
+			},
+		},
+	}).WithConfigRoot("/cfg")
+
+	steps, err := p.Compile(ctx)
+	require.NoError(t, err)
+	require.Len(t, steps, 1)
+	explanation := steps[0].Explain(compiler.NewExplainContext())
+	assert.Contains(t, explanation.Detail(), "kube/prod.yaml")
+}
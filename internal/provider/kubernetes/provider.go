@@ -2,6 +2,8 @@
 package kubernetes
 
 import (
+	"path/filepath"
+
 	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
 	"github.com/felixgeelhaar/preflight/internal/ports"
 )
@@ -35,14 +37,29 @@ func (p *Provider) Compile(ctx compiler.CompileContext) ([]compiler.Step, error)
 
 	steps := make([]compiler.Step, 0)
 
-	// Add plugin steps (krew plugins)
+	// Add krew and plugin steps
+	if len(cfg.Plugins) > 0 {
+		steps = append(steps, NewKrewStep(p.runner))
+	}
 	for _, plugin := range cfg.Plugins {
-		steps = append(steps, NewPluginStep(plugin, p.runner))
+		steps = append(steps, NewPluginStep(plugin, true, p.runner))
 	}
 
-	// Add context steps
+	// Add context steps; templated contexts are rendered into the kubeconfig
 	for _, context := range cfg.Contexts {
-		steps = append(steps, NewContextStep(context, p.runner))
+		if context.Template == "" {
+			steps = append(steps, NewContextStep(context, p.runner))
+			continue
+		}
+		kubeconfig, err := KubeconfigPath()
+		if err != nil {
+			return nil, err
+		}
+		templatePath := context.Template
+		if !filepath.IsAbs(templatePath) {
+			templatePath = filepath.Join(ctx.ConfigRoot(), templatePath)
+		}
+		steps = append(steps, NewKubeconfigStep(context, templatePath, kubeconfig, p.runner))
 	}
 
 	// Add namespace step if default namespace is specified
@@ -61,9 +61,10 @@ func TestProvider_Compile_WithPlugins(t *testing.T) {
 	steps, err := p.Compile(ctx)
 
 	require.NoError(t, err)
-	require.Len(t, steps, 2)
-	assert.Equal(t, "kubernetes:plugin:ctx", steps[0].ID().String())
-	assert.Equal(t, "kubernetes:plugin:ns", steps[1].ID().String())
+	require.Len(t, steps, 3)
+	assert.Equal(t, "kubernetes:krew", steps[0].ID().String())
+	assert.Equal(t, "kubernetes:plugin:ctx", steps[1].ID().String())
+	assert.Equal(t, "kubernetes:plugin:ns", steps[2].ID().String())
 }
 
 func TestProvider_Compile_WithContexts(t *testing.T) {
@@ -128,5 +129,5 @@ func TestProvider_Compile_Complete(t *testing.T) {
 	steps, err := p.Compile(ctx)
 
 	require.NoError(t, err)
-	require.Len(t, steps, 3)
+	require.Len(t, steps, 4)
 }
@@ -8,20 +8,85 @@ import (
 	"github.com/felixgeelhaar/preflight/internal/ports"
 )
 
-// PluginStep represents a kubectl krew plugin installation step.
-type PluginStep struct {
-	plugin string
+// KrewStep installs krew, the kubectl plugin manager.
+type KrewStep struct {
 	id     compiler.StepID
 	runner ports.CommandRunner
 }
 
-// NewPluginStep creates a new PluginStep.
-func NewPluginStep(plugin string, runner ports.CommandRunner) *PluginStep {
+// NewKrewStep creates a new KrewStep.
+func NewKrewStep(runner ports.CommandRunner) *KrewStep {
+	return &KrewStep{
+		id:     compiler.MustNewStepID("kubernetes:krew"),
+		runner: runner,
+	}
+}
+
+// ID returns the step identifier.
+func (s *KrewStep) ID() compiler.StepID {
+	return s.id
+}
+
+// DependsOn returns the step dependencies.
+func (s *KrewStep) DependsOn() []compiler.StepID {
+	return nil
+}
+
+// Check determines if krew is installed.
+func (s *KrewStep) Check(ctx compiler.RunContext) (compiler.StepStatus, error) {
+	result, err := s.runner.Run(ctx.Context(), "kubectl", "krew", "version")
+	if err == nil && result.Success() {
+		return compiler.StatusSatisfied, nil
+	}
+	return compiler.StatusNeedsApply, nil
+}
+
+// Plan returns the diff for this step.
+func (s *KrewStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
+	return compiler.NewDiff(compiler.DiffTypeAdd, "krew", "krew", "", "installed"), nil
+}
+
+// Apply installs krew with Homebrew.
+func (s *KrewStep) Apply(ctx compiler.RunContext) error {
+	result, err := s.runner.Run(ctx.Context(), "brew", "install", "krew")
+	if err != nil {
+		return err
+	}
+	if !result.Success() {
+		return fmt.Errorf("brew install krew failed: %s", result.Stderr)
+	}
+	return nil
+}
+
+// Explain provides a human-readable explanation.
+func (s *KrewStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
+	return compiler.NewExplanation(
+		"Install krew",
+		"Installs krew, the kubectl plugin manager, so declared plugins can be installed",
+		[]string{"https://krew.sigs.k8s.io/docs/user-guide/setup/install/"},
+	).WithTradeoffs([]string{
+		"+ Plugins install and upgrade like packages",
+		"- Plugins run from ~/.krew/bin, which must be on PATH",
+	})
+}
+
+// PluginStep represents a kubectl krew plugin installation step.
+type PluginStep struct {
+	plugin       string
+	id           compiler.StepID
+	requiresKrew bool
+	runner       ports.CommandRunner
+}
+
+// NewPluginStep creates a new PluginStep. With requiresKrew, the step runs
+// after krew is installed.
+func NewPluginStep(plugin string, requiresKrew bool, runner ports.CommandRunner) *PluginStep {
 	id := compiler.MustNewStepID("kubernetes:plugin:" + plugin)
 	return &PluginStep{
-		plugin: plugin,
-		id:     id,
-		runner: runner,
+		plugin:       plugin,
+		id:           id,
+		requiresKrew: requiresKrew,
+		runner:       runner,
 	}
 }
 
@@ -32,6 +97,9 @@ func (s *PluginStep) ID() compiler.StepID {
 
 // DependsOn returns the step dependencies.
 func (s *PluginStep) DependsOn() []compiler.StepID {
+	if s.requiresKrew {
+		return []compiler.StepID{compiler.MustNewStepID("kubernetes:krew")}
+	}
 	return nil
 }
 
@@ -128,12 +196,25 @@ func (s *ContextStep) Check(ctx compiler.RunContext) (compiler.StepStatus, error
 	contexts := strings.Split(strings.TrimSpace(result.Stdout), "\n")
 	for _, c := range contexts {
 		if strings.TrimSpace(c) == s.context.Name {
-			return compiler.StatusSatisfied, nil
+			return s.checkNamespace(ctx)
 		}
 	}
 	return compiler.StatusNeedsApply, nil
 }
 
+// checkNamespace verifies the existing context uses the declared namespace.
+func (s *ContextStep) checkNamespace(ctx compiler.RunContext) (compiler.StepStatus, error) {
+	if s.context.Namespace == "" {
+		return compiler.StatusSatisfied, nil
+	}
+	query := fmt.Sprintf(`jsonpath={.contexts[?(@.name==%q)].context.namespace}`, s.context.Name)
+	result, err := s.runner.Run(ctx.Context(), "kubectl", "config", "view", "-o", query)
+	if err != nil || !result.Success() || strings.TrimSpace(result.Stdout) != s.context.Namespace {
+		return compiler.StatusNeedsApply, nil //nolint:nilerr // unreadable namespace means needs apply
+	}
+	return compiler.StatusSatisfied, nil
+}
+
 // Plan returns the diff for this step.
 func (s *ContextStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
 	return compiler.NewDiff(compiler.DiffTypeAdd, "context", s.context.Name, "", s.context.Cluster), nil
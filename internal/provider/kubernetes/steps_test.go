@@ -20,7 +20,7 @@ func TestPluginStep_ID(t *testing.T) {
 	t.Parallel()
 
 	runner := mocks.NewCommandRunner()
-	step := kubernetes.NewPluginStep("ctx", runner)
+	step := kubernetes.NewPluginStep("ctx", false, runner)
 
 	assert.Equal(t, "kubernetes:plugin:ctx", step.ID().String())
 }
@@ -29,7 +29,7 @@ func TestPluginStep_DependsOn(t *testing.T) {
 	t.Parallel()
 
 	runner := mocks.NewCommandRunner()
-	step := kubernetes.NewPluginStep("ctx", runner)
+	step := kubernetes.NewPluginStep("ctx", false, runner)
 
 	deps := step.DependsOn()
 	assert.Empty(t, deps)
@@ -44,7 +44,7 @@ func TestPluginStep_Check_Satisfied(t *testing.T) {
 		Stdout:   "ctx\nns\nstern\n",
 	})
 
-	step := kubernetes.NewPluginStep("ctx", runner)
+	step := kubernetes.NewPluginStep("ctx", false, runner)
 
 	ctx := compiler.NewRunContext(context.Background())
 	status, err := step.Check(ctx)
@@ -62,7 +62,7 @@ func TestPluginStep_Check_NeedsApply(t *testing.T) {
 		Stdout:   "ns\nstern\n",
 	})
 
-	step := kubernetes.NewPluginStep("ctx", runner)
+	step := kubernetes.NewPluginStep("ctx", false, runner)
 
 	ctx := compiler.NewRunContext(context.Background())
 	status, err := step.Check(ctx)
@@ -77,7 +77,7 @@ func TestPluginStep_Check_KrewNotInstalled(t *testing.T) {
 	runner := mocks.NewCommandRunner()
 	// Don't register any result - the mock will return an error
 
-	step := kubernetes.NewPluginStep("ctx", runner)
+	step := kubernetes.NewPluginStep("ctx", false, runner)
 
 	ctx := compiler.NewRunContext(context.Background())
 	status, err := step.Check(ctx)
@@ -95,7 +95,7 @@ func TestPluginStep_Check_CommandFailed(t *testing.T) {
 		Stderr:   "permission denied",
 	})
 
-	step := kubernetes.NewPluginStep("ctx", runner)
+	step := kubernetes.NewPluginStep("ctx", false, runner)
 
 	ctx := compiler.NewRunContext(context.Background())
 	status, err := step.Check(ctx)
@@ -108,7 +108,7 @@ func TestPluginStep_Plan(t *testing.T) {
 	t.Parallel()
 
 	runner := mocks.NewCommandRunner()
-	step := kubernetes.NewPluginStep("ctx", runner)
+	step := kubernetes.NewPluginStep("ctx", false, runner)
 
 	ctx := compiler.NewRunContext(context.Background())
 	diff, err := step.Plan(ctx)
@@ -127,7 +127,7 @@ func TestPluginStep_Apply_Success(t *testing.T) {
 		ExitCode: 0,
 	})
 
-	step := kubernetes.NewPluginStep("ctx", runner)
+	step := kubernetes.NewPluginStep("ctx", false, runner)
 
 	ctx := compiler.NewRunContext(context.Background())
 	err := step.Apply(ctx)
@@ -144,7 +144,7 @@ func TestPluginStep_Apply_Failure(t *testing.T) {
 		Stderr:   "plugin not found",
 	})
 
-	step := kubernetes.NewPluginStep("ctx", runner)
+	step := kubernetes.NewPluginStep("ctx", false, runner)
 
 	ctx := compiler.NewRunContext(context.Background())
 	err := step.Apply(ctx)
@@ -157,7 +157,7 @@ func TestPluginStep_Explain(t *testing.T) {
 	t.Parallel()
 
 	runner := mocks.NewCommandRunner()
-	step := kubernetes.NewPluginStep("ctx", runner)
+	step := kubernetes.NewPluginStep("ctx", false, runner)
 
 	ctx := compiler.NewExplainContext()
 	explanation := step.Explain(ctx)
@@ -2,14 +2,16 @@ package macos
 
 import (
 	"fmt"
+	"strings"
 )
 
 // Config represents the macos section of the configuration.
 type Config struct {
-	Defaults []Default
-	Dock     DockConfig
-	Finder   FinderConfig
-	Keyboard KeyboardConfig
+	Defaults   []Default
+	Dock       DockConfig
+	HotCorners map[string]string // corner -> action
+	Finder     FinderConfig
+	Keyboard   KeyboardConfig
 }
 
 // Default represents a single macOS defaults setting.
@@ -22,15 +24,57 @@ type Default struct {
 
 // DockConfig represents Dock preferences.
 type DockConfig struct {
-	Add    []string // Apps to add to dock
-	Remove []string // Apps to remove from dock
+	Apps        []string // Exact Dock contents, in order
+	Add         []string // Apps to add to dock
+	Remove      []string // Apps to remove from dock
+	Autohide    *bool
+	TileSize    *int
+	Orientation string // left, bottom or right
+	ShowRecents *bool
 }
 
 // FinderConfig represents Finder preferences.
 type FinderConfig struct {
-	ShowHidden     *bool
-	ShowExtensions *bool
-	ShowPathBar    *bool
+	ShowHidden            *bool
+	ShowExtensions        *bool
+	ShowPathBar           *bool
+	ShowStatusBar         *bool
+	DefaultView           string // icon, list, column or gallery
+	NewWindowTarget       string // home, desktop, documents or a path
+	SearchCurrentFolder   *bool
+	FoldersFirst          *bool
+	WarnOnExtensionChange *bool
+}
+
+// hotCornerActions maps hot corner action names to their Dock codes.
+var hotCornerActions = map[string]int{
+	"none":                 1,
+	"mission-control":      2,
+	"application-windows":  3,
+	"desktop":              4,
+	"start-screen-saver":   5,
+	"disable-screen-saver": 6,
+	"put-display-to-sleep": 10,
+	"launchpad":            11,
+	"notification-center":  12,
+	"lock-screen":          13,
+	"quick-note":           14,
+}
+
+// hotCornerKeys maps corner names to the suffix of their Dock defaults keys.
+var hotCornerKeys = map[string]string{
+	"top_left":     "tl",
+	"top_right":    "tr",
+	"bottom_left":  "bl",
+	"bottom_right": "br",
+}
+
+// finderViews maps view names to FXPreferredViewStyle codes.
+var finderViews = map[string]string{
+	"icon":    "icnv",
+	"list":    "Nlsv",
+	"column":  "clmv",
+	"gallery": "glyv",
 }
 
 // KeyboardConfig represents keyboard preferences.
@@ -76,6 +120,51 @@ func ParseConfig(raw map[string]interface{}) (*Config, error) {
 				}
 			}
 		}
+		if apps, ok := dock["apps"].([]interface{}); ok {
+			for _, item := range apps {
+				if s, ok := item.(string); ok {
+					cfg.Dock.Apps = append(cfg.Dock.Apps, s)
+				}
+			}
+		}
+		if len(cfg.Dock.Apps) > 0 && (len(cfg.Dock.Add) > 0 || len(cfg.Dock.Remove) > 0) {
+			return nil, fmt.Errorf("dock.apps sets the whole Dock and cannot be combined with dock.add or dock.remove")
+		}
+		if v, ok := dock["autohide"].(bool); ok {
+			cfg.Dock.Autohide = &v
+		}
+		if v, ok := dock["tile_size"].(int); ok {
+			if v < 16 || v > 128 {
+				return nil, fmt.Errorf("dock.tile_size must be between 16 and 128, got %d", v)
+			}
+			cfg.Dock.TileSize = &v
+		}
+		if v, ok := dock["orientation"].(string); ok {
+			switch v {
+			case "left", "bottom", "right":
+				cfg.Dock.Orientation = v
+			default:
+				return nil, fmt.Errorf("dock.orientation must be left, bottom or right, got %q", v)
+			}
+		}
+		if v, ok := dock["show_recents"].(bool); ok {
+			cfg.Dock.ShowRecents = &v
+		}
+	}
+
+	// Parse hot corners
+	if corners, ok := raw["hot_corners"].(map[string]interface{}); ok {
+		cfg.HotCorners = make(map[string]string, len(corners))
+		for corner, value := range corners {
+			action, _ := value.(string)
+			if _, ok := hotCornerKeys[corner]; !ok {
+				return nil, fmt.Errorf("unknown hot corner %q (use top_left, top_right, bottom_left or bottom_right)", corner)
+			}
+			if _, ok := hotCornerActions[action]; !ok {
+				return nil, fmt.Errorf("unknown hot corner action %q for %s", action, corner)
+			}
+			cfg.HotCorners[corner] = action
+		}
 	}
 
 	// Parse finder
@@ -89,6 +178,32 @@ func ParseConfig(raw map[string]interface{}) (*Config, error) {
 		if v, ok := finder["show_path_bar"].(bool); ok {
 			cfg.Finder.ShowPathBar = &v
 		}
+		if v, ok := finder["show_status_bar"].(bool); ok {
+			cfg.Finder.ShowStatusBar = &v
+		}
+		if v, ok := finder["default_view"].(string); ok {
+			if _, known := finderViews[v]; !known {
+				return nil, fmt.Errorf("finder.default_view must be icon, list, column or gallery, got %q", v)
+			}
+			cfg.Finder.DefaultView = v
+		}
+		if v, ok := finder["new_window_target"].(string); ok {
+			switch {
+			case v == "home", v == "desktop", v == "documents", strings.HasPrefix(v, "/"), strings.HasPrefix(v, "~/"):
+				cfg.Finder.NewWindowTarget = v
+			default:
+				return nil, fmt.Errorf("finder.new_window_target must be home, desktop, documents or an absolute path, got %q", v)
+			}
+		}
+		if v, ok := finder["search_current_folder"].(bool); ok {
+			cfg.Finder.SearchCurrentFolder = &v
+		}
+		if v, ok := finder["folders_first"].(bool); ok {
+			cfg.Finder.FoldersFirst = &v
+		}
+		if v, ok := finder["warn_on_extension_change"].(bool); ok {
+			cfg.Finder.WarnOnExtensionChange = &v
+		}
 	}
 
 	// Parse keyboard
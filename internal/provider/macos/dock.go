@@ -0,0 +1,188 @@
+package macos
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/provider/commandutil"
+	"github.com/felixgeelhaar/preflight/internal/provider/plistutil"
+)
+
+// appDirs are searched, in order, for apps declared by name.
+var appDirs = []string{"/Applications", "/System/Applications", "/System/Applications/Utilities"}
+
+// DockLayoutStep makes the Dock's app section exactly match a declared,
+// ordered list. The current layout is read from the com.apple.dock
+// preferences; changes are written with dockutil.
+type DockLayoutStep struct {
+	apps   []string
+	id     compiler.StepID
+	runner ports.CommandRunner
+}
+
+// NewDockLayoutStep creates a step arranging the Dock to apps. Apps are
+// paths or names of apps in /Applications or /System/Applications.
+func NewDockLayoutStep(apps []string, runner ports.CommandRunner) *DockLayoutStep {
+	return &DockLayoutStep{
+		apps:   apps,
+		id:     compiler.MustNewStepID("macos:dock:layout"),
+		runner: runner,
+	}
+}
+
+// ID returns the step identifier.
+func (s *DockLayoutStep) ID() compiler.StepID {
+	return s.id
+}
+
+// DependsOn returns the step dependencies.
+func (s *DockLayoutStep) DependsOn() []compiler.StepID {
+	return nil
+}
+
+// Check compares the Dock's apps and their order with the declaration.
+func (s *DockLayoutStep) Check(ctx compiler.RunContext) (compiler.StepStatus, error) {
+	current, err := s.current(ctx)
+	if err != nil {
+		return compiler.StatusUnknown, err
+	}
+	if strings.Join(current, "\n") == strings.Join(s.paths(), "\n") {
+		return compiler.StatusSatisfied, nil
+	}
+	return compiler.StatusNeedsApply, nil
+}
+
+// Plan returns the diff for this step.
+func (s *DockLayoutStep) Plan(ctx compiler.RunContext) (compiler.Diff, error) {
+	current, _ := s.current(ctx)
+	labels := make([]string, 0, len(current))
+	for _, path := range current {
+		labels = append(labels, AppLabel(path))
+	}
+	return compiler.NewDiff(compiler.DiffTypeModify, "dock", "apps", strings.Join(labels, ", "), strings.Join(s.apps, ", ")), nil
+}
+
+// Apply replaces the Dock's apps with the declared list and restarts the
+// Dock once.
+func (s *DockLayoutStep) Apply(ctx compiler.RunContext) error {
+	commands := [][]string{{"--remove", "all", "--no-restart"}}
+	for _, path := range s.paths() {
+		commands = append(commands, []string{"--add", path, "--no-restart"})
+	}
+	for _, args := range commands {
+		result, err := s.runner.Run(ctx.Context(), "dockutil", args...)
+		if err != nil {
+			if commandutil.IsCommandNotFound(err) {
+				return fmt.Errorf("dockutil not found in PATH; add it to packages.brew.formulae")
+			}
+			return err
+		}
+		if !result.Success() {
+			return fmt.Errorf("dockutil %s failed: %s", strings.Join(args, " "), strings.TrimSpace(result.Stderr))
+		}
+	}
+	_, _ = s.runner.Run(ctx.Context(), "killall", "Dock")
+	return nil
+}
+
+// Explain provides a human-readable explanation.
+func (s *DockLayoutStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
+	return compiler.NewExplanation(
+		"Arrange Dock",
+		fmt.Sprintf("Sets the Dock to %d apps in the declared order", len(s.apps)),
+		[]string{
+			"https://github.com/kcrawford/dockutil",
+		},
+	).WithTradeoffs([]string{
+		"+ New machines get the same Dock layout",
+		"- Apps pinned by hand are removed on the next apply",
+		"- Requires dockutil to be installed",
+	})
+}
+
+func (s *DockLayoutStep) current(ctx compiler.RunContext) ([]string, error) {
+	result, err := s.runner.Run(ctx.Context(), "defaults", "export", "com.apple.dock", "-")
+	if err != nil {
+		return nil, err
+	}
+	if !result.Success() {
+		return nil, fmt.Errorf("defaults export com.apple.dock failed: %s", strings.TrimSpace(result.Stderr))
+	}
+	return DockApps([]byte(result.Stdout))
+}
+
+func (s *DockLayoutStep) paths() []string {
+	paths := make([]string, 0, len(s.apps))
+	for _, app := range s.apps {
+		paths = append(paths, ResolveApp(app))
+	}
+	return paths
+}
+
+// DockApps returns the paths of the apps in the Dock, in order, from an
+// exported com.apple.dock preferences plist.
+func DockApps(data []byte) ([]string, error) {
+	prefs, err := plistutil.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	root, _ := prefs.(map[string]interface{})
+	tiles, _ := root["persistent-apps"].([]interface{})
+	apps := make([]string, 0, len(tiles))
+	for _, tile := range tiles {
+		tileMap, _ := tile.(map[string]interface{})
+		tileData, _ := tileMap["tile-data"].(map[string]interface{})
+		fileData, _ := tileData["file-data"].(map[string]interface{})
+		raw, _ := fileData["_CFURLString"].(string)
+		if raw == "" {
+			continue
+		}
+		path := raw
+		if u, err := url.Parse(raw); err == nil && u.Scheme == "file" {
+			path = u.Path
+		}
+		apps = append(apps, strings.TrimSuffix(path, "/"))
+	}
+	return apps, nil
+}
+
+// ResolveApp returns the path of a Dock app declared as a path or as the
+// name of an app in one of the standard application folders.
+func ResolveApp(app string) string {
+	if strings.HasPrefix(app, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, app[2:])
+		}
+	}
+	if strings.HasPrefix(app, "/") {
+		return strings.TrimSuffix(app, "/")
+	}
+	name := strings.TrimSuffix(app, ".app") + ".app"
+	for _, dir := range appDirs {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return filepath.Join(appDirs[0], name)
+}
+
+// AppLabel returns the name used to declare the app at path: its name when
+// it lives in a standard application folder, otherwise the path itself.
+func AppLabel(path string) string {
+	if strings.HasSuffix(path, ".app") {
+		for _, dir := range appDirs {
+			if filepath.Dir(path) == dir {
+				return strings.TrimSuffix(filepath.Base(path), ".app")
+			}
+		}
+	}
+	return path
+}
+
+var _ compiler.Step = (*DockLayoutStep)(nil)
@@ -0,0 +1,177 @@
+package macos
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/testutil/mocks"
+)
+
+const dockExport = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>autohide</key>
+	<true/>
+	<key>persistent-apps</key>
+	<array>
+		<dict>
+			<key>tile-data</key>
+			<dict>
+				<key>file-data</key>
+				<dict>
+					<key>_CFURLString</key>
+					<string>file:///Applications/Safari.app/</string>
+					<key>_CFURLStringType</key>
+					<integer>15</integer>
+				</dict>
+				<key>file-label</key>
+				<string>Safari</string>
+			</dict>
+			<key>tile-type</key>
+			<string>file-tile</string>
+		</dict>
+		<dict>
+			<key>tile-data</key>
+			<dict>
+				<key>file-data</key>
+				<dict>
+					<key>_CFURLString</key>
+					<string>file:///System/Applications/System%20Settings.app/</string>
+				</dict>
+			</dict>
+		</dict>
+	</array>
+	<key>tilesize</key>
+	<integer>48</integer>
+</dict>
+</plist>
+`
+
+func TestDockApps(t *testing.T) {
+	t.Parallel()
+
+	apps, err := DockApps([]byte(dockExport))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/Applications/Safari.app", "/System/Applications/System Settings.app"}, apps)
+	assert.Equal(t, "Safari", AppLabel(apps[0]))
+	assert.Equal(t, "System Settings", AppLabel(apps[1]))
+	assert.Equal(t, "/Users/me/Apps/Tool.app", AppLabel("/Users/me/Apps/Tool.app"))
+}
+
+func TestResolveApp(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "/opt/Tool.app", ResolveApp("/opt/Tool.app/"))
+	assert.Equal(t, "/Applications/Nonexistent Thing.app", ResolveApp("Nonexistent Thing"))
+}
+
+func TestDockLayoutStep_Check(t *testing.T) {
+	t.Parallel()
+
+	runner := mocks.NewCommandRunner()
+	runner.AddResult("defaults", []string{"export", "com.apple.dock", "-"}, ports.CommandResult{Stdout: dockExport})
+	ctx := compiler.NewRunContext(context.Background())
+
+	status, err := NewDockLayoutStep([]string{"/Applications/Safari.app", "/System/Applications/System Settings.app"}, runner).Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, status)
+
+	status, err = NewDockLayoutStep([]string{"/System/Applications/System Settings.app", "/Applications/Safari.app"}, runner).Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status, "order matters")
+}
+
+func TestDockLayoutStep_Apply(t *testing.T) {
+	t.Parallel()
+
+	runner := mocks.NewCommandRunner()
+	runner.AddResult("dockutil", []string{"--remove", "all", "--no-restart"}, ports.CommandResult{})
+	runner.AddResult("dockutil", []string{"--add", "/Applications/Safari.app", "--no-restart"}, ports.CommandResult{})
+	runner.AddResult("dockutil", []string{"--add", "/opt/Tool.app", "--no-restart"}, ports.CommandResult{})
+	runner.AddResult("killall", []string{"Dock"}, ports.CommandResult{})
+
+	step := NewDockLayoutStep([]string{"/Applications/Safari.app", "/opt/Tool.app"}, runner)
+	require.NoError(t, step.Apply(compiler.NewRunContext(context.Background())))
+
+	calls := runner.Calls()
+	require.Len(t, calls, 4)
+	assert.Equal(t, []string{"--add", "/opt/Tool.app", "--no-restart"}, calls[2].Args)
+	assert.Equal(t, "killall", calls[3].Command)
+}
+
+func TestProvider_Compile_DockLayoutAndHotCorners(t *testing.T) {
+	t.Parallel()
+
+	steps, err := NewProvider(mocks.NewCommandRunner()).Compile(compiler.NewCompileContext(map[string]interface{}{
+		"macos": map[string]interface{}{
+			"dock": map[string]interface{}{
+				"apps":      []interface{}{"Safari", "/opt/Tool.app"},
+				"autohide":  true,
+				"tile_size": 48,
+			},
+			"hot_corners": map[string]interface{}{"top_right": "desktop", "bottom_left": "lock-screen"},
+			"finder": map[string]interface{}{
+				"default_view":      "column",
+				"new_window_target": "~/src",
+				"folders_first":     true,
+			},
+		},
+	}))
+	require.NoError(t, err)
+
+	ids := make([]string, 0, len(steps))
+	for _, step := range steps {
+		ids = append(ids, step.ID().String())
+	}
+	assert.Equal(t, []string{
+		"macos:dock:layout",
+		"macos:defaults:com.apple.dock:autohide",
+		"macos:defaults:com.apple.dock:tilesize",
+		"macos:defaults:com.apple.dock:wvous-bl-corner",
+		"macos:defaults:com.apple.dock:wvous-bl-modifier",
+		"macos:defaults:com.apple.dock:wvous-tr-corner",
+		"macos:defaults:com.apple.dock:wvous-tr-modifier",
+		"macos:defaults:com.apple.finder:FXPreferredViewStyle",
+		"macos:defaults:com.apple.finder:NewWindowTarget",
+		"macos:defaults:com.apple.finder:NewWindowTargetPath",
+		"macos:defaults:com.apple.finder:FXSortFoldersFirst",
+	}, ids)
+	corner := steps[5].(*DefaultsStep)
+	assert.Equal(t, 4, corner.setting.Value)
+	assert.Equal(t, "Dock", corner.restart)
+}
+
+func TestParseConfig_DockAndFinderErrors(t *testing.T) {
+	t.Parallel()
+
+	for name, raw := range map[string]map[string]interface{}{
+		"apps with add":   {"dock": map[string]interface{}{"apps": []interface{}{"Safari"}, "add": []interface{}{"Mail"}}},
+		"tile size":       {"dock": map[string]interface{}{"tile_size": 500}},
+		"orientation":     {"dock": map[string]interface{}{"orientation": "top"}},
+		"corner":          {"hot_corners": map[string]interface{}{"middle": "desktop"}},
+		"corner action":   {"hot_corners": map[string]interface{}{"top_left": "explode"}},
+		"view":            {"finder": map[string]interface{}{"default_view": "tree"}},
+		"relative target": {"finder": map[string]interface{}{"new_window_target": "src"}},
+	} {
+		_, err := ParseConfig(raw)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestDefaultsStep_Check_BoolValue(t *testing.T) {
+	t.Parallel()
+
+	runner := mocks.NewCommandRunner()
+	runner.AddResult("defaults", []string{"read", "com.apple.dock", "autohide"}, ports.CommandResult{Stdout: "1\n"})
+	step := NewDefaultsStep(Default{Domain: "com.apple.dock", Key: "autohide", Type: "bool", Value: true}, runner)
+
+	status, err := step.Check(compiler.NewRunContext(context.Background()))
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, status)
+}
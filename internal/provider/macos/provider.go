@@ -2,6 +2,12 @@
 package macos
 
 import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
 	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
 	"github.com/felixgeelhaar/preflight/internal/ports"
 )
@@ -41,6 +47,9 @@ func (p *Provider) Compile(ctx compiler.CompileContext) ([]compiler.Step, error)
 	}
 
 	// Add dock steps
+	if len(cfg.Dock.Apps) > 0 {
+		steps = append(steps, NewDockLayoutStep(cfg.Dock.Apps, p.runner))
+	}
 	for _, item := range cfg.Dock.Add {
 		steps = append(steps, NewDockStep(item, true, p.runner))
 	}
@@ -48,6 +57,10 @@ func (p *Provider) Compile(ctx compiler.CompileContext) ([]compiler.Step, error)
 		steps = append(steps, NewDockStep(item, false, p.runner))
 	}
 
+	for _, def := range dockDefaults(cfg) {
+		steps = append(steps, NewDefaultsStep(def, p.runner).WithRestart("Dock"))
+	}
+
 	// Add Finder settings
 	if cfg.Finder.ShowHidden != nil {
 		steps = append(steps, NewFinderStep("AppleShowAllFiles", *cfg.Finder.ShowHidden, p.runner))
@@ -58,6 +71,12 @@ func (p *Provider) Compile(ctx compiler.CompileContext) ([]compiler.Step, error)
 	if cfg.Finder.ShowPathBar != nil {
 		steps = append(steps, NewFinderStep("ShowPathbar", *cfg.Finder.ShowPathBar, p.runner))
 	}
+	if cfg.Finder.ShowStatusBar != nil {
+		steps = append(steps, NewFinderStep("ShowStatusBar", *cfg.Finder.ShowStatusBar, p.runner))
+	}
+	for _, def := range finderDefaults(cfg.Finder) {
+		steps = append(steps, NewDefaultsStep(def, p.runner).WithRestart("Finder"))
+	}
 
 	// Add keyboard settings
 	if cfg.Keyboard.KeyRepeat != nil {
@@ -70,5 +89,84 @@ func (p *Provider) Compile(ctx compiler.CompileContext) ([]compiler.Step, error)
 	return steps, nil
 }
 
+// dockDefaults returns the com.apple.dock settings for Dock preferences and
+// hot corners. Hot corners are written without a modifier key.
+func dockDefaults(cfg *Config) []Default {
+	const domain = "com.apple.dock"
+	var defs []Default
+	if cfg.Dock.Autohide != nil {
+		defs = append(defs, Default{Domain: domain, Key: "autohide", Type: "bool", Value: *cfg.Dock.Autohide})
+	}
+	if cfg.Dock.TileSize != nil {
+		defs = append(defs, Default{Domain: domain, Key: "tilesize", Type: "int", Value: *cfg.Dock.TileSize})
+	}
+	if cfg.Dock.Orientation != "" {
+		defs = append(defs, Default{Domain: domain, Key: "orientation", Type: "string", Value: cfg.Dock.Orientation})
+	}
+	if cfg.Dock.ShowRecents != nil {
+		defs = append(defs, Default{Domain: domain, Key: "show-recents", Type: "bool", Value: *cfg.Dock.ShowRecents})
+	}
+
+	corners := make([]string, 0, len(cfg.HotCorners))
+	for corner := range cfg.HotCorners {
+		corners = append(corners, corner)
+	}
+	sort.Strings(corners)
+	for _, corner := range corners {
+		key := "wvous-" + hotCornerKeys[corner]
+		defs = append(defs,
+			Default{Domain: domain, Key: key + "-corner", Type: "int", Value: hotCornerActions[cfg.HotCorners[corner]]},
+			Default{Domain: domain, Key: key + "-modifier", Type: "int", Value: 0},
+		)
+	}
+	return defs
+}
+
+// finderDefaults returns the com.apple.finder settings that are not simple
+// booleans handled by FinderStep.
+func finderDefaults(finder FinderConfig) []Default {
+	const domain = "com.apple.finder"
+	var defs []Default
+	if finder.DefaultView != "" {
+		defs = append(defs, Default{Domain: domain, Key: "FXPreferredViewStyle", Type: "string", Value: finderViews[finder.DefaultView]})
+	}
+	if finder.NewWindowTarget != "" {
+		switch finder.NewWindowTarget {
+		case "home":
+			defs = append(defs, Default{Domain: domain, Key: "NewWindowTarget", Type: "string", Value: "PfHm"})
+		case "desktop":
+			defs = append(defs, Default{Domain: domain, Key: "NewWindowTarget", Type: "string", Value: "PfDe"})
+		case "documents":
+			defs = append(defs, Default{Domain: domain, Key: "NewWindowTarget", Type: "string", Value: "PfDo"})
+		default:
+			path := finder.NewWindowTarget
+			if rest, ok := strings.CutPrefix(path, "~/"); ok {
+				if home, err := os.UserHomeDir(); err == nil {
+					path = filepath.Join(home, rest)
+				}
+			}
+			target := (&url.URL{Scheme: "file", Path: strings.TrimSuffix(path, "/") + "/"}).String()
+			defs = append(defs,
+				Default{Domain: domain, Key: "NewWindowTarget", Type: "string", Value: "PfLo"},
+				Default{Domain: domain, Key: "NewWindowTargetPath", Type: "string", Value: target},
+			)
+		}
+	}
+	if finder.SearchCurrentFolder != nil {
+		scope := "SCev"
+		if *finder.SearchCurrentFolder {
+			scope = "SCcf"
+		}
+		defs = append(defs, Default{Domain: domain, Key: "FXDefaultSearchScope", Type: "string", Value: scope})
+	}
+	if finder.FoldersFirst != nil {
+		defs = append(defs, Default{Domain: domain, Key: "_FXSortFoldersFirst", Type: "bool", Value: *finder.FoldersFirst})
+	}
+	if finder.WarnOnExtensionChange != nil {
+		defs = append(defs, Default{Domain: domain, Key: "FXEnableExtensionChangeWarning", Type: "bool", Value: *finder.WarnOnExtensionChange})
+	}
+	return defs
+}
+
 // Ensure Provider implements compiler.Provider.
 var _ compiler.Provider = (*Provider)(nil)
@@ -13,12 +13,15 @@ import (
 type DefaultsStep struct {
 	setting Default
 	id      compiler.StepID
+	restart string
 	runner  ports.CommandRunner
 }
 
 // NewDefaultsStep creates a new DefaultsStep.
 func NewDefaultsStep(setting Default, runner ports.CommandRunner) *DefaultsStep {
-	id := compiler.MustNewStepID(fmt.Sprintf("macos:defaults:%s:%s", setting.Domain, setting.Key))
+	// Step ID segments must start with a letter or digit; keys such as
+	// _FXSortFoldersFirst start with an underscore.
+	id := compiler.MustNewStepID(fmt.Sprintf("macos:defaults:%s:%s", setting.Domain, strings.TrimLeft(setting.Key, "_")))
 	return &DefaultsStep{
 		setting: setting,
 		id:      id,
@@ -26,6 +29,13 @@ func NewDefaultsStep(setting Default, runner ports.CommandRunner) *DefaultsStep
 	}
 }
 
+// WithRestart makes Apply restart app (such as Dock or Finder) so the new
+// value takes effect.
+func (s *DefaultsStep) WithRestart(app string) *DefaultsStep {
+	s.restart = app
+	return s
+}
+
 // ID returns the step identifier.
 func (s *DefaultsStep) ID() compiler.StepID {
 	return s.id
@@ -50,6 +60,13 @@ func (s *DefaultsStep) Check(ctx compiler.RunContext) (compiler.StepStatus, erro
 
 	currentValue := strings.TrimSpace(result.Stdout)
 	expectedValue := fmt.Sprintf("%v", s.setting.Value)
+	if v, ok := s.setting.Value.(bool); ok {
+		// defaults prints booleans as 1 or 0
+		expectedValue = "0"
+		if v {
+			expectedValue = "1"
+		}
+	}
 
 	if currentValue == expectedValue {
 		return compiler.StatusSatisfied, nil
@@ -101,6 +118,10 @@ func (s *DefaultsStep) Apply(ctx compiler.RunContext) error {
 	if !result.Success() {
 		return fmt.Errorf("defaults write failed: %s", result.Stderr)
 	}
+
+	if s.restart != "" {
+		_, _ = s.runner.Run(ctx.Context(), "killall", s.restart)
+	}
 	return nil
 }
 
@@ -0,0 +1,89 @@
+// Package mas provides the Mac App Store provider, which installs apps with
+// the mas command-line tool.
+package mas
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Config represents the mas section of the configuration.
+type Config struct {
+	Apps []App
+}
+
+// App is a Mac App Store app identified by its store ID.
+type App struct {
+	ID   int64
+	Name string
+}
+
+// Label returns the app name, or its ID when no name is declared.
+func (a App) Label() string {
+	if a.Name != "" {
+		return a.Name
+	}
+	return strconv.FormatInt(a.ID, 10)
+}
+
+// ParseConfig parses the mas configuration from a raw map. Apps are either
+// bare IDs or objects with an id and optional name.
+func ParseConfig(raw map[string]interface{}) (*Config, error) {
+	cfg := &Config{}
+	apps, ok := raw["apps"]
+	if !ok {
+		return cfg, nil
+	}
+	list, ok := apps.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("mas apps must be a list")
+	}
+	for _, item := range list {
+		app, err := parseApp(item)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Apps = append(cfg.Apps, app)
+	}
+	return cfg, nil
+}
+
+func parseApp(raw interface{}) (App, error) {
+	if m, ok := raw.(map[string]interface{}); ok {
+		id, err := parseID(m["id"])
+		if err != nil {
+			return App{}, err
+		}
+		name, _ := m["name"].(string)
+		return App{ID: id, Name: name}, nil
+	}
+	id, err := parseID(raw)
+	if err != nil {
+		return App{}, err
+	}
+	return App{ID: id}, nil
+}
+
+func parseID(raw interface{}) (int64, error) {
+	var id int64
+	switch v := raw.(type) {
+	case int:
+		id = int64(v)
+	case int64:
+		id = v
+	case float64:
+		id = int64(v)
+	case string:
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("mas app id %q is not a number", v)
+		}
+		id = parsed
+	default:
+		return 0, fmt.Errorf("mas app must be an id or an object with an id")
+	}
+	if id <= 0 {
+		return 0, fmt.Errorf("mas app id must be positive, got %d", id)
+	}
+	return id, nil
+}
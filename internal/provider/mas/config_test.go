@@ -0,0 +1,38 @@
+package mas
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfig(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseConfig(map[string]interface{}{
+		"apps": []interface{}{
+			map[string]interface{}{"id": int64(497799835), "name": "Xcode"},
+			904280696,
+			"1295203466",
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []App{{ID: 497799835, Name: "Xcode"}, {ID: 904280696}, {ID: 1295203466}}, cfg.Apps)
+	assert.Equal(t, "Xcode", cfg.Apps[0].Label())
+	assert.Equal(t, "904280696", cfg.Apps[1].Label())
+}
+
+func TestParseConfig_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for _, raw := range []map[string]interface{}{
+		{"apps": "Xcode"},
+		{"apps": []interface{}{"Xcode"}},
+		{"apps": []interface{}{map[string]interface{}{"name": "Xcode"}}},
+		{"apps": []interface{}{-1}},
+	} {
+		_, err := ParseConfig(raw)
+		assert.Error(t, err, raw)
+	}
+}
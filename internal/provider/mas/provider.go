@@ -0,0 +1,54 @@
+package mas
+
+import (
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+)
+
+// Provider implements the compiler.Provider interface for the Mac App Store.
+type Provider struct {
+	runner ports.CommandRunner
+}
+
+// NewProvider creates a new mas provider.
+func NewProvider(runner ports.CommandRunner) *Provider {
+	return &Provider{runner: runner}
+}
+
+// Name returns the provider name.
+func (p *Provider) Name() string {
+	return "mas"
+}
+
+// Compile transforms mas configuration into executable steps. When mas
+// itself is declared as a Homebrew formula, app installs wait for it.
+func (p *Provider) Compile(ctx compiler.CompileContext) ([]compiler.Step, error) {
+	rawConfig := ctx.GetSection("mas")
+	if rawConfig == nil {
+		return nil, nil
+	}
+
+	cfg, err := ParseConfig(rawConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var deps []compiler.StepID
+	if brew := ctx.GetSection("brew"); brew != nil {
+		formulae, _ := brew["formulae"].([]interface{})
+		for _, f := range formulae {
+			if f == "mas" {
+				deps = append(deps, compiler.MustNewStepID("brew:formula:mas"))
+			}
+		}
+	}
+
+	steps := make([]compiler.Step, 0, len(cfg.Apps))
+	for _, app := range cfg.Apps {
+		steps = append(steps, NewAppStep(app, p.runner, deps))
+	}
+	return steps, nil
+}
+
+// Ensure Provider implements compiler.Provider.
+var _ compiler.Provider = (*Provider)(nil)
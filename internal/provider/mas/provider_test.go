@@ -0,0 +1,31 @@
+package mas
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/testutil/mocks"
+)
+
+func TestProvider_Compile(t *testing.T) {
+	t.Parallel()
+
+	provider := NewProvider(mocks.NewCommandRunner())
+	assert.Equal(t, "mas", provider.Name())
+
+	steps, err := provider.Compile(compiler.NewCompileContext(map[string]interface{}{}))
+	require.NoError(t, err)
+	assert.Empty(t, steps)
+
+	steps, err = provider.Compile(compiler.NewCompileContext(map[string]interface{}{
+		"brew": map[string]interface{}{"formulae": []interface{}{"git", "mas"}},
+		"mas":  map[string]interface{}{"apps": []interface{}{497799835, 904280696}},
+	}))
+	require.NoError(t, err)
+	require.Len(t, steps, 2)
+	assert.Equal(t, "mas:app:497799835", steps[0].ID().String())
+	assert.Equal(t, []compiler.StepID{compiler.MustNewStepID("brew:formula:mas")}, steps[1].DependsOn())
+}
@@ -0,0 +1,147 @@
+package mas
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/provider/commandutil"
+)
+
+// ErrNotSignedIn is returned when an app cannot be installed because no
+// Apple ID is signed in to the App Store.
+var ErrNotSignedIn = errors.New("not signed in to the App Store")
+
+// AppStep installs a Mac App Store app.
+type AppStep struct {
+	app    App
+	id     compiler.StepID
+	runner ports.CommandRunner
+	deps   []compiler.StepID
+}
+
+// NewAppStep creates a new AppStep.
+func NewAppStep(app App, runner ports.CommandRunner, deps []compiler.StepID) *AppStep {
+	return &AppStep{
+		app:    app,
+		id:     compiler.MustNewStepID("mas:app:" + strconv.FormatInt(app.ID, 10)),
+		runner: runner,
+		deps:   deps,
+	}
+}
+
+// ID returns the step identifier.
+func (s *AppStep) ID() compiler.StepID {
+	return s.id
+}
+
+// DependsOn returns the step dependencies.
+func (s *AppStep) DependsOn() []compiler.StepID {
+	return s.deps
+}
+
+// Check determines if the app is already installed.
+func (s *AppStep) Check(ctx compiler.RunContext) (compiler.StepStatus, error) {
+	installed, err := InstalledApps(ctx, s.runner)
+	if err != nil {
+		if commandutil.IsCommandNotFound(err) {
+			if len(s.deps) == 0 {
+				return compiler.StatusUnknown, fmt.Errorf("mas not found in PATH; add it to packages.brew.formulae")
+			}
+			return compiler.StatusNeedsApply, nil
+		}
+		return compiler.StatusUnknown, err
+	}
+	if _, ok := installed[s.app.ID]; ok {
+		return compiler.StatusSatisfied, nil
+	}
+	return compiler.StatusNeedsApply, nil
+}
+
+// Plan returns the diff for this step.
+func (s *AppStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
+	return compiler.NewDiff(compiler.DiffTypeAdd, "mas-app", s.app.Label(), "", strconv.FormatInt(s.app.ID, 10)), nil
+}
+
+// Apply installs the app. Installing requires an Apple ID signed in to the
+// App Store and, for paid apps, a previous purchase.
+func (s *AppStep) Apply(ctx compiler.RunContext) error {
+	id := strconv.FormatInt(s.app.ID, 10)
+	result, err := s.runner.Run(ctx.Context(), "mas", "install", id)
+	if err != nil {
+		if commandutil.IsCommandNotFound(err) {
+			return fmt.Errorf("mas not found in PATH; add it to packages.brew.formulae")
+		}
+		return err
+	}
+	if !result.Success() {
+		output := result.Stderr + result.Stdout
+		if isNotSignedIn(output) {
+			return fmt.Errorf("cannot install %s: %w; sign in with the App Store app and re-run apply", s.app.Label(), ErrNotSignedIn)
+		}
+		return fmt.Errorf("mas install %s failed: %s", id, strings.TrimSpace(output))
+	}
+	return nil
+}
+
+// Explain provides a human-readable explanation.
+func (s *AppStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
+	return compiler.NewExplanation(
+		"Install App Store App",
+		fmt.Sprintf("Installs %s (%d) from the Mac App Store using mas.", s.app.Label(), s.app.ID),
+		[]string{
+			fmt.Sprintf("https://apps.apple.com/app/id%d", s.app.ID),
+			"https://github.com/mas-cli/mas",
+		},
+	).WithTradeoffs([]string{
+		"+ App Store apps are declared alongside Homebrew packages",
+		"+ Updates are delivered by the App Store",
+		"- Requires signing in to the App Store interactively",
+		"- Paid apps must already be purchased by the signed-in Apple ID",
+	})
+}
+
+// InstalledApps returns the installed App Store apps keyed by ID, with their
+// names, as reported by 'mas list'.
+func InstalledApps(ctx compiler.RunContext, runner ports.CommandRunner) (map[int64]string, error) {
+	result, err := runner.Run(ctx.Context(), "mas", "list")
+	if err != nil {
+		return nil, err
+	}
+	if !result.Success() {
+		return nil, fmt.Errorf("mas list failed: %s", strings.TrimSpace(result.Stderr))
+	}
+	return ParseList(result.Stdout), nil
+}
+
+// ParseList parses 'mas list' output, where each line is
+// "<id>  <name>  (<version>)".
+func ParseList(output string) map[int64]string {
+	apps := make(map[int64]string)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		id, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		name := fields[1:]
+		if last := name[len(name)-1]; len(name) > 1 && strings.HasPrefix(last, "(") {
+			name = name[:len(name)-1]
+		}
+		apps[id] = strings.Join(name, " ")
+	}
+	return apps
+}
+
+func isNotSignedIn(output string) bool {
+	lower := strings.ToLower(output)
+	return strings.Contains(lower, "not signed in") || strings.Contains(lower, "sign in")
+}
+
+var _ compiler.Step = (*AppStep)(nil)
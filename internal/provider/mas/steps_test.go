@@ -0,0 +1,82 @@
+package mas
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/testutil/mocks"
+)
+
+const masList = `497799835  Xcode           (15.4)
+904280696  Things 3        (3.20.1)
+  1295203466  Microsoft Remote Desktop (10.9.8)
+`
+
+type errRunner struct {
+	err error
+}
+
+func (r errRunner) Run(_ context.Context, _ string, _ ...string) (ports.CommandResult, error) {
+	return ports.CommandResult{}, r.err
+}
+
+func TestParseList(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, map[int64]string{
+		497799835:  "Xcode",
+		904280696:  "Things 3",
+		1295203466: "Microsoft Remote Desktop",
+	}, ParseList(masList+"No installed apps found\n"))
+}
+
+func TestAppStep_Check(t *testing.T) {
+	t.Parallel()
+
+	runner := mocks.NewCommandRunner()
+	runner.AddResult("mas", []string{"list"}, ports.CommandResult{Stdout: masList})
+	ctx := compiler.NewRunContext(context.Background())
+
+	status, err := NewAppStep(App{ID: 497799835, Name: "Xcode"}, runner, nil).Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, status)
+
+	status, err = NewAppStep(App{ID: 1333542190}, runner, nil).Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+}
+
+func TestAppStep_Check_MasMissing(t *testing.T) {
+	t.Parallel()
+
+	ctx := compiler.NewRunContext(context.Background())
+	runner := errRunner{err: exec.ErrNotFound}
+
+	_, err := NewAppStep(App{ID: 1}, runner, nil).Check(ctx)
+	require.Error(t, err)
+
+	status, err := NewAppStep(App{ID: 1}, runner, []compiler.StepID{compiler.MustNewStepID("brew:formula:mas")}).Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+}
+
+func TestAppStep_Apply(t *testing.T) {
+	t.Parallel()
+
+	runner := mocks.NewCommandRunner()
+	runner.AddResult("mas", []string{"install", "904280696"}, ports.CommandResult{})
+	runner.AddResult("mas", []string{"install", "497799835"}, ports.CommandResult{ExitCode: 1, Stderr: "Error: Not signed in"})
+	ctx := compiler.NewRunContext(context.Background())
+
+	require.NoError(t, NewAppStep(App{ID: 904280696}, runner, nil).Apply(ctx))
+
+	err := NewAppStep(App{ID: 497799835, Name: "Xcode"}, runner, nil).Apply(ctx)
+	require.ErrorIs(t, err, ErrNotSignedIn)
+	assert.Contains(t, err.Error(), "Xcode")
+}
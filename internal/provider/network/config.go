@@ -0,0 +1,140 @@
+// Package network provides the network provider. It asserts DNS servers,
+// proxy settings and preflight-managed /etc/hosts entries, applying them
+// where the platform allows and returning manual-fix instructions otherwise.
+package network
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// DefaultService is the macOS network service configured when none is set.
+const DefaultService = "Wi-Fi"
+
+// Config represents the network section of the configuration.
+type Config struct {
+	Service string
+	DNS     DNS
+	Proxy   Proxy
+	Hosts   []HostEntry
+	// HasHosts is true when the hosts list is declared, even if empty, so
+	// that apply removes the managed block.
+	HasHosts bool
+}
+
+// DNS declares the resolvers and search domains, in order.
+type DNS struct {
+	Servers       []string
+	SearchDomains []string
+}
+
+// IsZero reports whether no DNS settings are declared.
+func (d DNS) IsZero() bool {
+	return len(d.Servers) == 0 && len(d.SearchDomains) == 0
+}
+
+// Proxy declares the HTTP and HTTPS proxies as host:port and the hosts
+// that bypass them.
+type Proxy struct {
+	HTTP   string
+	HTTPS  string
+	Bypass []string
+}
+
+// IsZero reports whether no proxy settings are declared.
+func (p Proxy) IsZero() bool {
+	return p.HTTP == "" && p.HTTPS == "" && len(p.Bypass) == 0
+}
+
+// HostEntry maps an IP address to host names in /etc/hosts.
+type HostEntry struct {
+	IP    string
+	Names []string
+}
+
+// ParseConfig parses the network configuration from a raw map.
+func ParseConfig(raw map[string]interface{}) (*Config, error) {
+	cfg := &Config{Service: DefaultService}
+	if service, ok := raw["service"].(string); ok && service != "" {
+		cfg.Service = service
+	}
+
+	if dns, ok := raw["dns"].(map[string]interface{}); ok {
+		cfg.DNS.Servers = stringList(dns["servers"])
+		cfg.DNS.SearchDomains = stringList(dns["search_domains"])
+	}
+	for _, server := range cfg.DNS.Servers {
+		if net.ParseIP(server) == nil {
+			return nil, fmt.Errorf("dns server %q is not an IP address", server)
+		}
+	}
+
+	if proxy, ok := raw["proxy"].(map[string]interface{}); ok {
+		cfg.Proxy.HTTP, _ = proxy["http"].(string)
+		cfg.Proxy.HTTPS, _ = proxy["https"].(string)
+		cfg.Proxy.Bypass = stringList(proxy["bypass"])
+	}
+	for _, address := range []string{cfg.Proxy.HTTP, cfg.Proxy.HTTPS} {
+		if address == "" {
+			continue
+		}
+		if _, _, err := SplitProxy(address); err != nil {
+			return nil, err
+		}
+	}
+
+	if hosts, ok := raw["hosts"]; ok {
+		cfg.HasHosts = true
+		list, ok := hosts.([]interface{})
+		if hosts != nil && !ok {
+			return nil, fmt.Errorf("network hosts must be a list")
+		}
+		for _, item := range list {
+			m, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("network host entry must be an object")
+			}
+			entry := HostEntry{Names: stringList(m["names"])}
+			entry.IP, _ = m["ip"].(string)
+			if net.ParseIP(entry.IP) == nil {
+				return nil, fmt.Errorf("hosts entry %q is not an IP address", entry.IP)
+			}
+			if len(entry.Names) == 0 {
+				return nil, fmt.Errorf("hosts entry %s has no names", entry.IP)
+			}
+			for _, name := range entry.Names {
+				if name == "" || strings.ContainsAny(name, " \t#") {
+					return nil, fmt.Errorf("invalid host name %q for %s", name, entry.IP)
+				}
+			}
+			cfg.Hosts = append(cfg.Hosts, entry)
+		}
+	}
+	return cfg, nil
+}
+
+// SplitProxy splits a host:port proxy address.
+func SplitProxy(address string) (string, int, error) {
+	host, portText, err := net.SplitHostPort(address)
+	if err != nil || host == "" {
+		return "", 0, fmt.Errorf("proxy %q must be host:port", address)
+	}
+	port, err := strconv.Atoi(portText)
+	if err != nil || port < 1 || port > 65535 {
+		return "", 0, fmt.Errorf("proxy %q has an invalid port", address)
+	}
+	return host, port, nil
+}
+
+func stringList(v interface{}) []string {
+	list, _ := v.([]interface{})
+	values := make([]string, 0, len(list))
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			values = append(values, s)
+		}
+	}
+	return values
+}
@@ -0,0 +1,55 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfig(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseConfig(map[string]interface{}{
+		"dns": map[string]interface{}{
+			"servers":        []interface{}{"10.0.0.53", "2001:db8::53"},
+			"search_domains": []interface{}{"corp.example.com"},
+		},
+		"proxy": map[string]interface{}{
+			"http":   "proxy.corp.example.com:8080",
+			"bypass": []interface{}{"localhost", "*.corp.example.com"},
+		},
+		"hosts": []interface{}{
+			map[string]interface{}{"ip": "10.1.2.3", "names": []interface{}{"git.corp.example.com", "git"}},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultService, cfg.Service)
+	assert.Equal(t, []string{"10.0.0.53", "2001:db8::53"}, cfg.DNS.Servers)
+	assert.Equal(t, "proxy.corp.example.com:8080", cfg.Proxy.HTTP)
+	assert.True(t, cfg.HasHosts)
+	assert.Equal(t, []HostEntry{{IP: "10.1.2.3", Names: []string{"git.corp.example.com", "git"}}}, cfg.Hosts)
+
+	cfg, err = ParseConfig(map[string]interface{}{"service": "Ethernet", "hosts": []interface{}{}})
+	require.NoError(t, err)
+	assert.Equal(t, "Ethernet", cfg.Service)
+	assert.True(t, cfg.HasHosts)
+	assert.Empty(t, cfg.Hosts)
+}
+
+func TestParseConfig_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for name, raw := range map[string]map[string]interface{}{
+		"dns hostname":   {"dns": map[string]interface{}{"servers": []interface{}{"dns.example.com"}}},
+		"proxy no port":  {"proxy": map[string]interface{}{"http": "proxy.example.com"}},
+		"proxy bad port": {"proxy": map[string]interface{}{"https": "proxy.example.com:99999"}},
+		"hosts not list": {"hosts": "10.1.2.3 git"},
+		"hosts bad ip":   {"hosts": []interface{}{map[string]interface{}{"ip": "git", "names": []interface{}{"git"}}}},
+		"hosts no names": {"hosts": []interface{}{map[string]interface{}{"ip": "10.1.2.3"}}},
+		"hosts bad name": {"hosts": []interface{}{map[string]interface{}{"ip": "10.1.2.3", "names": []interface{}{"a b"}}}},
+	} {
+		_, err := ParseConfig(raw)
+		assert.Error(t, err, name)
+	}
+}
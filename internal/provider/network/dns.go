@@ -0,0 +1,224 @@
+package network
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+)
+
+// ErrManualFix is returned by Apply when a setting cannot be changed
+// automatically on the current platform. The error message carries the
+// instructions for changing it by hand.
+var ErrManualFix = errors.New("manual fix required")
+
+// resolvedResolvConf lists the upstream servers systemd-resolved uses;
+// /etc/resolv.conf only points at its local stub when it is running.
+const resolvedResolvConf = "/run/systemd/resolve/resolv.conf"
+
+// DNSStep asserts the DNS servers and search domains. On macOS they are set
+// on a network service with networksetup; on Linux they are read from
+// resolv.conf and changing them is left to the user.
+type DNSStep struct {
+	dns        DNS
+	service    string
+	goos       string
+	resolvConf string
+	runner     ports.CommandRunner
+}
+
+// NewDNSStep creates a DNS step for goos.
+func NewDNSStep(dns DNS, service, goos string, runner ports.CommandRunner) *DNSStep {
+	resolvConf := "/etc/resolv.conf"
+	if _, err := os.Stat(resolvedResolvConf); err == nil {
+		resolvConf = resolvedResolvConf
+	}
+	return &DNSStep{
+		dns:        dns,
+		service:    service,
+		goos:       goos,
+		resolvConf: resolvConf,
+		runner:     runner,
+	}
+}
+
+// ID returns the step identifier.
+func (s *DNSStep) ID() compiler.StepID {
+	return compiler.MustNewStepID("network:dns")
+}
+
+// DependsOn returns the step dependencies.
+func (s *DNSStep) DependsOn() []compiler.StepID {
+	return nil
+}
+
+// Check compares the configured resolvers with the declared ones.
+func (s *DNSStep) Check(ctx compiler.RunContext) (compiler.StepStatus, error) {
+	servers, domains, err := s.current(ctx)
+	if err != nil {
+		return compiler.StatusUnknown, err
+	}
+	if servers == nil && domains == nil {
+		return compiler.StatusUnknown, nil
+	}
+	if len(s.dns.Servers) > 0 && !slices.Equal(servers, s.dns.Servers) {
+		return compiler.StatusNeedsApply, nil
+	}
+	if len(s.dns.SearchDomains) > 0 && !slices.Equal(domains, s.dns.SearchDomains) {
+		return compiler.StatusNeedsApply, nil
+	}
+	return compiler.StatusSatisfied, nil
+}
+
+// Plan returns the diff for this step.
+func (s *DNSStep) Plan(ctx compiler.RunContext) (compiler.Diff, error) {
+	servers, _, _ := s.current(ctx)
+	return compiler.NewDiff(compiler.DiffTypeModify, "dns", s.service,
+		strings.Join(servers, ", "), strings.Join(s.dns.Servers, ", ")), nil
+}
+
+// Apply sets the DNS servers and search domains with networksetup. It
+// returns ErrManualFix on platforms where they are not changed automatically.
+func (s *DNSStep) Apply(ctx compiler.RunContext) error {
+	if s.goos != "darwin" {
+		return fmt.Errorf("%w: %s", ErrManualFix, s.ManualFix())
+	}
+	if len(s.dns.Servers) > 0 {
+		args := append([]string{"-setdnsservers", s.service}, s.dns.Servers...)
+		if err := runNetworksetup(ctx, s.runner, args...); err != nil {
+			return err
+		}
+	}
+	if len(s.dns.SearchDomains) > 0 {
+		args := append([]string{"-setsearchdomains", s.service}, s.dns.SearchDomains...)
+		if err := runNetworksetup(ctx, s.runner, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ManualFix returns instructions for setting the DNS servers by hand, or
+// an empty string on macOS where Apply sets them.
+func (s *DNSStep) ManualFix() string {
+	switch s.goos {
+	case "darwin":
+		return ""
+	case "linux":
+		if s.resolvConf == resolvedResolvConf {
+			var settings []string
+			if len(s.dns.Servers) > 0 {
+				settings = append(settings, "DNS="+strings.Join(s.dns.Servers, " "))
+			}
+			if len(s.dns.SearchDomains) > 0 {
+				settings = append(settings, "Domains="+strings.Join(s.dns.SearchDomains, " "))
+			}
+			return fmt.Sprintf("set %s under [Resolve] in /etc/systemd/resolved.conf, then run 'sudo systemctl restart systemd-resolved'",
+				strings.Join(settings, " and "))
+		}
+		lines := make([]string, 0, len(s.dns.Servers)+1)
+		for _, server := range s.dns.Servers {
+			lines = append(lines, "'nameserver "+server+"'")
+		}
+		if len(s.dns.SearchDomains) > 0 {
+			lines = append(lines, "'search "+strings.Join(s.dns.SearchDomains, " ")+"'")
+		}
+		return fmt.Sprintf("add %s to %s", strings.Join(lines, ", "), s.resolvConf)
+	default:
+		var settings []string
+		if len(s.dns.Servers) > 0 {
+			settings = append(settings, "DNS servers "+strings.Join(s.dns.Servers, ", "))
+		}
+		if len(s.dns.SearchDomains) > 0 {
+			settings = append(settings, "search domains "+strings.Join(s.dns.SearchDomains, ", "))
+		}
+		return fmt.Sprintf("set the %s of %s in the network settings", strings.Join(settings, " and "), s.service)
+	}
+}
+
+// PolicyValues returns network:dns:<server> and
+// network:search_domain:<domain> for org policies.
+func (s *DNSStep) PolicyValues() []string {
+	values := make([]string, 0, len(s.dns.Servers)+len(s.dns.SearchDomains))
+	for _, server := range s.dns.Servers {
+		values = append(values, "network:dns:"+server)
+	}
+	for _, domain := range s.dns.SearchDomains {
+		values = append(values, "network:search_domain:"+domain)
+	}
+	return values
+}
+
+// Explain provides a human-readable explanation.
+func (s *DNSStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
+	return compiler.NewExplanation(
+		"Set DNS Servers",
+		fmt.Sprintf("Resolves names through %s", strings.Join(s.dns.Servers, ", ")),
+		[]string{
+			"https://ss64.com/mac/networksetup.html",
+			"https://man7.org/linux/man-pages/man5/resolv.conf.5.html",
+		},
+	).WithTradeoffs([]string{
+		"+ Corporate names resolve without per-network setup",
+		"- Needs administrator rights on macOS",
+		"- Not applied automatically on Linux, where DHCP or NetworkManager own resolv.conf",
+	})
+}
+
+// current returns the configured DNS servers and search domains, or nil
+// slices when they cannot be read on this platform.
+func (s *DNSStep) current(ctx compiler.RunContext) ([]string, []string, error) {
+	switch s.goos {
+	case "darwin":
+		servers, err := networksetupList(ctx, s.runner, "-getdnsservers", s.service)
+		if err != nil {
+			return nil, nil, err
+		}
+		domains, err := networksetupList(ctx, s.runner, "-getsearchdomains", s.service)
+		if err != nil {
+			return nil, nil, err
+		}
+		return servers, domains, nil
+	case "linux":
+		// #nosec G304 -- path is the system resolv.conf.
+		data, err := os.ReadFile(s.resolvConf)
+		if err != nil {
+			return nil, nil, err
+		}
+		servers, domains := ParseResolvConf(string(data))
+		return servers, domains, nil
+	default:
+		return nil, nil, nil
+	}
+}
+
+// ParseResolvConf returns the nameservers and search domains of a
+// resolv.conf file.
+func ParseResolvConf(content string) ([]string, []string) {
+	servers, domains := []string{}, []string{}
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "nameserver":
+			servers = append(servers, fields[1])
+		case "search", "domain":
+			domains = append(domains, fields[1:]...)
+		}
+	}
+	return servers, domains
+}
+
+var (
+	_ compiler.Step             = (*DNSStep)(nil)
+	_ compiler.ManualStep       = (*DNSStep)(nil)
+	_ compiler.PolicyValuesStep = (*DNSStep)(nil)
+)
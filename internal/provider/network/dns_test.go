@@ -0,0 +1,82 @@
+package network
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/testutil/mocks"
+)
+
+var corporateDNS = DNS{Servers: []string{"10.0.0.53", "10.0.0.54"}, SearchDomains: []string{"corp.example.com"}}
+
+func TestDNSStep_Darwin(t *testing.T) {
+	t.Parallel()
+
+	ctx := compiler.NewRunContext(context.Background())
+	runner := mocks.NewCommandRunner()
+	runner.AddResult("networksetup", []string{"-getdnsservers", "Wi-Fi"}, ports.CommandResult{Stdout: "There aren't any DNS Servers set on Wi-Fi.\n"})
+	runner.AddResult("networksetup", []string{"-getsearchdomains", "Wi-Fi"}, ports.CommandResult{Stdout: "corp.example.com\n"})
+	runner.AddResult("sudo", []string{"networksetup", "-setdnsservers", "Wi-Fi", "10.0.0.53", "10.0.0.54"}, ports.CommandResult{})
+	runner.AddResult("sudo", []string{"networksetup", "-setsearchdomains", "Wi-Fi", "corp.example.com"}, ports.CommandResult{})
+
+	step := NewDNSStep(corporateDNS, "Wi-Fi", "darwin", runner)
+	status, err := step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+	assert.Empty(t, step.ManualFix())
+
+	require.NoError(t, step.Apply(ctx))
+	calls := runner.Calls()
+	assert.Equal(t, []string{"networksetup", "-setdnsservers", "Wi-Fi", "10.0.0.53", "10.0.0.54"}, calls[len(calls)-2].Args)
+
+	satisfied := mocks.NewCommandRunner()
+	satisfied.AddResult("networksetup", []string{"-getdnsservers", "Wi-Fi"}, ports.CommandResult{Stdout: "10.0.0.53\n10.0.0.54\n"})
+	satisfied.AddResult("networksetup", []string{"-getsearchdomains", "Wi-Fi"}, ports.CommandResult{Stdout: "corp.example.com\n"})
+	status, err = NewDNSStep(corporateDNS, "Wi-Fi", "darwin", satisfied).Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, status)
+}
+
+func TestDNSStep_LinuxManualFix(t *testing.T) {
+	t.Parallel()
+
+	resolvConf := filepath.Join(t.TempDir(), "resolv.conf")
+	require.NoError(t, os.WriteFile(resolvConf, []byte("# generated\nnameserver 192.168.1.1\nsearch home\n"), 0o644))
+
+	ctx := compiler.NewRunContext(context.Background())
+	step := NewDNSStep(corporateDNS, "Wi-Fi", "linux", mocks.NewCommandRunner())
+	step.resolvConf = resolvConf
+
+	status, err := step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+
+	err = step.Apply(ctx)
+	require.ErrorIs(t, err, ErrManualFix)
+	assert.Contains(t, err.Error(), "'nameserver 10.0.0.53', 'nameserver 10.0.0.54', 'search corp.example.com'")
+
+	step.resolvConf = resolvedResolvConf
+	assert.Equal(t, "set DNS=10.0.0.53 10.0.0.54 and Domains=corp.example.com under [Resolve] in /etc/systemd/resolved.conf, then run 'sudo systemctl restart systemd-resolved'", step.ManualFix())
+}
+
+func TestDNSStep_PolicyValues(t *testing.T) {
+	t.Parallel()
+
+	step := NewDNSStep(corporateDNS, "Wi-Fi", "darwin", mocks.NewCommandRunner())
+	assert.Equal(t, []string{"network:dns:10.0.0.53", "network:dns:10.0.0.54", "network:search_domain:corp.example.com"}, step.PolicyValues())
+}
+
+func TestParseResolvConf(t *testing.T) {
+	t.Parallel()
+
+	servers, domains := ParseResolvConf("nameserver 1.1.1.1\n# nameserver 9.9.9.9\nnameserver 8.8.8.8\nsearch a.example b.example\noptions edns0\n")
+	assert.Equal(t, []string{"1.1.1.1", "8.8.8.8"}, servers)
+	assert.Equal(t, []string{"a.example", "b.example"}, domains)
+}
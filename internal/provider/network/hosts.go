@@ -0,0 +1,207 @@
+package network
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+)
+
+// Markers delimiting the block of /etc/hosts that preflight owns.
+const (
+	hostsBegin = "# BEGIN preflight managed hosts"
+	hostsEnd   = "# END preflight managed hosts"
+)
+
+// HostsStep reconciles the preflight-managed block of the hosts file.
+// Lines outside the block are never touched, and an empty entry list
+// removes the block.
+type HostsStep struct {
+	entries []HostEntry
+	path    string
+	goos    string
+	runner  ports.CommandRunner
+}
+
+// NewHostsStep creates a hosts step for goos.
+func NewHostsStep(entries []HostEntry, goos string, runner ports.CommandRunner) *HostsStep {
+	return &HostsStep{
+		entries: entries,
+		path:    HostsPath(goos),
+		goos:    goos,
+		runner:  runner,
+	}
+}
+
+// HostsPath returns the hosts file of goos.
+func HostsPath(goos string) string {
+	if goos == "windows" {
+		return `C:\Windows\System32\drivers\etc\hosts`
+	}
+	return "/etc/hosts"
+}
+
+// ID returns the step identifier.
+func (s *HostsStep) ID() compiler.StepID {
+	return compiler.MustNewStepID("network:hosts")
+}
+
+// DependsOn returns the step dependencies.
+func (s *HostsStep) DependsOn() []compiler.StepID {
+	return nil
+}
+
+// Check compares the hosts file with the reconciled hosts file.
+func (s *HostsStep) Check(_ compiler.RunContext) (compiler.StepStatus, error) {
+	current, err := s.read()
+	if err != nil {
+		return compiler.StatusUnknown, err
+	}
+	if ReconcileHosts(current, s.entries) == current {
+		return compiler.StatusSatisfied, nil
+	}
+	return compiler.StatusNeedsApply, nil
+}
+
+// Plan returns the diff for this step.
+func (s *HostsStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
+	names := make([]string, 0, len(s.entries))
+	for _, entry := range s.entries {
+		names = append(names, entry.Names...)
+	}
+	return compiler.NewDiff(compiler.DiffTypeModify, "hosts", s.path, "", strings.Join(names, ", ")), nil
+}
+
+// Apply installs the reconciled hosts file with sudo. It returns
+// ErrManualFix on Windows.
+func (s *HostsStep) Apply(ctx compiler.RunContext) error {
+	if s.goos == "windows" {
+		return fmt.Errorf("%w: %s", ErrManualFix, s.ManualFix())
+	}
+	current, err := s.read()
+	if err != nil {
+		return err
+	}
+	file, err := os.CreateTemp("", "preflight-hosts-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(file.Name()) }()
+	if _, err := file.WriteString(ReconcileHosts(current, s.entries)); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	// cp keeps the owner and mode of the existing hosts file.
+	result, err := s.runner.Run(ctx.Context(), "sudo", "cp", file.Name(), s.path)
+	if err != nil {
+		return err
+	}
+	if !result.Success() {
+		return fmt.Errorf("failed to update %s: %s", s.path, strings.TrimSpace(result.Stderr))
+	}
+	return nil
+}
+
+// ManualFix returns instructions for editing the hosts file by hand on
+// Windows, or an empty string where Apply edits it.
+func (s *HostsStep) ManualFix() string {
+	if s.goos != "windows" {
+		return ""
+	}
+	return fmt.Sprintf("replace the preflight block of %s, as administrator, with: %s",
+		s.path, strings.Join(hostsBlock(s.entries), " / "))
+}
+
+// PolicyValues returns network:hosts:<name> for every managed host name.
+func (s *HostsStep) PolicyValues() []string {
+	var values []string
+	for _, entry := range s.entries {
+		for _, name := range entry.Names {
+			values = append(values, "network:hosts:"+name)
+		}
+	}
+	return values
+}
+
+// Explain provides a human-readable explanation.
+func (s *HostsStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
+	return compiler.NewExplanation(
+		"Manage Hosts Entries",
+		fmt.Sprintf("Keeps %d entries in a preflight-owned block of %s", len(s.entries), s.path),
+		[]string{
+			"https://man7.org/linux/man-pages/man5/hosts.5.html",
+		},
+	).WithTradeoffs([]string{
+		"+ Entries outside the managed block are preserved",
+		"+ Entries removed from the configuration are removed from the file",
+		"- Needs sudo to write the hosts file",
+	})
+}
+
+func (s *HostsStep) read() (string, error) {
+	// #nosec G304 -- path is the system hosts file.
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return string(data), nil
+}
+
+// ReconcileHosts returns content with its preflight-managed block replaced
+// by entries. The block keeps its position, is appended when missing and
+// is removed when there are no entries.
+func ReconcileHosts(content string, entries []HostEntry) string {
+	var lines []string
+	source := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	if content == "" {
+		source = nil
+	}
+	written := false
+	for i := 0; i < len(source); i++ {
+		if source[i] != hostsBegin {
+			lines = append(lines, source[i])
+			continue
+		}
+		for i < len(source) && source[i] != hostsEnd {
+			i++
+		}
+		if !written {
+			lines = append(lines, hostsBlock(entries)...)
+			written = true
+		}
+	}
+	if !written {
+		lines = append(lines, hostsBlock(entries)...)
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// hostsBlock returns the managed block for entries, or nil without entries.
+func hostsBlock(entries []HostEntry) []string {
+	if len(entries) == 0 {
+		return nil
+	}
+	lines := []string{hostsBegin}
+	for _, entry := range entries {
+		lines = append(lines, entry.IP+"\t"+strings.Join(entry.Names, " "))
+	}
+	return append(lines, hostsEnd)
+}
+
+var (
+	_ compiler.Step             = (*HostsStep)(nil)
+	_ compiler.ManualStep       = (*HostsStep)(nil)
+	_ compiler.PolicyValuesStep = (*HostsStep)(nil)
+)
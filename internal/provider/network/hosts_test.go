@@ -0,0 +1,81 @@
+package network
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/testutil/mocks"
+)
+
+const systemHosts = `127.0.0.1	localhost
+# BEGIN preflight managed hosts
+10.9.9.9	old.corp.example.com
+# END preflight managed hosts
+::1	localhost
+`
+
+var corporateHosts = []HostEntry{{IP: "10.1.2.3", Names: []string{"git.corp.example.com", "git"}}}
+
+func TestReconcileHosts(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, `127.0.0.1	localhost
+# BEGIN preflight managed hosts
+10.1.2.3	git.corp.example.com git
+# END preflight managed hosts
+::1	localhost
+`, ReconcileHosts(systemHosts, corporateHosts))
+
+	assert.Equal(t, "127.0.0.1\tlocalhost\n::1\tlocalhost\n", ReconcileHosts(systemHosts, nil))
+	assert.Equal(t, "127.0.0.1\tlocalhost\n", ReconcileHosts("127.0.0.1\tlocalhost\n", nil))
+	assert.Equal(t, "127.0.0.1\tlocalhost\n# BEGIN preflight managed hosts\n10.1.2.3\tgit.corp.example.com git\n# END preflight managed hosts\n",
+		ReconcileHosts("127.0.0.1\tlocalhost\n", corporateHosts))
+}
+
+// copyRunner performs 'sudo cp src dst' without sudo.
+type copyRunner struct{}
+
+func (copyRunner) Run(_ context.Context, _ string, args ...string) (ports.CommandResult, error) {
+	data, err := os.ReadFile(args[1])
+	if err != nil {
+		return ports.CommandResult{}, err
+	}
+	return ports.CommandResult{}, os.WriteFile(args[2], data, 0o644)
+}
+
+func TestHostsStep_CheckAndApply(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "hosts")
+	require.NoError(t, os.WriteFile(path, []byte(systemHosts), 0o644))
+	ctx := compiler.NewRunContext(context.Background())
+
+	step := NewHostsStep(corporateHosts, "linux", copyRunner{})
+	step.path = path
+	status, err := step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+
+	require.NoError(t, step.Apply(ctx))
+	status, err = step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, status)
+	assert.Equal(t, []string{"network:hosts:git.corp.example.com", "network:hosts:git"}, step.PolicyValues())
+}
+
+func TestHostsStep_WindowsManualFix(t *testing.T) {
+	t.Parallel()
+
+	step := NewHostsStep(corporateHosts, "windows", mocks.NewCommandRunner())
+	err := step.Apply(compiler.NewRunContext(context.Background()))
+	require.ErrorIs(t, err, ErrManualFix)
+	assert.Contains(t, err.Error(), `C:\Windows\System32\drivers\etc\hosts`)
+	assert.Contains(t, err.Error(), "10.1.2.3\tgit.corp.example.com git")
+}
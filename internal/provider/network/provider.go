@@ -0,0 +1,107 @@
+package network
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/provider/commandutil"
+)
+
+// Provider implements the compiler.Provider interface for network settings.
+type Provider struct {
+	runner ports.CommandRunner
+	goos   string
+}
+
+// NewProvider creates a new network provider.
+func NewProvider(runner ports.CommandRunner) *Provider {
+	return &Provider{runner: runner, goos: runtime.GOOS}
+}
+
+// Name returns the provider name.
+func (p *Provider) Name() string {
+	return "network"
+}
+
+// Compile transforms network configuration into executable steps.
+func (p *Provider) Compile(ctx compiler.CompileContext) ([]compiler.Step, error) {
+	rawConfig := ctx.GetSection("network")
+	if rawConfig == nil {
+		return nil, nil
+	}
+
+	cfg, err := ParseConfig(rawConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var steps []compiler.Step
+	if !cfg.DNS.IsZero() {
+		steps = append(steps, NewDNSStep(cfg.DNS, cfg.Service, p.goos, p.runner))
+	}
+	if !cfg.Proxy.IsZero() {
+		steps = append(steps, NewProxyStep(cfg.Proxy, cfg.Service, p.goos, p.runner))
+	}
+	if cfg.HasHosts {
+		steps = append(steps, NewHostsStep(cfg.Hosts, p.goos, p.runner))
+	}
+	return steps, nil
+}
+
+// runNetworksetup changes a setting with networksetup, which needs
+// administrator rights.
+func runNetworksetup(ctx compiler.RunContext, runner ports.CommandRunner, args ...string) error {
+	result, err := runner.Run(ctx.Context(), "sudo", append([]string{"networksetup"}, args...)...)
+	if err != nil {
+		return err
+	}
+	if !result.Success() || strings.Contains(result.Stdout, "** Error") {
+		return fmt.Errorf("networksetup %s failed: %s", args[0], strings.TrimSpace(result.Stderr+result.Stdout))
+	}
+	return nil
+}
+
+// runNetworksetupOutput runs a networksetup query and returns its output.
+func runNetworksetupOutput(ctx compiler.RunContext, runner ports.CommandRunner, args ...string) (string, error) {
+	result, err := runner.Run(ctx.Context(), "networksetup", args...)
+	if err != nil {
+		if commandutil.IsCommandNotFound(err) {
+			return "", fmt.Errorf("networksetup not found in PATH")
+		}
+		return "", err
+	}
+	if !result.Success() || strings.Contains(result.Stdout, "** Error") {
+		return "", fmt.Errorf("networksetup %s %s failed: %s", args[0], args[1], strings.TrimSpace(result.Stderr+result.Stdout))
+	}
+	return result.Stdout, nil
+}
+
+// networksetupList runs a networksetup query that prints one value per
+// line, such as -getdnsservers.
+func networksetupList(ctx compiler.RunContext, runner ports.CommandRunner, args ...string) ([]string, error) {
+	out, err := runNetworksetupOutput(ctx, runner, args...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseNetworksetupList(out), nil
+}
+
+// ParseNetworksetupList returns the values networksetup prints one per
+// line, or an empty list for its "There aren't any ..." message.
+func ParseNetworksetupList(output string) []string {
+	values := []string{}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "There aren't any") {
+			continue
+		}
+		values = append(values, line)
+	}
+	return values
+}
+
+// Ensure Provider implements compiler.Provider.
+var _ compiler.Provider = (*Provider)(nil)
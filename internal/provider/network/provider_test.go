@@ -0,0 +1,48 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/testutil/mocks"
+)
+
+func TestProvider_Compile(t *testing.T) {
+	t.Parallel()
+
+	provider := NewProvider(mocks.NewCommandRunner())
+	assert.Equal(t, "network", provider.Name())
+
+	steps, err := provider.Compile(compiler.NewCompileContext(map[string]interface{}{}))
+	require.NoError(t, err)
+	assert.Empty(t, steps)
+
+	steps, err = provider.Compile(compiler.NewCompileContext(map[string]interface{}{
+		"network": map[string]interface{}{
+			"dns":   map[string]interface{}{"servers": []interface{}{"10.0.0.53"}},
+			"proxy": map[string]interface{}{"http": "proxy:3128"},
+			"hosts": []interface{}{},
+		},
+	}))
+	require.NoError(t, err)
+	ids := make([]string, 0, len(steps))
+	for _, step := range steps {
+		ids = append(ids, step.ID().String())
+	}
+	assert.Equal(t, []string{"network:dns", "network:proxy", "network:hosts"}, ids)
+
+	_, err = provider.Compile(compiler.NewCompileContext(map[string]interface{}{
+		"network": map[string]interface{}{"dns": map[string]interface{}{"servers": []interface{}{"nope"}}},
+	}))
+	require.Error(t, err)
+}
+
+func TestParseNetworksetupList(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"10.0.0.53", "10.0.0.54"}, ParseNetworksetupList("10.0.0.53\n10.0.0.54\n"))
+	assert.Empty(t, ParseNetworksetupList("There aren't any DNS Servers set on Wi-Fi.\n"))
+}
@@ -0,0 +1,240 @@
+package network
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+)
+
+// ProxyStep asserts the HTTP and HTTPS proxies and bypass list. On macOS
+// they are set on a network service with networksetup; elsewhere they are
+// read from the proxy environment variables, which the user sets in their
+// shell.
+type ProxyStep struct {
+	proxy   Proxy
+	service string
+	goos    string
+	getenv  func(string) string
+	runner  ports.CommandRunner
+}
+
+// NewProxyStep creates a proxy step for goos.
+func NewProxyStep(proxy Proxy, service, goos string, runner ports.CommandRunner) *ProxyStep {
+	return &ProxyStep{
+		proxy:   proxy,
+		service: service,
+		goos:    goos,
+		getenv:  os.Getenv,
+		runner:  runner,
+	}
+}
+
+// ID returns the step identifier.
+func (s *ProxyStep) ID() compiler.StepID {
+	return compiler.MustNewStepID("network:proxy")
+}
+
+// DependsOn returns the step dependencies.
+func (s *ProxyStep) DependsOn() []compiler.StepID {
+	return nil
+}
+
+// Check compares the active proxy settings with the declared ones.
+func (s *ProxyStep) Check(ctx compiler.RunContext) (compiler.StepStatus, error) {
+	current, err := s.current(ctx)
+	if err != nil {
+		return compiler.StatusUnknown, err
+	}
+	if s.proxy.HTTP != "" && current.HTTP != s.proxy.HTTP {
+		return compiler.StatusNeedsApply, nil
+	}
+	if s.proxy.HTTPS != "" && current.HTTPS != s.proxy.HTTPS {
+		return compiler.StatusNeedsApply, nil
+	}
+	if len(s.proxy.Bypass) > 0 && !slices.Equal(current.Bypass, s.proxy.Bypass) {
+		return compiler.StatusNeedsApply, nil
+	}
+	return compiler.StatusSatisfied, nil
+}
+
+// Plan returns the diff for this step.
+func (s *ProxyStep) Plan(ctx compiler.RunContext) (compiler.Diff, error) {
+	current, _ := s.current(ctx)
+	return compiler.NewDiff(compiler.DiffTypeModify, "proxy", s.service,
+		describeProxy(current), describeProxy(s.proxy)), nil
+}
+
+// Apply sets the proxies with networksetup. It returns ErrManualFix on
+// platforms where they are not changed automatically.
+func (s *ProxyStep) Apply(ctx compiler.RunContext) error {
+	if s.goos != "darwin" {
+		return fmt.Errorf("%w: %s", ErrManualFix, s.ManualFix())
+	}
+	for _, proxy := range []struct{ flag, address string }{
+		{"-setwebproxy", s.proxy.HTTP},
+		{"-setsecurewebproxy", s.proxy.HTTPS},
+	} {
+		if proxy.address == "" {
+			continue
+		}
+		host, port, err := SplitProxy(proxy.address)
+		if err != nil {
+			return err
+		}
+		if err := runNetworksetup(ctx, s.runner, proxy.flag, s.service, host, strconv.Itoa(port)); err != nil {
+			return err
+		}
+	}
+	if len(s.proxy.Bypass) > 0 {
+		args := append([]string{"-setproxybypassdomains", s.service}, s.proxy.Bypass...)
+		if err := runNetworksetup(ctx, s.runner, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ManualFix returns instructions for setting the proxy by hand, or an
+// empty string on macOS where Apply sets it.
+func (s *ProxyStep) ManualFix() string {
+	if s.goos == "darwin" {
+		return ""
+	}
+	var exports []string
+	if s.proxy.HTTP != "" {
+		exports = append(exports, "http_proxy=http://"+s.proxy.HTTP)
+	}
+	if s.proxy.HTTPS != "" {
+		exports = append(exports, "https_proxy=http://"+s.proxy.HTTPS)
+	}
+	if len(s.proxy.Bypass) > 0 {
+		exports = append(exports, "no_proxy="+strings.Join(s.proxy.Bypass, ","))
+	}
+	return fmt.Sprintf("set %s under shell.env or export them in your shell profile", strings.Join(exports, " "))
+}
+
+// PolicyValues returns network:proxy:http:<host:port> and
+// network:proxy:https:<host:port> for org policies.
+func (s *ProxyStep) PolicyValues() []string {
+	var values []string
+	if s.proxy.HTTP != "" {
+		values = append(values, "network:proxy:http:"+s.proxy.HTTP)
+	}
+	if s.proxy.HTTPS != "" {
+		values = append(values, "network:proxy:https:"+s.proxy.HTTPS)
+	}
+	return values
+}
+
+// Explain provides a human-readable explanation.
+func (s *ProxyStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
+	return compiler.NewExplanation(
+		"Set Proxy",
+		fmt.Sprintf("Routes web traffic through %s", describeProxy(s.proxy)),
+		[]string{
+			"https://ss64.com/mac/networksetup.html",
+		},
+	).WithTradeoffs([]string{
+		"+ Tools that honour the system proxy work on the corporate network",
+		"- Needs administrator rights on macOS",
+		"- On Linux only the proxy environment variables are checked",
+	})
+}
+
+// current returns the active proxy settings.
+func (s *ProxyStep) current(ctx compiler.RunContext) (Proxy, error) {
+	if s.goos != "darwin" {
+		return Proxy{
+			HTTP:   envProxy(s.getenv, "http_proxy"),
+			HTTPS:  envProxy(s.getenv, "https_proxy"),
+			Bypass: splitList(envValue(s.getenv, "no_proxy")),
+		}, nil
+	}
+	var current Proxy
+	for _, proxy := range []struct {
+		flag    string
+		address *string
+	}{
+		{"-getwebproxy", &current.HTTP},
+		{"-getsecurewebproxy", &current.HTTPS},
+	} {
+		out, err := runNetworksetupOutput(ctx, s.runner, proxy.flag, s.service)
+		if err != nil {
+			return Proxy{}, err
+		}
+		*proxy.address = ParseWebProxy(out)
+	}
+	bypass, err := networksetupList(ctx, s.runner, "-getproxybypassdomains", s.service)
+	if err != nil {
+		return Proxy{}, err
+	}
+	current.Bypass = bypass
+	return current, nil
+}
+
+// ParseWebProxy returns the host:port of an enabled proxy in the output of
+// networksetup -getwebproxy, or an empty string when it is disabled.
+func ParseWebProxy(output string) string {
+	fields := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		if key, value, ok := strings.Cut(line, ":"); ok {
+			fields[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	if fields["Enabled"] != "Yes" || fields["Server"] == "" {
+		return ""
+	}
+	return fields["Server"] + ":" + fields["Port"]
+}
+
+// envProxy returns the host:port of a proxy environment variable.
+func envProxy(getenv func(string) string, name string) string {
+	value := envValue(getenv, name)
+	if i := strings.Index(value, "://"); i >= 0 {
+		value = value[i+3:]
+	}
+	return strings.TrimSuffix(value, "/")
+}
+
+// envValue reads name, falling back to its upper-case form.
+func envValue(getenv func(string) string, name string) string {
+	if value := getenv(name); value != "" {
+		return value
+	}
+	return getenv(strings.ToUpper(name))
+}
+
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func describeProxy(p Proxy) string {
+	var parts []string
+	if p.HTTP != "" {
+		parts = append(parts, "http "+p.HTTP)
+	}
+	if p.HTTPS != "" {
+		parts = append(parts, "https "+p.HTTPS)
+	}
+	if len(parts) == 0 {
+		return "no proxy"
+	}
+	return strings.Join(parts, ", ")
+}
+
+var (
+	_ compiler.Step             = (*ProxyStep)(nil)
+	_ compiler.ManualStep       = (*ProxyStep)(nil)
+	_ compiler.PolicyValuesStep = (*ProxyStep)(nil)
+)
@@ -0,0 +1,82 @@
+package network
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/testutil/mocks"
+)
+
+var corporateProxy = Proxy{HTTP: "proxy.corp.example.com:8080", HTTPS: "proxy.corp.example.com:8080", Bypass: []string{"localhost", "*.corp.example.com"}}
+
+func TestProxyStep_Darwin(t *testing.T) {
+	t.Parallel()
+
+	ctx := compiler.NewRunContext(context.Background())
+	runner := mocks.NewCommandRunner()
+	runner.AddResult("networksetup", []string{"-getwebproxy", "Wi-Fi"}, ports.CommandResult{Stdout: "Enabled: Yes\nServer: proxy.corp.example.com\nPort: 8080\nAuthenticated Proxy Enabled: 0\n"})
+	runner.AddResult("networksetup", []string{"-getsecurewebproxy", "Wi-Fi"}, ports.CommandResult{Stdout: "Enabled: No\nServer: \nPort: 0\n"})
+	runner.AddResult("networksetup", []string{"-getproxybypassdomains", "Wi-Fi"}, ports.CommandResult{Stdout: "localhost\n*.corp.example.com\n"})
+	runner.AddResult("sudo", []string{"networksetup", "-setwebproxy", "Wi-Fi", "proxy.corp.example.com", "8080"}, ports.CommandResult{})
+	runner.AddResult("sudo", []string{"networksetup", "-setsecurewebproxy", "Wi-Fi", "proxy.corp.example.com", "8080"}, ports.CommandResult{})
+	runner.AddResult("sudo", []string{"networksetup", "-setproxybypassdomains", "Wi-Fi", "localhost", "*.corp.example.com"}, ports.CommandResult{})
+
+	step := NewProxyStep(corporateProxy, "Wi-Fi", "darwin", runner)
+	status, err := step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+
+	plain := NewProxyStep(Proxy{HTTP: corporateProxy.HTTP}, "Wi-Fi", "darwin", runner)
+	status, err = plain.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, status)
+
+	require.NoError(t, step.Apply(ctx))
+	assert.Empty(t, step.ManualFix())
+}
+
+func TestProxyStep_LinuxEnvironment(t *testing.T) {
+	t.Parallel()
+
+	env := map[string]string{
+		"HTTP_PROXY":  "http://proxy.corp.example.com:8080/",
+		"https_proxy": "http://proxy.corp.example.com:8080",
+		"no_proxy":    "localhost, *.corp.example.com",
+	}
+	ctx := compiler.NewRunContext(context.Background())
+	step := NewProxyStep(corporateProxy, "Wi-Fi", "linux", mocks.NewCommandRunner())
+	step.getenv = func(name string) string { return env[name] }
+
+	status, err := step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, status)
+
+	delete(env, "https_proxy")
+	status, err = step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+
+	err = step.Apply(ctx)
+	require.ErrorIs(t, err, ErrManualFix)
+	assert.Contains(t, err.Error(), "https_proxy=http://proxy.corp.example.com:8080")
+	assert.Contains(t, err.Error(), "no_proxy=localhost,*.corp.example.com")
+}
+
+func TestProxyStep_PolicyValues(t *testing.T) {
+	t.Parallel()
+
+	step := NewProxyStep(Proxy{HTTPS: "proxy:3128"}, "Wi-Fi", "darwin", mocks.NewCommandRunner())
+	assert.Equal(t, []string{"network:proxy:https:proxy:3128"}, step.PolicyValues())
+}
+
+func TestParseWebProxy(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "proxy:3128", ParseWebProxy("Enabled: Yes\nServer: proxy\nPort: 3128\n"))
+	assert.Empty(t, ParseWebProxy("Enabled: No\nServer: proxy\nPort: 3128\n"))
+}
@@ -139,6 +139,11 @@ func (s *PackageStep) LockInfo() (compiler.LockInfo, bool) {
 	}, true
 }
 
+// UninstallCommand returns the command that uninstalls the global package.
+func (s *PackageStep) UninstallCommand() []string {
+	return []string{"npm", "uninstall", "-g", s.pkg.Name}
+}
+
 // InstalledVersion returns the installed npm package version if available.
 func (s *PackageStep) InstalledVersion(ctx compiler.RunContext) (string, bool, error) {
 	result, err := s.runner.Run(ctx.Context(), "npm", "list", "-g", "--depth=0", "--json")
@@ -13,6 +13,7 @@ type Config struct {
 	EnsureInstall bool     `yaml:"ensure_install,omitempty"`
 	ConfigSource  string   `yaml:"config_source,omitempty"` // Local dotfiles path
 	ExtraPlugins  []string `yaml:"extra_plugins,omitempty"` // Additional plugins for layer-specific customization
+	Packs         []string `yaml:"packs,omitempty"`         // Language packs stacked on the preset
 }
 
 // ConfigPath returns the path to the Neovim configuration directory.
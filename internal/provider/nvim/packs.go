@@ -0,0 +1,210 @@
+package nvim
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// LanguagePack adds the plugins, Mason tools and Treesitter parsers for a
+// language on top of a base preset. Packs are stacked with nvim.packs.
+type LanguagePack struct {
+	Name        string
+	Description string
+	// Plugins are lazy.nvim plugin specs ("owner/repo").
+	Plugins []string
+	// Tools are Mason package names: language servers, formatters,
+	// linters and debug adapters.
+	Tools []string
+	// Parsers are Treesitter parsers.
+	Parsers []string
+}
+
+// languagePacks are the built-in language packs.
+var languagePacks = map[string]LanguagePack{
+	"go": {
+		Name:        "go",
+		Description: "Go: gopls, gofumpt, goimports, golangci-lint and delve",
+		Tools:       []string{"delve", "gofumpt", "goimports", "golangci-lint", "gopls"},
+		Parsers:     []string{"go", "gomod", "gosum", "gowork"},
+	},
+	"rust": {
+		Name:        "rust",
+		Description: "Rust: rustaceanvim, rust-analyzer and codelldb",
+		Plugins:     []string{"mrcjkb/rustaceanvim"},
+		Tools:       []string{"codelldb", "rust-analyzer"},
+		Parsers:     []string{"rust", "toml"},
+	},
+	"python": {
+		Name:        "python",
+		Description: "Python: pyright, ruff and debugpy",
+		Tools:       []string{"debugpy", "pyright", "ruff"},
+		Parsers:     []string{"python", "requirements", "toml"},
+	},
+	"web": {
+		Name:        "web",
+		Description: "Web: TypeScript, ESLint, HTML, CSS and prettierd",
+		Tools:       []string{"css-lsp", "eslint-lsp", "html-lsp", "json-lsp", "prettierd", "typescript-language-server"},
+		Parsers:     []string{"css", "html", "javascript", "json", "tsx", "typescript"},
+	},
+}
+
+// Plugins of the generated specs.
+const (
+	pluginLSPConfig      = "neovim/nvim-lspconfig"
+	pluginMason          = "mason-org/mason.nvim"
+	pluginMasonLSPConfig = "mason-org/mason-lspconfig.nvim"
+	pluginToolInstaller  = "WhoIsSethDaniel/mason-tool-installer.nvim"
+	pluginTreesitter     = "nvim-treesitter/nvim-treesitter"
+)
+
+// minimalPreset is the preset whose configuration preflight generates
+// instead of cloning a starter: lazy.nvim, LSP and Treesitter.
+const minimalPreset = "minimal"
+
+// minimalPlugins and minimalParsers make up the minimal preset.
+var (
+	minimalPlugins = []string{pluginLSPConfig, pluginMason, pluginMasonLSPConfig, pluginTreesitter}
+	minimalParsers = []string{"lua", "markdown", "vim", "vimdoc"}
+)
+
+// LanguagePackNames returns the names of the built-in language packs.
+func LanguagePackNames() []string {
+	names := make([]string, 0, len(languagePacks))
+	for name := range languagePacks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupLanguagePack returns the built-in language pack with the name.
+func LookupLanguagePack(name string) (LanguagePack, bool) {
+	pack, ok := languagePacks[name]
+	return pack, ok
+}
+
+// Spec is the lazy.nvim spec combined from a base preset, language packs
+// and extra plugins. All lists are sorted and free of duplicates, so the
+// same configuration always renders the same file.
+type Spec struct {
+	Preset  string
+	Packs   []string
+	Plugins []string
+	Tools   []string
+	Parsers []string
+}
+
+// ComposeSpec stacks language packs and extra plugins on a base preset.
+// Only the minimal preset contributes plugins; other presets bring their
+// own through their starter configuration.
+func ComposeSpec(preset string, packs, extraPlugins []string) (*Spec, error) {
+	spec := &Spec{Preset: preset}
+	if preset == minimalPreset {
+		spec.Plugins = append(spec.Plugins, minimalPlugins...)
+		spec.Parsers = append(spec.Parsers, minimalParsers...)
+	}
+
+	for _, name := range packs {
+		pack, ok := LookupLanguagePack(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown nvim language pack %q (available: %s)", name, strings.Join(LanguagePackNames(), ", "))
+		}
+		spec.Packs = append(spec.Packs, pack.Name)
+		spec.Plugins = append(spec.Plugins, pack.Plugins...)
+		spec.Tools = append(spec.Tools, pack.Tools...)
+		spec.Parsers = append(spec.Parsers, pack.Parsers...)
+	}
+	spec.Plugins = append(spec.Plugins, extraPlugins...)
+
+	if len(spec.Tools) > 0 {
+		spec.Plugins = append(spec.Plugins, pluginMason, pluginToolInstaller)
+	}
+	if len(spec.Parsers) > 0 {
+		spec.Plugins = append(spec.Plugins, pluginTreesitter)
+	}
+
+	spec.Packs = sortedUnique(spec.Packs)
+	spec.Plugins = sortedUnique(spec.Plugins)
+	spec.Tools = sortedUnique(spec.Tools)
+	spec.Parsers = sortedUnique(spec.Parsers)
+	return spec, nil
+}
+
+// IsEmpty reports whether the spec has nothing to generate.
+func (s *Spec) IsEmpty() bool {
+	return len(s.Plugins) == 0
+}
+
+// generatedHeader starts every file preflight generates, so that files
+// written by hand are never overwritten.
+const generatedHeader = "-- Generated by preflight. Do not edit: changes are overwritten on apply.\n"
+
+// RenderPlugins renders the spec as a lazy.nvim plugin spec module for
+// lua/plugins/.
+func (s *Spec) RenderPlugins() string {
+	var b strings.Builder
+	b.WriteString(generatedHeader)
+	if s.Preset != "" {
+		fmt.Fprintf(&b, "-- Preset: %s\n", s.Preset)
+	}
+	if len(s.Packs) > 0 {
+		fmt.Fprintf(&b, "-- Packs: %s\n", strings.Join(s.Packs, ", "))
+	}
+	b.WriteString("return {\n")
+	for _, plugin := range s.Plugins {
+		switch plugin {
+		case pluginMason, pluginMasonLSPConfig:
+			fmt.Fprintf(&b, "  { %q, opts = {} },\n", plugin)
+		case pluginToolInstaller:
+			fmt.Fprintf(&b, "  {\n    %q,\n    dependencies = { %q },\n    opts = {\n", plugin, pluginMason)
+			writeLuaList(&b, "ensure_installed", s.Tools, "      ")
+			b.WriteString("    },\n  },\n")
+		case pluginTreesitter:
+			fmt.Fprintf(&b, "  {\n    %q,\n    build = \":TSUpdate\",\n    main = \"nvim-treesitter.configs\",\n    opts = {\n", plugin)
+			writeLuaList(&b, "ensure_installed", s.Parsers, "      ")
+			b.WriteString("      highlight = { enable = true },\n    },\n  },\n")
+		default:
+			fmt.Fprintf(&b, "  { %q },\n", plugin)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// RenderInit renders the init.lua of the minimal preset, which installs
+// lazy.nvim and loads the specs in lua/plugins/.
+func RenderInit() string {
+	return generatedHeader + `-- Preset: minimal
+vim.g.mapleader = " "
+vim.g.maplocalleader = "\\"
+
+local lazypath = vim.fn.stdpath("data") .. "/lazy/lazy.nvim"
+if not (vim.uv or vim.loop).fs_stat(lazypath) then
+  vim.fn.system({ "git", "clone", "--filter=blob:none", "--branch=stable", "https://github.com/folke/lazy.nvim.git", lazypath })
+end
+vim.opt.rtp:prepend(lazypath)
+
+require("lazy").setup({ spec = { { import = "plugins" } } })
+`
+}
+
+// writeLuaList writes key = { "a", "b" } with one item per line.
+func writeLuaList(b *strings.Builder, key string, items []string, indent string) {
+	fmt.Fprintf(b, "%s%s = {\n", indent, key)
+	for _, item := range items {
+		fmt.Fprintf(b, "%s  %q,\n", indent, item)
+	}
+	fmt.Fprintf(b, "%s},\n", indent)
+}
+
+// sortedUnique returns the sorted items without duplicates.
+func sortedUnique(items []string) []string {
+	if len(items) == 0 {
+		return nil
+	}
+	sorted := slices.Clone(items)
+	sort.Strings(sorted)
+	return slices.Compact(sorted)
+}
@@ -0,0 +1,97 @@
+package nvim_test
+
+import (
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/provider/nvim"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLanguagePackNames(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"go", "python", "rust", "web"}, nvim.LanguagePackNames())
+
+	pack, ok := nvim.LookupLanguagePack("go")
+	require.True(t, ok)
+	assert.Contains(t, pack.Tools, "gopls")
+}
+
+func TestComposeSpec_Deterministic(t *testing.T) {
+	t.Parallel()
+
+	a, err := nvim.ComposeSpec("minimal", []string{"rust", "python", "go"}, nil)
+	require.NoError(t, err)
+	b, err := nvim.ComposeSpec("minimal", []string{"go", "python", "rust", "go"}, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, a, b, "pack order and duplicates do not matter")
+	assert.Equal(t, []string{"go", "python", "rust"}, a.Packs)
+	assert.Equal(t, a.RenderPlugins(), b.RenderPlugins())
+	assert.Equal(t, 1, countOf(a.Parsers, "toml"), "parsers shared by packs are listed once")
+}
+
+func TestComposeSpec_StarterPreset(t *testing.T) {
+	t.Parallel()
+
+	spec, err := nvim.ComposeSpec("lazyvim", nil, nil)
+	require.NoError(t, err)
+	assert.True(t, spec.IsEmpty(), "starter presets bring their own plugins")
+
+	spec, err = nvim.ComposeSpec("lazyvim", nil, []string{"folke/zen-mode.nvim"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"folke/zen-mode.nvim"}, spec.Plugins)
+}
+
+func TestSpec_RenderPlugins(t *testing.T) {
+	t.Parallel()
+
+	spec, err := nvim.ComposeSpec("", []string{"go"}, []string{"folke/zen-mode.nvim"})
+	require.NoError(t, err)
+
+	assert.Equal(t, `-- Generated by preflight. Do not edit: changes are overwritten on apply.
+-- Packs: go
+return {
+  {
+    "WhoIsSethDaniel/mason-tool-installer.nvim",
+    dependencies = { "mason-org/mason.nvim" },
+    opts = {
+      ensure_installed = {
+        "delve",
+        "gofumpt",
+        "goimports",
+        "golangci-lint",
+        "gopls",
+      },
+    },
+  },
+  { "folke/zen-mode.nvim" },
+  { "mason-org/mason.nvim", opts = {} },
+  {
+    "nvim-treesitter/nvim-treesitter",
+    build = ":TSUpdate",
+    main = "nvim-treesitter.configs",
+    opts = {
+      ensure_installed = {
+        "go",
+        "gomod",
+        "gosum",
+        "gowork",
+      },
+      highlight = { enable = true },
+    },
+  },
+}
+`, spec.RenderPlugins())
+}
+
+func countOf(items []string, item string) int {
+	n := 0
+	for _, i := range items {
+		if i == item {
+			n++
+		}
+	}
+	return n
+}
@@ -1,6 +1,7 @@
 package nvim
 
 import (
+	"fmt"
 	"path/filepath"
 	"strings"
 
@@ -39,7 +40,12 @@ func (p *Provider) Compile(ctx compiler.CompileContext) ([]compiler.Step, error)
 		return nil, err
 	}
 
+	if cfg.ConfigSource != "" && len(cfg.Packs) > 0 {
+		return nil, fmt.Errorf("nvim.packs cannot be combined with nvim.config_source; add the plugins to your config instead")
+	}
+
 	var steps []compiler.Step
+	base := ""
 
 	// Priority: config_source > config_repo > preset
 	switch {
@@ -52,11 +58,32 @@ func (p *Provider) Compile(ctx compiler.CompileContext) ([]compiler.Step, error)
 	case cfg.ConfigRepo != "":
 		// Add config repo step if specified (alternative to preset)
 		steps = append(steps, NewConfigRepoStep(cfg.ConfigRepo, p.fs, p.runner))
+	case cfg.Preset == minimalPreset:
+		// The minimal preset is generated rather than cloned
+		base = cfg.Preset
+		steps = append(steps, NewGeneratedFileStep("init", "init.lua", RenderInit(), nil, p.fs))
 	case cfg.Preset != "" && cfg.Preset != "custom":
 		// Add preset step if specified (skip "custom" which means user has their own config)
+		base = cfg.Preset
 		steps = append(steps, NewPresetStep(cfg.Preset, p.fs, p.runner))
 	}
 
+	// Stack language packs and extra plugins on the base config. A linked
+	// config_source is the user's own and is not written to.
+	if cfg.ConfigSource == "" {
+		spec, err := ComposeSpec(base, cfg.Packs, cfg.ExtraPlugins)
+		if err != nil {
+			return nil, err
+		}
+		if !spec.IsEmpty() {
+			dependsOn := make([]compiler.StepID, 0, len(steps))
+			for _, step := range steps {
+				dependsOn = append(dependsOn, step.ID())
+			}
+			steps = append(steps, NewGeneratedFileStep("plugins", filepath.Join("lua", "plugins", "preflight.lua"), spec.RenderPlugins(), dependsOn, p.fs))
+		}
+	}
+
 	// Add lazy-lock step if using lazy plugin manager
 	if cfg.PluginManager == "lazy" {
 		steps = append(steps, NewLazyLockStep(p.fs, p.runner))
@@ -257,3 +257,77 @@ func TestProvider_Compile_WithConfigSource_PathTraversal(t *testing.T) {
 	// Path traversal should be rejected, no steps
 	assert.Empty(t, steps)
 }
+
+func TestProvider_Compile_MinimalWithPacks(t *testing.T) {
+	t.Parallel()
+
+	fs := mocks.NewFileSystem()
+	runner := mocks.NewCommandRunner()
+	p := nvim.NewProvider(fs, runner)
+
+	raw := map[string]interface{}{
+		"nvim": map[string]interface{}{
+			"preset": "minimal",
+			"packs":  []interface{}{"go", "rust"},
+		},
+	}
+	steps, err := p.Compile(compiler.NewCompileContext(raw))
+
+	require.NoError(t, err)
+	require.Len(t, steps, 2)
+	assert.Equal(t, "nvim:generated:init", steps[0].ID().String())
+	assert.Equal(t, "nvim:generated:plugins", steps[1].ID().String())
+	assert.Equal(t, []compiler.StepID{steps[0].ID()}, steps[1].DependsOn())
+}
+
+func TestProvider_Compile_PacksOnStarterPreset(t *testing.T) {
+	t.Parallel()
+
+	fs := mocks.NewFileSystem()
+	runner := mocks.NewCommandRunner()
+	p := nvim.NewProvider(fs, runner)
+
+	raw := map[string]interface{}{
+		"nvim": map[string]interface{}{
+			"preset": "lazyvim",
+			"packs":  []interface{}{"python"},
+		},
+	}
+	steps, err := p.Compile(compiler.NewCompileContext(raw))
+
+	require.NoError(t, err)
+	require.Len(t, steps, 2)
+	assert.Equal(t, "nvim:preset:lazyvim", steps[0].ID().String())
+	assert.Equal(t, []compiler.StepID{steps[0].ID()}, steps[1].DependsOn(), "the spec is written after the starter is cloned")
+}
+
+func TestProvider_Compile_UnknownPack(t *testing.T) {
+	t.Parallel()
+
+	p := nvim.NewProvider(mocks.NewFileSystem(), mocks.NewCommandRunner())
+	raw := map[string]interface{}{
+		"nvim": map[string]interface{}{
+			"preset": "minimal",
+			"packs":  []interface{}{"cobol"},
+		},
+	}
+	_, err := p.Compile(compiler.NewCompileContext(raw))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "available: go, python, rust, web")
+}
+
+func TestProvider_Compile_PacksWithConfigSource(t *testing.T) {
+	t.Parallel()
+
+	p := nvim.NewProvider(mocks.NewFileSystem(), mocks.NewCommandRunner())
+	raw := map[string]interface{}{
+		"nvim": map[string]interface{}{
+			"config_source": ".config/nvim",
+			"packs":         []interface{}{"go"},
+		},
+	}
+	_, err := p.Compile(compiler.NewCompileContext(raw))
+
+	assert.Error(t, err)
+}
@@ -2,6 +2,8 @@ package nvim
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
 
 	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
 	"github.com/felixgeelhaar/preflight/internal/ports"
@@ -355,3 +357,90 @@ func (s *ConfigSourceStep) Explain(_ compiler.ExplainContext) compiler.Explanati
 		nil,
 	)
 }
+
+// GeneratedFileStep writes a file of the Neovim configuration that preflight
+// generates, such as the combined spec of a preset and its language packs.
+// Files without the generated header were written by hand and are left
+// alone.
+type GeneratedFileStep struct {
+	id        compiler.StepID
+	path      string // Path relative to the Neovim config directory
+	content   string
+	dependsOn []compiler.StepID
+	fs        ports.FileSystem
+}
+
+// NewGeneratedFileStep creates a new GeneratedFileStep for path, relative to
+// the Neovim config directory.
+func NewGeneratedFileStep(name, path, content string, dependsOn []compiler.StepID, fs ports.FileSystem) *GeneratedFileStep {
+	return &GeneratedFileStep{
+		id:        compiler.MustNewStepID(fmt.Sprintf("nvim:generated:%s", name)),
+		path:      path,
+		content:   content,
+		dependsOn: dependsOn,
+		fs:        fs,
+	}
+}
+
+// ID returns the step identifier.
+func (s *GeneratedFileStep) ID() compiler.StepID {
+	return s.id
+}
+
+// DependsOn returns dependencies for this step.
+func (s *GeneratedFileStep) DependsOn() []compiler.StepID {
+	return s.dependsOn
+}
+
+// fullPath returns the path of the file in the Neovim config directory.
+func (s *GeneratedFileStep) fullPath() string {
+	return filepath.Join(NewDiscovery().BestPracticePath(), s.path)
+}
+
+// Check verifies if the file has the generated content.
+func (s *GeneratedFileStep) Check(_ compiler.RunContext) (compiler.StepStatus, error) {
+	data, err := s.fs.ReadFile(s.fullPath())
+	if err == nil && string(data) == s.content {
+		return compiler.StatusSatisfied, nil
+	}
+	return compiler.StatusNeedsApply, nil
+}
+
+// Plan returns the diff for this step.
+func (s *GeneratedFileStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
+	diffType := compiler.DiffTypeAdd
+	if s.fs.Exists(s.fullPath()) {
+		diffType = compiler.DiffTypeModify
+	}
+	return compiler.NewDiff(
+		diffType,
+		"generated",
+		s.path,
+		"",
+		fmt.Sprintf("Generate %s", s.path),
+	), nil
+}
+
+// Apply writes the file, refusing to replace one written by hand.
+func (s *GeneratedFileStep) Apply(_ compiler.RunContext) error {
+	path := s.fullPath()
+	if data, err := s.fs.ReadFile(path); err == nil && !strings.HasPrefix(string(data), generatedHeader) {
+		return fmt.Errorf("%s was not generated by preflight; remove it or manage the config with nvim.config_source", path)
+	}
+	if err := s.fs.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := s.fs.WriteFile(path, []byte(s.content), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// Explain provides context for this step.
+func (s *GeneratedFileStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
+	return compiler.NewExplanation(
+		"Generate Neovim Config",
+		fmt.Sprintf("Write %s from nvim.preset, nvim.packs and nvim.extra_plugins. Language packs add their plugins, Mason tools and Treesitter parsers; the result is sorted so that it only changes when the configuration does.", s.path),
+		[]string{"https://lazy.folke.io/spec", "https://github.com/WhoIsSethDaniel/mason-tool-installer.nvim"},
+	)
+}
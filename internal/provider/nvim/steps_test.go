@@ -2,6 +2,7 @@ package nvim_test
 
 import (
 	"context"
+	"path/filepath"
 	"testing"
 
 	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
@@ -689,3 +690,39 @@ func TestConfigSourceStep_Explain(t *testing.T) {
 	assert.Contains(t, exp.Detail(), sourcePath)
 	assert.Contains(t, exp.Detail(), "symlink")
 }
+
+func TestGeneratedFileStep_Apply(t *testing.T) {
+	t.Parallel()
+
+	fs := mocks.NewFileSystem()
+	step := nvim.NewGeneratedFileStep("init", "init.lua", nvim.RenderInit(), nil, fs)
+	ctx := compiler.NewRunContext(context.TODO())
+	assert.Equal(t, "nvim:generated:init", step.ID().String())
+
+	status, err := step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+
+	require.NoError(t, step.Apply(ctx))
+	status, err = step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, status)
+
+	updated := nvim.NewGeneratedFileStep("init", "init.lua", nvim.RenderInit()+"-- more\n", nil, fs)
+	require.NoError(t, updated.Apply(ctx), "generated files are replaced")
+}
+
+func TestGeneratedFileStep_Apply_KeepsHandwrittenFile(t *testing.T) {
+	t.Parallel()
+
+	fs := mocks.NewFileSystem()
+	path := filepath.Join(nvim.NewDiscovery().BestPracticePath(), "init.lua")
+	fs.AddFile(path, "require('mine')\n")
+
+	step := nvim.NewGeneratedFileStep("init", "init.lua", nvim.RenderInit(), nil, fs)
+	require.Error(t, step.Apply(compiler.NewRunContext(context.TODO())))
+
+	data, err := fs.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "require('mine')\n", string(data))
+}
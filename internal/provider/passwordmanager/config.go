@@ -0,0 +1,83 @@
+// Package passwordmanager provides the provider that installs and checks
+// the password manager CLIs (1Password, Bitwarden) secret references use.
+package passwordmanager
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Config represents the password_manager section of the configuration.
+type Config struct {
+	OnePassword *OnePassword
+	Bitwarden   *Bitwarden
+}
+
+// OnePassword declares the 1Password CLI (op).
+type OnePassword struct {
+	// Account is the sign-in address, email or shorthand of the account
+	// op should have; any account will do when empty.
+	Account string
+	// SSHAgent points SSH at the 1Password SSH agent.
+	SSHAgent bool
+}
+
+// Bitwarden declares the Bitwarden CLI (bw).
+type Bitwarden struct {
+	// Server is the URL of a self-hosted server.
+	Server string
+	// Email is the account bw should be logged in to; any account will do
+	// when empty.
+	Email string
+}
+
+// ParseConfig parses the password_manager configuration from a raw map.
+func ParseConfig(raw map[string]interface{}) (*Config, error) {
+	cfg := &Config{}
+
+	if value, ok := raw["1password"]; ok {
+		m, err := section("1password", value)
+		if err != nil {
+			return nil, err
+		}
+		cfg.OnePassword = &OnePassword{}
+		if account, ok := m["account"].(string); ok {
+			cfg.OnePassword.Account = account
+		}
+		if sshAgent, ok := m["ssh_agent"].(bool); ok {
+			cfg.OnePassword.SSHAgent = sshAgent
+		}
+	}
+
+	if value, ok := raw["bitwarden"]; ok {
+		m, err := section("bitwarden", value)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Bitwarden = &Bitwarden{}
+		if server, ok := m["server"].(string); ok {
+			if !strings.HasPrefix(server, "https://") && !strings.HasPrefix(server, "http://") {
+				return nil, fmt.Errorf("bitwarden server %q must be an http(s) URL", server)
+			}
+			cfg.Bitwarden.Server = server
+		}
+		if email, ok := m["email"].(string); ok {
+			cfg.Bitwarden.Email = email
+		}
+	}
+
+	return cfg, nil
+}
+
+// section returns the settings of a declared CLI. A CLI declared without
+// settings ("bitwarden: {}" or a bare "bitwarden:") only gets installed.
+func section(name string, value interface{}) (map[string]interface{}, error) {
+	if value == nil {
+		return map[string]interface{}{}, nil
+	}
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s must be an object", name)
+	}
+	return m, nil
+}
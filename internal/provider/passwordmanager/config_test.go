@@ -0,0 +1,62 @@
+package passwordmanager_test
+
+import (
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/provider/passwordmanager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfig(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := passwordmanager.ParseConfig(map[string]interface{}{
+		"1password": map[string]interface{}{
+			"account":   "acme.1password.com",
+			"ssh_agent": true,
+		},
+		"bitwarden": nil,
+	})
+	require.NoError(t, err)
+
+	require.NotNil(t, cfg.OnePassword)
+	assert.Equal(t, "acme.1password.com", cfg.OnePassword.Account)
+	assert.True(t, cfg.OnePassword.SSHAgent)
+	require.NotNil(t, cfg.Bitwarden)
+	assert.Empty(t, cfg.Bitwarden.Email)
+}
+
+func TestParseConfig_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		raw  map[string]interface{}
+	}{
+		{"section not an object", map[string]interface{}{"1password": "yes"}},
+		{"server not a URL", map[string]interface{}{"bitwarden": map[string]interface{}{"server": "vault.acme.com"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := passwordmanager.ParseConfig(tt.raw)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestOnePasswordAccount_Matches(t *testing.T) {
+	t.Parallel()
+
+	account := passwordmanager.OnePasswordAccount{
+		URL:       "https://acme.1password.com",
+		Email:     "jane@acme.com",
+		Shorthand: "acme",
+	}
+	assert.True(t, account.Matches("acme.1password.com"))
+	assert.True(t, account.Matches("https://acme.1password.com/"))
+	assert.True(t, account.Matches("Jane@acme.com"))
+	assert.True(t, account.Matches("acme"))
+	assert.False(t, account.Matches("my.1password.com"))
+}
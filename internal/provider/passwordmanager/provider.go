@@ -0,0 +1,88 @@
+package passwordmanager
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+)
+
+// Provider implements the compiler.Provider interface for password manager CLIs.
+type Provider struct {
+	fs     ports.FileSystem
+	runner ports.CommandRunner
+}
+
+// NewProvider creates a new password manager provider.
+func NewProvider(fs ports.FileSystem, runner ports.CommandRunner) *Provider {
+	return &Provider{fs: fs, runner: runner}
+}
+
+// Name returns the provider name.
+func (p *Provider) Name() string {
+	return "password_manager"
+}
+
+// Compile transforms password_manager configuration into executable steps.
+func (p *Provider) Compile(ctx compiler.CompileContext) ([]compiler.Step, error) {
+	rawConfig := ctx.GetSection("password_manager")
+	if rawConfig == nil {
+		return nil, nil
+	}
+
+	cfg, err := ParseConfig(rawConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	steps := make([]compiler.Step, 0)
+
+	if op := cfg.OnePassword; op != nil {
+		install := NewInstallStep(OnePasswordCLI, p.runner)
+		steps = append(steps, install, NewOnePasswordAccountStep(op.Account, install.ID(), p.runner))
+
+		if socket := AgentSocket(runtime.GOOS); op.SSHAgent && socket != "" {
+			agentStep, err := p.sshAgentStep(ctx, socket)
+			if err != nil {
+				return nil, err
+			}
+			if agentStep != nil {
+				steps = append(steps, agentStep)
+			}
+		}
+	}
+
+	if bw := cfg.Bitwarden; bw != nil {
+		install := NewInstallStep(BitwardenCLI, p.runner)
+		steps = append(steps, install)
+		loginDeps := []compiler.StepID{install.ID()}
+		if bw.Server != "" {
+			server := NewBitwardenServerStep(bw.Server, install.ID(), p.runner)
+			steps = append(steps, server)
+			loginDeps = append(loginDeps, server.ID())
+		}
+		steps = append(steps, NewBitwardenLoginStep(bw.Email, loginDeps, p.runner))
+	}
+
+	return steps, nil
+}
+
+// sshAgentStep returns the step that points SSH at the 1Password agent.
+// When the ssh section is declared, ~/.ssh/config is generated from it and
+// must set the agent itself, so no step is needed.
+func (p *Provider) sshAgentStep(ctx compiler.CompileContext, socket string) (compiler.Step, error) {
+	ssh := ctx.GetSection("ssh")
+	if ssh == nil {
+		return NewSSHAgentStep(socket, p.fs), nil
+	}
+	if defaults, ok := ssh["defaults"].(map[string]interface{}); ok {
+		if agent, _ := defaults["identityagent"].(string); agent != "" {
+			return nil, nil
+		}
+	}
+	return nil, fmt.Errorf("1password ssh_agent: ~/.ssh/config is generated from the ssh section; set ssh.defaults.identityagent to %q", socket)
+}
+
+// Ensure Provider implements compiler.Provider.
+var _ compiler.Provider = (*Provider)(nil)
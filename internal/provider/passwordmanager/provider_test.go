@@ -0,0 +1,86 @@
+package passwordmanager_test
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/provider/passwordmanager"
+	"github.com/felixgeelhaar/preflight/internal/testutil/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func stepIDs(steps []compiler.Step) []string {
+	ids := make([]string, 0, len(steps))
+	for _, step := range steps {
+		ids = append(ids, step.ID().String())
+	}
+	return ids
+}
+
+func TestProvider_Compile_NoConfig(t *testing.T) {
+	t.Parallel()
+
+	p := passwordmanager.NewProvider(mocks.NewFileSystem(), mocks.NewCommandRunner())
+	assert.Equal(t, "password_manager", p.Name())
+
+	steps, err := p.Compile(compiler.NewCompileContext(map[string]interface{}{}))
+	require.NoError(t, err)
+	assert.Empty(t, steps)
+}
+
+func TestProvider_Compile(t *testing.T) {
+	t.Parallel()
+
+	p := passwordmanager.NewProvider(mocks.NewFileSystem(), mocks.NewCommandRunner())
+	steps, err := p.Compile(compiler.NewCompileContext(map[string]interface{}{
+		"password_manager": map[string]interface{}{
+			"1password": map[string]interface{}{"account": "acme.1password.com"},
+			"bitwarden": map[string]interface{}{"server": "https://vault.acme.com", "email": "jane@acme.com"},
+		},
+	}))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"password_manager:1password:install",
+		"password_manager:1password:account",
+		"password_manager:bitwarden:install",
+		"password_manager:bitwarden:server",
+		"password_manager:bitwarden:login",
+	}, stepIDs(steps))
+	assert.Len(t, steps[4].DependsOn(), 2)
+}
+
+func TestProvider_Compile_SSHAgent(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("the 1Password agent needs no SSH setting on Windows")
+	}
+
+	p := passwordmanager.NewProvider(mocks.NewFileSystem(), mocks.NewCommandRunner())
+	section := map[string]interface{}{
+		"1password": map[string]interface{}{"ssh_agent": true},
+	}
+
+	steps, err := p.Compile(compiler.NewCompileContext(map[string]interface{}{"password_manager": section}))
+	require.NoError(t, err)
+	assert.Contains(t, stepIDs(steps), "password_manager:1password:ssh-agent")
+
+	// A generated ~/.ssh/config must set the agent itself
+	_, err = p.Compile(compiler.NewCompileContext(map[string]interface{}{
+		"password_manager": section,
+		"ssh":              map[string]interface{}{"include": "~/.ssh/config.d/*"},
+	}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ssh.defaults.identityagent")
+
+	steps, err = p.Compile(compiler.NewCompileContext(map[string]interface{}{
+		"password_manager": section,
+		"ssh": map[string]interface{}{
+			"defaults": map[string]interface{}{"identityagent": passwordmanager.AgentSocket(runtime.GOOS)},
+		},
+	}))
+	require.NoError(t, err)
+	assert.NotContains(t, stepIDs(steps), "password_manager:1password:ssh-agent")
+}
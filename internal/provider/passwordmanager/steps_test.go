@@ -0,0 +1,156 @@
+package passwordmanager_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/provider/passwordmanager"
+	"github.com/felixgeelhaar/preflight/internal/testutil/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCLI_InstallCommand(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"brew", "install", "--cask", "1password-cli"}, passwordmanager.OnePasswordCLI.InstallCommand("darwin"))
+	assert.Nil(t, passwordmanager.OnePasswordCLI.InstallCommand("linux"))
+	assert.Equal(t, []string{"brew", "install", "bitwarden-cli"}, passwordmanager.BitwardenCLI.InstallCommand("linux"))
+	assert.Contains(t, passwordmanager.BitwardenCLI.InstallCommand("windows"), "Bitwarden.CLI")
+}
+
+func TestInstallStep(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("installs with winget on Windows")
+	}
+
+	runner := mocks.NewCommandRunner()
+	runner.AddError("bw", []string{"--version"}, errors.New("executable file not found"))
+	runner.AddResult("brew", []string{"install", "bitwarden-cli"}, ports.CommandResult{})
+	ctx := compiler.NewRunContext(context.Background())
+
+	step := passwordmanager.NewInstallStep(passwordmanager.BitwardenCLI, runner)
+	status, err := step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+	require.NoError(t, step.Apply(ctx))
+
+	installed := mocks.NewCommandRunner()
+	installed.AddResult("bw", []string{"--version"}, ports.CommandResult{Stdout: "2024.6.0"})
+	status, err = passwordmanager.NewInstallStep(passwordmanager.BitwardenCLI, installed).Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, status)
+}
+
+func TestOnePasswordAccountStep(t *testing.T) {
+	t.Parallel()
+
+	runner := mocks.NewCommandRunner()
+	runner.AddResult("op", []string{"account", "list", "--format=json"}, ports.CommandResult{
+		Stdout: `[{"url": "my.1password.com", "email": "jane@example.com", "shorthand": "my"}]`,
+	})
+	ctx := compiler.NewRunContext(context.Background())
+	install := compiler.MustNewStepID("password_manager:1password:install")
+
+	status, err := passwordmanager.NewOnePasswordAccountStep("", install, runner).Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, status)
+
+	step := passwordmanager.NewOnePasswordAccountStep("acme.1password.com", install, runner)
+	status, err = step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+
+	err = step.Apply(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "op account add --address acme.1password.com")
+}
+
+func TestSSHAgentStep(t *testing.T) {
+	t.Parallel()
+
+	fs := mocks.NewFileSystem()
+	path := ports.ExpandPath("~/.ssh/config")
+	fs.AddFile(path, "Host github.com\n    User git\n")
+	ctx := compiler.NewRunContext(context.Background())
+	socket := "~/.1password/agent.sock"
+
+	step := passwordmanager.NewSSHAgentStep(socket, fs)
+	status, err := step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+
+	require.NoError(t, step.Apply(ctx))
+	content, err := fs.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "Host *\n    IdentityAgent \"~/.1password/agent.sock\"\n")
+	assert.Contains(t, string(content), "Host github.com\n    User git\n", "existing hosts are kept")
+
+	status, err = step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, status)
+}
+
+func TestUsesAgent(t *testing.T) {
+	t.Parallel()
+
+	socket := "~/.1password/agent.sock"
+	assert.True(t, passwordmanager.UsesAgent([]byte("Host *\n  IdentityAgent ~/.1password/agent.sock\n"), socket))
+	assert.True(t, passwordmanager.UsesAgent([]byte("Host *\n\tidentityagent \""+ports.ExpandPath(socket)+"\"\n"), socket))
+	assert.False(t, passwordmanager.UsesAgent([]byte("Host *\n  IdentityAgent SSH_AUTH_SOCK\n"), socket))
+}
+
+func TestBitwardenServerStep(t *testing.T) {
+	t.Parallel()
+
+	runner := mocks.NewCommandRunner()
+	runner.AddResult("bw", []string{"config", "server"}, ports.CommandResult{Stdout: "https://vault.bitwarden.com\n"})
+	runner.AddResult("bw", []string{"config", "server", "https://vault.acme.com"}, ports.CommandResult{})
+	ctx := compiler.NewRunContext(context.Background())
+
+	step := passwordmanager.NewBitwardenServerStep("https://vault.acme.com", compiler.MustNewStepID("password_manager:bitwarden:install"), runner)
+	status, err := step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+	require.NoError(t, step.Apply(ctx))
+}
+
+func TestBitwardenLoginStep(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		email   string
+		status  string
+		want    compiler.StepStatus
+		wantErr string
+	}{
+		{"locked counts as logged in", "", `{"status": "locked", "userEmail": "jane@acme.com"}`, compiler.StatusSatisfied, ""},
+		{"not logged in", "jane@acme.com", `{"status": "unauthenticated"}`, compiler.StatusNeedsApply, "log in with 'bw login jane@acme.com'"},
+		{"other account", "jane@acme.com", `{"status": "unlocked", "userEmail": "joe@acme.com"}`, compiler.StatusNeedsApply, "logged in as joe@acme.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := mocks.NewCommandRunner()
+			runner.AddResult("bw", []string{"status"}, ports.CommandResult{Stdout: tt.status})
+			ctx := compiler.NewRunContext(context.Background())
+
+			step := passwordmanager.NewBitwardenLoginStep(tt.email, nil, runner)
+			status, err := step.Check(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, status)
+			if tt.wantErr != "" {
+				err := step.Apply(ctx)
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
@@ -137,6 +137,11 @@ func (s *PackageStep) LockInfo() (compiler.LockInfo, bool) {
 	}, true
 }
 
+// UninstallCommand returns the command that uninstalls the package.
+func (s *PackageStep) UninstallCommand() []string {
+	return []string{"pip", "uninstall", "-y", s.pkg.Name}
+}
+
 // InstalledVersion returns the installed pip package version if available.
 func (s *PackageStep) InstalledVersion(ctx compiler.RunContext) (string, bool, error) {
 	result, err := s.runner.Run(ctx.Context(), "pip", "show", s.pkg.Name)
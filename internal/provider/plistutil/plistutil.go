@@ -0,0 +1,177 @@
+// Package plistutil renders XML property lists for providers that write
+// macOS preference and launchd files.
+package plistutil
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const plistHeader = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+`
+
+// Encode renders v as an XML property list. Dictionaries are written with
+// sorted keys so the output is stable across runs.
+func Encode(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(plistHeader)
+	if err := writePlistValue(&buf, v, 0); err != nil {
+		return nil, err
+	}
+	buf.WriteString("</plist>\n")
+	return buf.Bytes(), nil
+}
+
+func writePlistValue(buf *bytes.Buffer, v interface{}, depth int) error {
+	indent := strings.Repeat("\t", depth)
+	switch val := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		buf.WriteString(indent + "<dict>\n")
+		for _, k := range keys {
+			buf.WriteString(indent + "\t<key>")
+			_ = xml.EscapeText(buf, []byte(k))
+			buf.WriteString("</key>\n")
+			if err := writePlistValue(buf, val[k], depth+1); err != nil {
+				return fmt.Errorf("%s: %w", k, err)
+			}
+		}
+		buf.WriteString(indent + "</dict>\n")
+	case []map[string]interface{}:
+		items := make([]interface{}, len(val))
+		for i := range val {
+			items[i] = val[i]
+		}
+		return writePlistValue(buf, items, depth)
+	case []interface{}:
+		buf.WriteString(indent + "<array>\n")
+		for _, item := range val {
+			if err := writePlistValue(buf, item, depth+1); err != nil {
+				return err
+			}
+		}
+		buf.WriteString(indent + "</array>\n")
+	case string:
+		buf.WriteString(indent + "<string>")
+		_ = xml.EscapeText(buf, []byte(val))
+		buf.WriteString("</string>\n")
+	case bool:
+		if val {
+			buf.WriteString(indent + "<true/>\n")
+		} else {
+			buf.WriteString(indent + "<false/>\n")
+		}
+	case int:
+		buf.WriteString(indent + "<integer>" + strconv.Itoa(val) + "</integer>\n")
+	case float64:
+		buf.WriteString(indent + "<real>" + strconv.FormatFloat(val, 'f', -1, 64) + "</real>\n")
+	default:
+		return fmt.Errorf("unsupported plist value of type %T", v)
+	}
+	return nil
+}
+
+// Decode parses an XML property list into maps, slices, strings, bools,
+// ints and float64s. Data and date values are returned as strings.
+func Decode(data []byte) (interface{}, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("invalid plist: %w", err)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			if start.Name.Local != "plist" {
+				return readPlistValue(decoder, start)
+			}
+			for {
+				token, err := decoder.Token()
+				if err != nil {
+					return nil, fmt.Errorf("invalid plist: %w", err)
+				}
+				if inner, ok := token.(xml.StartElement); ok {
+					return readPlistValue(decoder, inner)
+				}
+			}
+		}
+	}
+}
+
+func readPlistValue(decoder *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	switch start.Name.Local {
+	case "dict":
+		dict := make(map[string]interface{})
+		var key string
+		for {
+			token, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch t := token.(type) {
+			case xml.StartElement:
+				if t.Name.Local == "key" {
+					if err := decoder.DecodeElement(&key, &t); err != nil {
+						return nil, err
+					}
+					continue
+				}
+				value, err := readPlistValue(decoder, t)
+				if err != nil {
+					return nil, err
+				}
+				dict[key] = value
+			case xml.EndElement:
+				return dict, nil
+			}
+		}
+	case "array":
+		array := make([]interface{}, 0)
+		for {
+			token, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch t := token.(type) {
+			case xml.StartElement:
+				value, err := readPlistValue(decoder, t)
+				if err != nil {
+					return nil, err
+				}
+				array = append(array, value)
+			case xml.EndElement:
+				return array, nil
+			}
+		}
+	case "true", "false":
+		if err := decoder.Skip(); err != nil {
+			return nil, err
+		}
+		return start.Name.Local == "true", nil
+	}
+
+	var text string
+	if err := decoder.DecodeElement(&text, &start); err != nil {
+		return nil, err
+	}
+	text = strings.TrimSpace(text)
+	switch start.Name.Local {
+	case "integer":
+		return strconv.Atoi(text)
+	case "real":
+		return strconv.ParseFloat(text, 64)
+	case "string", "data", "date":
+		return text, nil
+	default:
+		return nil, fmt.Errorf("unsupported plist element <%s>", start.Name.Local)
+	}
+}
@@ -0,0 +1,37 @@
+package plistutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeDecode_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	value := map[string]interface{}{
+		"Label":   "com.example.job",
+		"Enabled": true,
+		"Hidden":  false,
+		"Count":   3,
+		"Ratio":   0.5,
+		"Args":    []interface{}{"/bin/sh", "-c", "echo <hi> & bye"},
+		"Nested":  map[string]interface{}{"Hour": 9},
+	}
+	data, err := Encode(value)
+	require.NoError(t, err)
+
+	decoded, err := Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, value, decoded)
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := Decode([]byte("<plist><dict><key>a</key><unknown/></dict></plist>"))
+	require.Error(t, err)
+	_, err = Decode([]byte("not xml"))
+	require.Error(t, err)
+}
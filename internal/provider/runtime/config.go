@@ -2,7 +2,10 @@
 // It handles tool version management via rtx/asdf.
 package runtime
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // Config represents runtime tool version configuration.
 type Config struct {
@@ -10,12 +13,23 @@ type Config struct {
 	Scope   string
 	Tools   []ToolConfig
 	Plugins []PluginConfig
+	Shims   bool
 }
 
 // ToolConfig represents a tool with its desired version.
 type ToolConfig struct {
 	Name    string
 	Version string
+	Bins    []string
+}
+
+// Commands returns the commands the tool provides, which defaults to the
+// tool name.
+func (t ToolConfig) Commands() []string {
+	if len(t.Bins) > 0 {
+		return t.Bins
+	}
+	return []string{t.Name}
 }
 
 // PluginConfig represents a custom plugin source for asdf.
@@ -46,6 +60,10 @@ func ParseConfig(raw map[string]interface{}) (*Config, error) {
 		cfg.Scope = scope
 	}
 
+	if shims, ok := raw["shims"].(bool); ok {
+		cfg.Shims = shims
+	}
+
 	// Parse tools
 	if tools, ok := raw["tools"].([]interface{}); ok {
 		for i, t := range tools {
@@ -61,6 +79,15 @@ func ParseConfig(raw map[string]interface{}) (*Config, error) {
 			if v, ok := toolMap["version"].(string); ok {
 				tool.Version = v
 			}
+			if bins, ok := toolMap["bins"].([]interface{}); ok {
+				for _, b := range bins {
+					bin, ok := b.(string)
+					if !ok || bin == "" || strings.ContainsAny(bin, "/\\ \t") {
+						return nil, fmt.Errorf("invalid command %v for tool %s: must be a plain command name", b, tool.Name)
+					}
+					tool.Bins = append(tool.Bins, bin)
+				}
+			}
 
 			cfg.Tools = append(cfg.Tools, tool)
 		}
@@ -43,7 +43,7 @@ func (p *Provider) Compile(ctx compiler.CompileContext) ([]compiler.Step, error)
 		return nil, nil
 	}
 
-	steps := make([]compiler.Step, 0, len(cfg.Plugins)+1)
+	steps := make([]compiler.Step, 0, len(cfg.Plugins)+2)
 
 	// Add plugin steps first (plugins must be installed before tools)
 	for _, plugin := range cfg.Plugins {
@@ -54,6 +54,9 @@ func (p *Provider) Compile(ctx compiler.CompileContext) ([]compiler.Step, error)
 	if len(cfg.Tools) > 0 {
 		steps = append(steps, NewToolVersionStep(cfg, p.fs))
 	}
+	if cfg.Shims && len(cfg.Tools) > 0 {
+		steps = append(steps, NewShimStep(cfg, p.fs))
+	}
 
 	return steps, nil
 }
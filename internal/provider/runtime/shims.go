@@ -0,0 +1,214 @@
+package runtime
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+)
+
+// ShimsDir returns where tool shims are written: shims in the preflight
+// data directory. Put it first in PATH, for example with path.prepend, to
+// route tool commands through the shims.
+func ShimsDir() string {
+	dir, err := platform.Path(platform.DataDir, "shims")
+	if err != nil {
+		return ports.ExpandPath("~/.preflight/shims")
+	}
+	return dir
+}
+
+// shimManifest lists the shims preflight wrote, so the shims of tools that
+// are no longer declared are removed.
+const shimManifest = ".preflight-shims"
+
+const shimHeader = "# Generated by preflight - do not edit manually\n"
+
+// VersionEnvVar returns the variable that selects the version of tool for
+// backend. Shims prefer it over the version they were generated with, so
+// 'preflight exec' can run another profile's versions.
+func VersionEnvVar(backend, tool string) string {
+	name := strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(tool))
+	if isMise(backend) {
+		return "MISE_" + name + "_VERSION"
+	}
+	return "ASDF_" + name + "_VERSION"
+}
+
+// VersionEnv returns the version variables of the shimmed tools.
+func (c *Config) VersionEnv() map[string]string {
+	env := make(map[string]string)
+	if !c.Shims {
+		return env
+	}
+	for _, tool := range c.Tools {
+		if tool.Version != "" {
+			env[VersionEnvVar(c.Backend, tool.Name)] = tool.Version
+		}
+	}
+	return env
+}
+
+// ShimScripts returns the shim scripts by command name. It is empty unless shims
+// are enabled.
+func (c *Config) ShimScripts() map[string][]byte {
+	shims := make(map[string][]byte)
+	if !c.Shims {
+		return shims
+	}
+	for _, tool := range c.Tools {
+		if tool.Version == "" {
+			continue
+		}
+		for _, command := range tool.Commands() {
+			shims[command] = RenderShim(c.Backend, tool, command)
+		}
+	}
+	return shims
+}
+
+// RenderShim returns a POSIX shell script that runs command at the version
+// of tool through backend.
+func RenderShim(backend string, tool ToolConfig, command string) []byte {
+	envVar := VersionEnvVar(backend, tool.Name)
+	var buf bytes.Buffer
+	buf.WriteString("#!/bin/sh\n")
+	buf.WriteString(shimHeader)
+	if isMise(backend) {
+		fmt.Fprintf(&buf, "exec mise exec \"%s@${%s:-%s}\" -- %s \"$@\"\n", tool.Name, envVar, tool.Version, command)
+	} else {
+		fmt.Fprintf(&buf, ": \"${%s:=%s}\"\n", envVar, tool.Version)
+		fmt.Fprintf(&buf, "export %s\n", envVar)
+		fmt.Fprintf(&buf, "exec asdf exec %s \"$@\"\n", command)
+	}
+	return buf.Bytes()
+}
+
+func isMise(backend string) bool {
+	return backend == "rtx" || backend == "mise"
+}
+
+// ShimStep writes the tool shims of the target and removes the shims of
+// tools it no longer declares.
+type ShimStep struct {
+	cfg *Config
+	id  compiler.StepID
+	fs  ports.FileSystem
+	dir string
+}
+
+// NewShimStep creates a new ShimStep writing to ShimsDir().
+func NewShimStep(cfg *Config, fs ports.FileSystem) *ShimStep {
+	return &ShimStep{
+		cfg: cfg,
+		id:  compiler.MustNewStepID("runtime:shims"),
+		fs:  fs,
+		dir: ShimsDir(),
+	}
+}
+
+// ID returns the step identifier.
+func (s *ShimStep) ID() compiler.StepID {
+	return s.id
+}
+
+// DependsOn returns dependencies for this step.
+func (s *ShimStep) DependsOn() []compiler.StepID {
+	return nil
+}
+
+// Check verifies that every shim is current and no stale shim is left.
+func (s *ShimStep) Check(_ compiler.RunContext) (compiler.StepStatus, error) {
+	shims := s.cfg.ShimScripts()
+	for command, content := range shims {
+		existing, err := s.fs.ReadFile(filepath.Join(s.dir, command))
+		if err != nil || !bytes.Equal(existing, content) {
+			return compiler.StatusNeedsApply, nil //nolint:nilerr // a missing shim needs apply
+		}
+	}
+	if len(s.stale(shims)) > 0 {
+		return compiler.StatusNeedsApply, nil
+	}
+	return compiler.StatusSatisfied, nil
+}
+
+// Plan returns the diff for this step.
+func (s *ShimStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
+	shims := s.cfg.ShimScripts()
+	summary := fmt.Sprintf("%d command(s): %s", len(shims), strings.Join(sortedCommands(shims), ", "))
+	if stale := s.stale(shims); len(stale) > 0 {
+		summary += fmt.Sprintf("; remove %s", strings.Join(stale, ", "))
+	}
+	return compiler.NewDiff(compiler.DiffTypeModify, "shims", s.dir, "", summary), nil
+}
+
+// Apply writes the shims and the manifest, and removes stale shims.
+func (s *ShimStep) Apply(_ compiler.RunContext) error {
+	shims := s.cfg.ShimScripts()
+	if err := s.fs.MkdirAll(s.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", s.dir, err)
+	}
+	for command, content := range shims {
+		path := filepath.Join(s.dir, command)
+		// Replace rather than overwrite, so the file mode is always set
+		_ = s.fs.Remove(path)
+		if err := s.fs.WriteFile(path, content, 0o755); err != nil {
+			return fmt.Errorf("failed to write shim %s: %w", command, err)
+		}
+	}
+	for _, command := range s.stale(shims) {
+		path := filepath.Join(s.dir, command)
+		if err := s.fs.Remove(path); err != nil && s.fs.Exists(path) {
+			return fmt.Errorf("failed to remove shim %s: %w", command, err)
+		}
+	}
+
+	manifest := shimHeader
+	for _, command := range sortedCommands(shims) {
+		manifest += command + "\n"
+	}
+	return s.fs.WriteFile(filepath.Join(s.dir, shimManifest), []byte(manifest), 0o644)
+}
+
+// Explain provides context for this step.
+func (s *ShimStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
+	return compiler.NewExplanation(
+		"Write Tool Shims",
+		fmt.Sprintf("Write shims to %s that run %s at the versions this target declares. With %s first in PATH, switching profiles switches tool versions.",
+			s.dir, strings.Join(sortedCommands(s.cfg.ShimScripts()), ", "), s.dir),
+		nil,
+	)
+}
+
+// stale returns the shims in the manifest that are no longer wanted.
+func (s *ShimStep) stale(shims map[string][]byte) []string {
+	data, err := s.fs.ReadFile(filepath.Join(s.dir, shimManifest))
+	if err != nil {
+		return nil
+	}
+	var stale []string
+	for _, line := range strings.Split(string(data), "\n") {
+		command := strings.TrimSpace(line)
+		if command == "" || strings.HasPrefix(command, "#") {
+			continue
+		}
+		if _, ok := shims[command]; !ok {
+			stale = append(stale, command)
+		}
+	}
+	return stale
+}
+
+func sortedCommands(shims map[string][]byte) []string {
+	commands := make([]string, 0, len(shims))
+	for command := range shims {
+		commands = append(commands, command)
+	}
+	sort.Strings(commands)
+	return commands
+}
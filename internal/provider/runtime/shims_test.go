@@ -0,0 +1,134 @@
+package runtime
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	goruntime "runtime"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/testutil/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfig_Shims(t *testing.T) {
+	cfg, err := ParseConfig(map[string]interface{}{
+		"shims": true,
+		"tools": []interface{}{
+			map[string]interface{}{"name": "nodejs", "version": "20.11.0", "bins": []interface{}{"node", "npx"}},
+			map[string]interface{}{"name": "terraform", "version": "1.7.5"},
+		},
+	})
+	require.NoError(t, err)
+	assert.True(t, cfg.Shims)
+	assert.Equal(t, []string{"node", "npx"}, cfg.Tools[0].Commands())
+	assert.Equal(t, []string{"terraform"}, cfg.Tools[1].Commands())
+
+	_, err = ParseConfig(map[string]interface{}{
+		"tools": []interface{}{
+			map[string]interface{}{"name": "nodejs", "version": "20", "bins": []interface{}{"../node"}},
+		},
+	})
+	require.Error(t, err)
+}
+
+func TestVersionEnvVar(t *testing.T) {
+	assert.Equal(t, "MISE_TERRAFORM_VERSION", VersionEnvVar("mise", "terraform"))
+	assert.Equal(t, "ASDF_GOLANG_CI_LINT_VERSION", VersionEnvVar("asdf", "golang-ci-lint"))
+}
+
+func TestRenderShim(t *testing.T) {
+	tool := ToolConfig{Name: "terraform", Version: "1.7.5"}
+
+	assert.Equal(t, "#!/bin/sh\n"+shimHeader+
+		"exec mise exec \"terraform@${MISE_TERRAFORM_VERSION:-1.7.5}\" -- terraform \"$@\"\n",
+		string(RenderShim("rtx", tool, "terraform")))
+	assert.Equal(t, "#!/bin/sh\n"+shimHeader+
+		": \"${ASDF_TERRAFORM_VERSION:=1.7.5}\"\nexport ASDF_TERRAFORM_VERSION\nexec asdf exec terraform \"$@\"\n",
+		string(RenderShim("asdf", tool, "terraform")))
+}
+
+func TestRenderShim_Runs(t *testing.T) {
+	if goruntime.GOOS == "windows" {
+		t.Skip("shims are POSIX shell scripts")
+	}
+
+	// A fake mise that prints what it was asked to run
+	dir := t.TempDir()
+	fake := "#!/bin/sh\necho \"$@\"\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "mise"), []byte(fake), 0o755))
+	shim := filepath.Join(dir, "terraform")
+	require.NoError(t, os.WriteFile(shim, RenderShim("mise", ToolConfig{Name: "terraform", Version: "1.7.5"}, "terraform"), 0o755))
+
+	cmd := exec.Command(shim, "plan", "-out", "my plan")
+	cmd.Env = []string{"PATH=" + dir + ":/usr/bin:/bin"}
+	out, err := cmd.Output()
+	require.NoError(t, err)
+	assert.Equal(t, "exec terraform@1.7.5 -- terraform plan -out my plan\n", string(out))
+
+	cmd = exec.Command(shim, "version")
+	cmd.Env = []string{"PATH=" + dir + ":/usr/bin:/bin", "MISE_TERRAFORM_VERSION=1.5.0"}
+	out, err = cmd.Output()
+	require.NoError(t, err)
+	assert.Equal(t, "exec terraform@1.5.0 -- terraform version\n", string(out))
+}
+
+func TestShimStep(t *testing.T) {
+	fs := mocks.NewFileSystem()
+	ctx := compiler.NewRunContext(context.Background())
+	dir := ShimsDir()
+	work := &Config{Backend: "mise", Shims: true, Tools: []ToolConfig{
+		{Name: "terraform", Version: "1.7.5"},
+		{Name: "kubectl", Version: "1.29.0"},
+	}}
+
+	step := NewShimStep(work, fs)
+	assert.Equal(t, "runtime:shims", step.ID().String())
+	status, err := step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+
+	require.NoError(t, step.Apply(ctx))
+	content, err := fs.ReadFile(filepath.Join(dir, "kubectl"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "kubectl@${MISE_KUBECTL_VERSION:-1.29.0}")
+	status, err = step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, status)
+
+	// Switching to a target without kubectl removes its shim
+	oss := &Config{Backend: "mise", Shims: true, Tools: []ToolConfig{{Name: "terraform", Version: "1.8.0"}}}
+	step = NewShimStep(oss, fs)
+	status, err = step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+	diff, err := step.Plan(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, diff.NewValue(), "remove kubectl")
+
+	require.NoError(t, step.Apply(ctx))
+	assert.False(t, fs.Exists(filepath.Join(dir, "kubectl")))
+	content, err = fs.ReadFile(filepath.Join(dir, "terraform"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "1.8.0")
+
+	// Turning shims off removes them all
+	require.NoError(t, NewShimStep(&Config{Backend: "mise"}, fs).Apply(ctx))
+	assert.False(t, fs.Exists(filepath.Join(dir, "terraform")))
+}
+
+func TestProvider_Compile_WithShims(t *testing.T) {
+	p := NewProvider(mocks.NewFileSystem())
+	steps, err := p.Compile(compiler.NewCompileContext(map[string]interface{}{
+		"runtime": map[string]interface{}{
+			"shims": true,
+			"tools": []interface{}{map[string]interface{}{"name": "terraform", "version": "1.7.5"}},
+		},
+	}))
+	require.NoError(t, err)
+	require.Len(t, steps, 2)
+	assert.Equal(t, "runtime:shims", steps[1].ID().String())
+}
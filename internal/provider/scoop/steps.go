@@ -357,6 +357,11 @@ func (s *PackageStep) LockInfo() (compiler.LockInfo, bool) {
 	}, true
 }
 
+// UninstallCommand returns the command that uninstalls the package.
+func (s *PackageStep) UninstallCommand() []string {
+	return []string{s.scoopCommand(), "uninstall", s.pkg.Name}
+}
+
 // InstalledVersion returns the installed Scoop package version if available.
 func (s *PackageStep) InstalledVersion(ctx compiler.RunContext) (string, bool, error) {
 	cmd := s.scoopCommand()
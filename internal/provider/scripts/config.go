@@ -0,0 +1,99 @@
+// Package scripts provides the custom script provider. Each script declares
+// guards (creates, unless, check) so it only runs when needed.
+package scripts
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/provider/pathutil"
+)
+
+var scriptNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// Config represents the scripts section of the configuration.
+type Config struct {
+	Scripts []Script
+}
+
+// Script is a shell command or script file with idempotency guards.
+type Script struct {
+	Name string
+	// Run is an inline command; Script is a file relative to the config
+	// directory. Exactly one is set.
+	Run    string
+	Script string
+	Shell  string
+	// Creates is a path the script creates; the script is skipped when it
+	// exists and fails when it is still missing afterwards.
+	Creates string
+	// Unless is a command; the script is skipped when it succeeds.
+	Unless string
+	// Check is a command; the script is skipped when it succeeds and fails
+	// when it still does not succeed afterwards.
+	Check     string
+	DependsOn []compiler.StepID
+}
+
+// ParseConfig parses the scripts configuration from a raw map keyed by
+// script name. Relative script files are resolved against configRoot.
+func ParseConfig(raw map[string]interface{}, configRoot string) (*Config, error) {
+	names := make([]string, 0, len(raw))
+	for name := range raw {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	cfg := &Config{}
+	for _, name := range names {
+		m, ok := raw[name].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("script %q must be an object", name)
+		}
+		script := Script{Name: name, Shell: "sh"}
+		script.Run, _ = m["run"].(string)
+		script.Script, _ = m["script"].(string)
+		if shell, ok := m["shell"].(string); ok && shell != "" {
+			script.Shell = shell
+		}
+		script.Creates, _ = m["creates"].(string)
+		script.Unless, _ = m["unless"].(string)
+		script.Check, _ = m["check"].(string)
+		deps, _ := m["depends_on"].([]interface{})
+		for _, dep := range deps {
+			value, _ := dep.(string)
+			id, err := compiler.NewStepID(value)
+			if err != nil {
+				return nil, fmt.Errorf("script %q depends on invalid step %q: %w", name, value, err)
+			}
+			script.DependsOn = append(script.DependsOn, id)
+		}
+		if err := script.validate(); err != nil {
+			return nil, err
+		}
+		if script.Script != "" {
+			script.Script = pathutil.ExpandPath(script.Script)
+			if !filepath.IsAbs(script.Script) {
+				script.Script = filepath.Join(configRoot, script.Script)
+			}
+		}
+		cfg.Scripts = append(cfg.Scripts, script)
+	}
+	return cfg, nil
+}
+
+func (s Script) validate() error {
+	if !scriptNamePattern.MatchString(s.Name) {
+		return fmt.Errorf("invalid script name %q (use letters, digits, '.', '_' and '-')", s.Name)
+	}
+	if (s.Run == "") == (s.Script == "") {
+		return fmt.Errorf("script %q needs exactly one of run or script", s.Name)
+	}
+	if s.Creates == "" && s.Unless == "" && s.Check == "" {
+		return fmt.Errorf("script %q needs creates, unless or check so it only runs when needed", s.Name)
+	}
+	return nil
+}
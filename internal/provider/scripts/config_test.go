@@ -0,0 +1,51 @@
+package scripts
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfig(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := ParseConfig(map[string]interface{}{
+		"rustup": map[string]interface{}{
+			"run":        "curl -sSf https://sh.rustup.rs | sh -s -- -y",
+			"creates":    "~/.cargo/bin/rustup",
+			"depends_on": []interface{}{"brew:formula:curl"},
+		},
+		"defaults": map[string]interface{}{
+			"script": "scripts/defaults.sh",
+			"shell":  "bash",
+			"check":  "test -f ~/.defaults-done",
+		},
+	}, "/config")
+	require.NoError(t, err)
+	require.Len(t, cfg.Scripts, 2)
+
+	defaults, rustup := cfg.Scripts[0], cfg.Scripts[1]
+	assert.Equal(t, "defaults", defaults.Name)
+	assert.Equal(t, filepath.Join("/config", "scripts/defaults.sh"), defaults.Script)
+	assert.Equal(t, "bash", defaults.Shell)
+	assert.Equal(t, "sh", rustup.Shell)
+	assert.Equal(t, "brew:formula:curl", rustup.DependsOn[0].String())
+}
+
+func TestParseConfig_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for name, raw := range map[string]map[string]interface{}{
+		"not an object":  {"a": "echo hi"},
+		"bad name":       {"my script": map[string]interface{}{"run": "true", "unless": "true"}},
+		"no command":     {"a": map[string]interface{}{"unless": "true"}},
+		"run and script": {"a": map[string]interface{}{"run": "true", "script": "a.sh", "unless": "true"}},
+		"no guard":       {"a": map[string]interface{}{"run": "true"}},
+		"bad dependency": {"a": map[string]interface{}{"run": "true", "unless": "true", "depends_on": []interface{}{"::"}}},
+	} {
+		_, err := ParseConfig(raw, "/config")
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,43 @@
+package scripts
+
+import (
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+)
+
+// Provider implements the compiler.Provider interface for custom scripts.
+type Provider struct {
+	runner ports.CommandRunner
+}
+
+// NewProvider creates a new scripts provider.
+func NewProvider(runner ports.CommandRunner) *Provider {
+	return &Provider{runner: runner}
+}
+
+// Name returns the provider name.
+func (p *Provider) Name() string {
+	return "scripts"
+}
+
+// Compile transforms scripts configuration into executable steps.
+func (p *Provider) Compile(ctx compiler.CompileContext) ([]compiler.Step, error) {
+	rawConfig := ctx.GetSection("scripts")
+	if rawConfig == nil {
+		return nil, nil
+	}
+
+	cfg, err := ParseConfig(rawConfig, ctx.ConfigRoot())
+	if err != nil {
+		return nil, err
+	}
+
+	steps := make([]compiler.Step, 0, len(cfg.Scripts))
+	for _, script := range cfg.Scripts {
+		steps = append(steps, NewScriptStep(script, p.runner))
+	}
+	return steps, nil
+}
+
+// Ensure Provider implements compiler.Provider.
+var _ compiler.Provider = (*Provider)(nil)
@@ -0,0 +1,36 @@
+package scripts
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/testutil/mocks"
+)
+
+func TestProvider_Compile(t *testing.T) {
+	t.Parallel()
+
+	provider := NewProvider(mocks.NewCommandRunner())
+	assert.Equal(t, "scripts", provider.Name())
+
+	steps, err := provider.Compile(compiler.NewCompileContext(map[string]interface{}{}))
+	require.NoError(t, err)
+	assert.Empty(t, steps)
+
+	steps, err = provider.Compile(compiler.NewCompileContext(map[string]interface{}{
+		"scripts": map[string]interface{}{
+			"rustup": map[string]interface{}{
+				"run":        "curl -sSf https://sh.rustup.rs | sh -s -- -y",
+				"creates":    "~/.cargo/bin/rustup",
+				"depends_on": []interface{}{"brew:formula:curl"},
+			},
+		},
+	}))
+	require.NoError(t, err)
+	require.Len(t, steps, 1)
+	assert.Equal(t, "scripts:rustup", steps[0].ID().String())
+	assert.Equal(t, []compiler.StepID{compiler.MustNewStepID("brew:formula:curl")}, steps[0].DependsOn())
+}
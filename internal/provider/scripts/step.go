@@ -0,0 +1,174 @@
+package scripts
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/provider/pathutil"
+)
+
+// ScriptStep runs a script when none of its guards is satisfied.
+type ScriptStep struct {
+	script Script
+	id     compiler.StepID
+	runner ports.CommandRunner
+}
+
+// NewScriptStep creates a step running script.
+func NewScriptStep(script Script, runner ports.CommandRunner) *ScriptStep {
+	return &ScriptStep{
+		script: script,
+		id:     compiler.MustNewStepID("scripts:" + script.Name),
+		runner: runner,
+	}
+}
+
+// ID returns the step identifier.
+func (s *ScriptStep) ID() compiler.StepID {
+	return s.id
+}
+
+// DependsOn returns the step dependencies.
+func (s *ScriptStep) DependsOn() []compiler.StepID {
+	return s.script.DependsOn
+}
+
+// Check evaluates the guards. The script is satisfied when its creates
+// path exists, or its unless or check command succeeds.
+func (s *ScriptStep) Check(ctx compiler.RunContext) (compiler.StepStatus, error) {
+	satisfied, _, err := s.evaluate(ctx)
+	if err != nil {
+		return compiler.StatusUnknown, err
+	}
+	if satisfied {
+		return compiler.StatusSatisfied, nil
+	}
+	return compiler.StatusNeedsApply, nil
+}
+
+// Plan returns the diff for this step, including what each guard found.
+func (s *ScriptStep) Plan(ctx compiler.RunContext) (compiler.Diff, error) {
+	_, outcome, err := s.evaluate(ctx)
+	if err != nil {
+		return compiler.Diff{}, err
+	}
+	return compiler.NewDiff(compiler.DiffTypeAdd, "script", s.script.Name, "", outcome), nil
+}
+
+// Apply runs the script, then verifies that it created its creates path
+// and that its check command now succeeds.
+func (s *ScriptStep) Apply(ctx compiler.RunContext) error {
+	var result ports.CommandResult
+	var err error
+	if s.script.Script != "" {
+		result, err = s.runner.Run(ctx.Context(), s.script.Shell, s.script.Script)
+	} else {
+		result, err = s.shell(ctx, s.script.Run)
+	}
+	if err != nil {
+		return err
+	}
+	if !result.Success() {
+		return fmt.Errorf("script %s failed with exit code %d: %s", s.script.Name, result.ExitCode, strings.TrimSpace(result.Stderr))
+	}
+
+	if s.script.Creates != "" {
+		if _, err := os.Stat(pathutil.ExpandPath(s.script.Creates)); err != nil {
+			return fmt.Errorf("script %s ran but did not create %s", s.script.Name, s.script.Creates)
+		}
+	}
+	if s.script.Check != "" {
+		result, err := s.shell(ctx, s.script.Check)
+		if err != nil {
+			return err
+		}
+		if !result.Success() {
+			return fmt.Errorf("script %s ran but its check still fails with exit code %d", s.script.Name, result.ExitCode)
+		}
+	}
+	return nil
+}
+
+// Explain provides a human-readable explanation.
+func (s *ScriptStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
+	what := s.script.Run
+	if s.script.Script != "" {
+		what = filepath.Base(s.script.Script)
+	}
+	return compiler.NewExplanation(
+		"Run Script",
+		fmt.Sprintf("Runs %q with %s unless %s", what, s.script.Shell, strings.Join(s.guards(), " or ")),
+		nil,
+	).WithTradeoffs([]string{
+		"+ Covers setup no provider handles",
+		"+ Guards keep repeated applies from running it again",
+		"- preflight cannot undo what the script changed",
+	})
+}
+
+// evaluate runs the guards in order and reports whether one is satisfied
+// and what each found.
+func (s *ScriptStep) evaluate(ctx compiler.RunContext) (bool, string, error) {
+	var findings []string
+	satisfied := false
+	if s.script.Creates != "" {
+		if _, err := os.Stat(pathutil.ExpandPath(s.script.Creates)); err == nil {
+			satisfied = true
+			findings = append(findings, fmt.Sprintf("creates %s: exists", s.script.Creates))
+		} else {
+			findings = append(findings, fmt.Sprintf("creates %s: missing", s.script.Creates))
+		}
+	}
+	for _, guard := range []struct{ name, command string }{
+		{"unless", s.script.Unless},
+		{"check", s.script.Check},
+	} {
+		if guard.command == "" || satisfied {
+			continue
+		}
+		result, err := s.shell(ctx, guard.command)
+		if err != nil {
+			return false, "", err
+		}
+		if result.Success() {
+			satisfied = true
+			findings = append(findings, fmt.Sprintf("%s %q: succeeded", guard.name, guard.command))
+		} else {
+			findings = append(findings, fmt.Sprintf("%s %q: exit %d", guard.name, guard.command, result.ExitCode))
+		}
+	}
+	return satisfied, strings.Join(findings, "; "), nil
+}
+
+// guards describes the declared guards for explanations.
+func (s *ScriptStep) guards() []string {
+	var guards []string
+	if s.script.Creates != "" {
+		guards = append(guards, s.script.Creates+" exists")
+	}
+	if s.script.Unless != "" {
+		guards = append(guards, fmt.Sprintf("%q succeeds", s.script.Unless))
+	}
+	if s.script.Check != "" {
+		guards = append(guards, fmt.Sprintf("%q succeeds", s.script.Check))
+	}
+	return guards
+}
+
+// shell runs command with the script's shell.
+func (s *ScriptStep) shell(ctx compiler.RunContext, command string) (ports.CommandResult, error) {
+	flag := "-c"
+	switch strings.TrimSuffix(filepath.Base(s.script.Shell), ".exe") {
+	case "pwsh", "powershell":
+		flag = "-Command"
+	case "cmd":
+		flag = "/C"
+	}
+	return s.runner.Run(ctx.Context(), s.script.Shell, flag, command)
+}
+
+var _ compiler.Step = (*ScriptStep)(nil)
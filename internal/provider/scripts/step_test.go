@@ -0,0 +1,73 @@
+package scripts
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/testutil/mocks"
+)
+
+func TestScriptStep_Check(t *testing.T) {
+	t.Parallel()
+
+	ctx := compiler.NewRunContext(context.Background())
+	created := filepath.Join(t.TempDir(), "done")
+	runner := mocks.NewCommandRunner()
+	runner.AddResult("sh", []string{"-c", "command -v rustup"}, ports.CommandResult{ExitCode: 1})
+	runner.AddResult("sh", []string{"-c", "rustup --version"}, ports.CommandResult{})
+
+	step := NewScriptStep(Script{Name: "rustup", Run: "install", Shell: "sh", Creates: created, Unless: "command -v rustup"}, runner)
+	assert.Equal(t, "scripts:rustup", step.ID().String())
+	status, err := step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+
+	diff, err := step.Plan(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, `creates `+created+`: missing; unless "command -v rustup": exit 1`, diff.NewValue())
+
+	withCheck := NewScriptStep(Script{Name: "rustup", Run: "install", Shell: "sh", Unless: "command -v rustup", Check: "rustup --version"}, runner)
+	status, err = withCheck.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, status)
+
+	require.NoError(t, os.WriteFile(created, nil, 0o644))
+	status, err = step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, status)
+}
+
+func TestScriptStep_Apply(t *testing.T) {
+	t.Parallel()
+
+	ctx := compiler.NewRunContext(context.Background())
+	runner := mocks.NewCommandRunner()
+	runner.AddResult("bash", []string{"/config/setup.sh"}, ports.CommandResult{})
+	runner.AddResult("bash", []string{"-c", "test -f ~/.setup-done"}, ports.CommandResult{ExitCode: 1})
+
+	step := NewScriptStep(Script{Name: "setup", Script: "/config/setup.sh", Shell: "bash", Check: "test -f ~/.setup-done"}, runner)
+	err := step.Apply(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "check still fails")
+	assert.Equal(t, "bash", runner.Calls()[0].Command)
+	assert.Equal(t, []string{"/config/setup.sh"}, runner.Calls()[0].Args)
+
+	failing := mocks.NewCommandRunner()
+	failing.AddResult("pwsh", []string{"-Command", "exit 3"}, ports.CommandResult{ExitCode: 3, Stderr: "boom"})
+	err = NewScriptStep(Script{Name: "win", Run: "exit 3", Shell: "pwsh", Unless: "$false"}, failing).Apply(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exit code 3: boom")
+
+	missing := mocks.NewCommandRunner()
+	missing.AddResult("sh", []string{"-c", "true"}, ports.CommandResult{})
+	err = NewScriptStep(Script{Name: "noop", Run: "true", Shell: "sh", Creates: filepath.Join(t.TempDir(), "never")}, missing).Apply(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "did not create")
+}
@@ -0,0 +1,14 @@
+// Package secretutil provides helpers for providers that resolve
+// credentials from secret references with 'preflight secrets get'.
+package secretutil
+
+import "os"
+
+// Command returns the preflight binary used to resolve secrets: the
+// running executable, or "preflight" from PATH when it cannot be found.
+func Command() string {
+	if exe, err := os.Executable(); err == nil {
+		return exe
+	}
+	return "preflight"
+}
@@ -0,0 +1,14 @@
+package secretutil
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommand(t *testing.T) {
+	exe, err := os.Executable()
+	require.NoError(t, err)
+	require.Equal(t, exe, Command())
+}
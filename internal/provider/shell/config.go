@@ -7,11 +7,12 @@ import (
 
 // Config represents the shell configuration section.
 type Config struct {
-	Default  string            `yaml:"default,omitempty"`
-	Shells   []Entry           `yaml:"shells,omitempty"`
-	Starship StarshipConfig    `yaml:"starship,omitempty"`
-	Env      map[string]string `yaml:"env,omitempty"`
-	Aliases  map[string]string `yaml:"aliases,omitempty"`
+	Default   string            `yaml:"default,omitempty"`
+	Shells    []Entry           `yaml:"shells,omitempty"`
+	Starship  StarshipConfig    `yaml:"starship,omitempty"`
+	Env       map[string]string `yaml:"env,omitempty"`
+	Aliases   map[string]string `yaml:"aliases,omitempty"`
+	Functions map[string]string `yaml:"functions,omitempty"`
 }
 
 // Entry represents configuration for a single shell.
@@ -24,15 +25,18 @@ type Entry struct {
 }
 
 // CustomPlugin represents a custom plugin to install from a git repository.
+// Rev optionally pins the checkout to a commit, tag or branch.
 type CustomPlugin struct {
 	Name string `yaml:"name"`
 	Repo string `yaml:"repo"`
+	Rev  string `yaml:"rev,omitempty"`
 }
 
 // StarshipConfig represents starship prompt configuration.
 type StarshipConfig struct {
-	Enabled bool   `yaml:"enabled,omitempty"`
-	Preset  string `yaml:"preset,omitempty"`
+	Enabled      bool   `yaml:"enabled,omitempty"`
+	Preset       string `yaml:"preset,omitempty"`
+	ConfigSource string `yaml:"config_source,omitempty"` // starship.toml to manage, relative to the config root
 }
 
 // ConfigPath returns the path to the shell's configuration file.
@@ -69,6 +73,9 @@ func ParseConfig(raw map[string]interface{}) (*Config, error) {
 	if cfg.Aliases == nil {
 		cfg.Aliases = make(map[string]string)
 	}
+	if cfg.Functions == nil {
+		cfg.Functions = make(map[string]string)
+	}
 
 	return &cfg, nil
 }
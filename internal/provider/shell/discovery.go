@@ -0,0 +1,286 @@
+package shell
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/ports"
+)
+
+// Discovery is the shell setup found on a machine: frameworks and plugins
+// per shell, prompt configuration, and user-defined aliases and functions.
+type Discovery struct {
+	Shells    []Entry
+	Aliases   map[string]string
+	Functions map[string]string
+	// StarshipConfig is the path of starship.toml, if present.
+	StarshipConfig string
+}
+
+var (
+	zshThemeRe    = regexp.MustCompile(`(?m)^ZSH_THEME=["']?([^"'\s]*)["']?\s*$`)
+	zinitIceVerRe = regexp.MustCompile(`\bver["']([^"']+)["']`)
+	aliasRe       = regexp.MustCompile(`^alias\s+([A-Za-z0-9_.:-]+)=(.*)$`)
+	funcStartRe   = regexp.MustCompile(`^(?:function\s+)?([A-Za-z_][A-Za-z0-9_:.-]*)\s*\(\)\s*\{\s*$`)
+	fishFuncRe    = regexp.MustCompile(`^function\s+([A-Za-z_][A-Za-z0-9_:.-]*)\b`)
+)
+
+// Discover inspects the shell configuration under homeDir. The runner, when
+// set, records the origin and checked-out commit of custom plugins so they
+// can be pinned.
+func Discover(ctx context.Context, homeDir string, runner ports.CommandRunner) *Discovery {
+	d := &Discovery{
+		Aliases:   make(map[string]string),
+		Functions: make(map[string]string),
+	}
+
+	if content, ok := readFile(filepath.Join(homeDir, ".zshrc")); ok {
+		d.Shells = append(d.Shells, discoverZsh(ctx, homeDir, content, runner))
+		d.addDefinitions("zsh", content)
+	}
+
+	bashFound := false
+	for _, name := range []string{".bashrc", ".bash_profile"} {
+		if content, ok := readFile(filepath.Join(homeDir, name)); ok {
+			bashFound = true
+			d.addDefinitions("bash", content)
+		}
+	}
+	if bashFound {
+		d.Shells = append(d.Shells, Entry{Name: "bash"})
+	}
+
+	if content, ok := readFile(filepath.Join(homeDir, ".config", "fish", "config.fish")); ok {
+		d.Shells = append(d.Shells, discoverFish(homeDir))
+		d.addDefinitions("fish", content)
+	}
+
+	starship := filepath.Join(homeDir, ".config", "starship.toml")
+	if _, err := os.Stat(starship); err == nil {
+		d.StarshipConfig = starship
+	}
+
+	return d
+}
+
+func discoverZsh(ctx context.Context, homeDir, content string, runner ports.CommandRunner) Entry {
+	entry := Entry{Name: "zsh"}
+
+	switch {
+	case strings.Contains(content, "oh-my-zsh.sh"):
+		entry.Framework = "oh-my-zsh"
+		if m := zshThemeRe.FindStringSubmatch(content); m != nil {
+			entry.Theme = m[1]
+		}
+		if m := pluginsLineRe.FindStringSubmatch(content); m != nil {
+			entry.Plugins = strings.Fields(m[1])
+		}
+		entry.CustomPlugins = discoverCustomPlugins(ctx, filepath.Join(homeDir, ".oh-my-zsh", "custom", "plugins"), runner)
+	case strings.Contains(content, "zinit"):
+		entry.Framework = "zinit"
+		entry.Plugins = parseZinitPlugins(content)
+	}
+	return entry
+}
+
+func discoverFish(homeDir string) Entry {
+	entry := Entry{Name: "fish"}
+	if _, err := os.Stat(filepath.Join(homeDir, ".config", "fish", "functions", "fisher.fish")); err != nil {
+		return entry
+	}
+	entry.Framework = "fisher"
+	if content, ok := readFile(filepath.Join(homeDir, ".config", "fish", "fish_plugins")); ok {
+		for _, line := range strings.Split(content, "\n") {
+			line = strings.TrimSpace(line)
+			// fisher manages itself; the framework step installs it
+			if line == "" || strings.HasPrefix(line, "#") || line == "jorgebucaran/fisher" {
+				continue
+			}
+			entry.Plugins = append(entry.Plugins, line)
+		}
+	}
+	return entry
+}
+
+// parseZinitPlugins returns the plugins loaded with 'zinit light' or
+// 'zinit load', as owner/repo@rev when a preceding ice pins a version.
+func parseZinitPlugins(content string) []string {
+	var plugins []string
+	rev := ""
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || fields[0] != "zinit" {
+			continue
+		}
+		switch fields[1] {
+		case "ice":
+			if m := zinitIceVerRe.FindStringSubmatch(line); m != nil {
+				rev = m[1]
+			}
+		case "light", "load":
+			plugin := fields[2]
+			if rev != "" {
+				plugin += "@" + rev
+			}
+			plugins = append(plugins, plugin)
+			rev = ""
+		}
+	}
+	return plugins
+}
+
+// discoverCustomPlugins returns the git checkouts in dir. The runner, when
+// set, fills in each plugin's origin URL and checked-out commit.
+func discoverCustomPlugins(ctx context.Context, dir string, runner ports.CommandRunner) []CustomPlugin {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var plugins []CustomPlugin
+	for _, e := range entries {
+		path := filepath.Join(dir, e.Name())
+		if !e.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(path, ".git")); err != nil {
+			continue
+		}
+		plugin := CustomPlugin{Name: e.Name()}
+		if runner != nil {
+			plugin.Repo = gitOutput(ctx, runner, path, "config", "--get", "remote.origin.url")
+			plugin.Rev = gitOutput(ctx, runner, path, "rev-parse", "HEAD")
+		}
+		plugins = append(plugins, plugin)
+	}
+	return plugins
+}
+
+func gitOutput(ctx context.Context, runner ports.CommandRunner, dir string, args ...string) string {
+	result, err := runner.Run(ctx, "git", append([]string{"-C", dir}, args...)...)
+	if err != nil || !result.Success() {
+		return ""
+	}
+	return strings.TrimSpace(result.Stdout)
+}
+
+// addDefinitions records the aliases and functions a shell config defines
+// outside preflight's own managed blocks.
+func (d *Discovery) addDefinitions(shell, content string) {
+	content = stripManagedBlocks(content, "aliases", "functions")
+	for name, value := range parseAliases(content) {
+		d.Aliases[name] = value
+	}
+	for name, body := range parseFunctions(shell, content) {
+		d.Functions[name] = body
+	}
+}
+
+// parseAliases returns the aliases defined with alias name=value.
+func parseAliases(content string) map[string]string {
+	aliases := make(map[string]string)
+	for _, line := range strings.Split(content, "\n") {
+		m := aliasRe.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		aliases[m[1]] = unquoteShell(m[2])
+	}
+	return aliases
+}
+
+// unquoteShell removes one level of matching single or double quotes.
+func unquoteShell(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 {
+		switch {
+		case s[0] == '\'' && s[len(s)-1] == '\'':
+			return s[1 : len(s)-1]
+		case s[0] == '"' && s[len(s)-1] == '"':
+			return strings.ReplaceAll(s[1:len(s)-1], `\"`, `"`)
+		}
+	}
+	return s
+}
+
+// parseFunctions returns top-level function definitions. Bodies are read up
+// to the closing '}' (or 'end' for fish) at the start of a line and have the
+// indentation of their first line removed.
+func parseFunctions(shell, content string) map[string]string {
+	functions := make(map[string]string)
+	lines := strings.Split(content, "\n")
+	for i := 0; i < len(lines); i++ {
+		name, end := "", "}"
+		if shell == "fish" {
+			if m := fishFuncRe.FindStringSubmatch(lines[i]); m != nil {
+				name, end = m[1], "end"
+			}
+		} else if m := funcStartRe.FindStringSubmatch(lines[i]); m != nil {
+			name = m[1]
+		}
+		if name == "" {
+			continue
+		}
+
+		var body []string
+		j := i + 1
+		for ; j < len(lines) && strings.TrimRight(lines[j], " \t") != end; j++ {
+			body = append(body, lines[j])
+		}
+		if j == len(lines) {
+			break // unterminated definition
+		}
+		functions[name] = dedent(body)
+		i = j
+	}
+	return functions
+}
+
+// dedent removes the leading whitespace of the first non-empty line from
+// every line.
+func dedent(lines []string) string {
+	indent := ""
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			indent = line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+			break
+		}
+	}
+	out := make([]string, 0, len(lines))
+	for _, line := range lines {
+		out = append(out, strings.TrimPrefix(line, indent))
+	}
+	return strings.Join(out, "\n")
+}
+
+// stripManagedBlocks removes preflight managed blocks so discovery only
+// reports what the user wrote by hand.
+func stripManagedBlocks(content string, sections ...string) string {
+	for _, section := range sections {
+		start := strings.Index(content, fmt.Sprintf(blockStartFmt, section))
+		if start == -1 {
+			continue
+		}
+		endMarker := fmt.Sprintf(blockEndFmt, section)
+		end := strings.Index(content[start:], endMarker)
+		if end == -1 {
+			content = content[:start]
+			continue
+		}
+		content = content[:start] + content[start+end+len(endMarker):]
+	}
+	return content
+}
+
+func readFile(path string) (string, bool) {
+	// #nosec G304 -- path is a well-known shell config file under the home directory.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
@@ -0,0 +1,103 @@
+package shell
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/testutil/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseZinitPlugins(t *testing.T) {
+	t.Parallel()
+
+	content := `source "$HOME/.local/share/zinit/zinit.git/zinit.zsh"
+zinit ice ver"v0.7.0"
+zinit light zsh-users/zsh-autosuggestions
+zinit load zdharma-continuum/fast-syntax-highlighting
+`
+	assert.Equal(t, []string{
+		"zsh-users/zsh-autosuggestions@v0.7.0",
+		"zdharma-continuum/fast-syntax-highlighting",
+	}, parseZinitPlugins(content))
+}
+
+func TestParseAliasesAndFunctions(t *testing.T) {
+	t.Parallel()
+
+	content := `alias ll='ls -la'
+alias gs="git status"
+# >>> preflight aliases >>>
+alias managed="echo"
+# <<< preflight aliases <<<
+mkcd() {
+  mkdir -p "$1"
+  cd "$1"
+}
+function greet {
+	echo hi
+}
+`
+	stripped := stripManagedBlocks(content, "aliases")
+	assert.Equal(t, map[string]string{"ll": "ls -la", "gs": "git status"}, parseAliases(stripped))
+	assert.Equal(t, map[string]string{"mkcd": "mkdir -p \"$1\"\ncd \"$1\""}, parseFunctions("zsh", stripped))
+
+	fish := "function mkcd\n    mkdir -p $argv[1]\nend\n"
+	assert.Equal(t, map[string]string{"mkcd": "mkdir -p $argv[1]"}, parseFunctions("fish", fish))
+}
+
+func TestGenerateFunctionsBlock_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	functions := map[string]string{"mkcd": "mkdir -p \"$1\"\ncd \"$1\"", "up": "cd .."}
+	for _, shell := range []string{"zsh", "fish"} {
+		assert.Equal(t, functions, parseFunctions(shell, generateFunctionsBlock(shell, functions)), shell)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	t.Parallel()
+
+	home := t.TempDir()
+	write := func(rel, content string) {
+		path := filepath.Join(home, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	write(".zshrc", `export ZSH="$HOME/.oh-my-zsh"
+ZSH_THEME="agnoster"
+plugins=(git docker)
+source $ZSH/oh-my-zsh.sh
+alias k=kubectl
+`)
+	write(".oh-my-zsh/custom/plugins/zsh-autosuggestions/.git/HEAD", "ref: refs/heads/master\n")
+	write(".config/fish/config.fish", "alias g=git\n")
+	write(".config/fish/functions/fisher.fish", "")
+	write(".config/fish/fish_plugins", "jorgebucaran/fisher\nPatrickF1/fzf.fish@v9.0\n")
+	write(".config/starship.toml", "")
+
+	pluginPath := filepath.Join(home, ".oh-my-zsh", "custom", "plugins", "zsh-autosuggestions")
+	runner := mocks.NewCommandRunner()
+	runner.AddResult("git", []string{"-C", pluginPath, "config", "--get", "remote.origin.url"}, ports.CommandResult{Stdout: "https://github.com/zsh-users/zsh-autosuggestions\n"})
+	runner.AddResult("git", []string{"-C", pluginPath, "rev-parse", "HEAD"}, ports.CommandResult{Stdout: "abc123\n"})
+
+	d := Discover(context.Background(), home, runner)
+
+	require.Len(t, d.Shells, 2)
+	assert.Equal(t, Entry{
+		Name:      "zsh",
+		Framework: "oh-my-zsh",
+		Theme:     "agnoster",
+		Plugins:   []string{"git", "docker"},
+		CustomPlugins: []CustomPlugin{
+			{Name: "zsh-autosuggestions", Repo: "https://github.com/zsh-users/zsh-autosuggestions", Rev: "abc123"},
+		},
+	}, d.Shells[0])
+	assert.Equal(t, Entry{Name: "fish", Framework: "fisher", Plugins: []string{"PatrickF1/fzf.fish@v9.0"}}, d.Shells[1])
+	assert.Equal(t, map[string]string{"k": "kubectl", "g": "git"}, d.Aliases)
+	assert.Equal(t, filepath.Join(home, ".config", "starship.toml"), d.StarshipConfig)
+}
@@ -114,6 +114,66 @@ func generateAliasBlock(aliases map[string]string) string {
 	return b.String()
 }
 
+// zinitHome is where zinit is cloned, following its installer's default.
+const zinitHome = "~/.local/share/zinit/zinit.git"
+
+// generateZinitBlock produces the content for a managed zinit block. Plugins
+// load in declaration order; owner/repo@rev is pinned with a ver ice.
+func generateZinitBlock(plugins []string) string {
+	var b strings.Builder
+	b.WriteString(`source "$HOME/.local/share/zinit/zinit.git/zinit.zsh"` + "\n")
+	for _, plugin := range plugins {
+		repo, rev := splitPluginRev(plugin)
+		if rev != "" {
+			fmt.Fprintf(&b, "zinit ice ver%q\n", rev)
+		}
+		fmt.Fprintf(&b, "zinit light %s\n", repo)
+	}
+	return b.String()
+}
+
+// splitPluginRev splits a plugin spec of the form owner/repo@rev.
+func splitPluginRev(plugin string) (repo, rev string) {
+	repo, rev, _ = strings.Cut(plugin, "@")
+	return repo, rev
+}
+
+// generateFunctionsBlock produces the content for a managed functions block
+// in the syntax of the given shell.
+func generateFunctionsBlock(shell string, functions map[string]string) string {
+	if len(functions) == 0 {
+		return ""
+	}
+
+	// Sort names for deterministic output
+	names := make([]string, 0, len(functions))
+	for name := range functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		if shell == "fish" {
+			fmt.Fprintf(&b, "function %s\n", name)
+		} else {
+			fmt.Fprintf(&b, "%s() {\n", name)
+		}
+		for _, line := range strings.Split(strings.TrimRight(functions[name], "\n"), "\n") {
+			if line != "" {
+				b.WriteString("  " + line)
+			}
+			b.WriteString("\n")
+		}
+		if shell == "fish" {
+			b.WriteString("end\n")
+		} else {
+			b.WriteString("}\n")
+		}
+	}
+	return b.String()
+}
+
 // pluginsLineRe matches the plugins=(...) line in .zshrc.
 var pluginsLineRe = regexp.MustCompile(`(?m)^plugins=\(([^)]*)\)\s*$`)
 
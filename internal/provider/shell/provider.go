@@ -1,6 +1,9 @@
 package shell
 
 import (
+	"path/filepath"
+	"strings"
+
 	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
 	"github.com/felixgeelhaar/preflight/internal/ports"
 )
@@ -39,11 +42,11 @@ func (p *Provider) Compile(ctx compiler.CompileContext) ([]compiler.Step, error)
 	}
 
 	// Check if there's any actual configuration
-	if len(cfg.Shells) == 0 && !cfg.Starship.Enabled && len(cfg.Env) == 0 && len(cfg.Aliases) == 0 {
+	if len(cfg.Shells) == 0 && !cfg.Starship.Enabled && len(cfg.Env) == 0 && len(cfg.Aliases) == 0 && len(cfg.Functions) == 0 {
 		return nil, nil
 	}
 
-	// Estimate capacity: frameworks + plugins + custom plugins + starship + env + aliases
+	// Estimate capacity: frameworks + plugins + custom plugins + starship + env + aliases + functions
 	capacity := len(cfg.Shells) * 3 // rough estimate
 	if cfg.Starship.Enabled {
 		capacity++
@@ -54,6 +57,9 @@ func (p *Provider) Compile(ctx compiler.CompileContext) ([]compiler.Step, error)
 	if len(cfg.Aliases) > 0 {
 		capacity++
 	}
+	if len(cfg.Functions) > 0 {
+		capacity++
+	}
 	steps := make([]compiler.Step, 0, capacity)
 
 	// Add framework and plugin steps for each shell
@@ -63,12 +69,18 @@ func (p *Provider) Compile(ctx compiler.CompileContext) ([]compiler.Step, error)
 			steps = append(steps, NewFrameworkStepWith(shell, p.fs, p.runner))
 
 			// Add plugin steps based on framework
-			if shell.Framework == "fisher" {
-				// Fisher plugins
+			switch shell.Framework {
+			case "fisher":
+				// Fisher plugins (owner/repo[@ref])
 				for _, plugin := range shell.Plugins {
 					steps = append(steps, NewFisherPluginStepWith(plugin, p.fs, p.runner))
 				}
-			} else {
+			case "zinit":
+				// Zinit loads all plugins from one managed block
+				if len(shell.Plugins) > 0 {
+					steps = append(steps, NewZinitStepWithFS(shell.Name, shell.Plugins, p.fs))
+				}
+			default:
 				// Standard plugins (oh-my-zsh, etc.)
 				for _, plugin := range shell.Plugins {
 					steps = append(steps, NewPluginStepWithFS(shell.Name, shell.Framework, plugin, p.fs))
@@ -84,7 +96,8 @@ func (p *Provider) Compile(ctx compiler.CompileContext) ([]compiler.Step, error)
 
 	// Add starship step if enabled
 	if cfg.Starship.Enabled {
-		steps = append(steps, NewStarshipStepWithFS(cfg.Starship, p.fs))
+		source := p.resolveStarshipSource(cfg.Starship.ConfigSource, ctx.ConfigRoot())
+		steps = append(steps, NewStarshipStepWithSource(cfg.Starship, source, p.fs))
 	}
 
 	// Add env step if there are environment variables
@@ -99,8 +112,27 @@ func (p *Provider) Compile(ctx compiler.CompileContext) ([]compiler.Step, error)
 		steps = append(steps, NewAliasStepWithFS(cfg.Shells[0].Name, cfg.Aliases, p.fs))
 	}
 
+	// Add functions step if there are functions
+	if len(cfg.Functions) > 0 && len(cfg.Shells) > 0 {
+		// Use the first shell's name for functions step
+		steps = append(steps, NewFunctionsStepWithFS(cfg.Shells[0].Name, cfg.Functions, p.fs))
+	}
+
 	return steps, nil
 }
 
+// resolveStarshipSource resolves starship.config_source against the config
+// root. Returns empty string if unset, missing, or escaping the root.
+func (p *Provider) resolveStarshipSource(configSource, configRoot string) string {
+	if configSource == "" || configRoot == "" || strings.Contains(configSource, "..") {
+		return ""
+	}
+	path := filepath.Join(configRoot, configSource)
+	if !ports.IsPathWithinRoot(configRoot, path) || !p.fs.Exists(path) {
+		return ""
+	}
+	return path
+}
+
 // Ensure Provider implements compiler.Provider.
 var _ compiler.Provider = (*Provider)(nil)
@@ -1,6 +1,7 @@
 package shell_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
@@ -202,3 +203,60 @@ func TestProvider_Compile_FishWithFisher(t *testing.T) {
 	require.Len(t, steps, 2)
 	assert.Equal(t, "shell:framework:fish:fisher", steps[0].ID().String())
 }
+
+func TestProvider_Compile_ZinitFunctionsAndStarshipSource(t *testing.T) {
+	t.Parallel()
+
+	fs := mocks.NewFileSystem()
+	fs.AddDir("/dotfiles/starship")
+	fs.AddFile("/dotfiles/starship/starship.toml", "add_newline = false\n")
+	p := shell.NewProvider(fs)
+	ctx := compiler.NewCompileContext(map[string]interface{}{
+		"shell": map[string]interface{}{
+			"shells": []interface{}{
+				map[string]interface{}{
+					"name":      "zsh",
+					"framework": "zinit",
+					"plugins":   []interface{}{"zsh-users/zsh-autosuggestions@v0.7.0"},
+				},
+			},
+			"starship": map[string]interface{}{
+				"enabled":       true,
+				"config_source": "starship",
+			},
+			"functions": map[string]interface{}{"mkcd": "mkdir -p \"$1\" && cd \"$1\""},
+		},
+	}).WithConfigRoot("/dotfiles")
+
+	steps, err := p.Compile(ctx)
+	require.NoError(t, err)
+
+	ids := make([]string, 0, len(steps))
+	for _, step := range steps {
+		ids = append(ids, step.ID().String())
+	}
+	assert.Equal(t, []string{"shell:framework:zsh:zinit", "shell:zinit:zsh", "shell:starship", "shell:functions:zsh"}, ids)
+
+	diff, err := steps[2].Plan(compiler.NewRunContext(context.TODO()))
+	require.NoError(t, err)
+	assert.Contains(t, diff.Summary(), "/dotfiles/starship/starship.toml")
+}
+
+func TestProvider_Compile_StarshipSourceTraversal(t *testing.T) {
+	t.Parallel()
+
+	p := shell.NewProvider(mocks.NewFileSystem())
+	ctx := compiler.NewCompileContext(map[string]interface{}{
+		"shell": map[string]interface{}{
+			"starship": map[string]interface{}{"enabled": true, "config_source": "../outside"},
+		},
+	}).WithConfigRoot("/dotfiles")
+
+	steps, err := p.Compile(ctx)
+	require.NoError(t, err)
+	require.Len(t, steps, 1)
+
+	diff, err := steps[0].Plan(compiler.NewRunContext(context.TODO()))
+	require.NoError(t, err)
+	assert.NotContains(t, diff.Summary(), "outside")
+}
@@ -1,6 +1,7 @@
 package shell
 
 import (
+	"bytes"
 	"fmt"
 	"path/filepath"
 	"strings"
@@ -11,7 +12,10 @@ import (
 	"github.com/felixgeelhaar/preflight/internal/ports"
 )
 
-// FrameworkStep manages shell framework installation (oh-my-zsh, fisher, etc.).
+// zinitRepo is the repository zinit is cloned from.
+const zinitRepo = "https://github.com/zdharma-continuum/zinit.git"
+
+// FrameworkStep manages shell framework installation (oh-my-zsh, zinit, fisher, etc.).
 type FrameworkStep struct {
 	config Entry
 	id     compiler.StepID
@@ -88,6 +92,14 @@ func (s *FrameworkStep) Apply(ctx compiler.RunContext) error {
 		if !result.Success() {
 			return fmt.Errorf("oh-my-zsh install failed: %s", result.Stderr)
 		}
+	case "zinit":
+		result, err := s.runner.Run(ctx.Context(), "git", "clone", "--depth", "1", zinitRepo, s.frameworkPath())
+		if err != nil {
+			return fmt.Errorf("zinit install failed: %w", err)
+		}
+		if !result.Success() {
+			return fmt.Errorf("zinit install failed: %s", result.Stderr)
+		}
 	case "fisher":
 		installScript := `curl -sL https://raw.githubusercontent.com/jorgebucaran/fisher/main/functions/fisher.fish | source && fisher install jorgebucaran/fisher`
 		result, err := s.runner.Run(ctx.Context(), "fish", "-c", installScript)
@@ -117,6 +129,8 @@ func (s *FrameworkStep) Explain(_ compiler.ExplainContext) compiler.Explanation
 	switch s.config.Framework {
 	case "oh-my-zsh":
 		docLinks = []string{"https://ohmyz.sh/", "https://github.com/ohmyzsh/ohmyzsh"}
+	case "zinit":
+		docLinks = []string{"https://github.com/zdharma-continuum/zinit"}
 	case "fisher":
 		docLinks = []string{"https://github.com/jorgebucaran/fisher"}
 	case "oh-my-fish":
@@ -139,6 +153,8 @@ func (s *FrameworkStep) frameworkPath() string {
 	switch s.config.Framework {
 	case "oh-my-zsh":
 		return ports.ExpandPath("~/.oh-my-zsh")
+	case "zinit":
+		return ports.ExpandPath(zinitHome)
 	case "fisher":
 		return ports.ExpandPath("~/.config/fish/functions/fisher.fish")
 	case "oh-my-fish":
@@ -287,13 +303,37 @@ func (s *CustomPluginStep) DependsOn() []compiler.StepID {
 	return []compiler.StepID{frameworkID}
 }
 
-// Check verifies if the custom plugin is installed.
-func (s *CustomPluginStep) Check(_ compiler.RunContext) (compiler.StepStatus, error) {
+// Check verifies if the custom plugin is installed and, when a revision is
+// pinned, checked out at that revision.
+func (s *CustomPluginStep) Check(ctx compiler.RunContext) (compiler.StepStatus, error) {
 	path := s.pluginPath()
-	if s.fs.Exists(path) {
+	if !s.fs.Exists(path) {
+		return compiler.StatusNeedsApply, nil
+	}
+	if s.plugin.Rev == "" || s.runner == nil {
 		return compiler.StatusSatisfied, nil
 	}
-	return compiler.StatusNeedsApply, nil
+
+	head, err := s.gitRevParse(ctx, path, "HEAD")
+	if err != nil {
+		return compiler.StatusNeedsApply, nil //nolint:nilerr // unreadable checkout means needs apply
+	}
+	want, err := s.gitRevParse(ctx, path, s.plugin.Rev+"^{commit}")
+	if err != nil || head != want {
+		return compiler.StatusNeedsApply, nil //nolint:nilerr // unknown revision needs a fetch
+	}
+	return compiler.StatusSatisfied, nil
+}
+
+func (s *CustomPluginStep) gitRevParse(ctx compiler.RunContext, path, rev string) (string, error) {
+	result, err := s.runner.Run(ctx.Context(), "git", "-C", path, "rev-parse", "--verify", "--quiet", rev)
+	if err != nil {
+		return "", err
+	}
+	if !result.Success() {
+		return "", fmt.Errorf("unknown revision %s", rev)
+	}
+	return strings.TrimSpace(result.Stdout), nil
 }
 
 // Plan returns the diff for this step.
@@ -303,10 +343,17 @@ func (s *CustomPluginStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
 		"custom-plugin",
 		s.plugin.Name,
 		"",
-		fmt.Sprintf("Clone %s from %s", s.plugin.Name, s.plugin.Repo),
+		s.planSummary(),
 	), nil
 }
 
+func (s *CustomPluginStep) planSummary() string {
+	if s.plugin.Rev != "" {
+		return fmt.Sprintf("Clone %s from %s at %s", s.plugin.Name, s.plugin.Repo, s.plugin.Rev)
+	}
+	return fmt.Sprintf("Clone %s from %s", s.plugin.Name, s.plugin.Repo)
+}
+
 // Apply clones the custom plugin from its git repository.
 func (s *CustomPluginStep) Apply(ctx compiler.RunContext) error {
 	if s.runner == nil {
@@ -318,21 +365,47 @@ func (s *CustomPluginStep) Apply(ctx compiler.RunContext) error {
 		return fmt.Errorf("unsupported framework for custom plugins: %s", s.framework)
 	}
 
-	result, err := s.runner.Run(ctx.Context(), "git", "clone", s.plugin.Repo, path)
+	args := []string{"clone", s.plugin.Repo, path}
+	if s.fs.Exists(path) {
+		// Already cloned: only reached when the pinned revision differs.
+		args = []string{"-C", path, "fetch", "--tags", "origin"}
+	}
+	if err := s.git(ctx, args...); err != nil {
+		return err
+	}
+	if s.plugin.Rev == "" {
+		return nil
+	}
+	return s.git(ctx, "-C", path, "checkout", "--quiet", s.plugin.Rev)
+}
+
+func (s *CustomPluginStep) git(ctx compiler.RunContext, args ...string) error {
+	result, err := s.runner.Run(ctx.Context(), "git", args...)
 	if err != nil {
-		return fmt.Errorf("git clone failed for %s: %w", s.plugin.Name, err)
+		return fmt.Errorf("git %s failed for %s: %w", gitSubcommand(args), s.plugin.Name, err)
 	}
 	if !result.Success() {
-		return fmt.Errorf("git clone failed for %s: %s", s.plugin.Name, result.Stderr)
+		return fmt.Errorf("git %s failed for %s: %s", gitSubcommand(args), s.plugin.Name, result.Stderr)
 	}
 	return nil
 }
 
+// gitSubcommand returns the git subcommand in args, skipping a leading -C dir.
+func gitSubcommand(args []string) string {
+	if len(args) > 2 && args[0] == "-C" {
+		return args[2]
+	}
+	if len(args) > 0 {
+		return args[0]
+	}
+	return ""
+}
+
 // Explain provides context for this step.
 func (s *CustomPluginStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
 	return compiler.NewExplanation(
 		"Install Custom Plugin",
-		fmt.Sprintf("Clone %s plugin from %s", s.plugin.Name, s.plugin.Repo),
+		s.planSummary(),
 		nil,
 	)
 }
@@ -528,9 +601,12 @@ func (s *AliasStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
 	)
 }
 
-// StarshipStep manages starship prompt configuration.
+// StarshipStep manages starship prompt configuration. With a source file
+// it keeps ~/.config/starship.toml identical to that file; otherwise it
+// generates a config from the preset.
 type StarshipStep struct {
 	config StarshipConfig
+	source string
 	id     compiler.StepID
 	fs     ports.FileSystem
 }
@@ -542,9 +618,17 @@ func NewStarshipStep(config StarshipConfig) *StarshipStep {
 
 // NewStarshipStepWithFS creates a new StarshipStep with a custom filesystem.
 func NewStarshipStepWithFS(config StarshipConfig, fs ports.FileSystem) *StarshipStep {
+	return NewStarshipStepWithSource(config, "", fs)
+}
+
+// NewStarshipStepWithSource creates a StarshipStep that manages
+// ~/.config/starship.toml as a copy of source. Source may be the file itself
+// or a directory containing starship.toml.
+func NewStarshipStepWithSource(config StarshipConfig, source string, fs ports.FileSystem) *StarshipStep {
 	id := compiler.MustNewStepID("shell:starship")
 	return &StarshipStep{
 		config: config,
+		source: source,
 		id:     id,
 		fs:     fs,
 	}
@@ -563,16 +647,39 @@ func (s *StarshipStep) DependsOn() []compiler.StepID {
 // Check verifies if starship is configured.
 func (s *StarshipStep) Check(_ compiler.RunContext) (compiler.StepStatus, error) {
 	configPath := ports.ExpandPath("~/.config/starship.toml")
-	if s.fs.Exists(configPath) {
-		return compiler.StatusSatisfied, nil
+	if s.source == "" {
+		if s.fs.Exists(configPath) {
+			return compiler.StatusSatisfied, nil
+		}
+		return compiler.StatusNeedsApply, nil
 	}
-	return compiler.StatusNeedsApply, nil
+
+	desired, err := s.fs.ReadFile(s.sourceFile())
+	if err != nil {
+		return compiler.StatusUnknown, fmt.Errorf("failed to read starship source: %w", err)
+	}
+	current, err := s.fs.ReadFile(configPath)
+	if err != nil || !bytes.Equal(current, desired) {
+		return compiler.StatusNeedsApply, nil //nolint:nilerr // missing config means needs apply
+	}
+	return compiler.StatusSatisfied, nil
+}
+
+// sourceFile returns the starship.toml to copy from.
+func (s *StarshipStep) sourceFile() string {
+	if s.fs.IsDir(s.source) {
+		return filepath.Join(s.source, "starship.toml")
+	}
+	return s.source
 }
 
 // Plan returns the diff for this step.
 func (s *StarshipStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
 	desc := "Configure starship prompt"
-	if s.config.Preset != "" {
+	switch {
+	case s.source != "":
+		desc = fmt.Sprintf("Install starship prompt config from %s", s.sourceFile())
+	case s.config.Preset != "":
 		desc = fmt.Sprintf("Configure starship prompt with %s preset", s.config.Preset)
 	}
 	return compiler.NewDiff(
@@ -591,6 +698,13 @@ func (s *StarshipStep) Apply(_ compiler.RunContext) error {
 		return fmt.Errorf("failed to create config dir: %w", err)
 	}
 	content := s.generateConfig()
+	if s.source != "" {
+		var err error
+		content, err = s.fs.ReadFile(s.sourceFile())
+		if err != nil {
+			return fmt.Errorf("failed to read starship source: %w", err)
+		}
+	}
 	return s.fs.WriteFile(configPath, content, 0o644)
 }
 
@@ -662,14 +776,17 @@ func (s *FisherPluginStep) DependsOn() []compiler.StepID {
 }
 
 // Check verifies if the fisher plugin is installed by reading the fish_plugins file.
+// Plugins pinned with owner/repo@ref must be listed with the same ref.
 func (s *FisherPluginStep) Check(_ compiler.RunContext) (compiler.StepStatus, error) {
 	pluginsPath := ports.ExpandPath("~/.config/fish/fish_plugins")
 	content, err := s.fs.ReadFile(pluginsPath)
 	if err != nil {
 		return compiler.StatusNeedsApply, nil //nolint:nilerr // missing file means needs apply
 	}
-	if strings.Contains(string(content), s.plugin) {
-		return compiler.StatusSatisfied, nil
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.TrimSpace(line) == s.plugin {
+			return compiler.StatusSatisfied, nil
+		}
 	}
 	return compiler.StatusNeedsApply, nil
 }
@@ -709,3 +826,181 @@ func (s *FisherPluginStep) Explain(_ compiler.ExplainContext) compiler.Explanati
 		nil,
 	)
 }
+
+// ZinitStep manages the zinit plugin block in .zshrc.
+type ZinitStep struct {
+	shell   string
+	plugins []string
+	id      compiler.StepID
+	fs      ports.FileSystem
+}
+
+// NewZinitStep creates a new ZinitStep.
+func NewZinitStep(shell string, plugins []string) *ZinitStep {
+	return NewZinitStepWithFS(shell, plugins, filesystem.NewRealFileSystem())
+}
+
+// NewZinitStepWithFS creates a new ZinitStep with a custom filesystem.
+// Plugins are owner/repo, optionally pinned with owner/repo@rev.
+func NewZinitStepWithFS(shell string, plugins []string, fs ports.FileSystem) *ZinitStep {
+	id := compiler.MustNewStepID(fmt.Sprintf("shell:zinit:%s", shell))
+	return &ZinitStep{
+		shell:   shell,
+		plugins: plugins,
+		id:      id,
+		fs:      fs,
+	}
+}
+
+// ID returns the step identifier.
+func (s *ZinitStep) ID() compiler.StepID {
+	return s.id
+}
+
+// DependsOn returns dependencies for this step.
+func (s *ZinitStep) DependsOn() []compiler.StepID {
+	frameworkID := compiler.MustNewStepID(fmt.Sprintf("shell:framework:%s:zinit", s.shell))
+	return []compiler.StepID{frameworkID}
+}
+
+// Check verifies if the zinit block matches the declared plugins.
+func (s *ZinitStep) Check(_ compiler.RunContext) (compiler.StepStatus, error) {
+	configPath := ports.ExpandPath(shellConfigPath(s.shell))
+	if configPath == "" {
+		return compiler.StatusNeedsApply, nil
+	}
+
+	content, err := s.fs.ReadFile(configPath)
+	if err != nil {
+		return compiler.StatusNeedsApply, nil //nolint:nilerr // missing config means needs apply
+	}
+
+	if ReadManagedBlock(string(content), "zinit") == generateZinitBlock(s.plugins) {
+		return compiler.StatusSatisfied, nil
+	}
+	return compiler.StatusNeedsApply, nil
+}
+
+// Plan returns the diff for this step.
+func (s *ZinitStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
+	return compiler.NewDiff(
+		compiler.DiffTypeModify,
+		"zinit",
+		fmt.Sprintf("%d plugins", len(s.plugins)),
+		"",
+		fmt.Sprintf("Load %d zinit plugins for %s", len(s.plugins), s.shell),
+	), nil
+}
+
+// Apply writes the zinit block to the shell config file.
+func (s *ZinitStep) Apply(_ compiler.RunContext) error {
+	configPath := ports.ExpandPath(shellConfigPath(s.shell))
+	if configPath == "" {
+		return fmt.Errorf("unsupported shell for zinit: %s", s.shell)
+	}
+
+	content, err := s.fs.ReadFile(configPath)
+	if err != nil {
+		content = []byte{}
+	}
+
+	updated := WriteManagedBlock(string(content), "zinit", generateZinitBlock(s.plugins))
+	return s.fs.WriteFile(configPath, []byte(updated), 0o644)
+}
+
+// Explain provides context for this step.
+func (s *ZinitStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
+	return compiler.NewExplanation(
+		"Load Zinit Plugins",
+		fmt.Sprintf("Load %d plugins with zinit for %s. Pinned plugins are loaded at their declared revision.", len(s.plugins), s.shell),
+		[]string{"https://github.com/zdharma-continuum/zinit"},
+	)
+}
+
+// FunctionsStep manages shell function definitions.
+type FunctionsStep struct {
+	shell     string
+	functions map[string]string
+	id        compiler.StepID
+	fs        ports.FileSystem
+}
+
+// NewFunctionsStep creates a new FunctionsStep.
+func NewFunctionsStep(shell string, functions map[string]string) *FunctionsStep {
+	return NewFunctionsStepWithFS(shell, functions, filesystem.NewRealFileSystem())
+}
+
+// NewFunctionsStepWithFS creates a new FunctionsStep with a custom filesystem.
+func NewFunctionsStepWithFS(shell string, functions map[string]string, fs ports.FileSystem) *FunctionsStep {
+	id := compiler.MustNewStepID(fmt.Sprintf("shell:functions:%s", shell))
+	return &FunctionsStep{
+		shell:     shell,
+		functions: functions,
+		id:        id,
+		fs:        fs,
+	}
+}
+
+// ID returns the step identifier.
+func (s *FunctionsStep) ID() compiler.StepID {
+	return s.id
+}
+
+// DependsOn returns dependencies for this step.
+func (s *FunctionsStep) DependsOn() []compiler.StepID {
+	return nil
+}
+
+// Check verifies if functions are defined in the shell config file.
+func (s *FunctionsStep) Check(_ compiler.RunContext) (compiler.StepStatus, error) {
+	configPath := ports.ExpandPath(shellConfigPath(s.shell))
+	if configPath == "" {
+		return compiler.StatusNeedsApply, nil
+	}
+
+	content, err := s.fs.ReadFile(configPath)
+	if err != nil {
+		return compiler.StatusNeedsApply, nil //nolint:nilerr // missing config means needs apply
+	}
+
+	if ReadManagedBlock(string(content), "functions") == generateFunctionsBlock(s.shell, s.functions) {
+		return compiler.StatusSatisfied, nil
+	}
+	return compiler.StatusNeedsApply, nil
+}
+
+// Plan returns the diff for this step.
+func (s *FunctionsStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
+	return compiler.NewDiff(
+		compiler.DiffTypeModify,
+		"functions",
+		fmt.Sprintf("%d functions", len(s.functions)),
+		"",
+		fmt.Sprintf("Define %d functions for %s", len(s.functions), s.shell),
+	), nil
+}
+
+// Apply writes functions to the shell config file.
+func (s *FunctionsStep) Apply(_ compiler.RunContext) error {
+	configPath := ports.ExpandPath(shellConfigPath(s.shell))
+	if configPath == "" {
+		return fmt.Errorf("unsupported shell for function management: %s", s.shell)
+	}
+
+	content, err := s.fs.ReadFile(configPath)
+	if err != nil {
+		content = []byte{}
+	}
+
+	updated := WriteManagedBlock(string(content), "functions", generateFunctionsBlock(s.shell, s.functions))
+	return s.fs.WriteFile(configPath, []byte(updated), 0o644)
+}
+
+// Explain provides context for this step.
+func (s *FunctionsStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
+	return compiler.NewExplanation(
+		"Define Shell Functions",
+		fmt.Sprintf("Define %d shell functions for %s", len(s.functions), s.shell),
+		nil,
+	)
+}
@@ -1440,3 +1440,166 @@ func TestAliasStep_Check_MismatchedBlock(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, compiler.StatusNeedsApply, status)
 }
+
+func TestFrameworkStep_Apply_Zinit(t *testing.T) {
+	t.Parallel()
+
+	runner := mocks.NewCommandRunner()
+	zinitPath := ports.ExpandPath("~/.local/share/zinit/zinit.git")
+	runner.AddResult("git", []string{"clone", "--depth", "1", "https://github.com/zdharma-continuum/zinit.git", zinitPath}, ports.CommandResult{ExitCode: 0})
+
+	step := shell.NewFrameworkStepWith(shell.Entry{Name: "zsh", Framework: "zinit"}, mocks.NewFileSystem(), runner)
+	require.NoError(t, step.Apply(compiler.NewRunContext(context.TODO())))
+	require.Len(t, runner.Calls(), 1)
+}
+
+func TestCustomPluginStep_Check_PinnedRev(t *testing.T) {
+	t.Parallel()
+
+	plugin := shell.CustomPlugin{Name: "zsh-autosuggestions", Repo: "https://github.com/zsh-users/zsh-autosuggestions", Rev: "v0.7.0"}
+	pluginPath := ports.ExpandPath("~/.oh-my-zsh/custom/plugins/zsh-autosuggestions")
+	fs := mocks.NewFileSystem()
+	fs.AddDir(pluginPath)
+
+	tests := []struct {
+		name string
+		head string
+		want compiler.StepStatus
+	}{
+		{name: "at pinned rev", head: "abc123\n", want: compiler.StatusSatisfied},
+		{name: "at other rev", head: "def456\n", want: compiler.StatusNeedsApply},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := mocks.NewCommandRunner()
+			runner.AddResult("git", []string{"-C", pluginPath, "rev-parse", "--verify", "--quiet", "HEAD"}, ports.CommandResult{Stdout: tt.head})
+			runner.AddResult("git", []string{"-C", pluginPath, "rev-parse", "--verify", "--quiet", "v0.7.0^{commit}"}, ports.CommandResult{Stdout: "abc123\n"})
+
+			step := shell.NewCustomPluginStepWith("zsh", "oh-my-zsh", plugin, fs, runner)
+			status, err := step.Check(compiler.NewRunContext(context.TODO()))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, status)
+		})
+	}
+}
+
+func TestCustomPluginStep_Apply_PinnedRev(t *testing.T) {
+	t.Parallel()
+
+	plugin := shell.CustomPlugin{Name: "zsh-autosuggestions", Repo: "https://github.com/zsh-users/zsh-autosuggestions", Rev: "v0.7.0"}
+	pluginPath := ports.ExpandPath("~/.oh-my-zsh/custom/plugins/zsh-autosuggestions")
+	runner := mocks.NewCommandRunner()
+	runner.AddResult("git", []string{"clone", plugin.Repo, pluginPath}, ports.CommandResult{})
+	runner.AddResult("git", []string{"-C", pluginPath, "fetch", "--tags", "origin"}, ports.CommandResult{})
+	runner.AddResult("git", []string{"-C", pluginPath, "checkout", "--quiet", "v0.7.0"}, ports.CommandResult{})
+	ctx := compiler.NewRunContext(context.TODO())
+
+	fresh := shell.NewCustomPluginStepWith("zsh", "oh-my-zsh", plugin, mocks.NewFileSystem(), runner)
+	require.NoError(t, fresh.Apply(ctx))
+
+	cloned := mocks.NewFileSystem()
+	cloned.AddDir(pluginPath)
+	existing := shell.NewCustomPluginStepWith("zsh", "oh-my-zsh", plugin, cloned, runner)
+	require.NoError(t, existing.Apply(ctx))
+
+	calls := runner.Calls()
+	require.Len(t, calls, 4)
+	assert.Equal(t, "clone", calls[0].Args[0])
+	assert.Equal(t, "checkout", calls[1].Args[2])
+	assert.Equal(t, "fetch", calls[2].Args[2])
+	assert.Equal(t, "checkout", calls[3].Args[2])
+}
+
+func TestFisherPluginStep_Check_PinnedRef(t *testing.T) {
+	t.Parallel()
+
+	fs := mocks.NewFileSystem()
+	fs.AddFile(ports.ExpandPath("~/.config/fish/fish_plugins"), "jorgebucaran/fisher\nPatrickF1/fzf.fish@v9.0\n")
+	ctx := compiler.NewRunContext(context.TODO())
+
+	pinned, err := shell.NewFisherPluginStepWithFS("PatrickF1/fzf.fish@v9.0", fs).Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, pinned)
+
+	repinned, err := shell.NewFisherPluginStepWithFS("PatrickF1/fzf.fish@v10.0", fs).Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, repinned)
+}
+
+func TestZinitStep(t *testing.T) {
+	t.Parallel()
+
+	fs := mocks.NewFileSystem()
+	zshrc := ports.ExpandPath("~/.zshrc")
+	fs.AddFile(zshrc, "export EDITOR=nvim\n")
+	ctx := compiler.NewRunContext(context.TODO())
+
+	step := shell.NewZinitStepWithFS("zsh", []string{"zsh-users/zsh-autosuggestions@v0.7.0", "zsh-users/zsh-syntax-highlighting"}, fs)
+	assert.Equal(t, "shell:zinit:zsh", step.ID().String())
+	assert.Equal(t, []compiler.StepID{compiler.MustNewStepID("shell:framework:zsh:zinit")}, step.DependsOn())
+
+	status, err := step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+
+	require.NoError(t, step.Apply(ctx))
+	content, err := fs.ReadFile(zshrc)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "export EDITOR=nvim\n")
+	assert.Contains(t, string(content), "zinit ice ver\"v0.7.0\"\nzinit light zsh-users/zsh-autosuggestions\nzinit light zsh-users/zsh-syntax-highlighting\n")
+
+	status, err = step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, status)
+}
+
+func TestFunctionsStep_Apply(t *testing.T) {
+	t.Parallel()
+
+	functions := map[string]string{"mkcd": "mkdir -p \"$1\" && cd \"$1\""}
+	ctx := compiler.NewRunContext(context.TODO())
+
+	zshFS := mocks.NewFileSystem()
+	zsh := shell.NewFunctionsStepWithFS("zsh", functions, zshFS)
+	require.NoError(t, zsh.Apply(ctx))
+	content, err := zshFS.ReadFile(ports.ExpandPath("~/.zshrc"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "mkcd() {\n  mkdir -p \"$1\" && cd \"$1\"\n}\n")
+
+	status, err := zsh.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, status)
+
+	fishFS := mocks.NewFileSystem()
+	require.NoError(t, shell.NewFunctionsStepWithFS("fish", functions, fishFS).Apply(ctx))
+	content, err = fishFS.ReadFile(ports.ExpandPath("~/.config/fish/config.fish"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "function mkcd\n  mkdir -p \"$1\" && cd \"$1\"\nend\n")
+}
+
+func TestStarshipStep_Source(t *testing.T) {
+	t.Parallel()
+
+	fs := mocks.NewFileSystem()
+	fs.AddFile("/config/.config/starship.toml", "add_newline = false\n")
+	target := ports.ExpandPath("~/.config/starship.toml")
+	fs.AddFile(target, "# Managed by preflight\n")
+	ctx := compiler.NewRunContext(context.TODO())
+
+	step := shell.NewStarshipStepWithSource(shell.StarshipConfig{Enabled: true}, "/config/.config/starship.toml", fs)
+
+	status, err := step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+
+	require.NoError(t, step.Apply(ctx))
+	content, err := fs.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, "add_newline = false\n", string(content))
+
+	status, err = step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, status)
+}
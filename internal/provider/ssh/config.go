@@ -19,6 +19,7 @@ type DefaultsConfig struct {
 	ForwardAgent        bool
 	ServerAliveCountMax int
 	ServerAliveInterval int
+	IdentityAgent       string
 }
 
 // HostConfig represents a Host block in SSH config.
@@ -81,6 +82,9 @@ func ParseConfig(raw map[string]interface{}) (*Config, error) {
 		if v, ok := defaults["serveraliveinterval"].(int); ok {
 			cfg.Defaults.ServerAliveInterval = v
 		}
+		if v, ok := defaults["identityagent"].(string); ok {
+			cfg.Defaults.IdentityAgent = v
+		}
 	}
 
 	// Parse hosts
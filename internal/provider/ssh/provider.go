@@ -47,7 +47,7 @@ func (p *Provider) Compile(ctx compiler.CompileContext) ([]compiler.Step, error)
 func hasDefaults(cfg *Config) bool {
 	d := cfg.Defaults
 	return d.AddKeysToAgent || d.IdentitiesOnly || d.ForwardAgent ||
-		d.ServerAliveInterval > 0 || d.ServerAliveCountMax > 0
+		d.ServerAliveInterval > 0 || d.ServerAliveCountMax > 0 || d.IdentityAgent != ""
 }
 
 // Ensure Provider implements compiler.Provider.
@@ -3,6 +3,7 @@ package ssh
 import (
 	"bytes"
 	"fmt"
+	"strings"
 
 	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
 	"github.com/felixgeelhaar/preflight/internal/ports"
@@ -131,6 +132,10 @@ func (s *ConfigStep) generateConfig() []byte {
 		if s.cfg.Defaults.ServerAliveCountMax > 0 {
 			fmt.Fprintf(&buf, "    ServerAliveCountMax %d\n", s.cfg.Defaults.ServerAliveCountMax)
 		}
+		if s.cfg.Defaults.IdentityAgent != "" {
+			// Agent sockets such as 1Password's live in paths with spaces
+			fmt.Fprintf(&buf, "    IdentityAgent \"%s\"\n", s.cfg.Defaults.IdentityAgent)
+		}
 		buf.WriteString("\n")
 	}
 
@@ -214,7 +219,7 @@ func (s *ConfigStep) generateConfig() []byte {
 func (s *ConfigStep) hasDefaults() bool {
 	d := s.cfg.Defaults
 	return d.AddKeysToAgent || d.IdentitiesOnly || d.ForwardAgent ||
-		d.ServerAliveInterval > 0 || d.ServerAliveCountMax > 0
+		d.ServerAliveInterval > 0 || d.ServerAliveCountMax > 0 || d.IdentityAgent != ""
 }
 
 // validateConfig validates all SSH config values to prevent injection attacks.
@@ -226,6 +231,15 @@ func (s *ConfigStep) validateConfig() error {
 		}
 	}
 
+	if s.cfg.Defaults.IdentityAgent != "" {
+		if err := validation.ValidateSSHParameter(s.cfg.Defaults.IdentityAgent); err != nil {
+			return fmt.Errorf("invalid IdentityAgent: %w", err)
+		}
+		if strings.Contains(s.cfg.Defaults.IdentityAgent, `"`) {
+			return fmt.Errorf("invalid IdentityAgent: contains a quote")
+		}
+	}
+
 	// Validate host blocks
 	for i, host := range s.cfg.Hosts {
 		if host.Host != "" {
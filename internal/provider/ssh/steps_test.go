@@ -180,6 +180,30 @@ func TestSSHConfigStep_Apply_WithDefaults(t *testing.T) {
 	}
 }
 
+func TestSSHConfigStep_Apply_WithIdentityAgent(t *testing.T) {
+	fs := mocks.NewFileSystem()
+	cfg := &Config{
+		Defaults: DefaultsConfig{
+			IdentityAgent: "~/Library/Group Containers/2BUA8C4S2C.com.1password/t/agent.sock",
+		},
+	}
+
+	step := NewConfigStep(cfg, fs)
+	if err := step.Apply(compiler.RunContext{}); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	content, _ := fs.ReadFile(ports.ExpandPath("~/.ssh/config"))
+	if !contains(string(content), `IdentityAgent "~/Library/Group Containers/2BUA8C4S2C.com.1password/t/agent.sock"`) {
+		t.Errorf("config should contain a quoted IdentityAgent, got:\n%s", content)
+	}
+
+	cfg.Defaults.IdentityAgent = `agent".sock`
+	if err := step.Apply(compiler.RunContext{}); err == nil {
+		t.Error("Apply() should reject an IdentityAgent containing a quote")
+	}
+}
+
 func TestSSHConfigStep_Apply_WithInclude(t *testing.T) {
 	fs := mocks.NewFileSystem()
 	cfg := &Config{
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/pelletier/go-toml/v2"
 
@@ -95,10 +96,7 @@ func (s *AlacrittyConfigStep) checkSourceMode() (compiler.StepStatus, error) {
 }
 
 func (s *AlacrittyConfigStep) checkSettingsMode() (compiler.StepStatus, error) {
-	// If no settings and no global font/theme, nothing to do
-	hasGlobalFont := s.globalCfg != nil && s.globalCfg.Font != nil
-	hasGlobalTheme := s.globalCfg != nil && s.globalCfg.Theme != nil
-	if len(s.cfg.Settings) == 0 && !hasGlobalFont && !hasGlobalTheme {
+	if !s.hasSettings() {
 		return compiler.StatusSatisfied, nil
 	}
 
@@ -106,10 +104,27 @@ func (s *AlacrittyConfigStep) checkSettingsMode() (compiler.StepStatus, error) {
 		return compiler.StatusNeedsApply, nil
 	}
 
-	// For now, always apply settings mode (could add deep comparison later)
+	existing := s.readConfig()
+	current, err := toml.Marshal(existing)
+	if err != nil {
+		return compiler.StatusNeedsApply, nil //nolint:nilerr // intentional: unparsable config means needs apply
+	}
+	desired, err := toml.Marshal(s.desiredConfig(s.readConfig()))
+	if err != nil {
+		return compiler.StatusUnknown, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	if string(current) == string(desired) {
+		return compiler.StatusSatisfied, nil
+	}
 	return compiler.StatusNeedsApply, nil
 }
 
+// hasSettings reports whether settings mode has anything to write.
+func (s *AlacrittyConfigStep) hasSettings() bool {
+	g := s.globalCfg
+	return len(s.cfg.Settings) > 0 || (g != nil && (g.Font != nil || g.Theme != nil || len(g.Keybindings) > 0))
+}
+
 // Plan returns the diff for this step.
 func (s *AlacrittyConfigStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
 	if s.cfg.Source != "" {
@@ -166,7 +181,16 @@ func (s *AlacrittyConfigStep) applySourceMode() error {
 }
 
 func (s *AlacrittyConfigStep) applySettingsMode() error {
-	// Read existing config or start fresh
+	output, err := toml.Marshal(s.desiredConfig(s.readConfig()))
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	return s.fs.WriteFile(s.targetPath, output, 0o644)
+}
+
+// readConfig reads the existing config, or returns an empty one.
+func (s *AlacrittyConfigStep) readConfig() map[string]interface{} {
 	existing := make(map[string]interface{})
 	if s.fs.Exists(s.targetPath) {
 		content, err := s.fs.ReadFile(s.targetPath)
@@ -174,14 +198,23 @@ func (s *AlacrittyConfigStep) applySettingsMode() error {
 			_ = toml.Unmarshal(content, &existing)
 		}
 	}
+	return existing
+}
 
+// desiredConfig merges the settings, global font, theme colors and
+// keybindings into existing.
+func (s *AlacrittyConfigStep) desiredConfig(existing map[string]interface{}) map[string]interface{} {
 	// Merge settings
 	for k, v := range s.cfg.Settings {
 		existing[k] = v
 	}
 
+	if s.globalCfg == nil {
+		return existing
+	}
+
 	// Apply global font if set
-	if s.globalCfg != nil && s.globalCfg.Font != nil {
+	if s.globalCfg.Font != nil {
 		fontConfig := map[string]interface{}{
 			"normal": map[string]interface{}{
 				"family": s.globalCfg.Font.Family,
@@ -191,13 +224,82 @@ func (s *AlacrittyConfigStep) applySettingsMode() error {
 		existing["font"] = fontConfig
 	}
 
-	// Write TOML
-	output, err := toml.Marshal(existing)
-	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
+	// Apply custom theme colors
+	colors, _ := existing["colors"].(map[string]interface{})
+	setColor := func(group, name, value string) {
+		if colors == nil {
+			colors = make(map[string]interface{})
+		}
+		section, _ := colors[group].(map[string]interface{})
+		if section == nil {
+			section = make(map[string]interface{})
+			colors[group] = section
+		}
+		section[name] = value
+	}
+	for _, name := range []string{"background", "foreground"} {
+		if color := s.globalCfg.themeColor(name); color != "" {
+			setColor("primary", name, color)
+		}
+	}
+	palette := s.globalCfg.paletteColors()
+	for _, i := range sortedIndexes(palette) {
+		group := "normal"
+		if i >= 8 {
+			group = "bright"
+		}
+		setColor(group, ansiColors[i%8], palette[i])
+	}
+	if colors != nil {
+		existing["colors"] = colors
 	}
 
-	return s.fs.WriteFile(s.targetPath, output, 0o644)
+	// Declared keybindings replace the existing ones
+	if len(s.globalCfg.Keybindings) > 0 {
+		bindings := make([]interface{}, 0, len(s.globalCfg.Keybindings))
+		for _, kb := range s.globalCfg.Keybindings {
+			mods, key := splitKeys(kb.Keys)
+			binding := map[string]interface{}{
+				"key":    alacrittyKey(key),
+				"action": kb.Action,
+			}
+			if len(mods) > 0 {
+				binding["mods"] = alacrittyMods(mods)
+			}
+			bindings = append(bindings, binding)
+		}
+		keyboard, _ := existing["keyboard"].(map[string]interface{})
+		if keyboard == nil {
+			keyboard = make(map[string]interface{})
+		}
+		keyboard["bindings"] = bindings
+		existing["keyboard"] = keyboard
+	}
+
+	return existing
+}
+
+// alacrittyKey converts a key name to Alacritty's spelling, e.g. "t" to
+// "T" and "enter" to "Enter".
+func alacrittyKey(key string) string {
+	if key == "" {
+		return key
+	}
+	return strings.ToUpper(key[:1]) + key[1:]
+}
+
+// alacrittyMods joins modifiers the way Alacritty expects: "Control|Shift".
+func alacrittyMods(mods []string) string {
+	names := map[string]string{"ctrl": "Control", "shift": "Shift", "alt": "Alt", "super": "Super"}
+	out := make([]string, 0, len(mods))
+	for _, mod := range mods {
+		mod = canonicalMod(mod)
+		if name, ok := names[mod]; ok {
+			mod = name
+		}
+		out = append(out, mod)
+	}
+	return strings.Join(out, "|")
 }
 
 // Explain provides context for this step.
@@ -10,8 +10,9 @@ import (
 // Config represents the terminal section of the configuration.
 type Config struct {
 	// Global settings applied to all terminals where applicable
-	Font  *FontConfig  `yaml:"font,omitempty"`
-	Theme *ThemeConfig `yaml:"theme,omitempty"`
+	Font        *FontConfig  `yaml:"font,omitempty"`
+	Theme       *ThemeConfig `yaml:"theme,omitempty"`
+	Keybindings []Keybinding `yaml:"keybindings,omitempty"`
 
 	// Per-terminal configurations
 	Alacritty       *AlacrittyConfig       `yaml:"alacritty,omitempty"`
@@ -35,6 +36,13 @@ type ThemeConfig struct {
 	Custom map[string]string `yaml:"custom"` // Custom color overrides
 }
 
+// Keybinding maps a key chord such as "ctrl+shift+t" to an action in the
+// emulator's own vocabulary (e.g. "SpawnNewInstance" for Alacritty).
+type Keybinding struct {
+	Keys   string `yaml:"keys"`
+	Action string `yaml:"action"`
+}
+
 // AlacrittyConfig represents Alacritty terminal configuration.
 type AlacrittyConfig struct {
 	// ConfigPath overrides the default config location
@@ -64,7 +72,9 @@ type WezTermConfig struct {
 	ConfigPath string `yaml:"config_path,omitempty"`
 	Source     string `yaml:"source,omitempty"`
 	Link       bool   `yaml:"link,omitempty"`
-	// Note: WezTerm uses Lua, so we only support source/link, not settings merge
+	// Settings are rendered as config.<key> assignments in a generated
+	// wezterm.lua; existing Lua is not merged.
+	Settings map[string]interface{} `yaml:"settings,omitempty"`
 }
 
 // GhosttyConfig represents Ghostty terminal configuration.
@@ -602,6 +602,7 @@ func TestGhosttyConfigStep_Check_SourceMode_CopyMode_NotExists(t *testing.T) {
 
 	step := NewGhosttyConfigStep(
 		&GhosttyConfig{Source: "ghostty/config", Link: false},
+		nil,
 		"/home/user/.config/ghostty/config",
 		"/tmp/dotfiles",
 		fs,
@@ -624,6 +625,7 @@ func TestGhosttyConfigStep_Check_SourceMode_CopyMode_DifferentHash(t *testing.T)
 
 	step := NewGhosttyConfigStep(
 		&GhosttyConfig{Source: "ghostty/config", Link: false},
+		nil,
 		"/home/user/.config/ghostty/config",
 		"/tmp/dotfiles",
 		fs,
@@ -645,6 +647,7 @@ func TestGhosttyConfigStep_Check_SourceMode_CopyMode_SourceHashError(t *testing.
 
 	step := NewGhosttyConfigStep(
 		&GhosttyConfig{Source: "ghostty/config", Link: false},
+		nil,
 		"/home/user/.config/ghostty/config",
 		"/tmp/dotfiles",
 		fs,
@@ -668,6 +671,7 @@ func TestGhosttyConfigStep_Check_SettingsMode_ExistingConfigMatches(t *testing.T
 			"font-size": "14",
 			"theme":     "dark",
 		}},
+		nil,
 		"/home/user/.config/ghostty/config",
 		"/tmp/dotfiles",
 		fs,
@@ -690,6 +694,7 @@ func TestGhosttyConfigStep_Check_SettingsMode_ExistingConfigDiffers(t *testing.T
 		&GhosttyConfig{Settings: map[string]interface{}{
 			"font-size": "14",
 		}},
+		nil,
 		"/home/user/.config/ghostty/config",
 		"/tmp/dotfiles",
 		fs,
@@ -713,6 +718,7 @@ func TestGhosttyConfigStep_Apply_SettingsMode_MergeExisting(t *testing.T) {
 			"font-size": 14,
 			"cursor":    "beam",
 		}},
+		nil,
 		"/tmp/test/.config/ghostty/config",
 		"/tmp/dotfiles",
 		fs,
@@ -736,6 +742,7 @@ func TestGhosttyConfigStep_Apply_SettingsMode_NoSettings(t *testing.T) {
 	fs := mocks.NewFileSystem()
 	step := NewGhosttyConfigStep(
 		&GhosttyConfig{Settings: map[string]interface{}{}},
+		nil,
 		"/tmp/test/.config/ghostty/config",
 		"/tmp/dotfiles",
 		fs,
@@ -755,6 +762,7 @@ func TestGhosttyConfigStep_Apply_SourceMode_Copy(t *testing.T) {
 
 	step := NewGhosttyConfigStep(
 		&GhosttyConfig{Source: "ghostty/config", Link: false},
+		nil,
 		"/tmp/test/.config/ghostty/config",
 		"/tmp/dotfiles",
 		fs,
@@ -780,6 +788,7 @@ func TestGhosttyConfigStep_Apply_SourceMode_RemoveExisting(t *testing.T) {
 
 	step := NewGhosttyConfigStep(
 		&GhosttyConfig{Source: "ghostty/config", Link: false},
+		nil,
 		"/tmp/test/.config/ghostty/config",
 		"/tmp/dotfiles",
 		fs,
@@ -799,6 +808,7 @@ func TestGhosttyConfigStep_InstalledVersion(t *testing.T) {
 
 	step := NewGhosttyConfigStep(
 		&GhosttyConfig{},
+		nil,
 		"/home/user/.config/ghostty/config",
 		"/tmp/dotfiles",
 		mocks.NewFileSystem(),
@@ -988,6 +998,7 @@ func TestWezTermConfigStep_Check_CopyMode_NotExists(t *testing.T) {
 
 	step := NewWezTermConfigStep(
 		&WezTermConfig{Source: "wezterm.lua", Link: false},
+		nil,
 		"/home/user/.config/wezterm/wezterm.lua",
 		"/tmp/dotfiles",
 		fs,
@@ -1010,6 +1021,7 @@ func TestWezTermConfigStep_Check_CopyMode_DifferentHash(t *testing.T) {
 
 	step := NewWezTermConfigStep(
 		&WezTermConfig{Source: "wezterm.lua", Link: false},
+		nil,
 		"/home/user/.config/wezterm/wezterm.lua",
 		"/tmp/dotfiles",
 		fs,
@@ -1031,6 +1043,7 @@ func TestWezTermConfigStep_Check_CopyMode_SourceHashError(t *testing.T) {
 
 	step := NewWezTermConfigStep(
 		&WezTermConfig{Source: "wezterm.lua", Link: false},
+		nil,
 		"/home/user/.config/wezterm/wezterm.lua",
 		"/tmp/dotfiles",
 		fs,
@@ -1051,6 +1064,7 @@ func TestWezTermConfigStep_Apply_Copy(t *testing.T) {
 
 	step := NewWezTermConfigStep(
 		&WezTermConfig{Source: "wezterm.lua", Link: false},
+		nil,
 		"/tmp/test/.config/wezterm/wezterm.lua",
 		"/tmp/dotfiles",
 		fs,
@@ -1076,6 +1090,7 @@ func TestWezTermConfigStep_Apply_RemoveExisting(t *testing.T) {
 
 	step := NewWezTermConfigStep(
 		&WezTermConfig{Source: "wezterm.lua", Link: false},
+		nil,
 		"/tmp/test/.config/wezterm/wezterm.lua",
 		"/tmp/dotfiles",
 		fs,
@@ -1095,6 +1110,7 @@ func TestWezTermConfigStep_InstalledVersion(t *testing.T) {
 
 	step := NewWezTermConfigStep(
 		&WezTermConfig{},
+		nil,
 		"/home/user/.config/wezterm/wezterm.lua",
 		"/tmp/dotfiles",
 		mocks.NewFileSystem(),
@@ -1112,6 +1128,7 @@ func TestWezTermConfigStep_Plan_CopyMode(t *testing.T) {
 	fs := mocks.NewFileSystem()
 	step := NewWezTermConfigStep(
 		&WezTermConfig{Source: "wezterm.lua", Link: false},
+		nil,
 		"/home/user/.config/wezterm/wezterm.lua",
 		"/tmp/dotfiles",
 		fs,
@@ -1288,7 +1305,7 @@ func TestITerm2ProfilesStep_Apply_FullProfile(t *testing.T) {
 	err := step.Apply(ctx)
 	require.NoError(t, err)
 
-	content, err := fs.ReadFile("/tmp/test/DynamicProfiles/preflight-profiles.json")
+	content, err := fs.ReadFile("/tmp/test/DynamicProfiles/preflight-profiles.plist")
 	require.NoError(t, err)
 	contentStr := string(content)
 	assert.Contains(t, contentStr, "Dev")
@@ -2080,6 +2097,7 @@ func TestGhosttyConfigStep_ReadConfig_WithComments(t *testing.T) {
 			"font-size": "14",
 			"theme":     "dark",
 		}},
+		nil,
 		"/home/user/.config/ghostty/config",
 		"/tmp/dotfiles",
 		fs,
@@ -2105,6 +2123,7 @@ func TestGhosttyConfigStep_ReadConfig_NoEqualsSign(t *testing.T) {
 			"font-size": "14",
 			"theme":     "dark",
 		}},
+		nil,
 		"/home/user/.config/ghostty/config",
 		"/tmp/dotfiles",
 		fs,
@@ -16,15 +16,17 @@ import (
 // GhosttyConfigStep manages Ghostty configuration.
 type GhosttyConfigStep struct {
 	cfg        *GhosttyConfig
+	globalCfg  *Config
 	targetPath string
 	configRoot string
 	fs         ports.FileSystem
 }
 
 // NewGhosttyConfigStep creates a new Ghostty config step.
-func NewGhosttyConfigStep(cfg *GhosttyConfig, targetPath, configRoot string, fs ports.FileSystem) *GhosttyConfigStep {
+func NewGhosttyConfigStep(cfg *GhosttyConfig, globalCfg *Config, targetPath, configRoot string, fs ports.FileSystem) *GhosttyConfigStep {
 	return &GhosttyConfigStep{
 		cfg:        cfg,
+		globalCfg:  globalCfg,
 		targetPath: pathutil.ExpandPath(targetPath),
 		configRoot: configRoot,
 		fs:         fs,
@@ -86,7 +88,8 @@ func (s *GhosttyConfigStep) Check(_ compiler.RunContext) (compiler.StepStatus, e
 	}
 
 	// Settings merge mode
-	if len(s.cfg.Settings) == 0 {
+	desired := s.desiredSettings()
+	if len(desired) == 0 {
 		return compiler.StatusSatisfied, nil
 	}
 
@@ -100,8 +103,8 @@ func (s *GhosttyConfigStep) Check(_ compiler.RunContext) (compiler.StepStatus, e
 		return compiler.StatusNeedsApply, nil //nolint:nilerr // intentional: missing/invalid config means needs apply
 	}
 
-	for key, value := range s.cfg.Settings {
-		if fmt.Sprintf("%v", existing[key]) != fmt.Sprintf("%v", value) {
+	for key, value := range desired {
+		if strings.Join(ghosttyValues(existing[key]), "\n") != strings.Join(ghosttyValues(value), "\n") {
 			return compiler.StatusNeedsApply, nil
 		}
 	}
@@ -109,6 +112,68 @@ func (s *GhosttyConfigStep) Check(_ compiler.RunContext) (compiler.StepStatus, e
 	return compiler.StatusSatisfied, nil
 }
 
+// desiredSettings returns the settings combined with the global font,
+// theme and keybindings. Repeatable keys (palette, keybind) hold a
+// []string with one entry per line.
+func (s *GhosttyConfigStep) desiredSettings() map[string]interface{} {
+	desired := make(map[string]interface{}, len(s.cfg.Settings))
+	for key, value := range s.cfg.Settings {
+		desired[key] = value
+	}
+
+	g := s.globalCfg
+	if g == nil {
+		return desired
+	}
+	if g.Font != nil {
+		if g.Font.Family != "" {
+			desired["font-family"] = g.Font.Family
+		}
+		if g.Font.Size > 0 {
+			desired["font-size"] = g.Font.Size
+		}
+	}
+	if g.Theme != nil && g.Theme.Name != "" {
+		desired["theme"] = g.Theme.Name
+	}
+	for _, name := range []string{"background", "foreground"} {
+		if color := g.themeColor(name); color != "" {
+			desired[name] = color
+		}
+	}
+	if palette := g.paletteColors(); len(palette) > 0 {
+		entries := make([]string, 0, len(palette))
+		for _, i := range sortedIndexes(palette) {
+			entries = append(entries, fmt.Sprintf("%d=%s", i, palette[i]))
+		}
+		desired["palette"] = entries
+	}
+	if len(g.Keybindings) > 0 {
+		entries := make([]string, 0, len(g.Keybindings))
+		for _, kb := range g.Keybindings {
+			mods, key := splitKeys(kb.Keys)
+			for i := range mods {
+				mods[i] = canonicalMod(mods[i])
+			}
+			entries = append(entries, strings.Join(append(mods, key), "+")+"="+kb.Action)
+		}
+		desired["keybind"] = entries
+	}
+	return desired
+}
+
+// ghosttyValues returns the lines a setting value is written as.
+func ghosttyValues(value interface{}) []string {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []string:
+		return v
+	default:
+		return []string{fmt.Sprintf("%v", v)}
+	}
+}
+
 // Plan returns the diff for this step.
 func (s *GhosttyConfigStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
 	if s.cfg.Source != "" {
@@ -125,7 +190,8 @@ func (s *GhosttyConfigStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
 		), nil
 	}
 
-	if len(s.cfg.Settings) == 0 {
+	desired := s.desiredSettings()
+	if len(desired) == 0 {
 		return compiler.NewDiff(
 			compiler.DiffTypeNone,
 			"config",
@@ -140,7 +206,7 @@ func (s *GhosttyConfigStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
 		"config",
 		s.targetPath,
 		"",
-		fmt.Sprintf("merge %d settings", len(s.cfg.Settings)),
+		fmt.Sprintf("merge %d settings", len(desired)),
 	), nil
 }
 
@@ -167,7 +233,8 @@ func (s *GhosttyConfigStep) Apply(_ compiler.RunContext) error {
 	}
 
 	// Settings merge mode
-	if len(s.cfg.Settings) == 0 {
+	desired := s.desiredSettings()
+	if len(desired) == 0 {
 		return nil
 	}
 
@@ -176,7 +243,7 @@ func (s *GhosttyConfigStep) Apply(_ compiler.RunContext) error {
 		existing = make(map[string]interface{})
 	}
 
-	for key, value := range s.cfg.Settings {
+	for key, value := range desired {
 		existing[key] = value
 	}
 
@@ -202,7 +269,15 @@ func (s *GhosttyConfigStep) readGhosttyConfig() (map[string]interface{}, error)
 		if idx := strings.Index(line, "="); idx > 0 {
 			key := strings.TrimSpace(line[:idx])
 			value := strings.TrimSpace(line[idx+1:])
-			settings[key] = value
+			// Repeated keys such as keybind collect every value
+			switch existing := settings[key].(type) {
+			case nil:
+				settings[key] = value
+			case string:
+				settings[key] = []string{existing, value}
+			case []string:
+				settings[key] = append(existing, value)
+			}
 		}
 	}
 
@@ -224,7 +299,9 @@ func (s *GhosttyConfigStep) writeGhosttyConfig(settings map[string]interface{})
 	sort.Strings(keys)
 
 	for _, key := range keys {
-		lines = append(lines, fmt.Sprintf("%s = %v", key, settings[key]))
+		for _, value := range ghosttyValues(settings[key]) {
+			lines = append(lines, fmt.Sprintf("%s = %s", key, value))
+		}
 	}
 	lines = append(lines, "")
 
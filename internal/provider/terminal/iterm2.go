@@ -2,13 +2,16 @@ package terminal
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"path/filepath"
+	"strconv"
 	"strings"
 
+	"github.com/google/uuid"
+
 	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
 	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/felixgeelhaar/preflight/internal/provider/plistutil"
 )
 
 // ITerm2SettingsStep manages iTerm2 preferences via defaults command.
@@ -146,6 +149,17 @@ func (s *ITerm2SettingsStep) InstalledVersion(_ compiler.RunContext) (string, er
 	return "", nil
 }
 
+const (
+	// iterm2ProfilesFile is the dynamic profiles plist preflight generates.
+	iterm2ProfilesFile = "preflight-profiles.plist"
+	// iterm2LegacyProfilesFile is the JSON file written by earlier versions;
+	// it is removed so iTerm2 does not load the profiles twice.
+	iterm2LegacyProfilesFile = "preflight-profiles.json"
+)
+
+// iterm2GUIDNamespace derives stable GUIDs for profiles declared without one.
+var iterm2GUIDNamespace = uuid.MustParse("6c1f4d8e-3b2a-5f0e-9a7d-2e4b8c1d0f3a")
+
 // ITerm2ProfilesStep manages iTerm2 dynamic profiles.
 type ITerm2ProfilesStep struct {
 	cfg         *ITerm2Config
@@ -178,7 +192,11 @@ func (s *ITerm2ProfilesStep) Check(_ compiler.RunContext) (compiler.StepStatus,
 		return compiler.StatusSatisfied, nil
 	}
 
-	profilePath := filepath.Join(s.profilesDir, "preflight-profiles.json")
+	if s.fs.Exists(filepath.Join(s.profilesDir, iterm2LegacyProfilesFile)) {
+		return compiler.StatusNeedsApply, nil
+	}
+
+	profilePath := filepath.Join(s.profilesDir, iterm2ProfilesFile)
 	if !s.fs.Exists(profilePath) {
 		return compiler.StatusNeedsApply, nil
 	}
@@ -190,12 +208,12 @@ func (s *ITerm2ProfilesStep) Check(_ compiler.RunContext) (compiler.StepStatus,
 		return compiler.StatusNeedsApply, nil //nolint:nilerr // intentional: unreadable profile means needs apply
 	}
 
-	desired, err := s.generateProfilesJSON()
+	desired, err := s.generateProfilesPlist()
 	if err != nil {
 		return compiler.StatusUnknown, err
 	}
 
-	if string(content) == desired {
+	if string(content) == string(desired) {
 		return compiler.StatusSatisfied, nil
 	}
 	return compiler.StatusNeedsApply, nil
@@ -216,7 +234,7 @@ func (s *ITerm2ProfilesStep) Plan(_ compiler.RunContext) (compiler.Diff, error)
 	return compiler.NewDiff(
 		compiler.DiffTypeModify,
 		"profiles",
-		filepath.Join(s.profilesDir, "preflight-profiles.json"),
+		filepath.Join(s.profilesDir, iterm2ProfilesFile),
 		"",
 		fmt.Sprintf("create/update %d dynamic profiles", len(s.cfg.DynamicProfiles)),
 	), nil
@@ -232,32 +250,45 @@ func (s *ITerm2ProfilesStep) Apply(_ compiler.RunContext) error {
 		return fmt.Errorf("failed to create profiles directory: %w", err)
 	}
 
-	content, err := s.generateProfilesJSON()
+	content, err := s.generateProfilesPlist()
 	if err != nil {
 		return err
 	}
 
-	profilePath := filepath.Join(s.profilesDir, "preflight-profiles.json")
-	return s.fs.WriteFile(profilePath, []byte(content), 0o644)
+	legacyPath := filepath.Join(s.profilesDir, iterm2LegacyProfilesFile)
+	if s.fs.Exists(legacyPath) {
+		if err := s.fs.Remove(legacyPath); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", iterm2LegacyProfilesFile, err)
+		}
+	}
+
+	profilePath := filepath.Join(s.profilesDir, iterm2ProfilesFile)
+	return s.fs.WriteFile(profilePath, content, 0o644)
 }
 
-// generateProfilesJSON generates the dynamic profiles JSON.
-func (s *ITerm2ProfilesStep) generateProfilesJSON() (string, error) {
-	profiles := make([]map[string]interface{}, len(s.cfg.DynamicProfiles))
+// generateProfilesPlist generates the dynamic profiles property list.
+// iTerm2 requires a Guid per profile; profiles without one get a GUID
+// derived from their name so it stays the same across runs and machines.
+func (s *ITerm2ProfilesStep) generateProfilesPlist() ([]byte, error) {
+	profiles := make([]interface{}, len(s.cfg.DynamicProfiles))
 
 	for i, p := range s.cfg.DynamicProfiles {
+		guid := p.GUID
+		if guid == "" {
+			guid = strings.ToUpper(uuid.NewSHA1(iterm2GUIDNamespace, []byte(p.Name)).String())
+		}
 		profile := map[string]interface{}{
 			"Name": p.Name,
+			"Guid": guid,
 		}
 
-		if p.GUID != "" {
-			profile["Guid"] = p.GUID
-		}
+		// iTerm2 stores the font as "<PostScript name> <size>"
 		if p.Font != "" {
-			profile["Normal Font"] = p.Font
-		}
-		if p.FontSize > 0 {
-			profile["Normal Font Size"] = p.FontSize
+			font := p.Font
+			if p.FontSize > 0 {
+				font += " " + strconv.FormatFloat(p.FontSize, 'f', -1, 64)
+			}
+			profile["Normal Font"] = font
 		}
 		if p.ColorScheme != "" {
 			profile["Color Preset"] = p.ColorScheme
@@ -269,16 +300,11 @@ func (s *ITerm2ProfilesStep) generateProfilesJSON() (string, error) {
 		profiles[i] = profile
 	}
 
-	wrapper := map[string]interface{}{
-		"Profiles": profiles,
-	}
-
-	data, err := json.MarshalIndent(wrapper, "", "  ")
+	content, err := plistutil.Encode(map[string]interface{}{"Profiles": profiles})
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal profiles: %w", err)
+		return nil, fmt.Errorf("failed to encode profiles: %w", err)
 	}
-
-	return string(data), nil
+	return content, nil
 }
 
 // Explain provides context for this step.
@@ -170,6 +170,7 @@ func (p *Provider) compileWezTerm(ctx compiler.CompileContext, cfg *Config) ([]c
 
 	step := NewWezTermConfigStep(
 		cfg.WezTerm,
+		cfg,
 		targetPath,
 		ctx.ConfigRoot(),
 		p.fs,
@@ -187,6 +188,7 @@ func (p *Provider) compileGhostty(ctx compiler.CompileContext, cfg *Config) ([]c
 
 	step := NewGhosttyConfigStep(
 		cfg.Ghostty,
+		cfg,
 		targetPath,
 		ctx.ConfigRoot(),
 		p.fs,
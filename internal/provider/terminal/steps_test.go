@@ -131,6 +131,7 @@ func TestKittyConfigStep_Check_SettingsMode_NotExists(t *testing.T) {
 func TestWezTermConfigStep_ID(t *testing.T) {
 	step := NewWezTermConfigStep(
 		&WezTermConfig{Source: "wezterm.lua"},
+		nil,
 		"/home/user/.wezterm.lua",
 		"/tmp/dotfiles",
 		mocks.NewFileSystem(),
@@ -141,6 +142,7 @@ func TestWezTermConfigStep_ID(t *testing.T) {
 func TestGhosttyConfigStep_ID(t *testing.T) {
 	step := NewGhosttyConfigStep(
 		&GhosttyConfig{Settings: map[string]interface{}{"font-size": 14}},
+		nil,
 		"/home/user/.config/ghostty/config",
 		"/tmp/dotfiles",
 		mocks.NewFileSystem(),
@@ -239,6 +241,7 @@ func TestDiscovery_FindAlacrittyConfig(t *testing.T) {
 func TestGhosttyConfigStep_DependsOn(t *testing.T) {
 	step := NewGhosttyConfigStep(
 		&GhosttyConfig{Settings: map[string]interface{}{"font-size": 14}},
+		nil,
 		"/home/user/.config/ghostty/config",
 		"/tmp/dotfiles",
 		mocks.NewFileSystem(),
@@ -250,6 +253,7 @@ func TestGhosttyConfigStep_Check_NoConfig(t *testing.T) {
 	fs := mocks.NewFileSystem()
 	step := NewGhosttyConfigStep(
 		&GhosttyConfig{}, // No source, no settings
+		nil,
 		"/home/user/.config/ghostty/config",
 		"/tmp/dotfiles",
 		fs,
@@ -265,6 +269,7 @@ func TestGhosttyConfigStep_Check_SettingsMode_NeedsApply(t *testing.T) {
 	fs := mocks.NewFileSystem()
 	step := NewGhosttyConfigStep(
 		&GhosttyConfig{Settings: map[string]interface{}{"font-size": 14}},
+		nil,
 		"/home/user/.config/ghostty/config",
 		"/tmp/dotfiles",
 		fs,
@@ -280,6 +285,7 @@ func TestGhosttyConfigStep_Plan_NoConfig(t *testing.T) {
 	fs := mocks.NewFileSystem()
 	step := NewGhosttyConfigStep(
 		&GhosttyConfig{},
+		nil,
 		"/home/user/.config/ghostty/config",
 		"/tmp/dotfiles",
 		fs,
@@ -295,6 +301,7 @@ func TestGhosttyConfigStep_Plan_WithSettings(t *testing.T) {
 	fs := mocks.NewFileSystem()
 	step := NewGhosttyConfigStep(
 		&GhosttyConfig{Settings: map[string]interface{}{"font-size": 14, "theme": "dark"}},
+		nil,
 		"/home/user/.config/ghostty/config",
 		"/tmp/dotfiles",
 		fs,
@@ -311,6 +318,7 @@ func TestGhosttyConfigStep_Plan_WithSource(t *testing.T) {
 	fs := mocks.NewFileSystem()
 	step := NewGhosttyConfigStep(
 		&GhosttyConfig{Source: "ghostty/config", Link: true},
+		nil,
 		"/home/user/.config/ghostty/config",
 		"/tmp/dotfiles",
 		fs,
@@ -327,6 +335,7 @@ func TestGhosttyConfigStep_Apply_Settings(t *testing.T) {
 	fs := mocks.NewFileSystem()
 	step := NewGhosttyConfigStep(
 		&GhosttyConfig{Settings: map[string]interface{}{"font-size": 14}},
+		nil,
 		"/tmp/test/.config/ghostty/config",
 		"/tmp/dotfiles",
 		fs,
@@ -346,6 +355,7 @@ func TestGhosttyConfigStep_Explain(t *testing.T) {
 	fs := mocks.NewFileSystem()
 	step := NewGhosttyConfigStep(
 		&GhosttyConfig{Settings: map[string]interface{}{"font-size": 14}},
+		nil,
 		"/home/user/.config/ghostty/config",
 		"/tmp/dotfiles",
 		fs,
@@ -474,6 +484,7 @@ func TestHyperConfigStep_Explain(t *testing.T) {
 func TestWezTermConfigStep_DependsOn(t *testing.T) {
 	step := NewWezTermConfigStep(
 		&WezTermConfig{Source: "wezterm.lua"},
+		nil,
 		"/home/user/.wezterm.lua",
 		"/tmp/dotfiles",
 		mocks.NewFileSystem(),
@@ -485,6 +496,7 @@ func TestWezTermConfigStep_Check_NoSource(t *testing.T) {
 	fs := mocks.NewFileSystem()
 	step := NewWezTermConfigStep(
 		&WezTermConfig{},
+		nil,
 		"/home/user/.wezterm.lua",
 		"/tmp/dotfiles",
 		fs,
@@ -500,6 +512,7 @@ func TestWezTermConfigStep_Check_NeedsApply(t *testing.T) {
 	fs := mocks.NewFileSystem()
 	step := NewWezTermConfigStep(
 		&WezTermConfig{Source: "wezterm.lua"},
+		nil,
 		"/home/user/.wezterm.lua",
 		"/tmp/dotfiles",
 		fs,
@@ -515,6 +528,7 @@ func TestWezTermConfigStep_Plan_NoSource(t *testing.T) {
 	fs := mocks.NewFileSystem()
 	step := NewWezTermConfigStep(
 		&WezTermConfig{},
+		nil,
 		"/home/user/.wezterm.lua",
 		"/tmp/dotfiles",
 		fs,
@@ -530,6 +544,7 @@ func TestWezTermConfigStep_Plan_WithSource(t *testing.T) {
 	fs := mocks.NewFileSystem()
 	step := NewWezTermConfigStep(
 		&WezTermConfig{Source: "wezterm.lua", Link: true},
+		nil,
 		"/home/user/.wezterm.lua",
 		"/tmp/dotfiles",
 		fs,
@@ -550,6 +565,7 @@ func TestWezTermConfigStep_Apply_Link(t *testing.T) {
 
 	step := NewWezTermConfigStep(
 		&WezTermConfig{Source: "wezterm.lua", Link: true},
+		nil,
 		"/tmp/test/.wezterm.lua",
 		"/tmp/dotfiles",
 		fs,
@@ -564,6 +580,7 @@ func TestWezTermConfigStep_Explain(t *testing.T) {
 	fs := mocks.NewFileSystem()
 	step := NewWezTermConfigStep(
 		&WezTermConfig{Source: "wezterm.lua"},
+		nil,
 		"/home/user/.wezterm.lua",
 		"/tmp/dotfiles",
 		fs,
@@ -853,8 +870,8 @@ func TestITerm2ProfilesStep_Apply(t *testing.T) {
 	err := step.Apply(ctx)
 	require.NoError(t, err)
 
-	// Verify file was written (preflight-profiles.json)
-	content, err := fs.ReadFile("/tmp/test/DynamicProfiles/preflight-profiles.json")
+	// Verify file was written (preflight-profiles.plist)
+	content, err := fs.ReadFile("/tmp/test/DynamicProfiles/preflight-profiles.plist")
 	require.NoError(t, err)
 	assert.Contains(t, string(content), "Test")
 }
@@ -1201,6 +1218,7 @@ func TestGhosttyConfigStep_Apply_SourceMode(t *testing.T) {
 
 	step := NewGhosttyConfigStep(
 		&GhosttyConfig{Source: "ghostty.conf", Link: true},
+		nil,
 		"/home/user/.config/ghostty/config",
 		"/tmp/dotfiles",
 		fs,
@@ -1222,6 +1240,7 @@ func TestGhosttyConfigStep_Check_CopyMode_Satisfied(t *testing.T) {
 
 	step := NewGhosttyConfigStep(
 		&GhosttyConfig{Source: "ghostty.conf", Link: false},
+		nil,
 		"/home/user/.config/ghostty/config",
 		"/tmp/dotfiles",
 		fs,
@@ -1274,6 +1293,7 @@ func TestWezTermConfigStep_Check_CopyMode_Satisfied(t *testing.T) {
 
 	step := NewWezTermConfigStep(
 		&WezTermConfig{Source: "wezterm.lua", Link: false},
+		nil,
 		"/home/user/.config/wezterm/wezterm.lua",
 		"/tmp/dotfiles",
 		fs,
@@ -1389,6 +1409,7 @@ func TestGhosttyConfigStep_LockInfo(t *testing.T) {
 	fs := mocks.NewFileSystem()
 	step := NewGhosttyConfigStep(
 		&GhosttyConfig{Settings: map[string]interface{}{"font-size": 14}},
+		nil,
 		"/home/user/.config/ghostty/config",
 		"/tmp/dotfiles",
 		fs,
@@ -1415,6 +1436,7 @@ func TestWezTermConfigStep_LockInfo(t *testing.T) {
 	fs := mocks.NewFileSystem()
 	step := NewWezTermConfigStep(
 		&WezTermConfig{Source: "wezterm.lua"},
+		nil,
 		"/home/user/.config/wezterm/wezterm.lua",
 		"/tmp/dotfiles",
 		fs,
@@ -1472,3 +1494,153 @@ func TestITerm2ProfilesStep_LockInfo(t *testing.T) {
 	info := step.LockInfo()
 	assert.Empty(t, info.Provider)
 }
+
+// =============================================================================
+// Shared font, theme and keybinding Tests
+// =============================================================================
+
+func sharedTerminalConfig() *Config {
+	return &Config{
+		Font: &FontConfig{Family: "JetBrains Mono", Size: 13},
+		Theme: &ThemeConfig{Name: "Catppuccin Mocha", Custom: map[string]string{
+			"background": "#1e1e2e",
+			"red":        "#f38ba8",
+			"bright_red": "#ff0000",
+		}},
+		Keybindings: []Keybinding{{Keys: "ctrl+shift+t", Action: "new_tab"}},
+	}
+}
+
+func TestSplitKeys(t *testing.T) {
+	mods, key := splitKeys("ctrl+shift+t")
+	assert.Equal(t, []string{"ctrl", "shift"}, mods)
+	assert.Equal(t, "t", key)
+
+	mods, key = splitKeys("cmd++")
+	assert.Equal(t, []string{"cmd"}, mods)
+	assert.Equal(t, "+", key)
+
+	mods, key = splitKeys("f11")
+	assert.Empty(t, mods)
+	assert.Equal(t, "f11", key)
+}
+
+func TestAlacrittyConfigStep_SharedConfig(t *testing.T) {
+	fs := mocks.NewFileSystem()
+	step := NewAlacrittyConfigStep(&AlacrittyConfig{}, sharedTerminalConfig(), "/home/user/.config/alacritty/alacritty.toml", "/tmp/dotfiles", fs)
+	ctx := compiler.NewRunContext(context.Background())
+
+	require.NoError(t, step.Apply(ctx))
+	content, err := fs.ReadFile("/home/user/.config/alacritty/alacritty.toml")
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "background = '#1e1e2e'")
+	assert.Contains(t, string(content), "red = '#ff0000'")
+	assert.Contains(t, string(content), "mods = 'Control|Shift'")
+	assert.Contains(t, string(content), "key = 'T'")
+
+	status, err := step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, status)
+}
+
+func TestGhosttyConfigStep_SharedConfig(t *testing.T) {
+	fs := mocks.NewFileSystem()
+	step := NewGhosttyConfigStep(&GhosttyConfig{}, sharedTerminalConfig(), "/home/user/.config/ghostty/config", "/tmp/dotfiles", fs)
+	ctx := compiler.NewRunContext(context.Background())
+
+	require.NoError(t, step.Apply(ctx))
+	content, err := fs.ReadFile("/home/user/.config/ghostty/config")
+	require.NoError(t, err)
+	for _, line := range []string{
+		"font-family = JetBrains Mono",
+		"font-size = 13",
+		"theme = Catppuccin Mocha",
+		"background = #1e1e2e",
+		"palette = 1=#f38ba8",
+		"palette = 9=#ff0000",
+		"keybind = ctrl+shift+t=new_tab",
+	} {
+		assert.Contains(t, string(content), line+"\n")
+	}
+
+	status, err := step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, status)
+}
+
+func TestWezTermConfigStep_Generate(t *testing.T) {
+	fs := mocks.NewFileSystem()
+	step := NewWezTermConfigStep(
+		&WezTermConfig{Settings: map[string]interface{}{"hide_tab_bar_if_only_one_tab": true}},
+		sharedTerminalConfig(),
+		"/home/user/.config/wezterm/wezterm.lua",
+		"/tmp/dotfiles",
+		fs,
+	)
+	ctx := compiler.NewRunContext(context.Background())
+
+	status, err := step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+
+	require.NoError(t, step.Apply(ctx))
+	content, err := fs.ReadFile("/home/user/.config/wezterm/wezterm.lua")
+	require.NoError(t, err)
+	for _, line := range []string{
+		`config.font = wezterm.font("JetBrains Mono")`,
+		`config.font_size = 13`,
+		`config.color_scheme = "Catppuccin Mocha"`,
+		`  background = "#1e1e2e",`,
+		`  { key = "t", mods = "CTRL|SHIFT", action = wezterm.action.new_tab },`,
+		`config.hide_tab_bar_if_only_one_tab = true`,
+		`return config`,
+	} {
+		assert.Contains(t, string(content), line+"\n")
+	}
+	// Only two of the eight ANSI colors are set, so the palette is left alone
+	assert.NotContains(t, string(content), "ansi")
+
+	status, err = step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, status)
+}
+
+func TestWezTermConfigStep_Generate_RefusesHandWrittenConfig(t *testing.T) {
+	fs := mocks.NewFileSystem()
+	fs.AddFile("/home/user/.config/wezterm/wezterm.lua", "return {}\n")
+	step := NewWezTermConfigStep(&WezTermConfig{}, sharedTerminalConfig(), "/home/user/.config/wezterm/wezterm.lua", "/tmp/dotfiles", fs)
+
+	err := step.Apply(compiler.NewRunContext(context.Background()))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not generated by preflight")
+}
+
+func TestITerm2ProfilesStep_Apply_Plist(t *testing.T) {
+	fs := mocks.NewFileSystem()
+	fs.AddFile("/tmp/test/DynamicProfiles/preflight-profiles.json", `{"Profiles":[]}`)
+	step := NewITerm2ProfilesStep(
+		&ITerm2Config{DynamicProfiles: []ITerm2Profile{{Name: "Work & Play", Font: "JetBrainsMono-Regular", FontSize: 13}}},
+		"/tmp/test/DynamicProfiles",
+		fs,
+	)
+	ctx := compiler.NewRunContext(context.Background())
+
+	status, err := step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+
+	require.NoError(t, step.Apply(ctx))
+	assert.False(t, fs.Exists("/tmp/test/DynamicProfiles/preflight-profiles.json"))
+
+	content, err := fs.ReadFile("/tmp/test/DynamicProfiles/preflight-profiles.plist")
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `<plist version="1.0">`)
+	assert.Contains(t, string(content), "<string>Work &amp; Play</string>")
+	assert.Contains(t, string(content), "<string>JetBrainsMono-Regular 13</string>")
+	assert.Contains(t, string(content), "<key>Guid</key>")
+
+	// The derived GUID is stable, so a second run is a no-op
+	status, err = step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, status)
+}
@@ -0,0 +1,87 @@
+package terminal
+
+import (
+	"sort"
+	"strings"
+)
+
+// ansiColors are the ANSI color names in palette order. Their bright
+// variants are named bright_<color> and follow at indices 8-15.
+var ansiColors = []string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+// ansiIndex returns the palette index (0-15) of a custom theme color name.
+func ansiIndex(name string) (int, bool) {
+	offset := 0
+	if rest, ok := strings.CutPrefix(name, "bright_"); ok {
+		name, offset = rest, 8
+	}
+	for i, color := range ansiColors {
+		if color == name {
+			return i + offset, true
+		}
+	}
+	return 0, false
+}
+
+// splitKeys splits a chord such as "ctrl+shift+t" into its modifiers and
+// key. A trailing "+" is the plus key itself.
+func splitKeys(keys string) (mods []string, key string) {
+	if strings.HasSuffix(keys, "++") || keys == "+" {
+		return splitMods(strings.TrimSuffix(strings.TrimSuffix(keys, "+"), "+")), "+"
+	}
+	parts := strings.Split(keys, "+")
+	return splitMods(strings.Join(parts[:len(parts)-1], "+")), parts[len(parts)-1]
+}
+
+func splitMods(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "+")
+}
+
+// canonicalMod maps the modifier spellings users write to one name per
+// modifier: ctrl, shift, alt or super.
+func canonicalMod(mod string) string {
+	switch strings.ToLower(mod) {
+	case "control", "ctrl":
+		return "ctrl"
+	case "alt", "opt", "option", "meta":
+		return "alt"
+	case "cmd", "command", "super", "win":
+		return "super"
+	default:
+		return strings.ToLower(mod)
+	}
+}
+
+// themeColor returns a custom theme color, or "" when it is not set.
+func (c *Config) themeColor(name string) string {
+	if c == nil || c.Theme == nil {
+		return ""
+	}
+	return c.Theme.Custom[name]
+}
+
+// paletteColors returns the custom ANSI colors keyed by palette index.
+func (c *Config) paletteColors() map[int]string {
+	palette := make(map[int]string)
+	if c == nil || c.Theme == nil {
+		return palette
+	}
+	for name, color := range c.Theme.Custom {
+		if i, ok := ansiIndex(name); ok {
+			palette[i] = color
+		}
+	}
+	return palette
+}
+
+func sortedIndexes(m map[int]string) []int {
+	indexes := make([]int, 0, len(m))
+	for i := range m {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	return indexes
+}
@@ -4,25 +4,35 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
 	"github.com/felixgeelhaar/preflight/internal/ports"
 	"github.com/felixgeelhaar/preflight/internal/provider/pathutil"
 )
 
-// WezTermConfigStep manages WezTerm configuration.
-// Note: WezTerm uses Lua, so we only support source/link mode, not settings merge.
+// weztermHeader marks a wezterm.lua generated by preflight.
+const weztermHeader = "-- WezTerm configuration managed by preflight"
+
+// WezTermConfigStep manages WezTerm configuration. WezTerm is configured
+// in Lua, so instead of merging into an existing file the step either links
+// or copies a source file, or generates wezterm.lua from the settings and
+// the global font, theme and keybindings.
 type WezTermConfigStep struct {
 	cfg        *WezTermConfig
+	globalCfg  *Config
 	targetPath string
 	configRoot string
 	fs         ports.FileSystem
 }
 
 // NewWezTermConfigStep creates a new WezTerm config step.
-func NewWezTermConfigStep(cfg *WezTermConfig, targetPath, configRoot string, fs ports.FileSystem) *WezTermConfigStep {
+func NewWezTermConfigStep(cfg *WezTermConfig, globalCfg *Config, targetPath, configRoot string, fs ports.FileSystem) *WezTermConfigStep {
 	return &WezTermConfigStep{
 		cfg:        cfg,
+		globalCfg:  globalCfg,
 		targetPath: pathutil.ExpandPath(targetPath),
 		configRoot: configRoot,
 		fs:         fs,
@@ -42,8 +52,7 @@ func (s *WezTermConfigStep) DependsOn() []compiler.StepID {
 // Check determines if configuration needs to be applied.
 func (s *WezTermConfigStep) Check(_ compiler.RunContext) (compiler.StepStatus, error) {
 	if s.cfg.Source == "" {
-		// No source specified, nothing to do
-		return compiler.StatusSatisfied, nil
+		return s.checkGenerated()
 	}
 
 	sourcePath := filepath.Join(s.configRoot, s.cfg.Source)
@@ -87,15 +96,141 @@ func (s *WezTermConfigStep) Check(_ compiler.RunContext) (compiler.StepStatus, e
 	return compiler.StatusNeedsApply, nil
 }
 
+func (s *WezTermConfigStep) checkGenerated() (compiler.StepStatus, error) {
+	content := s.generateLua()
+	if content == "" {
+		// Nothing declared, nothing to do
+		return compiler.StatusSatisfied, nil
+	}
+
+	existing, err := s.fs.ReadFile(s.targetPath)
+	if err != nil {
+		return compiler.StatusNeedsApply, nil //nolint:nilerr // intentional: missing config means needs apply
+	}
+	if string(existing) == content {
+		return compiler.StatusSatisfied, nil
+	}
+	return compiler.StatusNeedsApply, nil
+}
+
+// generateLua renders wezterm.lua, or returns "" when neither settings nor
+// a global font, theme or keybindings are declared.
+func (s *WezTermConfigStep) generateLua() string {
+	g := s.globalCfg
+	if g == nil {
+		g = &Config{}
+	}
+	var lines []string
+	if g.Font != nil && g.Font.Family != "" {
+		lines = append(lines, fmt.Sprintf("config.font = wezterm.font(%s)", luaValue(g.Font.Family)))
+	}
+	if g.Font != nil && g.Font.Size > 0 {
+		lines = append(lines, fmt.Sprintf("config.font_size = %s", luaValue(g.Font.Size)))
+	}
+	if g.Theme != nil && g.Theme.Name != "" {
+		lines = append(lines, fmt.Sprintf("config.color_scheme = %s", luaValue(g.Theme.Name)))
+	}
+
+	var colors []string
+	for _, name := range []string{"background", "foreground"} {
+		if color := g.themeColor(name); color != "" {
+			colors = append(colors, fmt.Sprintf("  %s = %s,", name, luaValue(color)))
+		}
+	}
+	// WezTerm replaces the whole palette, so ANSI colors need all eight
+	palette := g.paletteColors()
+	for _, group := range []struct {
+		name   string
+		offset int
+	}{{"ansi", 0}, {"brights", 8}} {
+		values := make([]string, 0, 8)
+		for i := group.offset; i < group.offset+8; i++ {
+			if color, ok := palette[i]; ok {
+				values = append(values, luaValue(color))
+			}
+		}
+		if len(values) == 8 {
+			colors = append(colors, fmt.Sprintf("  %s = { %s },", group.name, strings.Join(values, ", ")))
+		}
+	}
+	if len(colors) > 0 {
+		lines = append(lines, "config.colors = {")
+		lines = append(lines, colors...)
+		lines = append(lines, "}")
+	}
+
+	if len(g.Keybindings) > 0 {
+		lines = append(lines, "config.keys = {")
+		for _, kb := range g.Keybindings {
+			mods, key := splitKeys(kb.Keys)
+			for i := range mods {
+				mods[i] = strings.ToUpper(canonicalMod(mods[i]))
+			}
+			binding := fmt.Sprintf("key = %s", luaValue(key))
+			if len(mods) > 0 {
+				binding += fmt.Sprintf(", mods = %s", luaValue(strings.Join(mods, "|")))
+			}
+			lines = append(lines, fmt.Sprintf("  { %s, action = wezterm.action.%s },", binding, kb.Action))
+		}
+		lines = append(lines, "}")
+	}
+
+	keys := make([]string, 0, len(s.cfg.Settings))
+	for key := range s.cfg.Settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		lines = append(lines, fmt.Sprintf("config.%s = %s", key, luaValue(s.cfg.Settings[key])))
+	}
+
+	if len(lines) == 0 {
+		return ""
+	}
+	header := []string{
+		weztermHeader,
+		"local wezterm = require 'wezterm'",
+		"local config = wezterm.config_builder()",
+		"",
+	}
+	footer := []string{"", "return config", ""}
+	return strings.Join(append(append(header, lines...), footer...), "\n")
+}
+
+// luaValue renders a scalar setting as a Lua literal.
+func luaValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strconv.Quote(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int, int64:
+		return fmt.Sprintf("%d", v)
+	default:
+		return strconv.Quote(fmt.Sprintf("%v", v))
+	}
+}
+
 // Plan returns the diff for this step.
 func (s *WezTermConfigStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
 	if s.cfg.Source == "" {
+		if s.generateLua() == "" {
+			return compiler.NewDiff(
+				compiler.DiffTypeNone,
+				"config",
+				s.targetPath,
+				"",
+				"no source specified",
+			), nil
+		}
 		return compiler.NewDiff(
-			compiler.DiffTypeNone,
+			compiler.DiffTypeModify,
 			"config",
 			s.targetPath,
 			"",
-			"no source specified",
+			"generate from settings",
 		), nil
 	}
 
@@ -114,8 +249,11 @@ func (s *WezTermConfigStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
 
 // Apply writes the configuration.
 func (s *WezTermConfigStep) Apply(_ compiler.RunContext) error {
+	content := ""
 	if s.cfg.Source == "" {
-		return nil
+		if content = s.generateLua(); content == "" {
+			return nil
+		}
 	}
 
 	dir := filepath.Dir(s.targetPath)
@@ -123,6 +261,14 @@ func (s *WezTermConfigStep) Apply(_ compiler.RunContext) error {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
+	if content != "" {
+		// Hand-written Lua cannot be merged into, so never replace it
+		if existing, err := s.fs.ReadFile(s.targetPath); err == nil && !strings.HasPrefix(string(existing), weztermHeader) {
+			return fmt.Errorf("%s was not generated by preflight; manage it with terminal.wezterm.source instead", s.targetPath)
+		}
+		return s.fs.WriteFile(s.targetPath, []byte(content), 0o644)
+	}
+
 	sourcePath := filepath.Join(s.configRoot, s.cfg.Source)
 
 	// Remove existing file/symlink
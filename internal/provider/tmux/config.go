@@ -2,13 +2,17 @@ package tmux
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 )
 
 // Config represents the tmux section of the configuration.
 type Config struct {
-	Plugins    []string
-	Settings   map[string]string
-	ConfigFile string
+	Plugins       []string
+	Settings      map[string]string
+	ConfigFile    string
+	UpdatePlugins bool
 }
 
 // ParseConfig parses the tmux configuration from a raw map.
@@ -47,5 +51,44 @@ func ParseConfig(raw map[string]interface{}) (*Config, error) {
 		cfg.ConfigFile = configFile
 	}
 
+	// Parse plugin update policy
+	if update, ok := raw["update_plugins"].(bool); ok {
+		cfg.UpdatePlugins = update
+	}
+
 	return cfg, nil
 }
+
+// ParseConf extracts TPM plugins and global options from tmux.conf
+// content. Option values are kept as written, including quotes.
+func ParseConf(content string) (plugins []string, settings map[string]string) {
+	settings = make(map[string]string)
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || (fields[0] != "set" && fields[0] != "set-option") || fields[1] != "-g" {
+			continue
+		}
+		key := fields[2]
+		value := strings.Join(fields[3:], " ")
+		if key == "@plugin" {
+			plugins = append(plugins, strings.Trim(value, `'"`))
+			continue
+		}
+		settings[key] = value
+	}
+	return plugins, settings
+}
+
+var versionRe = regexp.MustCompile(`(\d+)\.(\d+)`)
+
+// ParseVersion extracts the major and minor version from 'tmux -V' output,
+// such as "tmux 3.3a" or "tmux next-3.4".
+func ParseVersion(output string) (major, minor int, ok bool) {
+	m := versionRe.FindStringSubmatch(output)
+	if m == nil {
+		return 0, 0, false
+	}
+	major, _ = strconv.Atoi(m[1])
+	minor, _ = strconv.Atoi(m[2])
+	return major, minor, true
+}
@@ -132,3 +132,45 @@ func TestParseConfig_SettingsWithNonStringValues(t *testing.T) {
 	assert.Len(t, cfg.Settings, 1)
 	assert.Equal(t, "C-a", cfg.Settings["prefix"])
 }
+
+func TestParseConf(t *testing.T) {
+	t.Parallel()
+
+	plugins, settings := tmux.ParseConf(`# comment
+set -g prefix C-a
+set-option -g mouse on
+set -g @plugin 'tmux-plugins/tpm'
+set -g @plugin "tmux-plugins/tmux-sensible"
+set -g @continuum-restore 'on'
+setw -g mode-keys vi
+run '~/.tmux/plugins/tpm/tpm'
+`)
+
+	assert.Equal(t, []string{"tmux-plugins/tpm", "tmux-plugins/tmux-sensible"}, plugins)
+	assert.Equal(t, map[string]string{
+		"prefix":             "C-a",
+		"mouse":              "on",
+		"@continuum-restore": "'on'",
+	}, settings)
+}
+
+func TestParseVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		output       string
+		major, minor int
+		ok           bool
+	}{
+		{"tmux 3.3a\n", 3, 3, true},
+		{"tmux next-3.4", 3, 4, true},
+		{"tmux 1.8", 1, 8, true},
+		{"tmux master", 0, 0, false},
+	}
+	for _, tt := range tests {
+		major, minor, ok := tmux.ParseVersion(tt.output)
+		assert.Equal(t, tt.ok, ok, tt.output)
+		assert.Equal(t, tt.major, major, tt.output)
+		assert.Equal(t, tt.minor, minor, tt.output)
+	}
+}
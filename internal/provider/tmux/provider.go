@@ -41,9 +41,20 @@ func (p *Provider) Compile(ctx compiler.CompileContext) ([]compiler.Step, error)
 	}
 
 	// Add plugin steps
+	tpmStep := compiler.MustNewStepID("tmux:tpm")
+	installDeps := []compiler.StepID{tpmStep}
 	for _, plugin := range cfg.Plugins {
-		tpmStep := compiler.MustNewStepID("tmux:tpm")
-		steps = append(steps, NewPluginStep(plugin, tpmStep, p.runner))
+		step := NewPluginStep(plugin, tpmStep, p.runner)
+		steps = append(steps, step)
+		installDeps = append(installDeps, step.ID())
+	}
+
+	// Install (and optionally update) the declared plugins through TPM
+	if len(cfg.Plugins) > 0 {
+		steps = append(steps, NewInstallPluginsStep(cfg.Plugins, installDeps, p.runner))
+		if cfg.UpdatePlugins {
+			steps = append(steps, NewUpdatePluginsStep(cfg.Plugins, p.runner))
+		}
 	}
 
 	// Add config step if settings are defined
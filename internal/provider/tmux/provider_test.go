@@ -61,9 +61,11 @@ func TestProvider_Compile_WithPlugins(t *testing.T) {
 	steps, err := p.Compile(ctx)
 
 	require.NoError(t, err)
-	// TPM step + 2 plugin steps
-	require.Len(t, steps, 3)
+	// TPM step + 2 plugin steps + install step
+	require.Len(t, steps, 4)
 	assert.Equal(t, "tmux:tpm", steps[0].ID().String())
+	assert.Equal(t, "tmux:plugins:install", steps[3].ID().String())
+	assert.Len(t, steps[3].DependsOn(), 3)
 }
 
 func TestProvider_Compile_WithSettings(t *testing.T) {
@@ -121,6 +123,23 @@ func TestProvider_Compile_Complete(t *testing.T) {
 	steps, err := p.Compile(ctx)
 
 	require.NoError(t, err)
-	// TPM + plugin + config
-	require.Len(t, steps, 3)
+	// TPM + plugin + install + config
+	require.Len(t, steps, 4)
+}
+
+func TestProvider_Compile_UpdatePlugins(t *testing.T) {
+	t.Parallel()
+
+	p := tmux.NewProvider(mocks.NewCommandRunner())
+	ctx := compiler.NewCompileContext(map[string]interface{}{
+		"tmux": map[string]interface{}{
+			"plugins":        []interface{}{"tmux-plugins/tmux-sensible"},
+			"update_plugins": true,
+		},
+	})
+	steps, err := p.Compile(ctx)
+
+	require.NoError(t, err)
+	require.Len(t, steps, 4)
+	assert.Equal(t, "tmux:plugins:update", steps[3].ID().String())
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
@@ -38,6 +39,12 @@ func getTmuxConfigPath() string {
 	return discovery.BestPracticePath()
 }
 
+// ConfigPath returns the tmux config file preflight manages: the existing
+// config if one is found, otherwise the best-practice location.
+func ConfigPath() string {
+	return getTmuxConfigPath()
+}
+
 // TPMStep represents a TPM (Tmux Plugin Manager) installation step.
 type TPMStep struct {
 	id     compiler.StepID
@@ -264,26 +271,37 @@ func (s *ConfigStep) Apply(_ compiler.RunContext) error {
 	settingsApplied := make(map[string]bool)
 
 	for _, line := range lines {
-		updated := false
-		for key, value := range s.settings {
-			if strings.HasPrefix(strings.TrimSpace(line), fmt.Sprintf("set -g %s", key)) {
-				newLines = append(newLines, fmt.Sprintf("set -g %s %s", key, value))
-				settingsApplied[key] = true
-				updated = true
-				break
+		fields := strings.Fields(line)
+		if len(fields) >= 3 && fields[0] == "set" && fields[1] == "-g" {
+			if value, ok := s.settings[fields[2]]; ok {
+				newLines = append(newLines, fmt.Sprintf("set -g %s %s", fields[2], value))
+				settingsApplied[fields[2]] = true
+				continue
 			}
 		}
-		if !updated {
-			newLines = append(newLines, line)
-		}
+		newLines = append(newLines, line)
 	}
 
-	// Add any settings that weren't updated
-	for key, value := range s.settings {
+	// Add any settings that weren't updated, in a stable order and ahead
+	// of the TPM run line, which has to stay last
+	keys := make([]string, 0, len(s.settings))
+	for key := range s.settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var added []string
+	for _, key := range keys {
 		if !settingsApplied[key] {
-			newLines = append(newLines, fmt.Sprintf("set -g %s %s", key, value))
+			added = append(added, fmt.Sprintf("set -g %s %s", key, s.settings[key]))
+		}
+	}
+	insertAt := len(newLines)
+	for i, line := range newLines {
+		if isTPMRunLine(line) {
+			insertAt = i
 		}
 	}
+	newLines = append(newLines[:insertAt], append(added, newLines[insertAt:]...)...)
 
 	// Ensure parent directory exists (important for XDG paths)
 	if err := os.MkdirAll(filepath.Dir(configPath), 0o755); err != nil {
@@ -306,3 +324,210 @@ func (s *ConfigStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
 		"+ Reload with 'tmux source ~/.tmux.conf'",
 	})
 }
+
+// tpmRunLine is the line that initializes TPM; it must stay at the bottom
+// of tmux.conf so every @plugin declaration is seen first.
+func tpmRunLine(tpmPath string) string {
+	return fmt.Sprintf("run '%s'", filepath.Join(tpmPath, "tpm"))
+}
+
+func isTPMRunLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, "run") && strings.Contains(trimmed, "tpm/tpm")
+}
+
+// ensureTPMRunLine moves or adds the TPM run line to the end of content.
+func ensureTPMRunLine(content, tpmPath string) string {
+	runLine := tpmRunLine(tpmPath)
+	var kept []string
+	for _, line := range strings.Split(strings.TrimRight(content, "\n"), "\n") {
+		if isTPMRunLine(line) {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	if len(kept) == 1 && kept[0] == "" {
+		kept = nil
+	}
+	return strings.Join(append(kept, runLine), "\n") + "\n"
+}
+
+// PluginDir returns the directory TPM installs plugin into. Plugins may
+// carry a branch suffix (owner/repo#branch) or be full git URLs.
+func PluginDir(tpmPath, plugin string) string {
+	name, _, _ := strings.Cut(plugin, "#")
+	name = strings.TrimSuffix(name, ".git")
+	return filepath.Join(filepath.Dir(tpmPath), filepath.Base(name))
+}
+
+// InstallPluginsStep installs declared plugins through TPM and makes sure
+// tmux.conf initializes TPM.
+type InstallPluginsStep struct {
+	plugins []string
+	deps    []compiler.StepID
+	id      compiler.StepID
+	runner  ports.CommandRunner
+}
+
+// NewInstallPluginsStep creates a new InstallPluginsStep that runs after deps.
+func NewInstallPluginsStep(plugins []string, deps []compiler.StepID, runner ports.CommandRunner) *InstallPluginsStep {
+	return &InstallPluginsStep{
+		plugins: plugins,
+		deps:    deps,
+		id:      compiler.MustNewStepID("tmux:plugins:install"),
+		runner:  runner,
+	}
+}
+
+// ID returns the step identifier.
+func (s *InstallPluginsStep) ID() compiler.StepID {
+	return s.id
+}
+
+// DependsOn returns the step dependencies.
+func (s *InstallPluginsStep) DependsOn() []compiler.StepID {
+	return s.deps
+}
+
+// Check determines if TPM is initialized and every plugin is installed.
+func (s *InstallPluginsStep) Check(_ compiler.RunContext) (compiler.StepStatus, error) {
+	tpmPath := getTPMPath()
+	data, err := os.ReadFile(getTmuxConfigPath())
+	if err != nil || !strings.Contains(string(data), tpmRunLine(tpmPath)) {
+		return compiler.StatusNeedsApply, nil //nolint:nilerr // missing config means needs apply
+	}
+	for _, plugin := range s.plugins {
+		if _, err := os.Stat(PluginDir(tpmPath, plugin)); err != nil {
+			return compiler.StatusNeedsApply, nil
+		}
+	}
+	return compiler.StatusSatisfied, nil
+}
+
+// Plan returns the diff for this step.
+func (s *InstallPluginsStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
+	return compiler.NewDiff(compiler.DiffTypeAdd, "plugins", "tpm", "", fmt.Sprintf("install %d plugins", len(s.plugins))), nil
+}
+
+// Apply initializes TPM in tmux.conf and installs missing plugins.
+func (s *InstallPluginsStep) Apply(ctx compiler.RunContext) error {
+	tpmPath := getTPMPath()
+	configPath := getTmuxConfigPath()
+
+	content, _ := os.ReadFile(configPath)
+	if err := os.MkdirAll(filepath.Dir(configPath), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(configPath, []byte(ensureTPMRunLine(string(content), tpmPath)), 0o644); err != nil {
+		return err
+	}
+
+	result, err := s.runner.Run(ctx.Context(), filepath.Join(tpmPath, "bin", "install_plugins"))
+	if err != nil {
+		return err
+	}
+	if !result.Success() {
+		return fmt.Errorf("tpm install_plugins failed: %s", result.Stderr)
+	}
+	return nil
+}
+
+// Explain provides a human-readable explanation.
+func (s *InstallPluginsStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
+	return compiler.NewExplanation(
+		"Install tmux Plugins",
+		fmt.Sprintf("Installs %d tmux plugins with TPM without starting tmux", len(s.plugins)),
+		[]string{
+			"https://github.com/tmux-plugins/tpm",
+		},
+	)
+}
+
+// UpdatePluginsStep keeps installed plugins at their upstream HEAD.
+type UpdatePluginsStep struct {
+	plugins []string
+	id      compiler.StepID
+	runner  ports.CommandRunner
+}
+
+// NewUpdatePluginsStep creates a new UpdatePluginsStep.
+func NewUpdatePluginsStep(plugins []string, runner ports.CommandRunner) *UpdatePluginsStep {
+	return &UpdatePluginsStep{
+		plugins: plugins,
+		id:      compiler.MustNewStepID("tmux:plugins:update"),
+		runner:  runner,
+	}
+}
+
+// ID returns the step identifier.
+func (s *UpdatePluginsStep) ID() compiler.StepID {
+	return s.id
+}
+
+// DependsOn returns the step dependencies.
+func (s *UpdatePluginsStep) DependsOn() []compiler.StepID {
+	return []compiler.StepID{compiler.MustNewStepID("tmux:plugins:install")}
+}
+
+// Check compares each plugin checkout with its remote. Plugins whose
+// remote cannot be reached are treated as up to date.
+func (s *UpdatePluginsStep) Check(ctx compiler.RunContext) (compiler.StepStatus, error) {
+	if len(s.Outdated(ctx)) > 0 {
+		return compiler.StatusNeedsApply, nil
+	}
+	return compiler.StatusSatisfied, nil
+}
+
+// Outdated returns the plugins whose checkout is behind the remote HEAD.
+func (s *UpdatePluginsStep) Outdated(ctx compiler.RunContext) []string {
+	tpmPath := getTPMPath()
+	var outdated []string
+	for _, plugin := range s.plugins {
+		dir := PluginDir(tpmPath, plugin)
+		head, err := s.runner.Run(ctx.Context(), "git", "-C", dir, "rev-parse", "HEAD")
+		if err != nil || !head.Success() {
+			continue
+		}
+		remote, err := s.runner.Run(ctx.Context(), "git", "-C", dir, "ls-remote", "origin", "HEAD")
+		if err != nil || !remote.Success() {
+			continue
+		}
+		fields := strings.Fields(remote.Stdout)
+		if len(fields) > 0 && fields[0] != strings.TrimSpace(head.Stdout) {
+			outdated = append(outdated, plugin)
+		}
+	}
+	return outdated
+}
+
+// Plan returns the diff for this step.
+func (s *UpdatePluginsStep) Plan(ctx compiler.RunContext) (compiler.Diff, error) {
+	outdated := s.Outdated(ctx)
+	return compiler.NewDiff(compiler.DiffTypeModify, "plugins", "tpm", "", fmt.Sprintf("update %s", strings.Join(outdated, ", "))), nil
+}
+
+// Apply updates all plugins through TPM.
+func (s *UpdatePluginsStep) Apply(ctx compiler.RunContext) error {
+	result, err := s.runner.Run(ctx.Context(), filepath.Join(getTPMPath(), "bin", "update_plugins"), "all")
+	if err != nil {
+		return err
+	}
+	if !result.Success() {
+		return fmt.Errorf("tpm update_plugins failed: %s", result.Stderr)
+	}
+	return nil
+}
+
+// Explain provides a human-readable explanation.
+func (s *UpdatePluginsStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
+	return compiler.NewExplanation(
+		"Update tmux Plugins",
+		"Updates tmux plugins whose checkout is behind upstream",
+		[]string{
+			"https://github.com/tmux-plugins/tpm",
+		},
+	).WithTradeoffs([]string{
+		"+ Plugins stay current without manual prefix + U",
+		"- Checking needs network access to each plugin remote",
+	})
+}
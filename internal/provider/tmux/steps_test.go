@@ -506,3 +506,79 @@ func TestConfigStep_Apply_UpdateExisting(t *testing.T) {
 	assert.Contains(t, string(data), "set -g mouse on")
 	assert.Contains(t, string(data), "set -g status on") // Preserved
 }
+
+// =============================================================================
+// Plugin install/update Tests
+// =============================================================================
+
+func TestPluginDir(t *testing.T) {
+	t.Parallel()
+
+	tpm := "/home/me/.tmux/plugins/tpm"
+	assert.Equal(t, "/home/me/.tmux/plugins/tmux-sensible", tmux.PluginDir(tpm, "tmux-plugins/tmux-sensible"))
+	assert.Equal(t, "/home/me/.tmux/plugins/tmux-yank", tmux.PluginDir(tpm, "tmux-plugins/tmux-yank#v2.3.0"))
+	assert.Equal(t, "/home/me/.tmux/plugins/tmux", tmux.PluginDir(tpm, "https://github.com/dracula/tmux.git#master"))
+}
+
+func TestInstallPluginsStep(t *testing.T) {
+	// Cannot use t.Parallel() with t.Setenv()
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	tpmPath := filepath.Join(tmpDir, ".tmux", "plugins", "tpm")
+	require.NoError(t, os.MkdirAll(tpmPath, 0o755))
+	configPath := tmuxConfigPath(tmpDir)
+	require.NoError(t, os.MkdirAll(filepath.Dir(configPath), 0o755))
+	require.NoError(t, os.WriteFile(configPath, []byte("run '~/.tmux/plugins/tpm/tpm'\nset -g @plugin 'tmux-plugins/tmux-sensible'\n"), 0o644))
+
+	runner := mocks.NewCommandRunner()
+	runner.AddResult(filepath.Join(tpmPath, "bin", "install_plugins"), nil, ports.CommandResult{ExitCode: 0})
+	step := tmux.NewInstallPluginsStep([]string{"tmux-plugins/tmux-sensible"}, nil, runner)
+	ctx := compiler.NewRunContext(context.Background())
+
+	status, err := step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+
+	require.NoError(t, step.Apply(ctx))
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "set -g @plugin 'tmux-plugins/tmux-sensible'\nrun '"+filepath.Join(tpmPath, "tpm")+"'\n", string(data))
+	require.Len(t, runner.Calls(), 1)
+
+	// Settings added later stay ahead of the run line
+	require.NoError(t, tmux.NewConfigStep(map[string]string{"mouse": "on"}, "", runner).Apply(ctx))
+	data, err = os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "set -g mouse on\nrun '")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, ".tmux", "plugins", "tmux-sensible"), 0o755))
+	status, err = step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, status)
+}
+
+func TestUpdatePluginsStep_Check(t *testing.T) {
+	// Cannot use t.Parallel() with t.Setenv()
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	tpmPath := filepath.Join(tmpDir, ".tmux", "plugins", "tpm")
+	require.NoError(t, os.MkdirAll(tpmPath, 0o755))
+	sensible := filepath.Join(tmpDir, ".tmux", "plugins", "tmux-sensible")
+	yank := filepath.Join(tmpDir, ".tmux", "plugins", "tmux-yank")
+
+	runner := mocks.NewCommandRunner()
+	runner.AddResult("git", []string{"-C", sensible, "rev-parse", "HEAD"}, ports.CommandResult{Stdout: "aaa\n"})
+	runner.AddResult("git", []string{"-C", sensible, "ls-remote", "origin", "HEAD"}, ports.CommandResult{Stdout: "aaa\tHEAD\n"})
+	runner.AddResult("git", []string{"-C", yank, "rev-parse", "HEAD"}, ports.CommandResult{Stdout: "bbb\n"})
+	runner.AddResult("git", []string{"-C", yank, "ls-remote", "origin", "HEAD"}, ports.CommandResult{Stdout: "ccc\tHEAD\n"})
+
+	step := tmux.NewUpdatePluginsStep([]string{"tmux-plugins/tmux-sensible", "tmux-plugins/tmux-yank"}, runner)
+	ctx := compiler.NewRunContext(context.Background())
+
+	assert.Equal(t, []string{"tmux-plugins/tmux-yank"}, step.Outdated(ctx))
+	status, err := step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+}
@@ -3,12 +3,17 @@
 // Includes Remote-WSL support for Windows/WSL environments.
 package vscode
 
+import (
+	"fmt"
+	"strings"
+)
+
 // Keybinding represents a single keybinding configuration.
 type Keybinding struct {
-	Key     string `yaml:"key"`
-	Command string `yaml:"command"`
-	When    string `yaml:"when,omitempty"`
-	Args    string `yaml:"args,omitempty"`
+	Key     string `yaml:"key" json:"key"`
+	Command string `yaml:"command" json:"command"`
+	When    string `yaml:"when,omitempty" json:"when,omitempty"`
+	Args    string `yaml:"args,omitempty" json:"args,omitempty"`
 }
 
 // WSLConfig contains configuration specific to Remote-WSL.
@@ -29,6 +34,7 @@ type Config struct {
 	Settings    map[string]interface{}
 	Keybindings []Keybinding
 	WSL         *WSLConfig
+	Profile     *ProfileConfig
 }
 
 // ParseConfig parses raw config into VSCode Config.
@@ -108,5 +114,22 @@ func ParseConfig(raw map[string]interface{}) (*Config, error) {
 		}
 	}
 
+	// Parse profile export
+	if profile, ok := raw["profile"].(map[string]interface{}); ok {
+		cfg.Profile = &ProfileConfig{}
+		if export, ok := profile["export"].(bool); ok {
+			cfg.Profile.Export = export
+		}
+		if name, ok := profile["name"].(string); ok {
+			if strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+				return nil, fmt.Errorf("invalid vscode.profile.name %q", name)
+			}
+			cfg.Profile.Name = name
+		}
+		if sw, ok := profile["switch"].(bool); ok {
+			cfg.Profile.Switch = sw
+		}
+	}
+
 	return cfg, nil
 }
@@ -0,0 +1,208 @@
+package vscode
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+)
+
+// ProfileDir returns where profiles are exported: vscode/profiles in the
+// preflight data directory.
+func ProfileDir() string {
+	dir, err := platform.Path(platform.DataDir, "vscode", "profiles")
+	if err != nil {
+		return ports.ExpandPath("~/.preflight/vscode/profiles")
+	}
+	return dir
+}
+
+// ProfileConfig exports the extensions, settings and keybindings of a target
+// as a VS Code profile.
+type ProfileConfig struct {
+	// Export writes <target>.code-profile to ProfileDir() on apply.
+	Export bool
+	// Name of the profile; defaults to the target name.
+	Name string
+	// Switch opens VS Code with the profile on 'preflight profile switch'.
+	Switch bool
+}
+
+// NameFor returns the profile name for a target.
+func (p *ProfileConfig) NameFor(target string) string {
+	if p.Name != "" {
+		return p.Name
+	}
+	if target == "" {
+		return "default"
+	}
+	return target
+}
+
+// ProfilePath returns the path of the exported profile with the name.
+func ProfilePath(name string) string {
+	return filepath.Join(ProfileDir(), name+".code-profile")
+}
+
+// profileExtension is an extension entry of a .code-profile file.
+type profileExtension struct {
+	Identifier struct {
+		ID string `json:"id"`
+	} `json:"identifier"`
+	Version string `json:"version,omitempty"`
+}
+
+// codeProfile is the .code-profile format that 'Profiles: Import Profile'
+// reads. Its resources are JSON documents embedded as strings.
+type codeProfile struct {
+	Name        string `json:"name"`
+	Settings    string `json:"settings,omitempty"`
+	Keybindings string `json:"keybindings,omitempty"`
+	Extensions  string `json:"extensions,omitempty"`
+}
+
+// keybindingsPlatform is the platform VS Code records with keybindings.
+func keybindingsPlatform() int {
+	switch runtime.GOOS {
+	case "darwin":
+		return 1
+	case "linux":
+		return 2
+	case "windows":
+		return 3
+	default:
+		return 0
+	}
+}
+
+// RenderProfile renders the extensions, settings and keybindings of cfg as
+// a .code-profile file.
+func RenderProfile(name string, cfg *Config) ([]byte, error) {
+	profile := codeProfile{Name: name}
+
+	if len(cfg.Settings) > 0 {
+		settings, err := json.MarshalIndent(cfg.Settings, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal settings: %w", err)
+		}
+		resource, err := json.Marshal(map[string]string{"settings": string(settings)})
+		if err != nil {
+			return nil, err
+		}
+		profile.Settings = string(resource)
+	}
+
+	if len(cfg.Keybindings) > 0 {
+		keybindings, err := json.MarshalIndent(cfg.Keybindings, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal keybindings: %w", err)
+		}
+		resource, err := json.Marshal(map[string]interface{}{
+			"keybindings": string(keybindings),
+			"platform":    keybindingsPlatform(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		profile.Keybindings = string(resource)
+	}
+
+	if len(cfg.Extensions) > 0 {
+		extensions := make([]profileExtension, 0, len(cfg.Extensions))
+		for _, ext := range cfg.Extensions {
+			id, version, _ := strings.Cut(ext, "@")
+			entry := profileExtension{Version: version}
+			entry.Identifier.ID = strings.ToLower(id)
+			extensions = append(extensions, entry)
+		}
+		resource, err := json.Marshal(extensions)
+		if err != nil {
+			return nil, err
+		}
+		profile.Extensions = string(resource)
+	}
+
+	data, err := json.MarshalIndent(profile, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// ProfileExportStep writes the VS Code profile of a target.
+type ProfileExportStep struct {
+	name    string
+	content []byte
+	id      compiler.StepID
+	fs      ports.FileSystem
+}
+
+// NewProfileExportStep creates a new ProfileExportStep.
+func NewProfileExportStep(name string, content []byte, fs ports.FileSystem) *ProfileExportStep {
+	return &ProfileExportStep{
+		name:    name,
+		content: content,
+		id:      compiler.MustNewStepID("vscode:profile"),
+		fs:      fs,
+	}
+}
+
+// ID returns the step identifier.
+func (s *ProfileExportStep) ID() compiler.StepID {
+	return s.id
+}
+
+// DependsOn returns dependencies for this step.
+func (s *ProfileExportStep) DependsOn() []compiler.StepID {
+	return nil
+}
+
+// Check verifies if the exported profile is up to date.
+func (s *ProfileExportStep) Check(_ compiler.RunContext) (compiler.StepStatus, error) {
+	data, err := s.fs.ReadFile(ProfilePath(s.name))
+	if err == nil && string(data) == string(s.content) {
+		return compiler.StatusSatisfied, nil
+	}
+	return compiler.StatusNeedsApply, nil
+}
+
+// Plan returns the diff for this step.
+func (s *ProfileExportStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
+	diffType := compiler.DiffTypeAdd
+	if s.fs.Exists(ProfilePath(s.name)) {
+		diffType = compiler.DiffTypeModify
+	}
+	return compiler.NewDiff(
+		diffType,
+		"profile",
+		s.name+".code-profile",
+		"",
+		fmt.Sprintf("Export VS Code profile %s", s.name),
+	), nil
+}
+
+// Apply writes the profile.
+func (s *ProfileExportStep) Apply(_ compiler.RunContext) error {
+	path := ProfilePath(s.name)
+	if err := s.fs.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create profile directory: %w", err)
+	}
+	if err := s.fs.WriteFile(path, s.content, 0o644); err != nil {
+		return fmt.Errorf("failed to write profile: %w", err)
+	}
+	return nil
+}
+
+// Explain provides context for this step.
+func (s *ProfileExportStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
+	return compiler.NewExplanation(
+		"Export VS Code Profile",
+		fmt.Sprintf("Bundle the extensions, settings and keybindings of this target as the VS Code profile %s in %s. Import it once with 'Profiles: Import Profile...'; 'preflight profile switch' can then open VS Code with it.", s.name, ProfileDir()),
+		[]string{"https://code.visualstudio.com/docs/editor/profiles"},
+	)
+}
@@ -0,0 +1,105 @@
+package vscode_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/provider/vscode"
+	"github.com/felixgeelhaar/preflight/internal/testutil/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderProfile(t *testing.T) {
+	t.Parallel()
+
+	cfg := &vscode.Config{
+		Extensions:  []string{"golang.Go", "esbenp.prettier-vscode@10.1.0"},
+		Settings:    map[string]interface{}{"editor.fontSize": 14},
+		Keybindings: []vscode.Keybinding{{Key: "ctrl+p", Command: "workbench.action.quickOpen"}},
+	}
+	data, err := vscode.RenderProfile("work", cfg)
+	require.NoError(t, err)
+
+	var profile map[string]string
+	require.NoError(t, json.Unmarshal(data, &profile))
+	assert.Equal(t, "work", profile["name"])
+
+	var extensions []map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(profile["extensions"]), &extensions))
+	require.Len(t, extensions, 2)
+	assert.Equal(t, map[string]interface{}{"id": "golang.go"}, extensions[0]["identifier"])
+	assert.Equal(t, "10.1.0", extensions[1]["version"])
+
+	var settings map[string]string
+	require.NoError(t, json.Unmarshal([]byte(profile["settings"]), &settings))
+	assert.JSONEq(t, `{"editor.fontSize": 14}`, settings["settings"])
+
+	var keybindings map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(profile["keybindings"]), &keybindings))
+	assert.JSONEq(t, `[{"key": "ctrl+p", "command": "workbench.action.quickOpen"}]`, keybindings["keybindings"].(string))
+
+	again, err := vscode.RenderProfile("work", cfg)
+	require.NoError(t, err)
+	assert.Equal(t, string(data), string(again), "rendering is deterministic")
+}
+
+func TestProfileExportStep(t *testing.T) {
+	t.Parallel()
+
+	fs := mocks.NewFileSystem()
+	step := vscode.NewProfileExportStep("work", []byte("{}\n"), fs)
+	ctx := compiler.NewRunContext(context.TODO())
+
+	status, err := step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+
+	require.NoError(t, step.Apply(ctx))
+	data, err := fs.ReadFile(vscode.ProfilePath("work"))
+	require.NoError(t, err)
+	assert.Equal(t, "{}\n", string(data))
+
+	status, err = step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, status)
+}
+
+func TestProvider_Compile_ProfileExport(t *testing.T) {
+	t.Parallel()
+
+	p := vscode.NewProvider(mocks.NewFileSystem(), mocks.NewCommandRunner(), nil)
+	raw := map[string]interface{}{
+		"vscode": map[string]interface{}{
+			"extensions": []interface{}{"golang.go"},
+			"profile":    map[string]interface{}{"export": true},
+		},
+	}
+	steps, err := p.Compile(compiler.NewCompileContext(raw).WithTarget("work"))
+	require.NoError(t, err)
+	require.Len(t, steps, 2)
+	assert.Equal(t, "vscode:profile", steps[1].ID().String())
+
+	diff, err := steps[1].Plan(compiler.NewRunContext(context.TODO()))
+	require.NoError(t, err)
+	assert.Equal(t, "work.code-profile", diff.Name())
+}
+
+func TestParseConfig_Profile(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := vscode.ParseConfig(map[string]interface{}{
+		"profile": map[string]interface{}{"export": true, "name": "Work", "switch": true},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &vscode.ProfileConfig{Export: true, Name: "Work", Switch: true}, cfg.Profile)
+	assert.Equal(t, "Work", cfg.Profile.NameFor("work"))
+	assert.Equal(t, "default", (&vscode.ProfileConfig{}).NameFor(""))
+
+	_, err = vscode.ParseConfig(map[string]interface{}{
+		"profile": map[string]interface{}{"name": "../work"},
+	})
+	assert.Error(t, err)
+}
@@ -72,6 +72,16 @@ func (p *Provider) Compile(ctx compiler.CompileContext) ([]compiler.Step, error)
 		steps = append(steps, NewKeybindingsStep(cfg.Keybindings, p.fs))
 	}
 
+	// Export the target's VS Code profile
+	if cfg.Profile != nil && cfg.Profile.Export {
+		name := cfg.Profile.NameFor(ctx.Target())
+		content, err := RenderProfile(name, cfg)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, NewProfileExportStep(name, content, p.fs))
+	}
+
 	// Add WSL steps if WSL configuration is present and on appropriate platform
 	if cfg.WSL != nil && p.shouldApplyWSL() {
 		steps = append(steps, p.compileWSLSteps(cfg.WSL)...)
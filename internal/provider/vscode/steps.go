@@ -9,20 +9,24 @@ import (
 	"github.com/felixgeelhaar/preflight/internal/ports"
 )
 
-// ExtensionStep manages VSCode extension installation.
+// ExtensionStep manages VSCode extension installation. An extension
+// written as "publisher.name@1.2.3" is locked to that version.
 type ExtensionStep struct {
 	extension string
+	version   string
 	id        compiler.StepID
 	runner    ports.CommandRunner
 }
 
 // NewExtensionStep creates a new ExtensionStep.
 func NewExtensionStep(extension string, runner ports.CommandRunner) *ExtensionStep {
+	name, version, _ := strings.Cut(extension, "@")
 	// Replace dots with underscores for valid step ID (dots not allowed in StepID pattern)
-	safeExt := strings.ReplaceAll(extension, ".", "_")
+	safeExt := strings.ReplaceAll(name, ".", "_")
 	id := compiler.MustNewStepID(fmt.Sprintf("vscode:extension:%s", safeExt))
 	return &ExtensionStep{
 		extension: extension,
+		version:   version,
 		id:        id,
 		runner:    runner,
 	}
@@ -38,8 +42,12 @@ func (s *ExtensionStep) DependsOn() []compiler.StepID {
 	return nil
 }
 
-// Check verifies if the extension is installed.
+// Check verifies if the extension is installed, at the locked version if
+// there is one.
 func (s *ExtensionStep) Check(ctx compiler.RunContext) (compiler.StepStatus, error) {
+	if s.version != "" {
+		return s.checkVersion(ctx)
+	}
 	result, err := s.runner.Run(ctx.Context(), "code", "--list-extensions")
 	if err != nil {
 		return compiler.StatusUnknown, err
@@ -55,6 +63,21 @@ func (s *ExtensionStep) Check(ctx compiler.RunContext) (compiler.StepStatus, err
 	return compiler.StatusNeedsApply, nil
 }
 
+func (s *ExtensionStep) checkVersion(ctx compiler.RunContext) (compiler.StepStatus, error) {
+	result, err := s.runner.Run(ctx.Context(), "code", "--list-extensions", "--show-versions")
+	if err != nil {
+		return compiler.StatusUnknown, err
+	}
+	name, _, _ := strings.Cut(s.extension, "@")
+	for _, line := range strings.Split(result.Stdout, "\n") {
+		id, version, _ := strings.Cut(strings.TrimSpace(line), "@")
+		if strings.EqualFold(id, name) && version == s.version {
+			return compiler.StatusSatisfied, nil
+		}
+	}
+	return compiler.StatusNeedsApply, nil
+}
+
 // Plan returns the diff for this step.
 func (s *ExtensionStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
 	return compiler.NewDiff(
@@ -80,10 +103,11 @@ func (s *ExtensionStep) Apply(ctx compiler.RunContext) error {
 
 // Explain provides context for this step.
 func (s *ExtensionStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
+	name, _, _ := strings.Cut(s.extension, "@")
 	return compiler.NewExplanation(
 		"Install VSCode Extension",
 		fmt.Sprintf("Install the VSCode extension %s using the 'code' CLI", s.extension),
-		[]string{fmt.Sprintf("https://marketplace.visualstudio.com/items?itemName=%s", s.extension)},
+		[]string{fmt.Sprintf("https://marketplace.visualstudio.com/items?itemName=%s", name)},
 	)
 }
 
@@ -103,6 +103,32 @@ func TestExtensionStep_Explain(t *testing.T) {
 	assert.Contains(t, explanation.Detail(), "ms-python.python")
 }
 
+func TestExtensionStep_VersionLock(t *testing.T) {
+	t.Parallel()
+
+	runner := mocks.NewCommandRunner()
+	runner.AddResult("code", []string{"--list-extensions", "--show-versions"}, ports.CommandResult{
+		ExitCode: 0,
+		Stdout:   "Golang.Go@0.41.2\nms-python.python@2024.4.0\n",
+	})
+	runner.AddResult("code", []string{"--install-extension", "golang.go@0.41.2", "--force"}, ports.CommandResult{
+		ExitCode: 0,
+	})
+	ctx := compiler.NewRunContext(context.TODO())
+
+	step := vscode.NewExtensionStep("golang.go@0.41.2", runner)
+	assert.Equal(t, "vscode:extension:golang_go", step.ID().String())
+	status, err := step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, status)
+	require.NoError(t, step.Apply(ctx))
+
+	step = vscode.NewExtensionStep("ms-python.python@2024.2.0", runner)
+	status, err = step.Check(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+}
+
 func TestSettingsStep_ID(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,200 @@
+package wasmplugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/capability"
+	"github.com/felixgeelhaar/preflight/internal/domain/plugin"
+	"github.com/felixgeelhaar/preflight/internal/domain/sandbox"
+)
+
+// ErrNotProviderPlugin is returned when loading a plugin without a WASM provider module.
+var ErrNotProviderPlugin = errors.New("not a WASM provider plugin")
+
+// LoadModule reads and verifies a provider plugin's WASM module.
+func LoadModule(p *plugin.Plugin) (*sandbox.Plugin, error) {
+	m := p.Manifest
+	if !m.IsProviderPlugin() || m.WASM == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotProviderPlugin, p.ID())
+	}
+
+	modulePath := filepath.Join(p.Path, m.WASM.Module)
+	if rel, err := filepath.Rel(p.Path, modulePath); err != nil || strings.HasPrefix(rel, "..") {
+		return nil, fmt.Errorf("%w: module path escapes plugin directory: %s", sandbox.ErrPluginInvalid, m.WASM.Module)
+	}
+
+	data, err := os.ReadFile(modulePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", sandbox.ErrPluginModuleNotFound, modulePath)
+		}
+		return nil, fmt.Errorf("failed to read module: %w", err)
+	}
+
+	checksum := strings.TrimPrefix(strings.ToLower(m.WASM.Checksum), "sha256:")
+	if err := plugin.VerifyChecksum(data, checksum); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", sandbox.ErrPluginChecksumMismatch, p.ID(), err)
+	}
+
+	var caps *capability.Requirements
+	for _, c := range m.WASM.Capabilities {
+		mapped, ok := SandboxCapability(c.Name)
+		if !ok {
+			continue
+		}
+		if caps == nil {
+			caps = capability.NewRequirements()
+		}
+		if c.Optional {
+			caps.AddOptional(mapped, c.Justification)
+		} else {
+			caps.AddCapability(mapped, c.Justification)
+		}
+	}
+
+	return &sandbox.Plugin{
+		ID:           p.ID(),
+		Name:         p.ID(),
+		Version:      m.Version,
+		Module:       data,
+		Capabilities: caps,
+		Checksum:     checksum,
+	}, nil
+}
+
+// manifestCapabilities maps plugin manifest capability names to the sandbox
+// capabilities that gate host functions. Manifest capabilities without a
+// host function (env:read, sys:info, preflight:*) have no sandbox equivalent.
+var manifestCapabilities = map[string]capability.Capability{
+	"files:read":    capability.CapFilesRead,
+	"files:stat":    capability.CapFilesRead,
+	"files:write":   capability.CapFilesWrite,
+	"shell:execute": capability.CapShellExecute,
+	"net:http":      capability.CapNetworkFetch,
+}
+
+// SandboxCapability returns the sandbox capability for a manifest capability name.
+// Names already in sandbox form (e.g. "network:fetch") are accepted as is.
+func SandboxCapability(name string) (capability.Capability, bool) {
+	if c, ok := manifestCapabilities[name]; ok {
+		return c, true
+	}
+	c, err := capability.ParseCapability(name)
+	if err != nil {
+		return capability.Capability{}, false
+	}
+	return c, true
+}
+
+// GrantPolicy builds the capability policy for a plugin from the user's
+// grant. Capabilities that do not need consent are granted with the plugin;
+// the rest are granted only when approved and blocked when denied.
+func GrantPolicy(p *plugin.Plugin, grant plugin.Grant) *capability.Policy {
+	builder := capability.NewPolicyBuilder()
+	if p.Manifest.WASM == nil {
+		return builder.Build()
+	}
+	for _, c := range p.Manifest.WASM.Capabilities {
+		mapped, ok := SandboxCapability(c.Name)
+		if !ok {
+			continue
+		}
+		if !plugin.RequiresConsent(c.Name) {
+			builder.Grant(mapped)
+			continue
+		}
+		granted, decided := grant.Decision(c.Name)
+		switch {
+		case granted:
+			builder.Grant(mapped).Approve(mapped)
+		case decided:
+			builder.Block(mapped)
+		}
+	}
+	return builder.Build()
+}
+
+// PolicyFunc returns the capability policy for a plugin. It is called once
+// per plugin, before its first hook runs, so it may prompt for consent.
+type PolicyFunc func(ctx context.Context, p *plugin.Plugin) (*capability.Policy, error)
+
+// Options configures how provider plugins are run.
+type Options struct {
+	// Runtime executes the plugin modules.
+	Runtime sandbox.Runtime
+	// Config is the base sandbox configuration.
+	Config sandbox.Config
+	// Services are the host services exposed to plugins.
+	Services *sandbox.HostServices
+	// Policy returns the per-plugin policy; nil uses Config.Policy.
+	Policy PolicyFunc
+}
+
+// Providers builds a compiler provider for every provider declared by the
+// enabled WASM plugins. Plugins that fail to load are returned as errors and
+// skipped so one broken plugin does not disable the rest.
+func Providers(plugins []*plugin.Plugin, opts Options) ([]*Provider, []error) {
+	var providers []*Provider
+	var errs []error
+	for _, p := range plugins {
+		if !p.Enabled || !p.Manifest.IsProviderPlugin() {
+			continue
+		}
+		module, err := LoadModule(p)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		runner := &lazyRunner{plugin: p, opts: opts}
+		for _, spec := range p.Manifest.Provides.Providers {
+			providers = append(providers, newProvider(spec.Name, spec.ConfigKey, module, runner))
+		}
+	}
+	return providers, errs
+}
+
+// lazyRunner defers resolving a plugin's policy until its first hook, so
+// consent is only requested for plugins that are actually used.
+type lazyRunner struct {
+	plugin *plugin.Plugin
+	opts   Options
+	once   sync.Once
+	runner *sandbox.HookRunner
+	err    error
+}
+
+func (l *lazyRunner) Invoke(ctx context.Context, module *sandbox.Plugin, req sandbox.HookRequest) (*sandbox.HookResponse, error) {
+	l.once.Do(func() {
+		l.runner, l.err = l.build(ctx)
+	})
+	if l.err != nil {
+		return nil, l.err
+	}
+	return l.runner.Invoke(ctx, module, req)
+}
+
+func (l *lazyRunner) build(ctx context.Context) (*sandbox.HookRunner, error) {
+	cfg := l.opts.Config
+	var services *sandbox.HostServices
+	if l.opts.Services != nil {
+		copied := *l.opts.Services
+		services = &copied
+	}
+	if l.opts.Policy != nil {
+		policy, err := l.opts.Policy(ctx, l.plugin)
+		if err != nil {
+			return nil, fmt.Errorf("plugin %s: %w", l.plugin.ID(), err)
+		}
+		cfg.Policy = policy
+		if services != nil {
+			services.Policy = policy
+		}
+	}
+	return sandbox.NewHookRunner(l.opts.Runtime, cfg, services), nil
+}
@@ -0,0 +1,111 @@
+// Package wasmplugin exposes WASM provider plugins as compiler providers.
+//
+// Each provider declared by a plugin manifest becomes a compiler.Provider that
+// handles the manifest's config key. Planning and applying are delegated to the
+// plugin's plan and apply hooks, which run in the sandbox.
+package wasmplugin
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/domain/sandbox"
+)
+
+// Provider implements compiler.Provider by invoking plugin hooks.
+type Provider struct {
+	name      string
+	configKey string
+	plugin    *sandbox.Plugin
+	runner    hookInvoker
+}
+
+// hookInvoker runs plugin hooks; satisfied by *sandbox.HookRunner.
+type hookInvoker interface {
+	Invoke(ctx context.Context, plugin *sandbox.Plugin, req sandbox.HookRequest) (*sandbox.HookResponse, error)
+}
+
+// NewProvider creates a provider for one entry of a plugin's provides.providers.
+// An empty configKey defaults to the provider name.
+func NewProvider(name, configKey string, plugin *sandbox.Plugin, runner *sandbox.HookRunner) *Provider {
+	return newProvider(name, configKey, plugin, runner)
+}
+
+func newProvider(name, configKey string, plugin *sandbox.Plugin, runner hookInvoker) *Provider {
+	if configKey == "" {
+		configKey = name
+	}
+	return &Provider{
+		name:      name,
+		configKey: configKey,
+		plugin:    plugin,
+		runner:    runner,
+	}
+}
+
+// Name returns the provider name.
+func (p *Provider) Name() string {
+	return p.name
+}
+
+// ConfigKey returns the config section handled by the provider.
+func (p *Provider) ConfigKey() string {
+	return p.configKey
+}
+
+// PluginID returns the ID of the plugin implementing the provider.
+func (p *Provider) PluginID() string {
+	return p.plugin.ID
+}
+
+// Compile runs the plugin's plan hook and converts the returned steps.
+func (p *Provider) Compile(ctx compiler.CompileContext) ([]compiler.Step, error) {
+	section := ctx.GetSection(p.configKey)
+	if section == nil {
+		return nil, nil
+	}
+
+	resp, err := p.runner.Invoke(context.Background(), p.plugin, sandbox.HookRequest{
+		Hook:     sandbox.HookPlan,
+		Provider: p.name,
+		Config:   section,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	steps := make([]compiler.Step, 0, len(resp.Steps))
+	for _, hs := range resp.Steps {
+		step, err := newHookStep(p, section, hs)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// Capture runs the plugin's capture hook and returns the captured config section.
+func (p *Provider) Capture(ctx context.Context) (map[string]interface{}, error) {
+	resp, err := p.runner.Invoke(ctx, p.plugin, sandbox.HookRequest{
+		Hook:     sandbox.HookCapture,
+		Provider: p.name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Captured, nil
+}
+
+// stepID namespaces a plugin step ID under the provider name.
+func (p *Provider) stepID(id string) (compiler.StepID, error) {
+	stepID, err := compiler.NewStepID(p.name + ":" + id)
+	if err != nil {
+		return compiler.StepID{}, fmt.Errorf("plugin %s returned invalid step %q: %w", p.plugin.ID, id, err)
+	}
+	return stepID, nil
+}
+
+// Ensure Provider implements compiler.Provider.
+var _ compiler.Provider = (*Provider)(nil)
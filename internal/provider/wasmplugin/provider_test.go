@@ -0,0 +1,281 @@
+package wasmplugin_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/capability"
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/domain/plugin"
+	"github.com/felixgeelhaar/preflight/internal/domain/sandbox"
+	"github.com/felixgeelhaar/preflight/internal/provider/wasmplugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRuntime answers hook requests with canned responses keyed by hook.
+type fakeRuntime struct {
+	responses map[sandbox.Hook]string
+	requests  []sandbox.HookRequest
+}
+
+func (r *fakeRuntime) NewSandbox(_ sandbox.Config) (sandbox.Sandbox, error) {
+	return &fakeSandbox{runtime: r}, nil
+}
+func (r *fakeRuntime) IsAvailable() bool { return true }
+func (r *fakeRuntime) Version() string   { return "fake" }
+func (r *fakeRuntime) Close() error      { return nil }
+
+type fakeSandbox struct {
+	runtime *fakeRuntime
+}
+
+func (s *fakeSandbox) Execute(_ context.Context, _ *sandbox.Plugin, input []byte) (*sandbox.ExecutionResult, error) {
+	var req sandbox.HookRequest
+	if err := json.Unmarshal(input, &req); err != nil {
+		return nil, err
+	}
+	s.runtime.requests = append(s.runtime.requests, req)
+	return &sandbox.ExecutionResult{Success: true, Output: []byte(s.runtime.responses[req.Hook])}, nil
+}
+func (s *fakeSandbox) Validate(_ context.Context, _ *sandbox.Plugin) error { return nil }
+func (s *fakeSandbox) Close() error                                        { return nil }
+
+func newTestProvider(rt *fakeRuntime) *wasmplugin.Provider {
+	module := &sandbox.Plugin{ID: "docker-plugin", Name: "docker-plugin", Module: []byte{0}}
+	runner := sandbox.NewHookRunner(rt, sandbox.DefaultConfig(), nil)
+	return wasmplugin.NewProvider("docker", "", module, runner)
+}
+
+func TestProvider_Compile_NoConfig(t *testing.T) {
+	t.Parallel()
+
+	rt := &fakeRuntime{}
+	p := newTestProvider(rt)
+
+	steps, err := p.Compile(compiler.NewCompileContext(map[string]interface{}{}))
+	require.NoError(t, err)
+	assert.Empty(t, steps)
+	assert.Empty(t, rt.requests)
+}
+
+func TestProvider_CompileAndApply(t *testing.T) {
+	t.Parallel()
+
+	rt := &fakeRuntime{responses: map[sandbox.Hook]string{
+		sandbox.HookPlan: `{"steps":[
+			{"id":"engine","description":"Install engine","needs_apply":true,"desired":"colima"},
+			{"id":"context","description":"Set context","needs_apply":false,"depends_on":["engine"],"current":"colima","desired":"colima"}
+		]}`,
+		sandbox.HookApply: `{}`,
+	}}
+	p := newTestProvider(rt)
+	assert.Equal(t, "docker", p.Name())
+	assert.Equal(t, "docker", p.ConfigKey())
+
+	cfg := map[string]interface{}{"docker": map[string]interface{}{"engine": "colima"}}
+	steps, err := p.Compile(compiler.NewCompileContext(cfg))
+	require.NoError(t, err)
+	require.Len(t, steps, 2)
+
+	assert.Equal(t, "docker:engine", steps[0].ID().String())
+	assert.Equal(t, []compiler.StepID{steps[0].ID()}, steps[1].DependsOn())
+
+	runCtx := compiler.NewRunContext(context.Background())
+	status, err := steps[0].Check(runCtx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusNeedsApply, status)
+	status, err = steps[1].Check(runCtx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.StatusSatisfied, status)
+
+	diff, err := steps[0].Plan(runCtx)
+	require.NoError(t, err)
+	assert.Equal(t, compiler.DiffTypeAdd, diff.Type())
+
+	explanation := steps[0].Explain(compiler.NewExplainContext())
+	assert.Equal(t, "Install engine", explanation.Summary())
+
+	require.NoError(t, steps[0].Apply(runCtx))
+	last := rt.requests[len(rt.requests)-1]
+	assert.Equal(t, sandbox.HookApply, last.Hook)
+	assert.Equal(t, "engine", last.Step)
+	assert.Equal(t, "colima", last.Config["engine"])
+}
+
+func TestProvider_Compile_InvalidStepID(t *testing.T) {
+	t.Parallel()
+
+	rt := &fakeRuntime{responses: map[sandbox.Hook]string{
+		sandbox.HookPlan: `{"steps":[{"id":"has space","needs_apply":true}]}`,
+	}}
+	p := newTestProvider(rt)
+
+	_, err := p.Compile(compiler.NewCompileContext(map[string]interface{}{"docker": map[string]interface{}{}}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid step")
+}
+
+func TestProvider_Capture(t *testing.T) {
+	t.Parallel()
+
+	rt := &fakeRuntime{responses: map[sandbox.Hook]string{
+		sandbox.HookCapture: `{"captured":{"engine":"colima"}}`,
+	}}
+	p := newTestProvider(rt)
+
+	captured, err := p.Capture(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "colima", captured["engine"])
+}
+
+func writePlugin(t *testing.T, module []byte, checksum string) *plugin.Plugin {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "plugin.wasm"), module, 0o600))
+	return &plugin.Plugin{
+		Path:    dir,
+		Enabled: true,
+		Manifest: plugin.Manifest{
+			Name:    "docker-plugin",
+			Version: "1.0.0",
+			Type:    plugin.TypeProvider,
+			Provides: plugin.Capabilities{
+				Providers: []plugin.ProviderSpec{{Name: "docker", ConfigKey: "containers"}},
+			},
+			WASM: &plugin.WASMConfig{
+				Module:   "plugin.wasm",
+				Checksum: checksum,
+				Capabilities: []plugin.WASMCapability{
+					{Name: "files:stat", Justification: "check config"},
+					{Name: "net:http", Justification: "download releases"},
+					{Name: "sys:info", Justification: "detect platform"},
+				},
+			},
+		},
+	}
+}
+
+func TestLoadModule(t *testing.T) {
+	t.Parallel()
+
+	module := []byte("\x00asm\x01\x00\x00\x00")
+	sum := sha256.Sum256(module)
+	checksum := hex.EncodeToString(sum[:])
+
+	t.Run("loads and verifies module", func(t *testing.T) {
+		t.Parallel()
+		loaded, err := wasmplugin.LoadModule(writePlugin(t, module, "sha256:"+checksum))
+		require.NoError(t, err)
+		assert.Equal(t, "docker-plugin", loaded.ID)
+		assert.Equal(t, module, loaded.Module)
+		require.NotNil(t, loaded.Capabilities)
+		assert.ElementsMatch(t, []string{"files:read", "network:fetch"}, loaded.Capabilities.FullSet().Strings())
+	})
+
+	t.Run("rejects checksum mismatch", func(t *testing.T) {
+		t.Parallel()
+		other := sha256.Sum256([]byte("other"))
+		_, err := wasmplugin.LoadModule(writePlugin(t, module, hex.EncodeToString(other[:])))
+		assert.ErrorIs(t, err, sandbox.ErrPluginChecksumMismatch)
+	})
+
+	t.Run("rejects module outside plugin directory", func(t *testing.T) {
+		t.Parallel()
+		p := writePlugin(t, module, checksum)
+		p.Manifest.WASM.Module = "../plugin.wasm"
+		_, err := wasmplugin.LoadModule(p)
+		assert.ErrorIs(t, err, sandbox.ErrPluginInvalid)
+	})
+
+	t.Run("rejects config plugin", func(t *testing.T) {
+		t.Parallel()
+		p := writePlugin(t, module, checksum)
+		p.Manifest.Type = plugin.TypeConfig
+		_, err := wasmplugin.LoadModule(p)
+		assert.ErrorIs(t, err, wasmplugin.ErrNotProviderPlugin)
+	})
+}
+
+func TestProviders(t *testing.T) {
+	t.Parallel()
+
+	module := []byte("\x00asm\x01\x00\x00\x00")
+	sum := sha256.Sum256(module)
+	good := writePlugin(t, module, hex.EncodeToString(sum[:]))
+	bad := writePlugin(t, module, hex.EncodeToString(make([]byte, 32)))
+	disabled := writePlugin(t, module, hex.EncodeToString(sum[:]))
+	disabled.Enabled = false
+
+	providers, errs := wasmplugin.Providers([]*plugin.Plugin{good, bad, disabled}, wasmplugin.Options{
+		Runtime: &fakeRuntime{},
+		Config:  sandbox.DefaultConfig(),
+	})
+
+	require.Len(t, providers, 1)
+	assert.Equal(t, "docker", providers[0].Name())
+	assert.Equal(t, "containers", providers[0].ConfigKey())
+	assert.Equal(t, "docker-plugin", providers[0].PluginID())
+	assert.Len(t, errs, 1)
+}
+
+func TestProviders_ResolvesPolicyOnFirstUse(t *testing.T) {
+	t.Parallel()
+
+	module := []byte("\x00asm\x01\x00\x00\x00")
+	sum := sha256.Sum256(module)
+	p := writePlugin(t, module, hex.EncodeToString(sum[:]))
+
+	calls := 0
+	rt := &fakeRuntime{responses: map[sandbox.Hook]string{sandbox.HookCapture: `{}`}}
+	providers, errs := wasmplugin.Providers([]*plugin.Plugin{p}, wasmplugin.Options{
+		Runtime: rt,
+		Config:  sandbox.DefaultConfig(),
+		Policy: func(_ context.Context, _ *plugin.Plugin) (*capability.Policy, error) {
+			calls++
+			return capability.DefaultPolicy(), nil
+		},
+	})
+	require.Empty(t, errs)
+	require.Len(t, providers, 1)
+	assert.Equal(t, 0, calls)
+
+	for i := 0; i < 2; i++ {
+		_, err := providers[0].Capture(context.Background())
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, calls)
+}
+
+func TestGrantPolicy(t *testing.T) {
+	t.Parallel()
+
+	p := &plugin.Plugin{Manifest: plugin.Manifest{
+		Name:    "docker-plugin",
+		Version: "1.0.0",
+		Type:    plugin.TypeProvider,
+		WASM: &plugin.WASMConfig{Capabilities: []plugin.WASMCapability{
+			{Name: "files:read"},
+			{Name: "shell:execute"},
+			{Name: "net:http"},
+			{Name: "files:write"},
+		}},
+	}}
+	grant := plugin.Grant{
+		Plugin:  "docker-plugin",
+		Version: "1.0.0",
+		Granted: []string{"shell:execute"},
+		Denied:  []string{"net:http"},
+	}
+
+	policy := wasmplugin.GrantPolicy(p, grant)
+	assert.NoError(t, policy.Check(capability.CapFilesRead))
+	assert.NoError(t, policy.Check(capability.CapShellExecute))
+	assert.ErrorIs(t, policy.Check(capability.CapNetworkFetch), capability.ErrCapabilityDenied)
+	assert.ErrorIs(t, policy.Check(capability.CapFilesWrite), capability.ErrCapabilityNotGranted)
+}
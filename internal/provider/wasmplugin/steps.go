@@ -0,0 +1,90 @@
+package wasmplugin
+
+import (
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
+	"github.com/felixgeelhaar/preflight/internal/domain/sandbox"
+)
+
+// HookStep is a step planned by a plugin and applied through its apply hook.
+type HookStep struct {
+	id        compiler.StepID
+	dependsOn []compiler.StepID
+	provider  *Provider
+	config    map[string]interface{}
+	planned   sandbox.HookStep
+}
+
+func newHookStep(p *Provider, config map[string]interface{}, planned sandbox.HookStep) (*HookStep, error) {
+	id, err := p.stepID(planned.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	deps := make([]compiler.StepID, 0, len(planned.DependsOn))
+	for _, dep := range planned.DependsOn {
+		depID, err := p.stepID(dep)
+		if err != nil {
+			return nil, err
+		}
+		deps = append(deps, depID)
+	}
+
+	return &HookStep{
+		id:        id,
+		dependsOn: deps,
+		provider:  p,
+		config:    config,
+		planned:   planned,
+	}, nil
+}
+
+// ID returns the step identifier.
+func (s *HookStep) ID() compiler.StepID {
+	return s.id
+}
+
+// DependsOn returns the step dependencies.
+func (s *HookStep) DependsOn() []compiler.StepID {
+	return s.dependsOn
+}
+
+// Check reports the status determined by the plan hook.
+func (s *HookStep) Check(_ compiler.RunContext) (compiler.StepStatus, error) {
+	if s.planned.NeedsApply {
+		return compiler.StatusNeedsApply, nil
+	}
+	return compiler.StatusSatisfied, nil
+}
+
+// Plan returns the diff reported by the plan hook.
+func (s *HookStep) Plan(_ compiler.RunContext) (compiler.Diff, error) {
+	if !s.planned.NeedsApply {
+		return compiler.NewDiff(compiler.DiffTypeNone, s.provider.name, s.planned.ID, s.planned.Current, s.planned.Desired), nil
+	}
+	diffType := compiler.DiffTypeModify
+	if s.planned.Current == "" {
+		diffType = compiler.DiffTypeAdd
+	}
+	return compiler.NewDiff(diffType, s.provider.name, s.planned.ID, s.planned.Current, s.planned.Desired), nil
+}
+
+// Apply runs the plugin's apply hook for this step.
+func (s *HookStep) Apply(ctx compiler.RunContext) error {
+	_, err := s.provider.runner.Invoke(ctx.Context(), s.provider.plugin, sandbox.HookRequest{
+		Hook:     sandbox.HookApply,
+		Provider: s.provider.name,
+		Config:   s.config,
+		Step:     s.planned.ID,
+		DryRun:   ctx.DryRun(),
+	})
+	return err
+}
+
+// Explain returns the plugin's description of the step.
+func (s *HookStep) Explain(_ compiler.ExplainContext) compiler.Explanation {
+	return compiler.NewExplanation(
+		s.planned.Description,
+		"Provided by plugin "+s.provider.plugin.ID+" (WASM sandbox)",
+		nil,
+	)
+}
@@ -218,6 +218,11 @@ func (s *PackageStep) LockInfo() (compiler.LockInfo, bool) {
 	}, true
 }
 
+// UninstallCommand returns the command that uninstalls the package.
+func (s *PackageStep) UninstallCommand() []string {
+	return []string{s.wingetCommand(), "uninstall", "--id", s.pkg.ID, "--exact", "--silent"}
+}
+
 // InstalledVersion returns the installed winget package version if available.
 func (s *PackageStep) InstalledVersion(ctx compiler.RunContext) (string, bool, error) {
 	cmd := s.wingetCommand()
@@ -0,0 +1,79 @@
+package securestate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/felixgeelhaar/preflight/internal/adapters/age"
+)
+
+const (
+	// maxLogSize bounds a log written by LogWriter; the oldest lines are
+	// dropped beyond it.
+	maxLogSize = 1 << 20
+	// previousLogSuffix names the log of an earlier process that was
+	// encrypted when a LogWriter opened it.
+	previousLogSuffix = ".1"
+)
+
+// LogWriter writes a log file through WriteFile, so the log is encrypted
+// like the rest of the state while encryption is enabled. An age file
+// cannot be appended to, so every write rewrites the log, which is kept
+// under 1MB.
+type LogWriter struct {
+	mu   sync.Mutex
+	path string
+	buf  []byte
+}
+
+// OpenLog opens the log at path for writing. A plain text log is continued.
+// An encrypted one is moved to <path>.1 instead, since continuing it would
+// mean asking the keychain for the state key.
+func OpenLog(path string) (*LogWriter, error) {
+	// #nosec G304 -- callers pass the agent log in preflight's state directory.
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		data = nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	case age.IsEncrypted(data):
+		if err := os.Rename(path, path+previousLogSuffix); err != nil {
+			return nil, fmt.Errorf("failed to rotate %s: %w", path, err)
+		}
+		data = nil
+	}
+	return &LogWriter{path: path, buf: trimLog(data)}, nil
+}
+
+// Write appends p to the log and writes the log out.
+func (w *LogWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = trimLog(append(w.buf, p...))
+	if err := WriteFile(w.path, w.buf, 0o600); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close implements io.Closer; every write is already on disk.
+func (w *LogWriter) Close() error {
+	return nil
+}
+
+// trimLog drops the oldest lines of data beyond maxLogSize.
+func trimLog(data []byte) []byte {
+	if len(data) <= maxLogSize {
+		return data
+	}
+	data = data[len(data)-maxLogSize:]
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		data = data[i+1:]
+	}
+	return data
+}
@@ -0,0 +1,68 @@
+package securestate
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/adapters/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogWriter(t *testing.T) {
+	home, _ := setup(t)
+	path := filepath.Join(home, "agent.log")
+	writeState(t, path, "started\n")
+
+	// A plain text log is continued while encryption is off
+	w, err := OpenLog(path)
+	require.NoError(t, err)
+	_, err = w.Write([]byte("reconciled\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "started\nreconciled\n", string(raw(t, path)))
+
+	// and encrypted once it is on
+	_, err = Enable(time.Now())
+	require.NoError(t, err)
+	_, err = w.Write([]byte("drift detected in /home/me/.zshrc\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	data := raw(t, path)
+	assert.True(t, age.IsEncrypted(data))
+	assert.NotContains(t, string(data), ".zshrc")
+	plaintext, err := ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "started\nreconciled\ndrift detected in /home/me/.zshrc\n", string(plaintext))
+
+	// An encrypted log is moved aside rather than decrypted
+	identity = nil
+	w, err = OpenLog(path)
+	require.NoError(t, err)
+	_, err = w.Write([]byte("restarted\n"))
+	require.NoError(t, err)
+	assert.True(t, age.IsEncrypted(raw(t, path+previousLogSuffix)))
+	assert.True(t, age.IsEncrypted(raw(t, path)))
+	plaintext, err = ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "restarted\n", string(plaintext))
+
+	encrypted, _, err := Count()
+	require.NoError(t, err)
+	assert.Equal(t, 2, encrypted)
+}
+
+func TestTrimLog(t *testing.T) {
+	var buf bytes.Buffer
+	for i := 0; buf.Len() <= maxLogSize; i++ {
+		fmt.Fprintf(&buf, "line %d\n", i)
+	}
+	trimmed := trimLog(buf.Bytes())
+	assert.LessOrEqual(t, len(trimmed), maxLogSize)
+	assert.False(t, strings.HasPrefix(string(trimmed), "line 0\n"))
+	assert.True(t, strings.HasPrefix(string(trimmed), "line "), "the log starts at a whole line")
+	assert.Equal(t, []byte("short\n"), trimLog([]byte("short\n")))
+}
@@ -0,0 +1,346 @@
+// Package securestate encrypts preflight's local state at rest.
+//
+// **Default: disabled.** 'preflight secure-state enable' creates an age key,
+// keeps its private half in the OS keychain and records the public half in
+// the preflight config directory. From then on, history, pending patches,
+// the drift state and the agent log are written as age files. Writing needs only the public key, so
+// the agent never touches the keychain; reading asks the keychain once per
+// process. Files written while encryption was off are read as they are, so
+// every command works the same either way.
+package securestate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/adapters/age"
+	"github.com/felixgeelhaar/preflight/internal/adapters/keychain"
+	"github.com/felixgeelhaar/preflight/internal/domain/platform"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+)
+
+// The state key is kept in the keychain under this service and account.
+const (
+	KeychainService = "preflight-state"
+	KeychainAccount = "age-identity"
+)
+
+// settingsFile is the name of the settings file in the preflight config
+// directory.
+const settingsFile = "secure-state.json"
+
+// ErrKeyMissing is returned when an encrypted state file is read and the
+// keychain does not hold the state key.
+var ErrKeyMissing = errors.New("the state key is not in the keychain")
+
+// Keychain returns the keychain that holds the state key; tests replace it.
+var Keychain = func() ports.Keychain {
+	return keychain.NewPlatformKeychain()
+}
+
+// Settings is the record of encryption being enabled.
+type Settings struct {
+	// Recipient is the public key state files are encrypted to.
+	Recipient string `json:"recipient"`
+	// EnabledAt is when encryption was enabled.
+	EnabledAt time.Time `json:"enabled_at"`
+}
+
+var (
+	mu       sync.Mutex
+	identity *age.Identity
+)
+
+// LoadSettings returns the settings, or nil when encryption is disabled.
+func LoadSettings() (*Settings, error) {
+	path, err := platform.Path(platform.ConfigDir, settingsFile)
+	if err != nil {
+		return nil, err
+	}
+	// #nosec G304 -- the settings file in the preflight config directory.
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state encryption settings: %w", err)
+	}
+	var s Settings
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse state encryption settings: %w", err)
+	}
+	return &s, nil
+}
+
+// Enabled reports whether state files are encrypted when written.
+func Enabled() bool {
+	s, err := LoadSettings()
+	return err == nil && s != nil
+}
+
+// Dirs returns the state that is encrypted: the history and pending
+// patches directories, and the drift state and agent logs in the state
+// directory. Files are listed by path, directories stand for the files in
+// them.
+func Dirs() ([]string, error) {
+	var paths []string
+	for _, name := range []string{"history", "patches", "state.json", "agent.log", "agent.log" + previousLogSuffix} {
+		path, err := platform.Path(platform.StateDir, name)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// ReadFile reads the state file at path, decrypting it when it is
+// encrypted.
+func ReadFile(path string) ([]byte, error) {
+	// #nosec G304 -- callers pass files in preflight's state directory.
+	data, err := os.ReadFile(path)
+	if err != nil || !age.IsEncrypted(data) {
+		return data, err
+	}
+	plaintext, err := decrypt(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: %w", path, err)
+	}
+	return plaintext, nil
+}
+
+// WriteFile writes the state file at path, encrypted when encryption is
+// enabled.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	s, err := LoadSettings()
+	if err != nil {
+		return err
+	}
+	var recipient *age.Recipient
+	if s != nil {
+		if recipient, err = age.ParseRecipient(s.Recipient); err != nil {
+			return fmt.Errorf("invalid state encryption settings: %w", err)
+		}
+	}
+	return writeFile(path, data, perm, recipient)
+}
+
+// Enable creates the state key, stores it in the keychain and encrypts the
+// existing state files. It returns the number of files encrypted. Once
+// enabled, Enable only encrypts files still in plain text.
+func Enable(now time.Time) (int, error) {
+	s, err := LoadSettings()
+	if err != nil {
+		return 0, err
+	}
+	if s == nil {
+		if s, err = createKey(now); err != nil {
+			return 0, err
+		}
+	}
+	recipient, err := age.ParseRecipient(s.Recipient)
+	if err != nil {
+		return 0, fmt.Errorf("invalid state encryption settings: %w", err)
+	}
+	return rewrite(recipient)
+}
+
+// createKey stores a new state key in the keychain and records its public
+// half in the settings. A key already in the keychain is kept, so files
+// encrypted with it stay readable.
+func createKey(now time.Time) (*Settings, error) {
+	kc := Keychain()
+	if !kc.Available() {
+		return nil, ports.ErrKeychainUnavailable
+	}
+	var id *age.Identity
+	if secret, err := kc.Get(KeychainService, KeychainAccount); err == nil {
+		id, _ = age.ParseIdentity(strings.TrimSpace(secret))
+	}
+	if id == nil {
+		generated, err := age.GenerateIdentity()
+		if err != nil {
+			return nil, err
+		}
+		if err := kc.Set(KeychainService, KeychainAccount, generated.String()); err != nil {
+			return nil, fmt.Errorf("failed to store the state key in the keychain: %w", err)
+		}
+		id = generated
+	}
+	mu.Lock()
+	identity = id
+	mu.Unlock()
+
+	s := &Settings{Recipient: id.Recipient().String(), EnabledAt: now.UTC()}
+	path, err := platform.Path(platform.ConfigDir, settingsFile)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write state encryption settings: %w", err)
+	}
+	return s, nil
+}
+
+// Disable decrypts the state files, then removes the settings and the
+// state key. It returns the number of files decrypted.
+func Disable() (int, error) {
+	n, err := rewrite(nil)
+	if err != nil {
+		return n, err
+	}
+	path, err := platform.Path(platform.ConfigDir, settingsFile)
+	if err != nil {
+		return n, err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return n, fmt.Errorf("failed to remove state encryption settings: %w", err)
+	}
+	if err := Keychain().Delete(KeychainService, KeychainAccount); err != nil && !errors.Is(err, ports.ErrKeychainItemNotFound) {
+		return n, fmt.Errorf("failed to remove the state key from the keychain: %w", err)
+	}
+	mu.Lock()
+	identity = nil
+	mu.Unlock()
+	return n, nil
+}
+
+// Count returns the number of encrypted and plain files in Dirs.
+func Count() (encrypted, plain int, err error) {
+	err = walk(func(path string, _ os.FileMode) error {
+		// #nosec G304 -- files in preflight's state directory.
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if age.IsEncrypted(data) {
+			encrypted++
+		} else {
+			plain++
+		}
+		return nil
+	})
+	return encrypted, plain, err
+}
+
+// rewrite writes every file in Dirs again, encrypted to recipient or in
+// plain text when recipient is nil, and returns the number of files
+// changed.
+func rewrite(recipient *age.Recipient) (int, error) {
+	n := 0
+	err := walk(func(path string, perm os.FileMode) error {
+		// #nosec G304 -- files in preflight's state directory.
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if age.IsEncrypted(raw) == (recipient != nil) {
+			return nil
+		}
+		data, err := ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := writeFile(path, data, perm, recipient); err != nil {
+			return err
+		}
+		n++
+		return nil
+	})
+	return n, err
+}
+
+// walk calls fn for every regular file in Dirs.
+func walk(fn func(path string, perm os.FileMode) error) error {
+	paths, err := Dirs()
+	if err != nil {
+		return err
+	}
+	for _, dir := range paths {
+		info, err := os.Stat(dir)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			if err := fn(dir, info.Mode().Perm()); err != nil {
+				return err
+			}
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if !entry.Type().IsRegular() || strings.HasSuffix(entry.Name(), ".tmp") {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			if err := fn(filepath.Join(dir, entry.Name()), info.Mode().Perm()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeFile writes data to path through a temporary file, encrypted to
+// recipient unless it is nil.
+func writeFile(path string, data []byte, perm os.FileMode, recipient *age.Recipient) error {
+	if recipient != nil {
+		ciphertext, err := age.Encrypt(data, recipient)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt %s: %w", path, err)
+		}
+		data = ciphertext
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// decrypt decrypts data with the state key, which is read from the
+// keychain once.
+func decrypt(data []byte) ([]byte, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if identity != nil {
+		if plaintext, err := age.Decrypt(data, identity); !errors.Is(err, age.ErrNoIdentityMatched) {
+			return plaintext, err
+		}
+	}
+	secret, err := Keychain().Get(KeychainService, KeychainAccount)
+	if errors.Is(err, ports.ErrKeychainItemNotFound) {
+		return nil, ErrKeyMissing
+	}
+	if err != nil {
+		return nil, err
+	}
+	id, err := age.ParseIdentity(strings.TrimSpace(secret))
+	if err != nil {
+		return nil, fmt.Errorf("invalid state key in the keychain: %w", err)
+	}
+	identity = id
+	return age.Decrypt(data, id)
+}
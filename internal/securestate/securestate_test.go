@@ -0,0 +1,195 @@
+package securestate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/felixgeelhaar/preflight/internal/adapters/age"
+	"github.com/felixgeelhaar/preflight/internal/adapters/keychain"
+	"github.com/felixgeelhaar/preflight/internal/ports"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setup points the state at a fresh directory and the keychain at a file,
+// and returns the state directory and the keychain.
+func setup(t *testing.T) (string, *keychain.FileKeychain) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("PREFLIGHT_HOME", home)
+
+	kc := keychain.NewFileKeychain(filepath.Join(t.TempDir(), "keychain.json"))
+	oldKeychain := Keychain
+	Keychain = func() ports.Keychain { return kc }
+	t.Cleanup(func() {
+		Keychain = oldKeychain
+		identity = nil
+	})
+	identity = nil
+	return home, kc
+}
+
+func writeState(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func raw(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return data
+}
+
+func TestEnableDisable(t *testing.T) {
+	home, kc := setup(t)
+	entry := filepath.Join(home, "history", "1.json")
+	patches := filepath.Join(home, "patches", "p.json")
+	other := filepath.Join(home, "snapshots", "s.json")
+	writeState(t, entry, `{"id":"1"}`)
+	writeState(t, patches, `{"id":"p"}`)
+	writeState(t, other, `{}`)
+	assert.False(t, Enabled())
+
+	n, err := Enable(time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.True(t, Enabled())
+	assert.True(t, age.IsEncrypted(raw(t, entry)))
+	assert.True(t, age.IsEncrypted(raw(t, patches)))
+	assert.False(t, age.IsEncrypted(raw(t, other)))
+	info, err := os.Stat(entry)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	secret, err := kc.Get(KeychainService, KeychainAccount)
+	require.NoError(t, err)
+	assert.Contains(t, secret, "AGE-SECRET-KEY-")
+
+	// Reads are transparent, in a new process too
+	identity = nil
+	data, err := ReadFile(entry)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":"1"}`, string(data))
+
+	encrypted, plain, err := Count()
+	require.NoError(t, err)
+	assert.Equal(t, 2, encrypted)
+	assert.Equal(t, 0, plain)
+
+	n, err = Disable()
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.False(t, Enabled())
+	assert.JSONEq(t, `{"id":"1"}`, string(raw(t, entry)))
+	_, err = kc.Get(KeychainService, KeychainAccount)
+	assert.ErrorIs(t, err, ports.ErrKeychainItemNotFound)
+}
+
+func TestWriteFile(t *testing.T) {
+	home, _ := setup(t)
+	path := filepath.Join(home, "history", "1.json")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+
+	require.NoError(t, WriteFile(path, []byte("plain"), 0o644))
+	assert.Equal(t, "plain", string(raw(t, path)))
+
+	_, err := Enable(time.Now())
+	require.NoError(t, err)
+	require.NoError(t, WriteFile(path, []byte("secret"), 0o644))
+	assert.True(t, age.IsEncrypted(raw(t, path)))
+	data, err := ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", string(data))
+	assert.NoFileExists(t, path+".tmp")
+}
+
+func TestEnable_Idempotent(t *testing.T) {
+	home, kc := setup(t)
+	_, err := Enable(time.Now())
+	require.NoError(t, err)
+	first, err := kc.Get(KeychainService, KeychainAccount)
+	require.NoError(t, err)
+
+	writeState(t, filepath.Join(home, "patches", "late.json"), "{}")
+	n, err := Enable(time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	second, err := kc.Get(KeychainService, KeychainAccount)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+}
+
+func TestEnable_KeepsKeyInKeychain(t *testing.T) {
+	_, kc := setup(t)
+	id, err := age.GenerateIdentity()
+	require.NoError(t, err)
+	require.NoError(t, kc.Set(KeychainService, KeychainAccount, id.String()))
+
+	_, err = Enable(time.Now())
+	require.NoError(t, err)
+	settings, err := LoadSettings()
+	require.NoError(t, err)
+	assert.Equal(t, id.Recipient().String(), settings.Recipient)
+}
+
+func TestReadFile_KeyMissing(t *testing.T) {
+	home, kc := setup(t)
+	path := filepath.Join(home, "history", "1.json")
+	writeState(t, path, "{}")
+	_, err := Enable(time.Now())
+	require.NoError(t, err)
+
+	require.NoError(t, kc.Delete(KeychainService, KeychainAccount))
+	identity = nil
+	_, err = ReadFile(path)
+	require.ErrorIs(t, err, ErrKeyMissing)
+
+	// Disabling needs the key and leaves encryption on without it
+	_, err = Disable()
+	require.Error(t, err)
+	assert.True(t, Enabled())
+}
+
+type unavailableKeychain struct{ ports.Keychain }
+
+func (unavailableKeychain) Available() bool { return false }
+
+func TestEnable_KeychainUnavailable(t *testing.T) {
+	setup(t)
+	Keychain = func() ports.Keychain { return unavailableKeychain{} }
+
+	_, err := Enable(time.Now())
+	require.ErrorIs(t, err, ports.ErrKeychainUnavailable)
+	assert.False(t, Enabled())
+}
+
+func TestEnable_AgentState(t *testing.T) {
+	home, _ := setup(t)
+	driftState := filepath.Join(home, "state.json")
+	agentLog := filepath.Join(home, "agent.log")
+	writeState(t, driftState, `{"files":{"/home/me/.zshrc":{}}}`)
+	writeState(t, agentLog, "level=WARN msg=\"drift detected\" path=/home/me/.zshrc\n")
+
+	n, err := Enable(time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	for _, path := range []string{driftState, agentLog} {
+		data := raw(t, path)
+		assert.True(t, age.IsEncrypted(data), path)
+		assert.NotContains(t, string(data), ".zshrc", path)
+	}
+
+	encrypted, plain, err := Count()
+	require.NoError(t, err)
+	assert.Equal(t, 2, encrypted)
+	assert.Zero(t, plain)
+
+	n, err = Disable()
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Contains(t, string(raw(t, agentLog)), "drift detected")
+}
@@ -301,8 +301,11 @@ func (s *StatefulCommandRunner) Run(_ context.Context, command string, args ...s
 			s.mu.Unlock()
 			return ports.CommandResult{Stdout: result, ExitCode: 0}, nil
 		case "install":
-			if len(args) >= 2 {
-				s.markInstalled("brew", args[1])
+			// `brew install a b c` installs every named package
+			for _, a := range args[1:] {
+				if !strings.HasPrefix(a, "-") {
+					s.markInstalled("brew", a)
+				}
 			}
 			s.mu.Unlock()
 			return ports.CommandResult{ExitCode: 0}, nil
@@ -0,0 +1,328 @@
+package testutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/url"
+	"testing"
+	"time"
+
+	ct "github.com/google/certificate-transparency-go"
+	cttls "github.com/google/certificate-transparency-go/tls"
+	ctx509 "github.com/google/certificate-transparency-go/x509"
+	"github.com/stretchr/testify/require"
+)
+
+// rekorOrigin names the fixture's Rekor log in checkpoints, in the
+// "<host> - <tree ID>" form of Rekor v1.
+const rekorOrigin = "rekor.example.com - 1"
+
+// oidSCTList is the X.509 extension holding embedded SCTs.
+var oidSCTList = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 2}
+
+// SigstoreFixture is a private Fulcio CA, certificate transparency log and
+// Rekor log that issue keyless Sigstore bundles for tests, along with the
+// trusted_root.json trusting them. Bundles carry everything a public-good
+// bundle does: an SCT embedded in the certificate, and a log entry with a
+// signed entry timestamp, an inclusion proof and a signed checkpoint.
+type SigstoreFixture struct {
+	// TrustedRoot is a trusted_root.json for the CA and logs.
+	TrustedRoot []byte
+
+	caKey    *ecdsa.PrivateKey
+	caCert   *x509.Certificate
+	ctKey    *ecdsa.PrivateKey
+	ctLogID  [sha256.Size]byte
+	rekorKey *ecdsa.PrivateKey
+	logID    []byte
+	// leaves are the bodies of the Rekor log, in order.
+	leaves [][]byte
+}
+
+// NewSigstoreFixture creates a CA and its logs.
+func NewSigstoreFixture(t testing.TB) *SigstoreFixture {
+	t.Helper()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{Organization: []string{"sigstore.dev"}, CommonName: "sigstore"},
+		NotBefore:             time.Now().Add(-24 * time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	caCert, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	ctKey, ctDER, ctLogID := newLogKey(t)
+	rekorKey, rekorDER, rekorLogID := newLogKey(t)
+	start := caCert.NotBefore.Format(time.RFC3339)
+	logKey := func(der []byte, id [sha256.Size]byte, baseURL string) map[string]any {
+		return map[string]any{
+			"baseUrl":       baseURL,
+			"hashAlgorithm": "SHA2_256",
+			"publicKey": map[string]any{
+				"rawBytes":   base64.StdEncoding.EncodeToString(der),
+				"keyDetails": "PKIX_ECDSA_P256_SHA_256",
+				"validFor":   map[string]any{"start": start},
+			},
+			"logId": map[string]any{"keyId": base64.StdEncoding.EncodeToString(id[:])},
+		}
+	}
+
+	root := map[string]any{
+		"mediaType": "application/vnd.dev.sigstore.trustedroot+json;version=0.1",
+		"tlogs":     []any{logKey(rekorDER, rekorLogID, "https://rekor.example.com")},
+		"ctlogs":    []any{logKey(ctDER, ctLogID, "https://ctfe.example.com")},
+		"certificateAuthorities": []any{map[string]any{
+			"uri": "https://fulcio.example.com",
+			"certChain": map[string]any{"certificates": []any{
+				map[string]any{"rawBytes": base64.StdEncoding.EncodeToString(caDER)},
+			}},
+			"validFor": map[string]any{"start": start},
+		}},
+	}
+	trustedRoot, err := json.Marshal(root)
+	require.NoError(t, err)
+
+	return &SigstoreFixture{
+		TrustedRoot: trustedRoot,
+		caKey:       caKey,
+		caCert:      caCert,
+		ctKey:       ctKey,
+		ctLogID:     ctLogID,
+		rekorKey:    rekorKey,
+		logID:       rekorLogID[:],
+	}
+}
+
+func newLogKey(t testing.TB) (*ecdsa.PrivateKey, []byte, [sha256.Size]byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	return key, der, sha256.Sum256(der)
+}
+
+// Sign returns a Sigstore bundle (.sigstore.json) signing content as the
+// OIDC subject of issuer, like 'cosign sign-blob --bundle'. Subjects
+// containing "@" become email SANs and others URI SANs.
+func (f *SigstoreFixture) Sign(t testing.TB, content []byte, issuer, subject string) []byte {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	certDER := f.issueCertificate(t, &key.PublicKey, issuer, subject)
+
+	digest := sha256.Sum256(content)
+	signature, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	require.NoError(t, err)
+
+	body, err := json.Marshal(map[string]any{
+		"apiVersion": "0.0.1",
+		"kind":       "hashedrekord",
+		"spec": map[string]any{
+			"data": map[string]any{"hash": map[string]any{"algorithm": "sha256", "value": hex.EncodeToString(digest[:])}},
+			"signature": map[string]any{
+				"content": base64.StdEncoding.EncodeToString(signature),
+				"publicKey": map[string]any{"content": base64.StdEncoding.EncodeToString(
+					pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}))},
+			},
+		},
+	})
+	require.NoError(t, err)
+	encodedBody := base64.StdEncoding.EncodeToString(body)
+
+	logIndex := int64(len(f.leaves))
+	f.leaves = append(f.leaves, body)
+	integratedTime := time.Now().Unix()
+	payload, err := json.Marshal(struct {
+		Body           string `json:"body"`
+		IntegratedTime int64  `json:"integratedTime"`
+		LogID          string `json:"logID"`
+		LogIndex       int64  `json:"logIndex"`
+	}{encodedBody, integratedTime, hex.EncodeToString(f.logID), logIndex})
+	require.NoError(t, err)
+	set := f.signLog(t, payload)
+
+	hashes := inclusionProof(int(logIndex), f.leaves)
+	proofHashes := make([]string, len(hashes))
+	for i, h := range hashes {
+		proofHashes[i] = base64.StdEncoding.EncodeToString(h)
+	}
+	rootHash := treeHash(f.leaves)
+
+	bundle, err := json.Marshal(map[string]any{
+		"mediaType": "application/vnd.dev.sigstore.bundle.v0.3+json",
+		"verificationMaterial": map[string]any{
+			"certificate": map[string]any{"rawBytes": base64.StdEncoding.EncodeToString(certDER)},
+			"tlogEntries": []any{map[string]any{
+				"logIndex":         fmt.Sprint(logIndex),
+				"logId":            map[string]any{"keyId": base64.StdEncoding.EncodeToString(f.logID)},
+				"kindVersion":      map[string]any{"kind": "hashedrekord", "version": "0.0.1"},
+				"integratedTime":   fmt.Sprint(integratedTime),
+				"inclusionPromise": map[string]any{"signedEntryTimestamp": base64.StdEncoding.EncodeToString(set)},
+				"inclusionProof": map[string]any{
+					"logIndex":   fmt.Sprint(logIndex),
+					"rootHash":   base64.StdEncoding.EncodeToString(rootHash),
+					"treeSize":   fmt.Sprint(len(f.leaves)),
+					"hashes":     proofHashes,
+					"checkpoint": map[string]any{"envelope": f.checkpoint(t, len(f.leaves), rootHash)},
+				},
+				"canonicalizedBody": encodedBody,
+			}},
+		},
+		"messageSignature": map[string]any{
+			"messageDigest": map[string]any{"algorithm": "SHA2_256", "digest": base64.StdEncoding.EncodeToString(digest[:])},
+			"signature":     base64.StdEncoding.EncodeToString(signature),
+		},
+	})
+	require.NoError(t, err)
+	return bundle
+}
+
+// issueCertificate issues a Fulcio certificate for the OIDC identity with
+// an SCT from the certificate transparency log embedded in it.
+func (f *SigstoreFixture) issueCertificate(t testing.TB, key *ecdsa.PublicKey, issuer, subject string) []byte {
+	t.Helper()
+
+	issuerExt, err := asn1.MarshalWithParams(issuer, "utf8")
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(10 * time.Minute),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		ExtraExtensions: []pkix.Extension{
+			{Id: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 8}, Value: issuerExt},
+		},
+	}
+	if u, err := url.Parse(subject); err == nil && u.Scheme != "" {
+		template.URIs = []*url.URL{u}
+	} else {
+		template.EmailAddresses = []string{subject}
+	}
+
+	// The SCT signs the certificate without the SCT list, as the
+	// precertificate Fulcio submits to the log.
+	precertDER, err := x509.CreateCertificate(rand.Reader, template, f.caCert, key, f.caKey)
+	require.NoError(t, err)
+	precert, err := x509.ParseCertificate(precertDER)
+	require.NoError(t, err)
+
+	sct := ct.SignedCertificateTimestamp{
+		SCTVersion: ct.V1,
+		LogID:      ct.LogID{KeyID: f.ctLogID},
+		Timestamp:  uint64(time.Now().UnixMilli()),
+	}
+	input, err := ct.SerializeSCTSignatureInput(sct, ct.LogEntry{Leaf: ct.MerkleTreeLeaf{
+		Version:  ct.V1,
+		LeafType: ct.TimestampedEntryLeafType,
+		TimestampedEntry: &ct.TimestampedEntry{
+			Timestamp: sct.Timestamp,
+			EntryType: ct.PrecertLogEntryType,
+			PrecertEntry: &ct.PreCert{
+				IssuerKeyHash:  sha256.Sum256(f.caCert.RawSubjectPublicKeyInfo),
+				TBSCertificate: precert.RawTBSCertificate,
+			},
+		},
+	}})
+	require.NoError(t, err)
+	inputHash := sha256.Sum256(input)
+	sctSignature, err := ecdsa.SignASN1(rand.Reader, f.ctKey, inputHash[:])
+	require.NoError(t, err)
+	sct.Signature = ct.DigitallySigned{
+		Algorithm: cttls.SignatureAndHashAlgorithm{Hash: cttls.SHA256, Signature: cttls.ECDSA},
+		Signature: sctSignature,
+	}
+
+	serialized, err := cttls.Marshal(sct)
+	require.NoError(t, err)
+	list, err := cttls.Marshal(ctx509.SignedCertificateTimestampList{
+		SCTList: []ctx509.SerializedSCT{{Val: serialized}},
+	})
+	require.NoError(t, err)
+	listExt, err := asn1.Marshal(list)
+	require.NoError(t, err)
+	template.ExtraExtensions = append(template.ExtraExtensions, pkix.Extension{Id: oidSCTList, Value: listExt})
+
+	certDER, err := x509.CreateCertificate(rand.Reader, template, f.caCert, key, f.caKey)
+	require.NoError(t, err)
+	return certDER
+}
+
+// checkpoint returns the Rekor log's signed checkpoint for a tree.
+func (f *SigstoreFixture) checkpoint(t testing.TB, size int, rootHash []byte) string {
+	t.Helper()
+
+	body := fmt.Sprintf("%s\n%d\n%s\n", rekorOrigin, size, base64.StdEncoding.EncodeToString(rootHash))
+	signature := f.signLog(t, []byte(body))
+	// Signatures start with a key hint: the first four bytes of the log ID.
+	keyHint := append([]byte{}, f.logID[:4]...)
+	return fmt.Sprintf("%s\n— rekor.example.com %s\n", body,
+		base64.StdEncoding.EncodeToString(append(keyHint, signature...)))
+}
+
+// signLog signs message with the Rekor log key.
+func (f *SigstoreFixture) signLog(t testing.TB, message []byte) []byte {
+	t.Helper()
+	hash := sha256.Sum256(message)
+	signature, err := ecdsa.SignASN1(rand.Reader, f.rekorKey, hash[:])
+	require.NoError(t, err)
+	return signature
+}
+
+// treeHash returns the RFC 6962 Merkle tree hash of leaves.
+func treeHash(leaves [][]byte) []byte {
+	if len(leaves) == 1 {
+		hash := sha256.Sum256(append([]byte{0}, leaves[0]...))
+		return hash[:]
+	}
+	k := splitPoint(len(leaves))
+	return nodeHash(treeHash(leaves[:k]), treeHash(leaves[k:]))
+}
+
+// inclusionProof returns the RFC 6962 audit path of leaf index in leaves.
+func inclusionProof(index int, leaves [][]byte) [][]byte {
+	if len(leaves) == 1 {
+		return nil
+	}
+	k := splitPoint(len(leaves))
+	if index < k {
+		return append(inclusionProof(index, leaves[:k]), treeHash(leaves[k:]))
+	}
+	return append(inclusionProof(index-k, leaves[k:]), treeHash(leaves[:k]))
+}
+
+func nodeHash(left, right []byte) []byte {
+	data := append([]byte{1}, left...)
+	hash := sha256.Sum256(append(data, right...))
+	return hash[:]
+}
+
+// splitPoint returns the largest power of two smaller than n.
+func splitPoint(n int) int {
+	k := 1
+	for k<<1 < n {
+		k <<= 1
+	}
+	return k
+}
@@ -2,8 +2,11 @@ package tui
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
 	"github.com/felixgeelhaar/preflight/internal/domain/execution"
@@ -31,6 +34,7 @@ type applyProgressModel struct {
 	plan           *execution.Plan
 	options        ApplyProgressOptions
 	progressBar    components.Progress
+	spinner        components.Spinner
 	styles         ui.Styles
 	width          int
 	height         int
@@ -38,6 +42,10 @@ type applyProgressModel struct {
 	stepsCompleted int
 	stepsFailed    int
 	currentStep    compiler.StepID
+	pending        []string
+	running        map[string]time.Time
+	started        time.Time
+	now            func() time.Time
 	completed      []execution.StepResult
 	done           bool
 	cancelled      bool
@@ -48,21 +56,32 @@ func newApplyProgressModel(plan *execution.Plan, opts ApplyProgressOptions) appl
 	styles := ui.DefaultStyles()
 	progressBar := components.NewProgress().WithWidth(40)
 
+	needsApply := plan.NeedsApply()
+	pending := make([]string, 0, len(needsApply))
+	for _, entry := range needsApply {
+		pending = append(pending, entry.Step().ID().String())
+	}
+
 	return applyProgressModel{
 		plan:        plan,
 		options:     opts,
 		progressBar: progressBar,
+		spinner:     components.NewSpinner().WithStyles(styles),
 		styles:      styles,
 		width:       80,
 		height:      24,
-		stepsTotal:  len(plan.NeedsApply()),
+		stepsTotal:  len(needsApply),
+		pending:     pending,
+		running:     make(map[string]time.Time),
+		started:     time.Now(),
+		now:         time.Now,
 		completed:   make([]execution.StepResult, 0),
 	}
 }
 
 // Init initializes the model.
 func (m applyProgressModel) Init() tea.Cmd {
-	return tea.WindowSize()
+	return tea.Batch(tea.WindowSize(), m.spinner.Init())
 }
 
 // Update handles messages.
@@ -80,11 +99,20 @@ func (m applyProgressModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Quit
 		}
 
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
 	case StepStartMsg:
 		m.currentStep = msg.StepID
+		m.running[msg.StepID.String()] = m.now()
 		return m, nil
 
 	case StepCompleteMsg:
+		id := msg.Result.StepID().String()
+		delete(m.running, id)
+		m.pending = removeString(m.pending, id)
 		m.completed = append(m.completed, msg.Result)
 		m.stepsCompleted++
 
@@ -136,14 +164,21 @@ func (m applyProgressModel) View() string {
 	if m.stepsFailed > 0 {
 		statusLine += fmt.Sprintf(" (%d failed)", m.stepsFailed)
 	}
+	statusLine += " · " + formatElapsed(m.now().Sub(m.started)) + " elapsed"
+	if eta, ok := m.eta(); ok && !m.done {
+		statusLine += " · ~" + formatElapsed(eta) + " remaining"
+	}
 	b.WriteString(m.styles.Help.Render(statusLine))
 	b.WriteString("\n\n")
 
-	// Current step (if any)
-	if m.currentStep.String() != "" && !m.done {
-		currentLine := fmt.Sprintf("Running: %s", m.currentStep.String())
-		b.WriteString(m.styles.Info.Render(currentLine))
-		b.WriteString("\n\n")
+	// Running steps, each with a spinner and its elapsed time
+	if !m.done && len(m.running) > 0 {
+		for _, id := range m.runningIDs() {
+			line := fmt.Sprintf("%s %s (%s)", m.spinner.View(), id, formatElapsed(m.now().Sub(m.running[id])))
+			b.WriteString(m.styles.Info.Render(line))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
 	}
 
 	// Recent completions (if showing details)
@@ -160,6 +195,9 @@ func (m applyProgressModel) View() string {
 		for _, result := range m.completed[start:] {
 			status := m.formatResultStatus(result)
 			line := fmt.Sprintf("  %s %s", status, result.StepID().String())
+			if d := result.Duration(); d > 0 {
+				line += m.styles.Help.Render(" " + formatElapsed(d))
+			}
 			b.WriteString(line)
 			b.WriteString("\n")
 		}
@@ -186,6 +224,51 @@ func (m applyProgressModel) View() string {
 	return b.String()
 }
 
+// runningIDs returns the running steps in the order they started.
+func (m applyProgressModel) runningIDs() []string {
+	ids := make([]string, 0, len(m.running))
+	for id := range m.running {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return m.running[ids[i]].Before(m.running[ids[j]])
+	})
+	return ids
+}
+
+// eta estimates the time left from the historical duration of each pending
+// step, falling back to the average duration of the steps completed in this
+// run. It reports false when there is nothing to base an estimate on.
+func (m applyProgressModel) eta() (time.Duration, bool) {
+	var fallback time.Duration
+	if len(m.completed) > 0 {
+		var sum time.Duration
+		for _, result := range m.completed {
+			sum += result.Duration()
+		}
+		fallback = sum / time.Duration(len(m.completed))
+	}
+
+	var remaining time.Duration
+	for _, id := range m.pending {
+		estimate, ok := m.options.Estimates[id]
+		if !ok {
+			if fallback == 0 {
+				return 0, false
+			}
+			estimate = fallback
+		}
+		if started, ok := m.running[id]; ok {
+			estimate -= m.now().Sub(started)
+			if estimate < 0 {
+				estimate = 0
+			}
+		}
+		remaining += estimate
+	}
+	return remaining, true
+}
+
 // progressPercent returns the current progress as a percentage (0.0 to 1.0).
 func (m applyProgressModel) progressPercent() float64 {
 	if m.stepsTotal == 0 {
@@ -213,3 +296,24 @@ func (m applyProgressModel) formatResultStatus(result execution.StepResult) stri
 func (m applyProgressModel) progress() float64 {
 	return m.progressPercent()
 }
+
+// formatElapsed formats a duration as m:ss, or h:mm:ss from an hour.
+func formatElapsed(d time.Duration) string {
+	d = d.Round(time.Second)
+	h := int(d / time.Hour)
+	mins := int(d/time.Minute) % 60
+	secs := int(d/time.Second) % 60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, mins, secs)
+	}
+	return fmt.Sprintf("%d:%02d", mins, secs)
+}
+
+func removeString(list []string, s string) []string {
+	for i, v := range list {
+		if v == s {
+			return append(list[:i:i], list[i+1:]...)
+		}
+	}
+	return list
+}
@@ -1,12 +1,16 @@
 package tui
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
 	"github.com/felixgeelhaar/preflight/internal/domain/execution"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestApplyProgressModel_Init(t *testing.T) {
@@ -258,3 +262,62 @@ func TestApplyProgressModel_View_Done(t *testing.T) {
 	view := model.View()
 	assert.Contains(t, view, "completed successfully")
 }
+
+func TestApplyProgressModel_ETA(t *testing.T) {
+	t.Parallel()
+
+	plan := createTestPlanWithMultipleEntries(t)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	model := newApplyProgressModel(plan, ApplyProgressOptions{Estimates: map[string]time.Duration{
+		"brew:formula:git":  10 * time.Second,
+		"brew:formula:curl": 20 * time.Second,
+	}})
+	model.started = now
+	model.now = func() time.Time { return now }
+
+	// jq has no estimate and nothing completed yet to average.
+	_, ok := model.eta()
+	assert.False(t, ok)
+
+	git := mustNewStepID(t, "brew:formula:git")
+	newModel, _ := model.Update(StepStartMsg{StepID: git})
+	m := newModel.(applyProgressModel)
+	now = now.Add(4 * time.Second)
+	newModel, _ = m.Update(StepCompleteMsg{Result: execution.NewStepResult(git, compiler.StatusSatisfied, nil).WithDuration(4 * time.Second)})
+	m = newModel.(applyProgressModel)
+
+	newModel, _ = m.Update(StepStartMsg{StepID: mustNewStepID(t, "brew:formula:curl")})
+	m = newModel.(applyProgressModel)
+	now = now.Add(5 * time.Second)
+
+	// curl: 20s estimate less 5s running; jq: the 4s average of this run.
+	eta, ok := m.eta()
+	assert.True(t, ok)
+	assert.Equal(t, 19*time.Second, eta)
+
+	view := m.View()
+	assert.Contains(t, view, "brew:formula:curl (0:05)")
+	assert.Contains(t, view, "0:09 elapsed")
+	assert.Contains(t, view, "~0:19 remaining")
+}
+
+func TestRunApplyProgress_Quiet(t *testing.T) {
+	t.Parallel()
+
+	plan := createTestPlanWithMultipleEntries(t)
+	result, err := RunApplyProgress(context.Background(), plan, ApplyProgressOptions{Quiet: true},
+		func(_ context.Context, start execution.StepStartObserver, done execution.StepObserver) ([]execution.StepResult, error) {
+			assert.Nil(t, start)
+			assert.Nil(t, done)
+			return []execution.StepResult{
+				execution.NewStepResult(mustNewStepID(t, "brew:formula:git"), compiler.StatusSatisfied, nil),
+				execution.NewStepResult(mustNewStepID(t, "brew:formula:jq"), compiler.StatusFailed, errors.New("no bottle")),
+			}, nil
+		})
+	require.NoError(t, err)
+	assert.False(t, result.Success)
+	assert.Equal(t, 3, result.StepsTotal)
+	assert.Equal(t, 2, result.StepsDone)
+	assert.Len(t, result.Errors, 1)
+	assert.Len(t, result.Results, 2)
+}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/felixgeelhaar/preflight/internal/domain/config"
 	"gopkg.in/yaml.v3"
 )
 
@@ -129,6 +130,7 @@ func (g *ConfigGenerator) generateManifest() error {
 		return err
 	}
 
+	data = append([]byte(config.SchemaModeline(config.ManifestSchemaFile)), data...)
 	manifestPath := filepath.Join(g.targetDir, "preflight.yaml")
 	return os.WriteFile(manifestPath, data, 0o644)
 }
@@ -158,6 +160,7 @@ func (g *ConfigGenerator) generateBaseLayer(provider, presetName string) error {
 		return err
 	}
 
+	data = append([]byte(config.SchemaModeline(config.LayerSchemaFile)), data...)
 	layerPath := filepath.Join(g.targetDir, "layers", "base.yaml")
 	return os.WriteFile(layerPath, data, 0o644)
 }
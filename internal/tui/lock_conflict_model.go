@@ -0,0 +1,359 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/felixgeelhaar/preflight/internal/domain/sync"
+	"github.com/felixgeelhaar/preflight/internal/tui/ui"
+)
+
+// LockConflictResult holds the result of interactive lockfile conflict resolution.
+type LockConflictResult struct {
+	Resolutions []sync.Resolution
+	Cancelled   bool
+}
+
+// lockConflictModel is the Bubble Tea model for resolving lockfile conflicts
+// with a three-way (local/base/remote) view per package.
+type lockConflictModel struct {
+	conflicts   []sync.LockConflict
+	resolutions []sync.Resolution
+	current     int
+	editing     bool
+	input       textinput.Model
+	errMsg      string
+	styles      ui.Styles
+	width       int
+	height      int
+	now         func() time.Time
+	done        bool
+	cancelled   bool
+}
+
+// newLockConflictModel creates a new lockfile conflict model.
+func newLockConflictModel(conflicts []sync.LockConflict) lockConflictModel {
+	input := textinput.New()
+	input.Placeholder = "version"
+	input.CharLimit = 128
+
+	return lockConflictModel{
+		conflicts:   conflicts,
+		resolutions: make([]sync.Resolution, len(conflicts)),
+		input:       input,
+		styles:      ui.DefaultStyles(),
+		width:       80,
+		height:      24,
+		now:         time.Now,
+	}
+}
+
+// Init initializes the model.
+func (m lockConflictModel) Init() tea.Cmd {
+	return tea.WindowSize()
+}
+
+// Update handles messages.
+func (m lockConflictModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.styles = m.styles.WithWidth(msg.Width)
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.editing {
+			return m.handleEditKey(msg)
+		}
+		return m.handleKeyMsg(msg)
+	}
+
+	return m, nil
+}
+
+// handleKeyMsg handles key input while browsing conflicts.
+func (m lockConflictModel) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	//nolint:exhaustive // We only handle specific key types
+	switch msg.Type {
+	case tea.KeyCtrlC, tea.KeyEsc:
+		m.cancelled = true
+		return m, tea.Quit
+	case tea.KeyUp:
+		return m.move(-1), nil
+	case tea.KeyDown:
+		return m.move(1), nil
+	case tea.KeyRunes:
+		if len(msg.Runes) == 0 {
+			return m, nil
+		}
+	default:
+		return m, nil
+	}
+
+	m.errMsg = ""
+	conflict := m.conflicts[m.current]
+
+	switch msg.Runes[0] {
+	case 'q':
+		m.cancelled = true
+		return m, tea.Quit
+	case 'n', 'j':
+		return m.move(1), nil
+	case 'p', 'k':
+		return m.move(-1), nil
+	case 'l':
+		return m.resolve(sync.ResolveManually(conflict, sync.ChooseLocal))
+	case 'r':
+		return m.resolve(sync.ResolveManually(conflict, sync.ChooseRemote))
+	case 'b':
+		if !conflict.HasBase() {
+			m.errMsg = "No base version known for this package"
+			return m, nil
+		}
+		return m.resolve(sync.ResolveManually(conflict, sync.ChooseBase))
+	case 's':
+		return m.resolve(sync.ResolveManually(conflict, sync.ChooseSkip))
+	case 'e':
+		m.editing = true
+		initial := conflict.Remote().Version()
+		if initial == "" {
+			initial = conflict.Local().Version()
+		}
+		m.input.SetValue(initial)
+		m.input.CursorEnd()
+		m.input.Focus()
+		return m, textinput.Blink
+	case 'L':
+		return m.resolveAll(sync.ChooseLocal)
+	case 'R':
+		return m.resolveAll(sync.ChooseRemote)
+	}
+
+	return m, nil
+}
+
+// handleEditKey handles key input while editing a custom version.
+func (m lockConflictModel) handleEditKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	//nolint:exhaustive // We only handle specific key types
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		m.cancelled = true
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.editing = false
+		m.input.Blur()
+		return m, nil
+	case tea.KeyEnter:
+		version := strings.TrimSpace(m.input.Value())
+		if version == "" {
+			m.errMsg = "Version cannot be empty"
+			return m, nil
+		}
+		m.editing = false
+		m.input.Blur()
+		m.errMsg = ""
+		return m.resolve(sync.ResolveWithVersion(m.conflicts[m.current], version, m.now()))
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// move changes the current conflict by delta, clamped to the list bounds.
+func (m lockConflictModel) move(delta int) lockConflictModel {
+	m.current = max(0, min(len(m.conflicts)-1, m.current+delta))
+	return m
+}
+
+// resolve records a resolution for the current conflict and advances to the
+// next unresolved one, finishing once every conflict is resolved.
+func (m lockConflictModel) resolve(resolution sync.Resolution) (tea.Model, tea.Cmd) {
+	m.resolutions[m.current] = resolution
+
+	for offset := 1; offset <= len(m.conflicts); offset++ {
+		i := (m.current + offset) % len(m.conflicts)
+		if m.resolutions[i].IsZero() {
+			m.current = i
+			return m, nil
+		}
+	}
+
+	m.done = true
+	return m, tea.Quit
+}
+
+// resolveAll resolves every conflict with the same choice.
+func (m lockConflictModel) resolveAll(choice sync.ResolutionChoice) (tea.Model, tea.Cmd) {
+	for i, c := range m.conflicts {
+		m.resolutions[i] = sync.ResolveManually(c, choice)
+	}
+	m.done = true
+	return m, tea.Quit
+}
+
+// View renders the model.
+func (m lockConflictModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.Title.Render("Lockfile Conflicts"))
+	b.WriteString("\n")
+
+	resolved := 0
+	for _, r := range m.resolutions {
+		if !r.IsZero() {
+			resolved++
+		}
+	}
+	b.WriteString(m.styles.Subtitle.Render(fmt.Sprintf("Conflict %d/%d • Resolved: %d/%d",
+		m.current+1, len(m.conflicts), resolved, len(m.conflicts))))
+	b.WriteString("\n\n")
+
+	b.WriteString(m.renderList())
+	b.WriteString("\n")
+
+	if m.current < len(m.conflicts) {
+		b.WriteString(m.renderConflict(m.conflicts[m.current]))
+	}
+	b.WriteString("\n")
+
+	if m.editing {
+		b.WriteString("Version: ")
+		b.WriteString(m.input.View())
+		b.WriteString("\n\n")
+	}
+	if m.errMsg != "" {
+		b.WriteString(m.styles.Error.Render(m.errMsg))
+		b.WriteString("\n\n")
+	}
+
+	helpItems := []string{
+		"l local",
+		"r remote",
+		"b base",
+		"e edit",
+		"s skip",
+		"n/p next/prev",
+		"L/R all local/remote",
+		"q/Esc cancel",
+	}
+	if m.editing {
+		helpItems = []string{"enter save", "esc back"}
+	}
+	b.WriteString(m.styles.Help.Render(strings.Join(helpItems, " • ")))
+
+	return b.String()
+}
+
+// renderList renders the conflicting packages with their resolution status.
+func (m lockConflictModel) renderList() string {
+	var b strings.Builder
+
+	visible := max(3, m.height-18)
+	start := 0
+	if m.current >= visible {
+		start = m.current - visible + 1
+	}
+	end := min(len(m.conflicts), start+visible)
+
+	for i := start; i < end; i++ {
+		cursor := "  "
+		if i == m.current {
+			cursor = "▸ "
+		}
+		status := m.styles.Warning.Render("unresolved")
+		if r := m.resolutions[i]; !r.IsZero() {
+			status = m.styles.Success.Render("✓ " + describeResolution(r))
+		}
+		line := cursor + padOrTruncate(m.conflicts[i].PackageKey(), 32) + " " + status
+		if i == m.current {
+			line = m.styles.ListItemActive.Render(line)
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// renderConflict renders the local, base and remote sides side by side.
+func (m lockConflictModel) renderConflict(c sync.LockConflict) string {
+	var b strings.Builder
+
+	panelWidth := max(16, (m.width-6)/3)
+	sides := []struct {
+		title string
+		info  sync.PackageLockInfo
+		style func(...string) string
+	}{
+		{"LOCAL", c.Local(), m.styles.DiffRemove.Render},
+		{"BASE", c.Base(), m.styles.DiffHeader.Render},
+		{"REMOTE", c.Remote(), m.styles.DiffAdd.Render},
+	}
+
+	headers := make([]string, 0, len(sides))
+	for _, s := range sides {
+		headers = append(headers, s.style(padOrTruncate(s.title, panelWidth)))
+	}
+	b.WriteString(m.styles.Subtitle.Render(fmt.Sprintf("%s (%s)", c.PackageKey(), c.Type())))
+	b.WriteString("\n")
+	b.WriteString(strings.Join(headers, " │ "))
+	b.WriteString("\n")
+	b.WriteString(m.styles.Help.Render(strings.Repeat("─", panelWidth*3+6)))
+	b.WriteString("\n")
+
+	rows := [][]string{}
+	for _, s := range sides {
+		rows = append(rows, lockInfoLines(s.info))
+	}
+	for line := 0; line < len(rows[0]); line++ {
+		cells := make([]string, 0, len(rows))
+		for _, r := range rows {
+			cells = append(cells, m.styles.Paragraph.Render(padOrTruncate(r[line], panelWidth)))
+		}
+		b.WriteString(strings.Join(cells, " │ "))
+		b.WriteString("\n")
+	}
+
+	if r := m.resolutions[m.current]; !r.IsZero() {
+		b.WriteString("\n")
+		b.WriteString(m.styles.Success.Render("✓ Resolved with: " + describeResolution(r)))
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// lockInfoLines describes one side of a conflict.
+func lockInfoLines(info sync.PackageLockInfo) []string {
+	if info.IsZero() {
+		return []string{"(absent)", "", ""}
+	}
+	by := info.Provenance().ModifiedBy()
+	if len(by) > 8 {
+		by = by[:8]
+	}
+	if by == "" {
+		by = "unknown"
+	}
+	at := "unknown"
+	if !info.ModifiedAt().IsZero() {
+		at = info.ModifiedAt().Local().Format("2006-01-02 15:04")
+	}
+	return []string{info.Version(), "by " + by, at}
+}
+
+// describeResolution summarizes a resolution for display.
+func describeResolution(r sync.Resolution) string {
+	switch {
+	case r.IsSkipped():
+		return "skip (keep local)"
+	case r.IsDelete():
+		return r.Choice().String() + " (remove)"
+	default:
+		return fmt.Sprintf("%s %s", r.Choice(), r.Result().Version())
+	}
+}
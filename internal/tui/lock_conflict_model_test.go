@@ -0,0 +1,146 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/felixgeelhaar/preflight/internal/domain/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testLockConflicts(t *testing.T) []sync.LockConflict {
+	t.Helper()
+	machineA, err := sync.ParseMachineID("550e8400-e29b-41d4-a716-446655440000")
+	require.NoError(t, err)
+	prov := sync.NewPackageProvenance(machineA, sync.NewVersionVector().Increment(machineA))
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	return []sync.LockConflict{
+		sync.NewLockConflict("brew:ripgrep", sync.BothModified,
+			sync.NewPackageLockInfoWithTime("14.0.0", prov, at),
+			sync.NewPackageLockInfoWithTime("14.1.0", prov, at),
+			sync.NewPackageLockInfoWithTime("13.0.0", prov, at)),
+		sync.NewLockConflict("brew:fd", sync.VersionMismatch,
+			sync.NewPackageLockInfoWithTime("9.0.0", prov, at),
+			sync.NewPackageLockInfoWithTime("10.0.0", prov, at),
+			sync.PackageLockInfo{}),
+	}
+}
+
+func pressKey(m lockConflictModel, r rune) (lockConflictModel, tea.Cmd) {
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	return updated.(lockConflictModel), cmd
+}
+
+func TestLockConflictModel_PickPerItem(t *testing.T) {
+	t.Parallel()
+
+	m := newLockConflictModel(testLockConflicts(t))
+
+	m, cmd := pressKey(m, 'b')
+	assert.Nil(t, cmd)
+	assert.Equal(t, 1, m.current, "advances to next unresolved conflict")
+	assert.Equal(t, sync.ChooseBase, m.resolutions[0].Choice())
+
+	// No base for the second conflict.
+	m, _ = pressKey(m, 'b')
+	assert.True(t, m.resolutions[1].IsZero())
+	assert.Contains(t, m.View(), "No base version")
+
+	m, cmd = pressKey(m, 'r')
+	require.NotNil(t, cmd)
+	assert.True(t, m.done)
+	assert.Equal(t, "10.0.0", m.resolutions[1].Result().Version())
+}
+
+func TestLockConflictModel_EditVersion(t *testing.T) {
+	t.Parallel()
+
+	m := newLockConflictModel(testLockConflicts(t))
+	fixed := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return fixed }
+
+	m, _ = pressKey(m, 'e')
+	require.True(t, m.editing)
+	assert.Equal(t, "14.1.0", m.input.Value(), "prefilled with the remote version")
+
+	// Keys go to the input while editing.
+	m, _ = pressKey(m, '1')
+	assert.True(t, m.editing)
+	assert.True(t, m.resolutions[0].IsZero())
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(lockConflictModel)
+	assert.False(t, m.editing)
+	assert.Equal(t, sync.ChooseCustom, m.resolutions[0].Choice())
+	assert.Equal(t, "14.1.01", m.resolutions[0].Result().Version())
+	assert.Equal(t, fixed, m.resolutions[0].Result().ModifiedAt())
+}
+
+func TestLockConflictModel_EditRejectsEmptyVersion(t *testing.T) {
+	t.Parallel()
+
+	m := newLockConflictModel(testLockConflicts(t))
+	m, _ = pressKey(m, 'e')
+	m.input.SetValue("  ")
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(lockConflictModel)
+	assert.True(t, m.editing)
+	assert.Equal(t, "Version cannot be empty", m.errMsg)
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(lockConflictModel)
+	assert.False(t, m.editing)
+	assert.False(t, m.cancelled, "esc leaves edit mode without cancelling")
+}
+
+func TestLockConflictModel_ResolveAllAndCancel(t *testing.T) {
+	t.Parallel()
+
+	m := newLockConflictModel(testLockConflicts(t))
+	m, _ = pressKey(m, 'L')
+	assert.True(t, m.done)
+	for _, r := range m.resolutions {
+		assert.Equal(t, sync.ChooseLocal, r.Choice())
+	}
+
+	m = newLockConflictModel(testLockConflicts(t))
+	m, _ = pressKey(m, 'q')
+	assert.True(t, m.cancelled)
+}
+
+func TestLockConflictModel_Navigation(t *testing.T) {
+	t.Parallel()
+
+	m := newLockConflictModel(testLockConflicts(t))
+	m, _ = pressKey(m, 'p')
+	assert.Equal(t, 0, m.current)
+	m, _ = pressKey(m, 'n')
+	assert.Equal(t, 1, m.current)
+	m, _ = pressKey(m, 'n')
+	assert.Equal(t, 1, m.current)
+}
+
+func TestLockConflictModel_View(t *testing.T) {
+	t.Parallel()
+
+	m := newLockConflictModel(testLockConflicts(t))
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(lockConflictModel)
+
+	view := m.View()
+	assert.Contains(t, view, "Lockfile Conflicts")
+	assert.Contains(t, view, "LOCAL")
+	assert.Contains(t, view, "BASE")
+	assert.Contains(t, view, "REMOTE")
+	assert.Contains(t, view, "14.0.0")
+	assert.Contains(t, view, "13.0.0")
+	assert.Contains(t, view, "14.1.0")
+	assert.Contains(t, view, "by 550e8400")
+
+	m, _ = pressKey(m, 's')
+	assert.Contains(t, m.View(), "skip (keep local)")
+}
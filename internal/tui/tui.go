@@ -3,12 +3,16 @@ package tui
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/felixgeelhaar/preflight/internal/domain/advisor"
+	"github.com/felixgeelhaar/preflight/internal/domain/compiler"
 	"github.com/felixgeelhaar/preflight/internal/domain/execution"
 	"github.com/felixgeelhaar/preflight/internal/domain/merge"
+	"github.com/felixgeelhaar/preflight/internal/domain/sync"
 	"github.com/felixgeelhaar/preflight/internal/tui/ui"
 )
 
@@ -237,6 +241,8 @@ func RunPlanReview(ctx context.Context, plan *execution.Plan, opts PlanReviewOpt
 type ApplyProgressOptions struct {
 	Quiet       bool
 	ShowDetails bool
+	// Estimates are typical step durations by step ID, used for the ETA.
+	Estimates map[string]time.Duration
 }
 
 // NewApplyProgressOptions creates default apply progress options.
@@ -252,55 +258,99 @@ func (o ApplyProgressOptions) WithQuiet(quiet bool) ApplyProgressOptions {
 	return o
 }
 
+// WithEstimates sets typical step durations by step ID for the ETA.
+func (o ApplyProgressOptions) WithEstimates(estimates map[string]time.Duration) ApplyProgressOptions {
+	o.Estimates = estimates
+	return o
+}
+
 // ApplyProgressResult holds the result of apply progress.
 type ApplyProgressResult struct {
 	Success    bool
 	StepsTotal int
 	StepsDone  int
 	Errors     []error
+	// Results and Err are what the apply returned.
+	Results []execution.StepResult
+	Err     error
 }
 
-// RunApplyProgress runs the apply progress display.
-func RunApplyProgress(ctx context.Context, plan *execution.Plan, opts ApplyProgressOptions) (*ApplyProgressResult, error) {
-	// Handle quiet mode - just return success without TUI
+// ApplyFunc runs an apply, reporting each step to start before it runs and
+// to done after it ran.
+type ApplyFunc func(ctx context.Context, start execution.StepStartObserver, done execution.StepObserver) ([]execution.StepResult, error)
+
+// RunApplyProgress runs apply while displaying its progress. Pressing
+// Ctrl+C cancels the context apply runs with.
+func RunApplyProgress(ctx context.Context, plan *execution.Plan, opts ApplyProgressOptions, apply ApplyFunc) (*ApplyProgressResult, error) {
+	// Only steps that need applying are shown.
+	shown := make(map[string]bool)
+	for _, entry := range plan.NeedsApply() {
+		shown[entry.Step().ID().String()] = true
+	}
+
+	// Handle quiet mode - apply without TUI
 	if opts.Quiet {
-		return &ApplyProgressResult{
-			Success:    true,
-			StepsTotal: len(plan.NeedsApply()),
-			StepsDone:  len(plan.NeedsApply()),
-		}, nil
+		results, err := apply(ctx, nil, nil)
+		return newApplyProgressResult(shown, results, err, false), nil
 	}
 
-	// Create the apply progress model
-	model := newApplyProgressModel(plan, opts)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-	// Run the program
+	model := newApplyProgressModel(plan, opts)
 	p := tea.NewProgram(model, tea.WithContext(ctx))
-	finalModel, err := p.Run()
-	if err != nil {
-		return nil, fmt.Errorf("apply progress failed: %w", err)
-	}
 
-	// Extract result from final model
-	m, ok := finalModel.(applyProgressModel)
-	if !ok {
-		return nil, fmt.Errorf("unexpected model type")
-	}
-
-	// Collect errors from failed steps
-	var errors []error
-	for _, result := range m.completed {
-		if result.Error() != nil {
-			errors = append(errors, result.Error())
+	type outcome struct {
+		results []execution.StepResult
+		err     error
+	}
+	applied := make(chan outcome, 1)
+	go func() {
+		results, err := apply(ctx,
+			func(id compiler.StepID) {
+				if shown[id.String()] {
+					p.Send(StepStartMsg{StepID: id})
+				}
+			},
+			func(result execution.StepResult) {
+				if shown[result.StepID().String()] {
+					p.Send(StepCompleteMsg{Result: result})
+				}
+			})
+		p.Send(AllCompleteMsg{Results: results})
+		applied <- outcome{results, err}
+	}()
+
+	finalModel, runErr := p.Run()
+	cancelled := false
+	if m, ok := finalModel.(applyProgressModel); ok && m.cancelled {
+		cancelled = true
+		cancel()
+	}
+	out := <-applied
+	if runErr != nil && !errors.Is(runErr, tea.ErrProgramKilled) {
+		return nil, fmt.Errorf("apply progress failed: %w", runErr)
+	}
+
+	return newApplyProgressResult(shown, out.results, out.err, cancelled), nil
+}
+
+func newApplyProgressResult(shown map[string]bool, results []execution.StepResult, err error, cancelled bool) *ApplyProgressResult {
+	result := &ApplyProgressResult{
+		StepsTotal: len(shown),
+		Results:    results,
+		Err:        err,
+	}
+	for _, r := range results {
+		if shown[r.StepID().String()] {
+			result.StepsDone++
+		}
+		if r.Error() != nil {
+			result.Errors = append(result.Errors, r.Error())
 		}
 	}
-
-	return &ApplyProgressResult{
-		Success:    m.stepsFailed == 0 && !m.cancelled,
-		StepsTotal: m.stepsTotal,
-		StepsDone:  m.stepsCompleted,
-		Errors:     errors,
-	}, nil
+	result.Success = err == nil && len(result.Errors) == 0 && !cancelled
+	return result
 }
 
 // DoctorReportOptions configures the doctor report TUI.
@@ -497,6 +547,31 @@ func RunConflictResolution(ctx context.Context, filePath string, conflicts []mer
 	}, nil
 }
 
+// RunLockConflictResolution runs the interactive lockfile conflict resolver.
+func RunLockConflictResolution(ctx context.Context, conflicts []sync.LockConflict) (*LockConflictResult, error) {
+	if len(conflicts) == 0 {
+		return &LockConflictResult{}, nil
+	}
+
+	model := newLockConflictModel(conflicts)
+
+	p := tea.NewProgram(model, tea.WithContext(ctx))
+	finalModel, err := p.Run()
+	if err != nil {
+		return nil, fmt.Errorf("conflict resolution failed: %w", err)
+	}
+
+	m, ok := finalModel.(lockConflictModel)
+	if !ok {
+		return nil, fmt.Errorf("unexpected model type")
+	}
+
+	if m.cancelled {
+		return &LockConflictResult{Cancelled: true}, nil
+	}
+	return &LockConflictResult{Resolutions: m.resolutions}, nil
+}
+
 // RunLayerPreview runs the layer preview interface.
 func RunLayerPreview(ctx context.Context, files []PreviewFile, opts LayerPreviewOptions) (*LayerPreviewResult, error) {
 	if len(files) == 0 {
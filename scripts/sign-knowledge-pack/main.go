@@ -0,0 +1,67 @@
+// Command sign-knowledge-pack signs the tool knowledge pack with the
+// project's knowledge pack key, writing tools.yaml.sig next to tools.yaml.
+//
+// Usage:
+//
+//	go run ./scripts/sign-knowledge-pack -key <ed25519 private key>
+//	go run ./scripts/sign-knowledge-pack -key <ed25519 private key> -public-key
+//
+// The key is an unencrypted OpenSSH ED25519 private key (ssh-keygen -t
+// ed25519 -N ""). With -public-key the command prints the key's public half
+// in the form knowledgePackKey in internal/domain/catalog/tools/pack.go
+// expects, for rotating the key, and signs nothing.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/felixgeelhaar/preflight/internal/domain/catalog/tools"
+	"github.com/felixgeelhaar/preflight/internal/domain/marketplace"
+)
+
+func main() {
+	keyPath := flag.String("key", os.Getenv("PREFLIGHT_KB_SIGNING_KEY"), "path to the knowledge pack signing key (default $PREFLIGHT_KB_SIGNING_KEY)")
+	packPath := flag.String("pack", "internal/domain/catalog/tools/tools.yaml", "path to the knowledge pack")
+	printPublic := flag.Bool("public-key", false, "print the public half of the key and exit")
+	flag.Parse()
+
+	if err := run(*keyPath, *packPath, *printPublic); err != nil {
+		fmt.Fprintf(os.Stderr, "sign-knowledge-pack: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(keyPath, packPath string, printPublic bool) error {
+	if keyPath == "" {
+		return fmt.Errorf("no signing key; pass -key or set PREFLIGHT_KB_SIGNING_KEY")
+	}
+	key, err := marketplace.LoadSigningKey(keyPath)
+	if err != nil {
+		return err
+	}
+	if printPublic {
+		fmt.Println(tools.KnowledgePackPublicKey(key))
+		return nil
+	}
+
+	// #nosec G304 -- the pack path is supplied by the maintainer.
+	data, err := os.ReadFile(packPath)
+	if err != nil {
+		return err
+	}
+	if _, err := tools.ParseKnowledgeBase(data); err != nil {
+		return fmt.Errorf("%s: %w", packPath, err)
+	}
+	signature, err := tools.SignKnowledgePack(data, key)
+	if err != nil {
+		return fmt.Errorf("%w (public half %s)", err, tools.KnowledgePackPublicKey(key))
+	}
+	// #nosec G306 -- the signature is published with the pack.
+	if err := os.WriteFile(packPath+".sig", []byte(signature+"\n"), 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("Signed %s\n", packPath)
+	return nil
+}